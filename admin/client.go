@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// The backoff between attempts to watch doubles from minBackoff up to
+// maxBackoff.
+const (
+	minBackoff = 500 * time.Millisecond
+	maxBackoff = 20 * time.Second
+)
+
+// state is the state of the stream of updates.
+type state int
+
+const (
+	connecting state = iota
+	streaming
+	waiting
+)
+
+// statusEvent reports a change of the stream, with the error and the
+// time of the next attempt while waiting.
+type statusEvent struct {
+	state state
+	err   error
+	retry time.Time
+}
+
+// watch streams the events of the fleet to events, watching again with
+// backoff when the stream fails, until ctx is done.
+func watch(ctx context.Context, c FleetClient, events chan<- interface{}) {
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	send := func(e interface{}) bool {
+		select {
+		case events <- e:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	failures := 0
+	for {
+		if !send(statusEvent{state: connecting}) {
+			return
+		}
+		sctx, cancel := context.WithCancel(ctx)
+		s, err := c.Watch(sctx, &WatchRequest{})
+		if err == nil {
+			if !send(statusEvent{state: streaming}) {
+				cancel()
+				return
+			}
+			for {
+				var e *Event
+				e, err = s.Recv()
+				if err != nil {
+					break
+				}
+				// The stream works once it is synced, and failing
+				// after that is not failing again.
+				if e.Kind == Event_SYNCED {
+					failures = 0
+				}
+				if !send(e) {
+					cancel()
+					return
+				}
+			}
+		}
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		failures++
+		d := backoff(failures, rnd)
+		if !send(statusEvent{state: waiting, err: err, retry: time.Now().Add(d)}) {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d):
+		}
+	}
+}
+
+// backoff returns the wait before watching again after a number of
+// failures in a row: doubling up to maxBackoff, less a random fifth.
+func backoff(failures int, rnd *rand.Rand) time.Duration {
+	d := maxBackoff
+	if failures < 16 {
+		if e := minBackoff << (failures - 1); e < d {
+			d = e
+		}
+	}
+	return d - time.Duration(rnd.Int63n(int64(d/5)+1))
+}
@@ -0,0 +1,494 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program is an admin console for a fleet of servers, a client of
+// the gRPC service in fleet.proto. A server-streaming call pushes the
+// servers into a table as they change; select one to see its details and
+// restart it with a unary call. The stream is watched again with growing
+// waits when it fails, and the connection state is shown at the top.
+//
+// Without -addr, the program serves a demo of the service on a local
+// port, and the Simulate outage button makes it unavailable for a while
+// to show the retries.
+//
+// Usage:
+//
+//	go run ./admin [-addr host:port]
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/pointer"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/proto"
+)
+
+var addrFlag = flag.String("addr", "", "address of a Fleet gRPC server with TLS, host:port (default a local demo server)")
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+// outage is the length of a simulated outage.
+const outage = 8 * time.Second
+
+// flash is how long changed rows are highlighted.
+const flash = time.Second
+
+var (
+	errorColor     = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	connectedColor = color.NRGBA{R: 0x2e, G: 0x7d, B: 0x32, A: 0xff}
+	waitingColor   = color.NRGBA{R: 0xf9, G: 0xa8, B: 0x25, A: 0xff}
+	staleColor     = color.NRGBA{A: 0x60}
+)
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Fleet Admin"),
+			app.Size(unit.Dp(1000), unit.Dp(640)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// row is a server in the table.
+type row struct {
+	srv *Server
+	// gen is the generation of the stream that last sent the server.
+	gen     int
+	changed time.Time
+	click   widget.Clickable
+}
+
+// restartResult is the outcome of a restart.
+type restartResult struct {
+	id   string
+	resp *RestartResponse
+	err  error
+}
+
+type App struct {
+	w      *app.Window
+	ctx    context.Context
+	client FleetClient
+	events chan interface{}
+	demo   *fleet
+	addr   string
+
+	status statusEvent
+	// gen counts the streams, to drop the servers a new stream doesn't
+	// send before it is synced.
+	gen    int
+	synced bool
+	rows   map[string]*row
+	order  []string
+
+	selected   string
+	restarting bool
+	result     string
+	resultErr  bool
+
+	list               layout.List
+	restart, outageBtn widget.Clickable
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a := &App{
+		w:      w,
+		ctx:    ctx,
+		addr:   *addrFlag,
+		events: make(chan interface{}),
+		rows:   make(map[string]*row),
+		list:   layout.List{Axis: layout.Vertical},
+	}
+	tlsConf := new(tls.Config)
+	if a.addr == "" {
+		a.demo = newFleet()
+		addr, cert, err := serveDemo(ctx, a.demo)
+		if err != nil {
+			return err
+		}
+		a.addr = addr
+		tlsConf.RootCAs = x509.NewCertPool()
+		tlsConf.RootCAs.AddCert(cert)
+	}
+	conn, err := grpc.DialContext(ctx, a.addr, grpc.WithTransportCredentials(credentials.NewTLS(tlsConf)))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	a.client = NewFleetClient(conn)
+	go watch(ctx, a.client, a.events)
+	var ops op.Ops
+	for {
+		select {
+		case e := <-a.events:
+			a.handle(e)
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update()
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+func (a *App) handle(e interface{}) {
+	switch e := e.(type) {
+	case statusEvent:
+		a.status = e
+		if e.state == streaming {
+			a.gen++
+			a.synced = false
+		}
+	case *Event:
+		a.apply(e)
+	case restartResult:
+		a.restarting = false
+		if e.err != nil {
+			a.result, a.resultErr = e.err.Error(), true
+		} else {
+			a.result, a.resultErr = e.resp.Message, false
+		}
+	}
+}
+
+// apply applies an event of the stream to the table.
+func (a *App) apply(e *Event) {
+	now := time.Now()
+	switch e.Kind {
+	case Event_UPSERT:
+		r, ok := a.rows[e.Server.Id]
+		if !ok {
+			r = new(row)
+			a.rows[e.Server.Id] = r
+		}
+		if ok && !proto.Equal(r.srv, e.Server) && a.synced {
+			r.changed = now
+		}
+		r.srv, r.gen = e.Server, a.gen
+	case Event_REMOVE:
+		delete(a.rows, e.Server.Id)
+	case Event_SYNCED:
+		a.synced = true
+		for id, r := range a.rows {
+			if r.gen != a.gen {
+				delete(a.rows, id)
+			}
+		}
+	}
+	a.order = a.order[:0]
+	for id := range a.rows {
+		a.order = append(a.order, id)
+	}
+	sort.Slice(a.order, func(i, j int) bool {
+		return a.rows[a.order[i]].srv.Name < a.rows[a.order[j]].srv.Name
+	})
+}
+
+func (a *App) update() {
+	for id, r := range a.rows {
+		if r.click.Clicked() && a.selected != id {
+			a.selected = id
+			a.result = ""
+		}
+	}
+	if a.restart.Clicked() && !a.restarting && a.rows[a.selected] != nil {
+		a.restarting, a.result = true, ""
+		id := a.selected
+		go func() {
+			ctx, cancel := context.WithTimeout(a.ctx, 5*time.Second)
+			defer cancel()
+			resp, err := a.client.Restart(ctx, &RestartRequest{Id: id})
+			select {
+			case a.events <- restartResult{id: id, resp: resp, err: err}:
+			case <-a.ctx.Done():
+			}
+		}()
+	}
+	if a.demo != nil && a.outageBtn.Clicked() {
+		a.demo.Outage(outage)
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return a.layoutStatus(gtx, th)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Flexed(1, func(gtx C) D {
+					return a.layoutTable(gtx, th)
+				}),
+				layout.Rigid(func(gtx C) D {
+					gtx.Constraints.Min.X = gtx.Px(unit.Dp(300))
+					gtx.Constraints.Max.X = gtx.Constraints.Min.X
+					return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+						return a.layoutDetails(gtx, th)
+					})
+				}),
+			)
+		}),
+	)
+}
+
+func (a *App) layoutStatus(gtx C, th *material.Theme) D {
+	s := a.status
+	col := waitingColor
+	var txt string
+	switch {
+	case s.state == connecting:
+		txt = "Connecting to " + a.addr + "…"
+	case s.state == streaming && !a.synced:
+		txt = "Loading servers from " + a.addr + "…"
+	case s.state == streaming:
+		col = connectedColor
+		txt = fmt.Sprintf("Streaming updates from %s · %d servers", a.addr, len(a.rows))
+	case s.state == waiting:
+		col = errorColor
+		wait := s.retry.Sub(gtx.Now).Round(time.Second)
+		if wait < 0 {
+			wait = 0
+		}
+		txt = fmt.Sprintf("%v. Retrying in %v", s.err, wait)
+		op.InvalidateOp{At: gtx.Now.Add(time.Second)}.Add(gtx.Ops)
+	}
+	return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+		children := []layout.FlexChild{
+			layout.Rigid(func(gtx C) D {
+				size := gtx.Px(unit.Dp(10))
+				paint.FillShape(gtx.Ops, col, clip.UniformRRect(f32.Rectangle{Max: layout.FPt(image.Pt(size, size))}, float32(size)/2).Op(gtx.Ops))
+				return D{Size: image.Pt(size, size)}
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+			layout.Flexed(1, func(gtx C) D {
+				l := material.Body1(th, txt)
+				l.MaxLines = 1
+				return l.Layout(gtx)
+			}),
+		}
+		if a.demo != nil {
+			children = append(children, layout.Rigid(material.Button(th, &a.outageBtn, "Simulate outage").Layout))
+		}
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx, children...)
+	})
+}
+
+// columns are the headers and relative widths of the table columns.
+var columns = []struct {
+	title string
+	width float32
+}{
+	{"Name", 3}, {"Region", 2}, {"State", 2}, {"CPU", 1}, {"Memory", 1}, {"Version", 1},
+}
+
+func (a *App) layoutTable(gtx C, th *material.Theme) D {
+	stale := a.status.state != streaming
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return tableRow(gtx, func(gtx C, col int) D {
+				l := material.Body2(th, columns[col].title)
+				l.Color = staleColor
+				return l.Layout(gtx)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return a.list.Layout(gtx, len(a.order), func(gtx C, i int) D {
+				id := a.order[i]
+				r := a.rows[id]
+				return material.Clickable(gtx, &r.click, func(gtx C) D {
+					bg := color.NRGBA{}
+					if age := gtx.Now.Sub(r.changed); age < flash {
+						// Fade the highlight of changed rows.
+						bg = th.Palette.ContrastBg
+						bg.A = uint8(0x50 * (1 - float32(age)/float32(flash)))
+						op.InvalidateOp{}.Add(gtx.Ops)
+					}
+					if id == a.selected {
+						bg = th.Palette.ContrastBg
+						bg.A = 0x30
+					}
+					return layout.Stack{}.Layout(gtx,
+						layout.Expanded(func(gtx C) D {
+							paint.FillShape(gtx.Ops, bg, clip.Rect{Max: gtx.Constraints.Min}.Op())
+							pointer.CursorNameOp{Name: pointer.CursorPointer}.Add(gtx.Ops)
+							return D{Size: gtx.Constraints.Min}
+						}),
+						layout.Stacked(func(gtx C) D {
+							return tableRow(gtx, func(gtx C, col int) D {
+								l := material.Body1(th, cell(r.srv, col))
+								l.MaxLines = 1
+								switch {
+								case stale:
+									l.Color = staleColor
+								case col == 2 && r.srv.State != State_STATE_RUNNING:
+									l.Color = stateColor(r.srv.State)
+								}
+								return l.Layout(gtx)
+							})
+						}),
+					)
+				})
+			})
+		}),
+	)
+}
+
+func tableRow(gtx C, cell func(gtx C, col int) D) D {
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	children := make([]layout.FlexChild, len(columns))
+	for i, c := range columns {
+		i := i
+		children[i] = layout.Flexed(c.width, func(gtx C) D {
+			return cell(gtx, i)
+		})
+	}
+	return layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(6), Left: unit.Dp(12), Right: unit.Dp(12)}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{}.Layout(gtx, children...)
+	})
+}
+
+func cell(s *Server, col int) string {
+	switch col {
+	case 0:
+		return s.Name
+	case 1:
+		return s.Region
+	case 2:
+		return stateName(s.State)
+	case 3:
+		return fmt.Sprintf("%.0f%%", s.Cpu)
+	case 4:
+		return fmt.Sprintf("%d GiB", s.MemoryBytes>>30)
+	default:
+		return s.Version
+	}
+}
+
+// stateName returns the name of a state for display.
+func stateName(s State) string {
+	switch s {
+	case State_STATE_RUNNING:
+		return "Running"
+	case State_STATE_DEGRADED:
+		return "Degraded"
+	case State_STATE_RESTARTING:
+		return "Restarting"
+	case State_STATE_DOWN:
+		return "Down"
+	default:
+		return "Unknown"
+	}
+}
+
+func stateColor(s State) color.NRGBA {
+	switch s {
+	case State_STATE_DEGRADED, State_STATE_RESTARTING:
+		return color.NRGBA{R: 0xe6, G: 0x51, B: 0x00, A: 0xff}
+	case State_STATE_DOWN:
+		return errorColor
+	default:
+		return color.NRGBA{A: 0xff}
+	}
+}
+
+func (a *App) layoutDetails(gtx C, th *material.Theme) D {
+	if a.selected == "" {
+		return material.Body1(th, "Select a server to see its details.").Layout(gtx)
+	}
+	r, ok := a.rows[a.selected]
+	if !ok {
+		return material.Body1(th, "The server was removed.").Layout(gtx)
+	}
+	s := r.srv
+	field := func(name, value string) layout.FlexChild {
+		return layout.Rigid(func(gtx C) D {
+			return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx C) D {
+				return layout.Flex{}.Layout(gtx,
+					layout.Flexed(1, func(gtx C) D {
+						l := material.Body2(th, name)
+						l.Color = staleColor
+						return l.Layout(gtx)
+					}),
+					layout.Flexed(2, material.Body1(th, value).Layout),
+				)
+			})
+		})
+	}
+	restart := material.Button(th, &a.restart, "Restart")
+	if a.restarting {
+		restart.Text = "Restarting…"
+	}
+	restartBtn := func(gtx C) D {
+		if a.restarting || a.status.state != streaming {
+			gtx = gtx.Disabled()
+			restart.Background = staleColor
+		}
+		return restart.Layout(gtx)
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Bottom: unit.Dp(12)}.Layout(gtx, material.H6(th, s.Name).Layout)
+		}),
+		field("ID", s.Id),
+		field("Region", s.Region),
+		field("State", stateName(s.State)),
+		field("CPU", fmt.Sprintf("%.1f%%", s.Cpu)),
+		field("Memory", fmt.Sprintf("%d GiB", s.MemoryBytes>>30)),
+		field("Uptime", (time.Duration(s.UptimeSeconds)*time.Second).String()),
+		field("Version", s.Version),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+		layout.Rigid(restartBtn),
+		layout.Rigid(func(gtx C) D {
+			if a.result == "" {
+				return D{}
+			}
+			l := material.Body2(th, a.result)
+			if a.resultErr {
+				l.Color = errorColor
+			}
+			return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, l.Layout)
+		}),
+	)
+}
@@ -0,0 +1,296 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	mrand "math/rand"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// fleet is the demo implementation of the Fleet service: a made up fleet
+// of servers whose load changes, which now and then degrade, and which
+// are added and removed as if by an autoscaler.
+type fleet struct {
+	UnimplementedFleetServer
+
+	mu       sync.Mutex
+	rnd      *mrand.Rand
+	servers  map[string]*Server
+	nextID   int
+	watchers map[*watcher]bool
+	// outage is the end of a simulated outage.
+	outage time.Time
+}
+
+type watcher struct {
+	region string
+	events chan *Event
+}
+
+var regions = []string{"eu-west", "us-east", "ap-south"}
+
+func newFleet() *fleet {
+	f := &fleet{
+		rnd:      mrand.New(mrand.NewSource(1)),
+		servers:  make(map[string]*Server),
+		watchers: make(map[*watcher]bool),
+	}
+	for i := 0; i < 24; i++ {
+		f.add()
+	}
+	return f
+}
+
+// add adds a server, which must be called with f.mu held.
+func (f *fleet) add() *Server {
+	f.nextID++
+	region := regions[f.rnd.Intn(len(regions))]
+	s := &Server{
+		Id:            fmt.Sprintf("srv-%04d", f.nextID),
+		Name:          fmt.Sprintf("%s-web-%02d", region, f.nextID),
+		Region:        region,
+		State:         State_STATE_RUNNING,
+		Cpu:           10 + 40*f.rnd.Float64(),
+		MemoryBytes:   int64(1+f.rnd.Intn(7)) << 30,
+		UptimeSeconds: int64(f.rnd.Intn(30 * 24 * 3600)),
+		Version:       "1.8.2",
+	}
+	if f.rnd.Intn(4) == 0 {
+		s.Version = "1.9.0"
+	}
+	f.servers[s.Id] = s
+	return s
+}
+
+// run changes the fleet until ctx is done.
+func (f *fleet) run(ctx context.Context) {
+	t := time.NewTicker(500 * time.Millisecond)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			f.step()
+		}
+	}
+}
+
+func (f *fleet) step() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ids := make([]string, 0, len(f.servers))
+	for id := range f.servers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for i := 0; i < 3 && len(ids) > 0; i++ {
+		s := f.servers[ids[f.rnd.Intn(len(ids))]]
+		s.UptimeSeconds++
+		s.Cpu += f.rnd.NormFloat64() * 8
+		if s.Cpu < 2 {
+			s.Cpu = 2
+		}
+		if s.Cpu > 100 {
+			s.Cpu = 100
+		}
+		switch {
+		case s.State == State_STATE_RUNNING && s.Cpu > 90:
+			s.State = State_STATE_DEGRADED
+		case s.State == State_STATE_DEGRADED && s.Cpu < 70:
+			s.State = State_STATE_RUNNING
+		case s.State == State_STATE_RUNNING && f.rnd.Intn(200) == 0:
+			s.State = State_STATE_DOWN
+			s.Cpu = 0
+		}
+		f.publish(Event_UPSERT, s)
+	}
+	switch n := f.rnd.Intn(40); {
+	case n == 0 && len(ids) < 40:
+		f.publish(Event_UPSERT, f.add())
+	case n == 1 && len(ids) > 10:
+		s := f.servers[ids[f.rnd.Intn(len(ids))]]
+		delete(f.servers, s.Id)
+		f.publish(Event_REMOVE, s)
+	}
+}
+
+// publish sends an event about a server to the watchers, with f.mu held.
+// The event carries a copy of the server.
+func (f *fleet) publish(kind Event_Kind, s *Server) {
+	s = proto.Clone(s).(*Server)
+	e := &Event{Kind: kind, Server: s}
+	for w := range f.watchers {
+		if w.region != "" && w.region != s.Region {
+			continue
+		}
+		select {
+		case w.events <- e:
+		default:
+			// Drop watchers too slow to keep up, ending their
+			// streams.
+			delete(f.watchers, w)
+			close(w.events)
+		}
+	}
+}
+
+// Outage simulates an outage of the service for a duration: streams end
+// and calls fail with Unavailable.
+func (f *fleet) Outage(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.outage = time.Now().Add(d)
+	for w := range f.watchers {
+		delete(f.watchers, w)
+		close(w.events)
+	}
+}
+
+func (f *fleet) unavailable() error {
+	if time.Now().Before(f.outage) {
+		return status.Error(codes.Unavailable, "service unavailable (simulated outage)")
+	}
+	return nil
+}
+
+func (f *fleet) Watch(req *WatchRequest, stream Fleet_WatchServer) error {
+	f.mu.Lock()
+	if err := f.unavailable(); err != nil {
+		f.mu.Unlock()
+		return err
+	}
+	w := &watcher{region: req.Region, events: make(chan *Event, 256)}
+	var snapshot []*Server
+	for _, s := range f.servers {
+		if req.Region == "" || s.Region == req.Region {
+			snapshot = append(snapshot, proto.Clone(s).(*Server))
+		}
+	}
+	f.watchers[w] = true
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		if f.watchers[w] {
+			delete(f.watchers, w)
+			close(w.events)
+		}
+		f.mu.Unlock()
+	}()
+
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].Id < snapshot[j].Id })
+	for _, s := range snapshot {
+		if err := stream.Send(&Event{Kind: Event_UPSERT, Server: s}); err != nil {
+			return err
+		}
+	}
+	if err := stream.Send(&Event{Kind: Event_SYNCED}); err != nil {
+		return err
+	}
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case e, ok := <-w.events:
+			if !ok {
+				return status.Error(codes.Unavailable, "stream ended by the server")
+			}
+			if err := stream.Send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (f *fleet) Restart(ctx context.Context, req *RestartRequest) (*RestartResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.unavailable(); err != nil {
+		return nil, err
+	}
+	s, ok := f.servers[req.Id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "no server "+req.Id)
+	}
+	s.State, s.Cpu, s.UptimeSeconds = State_STATE_RESTARTING, 0, 0
+	f.publish(Event_UPSERT, s)
+	time.AfterFunc(3*time.Second, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if s, ok := f.servers[req.Id]; ok && s.State == State_STATE_RESTARTING {
+			s.State, s.Cpu = State_STATE_RUNNING, 15
+			f.publish(Event_UPSERT, s)
+		}
+	})
+	return &RestartResponse{Message: s.Name + " is restarting"}, nil
+}
+
+// serveDemo serves the demo service over TLS on a local port, returning
+// its address and the certificate to trust.
+func serveDemo(ctx context.Context, f *fleet) (string, *x509.Certificate, error) {
+	cert, err := selfSigned()
+	if err != nil {
+		return "", nil, err
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+	srv := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{cert}})))
+	RegisterFleetServer(srv, f)
+	go srv.Serve(l)
+	go f.run(ctx)
+	go func() {
+		<-ctx.Done()
+		srv.Stop()
+	}()
+	return l.Addr().String(), cert.Leaf, nil
+}
+
+// selfSigned returns a certificate for 127.0.0.1, valid for a day.
+func selfSigned() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Fleet demo"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, nil
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestConn(t *testing.T, f *fleet) *grpc.ClientConn {
+	t.Helper()
+	l := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	RegisterFleetServer(srv, f)
+	go srv.Serve(l)
+	t.Cleanup(srv.Stop)
+	dial := func(ctx context.Context, _ string) (net.Conn, error) {
+		return l.DialContext(ctx)
+	}
+	conn, err := grpc.Dial("bufnet", grpc.WithContextDialer(dial), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestWatch(t *testing.T) {
+	f := newFleet()
+	c := NewFleetClient(newTestConn(t, f))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	s, err := c.Watch(ctx, &WatchRequest{Region: "eu-west"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first *Server
+	for {
+		e, err := s.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Kind == Event_SYNCED {
+			break
+		}
+		if e.Server.Region != "eu-west" {
+			t.Errorf("server %s of region %s sent", e.Server.Id, e.Server.Region)
+		}
+		if first == nil {
+			first = e.Server
+		}
+	}
+	if first == nil {
+		t.Fatal("no servers")
+	}
+	resp, err := c.Restart(ctx, &RestartRequest{Id: first.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Message == "" {
+		t.Error("restart response has no message")
+	}
+	e, err := s.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Server.Id != first.Id || e.Server.State != State_STATE_RESTARTING {
+		t.Errorf("got %v, want %s restarting", e.Server, first.Id)
+	}
+
+	// An outage ends the stream and fails calls as unavailable.
+	f.Outage(time.Minute)
+	if _, err := s.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("stream ended with %v, want code %v", err, codes.Unavailable)
+	}
+	// Errors of streams come with their first message.
+	s, err = c.Watch(ctx, &WatchRequest{})
+	if err == nil {
+		_, err = s.Recv()
+	}
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("watch during outage failed with %v, want code %v", err, codes.Unavailable)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	conn := newTestConn(t, newFleet())
+	c := NewFleetClient(conn)
+	ctx := context.Background()
+	if _, err := c.Restart(ctx, &RestartRequest{Id: "nonexistent"}); status.Code(err) != codes.NotFound {
+		t.Errorf("restart of unknown server failed with %v, want code %v", err, codes.NotFound)
+	}
+	var resp RestartResponse
+	if err := conn.Invoke(ctx, "/fleet.Fleet/Delete", &RestartRequest{}, &resp); status.Code(err) != codes.Unimplemented {
+		t.Errorf("unknown method failed with %v, want code %v", err, codes.Unimplemented)
+	}
+}
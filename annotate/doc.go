@@ -0,0 +1,281 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+// Kind is the kind of an annotation.
+type Kind int
+
+const (
+	Arrow Kind = iota
+	Box
+	Text
+	Blur
+)
+
+const (
+	// strokeWidth is the width of arrows and boxes, and textSize the
+	// size of text, in pixels of the image.
+	strokeWidth = 4
+	textSize    = 28
+	// blurRadius is the radius of the box blurs of blurred regions.
+	blurRadius = 10
+)
+
+// Annotation is a mark on the image. Annotations are not changed once
+// added to a document.
+type Annotation struct {
+	Kind Kind
+	// From and To are the tail and tip of arrows, or opposite corners of
+	// boxes and blurred regions, in pixels of the image. Text starts at
+	// From.
+	From, To f32.Point
+	Color    color.NRGBA
+	Text     string
+	// blurred is the image of a blurred region.
+	blurred paint.ImageOp
+}
+
+// Rect returns the rectangle between the points of an annotation.
+func (a *Annotation) Rect() image.Rectangle {
+	return image.Rect(int(a.From.X), int(a.From.Y), int(a.To.X), int(a.To.Y)).Canon()
+}
+
+// Doc is an image and its annotations, with undo.
+type Doc struct {
+	img    *image.RGBA
+	imgOp  paint.ImageOp
+	annots []*Annotation
+	undo   []docState
+}
+
+type docState struct {
+	img    *image.RGBA
+	imgOp  paint.ImageOp
+	annots []*Annotation
+}
+
+func NewDoc(img image.Image) *Doc {
+	rgba := image.NewRGBA(image.Rectangle{Max: img.Bounds().Size()})
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return &Doc{img: rgba, imgOp: paint.NewImageOp(rgba)}
+}
+
+func (d *Doc) Size() image.Point {
+	return d.img.Bounds().Size()
+}
+
+func (d *Doc) Annotations() []*Annotation {
+	return d.annots
+}
+
+func (d *Doc) save() {
+	d.undo = append(d.undo, docState{img: d.img, imgOp: d.imgOp, annots: d.annots})
+}
+
+// Add adds an annotation. Blurred regions blur the image as it is,
+// annotations included.
+func (d *Doc) Add(a Annotation) {
+	d.save()
+	if a.Kind == Blur {
+		a.blurred = paint.NewImageOp(d.blur(a.Rect()))
+	}
+	// Copy on write, so that undo states share the annotations.
+	annots := make([]*Annotation, len(d.annots), len(d.annots)+1)
+	copy(annots, d.annots)
+	d.annots = append(annots, &a)
+}
+
+// blur returns the region r of the image blurred. Regions blur the
+// pixels of the image, not the annotations drawn over them.
+func (d *Doc) blur(r image.Rectangle) *image.RGBA {
+	return blur(d.img, r, blurRadius)
+}
+
+// Crop crops the image to r, moving the annotations along.
+func (d *Doc) Crop(r image.Rectangle) {
+	r = r.Intersect(d.img.Bounds())
+	if r.Empty() {
+		return
+	}
+	d.save()
+	img := image.NewRGBA(image.Rectangle{Max: r.Size()})
+	draw.Draw(img, img.Bounds(), d.img, r.Min, draw.Src)
+	d.img, d.imgOp = img, paint.NewImageOp(img)
+	off := layout.FPt(r.Min)
+	var annots []*Annotation
+	for _, a := range d.annots {
+		moved := *a
+		moved.From, moved.To = a.From.Sub(off), a.To.Sub(off)
+		annots = append(annots, &moved)
+	}
+	d.annots = annots
+}
+
+// CanUndo reports whether there is a change to undo.
+func (d *Doc) CanUndo() bool {
+	return len(d.undo) > 0
+}
+
+func (d *Doc) Undo() {
+	if len(d.undo) == 0 {
+		return
+	}
+	s := d.undo[len(d.undo)-1]
+	d.undo = d.undo[:len(d.undo)-1]
+	d.img, d.imgOp, d.annots = s.img, s.imgOp, s.annots
+}
+
+// Draw draws the image and its annotations at their size in pixels, and
+// a pending annotation, if any. Drawing is the same on the screen and
+// offscreen, for saving.
+func (d *Doc) Draw(gtx layout.Context, th *material.Theme, pending *Annotation) {
+	d.imgOp.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+	for _, a := range d.annots {
+		drawAnnotation(gtx, th, a)
+	}
+	if pending != nil {
+		drawAnnotation(gtx, th, pending)
+	}
+}
+
+func drawAnnotation(gtx layout.Context, th *material.Theme, a *Annotation) {
+	ops := gtx.Ops
+	switch a.Kind {
+	case Arrow:
+		drawArrow(ops, a.From, a.To, a.Color)
+	case Box:
+		r := layout.FRect(a.Rect())
+		var p clip.Path
+		p.Begin(ops)
+		p.MoveTo(r.Min)
+		p.LineTo(f32.Pt(r.Max.X, r.Min.Y))
+		p.LineTo(r.Max)
+		p.LineTo(f32.Pt(r.Min.X, r.Max.Y))
+		p.Close()
+		paint.FillShape(ops, a.Color, clip.Stroke{Path: p.End(), Style: clip.StrokeStyle{Width: strokeWidth, Join: clip.RoundJoin}}.Op())
+	case Blur:
+		r := a.Rect()
+		st := op.Save(ops)
+		if a.blurred.Size() == (image.Point{}) {
+			// A region being dragged.
+			paint.FillShape(ops, color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0x80}, clip.Rect(r).Op())
+		} else {
+			op.Offset(layout.FPt(r.Min)).Add(ops)
+			a.blurred.Add(ops)
+			paint.PaintOp{}.Add(ops)
+		}
+		st.Load()
+	case Text:
+		st := op.Save(ops)
+		op.Offset(a.From).Add(ops)
+		gtx := gtx
+		gtx.Constraints = layout.Constraints{Max: image.Pt(1<<20, 1<<20)}
+		l := material.Label(th, unit.Px(textSize), a.Text)
+		l.Font.Weight = text.Bold
+		l.Color = a.Color
+		l.Layout(gtx)
+		st.Load()
+	}
+}
+
+// drawArrow draws an arrow from the tail to the tip, with a filled head.
+func drawArrow(ops *op.Ops, tail, tip f32.Point, col color.NRGBA) {
+	d := tip.Sub(tail)
+	length := float32(math.Hypot(float64(d.X), float64(d.Y)))
+	if length < 1 {
+		return
+	}
+	dir := d.Mul(1 / length)
+	normal := f32.Pt(-dir.Y, dir.X)
+	head := float32(5 * strokeWidth)
+	if head > length {
+		head = length
+	}
+	base := tip.Sub(dir.Mul(head))
+	var p clip.Path
+	p.Begin(ops)
+	p.MoveTo(tail)
+	p.LineTo(base)
+	paint.FillShape(ops, col, clip.Stroke{Path: p.End(), Style: clip.StrokeStyle{Width: strokeWidth, Cap: clip.RoundCap}}.Op())
+	p.Begin(ops)
+	p.MoveTo(tip)
+	p.LineTo(base.Add(normal.Mul(head / 2)))
+	p.LineTo(base.Sub(normal.Mul(head / 2)))
+	p.Close()
+	paint.FillShape(ops, col, clip.Outline{Path: p.End()}.Op())
+}
+
+// blur returns the region r of img blurred by three box blurs of a
+// radius, which approximate a Gaussian blur.
+func blur(img *image.RGBA, r image.Rectangle, radius int) *image.RGBA {
+	r = r.Intersect(img.Bounds())
+	out := image.NewRGBA(image.Rectangle{Max: r.Size()})
+	draw.Draw(out, out.Bounds(), img, r.Min, draw.Src)
+	if r.Empty() {
+		return out
+	}
+	tmp := image.NewRGBA(out.Bounds())
+	for i := 0; i < 3; i++ {
+		boxBlur(tmp, out, radius, true)
+		boxBlur(out, tmp, radius, false)
+	}
+	return out
+}
+
+// boxBlur blurs the rows or columns of src into dst, of the same size,
+// with a moving average. Pixels past the edges repeat the edge pixels.
+func boxBlur(dst, src *image.RGBA, radius int, rows bool) {
+	size := src.Bounds().Size()
+	n, lines := size.X, size.Y
+	if !rows {
+		n, lines = size.Y, size.X
+	}
+	offset := func(line, i int) int {
+		if i < 0 {
+			i = 0
+		} else if i >= n {
+			i = n - 1
+		}
+		if rows {
+			return line*src.Stride + i*4
+		}
+		return i*src.Stride + line*4
+	}
+	div := 2*radius + 1
+	for l := 0; l < lines; l++ {
+		var sum [4]int
+		for i := -radius; i <= radius; i++ {
+			o := offset(l, i)
+			for c := range sum {
+				sum[c] += int(src.Pix[o+c])
+			}
+		}
+		for i := 0; i < n; i++ {
+			o := offset(l, i)
+			for c := range sum {
+				dst.Pix[o+c] = uint8(sum[c] / div)
+			}
+			in, out := offset(l, i+radius+1), offset(l, i-radius)
+			for c := range sum {
+				sum[c] += int(src.Pix[in+c]) - int(src.Pix[out+c])
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"gioui.org/f32"
+)
+
+func TestBlur(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	gray := color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}
+	draw.Draw(img, img.Bounds(), &image.Uniform{gray}, image.Point{}, draw.Src)
+	// A white stripe down the middle.
+	draw.Draw(img, image.Rect(19, 0, 21, 20), image.White, image.Point{}, draw.Src)
+
+	out := blur(img, image.Rect(-5, 0, 30, 20), 3)
+	if got := out.Bounds(); got != image.Rect(0, 0, 30, 20) {
+		t.Fatalf("bounds %v, want the region within the image", got)
+	}
+	if got := out.RGBAAt(0, 10); got != gray {
+		t.Errorf("far from the stripe %v, want %v", got, gray)
+	}
+	center, near := out.RGBAAt(19, 10), out.RGBAAt(15, 10)
+	if center.R == 0xff || center.R <= near.R || near.R <= gray.R {
+		t.Errorf("stripe not spread: center %v, near %v", center, near)
+	}
+	if center.A != 0xff {
+		t.Errorf("alpha %d, want opaque", center.A)
+	}
+}
+
+func TestDoc(t *testing.T) {
+	d := NewDoc(image.NewRGBA(image.Rect(10, 10, 110, 60)))
+	if d.Size() != image.Pt(100, 50) || d.CanUndo() {
+		t.Fatalf("new document of size %v", d.Size())
+	}
+	d.Add(Annotation{Kind: Arrow, From: f32.Pt(50, 40), To: f32.Pt(20, 20)})
+	d.Add(Annotation{Kind: Blur, From: f32.Pt(90, 40), To: f32.Pt(60, 10)})
+	if b := d.Annotations()[1]; b.Rect() != image.Rect(60, 10, 90, 40) || b.blurred.Size() != image.Pt(30, 30) {
+		t.Errorf("blur of %v, %v", b.Rect(), b.blurred.Size())
+	}
+	d.Crop(image.Rect(10, 5, 200, 45))
+	if d.Size() != image.Pt(90, 40) {
+		t.Errorf("cropped to %v, want 90x40", d.Size())
+	}
+	if a := d.Annotations()[0]; a.From != f32.Pt(40, 35) || a.To != f32.Pt(10, 15) {
+		t.Errorf("arrow moved to %v-%v", a.From, a.To)
+	}
+	d.Undo()
+	if d.Size() != image.Pt(100, 50) || d.Annotations()[0].From != f32.Pt(50, 40) {
+		t.Error("undo didn't restore the crop")
+	}
+	d.Undo()
+	d.Undo()
+	if len(d.Annotations()) != 0 || d.CanUndo() {
+		t.Errorf("%d annotations after undoing all", len(d.Annotations()))
+	}
+}
@@ -0,0 +1,528 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program annotates screenshots. It captures the screen with the
+// screenshot tool of the platform after a delay, to leave time to
+// arrange the windows, or opens an image file. The image is cropped to
+// the region of interest and annotated with arrows, boxes, text and
+// blurred regions, then copied to the clipboard or saved as PNG.
+//
+// The annotated image is rendered offscreen with the same drawing code
+// as on the screen, at the size of the image.
+//
+// Usage:
+//
+//	go run ./annotate [-delay 3s] [-o directory] [image file]
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/gpu/headless"
+	"gioui.org/io/pointer"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	delayFlag = flag.Duration("delay", 3*time.Second, "delay before capturing the screen")
+	outFlag   = flag.String("o", ".", "directory for the saved images")
+)
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Annotate"),
+			app.Size(unit.Dp(1000), unit.Dp(720)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var (
+	errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	canvasBg   = color.NRGBA{R: 0x60, G: 0x60, B: 0x60, A: 0xff}
+	// palette are the colors of annotations.
+	palette = []color.NRGBA{
+		{R: 0xf4, G: 0x43, B: 0x36, A: 0xff},
+		{R: 0xff, G: 0xeb, B: 0x3b, A: 0xff},
+		{R: 0x4c, G: 0xaf, B: 0x50, A: 0xff},
+		{R: 0x21, G: 0x96, B: 0xf3, A: 0xff},
+		{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		{R: 0x00, G: 0x00, B: 0x00, A: 0xff},
+	}
+)
+
+// tools are the values of the tool picker, and the kinds of the
+// annotations they add.
+var tools = []struct {
+	value, name string
+	kind        Kind
+}{
+	{"crop", "Crop", 0},
+	{"arrow", "Arrow", Arrow},
+	{"box", "Box", Box},
+	{"text", "Text", Text},
+	{"blur", "Blur", Blur},
+}
+
+// minDrag is the distance in image pixels a drag must cover to add an
+// annotation, so that stray clicks don't.
+const minDrag = 4
+
+// result is the outcome of a background task: a captured image, or a
+// status to show.
+type result struct {
+	img    image.Image
+	status string
+	err    error
+}
+
+// renderer renders documents offscreen and reads back the pixels.
+type renderer struct {
+	win  *headless.Window
+	size image.Point
+	ops  op.Ops
+}
+
+func (r *renderer) render(th *material.Theme, d *Doc) (*image.RGBA, error) {
+	size := d.Size()
+	if r.win == nil || r.size != size {
+		r.release()
+		w, err := headless.NewWindow(size.X, size.Y)
+		if err != nil {
+			return nil, err
+		}
+		r.win, r.size = w, size
+	}
+	r.ops.Reset()
+	gtx := layout.Context{Ops: &r.ops, Constraints: layout.Exact(size)}
+	d.Draw(gtx, th, nil)
+	if err := r.win.Frame(&r.ops); err != nil {
+		return nil, err
+	}
+	return r.win.Screenshot()
+}
+
+func (r *renderer) release() {
+	if r.win != nil {
+		r.win.Release()
+		r.win = nil
+	}
+}
+
+type App struct {
+	doc      *Doc
+	renderer renderer
+	results  chan result
+	// busy describes the background task running, if any.
+	busy   string
+	status string
+	err    error
+
+	capture, undo, copy, save widget.Clickable
+	tool                      widget.Enum
+	swatches                  []widget.Clickable
+	color                     color.NRGBA
+	text                      widget.Editor
+
+	// pending is the annotation or crop being dragged, in pixels of the
+	// image.
+	pending  *Annotation
+	cropping bool
+	// scale and offset map the image to the canvas.
+	scale  float32
+	offset f32.Point
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		results:  make(chan result, 1),
+		tool:     widget.Enum{Value: "arrow"},
+		swatches: make([]widget.Clickable, len(palette)),
+		color:    palette[0],
+	}
+	a.text.SingleLine = true
+	a.text.SetText("Look here")
+	defer a.renderer.release()
+	if file := flag.Arg(0); file != "" {
+		img, err := openImage(file)
+		if err != nil {
+			return err
+		}
+		a.doc = NewDoc(img)
+	}
+	var ops op.Ops
+	for {
+		select {
+		case res := <-a.results:
+			a.busy = ""
+			a.err = res.err
+			a.status = res.status
+			if res.img != nil {
+				a.doc = NewDoc(res.img)
+				a.status = "Drag to crop or annotate."
+			}
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update(gtx, th)
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+func openImage(file string) (image.Image, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return img, nil
+}
+
+// background runs a task, unless one is running.
+func (a *App) background(busy string, task func() result) {
+	if a.busy != "" {
+		return
+	}
+	a.busy = busy
+	a.err = nil
+	go func() {
+		a.results <- task()
+	}()
+}
+
+func (a *App) update(gtx C, th *material.Theme) {
+	for a.capture.Clicked() {
+		a.background(fmt.Sprintf("Capturing the screen in %v…", *delayFlag), func() result {
+			time.Sleep(*delayFlag)
+			img, err := grabScreen()
+			return result{img: img, err: err}
+		})
+	}
+	for i := range a.swatches {
+		for a.swatches[i].Clicked() {
+			a.color = palette[i]
+		}
+	}
+	for a.undo.Clicked() {
+		if a.doc != nil {
+			a.doc.Undo()
+		}
+	}
+	for a.copy.Clicked() {
+		data, err := a.encode(th)
+		if err != nil {
+			a.err = err
+			continue
+		}
+		a.background("Copying…", func() result {
+			return result{status: "Copied to the clipboard.", err: copyImage(data)}
+		})
+	}
+	for a.save.Clicked() {
+		data, err := a.encode(th)
+		if err != nil {
+			a.err = err
+			continue
+		}
+		name := fmt.Sprintf("annotated-%s.png", time.Now().Format("20060102-150405"))
+		path := filepath.Join(*outFlag, name)
+		a.background("Saving…", func() result {
+			return result{status: "Saved " + path, err: ioutil.WriteFile(path, data, 0644)}
+		})
+	}
+	a.pointer(gtx)
+}
+
+// encode renders the document and encodes it as PNG.
+func (a *App) encode(th *material.Theme) ([]byte, error) {
+	if a.doc == nil {
+		return nil, fmt.Errorf("nothing to export")
+	}
+	img, err := a.renderer.render(th, a.doc)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// pointer handles the drags and clicks of the canvas.
+func (a *App) pointer(gtx C) {
+	for _, e := range gtx.Events(a) {
+		e, ok := e.(pointer.Event)
+		if !ok || a.doc == nil || a.scale == 0 {
+			continue
+		}
+		p := a.imagePoint(e.Position)
+		switch e.Type {
+		case pointer.Press:
+			a.press(p)
+		case pointer.Drag:
+			if a.pending != nil {
+				a.pending.To = p
+			}
+		case pointer.Release:
+			if a.pending == nil {
+				break
+			}
+			pa := a.pending
+			a.pending = nil
+			d := pa.To.Sub(pa.From)
+			if d.X*d.X+d.Y*d.Y < minDrag*minDrag {
+				break
+			}
+			if a.cropping {
+				a.doc.Crop(pa.Rect())
+			} else {
+				a.doc.Add(*pa)
+			}
+		case pointer.Cancel:
+			a.pending = nil
+		}
+	}
+}
+
+func (a *App) press(p f32.Point) {
+	a.cropping = a.tool.Value == "crop"
+	var kind Kind
+	for _, t := range tools {
+		if t.value == a.tool.Value {
+			kind = t.kind
+		}
+	}
+	if kind == Text && !a.cropping {
+		if txt := strings.TrimSpace(a.text.Text()); txt != "" {
+			a.doc.Add(Annotation{Kind: Text, From: p, Color: a.color, Text: txt})
+		}
+		return
+	}
+	a.pending = &Annotation{Kind: kind, From: p, To: p, Color: a.color}
+}
+
+// imagePoint maps a point of the canvas to the image, within its
+// bounds.
+func (a *App) imagePoint(p f32.Point) f32.Point {
+	p = p.Sub(a.offset).Mul(1 / a.scale)
+	size := layout.FPt(a.doc.Size())
+	clamp := func(v, max float32) float32 {
+		if v < 0 {
+			return 0
+		}
+		if v > max {
+			return max
+		}
+		return v
+	}
+	return f32.Pt(clamp(p.X, size.X), clamp(p.Y, size.Y))
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return a.layoutActions(gtx, th)
+			})
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Left: unit.Dp(8), Right: unit.Dp(8), Bottom: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+				return a.layoutTools(gtx, th)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return a.layoutCanvas(gtx, th)
+		}),
+	)
+}
+
+func (a *App) layoutActions(gtx C, th *material.Theme) D {
+	button := func(c *widget.Clickable, label string, enabled bool) layout.FlexChild {
+		return layout.Rigid(func(gtx C) D {
+			if !enabled {
+				gtx = gtx.Disabled()
+			}
+			return layout.Inset{Right: unit.Dp(8)}.Layout(gtx, material.Button(th, c, label).Layout)
+		})
+	}
+	idle := a.busy == ""
+	hasDoc := a.doc != nil
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		button(&a.capture, "Capture screen", idle),
+		button(&a.undo, "Undo", hasDoc && a.doc.CanUndo()),
+		button(&a.copy, "Copy", idle && hasDoc),
+		button(&a.save, "Save", idle && hasDoc),
+		layout.Flexed(1, func(gtx C) D {
+			l := material.Body2(th, a.status)
+			switch {
+			case a.err != nil:
+				l.Text, l.Color = a.err.Error(), errorColor
+			case a.busy != "":
+				l.Text = a.busy
+			}
+			l.MaxLines = 2
+			return layout.Inset{Left: unit.Dp(8)}.Layout(gtx, l.Layout)
+		}),
+	)
+}
+
+func (a *App) layoutTools(gtx C, th *material.Theme) D {
+	var children []layout.FlexChild
+	for _, t := range tools {
+		children = append(children, layout.Rigid(material.RadioButton(th, &a.tool, t.value, t.name).Layout))
+	}
+	children = append(children, layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout))
+	for i := range a.swatches {
+		i := i
+		children = append(children, layout.Rigid(func(gtx C) D {
+			return a.layoutSwatch(gtx, th, i)
+		}))
+	}
+	children = append(children,
+		layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+		layout.Flexed(1, func(gtx C) D {
+			if a.tool.Value != "text" {
+				return D{}
+			}
+			return widget.Border{
+				Color:        color.NRGBA{A: 0x40},
+				CornerRadius: unit.Dp(4),
+				Width:        unit.Px(1),
+			}.Layout(gtx, func(gtx C) D {
+				return layout.UniformInset(unit.Dp(6)).Layout(gtx, func(gtx C) D {
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					return material.Editor(th, &a.text, "Text to place").Layout(gtx)
+				})
+			})
+		}),
+	)
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx, children...)
+}
+
+// layoutSwatch lays out a button of a color of the palette, ringed if
+// selected.
+func (a *App) layoutSwatch(gtx C, th *material.Theme, i int) D {
+	return material.Clickable(gtx, &a.swatches[i], func(gtx C) D {
+		sz := gtx.Px(unit.Dp(28))
+		c := f32.Pt(float32(sz)/2, float32(sz)/2)
+		r := float32(gtx.Px(unit.Dp(9)))
+		if palette[i] == a.color {
+			paint.FillShape(gtx.Ops, th.Palette.ContrastBg, clip.Circle{Center: c, Radius: r + float32(gtx.Px(unit.Dp(4)))}.Op(gtx.Ops))
+		}
+		// Outline the swatches, for the white one.
+		paint.FillShape(gtx.Ops, color.NRGBA{A: 0x60}, clip.Circle{Center: c, Radius: r + 1}.Op(gtx.Ops))
+		paint.FillShape(gtx.Ops, palette[i], clip.Circle{Center: c, Radius: r}.Op(gtx.Ops))
+		return D{Size: image.Pt(sz, sz)}
+	})
+}
+
+// layoutCanvas draws the image fitted to the canvas, and takes the
+// pointer input of the tools.
+func (a *App) layoutCanvas(gtx C, th *material.Theme) D {
+	size := gtx.Constraints.Max
+	paint.FillShape(gtx.Ops, canvasBg, clip.Rect{Max: size}.Op())
+	if a.doc == nil {
+		a.scale = 0
+		return layout.Center.Layout(gtx, func(gtx C) D {
+			l := material.Body1(th, "Capture the screen, or pass an image file on the command line.")
+			l.Color = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+			l.Alignment = text.Middle
+			return l.Layout(gtx)
+		})
+	}
+	isz := layout.FPt(a.doc.Size())
+	// Fit the image, without enlarging it.
+	margin := float32(gtx.Px(unit.Dp(16)))
+	a.scale = 1
+	if s := (float32(size.X) - 2*margin) / isz.X; s < a.scale {
+		a.scale = s
+	}
+	if s := (float32(size.Y) - 2*margin) / isz.Y; s < a.scale {
+		a.scale = s
+	}
+	if a.scale <= 0 {
+		a.scale = 0
+		return D{Size: size}
+	}
+	a.offset = layout.FPt(size).Sub(isz.Mul(a.scale)).Mul(0.5)
+
+	st := op.Save(gtx.Ops)
+	op.Affine(f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(a.scale, a.scale)).Offset(a.offset)).Add(gtx.Ops)
+	clip.Rect{Max: a.doc.Size()}.Add(gtx.Ops)
+	pending := a.pending
+	if a.cropping {
+		pending = nil
+	}
+	a.doc.Draw(gtx, th, pending)
+	if a.pending != nil && a.cropping {
+		drawCrop(gtx.Ops, a.doc.Size(), a.pending.Rect())
+	}
+	st.Load()
+
+	pointer.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	pointer.CursorNameOp{Name: pointer.CursorCrossHair}.Add(gtx.Ops)
+	pointer.InputOp{Tag: a, Types: pointer.Press | pointer.Drag | pointer.Release}.Add(gtx.Ops)
+	return D{Size: size}
+}
+
+// drawCrop dims the image outside of the crop rectangle r.
+func drawCrop(ops *op.Ops, size image.Point, r image.Rectangle) {
+	dim := color.NRGBA{A: 0x90}
+	for _, out := range []image.Rectangle{
+		{Max: image.Pt(size.X, r.Min.Y)},
+		{Min: image.Pt(0, r.Max.Y), Max: size},
+		{Min: image.Pt(0, r.Min.Y), Max: image.Pt(r.Min.X, r.Max.Y)},
+		{Min: image.Pt(r.Max.X, r.Min.Y), Max: image.Pt(size.X, r.Max.Y)},
+	} {
+		if !out.Empty() {
+			paint.FillShape(ops, dim, clip.Rect(out).Op())
+		}
+	}
+}
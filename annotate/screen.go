@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// errUnsupported is returned when the platform has no supported way to
+// capture the screen or to copy images.
+var errUnsupported = errors.New("not supported on this platform")
+
+// grabScreen captures the screen with the screenshot tool of the
+// platform.
+func grabScreen() (image.Image, error) {
+	path, err := tempFile()
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+	if err := grab(path); err != nil {
+		return nil, fmt.Errorf("capturing the screen: %w", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+// copyImage copies a PNG encoded image to the clipboard. Gio's
+// clipboard only holds text, so the tools of the platform are used.
+func copyImage(data []byte) error {
+	path, err := tempFile()
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	if err := copyPNG(path); err != nil {
+		return fmt.Errorf("copying the image: %w", err)
+	}
+	return nil
+}
+
+func tempFile() (string, error) {
+	f, err := ioutil.TempFile("", "annotate-*.png")
+	if err != nil {
+		return "", err
+	}
+	f.Close()
+	return f.Name(), nil
+}
+
+// run runs a command, with its error output in the error.
+func run(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %v: %s", cmd.Args[0], err, msg)
+		}
+		return fmt.Errorf("%s: %v", cmd.Args[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"os/exec"
+	"strings"
+)
+
+func grab(file string) error {
+	// -x keeps the camera sound quiet.
+	return run(exec.Command("screencapture", "-x", file))
+}
+
+func copyPNG(file string) error {
+	path := strings.ReplaceAll(file, `"`, `\"`)
+	return run(exec.Command("osascript", "-e",
+		`set the clipboard to (read (POSIX file "`+path+`") as «class PNGf»)`))
+}
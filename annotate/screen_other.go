@@ -0,0 +1,18 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build !darwin && !windows && !((linux && !android) || freebsd || openbsd)
+// +build !darwin
+// +build !windows
+// +build !linux android
+// +build !freebsd
+// +build !openbsd
+
+package main
+
+func grab(file string) error {
+	return errUnsupported
+}
+
+func copyPNG(file string) error {
+	return errUnsupported
+}
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build (linux && !android) || freebsd || openbsd
+// +build linux,!android freebsd openbsd
+
+package main
+
+import (
+	"os"
+	"os/exec"
+)
+
+// grabbers are the screenshot tools tried in order, with the file
+// appended to their arguments: those of wlroots compositors, GNOME and
+// KDE, then the X11 ones.
+var grabbers = [][]string{
+	{"grim"},
+	{"gnome-screenshot", "-f"},
+	{"spectacle", "-b", "-n", "-o"},
+	{"scrot", "-o"},
+	{"import", "-window", "root"},
+}
+
+// copiers are the clipboard tools tried in order, reading the image
+// from their standard input: the Wayland one, then the X11 one.
+var copiers = [][]string{
+	{"wl-copy", "--type", "image/png"},
+	{"xclip", "-selection", "clipboard", "-t", "image/png"},
+}
+
+func grab(file string) error {
+	// Tools fail outside their desktops, so try each one installed.
+	err := errUnsupported
+	for _, t := range grabbers {
+		path, lerr := exec.LookPath(t[0])
+		if lerr != nil {
+			continue
+		}
+		args := append(t[1:len(t):len(t)], file)
+		if err = run(exec.Command(path, args...)); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func copyPNG(file string) error {
+	err := errUnsupported
+	for _, t := range copiers {
+		path, lerr := exec.LookPath(t[0])
+		if lerr != nil {
+			continue
+		}
+		f, ferr := os.Open(file)
+		if ferr != nil {
+			return ferr
+		}
+		cmd := exec.Command(path, t[1:]...)
+		cmd.Stdin = f
+		err = run(cmd)
+		f.Close()
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// The screen is captured and the clipboard set by PowerShell scripts
+// with the System.Drawing and Windows Forms classes of .NET.
+const (
+	grabScript = `Add-Type -AssemblyName System.Windows.Forms, System.Drawing
+$b = [System.Windows.Forms.SystemInformation]::VirtualScreen
+$img = New-Object System.Drawing.Bitmap $b.Width, $b.Height
+$g = [System.Drawing.Graphics]::FromImage($img)
+$g.CopyFromScreen($b.Left, $b.Top, 0, 0, $img.Size)
+$img.Save('FILE', [System.Drawing.Imaging.ImageFormat]::Png)`
+
+	copyScript = `Add-Type -AssemblyName System.Windows.Forms, System.Drawing
+[System.Windows.Forms.Clipboard]::SetImage([System.Drawing.Image]::FromFile('FILE'))`
+)
+
+func grab(file string) error {
+	return powershell(grabScript, file)
+}
+
+func copyPNG(file string) error {
+	return powershell(copyScript, file)
+}
+
+func powershell(script, file string) error {
+	script = strings.ReplaceAll(script, "FILE", strings.ReplaceAll(file, "'", "''"))
+	// The clipboard needs a single threaded apartment.
+	return run(exec.Command("powershell", "-NoProfile", "-STA", "-Command", script))
+}
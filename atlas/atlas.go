@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/draw"
+	"sort"
+
+	"gioui.org/f32"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+)
+
+// padding separates the images of an atlas, so that scaled images don't
+// sample the pixels of their neighbours.
+const padding = 2
+
+// Atlas is a texture atlas: many small images packed into a single one.
+// The GPU keeps one texture for the atlas, and an image is drawn by
+// clipping the atlas to its area.
+type Atlas struct {
+	// Image is the packed image, and Rects the areas of the original
+	// images in it.
+	Image *image.RGBA
+	Rects []image.Rectangle
+
+	op paint.ImageOp
+}
+
+// Pack packs imgs into an atlas of the given width, or of the width of
+// the widest image. Images are placed on shelves from the tallest down,
+// which wastes little space for images of a few sizes, such as icons.
+func Pack(imgs []image.Image, width int) *Atlas {
+	order := make([]int, len(imgs))
+	for i, img := range imgs {
+		order[i] = i
+		if w := img.Bounds().Dx() + 2*padding; w > width {
+			width = w
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return imgs[order[i]].Bounds().Dy() > imgs[order[j]].Bounds().Dy()
+	})
+	rects := make([]image.Rectangle, len(imgs))
+	var x, y, shelf int
+	for _, i := range order {
+		sz := imgs[i].Bounds().Size()
+		if x > 0 && x+sz.X+padding > width {
+			// Start a new shelf below the tallest image of this one.
+			x, y, shelf = 0, y+shelf, 0
+		}
+		rects[i] = image.Rectangle{Min: image.Pt(x+padding, y+padding), Max: image.Pt(x+padding+sz.X, y+padding+sz.Y)}
+		x += sz.X + padding
+		if h := sz.Y + padding; h > shelf {
+			shelf = h
+		}
+	}
+	a := &Atlas{
+		Image: image.NewRGBA(image.Rect(0, 0, width, y+shelf+padding)),
+		Rects: rects,
+	}
+	for i, img := range imgs {
+		draw.Draw(a.Image, rects[i], img, img.Bounds().Min, draw.Src)
+	}
+	a.op = paint.NewImageOp(a.Image)
+	return a
+}
+
+// Draw draws image i of the atlas with its top left corner at the
+// current offset.
+func (a *Atlas) Draw(ops *op.Ops, i int) {
+	r := a.Rects[i]
+	defer op.Save(ops).Load()
+	clip.Rect{Max: r.Size()}.Add(ops)
+	op.Offset(f32.Pt(-float32(r.Min.X), -float32(r.Min.Y))).Add(ops)
+	a.op.Add(ops)
+	paint.PaintOp{}.Add(ops)
+}
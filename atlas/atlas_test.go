@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"testing"
+
+	"gioui.org/gpu/headless"
+	"gioui.org/layout"
+	"gioui.org/op"
+)
+
+func TestPack(t *testing.T) {
+	imgs := makeIcons(200)
+	a := Pack(imgs, 256)
+	if got := a.Image.Bounds().Dx(); got != 256 {
+		t.Errorf("got width %d, want 256", got)
+	}
+	for i, r := range a.Rects {
+		if r.Size() != imgs[i].Bounds().Size() {
+			t.Fatalf("icon %d: got size %v, want %v", i, r.Size(), imgs[i].Bounds().Size())
+		}
+		if !r.In(a.Image.Bounds()) {
+			t.Errorf("icon %d at %v outside the atlas %v", i, r, a.Image.Bounds())
+		}
+		// Neighbours keep their distance.
+		padded := r.Inset(-padding + 1)
+		for j, s := range a.Rects[:i] {
+			if padded.Overlaps(s) {
+				t.Errorf("icons %d at %v and %d at %v are too close", i, r, j, s)
+			}
+		}
+		// The pixels are copied.
+		b := imgs[i].Bounds()
+		for _, p := range []image.Point{{}, b.Size().Div(2), b.Size().Sub(image.Pt(1, 1))} {
+			want := imgs[i].At(b.Min.X+p.X, b.Min.Y+p.Y)
+			got := a.Image.At(r.Min.X+p.X, r.Min.Y+p.Y)
+			r1, g1, b1, a1 := want.RGBA()
+			r2, g2, b2, a2 := got.RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				t.Errorf("icon %d: got %v at %v, want %v", i, got, p, want)
+			}
+		}
+	}
+	// Shelves of icons of a few sizes waste little space.
+	area := 0
+	for _, img := range imgs {
+		sz := img.Bounds().Size()
+		area += (sz.X + padding) * (sz.Y + padding)
+	}
+	if total := a.Image.Bounds().Dx() * a.Image.Bounds().Dy(); area*10 < total*8 {
+		t.Errorf("icons cover %d of %d pixels, want at least 80%%", area, total)
+	}
+}
+
+func TestPackWide(t *testing.T) {
+	a := Pack([]image.Image{image.NewRGBA(image.Rect(0, 0, 100, 10))}, 50)
+	if r := a.Rects[0]; !r.In(a.Image.Bounds()) {
+		t.Errorf("image at %v outside the atlas %v", r, a.Image.Bounds())
+	}
+}
+
+func BenchmarkIcons(b *testing.B) {
+	b.Run("images", func(b *testing.B) { benchmarkIcons(b, false) })
+	b.Run("atlas", func(b *testing.B) { benchmarkIcons(b, true) })
+}
+
+// benchmarkIcons draws frames of icons on the GPU.
+func benchmarkIcons(b *testing.B, useAtlas bool) {
+	size := image.Pt(800, 600)
+	w, err := headless.NewWindow(size.X, size.Y)
+	if err != nil {
+		b.Skipf("no GPU: %v", err)
+	}
+	defer w.Release()
+	ic := newIcons(600)
+	var ops op.Ops
+	for i := 0; i < b.N; i++ {
+		ops.Reset()
+		gtx := layout.Context{Ops: &ops, Constraints: layout.Exact(size)}
+		ic.layout(gtx, useAtlas, float64(i)/60)
+		if err := w.Frame(&ops); err != nil {
+			b.Fatal(err)
+		}
+	}
+	// Wait for the GPU to finish the frames.
+	if _, err := w.Screenshot(); err != nil {
+		b.Fatal(err)
+	}
+}
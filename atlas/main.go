@@ -0,0 +1,236 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program draws hundreds of small icons, as toolbars, palettes and
+// map markers do, either as an image each or from a texture atlas: one
+// image with all the icons packed at startup, drawn by clipping it to the
+// area of an icon. Every image is a texture of its own on the GPU, so an
+// atlas saves the uploads and texture switches of many small images.
+// The toolbar switches between the two and shows the frame rate while
+// the icons move.
+//
+// Run the benchmarks to compare the two on a GPU:
+//
+//	go test -bench . ./atlas
+//
+// Usage:
+//
+//	go run ./atlas [-icons 600]
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"os"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/fakedata"
+	"gioui.org/example/internal/memstats"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var iconsFlag = flag.Int("icons", 600, "number of icons")
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Atlas"),
+			app.Size(unit.Dp(900), unit.Dp(700)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// iconSizes are the sizes of the icons in pixels.
+var iconSizes = []int{16, 24, 32, 48}
+
+// makeIcons returns n icons of a few sizes.
+func makeIcons(n int) []image.Image {
+	icons := make([]image.Image, n)
+	for i := range icons {
+		icons[i] = fakedata.Avatar(fmt.Sprintf("icon %d", i), iconSizes[i%len(iconSizes)])
+	}
+	return icons
+}
+
+// icons draws the same icons as separate images or from an atlas.
+type icons struct {
+	images []paint.ImageOp
+	sizes  []image.Point
+	atlas  *Atlas
+}
+
+func newIcons(n int) *icons {
+	imgs := makeIcons(n)
+	ic := &icons{atlas: Pack(imgs, 512)}
+	for _, img := range imgs {
+		ic.images = append(ic.images, paint.NewImageOp(img))
+		ic.sizes = append(ic.sizes, img.Bounds().Size())
+	}
+	return ic
+}
+
+// draw draws icon i at the current offset.
+func (ic *icons) draw(ops *op.Ops, i int, useAtlas bool) {
+	if useAtlas {
+		ic.atlas.Draw(ops, i)
+		return
+	}
+	ic.images[i].Add(ops)
+	paint.PaintOp{}.Add(ops)
+}
+
+// layout draws all the icons in rows across the width of gtx, moving
+// with time t.
+func (ic *icons) layout(gtx C, useAtlas bool, t float64) D {
+	const gap = 8
+	width := gtx.Constraints.Max.X
+	var x, y, row int
+	for i, sz := range ic.sizes {
+		if x > 0 && x+sz.X > width {
+			x, y, row = 0, y+row+gap, 0
+		}
+		// Let the icons bob, like markers on a map being panned.
+		dy := float32(4 * math.Sin(t*2+float64(i)*0.3))
+		stack := op.Save(gtx.Ops)
+		op.Offset(f32.Pt(float32(x), float32(y)+dy)).Add(gtx.Ops)
+		ic.draw(gtx.Ops, i, useAtlas)
+		stack.Load()
+		x += sz.X + gap
+		if sz.Y > row {
+			row = sz.Y
+		}
+	}
+	return D{Size: image.Pt(width, y+row)}
+}
+
+type App struct {
+	icons *icons
+
+	useAtlas, animate, showAtlas widget.Bool
+
+	last     time.Time
+	interval time.Duration
+	start    time.Time
+	// images are the images of the last frame.
+	images memstats.ImageUsage
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{icons: newIcons(*iconsFlag)}
+	a.useAtlas.Value = true
+	a.animate.Value = true
+	var ops op.Ops
+	for e := range w.Events() {
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			a.Layout(gtx, th)
+			a.images = memstats.Images(gtx.Ops)
+			e.Frame(gtx.Ops)
+		}
+	}
+	return nil
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	if a.start.IsZero() {
+		a.start = gtx.Now
+	}
+	if a.animate.Value {
+		if !a.last.IsZero() {
+			// Smooth the frame interval over the last frames.
+			d := gtx.Now.Sub(a.last)
+			a.interval += (d - a.interval) / 8
+		}
+		a.last = gtx.Now
+		op.InvalidateOp{}.Add(gtx.Ops)
+	} else {
+		a.last, a.interval = time.Time{}, 0
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, a.layoutToolbar(th))
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			defer op.Save(gtx.Ops).Load()
+			clip.Rect{Max: gtx.Constraints.Max}.Add(gtx.Ops)
+			if a.showAtlas.Value {
+				return a.layoutAtlas(gtx)
+			}
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				t := gtx.Now.Sub(a.start).Seconds()
+				return a.icons.layout(gtx, a.useAtlas.Value, t)
+			})
+		}),
+	)
+}
+
+var (
+	checker = color.NRGBA{R: 0xee, G: 0xee, B: 0xee, A: 0xff}
+	outline = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0x80}
+)
+
+// layoutAtlas draws the atlas image with the areas of the icons.
+func (a *App) layoutAtlas(gtx C) D {
+	at := a.icons.atlas
+	size := at.Image.Bounds().Size()
+	paint.FillShape(gtx.Ops, checker, clip.Rect{Max: size}.Op())
+	at.op.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+	for _, r := range at.Rects {
+		fr := f32.Rect(float32(r.Min.X), float32(r.Min.Y), float32(r.Max.X), float32(r.Max.Y))
+		paint.FillShape(gtx.Ops, outline, clip.Stroke{
+			Path:  clip.UniformRRect(fr, 0).Path(gtx.Ops),
+			Style: clip.StrokeStyle{Width: 1},
+		}.Op())
+	}
+	return D{Size: size}
+}
+
+func (a *App) layoutToolbar(th *material.Theme) layout.Widget {
+	return func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(material.CheckBox(th, &a.useAtlas, "Use atlas").Layout),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+			layout.Rigid(material.CheckBox(th, &a.animate, "Animate").Layout),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+			layout.Rigid(material.CheckBox(th, &a.showAtlas, "Show atlas").Layout),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+			layout.Flexed(1, func(gtx C) D {
+				s := fmt.Sprintf("%d icons, %d textures of %d KiB", len(a.icons.sizes), a.images.Count, a.images.Bytes/1024)
+				if a.interval > 0 {
+					s += fmt.Sprintf(", %.0f fps", float64(time.Second)/float64(a.interval))
+				}
+				return material.Body2(th, s).Layout(gtx)
+			}),
+		)
+	}
+}
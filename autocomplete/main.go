@@ -0,0 +1,243 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates typeahead completion with internal/complete.
+// The search field completes country names from a source with simulated
+// network latency, and the code editor completes the Go identifier at the
+// caret. Use the arrow keys to select a suggestion and Tab or Enter to
+// accept it.
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/complete"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Autocomplete"),
+			app.Size(unit.Dp(640), unit.Dp(640)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var countries = []complete.Suggestion{
+	{Text: "Argentina", Detail: "Buenos Aires"},
+	{Text: "Australia", Detail: "Canberra"},
+	{Text: "Austria", Detail: "Vienna"},
+	{Text: "Belgium", Detail: "Brussels"},
+	{Text: "Brazil", Detail: "Brasília"},
+	{Text: "Canada", Detail: "Ottawa"},
+	{Text: "Chile", Detail: "Santiago"},
+	{Text: "China", Detail: "Beijing"},
+	{Text: "Colombia", Detail: "Bogotá"},
+	{Text: "Denmark", Detail: "Copenhagen"},
+	{Text: "Egypt", Detail: "Cairo"},
+	{Text: "Finland", Detail: "Helsinki"},
+	{Text: "France", Detail: "Paris"},
+	{Text: "Germany", Detail: "Berlin"},
+	{Text: "Greece", Detail: "Athens"},
+	{Text: "Iceland", Detail: "Reykjavík"},
+	{Text: "India", Detail: "New Delhi"},
+	{Text: "Indonesia", Detail: "Jakarta"},
+	{Text: "Ireland", Detail: "Dublin"},
+	{Text: "Italy", Detail: "Rome"},
+	{Text: "Japan", Detail: "Tokyo"},
+	{Text: "Kenya", Detail: "Nairobi"},
+	{Text: "Mexico", Detail: "Mexico City"},
+	{Text: "Netherlands", Detail: "Amsterdam"},
+	{Text: "New Zealand", Detail: "Wellington"},
+	{Text: "Nigeria", Detail: "Abuja"},
+	{Text: "Norway", Detail: "Oslo"},
+	{Text: "Peru", Detail: "Lima"},
+	{Text: "Poland", Detail: "Warsaw"},
+	{Text: "Portugal", Detail: "Lisbon"},
+	{Text: "South Africa", Detail: "Pretoria"},
+	{Text: "South Korea", Detail: "Seoul"},
+	{Text: "Spain", Detail: "Madrid"},
+	{Text: "Sweden", Detail: "Stockholm"},
+	{Text: "Switzerland", Detail: "Bern"},
+	{Text: "United Kingdom", Detail: "London"},
+	{Text: "United States", Detail: "Washington"},
+	{Text: "Vietnam", Detail: "Hanoi"},
+}
+
+// searchCountries matches the query anywhere in the country names,
+// pretending to be a slow server.
+func searchCountries(ctx context.Context, query string) ([]complete.Suggestion, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(300 * time.Millisecond):
+	}
+	q := strings.ToLower(query)
+	var res []complete.Suggestion
+	for _, c := range countries {
+		if strings.Contains(strings.ToLower(c.Text), q) {
+			res = append(res, c)
+		}
+	}
+	return res, nil
+}
+
+var symbols = map[string][]string{
+	"keyword": {"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func", "go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select", "struct", "switch", "type", "var"},
+	"func":    {"append", "cap", "close", "copy", "delete", "len", "make", "new", "panic", "print", "println", "recover"},
+	"type":    {"bool", "byte", "complex128", "error", "float32", "float64", "int", "int64", "rune", "string", "uint", "uint8"},
+	"package": {"context", "errors", "fmt", "image", "io", "log", "os", "sort", "strings", "time"},
+}
+
+// searchSymbols suggests the Go symbols with the query as prefix.
+func searchSymbols(ctx context.Context, query string) ([]complete.Suggestion, error) {
+	var res []complete.Suggestion
+	for kind, names := range symbols {
+		matches, _ := complete.Static(names...)(ctx, query)
+		for _, m := range matches {
+			res = append(res, complete.Suggestion{Text: m.Text, Detail: kind})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Text < res[j].Text
+	})
+	return res, nil
+}
+
+const sampleCode = "package main\n\nfunc main() {\n\tfm\n}\n"
+
+func newSearchCompleter(search *widget.Editor, invalidate func()) *complete.Completer {
+	return &complete.Completer{
+		Editor:     search,
+		Source:     searchCountries,
+		Delay:      100 * time.Millisecond,
+		Invalidate: invalidate,
+	}
+}
+
+func newCodeCompleter(code *widget.Editor, invalidate func()) *complete.Completer {
+	return &complete.Completer{
+		Editor:     code,
+		Source:     searchSymbols,
+		Token:      complete.Identifier,
+		MinLength:  2,
+		Invalidate: invalidate,
+	}
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	var (
+		ops      op.Ops
+		search   = &widget.Editor{SingleLine: true, Submit: true}
+		code     = new(widget.Editor)
+		selected string
+	)
+	code.SetText(sampleCode)
+	searchCompl := newSearchCompleter(search, w.Invalidate)
+	codeCompl := newCodeCompleter(code, w.Invalidate)
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			for _, e := range search.Events() {
+				if e, ok := e.(widget.SubmitEvent); ok {
+					selected = e.Text
+				}
+			}
+			for {
+				s, ok := searchCompl.Accepted()
+				if !ok {
+					break
+				}
+				selected = fmt.Sprintf("%s (capital: %s)", s.Text, s.Detail)
+			}
+			headerHeight := unit.Dp(140)
+			layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+				// The search section is laid out after the code editor
+				// below it, so that its popup covers the editor.
+				return layout.Stack{}.Layout(gtx,
+					layout.Expanded(func(gtx C) D {
+						return layout.Inset{Top: headerHeight}.Layout(gtx, func(gtx C) D {
+							return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+								layout.Rigid(material.Body1(th, "Code").Layout),
+								layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+								layout.Flexed(1, func(gtx C) D {
+									return field(gtx, func(gtx C) D {
+										gtx.Constraints.Min = gtx.Constraints.Max
+										return codeCompl.Layout(gtx, th, func(gtx C) D {
+											e := material.Editor(th, code, "")
+											e.Font.Variant = "Mono"
+											return e.Layout(gtx)
+										})
+									})
+								}),
+							)
+						})
+					}),
+					layout.Stacked(func(gtx C) D {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(material.Body1(th, "Country").Layout),
+							layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+							layout.Rigid(func(gtx C) D {
+								return field(gtx, func(gtx C) D {
+									return searchCompl.Layout(gtx, th, material.Editor(th, search, "Search countries").Layout)
+								})
+							}),
+							layout.Rigid(func(gtx C) D {
+								status := selected
+								if searchCompl.Loading() {
+									status = "Searching…"
+								}
+								l := material.Caption(th, status)
+								l.Color = color.NRGBA{A: 0xa0}
+								return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, l.Layout)
+							}),
+						)
+					}),
+				)
+			})
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+// field draws a border around an editor.
+func field(gtx C, w layout.Widget) D {
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	return widget.Border{
+		Color:        color.NRGBA{A: 0x40},
+		CornerRadius: unit.Dp(4),
+		Width:        unit.Px(1),
+	}.Layout(gtx, func(gtx C) D {
+		return layout.UniformInset(unit.Dp(8)).Layout(gtx, w)
+	})
+}
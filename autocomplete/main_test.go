@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"testing"
+
+	"gioui.org/example/internal/edittest"
+	"gioui.org/font/gofont"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+func TestCodeEditorFuzz(t *testing.T) {
+	th := material.NewTheme(gofont.Collection())
+	edittest.Run(t, func(seed int64) *edittest.Harness {
+		code := new(widget.Editor)
+		code.SetText(sampleCode)
+		compl := newCodeCompleter(code, nil)
+		// The completer takes the focus and the editing keys while its
+		// popup is open, so the text can't be predicted.
+		return &edittest.Harness{
+			Editor: code,
+			Layout: func(gtx C) {
+				compl.Layout(gtx, th, material.Editor(th, code, "").Layout)
+			},
+		}
+	})
+}
+
+func TestSearchEditorFuzz(t *testing.T) {
+	th := material.NewTheme(gofont.Collection())
+	edittest.Run(t, func(seed int64) *edittest.Harness {
+		search := &widget.Editor{SingleLine: true, Submit: true}
+		compl := newSearchCompleter(search, nil)
+		return &edittest.Harness{
+			Editor: search,
+			Layout: func(gtx C) {
+				compl.Layout(gtx, th, material.Editor(th, search, "").Layout)
+				for {
+					if _, ok := compl.Accepted(); !ok {
+						break
+					}
+				}
+			},
+		}
+	})
+}
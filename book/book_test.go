@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gioui.org/font/gofont"
+	"gioui.org/text"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestRead(t *testing.T) {
+	b, err := Read(strings.NewReader("CHAPTER I\n\nIt was a\ndark night.\n\n\nThe end.\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Para{
+		{Text: "CHAPTER I", Heading: true},
+		{Text: "It was a dark night."},
+		{Text: "The end."},
+	}
+	if !reflect.DeepEqual(b.Paras, want) {
+		t.Errorf("got %+v, want %+v", b.Paras, want)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	b := Generate(1, 100000)
+	if b.Size < 100000 {
+		t.Errorf("got %d bytes, want 100000", b.Size)
+	}
+	if !b.Paras[0].Heading || !b.Paras[chapterLen+1].Heading {
+		t.Errorf("chapters don't start with headings")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	b := &Book{Paras: []Para{
+		{Text: "Gio draws with the GPU."},
+		{Text: "Nothing here."},
+		{Text: "gio, gio and GIO."},
+	}}
+	got := Search(context.Background(), b, "gio")
+	want := []Match{{0, 0, 3}, {2, 0, 3}, {2, 5, 8}, {2, 13, 16}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got := Search(ctx, b, "gio"); len(got) != 0 {
+		t.Errorf("cancelled search found %v", got)
+	}
+}
+
+func TestTextRects(t *testing.T) {
+	sh := text.NewCache(gofont.Collection())
+	const str = "The quick brown fox jumps over the lazy dog"
+	lines := sh.LayoutString(text.Font{}, fixed.I(16), 120, str)
+	if len(lines) < 2 {
+		t.Fatalf("got %d lines, want several", len(lines))
+	}
+	// A match within the first line.
+	r := textRects(lines, 4, 9)
+	if len(r) != 1 || r[0].Min.X <= 0 || r[0].Dx() <= 0 || r[0].Min.Y > 0 {
+		t.Errorf("got %v for \"quick\", want one area in the first line", r)
+	}
+	// A match across lines has an area in each.
+	start := strings.Index(str, "fox")
+	r = textRects(lines, start, len(str))
+	if len(r) < 2 {
+		t.Fatalf("got %v for the end, want several lines", r)
+	}
+	for i := 1; i < len(r); i++ {
+		if r[i].Min.Y < r[i-1].Max.Y-1 {
+			t.Errorf("line %d at %v overlaps the line before at %v", i, r[i], r[i-1])
+		}
+		if r[i].Min.X != 0 {
+			t.Errorf("line %d at %v doesn't start at the left edge", i, r[i])
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"container/list"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+)
+
+// layoutKey identifies the layout of a paragraph: its index and the
+// width and text size it was laid out for.
+type layoutKey struct {
+	para  int
+	width int
+	size  float32
+}
+
+// cachedLayout is the recorded layout of a paragraph. Its operations are
+// kept in their own list, to be called from the frames that show the
+// paragraph.
+type cachedLayout struct {
+	key  layoutKey
+	ops  op.Ops
+	call op.CallOp
+	dims layout.Dimensions
+}
+
+// layoutCache keeps the layouts of the most recently shown paragraphs,
+// so scrolling back and forth and changing the text size back don't
+// shape text again. Layouts can't be cached if they handle input.
+type layoutCache struct {
+	max     int
+	entries map[layoutKey]*list.Element
+	lru     list.List
+
+	// hits and misses count the lookups since the last call to stats.
+	hits, misses int
+}
+
+func newLayoutCache(max int) *layoutCache {
+	return &layoutCache{max: max, entries: make(map[layoutKey]*list.Element)}
+}
+
+// Layout draws the layout of key from the cache, or records it with w.
+func (c *layoutCache) Layout(gtx layout.Context, key layoutKey, w layout.Widget) layout.Dimensions {
+	if e, ok := c.entries[key]; ok {
+		c.hits++
+		c.lru.MoveToFront(e)
+		l := e.Value.(*cachedLayout)
+		l.call.Add(gtx.Ops)
+		return l.dims
+	}
+	c.misses++
+	l := &cachedLayout{key: key}
+	rgtx := gtx
+	rgtx.Ops = &l.ops
+	m := op.Record(rgtx.Ops)
+	l.dims = w(rgtx)
+	l.call = m.Stop()
+	l.call.Add(gtx.Ops)
+	c.entries[key] = c.lru.PushFront(l)
+	for c.lru.Len() > c.max {
+		// Drop the operations of evicted layouts without reusing them,
+		// as the current frame may still call them.
+		old := c.lru.Remove(c.lru.Back()).(*cachedLayout)
+		delete(c.entries, old.key)
+	}
+	return l.dims
+}
+
+// Len returns the number of cached layouts.
+func (c *layoutCache) Len() int {
+	return c.lru.Len()
+}
+
+// stats returns and resets the hit and miss counts.
+func (c *layoutCache) stats() (hits, misses int) {
+	hits, misses = c.hits, c.misses
+	c.hits, c.misses = 0, 0
+	return hits, misses
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"testing"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+)
+
+func TestLayoutCache(t *testing.T) {
+	c := newLayoutCache(2)
+	laidOut := 0
+	w := func(gtx layout.Context) layout.Dimensions {
+		laidOut++
+		return layout.Dimensions{Size: image.Pt(10, 20)}
+	}
+	var ops op.Ops
+	gtx := layout.Context{Ops: &ops}
+	key := func(para int) layoutKey {
+		return layoutKey{para: para, width: 100, size: 16}
+	}
+	for _, para := range []int{0, 1, 0, 1} {
+		if dims := c.Layout(gtx, key(para), w); dims.Size != image.Pt(10, 20) {
+			t.Errorf("got size %v, want (10,20)", dims.Size)
+		}
+	}
+	if hits, misses := c.stats(); hits != 2 || misses != 2 || laidOut != 2 {
+		t.Errorf("got %d hits, %d misses, %d layouts; want 2, 2, 2", hits, misses, laidOut)
+	}
+	// Another text size is another layout, and evicts the least
+	// recently used one.
+	big := key(1)
+	big.size = 20
+	c.Layout(gtx, big, w)
+	c.Layout(gtx, key(1), w)
+	c.Layout(gtx, key(0), w)
+	if hits, misses := c.stats(); hits != 1 || misses != 2 {
+		t.Errorf("got %d hits, %d misses, want 1, 2", hits, misses)
+	}
+	if c.Len() != 2 {
+		t.Errorf("got %d layouts, want 2", c.Len())
+	}
+}
@@ -0,0 +1,373 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program reads a large book, 5 MB of text by default, as a
+// reference for reader style applications. Only the paragraphs on screen
+// are laid out, and their layouts are cached by paragraph, width and
+// text size: scrolling back, and changing the text size back and forth,
+// draws recorded operations instead of shaping text again. Search runs
+// in the background over the whole book and highlights the matches.
+//
+// Usage:
+//
+//	go run ./book [-file book.txt] [-size 5]
+//
+// Without -file, a book of -size megabytes is generated.
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/scrollbar"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"golang.org/x/image/math/fixed"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	fileFlag = flag.String("file", "", "plain text book to read")
+	sizeFlag = flag.Float64("size", 5, "size in megabytes of the generated book")
+)
+
+func main() {
+	flag.Parse()
+	var b *Book
+	if *fileFlag != "" {
+		f, err := os.Open(*fileFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		b, err = Read(f)
+		f.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		b = Generate(1, int(*sizeFlag*(1<<20)))
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("Book"),
+			app.Size(unit.Dp(800), unit.Dp(900)),
+		)
+		if err := loop(w, b); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// cacheSize is the number of paragraph layouts kept, a few screens full.
+const cacheSize = 500
+
+// Text sizes in sp.
+const (
+	minTextSize     = 10
+	maxTextSize     = 32
+	defaultTextSize = 16
+)
+
+var (
+	highlight = color.NRGBA{R: 0xff, G: 0xeb, B: 0x3b, A: 0xa0}
+	current   = color.NRGBA{R: 0xff, G: 0x98, B: 0x00, A: 0xc0}
+)
+
+// searchResult is the result of the search for query.
+type searchResult struct {
+	query   string
+	matches []Match
+	elapsed time.Duration
+}
+
+type App struct {
+	book  *Book
+	cache *layoutCache
+	list  layout.List
+	bar   scrollbar.Scrollbar
+	// visible is the number of paragraphs laid out in the last frame.
+	visible  int
+	textSize float32
+
+	smaller, larger widget.Clickable
+	search          widget.Editor
+	prev, next      widget.Clickable
+
+	query   string
+	cancel  context.CancelFunc
+	results chan searchResult
+	result  searchResult
+	// matches are the matches by paragraph, and match the current
+	// one.
+	matches map[int][]int
+	match   int
+
+	// hits and misses are the cache lookups of the last frame.
+	hits, misses int
+}
+
+func loop(w *app.Window, b *Book) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		book:     b,
+		cache:    newLayoutCache(cacheSize),
+		list:     layout.List{Axis: layout.Vertical},
+		textSize: defaultTextSize,
+		results:  make(chan searchResult, 1),
+	}
+	a.search.SingleLine = true
+	a.search.Submit = true
+	var ops op.Ops
+	for {
+		select {
+		case r := <-a.results:
+			a.setResult(r)
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+// startSearch searches the book in the background, cancelling the
+// search before.
+func (a *App) startSearch(query string) {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	a.query = query
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	go func() {
+		start := time.Now()
+		m := Search(ctx, a.book, query)
+		if ctx.Err() != nil {
+			return
+		}
+		a.results <- searchResult{query: query, matches: m, elapsed: time.Since(start)}
+	}()
+}
+
+func (a *App) setResult(r searchResult) {
+	if r.query != a.query {
+		return
+	}
+	a.result = r
+	a.matches = make(map[int][]int)
+	for i, m := range r.matches {
+		a.matches[m.Para] = append(a.matches[m.Para], i)
+	}
+	// Start at the first match on or after the top of the screen.
+	a.match = 0
+	for i, m := range r.matches {
+		if m.Para >= a.list.Position.First {
+			a.match = i
+			break
+		}
+	}
+	a.showMatch()
+}
+
+// showMatch scrolls to the current match.
+func (a *App) showMatch() {
+	if len(a.result.matches) == 0 {
+		return
+	}
+	a.list.Position.First = a.result.matches[a.match].Para
+	a.list.Position.Offset = 0
+}
+
+func (a *App) update(gtx C) {
+	for _, e := range a.search.Events() {
+		switch e.(type) {
+		case widget.ChangeEvent:
+			a.startSearch(a.search.Text())
+		case widget.SubmitEvent:
+			a.step(1)
+		}
+	}
+	for a.next.Clicked() {
+		a.step(1)
+	}
+	for a.prev.Clicked() {
+		a.step(-1)
+	}
+	for a.smaller.Clicked() {
+		if a.textSize > minTextSize {
+			a.textSize -= 2
+		}
+	}
+	for a.larger.Clicked() {
+		if a.textSize < maxTextSize {
+			a.textSize += 2
+		}
+	}
+	if d := a.bar.Scrolled(); d != 0 {
+		n := len(a.book.Paras)
+		first := int((float32(a.list.Position.First)/float32(n) + d) * float32(n))
+		if max := n - a.visible; first > max {
+			first = max
+		}
+		if first < 0 {
+			first = 0
+		}
+		a.list.Position.First = first
+		a.list.Position.Offset = 0
+	}
+}
+
+// step moves to the next or previous match.
+func (a *App) step(dir int) {
+	n := len(a.result.matches)
+	if n == 0 {
+		return
+	}
+	a.match = (a.match + dir + n) % n
+	a.showMatch()
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	a.update(gtx)
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, a.layoutToolbar(th))
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Flexed(1, func(gtx C) D {
+					return a.layoutBook(gtx, th)
+				}),
+				layout.Rigid(func(gtx C) D {
+					n := float32(len(a.book.Paras))
+					start := float32(a.list.Position.First) / n
+					end := float32(a.list.Position.First+a.visible) / n
+					return scrollbar.New(&a.bar).Layout(gtx, start, end)
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, a.layoutStatus(th))
+		}),
+	)
+}
+
+func (a *App) layoutToolbar(th *material.Theme) layout.Widget {
+	return func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx C) D {
+				return widget.Border{
+					Color:        th.Palette.ContrastBg,
+					CornerRadius: unit.Dp(4),
+					Width:        unit.Px(1),
+				}.Layout(gtx, func(gtx C) D {
+					return layout.UniformInset(unit.Dp(6)).Layout(gtx, material.Editor(th, &a.search, "Search").Layout)
+				})
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+			layout.Rigid(func(gtx C) D {
+				l := "No matches"
+				switch n := len(a.result.matches); {
+				case a.query == "":
+					l = ""
+				case a.result.query != a.query:
+					l = "Searching…"
+				case n > 0:
+					l = fmt.Sprintf("%d of %d", a.match+1, n)
+				}
+				return material.Body2(th, l).Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+			layout.Rigid(material.Button(th, &a.prev, "Prev").Layout),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+			layout.Rigid(material.Button(th, &a.next, "Next").Layout),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+			layout.Rigid(material.Button(th, &a.smaller, "A−").Layout),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+			layout.Rigid(material.Button(th, &a.larger, "A+").Layout),
+		)
+	}
+}
+
+func (a *App) layoutStatus(th *material.Theme) layout.Widget {
+	return func(gtx C) D {
+		s := fmt.Sprintf("%d paragraphs, %.1f MB. Frame: %d paragraphs, %d cached, %d shaped. Cache: %d layouts.",
+			len(a.book.Paras), float64(a.book.Size)/(1<<20), a.visible, a.hits, a.misses, a.cache.Len())
+		if a.result.query != "" && a.result.query == a.query {
+			s += fmt.Sprintf(" Search: %s.", a.result.elapsed.Round(time.Millisecond))
+		}
+		return material.Caption(th, s).Layout(gtx)
+	}
+}
+
+func (a *App) layoutBook(gtx C, th *material.Theme) D {
+	visible := 0
+	dims := layout.Inset{Left: unit.Dp(24), Right: unit.Dp(16)}.Layout(gtx, func(gtx C) D {
+		return a.list.Layout(gtx, len(a.book.Paras), func(gtx C, i int) D {
+			visible++
+			return layout.Inset{Bottom: unit.Dp(12)}.Layout(gtx, func(gtx C) D {
+				return a.layoutPara(gtx, th, i)
+			})
+		})
+	})
+	a.visible = visible
+	a.hits, a.misses = a.cache.stats()
+	return dims
+}
+
+// layoutPara draws paragraph i from the cache, over the highlights of
+// its matches.
+func (a *App) layoutPara(gtx C, th *material.Theme, i int) D {
+	p := a.book.Paras[i]
+	l := material.Body1(th, p.Text)
+	size := a.textSize
+	if p.Heading {
+		l = material.H5(th, p.Text)
+		size *= 1.5
+	}
+	l.TextSize = unit.Sp(size)
+	if ms := a.matches[i]; len(ms) > 0 {
+		lines := th.Shaper.LayoutString(l.Font, fixed.I(gtx.Px(l.TextSize)), gtx.Constraints.Max.X, p.Text)
+		for _, mi := range ms {
+			m := a.result.matches[mi]
+			col := highlight
+			if mi == a.match {
+				col = current
+			}
+			for _, r := range textRects(lines, m.Start, m.End) {
+				paint.FillShape(gtx.Ops, col, clip.Rect(r).Op())
+			}
+		}
+	}
+	key := layoutKey{para: i, width: gtx.Constraints.Max.X, size: float32(gtx.Px(l.TextSize))}
+	return a.cache.Layout(gtx, key, func(gtx C) D {
+		gtx.Constraints.Min = image.Point{}
+		return l.Layout(gtx)
+	})
+}
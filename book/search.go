@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"image"
+	"strings"
+
+	"gioui.org/text"
+	"golang.org/x/image/math/fixed"
+)
+
+// Match is an occurrence of a search in a paragraph, from byte Start to
+// End of its text.
+type Match struct {
+	Para       int
+	Start, End int
+}
+
+// maxMatches limits the matches of a search.
+const maxMatches = 10000
+
+// Search finds the occurrences of query in the book, ignoring case. It
+// stops early with the matches so far if ctx is done.
+func Search(ctx context.Context, b *Book, query string) []Match {
+	if query == "" {
+		return nil
+	}
+	lq := strings.ToLower(query)
+	var matches []Match
+	for i, p := range b.Paras {
+		if i%1000 == 0 && ctx.Err() != nil {
+			break
+		}
+		t, q := strings.ToLower(p.Text), lq
+		if len(t) != len(p.Text) {
+			// Lower case changed the offsets; match the case exactly.
+			t, q = p.Text, query
+		}
+		for off := 0; ; {
+			j := strings.Index(t[off:], q)
+			if j == -1 {
+				break
+			}
+			start := off + j
+			matches = append(matches, Match{Para: i, Start: start, End: start + len(q)})
+			if len(matches) == maxMatches {
+				return matches
+			}
+			off = start + len(q)
+		}
+	}
+	return matches
+}
+
+// textRects returns the areas of the bytes start to end of the text of
+// lines, as drawn by widget.Label aligned at the start.
+func textRects(lines []text.Line, start, end int) []image.Rectangle {
+	var rects []image.Rectangle
+	var prevDesc fixed.Int26_6
+	y, off := 0, 0
+	for _, l := range lines {
+		// The baseline of the line, rounded like widget.Label.
+		y += (prevDesc + l.Ascent).Ceil()
+		prevDesc = l.Descent
+		lineEnd := off + len(l.Layout.Text)
+		if lineEnd <= start || off >= end {
+			off = lineEnd
+			continue
+		}
+		x, x0 := fixed.Int26_6(0), fixed.Int26_6(-1)
+		i := 0
+		for n := range l.Layout.Text {
+			pos := off + n
+			if x0 < 0 && pos >= start {
+				x0 = x
+			}
+			if pos >= end {
+				break
+			}
+			x += l.Layout.Advances[i]
+			i++
+		}
+		if x0 < 0 {
+			x0 = x
+		}
+		rects = append(rects, image.Rect(x0.Floor(), y-l.Ascent.Ceil(), x.Ceil(), y+l.Descent.Ceil()))
+		off = lineEnd
+	}
+	return rects
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"gioui.org/example/internal/fakedata"
+)
+
+// Book is a document of paragraphs.
+type Book struct {
+	Paras []Para
+	// Size is the length of the text in bytes.
+	Size int
+}
+
+// Para is a paragraph, or the heading of a chapter.
+type Para struct {
+	Text    string
+	Heading bool
+}
+
+// chapterLen is the number of paragraphs of the chapters of generated
+// books.
+const chapterLen = 40
+
+// Generate generates a book of at least size bytes from seed.
+func Generate(seed int64, size int) *Book {
+	f := fakedata.New(seed)
+	b := new(Book)
+	for i := 0; b.Size < size; i++ {
+		if i%chapterLen == 0 {
+			b.add(Para{Text: fmt.Sprintf("Chapter %d", i/chapterLen+1), Heading: true})
+		}
+		b.add(Para{Text: f.Paragraph(3 + f.Intn(6))})
+	}
+	return b
+}
+
+// Read reads a plain text book, such as from Project Gutenberg: blank
+// lines separate paragraphs, and the lines of a paragraph are joined.
+// Short paragraphs in upper case, or starting with "Chapter", are
+// headings.
+func Read(r io.Reader) (*Book, error) {
+	b := new(Book)
+	var lines []string
+	flush := func() {
+		if len(lines) == 0 {
+			return
+		}
+		text := strings.Join(lines, " ")
+		lines = lines[:0]
+		b.add(Para{Text: text, Heading: isHeading(text)})
+	}
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			flush()
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	return b, s.Err()
+}
+
+func isHeading(text string) bool {
+	if len(text) > 60 {
+		return false
+	}
+	return strings.HasPrefix(strings.ToLower(text), "chapter ") || strings.ToUpper(text) == text && strings.ToLower(text) != text
+}
+
+func (b *Book) add(p Para) {
+	b.Paras = append(b.Paras, p)
+	b.Size += len(p.Text)
+}
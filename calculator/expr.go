@@ -0,0 +1,347 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SyntaxError is an error in an expression at byte offset Pos.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos+1)
+}
+
+// functions are the functions of one argument, by name.
+var functions = map[string]func(float64) float64{
+	"sqrt": math.Sqrt,
+	"abs":  math.Abs,
+	"sin":  math.Sin,
+	"cos":  math.Cos,
+	"tan":  math.Tan,
+	"asin": math.Asin,
+	"acos": math.Acos,
+	"atan": math.Atan,
+	"ln":   math.Log,
+	"log":  math.Log10,
+	"exp":  math.Exp,
+}
+
+// constants are the predefined variables.
+var constants = map[string]float64{
+	"pi": math.Pi,
+	"π":  math.Pi,
+	"e":  math.E,
+}
+
+// Eval evaluates an arithmetic expression. It supports numbers, the
+// operators + - * / % ^, parentheses, the functions above and variables,
+// and multiplication by juxtaposition as in 2pi or 3(4+5). The operators
+// × ÷ − and √ of the calculator keys are accepted as well.
+func Eval(expr string, vars map[string]float64) (float64, error) {
+	p := &parser{src: expr, vars: vars}
+	p.next()
+	v, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+	if p.tok != tokEOF {
+		return 0, p.errorf("unexpected %s", p.tok)
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("result is undefined")
+	}
+	return v, nil
+}
+
+type token int
+
+const (
+	tokEOF token = iota
+	tokNum
+	tokIdent
+	tokOp
+	tokBadNum
+	tokInvalid
+)
+
+func (t token) String() string {
+	switch t {
+	case tokEOF:
+		return "end of expression"
+	case tokNum:
+		return "number"
+	case tokIdent:
+		return "name"
+	case tokOp:
+		return "operator"
+	case tokBadNum:
+		return "malformed number"
+	default:
+		return "character"
+	}
+}
+
+type parser struct {
+	src  string
+	vars map[string]float64
+
+	// tok is the current token, starting at pos, with the text lit and
+	// the value num for numbers.
+	tok token
+	pos int
+	lit string
+	num float64
+	// off is the offset after the current token.
+	off int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// next scans the next token.
+func (p *parser) next() {
+	s := p.src
+	i := p.off
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	p.pos = i
+	if i == len(s) {
+		p.tok, p.lit, p.off = tokEOF, "", i
+		return
+	}
+	start := i
+	r, n := rune(s[i]), 1
+	if r >= 0x80 {
+		r, n = decodeRune(s[i:])
+	}
+	switch {
+	case r >= '0' && r <= '9' || r == '.':
+		for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+			i++
+		}
+		// An exponent, as in 1.5e-3.
+		if i+1 < len(s) && (s[i] == 'e' || s[i] == 'E') {
+			j := i + 1
+			if s[j] == '+' || s[j] == '-' {
+				j++
+			}
+			if j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				for i = j; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+				}
+			}
+		}
+		p.tok, p.lit = tokNum, s[start:i]
+		v, err := strconv.ParseFloat(p.lit, 64)
+		if err != nil {
+			p.tok = tokBadNum
+		}
+		p.num = v
+	case unicode.IsLetter(r):
+		for i < len(s) {
+			r, n := decodeRune(s[i:])
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				break
+			}
+			i += n
+		}
+		p.tok, p.lit = tokIdent, strings.ToLower(s[start:i])
+	default:
+		i += n
+		p.tok, p.lit = tokOp, normalizeOp(s[start:i])
+		if !strings.Contains("+-*/%^()√", p.lit) {
+			p.tok = tokInvalid
+		}
+	}
+	p.off = i
+}
+
+func decodeRune(s string) (rune, int) {
+	for _, r := range s {
+		return r, len(string(r))
+	}
+	return 0, 0
+}
+
+// normalizeOp maps the symbols of the calculator keys to operators.
+func normalizeOp(op string) string {
+	switch op {
+	case "×", "·":
+		return "*"
+	case "÷":
+		return "/"
+	case "−":
+		return "-"
+	}
+	return op
+}
+
+func (p *parser) isOp(op string) bool {
+	return p.tok == tokOp && p.lit == op
+}
+
+// expr parses sums.
+func (p *parser) expr() (float64, error) {
+	v, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+	for p.isOp("+") || p.isOp("-") {
+		op := p.lit
+		p.next()
+		w, err := p.term()
+		if err != nil {
+			return 0, err
+		}
+		if op == "+" {
+			v += w
+		} else {
+			v -= w
+		}
+	}
+	return v, nil
+}
+
+// term parses products, including juxtaposition.
+func (p *parser) term() (float64, error) {
+	v, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := "*"
+		switch {
+		case p.isOp("*") || p.isOp("/") || p.isOp("%"):
+			op = p.lit
+			p.next()
+		case p.tok == tokNum || p.tok == tokIdent || p.isOp("(") || p.isOp("√"):
+			// Juxtaposition.
+		default:
+			return v, nil
+		}
+		pos := p.pos
+		w, err := p.unary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case "*":
+			v *= w
+		case "/", "%":
+			if w == 0 {
+				return 0, &SyntaxError{Pos: pos, Msg: "division by zero"}
+			}
+			if op == "/" {
+				v /= w
+			} else {
+				v = math.Mod(v, w)
+			}
+		}
+	}
+}
+
+// unary parses signs, which bind looser than powers: -2^2 is -4.
+func (p *parser) unary() (float64, error) {
+	switch {
+	case p.isOp("-"):
+		p.next()
+		v, err := p.unary()
+		return -v, err
+	case p.isOp("+"):
+		p.next()
+		return p.unary()
+	}
+	return p.power()
+}
+
+// power parses right associative powers.
+func (p *parser) power() (float64, error) {
+	v, err := p.primary()
+	if err != nil {
+		return 0, err
+	}
+	if !p.isOp("^") {
+		return v, nil
+	}
+	p.next()
+	w, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+	return math.Pow(v, w), nil
+}
+
+func (p *parser) primary() (float64, error) {
+	switch {
+	case p.tok == tokNum:
+		v := p.num
+		p.next()
+		return v, nil
+	case p.isOp("("):
+		p.next()
+		v, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		if !p.isOp(")") {
+			return 0, p.errorf("missing )")
+		}
+		p.next()
+		return v, nil
+	case p.isOp("√"):
+		p.next()
+		return p.apply(math.Sqrt)
+	case p.tok == tokIdent:
+		name := p.lit
+		if f, ok := functions[name]; ok {
+			p.next()
+			return p.apply(f)
+		}
+		if v, ok := p.vars[name]; ok {
+			p.next()
+			return v, nil
+		}
+		if v, ok := constants[name]; ok {
+			p.next()
+			return v, nil
+		}
+		return 0, p.errorf("unknown name %q", name)
+	case p.tok == tokEOF:
+		return 0, p.errorf("incomplete expression")
+	default:
+		return 0, p.errorf("unexpected %s %q", p.tok, p.src[p.pos:p.off])
+	}
+}
+
+// apply parses the argument of a function and applies it. An argument
+// in parentheses binds tighter than powers, as in sqrt(2)^2, and one
+// without looser, as in sqrt 2^2.
+func (p *parser) apply(f func(float64) float64) (float64, error) {
+	var v float64
+	var err error
+	if p.isOp("(") {
+		v, err = p.primary()
+	} else {
+		v, err = p.power()
+	}
+	return f(v), err
+}
+
+// formatNumber formats v with up to 12 significant digits.
+func formatNumber(v float64) string {
+	if v == 0 {
+		// Avoid -0.
+		return "0"
+	}
+	return strconv.FormatFloat(v, 'g', 12, 64)
+}
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	vars := map[string]float64{"ans": 10}
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"1+2*3", 7},
+		{"(1+2)*3", 9},
+		{"10-4-3", 3},
+		{"2^3^2", 512},
+		{"-2^2", -4},
+		{"2^-1", 0.5},
+		{"7 % 3", 1},
+		{"8÷2×3−1", 11},
+		{"2pi", 2 * math.Pi},
+		{"3(4+5)", 27},
+		{"√16+1", 5},
+		{"sqrt(2)^2", 2.0000000000000004},
+		{"1.5e3 + .5", 1500.5},
+		{"ans/4", 2.5},
+		{"2e", 2 * math.E},
+		{"SIN(0)", 0},
+		{"log 1000", 3},
+	}
+	for _, test := range tests {
+		got, err := Eval(test.expr, vars)
+		if err != nil {
+			t.Errorf("Eval(%q): %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Eval(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+	}{
+		{"", 0},
+		{"1+", 2},
+		{"(1+2", 4},
+		{"1/0", 2},
+		{"1/(2-2)", 2},
+		{"2*foo", 2},
+		{"1.2.3", 0},
+		{"1 $ 2", 2},
+		{"1)", 1},
+	}
+	for _, test := range tests {
+		_, err := Eval(test.expr, nil)
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			t.Errorf("Eval(%q): got error %v, want a syntax error", test.expr, err)
+			continue
+		}
+		if serr.Pos != test.pos {
+			t.Errorf("Eval(%q): error %q at %d, want at %d", test.expr, serr.Msg, serr.Pos, test.pos)
+		}
+	}
+	if _, err := Eval("sqrt(-1)", nil); err == nil {
+		t.Error("Eval(sqrt(-1)) succeeded")
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{0, "0"},
+		{math.Copysign(0, -1), "0"},
+		{0.1 + 0.2, "0.3"},
+		{1.0 / 3, "0.333333333333"},
+		{1e20, "1e+20"},
+		{-42, "-42"},
+	}
+	for _, test := range tests {
+		if got := formatNumber(test.v); got != test.want {
+			t.Errorf("formatNumber(%v) = %q, want %q", test.v, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program is a calculator with a key grid, keyboard input and a
+// history of results. Expressions are parsed and evaluated by a small
+// recursive descent parser with operator precedence, functions and
+// the last result as ans.
+//
+// The expression is an editor that keeps the keyboard focus while the
+// keys are clicked, so typing and clicking mix freely. Enter or = shows
+// the result, and clicking an entry of the history inserts its result.
+//
+// Usage:
+//
+//	go run ./calculator
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/style"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Calculator"),
+			app.Size(unit.Dp(360), unit.Dp(600)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+
+// keys are the rows of the key grid, by label. Labels not handled by
+// press are inserted into the expression.
+var keys = [][]string{
+	{"√", "^", "π", "ans"},
+	{"C", "(", ")", "÷"},
+	{"7", "8", "9", "×"},
+	{"4", "5", "6", "−"},
+	{"1", "2", "3", "+"},
+	{"0", ".", "⌫", "="},
+}
+
+// entry is an evaluated expression in the history.
+type entry struct {
+	expr   string
+	result float64
+	click  widget.Clickable
+}
+
+type App struct {
+	display widget.Editor
+	buttons map[string]*widget.Clickable
+
+	history     []*entry
+	historyList layout.List
+	// ans is the last result.
+	ans float64
+	// preview is the result of the expression as typed, or err the
+	// reason it has none.
+	preview string
+	err     error
+}
+
+func newApp() *App {
+	a := &App{
+		buttons:     make(map[string]*widget.Clickable),
+		historyList: layout.List{Axis: layout.Vertical, ScrollToEnd: true},
+	}
+	a.display.SingleLine = true
+	a.display.Submit = true
+	a.display.Alignment = text.End
+	a.display.Focus()
+	for _, row := range keys {
+		for _, k := range row {
+			a.buttons[k] = new(widget.Clickable)
+		}
+	}
+	return a
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := newApp()
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			a.Layout(gtx, th)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+func (a *App) vars() map[string]float64 {
+	return map[string]float64{"ans": a.ans}
+}
+
+func (a *App) update() {
+	for _, e := range a.display.Events() {
+		switch e.(type) {
+		case widget.ChangeEvent:
+			// A typed = evaluates, like Enter.
+			if t := a.display.Text(); strings.HasSuffix(t, "=") {
+				a.setText(strings.TrimSuffix(t, "="))
+				a.evaluate()
+			}
+		case widget.SubmitEvent:
+			a.evaluate()
+		}
+	}
+	for _, row := range keys {
+		for _, k := range row {
+			for a.buttons[k].Clicked() {
+				a.press(k)
+				// Keep typing in the expression after clicking.
+				a.display.Focus()
+			}
+		}
+	}
+	for _, h := range a.history {
+		for h.click.Clicked() {
+			a.display.Insert(formatNumber(h.result))
+			a.display.Focus()
+		}
+	}
+	a.preview, a.err = "", nil
+	if expr := strings.TrimSpace(a.display.Text()); expr != "" {
+		v, err := Eval(expr, a.vars())
+		if err != nil {
+			a.err = err
+		} else {
+			a.preview = formatNumber(v)
+		}
+	}
+}
+
+func (a *App) press(k string) {
+	switch k {
+	case "C":
+		a.setText("")
+	case "⌫":
+		a.display.Delete(-1)
+	case "=":
+		a.evaluate()
+	case "√":
+		a.display.Insert("√(")
+	default:
+		a.display.Insert(k)
+	}
+}
+
+// evaluate replaces the expression with its result and adds it to the
+// history.
+func (a *App) evaluate() {
+	expr := strings.TrimSpace(a.display.Text())
+	if expr == "" {
+		return
+	}
+	v, err := Eval(expr, a.vars())
+	if err != nil {
+		// Leave the expression for fixing; the error is shown below it.
+		return
+	}
+	a.history = append(a.history, &entry{expr: expr, result: v})
+	a.ans = v
+	a.setText(formatNumber(v))
+}
+
+// setText replaces the expression, with the caret at its end.
+func (a *App) setText(s string) {
+	a.display.SetText(s)
+	n := utf8.RuneCountInString(s)
+	a.display.MoveCaret(n, n)
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	a.update()
+	return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Flexed(1, func(gtx C) D {
+				return a.layoutHistory(gtx, th)
+			}),
+			layout.Rigid(func(gtx C) D {
+				return a.layoutDisplay(gtx, th)
+			}),
+			layout.Rigid(func(gtx C) D {
+				gtx.Constraints.Min.Y = gtx.Constraints.Max.Y * 3 / 5
+				gtx.Constraints.Max.Y = gtx.Constraints.Min.Y
+				return a.layoutKeys(gtx, th)
+			}),
+		)
+	})
+}
+
+func (a *App) layoutHistory(gtx C, th *material.Theme) D {
+	return a.historyList.Layout(gtx, len(a.history), func(gtx C, i int) D {
+		h := a.history[i]
+		return material.Clickable(gtx, &h.click, func(gtx C) D {
+			return layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx C) D {
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+				l := material.Body1(th, fmt.Sprintf("%s = %s", h.expr, formatNumber(h.result)))
+				l.Alignment = text.End
+				l.Color = style.MulAlpha(l.Color, 0xa0)
+				return l.Layout(gtx)
+			})
+		})
+	})
+}
+
+func (a *App) layoutDisplay(gtx C, th *material.Theme) D {
+	return layout.Inset{Top: unit.Dp(8), Bottom: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+				ed := material.Editor(th, &a.display, "0")
+				ed.TextSize = unit.Sp(32)
+				return ed.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx C) D {
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+				l := material.Body2(th, a.preview)
+				l.Color = style.MulAlpha(l.Color, 0xa0)
+				if a.err != nil {
+					l.Text = a.err.Error()
+					l.Color = errorColor
+				}
+				l.Alignment = text.End
+				return l.Layout(gtx)
+			}),
+		)
+	})
+}
+
+func (a *App) layoutKeys(gtx C, th *material.Theme) D {
+	var rows []layout.FlexChild
+	for _, row := range keys {
+		row := row
+		rows = append(rows, layout.Flexed(1, func(gtx C) D {
+			var cols []layout.FlexChild
+			for _, k := range row {
+				k := k
+				cols = append(cols, layout.Flexed(1, func(gtx C) D {
+					return layout.UniformInset(unit.Dp(3)).Layout(gtx, func(gtx C) D {
+						gtx.Constraints.Min = gtx.Constraints.Max
+						b := material.Button(th, a.buttons[k], k)
+						b.TextSize = unit.Sp(20)
+						if !isDigit(k) {
+							b.Background = style.MulAlpha(th.Palette.ContrastBg, 0xc0)
+						}
+						return b.Layout(gtx)
+					})
+				}))
+			}
+			return layout.Flex{}.Layout(gtx, cols...)
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
+}
+
+func isDigit(k string) bool {
+	return len(k) == 1 && (k[0] >= '0' && k[0] <= '9' || k[0] == '.')
+}
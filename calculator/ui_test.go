@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"testing"
+
+	"gioui.org/example/internal/uitest"
+	"gioui.org/font/gofont"
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/widget/material"
+)
+
+func newDriver(t *testing.T) (*uitest.Driver, *App) {
+	th := material.NewTheme(gofont.Collection())
+	a := newApp()
+	d := uitest.New(image.Pt(360, 600), func(gtx layout.Context) {
+		a.Layout(gtx, th)
+	})
+	t.Cleanup(d.Close)
+	return d, a
+}
+
+func TestKeyboard(t *testing.T) {
+	d, a := newDriver(t)
+	// The expression has the focus from the start.
+	d.Type("1+2")
+	if a.preview != "3" {
+		t.Errorf("preview is %q, want 3", a.preview)
+	}
+	d.Press(key.NameReturn, 0)
+	if got := a.display.Text(); got != "3" {
+		t.Errorf("expression is %q after Enter, want the result 3", got)
+	}
+	// Typed text continues after the result.
+	d.Type("*4=")
+	if got := a.display.Text(); got != "12" {
+		t.Errorf("expression is %q after typing =, want 12", got)
+	}
+	if len(a.history) != 2 || a.history[1].expr != "3*4" {
+		t.Errorf("unexpected history %v", a.history)
+	}
+}
+
+func TestKeysKeepFocus(t *testing.T) {
+	d, a := newDriver(t)
+	for _, k := range []string{"7", "×", "("} {
+		if err := d.Click(a.buttons[k]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d.Type("2+ans")
+	if err := d.Click(a.buttons[")"]); err != nil {
+		t.Fatal(err)
+	}
+	if got := a.display.Text(); got != "7×(2+ans)" {
+		t.Errorf("expression is %q, want 7×(2+ans)", got)
+	}
+	if err := d.Click(a.buttons["="]); err != nil {
+		t.Fatal(err)
+	}
+	if got := a.display.Text(); got != "14" {
+		t.Errorf("result is %q, want 14", got)
+	}
+	if err := d.Click(a.buttons["C"]); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Click(&a.history[0].click); err != nil {
+		t.Fatal(err)
+	}
+	d.Type("/2")
+	d.Press(key.NameReturn, 0)
+	if got := a.display.Text(); got != "7" {
+		t.Errorf("result is %q, want 7", got)
+	}
+}
+
+func TestError(t *testing.T) {
+	d, a := newDriver(t)
+	d.Type("1/0")
+	d.Press(key.NameReturn, 0)
+	if a.err == nil {
+		t.Error("no error shown for 1/0")
+	}
+	if got := a.display.Text(); got != "1/0" {
+		t.Errorf("expression is %q, want it left for fixing", got)
+	}
+	if len(a.history) != 0 {
+		t.Errorf("failed expression added to the history")
+	}
+}
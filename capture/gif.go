@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"sort"
+	"time"
+
+	"golang.org/x/image/draw"
+)
+
+// Recorder assembles captured frames into an animated GIF. Frames are
+// converted to paletted images as they arrive, which keeps a long
+// recording small.
+type Recorder struct {
+	// Scale is applied to the frames, for smaller files.
+	Scale float32
+
+	anim gif.GIF
+	// last is the previous frame, to merge unchanged frames, and lastAt
+	// the time it was captured.
+	last   *image.RGBA
+	lastAt time.Time
+}
+
+// Add adds a frame captured at time t. A frame identical to the
+// previous one extends its display time instead.
+func (r *Recorder) Add(img *image.RGBA, t time.Time) {
+	if r.last != nil {
+		if r.last.Rect == img.Rect && bytes.Equal(r.last.Pix, img.Pix) {
+			return
+		}
+		r.setDelay(t)
+	}
+	r.last, r.lastAt = img, t
+	if r.Scale > 0 && r.Scale != 1 {
+		b := img.Bounds()
+		dst := image.NewRGBA(image.Rect(0, 0, int(float32(b.Dx())*r.Scale), int(float32(b.Dy())*r.Scale)))
+		draw.ApproxBiLinear.Scale(dst, dst.Rect, img, b, draw.Src, nil)
+		img = dst
+	}
+	r.anim.Image = append(r.anim.Image, quantize(img))
+	r.anim.Delay = append(r.anim.Delay, 0)
+}
+
+// Frames returns the number of distinct frames added.
+func (r *Recorder) Frames() int {
+	return len(r.anim.Image)
+}
+
+// setDelay sets the display time of the last frame to last until t.
+func (r *Recorder) setDelay(t time.Time) {
+	n := len(r.anim.Delay)
+	if n == 0 {
+		return
+	}
+	// Delays are in hundredths of a second; GIF viewers treat very
+	// short delays as slow ones.
+	d := int(t.Sub(r.lastAt) / (10 * time.Millisecond))
+	if d < 2 {
+		d = 2
+	}
+	r.anim.Delay[n-1] = d
+}
+
+// Finish completes the recording at time end and returns the animation.
+func (r *Recorder) Finish(end time.Time) *gif.GIF {
+	r.setDelay(end)
+	return &r.anim
+}
+
+// quantize converts img to a paletted image with a palette of its most
+// frequent colors. User interfaces are mostly flat areas of few colors,
+// which a fixed palette with dithering renders poorly.
+func quantize(img *image.RGBA) *image.Paletted {
+	// Colors are counted at 5 bits per channel.
+	key := func(i int) uint16 {
+		p := img.Pix[i : i+3]
+		return uint16(p[0]>>3)<<10 | uint16(p[1]>>3)<<5 | uint16(p[2]>>3)
+	}
+	counts := make(map[uint16]int)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		i := img.PixOffset(b.Min.X, y)
+		for x := b.Min.X; x < b.Max.X; x, i = x+1, i+4 {
+			counts[key(i)]++
+		}
+	}
+	keys := make([]uint16, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > 256 {
+		keys = keys[:256]
+	}
+	expand := func(v uint16) uint8 {
+		v &= 0x1f
+		return uint8(v<<3 | v>>2)
+	}
+	pal := make(color.Palette, len(keys))
+	for i, k := range keys {
+		pal[i] = color.RGBA{R: expand(k >> 10), G: expand(k >> 5), B: expand(k), A: 0xff}
+	}
+	index := make(map[uint16]uint8, len(counts))
+	for i, k := range keys {
+		index[k] = uint8(i)
+	}
+	dst := image.NewPaletted(image.Rect(0, 0, b.Dx(), b.Dy()), pal)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		i := img.PixOffset(b.Min.X, y)
+		j := dst.PixOffset(0, y-b.Min.Y)
+		for x := b.Min.X; x < b.Max.X; x, i, j = x+1, i+4, j+1 {
+			k := key(i)
+			idx, ok := index[k]
+			if !ok {
+				// A rare color; use the closest of the palette.
+				idx = uint8(pal.Index(color.RGBA{R: expand(k >> 10), G: expand(k >> 5), B: expand(k), A: 0xff}))
+				index[k] = idx
+			}
+			dst.Pix[j] = idx
+		}
+	}
+	return dst
+}
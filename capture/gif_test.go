@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+	"time"
+)
+
+func TestQuantize(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 10))
+	red := color.RGBA{R: 0xff, A: 0xff}
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, 10, 10), image.NewUniform(red), image.Point{}, draw.Src)
+	p := quantize(img)
+	if len(p.Palette) != 2 {
+		t.Fatalf("got %d colors, want 2", len(p.Palette))
+	}
+	// The most frequent color comes first.
+	if got := p.ColorIndexAt(20, 5); got != 0 {
+		t.Errorf("white has index %d", got)
+	}
+	if got := color.RGBAModel.Convert(p.At(5, 5)); got != red {
+		t.Errorf("got %v, want %v", got, red)
+	}
+}
+
+func TestQuantizeManyColors(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 256, 256))
+	for y := 0; y < 256; y++ {
+		for x := 0; x < 256; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 0x80, A: 0xff})
+		}
+	}
+	p := quantize(img)
+	if len(p.Palette) != 256 {
+		t.Fatalf("got %d colors, want 256", len(p.Palette))
+	}
+}
+
+func TestRecorderMergesFrames(t *testing.T) {
+	frame := func(c color.Color) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+		draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+		return img
+	}
+	start := time.Now()
+	var r Recorder
+	r.Add(frame(color.White), start)
+	r.Add(frame(color.White), start.Add(100*time.Millisecond))
+	r.Add(frame(color.Black), start.Add(500*time.Millisecond))
+	anim := r.Finish(start.Add(800 * time.Millisecond))
+	if len(anim.Image) != 2 {
+		t.Fatalf("got %d frames, want 2", len(anim.Image))
+	}
+	if want := []int{50, 30}; anim.Delay[0] != want[0] || anim.Delay[1] != want[1] {
+		t.Errorf("got delays %v, want %v", anim.Delay, want)
+	}
+}
@@ -0,0 +1,361 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program captures its own user interface to image files, for
+// making screenshots and animated demos of widgets. The demo area is
+// rendered a second time into an offscreen headless window and read
+// back: once for a PNG screenshot, or on every frame while recording an
+// animated GIF. The toolbar itself is not captured.
+//
+// Usage:
+//
+//	go run ./capture [-o directory]
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/gpu/headless"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var outDir = flag.String("o", ".", "directory for the captured files")
+
+// captureInterval limits the frame rate of recordings.
+const captureInterval = 40 * time.Millisecond
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Capture"),
+			app.Size(unit.Dp(720), unit.Dp(560)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+
+// saved is the result of writing a file.
+type saved struct {
+	path string
+	err  error
+}
+
+// capturer renders ops offscreen and reads back the pixels.
+type capturer struct {
+	win  *headless.Window
+	size image.Point
+	ops  op.Ops
+}
+
+// capture renders call on a background of color bg.
+func (c *capturer) capture(size image.Point, bg color.NRGBA, call op.CallOp) (*image.RGBA, error) {
+	if c.win == nil || c.size != size {
+		c.release()
+		w, err := headless.NewWindow(size.X, size.Y)
+		if err != nil {
+			return nil, err
+		}
+		c.win, c.size = w, size
+	}
+	c.ops.Reset()
+	paint.Fill(&c.ops, bg)
+	call.Add(&c.ops)
+	if err := c.win.Frame(&c.ops); err != nil {
+		return nil, err
+	}
+	return c.win.Screenshot()
+}
+
+func (c *capturer) release() {
+	if c.win != nil {
+		c.win.Release()
+		c.win = nil
+	}
+}
+
+type App struct {
+	cap   capturer
+	saves chan saved
+	// shot requests a screenshot of the next frame.
+	shot bool
+	// rec is the recording in progress, started at recStart; lastCapture
+	// is the time of the last frame captured.
+	rec         *Recorder
+	recStart    time.Time
+	lastCapture time.Time
+	saving      bool
+	status      string
+	err         error
+
+	screenshot, record widget.Clickable
+	half               widget.Bool
+
+	demo demo
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{saves: make(chan saved, 1)}
+	a.demo.animate.Value = true
+	defer a.cap.release()
+	var ops op.Ops
+	for {
+		select {
+		case s := <-a.saves:
+			a.saving = false
+			a.err = s.err
+			if s.err == nil {
+				a.status = "Saved " + s.path
+			}
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update(gtx)
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+func (a *App) update(gtx C) {
+	for a.screenshot.Clicked() {
+		a.shot = true
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+	for a.record.Clicked() {
+		if a.rec == nil {
+			a.rec = new(Recorder)
+			if a.half.Value {
+				a.rec.Scale = 0.5
+			}
+			a.recStart = gtx.Now
+			a.lastCapture = time.Time{}
+			a.err = nil
+			op.InvalidateOp{}.Add(gtx.Ops)
+			continue
+		}
+		rec := a.rec
+		a.rec = nil
+		if rec.Frames() == 0 {
+			continue
+		}
+		anim := rec.Finish(gtx.Now)
+		a.save("gif", func(f *os.File) error { return gif.EncodeAll(f, anim) })
+	}
+}
+
+// save writes a capture to a new file in the background.
+func (a *App) save(ext string, write func(f *os.File) error) {
+	name := fmt.Sprintf("capture-%s.%s", time.Now().Format("20060102-150405.000"), ext)
+	path := filepath.Join(*outDir, name)
+	a.saving = true
+	a.status = "Saving…"
+	go func() {
+		f, err := os.Create(path)
+		if err == nil {
+			err = write(f)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+		a.saves <- saved{path, err}
+	}()
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, a.layoutToolbar(th))
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			gtx.Constraints.Min = gtx.Constraints.Max
+			// Record the demo to draw it both here and offscreen.
+			m := op.Record(gtx.Ops)
+			dims := a.demo.Layout(gtx, th)
+			call := m.Stop()
+			paint.FillShape(gtx.Ops, th.Palette.Bg, clipRect(dims.Size))
+			call.Add(gtx.Ops)
+			a.capture(gtx, th, dims.Size, call)
+			return dims
+		}),
+	)
+}
+
+// capture captures the demo if a screenshot was requested or a
+// recording is due for a frame.
+func (a *App) capture(gtx C, th *material.Theme, size image.Point, call op.CallOp) {
+	if size.X == 0 || size.Y == 0 {
+		return
+	}
+	if a.shot {
+		a.shot = false
+		img, err := a.cap.capture(size, th.Palette.Bg, call)
+		if err != nil {
+			a.err = err
+			return
+		}
+		a.save("png", func(f *os.File) error { return png.Encode(f, img) })
+	}
+	if a.rec == nil {
+		return
+	}
+	// Redraw to show the recording time, and to capture the last state
+	// of frames skipped for the frame rate.
+	op.InvalidateOp{At: gtx.Now.Add(captureInterval)}.Add(gtx.Ops)
+	if gtx.Now.Sub(a.lastCapture) < captureInterval {
+		return
+	}
+	img, err := a.cap.capture(size, th.Palette.Bg, call)
+	if err != nil {
+		a.err = err
+		a.rec = nil
+		return
+	}
+	a.lastCapture = gtx.Now
+	a.rec.Add(img, gtx.Now)
+}
+
+func (a *App) layoutToolbar(th *material.Theme) layout.Widget {
+	return func(gtx C) D {
+		recLabel := "Record GIF"
+		if a.rec != nil {
+			recLabel = "Stop"
+		}
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				if a.rec != nil {
+					gtx = gtx.Disabled()
+				}
+				return material.Button(th, &a.screenshot, "Screenshot").Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+			layout.Rigid(func(gtx C) D {
+				if a.saving {
+					gtx = gtx.Disabled()
+				}
+				return material.Button(th, &a.record, recLabel).Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+			layout.Rigid(func(gtx C) D {
+				if a.rec != nil {
+					gtx = gtx.Disabled()
+				}
+				return material.CheckBox(th, &a.half, "Half size").Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+			layout.Flexed(1, func(gtx C) D {
+				l := material.Body2(th, a.status)
+				switch {
+				case a.err != nil:
+					l.Text = a.err.Error()
+					l.Color = errorColor
+				case a.rec != nil:
+					secs := gtx.Now.Sub(a.recStart).Seconds()
+					l.Text = fmt.Sprintf("Recording… %.1f s, %d frames", secs, a.rec.Frames())
+					l.Color = errorColor
+				}
+				return l.Layout(gtx)
+			}),
+		)
+	}
+}
+
+// demo is the part of the window captured: a few widgets, one of them
+// animated.
+type demo struct {
+	button  widget.Clickable
+	clicks  int
+	animate widget.Bool
+	dark    widget.Bool
+	volume  widget.Float
+	name    widget.Editor
+}
+
+func (d *demo) Layout(gtx C, th *material.Theme) D {
+	for d.button.Clicked() {
+		d.clicks++
+	}
+	progress := float32(0.4)
+	if d.animate.Value {
+		// A progress bar filling every two seconds.
+		const period = 2 * time.Second
+		progress = float32(gtx.Now.UnixNano()%int64(period)) / float32(period)
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+	if d.dark.Value {
+		paint.FillShape(gtx.Ops, color.NRGBA{R: 0x30, G: 0x30, B: 0x38, A: 0xff}, clipRect(gtx.Constraints.Max))
+		dark := *th
+		dark.Palette.Fg = color.NRGBA{R: 0xee, G: 0xee, B: 0xee, A: 0xff}
+		dark.Palette.Bg = color.NRGBA{R: 0x30, G: 0x30, B: 0x38, A: 0xff}
+		th = &dark
+	}
+	widgets := []layout.Widget{
+		material.H5(th, "Widgets to capture").Layout,
+		func(gtx C) D {
+			return material.Button(th, &d.button, fmt.Sprintf("Clicked %d times", d.clicks)).Layout(gtx)
+		},
+		material.CheckBox(th, &d.animate, "Animate the progress bar").Layout,
+		material.ProgressBar(th, progress).Layout,
+		material.Switch(th, &d.dark).Layout,
+		func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(material.Body1(th, "Volume").Layout),
+				layout.Flexed(1, material.Slider(th, &d.volume, 0, 100).Layout),
+				layout.Rigid(func(gtx C) D {
+					return material.Body1(th, fmt.Sprintf("%3.0f", d.volume.Value)).Layout(gtx)
+				}),
+			)
+		},
+		material.Editor(th, &d.name, "Type something").Layout,
+	}
+	var children []layout.FlexChild
+	for _, w := range widgets {
+		children = append(children,
+			layout.Rigid(w),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+		)
+	}
+	layout.UniformInset(unit.Dp(24)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	})
+	return D{Size: gtx.Constraints.Max}
+}
+
+func clipRect(size image.Point) clip.Op {
+	return clip.Rect{Max: size}.Op()
+}
@@ -0,0 +1,269 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates a filter bar of removable chips wrapped with
+// the flow layout from internal/flow. Chips animate in and out as they
+// are added and removed.
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/flow"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Chips"),
+			app.Size(unit.Dp(500), unit.Dp(600)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// animationDuration is the duration of the chip appear and disappear
+// animations.
+const animationDuration = 200 * time.Millisecond
+
+// Chip is a removable filter.
+type Chip struct {
+	Label string
+
+	remove  widget.Clickable
+	added   time.Time
+	removed time.Time
+}
+
+// progress returns how far the chip is shown, from 0 to 1.
+func (c *Chip) progress(now time.Time) float32 {
+	var t float32 = 1
+	if !c.added.IsZero() {
+		t = float32(now.Sub(c.added)) / float32(animationDuration)
+	}
+	if !c.removed.IsZero() {
+		t = 1 - float32(now.Sub(c.removed))/float32(animationDuration)
+	}
+	if t < 0 {
+		return 0
+	}
+	if t > 1 {
+		return 1
+	}
+	return t
+}
+
+type item struct {
+	name string
+	tags []string
+}
+
+var items = []item{
+	{"Apple", []string{"fruit", "red", "autumn"}},
+	{"Banana", []string{"fruit", "yellow", "tropical"}},
+	{"Carrot", []string{"vegetable", "orange", "winter"}},
+	{"Cherry", []string{"fruit", "red", "summer"}},
+	{"Kale", []string{"vegetable", "green", "winter"}},
+	{"Lemon", []string{"fruit", "yellow", "winter"}},
+	{"Mango", []string{"fruit", "orange", "tropical"}},
+	{"Pea", []string{"vegetable", "green", "summer"}},
+	{"Pumpkin", []string{"vegetable", "orange", "autumn"}},
+	{"Radish", []string{"vegetable", "red", "spring"}},
+	{"Strawberry", []string{"fruit", "red", "spring"}},
+	{"Zucchini", []string{"vegetable", "green", "summer"}},
+}
+
+// UI holds the program state.
+type UI struct {
+	theme *material.Theme
+	chips []*Chip
+	input widget.Editor
+	list  layout.List
+	flow  flow.Flow
+}
+
+func loop(w *app.Window) error {
+	ui := &UI{
+		theme: material.NewTheme(gofont.Collection()),
+		input: widget.Editor{SingleLine: true, Submit: true},
+		list:  layout.List{Axis: layout.Vertical},
+		flow: flow.Flow{
+			Spacing:     unit.Dp(6),
+			LineSpacing: unit.Dp(6),
+			Alignment:   layout.Middle,
+		},
+	}
+	ui.chips = []*Chip{{Label: "fruit"}}
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			ui.Layout(gtx)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+func (ui *UI) update(gtx C) {
+	for _, e := range ui.input.Events() {
+		if e, ok := e.(widget.SubmitEvent); ok {
+			if label := strings.ToLower(strings.TrimSpace(e.Text)); label != "" {
+				ui.chips = append(ui.chips, &Chip{Label: label, added: gtx.Now})
+			}
+			ui.input.SetText("")
+		}
+	}
+	live := ui.chips[:0]
+	for _, c := range ui.chips {
+		if c.remove.Clicked() && c.removed.IsZero() {
+			c.removed = gtx.Now
+		}
+		if !c.removed.IsZero() && c.progress(gtx.Now) == 0 {
+			continue
+		}
+		live = append(live, c)
+	}
+	ui.chips = live
+}
+
+// Layout lays out the program.
+func (ui *UI) Layout(gtx C) D {
+	ui.update(gtx)
+	var shown []item
+	for _, it := range items {
+		if ui.matches(it) {
+			shown = append(shown, it)
+		}
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return widget.Border{
+					Color:        color.NRGBA{A: 0x40},
+					CornerRadius: unit.Dp(6),
+					Width:        unit.Px(1),
+				}.Layout(gtx, func(gtx C) D {
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					return layout.UniformInset(unit.Dp(6)).Layout(gtx, func(gtx C) D {
+						// The editor is the last child of the flow, so it
+						// wraps along with the chips.
+						return ui.flow.Layout(gtx, len(ui.chips)+1, func(gtx C, i int) D {
+							if i == len(ui.chips) {
+								gtx.Constraints.Min.X = gtx.Px(unit.Dp(120))
+								return layout.UniformInset(unit.Dp(4)).Layout(gtx,
+									material.Editor(ui.theme, &ui.input, "Add filter").Layout,
+								)
+							}
+							return ui.layoutChip(gtx, ui.chips[i])
+						})
+					})
+				})
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return ui.list.Layout(gtx, len(shown), func(gtx C, i int) D {
+				it := shown[i]
+				return layout.Inset{Left: unit.Dp(16), Top: unit.Dp(8), Bottom: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(material.Body1(ui.theme, it.name).Layout),
+						layout.Rigid(material.Caption(ui.theme, strings.Join(it.tags, ", ")).Layout),
+					)
+				})
+			})
+		}),
+	)
+}
+
+// matches reports whether it has every tag of the chips not being removed.
+func (ui *UI) matches(it item) bool {
+outer:
+	for _, c := range ui.chips {
+		if !c.removed.IsZero() {
+			continue
+		}
+		if strings.Contains(strings.ToLower(it.name), c.Label) {
+			continue
+		}
+		for _, t := range it.tags {
+			if t == c.Label {
+				continue outer
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// layoutChip lays out a chip scaled horizontally by its animation
+// progress.
+func (ui *UI) layoutChip(gtx C, c *Chip) D {
+	t := c.progress(gtx.Now)
+	if t < 1 {
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+	m := op.Record(gtx.Ops)
+	dims := ui.chip(gtx, c, t)
+	call := m.Stop()
+
+	w := int(float32(dims.Size.X) * t)
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect(image.Rect(0, 0, w, dims.Size.Y)).Add(gtx.Ops)
+	call.Add(gtx.Ops)
+	dims.Size.X = w
+	return dims
+}
+
+func (ui *UI) chip(gtx C, c *Chip, alpha float32) D {
+	th := ui.theme
+	bg := th.Palette.ContrastBg
+	bg.A = uint8(float32(0x30) * alpha)
+	return layout.Stack{}.Layout(gtx,
+		layout.Expanded(func(gtx C) D {
+			sz := gtx.Constraints.Min
+			rr := float32(sz.Y) / 2
+			paint.FillShape(gtx.Ops, bg, clip.UniformRRect(f32.Rectangle{Max: layout.FPt(sz)}, rr).Op(gtx.Ops))
+			return D{Size: sz}
+		}),
+		layout.Stacked(func(gtx C) D {
+			return layout.Inset{Left: unit.Dp(12), Right: unit.Dp(4)}.Layout(gtx, func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(material.Body2(th, c.Label).Layout),
+					layout.Rigid(func(gtx C) D {
+						return material.Clickable(gtx, &c.remove, func(gtx C) D {
+							return layout.UniformInset(unit.Dp(6)).Layout(gtx, material.Body2(th, "✕").Layout)
+						})
+					}),
+				)
+			})
+		}),
+	)
+}
@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStopwatch(t *testing.T) {
+	t0 := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return t0.Add(d) }
+	var s Stopwatch
+	s.Start(at(0))
+	s.Lap(at(3 * time.Second))
+	s.Stop(at(5 * time.Second))
+	if got := s.Elapsed(at(time.Hour)); got != 5*time.Second {
+		t.Errorf("stopped at %v, want 5s", got)
+	}
+	s.Start(at(10 * time.Second))
+	s.Lap(at(12 * time.Second))
+	if got := s.Elapsed(at(13 * time.Second)); got != 8*time.Second {
+		t.Errorf("elapsed %v, want 8s", got)
+	}
+	want := []time.Duration{3 * time.Second, 7 * time.Second}
+	laps := s.Laps()
+	if len(laps) != len(want) || laps[0] != want[0] || laps[1] != want[1] {
+		t.Errorf("laps %v, want %v", laps, want)
+	}
+	s.Reset()
+	if s.Running() || s.Elapsed(at(20*time.Second)) != 0 || len(s.Laps()) != 0 {
+		t.Errorf("not reset: %+v", s)
+	}
+}
+
+func TestCountdown(t *testing.T) {
+	t0 := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return t0.Add(d) }
+	var c Countdown
+	c.Set(time.Minute)
+	if left := c.Start(at(0)); left != time.Minute {
+		t.Errorf("started with %v left, want 1m", left)
+	}
+	c.Pause(at(20 * time.Second))
+	if got := c.Remaining(at(time.Hour)); got != 40*time.Second {
+		t.Errorf("paused with %v left, want 40s", got)
+	}
+	c.Add(10 * time.Second)
+	if left := c.Start(at(time.Hour)); left != 50*time.Second {
+		t.Errorf("resumed with %v left, want 50s", left)
+	}
+	if got := c.Progress(at(time.Hour + 15*time.Second)); got != 0.5 {
+		t.Errorf("progress %v, want 0.5", got)
+	}
+	if got := c.Remaining(at(2 * time.Hour)); got != 0 {
+		t.Errorf("%v left after the end, want 0", got)
+	}
+	c.Finish()
+	if c.Start(at(3*time.Hour)) != 0 || c.Running() {
+		t.Error("finished countdown started")
+	}
+	c.Reset()
+	if got := c.Remaining(at(0)); got != 70*time.Second {
+		t.Errorf("reset to %v, want 1m10s", got)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		d               time.Duration
+		elapsed, remain string
+	}{
+		{0, "00:00.00", "00:00"},
+		{1234 * time.Millisecond, "00:01.23", "00:02"},
+		{61 * time.Second, "01:01.00", "01:01"},
+		{time.Hour + 2*time.Minute + 3*time.Second, "1:02:03.00", "1:02:03"},
+	}
+	for _, test := range tests {
+		if got := formatDuration(test.d); got != test.elapsed {
+			t.Errorf("formatDuration(%v) = %q, want %q", test.d, got, test.elapsed)
+		}
+		if got := formatCountdown(test.d); got != test.remain {
+			t.Errorf("formatCountdown(%v) = %q, want %q", test.d, got, test.remain)
+		}
+	}
+}
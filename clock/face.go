@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/widget/material"
+)
+
+// Hand is a hand of a dial.
+type Hand struct {
+	// Turn is the position of the hand as a fraction of a turn
+	// clockwise from the top.
+	Turn float32
+	// Length is the length relative to the radius of the dial, and Width
+	// relative to the diameter.
+	Length, Width float32
+	Color         color.NRGBA
+}
+
+// DialStyle draws a round dial with minute ticks, hands and an optional
+// progress ring around it.
+type DialStyle struct {
+	Face, Rim, Ticks color.NRGBA
+	Hands            []Hand
+	// Progress draws a ring of the fraction of a turn, if positive.
+	Progress      float32
+	ProgressColor color.NRGBA
+}
+
+func dial(th *material.Theme, hands ...Hand) DialStyle {
+	return DialStyle{
+		Face:          th.Palette.Bg,
+		Rim:           th.Palette.Fg,
+		Ticks:         th.Palette.Fg,
+		Hands:         hands,
+		ProgressColor: th.Palette.ContrastBg,
+	}
+}
+
+// turnPoint returns the point at radius r and a fraction of a turn
+// clockwise from the top.
+func turnPoint(c f32.Point, r, turn float32) f32.Point {
+	s, co := math.Sincos(2*math.Pi*float64(turn) - math.Pi/2)
+	return c.Add(f32.Pt(r*float32(co), r*float32(s)))
+}
+
+// line returns the stroke of the line from p0 to p1.
+func line(ops *op.Ops, p0, p1 f32.Point, width float32) clip.Op {
+	var p clip.Path
+	p.Begin(ops)
+	p.MoveTo(p0)
+	p.LineTo(p1)
+	return clip.Stroke{Path: p.End(), Style: clip.StrokeStyle{Width: width}}.Op()
+}
+
+// ring returns the stroke of the arc of a circle from the top to a
+// fraction of a turn.
+func ring(ops *op.Ops, c f32.Point, r, turn, width float32) clip.Op {
+	// Approximate with one segment per 3 degrees.
+	n := int(math.Ceil(float64(turn) * 120))
+	var p clip.Path
+	p.Begin(ops)
+	p.MoveTo(turnPoint(c, r, 0))
+	for i := 1; i <= n; i++ {
+		p.LineTo(turnPoint(c, r, turn*float32(i)/float32(n)))
+	}
+	return clip.Stroke{Path: p.End(), Style: clip.StrokeStyle{Width: width}}.Op()
+}
+
+// Layout draws the dial as large as the constraints allow.
+func (d DialStyle) Layout(gtx layout.Context) layout.Dimensions {
+	size := gtx.Constraints.Max.X
+	if gtx.Constraints.Max.Y < size {
+		size = gtx.Constraints.Max.Y
+	}
+	if size < gtx.Constraints.Min.X {
+		size = gtx.Constraints.Min.X
+	}
+	sz := float32(size)
+	c := f32.Pt(sz/2, sz/2)
+	// Leave room for the progress ring.
+	r := sz/2 - sz*0.05
+
+	paint.FillShape(gtx.Ops, d.Face, clip.Circle{Center: c, Radius: r}.Op(gtx.Ops))
+	paint.FillShape(gtx.Ops, d.Rim, clip.Stroke{
+		Path:  clip.Circle{Center: c, Radius: r}.Path(gtx.Ops),
+		Style: clip.StrokeStyle{Width: sz * 0.01},
+	}.Op())
+	for i := 0; i < 60; i++ {
+		turn := float32(i) / 60
+		inner, width := r*0.92, sz*0.005
+		if i%5 == 0 {
+			inner, width = r*0.84, sz*0.015
+		}
+		paint.FillShape(gtx.Ops, d.Ticks, line(gtx.Ops, turnPoint(c, inner, turn), turnPoint(c, r*0.96, turn), width))
+	}
+	if d.Progress > 0 {
+		paint.FillShape(gtx.Ops, d.ProgressColor, ring(gtx.Ops, c, sz/2-sz*0.02, d.Progress, sz*0.03))
+	}
+	for _, h := range d.Hands {
+		// Hands stick out a little behind the center.
+		tail := turnPoint(c, r*0.1, h.Turn+0.5)
+		paint.FillShape(gtx.Ops, h.Color, line(gtx.Ops, tail, turnPoint(c, r*h.Length, h.Turn), sz*h.Width))
+	}
+	if len(d.Hands) > 0 {
+		paint.FillShape(gtx.Ops, d.Rim, clip.Circle{Center: c, Radius: sz * 0.02}.Op(gtx.Ops))
+	}
+	return layout.Dimensions{Size: image.Pt(size, size)}
+}
@@ -0,0 +1,366 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program is a small clock utility: an analog clock drawn with
+// paths, a stopwatch with laps and a countdown timer. The timer is backed
+// by a Go timer rather than by frames, so it fires on time while the
+// window is hidden, and announces the end with a system notification and
+// a chime.
+//
+// Usage:
+//
+//	go run ./clock [-timer 5m]
+
+import (
+	"flag"
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/chime"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/notify"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var timerFlag = flag.Duration("timer", 5*time.Minute, "initial duration of the timer")
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Clock"),
+			app.Size(unit.Dp(420), unit.Dp(640)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var (
+	accent     = color.NRGBA{R: 0xff, G: 0x6f, B: 0x00, A: 0xff}
+	errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+)
+
+// The notes of the chime of the timer, in Hz.
+var chimeNotes = []float64{988, 784, 659, 784, 988}
+
+type App struct {
+	mode widget.Enum
+
+	watch           Stopwatch
+	startStop, lap  widget.Clickable
+	laps            layout.List
+	countdown       Countdown
+	plusMin, plus10 widget.Clickable
+	run, reset      widget.Clickable
+
+	// alarm fires when the countdown ends, and finished is set after it
+	// has.
+	alarm    *time.Timer
+	finished bool
+	alerts   chan<- string
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	alerts := make(chan string, 1)
+	go alerter(alerts)
+	a := &App{
+		mode:   widget.Enum{Value: "clock"},
+		laps:   layout.List{Axis: layout.Vertical},
+		alerts: alerts,
+	}
+	a.countdown.Set(*timerFlag)
+	var ops op.Ops
+	for {
+		select {
+		case <-a.alarmC():
+			a.finish()
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+// alerter announces the messages received with a system notification
+// and a chime.
+func alerter(msgs <-chan string) {
+	mgr, err := notify.NewManager()
+	if err != nil {
+		log.Printf("notifications unavailable: %v", err)
+	}
+	sound := chime.Tones(chimeNotes...)
+	for msg := range msgs {
+		if mgr != nil {
+			if _, err := mgr.CreateNotification("Timer", msg); err != nil {
+				log.Printf("notification failed: %v", err)
+			}
+		}
+		if err := chime.Play(sound); err != nil {
+			log.Printf("chime failed: %v", err)
+		}
+	}
+}
+
+// alarmC returns the channel of the alarm, or nil when the countdown
+// isn't running.
+func (a *App) alarmC() <-chan time.Time {
+	if a.alarm == nil {
+		return nil
+	}
+	return a.alarm.C
+}
+
+func (a *App) stopAlarm() {
+	if a.alarm != nil {
+		a.alarm.Stop()
+		a.alarm = nil
+	}
+}
+
+func (a *App) finish() {
+	a.alarm = nil
+	a.countdown.Finish()
+	a.finished = true
+	select {
+	case a.alerts <- fmt.Sprintf("%s is up.", formatCountdown(a.countdown.Duration())):
+	default:
+	}
+}
+
+func (a *App) update(gtx C) {
+	now := gtx.Now
+	for a.startStop.Clicked() {
+		if a.watch.Running() {
+			a.watch.Stop(now)
+		} else {
+			a.watch.Start(now)
+		}
+	}
+	for a.lap.Clicked() {
+		if a.watch.Running() {
+			a.watch.Lap(now)
+		} else {
+			a.watch.Reset()
+		}
+	}
+	for a.plusMin.Clicked() {
+		a.add(time.Minute)
+	}
+	for a.plus10.Clicked() {
+		a.add(10 * time.Second)
+	}
+	for a.run.Clicked() {
+		if a.countdown.Running() {
+			a.countdown.Pause(now)
+			a.stopAlarm()
+		} else if left := a.countdown.Start(now); left > 0 {
+			a.finished = false
+			a.alarm = time.NewTimer(left)
+		}
+	}
+	for a.reset.Clicked() {
+		a.stopAlarm()
+		a.countdown.Reset()
+		a.finished = false
+	}
+}
+
+// add adds to the countdown, unless it is running.
+func (a *App) add(d time.Duration) {
+	if a.countdown.Running() {
+		return
+	}
+	a.countdown.Add(d)
+	a.finished = false
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	a.update(gtx)
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{}.Layout(gtx,
+					layout.Rigid(material.RadioButton(th, &a.mode, "clock", "Clock").Layout),
+					layout.Rigid(material.RadioButton(th, &a.mode, "stopwatch", "Stopwatch").Layout),
+					layout.Rigid(material.RadioButton(th, &a.mode, "timer", "Timer").Layout),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+				switch a.mode.Value {
+				case "stopwatch":
+					return a.layoutStopwatch(gtx, th)
+				case "timer":
+					return a.layoutTimer(gtx, th)
+				default:
+					return a.layoutClock(gtx, th)
+				}
+			})
+		}),
+	)
+}
+
+// layoutPanel lays out a dial above a digital readout and the rest of a
+// panel.
+func layoutPanel(gtx C, th *material.Theme, d DialStyle, readout string, rest layout.Widget) D {
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Center.Layout(gtx, d.Layout)
+		}),
+		layout.Rigid(func(gtx C) D {
+			l := material.H3(th, readout)
+			l.Alignment = text.Middle
+			return layout.Inset{Top: unit.Dp(8), Bottom: unit.Dp(8)}.Layout(gtx, l.Layout)
+		}),
+		layout.Rigid(rest),
+	)
+}
+
+func (a *App) layoutClock(gtx C, th *material.Theme) D {
+	now := gtx.Now
+	sec := float32(now.Second())
+	min := float32(now.Minute()) + sec/60
+	hour := float32(now.Hour()%12) + min/60
+	d := dial(th,
+		Hand{Turn: hour / 12, Length: 0.5, Width: 0.03, Color: th.Palette.Fg},
+		Hand{Turn: min / 60, Length: 0.75, Width: 0.02, Color: th.Palette.Fg},
+		Hand{Turn: sec / 60, Length: 0.85, Width: 0.008, Color: accent},
+	)
+	// The clock ticks every second.
+	op.InvalidateOp{At: now.Truncate(time.Second).Add(time.Second)}.Add(gtx.Ops)
+	return layoutPanel(gtx, th, d, now.Format("15:04:05"), material.Body1(th, now.Format("Monday, 2 January 2006")).Layout)
+}
+
+func (a *App) layoutStopwatch(gtx C, th *material.Theme) D {
+	elapsed := a.watch.Elapsed(gtx.Now)
+	secs := float32(elapsed.Seconds())
+	d := dial(th,
+		Hand{Turn: secs / 3600, Length: 0.55, Width: 0.02, Color: th.Palette.Fg},
+		Hand{Turn: secs / 60, Length: 0.85, Width: 0.008, Color: accent},
+	)
+	startStop, lap := "Start", "Reset"
+	if a.watch.Running() {
+		startStop, lap = "Stop", "Lap"
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+	return layoutPanel(gtx, th, d, formatDuration(elapsed), func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return layoutButtons(gtx,
+					material.Button(th, &a.startStop, startStop).Layout,
+					material.Button(th, &a.lap, lap).Layout,
+				)
+			}),
+			layout.Rigid(func(gtx C) D {
+				gtx.Constraints.Max.Y = gtx.Px(unit.Dp(120))
+				return a.layoutLaps(gtx, th, elapsed)
+			}),
+		)
+	})
+}
+
+// layoutLaps lists the laps, the current one first.
+func (a *App) layoutLaps(gtx C, th *material.Theme, elapsed time.Duration) D {
+	laps := a.watch.Laps()
+	if len(laps) == 0 {
+		return D{}
+	}
+	// The current lap ends at the elapsed time.
+	ends := append(laps[:len(laps):len(laps)], elapsed)
+	return a.laps.Layout(gtx, len(ends), func(gtx C, i int) D {
+		n := len(ends) - 1 - i
+		start := time.Duration(0)
+		if n > 0 {
+			start = ends[n-1]
+		}
+		return layout.Flex{}.Layout(gtx,
+			layout.Flexed(1, material.Body1(th, fmt.Sprintf("Lap %d", n+1)).Layout),
+			layout.Flexed(1, material.Body1(th, formatDuration(ends[n]-start)).Layout),
+			layout.Flexed(1, material.Body1(th, formatDuration(ends[n])).Layout),
+		)
+	})
+}
+
+func (a *App) layoutTimer(gtx C, th *material.Theme) D {
+	left := a.countdown.Remaining(gtx.Now)
+	d := dial(th,
+		Hand{Turn: float32(left.Minutes()) / 60, Length: 0.8, Width: 0.015, Color: accent},
+	)
+	d.Progress = 1 - a.countdown.Progress(gtx.Now)
+	run := "Start"
+	if a.countdown.Running() {
+		run = "Pause"
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+	return layoutPanel(gtx, th, d, formatCountdown(left), func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				if !a.finished {
+					return D{}
+				}
+				l := material.H6(th, "Time's up")
+				l.Color = errorColor
+				return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, l.Layout)
+			}),
+			layout.Rigid(func(gtx C) D {
+				return layoutButtons(gtx,
+					material.Button(th, &a.plusMin, "+1 min").Layout,
+					material.Button(th, &a.plus10, "+10 s").Layout,
+					material.Button(th, &a.run, run).Layout,
+					material.Button(th, &a.reset, "Reset").Layout,
+				)
+			}),
+		)
+	})
+}
+
+// layoutButtons lays out a row of buttons.
+func layoutButtons(gtx C, buttons ...layout.Widget) D {
+	var children []layout.FlexChild
+	for i, b := range buttons {
+		if i > 0 {
+			children = append(children, layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout))
+		}
+		children = append(children, layout.Rigid(b))
+	}
+	return layout.Flex{}.Layout(gtx, children...)
+}
+
+// formatCountdown formats the time left of a countdown in whole seconds,
+// rounded up so that it shows zero only at the end.
+func formatCountdown(d time.Duration) string {
+	s := int((d + time.Second - 1) / time.Second)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%02d:%02d", s/60, s%60)
+}
@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Stopwatch measures elapsed time with laps. Its methods take the current
+// time, to keep them simple to test.
+type Stopwatch struct {
+	running bool
+	// start is the time the stopwatch was last started, and elapsed the
+	// time measured before.
+	start   time.Time
+	elapsed time.Duration
+	// laps are the elapsed times at the end of each lap.
+	laps []time.Duration
+}
+
+func (s *Stopwatch) Running() bool {
+	return s.running
+}
+
+func (s *Stopwatch) Start(now time.Time) {
+	if s.running {
+		return
+	}
+	s.running = true
+	s.start = now
+}
+
+func (s *Stopwatch) Stop(now time.Time) {
+	if !s.running {
+		return
+	}
+	s.elapsed = s.Elapsed(now)
+	s.running = false
+}
+
+// Lap ends the current lap.
+func (s *Stopwatch) Lap(now time.Time) {
+	s.laps = append(s.laps, s.Elapsed(now))
+}
+
+// Laps returns the elapsed times at the end of each lap.
+func (s *Stopwatch) Laps() []time.Duration {
+	return s.laps
+}
+
+func (s *Stopwatch) Reset() {
+	*s = Stopwatch{}
+}
+
+func (s *Stopwatch) Elapsed(now time.Time) time.Duration {
+	if !s.running {
+		return s.elapsed
+	}
+	return s.elapsed + now.Sub(s.start)
+}
+
+// Countdown counts down from a duration.
+type Countdown struct {
+	duration time.Duration
+	running  bool
+	// end is the time a running countdown reaches zero, and remaining the
+	// time left of a paused one.
+	end       time.Time
+	remaining time.Duration
+}
+
+func (c *Countdown) Duration() time.Duration {
+	return c.duration
+}
+
+// Set sets the duration and resets the countdown.
+func (c *Countdown) Set(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	c.duration = d
+	c.Reset()
+}
+
+// Add adds to the duration and to the time left of a stopped countdown.
+func (c *Countdown) Add(d time.Duration) {
+	if c.running {
+		return
+	}
+	c.duration += d
+	c.remaining += d
+}
+
+func (c *Countdown) Running() bool {
+	return c.running
+}
+
+// Start starts or resumes the countdown, and returns the time left.
+func (c *Countdown) Start(now time.Time) time.Duration {
+	if !c.running && c.remaining > 0 {
+		c.running = true
+		c.end = now.Add(c.remaining)
+	}
+	return c.Remaining(now)
+}
+
+func (c *Countdown) Pause(now time.Time) {
+	if !c.running {
+		return
+	}
+	c.remaining = c.Remaining(now)
+	c.running = false
+}
+
+// Finish stops the countdown at zero.
+func (c *Countdown) Finish() {
+	c.running = false
+	c.remaining = 0
+}
+
+func (c *Countdown) Reset() {
+	c.running = false
+	c.remaining = c.duration
+}
+
+func (c *Countdown) Remaining(now time.Time) time.Duration {
+	if !c.running {
+		return c.remaining
+	}
+	if r := c.end.Sub(now); r > 0 {
+		return r
+	}
+	return 0
+}
+
+// Progress returns the fraction of the countdown done.
+func (c *Countdown) Progress(now time.Time) float32 {
+	if c.duration == 0 {
+		return 0
+	}
+	return 1 - float32(c.Remaining(now))/float32(c.duration)
+}
+
+// formatDuration formats d as minutes, seconds and hundredths, with hours
+// when needed.
+func formatDuration(d time.Duration) string {
+	cs := int(d / (10 * time.Millisecond))
+	h, m, s := cs/360000, cs/6000%60, cs/100%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d.%02d", h, m, s, cs%100)
+	}
+	return fmt.Sprintf("%02d:%02d.%02d", m, s, cs%100)
+}
@@ -0,0 +1,234 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import "strings"
+
+// ID identifies a character of a document: the Lamport time of its
+// insertion and the site inserting it. The zero ID is the start of the
+// document.
+type ID struct {
+	Seq  uint64 `json:"s"`
+	Site uint32 `json:"a"`
+}
+
+// Less orders IDs by time, and by site for insertions at the same time.
+func (id ID) Less(o ID) bool {
+	if id.Seq != o.Seq {
+		return id.Seq < o.Seq
+	}
+	return id.Site < o.Site
+}
+
+// Op is an edit of a document: the insertion of a character after
+// another, or the deletion of a character.
+type Op struct {
+	ID ID `json:"i"`
+	// Origin is the character an insertion follows.
+	Origin ID   `json:"o,omitempty"`
+	Char   rune `json:"c,omitempty"`
+	// Delete marks the deletion of the character ID.
+	Delete bool `json:"d,omitempty"`
+}
+
+type elem struct {
+	id      ID
+	origin  ID
+	char    rune
+	deleted bool
+}
+
+// Doc is a replicated text: a sequence of characters in the style of a
+// Replicated Growable Array. Every site applies the same operations in
+// any order respecting their causality and ends up with the same text.
+// Deleted characters are kept as tombstones, so that the operations
+// referring to them still apply.
+type Doc struct {
+	site  uint32
+	clock uint64
+	elems []elem
+	// pending are the operations waiting for the characters they refer
+	// to.
+	pending []Op
+}
+
+func NewDoc(site uint32) *Doc {
+	return &Doc{site: site}
+}
+
+// Text returns the text of the document.
+func (d *Doc) Text() string {
+	var b strings.Builder
+	for _, e := range d.elems {
+		if !e.deleted {
+			b.WriteRune(e.char)
+		}
+	}
+	return b.String()
+}
+
+// index returns the index of the character id, -1 for the start of the
+// document, or false if the document has no such character.
+func (d *Doc) index(id ID) (int, bool) {
+	if id == (ID{}) {
+		return -1, true
+	}
+	for i, e := range d.elems {
+		if e.id == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// visible returns the index of the element of the pos'th visible
+// character, or len(d.elems) past the end.
+func (d *Doc) visible(pos int) int {
+	for i, e := range d.elems {
+		if e.deleted {
+			continue
+		}
+		if pos == 0 {
+			return i
+		}
+		pos--
+	}
+	return len(d.elems)
+}
+
+// Anchor returns the ID of the character before position pos, counted
+// in characters, for keeping a position through the edits of others.
+func (d *Doc) Anchor(pos int) ID {
+	if pos <= 0 {
+		return ID{}
+	}
+	i := d.visible(pos - 1)
+	if i == len(d.elems) {
+		return d.last()
+	}
+	return d.elems[i].id
+}
+
+// last returns the ID of the last visible character.
+func (d *Doc) last() ID {
+	for i := len(d.elems) - 1; i >= 0; i-- {
+		if !d.elems[i].deleted {
+			return d.elems[i].id
+		}
+	}
+	return ID{}
+}
+
+// Pos returns the position after the character of an anchor, or before
+// where it was if deleted.
+func (d *Doc) Pos(anchor ID) int {
+	i, ok := d.index(anchor)
+	if !ok {
+		return 0
+	}
+	pos := 0
+	for _, e := range d.elems[:i+1] {
+		if !e.deleted {
+			pos++
+		}
+	}
+	return pos
+}
+
+// Insert inserts text at position pos, returning the operations to send
+// to the other sites.
+func (d *Doc) Insert(pos int, text string) []Op {
+	var ops []Op
+	origin := d.Anchor(pos)
+	for _, c := range text {
+		d.clock++
+		op := Op{ID: ID{Seq: d.clock, Site: d.site}, Origin: origin, Char: c}
+		d.apply(op)
+		ops = append(ops, op)
+		origin = op.ID
+	}
+	return ops
+}
+
+// Delete deletes n characters from position pos, returning the
+// operations to send to the other sites.
+func (d *Doc) Delete(pos, n int) []Op {
+	var ops []Op
+	for i := d.visible(pos); i < len(d.elems) && n > 0; i++ {
+		if d.elems[i].deleted {
+			continue
+		}
+		d.elems[i].deleted = true
+		ops = append(ops, Op{ID: d.elems[i].id, Delete: true})
+		n--
+	}
+	return ops
+}
+
+// Apply applies the operations of another site. Operations already
+// applied are ignored, and operations referring to characters not yet
+// inserted wait for them.
+func (d *Doc) Apply(ops []Op) {
+	d.pending = append(d.pending, ops...)
+	for {
+		n := len(d.pending)
+		rest := d.pending[:0]
+		for _, op := range d.pending {
+			if !d.apply(op) {
+				rest = append(rest, op)
+			}
+		}
+		d.pending = rest
+		if len(rest) == n || len(rest) == 0 {
+			return
+		}
+	}
+}
+
+// apply applies an operation, reporting false if it refers to a
+// character not in the document.
+func (d *Doc) apply(op Op) bool {
+	if op.ID.Seq > d.clock {
+		d.clock = op.ID.Seq
+	}
+	if op.Delete {
+		i, ok := d.index(op.ID)
+		if !ok || i < 0 {
+			return false
+		}
+		d.elems[i].deleted = true
+		return true
+	}
+	if _, ok := d.index(op.ID); ok {
+		return true
+	}
+	i, ok := d.index(op.Origin)
+	if !ok {
+		return false
+	}
+	// Skip the characters inserted after the same origin by later
+	// operations, and their successors, which are all later still.
+	i++
+	for i < len(d.elems) && op.ID.Less(d.elems[i].id) {
+		i++
+	}
+	d.elems = append(d.elems, elem{})
+	copy(d.elems[i+1:], d.elems[i:])
+	d.elems[i] = elem{id: op.ID, origin: op.Origin, char: op.Char}
+	return true
+}
+
+// Ops returns the operations recreating the document, for sites joining
+// late.
+func (d *Doc) Ops() []Op {
+	var ops []Op
+	for _, e := range d.elems {
+		ops = append(ops, Op{ID: e.id, Origin: e.origin, Char: e.char})
+	}
+	for _, e := range d.elems {
+		if e.deleted {
+			ops = append(ops, Op{ID: e.id, Delete: true})
+		}
+	}
+	return ops
+}
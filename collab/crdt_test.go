@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestConcurrentInsert(t *testing.T) {
+	a, b := NewDoc(1), NewDoc(2)
+	b.Apply(a.Insert(0, "ac"))
+	// Both insert between a and c at once.
+	opsA := a.Insert(1, "XY")
+	opsB := b.Insert(1, "b")
+	a.Apply(opsB)
+	b.Apply(opsA)
+	if a.Text() != b.Text() {
+		t.Fatalf("texts differ: %q and %q", a.Text(), b.Text())
+	}
+	// Runs of characters typed by one site stay together.
+	if got := a.Text(); got != "abXYc" && got != "aXYbc" {
+		t.Errorf("got %q, want the insertions unmixed", got)
+	}
+}
+
+func TestConvergence(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		rnd := rand.New(rand.NewSource(seed))
+		const sites = 3
+		docs := make([]*Doc, sites)
+		// sent[i] are the operations of site i; received[i][j] is
+		// how many of them site j applied.
+		sent := make([][]Op, sites)
+		var received [sites][sites]int
+		for i := range docs {
+			docs[i] = NewDoc(uint32(i + 1))
+		}
+		for step := 0; step < 300; step++ {
+			i := rnd.Intn(sites)
+			d := docs[i]
+			n := len([]rune(d.Text()))
+			switch rnd.Intn(3) {
+			case 0:
+				// Receive some operations of another site, in
+				// order.
+				j := rnd.Intn(sites)
+				if j == i {
+					break
+				}
+				k := received[j][i] + rnd.Intn(len(sent[j])-received[j][i]+1)
+				d.Apply(sent[j][received[j][i]:k])
+				received[j][i] = k
+			case 1:
+				if n > 0 {
+					sent[i] = append(sent[i], d.Delete(rnd.Intn(n), 1+rnd.Intn(3))...)
+					break
+				}
+				fallthrough
+			default:
+				sent[i] = append(sent[i], d.Insert(rnd.Intn(n+1), string(rune('a'+rnd.Intn(26)))+"z")...)
+			}
+		}
+		for i, d := range docs {
+			for j := range docs {
+				if j != i {
+					d.Apply(sent[j][received[j][i]:])
+				}
+			}
+		}
+		for i := 1; i < sites; i++ {
+			if got, want := docs[i].Text(), docs[0].Text(); got != want {
+				t.Fatalf("seed %d: site %d has %q, site 1 has %q", seed, i+1, got, want)
+			}
+		}
+		late := NewDoc(9)
+		late.Apply(docs[0].Ops())
+		if got, want := late.Text(), docs[0].Text(); got != want {
+			t.Fatalf("seed %d: document recreated as %q, want %q", seed, got, want)
+		}
+	}
+}
+
+func TestOutOfOrder(t *testing.T) {
+	a, b := NewDoc(1), NewDoc(2)
+	ops := a.Insert(0, "hello")
+	ops = append(ops, a.Delete(0, 1)...)
+	// Deliver in reverse; the operations wait for their origins.
+	for i := len(ops) - 1; i >= 0; i-- {
+		b.Apply(ops[i : i+1])
+	}
+	if got := b.Text(); got != "ello" {
+		t.Errorf("got %q, want %q", got, "ello")
+	}
+}
+
+func TestAnchor(t *testing.T) {
+	a, b := NewDoc(1), NewDoc(2)
+	b.Apply(a.Insert(0, "hello world"))
+	// b's caret after "hello".
+	caret := b.Anchor(5)
+	b.Apply(a.Insert(0, ">> "))
+	if got := b.Pos(caret); got != 8 {
+		t.Errorf("caret at %d after insertion before it, want 8", got)
+	}
+	b.Apply(a.Delete(6, 2))
+	if got := b.Pos(caret); got != 6 {
+		t.Errorf("caret at %d after its character was deleted, want 6", got)
+	}
+	if got := b.Pos(b.Anchor(0)); got != 0 {
+		t.Errorf("start anchored at %d", got)
+	}
+}
@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"net"
+	"net/http"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// Peer is a site editing the document, and its selection.
+type Peer struct {
+	Site uint32 `json:"site"`
+	Name string `json:"name"`
+	// Caret and Anchor are the ends of the selection, as the characters
+	// before them.
+	Caret  ID `json:"caret"`
+	Anchor ID `json:"anchor"`
+}
+
+// Message types. A site connecting sends hello with its operations, for
+// the document to survive the hub, and the hub answers sync with all
+// operations and peers. After that, sites send ops and cursor messages
+// to the hub, which relays them to the other sites along with leave
+// messages for sites gone.
+const (
+	msgHello  = "hello"
+	msgSync   = "sync"
+	msgOps    = "ops"
+	msgCursor = "cursor"
+	msgLeave  = "leave"
+)
+
+type message struct {
+	Type  string `json:"type"`
+	Ops   []Op   `json:"ops,omitempty"`
+	Peer  *Peer  `json:"peer,omitempty"`
+	Peers []Peer `json:"peers,omitempty"`
+	// Site is the site leaving.
+	Site uint32 `json:"site,omitempty"`
+}
+
+// hub relays the operations and selections of the sites connected to
+// it, and keeps the operations for sites connecting later. It doesn't
+// interpret the operations, only drops those it already has.
+type hub struct {
+	mu      sync.Mutex
+	ops     []Op
+	applied map[opKey]bool
+	clients map[*hubClient]*Peer
+}
+
+type opKey struct {
+	id     ID
+	delete bool
+}
+
+type hubClient struct {
+	ws  *websocket.Conn
+	out chan message
+}
+
+func newHub() *hub {
+	return &hub{
+		applied: make(map[opKey]bool),
+		clients: make(map[*hubClient]*Peer),
+	}
+}
+
+// listenHub serves a hub on addr, failing if the address is in use by
+// the hub of another instance.
+func listenHub(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	go http.Serve(l, newHub().handler())
+	return nil
+}
+
+func (h *hub) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/doc", websocket.Handler(h.serve))
+	return mux
+}
+
+func (h *hub) serve(ws *websocket.Conn) {
+	defer ws.Close()
+	var hello message
+	if err := websocket.JSON.Receive(ws, &hello); err != nil || hello.Type != msgHello || hello.Peer == nil {
+		return
+	}
+	c := &hubClient{ws: ws, out: make(chan message, 256)}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case m := <-c.out:
+				if websocket.JSON.Send(ws, m) != nil {
+					ws.Close()
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	h.mu.Lock()
+	fresh := h.merge(hello.Ops)
+	welcome := message{Type: msgSync, Ops: append([]Op(nil), h.ops...)}
+	for _, p := range h.clients {
+		welcome.Peers = append(welcome.Peers, *p)
+	}
+	peer := *hello.Peer
+	h.clients[c] = &peer
+	c.out <- welcome
+	h.broadcast(c, message{Type: msgOps, Ops: fresh})
+	h.broadcast(c, message{Type: msgCursor, Peer: &peer})
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, c)
+		h.broadcast(c, message{Type: msgLeave, Site: peer.Site})
+		h.mu.Unlock()
+	}()
+	for {
+		var m message
+		if err := websocket.JSON.Receive(ws, &m); err != nil {
+			return
+		}
+		h.mu.Lock()
+		switch m.Type {
+		case msgOps:
+			if fresh := h.merge(m.Ops); len(fresh) > 0 {
+				h.broadcast(c, message{Type: msgOps, Ops: fresh})
+			}
+		case msgCursor:
+			if m.Peer != nil && m.Peer.Site == peer.Site {
+				peer = *m.Peer
+				h.broadcast(c, message{Type: msgCursor, Peer: &peer})
+			}
+		}
+		h.mu.Unlock()
+	}
+}
+
+// merge adds the operations not yet kept, returning them.
+func (h *hub) merge(ops []Op) []Op {
+	var fresh []Op
+	for _, op := range ops {
+		k := opKey{op.ID, op.Delete}
+		if !h.applied[k] {
+			h.applied[k] = true
+			h.ops = append(h.ops, op)
+			fresh = append(fresh, op)
+		}
+	}
+	return fresh
+}
+
+// broadcast queues a message to the clients but from. Clients too slow
+// to keep up are disconnected, and catch up when they connect again.
+func (h *hub) broadcast(from *hubClient, m message) {
+	if m.Type == msgOps && len(m.Ops) == 0 {
+		return
+	}
+	for c := range h.clients {
+		if c == from {
+			continue
+		}
+		select {
+		case c.out <- m:
+		default:
+			c.ws.Close()
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/websocket"
+)
+
+func dialHub(t *testing.T, srv *httptest.Server, p Peer, ops []Op) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/doc"
+	ws, err := websocket.Dial(url, "", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	if err := websocket.JSON.Send(ws, message{Type: msgHello, Peer: &p, Ops: ops}); err != nil {
+		t.Fatal(err)
+	}
+	return ws
+}
+
+func receive(t *testing.T, ws *websocket.Conn, typ string) message {
+	t.Helper()
+	for {
+		var m message
+		if err := websocket.JSON.Receive(ws, &m); err != nil {
+			t.Fatal(err)
+		}
+		if m.Type == typ {
+			return m
+		}
+	}
+}
+
+func TestHub(t *testing.T) {
+	srv := httptest.NewServer(newHub().handler())
+	defer srv.Close()
+
+	a := NewDoc(1)
+	wsA := dialHub(t, srv, Peer{Site: 1, Name: "A"}, a.Insert(0, "hi"))
+	receive(t, wsA, msgSync)
+
+	// A site connecting later gets the document and the peers.
+	b := NewDoc(2)
+	wsB := dialHub(t, srv, Peer{Site: 2, Name: "B"}, nil)
+	m := receive(t, wsB, msgSync)
+	b.Apply(m.Ops)
+	if got := b.Text(); got != "hi" {
+		t.Fatalf("synced text %q, want %q", got, "hi")
+	}
+	if len(m.Peers) != 1 || m.Peers[0].Name != "A" {
+		t.Fatalf("synced peers %+v, want A", m.Peers)
+	}
+
+	// Operations and leaving are relayed to the others.
+	if err := websocket.JSON.Send(wsB, message{Type: msgOps, Ops: b.Insert(2, "!")}); err != nil {
+		t.Fatal(err)
+	}
+	m = receive(t, wsA, msgOps)
+	a.Apply(m.Ops)
+	if got := a.Text(); got != "hi!" {
+		t.Fatalf("relayed text %q, want %q", got, "hi!")
+	}
+	wsB.Close()
+	if m := receive(t, wsA, msgLeave); m.Site != 2 {
+		t.Fatalf("site %d left, want 2", m.Site)
+	}
+}
@@ -0,0 +1,458 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program edits a document together with other instances of
+// itself, synchronized over a local WebSocket. The first instance serves
+// the hub the others connect to; every instance keeps the document as a
+// CRDT, so edits made at once merge without conflicts, and the hub passes
+// on the changes and the selections of the others, drawn in their colors.
+// When the instance serving the hub quits, another takes over and the
+// document carries on.
+//
+// Run the program twice, or open two windows with -windows 2.
+//
+// Usage:
+//
+//	go run ./collab [-name Ada] [-windows 2] [-addr 127.0.0.1:7341]
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"golang.org/x/image/math/fixed"
+)
+
+var (
+	addrFlag    = flag.String("addr", "127.0.0.1:7341", "address of the hub, served by the first instance")
+	nameFlag    = flag.String("name", "", "name shown to the others (default a guest name)")
+	windowsFlag = flag.Int("windows", 1, "number of windows, each editing as another site")
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+// peerColors are the colors of the sites, by site.
+var peerColors = []color.NRGBA{
+	{R: 0xe5, G: 0x39, B: 0x35, A: 0xff},
+	{R: 0x1e, G: 0x88, B: 0xe5, A: 0xff},
+	{R: 0x43, G: 0xa0, B: 0x47, A: 0xff},
+	{R: 0x8e, G: 0x24, B: 0xaa, A: 0xff},
+	{R: 0xfb, G: 0x8c, B: 0x00, A: 0xff},
+	{R: 0x00, G: 0x89, B: 0x7b, A: 0xff},
+}
+
+var errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+
+func peerColor(site uint32) color.NRGBA {
+	return peerColors[site%uint32(len(peerColors))]
+}
+
+func main() {
+	flag.Parse()
+	rand.Seed(time.Now().UnixNano())
+	var wg sync.WaitGroup
+	for i := 0; i < *windowsFlag; i++ {
+		site := rand.Uint32()%1e6 + 1
+		name := *nameFlag
+		if name == "" {
+			name = fmt.Sprintf("Guest %d", site%1000)
+		} else if *windowsFlag > 1 {
+			name = fmt.Sprintf("%s %d", name, i+1)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := app.NewWindow(
+				app.Title("Collaborative Editor – "+name),
+				app.Size(unit.Dp(720), unit.Dp(560)),
+			)
+			if err := loop(w, site, name); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+type App struct {
+	site uint32
+	name string
+	doc  *Doc
+	// text is the text of doc, as last set in the editor.
+	text string
+
+	conn    *conn
+	hosting bool
+	err     error
+	peers   map[uint32]Peer
+	// sent is the selection last sent to the hub.
+	sent Peer
+
+	editor widget.Editor
+	list   layout.List
+}
+
+func loop(w *app.Window, site uint32, name string) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		site:  site,
+		name:  name,
+		doc:   NewDoc(site),
+		peers: make(map[uint32]Peer),
+		list:  layout.List{Axis: layout.Vertical},
+	}
+	a.editor.Focus()
+	events := make(chan interface{})
+	stop := make(chan struct{})
+	defer close(stop)
+	go connect(*addrFlag, events, stop)
+	var ops op.Ops
+	for {
+		select {
+		case e := <-events:
+			a.handle(e)
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				if a.conn != nil {
+					a.conn.Close()
+				}
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update()
+				a.Layout(gtx, th)
+				// Take in the edits of this frame before sending the
+				// selection, whose offsets are into the edited text.
+				a.update()
+				a.sendSelection()
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+func (a *App) handle(e interface{}) {
+	switch e := e.(type) {
+	case connectedEvent:
+		a.conn, a.hosting, a.err = e.conn, e.hosting, nil
+		a.sent = a.selection()
+		a.conn.Send(message{Type: msgHello, Peer: &a.sent, Ops: a.doc.Ops()})
+	case disconnectedEvent:
+		if e.conn == nil || e.conn == a.conn {
+			a.conn, a.err = nil, e.err
+			a.peers = make(map[uint32]Peer)
+		}
+	case messageEvent:
+		if e.conn != a.conn {
+			return
+		}
+		m := e.msg
+		switch m.Type {
+		case msgSync:
+			a.peers = make(map[uint32]Peer)
+			for _, p := range m.Peers {
+				a.peers[p.Site] = p
+			}
+			a.applyRemote(m.Ops)
+		case msgOps:
+			a.applyRemote(m.Ops)
+		case msgCursor:
+			if m.Peer != nil && m.Peer.Site != a.site {
+				a.peers[m.Peer.Site] = *m.Peer
+			}
+		case msgLeave:
+			delete(a.peers, m.Site)
+		}
+	}
+}
+
+// applyRemote applies the operations of others, keeping the selection
+// on the same characters.
+func (a *App) applyRemote(ops []Op) {
+	caret, anchor := a.selectionAnchors()
+	a.doc.Apply(ops)
+	text := a.doc.Text()
+	if text == a.text {
+		return
+	}
+	a.text = text
+	a.editor.SetText(text)
+	a.editor.SetCaret(byteOffset(text, a.doc.Pos(caret)), byteOffset(text, a.doc.Pos(anchor)))
+}
+
+func (a *App) update() {
+	for _, e := range a.editor.Events() {
+		if _, ok := e.(widget.ChangeEvent); ok {
+			a.localEdit()
+		}
+	}
+}
+
+// localEdit turns the change of the editor text into operations, by
+// the text replaced between the common prefix and suffix.
+func (a *App) localEdit() {
+	old, cur := []rune(a.text), []rune(a.editor.Text())
+	p := 0
+	for p < len(old) && p < len(cur) && old[p] == cur[p] {
+		p++
+	}
+	s := 0
+	for s < len(old)-p && s < len(cur)-p && old[len(old)-1-s] == cur[len(cur)-1-s] {
+		s++
+	}
+	ops := a.doc.Delete(p, len(old)-p-s)
+	ops = append(ops, a.doc.Insert(p, string(cur[p:len(cur)-s]))...)
+	a.text = string(cur)
+	if a.conn != nil && len(ops) > 0 {
+		a.conn.Send(message{Type: msgOps, Ops: ops})
+	}
+}
+
+// selectionAnchors returns the anchors of the ends of the selection.
+func (a *App) selectionAnchors() (caret, anchor ID) {
+	start, end := a.editor.Selection()
+	return a.doc.Anchor(runeOffset(a.text, start)), a.doc.Anchor(runeOffset(a.text, end))
+}
+
+func (a *App) selection() Peer {
+	caret, anchor := a.selectionAnchors()
+	return Peer{Site: a.site, Name: a.name, Caret: caret, Anchor: anchor}
+}
+
+// sendSelection sends the selection to the others if it moved.
+func (a *App) sendSelection() {
+	if a.conn == nil {
+		return
+	}
+	if p := a.selection(); p != a.sent {
+		a.sent = p
+		a.conn.Send(message{Type: msgCursor, Peer: &p})
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return a.layoutBar(gtx, th)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return a.list.Layout(gtx, 1, func(gtx C, _ int) D {
+				return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+					return a.layoutEditor(gtx, th)
+				})
+			})
+		}),
+	)
+}
+
+func (a *App) layoutBar(gtx C, th *material.Theme) D {
+	var status string
+	switch {
+	case a.conn == nil && a.err != nil:
+		status = fmt.Sprintf("Offline, reconnecting: %v", a.err)
+	case a.conn == nil:
+		status = "Connecting…"
+	case a.hosting:
+		status = "Hosting the session at " + *addrFlag
+	default:
+		status = "Joined the session at " + *addrFlag
+	}
+	peers := []Peer{{Site: a.site, Name: a.name + " (you)"}}
+	var others []Peer
+	for _, p := range a.peers {
+		others = append(others, p)
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i].Name < others[j].Name })
+	peers = append(peers, others...)
+	children := []layout.FlexChild{
+		layout.Flexed(1, func(gtx C) D {
+			l := material.Body2(th, status)
+			l.MaxLines = 1
+			if a.conn == nil && a.err != nil {
+				l.Color = errorColor
+			}
+			return l.Layout(gtx)
+		}),
+	}
+	for _, p := range peers {
+		p := p
+		children = append(children, layout.Rigid(func(gtx C) D {
+			return layout.Inset{Left: unit.Dp(12)}.Layout(gtx, func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						size := gtx.Px(unit.Dp(10))
+						paint.FillShape(gtx.Ops, peerColor(p.Site), clip.UniformRRect(f32.Rectangle{Max: layout.FPt(image.Pt(size, size))}, float32(size)/2).Op(gtx.Ops))
+						return D{Size: image.Pt(size, size)}
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+					layout.Rigid(material.Body2(th, p.Name).Layout),
+				)
+			})
+		}))
+	}
+	return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx, children...)
+	})
+}
+
+func (a *App) layoutEditor(gtx C, th *material.Theme) D {
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	ed := material.Editor(th, &a.editor, "Type here; the others see it as you type.")
+	dims := ed.Layout(gtx)
+	// Lay out the text as the editor does, to find where the selections
+	// of the others are.
+	lines := th.Shaper.LayoutString(ed.Font, fixed.I(gtx.Px(ed.TextSize)), gtx.Constraints.Max.X, a.text)
+	for _, p := range a.peers {
+		a.layoutPeer(gtx, th, lines, p)
+	}
+	return dims
+}
+
+// layoutPeer draws the selection of another site, with its caret and
+// name.
+func (a *App) layoutPeer(gtx C, th *material.Theme, lines []text.Line, p Peer) {
+	if len(lines) == 0 {
+		return
+	}
+	col := peerColor(p.Site)
+	start := byteOffset(a.text, a.doc.Pos(p.Anchor))
+	end := byteOffset(a.text, a.doc.Pos(p.Caret))
+	if start > end {
+		start, end = end, start
+	}
+	sel := col
+	sel.A = 0x40
+	geom := lineGeometry(lines)
+	s, e := position(lines, start), position(lines, end)
+	for i := s.line; i <= e.line && start != end; i++ {
+		x0, x1 := 0, geom[i].width
+		if i == s.line {
+			x0 = s.x
+		}
+		if i == e.line {
+			x1 = e.x
+		}
+		paint.FillShape(gtx.Ops, sel, clip.Rect{Min: image.Pt(x0, geom[i].top), Max: image.Pt(x1, geom[i].bottom)}.Op())
+	}
+	// The caret, with the name above it.
+	c := position(lines, byteOffset(a.text, a.doc.Pos(p.Caret)))
+	g := geom[c.line]
+	w := gtx.Px(unit.Dp(2))
+	paint.FillShape(gtx.Ops, col, clip.Rect{Min: image.Pt(c.x-w/2, g.top), Max: image.Pt(c.x-w/2+w, g.bottom)}.Op())
+
+	stack := op.Save(gtx.Ops)
+	macro := op.Record(gtx.Ops)
+	l := material.Caption(th, p.Name)
+	l.Color = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	cgtx := gtx
+	cgtx.Constraints = layout.Constraints{Max: image.Pt(gtx.Constraints.Max.X, gtx.Constraints.Max.X)}
+	dims := layout.Inset{Left: unit.Dp(3), Right: unit.Dp(3)}.Layout(cgtx, l.Layout)
+	call := macro.Stop()
+	op.Offset(layout.FPt(image.Pt(c.x-w/2, g.top-dims.Size.Y))).Add(gtx.Ops)
+	paint.FillShape(gtx.Ops, col, clip.Rect{Max: dims.Size}.Op())
+	call.Add(gtx.Ops)
+	stack.Load()
+}
+
+// linePos is the position of an offset in laid out text.
+type linePos struct {
+	line int
+	x    int
+}
+
+// lineGeom is the vertical extent and width of a line.
+type lineGeom struct {
+	top, bottom, width int
+}
+
+func lineGeometry(lines []text.Line) []lineGeom {
+	geom := make([]lineGeom, len(lines))
+	var y int
+	var prevDesc fixed.Int26_6
+	for i, l := range lines {
+		if i == 0 {
+			y = l.Ascent.Ceil()
+		} else {
+			y += (prevDesc + l.Ascent).Ceil()
+		}
+		prevDesc = l.Descent
+		geom[i] = lineGeom{top: y - l.Ascent.Ceil(), bottom: y + l.Descent.Ceil(), width: l.Width.Ceil()}
+	}
+	return geom
+}
+
+// position returns the line and x coordinate of a byte offset of the
+// text of lines, placing offsets at soft line breaks at the start of
+// the next line, as widget.Editor does.
+func position(lines []text.Line, offset int) linePos {
+	idx := 0
+	for i, l := range lines {
+		var x fixed.Int26_6
+		adv := l.Layout.Advances
+		col := 0
+		for _, r := range l.Layout.Text {
+			if idx >= offset || col >= len(adv) {
+				break
+			}
+			x += adv[col]
+			idx += utf8.RuneLen(r)
+			col++
+		}
+		if idx >= offset && (col < len(adv) || i == len(lines)-1 || idx > offset) {
+			return linePos{line: i, x: x.Round()}
+		}
+		if i == len(lines)-1 {
+			return linePos{line: i, x: x.Round()}
+		}
+	}
+	return linePos{}
+}
+
+// runeOffset converts a byte offset of s to a rune offset.
+func runeOffset(s string, b int) int {
+	if b > len(s) {
+		b = len(s)
+	}
+	return utf8.RuneCountInString(s[:b])
+}
+
+// byteOffset converts a rune offset of s to a byte offset.
+func byteOffset(s string, r int) int {
+	for i := range s {
+		if r == 0 {
+			return i
+		}
+		r--
+	}
+	return len(s)
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// conn is a connection of a site to the hub.
+type conn struct {
+	ws   *websocket.Conn
+	out  chan message
+	done chan struct{}
+	once sync.Once
+}
+
+// connectedEvent reports a new connection, over which the site is to
+// send its hello.
+type connectedEvent struct {
+	conn *conn
+	// hosting reports whether this instance serves the hub.
+	hosting bool
+}
+
+// disconnectedEvent reports the loss of the connection.
+type disconnectedEvent struct {
+	conn *conn
+	err  error
+}
+
+// messageEvent is a message from the hub.
+type messageEvent struct {
+	conn *conn
+	msg  message
+}
+
+// hosting is set once an instance serves the hub, to not try again.
+var (
+	hostingMu sync.Mutex
+	hosting   bool
+)
+
+// connect keeps a connection to the hub at addr, serving the hub
+// itself if no other instance does, until stop is closed. When the
+// instance serving the hub quits, one of the others takes over.
+func connect(addr string, events chan<- interface{}, stop <-chan struct{}) {
+	send := func(e interface{}) bool {
+		select {
+		case events <- e:
+			return true
+		case <-stop:
+			return false
+		}
+	}
+	for {
+		hostingMu.Lock()
+		if !hosting {
+			hosting = listenHub(addr) == nil
+		}
+		host := hosting
+		hostingMu.Unlock()
+		ws, err := websocket.Dial("ws://"+addr+"/doc", "", "http://"+addr+"/")
+		if err != nil {
+			if !send(disconnectedEvent{err: err}) {
+				return
+			}
+			select {
+			case <-stop:
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		c := &conn{ws: ws, out: make(chan message, 256), done: make(chan struct{})}
+		go c.write()
+		if !send(connectedEvent{conn: c, hosting: host}) {
+			c.Close()
+			return
+		}
+		for {
+			var m message
+			if err = websocket.JSON.Receive(ws, &m); err != nil {
+				break
+			}
+			if !send(messageEvent{conn: c, msg: m}) {
+				c.Close()
+				return
+			}
+		}
+		c.Close()
+		if !send(disconnectedEvent{conn: c, err: err}) {
+			return
+		}
+	}
+}
+
+func (c *conn) write() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case m := <-c.out:
+			if err := websocket.JSON.Send(c.ws, m); err != nil {
+				c.Close()
+				return
+			}
+		}
+	}
+}
+
+// Send queues a message to the hub. If the queue is full, the
+// connection is closed; the site catches up when connecting again.
+func (c *conn) Send(m message) {
+	select {
+	case c.out <- m:
+	case <-c.done:
+	default:
+		c.Close()
+	}
+}
+
+func (c *conn) Close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.ws.Close()
+	})
+}
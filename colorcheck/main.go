@@ -0,0 +1,277 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program renders reference color patterns to verify the color
+// handling of a GPU backend and display. Each pattern describes what to
+// look for on screen, and the self-check renders the same patterns
+// offscreen, reads the pixels back and compares them to the colors
+// computed for a correct sRGB pipeline: sRGB encoded framebuffers,
+// blending and gradients in linear light.
+//
+// The self-check covers the renderer and the driver. The patterns in the
+// window also go through the window surface and the display, so compare
+// them with the descriptions; a window surface missing sRGB encoding, as
+// on EGL drivers without EGL_KHR_gl_colorspace, shows too dark gradients
+// and blends.
+//
+// Patterns are drawn in pixels, not dp, so that no scaling blurs them.
+//
+// Usage:
+//
+//	go run ./colorcheck [-check]
+//
+// With -check, the program runs the self-check without a window, prints
+// the results and exits with status 1 on failures. Attach its output to
+// color bug reports.
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/gpu/headless"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var checkOnly = flag.Bool("check", false, "run the self-check, print the results and exit")
+
+func main() {
+	flag.Parse()
+	if *checkOnly {
+		res, err := selfCheck()
+		if err != nil {
+			log.Fatal(err)
+		}
+		failed := 0
+		for _, r := range res {
+			status := "ok  "
+			if !r.ok() {
+				status = "FAIL"
+				failed++
+			}
+			fmt.Printf("%s %s\n", status, r)
+		}
+		fmt.Printf("%d of %d probes failed\n", failed, len(res))
+		if failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("Color Check"),
+			app.Size(unit.Dp(640), unit.Dp(800)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var (
+	errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	okColor    = color.NRGBA{R: 0x38, G: 0x8e, B: 0x3c, A: 0xff}
+)
+
+// checked is the outcome of a self-check.
+type checked struct {
+	res []result
+	err error
+}
+
+// selfCheck renders all patterns offscreen, one below the other, and
+// evaluates their probes.
+func selfCheck() ([]result, error) {
+	sz := image.Pt(patternSize.X, patternSize.Y*len(patterns))
+	w, err := headless.NewWindow(sz.X, sz.Y)
+	if err != nil {
+		return nil, err
+	}
+	defer w.Release()
+	var ops op.Ops
+	for i, p := range patterns {
+		st := op.Save(&ops)
+		op.Offset(f32.Pt(0, float32(i*patternSize.Y))).Add(&ops)
+		p.draw(&ops, patternSize)
+		st.Load()
+	}
+	if err := w.Frame(&ops); err != nil {
+		return nil, err
+	}
+	img, err := w.Screenshot()
+	if err != nil {
+		return nil, err
+	}
+	var res []result
+	for i, p := range patterns {
+		res = append(res, evaluate(img, image.Pt(0, i*patternSize.Y), p)...)
+	}
+	return res, nil
+}
+
+type App struct {
+	run     widget.Clickable
+	running bool
+	// results maps pattern names to their probes, after a self-check.
+	results map[string][]result
+	err     error
+	checks  chan checked
+	list    layout.List
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		checks: make(chan checked, 1),
+		list:   layout.List{Axis: layout.Vertical},
+	}
+	var ops op.Ops
+	for {
+		select {
+		case c := <-a.checks:
+			a.running = false
+			a.err = c.err
+			a.results = make(map[string][]result)
+			for _, r := range c.res {
+				a.results[r.pattern] = append(a.results[r.pattern], r)
+			}
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update()
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+func (a *App) update() {
+	for a.run.Clicked() {
+		if a.running {
+			continue
+		}
+		a.running = true
+		go func() {
+			res, err := selfCheck()
+			a.checks <- checked{res: res, err: err}
+		}()
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						if a.running {
+							gtx = gtx.Disabled()
+						}
+						return material.Button(th, &a.run, "Run self-check").Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+					layout.Flexed(1, a.layoutSummary(th)),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+			layout.Flexed(1, func(gtx C) D {
+				return a.list.Layout(gtx, len(patterns), func(gtx C, i int) D {
+					return layout.Inset{Bottom: unit.Dp(20)}.Layout(gtx, func(gtx C) D {
+						return a.layoutPattern(gtx, th, patterns[i])
+					})
+				})
+			}),
+		)
+	})
+}
+
+func (a *App) layoutSummary(th *material.Theme) layout.Widget {
+	return func(gtx C) D {
+		switch {
+		case a.running:
+			return material.Body1(th, "Checking…").Layout(gtx)
+		case a.err != nil:
+			l := material.Body1(th, a.err.Error())
+			l.Color = errorColor
+			return l.Layout(gtx)
+		case a.results == nil:
+			return material.Body1(th, "Compare the patterns with their descriptions, or run the self-check.").Layout(gtx)
+		}
+		n, failed := 0, 0
+		for _, rs := range a.results {
+			for _, r := range rs {
+				n++
+				if !r.ok() {
+					failed++
+				}
+			}
+		}
+		l := material.Body1(th, fmt.Sprintf("All %d probes passed.", n))
+		l.Color = okColor
+		if failed > 0 {
+			l.Text = fmt.Sprintf("%d of %d probes failed.", failed, n)
+			l.Color = errorColor
+		}
+		return l.Layout(gtx)
+	}
+}
+
+func (a *App) layoutPattern(gtx C, th *material.Theme, p pattern) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(material.H6(th, p.name).Layout),
+		layout.Rigid(material.Body2(th, p.desc).Layout),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+		layout.Rigid(func(gtx C) D {
+			// Offsets are whole pixels here, keeping the pattern pixels
+			// aligned to the screen.
+			p.draw(gtx.Ops, patternSize)
+			return D{Size: patternSize}
+		}),
+		layout.Rigid(func(gtx C) D {
+			rs, ok := a.results[p.name]
+			if !ok {
+				return D{}
+			}
+			var children []layout.FlexChild
+			for _, r := range rs {
+				r := r
+				children = append(children, layout.Rigid(func(gtx C) D {
+					l := material.Caption(th, "✓ "+r.String())
+					l.Color = okColor
+					if !r.ok() {
+						l.Text = "✗ " + r.String()
+						l.Color = errorColor
+					}
+					return l.Layout(gtx)
+				}))
+			}
+			return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+			})
+		}),
+	)
+}
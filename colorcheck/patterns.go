@@ -0,0 +1,250 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+)
+
+// patternSize is the size in pixels of every pattern.
+var patternSize = image.Pt(256, 48)
+
+// pattern is a test pattern and the colors it must render.
+type pattern struct {
+	name string
+	// desc tells what to look for on screen.
+	desc string
+	draw func(ops *op.Ops, sz image.Point)
+	// probes returns the pixels to check.
+	probes func(sz image.Point) []probe
+}
+
+// probe is the expected color of a pixel. Renderers may differ from the
+// exact result by up to tol in each channel.
+type probe struct {
+	at   image.Point
+	want color.NRGBA
+	tol  uint8
+}
+
+// result is the color read back at a probe.
+type result struct {
+	pattern string
+	probe
+	got color.NRGBA
+}
+
+func (r result) ok() bool {
+	near := func(a, b uint8) bool {
+		d := int(a) - int(b)
+		return d >= -int(r.tol) && d <= int(r.tol)
+	}
+	return near(r.got.R, r.want.R) && near(r.got.G, r.want.G) && near(r.got.B, r.want.B)
+}
+
+func (r result) String() string {
+	return fmt.Sprintf("%s at %d,%d: got %s, want %s ±%d", r.pattern, r.at.X, r.at.Y, hex(r.got), hex(r.want), r.tol)
+}
+
+func hex(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+var (
+	black = color.NRGBA{A: 0xff}
+	white = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+)
+
+// fromSRGB converts an sRGB encoded channel to linear light.
+func fromSRGB(c uint8) float64 {
+	v := float64(c) / 0xff
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// toSRGB converts linear light to an sRGB encoded channel.
+func toSRGB(v float64) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 1:
+		return 0xff
+	case v <= 0.0031308:
+		v *= 12.92
+	default:
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+	return uint8(v*0xff + 0.5)
+}
+
+// mix returns the sRGB color a fraction t from a to b, interpolated in
+// linear light like Gio blends and interpolates gradients.
+func mix(a, b color.NRGBA, t float64) color.NRGBA {
+	ch := func(a, b uint8) uint8 {
+		return toSRGB(fromSRGB(a)*(1-t) + fromSRGB(b)*t)
+	}
+	return color.NRGBA{R: ch(a.R, b.R), G: ch(a.G, b.G), B: ch(a.B, b.B), A: 0xff}
+}
+
+// steps draws equal width bands of cols.
+func steps(ops *op.Ops, sz image.Point, cols []color.NRGBA) {
+	for i, c := range cols {
+		r := image.Rect(i*sz.X/len(cols), 0, (i+1)*sz.X/len(cols), sz.Y)
+		paint.FillShape(ops, c, clip.Rect(r).Op())
+	}
+}
+
+// stepProbes probes the centers of the bands drawn by steps.
+func stepProbes(sz image.Point, cols []color.NRGBA, tol uint8) []probe {
+	var ps []probe
+	for i, c := range cols {
+		x := (i*sz.X/len(cols) + (i+1)*sz.X/len(cols)) / 2
+		ps = append(ps, probe{at: image.Pt(x, sz.Y/2), want: c, tol: tol})
+	}
+	return ps
+}
+
+func greys(n int) []color.NRGBA {
+	var cols []color.NRGBA
+	for i := 0; i < n; i++ {
+		v := uint8(i * 0xff / (n - 1))
+		cols = append(cols, color.NRGBA{R: v, G: v, B: v, A: 0xff})
+	}
+	return cols
+}
+
+var primaries = []color.NRGBA{
+	{R: 0xff, A: 0xff},
+	{G: 0xff, A: 0xff},
+	{B: 0xff, A: 0xff},
+	{G: 0xff, B: 0xff, A: 0xff},
+	{R: 0xff, B: 0xff, A: 0xff},
+	{R: 0xff, G: 0xff, A: 0xff},
+	white,
+	black,
+}
+
+// gradient draws a horizontal gradient from c1 to c2.
+func gradient(c1, c2 color.NRGBA) func(ops *op.Ops, sz image.Point) {
+	return func(ops *op.Ops, sz image.Point) {
+		defer op.Save(ops).Load()
+		clip.Rect{Max: sz}.Add(ops)
+		paint.LinearGradientOp{
+			Stop1:  f32.Pt(0, 0),
+			Color1: c1,
+			Stop2:  f32.Pt(float32(sz.X), 0),
+			Color2: c2,
+		}.Add(ops)
+		paint.PaintOp{}.Add(ops)
+	}
+}
+
+// gradientProbes probes a gradient at the quarters.
+func gradientProbes(c1, c2 color.NRGBA) func(sz image.Point) []probe {
+	return func(sz image.Point) []probe {
+		var ps []probe
+		for _, q := range []int{1, 2, 3} {
+			x := q * sz.X / 4
+			t := (float64(x) + 0.5) / float64(sz.X)
+			ps = append(ps, probe{at: image.Pt(x, sz.Y/2), want: mix(c1, c2, t), tol: 3})
+		}
+		return ps
+	}
+}
+
+// grey50 is the sRGB encoding of half the light of white.
+var grey50 = mix(black, white, 0.5)
+
+var patterns = []pattern{
+	{
+		name: "sRGB grey ramp",
+		desc: "16 steps from black to white. Every step must be distinct; if the darkest steps merge, the display crushes shadows.",
+		draw: func(ops *op.Ops, sz image.Point) {
+			steps(ops, sz, greys(16))
+		},
+		probes: func(sz image.Point) []probe {
+			return stepProbes(sz, greys(16), 1)
+		},
+	},
+	{
+		name: "Primaries and secondaries",
+		desc: "Red, green, blue, cyan, magenta, yellow, white and black, at full saturation.",
+		draw: func(ops *op.Ops, sz image.Point) {
+			steps(ops, sz, primaries)
+		},
+		probes: func(sz image.Point) []probe {
+			return stepProbes(sz, primaries, 0)
+		},
+	},
+	{
+		name:   "Black to white gradient",
+		desc:   "Gradients interpolate in linear light: the middle is lighter than the middle grey of an sRGB ramp.",
+		draw:   gradient(black, white),
+		probes: gradientProbes(black, white),
+	},
+	{
+		name:   "Red to green gradient",
+		desc:   "The middle is a bright olive yellow; a dark, muddy band means interpolation of sRGB values.",
+		draw:   gradient(color.NRGBA{R: 0xff, A: 0xff}, color.NRGBA{G: 0xff, A: 0xff}),
+		probes: gradientProbes(color.NRGBA{R: 0xff, A: 0xff}, color.NRGBA{G: 0xff, A: 0xff}),
+	},
+	{
+		name: "Alpha blending",
+		desc: "Half transparent white over black, and half transparent black over white, blended in linear light.",
+		draw: func(ops *op.Ops, sz image.Point) {
+			half := image.Rect(0, 0, sz.X/2, sz.Y)
+			paint.FillShape(ops, black, clip.Rect(half).Op())
+			paint.FillShape(ops, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x80}, clip.Rect(half).Op())
+			half = image.Rect(sz.X/2, 0, sz.X, sz.Y)
+			paint.FillShape(ops, white, clip.Rect(half).Op())
+			paint.FillShape(ops, color.NRGBA{A: 0x80}, clip.Rect(half).Op())
+		},
+		probes: func(sz image.Point) []probe {
+			a := float64(0x80) / 0xff
+			return []probe{
+				{at: image.Pt(sz.X/4, sz.Y/2), want: mix(black, white, a), tol: 2},
+				{at: image.Pt(sz.X*3/4, sz.Y/2), want: mix(white, black, a), tol: 2},
+			}
+		},
+	},
+	{
+		name: "Gamma stripes",
+		desc: "Alternating black and white pixel rows next to solid grey. Seen from a distance, both halves have the same brightness on a correct display.",
+		draw: func(ops *op.Ops, sz image.Point) {
+			paint.FillShape(ops, black, clip.Rect{Max: image.Pt(sz.X/2, sz.Y)}.Op())
+			for y := 0; y < sz.Y; y += 2 {
+				paint.FillShape(ops, white, clip.Rect{Min: image.Pt(0, y), Max: image.Pt(sz.X/2, y+1)}.Op())
+			}
+			paint.FillShape(ops, grey50, clip.Rect{Min: image.Pt(sz.X/2, 0), Max: sz}.Op())
+		},
+		probes: func(sz image.Point) []probe {
+			return []probe{
+				{at: image.Pt(sz.X/4, 0), want: white},
+				{at: image.Pt(sz.X/4, 1), want: black},
+				{at: image.Pt(sz.X*3/4, sz.Y/2), want: grey50},
+			}
+		},
+	},
+}
+
+// evaluate reads the probes of p from img, where the pattern is drawn at
+// origin.
+func evaluate(img image.Image, origin image.Point, p pattern) []result {
+	var res []result
+	for _, pr := range p.probes(patternSize) {
+		r, g, b, _ := img.At(origin.X+pr.at.X, origin.Y+pr.at.Y).RGBA()
+		got := color.NRGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: 0xff}
+		res = append(res, result{pattern: p.name, probe: pr, got: got})
+	}
+	return res
+}
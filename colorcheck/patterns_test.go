@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestSRGB(t *testing.T) {
+	for c := 0; c <= 0xff; c++ {
+		if got := toSRGB(fromSRGB(uint8(c))); got != uint8(c) {
+			t.Errorf("round trip of %#x gave %#x", c, got)
+		}
+	}
+	if got := mix(black, white, 0.5); got.R != 0xbc {
+		t.Errorf("half of white encodes to %#x, want 0xbc", got.R)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	p := patterns[0]
+	img := image.NewRGBA(image.Rectangle{Max: patternSize.Add(image.Pt(0, 10))})
+	cols := greys(16)
+	for y := 10; y < img.Bounds().Max.Y; y++ {
+		for x := 0; x < patternSize.X; x++ {
+			img.Set(x, y, cols[x*len(cols)/patternSize.X])
+		}
+	}
+	// Spoil one step beyond the tolerance.
+	bad := image.Pt(patternSize.X/16*3+patternSize.X/32, 10+patternSize.Y/2)
+	img.Set(bad.X, bad.Y, color.NRGBA{R: 0x40, G: 0x40, B: 0x40, A: 0xff})
+	res := evaluate(img, image.Pt(0, 10), p)
+	if len(res) != len(cols) {
+		t.Fatalf("got %d results, want %d", len(res), len(cols))
+	}
+	for i, r := range res {
+		if want := i != 3; r.ok() != want {
+			t.Errorf("%v: ok is %v, want %v", r, r.ok(), want)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program is a small theme editor built on the color picker from
+// internal/colorpicker. Select a role of the material palette, edit its
+// color with the wheel, the sliders or the hex entry, save colors to the
+// palette or pick them from the sample image with the eyedropper.
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"math"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/colorpicker"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var imageFile = flag.String("image", "", "image to sample with the eyedropper")
+
+func main() {
+	flag.Parse()
+	img, err := sampleImage()
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("Theme editor"),
+			app.Size(unit.Dp(900), unit.Dp(700)),
+		)
+		if err := loop(w, img); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// roles are the editable colors of the palette.
+var roles = []string{"Background", "Foreground", "Primary", "On primary"}
+
+// roleColor returns a pointer to the palette color of role.
+func roleColor(p *material.Palette, role string) *color.NRGBA {
+	switch role {
+	case "Background":
+		return &p.Bg
+	case "Foreground":
+		return &p.Fg
+	case "Primary":
+		return &p.ContrastBg
+	default:
+		return &p.ContrastFg
+	}
+}
+
+func loop(w *app.Window, img image.Image) error {
+	// th is the theme of the editor itself; preview is the theme being
+	// edited.
+	th := material.NewTheme(gofont.Collection())
+	preview := material.NewTheme(gofont.Collection())
+	var (
+		ops      op.Ops
+		role     = widget.Enum{Value: roles[0]}
+		lastRole string
+		picker   colorpicker.State
+		palette  = colorpicker.Palette{Colors: []color.NRGBA{
+			{R: 0x3f, G: 0x51, B: 0xb5, A: 0xff},
+			{R: 0xe9, G: 0x1e, B: 0x63, A: 0xff},
+			{R: 0x00, G: 0x96, B: 0x88, A: 0xff},
+			{R: 0xff, G: 0xc1, B: 0x07, A: 0xff},
+		}}
+		eyedropper colorpicker.Eyedropper
+		imgOp      = paint.NewImageOp(img)
+
+		button   widget.Clickable
+		checkbox = widget.Bool{Value: true}
+		slider   = widget.Float{Value: .4}
+		editor   = widget.Editor{SingleLine: true}
+	)
+	editor.SetText("Editable text")
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			if role.Value != lastRole {
+				lastRole = role.Value
+				picker.SetColor(*roleColor(&preview.Palette, role.Value))
+			}
+
+			layout.Flex{}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					gtx.Constraints.Max.X = gtx.Px(unit.Dp(320))
+					return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+						children := []layout.FlexChild{
+							layout.Rigid(material.H6(th, "Palette").Layout),
+						}
+						for _, r := range roles {
+							children = append(children, layout.Rigid(material.RadioButton(th, &role, r, r).Layout))
+						}
+						children = append(children,
+							layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+							layout.Rigid(colorpicker.Picker(th, &picker).Layout),
+							layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+							layout.Rigid(material.Caption(th, "Saved colors").Layout),
+							layout.Rigid(colorpicker.PaletteOf(th, &palette, &picker).Layout),
+						)
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+					})
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Flexed(1, func(gtx C) D {
+							return layoutPreview(gtx, preview, &button, &checkbox, &slider, &editor)
+						}),
+						layout.Flexed(1, func(gtx C) D {
+							return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+								return eyedropper.Layout(gtx, &picker, img, func(gtx C) D {
+									return layoutImage(gtx, imgOp)
+								})
+							})
+						}),
+					)
+				}),
+			)
+			if picker.Changed() {
+				// Redraw the preview with the new color.
+				*roleColor(&preview.Palette, role.Value) = picker.Color()
+				op.InvalidateOp{}.Add(gtx.Ops)
+			}
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+// layoutPreview lays out a few widgets with the edited theme.
+func layoutPreview(gtx C, th *material.Theme, button *widget.Clickable, checkbox *widget.Bool, slider *widget.Float, editor *widget.Editor) D {
+	paint.FillShape(gtx.Ops, th.Palette.Bg, clip.Rect(image.Rectangle{Max: gtx.Constraints.Max}).Op())
+	return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(material.H4(th, "Preview").Layout),
+			layout.Rigid(material.Body1(th, "Body text in the foreground color.").Layout),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+			layout.Rigid(material.Button(th, button, "Button").Layout),
+			layout.Rigid(material.CheckBox(th, checkbox, "Checkbox").Layout),
+			layout.Rigid(material.Slider(th, slider, 0, 1).Layout),
+			layout.Rigid(material.Editor(th, editor, "Hint").Layout),
+		)
+	})
+}
+
+// layoutImage draws img scaled to fit the constraints.
+func layoutImage(gtx C, img paint.ImageOp) D {
+	isz := img.Size()
+	avail := gtx.Constraints.Max
+	scale := float32(math.Min(float64(avail.X)/float64(isz.X), float64(avail.Y)/float64(isz.Y)))
+	size := image.Pt(int(float32(isz.X)*scale), int(float32(isz.Y)*scale))
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	op.Affine(f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(scale, scale))).Add(gtx.Ops)
+	img.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+	return D{Size: size}
+}
+
+// sampleImage loads the image given by the flag, or generates a gradient.
+func sampleImage() (image.Image, error) {
+	if *imageFile != "" {
+		f, err := os.Open(*imageFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		img, _, err := image.Decode(f)
+		return img, err
+	}
+	const w, h = 360, 200
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			hsv := colorpicker.HSV{H: float32(x), S: 1 - float32(y)/h/2, V: 1 - float32(y)/h/2}
+			img.SetNRGBA(x, y, hsv.RGB(0xff))
+		}
+	}
+	return img, nil
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"unicode"
+)
+
+// fuzzy matches pattern against s, case insensitively, as a subsequence.
+// It returns the score of the match, higher being better, and the rune
+// indices in s of the matched pattern runes. Runes matched at the start
+// of a word or directly after the previous match score higher.
+func fuzzy(pattern, s string) (score int, positions []int, ok bool) {
+	p := []rune(pattern)
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+	r := []rune(s)
+	// Greedy matching can miss better alignments: "dl" in "Edit: Delete"
+	// should match the D of Delete rather than the d in Edit. Try every
+	// position of the first rune and keep the best.
+	for start := range r {
+		if !equalFold(r[start], p[0]) {
+			continue
+		}
+		sc, pos, matched := matchFrom(p, r, start)
+		if matched && (!ok || sc > score) {
+			score, positions, ok = sc, pos, true
+		}
+	}
+	return score, positions, ok
+}
+
+// matchFrom greedily matches p in r, with p[0] matched at r[start].
+func matchFrom(p, r []rune, start int) (int, []int, bool) {
+	pos := make([]int, 0, len(p))
+	score := 0
+	j := 0
+	for i := start; i < len(r) && j < len(p); i++ {
+		if !equalFold(r[i], p[j]) {
+			continue
+		}
+		score++
+		if wordStart(r, i) {
+			score += 8
+		}
+		if len(pos) > 0 && pos[len(pos)-1] == i-1 {
+			score += 4
+		}
+		pos = append(pos, i)
+		j++
+	}
+	if j < len(p) {
+		return 0, nil, false
+	}
+	// Prefer compact matches.
+	score -= (pos[len(pos)-1] - pos[0] + 1 - len(pos)) / 2
+	return score, pos, true
+}
+
+func wordStart(r []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev := r[i-1]
+	return !unicode.IsLetter(prev) && !unicode.IsDigit(prev) ||
+		unicode.IsLower(prev) && unicode.IsUpper(r[i])
+}
+
+func equalFold(a, b rune) bool {
+	return unicode.ToLower(a) == unicode.ToLower(b)
+}
@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		ok         bool
+		positions  []int
+	}{
+		{"", "View: Zoom In", true, nil},
+		{"zi", "View: Zoom In", true, []int{6, 11}},
+		{"ZOOM", "View: Zoom In", true, []int{6, 7, 8, 9}},
+		{"dl", "Edit: Delete", true, []int{6, 8}},
+		{"xyz", "View: Zoom In", false, nil},
+		{"nz", "View: Zoom In", false, nil},
+	}
+	for _, test := range tests {
+		_, pos, ok := fuzzy(test.pattern, test.s)
+		if ok != test.ok || !reflect.DeepEqual(pos, test.positions) {
+			t.Errorf("fuzzy(%q, %q) = %v, %v; want %v, %v", test.pattern, test.s, pos, ok, test.positions, test.ok)
+		}
+	}
+}
+
+func TestFuzzyRanking(t *testing.T) {
+	// Matches at word starts rank above scattered matches.
+	better, _, _ := fuzzy("zi", "View: Zoom In")
+	worse, _, _ := fuzzy("zi", "Edit: Fizzing")
+	if better <= worse {
+		t.Errorf("word start match scored %d, not above scattered match %d", better, worse)
+	}
+}
@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates a command palette: Ctrl+K (Cmd+K on macOS)
+// opens a searchable list of every command of the program. Commands are
+// matched fuzzily, recently run commands are listed first and the palette
+// works entirely from the keyboard.
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"runtime"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/key"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Commands"),
+			app.Size(unit.Dp(800), unit.Dp(600)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// shortcutMod is the name of the shortcut modifier on this platform.
+var shortcutMod = "Ctrl"
+
+func init() {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "ios" {
+		shortcutMod = "Cmd"
+	}
+}
+
+// canvas is the state changed by the commands.
+type canvas struct {
+	shape string
+	color color.NRGBA
+	zoom  float32
+	dark  bool
+}
+
+// workspace is the state of the program.
+type workspace struct {
+	canvas canvas
+	// history holds the previous canvas states for undo.
+	history []canvas
+	last    string
+}
+
+// change records the current state for undo before applying f.
+func (ws *workspace) change(name string, f func(c *canvas)) func() {
+	return func() {
+		ws.history = append(ws.history, ws.canvas)
+		f(&ws.canvas)
+		ws.last = name
+	}
+}
+
+func (ws *workspace) undo() {
+	if n := len(ws.history); n > 0 {
+		ws.canvas = ws.history[n-1]
+		ws.history = ws.history[:n-1]
+		ws.last = "Undo"
+	}
+}
+
+func (ws *workspace) toggleDark() {
+	ws.change("Toggle Dark Theme", func(c *canvas) { c.dark = !c.dark })()
+}
+
+func (ws *workspace) commands() []*Command {
+	colorCmd := func(name string, c color.NRGBA) *Command {
+		return &Command{Category: "Color", Title: name, Run: ws.change(name, func(cv *canvas) { cv.color = c })}
+	}
+	shapeCmd := func(name string) *Command {
+		return &Command{Category: "Shape", Title: name, Run: ws.change(name, func(cv *canvas) { cv.shape = name })}
+	}
+	zoomCmd := func(name string, f func(z float32) float32) *Command {
+		return &Command{Category: "View", Title: name, Run: ws.change(name, func(cv *canvas) { cv.zoom = f(cv.zoom) })}
+	}
+	return []*Command{
+		shapeCmd("Circle"),
+		shapeCmd("Square"),
+		shapeCmd("Triangle"),
+		colorCmd("Red", color.NRGBA{R: 0xe5, G: 0x39, B: 0x35, A: 0xff}),
+		colorCmd("Green", color.NRGBA{R: 0x43, G: 0xa0, B: 0x47, A: 0xff}),
+		colorCmd("Blue", color.NRGBA{R: 0x1e, G: 0x88, B: 0xe5, A: 0xff}),
+		colorCmd("Orange", color.NRGBA{R: 0xfb, G: 0x8c, B: 0x00, A: 0xff}),
+		zoomCmd("Zoom In", func(z float32) float32 { return z * 1.25 }),
+		zoomCmd("Zoom Out", func(z float32) float32 { return z / 1.25 }),
+		zoomCmd("Reset Zoom", func(float32) float32 { return 1 }),
+		{Category: "View", Title: "Toggle Dark Theme", Shortcut: shortcutMod + "+D", Run: ws.toggleDark},
+		{Category: "Edit", Title: "Undo", Shortcut: shortcutMod + "+Z", Run: ws.undo},
+	}
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	light := th.Palette
+	dark := light
+	dark.Fg, dark.Bg = light.Bg, color.NRGBA{R: 0x20, G: 0x20, B: 0x24, A: 0xff}
+	ws := &workspace{canvas: canvas{shape: "Circle", color: color.NRGBA{R: 0x1e, G: 0x88, B: 0xe5, A: 0xff}, zoom: 1}}
+	palette := &Palette{Commands: ws.commands()}
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case key.Event:
+			// Key events arrive here when the palette is closed and thus
+			// not focused.
+			if e.State != key.Press || !e.Modifiers.Contain(key.ModShortcut) {
+				break
+			}
+			switch e.Name {
+			case "K":
+				palette.Open()
+			case "D":
+				ws.toggleDark()
+			case "Z":
+				ws.undo()
+			}
+			w.Invalidate()
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			th.Palette = light
+			if ws.canvas.dark {
+				th.Palette = dark
+			}
+			paint.Fill(gtx.Ops, th.Palette.Bg)
+			layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Flexed(1, func(gtx C) D {
+					return layoutCanvas(gtx, ws.canvas)
+				}),
+				layout.Rigid(func(gtx C) D {
+					status := fmt.Sprintf("Press %s+K to open the command palette", shortcutMod)
+					if ws.last != "" {
+						status = fmt.Sprintf("Ran %q. %s", ws.last, status)
+					}
+					return layout.UniformInset(unit.Dp(12)).Layout(gtx, material.Body2(th, status).Layout)
+				}),
+			)
+			palette.Layout(gtx, th)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+// layoutCanvas draws the shape of c centered in the available space.
+func layoutCanvas(gtx C, c canvas) D {
+	size := gtx.Constraints.Max
+	s := float32(gtx.Px(unit.Dp(120))) * c.zoom
+	center := layout.FPt(size).Mul(.5)
+	r := f32.Rectangle{Min: center.Sub(f32.Pt(s/2, s/2)), Max: center.Add(f32.Pt(s/2, s/2))}
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	switch c.shape {
+	case "Circle":
+		paint.FillShape(gtx.Ops, c.color, clip.UniformRRect(r, s/2).Op(gtx.Ops))
+	case "Square":
+		paint.FillShape(gtx.Ops, c.color, clip.UniformRRect(r, 0).Op(gtx.Ops))
+	case "Triangle":
+		var p clip.Path
+		p.Begin(gtx.Ops)
+		p.MoveTo(f32.Pt(center.X, r.Min.Y))
+		p.LineTo(r.Max)
+		p.LineTo(f32.Pt(r.Min.X, r.Max.Y))
+		p.Close()
+		paint.FillShape(gtx.Ops, c.color, clip.Outline{Path: p.End()}.Op())
+	}
+	return D{Size: size}
+}
@@ -0,0 +1,353 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"sort"
+
+	"gioui.org/f32"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Command is an action that can be run from the palette.
+type Command struct {
+	Category string
+	Title    string
+	// Shortcut is a description of the key binding of the command, if
+	// any.
+	Shortcut string
+	Run      func()
+
+	click widget.Clickable
+}
+
+func (c *Command) label() string {
+	return c.Category + ": " + c.Title
+}
+
+// Palette is a searchable overlay listing commands. It is operated by
+// keyboard: typing filters the commands, the arrow keys select, enter runs
+// and escape closes.
+type Palette struct {
+	Commands []*Command
+
+	open    bool
+	focus   bool
+	query   string
+	matches []match
+	// selected indexes matches, top the first visible match.
+	selected, top int
+	// recent lists the most recently run commands first.
+	recent []*Command
+	// scrim and card are the tags of their pointer areas.
+	scrim, card int
+}
+
+type match struct {
+	cmd       *Command
+	score     int
+	positions []int
+	recent    bool
+}
+
+const (
+	maxRecent  = 5
+	maxVisible = 8
+)
+
+// Open shows the palette with an empty query.
+func (p *Palette) Open() {
+	p.open = true
+	p.focus = true
+	p.query = ""
+	p.filter()
+}
+
+func (p *Palette) Close() {
+	p.open = false
+}
+
+func (p *Palette) Opened() bool {
+	return p.open
+}
+
+// filter updates the matches for the query. Without a query, the recent
+// commands are listed first.
+func (p *Palette) filter() {
+	p.matches = p.matches[:0]
+	p.selected, p.top = 0, 0
+	if p.query == "" {
+		seen := make(map[*Command]bool)
+		for _, c := range p.recent {
+			p.matches = append(p.matches, match{cmd: c, recent: true})
+			seen[c] = true
+		}
+		for _, c := range p.Commands {
+			if !seen[c] {
+				p.matches = append(p.matches, match{cmd: c})
+			}
+		}
+		return
+	}
+	for _, c := range p.Commands {
+		if score, pos, ok := fuzzy(p.query, c.label()); ok {
+			p.matches = append(p.matches, match{cmd: c, score: score, positions: pos})
+		}
+	}
+	sort.SliceStable(p.matches, func(i, j int) bool {
+		return p.matches[i].score > p.matches[j].score
+	})
+}
+
+// run closes the palette, records c as recent and runs it.
+func (p *Palette) run(c *Command) {
+	p.Close()
+	recent := []*Command{c}
+	for _, r := range p.recent {
+		if r != c && len(recent) < maxRecent {
+			recent = append(recent, r)
+		}
+	}
+	p.recent = recent
+	c.Run()
+}
+
+// move moves the selection by n, keeping it visible.
+func (p *Palette) move(n int) {
+	if len(p.matches) == 0 {
+		return
+	}
+	p.selected = (p.selected + n + len(p.matches)) % len(p.matches)
+	if p.selected < p.top {
+		p.top = p.selected
+	}
+	if p.selected >= p.top+maxVisible {
+		p.top = p.selected - maxVisible + 1
+	}
+}
+
+func (p *Palette) events(gtx C) {
+	for _, e := range gtx.Events(p) {
+		switch e := e.(type) {
+		case key.FocusEvent:
+			if !e.Focus {
+				p.Close()
+			}
+		case key.EditEvent:
+			p.query += e.Text
+			p.filter()
+		case key.Event:
+			if e.State != key.Press {
+				continue
+			}
+			switch e.Name {
+			case key.NameEscape:
+				p.Close()
+			case key.NameUpArrow:
+				p.move(-1)
+			case key.NameDownArrow:
+				p.move(1)
+			case key.NameReturn, key.NameEnter:
+				if len(p.matches) > 0 {
+					p.run(p.matches[p.selected].cmd)
+				}
+			case key.NameDeleteBackward:
+				if q := []rune(p.query); len(q) > 0 {
+					p.query = string(q[:len(q)-1])
+					p.filter()
+				}
+			case "K":
+				if e.Modifiers.Contain(key.ModShortcut) {
+					p.Close()
+				}
+			}
+		}
+	}
+	for _, e := range gtx.Events(&p.scrim) {
+		if e, ok := e.(pointer.Event); ok && e.Type == pointer.Press {
+			p.Close()
+		}
+	}
+	for _, m := range p.matches {
+		for m.cmd.click.Clicked() {
+			p.run(m.cmd)
+		}
+	}
+}
+
+// Layout lays out the palette over the whole window, if open.
+func (p *Palette) Layout(gtx C, th *material.Theme) D {
+	if !p.open {
+		return D{}
+	}
+	p.events(gtx)
+	if !p.open {
+		// A command ran or the palette was dismissed; redraw without it.
+		op.InvalidateOp{}.Add(gtx.Ops)
+		return D{}
+	}
+	size := gtx.Constraints.Max
+
+	// The scrim dims the window and closes the palette when clicked.
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 0x60}, clip.Rect(image.Rectangle{Max: size}).Op())
+	stack := op.Save(gtx.Ops)
+	pointer.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	pointer.InputOp{Tag: &p.scrim, Types: pointer.Press}.Add(gtx.Ops)
+	stack.Load()
+
+	stack = op.Save(gtx.Ops)
+	key.InputOp{Tag: p}.Add(gtx.Ops)
+	if p.focus {
+		key.FocusOp{Tag: p}.Add(gtx.Ops)
+		p.focus = false
+	}
+	stack.Load()
+
+	layout.N.Layout(gtx, func(gtx C) D {
+		return layout.Inset{Top: unit.Dp(64), Left: unit.Dp(16), Right: unit.Dp(16)}.Layout(gtx, func(gtx C) D {
+			if w := gtx.Px(unit.Dp(560)); gtx.Constraints.Max.X > w {
+				gtx.Constraints.Max.X = w
+			}
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			return layout.Stack{}.Layout(gtx,
+				layout.Expanded(func(gtx C) D {
+					sz := gtx.Constraints.Min
+					rr := float32(gtx.Px(unit.Dp(8)))
+					paint.FillShape(gtx.Ops, th.Palette.Bg, clip.UniformRRect(f32.Rectangle{Max: layout.FPt(sz)}, rr).Op(gtx.Ops))
+					// Block pointer events from reaching the scrim.
+					defer op.Save(gtx.Ops).Load()
+					pointer.Rect(image.Rectangle{Max: sz}).Add(gtx.Ops)
+					pointer.InputOp{Tag: &p.card, Types: pointer.Press}.Add(gtx.Ops)
+					return D{Size: sz}
+				}),
+				layout.Stacked(func(gtx C) D {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(func(gtx C) D {
+							return p.layoutQuery(gtx, th)
+						}),
+						layout.Rigid(func(gtx C) D {
+							sz := image.Pt(gtx.Constraints.Max.X, gtx.Px(unit.Dp(1)))
+							paint.FillShape(gtx.Ops, color.NRGBA{A: 0x20}, clip.Rect(image.Rectangle{Max: sz}).Op())
+							return D{Size: sz}
+						}),
+						layout.Rigid(func(gtx C) D {
+							return p.layoutMatches(gtx, th)
+						}),
+					)
+				}),
+			)
+		})
+	})
+	return D{Size: size}
+}
+
+func (p *Palette) layoutQuery(gtx C, th *material.Theme) D {
+	return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(material.Body1(th, "› ").Layout),
+			layout.Rigid(func(gtx C) D {
+				if p.query == "" {
+					l := material.Body1(th, "Type a command")
+					l.Color = color.NRGBA{A: 0x60}
+					return l.Layout(gtx)
+				}
+				return material.Body1(th, p.query).Layout(gtx)
+			}),
+			// The caret.
+			layout.Rigid(func(gtx C) D {
+				sz := image.Pt(gtx.Px(unit.Dp(2)), gtx.Px(unit.Dp(18)))
+				paint.FillShape(gtx.Ops, th.Palette.ContrastBg, clip.Rect(image.Rectangle{Max: sz}).Op())
+				return D{Size: sz}
+			}),
+		)
+	})
+}
+
+func (p *Palette) layoutMatches(gtx C, th *material.Theme) D {
+	if len(p.matches) == 0 {
+		return layout.UniformInset(unit.Dp(12)).Layout(gtx, material.Body2(th, "No matching commands").Layout)
+	}
+	end := p.top + maxVisible
+	if end > len(p.matches) {
+		end = len(p.matches)
+	}
+	var rows []layout.FlexChild
+	for i := p.top; i < end; i++ {
+		i := i
+		rows = append(rows, layout.Rigid(func(gtx C) D {
+			return p.layoutMatch(gtx, th, p.matches[i], i == p.selected)
+		}))
+	}
+	return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
+	})
+}
+
+func (p *Palette) layoutMatch(gtx C, th *material.Theme, m match, selected bool) D {
+	return material.Clickable(gtx, &m.cmd.click, func(gtx C) D {
+		return layout.Stack{}.Layout(gtx,
+			layout.Expanded(func(gtx C) D {
+				if selected {
+					bg := th.Palette.ContrastBg
+					bg.A = 0x30
+					paint.FillShape(gtx.Ops, bg, clip.Rect(image.Rectangle{Max: gtx.Constraints.Min}).Op())
+				}
+				return D{Size: gtx.Constraints.Min}
+			}),
+			layout.Stacked(func(gtx C) D {
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+				return layout.Inset{Left: unit.Dp(12), Right: unit.Dp(12), Top: unit.Dp(6), Bottom: unit.Dp(6)}.Layout(gtx, func(gtx C) D {
+					return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+						layout.Flexed(1, func(gtx C) D {
+							return highlighted(gtx, th, m.cmd.label(), m.positions)
+						}),
+						layout.Rigid(func(gtx C) D {
+							hint := m.cmd.Shortcut
+							if m.recent {
+								hint = "recently used  " + hint
+							}
+							l := material.Caption(th, hint)
+							l.Color = color.NRGBA{A: 0x90}
+							return l.Layout(gtx)
+						}),
+					)
+				})
+			}),
+		)
+	})
+}
+
+// highlighted lays out s with the runes at the positions in bold.
+func highlighted(gtx C, th *material.Theme, s string, positions []int) D {
+	r := []rune(s)
+	bold := make([]bool, len(r))
+	for _, i := range positions {
+		bold[i] = true
+	}
+	var spans []layout.FlexChild
+	for i := 0; i < len(r); {
+		j := i + 1
+		for j < len(r) && bold[j] == bold[i] {
+			j++
+		}
+		l := material.Body1(th, string(r[i:j]))
+		if bold[i] {
+			l.Font.Weight = text.Bold
+			l.Color = th.Palette.ContrastBg
+		}
+		spans = append(spans, layout.Rigid(l.Layout))
+		i = j
+	}
+	return layout.Flex{}.Layout(gtx, spans...)
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates the anchor based layout from
+// internal/constraint. A profile card is laid out by pinning edges of its
+// parts to each other and to guides, instead of nesting Flex and Stack
+// layouts.
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/constraint"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Constraint layout"),
+			app.Size(unit.Dp(480), unit.Dp(360)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	var follow, message widget.Clickable
+	following := false
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			for follow.Clicked() {
+				following = !following
+			}
+			label := "Follow"
+			if following {
+				label = "Unfollow"
+			}
+
+			var l constraint.Layout
+			parent := l.Parent()
+			// Guides split the window into a left third for the avatar
+			// and the rest for the text.
+			split := l.VGuide(1.0 / 3)
+			middle := l.HGuide(.4)
+
+			avatar := l.Add(func(gtx C) D {
+				sz := gtx.Px(unit.Dp(96))
+				rr := float32(sz) / 2
+				bounds := f32.Rectangle{Max: layout.FPt(image.Pt(sz, sz))}
+				paint.FillShape(gtx.Ops, avatarColor, clip.UniformRRect(bounds, rr).Op(gtx.Ops))
+				return D{Size: image.Pt(sz, sz)}
+			})
+			name := l.Add(material.H5(th, "Gopher").Layout)
+			handle := l.Add(material.Body2(th, "@gopher").Layout)
+			bio := l.Add(func(gtx C) D {
+				gtx.Constraints.Max.X = gtx.Constraints.Max.X * 2 / 3
+				return material.Body1(th, "Likes concurrency, immediate mode GUIs and running across the screen.").Layout(gtx)
+			})
+			followBtn := l.Add(material.Button(th, &follow, label).Layout)
+			messageBtn := l.Add(material.Button(th, &message, "Message").Layout)
+
+			// Center the avatar in the left third, level with the middle
+			// guide.
+			l.CenterBetween(avatar.CenterX(), parent.Left(), split.Left())
+			l.Align(avatar.CenterY(), middle.Top(), unit.Value{})
+			// The name starts at the split and sits above the middle
+			// guide; the handle follows the name's trailing edge.
+			l.Align(name.Left(), split.Left(), unit.Value{})
+			l.Align(name.Bottom(), middle.Top(), unit.Value{})
+			l.Align(handle.Left(), name.Right(), unit.Dp(8))
+			l.Align(handle.CenterY(), name.CenterY(), unit.Value{})
+			l.Align(bio.Left(), split.Left(), unit.Value{})
+			l.Align(bio.Top(), middle.Top(), unit.Dp(4))
+			// The buttons hang off the bottom right corner.
+			l.Align(messageBtn.Right(), parent.Right(), unit.Value{})
+			l.Align(messageBtn.Bottom(), parent.Bottom(), unit.Value{})
+			l.Align(followBtn.Right(), messageBtn.Left(), unit.Dp(-8))
+			l.Align(followBtn.CenterY(), messageBtn.CenterY(), unit.Value{})
+
+			layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+				gtx.Constraints.Min = gtx.Constraints.Max
+				return l.Layout(gtx)
+			})
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+var avatarColor = color.NRGBA{R: 0x5e, G: 0xc5, B: 0xe0, A: 0xff}
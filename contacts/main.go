@@ -0,0 +1,326 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates advanced list navigation: an alphabet index
+// rail that jumps through a long contact list while dragging, and a search
+// field revealed by pulling the list down past its top.
+//
+// Set GIO_MEMSTATS=1 to see the allocations of each frame.
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/fakedata"
+	"gioui.org/example/internal/kinetic"
+	"gioui.org/example/internal/memstats"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/pointer"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Contacts"),
+			app.Size(unit.Dp(400), unit.Dp(700)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// pullThreshold is how far the list must be pulled down to reveal the
+// search field.
+var pullThreshold = unit.Dp(64)
+
+// UI holds the program state.
+type UI struct {
+	theme    *material.Theme
+	contacts []string
+	// shown is the filtered list of contacts.
+	shown []string
+
+	list kinetic.List
+	rail Rail
+
+	search     widget.Editor
+	cancel     widget.Clickable
+	searching  bool
+	pullArmed  bool
+	lastFilter string
+}
+
+func loop(w *app.Window) error {
+	ui := &UI{
+		theme:    material.NewTheme(gofont.Collection()),
+		contacts: generateContacts(),
+		search:   widget.Editor{SingleLine: true},
+	}
+	ui.list.Overscroll = kinetic.Bounce
+	ui.list.Physics = kinetic.IOS
+	ui.shown = ui.contacts
+	var mem memstats.Overlay
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			ui.Layout(gtx)
+			mem.Layout(gtx, ui.theme)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+// generateContacts returns a sorted list of made up names, last name
+// first.
+func generateContacts() []string {
+	names := fakedata.New(1).Names(156)
+	for i, n := range names {
+		f := strings.Fields(n)
+		names[i] = f[1] + ", " + f[0]
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Layout lays out the program.
+func (ui *UI) Layout(gtx C) D {
+	ui.update(gtx)
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			if !ui.searching {
+				return ui.layoutPullHint(gtx)
+			}
+			return ui.layoutSearch(gtx)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Stack{Alignment: layout.E}.Layout(gtx,
+				layout.Expanded(func(gtx C) D {
+					return ui.list.Layout(gtx, len(ui.shown), ui.layoutContact)
+				}),
+				layout.Stacked(func(gtx C) D {
+					return ui.rail.Layout(gtx, ui.theme)
+				}),
+			)
+		}),
+	)
+}
+
+// update handles the rail, the pull gesture and the search field.
+func (ui *UI) update(gtx C) {
+	if letter, ok := ui.rail.Selected(); ok {
+		// Jump to the first contact at or after the letter.
+		i := sort.Search(len(ui.shown), func(i int) bool {
+			return ui.shown[i][0] >= letter
+		})
+		if i == len(ui.shown) {
+			i = len(ui.shown) - 1
+		}
+		ui.list.Stop()
+		ui.list.Position.First = i
+		ui.list.Position.Offset = 0
+	}
+
+	pulled := -ui.list.Overscrolled() >= float32(gtx.Px(pullThreshold))
+	if ui.list.Dragging() {
+		ui.pullArmed = pulled
+	} else if ui.pullArmed {
+		ui.pullArmed = false
+		ui.searching = true
+		ui.search.Focus()
+	}
+
+	for ui.cancel.Clicked() {
+		ui.searching = false
+		ui.search.SetText("")
+	}
+	if filter := strings.ToLower(ui.search.Text()); filter != ui.lastFilter {
+		ui.lastFilter = filter
+		ui.shown = ui.shown[:0:0]
+		for _, c := range ui.contacts {
+			if strings.Contains(strings.ToLower(c), filter) {
+				ui.shown = append(ui.shown, c)
+			}
+		}
+		ui.list.Position = layout.Position{}
+	}
+}
+
+func (ui *UI) layoutPullHint(gtx C) D {
+	if ui.list.Overscrolled() >= 0 {
+		return D{}
+	}
+	msg := "Pull to search"
+	if ui.pullArmed {
+		msg = "Release to search"
+	}
+	return layout.Center.Layout(gtx, func(gtx C) D {
+		return layout.UniformInset(unit.Dp(4)).Layout(gtx, material.Caption(ui.theme, msg).Layout)
+	})
+}
+
+func (ui *UI) layoutSearch(gtx C) D {
+	return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx C) D {
+				return widget.Border{
+					Color:        color.NRGBA{A: 0x40},
+					CornerRadius: unit.Dp(4),
+					Width:        unit.Px(1),
+				}.Layout(gtx, func(gtx C) D {
+					return layout.UniformInset(unit.Dp(8)).Layout(gtx,
+						material.Editor(ui.theme, &ui.search, "Search contacts").Layout,
+					)
+				})
+			}),
+			layout.Rigid(func(gtx C) D {
+				return layout.Inset{Left: unit.Dp(8)}.Layout(gtx,
+					material.Button(ui.theme, &ui.cancel, "Cancel").Layout,
+				)
+			}),
+		)
+	})
+}
+
+func (ui *UI) layoutContact(gtx C, i int) D {
+	name := ui.shown[i]
+	showLetter := i == 0 || ui.shown[i-1][0] != name[0]
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			if !showLetter {
+				return D{}
+			}
+			return layout.Inset{Top: unit.Dp(12), Left: unit.Dp(16)}.Layout(gtx, func(gtx C) D {
+				l := material.Body1(ui.theme, name[:1])
+				l.Color = ui.theme.Palette.ContrastBg
+				return l.Layout(gtx)
+			})
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Top: unit.Dp(10), Bottom: unit.Dp(10), Left: unit.Dp(16), Right: unit.Dp(32)}.Layout(gtx,
+				material.Body1(ui.theme, name).Layout,
+			)
+		}),
+	)
+}
+
+// Rail is a vertical strip of letters that can be tapped or dragged
+// along to jump through an alphabetic list.
+type Rail struct {
+	active   bool
+	letter   byte
+	selected bool
+}
+
+// Selected returns the letter under the pointer if it changed since the
+// last call.
+func (r *Rail) Selected() (byte, bool) {
+	s := r.selected
+	r.selected = false
+	return r.letter, s
+}
+
+// Layout lays out the rail and, while dragging, a bubble with the current
+// letter.
+func (r *Rail) Layout(gtx C, th *material.Theme) D {
+	height := gtx.Constraints.Max.Y
+	width := gtx.Px(unit.Dp(24))
+	cell := float32(height) / float32(len(letters))
+	for _, e := range gtx.Events(r) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case pointer.Press, pointer.Drag:
+			r.active = true
+			i := int(e.Position.Y / cell)
+			if i < 0 {
+				i = 0
+			}
+			if i >= len(letters) {
+				i = len(letters) - 1
+			}
+			if l := letters[i]; l != r.letter || e.Type == pointer.Press {
+				r.letter = l
+				r.selected = true
+			}
+		case pointer.Release, pointer.Cancel:
+			r.active = false
+		}
+	}
+
+	size := image.Pt(width, height)
+	for i := 0; i < len(letters); i++ {
+		stack := op.Save(gtx.Ops)
+		op.Offset(f32.Pt(0, cell*float32(i))).Add(gtx.Ops)
+		cgtx := gtx
+		cgtx.Constraints = layout.Exact(image.Pt(width, int(cell)))
+		l := material.Caption(th, letters[i:i+1])
+		l.Color = th.Palette.ContrastBg
+		layout.Center.Layout(cgtx, l.Layout)
+		stack.Load()
+	}
+
+	if r.active {
+		r.layoutBubble(gtx, th, cell, width)
+	}
+
+	defer op.Save(gtx.Ops).Load()
+	pointer.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	pointer.InputOp{
+		Tag:   r,
+		Grab:  r.active,
+		Types: pointer.Press | pointer.Drag | pointer.Release,
+	}.Add(gtx.Ops)
+	return D{Size: size}
+}
+
+// layoutBubble draws the current letter in a circle to the left of the
+// rail, at the height of the letter.
+func (r *Rail) layoutBubble(gtx C, th *material.Theme, cell float32, railWidth int) {
+	i := strings.IndexByte(letters, r.letter)
+	d := gtx.Px(unit.Dp(64))
+	y := cell*(float32(i)+.5) - float32(d)/2
+	if y < 0 {
+		y = 0
+	}
+	defer op.Save(gtx.Ops).Load()
+	op.Offset(f32.Pt(-float32(d+railWidth), y)).Add(gtx.Ops)
+	bounds := f32.Rectangle{Max: f32.Pt(float32(d), float32(d))}
+	paint.FillShape(gtx.Ops, th.Palette.ContrastBg, clip.UniformRRect(bounds, float32(d)/2).Op(gtx.Ops))
+	gtx.Constraints = layout.Exact(image.Pt(d, d))
+	l := material.H4(th, string(r.letter))
+	l.Color = th.Palette.ContrastFg
+	layout.Center.Layout(gtx, l.Layout)
+}
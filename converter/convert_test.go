@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func unitOf(c Category, symbol string) Unit {
+	return c.Units[unitIndex(c, symbol)]
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		v        float64
+		cat      Category
+		from, to string
+		want     float64
+	}{
+		{1, length, "mi", "km", 1.609344},
+		{12, length, "in", "ft", 1},
+		{1, mass, "lb", "oz", 16},
+		{14, mass, "lb", "st", 1},
+		{100, temperature, "°C", "°F", 212},
+		{-40, temperature, "°F", "°C", -40},
+		{0, temperature, "K", "°C", -273.15},
+		{491.67, temperature, "°R", "°F", 32},
+	}
+	for _, test := range tests {
+		got := Convert(test.v, unitOf(test.cat, test.from), unitOf(test.cat, test.to))
+		if math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%v %s in %s: %v, want %v", test.v, test.from, test.to, got, test.want)
+		}
+	}
+}
+
+func TestCurrency(t *testing.T) {
+	c := currency(&Rates{Base: "EUR", Rates: map[string]float64{"USD": 1.25, "GBP": 0.8, "EUR": 1, "XYZ": 2}})
+	var syms []string
+	for _, u := range c.Units {
+		syms = append(syms, u.Symbol)
+	}
+	if len(syms) != 4 || syms[0] != "EUR" || syms[1] != "GBP" || syms[3] != "XYZ" {
+		t.Errorf("currencies %v, want the base first and the rest sorted", syms)
+	}
+	if got := Convert(10, unitOf(c, "USD"), unitOf(c, "GBP")); math.Abs(got-6.4) > 1e-9 {
+		t.Errorf("10 USD in GBP: %v, want 6.4", got)
+	}
+	if n := unitOf(c, "XYZ").Name; n != "XYZ" {
+		t.Errorf("name of an unknown currency: %q", n)
+	}
+	if c := currency(nil); len(c.Units) != 0 {
+		t.Errorf("%d currencies without rates", len(c.Units))
+	}
+}
@@ -0,0 +1,239 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"strings"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Option is a choice of a Dropdown. Detail is shown dimmed after the
+// label, and is searched as well.
+type Option struct {
+	Label  string
+	Detail string
+}
+
+// Dropdown is a button showing the selected option, which opens a list
+// of the options with a search field at its top. Typing filters the
+// options and Enter picks the first match. The list is deferred, so it
+// covers the widgets laid out after the dropdown.
+type Dropdown struct {
+	options  []Option
+	selected int
+
+	button widget.Clickable
+	open   bool
+	search widget.Editor
+	list   layout.List
+	clicks []widget.Clickable
+	// matches are the indices of the options matching the search.
+	matches []int
+	changed bool
+	// scrim and popup are pointer tags.
+	scrim, popup int
+}
+
+// SetOptions replaces the options, selecting the option at index sel.
+func (d *Dropdown) SetOptions(opts []Option, sel int) {
+	d.options = opts
+	d.clicks = make([]widget.Clickable, len(opts))
+	d.Select(sel)
+	d.open = false
+}
+
+// Select selects the option at index i, if there is one.
+func (d *Dropdown) Select(i int) {
+	if i < 0 || i >= len(d.options) {
+		i = 0
+	}
+	d.selected = i
+}
+
+// Selected returns the index of the selected option.
+func (d *Dropdown) Selected() int {
+	return d.selected
+}
+
+// Changed reports whether the user selected an option since the last
+// call.
+func (d *Dropdown) Changed() bool {
+	c := d.changed
+	d.changed = false
+	return c
+}
+
+func (d *Dropdown) filter() {
+	q := strings.ToLower(strings.TrimSpace(d.search.Text()))
+	d.matches = d.matches[:0]
+	for i, o := range d.options {
+		if q == "" || strings.Contains(strings.ToLower(o.Label), q) || strings.Contains(strings.ToLower(o.Detail), q) {
+			d.matches = append(d.matches, i)
+		}
+	}
+	d.list.Position = layout.Position{}
+}
+
+func (d *Dropdown) pick(i int) {
+	if i != d.selected {
+		d.selected = i
+		d.changed = true
+	}
+	d.open = false
+}
+
+func (d *Dropdown) update(gtx layout.Context) {
+	for d.button.Clicked() {
+		d.open = !d.open && len(d.options) > 0
+		if d.open {
+			d.search.SingleLine = true
+			d.search.Submit = true
+			d.search.SetText("")
+			d.search.Focus()
+			d.filter()
+		}
+	}
+	if !d.open {
+		return
+	}
+	for _, e := range d.search.Events() {
+		switch e.(type) {
+		case widget.ChangeEvent:
+			d.filter()
+		case widget.SubmitEvent:
+			if len(d.matches) > 0 {
+				d.pick(d.matches[0])
+			}
+		}
+	}
+	for _, i := range d.matches {
+		for d.clicks[i].Clicked() {
+			d.pick(i)
+		}
+	}
+	for _, e := range gtx.Events(&d.scrim) {
+		if e, ok := e.(pointer.Event); ok && e.Type == pointer.Press {
+			d.open = false
+		}
+	}
+}
+
+// Layout lays out the button, as wide as the constraints allow, and the
+// list if open.
+func (d *Dropdown) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	d.update(gtx)
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	dims := material.Clickable(gtx, &d.button, func(gtx layout.Context) layout.Dimensions {
+		return widget.Border{
+			Color:        color.NRGBA{A: 0x40},
+			CornerRadius: unit.Dp(4),
+			Width:        unit.Px(1),
+		}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				var o Option
+				if d.selected < len(d.options) {
+					o = d.options[d.selected]
+				}
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						l := material.Body1(th, o.Label)
+						l.MaxLines = 1
+						return l.Layout(gtx)
+					}),
+					layout.Rigid(material.Body1(th, "▾").Layout),
+				)
+			})
+		})
+	})
+	if d.open {
+		macro := op.Record(gtx.Ops)
+		d.layoutPopup(gtx, th, dims.Size)
+		op.Defer(gtx.Ops, macro.Stop())
+	}
+	return dims
+}
+
+// layoutPopup lays out the list below a button of the given size, over
+// a transparent scrim that closes it when clicked.
+func (d *Dropdown) layoutPopup(gtx layout.Context, th *material.Theme, button image.Point) {
+	// The scrim covers the window, wherever the dropdown is in it.
+	const far = 1 << 20
+	stack := op.Save(gtx.Ops)
+	pointer.Rect(image.Rect(-far, -far, far, far)).Add(gtx.Ops)
+	pointer.InputOp{Tag: &d.scrim, Types: pointer.Press}.Add(gtx.Ops)
+	stack.Load()
+
+	op.Offset(f32.Pt(0, float32(button.Y+gtx.Px(unit.Dp(2))))).Add(gtx.Ops)
+	gtx.Constraints = layout.Constraints{
+		Min: image.Pt(button.X, 0),
+		Max: image.Pt(button.X, gtx.Px(unit.Dp(320))),
+	}
+	macro := op.Record(gtx.Ops)
+	dims := layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.UniformInset(unit.Dp(8)).Layout(gtx, material.Editor(th, &d.search, "Search").Layout)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return d.list.Layout(gtx, len(d.matches), func(gtx layout.Context, i int) layout.Dimensions {
+					return d.layoutOption(gtx, th, d.matches[i])
+				})
+			}),
+		)
+	})
+	call := macro.Stop()
+	rr := float32(gtx.Px(unit.Dp(4)))
+	rect := f32.Rectangle{Max: layout.FPt(dims.Size)}
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 0x30}, clip.UniformRRect(rect.Add(f32.Pt(0, 2)), rr).Op(gtx.Ops))
+	paint.FillShape(gtx.Ops, th.Palette.Bg, clip.UniformRRect(rect, rr).Op(gtx.Ops))
+	// Keep presses on the list from reaching the scrim.
+	stack = op.Save(gtx.Ops)
+	pointer.Rect(image.Rectangle{Max: dims.Size}).Add(gtx.Ops)
+	pointer.InputOp{Tag: &d.popup, Types: pointer.Press}.Add(gtx.Ops)
+	stack.Load()
+	call.Add(gtx.Ops)
+}
+
+func (d *Dropdown) layoutOption(gtx layout.Context, th *material.Theme, i int) layout.Dimensions {
+	o := d.options[i]
+	return material.Clickable(gtx, &d.clicks[i], func(gtx layout.Context) layout.Dimensions {
+		return layout.Stack{}.Layout(gtx,
+			layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+				if i == d.selected {
+					bg := th.Palette.ContrastBg
+					bg.A = 0x30
+					paint.FillShape(gtx.Ops, bg, clip.Rect{Max: gtx.Constraints.Min}.Op())
+				}
+				return layout.Dimensions{Size: gtx.Constraints.Min}
+			}),
+			layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+				return layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(6), Left: unit.Dp(8), Right: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Alignment: layout.Baseline}.Layout(gtx,
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							l := material.Body1(th, o.Label)
+							l.MaxLines = 1
+							return l.Layout(gtx)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							l := material.Caption(th, o.Detail)
+							l.Color.A = 0x90
+							return l.Layout(gtx)
+						}),
+					)
+				})
+			}),
+		)
+	})
+}
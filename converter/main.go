@@ -0,0 +1,411 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program converts lengths, masses, temperatures and currencies.
+// The units to convert between are picked from dropdowns that depend on
+// the category picked, and each dropdown can be searched by typing, by
+// name or symbol. Numbers are read and written the way the chosen
+// locale does, with its decimal and group separators, and converted
+// currencies and the dates of the rates are written as the locale
+// writes amounts of money and dates.
+//
+// Currency rates are the reference rates of the European Central Bank.
+// They are cached for half a day, and the last ones are used offline.
+//
+// Usage:
+//
+//	go run ./converter [-locale de-CH]
+//
+// The locale defaults to the one of the environment, from LC_ALL or
+// LANG.
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/i18n"
+	"gioui.org/example/internal/style"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"golang.org/x/exp/shiny/materialdesign/icons"
+	xcurrency "golang.org/x/text/currency"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var localeFlag = flag.String("locale", "", "locale of numbers, such as de-CH (default from the environment)")
+
+// digits is the number of significant digits of results.
+const digits = 6
+
+var errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Converter"),
+			app.Size(unit.Dp(560), unit.Dp(520)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// rateResult is the outcome of fetching rates.
+type rateResult struct {
+	rates *Rates
+	err   error
+}
+
+type App struct {
+	cache    *RateCache
+	rates    *Rates
+	fetching bool
+	// ratesErr is the error of the last fetch of rates.
+	ratesErr error
+	results  chan rateResult
+
+	cats []Category
+	// locale reads and writes the numbers, amounts of money and dates.
+	locale *i18n.Locale
+
+	category, from, to, locales Dropdown
+	amount                      widget.Editor
+	swap, refresh               widget.Clickable
+	swapIcon                    *widget.Icon
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	swapIcon, err := widget.NewIcon(icons.ActionSwapVert)
+	if err != nil {
+		return err
+	}
+	a := &App{
+		cache:    newRateCache(),
+		results:  make(chan rateResult, 1),
+		swapIcon: swapIcon,
+		locale:   i18n.FromEnv(),
+	}
+	if *localeFlag != "" {
+		a.locale = i18n.Find(*localeFlag)
+	}
+	var opts []Option
+	sel := 0
+	for i, l := range i18n.Locales {
+		opts = append(opts, Option{Label: l.Name, Detail: l.Tag})
+		if l == a.locale {
+			sel = i
+		}
+	}
+	a.locales.SetOptions(opts, sel)
+	a.amount.SingleLine = true
+	a.amount.SetText("1")
+	a.rates = a.cache.Cached()
+	a.cats = []Category{length, mass, temperature, currency(a.rates)}
+	opts = nil
+	for _, c := range a.cats {
+		opts = append(opts, Option{Label: c.Name})
+	}
+	a.category.SetOptions(opts, 0)
+	a.setCategory(0)
+	if a.cache.Stale(a.rates, time.Now()) {
+		a.fetchRates()
+	}
+	var ops op.Ops
+	for {
+		select {
+		case res := <-a.results:
+			a.fetching = false
+			a.ratesErr = res.err
+			if res.err == nil {
+				a.setRates(res.rates)
+			}
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+// fetchRates fetches the currency rates in the background.
+func (a *App) fetchRates() {
+	if a.fetching {
+		return
+	}
+	a.fetching = true
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		r, err := a.cache.Fetch(ctx)
+		a.results <- rateResult{rates: r, err: err}
+	}()
+}
+
+// setRates replaces the currency units, keeping the currencies picked.
+func (a *App) setRates(r *Rates) {
+	a.rates = r
+	i := len(a.cats) - 1
+	old := a.cats[i]
+	a.cats[i] = currency(r)
+	if a.category.Selected() != i {
+		return
+	}
+	from, to := "EUR", "USD"
+	if len(old.Units) > 0 {
+		from, to = old.Units[a.from.Selected()].Symbol, old.Units[a.to.Selected()].Symbol
+	}
+	a.setUnits(a.cats[i], unitIndex(a.cats[i], from), unitIndex(a.cats[i], to))
+}
+
+// setCategory picks a category, and its default units.
+func (a *App) setCategory(i int) {
+	c := a.cats[i]
+	to := 1
+	if c.Name == "Currency" {
+		to = unitIndex(c, "USD")
+	}
+	a.setUnits(c, 0, to)
+}
+
+func (a *App) setUnits(c Category, from, to int) {
+	var opts []Option
+	for _, u := range c.Units {
+		opts = append(opts, Option{Label: u.Name, Detail: u.Symbol})
+	}
+	a.from.SetOptions(opts, from)
+	a.to.SetOptions(opts, to)
+}
+
+// unitIndex returns the index of the unit with a symbol, or 1 if there
+// is none.
+func unitIndex(c Category, symbol string) int {
+	for i, u := range c.Units {
+		if u.Symbol == symbol {
+			return i
+		}
+	}
+	return 1
+}
+
+func (a *App) update() {
+	if a.category.Changed() {
+		a.setCategory(a.category.Selected())
+	}
+	if a.locales.Changed() {
+		// Rewrite the amount for the new locale, so that its value
+		// doesn't change with the meaning of the separators.
+		old := a.locale
+		a.locale = i18n.Locales[a.locales.Selected()]
+		if v, err := old.Parse(a.amount.Text()); err == nil {
+			a.amount.SetText(a.locale.Format(v, 15))
+		}
+	}
+	for a.swap.Clicked() {
+		from, to := a.from.Selected(), a.to.Selected()
+		a.from.Select(to)
+		a.to.Select(from)
+	}
+	for a.refresh.Clicked() {
+		a.fetchRates()
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	a.update()
+	cat := a.cats[a.category.Selected()]
+	return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return layout.Flex{}.Layout(gtx,
+					layout.Flexed(1, func(gtx C) D {
+						return field(gtx, th, "Category", func(gtx C) D {
+							return a.category.Layout(gtx, th)
+						})
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+					layout.Flexed(1, func(gtx C) D {
+						return field(gtx, th, "Number format", func(gtx C) D {
+							return a.locales.Layout(gtx, th)
+						})
+					}),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(24)}.Layout),
+			layout.Rigid(func(gtx C) D {
+				if len(cat.Units) == 0 {
+					l := material.Body1(th, "Loading exchange rates…")
+					if a.ratesErr != nil {
+						l.Text, l.Color = a.ratesErr.Error(), errorColor
+					}
+					return l.Layout(gtx)
+				}
+				return a.layoutConversion(gtx, th, cat)
+			}),
+			layout.Rigid(func(gtx C) D {
+				if cat.Name != "Currency" {
+					return D{}
+				}
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx C) D {
+					return a.layoutRates(gtx, th)
+				})
+			}),
+		)
+	})
+}
+
+func (a *App) layoutConversion(gtx C, th *material.Theme, cat Category) D {
+	from, to := cat.Units[a.from.Selected()], cat.Units[a.to.Selected()]
+	v, err := a.locale.Parse(a.amount.Text())
+	// unitWidth is the width of the unit pickers.
+	unitWidth := gtx.Constraints.Max.X * 2 / 5
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return field(gtx, th, "From", func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, func(gtx C) D {
+						return widget.Border{
+							Color:        color.NRGBA{A: 0x40},
+							CornerRadius: unit.Dp(4),
+							Width:        unit.Px(1),
+						}.Layout(gtx, func(gtx C) D {
+							return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+								gtx.Constraints.Min.X = gtx.Constraints.Max.X
+								return material.Editor(th, &a.amount, "Amount").Layout(gtx)
+							})
+						})
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(func(gtx C) D {
+						gtx.Constraints.Max.X = unitWidth
+						return a.from.Layout(gtx, th)
+					}),
+				)
+			})
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.Center.Layout(gtx, func(gtx C) D {
+				return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+					b := material.IconButton(th, &a.swap, a.swapIcon)
+					b.Size = unit.Dp(20)
+					b.Inset = layout.UniformInset(unit.Dp(8))
+					return b.Layout(gtx)
+				})
+			})
+		}),
+		layout.Rigid(func(gtx C) D {
+			return field(gtx, th, "To", func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, func(gtx C) D {
+						l := material.H4(th, "")
+						l.MaxLines = 1
+						if err != nil {
+							l = material.Body1(th, err.Error())
+							l.Color = errorColor
+						} else {
+							l.Text = a.formatResult(Convert(v, from, to), cat, to)
+						}
+						return l.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(func(gtx C) D {
+						gtx.Constraints.Max.X = unitWidth
+						return a.to.Layout(gtx, th)
+					}),
+				)
+			})
+		}),
+		layout.Rigid(func(gtx C) D {
+			txt := fmt.Sprintf("1 %s = %s %s", from.Symbol, a.locale.Format(Convert(1, from, to), digits), to.Symbol)
+			l := material.Caption(th, txt)
+			l.Color = style.MulAlpha(l.Color, 0xa0)
+			return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, l.Layout)
+		}),
+	)
+}
+
+func (a *App) layoutRates(gtx C, th *material.Theme) D {
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			l := material.Caption(th, "")
+			l.Color = style.MulAlpha(l.Color, 0xa0)
+			switch {
+			case a.fetching:
+				l.Text = "Updating exchange rates…"
+			case a.ratesErr != nil && a.rates != nil:
+				l.Text = fmt.Sprintf("Using the rates of %s: %v", a.ratesDate(), a.ratesErr)
+				l.Color = errorColor
+			case a.rates != nil:
+				f := a.rates.Fetched
+				l.Text = fmt.Sprintf("Reference rates of the European Central Bank of %s, fetched at %s %s.",
+					a.ratesDate(), a.locale.ShortDate(f), f.Format("15:04"))
+			}
+			return l.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx C) D {
+			b := material.Button(th, &a.refresh, "Refresh")
+			b.TextSize = unit.Sp(12)
+			return b.Layout(gtx)
+		}),
+	)
+}
+
+// formatResult formats a converted value: an amount of money the way the
+// locale writes it, and other values with the symbol of their unit.
+func (a *App) formatResult(v float64, cat Category, to Unit) string {
+	if cat.Name == "Currency" {
+		if cur, err := xcurrency.ParseISO(to.Symbol); err == nil {
+			return a.locale.Amount(v, cur)
+		}
+	}
+	return a.locale.Format(v, digits) + " " + to.Symbol
+}
+
+// ratesDate formats the day the rates were published.
+func (a *App) ratesDate() string {
+	d, err := time.Parse("2006-01-02", a.rates.Date)
+	if err != nil {
+		return a.rates.Date
+	}
+	return a.locale.LongDate(d)
+}
+
+// field lays out a widget below a caption naming it.
+func field(gtx C, th *material.Theme, name string, w layout.Widget) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			l := material.Caption(th, name)
+			l.Font.Weight = text.Bold
+			return layout.Inset{Bottom: unit.Dp(4)}.Layout(gtx, l.Layout)
+		}),
+		layout.Rigid(w),
+	)
+}
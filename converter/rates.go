@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Rates are exchange rates of currencies, in units per one Base.
+type Rates struct {
+	Base string `json:"base"`
+	// Date is the day the rates were published.
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+	// Fetched is when the rates were downloaded.
+	Fetched time.Time `json:"fetched"`
+}
+
+// RateCache fetches exchange rates and keeps the last ones in a file, to
+// start without waiting and to convert offline.
+type RateCache struct {
+	URL    string
+	Path   string
+	Client *http.Client
+	// MaxAge is the age after which cached rates are refreshed.
+	MaxAge time.Duration
+}
+
+// ratesURL serves the reference rates of the European Central Bank.
+const ratesURL = "https://api.frankfurter.app/latest?from=EUR"
+
+func newRateCache() *RateCache {
+	c := &RateCache{
+		URL:    ratesURL,
+		Client: &http.Client{Timeout: 30 * time.Second},
+		MaxAge: 12 * time.Hour,
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		c.Path = filepath.Join(dir, "gio-converter", "rates.json")
+	}
+	return c
+}
+
+// Cached returns the cached rates, or nil if there are none.
+func (c *RateCache) Cached() *Rates {
+	if c.Path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(c.Path)
+	if err != nil {
+		return nil
+	}
+	r := new(Rates)
+	if err := json.Unmarshal(data, r); err != nil || len(r.Rates) == 0 {
+		return nil
+	}
+	return r
+}
+
+// Stale reports whether r should be refreshed at now.
+func (c *RateCache) Stale(r *Rates, now time.Time) bool {
+	return r == nil || now.Sub(r.Fetched) > c.MaxAge
+}
+
+// Fetch downloads the current rates and caches them. A failure to write
+// the cache is not an error.
+func (c *RateCache) Fetch(ctx context.Context) (*Rates, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching rates: %s", resp.Status)
+	}
+	r := new(Rates)
+	if err := json.NewDecoder(resp.Body).Decode(r); err != nil {
+		return nil, fmt.Errorf("decoding rates: %v", err)
+	}
+	if r.Base == "" || len(r.Rates) == 0 {
+		return nil, errors.New("decoding rates: no rates")
+	}
+	r.Rates[r.Base] = 1
+	r.Fetched = time.Now()
+	c.save(r)
+	return r, nil
+}
+
+func (c *RateCache) save(r *Rates) {
+	if c.Path == "" {
+		return
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0700); err != nil {
+		return
+	}
+	// Write and rename, so a crash doesn't leave a partial cache.
+	tmp := c.Path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return
+	}
+	os.Rename(tmp, c.Path)
+}
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRateCache(t *testing.T) {
+	up := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up {
+			http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"amount":1.0,"base":"EUR","date":"2021-05-20","rates":{"GBP":0.86,"USD":1.2225}}`)
+	}))
+	defer srv.Close()
+	c := &RateCache{
+		URL:    srv.URL,
+		Path:   filepath.Join(t.TempDir(), "cache", "rates.json"),
+		Client: srv.Client(),
+		MaxAge: time.Hour,
+	}
+	if r := c.Cached(); r != nil {
+		t.Fatalf("cached rates before fetching: %+v", r)
+	}
+	if !c.Stale(nil, time.Now()) {
+		t.Error("no rates are not stale")
+	}
+	r, err := c.Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Date != "2021-05-20" || r.Rates["USD"] != 1.2225 || r.Rates["EUR"] != 1 {
+		t.Errorf("rates %+v", r)
+	}
+	cached := c.Cached()
+	if cached == nil || cached.Rates["GBP"] != 0.86 || !cached.Fetched.Equal(r.Fetched) {
+		t.Fatalf("cached rates %+v, want %+v", cached, r)
+	}
+	if c.Stale(cached, r.Fetched.Add(30*time.Minute)) {
+		t.Error("rates stale after 30 minutes")
+	}
+	if !c.Stale(cached, r.Fetched.Add(2*time.Hour)) {
+		t.Error("rates not stale after 2 hours")
+	}
+
+	// A failed fetch keeps the cache.
+	up = false
+	if _, err := c.Fetch(context.Background()); err == nil {
+		t.Error("no error from a failed fetch")
+	}
+	if c.Cached() == nil {
+		t.Error("failed fetch removed the cache")
+	}
+}
@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import "sort"
+
+// Unit is a unit of measure of a category. A value in the unit converts
+// to the base unit of its category by multiplying by Scale and adding
+// Offset; only temperatures have offsets.
+type Unit struct {
+	Name   string
+	Symbol string
+	Scale  float64
+	Offset float64
+}
+
+// Category is a kind of quantity and its units, the first of which is
+// the base unit.
+type Category struct {
+	Name  string
+	Units []Unit
+}
+
+func (u Unit) toBase(v float64) float64 {
+	return v*u.Scale + u.Offset
+}
+
+func (u Unit) fromBase(v float64) float64 {
+	return (v - u.Offset) / u.Scale
+}
+
+// Convert converts v from one unit to another of the same category.
+func Convert(v float64, from, to Unit) float64 {
+	return to.fromBase(from.toBase(v))
+}
+
+var (
+	length = Category{
+		Name: "Length",
+		Units: []Unit{
+			{Name: "Metre", Symbol: "m", Scale: 1},
+			{Name: "Kilometre", Symbol: "km", Scale: 1e3},
+			{Name: "Centimetre", Symbol: "cm", Scale: 1e-2},
+			{Name: "Millimetre", Symbol: "mm", Scale: 1e-3},
+			{Name: "Micrometre", Symbol: "µm", Scale: 1e-6},
+			{Name: "Mile", Symbol: "mi", Scale: 1609.344},
+			{Name: "Yard", Symbol: "yd", Scale: 0.9144},
+			{Name: "Foot", Symbol: "ft", Scale: 0.3048},
+			{Name: "Inch", Symbol: "in", Scale: 0.0254},
+			{Name: "Nautical mile", Symbol: "nmi", Scale: 1852},
+			{Name: "Astronomical unit", Symbol: "au", Scale: 149597870700},
+			{Name: "Light-year", Symbol: "ly", Scale: 9460730472580800},
+		},
+	}
+	mass = Category{
+		Name: "Mass",
+		Units: []Unit{
+			{Name: "Kilogram", Symbol: "kg", Scale: 1},
+			{Name: "Gram", Symbol: "g", Scale: 1e-3},
+			{Name: "Milligram", Symbol: "mg", Scale: 1e-6},
+			{Name: "Tonne", Symbol: "t", Scale: 1e3},
+			{Name: "Pound", Symbol: "lb", Scale: 0.45359237},
+			{Name: "Ounce", Symbol: "oz", Scale: 0.028349523125},
+			{Name: "Stone", Symbol: "st", Scale: 6.35029318},
+			{Name: "Short ton", Symbol: "tn", Scale: 907.18474},
+			{Name: "Carat", Symbol: "ct", Scale: 2e-4},
+		},
+	}
+	temperature = Category{
+		Name: "Temperature",
+		Units: []Unit{
+			{Name: "Celsius", Symbol: "°C", Scale: 1},
+			{Name: "Fahrenheit", Symbol: "°F", Scale: 5.0 / 9, Offset: -32 * 5.0 / 9},
+			{Name: "Kelvin", Symbol: "K", Scale: 1, Offset: -273.15},
+			{Name: "Rankine", Symbol: "°R", Scale: 5.0 / 9, Offset: -273.15},
+		},
+	}
+)
+
+// currencyNames are the names of the currencies with reference rates
+// from the European Central Bank.
+var currencyNames = map[string]string{
+	"AUD": "Australian dollar",
+	"BGN": "Bulgarian lev",
+	"BRL": "Brazilian real",
+	"CAD": "Canadian dollar",
+	"CHF": "Swiss franc",
+	"CNY": "Chinese yuan",
+	"CZK": "Czech koruna",
+	"DKK": "Danish krone",
+	"EUR": "Euro",
+	"GBP": "Pound sterling",
+	"HKD": "Hong Kong dollar",
+	"HRK": "Croatian kuna",
+	"HUF": "Hungarian forint",
+	"IDR": "Indonesian rupiah",
+	"ILS": "Israeli new shekel",
+	"INR": "Indian rupee",
+	"ISK": "Icelandic króna",
+	"JPY": "Japanese yen",
+	"KRW": "South Korean won",
+	"MXN": "Mexican peso",
+	"MYR": "Malaysian ringgit",
+	"NOK": "Norwegian krone",
+	"NZD": "New Zealand dollar",
+	"PHP": "Philippine peso",
+	"PLN": "Polish złoty",
+	"RON": "Romanian leu",
+	"RUB": "Russian ruble",
+	"SEK": "Swedish krona",
+	"SGD": "Singapore dollar",
+	"THB": "Thai baht",
+	"TRY": "Turkish lira",
+	"USD": "United States dollar",
+	"ZAR": "South African rand",
+}
+
+// currency returns the currency category for rates, with the base
+// currency first and the rest sorted by code. Without rates it has no
+// units.
+func currency(r *Rates) Category {
+	c := Category{Name: "Currency"}
+	if r == nil {
+		return c
+	}
+	unit := func(code string, rate float64) Unit {
+		name, ok := currencyNames[code]
+		if !ok {
+			name = code
+		}
+		return Unit{Name: name, Symbol: code, Scale: 1 / rate}
+	}
+	c.Units = append(c.Units, unit(r.Base, 1))
+	var codes []string
+	for code := range r.Rates {
+		if code != r.Base {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		c.Units = append(c.Units, unit(code, r.Rates[code]))
+	}
+	return c
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// condition is a term of a filter.
+type condition struct {
+	// col is the column compared, or -1 for any column.
+	col   int
+	op    string
+	value string
+}
+
+// operators are the comparison operators, longest first.
+var operators = []string{"!=", ">=", "<=", "=", ">", "<", "~"}
+
+// parseFilter parses a filter of space separated terms that must all
+// match. A term is either text, which matches rows with a cell containing
+// it, or a comparison of a column with a value such as price>10,
+// name~smith or city=Oslo. Comparisons use the type of the column.
+func parseFilter(expr string, header []string) ([]condition, error) {
+	var conds []condition
+	for _, term := range strings.Fields(expr) {
+		cond := condition{col: -1, op: "~", value: strings.ToLower(term)}
+		for _, op := range operators {
+			i := strings.Index(term, op)
+			if i <= 0 {
+				continue
+			}
+			name := term[:i]
+			col := -1
+			for c, h := range header {
+				if strings.EqualFold(strings.TrimSpace(h), name) {
+					col = c
+				}
+			}
+			if col == -1 {
+				return nil, fmt.Errorf("no column named %q", name)
+			}
+			cond = condition{col: col, op: op, value: term[i+len(op):]}
+			break
+		}
+		conds = append(conds, cond)
+	}
+	return conds, nil
+}
+
+// match reports whether a row matches all conditions.
+func (t *Table) match(row []string, conds []condition) bool {
+	for _, c := range conds {
+		if c.col == -1 {
+			found := false
+			for _, v := range row {
+				if strings.Contains(strings.ToLower(v), c.value) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+			continue
+		}
+		v := cell(row, c.col)
+		var ok bool
+		switch c.op {
+		case "~":
+			ok = strings.Contains(strings.ToLower(v), strings.ToLower(c.value))
+		case "=":
+			ok = compare(t.Types[c.col], v, c.value) == 0 || strings.EqualFold(v, c.value)
+		case "!=":
+			ok = compare(t.Types[c.col], v, c.value) != 0 && !strings.EqualFold(v, c.value)
+		default:
+			if v == "" {
+				return false
+			}
+			cmp := compare(t.Types[c.col], v, c.value)
+			switch c.op {
+			case ">":
+				ok = cmp > 0
+			case "<":
+				ok = cmp < 0
+			case ">=":
+				ok = cmp >= 0
+			case "<=":
+				ok = cmp <= 0
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
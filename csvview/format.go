@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"strings"
+
+	"gioui.org/example/internal/i18n"
+)
+
+// formatCell formats a value of column c the way the locale writes it.
+// Values that don't parse as the type of the column are shown as they
+// are.
+func (t *Table) formatCell(l *i18n.Locale, c int, v string) string {
+	if v == "" {
+		return v
+	}
+	switch t.Types[c] {
+	case Integer, Decimal:
+		f, ok := parseNumber(v)
+		if !ok || strings.ContainsAny(v, "eE") {
+			return v
+		}
+		if cur, ok := t.Money[c]; ok {
+			return l.Amount(f, cur)
+		}
+		frac := 0
+		if i := strings.IndexByte(v, '.'); i >= 0 {
+			frac = len(v) - i - 1
+		}
+		return l.Number(f, frac)
+	case Date:
+		d, ok := parseDate(v)
+		if !ok {
+			return v
+		}
+		s := l.ShortDate(d)
+		if d.Hour() != 0 || d.Minute() != 0 || d.Second() != 0 {
+			s += " " + d.Format("15:04:05")
+		}
+		return s
+	}
+	return v
+}
+
+// formatNumber formats a sum or mean of column c, with frac fraction
+// digits unless the column is of amounts of money.
+func (t *Table) formatNumber(l *i18n.Locale, c int, v float64, frac int) string {
+	if cur, ok := t.Money[c]; ok {
+		return l.Amount(v, cur)
+	}
+	return l.Number(v, frac)
+}
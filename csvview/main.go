@@ -0,0 +1,357 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program views CSV and Excel files in the virtualized table of
+// internal/datagrid. Column types are inferred from the values and used
+// for sorting and filtering, the selected column is summarized, and the
+// filtered and sorted rows can be exported to CSV. Without a file, the
+// program shows generated orders.
+//
+// Numbers, amounts and dates are shown the way the locale writes them;
+// click the locale button to switch to the next one. Filters and the
+// exported file use the values of the file as they are.
+//
+// Usage:
+//
+//	go run ./csvview [-locale de-DE] [data.csv]
+//
+// The locale defaults to the one of the environment, from LC_ALL or
+// LANG.
+
+import (
+	"flag"
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/datagrid"
+	"gioui.org/example/internal/i18n"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var localeFlag = flag.String("locale", "", "locale of numbers and dates, such as de-DE (default from the environment)")
+
+func main() {
+	flag.Parse()
+	if flag.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "usage: csvview [-locale tag] [file.csv|file.xlsx]")
+		os.Exit(2)
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("CSV Viewer"),
+			app.Size(unit.Dp(1000), unit.Dp(700)),
+		)
+		if err := loop(w, flag.Arg(0)); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// load reads a CSV or Excel file, or generates a sample table if path
+// is empty.
+func load(path string) (*Table, error) {
+	if path == "" {
+		return sampleTable(), nil
+	}
+	if strings.EqualFold(filepath.Ext(path), ".xlsx") {
+		return readXLSX(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readCSV(f)
+}
+
+var errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+
+type loaded struct {
+	table *Table
+	err   error
+}
+
+type App struct {
+	path  string
+	table *Table
+	grid  *datagrid.Grid
+	// view lists the rows shown, filtered and sorted.
+	view   []int
+	err    error
+	status string
+	// filterErr is the error of the filter expression, if any.
+	filterErr error
+	// stats caches the statistics of the selected column.
+	stats    Stats
+	statsCol int
+
+	locale *i18n.Locale
+
+	filter widget.Editor
+	export widget.Clickable
+	next   widget.Clickable
+}
+
+func loop(w *app.Window, path string) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		path:     path,
+		filter:   widget.Editor{SingleLine: true, Submit: true},
+		statsCol: -1,
+		locale:   i18n.FromEnv(),
+	}
+	if *localeFlag != "" {
+		a.locale = i18n.Find(*localeFlag)
+	}
+	results := make(chan loaded, 1)
+	go func() {
+		t, err := load(path)
+		results <- loaded{t, err}
+	}()
+	var ops op.Ops
+	for {
+		select {
+		case res := <-results:
+			if res.err != nil {
+				a.err = res.err
+			} else {
+				a.setTable(res.table)
+			}
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update()
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+func (a *App) setTable(t *Table) {
+	a.table = t
+	cols := make([]datagrid.Column, len(t.Header))
+	for i, h := range t.Header {
+		cols[i] = datagrid.Column{Title: fmt.Sprintf("%s (%s)", h, t.Types[i]), Width: unit.Dp(140)}
+		if t.Types[i].Numeric() {
+			cols[i].Alignment = text.End
+		}
+	}
+	a.grid = datagrid.New(cols...)
+	a.applyFilter()
+}
+
+// applyFilter recomputes the rows shown.
+func (a *App) applyFilter() {
+	t := a.table
+	conds, err := parseFilter(a.filter.Text(), t.Header)
+	a.filterErr = err
+	if err != nil {
+		return
+	}
+	a.view = a.view[:0]
+	for i, r := range t.Rows {
+		if t.match(r, conds) {
+			a.view = append(a.view, i)
+		}
+	}
+	a.sort()
+}
+
+func (a *App) sort() {
+	col := a.grid.SortColumn
+	if col >= 0 {
+		t := a.table
+		typ, desc := t.Types[col], a.grid.SortDesc
+		sort.SliceStable(a.view, func(i, j int) bool {
+			c := compare(typ, cell(t.Rows[a.view[i]], col), cell(t.Rows[a.view[j]], col))
+			if desc {
+				return c > 0
+			}
+			return c < 0
+		})
+	}
+	a.statsCol = -1
+}
+
+func (a *App) update() {
+	if a.table == nil {
+		return
+	}
+	for _, e := range a.filter.Events() {
+		if _, ok := e.(widget.SubmitEvent); ok {
+			a.applyFilter()
+		}
+	}
+	if a.grid.Sorted() {
+		a.sort()
+	}
+	for a.next.Clicked() {
+		a.locale = nextLocale(a.locale)
+	}
+	for a.export.Clicked() {
+		if p, err := a.exportCSV(); err != nil {
+			a.status = err.Error()
+		} else {
+			a.status = fmt.Sprintf("Exported %d rows to %s.", len(a.view), p)
+		}
+	}
+	if _, col := a.grid.Selection(); col >= 0 && col < len(a.table.Header) && col != a.statsCol {
+		a.stats = a.table.columnStats(col, a.view)
+		a.statsCol = col
+	}
+}
+
+// nextLocale returns the locale after l in the supported locales.
+func nextLocale(l *i18n.Locale) *i18n.Locale {
+	for i, o := range i18n.Locales {
+		if o == l {
+			return i18n.Locales[(i+1)%len(i18n.Locales)]
+		}
+	}
+	return i18n.Locales[0]
+}
+
+// exportCSV writes the rows shown next to the file.
+func (a *App) exportCSV() (string, error) {
+	base := strings.TrimSuffix(a.path, filepath.Ext(a.path))
+	if base == "" {
+		base = "sample"
+	}
+	p := fmt.Sprintf("%s-export-%s.csv", base, time.Now().Format("20060102-150405"))
+	f, err := os.Create(p)
+	if err != nil {
+		return "", err
+	}
+	if err := a.table.writeCSV(f, a.view); err != nil {
+		f.Close()
+		return "", err
+	}
+	return p, f.Close()
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	switch {
+	case a.err != nil:
+		return layout.Center.Layout(gtx, material.Body1(th, a.err.Error()).Layout)
+	case a.table == nil:
+		return layout.Center.Layout(gtx, material.H6(th, "Loading "+filepath.Base(a.path)+"…").Layout)
+	}
+	t := a.table
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, func(gtx C) D {
+						gtx.Constraints.Min.X = gtx.Constraints.Max.X
+						return widget.Border{Color: color.NRGBA{A: 0x40}, CornerRadius: unit.Dp(4), Width: unit.Dp(1)}.Layout(gtx, func(gtx C) D {
+							return layout.UniformInset(unit.Dp(6)).Layout(gtx, material.Editor(th, &a.filter, "Filter, e.g. paris or city=Paris age>30 name~ann (Enter to apply)").Layout)
+						})
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(material.Button(th, &a.next, a.locale.Tag).Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(material.Button(th, &a.export, "Export CSV").Layout),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Flexed(1, func(gtx C) D {
+					return a.grid.Layout(gtx, th, len(a.view), func(gtx C, row, col int) D {
+						v := t.formatCell(a.locale, col, cell(t.Rows[a.view[row]], col))
+						l := material.Body2(th, v)
+						l.MaxLines = 1
+						if t.Types[col].Numeric() {
+							gtx.Constraints.Min.X = gtx.Constraints.Max.X
+							l.Alignment = text.End
+						}
+						return l.Layout(gtx)
+					})
+				}),
+				layout.Rigid(func(gtx C) D {
+					gtx.Constraints.Min.X = gtx.Px(unit.Dp(220))
+					gtx.Constraints.Max.X = gtx.Constraints.Min.X
+					return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+						return a.layoutStats(gtx, th)
+					})
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			loc := a.locale
+			l := material.Caption(th, fmt.Sprintf("%s of %s rows · %s columns · %s", loc.Int(len(a.view)), loc.Int(len(t.Rows)), loc.Int(len(t.Header)), loc.Name))
+			switch {
+			case a.filterErr != nil:
+				l.Text = a.filterErr.Error()
+				l.Color = errorColor
+			case a.status != "":
+				l.Text = a.status
+			}
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, l.Layout)
+		}),
+	)
+}
+
+func (a *App) layoutStats(gtx C, th *material.Theme) D {
+	if a.statsCol < 0 {
+		return material.Body2(th, "Select a cell to summarize its column.").Layout(gtx)
+	}
+	t, s, l, c := a.table, a.stats, a.locale, a.statsCol
+	lines := []string{
+		fmt.Sprintf("Type: %s", t.Types[c]),
+		"Values: " + l.Int(s.Count-s.Empty),
+		"Empty: " + l.Int(s.Empty),
+	}
+	if s.Distinct >= 0 {
+		lines = append(lines, "Distinct: "+l.Int(s.Distinct))
+	} else {
+		lines = append(lines, "Distinct: over "+l.Int(maxDistinct))
+	}
+	lines = append(lines, "Min: "+t.formatCell(l, c, s.Min), "Max: "+t.formatCell(l, c, s.Max))
+	if t.Types[c].Numeric() {
+		frac := 0
+		if t.Types[c] == Decimal {
+			frac = 2
+		}
+		lines = append(lines, "Sum: "+t.formatNumber(l, c, s.Sum, frac), "Mean: "+t.formatNumber(l, c, s.Mean, 2))
+	}
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, material.H6(th, t.Header[a.statsCol]).Layout)
+		}),
+	}
+	for _, l := range lines {
+		l := l
+		children = append(children, layout.Rigid(func(gtx C) D {
+			return layout.Inset{Bottom: unit.Dp(4)}.Layout(gtx, material.Body2(th, l).Layout)
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
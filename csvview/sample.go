@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"gioui.org/example/internal/fakedata"
+
+	"golang.org/x/text/currency"
+)
+
+// sampleTable generates a table of orders, for running without a file.
+func sampleTable() *Table {
+	f := fakedata.New(1)
+	customers := f.Names(200)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	t := &Table{Header: []string{"Date", "Customer", "Email", "Items", "Amount", "Paid"}}
+	for _, p := range f.TimeSeries(start, 6*time.Hour, 5000) {
+		c := customers[f.Intn(len(customers))]
+		items := 1 + f.Intn(8)
+		t.Rows = append(t.Rows, []string{
+			p.Time.Format("2006-01-02"),
+			c,
+			f.Email(c),
+			strconv.Itoa(items),
+			fmt.Sprintf("%.2f", p.Value*float64(items)/4),
+			strconv.FormatBool(f.Intn(10) != 0),
+		})
+	}
+	t.inferTypes()
+	t.Money = map[int]currency.Unit{4: currency.USD}
+	return t
+}
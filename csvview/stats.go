@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// maxDistinct limits the values tracked for counting distinct values.
+const maxDistinct = 100000
+
+// Stats summarizes the values of a column.
+type Stats struct {
+	Count, Empty int
+	// Distinct is the number of distinct values, or -1 if there are more
+	// than maxDistinct.
+	Distinct int
+	// Min and Max are the smallest and largest values.
+	Min, Max string
+	// Mean and Sum are set for numeric columns.
+	Mean, Sum float64
+}
+
+// columnStats computes the statistics of column c over the rows in view.
+func (t *Table) columnStats(c int, view []int) Stats {
+	var s Stats
+	typ := t.Types[c]
+	distinct := make(map[string]struct{})
+	numbers := 0
+	for _, i := range view {
+		v := cell(t.Rows[i], c)
+		s.Count++
+		if v == "" {
+			s.Empty++
+			continue
+		}
+		if distinct != nil {
+			distinct[v] = struct{}{}
+			if len(distinct) > maxDistinct {
+				distinct = nil
+			}
+		}
+		if s.Min == "" || compare(typ, v, s.Min) < 0 {
+			s.Min = v
+		}
+		if s.Max == "" || compare(typ, v, s.Max) > 0 {
+			s.Max = v
+		}
+		if typ.Numeric() {
+			if f, ok := parseNumber(v); ok {
+				s.Sum += f
+				numbers++
+			}
+		}
+	}
+	s.Distinct = -1
+	if distinct != nil {
+		s.Distinct = len(distinct)
+	}
+	if numbers > 0 {
+		s.Mean = s.Sum / float64(numbers)
+	}
+	return s
+}
@@ -0,0 +1,225 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/currency"
+)
+
+// Table is a loaded data file.
+type Table struct {
+	Header []string
+	Rows   [][]string
+	Types  []Type
+	// Money maps the columns of amounts of money to their currency.
+	Money map[int]currency.Unit
+}
+
+// Type is the inferred type of a column.
+type Type int
+
+const (
+	Text Type = iota
+	Integer
+	Decimal
+	Boolean
+	Date
+)
+
+func (t Type) String() string {
+	switch t {
+	case Integer:
+		return "integer"
+	case Decimal:
+		return "decimal"
+	case Boolean:
+		return "boolean"
+	case Date:
+		return "date"
+	default:
+		return "text"
+	}
+}
+
+// Numeric reports whether values of the type are numbers.
+func (t Type) Numeric() bool {
+	return t == Integer || t == Decimal
+}
+
+// dateLayouts are the date formats recognized.
+var dateLayouts = []string{"2006-01-02", "2006-01-02 15:04:05", time.RFC3339, "01/02/2006", "02.01.2006"}
+
+func parseDate(s string) (time.Time, bool) {
+	for _, l := range dateLayouts {
+		if t, err := time.Parse(l, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func parseNumber(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil && !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// inferType returns the narrowest type of the non-empty values.
+func inferType(values []string) Type {
+	isInt, isNum, isBool, isDate := true, true, true, true
+	seen := false
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		seen = true
+		if isInt {
+			_, err := strconv.ParseInt(v, 10, 64)
+			isInt = err == nil
+		}
+		if isNum {
+			_, isNum = parseNumber(v)
+		}
+		if isBool {
+			_, err := strconv.ParseBool(v)
+			isBool = err == nil
+		}
+		if isDate {
+			_, isDate = parseDate(v)
+		}
+	}
+	switch {
+	case !seen:
+		return Text
+	case isInt:
+		return Integer
+	case isNum:
+		return Decimal
+	case isBool:
+		return Boolean
+	case isDate:
+		return Date
+	default:
+		return Text
+	}
+}
+
+// maxInferRows is the number of rows sampled for type inference.
+const maxInferRows = 10000
+
+// inferTypes sets the column types from the first rows.
+func (t *Table) inferTypes() {
+	t.Types = make([]Type, len(t.Header))
+	rows := t.Rows
+	if len(rows) > maxInferRows {
+		rows = rows[:maxInferRows]
+	}
+	values := make([]string, len(rows))
+	for c := range t.Header {
+		for i, r := range rows {
+			values[i] = cell(r, c)
+		}
+		t.Types[c] = inferType(values)
+	}
+}
+
+// cell returns column c of a row, which may be short.
+func cell(row []string, c int) string {
+	if c < len(row) {
+		return row[c]
+	}
+	return ""
+}
+
+// readCSV reads a table from CSV data, detecting whether fields are
+// separated by commas, semicolons or tabs from the first line.
+func readCSV(r io.Reader) (*Table, error) {
+	br := bufio.NewReader(r)
+	first, err := br.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	if i := bytes.IndexByte(first, '\n'); i != -1 {
+		first = first[:i]
+	}
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	cr.Comma = ','
+	for _, sep := range []rune{'\t', ';'} {
+		if bytes.Count(first, []byte(string(sep))) > bytes.Count(first, []byte(string(cr.Comma))) {
+			cr.Comma = sep
+		}
+	}
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	t := new(Table)
+	if len(records) > 0 {
+		t.Header, t.Rows = records[0], records[1:]
+	}
+	t.inferTypes()
+	return t, nil
+}
+
+// writeCSV writes the header and the rows of t listed in view.
+func (t *Table) writeCSV(w io.Writer, view []int) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Header); err != nil {
+		return err
+	}
+	for _, i := range view {
+		if err := cw.Write(t.Rows[i]); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// compare compares two values of a type, ordering empty values first.
+func compare(typ Type, a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return -1
+	case b == "":
+		return 1
+	}
+	switch typ {
+	case Integer, Decimal:
+		x, ok1 := parseNumber(a)
+		y, ok2 := parseNumber(b)
+		if ok1 && ok2 {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	case Date:
+		x, ok1 := parseDate(a)
+		y, ok2 := parseDate(b)
+		if ok1 && ok2 {
+			switch {
+			case x.Before(y):
+				return -1
+			case x.After(y):
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gioui.org/example/internal/i18n"
+
+	"golang.org/x/text/currency"
+)
+
+const sample = `name;age;score;member;joined
+Ada;36;9.5;true;2020-01-15
+Bob;;7;false;2019-11-02
+Cy;29;8.25;true;
+`
+
+func TestReadCSV(t *testing.T) {
+	tbl, err := readCSV(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tbl.Header) != 5 || len(tbl.Rows) != 3 {
+		t.Fatalf("got %d columns and %d rows, want 5 and 3", len(tbl.Header), len(tbl.Rows))
+	}
+	want := []Type{Text, Integer, Decimal, Boolean, Date}
+	if !reflect.DeepEqual(tbl.Types, want) {
+		t.Errorf("got types %v, want %v", tbl.Types, want)
+	}
+	var buf bytes.Buffer
+	if err := tbl.writeCSV(&buf, []int{2, 0}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "name,age,score,member,joined\nCy,29,8.25,true,\nAda,36,9.5,true,2020-01-15\n"; got != want {
+		t.Errorf("got export %q, want %q", got, want)
+	}
+}
+
+func TestFilterAndStats(t *testing.T) {
+	tbl, err := readCSV(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		expr string
+		want []int
+	}{
+		{"", []int{0, 1, 2}},
+		{"ada", []int{0}},
+		{"age>30", []int{0}},
+		{"score>=8 member=true", []int{0, 2}},
+		{"joined<2020-01-01", []int{1}},
+		{"name~b", []int{1}},
+		{"age!=36", []int{1, 2}},
+	}
+	for _, test := range tests {
+		conds, err := parseFilter(test.expr, tbl.Header)
+		if err != nil {
+			t.Errorf("%q: %v", test.expr, err)
+			continue
+		}
+		var got []int
+		for i, r := range tbl.Rows {
+			if tbl.match(r, conds) {
+				got = append(got, i)
+			}
+		}
+		if !reflect.DeepEqual(got, test.want) && !(len(got) == 0 && len(test.want) == 0) {
+			t.Errorf("%q: got rows %v, want %v", test.expr, got, test.want)
+		}
+	}
+	if _, err := parseFilter("height>2", tbl.Header); err == nil {
+		t.Error("no error for an unknown column")
+	}
+	s := tbl.columnStats(2, []int{0, 1, 2})
+	if s.Count != 3 || s.Empty != 0 || s.Distinct != 3 || s.Min != "7" || s.Max != "9.5" || s.Mean != 24.75/3 {
+		t.Errorf("got score stats %+v", s)
+	}
+	if s := tbl.columnStats(1, []int{0, 1, 2}); s.Empty != 1 || s.Min != "29" {
+		t.Errorf("got age stats %+v", s)
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z := zip.NewWriter(f)
+	files := map[string]string{
+		"xl/sharedStrings.xml": `<sst><si><t>name</t></si><si><t>qty</t></si><si><r><t>Wid</t></r><r><t>get</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2"><v>12</v></c></row>
+<row r="4"><c r="A4" t="inlineStr"><is><t>Gear</t></is></c><c r="C4" t="b"><v>1</v></c></row>
+</sheetData></worksheet>`,
+	}
+	for name, content := range files {
+		w, err := z.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	tbl, err := readXLSX(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"Widget", "12"}, nil, {"Gear", "", "true"}}
+	if !reflect.DeepEqual(tbl.Header, []string{"name", "qty"}) || !reflect.DeepEqual(tbl.Rows, want) {
+		t.Errorf("got %q %q, want %q", tbl.Header, tbl.Rows, want)
+	}
+	if tbl.Types[1] != Integer {
+		t.Errorf("got type %v for qty, want integer", tbl.Types[1])
+	}
+}
+
+func TestSampleTable(t *testing.T) {
+	tab := sampleTable()
+	if len(tab.Rows) == 0 {
+		t.Fatal("no sample rows")
+	}
+	want := []Type{Date, Text, Text, Integer, Decimal, Boolean}
+	if !reflect.DeepEqual(tab.Types, want) {
+		t.Errorf("got types %v, want %v", tab.Types, want)
+	}
+}
+
+func TestFormatCell(t *testing.T) {
+	tbl, err := readCSV(strings.NewReader("n;price;score;when\n1234;1234.5;0.125;2020-01-15\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl.Money = map[int]currency.Unit{1: currency.EUR}
+	tests := []struct {
+		tag  string
+		want []string
+	}{
+		{"en-US", []string{"1,234", "€1,234.50", "0.125", "1/15/2020"}},
+		{"de-DE", []string{"1.234", "1.234,50\u00a0€", "0,125", "15.01.2020"}},
+	}
+	for _, test := range tests {
+		l := i18n.Find(test.tag)
+		for c, want := range test.want {
+			if got := tbl.formatCell(l, c, tbl.Rows[0][c]); got != want {
+				t.Errorf("%s in %s: %q, want %q", tbl.Header[c], test.tag, got, want)
+			}
+		}
+	}
+}
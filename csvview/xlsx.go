@@ -0,0 +1,181 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// readXLSX reads the first worksheet of an Excel workbook. Only cell
+// values are read; formulas are replaced by their cached results, and
+// dates appear as the serial numbers Excel stores.
+func readXLSX(name string) (*Table, error) {
+	z, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+	files := make(map[string]*zip.File)
+	var sheets []string
+	for _, f := range z.File {
+		files[f.Name] = f
+		if dir, _ := path.Split(f.Name); dir == "xl/worksheets/" && strings.HasSuffix(f.Name, ".xml") {
+			sheets = append(sheets, f.Name)
+		}
+	}
+	if len(sheets) == 0 {
+		return nil, errors.New("xlsx: no worksheet")
+	}
+	sheet := "xl/worksheets/sheet1.xml"
+	if files[sheet] == nil {
+		sort.Strings(sheets)
+		sheet = sheets[0]
+	}
+	var shared []string
+	if f := files["xl/sharedStrings.xml"]; f != nil {
+		r, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		shared, err = readSharedStrings(r)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	r, err := files[sheet].Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	rows, err := readSheet(r, shared)
+	if err != nil {
+		return nil, err
+	}
+	t := new(Table)
+	if len(rows) > 0 {
+		t.Header, t.Rows = rows[0], rows[1:]
+	}
+	t.inferTypes()
+	return t, nil
+}
+
+// readSharedStrings reads the shared string table of a workbook.
+func readSharedStrings(r io.Reader) ([]string, error) {
+	var sst struct {
+		Items []struct {
+			T    string `xml:"t"`
+			Runs []struct {
+				T string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	if err := xml.NewDecoder(r).Decode(&sst); err != nil {
+		return nil, err
+	}
+	strs := make([]string, len(sst.Items))
+	for i, si := range sst.Items {
+		s := si.T
+		for _, r := range si.Runs {
+			s += r.T
+		}
+		strs[i] = s
+	}
+	return strs, nil
+}
+
+// xlsxCell is a cell of a worksheet.
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline string `xml:"is>t"`
+}
+
+// readSheet reads the rows of a worksheet, streaming them to keep large
+// sheets out of memory as XML.
+func readSheet(r io.Reader, shared []string) ([][]string, error) {
+	d := xml.NewDecoder(r)
+	var rows [][]string
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != "row" {
+			continue
+		}
+		var row struct {
+			Ref   int        `xml:"r,attr"`
+			Cells []xlsxCell `xml:"c"`
+		}
+		if err := d.DecodeElement(&row, &se); err != nil {
+			return nil, err
+		}
+		// Rows and cells may be omitted when empty.
+		for row.Ref > len(rows)+1 {
+			rows = append(rows, nil)
+		}
+		var cells []string
+		for _, c := range row.Cells {
+			col := columnIndex(c.Ref)
+			if col < 0 {
+				col = len(cells)
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+			cells[col] = cellValue(c, shared)
+		}
+		rows = append(rows, cells)
+	}
+}
+
+func cellValue(c xlsxCell, shared []string) string {
+	switch c.Type {
+	case "s":
+		i, err := strconv.Atoi(c.Value)
+		if err != nil || i < 0 || i >= len(shared) {
+			return ""
+		}
+		return shared[i]
+	case "inlineStr":
+		return c.Inline
+	case "b":
+		if c.Value == "1" {
+			return "true"
+		}
+		return "false"
+	default:
+		return c.Value
+	}
+}
+
+// columnIndex returns the column of a cell reference such as "AB12", or
+// -1.
+func columnIndex(ref string) int {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		n++
+	}
+	if n == 0 {
+		return -1
+	}
+	return col - 1
+}
@@ -0,0 +1,202 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"encoding/json"
+	"image"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Tile is a widget of the dashboard, spanning W by H cells of the grid.
+type Tile struct {
+	ID   int    `json:"-"`
+	Kind string `json:"kind"`
+	W    int    `json:"w"`
+	H    int    `json:"h"`
+}
+
+// maxCols is the number of columns of the widest grid, and maxHeight
+// the tallest tile, in cells.
+const (
+	maxCols   = 8
+	maxHeight = 4
+)
+
+// Board is the arrangement of the tiles of the dashboard. The tiles are
+// ordered; Pack places them on the grid in order.
+type Board struct {
+	Tiles  []Tile
+	nextID int
+}
+
+// NewBoard returns a board of tiles, assigning their IDs.
+func NewBoard(tiles []Tile) *Board {
+	b := new(Board)
+	for _, t := range tiles {
+		b.Add(t.Kind, t.W, t.H)
+	}
+	return b
+}
+
+// LoadBoard loads a board saved with Save. A missing file has no board
+// and no error.
+func LoadBoard(path string) (*Board, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var saved struct {
+		Tiles []Tile `json:"tiles"`
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+	return NewBoard(saved.Tiles), nil
+}
+
+// Save saves the board to a file, atomically.
+func (b *Board) Save(path string) error {
+	data, err := json.MarshalIndent(struct {
+		Tiles []Tile `json:"tiles"`
+	}{b.Tiles}, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Add adds a tile at the end, and returns its ID.
+func (b *Board) Add(kind string, w, h int) int {
+	b.nextID++
+	b.Tiles = append(b.Tiles, Tile{ID: b.nextID, Kind: kind, W: clampInt(w, 1, maxCols), H: clampInt(h, 1, maxHeight)})
+	return b.nextID
+}
+
+// Index returns the index of a tile, or -1.
+func (b *Board) Index(id int) int {
+	for i, t := range b.Tiles {
+		if t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (b *Board) Remove(id int) {
+	if i := b.Index(id); i >= 0 {
+		b.Tiles = append(b.Tiles[:i:i], b.Tiles[i+1:]...)
+	}
+}
+
+// Move moves a tile to an index, shifting the tiles in between. It
+// reports whether the order changed.
+func (b *Board) Move(id, to int) bool {
+	i := b.Index(id)
+	if i < 0 || to < 0 || to >= len(b.Tiles) || i == to {
+		return false
+	}
+	t := b.Tiles[i]
+	if i < to {
+		copy(b.Tiles[i:], b.Tiles[i+1:to+1])
+	} else {
+		copy(b.Tiles[to+1:], b.Tiles[to:i])
+	}
+	b.Tiles[to] = t
+	return true
+}
+
+// Resize resizes a tile to w by h cells, at most maxCols wide and
+// maxHeight tall. It reports whether the size changed.
+func (b *Board) Resize(id, w, h int) bool {
+	i := b.Index(id)
+	if i < 0 {
+		return false
+	}
+	w, h = clampInt(w, 1, maxCols), clampInt(h, 1, maxHeight)
+	t := &b.Tiles[i]
+	if t.W == w && t.H == h {
+		return false
+	}
+	t.W, t.H = w, h
+	return true
+}
+
+// Pack places tiles on a grid of columns, in order, each at the first
+// free cells from the top left that fit it. Tiles wider than the grid
+// are narrowed to fit. Pack returns the cells of the tiles.
+func Pack(tiles []Tile, cols int) []image.Rectangle {
+	var used [][]bool
+	free := func(r image.Rectangle) bool {
+		for y := r.Min.Y; y < r.Max.Y && y < len(used); y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				if used[y][x] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	cells := make([]image.Rectangle, len(tiles))
+	// first is the first row with free cells.
+	first := 0
+	for i, t := range tiles {
+		w := clampInt(t.W, 1, cols)
+		var r image.Rectangle
+		for y := first; ; y++ {
+			x := 0
+			for ; x+w <= cols; x++ {
+				if r = image.Rect(x, y, x+w, y+t.H); free(r) {
+					break
+				}
+			}
+			if x+w <= cols {
+				break
+			}
+		}
+		for len(used) < r.Max.Y {
+			used = append(used, make([]bool, cols))
+		}
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				used[y][x] = true
+			}
+		}
+		for first < len(used) && full(used[first]) {
+			first++
+		}
+		cells[i] = r
+	}
+	return cells
+}
+
+func full(row []bool) bool {
+	for _, u := range row {
+		if !u {
+			return false
+		}
+	}
+	return true
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
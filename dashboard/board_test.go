@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPack(t *testing.T) {
+	tiles := []Tile{
+		{W: 2, H: 2},
+		{W: 1, H: 1},
+		{W: 1, H: 1},
+		{W: 3, H: 1},
+		{W: 5, H: 1},
+		{W: 1, H: 1},
+	}
+	got := Pack(tiles, 3)
+	want := []image.Rectangle{
+		image.Rect(0, 0, 2, 2),
+		// The small tiles fill the column beside the first.
+		image.Rect(2, 0, 3, 1),
+		image.Rect(2, 1, 3, 2),
+		image.Rect(0, 2, 3, 3),
+		// Tiles wider than the grid are narrowed.
+		image.Rect(0, 3, 3, 4),
+		image.Rect(0, 4, 1, 5),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Pack = %v, want %v", got, want)
+	}
+	// A tile too wide for the gap left by a tall one goes below it.
+	got = Pack([]Tile{{W: 1, H: 3}, {W: 2, H: 1}, {W: 3, H: 1}}, 3)
+	want = []image.Rectangle{image.Rect(0, 0, 1, 3), image.Rect(1, 0, 3, 1), image.Rect(0, 3, 3, 4)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Pack = %v, want %v", got, want)
+	}
+}
+
+func TestBoard(t *testing.T) {
+	b := NewBoard([]Tile{{Kind: "a", W: 1, H: 1}, {Kind: "b", W: 2, H: 1}, {Kind: "c", W: 1, H: 9}})
+	kinds := func() string {
+		var s string
+		for _, t := range b.Tiles {
+			s += t.Kind
+		}
+		return s
+	}
+	if h := b.Tiles[2].H; h != maxHeight {
+		t.Errorf("tile %d cells tall, want at most %d", h, maxHeight)
+	}
+	a, c := b.Tiles[0].ID, b.Tiles[2].ID
+	if !b.Move(a, 2) || kinds() != "bca" {
+		t.Errorf("moved a to the end: %s, want bca", kinds())
+	}
+	if !b.Move(c, 0) || kinds() != "cba" {
+		t.Errorf("moved c to the start: %s, want cba", kinds())
+	}
+	if b.Move(c, 0) || b.Move(c, 3) {
+		t.Error("moved c in place or out of the board")
+	}
+	if !b.Resize(a, 0, 2) || b.Resize(a, 1, 2) {
+		t.Error("resize didn't report the change of size")
+	}
+	id := b.Add("d", 1, 1)
+	b.Remove(b.Tiles[1].ID)
+	if kinds() != "cad" || b.Index(id) != 2 {
+		t.Errorf("added d and removed b: %s, want cad", kinds())
+	}
+
+	path := filepath.Join(t.TempDir(), "dashboard", "layout.json")
+	if err := b.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadBoard(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	strip := func(tiles []Tile) []Tile {
+		var s []Tile
+		for _, t := range tiles {
+			t.ID = 0
+			s = append(s, t)
+		}
+		return s
+	}
+	if !reflect.DeepEqual(strip(loaded.Tiles), strip(b.Tiles)) {
+		t.Errorf("loaded %v, want %v", loaded.Tiles, b.Tiles)
+	}
+	if missing, err := LoadBoard(filepath.Join(t.TempDir(), "none.json")); missing != nil || err != nil {
+		t.Errorf("loading a missing file: %v, %v", missing, err)
+	}
+}
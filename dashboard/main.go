@@ -0,0 +1,511 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates a dashboard of tiles on a grid: charts, a
+// gauge, a counter and a list of made up live data. Drag a tile by its
+// title to move it, drag the handle at its lower right corner to resize
+// it, and add tiles from the catalog. The arrangement is saved after
+// every change and restored on the next start.
+//
+// Usage:
+//
+//	go run ./dashboard [-layout file]
+//
+// The layout is saved to gio-dashboard/layout.json in the user's
+// configuration directory, unless -layout names another file.
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/style"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/gesture"
+	"gioui.org/io/pointer"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var layoutFlag = flag.String("layout", "", "file of the saved layout")
+
+// tick is the interval between updates of the data.
+const tick = time.Second
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Dashboard"),
+			app.Size(unit.Dp(1100), unit.Dp(760)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+const (
+	// cellWidth is the narrowest width of a cell of the grid, and
+	// cellHeight its height.
+	cellWidth  = 180
+	cellHeight = 130
+	gap        = 12
+)
+
+var (
+	background = color.NRGBA{R: 0xee, G: 0xef, B: 0xf3, A: 0xff}
+	tileBg     = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	shadow     = color.NRGBA{A: 0x30}
+	errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+)
+
+var closeIcon = func() *widget.Icon {
+	ic, _ := widget.NewIcon(icons.NavigationClose)
+	return ic
+}()
+
+// tileState is the state of the widgets of a tile.
+type tileState struct {
+	move   gesture.Drag
+	resize gesture.Drag
+	remove widget.Clickable
+	list   layout.List
+	// pos is where the tile is drawn, in the coordinates of the grid. It
+	// glides to the place of the tile on the grid.
+	pos    f32.Rectangle
+	placed bool
+}
+
+type App struct {
+	board  *Board
+	path   string
+	states map[int]*tileState
+	data   *Data
+	// err is the last error of saving the layout.
+	err error
+
+	// cells are the cells of the tiles in the last frame, and pitch the
+	// distance between cells.
+	cells []image.Rectangle
+	pitch f32.Point
+	list  layout.List
+
+	// dragging is the ID of the tile being moved, grab where it was
+	// grabbed, in the tile, and pointer where the pointer is, in the
+	// grid. cell is the cell last moved to.
+	dragging int
+	grab     f32.Point
+	pointer  f32.Point
+	cell     image.Point
+
+	// resizing is the ID of the tile being resized, from its size at
+	// the start of the drag.
+	resizing    int
+	resizeStart f32.Point
+	startSize   image.Point
+
+	showCatalog bool
+	add         widget.Clickable
+	reset       widget.Clickable
+	kindClicks  map[string]*widget.Clickable
+
+	// last is the time of the last frame, for animations.
+	last time.Time
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	path := *layoutFlag
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(dir, "gio-dashboard", "layout.json")
+	}
+	board, err := LoadBoard(path)
+	if err != nil {
+		return err
+	}
+	if board == nil {
+		board = NewBoard(defaultTiles())
+	}
+	a := &App{
+		board:      board,
+		path:       path,
+		states:     make(map[int]*tileState),
+		data:       newData(time.Now()),
+		list:       layout.List{Axis: layout.Vertical},
+		kindClicks: make(map[string]*widget.Clickable),
+	}
+	for _, k := range kindOrder {
+		a.kindClicks[k] = new(widget.Clickable)
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	var ops op.Ops
+	for {
+		select {
+		case t := <-ticker.C:
+			a.data.Tick(t)
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update(gtx)
+				a.layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+func (a *App) state(id int) *tileState {
+	st := a.states[id]
+	if st == nil {
+		st = new(tileState)
+		a.states[id] = st
+	}
+	return st
+}
+
+func (a *App) save() {
+	a.err = a.board.Save(a.path)
+}
+
+func (a *App) update(gtx C) {
+	for a.add.Clicked() {
+		a.showCatalog = !a.showCatalog
+	}
+	for k, click := range a.kindClicks {
+		for click.Clicked() {
+			kind := tileKinds[k]
+			a.board.Add(k, kind.W, kind.H)
+			a.showCatalog = false
+			a.list.ScrollToEnd = true
+			a.save()
+		}
+	}
+	for a.reset.Clicked() {
+		a.board = NewBoard(defaultTiles())
+		a.states = make(map[int]*tileState)
+		a.save()
+	}
+	for _, t := range append([]Tile(nil), a.board.Tiles...) {
+		st := a.state(t.ID)
+		for st.remove.Clicked() {
+			a.board.Remove(t.ID)
+			delete(a.states, t.ID)
+			a.save()
+		}
+		// The positions of the events are in the coordinates of the
+		// tile where it was drawn.
+		for _, e := range st.move.Events(gtx.Metric, gtx, gesture.Both) {
+			p := st.pos.Min.Add(e.Position)
+			switch e.Type {
+			case pointer.Press:
+				a.dragging, a.grab, a.pointer = t.ID, e.Position, p
+				a.cell = image.Pt(-1, -1)
+				a.list.ScrollToEnd = false
+			case pointer.Drag:
+				if a.dragging == t.ID {
+					a.pointer = p
+					a.reorder()
+				}
+			case pointer.Release, pointer.Cancel:
+				if a.dragging == t.ID {
+					a.dragging = 0
+					a.save()
+				}
+			}
+		}
+		for _, e := range st.resize.Events(gtx.Metric, gtx, gesture.Both) {
+			p := st.pos.Min.Add(e.Position)
+			switch e.Type {
+			case pointer.Press:
+				a.resizing, a.resizeStart = t.ID, p
+				a.startSize = image.Pt(t.W, t.H)
+				a.list.ScrollToEnd = false
+			case pointer.Drag:
+				if a.resizing == t.ID {
+					d := p.Sub(a.resizeStart)
+					w := a.startSize.X + round(d.X/a.pitch.X)
+					h := a.startSize.Y + round(d.Y/a.pitch.Y)
+					a.board.Resize(t.ID, w, h)
+				}
+			case pointer.Release, pointer.Cancel:
+				if a.resizing == t.ID {
+					a.resizing = 0
+					a.save()
+				}
+			}
+		}
+	}
+}
+
+// reorder moves the dragged tile to the place of the tile under the
+// pointer, once per cell the pointer enters.
+func (a *App) reorder() {
+	if a.pitch.X == 0 || a.pointer.X < 0 || a.pointer.Y < 0 {
+		return
+	}
+	cell := image.Pt(int(a.pointer.X/a.pitch.X), int(a.pointer.Y/a.pitch.Y))
+	if cell == a.cell {
+		return
+	}
+	a.cell = cell
+	for i, c := range a.cells {
+		if i < len(a.board.Tiles) && cell.In(c) && a.board.Tiles[i].ID != a.dragging {
+			a.board.Move(a.dragging, i)
+			return
+		}
+	}
+}
+
+func round(v float32) int {
+	if v < 0 {
+		return -int(-v + .5)
+	}
+	return int(v + .5)
+}
+
+func (a *App) layout(gtx C, th *material.Theme) D {
+	paint.Fill(gtx.Ops, background)
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return a.layoutBar(gtx, th)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return a.list.Layout(gtx, 1, func(gtx C, _ int) D {
+				return a.layoutGrid(gtx, th)
+			})
+		}),
+	)
+}
+
+func (a *App) layoutBar(gtx C, th *material.Theme) D {
+	return layout.UniformInset(unit.Dp(gap)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(material.H6(th, "Dashboard").Layout),
+					layout.Flexed(1, func(gtx C) D {
+						if a.err == nil {
+							return D{}
+						}
+						l := material.Body2(th, a.err.Error())
+						l.Color = errorColor
+						return layout.Inset{Left: unit.Dp(16)}.Layout(gtx, l.Layout)
+					}),
+					layout.Rigid(material.Button(th, &a.reset, "Reset").Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(func(gtx C) D {
+						label := "Add tile"
+						if a.showCatalog {
+							label = "Close"
+						}
+						return material.Button(th, &a.add, label).Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx C) D {
+				if !a.showCatalog {
+					return D{}
+				}
+				var chips []layout.FlexChild
+				for _, k := range kindOrder {
+					k := k
+					chips = append(chips, layout.Rigid(func(gtx C) D {
+						return layout.Inset{Top: unit.Dp(8), Right: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+							b := material.Button(th, a.kindClicks[k], "+ "+tileKinds[k].Title)
+							b.Background = style.MulAlpha(th.ContrastBg, 0x30)
+							b.Color = th.Fg
+							return b.Layout(gtx)
+						})
+					}))
+				}
+				return layout.Flex{}.Layout(gtx, chips...)
+			}),
+		)
+	})
+}
+
+// layoutGrid lays out the tiles on a grid as wide as the constraints,
+// of as many columns of at least cellWidth as fit.
+func (a *App) layoutGrid(gtx C, th *material.Theme) D {
+	dt := gtx.Now.Sub(a.last).Seconds()
+	a.last = gtx.Now
+	width := gtx.Constraints.Max.X
+	g := float32(gtx.Px(unit.Dp(gap)))
+	cols := clampInt(int((float32(width)-g)/float32(gtx.Px(unit.Dp(cellWidth))+int(g))), 1, maxCols)
+	a.pitch = f32.Pt((float32(width)-g)/float32(cols), float32(gtx.Px(unit.Dp(cellHeight)))+g)
+	a.cells = Pack(a.board.Tiles, cols)
+	place := func(c image.Rectangle) f32.Rectangle {
+		return f32.Rectangle{
+			Min: f32.Pt(g+float32(c.Min.X)*a.pitch.X, g+float32(c.Min.Y)*a.pitch.Y),
+			Max: f32.Pt(float32(c.Max.X)*a.pitch.X, float32(c.Max.Y)*a.pitch.Y),
+		}
+	}
+	rows := 0
+	animating := false
+	var dragged *Tile
+	for i := range a.board.Tiles {
+		t := &a.board.Tiles[i]
+		c := a.cells[i]
+		if c.Max.Y > rows {
+			rows = c.Max.Y
+		}
+		st := a.state(t.ID)
+		target := place(c)
+		if t.ID == a.dragging {
+			// The dragged tile follows the pointer, above a placeholder
+			// of its place.
+			dragged = t
+			paint.FillShape(gtx.Ops, style.MulAlpha(th.ContrastBg, 0x30), clip.UniformRRect(target, 8).Op(gtx.Ops))
+			continue
+		}
+		if glide(st, target, dt) {
+			animating = true
+		}
+		a.layoutTile(gtx, th, t, st)
+	}
+	if dragged != nil {
+		st := a.state(dragged.ID)
+		size := st.pos.Size()
+		// The tile takes the size of its place, which resizes on a grid
+		// of different columns.
+		if target := place(a.cells[a.board.Index(dragged.ID)]); target.Size() != size {
+			size = target.Size()
+		}
+		st.pos.Min = a.pointer.Sub(a.grab)
+		st.pos.Max = st.pos.Min.Add(size)
+		r := st.pos.Add(f32.Pt(0, 4))
+		paint.FillShape(gtx.Ops, shadow, clip.UniformRRect(r, 8).Op(gtx.Ops))
+		a.layoutTile(gtx, th, dragged, st)
+	}
+	if animating {
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+	return D{Size: image.Pt(width, int(g+float32(rows)*a.pitch.Y))}
+}
+
+// glide moves a tile towards its place, and reports whether it is still
+// on its way.
+func glide(st *tileState, target f32.Rectangle, dt float64) bool {
+	if !st.placed || dt <= 0 {
+		st.pos, st.placed = target, true
+		return false
+	}
+	t := float32(dt * 15)
+	if t > 1 {
+		t = 1
+	}
+	lerp := func(a, b f32.Point) f32.Point {
+		return a.Add(b.Sub(a).Mul(t))
+	}
+	st.pos = f32.Rectangle{Min: lerp(st.pos.Min, target.Min), Max: lerp(st.pos.Max, target.Max)}
+	d := st.pos.Min.Sub(target.Min)
+	e := st.pos.Max.Sub(target.Max)
+	if d.X*d.X+d.Y*d.Y+e.X*e.X+e.Y*e.Y < 0.25 {
+		st.pos = target
+		return false
+	}
+	return true
+}
+
+// layoutTile lays out a tile at its position: a title bar to drag it
+// by, its content and the resize handle.
+func (a *App) layoutTile(gtx C, th *material.Theme, t *Tile, st *tileState) {
+	kind, ok := tileKinds[t.Kind]
+	if !ok {
+		kind = tileKind{Title: t.Kind, Layout: func(gtx C, th *material.Theme, d *Data, st *tileState) D {
+			return layout.Center.Layout(gtx, material.Body2(th, "Unknown tile").Layout)
+		}}
+	}
+	defer op.Save(gtx.Ops).Load()
+	op.Offset(st.pos.Min).Add(gtx.Ops)
+	size := image.Pt(int(st.pos.Dx()), int(st.pos.Dy()))
+	rr := float32(gtx.Px(unit.Dp(8)))
+	paint.FillShape(gtx.Ops, tileBg, clip.UniformRRect(f32.Rectangle{Max: layout.FPt(size)}, rr).Op(gtx.Ops))
+	gtx.Constraints = layout.Exact(size)
+
+	title := gtx.Px(unit.Dp(40))
+	// The areas of the gestures are in the coordinates of the tile.
+	area := op.Save(gtx.Ops)
+	pointer.Rect(image.Rect(0, 0, size.X, title)).Add(gtx.Ops)
+	pointer.CursorNameOp{Name: pointer.CursorGrab}.Add(gtx.Ops)
+	st.move.Add(gtx.Ops)
+	area.Load()
+
+	layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			gtx.Constraints.Min.Y = title
+			gtx.Constraints.Max.Y = title
+			return layout.Inset{Left: unit.Dp(12), Right: unit.Dp(4)}.Layout(gtx, func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, material.Body1(th, kind.Title).Layout),
+					layout.Rigid(func(gtx C) D {
+						b := material.IconButton(th, &st.remove, closeIcon)
+						b.Background = color.NRGBA{}
+						b.Color = style.MulAlpha(th.Fg, 0x80)
+						b.Size = unit.Dp(18)
+						b.Inset = layout.UniformInset(unit.Dp(6))
+						return b.Layout(gtx)
+					}),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Inset{Left: unit.Dp(12), Right: unit.Dp(12), Bottom: unit.Dp(12)}.Layout(gtx, func(gtx C) D {
+				return kind.Layout(gtx, th, a.data, st)
+			})
+		}),
+	)
+
+	// The resize handle: three diagonal lines in the corner.
+	h := gtx.Px(unit.Dp(20))
+	corner := image.Rectangle{Min: size.Sub(image.Pt(h, h)), Max: size}
+	area = op.Save(gtx.Ops)
+	pointer.Rect(corner).Add(gtx.Ops)
+	pointer.CursorNameOp{Name: pointer.CursorCrossHair}.Add(gtx.Ops)
+	st.resize.Add(gtx.Ops)
+	area.Load()
+	col := style.MulAlpha(th.Fg, 0x60)
+	if t.ID == a.resizing {
+		col = th.ContrastBg
+	}
+	var p clip.Path
+	p.Begin(gtx.Ops)
+	m := layout.FPt(size).Sub(f32.Pt(4, 4))
+	for i := 1; i <= 3; i++ {
+		d := float32(i * h / 4)
+		p.MoveTo(m.Sub(f32.Pt(d, 0)))
+		p.LineTo(m.Sub(f32.Pt(0, d)))
+	}
+	paint.FillShape(gtx.Ops, col, clip.Stroke{Path: p.End(), Style: clip.StrokeStyle{Width: float32(gtx.Px(unit.Dp(1.5)))}}.Op())
+}
@@ -0,0 +1,267 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"time"
+
+	"gioui.org/example/internal/style"
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"gioui.org/example/internal/fakedata"
+)
+
+// Data is the live data of the tiles, made up.
+type Data struct {
+	f *fakedata.Faker
+	// Traffic is the requests per second, a sample per tick, newest last.
+	Traffic []float64
+	// CPU is the load in percent.
+	CPU      float64
+	Visitors int
+	// Sales is the number of sales of the last seven days, today last.
+	Sales  [7]int
+	Orders []Order
+}
+
+type Order struct {
+	Customer string
+	Amount   float64
+	Time     time.Time
+}
+
+// samples is the number of traffic samples kept, and maxOrders the
+// number of recent orders.
+const (
+	samples   = 60
+	maxOrders = 20
+)
+
+func newData(now time.Time) *Data {
+	f := fakedata.New(1)
+	d := &Data{f: f, CPU: 35, Visitors: 120}
+	for _, p := range f.TimeSeries(now.Add(-samples*tick), tick, samples) {
+		d.Traffic = append(d.Traffic, p.Value)
+	}
+	for i := range d.Sales {
+		d.Sales[i] = 20 + f.Intn(60)
+	}
+	for i := 0; i < 8; i++ {
+		d.Orders = append(d.Orders, d.order(now.Add(-time.Duration(i)*7*time.Minute)))
+	}
+	return d
+}
+
+func (d *Data) order(t time.Time) Order {
+	return Order{Customer: d.f.Name(), Amount: 5 + d.f.Float64()*195, Time: t}
+}
+
+// Tick moves the data along by a tick.
+func (d *Data) Tick(now time.Time) {
+	last := d.Traffic[len(d.Traffic)-1]
+	next := math.Max(0, last+(100-last)*0.05+(d.f.Float64()-0.5)*12)
+	d.Traffic = append(d.Traffic[1:], next)
+	d.CPU = math.Min(100, math.Max(0, d.CPU+(40-d.CPU)*0.1+(d.f.Float64()-0.5)*15))
+	d.Visitors += d.f.Intn(11) - 5
+	if d.Visitors < 0 {
+		d.Visitors = 0
+	}
+	if d.f.Intn(4) == 0 {
+		d.Orders = append([]Order{d.order(now)}, d.Orders...)
+		if len(d.Orders) > maxOrders {
+			d.Orders = d.Orders[:maxOrders]
+		}
+		d.Sales[len(d.Sales)-1]++
+	}
+}
+
+// tileKind is a kind of tile of the catalog.
+type tileKind struct {
+	Title string
+	// W and H are the size of new tiles, in cells.
+	W, H   int
+	Layout func(gtx C, th *material.Theme, d *Data, st *tileState) D
+}
+
+var tileKinds = map[string]tileKind{
+	"traffic":  {Title: "Traffic", W: 3, H: 2, Layout: layoutTraffic},
+	"cpu":      {Title: "CPU load", W: 1, H: 2, Layout: layoutCPU},
+	"visitors": {Title: "Visitors online", W: 1, H: 1, Layout: layoutVisitors},
+	"sales":    {Title: "Sales this week", W: 2, H: 2, Layout: layoutSales},
+	"orders":   {Title: "Recent orders", W: 2, H: 3, Layout: layoutOrders},
+}
+
+// kindOrder is the order of the kinds in the catalog.
+var kindOrder = []string{"traffic", "cpu", "visitors", "sales", "orders"}
+
+// defaultTiles is the board of a new dashboard.
+func defaultTiles() []Tile {
+	var tiles []Tile
+	for _, k := range kindOrder {
+		kind := tileKinds[k]
+		tiles = append(tiles, Tile{Kind: k, W: kind.W, H: kind.H})
+	}
+	return tiles
+}
+
+var (
+	chartColor = color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0xff}
+	alertColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	trackColor = color.NRGBA{A: 0x20}
+)
+
+// layoutTraffic draws the traffic as a filled line chart, scaled to the
+// peak of the samples.
+func layoutTraffic(gtx C, th *material.Theme, d *Data, st *tileState) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			l := material.Body2(th, fmt.Sprintf("%.0f req/s", d.Traffic[len(d.Traffic)-1]))
+			return l.Layout(gtx)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			size := gtx.Constraints.Max
+			peak := 1.0
+			for _, v := range d.Traffic {
+				peak = math.Max(peak, v)
+			}
+			pt := func(i int) f32.Point {
+				x := float32(size.X) * float32(i) / float32(len(d.Traffic)-1)
+				y := float32(size.Y) * (1 - float32(d.Traffic[i]/(peak*1.1)))
+				return f32.Pt(x, y)
+			}
+			var p clip.Path
+			p.Begin(gtx.Ops)
+			p.MoveTo(f32.Pt(0, float32(size.Y)))
+			for i := range d.Traffic {
+				p.LineTo(pt(i))
+			}
+			p.LineTo(layout.FPt(size))
+			p.Close()
+			paint.FillShape(gtx.Ops, style.MulAlpha(chartColor, 0x40), clip.Outline{Path: p.End()}.Op())
+			p.Begin(gtx.Ops)
+			p.MoveTo(pt(0))
+			for i := 1; i < len(d.Traffic); i++ {
+				p.LineTo(pt(i))
+			}
+			paint.FillShape(gtx.Ops, chartColor, clip.Stroke{Path: p.End(), Style: clip.StrokeStyle{Width: float32(gtx.Px(unit.Dp(2)))}}.Op())
+			return D{Size: size}
+		}),
+	)
+}
+
+// layoutCPU draws the load as a ring, red above 80%.
+func layoutCPU(gtx C, th *material.Theme, d *Data, st *tileState) D {
+	return layout.Center.Layout(gtx, func(gtx C) D {
+		size := gtx.Constraints.Max.X
+		if gtx.Constraints.Max.Y < size {
+			size = gtx.Constraints.Max.Y
+		}
+		width := float32(size) / 10
+		c := f32.Pt(float32(size)/2, float32(size)/2)
+		r := float32(size)/2 - width/2
+		paint.FillShape(gtx.Ops, trackColor, ring(gtx.Ops, c, r, width, 1))
+		col := chartColor
+		if d.CPU > 80 {
+			col = alertColor
+		}
+		if d.CPU > 0 {
+			paint.FillShape(gtx.Ops, col, ring(gtx.Ops, c, r, width, float32(d.CPU/100)))
+		}
+		gtx.Constraints = layout.Exact(image.Pt(size, size))
+		layout.Center.Layout(gtx, material.H5(th, fmt.Sprintf("%.0f%%", d.CPU)).Layout)
+		return D{Size: image.Pt(size, size)}
+	})
+}
+
+// ring returns the stroke of a fraction of a circle, clockwise from the
+// top.
+func ring(ops *op.Ops, c f32.Point, r, width, fraction float32) clip.Op {
+	// One segment per 3 degrees.
+	n := int(math.Ceil(float64(fraction) * 120))
+	var p clip.Path
+	p.Begin(ops)
+	for i := 0; i <= n; i++ {
+		a := -math.Pi/2 + 2*math.Pi*float64(fraction)*float64(i)/float64(n)
+		s, co := math.Sincos(a)
+		pt := c.Add(f32.Pt(r*float32(co), r*float32(s)))
+		if i == 0 {
+			p.MoveTo(pt)
+		} else {
+			p.LineTo(pt)
+		}
+	}
+	return clip.Stroke{Path: p.End(), Style: clip.StrokeStyle{Width: width, Cap: clip.FlatCap}}.Op()
+}
+
+func layoutVisitors(gtx C, th *material.Theme, d *Data, st *tileState) D {
+	return layout.Center.Layout(gtx, func(gtx C) D {
+		l := material.H3(th, fmt.Sprint(d.Visitors))
+		l.Font.Weight = text.Bold
+		l.Color = chartColor
+		return l.Layout(gtx)
+	})
+}
+
+// layoutSales draws the sales of the week as bars, labeled with their
+// weekdays.
+func layoutSales(gtx C, th *material.Theme, d *Data, st *tileState) D {
+	peak := 1
+	for _, n := range d.Sales {
+		if n > peak {
+			peak = n
+		}
+	}
+	var bars []layout.FlexChild
+	for i, n := range d.Sales {
+		i, n := i, n
+		bars = append(bars, layout.Flexed(1, func(gtx C) D {
+			day := gtx.Now.AddDate(0, 0, i-len(d.Sales)+1).Weekday().String()[:3]
+			return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(material.Caption(th, fmt.Sprint(n)).Layout),
+				layout.Flexed(1, func(gtx C) D {
+					size := gtx.Constraints.Max
+					h := size.Y * n / peak
+					w := size.X * 2 / 3
+					col := style.MulAlpha(chartColor, 0xa0)
+					if i == len(d.Sales)-1 {
+						col = chartColor
+					}
+					rect := image.Rect((size.X-w)/2, size.Y-h, (size.X+w)/2, size.Y)
+					paint.FillShape(gtx.Ops, col, clip.Rect(rect).Op())
+					return D{Size: size}
+				}),
+				layout.Rigid(material.Caption(th, day).Layout),
+			)
+		}))
+	}
+	return layout.Flex{}.Layout(gtx, bars...)
+}
+
+func layoutOrders(gtx C, th *material.Theme, d *Data, st *tileState) D {
+	st.list.Axis = layout.Vertical
+	return st.list.Layout(gtx, len(d.Orders), func(gtx C, i int) D {
+		o := d.Orders[i]
+		return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx, func(gtx C) D {
+			return layout.Flex{Alignment: layout.Baseline}.Layout(gtx,
+				layout.Flexed(1, material.Body2(th, o.Customer).Layout),
+				layout.Rigid(func(gtx C) D {
+					l := material.Caption(th, o.Time.Format("15:04"))
+					l.Color = style.MulAlpha(th.Fg, 0xa0)
+					return layout.Inset{Right: unit.Dp(12)}.Layout(gtx, l.Layout)
+				}),
+				layout.Rigid(material.Body2(th, fmt.Sprintf("$%.2f", o.Amount)).Layout),
+			)
+		})
+	})
+}
@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"sync"
+	"time"
+
+	"gioui.org/op/paint"
+)
+
+// tileKey identifies a tile in the pyramid.
+type tileKey struct {
+	level, col, row int
+}
+
+// parent returns the key of the tile in the previous level that covers
+// k.
+func (k tileKey) parent() tileKey {
+	return tileKey{level: k.level - 1, col: k.col / 2, row: k.row / 2}
+}
+
+// retryDelay is how long a tile that failed to load waits before it is
+// requested again.
+const retryDelay = 2 * time.Second
+
+type tile struct {
+	img    paint.ImageOp
+	loaded bool
+	err    error
+	// retry is when a tile that failed may be requested again.
+	retry time.Time
+	// used is the frame number the tile was last drawn or wanted.
+	used int
+}
+
+// Cache loads tiles in the background and keeps at most a fixed number of
+// them in memory, evicting the least recently used.
+type Cache struct {
+	src      Source
+	max      int
+	changed  func()
+	frame    int
+	mu       sync.Mutex
+	cond     *sync.Cond
+	tiles    map[tileKey]*tile
+	queue    []tileKey
+	inflight map[tileKey]bool
+}
+
+// NewCache returns a cache loading tiles from src with workers goroutines.
+// changed is called from a worker whenever a tile is loaded.
+func NewCache(src Source, max, workers int, changed func()) *Cache {
+	c := &Cache{
+		src:      src,
+		max:      max,
+		changed:  changed,
+		tiles:    make(map[tileKey]*tile),
+		inflight: make(map[tileKey]bool),
+	}
+	c.cond = sync.NewCond(&c.mu)
+	for i := 0; i < workers; i++ {
+		go c.worker()
+	}
+	return c
+}
+
+// Frame starts a new frame. The wanted tiles are replaced by those passed
+// to Want during the frame.
+func (c *Cache) Frame() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.frame++
+	c.queue = c.queue[:0]
+}
+
+// Want requests a tile, in order of priority. A tile that failed to load
+// is requested again after retryDelay.
+func (c *Cache) Want(k tileKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t, ok := c.tiles[k]; ok {
+		if t.err == nil || time.Now().Before(t.retry) {
+			t.used = c.frame
+			return
+		}
+		delete(c.tiles, k)
+	}
+	if !c.inflight[k] {
+		c.queue = append(c.queue, k)
+		c.cond.Signal()
+	}
+}
+
+// Get returns the tile if it is loaded.
+func (c *Cache) Get(k tileKey) (paint.ImageOp, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, ok := c.tiles[k]
+	if !ok || !t.loaded {
+		return paint.ImageOp{}, false
+	}
+	t.used = c.frame
+	return t.img, true
+}
+
+// Stats returns the number of loaded and pending tiles.
+func (c *Cache) Stats() (loaded, pending int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.tiles), len(c.queue) + len(c.inflight)
+}
+
+// Evict drops the least recently used tiles until at most max remain.
+// Tiles used in the current frame are never evicted.
+func (c *Cache) Evict() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.tiles) > c.max {
+		var (
+			oldest tileKey
+			found  bool
+			used   = c.frame
+		)
+		for k, t := range c.tiles {
+			if t.used < used {
+				oldest, used, found = k, t.used, true
+			}
+		}
+		if !found {
+			return
+		}
+		delete(c.tiles, oldest)
+	}
+}
+
+func (c *Cache) worker() {
+	for {
+		c.mu.Lock()
+		for len(c.queue) == 0 {
+			c.cond.Wait()
+		}
+		k := c.queue[0]
+		c.queue = c.queue[1:]
+		if _, ok := c.tiles[k]; ok || c.inflight[k] {
+			c.mu.Unlock()
+			continue
+		}
+		c.inflight[k] = true
+		c.mu.Unlock()
+
+		t := &tile{}
+		img, err := c.src.Tile(k.level, k.col, k.row)
+		if err != nil {
+			t.err = err
+			t.retry = time.Now().Add(retryDelay)
+			// Draw a frame when the tile may be retried, or it waits
+			// for the next frame drawn for another reason.
+			time.AfterFunc(retryDelay, c.changed)
+		} else {
+			t.img = paint.NewImageOp(img)
+			t.loaded = true
+		}
+
+		c.mu.Lock()
+		delete(c.inflight, k)
+		t.used = c.frame
+		c.tiles[k] = t
+		c.mu.Unlock()
+		c.changed()
+	}
+}
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"errors"
+	"image"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakySource fails to load its tiles until it is fixed.
+type flakySource struct {
+	mu    sync.Mutex
+	fixed bool
+}
+
+func (s *flakySource) Size() image.Point { return image.Pt(256, 256) }
+func (s *flakySource) TileSize() int     { return 256 }
+func (s *flakySource) Overlap() int      { return 0 }
+
+func (s *flakySource) Tile(level, col, row int) (image.Image, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.fixed {
+		return nil, errors.New("offline")
+	}
+	return image.NewNRGBA(image.Rect(0, 0, 1, 1)), nil
+}
+
+func TestCacheRetry(t *testing.T) {
+	src := new(flakySource)
+	loaded := make(chan struct{}, 10)
+	c := NewCache(src, 10, 1, func() { loaded <- struct{}{} })
+	k := tileKey{}
+	c.Want(k)
+	<-loaded
+	c.mu.Lock()
+	// Pretend the retry delay has passed.
+	c.tiles[k].retry = time.Now()
+	c.mu.Unlock()
+	src.mu.Lock()
+	src.fixed = true
+	src.mu.Unlock()
+	c.Want(k)
+	<-loaded
+	if _, ok := c.Get(k); !ok {
+		t.Errorf("the tile wasn't loaded again after failing")
+	}
+}
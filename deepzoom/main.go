@@ -0,0 +1,339 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program is a deep zoom viewer for images too large to fit in
+// memory. Tiles of the image pyramid are loaded on demand as you pan and
+// zoom, neighbouring tiles are prefetched and the least recently used
+// tiles are evicted. While a tile loads, a coarser level is scaled up in
+// its place.
+//
+// Pass the path or URL of a Deep Zoom Image (.dzi) descriptor, or run
+// without arguments to explore a generated Mandelbrot set of a
+// terapixel.
+//
+// Scroll or use the + and - buttons or keys to zoom, and drag to pan.
+//
+// The -net-* flags simulate a slow or failing network for remote
+// images, to watch the coarser levels stand in for loading tiles.
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/netsim"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+var maxTiles = flag.Int("tiles", 512, "maximum number of tiles kept in memory")
+
+func main() {
+	flag.Parse()
+	netsim.Install()
+	var src Source = mandelbrot{size: 1 << 20}
+	if flag.NArg() > 0 {
+		dzi, err := openDZI(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		src = dzi
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("Deep zoom"),
+			app.Size(unit.Dp(900), unit.Dp(700)),
+		)
+		if err := loop(w, src); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+func loop(w *app.Window, src Source) error {
+	th := material.NewTheme(gofont.Collection())
+	v := &Viewer{
+		src:   src,
+		cache: NewCache(src, *maxTiles, 4, w.Invalidate),
+	}
+	var zoomIn, zoomOut widget.Clickable
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case key.Event:
+			if e.State != key.Press {
+				break
+			}
+			switch e.Name {
+			case "+", "=":
+				v.ZoomBy(2)
+				w.Invalidate()
+			case "-":
+				v.ZoomBy(0.5)
+				w.Invalidate()
+			}
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			for zoomIn.Clicked() {
+				v.ZoomBy(2)
+			}
+			for zoomOut.Clicked() {
+				v.ZoomBy(0.5)
+			}
+			paint.Fill(gtx.Ops, color.NRGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xff})
+			v.Layout(gtx)
+			layout.NE.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{}.Layout(gtx,
+						layout.Rigid(material.Button(th, &zoomOut, "−").Layout),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+						layout.Rigid(material.Button(th, &zoomIn, "+").Layout),
+					)
+				})
+			})
+			loaded, pending := v.cache.Stats()
+			status := fmt.Sprintf("Level %d/%d, zoom %.4g, %d tiles in memory, %d pending",
+				v.level, maxLevel(src), v.zoom, loaded, pending)
+			layout.SW.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					l := material.Body2(th, status)
+					l.Color = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+					return l.Layout(gtx)
+				})
+			})
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+// Viewer displays a Source and handles panning and zooming.
+type Viewer struct {
+	src   Source
+	cache *Cache
+
+	// zoom is the number of screen pixels per full resolution pixel.
+	zoom float64
+	// origin is the image position, in full resolution pixels, at the
+	// top left corner of the viewer.
+	origin [2]float64
+	level  int
+
+	// size is the size of the viewer in the last frame.
+	size image.Point
+
+	dragging bool
+	last     f32.Point
+}
+
+// Layout draws the visible tiles, requests missing ones and prefetches a
+// ring of tiles around the view as well as the next level.
+func (v *Viewer) Layout(gtx layout.Context) layout.Dimensions {
+	size := gtx.Constraints.Max
+	if v.zoom == 0 {
+		// Fit the image to the window.
+		isz := v.src.Size()
+		v.zoom = math.Min(float64(size.X)/float64(isz.X), float64(size.Y)/float64(isz.Y))
+	}
+	v.size = size
+	v.events(gtx)
+
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	pointer.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	pointer.InputOp{
+		Tag:   v,
+		Grab:  v.dragging,
+		Types: pointer.Press | pointer.Drag | pointer.Release | pointer.Scroll,
+		// Zooming has no bounds; the router clamps scroll distances to
+		// these.
+		ScrollBounds: image.Rect(0, -1e6, 0, 1e6),
+	}.Add(gtx.Ops)
+
+	top := maxLevel(v.src)
+	// Use the coarsest level with at least one pixel per screen pixel.
+	v.level = top + int(math.Ceil(math.Log2(v.zoom)))
+	if v.level > top {
+		v.level = top
+	}
+	if v.level < 0 {
+		v.level = 0
+	}
+
+	v.cache.Frame()
+	visible := v.tiles(v.level, size, 0)
+	for _, k := range visible {
+		v.cache.Want(k)
+	}
+	for _, k := range visible {
+		v.drawTile(gtx, k)
+	}
+	// Prefetch, after the visible tiles so they have priority.
+	for _, k := range v.tiles(v.level, size, 1) {
+		v.cache.Want(k)
+	}
+	if v.level < top {
+		for _, k := range v.tiles(v.level+1, size, 0) {
+			v.cache.Want(k)
+		}
+	}
+	v.cache.Evict()
+	return layout.Dimensions{Size: size}
+}
+
+// tiles returns the keys of the tiles at level covering the view, grown by
+// margin tiles in every direction.
+func (v *Viewer) tiles(level int, size image.Point, margin int) []tileKey {
+	ts := float64(v.src.TileSize())
+	scale := v.levelScale(level)
+	lsz := levelSize(v.src, level)
+	cols := int(math.Ceil(float64(lsz.X) / ts))
+	rows := int(math.Ceil(float64(lsz.Y) / ts))
+	x0 := int(math.Floor(v.origin[0]*scale/ts)) - margin
+	y0 := int(math.Floor(v.origin[1]*scale/ts)) - margin
+	x1 := int(math.Floor((v.origin[0]+float64(size.X)/v.zoom)*scale/ts)) + margin
+	y1 := int(math.Floor((v.origin[1]+float64(size.Y)/v.zoom)*scale/ts)) + margin
+	var keys []tileKey
+	for row := y0; row <= y1; row++ {
+		for col := x0; col <= x1; col++ {
+			if col < 0 || row < 0 || col >= cols || row >= rows {
+				continue
+			}
+			if margin > 0 && col > x0 && col < x1 && row > y0 && row < y1 {
+				// Already visible.
+				continue
+			}
+			keys = append(keys, tileKey{level: level, col: col, row: row})
+		}
+	}
+	return keys
+}
+
+// levelScale returns the size of a level relative to the full resolution
+// image.
+func (v *Viewer) levelScale(level int) float64 {
+	return math.Exp2(float64(level - maxLevel(v.src)))
+}
+
+// drawTile draws the tile k, or the closest loaded ancestor clipped to
+// the area of k.
+func (v *Viewer) drawTile(gtx layout.Context, k tileKey) {
+	ts := v.src.TileSize()
+	// area is the region of k in full resolution pixels.
+	scale := v.levelScale(k.level)
+	area := [4]float64{
+		float64(k.col*ts) / scale, float64(k.row*ts) / scale,
+		float64((k.col+1)*ts) / scale, float64((k.row+1)*ts) / scale,
+	}
+	src := k
+	for {
+		if img, ok := v.cache.Get(src); ok {
+			stack := op.Save(gtx.Ops)
+			clip.Rect(image.Rect(
+				v.screenX(area[0]), v.screenY(area[1]),
+				v.screenX(area[2]), v.screenY(area[3]),
+			)).Add(gtx.Ops)
+			v.drawImage(gtx, src, img)
+			stack.Load()
+			return
+		}
+		if src.level == 0 {
+			return
+		}
+		src = src.parent()
+	}
+}
+
+// drawImage draws the image of tile k in place.
+func (v *Viewer) drawImage(gtx layout.Context, k tileKey, img paint.ImageOp) {
+	ts, overlap := v.src.TileSize(), v.src.Overlap()
+	scale := v.levelScale(k.level)
+	// The position of the tile image in level pixels; tiles other than
+	// the first in a row or column start with overlap pixels of their
+	// neighbour.
+	x, y := k.col*ts, k.row*ts
+	if k.col > 0 {
+		x -= overlap
+	}
+	if k.row > 0 {
+		y -= overlap
+	}
+	f := float32(v.zoom / scale)
+	pos := f32.Pt(
+		float32((float64(x)/scale-v.origin[0])*v.zoom),
+		float32((float64(y)/scale-v.origin[1])*v.zoom),
+	)
+	op.Affine(f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(f, f)).Offset(pos)).Add(gtx.Ops)
+	img.Add(gtx.Ops)
+	clip.Rect(image.Rectangle{Max: img.Size()}).Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+}
+
+func (v *Viewer) screenX(x float64) int {
+	return int(math.Round((x - v.origin[0]) * v.zoom))
+}
+
+func (v *Viewer) screenY(y float64) int {
+	return int(math.Round((y - v.origin[1]) * v.zoom))
+}
+
+func (v *Viewer) events(gtx layout.Context) {
+	for _, e := range gtx.Events(v) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case pointer.Press:
+			v.dragging = true
+			v.last = e.Position
+		case pointer.Drag:
+			d := e.Position.Sub(v.last)
+			v.origin[0] -= float64(d.X) / v.zoom
+			v.origin[1] -= float64(d.Y) / v.zoom
+			v.last = e.Position
+		case pointer.Release, pointer.Cancel:
+			v.dragging = false
+		case pointer.Scroll:
+			v.zoomAt(math.Pow(2, -float64(e.Scroll.Y)/200), e.Position)
+		}
+	}
+}
+
+// ZoomBy multiplies the zoom by factor around the center of the viewer.
+func (v *Viewer) ZoomBy(factor float64) {
+	v.zoomAt(factor, layout.FPt(v.size).Mul(.5))
+}
+
+// zoomAt multiplies the zoom by factor, keeping the image position under
+// p fixed.
+func (v *Viewer) zoomAt(factor float64, p f32.Point) {
+	if v.zoom == 0 {
+		return
+	}
+	px := v.origin[0] + float64(p.X)/v.zoom
+	py := v.origin[1] + float64(p.Y)/v.zoom
+	v.zoom *= factor
+	v.origin[0] = px - float64(p.X)/v.zoom
+	v.origin[1] = py - float64(p.Y)/v.zoom
+}
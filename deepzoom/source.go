@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Source provides the tiles of an image pyramid. Level 0 is a single pixel
+// and every following level doubles the size, up to the full resolution
+// image at MaxLevel.
+type Source interface {
+	// Size returns the size of the full resolution image.
+	Size() image.Point
+	// TileSize returns the size of tiles, excluding overlap.
+	TileSize() int
+	// Overlap returns the number of pixels each tile shares with its
+	// neighbours.
+	Overlap() int
+	// Tile loads a tile. It is called from several goroutines.
+	Tile(level, col, row int) (image.Image, error)
+}
+
+// maxLevel returns the level of the full resolution image.
+func maxLevel(s Source) int {
+	sz := s.Size()
+	d := sz.X
+	if sz.Y > d {
+		d = sz.Y
+	}
+	return int(math.Ceil(math.Log2(float64(d))))
+}
+
+// levelSize returns the size of the image at a level.
+func levelSize(s Source, level int) image.Point {
+	sz := s.Size()
+	scale := math.Exp2(float64(level - maxLevel(s)))
+	return image.Pt(
+		int(math.Ceil(float64(sz.X)*scale)),
+		int(math.Ceil(float64(sz.Y)*scale)),
+	)
+}
+
+// dziSource loads tiles in the Deep Zoom Image format, from a local file
+// or over HTTP.
+type dziSource struct {
+	size     image.Point
+	tileSize int
+	overlap  int
+	format   string
+	// base is the path of the tiles directory, without the trailing
+	// slash.
+	base string
+}
+
+type dziImage struct {
+	TileSize int    `xml:"TileSize,attr"`
+	Overlap  int    `xml:"Overlap,attr"`
+	Format   string `xml:"Format,attr"`
+	Size     struct {
+		Width  int `xml:"Width,attr"`
+		Height int `xml:"Height,attr"`
+	} `xml:"Size"`
+}
+
+// openDZI reads the descriptor at path, a file name or an HTTP URL ending
+// in .dzi.
+func openDZI(path string) (*dziSource, error) {
+	r, err := fetch(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var desc dziImage
+	if err := xml.NewDecoder(r).Decode(&desc); err != nil {
+		return nil, fmt.Errorf("deepzoom: %s: %v", path, err)
+	}
+	if desc.TileSize <= 0 || desc.Size.Width <= 0 || desc.Size.Height <= 0 {
+		return nil, fmt.Errorf("deepzoom: %s: invalid descriptor", path)
+	}
+	return &dziSource{
+		size:     image.Pt(desc.Size.Width, desc.Size.Height),
+		tileSize: desc.TileSize,
+		overlap:  desc.Overlap,
+		format:   desc.Format,
+		base:     strings.TrimSuffix(path, ".dzi") + "_files",
+	}, nil
+}
+
+func (d *dziSource) Size() image.Point { return d.size }
+func (d *dziSource) TileSize() int     { return d.tileSize }
+func (d *dziSource) Overlap() int      { return d.overlap }
+
+func (d *dziSource) Tile(level, col, row int) (image.Image, error) {
+	r, err := fetch(fmt.Sprintf("%s/%d/%d_%d.%s", d.base, level, col, row, d.format))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	img, _, err := image.Decode(r)
+	return img, err
+}
+
+func fetch(path string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		return os.Open(path)
+	}
+	resp, err := http.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("deepzoom: %s: %s", path, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// mandelbrot is a Source that renders the Mandelbrot set on demand. It
+// stands in for a gigapixel image when no DZI descriptor is given.
+type mandelbrot struct {
+	size int
+}
+
+func (m mandelbrot) Size() image.Point { return image.Pt(m.size, m.size) }
+func (m mandelbrot) TileSize() int     { return 256 }
+func (m mandelbrot) Overlap() int      { return 0 }
+
+func (m mandelbrot) Tile(level, col, row int) (image.Image, error) {
+	ts := m.TileSize()
+	lsz := levelSize(m, level)
+	w, h := ts, ts
+	if r := lsz.X - col*ts; r < w {
+		w = r
+	}
+	if r := lsz.Y - row*ts; r < h {
+		h = r
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	// The set spans [-2, 1] x [-1.5, 1.5].
+	scale := 3 / float64(lsz.X)
+	maxIter := 64 + 16*level
+	for y := 0; y < h; y++ {
+		ci := float64(row*ts+y)*scale - 1.5
+		for x := 0; x < w; x++ {
+			cr := float64(col*ts+x)*scale - 2
+			var zr, zi float64
+			i := 0
+			for ; i < maxIter && zr*zr+zi*zi < 4; i++ {
+				zr, zi = zr*zr-zi*zi+cr, 2*zr*zi+ci
+			}
+			img.SetNRGBA(x, y, shade(i, maxIter))
+		}
+	}
+	return img, nil
+}
+
+func shade(i, max int) color.NRGBA {
+	if i == max {
+		return color.NRGBA{A: 0xff}
+	}
+	t := math.Sqrt(float64(i) / float64(max))
+	return color.NRGBA{
+		R: uint8(255 * t),
+		G: uint8(255 * t * t),
+		B: uint8(255 * math.Sqrt(t)),
+		A: 0xff,
+	}
+}
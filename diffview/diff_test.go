@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// check applies edits to a, checking that they produce b.
+func check(t *testing.T, a, b []string, edits []Edit) {
+	t.Helper()
+	var out []string
+	ai, bi := 0, 0
+	for _, e := range edits {
+		switch e.Kind {
+		case Equal:
+			if e.A != ai || e.B != bi || a[e.A] != b[e.B] {
+				t.Fatalf("bad equal %+v at %d, %d", e, ai, bi)
+			}
+			out = append(out, a[e.A])
+			ai++
+			bi++
+		case Delete:
+			if e.A != ai {
+				t.Fatalf("bad delete %+v at %d", e, ai)
+			}
+			ai++
+		case Insert:
+			if e.B != bi {
+				t.Fatalf("bad insert %+v at %d", e, bi)
+			}
+			out = append(out, b[e.B])
+			bi++
+		}
+	}
+	if ai != len(a) || bi != len(b) || strings.Join(out, "\n") != strings.Join(b, "\n") {
+		t.Fatalf("edits produce %q, want %q", out, b)
+	}
+}
+
+func diffStrings(a, b []string) []Edit {
+	return Diff(len(a), len(b), func(i, j int) bool { return a[i] == b[j] })
+}
+
+func TestDiffShortest(t *testing.T) {
+	// The example of Myers' paper, with 5 differences.
+	a := strings.Split("ABCABBA", "")
+	b := strings.Split("CBABAC", "")
+	edits := diffStrings(a, b)
+	check(t, a, b, edits)
+	d := 0
+	for _, e := range edits {
+		if e.Kind != Equal {
+			d++
+		}
+	}
+	if d != 5 {
+		t.Errorf("got %d differences, want 5", d)
+	}
+}
+
+func TestDiffRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	gen := func() []string {
+		s := make([]string, r.Intn(30))
+		for i := range s {
+			s[i] = string(rune('a' + r.Intn(4)))
+		}
+		return s
+	}
+	for i := 0; i < 500; i++ {
+		a, b := gen(), gen()
+		check(t, a, b, diffStrings(a, b))
+	}
+}
+
+func TestRows(t *testing.T) {
+	a := []string{"one", "two", "three", "four"}
+	b := []string{"one", "2", "three", "four", "five"}
+	edits := diffStrings(a, b)
+	got := sideBySide(edits)
+	want := []Row{
+		{Kind: Same, A: 0, B: 0},
+		{Kind: Changed, A: 1, B: 1},
+		{Kind: Same, A: 2, B: 2},
+		{Kind: Same, A: 3, B: 3},
+		{Kind: Inserted, A: -1, B: 4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("side by side: got %+v, want %+v", got, want)
+	}
+	if u := unified(edits); len(u) != 6 || u[1].Kind != Deleted || u[2].Kind != Inserted {
+		t.Errorf("unexpected unified rows %+v", u)
+	}
+	folded := fold(got, 0)
+	if len(folded) != 4 || folded[0].Kind != Fold || folded[2].Kind != Fold || folded[2].Folded != 2 {
+		t.Errorf("unexpected folded rows %+v", folded)
+	}
+}
+
+func TestWordDiff(t *testing.T) {
+	as, bs := wordDiff("x := foo(a, b)", "x := bar(a, c)")
+	wantA := []span{{"x := ", false}, {"foo", true}, {"(a, ", false}, {"b", true}, {")", false}}
+	wantB := []span{{"x := ", false}, {"bar", true}, {"(a, ", false}, {"c", true}, {")", false}}
+	if !reflect.DeepEqual(as, wantA) || !reflect.DeepEqual(bs, wantB) {
+		t.Errorf("got %+v and %+v", as, bs)
+	}
+}
@@ -0,0 +1,497 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program shows the differences between two text files, computed
+// with Myers' algorithm, side by side or as a unified diff. Changed lines
+// highlight the words that differ, unchanged regions can be folded away,
+// and the two sides of the side by side view scroll together.
+//
+// Usage:
+//
+//	go run ./diffview old.txt new.txt
+//
+// Without arguments, two versions of a small program are compared.
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/style"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+// context is the number of unchanged lines kept around changes when
+// folding.
+const context = 3
+
+const sampleOld = `package main
+
+import "fmt"
+
+func main() {
+	names := []string{"Ann", "Bob"}
+	for _, n := range names {
+		fmt.Println("Hello,", n)
+	}
+}
+
+func unused() {
+}
+`
+
+const sampleNew = `package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+func main() {
+	names := []string{"Ann", "Bob", "Carol"}
+	for _, n := range names {
+		fmt.Println("Hello,", strings.ToUpper(n))
+	}
+}
+`
+
+func main() {
+	flag.Parse()
+	oldName, newName := "old.go", "new.go"
+	oldText, newText := sampleOld, sampleNew
+	switch flag.NArg() {
+	case 0:
+	case 2:
+		oldName, newName = flag.Arg(0), flag.Arg(1)
+		o, err := ioutil.ReadFile(oldName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		n, err := ioutil.ReadFile(newName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		oldText, newText = string(o), string(n)
+	default:
+		fmt.Fprintln(os.Stderr, "usage: diffview [old new]")
+		os.Exit(2)
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("Diff Viewer"),
+			app.Size(unit.Dp(1200), unit.Dp(760)),
+		)
+		if err := loop(w, newDiff(oldName, newName, oldText, newText)); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var (
+	monoFont     = text.Font{Variant: "Mono"}
+	deletedBg    = color.NRGBA{R: 0xff, G: 0xeb, B: 0xee, A: 0xff}
+	deletedWord  = color.NRGBA{R: 0xff, G: 0xb3, B: 0xba, A: 0xff}
+	insertedBg   = color.NRGBA{R: 0xe6, G: 0xf4, B: 0xea, A: 0xff}
+	insertedWord = color.NRGBA{R: 0xa8, G: 0xe0, B: 0xb5, A: 0xff}
+	emptyBg      = color.NRGBA{A: 0x0a}
+	foldBg       = color.NRGBA{R: 0xe3, G: 0xf2, B: 0xfd, A: 0xff}
+	gutterColor  = color.NRGBA{A: 0x70}
+)
+
+// fileDiff is the difference between two files.
+type fileDiff struct {
+	oldName, newName string
+	old, new         []string
+	edits            []Edit
+	split, uni       []Row
+	// pairs maps the deleted lines of changes to the lines replacing
+	// them, and back.
+	oldPair, newPair map[int]int
+	inserted         int
+	deleted          int
+}
+
+func newDiff(oldName, newName, oldText, newText string) *fileDiff {
+	lines := func(s string) []string {
+		s = strings.TrimSuffix(s, "\n")
+		if s == "" {
+			return nil
+		}
+		return strings.Split(s, "\n")
+	}
+	d := &fileDiff{
+		oldName: oldName,
+		newName: newName,
+		old:     lines(oldText),
+		new:     lines(newText),
+		oldPair: make(map[int]int),
+		newPair: make(map[int]int),
+	}
+	d.edits = Diff(len(d.old), len(d.new), func(i, j int) bool { return d.old[i] == d.new[j] })
+	d.split = sideBySide(d.edits)
+	d.uni = unified(d.edits)
+	for _, r := range d.split {
+		if r.Kind == Changed {
+			d.oldPair[r.A] = r.B
+			d.newPair[r.B] = r.A
+		}
+	}
+	for _, e := range d.edits {
+		switch e.Kind {
+		case Delete:
+			d.deleted++
+		case Insert:
+			d.inserted++
+		}
+	}
+	return d
+}
+
+// oldSpans returns the spans of an old line, with the words changed by
+// its replacement marked.
+func (d *fileDiff) oldSpans(a int) []span {
+	if b, ok := d.oldPair[a]; ok {
+		s, _ := wordDiff(d.old[a], d.new[b])
+		return s
+	}
+	return []span{{Text: d.old[a]}}
+}
+
+func (d *fileDiff) newSpans(b int) []span {
+	if a, ok := d.newPair[b]; ok {
+		_, s := wordDiff(d.old[a], d.new[b])
+		return s
+	}
+	return []span{{Text: d.new[b]}}
+}
+
+type App struct {
+	diff *fileDiff
+	rows []Row
+	// syncPos is the position of the side by side lists after the last
+	// synchronization.
+	syncPos layout.Position
+
+	mode              widget.Enum
+	fold              widget.Bool
+	prev, next        widget.Clickable
+	left, right, list layout.List
+}
+
+func loop(w *app.Window, d *fileDiff) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		diff:  d,
+		mode:  widget.Enum{Value: "split"},
+		fold:  widget.Bool{Value: true},
+		left:  layout.List{Axis: layout.Vertical},
+		right: layout.List{Axis: layout.Vertical},
+		list:  layout.List{Axis: layout.Vertical},
+	}
+	a.updateRows()
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			a.update(gtx)
+			a.Layout(gtx, th)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+func (a *App) updateRows() {
+	rows := a.diff.uni
+	if a.mode.Value == "split" {
+		rows = a.diff.split
+	}
+	if a.fold.Value {
+		rows = fold(rows, context)
+	}
+	a.rows = rows
+	a.scrollTo(0)
+}
+
+func (a *App) update(gtx C) {
+	if a.mode.Changed() || a.fold.Changed() {
+		a.updateRows()
+	}
+	first := a.list.Position.First
+	if a.mode.Value == "split" {
+		first = a.left.Position.First
+	}
+	for a.next.Clicked() {
+		// The first change below the top context lines.
+		for i := first + context + 1; i < len(a.rows); i++ {
+			if a.changeStart(i) {
+				a.scrollTo(i - context)
+				break
+			}
+		}
+	}
+	for a.prev.Clicked() {
+		for i := first + context - 1; i >= 0; i-- {
+			if a.changeStart(i) {
+				a.scrollTo(i - context)
+				break
+			}
+		}
+	}
+}
+
+// changeStart reports whether row i starts a change.
+func (a *App) changeStart(i int) bool {
+	changed := func(i int) bool {
+		k := a.rows[i].Kind
+		return k != Same && k != Fold
+	}
+	return changed(i) && (i == 0 || !changed(i-1))
+}
+
+func (a *App) scrollTo(row int) {
+	if row < 0 {
+		row = 0
+	}
+	p := layout.Position{First: row}
+	a.list.Position = p
+	a.left.Position = p
+	a.right.Position = p
+	a.syncPos = p
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	d := a.diff
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(material.RadioButton(th, &a.mode, "split", "Side by side").Layout),
+					layout.Rigid(material.RadioButton(th, &a.mode, "unified", "Unified").Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+					layout.Rigid(material.CheckBox(th, &a.fold, "Fold unchanged lines").Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+					layout.Rigid(style.TextButton(th, &a.prev, "Previous change")),
+					layout.Rigid(style.TextButton(th, &a.next, "Next change")),
+					layout.Flexed(1, func(gtx C) D {
+						gtx.Constraints.Min.X = gtx.Constraints.Max.X
+						l := material.Body2(th, fmt.Sprintf("+%d −%d lines", d.inserted, d.deleted))
+						l.Alignment = text.End
+						return l.Layout(gtx)
+					}),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			if len(d.edits) == 0 || d.inserted+d.deleted == 0 {
+				return layout.Center.Layout(gtx, material.Body1(th, "The files are identical.").Layout)
+			}
+			if a.mode.Value == "split" {
+				return a.layoutSplit(gtx, th)
+			}
+			return a.layoutUnified(gtx, th)
+		}),
+	)
+}
+
+// layoutSplit lays out the side by side view. Scrolling either side
+// scrolls the other to the same row.
+func (a *App) layoutSplit(gtx C, th *material.Theme) D {
+	d := a.diff
+	moved := func(p layout.Position) bool {
+		return p.First != a.syncPos.First || p.Offset != a.syncPos.Offset
+	}
+	sync := func(to *layout.List, p layout.Position) {
+		to.Position.First, to.Position.Offset = p.First, p.Offset
+		a.syncPos = p
+	}
+	side := func(l *layout.List, old bool) layout.Widget {
+		return func(gtx C) D {
+			return a.layoutSide(gtx, th, l, old)
+		}
+	}
+	header := func(name string) layout.Widget {
+		return func(gtx C) D {
+			return layout.UniformInset(unit.Dp(6)).Layout(gtx, material.Body2(th, name).Layout)
+		}
+	}
+	return layout.Flex{}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(header(d.oldName)),
+				layout.Flexed(1, func(gtx C) D {
+					dims := side(&a.left, true)(gtx)
+					if moved(a.left.Position) {
+						// Follow before the right side is laid out.
+						sync(&a.right, a.left.Position)
+					}
+					return dims
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			size := image.Pt(gtx.Px(unit.Dp(1)), gtx.Constraints.Max.Y)
+			paint.FillShape(gtx.Ops, color.NRGBA{A: 0x30}, clip.Rect{Max: size}.Op())
+			return D{Size: size}
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(header(d.newName)),
+				layout.Flexed(1, func(gtx C) D {
+					dims := side(&a.right, false)(gtx)
+					if moved(a.right.Position) {
+						sync(&a.left, a.right.Position)
+						op.InvalidateOp{}.Add(gtx.Ops)
+					}
+					return dims
+				}),
+			)
+		}),
+	)
+}
+
+func (a *App) layoutSide(gtx C, th *material.Theme, l *layout.List, old bool) D {
+	d := a.diff
+	gutter := len(strconv.Itoa(len(d.old)))
+	if n := len(strconv.Itoa(len(d.new))); n > gutter {
+		gutter = n
+	}
+	return l.Layout(gtx, len(a.rows), func(gtx C, i int) D {
+		r := a.rows[i]
+		if r.Kind == Fold {
+			return layoutFold(gtx, th, r)
+		}
+		line := r.A
+		if !old {
+			line = r.B
+		}
+		if line < 0 {
+			return layoutLine(gtx, th, emptyBg, color.NRGBA{}, strings.Repeat(" ", gutter), nil)
+		}
+		num := fmt.Sprintf("%*d", gutter, line+1)
+		switch {
+		case old && r.Kind != Same:
+			return layoutLine(gtx, th, deletedBg, deletedWord, num, d.oldSpans(line))
+		case !old && r.Kind != Same:
+			return layoutLine(gtx, th, insertedBg, insertedWord, num, d.newSpans(line))
+		case old:
+			return layoutLine(gtx, th, color.NRGBA{}, color.NRGBA{}, num, []span{{Text: d.old[line]}})
+		default:
+			return layoutLine(gtx, th, color.NRGBA{}, color.NRGBA{}, num, []span{{Text: d.new[line]}})
+		}
+	})
+}
+
+func (a *App) layoutUnified(gtx C, th *material.Theme) D {
+	d := a.diff
+	gutter := len(strconv.Itoa(len(d.old)))
+	if n := len(strconv.Itoa(len(d.new))); n > gutter {
+		gutter = n
+	}
+	nums := func(r Row) string {
+		num := func(i int) string {
+			if i < 0 {
+				return strings.Repeat(" ", gutter)
+			}
+			return fmt.Sprintf("%*d", gutter, i+1)
+		}
+		sign := " "
+		switch r.Kind {
+		case Deleted:
+			sign = "-"
+		case Inserted:
+			sign = "+"
+		}
+		return num(r.A) + " " + num(r.B) + " " + sign
+	}
+	return a.list.Layout(gtx, len(a.rows), func(gtx C, i int) D {
+		r := a.rows[i]
+		switch r.Kind {
+		case Fold:
+			return layoutFold(gtx, th, r)
+		case Deleted:
+			return layoutLine(gtx, th, deletedBg, deletedWord, nums(r), d.oldSpans(r.A))
+		case Inserted:
+			return layoutLine(gtx, th, insertedBg, insertedWord, nums(r), d.newSpans(r.B))
+		default:
+			return layoutLine(gtx, th, color.NRGBA{}, color.NRGBA{}, nums(r), []span{{Text: d.old[r.A]}})
+		}
+	})
+}
+
+// layoutLine lays out a line of a file with a line number gutter, on a
+// background, with the changed spans on another.
+func layoutLine(gtx C, th *material.Theme, bg, changedBg color.NRGBA, gutter string, spans []span) D {
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	m := op.Record(gtx.Ops)
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx C) D {
+			l := material.Body2(th, gutter+"  ")
+			l.Font = monoFont
+			l.Color = gutterColor
+			return l.Layout(gtx)
+		}),
+	}
+	for _, s := range spans {
+		s := s
+		children = append(children, layout.Rigid(func(gtx C) D {
+			l := material.Body2(th, strings.ReplaceAll(s.Text, "\t", "    "))
+			l.Font = monoFont
+			l.MaxLines = 1
+			m := op.Record(gtx.Ops)
+			dims := l.Layout(gtx)
+			call := m.Stop()
+			if s.Changed {
+				paint.FillShape(gtx.Ops, changedBg, clip.Rect{Max: dims.Size}.Op())
+			}
+			call.Add(gtx.Ops)
+			return dims
+		}))
+	}
+	dims := layout.Flex{}.Layout(gtx, children...)
+	call := m.Stop()
+	paint.FillShape(gtx.Ops, bg, clip.Rect{Max: dims.Size}.Op())
+	call.Add(gtx.Ops)
+	return dims
+}
+
+func layoutFold(gtx C, th *material.Theme, r Row) D {
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	m := op.Record(gtx.Ops)
+	dims := layout.Inset{Top: unit.Dp(2), Bottom: unit.Dp(2), Left: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+		l := material.Body2(th, fmt.Sprintf("⋯ %d unchanged lines", r.Folded))
+		l.Color = gutterColor
+		return l.Layout(gtx)
+	})
+	call := m.Stop()
+	paint.FillShape(gtx.Ops, foldBg, clip.Rect{Max: dims.Size}.Op())
+	call.Add(gtx.Ops)
+	return dims
+}
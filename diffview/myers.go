@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// Kind is the kind of an Edit.
+type Kind int
+
+const (
+	Equal Kind = iota
+	Delete
+	Insert
+)
+
+// Edit is a step of an edit script from a sequence a to a sequence b.
+type Edit struct {
+	Kind Kind
+	// A is the index in a of an Equal or Delete, B the index in b of an
+	// Equal or Insert.
+	A, B int
+}
+
+// maxEdits bounds the differences searched for by Myers' algorithm,
+// whose memory grows with their square. Beyond it, the remaining lines
+// are reported as deleted and inserted.
+const maxEdits = 2000
+
+// Diff returns a shortest edit script from a sequence of n elements to
+// one of m elements, where eq reports whether the i'th element of the
+// first equals the j'th of the second. It uses the algorithm of
+// "An O(ND) Difference Algorithm and Its Variations", Eugene W. Myers.
+func Diff(n, m int, eq func(i, j int) bool) []Edit {
+	// Trim the common prefix and suffix, often most of the sequences.
+	pre := 0
+	for pre < n && pre < m && eq(pre, pre) {
+		pre++
+	}
+	suf := 0
+	for suf < n-pre && suf < m-pre && eq(n-1-suf, m-1-suf) {
+		suf++
+	}
+	var edits []Edit
+	for i := 0; i < pre; i++ {
+		edits = append(edits, Edit{Equal, i, i})
+	}
+	edits = append(edits, myers(pre, n-suf, pre, m-suf, eq)...)
+	for i := 0; i < suf; i++ {
+		edits = append(edits, Edit{Equal, n - suf + i, m - suf + i})
+	}
+	return edits
+}
+
+// myers returns the edit script from a[a0:a1] to b[b0:b1].
+func myers(a0, a1, b0, b1 int, eq func(i, j int) bool) []Edit {
+	n, m := a1-a0, b1-b0
+	max := n + m
+	if max > maxEdits {
+		max = maxEdits
+	}
+	// v[off+k] is the furthest x reached on diagonal k = x - y.
+	off := max + 1
+	v := make([]int, 2*max+3)
+	// trace[d] holds v for diagonals -d..d after d differences.
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[off+k-1] < v[off+k+1] {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && eq(a0+x, b0+y) {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, d, n, m, a0, b0)
+			}
+		}
+		trace = append(trace, append([]int(nil), v[off-d:off+d+1]...))
+	}
+	// Too different; replace everything.
+	var edits []Edit
+	for i := 0; i < n; i++ {
+		edits = append(edits, Edit{Kind: Delete, A: a0 + i, B: -1})
+	}
+	for j := 0; j < m; j++ {
+		edits = append(edits, Edit{Kind: Insert, A: -1, B: b0 + j})
+	}
+	return edits
+}
+
+// backtrack follows the path of D differences to (n, m) back to the
+// start.
+func backtrack(trace [][]int, D, n, m, a0, b0 int) []Edit {
+	var edits []Edit
+	x, y := n, m
+	for d := D; d > 0; d-- {
+		prev := trace[d-1]
+		at := func(k int) int { return prev[k+d-1] }
+		k := x - y
+		var pk int
+		if k == -d || k != d && at(k-1) < at(k+1) {
+			pk = k + 1
+		} else {
+			pk = k - 1
+		}
+		px := at(pk)
+		py := px - pk
+		// The point after the move from the previous path, followed by
+		// a snake of equal elements.
+		sx, sy := px+1, py
+		if pk == k+1 {
+			sx, sy = px, py+1
+		}
+		for x > sx && y > sy {
+			x--
+			y--
+			edits = append(edits, Edit{Equal, a0 + x, b0 + y})
+		}
+		if pk == k+1 {
+			edits = append(edits, Edit{Kind: Insert, A: -1, B: b0 + py})
+		} else {
+			edits = append(edits, Edit{Kind: Delete, A: a0 + px, B: -1})
+		}
+		x, y = px, py
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		edits = append(edits, Edit{Equal, a0 + x, b0 + y})
+	}
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// RowKind is the kind of a row of a rendered diff.
+type RowKind int
+
+const (
+	Same RowKind = iota
+	// Changed rows pair a deleted line with the inserted line replacing it.
+	Changed
+	Deleted
+	Inserted
+	// Fold rows stand in for unchanged lines hidden.
+	Fold
+)
+
+// Row is a row of a rendered diff.
+type Row struct {
+	Kind RowKind
+	// A and B are the line indices in the old and new files, or -1.
+	A, B int
+	// Folded is the number of lines of a Fold row.
+	Folded int
+}
+
+// blocks calls same for every Equal edit and change for every run of
+// deletes and inserts between them.
+func blocks(edits []Edit, same func(e Edit), change func(dels, ins []int)) {
+	var dels, ins []int
+	flush := func() {
+		if len(dels) > 0 || len(ins) > 0 {
+			change(dels, ins)
+		}
+		dels, ins = nil, nil
+	}
+	for _, e := range edits {
+		switch e.Kind {
+		case Equal:
+			flush()
+			same(e)
+		case Delete:
+			dels = append(dels, e.A)
+		case Insert:
+			ins = append(ins, e.B)
+		}
+	}
+	flush()
+}
+
+// sideBySide returns the rows of a side by side diff, with the deleted
+// and inserted lines of a change paired up.
+func sideBySide(edits []Edit) []Row {
+	var rows []Row
+	blocks(edits, func(e Edit) {
+		rows = append(rows, Row{Kind: Same, A: e.A, B: e.B})
+	}, func(dels, ins []int) {
+		for i := 0; i < len(dels) || i < len(ins); i++ {
+			switch {
+			case i < len(dels) && i < len(ins):
+				rows = append(rows, Row{Kind: Changed, A: dels[i], B: ins[i]})
+			case i < len(dels):
+				rows = append(rows, Row{Kind: Deleted, A: dels[i], B: -1})
+			default:
+				rows = append(rows, Row{Kind: Inserted, A: -1, B: ins[i]})
+			}
+		}
+	})
+	return rows
+}
+
+// unified returns the rows of a unified diff, with the deleted lines of
+// a change before the inserted lines.
+func unified(edits []Edit) []Row {
+	var rows []Row
+	blocks(edits, func(e Edit) {
+		rows = append(rows, Row{Kind: Same, A: e.A, B: e.B})
+	}, func(dels, ins []int) {
+		for _, a := range dels {
+			rows = append(rows, Row{Kind: Deleted, A: a, B: -1})
+		}
+		for _, b := range ins {
+			rows = append(rows, Row{Kind: Inserted, A: -1, B: b})
+		}
+	})
+	return rows
+}
+
+// fold replaces the unchanged rows further than context rows from a
+// change by Fold rows.
+func fold(rows []Row, context int) []Row {
+	keep := make([]bool, len(rows))
+	for i, r := range rows {
+		if r.Kind == Same {
+			continue
+		}
+		for j := i - context; j <= i+context; j++ {
+			if j >= 0 && j < len(rows) {
+				keep[j] = true
+			}
+		}
+	}
+	var folded []Row
+	for i := 0; i < len(rows); {
+		if keep[i] {
+			folded = append(folded, rows[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(rows) && !keep[j] {
+			j++
+		}
+		folded = append(folded, Row{Kind: Fold, A: rows[i].A, B: rows[i].B, Folded: j - i})
+		i = j
+	}
+	return folded
+}
+
+// span is a run of a line, changed or not.
+type span struct {
+	Text    string
+	Changed bool
+}
+
+// words splits s into words, runs of spaces, and single other characters.
+func words(s string) []string {
+	var toks []string
+	class := func(r rune) int {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			return 1
+		case unicode.IsSpace(r):
+			return 2
+		}
+		return 0
+	}
+	for i := 0; i < len(s); {
+		r, n := utf8.DecodeRuneInString(s[i:])
+		c := class(r)
+		j := i + n
+		for c != 0 && j < len(s) {
+			r, n := utf8.DecodeRuneInString(s[j:])
+			if class(r) != c {
+				break
+			}
+			j += n
+		}
+		toks = append(toks, s[i:j])
+		i = j
+	}
+	return toks
+}
+
+// wordDiff returns the spans of a changed line in its old and new
+// versions, with the words that differ marked.
+func wordDiff(a, b string) (as, bs []span) {
+	wa, wb := words(a), words(b)
+	add := func(spans []span, text string, changed bool) []span {
+		if n := len(spans); n > 0 && spans[n-1].Changed == changed {
+			spans[n-1].Text += text
+			return spans
+		}
+		return append(spans, span{text, changed})
+	}
+	for _, e := range Diff(len(wa), len(wb), func(i, j int) bool { return wa[i] == wb[j] }) {
+		switch e.Kind {
+		case Equal:
+			as = add(as, wa[e.A], false)
+			bs = add(bs, wb[e.B], false)
+		case Delete:
+			as = add(as, wa[e.A], true)
+		case Insert:
+			bs = add(bs, wb[e.B], true)
+		}
+	}
+	return as, bs
+}
@@ -0,0 +1,362 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program analyzes the disk usage of a directory tree, given as
+// argument, and shows it as a treemap: every file and directory is a box
+// with an area proportional to its size. The tree is scanned concurrently
+// in the background. Click a directory to zoom into it, and hover a box
+// for its details.
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/bytesize"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/pointer"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	flag.Parse()
+	root := flag.Arg(0)
+	if root == "" {
+		root = "."
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("Disk Usage"),
+			app.Size(unit.Dp(900), unit.Dp(640)),
+		)
+		if err := loop(w, root); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+type scanResult struct {
+	root *Node
+	err  error
+}
+
+type App struct {
+	root    string
+	scanner *Scanner
+	cancel  context.CancelFunc
+	results chan scanResult
+	tree    Treemap
+	err     error
+
+	up, rescan widget.Clickable
+}
+
+func loop(w *app.Window, root string) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		root:    root,
+		results: make(chan scanResult, 1),
+	}
+	a.scan()
+	var ops op.Ops
+	for {
+		select {
+		case res := <-a.results:
+			a.cancel = nil
+			a.scanner = nil
+			a.err = res.err
+			if res.err == nil {
+				a.tree.SetRoot(res.root)
+			}
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				if a.cancel != nil {
+					a.cancel()
+				}
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update()
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+// scan starts scanning the root directory in the background.
+func (a *App) scan() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewScanner(8)
+	a.scanner = s
+	a.cancel = cancel
+	go func() {
+		n, err := s.Scan(ctx, a.root)
+		a.results <- scanResult{root: n, err: err}
+	}()
+}
+
+func (a *App) update() {
+	for a.up.Clicked() {
+		a.tree.Up()
+	}
+	for a.rescan.Clicked() {
+		if a.scanner == nil {
+			a.scan()
+		}
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				title := a.root
+				if n := a.tree.Current(); n != nil {
+					title = fmt.Sprintf("%s — %s", n.Path(), bytesize.Format(n.Size))
+				}
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						if n := a.tree.Current(); n == nil || n.Parent == nil {
+							gtx = gtx.Disabled()
+						}
+						return material.Button(th, &a.up, "Up").Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(func(gtx C) D {
+						if a.scanner != nil {
+							gtx = gtx.Disabled()
+						}
+						return material.Button(th, &a.rescan, "Rescan").Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+					layout.Flexed(1, material.Body1(th, title).Layout),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			switch {
+			case a.scanner != nil:
+				// Poll the file count while scanning.
+				op.InvalidateOp{At: gtx.Now.Add(100 * time.Millisecond)}.Add(gtx.Ops)
+				files := atomic.LoadInt64(&a.scanner.Files)
+				return layout.Center.Layout(gtx, material.H6(th, fmt.Sprintf("Scanning… %d files", files)).Layout)
+			case a.err != nil:
+				return layout.Center.Layout(gtx, material.Body1(th, a.err.Error()).Layout)
+			default:
+				return a.tree.Layout(gtx, th)
+			}
+		}),
+		layout.Rigid(func(gtx C) D {
+			details := "Click a directory to zoom in."
+			if n := a.tree.Hovered(); n != nil {
+				details = fmt.Sprintf("%s — %s", n.Path(), bytesize.Format(n.Size))
+				if p := n.Parent; p != nil && p.Size > 0 {
+					details += fmt.Sprintf(" (%.1f%% of %s)", float64(n.Size)*100/float64(p.Size), p.Name)
+				}
+			}
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, material.Body2(th, details).Layout)
+		}),
+	)
+}
+
+// Treemap shows the tree of a directory as nested boxes.
+type Treemap struct {
+	current *Node
+	hovered *Node
+	cells   []cell
+}
+
+// cell is a box of a node in the last layout.
+type cell struct {
+	node  *Node
+	box   box
+	depth int
+	// color is the fill of the cell.
+	color color.NRGBA
+}
+
+// palette colors the top level boxes.
+var palette = []color.NRGBA{
+	{R: 0x42, G: 0x85, B: 0xf4, A: 0xff},
+	{R: 0xdb, G: 0x44, B: 0x37, A: 0xff},
+	{R: 0xf4, G: 0xb4, B: 0x00, A: 0xff},
+	{R: 0x0f, G: 0x9d, B: 0x58, A: 0xff},
+	{R: 0xab, G: 0x47, B: 0xbc, A: 0xff},
+	{R: 0x00, G: 0xac, B: 0xc1, A: 0xff},
+	{R: 0xff, G: 0x70, B: 0x43, A: 0xff},
+	{R: 0x9e, G: 0x9d, B: 0x24, A: 0xff},
+}
+
+// SetRoot shows a new tree.
+func (t *Treemap) SetRoot(n *Node) {
+	t.current = n
+	t.hovered = nil
+}
+
+// Current returns the directory shown.
+func (t *Treemap) Current() *Node {
+	return t.current
+}
+
+// Hovered returns the node under the pointer, or nil.
+func (t *Treemap) Hovered() *Node {
+	return t.hovered
+}
+
+// Up zooms out to the parent directory.
+func (t *Treemap) Up() {
+	if t.current != nil && t.current.Parent != nil {
+		t.current = t.current.Parent
+		t.hovered = nil
+	}
+}
+
+// hit returns the deepest cell at a position, or nil.
+func (t *Treemap) hit(pos f32.Point, maxDepth int) *cell {
+	var hit *cell
+	for i := range t.cells {
+		c := &t.cells[i]
+		if c.depth <= maxDepth && c.box.contains(float64(pos.X), float64(pos.Y)) {
+			if hit == nil || c.depth > hit.depth {
+				hit = c
+			}
+		}
+	}
+	return hit
+}
+
+func (t *Treemap) Layout(gtx C, th *material.Theme) D {
+	for _, e := range gtx.Events(t) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case pointer.Move, pointer.Enter:
+			t.hovered = nil
+			if c := t.hit(e.Position, 1); c != nil {
+				t.hovered = c.node
+			}
+		case pointer.Leave:
+			t.hovered = nil
+		case pointer.Press:
+			// Zoom into the top level directory clicked.
+			if c := t.hit(e.Position, 0); c != nil && c.node.Dir && len(c.node.Children) > 0 {
+				t.current = c.node
+				t.hovered = nil
+			}
+		}
+	}
+	size := gtx.Constraints.Max
+	t.cells = t.cells[:0]
+	if t.current != nil {
+		t.layoutCells(gtx, t.current, box{W: float64(size.X), H: float64(size.Y)}, 0, color.NRGBA{})
+	}
+	for _, c := range t.cells {
+		t.drawCell(gtx, th, c)
+	}
+	defer op.Save(gtx.Ops).Load()
+	pointer.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	pointer.CursorNameOp{Name: pointer.CursorPointer}.Add(gtx.Ops)
+	pointer.InputOp{Tag: t, Types: pointer.Move | pointer.Enter | pointer.Leave | pointer.Press}.Add(gtx.Ops)
+	return D{Size: size}
+}
+
+// layoutCells lays out the children of n in b, and the children of
+// directories large enough below a header with their name.
+func (t *Treemap) layoutCells(gtx C, n *Node, b box, depth int, base color.NRGBA) {
+	sizes := make([]float64, len(n.Children))
+	for i, c := range n.Children {
+		sizes[i] = float64(c.Size)
+	}
+	header := float64(gtx.Px(unit.Dp(18)))
+	pad := float64(gtx.Px(unit.Dp(2)))
+	for i, cb := range squarify(sizes, b) {
+		if cb.W < 1 || cb.H < 1 {
+			continue
+		}
+		child := n.Children[i]
+		col := base
+		if depth == 0 {
+			col = palette[i%len(palette)]
+		}
+		t.cells = append(t.cells, cell{node: child, box: cb, depth: depth, color: col})
+		if depth == 0 && child.Dir && cb.W > 4*header && cb.H > 3*header {
+			inner := box{X: cb.X + pad, Y: cb.Y + header, W: cb.W - 2*pad, H: cb.H - header - pad}
+			t.layoutCells(gtx, child, inner, depth+1, col)
+		}
+	}
+}
+
+func (t *Treemap) drawCell(gtx C, th *material.Theme, c cell) {
+	border := gtx.Px(unit.Dp(1))
+	r := image.Rect(
+		int(math.Round(c.box.X)), int(math.Round(c.box.Y)),
+		int(math.Round(c.box.X+c.box.W)), int(math.Round(c.box.Y+c.box.H)),
+	)
+	fill := c.color
+	if c.depth > 0 {
+		// Lighten nested boxes.
+		fill = mix(fill, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, 0.45)
+	}
+	if c.node == t.hovered {
+		fill = mix(fill, color.NRGBA{A: 0xff}, 0.2)
+	}
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 0x60}, clip.Rect(r).Op())
+	inner := r.Inset(border)
+	if inner.Empty() {
+		return
+	}
+	paint.FillShape(gtx.Ops, fill, clip.Rect(inner).Op())
+	// Label boxes wide enough for a few characters.
+	if inner.Dx() < gtx.Px(unit.Dp(40)) || inner.Dy() < gtx.Px(unit.Dp(16)) {
+		return
+	}
+	stack := op.Save(gtx.Ops)
+	clip.Rect(inner).Add(gtx.Ops)
+	op.Offset(layout.FPt(inner.Min.Add(image.Pt(2*border, 0)))).Add(gtx.Ops)
+	gtx.Constraints = layout.Constraints{Max: inner.Size()}
+	l := material.Caption(th, c.node.Name)
+	l.MaxLines = 1
+	l.Color = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	if c.depth > 0 {
+		l.Color = color.NRGBA{A: 0xcc}
+	}
+	l.Layout(gtx)
+	stack.Load()
+}
+
+// mix blends c1 towards c2 by t.
+func mix(c1, c2 color.NRGBA, t float32) color.NRGBA {
+	m := func(a, b uint8) uint8 {
+		return uint8(float32(a)*(1-t) + float32(b)*t)
+	}
+	return color.NRGBA{R: m(c1.R, c2.R), G: m(c1.G, c2.G), B: m(c1.B, c2.B), A: m(c1.A, c2.A)}
+}
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Node is a file or a directory with the total size of its contents.
+type Node struct {
+	Name   string
+	Size   int64
+	Dir    bool
+	Parent *Node
+	// Children are sorted by decreasing size.
+	Children []*Node
+}
+
+// Path returns the path of the node from the root of the scan.
+func (n *Node) Path() string {
+	if n.Parent == nil {
+		return n.Name
+	}
+	return filepath.Join(n.Parent.Path(), n.Name)
+}
+
+// Scanner walks a directory tree concurrently.
+type Scanner struct {
+	// Files is the number of files scanned so far.
+	Files int64
+
+	sem chan struct{}
+}
+
+// NewScanner returns a Scanner reading at most parallel directories at a
+// time.
+func NewScanner(parallel int) *Scanner {
+	return &Scanner{sem: make(chan struct{}, parallel)}
+}
+
+// Scan returns the tree of root. Unreadable directories count as empty.
+func (s *Scanner) Scan(ctx context.Context, root string) (*Node, error) {
+	n := &Node{Name: root, Dir: true}
+	s.scan(ctx, n, root)
+	return n, ctx.Err()
+}
+
+func (s *Scanner) scan(ctx context.Context, n *Node, path string) {
+	if ctx.Err() != nil {
+		return
+	}
+	s.sem <- struct{}{}
+	entries, err := ioutil.ReadDir(path)
+	<-s.sem
+	if err != nil {
+		return
+	}
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		c := &Node{Name: e.Name(), Parent: n}
+		n.Children = append(n.Children, c)
+		switch {
+		case e.IsDir():
+			c.Dir = true
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.scan(ctx, c, filepath.Join(path, c.Name))
+			}()
+		case e.Mode().IsRegular():
+			c.Size = e.Size()
+			atomic.AddInt64(&s.Files, 1)
+		}
+	}
+	wg.Wait()
+	for _, c := range n.Children {
+		n.Size += c.Size
+	}
+	sort.Slice(n.Children, func(i, j int) bool {
+		return n.Children[i].Size > n.Children[j].Size
+	})
+}
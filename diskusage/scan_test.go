@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]int{
+		"a":       10,
+		"sub/b":   100,
+		"sub/c":   5,
+		"sub/d/e": 50,
+	}
+	for name, size := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := NewScanner(2)
+	n, err := s.Scan(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Size != 165 || s.Files != 4 {
+		t.Errorf("got %d bytes in %d files, want 165 in 4", n.Size, s.Files)
+	}
+	sub := n.Children[0]
+	if sub.Name != "sub" || sub.Size != 155 || !sub.Dir {
+		t.Errorf("got largest child %+v, want sub of 155 bytes", sub)
+	}
+	if got, want := sub.Children[1].Children[0].Path(), filepath.Join(root, "sub", "d", "e"); got != want {
+		t.Errorf("got path %q, want %q", got, want)
+	}
+}
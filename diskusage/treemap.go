@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// box is an axis aligned rectangle.
+type box struct {
+	X, Y, W, H float64
+}
+
+func (b box) contains(x, y float64) bool {
+	return x >= b.X && x < b.X+b.W && y >= b.Y && y < b.Y+b.H
+}
+
+// squarify divides b into boxes with areas proportional to sizes, which
+// must be sorted in decreasing order. It implements the squarified
+// treemap algorithm of Bruls, Huizing and van Wijk, which lays out rows
+// along the shorter side and keeps the boxes close to square.
+func squarify(sizes []float64, b box) []box {
+	boxes := make([]box, 0, len(sizes))
+	var total float64
+	for _, s := range sizes {
+		total += s
+	}
+	if total <= 0 || b.W <= 0 || b.H <= 0 {
+		for range sizes {
+			boxes = append(boxes, box{X: b.X, Y: b.Y})
+		}
+		return boxes
+	}
+	// Scale the sizes to areas.
+	scale := b.W * b.H / total
+	for len(sizes) > 0 {
+		side := b.W
+		if b.H < side {
+			side = b.H
+		}
+		// Grow the row while it improves the worst aspect ratio.
+		n, sum := 1, sizes[0]*scale
+		for n < len(sizes) {
+			next := sum + sizes[n]*scale
+			if worst(sizes[:n+1], next, side, scale) > worst(sizes[:n], sum, side, scale) {
+				break
+			}
+			sum = next
+			n++
+		}
+		thick := sum / side
+		pos := 0.0
+		for _, s := range sizes[:n] {
+			length := 0.0
+			if sum > 0 {
+				length = s * scale / sum * side
+			}
+			if b.W >= b.H {
+				// Lay out a column along the left edge.
+				boxes = append(boxes, box{X: b.X, Y: b.Y + pos, W: thick, H: length})
+			} else {
+				// Lay out a row along the top edge.
+				boxes = append(boxes, box{X: b.X + pos, Y: b.Y, W: length, H: thick})
+			}
+			pos += length
+		}
+		if b.W >= b.H {
+			b.X += thick
+			b.W -= thick
+		} else {
+			b.Y += thick
+			b.H -= thick
+		}
+		sizes = sizes[n:]
+	}
+	return boxes
+}
+
+// worst returns the worst aspect ratio of a row of sizes with the given
+// total area laid out along side.
+func worst(sizes []float64, area, side, scale float64) float64 {
+	if area <= 0 {
+		return 0
+	}
+	max, min := sizes[0]*scale, sizes[len(sizes)-1]*scale
+	if min <= 0 {
+		return 1e300
+	}
+	s2, a2 := side*side, area*area
+	r1 := s2 * max / a2
+	r2 := a2 / (s2 * min)
+	if r1 > r2 {
+		return r1
+	}
+	return r2
+}
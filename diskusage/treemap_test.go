@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSquarify(t *testing.T) {
+	// The example of the paper.
+	sizes := []float64{6, 6, 4, 3, 2, 2, 1}
+	bounds := box{W: 6, H: 4}
+	boxes := squarify(sizes, bounds)
+	if len(boxes) != len(sizes) {
+		t.Fatalf("got %d boxes, want %d", len(boxes), len(sizes))
+	}
+	for i, b := range boxes {
+		if a := b.W * b.H; math.Abs(a-sizes[i]) > 1e-9 {
+			t.Errorf("box %d: got area %g, want %g", i, a, sizes[i])
+		}
+		if b.X < 0 || b.Y < 0 || b.X+b.W > bounds.W+1e-9 || b.Y+b.H > bounds.H+1e-9 {
+			t.Errorf("box %d: %+v outside %+v", i, b, bounds)
+		}
+		for j, o := range boxes[:i] {
+			if b.X+1e-9 < o.X+o.W && o.X+1e-9 < b.X+b.W && b.Y+1e-9 < o.Y+o.H && o.Y+1e-9 < b.Y+b.H {
+				t.Errorf("box %d %+v overlaps box %d %+v", i, b, j, o)
+			}
+		}
+	}
+	// The first two boxes form a column of squares.
+	if b := boxes[0]; math.Abs(b.W-3) > 1e-9 || math.Abs(b.H-2) > 1e-9 {
+		t.Errorf("first box: got %+v, want 3x2", b)
+	}
+}
+
+func TestSquarifyEmpty(t *testing.T) {
+	boxes := squarify([]float64{0, 0}, box{W: 10, H: 10})
+	for i, b := range boxes {
+		if b.W != 0 || b.H != 0 {
+			t.Errorf("box %d: got %+v, want empty", i, b)
+		}
+	}
+}
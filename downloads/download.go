@@ -0,0 +1,279 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Status is the status of a download.
+type Status int
+
+const (
+	Waiting Status = iota
+	Active
+	Paused
+	Completed
+	Failed
+)
+
+func (s Status) String() string {
+	switch s {
+	case Waiting:
+		return "Waiting"
+	case Active:
+		return "Downloading"
+	case Paused:
+		return "Paused"
+	case Completed:
+		return "Completed"
+	case Failed:
+		return "Failed"
+	default:
+		panic("invalid status")
+	}
+}
+
+// Download is a file downloaded in the background. Data is written to a
+// partial file next to the destination, so a paused or failed download
+// resumes where it stopped with a HTTP range request.
+type Download struct {
+	URL  string
+	Path string
+
+	mu       sync.Mutex
+	status   Status
+	received int64
+	// total is the size of the file, or -1 if unknown.
+	total  int64
+	err    error
+	rate   rate
+	cancel context.CancelFunc
+}
+
+// Progress is a snapshot of the state of a download.
+type Progress struct {
+	Status   Status
+	Received int64
+	Total    int64
+	Err      error
+	// BytesPerSecond is the current transfer rate.
+	BytesPerSecond float64
+}
+
+func NewDownload(url, path string) *Download {
+	return &Download{URL: url, Path: path, total: -1}
+}
+
+// Progress returns the current state of the download.
+func (d *Download) Progress() Progress {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return Progress{
+		Status:         d.status,
+		Received:       d.received,
+		Total:          d.total,
+		Err:            d.err,
+		BytesPerSecond: d.rate.BytesPerSecond(),
+	}
+}
+
+// Start runs the download once a slot of slots is free, and calls changed
+// when the download completes or fails.
+func (d *Download) Start(slots chan struct{}, changed func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.mu.Lock()
+	if d.cancel != nil {
+		d.mu.Unlock()
+		cancel()
+		return
+	}
+	d.status = Waiting
+	d.err = nil
+	d.cancel = cancel
+	d.mu.Unlock()
+	go func() {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			d.stopped(ctx, nil)
+			changed()
+			return
+		}
+		d.setStatus(Active)
+		err := d.run(ctx)
+		<-slots
+		d.stopped(ctx, err)
+		changed()
+	}()
+}
+
+// Pause stops a waiting or active download.
+func (d *Download) Pause() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cancel != nil {
+		d.cancel()
+	}
+}
+
+// Remove stops the download and deletes the partial file.
+func (d *Download) Remove() {
+	d.Pause()
+	// The partial file may still be open; removing it is best effort.
+	os.Remove(d.partPath())
+}
+
+func (d *Download) setStatus(s Status) {
+	d.mu.Lock()
+	d.status = s
+	d.mu.Unlock()
+}
+
+func (d *Download) stopped(ctx context.Context, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cancel = nil
+	d.rate.Reset()
+	switch {
+	case ctx.Err() != nil:
+		d.status = Paused
+	case err != nil:
+		d.status = Failed
+		d.err = err
+	default:
+		d.status = Completed
+	}
+}
+
+func (d *Download) partPath() string {
+	return d.Path + ".part"
+}
+
+func (d *Download) run(ctx context.Context) error {
+	f, err := os.OpenFile(d.partPath(), os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	resp, err := d.get(ctx, offset)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		if size, err := parseUnsatisfiedRange(resp.Header.Get("Content-Range")); err != nil || size != offset {
+			// The partial file is longer than the file on the server, or
+			// the file changed; start over.
+			resp.Body.Close()
+			if err := f.Truncate(0); err != nil {
+				return err
+			}
+			if offset, err = f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			if resp, err = d.get(ctx, offset); err != nil {
+				return err
+			}
+		}
+	}
+	defer resp.Body.Close()
+	total := int64(-1)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// The server sent the whole file; start over.
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		offset = 0
+		if resp.ContentLength >= 0 {
+			total = resp.ContentLength
+		}
+	case http.StatusPartialContent:
+		start, t, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return err
+		}
+		if start != offset {
+			return fmt.Errorf("server resumed at byte %d, not %d", start, offset)
+		}
+		total = t
+	case http.StatusRequestedRangeNotSatisfiable:
+		if offset == 0 {
+			return fmt.Errorf("%s: %s", d.URL, resp.Status)
+		}
+		// The partial file is already complete, as checked above.
+		total = offset
+	default:
+		return fmt.Errorf("%s: %s", d.URL, resp.Status)
+	}
+	d.mu.Lock()
+	d.received, d.total = offset, total
+	d.mu.Unlock()
+	if resp.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		if err := d.copy(f, resp.Body); err != nil {
+			return err
+		}
+	}
+	d.mu.Lock()
+	received := d.received
+	if total < 0 {
+		// Now the size is known.
+		d.total = received
+	}
+	d.mu.Unlock()
+	if total >= 0 && received != total {
+		return errors.New("download ended early")
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(d.partPath(), d.Path)
+}
+
+// get requests the file from byte offset on.
+func (d *Download) get(ctx context.Context, offset int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", d.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	return http.DefaultClient.Do(req)
+}
+
+func (d *Download) copy(w io.Writer, r io.Reader) error {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+			d.mu.Lock()
+			d.received += int64(n)
+			d.rate.Add(int64(n), time.Now())
+			d.mu.Unlock()
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
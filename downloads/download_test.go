@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDownloadResume(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"range", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+		}},
+		{"no range", func(w http.ResponseWriter, r *http.Request) {
+			w.Write(content)
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := httptest.NewServer(test.handler)
+			defer srv.Close()
+			path := filepath.Join(t.TempDir(), "file")
+			d := NewDownload(srv.URL, path)
+			// Simulate an interrupted download, with a corrupt tail for
+			// the server without range support to overwrite.
+			partial := append(content[:len(content)/2:len(content)/2], "xx"...)
+			if test.name == "range" {
+				partial = content[:len(content)/2]
+			}
+			if err := ioutil.WriteFile(d.partPath(), partial, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := d.run(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, content) {
+				t.Errorf("got %d bytes, want the %d bytes served", len(got), len(content))
+			}
+			if p := d.Progress(); p.Received != int64(len(content)) || p.Total != int64(len(content)) {
+				t.Errorf("got progress %d/%d, want %d", p.Received, p.Total, len(content))
+			}
+		})
+	}
+}
+
+func TestDownloadRangeNotSatisfiable(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+	tests := []struct {
+		name    string
+		partial []byte
+	}{
+		{"complete", content},
+		// The file on the server is shorter than the partial file.
+		{"longer", append(content[:len(content):len(content)], "xx"...)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file")
+			d := NewDownload(srv.URL, path)
+			if err := ioutil.WriteFile(d.partPath(), test.partial, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := d.run(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, content) {
+				t.Errorf("got %d bytes, want the %d bytes served", len(got), len(content))
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates a download manager: files download in
+// parallel in the background, up to a limit, and can be paused and
+// resumed with HTTP range requests. The list shows the progress, speed
+// and time left of each download, refreshed a few times a second while
+// downloads are active.
+//
+// Gio has no API for dragging files out of the window to other programs,
+// so completed files are shown in the file manager instead.
+//
+// The -net-* flags simulate a slow or failing network to see the
+// progress and retry states; for example -net-bandwidth 100k.
+
+import (
+	"flag"
+	"fmt"
+	"image/color"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/bytesize"
+	"gioui.org/example/internal/netsim"
+	"gioui.org/example/internal/style"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+var (
+	dir      = flag.String("dir", defaultDir(), "directory to download to")
+	parallel = flag.Int("parallel", 3, "number of simultaneous downloads")
+)
+
+// refreshInterval is how often the list is redrawn while downloading.
+const refreshInterval = 250 * time.Millisecond
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	flag.Parse()
+	netsim.Install()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Downloads"),
+			app.Size(unit.Dp(600), unit.Dp(520)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+func defaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return os.TempDir()
+	}
+	return filepath.Join(home, "Downloads")
+}
+
+// item is a download in the list.
+type item struct {
+	*Download
+	name string
+
+	toggle, remove, show widget.Clickable
+}
+
+type App struct {
+	w     *app.Window
+	slots chan struct{}
+	items []*item
+	err   string
+
+	url  widget.Editor
+	add  widget.Clickable
+	list layout.List
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		w:     w,
+		slots: make(chan struct{}, *parallel),
+		url:   widget.Editor{SingleLine: true, Submit: true},
+		list:  layout.List{Axis: layout.Vertical},
+	}
+	a.url.Focus()
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			for _, it := range a.items {
+				it.Pause()
+			}
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			a.update()
+			layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+				return a.Layout(gtx, th)
+			})
+			for _, it := range a.items {
+				if s := it.Progress().Status; s == Active || s == Waiting {
+					op.InvalidateOp{At: gtx.Now.Add(refreshInterval)}.Add(gtx.Ops)
+					break
+				}
+			}
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+func (a *App) update() {
+	submitted := false
+	for _, e := range a.url.Events() {
+		if _, ok := e.(widget.SubmitEvent); ok {
+			submitted = true
+		}
+	}
+	for a.add.Clicked() {
+		submitted = true
+	}
+	if submitted {
+		if err := a.download(strings.TrimSpace(a.url.Text())); err != nil {
+			a.err = err.Error()
+		} else {
+			a.err = ""
+			a.url.SetText("")
+		}
+	}
+	for i := 0; i < len(a.items); i++ {
+		it := a.items[i]
+		for it.toggle.Clicked() {
+			switch it.Progress().Status {
+			case Waiting, Active:
+				it.Pause()
+			case Paused, Failed:
+				it.Start(a.slots, a.w.Invalidate)
+			}
+		}
+		for it.show.Clicked() {
+			if err := showFile(it.Path); err != nil {
+				a.err = err.Error()
+			}
+		}
+		for it.remove.Clicked() {
+			if it.Progress().Status != Completed {
+				it.Remove()
+			}
+			a.items = append(a.items[:i], a.items[i+1:]...)
+			i--
+			break
+		}
+	}
+}
+
+// download starts downloading a URL.
+func (a *App) download(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%q is not a HTTP URL", rawURL)
+	}
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		return err
+	}
+	name := path.Base(u.Path)
+	if name == "/" || name == "." {
+		name = "download"
+	}
+	it := &item{
+		Download: NewDownload(u.String(), a.uniquePath(name)),
+	}
+	it.name = filepath.Base(it.Path)
+	a.items = append(a.items, it)
+	it.Start(a.slots, a.w.Invalidate)
+	return nil
+}
+
+// uniquePath returns a path in the download directory for name that
+// doesn't clash with existing files or other downloads.
+func (a *App) uniquePath(name string) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; ; i++ {
+		p := filepath.Join(*dir, name)
+		taken := false
+		for _, it := range a.items {
+			if it.Path == p {
+				taken = true
+			}
+		}
+		if _, err := os.Stat(p); err == nil {
+			taken = true
+		}
+		if !taken {
+			return p
+		}
+		name = base + " (" + strconv.Itoa(i) + ")" + ext
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, func(gtx C) D {
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					return widget.Border{Color: color.NRGBA{A: 0x40}, CornerRadius: unit.Dp(4), Width: unit.Dp(1)}.Layout(gtx, func(gtx C) D {
+						return layout.UniformInset(unit.Dp(8)).Layout(gtx, material.Editor(th, &a.url, "https://example.com/file.zip").Layout)
+					})
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+				layout.Rigid(material.Button(th, &a.add, "Download").Layout),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			msg := "Saving to " + *dir
+			l := material.Caption(th, msg)
+			if a.err != "" {
+				l = material.Caption(th, a.err)
+				l.Color = errorColor
+			}
+			return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(8)}.Layout(gtx, l.Layout)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return a.list.Layout(gtx, len(a.items), func(gtx C, i int) D {
+				return a.items[i].Layout(gtx, th)
+			})
+		}),
+	)
+}
+
+var errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+
+func (it *item) Layout(gtx C, th *material.Theme) D {
+	p := it.Progress()
+	var frac float32
+	if p.Total > 0 {
+		frac = float32(p.Received) / float32(p.Total)
+	}
+	size := bytesize.Format(p.Received)
+	if p.Total >= 0 {
+		size += " of " + bytesize.Format(p.Total)
+	}
+	status := p.Status.String()
+	switch p.Status {
+	case Active:
+		status = fmt.Sprintf("%s · %s/s", size, bytesize.Format(int64(p.BytesPerSecond)))
+		if p.Total >= 0 {
+			status += " · " + formatDuration(eta(p.Total-p.Received, p.BytesPerSecond)) + " left"
+		}
+	case Paused:
+		status = "Paused · " + size
+	case Completed:
+		status = bytesize.Format(p.Received)
+	case Failed:
+		status = fmt.Sprintf("Failed: %v", p.Err)
+	}
+	toggle := ""
+	switch p.Status {
+	case Waiting, Active:
+		toggle = "Pause"
+	case Paused:
+		toggle = "Resume"
+	case Failed:
+		toggle = "Retry"
+	}
+	return layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(6)}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(material.Body1(th, it.name).Layout),
+					layout.Rigid(func(gtx C) D {
+						if p.Status == Completed {
+							return D{}
+						}
+						return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx,
+							material.ProgressBar(th, frac).Layout)
+					}),
+					layout.Rigid(func(gtx C) D {
+						l := material.Caption(th, status)
+						if p.Status == Failed {
+							l.Color = errorColor
+						}
+						return l.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+			layout.Rigid(func(gtx C) D {
+				if toggle == "" {
+					return style.TextButton(th, &it.show, "Show in folder")(gtx)
+				}
+				return style.TextButton(th, &it.toggle, toggle)(gtx)
+			}),
+			layout.Rigid(style.TextButton(th, &it.remove, "Remove")),
+		)
+	})
+}
+
+// showFile shows a file in the file manager.
+func showFile(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", "-R", path)
+	case "windows":
+		cmd = exec.Command("explorer", "/select,"+path)
+	default:
+		cmd = exec.Command("xdg-open", filepath.Dir(path))
+	}
+	return cmd.Start()
+}
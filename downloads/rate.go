@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rateWindow is the interval over which transfer rates are sampled.
+const rateWindow = 500 * time.Millisecond
+
+// rate estimates a transfer rate, smoothed over time so that the speed and
+// time left don't jump around.
+type rate struct {
+	start time.Time
+	bytes int64
+	// bps is the smoothed rate in bytes per second.
+	bps float64
+}
+
+// Add records n bytes transferred at now.
+func (r *rate) Add(n int64, now time.Time) {
+	if r.start.IsZero() {
+		// The time the first bytes took is unknown; start timing from them.
+		r.start = now
+		return
+	}
+	r.bytes += n
+	dt := now.Sub(r.start)
+	if dt < rateWindow {
+		return
+	}
+	sample := float64(r.bytes) / dt.Seconds()
+	if r.bps == 0 {
+		r.bps = sample
+	} else {
+		r.bps = 0.7*r.bps + 0.3*sample
+	}
+	r.start = now
+	r.bytes = 0
+}
+
+// Reset forgets the rate, for when the transfer pauses.
+func (r *rate) Reset() {
+	*r = rate{}
+}
+
+// BytesPerSecond returns the estimated rate.
+func (r *rate) BytesPerSecond() float64 {
+	return r.bps
+}
+
+// eta returns the time left to transfer remaining bytes at bps, or -1 if
+// unknown.
+func eta(remaining int64, bps float64) time.Duration {
+	if bps <= 0 || remaining < 0 {
+		return -1
+	}
+	return time.Duration(float64(remaining) / bps * float64(time.Second))
+}
+
+// formatDuration formats a time left coarsely.
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "unknown"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()+0.5))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm %ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+// parseContentRange parses a Content-Range header such as
+// "bytes 100-199/1000", returning the first byte and the total size, or -1
+// for an unknown size.
+func parseContentRange(h string) (start, total int64, err error) {
+	spec := strings.TrimPrefix(h, "bytes ")
+	slash := strings.IndexByte(spec, '/')
+	dash := strings.IndexByte(spec, '-')
+	if spec == h || slash == -1 || dash == -1 || dash > slash {
+		return 0, 0, fmt.Errorf("invalid Content-Range: %q", h)
+	}
+	start, err = strconv.ParseInt(spec[:dash], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range: %q", h)
+	}
+	if t := spec[slash+1:]; t == "*" {
+		total = -1
+	} else if total, err = strconv.ParseInt(t, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range: %q", h)
+	}
+	return start, total, nil
+}
+
+// parseUnsatisfiedRange parses the Content-Range header of a 416 response,
+// "bytes */1000", returning the size of the file.
+func parseUnsatisfiedRange(h string) (int64, error) {
+	t := strings.TrimPrefix(h, "bytes */")
+	size, err := strconv.ParseInt(t, 10, 64)
+	if t == h || err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid Content-Range: %q", h)
+	}
+	return size, nil
+}
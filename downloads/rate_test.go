@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRate(t *testing.T) {
+	var r rate
+	now := time.Unix(0, 0)
+	for i := 0; i < 10; i++ {
+		r.Add(1000, now)
+		now = now.Add(100 * time.Millisecond)
+	}
+	if bps := r.BytesPerSecond(); bps < 9000 || bps > 11000 {
+		t.Errorf("got %.0f B/s, want about 10000", bps)
+	}
+	if d := eta(20000, r.BytesPerSecond()); d < time.Second || d > 3*time.Second {
+		t.Errorf("got ETA %v, want about 2s", d)
+	}
+	r.Reset()
+	if d := eta(20000, r.BytesPerSecond()); d != -1 {
+		t.Errorf("got ETA %v after reset, want unknown", d)
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		h            string
+		start, total int64
+		ok           bool
+	}{
+		{"bytes 100-199/1000", 100, 1000, true},
+		{"bytes 0-0/*", 0, -1, true},
+		{"bytes */1000", 0, 0, false},
+		{"items 0-1/2", 0, 0, false},
+		{"bytes 10-5", 0, 0, false},
+	}
+	for _, test := range tests {
+		start, total, err := parseContentRange(test.h)
+		if (err == nil) != test.ok || start != test.start || total != test.total {
+			t.Errorf("parseContentRange(%q) = %d, %d, %v", test.h, start, total, err)
+		}
+	}
+}
+
+func TestParseUnsatisfiedRange(t *testing.T) {
+	tests := []struct {
+		h    string
+		size int64
+		ok   bool
+	}{
+		{"bytes */1000", 1000, true},
+		{"bytes */0", 0, true},
+		{"bytes 0-9/1000", 0, false},
+		{"bytes */*", 0, false},
+		{"", 0, false},
+	}
+	for _, test := range tests {
+		size, err := parseUnsatisfiedRange(test.h)
+		if (err == nil) != test.ok || size != test.size {
+			t.Errorf("parseUnsatisfiedRange(%q) = %d, %v", test.h, size, err)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program exports a document laid out with Gio to SVG and PDF at
+// vector quality: the operations of the document are replayed into
+// paths, clips, gradients and images by package internal/vector, so text
+// and shapes stay sharp at any zoom. The document is a small report with
+// a chart, the kind of output a program might print or attach to an
+// email.
+//
+// Usage:
+//
+//	go run ./export [-o directory]
+//
+// With -svg or -pdf, the document is exported without opening a window:
+//
+//	go run ./export -svg report.svg -pdf report.pdf
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/fakedata"
+	"gioui.org/example/internal/vector"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	outDir  = flag.String("o", ".", "directory for the exported files")
+	svgFile = flag.String("svg", "", "export the document to an SVG file and exit")
+	pdfFile = flag.String("pdf", "", "export the document to a PDF file and exit")
+)
+
+// pageSize is the size of the document in exports without a window.
+var pageSize = image.Pt(595, 842)
+
+func main() {
+	flag.Parse()
+	if *svgFile != "" || *pdfFile != "" {
+		if err := exportFiles(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("Export"),
+			app.Size(unit.Dp(640), unit.Dp(860)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// exportFiles lays out the document on a page and writes the files of
+// the flags.
+func exportFiles() error {
+	th := material.NewTheme(gofont.Collection())
+	doc := newReport()
+	var ops op.Ops
+	gtx := layout.Context{
+		Ops:         &ops,
+		Now:         time.Now(),
+		Metric:      unit.Metric{PxPerDp: 1, PxPerSp: 1},
+		Constraints: layout.Exact(pageSize),
+	}
+	m := op.Record(gtx.Ops)
+	doc.Layout(gtx, th)
+	sc, err := decode(pageSize, th.Palette.Bg, m.Stop())
+	if err != nil {
+		return err
+	}
+	for _, f := range []struct {
+		name  string
+		write func(w io.Writer) error
+	}{
+		{*svgFile, sc.WriteSVG},
+		{*pdfFile, sc.WritePDF},
+	} {
+		if f.name == "" {
+			continue
+		}
+		if err := writeFile(f.name, f.write); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decode replays call on a background of color bg.
+func decode(size image.Point, bg color.NRGBA, call op.CallOp) (*vector.Scene, error) {
+	var ops op.Ops
+	paint.FillShape(&ops, bg, clip.Rect{Max: size}.Op())
+	call.Add(&ops)
+	return vector.Decode(&ops, size)
+}
+
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = write(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+var errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+
+// saved is the result of writing a file.
+type saved struct {
+	path string
+	err  error
+}
+
+type App struct {
+	saves  chan saved
+	saving bool
+	status string
+	err    error
+	// export is the format requested for the next frame, if any.
+	export string
+
+	svg, pdf widget.Clickable
+
+	doc *report
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{saves: make(chan saved, 1), doc: newReport()}
+	var ops op.Ops
+	for {
+		select {
+		case s := <-a.saves:
+			a.saving = false
+			a.err = s.err
+			if s.err == nil {
+				a.status = "Saved " + s.path
+			}
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	for a.svg.Clicked() {
+		a.export = "svg"
+	}
+	for a.pdf.Clicked() {
+		a.export = "pdf"
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, a.layoutToolbar(th))
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			gtx.Constraints.Min = gtx.Constraints.Max
+			// Record the document to draw it both here and into the
+			// export.
+			m := op.Record(gtx.Ops)
+			dims := a.doc.Layout(gtx, th)
+			call := m.Stop()
+			paint.FillShape(gtx.Ops, th.Palette.Bg, clip.Rect{Max: dims.Size}.Op())
+			call.Add(gtx.Ops)
+			if a.export != "" {
+				a.save(a.export, dims.Size, th.Palette.Bg, call)
+				a.export = ""
+			}
+			return dims
+		}),
+	)
+}
+
+// save exports the document to a new file in the background. The scene
+// is decoded right away, while the operations are valid.
+func (a *App) save(ext string, size image.Point, bg color.NRGBA, call op.CallOp) {
+	sc, err := decode(size, bg, call)
+	if err != nil {
+		a.err = err
+		return
+	}
+	write := sc.WriteSVG
+	if ext == "pdf" {
+		write = sc.WritePDF
+	}
+	name := fmt.Sprintf("export-%s.%s", time.Now().Format("20060102-150405.000"), ext)
+	path := filepath.Join(*outDir, name)
+	a.saving = true
+	a.status = "Saving…"
+	go func() {
+		a.saves <- saved{path, writeFile(path, write)}
+	}()
+}
+
+func (a *App) layoutToolbar(th *material.Theme) layout.Widget {
+	return func(gtx C) D {
+		if a.saving {
+			gtx = gtx.Disabled()
+		}
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(material.Button(th, &a.svg, "Save SVG").Layout),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+			layout.Rigid(material.Button(th, &a.pdf, "Save PDF").Layout),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+			layout.Flexed(1, func(gtx C) D {
+				l := material.Body2(th, a.status)
+				if a.err != nil {
+					l.Text = a.err.Error()
+					l.Color = errorColor
+				}
+				return l.Layout(gtx)
+			}),
+		)
+	}
+}
+
+// report is the exported document: a header with an image, text and a
+// bar chart.
+type report struct {
+	author string
+	avatar paint.ImageOp
+	text   string
+	sales  []float64
+}
+
+var months = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+func newReport() *report {
+	f := fakedata.New(7)
+	r := &report{
+		author: f.Name(),
+		text:   f.Paragraph(4),
+	}
+	start := time.Date(2021, time.January, 1, 12, 0, 0, 0, time.UTC)
+	for _, p := range f.TimeSeries(start, 30*24*time.Hour, len(months)) {
+		r.sales = append(r.sales, p.Value)
+	}
+	r.avatar = paint.NewImageOp(fakedata.Avatar(r.author, 64))
+	return r
+}
+
+var (
+	accent = color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0xff}
+	light  = color.NRGBA{R: 0x9f, G: 0xa8, B: 0xda, A: 0xff}
+	grid   = color.NRGBA{A: 0x30}
+)
+
+func (r *report) Layout(gtx C, th *material.Theme) D {
+	layout.UniformInset(unit.Dp(32)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						sz := gtx.Px(unit.Dp(48))
+						gtx.Constraints = layout.Exact(image.Pt(sz, sz))
+						defer op.Save(gtx.Ops).Load()
+						rr := float32(sz) / 2
+						clip.UniformRRect(f32.Rectangle{Max: f32.Pt(float32(sz), float32(sz))}, rr).Add(gtx.Ops)
+						return widget.Image{Src: r.avatar, Fit: widget.Fill}.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+					layout.Flexed(1, func(gtx C) D {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(material.H5(th, "Quarterly report").Layout),
+							layout.Rigid(material.Caption(th, "Prepared by "+r.author).Layout),
+						)
+					}),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+			layout.Rigid(func(gtx C) D {
+				// A banner with a gradient.
+				size := image.Pt(gtx.Constraints.Max.X, gtx.Px(unit.Dp(4)))
+				defer op.Save(gtx.Ops).Load()
+				clip.Rect{Max: size}.Add(gtx.Ops)
+				paint.LinearGradientOp{
+					Stop1:  f32.Pt(0, 0),
+					Color1: accent,
+					Stop2:  f32.Pt(float32(size.X), 0),
+					Color2: light,
+				}.Add(gtx.Ops)
+				paint.PaintOp{}.Add(gtx.Ops)
+				return D{Size: size}
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+			layout.Rigid(material.Body1(th, r.text).Layout),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(24)}.Layout),
+			layout.Rigid(material.H6(th, "Monthly sales").Layout),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+			layout.Flexed(1, func(gtx C) D {
+				return r.layoutChart(gtx, th)
+			}),
+		)
+	})
+	return D{Size: gtx.Constraints.Max}
+}
+
+// layoutChart draws the sales as bars over a grid, with the months
+// below.
+func (r *report) layoutChart(gtx C, th *material.Theme) D {
+	size := gtx.Constraints.Max
+	if size.Y > gtx.Px(unit.Dp(320)) {
+		size.Y = gtx.Px(unit.Dp(320))
+	}
+	labelH := gtx.Px(unit.Dp(20))
+	chartH := float32(size.Y - labelH)
+	max := 0.0
+	for _, v := range r.sales {
+		if v > max {
+			max = v
+		}
+	}
+	width := float32(gtx.Px(unit.Dp(1)))
+
+	// Grid lines and axes, stroked.
+	for i := 0; i <= 4; i++ {
+		y := chartH * float32(i) / 4
+		var p clip.Path
+		p.Begin(gtx.Ops)
+		p.MoveTo(f32.Pt(0, y))
+		p.LineTo(f32.Pt(float32(size.X), y))
+		col := grid
+		if i == 4 {
+			col = th.Palette.Fg
+		}
+		paint.FillShape(gtx.Ops, col, clip.Stroke{
+			Path:  p.End(),
+			Style: clip.StrokeStyle{Width: width, Cap: clip.RoundCap, Join: clip.RoundJoin},
+		}.Op())
+	}
+
+	slot := float32(size.X) / float32(len(r.sales))
+	for i, v := range r.sales {
+		h := chartH * float32(v/max)
+		x := slot * float32(i)
+		bar := f32.Rect(x+slot*0.2, chartH-h, x+slot*0.8, chartH)
+		stack := op.Save(gtx.Ops)
+		clip.RRect{Rect: bar, NE: 3 * width, NW: 3 * width}.Add(gtx.Ops)
+		paint.LinearGradientOp{
+			Stop1:  f32.Pt(0, chartH-h),
+			Color1: accent,
+			Stop2:  f32.Pt(0, chartH),
+			Color2: light,
+		}.Add(gtx.Ops)
+		paint.PaintOp{}.Add(gtx.Ops)
+		stack.Load()
+
+		// The month below the bar.
+		stack = op.Save(gtx.Ops)
+		op.Offset(f32.Pt(x, chartH)).Add(gtx.Ops)
+		lgtx := gtx
+		lgtx.Constraints = layout.Exact(image.Pt(int(slot), labelH))
+		layout.S.Layout(lgtx, func(gtx C) D {
+			l := material.Caption(th, months[i])
+			l.Alignment = text.Middle
+			return l.Layout(gtx)
+		})
+		stack.Load()
+	}
+	return D{Size: size}
+}
@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportFiles(t *testing.T) {
+	dir := t.TempDir()
+	*svgFile = filepath.Join(dir, "report.svg")
+	*pdfFile = filepath.Join(dir, "report.pdf")
+	defer func() { *svgFile, *pdfFile = "", "" }()
+	if err := exportFiles(); err != nil {
+		t.Fatal(err)
+	}
+	svg, err := os.ReadFile(*svgFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The avatar is an image and the text glyph outlines.
+	for _, want := range []string{"<image ", "<linearGradient ", `stroke-linecap="round"`} {
+		if !strings.Contains(string(svg), want) {
+			t.Errorf("SVG lacks %s", want)
+		}
+	}
+	if n := strings.Count(string(svg), "<path "); n < 30 {
+		t.Errorf("got %d paths, want the lines of text too", n)
+	}
+	pdf, err := os.ReadFile(*pdfFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+		t.Errorf("not a PDF document")
+	}
+}
@@ -0,0 +1,264 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+// Feed is a parsed RSS or Atom feed.
+type Feed struct {
+	Title string
+	Items []Item
+}
+
+// Item is an entry of a feed. Enclosure is the URL of the attached
+// media, such as the audio of a podcast episode.
+type Item struct {
+	GUID      string
+	Title     string
+	Link      string
+	Summary   string
+	Enclosure string
+	Published time.Time
+}
+
+// xmlFeed matches the documents of RSS 2.0, RSS 1.0 and Atom. Elements
+// are matched by local name, so namespaces don't matter.
+type xmlFeed struct {
+	XMLName xml.Name
+	// Channel is the channel of RSS, and Items the items of RSS 1.0,
+	// which are siblings of the channel.
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items []rssItem `xml:"item"`
+	// Title and Entries are the elements of Atom.
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	GUID        string `xml:"guid"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Content     string `xml:"encoded"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"date"`
+	Enclosure   struct {
+		URL string `xml:"url,attr"`
+	} `xml:"enclosure"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Summary   string     `xml:"summary"`
+	Content   string     `xml:"content"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+// ParseFeed parses an RSS or Atom document.
+func ParseFeed(r io.Reader) (*Feed, error) {
+	d := xml.NewDecoder(r)
+	// Feeds in the wild are often sloppy about entities.
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	d.CharsetReader = charsetReader
+	var doc xmlFeed
+	if err := d.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("feed: %w", err)
+	}
+	f := new(Feed)
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss", "rdf":
+		f.Title = doc.Channel.Title
+		for _, it := range append(doc.Channel.Items, doc.Items...) {
+			f.Items = append(f.Items, it.item())
+		}
+	case "feed":
+		f.Title = doc.Title
+		for _, e := range doc.Entries {
+			f.Items = append(f.Items, e.item())
+		}
+	default:
+		return nil, fmt.Errorf("feed: unknown document <%s>", doc.XMLName.Local)
+	}
+	f.Title = strings.TrimSpace(f.Title)
+	return f, nil
+}
+
+func (it rssItem) item() Item {
+	summary := it.Description
+	if summary == "" {
+		summary = it.Content
+	}
+	date := it.PubDate
+	if date == "" {
+		// The dc:date of RSS 1.0.
+		date = it.Date
+	}
+	return newItem(it.GUID, it.Title, strings.TrimSpace(it.Link), summary, it.Enclosure.URL, date)
+}
+
+func (e atomEntry) item() Item {
+	var link, enclosure string
+	for _, l := range e.Links {
+		switch l.Rel {
+		case "", "alternate":
+			if link == "" {
+				link = l.Href
+			}
+		case "enclosure":
+			enclosure = l.Href
+		}
+	}
+	summary := e.Summary
+	if summary == "" {
+		summary = e.Content
+	}
+	date := e.Published
+	if date == "" {
+		date = e.Updated
+	}
+	return newItem(e.ID, e.Title, link, summary, enclosure, date)
+}
+
+func newItem(guid, title, link, summary, enclosure, date string) Item {
+	it := Item{
+		GUID:      strings.TrimSpace(guid),
+		Title:     strings.TrimSpace(plainText(title)),
+		Link:      link,
+		Summary:   plainText(summary),
+		Enclosure: enclosure,
+		Published: parseTime(date),
+	}
+	// Not every feed has ids; the link or the title and date identify
+	// items well enough.
+	if it.GUID == "" {
+		it.GUID = it.Link
+	}
+	if it.GUID == "" {
+		it.GUID = it.Title + "\x00" + strings.TrimSpace(date)
+	}
+	return it
+}
+
+// timeLayouts are the date formats of feeds, starting with the standard
+// ones of RSS and Atom.
+var timeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseTime parses a date of a feed, returning the zero time for missing
+// or malformed dates.
+func parseTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, l := range timeLayouts {
+		if t, err := time.Parse(l, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// plainText converts the HTML of summaries to plain text: tags are
+// dropped, paragraphs and line breaks become newlines and runs of white
+// space collapse.
+func plainText(s string) string {
+	var b strings.Builder
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '<')
+		if i == -1 {
+			b.WriteString(s)
+			break
+		}
+		b.WriteString(s[:i])
+		s = s[i:]
+		j := strings.IndexByte(s, '>')
+		if j == -1 {
+			// Not a tag after all.
+			b.WriteString(s)
+			break
+		}
+		tag := strings.ToLower(strings.Trim(s[1:j], "/ "))
+		if k := strings.IndexAny(tag, " \t\n"); k != -1 {
+			tag = tag[:k]
+		}
+		switch tag {
+		case "p", "br", "div", "li", "h1", "h2", "h3", "h4", "blockquote":
+			b.WriteString("\n")
+		}
+		s = s[j+1:]
+	}
+	text := html.UnescapeString(b.String())
+	var lines []string
+	for _, l := range strings.Split(text, "\n") {
+		if l = strings.Join(strings.Fields(l), " "); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// charsetReader decodes the legacy charsets common in feeds. Latin-1 and
+// its Windows variant are decoded alike, which is right for everything
+// but the typographic characters of the latter.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "windows-1252", "us-ascii":
+		return &latin1Reader{r: input}, nil
+	}
+	return nil, errors.New("unsupported charset " + charset)
+}
+
+type latin1Reader struct {
+	r   io.Reader
+	buf []byte
+}
+
+func (l *latin1Reader) Read(p []byte) (int, error) {
+	// Each byte becomes at most 2 bytes of UTF-8.
+	if len(p) < 2 {
+		return 0, io.ErrShortBuffer
+	}
+	if cap(l.buf) < len(p)/2 {
+		l.buf = make([]byte, len(p)/2)
+	}
+	buf := l.buf[:len(p)/2]
+	n, err := l.r.Read(buf)
+	i := 0
+	for _, c := range buf[:n] {
+		if c < 0x80 {
+			p[i] = c
+			i++
+		} else {
+			p[i], p[i+1] = 0xc0|c>>6, 0x80|c&0x3f
+			i += 2
+		}
+	}
+	return i, err
+}
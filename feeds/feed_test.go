@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const rssDoc = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<channel>
+	<title> Gopher Radio </title>
+	<item>
+		<guid>ep-2</guid>
+		<title>Episode 2: Generics &amp; you</title>
+		<link>https://example.com/2</link>
+		<description>&lt;p&gt;Part &lt;b&gt;two&lt;/b&gt;.&lt;/p&gt;&lt;p&gt;More&amp;nbsp;soon.&lt;/p&gt;</description>
+		<pubDate>Tue, 02 Mar 2021 10:00:00 +0000</pubDate>
+		<enclosure url="https://example.com/2.mp3" type="audio/mpeg" length="1"/>
+	</item>
+	<item>
+		<title>Untitled</title>
+		<link>https://example.com/1</link>
+		<content:encoded><![CDATA[<div>First</div>]]></content:encoded>
+		<pubDate>Mon, 1 Mar 2021 09:30:00 GMT</pubDate>
+	</item>
+</channel>
+</rss>`
+
+const atomDoc = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>The Blog</title>
+	<entry>
+		<id>tag:example.com,2021:1</id>
+		<title type="html">Hello &lt;em&gt;world&lt;/em&gt;</title>
+		<link rel="alternate" href="https://example.com/hello"/>
+		<link rel="enclosure" href="https://example.com/hello.ogg"/>
+		<updated>2021-05-01T12:00:00Z</updated>
+		<summary>Short.</summary>
+	</entry>
+</feed>`
+
+func TestParseRSS(t *testing.T) {
+	f, err := ParseFeed(strings.NewReader(rssDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Title != "Gopher Radio" {
+		t.Errorf("title %q", f.Title)
+	}
+	if len(f.Items) != 2 {
+		t.Fatalf("%d items, want 2", len(f.Items))
+	}
+	it := f.Items[0]
+	want := Item{
+		GUID:      "ep-2",
+		Title:     "Episode 2: Generics & you",
+		Link:      "https://example.com/2",
+		Summary:   "Part two.\nMore soon.",
+		Enclosure: "https://example.com/2.mp3",
+		Published: time.Date(2021, 3, 2, 10, 0, 0, 0, time.UTC),
+	}
+	if !it.Published.Equal(want.Published) {
+		t.Errorf("published %v, want %v", it.Published, want.Published)
+	}
+	it.Published = want.Published
+	if it != want {
+		t.Errorf("item\n%+v\nwant\n%+v", it, want)
+	}
+	// Items without guids are identified by their links.
+	if it := f.Items[1]; it.GUID != "https://example.com/1" || it.Summary != "First" || it.Published.IsZero() {
+		t.Errorf("item %+v", it)
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	f, err := ParseFeed(strings.NewReader(atomDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Title != "The Blog" || len(f.Items) != 1 {
+		t.Fatalf("feed %+v", f)
+	}
+	it := f.Items[0]
+	if it.GUID != "tag:example.com,2021:1" || it.Title != "Hello world" || it.Link != "https://example.com/hello" ||
+		it.Enclosure != "https://example.com/hello.ogg" || it.Summary != "Short." {
+		t.Errorf("item %+v", it)
+	}
+	if want := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC); !it.Published.Equal(want) {
+		t.Errorf("published %v, want %v", it.Published, want)
+	}
+}
+
+func TestParseLatin1(t *testing.T) {
+	doc := "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><rss><channel><title>Caf\xe9</title></channel></rss>"
+	f, err := ParseFeed(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Title != "Café" {
+		t.Errorf("title %q", f.Title)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, doc := range []string{"", "<html><body>Not a feed</body></html>", "not xml"} {
+		if _, err := ParseFeed(strings.NewReader(doc)); err == nil {
+			t.Errorf("%q parsed", doc)
+		}
+	}
+}
@@ -0,0 +1,767 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program is a reader of RSS and Atom feeds, such as blogs and
+// podcasts. The feeds are fetched on a schedule by a background
+// refresher, a few at a time and with conditional requests, and their
+// items are stored in an SQLite database with their read state. The UI
+// only runs short queries, so it stays responsive while feeds download.
+//
+// Swipe an item right to toggle it read, or left to archive it; with a
+// mouse, drag it. Clicking an item shows it and marks it read.
+//
+// Usage:
+//
+//	go run ./feeds [-db feeds.db] [-interval 15m] [feed URL...]
+//
+// The feed URLs are subscribed to; with none, and no feeds in the
+// database, a few defaults are.
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/style"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/gesture"
+	"gioui.org/io/pointer"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	dbFlag       = flag.String("db", "", "database `file` (default feeds.db in the user config directory)")
+	intervalFlag = flag.Duration("interval", 15*time.Minute, "time between refreshes")
+	workersFlag  = flag.Int("workers", 4, "number of feeds fetched at once")
+)
+
+// defaultFeeds are subscribed to in a new database.
+var defaultFeeds = []string{
+	"https://go.dev/blog/feed.atom",
+	"https://changelog.com/gotime/feed",
+}
+
+func main() {
+	flag.Parse()
+	s, err := openStore()
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("Feeds"),
+			app.Size(unit.Dp(1100), unit.Dp(700)),
+		)
+		err := loop(w, s)
+		s.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// openStore opens the database and subscribes to the feeds of the
+// command line.
+func openStore() (*Store, error) {
+	path := *dbFlag
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(dir, "gio-feeds")
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, "feeds.db")
+	}
+	s, err := OpenStore(path)
+	if err != nil {
+		return nil, err
+	}
+	urls := flag.Args()
+	if len(urls) == 0 {
+		feeds, err := s.Feeds()
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		if len(feeds) == 0 {
+			urls = defaultFeeds
+		}
+	}
+	for _, u := range urls {
+		if _, err := s.AddFeed(u); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+const (
+	// swipeThreshold is how far an item is swiped to act on it.
+	swipeThreshold = 96
+	// itemLimit bounds the number of items listed.
+	itemLimit = 1000
+)
+
+var (
+	selectedBg   = color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0x30}
+	readColor    = color.NRGBA{R: 0x38, G: 0x8e, B: 0x3c, A: 0xff}
+	archiveColor = color.NRGBA{R: 0xef, G: 0x6c, B: 0x00, A: 0xff}
+	errorColor   = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+)
+
+// row is the swipe state of an item of the list.
+type row struct {
+	drag gesture.Drag
+	// start is where the press was, and offset how far the row has been
+	// dragged from there.
+	start, offset float32
+}
+
+type App struct {
+	store     *Store
+	refresher *Refresher
+
+	feeds []FeedInfo
+	// feed is the id of the selected feed, or zero for all of them.
+	feed       int64
+	feedClicks map[int64]*widget.Clickable
+	feedList   layout.List
+	url        widget.Editor
+	add        widget.Clickable
+
+	items      []StoredItem
+	rows       map[int64]*row
+	itemList   layout.List
+	unreadOnly widget.Bool
+	markAll    widget.Clickable
+	refresh    widget.Clickable
+	remove     widget.Clickable
+
+	// current is the item shown, if any.
+	current    *StoredItem
+	detail     layout.List
+	open, play widget.Clickable
+	toggleRead widget.Clickable
+
+	// refreshing is set while a refresh runs, with its progress in done
+	// and total; added counts the new items and failed the failed feeds.
+	refreshing    bool
+	done, total   int
+	added, failed int
+	updated       time.Time
+	// err is the last error of the store or of opening links.
+	err error
+}
+
+func loop(w *app.Window, s *Store) error {
+	th := material.NewTheme(gofont.Collection())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := NewRefresher(s, http.DefaultClient, *workersFlag)
+	go r.Run(ctx, *intervalFlag)
+	a := &App{
+		store:      s,
+		refresher:  r,
+		feedClicks: make(map[int64]*widget.Clickable),
+		feedList:   layout.List{Axis: layout.Vertical},
+		rows:       make(map[int64]*row),
+		itemList:   layout.List{Axis: layout.Vertical},
+		detail:     layout.List{Axis: layout.Vertical},
+		refreshing: true,
+	}
+	a.url.SingleLine = true
+	a.url.Submit = true
+	a.reload()
+	var ops op.Ops
+	for {
+		select {
+		case res := <-r.Results():
+			a.result(res)
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+// result records the result of fetching a feed.
+func (a *App) result(res Result) {
+	if !a.refreshing || res.Done == 1 {
+		a.refreshing = true
+		a.added, a.failed = 0, 0
+	}
+	a.done, a.total = res.Done, res.Total
+	a.added += res.New
+	if res.Err != nil {
+		a.failed++
+	}
+	if res.Done == res.Total {
+		a.refreshing = false
+		a.updated = time.Now()
+	}
+	if res.New > 0 || res.Err != nil || !a.refreshing {
+		a.reload()
+	}
+}
+
+// reload reloads the feeds and the items of the selected feed.
+func (a *App) reload() {
+	a.reloadFeeds()
+	items, err := a.store.Items(Query{Feed: a.feed, UnreadOnly: a.unreadOnly.Value, Limit: itemLimit})
+	if err != nil {
+		a.err = err
+		return
+	}
+	a.items = items
+	rows := make(map[int64]*row)
+	for _, it := range items {
+		if r, ok := a.rows[it.ID]; ok {
+			rows[it.ID] = r
+		} else {
+			rows[it.ID] = new(row)
+		}
+	}
+	a.rows = rows
+}
+
+// reloadFeeds reloads the feeds, for their titles, errors and unread
+// counts.
+func (a *App) reloadFeeds() {
+	feeds, err := a.store.Feeds()
+	if err != nil {
+		a.err = err
+		return
+	}
+	a.feeds = feeds
+	if a.feedClicks[0] == nil {
+		a.feedClicks[0] = new(widget.Clickable)
+	}
+	for _, f := range feeds {
+		if a.feedClicks[f.ID] == nil {
+			a.feedClicks[f.ID] = new(widget.Clickable)
+		}
+	}
+}
+
+func (a *App) update(gtx C) {
+	for id, c := range a.feedClicks {
+		for c.Clicked() {
+			a.feed = id
+			a.itemList.Position = layout.Position{}
+			a.reload()
+		}
+	}
+	for _, e := range a.url.Events() {
+		if _, ok := e.(widget.SubmitEvent); ok {
+			a.addFeed()
+		}
+	}
+	for a.add.Clicked() {
+		a.addFeed()
+	}
+	for a.unreadOnly.Changed() {
+		a.reload()
+	}
+	for a.markAll.Clicked() {
+		a.setErr(a.store.MarkAllRead(a.feed))
+		a.reload()
+	}
+	for a.refresh.Clicked() {
+		a.refresher.Trigger()
+	}
+	for a.remove.Clicked() {
+		if a.feed != 0 {
+			a.setErr(a.store.RemoveFeed(a.feed))
+			if a.current != nil && a.current.FeedID == a.feed {
+				a.current = nil
+			}
+			a.feed = 0
+			a.reload()
+		}
+	}
+	if it := a.current; it != nil {
+		for a.open.Clicked() {
+			a.setErr(openBrowser(it.Link))
+		}
+		for a.play.Clicked() {
+			a.setErr(openBrowser(it.Enclosure))
+		}
+		for a.toggleRead.Clicked() {
+			a.setRead(it.ID, !it.Read)
+		}
+	}
+	a.swipes(gtx)
+}
+
+// swipes handles the drags of the rows of the list. Rows dragged past
+// the threshold are acted on when released; rows released where they
+// were pressed were clicked.
+func (a *App) swipes(gtx C) {
+	threshold := float32(gtx.Px(unit.Dp(swipeThreshold)))
+	slop := float32(gtx.Px(unit.Dp(4)))
+	for id, r := range a.rows {
+		for _, e := range r.drag.Events(gtx.Metric, gtx, gesture.Horizontal) {
+			switch e.Type {
+			case pointer.Press:
+				r.start, r.offset = e.Position.X, 0
+			case pointer.Drag:
+				r.offset = e.Position.X - r.start
+			case pointer.Release:
+				switch off := r.offset; {
+				case off >= threshold:
+					if it := a.item(id); it != nil {
+						a.setRead(id, !it.Read)
+					}
+				case off <= -threshold:
+					a.archive(id)
+				case math.Abs(float64(off)) < float64(slop):
+					a.show(id)
+				}
+				r.offset = 0
+			case pointer.Cancel:
+				r.offset = 0
+			}
+		}
+	}
+}
+
+func (a *App) addFeed() {
+	u := strings.TrimSpace(a.url.Text())
+	if u == "" {
+		return
+	}
+	if !strings.Contains(u, "://") {
+		u = "https://" + u
+	}
+	if _, err := a.store.AddFeed(u); err != nil {
+		a.err = err
+		return
+	}
+	a.url.SetText("")
+	a.err = nil
+	a.reloadFeeds()
+	a.refresher.Trigger()
+}
+
+// item returns the listed item with an id.
+func (a *App) item(id int64) *StoredItem {
+	for i := range a.items {
+		if a.items[i].ID == id {
+			return &a.items[i]
+		}
+	}
+	return nil
+}
+
+// show shows an item and marks it read.
+func (a *App) show(id int64) {
+	it := a.item(id)
+	if it == nil {
+		return
+	}
+	cur := *it
+	a.current = &cur
+	a.detail.Position = layout.Position{}
+	if !it.Read {
+		a.setRead(id, true)
+	}
+}
+
+// setRead marks an item read or unread. The item stays in the list
+// until the next reload, even if only unread items are listed, so that
+// it doesn't vanish while being read.
+func (a *App) setRead(id int64, read bool) {
+	if err := a.store.SetRead(id, read); err != nil {
+		a.err = err
+		return
+	}
+	if it := a.item(id); it != nil {
+		it.Read = read
+	}
+	if a.current != nil && a.current.ID == id {
+		a.current.Read = read
+	}
+	a.reloadFeeds()
+}
+
+func (a *App) archive(id int64) {
+	if err := a.store.Archive(id); err != nil {
+		a.err = err
+		return
+	}
+	for i := range a.items {
+		if a.items[i].ID == id {
+			a.items = append(a.items[:i], a.items[i+1:]...)
+			break
+		}
+	}
+	delete(a.rows, id)
+	if a.current != nil && a.current.ID == id {
+		a.current = nil
+	}
+	a.reloadFeeds()
+}
+
+func (a *App) setErr(err error) {
+	if err != nil {
+		a.err = err
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	a.update(gtx)
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					gtx.Constraints.Min.X = gtx.Px(unit.Dp(260))
+					gtx.Constraints.Max.X = gtx.Constraints.Min.X
+					return a.layoutFeeds(gtx, th)
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					return a.layoutItems(gtx, th)
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+						return a.layoutDetail(gtx, th)
+					})
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, a.layoutStatus(th))
+		}),
+	)
+}
+
+func (a *App) layoutFeeds(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, material.Editor(th, &a.url, "Feed URL").Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(material.Button(th, &a.add, "Add").Layout),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			unread := 0
+			for _, f := range a.feeds {
+				unread += f.Unread
+			}
+			return a.feedList.Layout(gtx, len(a.feeds)+1, func(gtx C, i int) D {
+				if i == 0 {
+					return a.layoutFeed(gtx, th, 0, "All feeds", unread, "")
+				}
+				f := a.feeds[i-1]
+				return a.layoutFeed(gtx, th, f.ID, f.Name(), f.Unread, f.Error)
+			})
+		}),
+	)
+}
+
+func (a *App) layoutFeed(gtx C, th *material.Theme, id int64, name string, unread int, ferr string) D {
+	return material.Clickable(gtx, a.feedClicks[id], func(gtx C) D {
+		return layout.Stack{}.Layout(gtx,
+			layout.Expanded(func(gtx C) D {
+				if id == a.feed {
+					paint.FillShape(gtx.Ops, selectedBg, clip.Rect{Max: gtx.Constraints.Min}.Op())
+				}
+				return D{Size: gtx.Constraints.Min}
+			}),
+			layout.Stacked(func(gtx C) D {
+				return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(func(gtx C) D {
+							return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+								layout.Flexed(1, func(gtx C) D {
+									l := material.Body1(th, name)
+									l.MaxLines = 1
+									return l.Layout(gtx)
+								}),
+								layout.Rigid(func(gtx C) D {
+									if unread == 0 {
+										return D{}
+									}
+									l := material.Body2(th, fmt.Sprint(unread))
+									l.Font.Weight = text.Bold
+									return l.Layout(gtx)
+								}),
+							)
+						}),
+						layout.Rigid(func(gtx C) D {
+							if ferr == "" {
+								return D{}
+							}
+							l := material.Caption(th, ferr)
+							l.Color = errorColor
+							l.MaxLines = 1
+							return l.Layout(gtx)
+						}),
+					)
+				})
+			}),
+		)
+	})
+}
+
+func (a *App) layoutItems(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				children := []layout.FlexChild{
+					layout.Flexed(1, material.CheckBox(th, &a.unreadOnly, "Unread only").Layout),
+					layout.Rigid(material.Button(th, &a.markAll, "Mark all read").Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(material.Button(th, &a.refresh, "Refresh").Layout),
+				}
+				if a.feed != 0 {
+					children = append(children,
+						layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+						layout.Rigid(material.Button(th, &a.remove, "Unsubscribe").Layout),
+					)
+				}
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx, children...)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			if len(a.items) == 0 {
+				msg := "No items."
+				if a.refreshing && len(a.feeds) > 0 {
+					msg = "Fetching feeds…"
+				}
+				return layout.UniformInset(unit.Dp(16)).Layout(gtx, material.Body1(th, msg).Layout)
+			}
+			return a.itemList.Layout(gtx, len(a.items), func(gtx C, i int) D {
+				it := &a.items[i]
+				return a.layoutRow(gtx, th, it, a.rows[it.ID])
+			})
+		}),
+	)
+}
+
+// layoutRow lays out a swipeable item. The row follows the drag, and
+// reveals the action of the swipe behind it.
+func (a *App) layoutRow(gtx C, th *material.Theme, it *StoredItem, r *row) D {
+	m := op.Record(gtx.Ops)
+	dims := a.layoutItem(gtx, th, it)
+	content := m.Stop()
+	size := dims.Size
+
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect{Max: size}.Add(gtx.Ops)
+	if r.offset != 0 {
+		a.layoutSwipeHint(gtx, th, it, size, r.offset)
+	}
+	st := op.Save(gtx.Ops)
+	op.Offset(f32.Pt(r.offset, 0)).Add(gtx.Ops)
+	bg := th.Palette.Bg
+	paint.FillShape(gtx.Ops, bg, clip.Rect{Max: size}.Op())
+	if a.current != nil && a.current.ID == it.ID {
+		paint.FillShape(gtx.Ops, selectedBg, clip.Rect{Max: size}.Op())
+	}
+	content.Add(gtx.Ops)
+	st.Load()
+	// The drag area stays put, so that positions are relative to the
+	// row rather than to its moving content.
+	pointer.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	r.drag.Add(gtx.Ops)
+	return dims
+}
+
+// layoutSwipeHint fills the space uncovered by a swipe with the color
+// and the name of its action, faded until the swipe is far enough.
+func (a *App) layoutSwipeHint(gtx C, th *material.Theme, it *StoredItem, size image.Point, offset float32) {
+	c, label, dir := readColor, "Mark read", layout.W
+	if it.Read {
+		label = "Mark unread"
+	}
+	if offset < 0 {
+		c, label, dir = archiveColor, "Archive", layout.E
+	}
+	if math.Abs(float64(offset)) < float64(gtx.Px(unit.Dp(swipeThreshold))) {
+		c = style.MulAlpha(c, 0x80)
+	}
+	paint.FillShape(gtx.Ops, c, clip.Rect{Max: size}.Op())
+	gtx.Constraints = layout.Exact(size)
+	layout.Inset{Left: unit.Dp(16), Right: unit.Dp(16)}.Layout(gtx, func(gtx C) D {
+		return dir.Layout(gtx, func(gtx C) D {
+			l := material.Body1(th, label)
+			l.Color = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+			return l.Layout(gtx)
+		})
+	})
+}
+
+// layoutItem lays out the title, feed, date and the start of the
+// summary of an item. Unread items have bold titles.
+func (a *App) layoutItem(gtx C, th *material.Theme, it *StoredItem) D {
+	return layout.Inset{Top: unit.Dp(8), Bottom: unit.Dp(8), Left: unit.Dp(12), Right: unit.Dp(12)}.Layout(gtx, func(gtx C) D {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				l := material.Body1(th, it.Title)
+				l.MaxLines = 2
+				if !it.Read {
+					l.Font.Weight = text.Bold
+				}
+				return l.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx C) D {
+				l := material.Caption(th, it.FeedTitle+" · "+formatDate(it.Published, gtx.Now))
+				l.Color = style.MulAlpha(l.Color, 0xa0)
+				l.MaxLines = 1
+				return l.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx C) D {
+				l := material.Body2(th, it.Summary)
+				l.Color = style.MulAlpha(l.Color, 0xa0)
+				l.MaxLines = 1
+				return l.Layout(gtx)
+			}),
+		)
+	})
+}
+
+func (a *App) layoutDetail(gtx C, th *material.Theme) D {
+	it := a.current
+	if it == nil {
+		return material.Body1(th, "Select an item to read it.").Layout(gtx)
+	}
+	readLabel := "Mark unread"
+	if !it.Read {
+		readLabel = "Mark read"
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(material.H5(th, it.Title).Layout),
+		layout.Rigid(func(gtx C) D {
+			l := material.Caption(th, it.FeedTitle+" · "+it.Published.Format("Monday, 2 January 2006 15:04"))
+			l.Color = style.MulAlpha(l.Color, 0xa0)
+			return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(8)}.Layout(gtx, l.Layout)
+		}),
+		layout.Rigid(func(gtx C) D {
+			var buttons []layout.FlexChild
+			add := func(w layout.Widget) {
+				if len(buttons) > 0 {
+					buttons = append(buttons, layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout))
+				}
+				buttons = append(buttons, layout.Rigid(w))
+			}
+			if it.Link != "" {
+				add(material.Button(th, &a.open, "Open").Layout)
+			}
+			if it.Enclosure != "" {
+				add(material.Button(th, &a.play, "Play episode").Layout)
+			}
+			add(material.Button(th, &a.toggleRead, readLabel).Layout)
+			return layout.Inset{Bottom: unit.Dp(12)}.Layout(gtx, func(gtx C) D {
+				return layout.Flex{}.Layout(gtx, buttons...)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			paras := strings.Split(it.Summary, "\n")
+			return a.detail.Layout(gtx, len(paras), func(gtx C, i int) D {
+				return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, material.Body1(th, paras[i]).Layout)
+			})
+		}),
+	)
+}
+
+func (a *App) layoutStatus(th *material.Theme) layout.Widget {
+	return func(gtx C) D {
+		if a.err != nil {
+			l := material.Caption(th, a.err.Error())
+			l.Color = errorColor
+			return l.Layout(gtx)
+		}
+		var msg string
+		switch {
+		case len(a.feeds) == 0:
+			msg = "No feeds. Add one by its URL."
+		case a.refreshing && a.total > 0:
+			msg = fmt.Sprintf("Refreshing… %d of %d feeds.", a.done, a.total)
+		case a.refreshing:
+			msg = "Refreshing…"
+		default:
+			msg = fmt.Sprintf("Updated at %s, %d new items.", a.updated.Format("15:04"), a.added)
+			if a.failed > 0 {
+				msg += fmt.Sprintf(" %d feeds failed.", a.failed)
+			}
+		}
+		return material.Caption(th, msg).Layout(gtx)
+	}
+}
+
+// formatDate formats the date of an item, briefly for recent ones.
+func formatDate(t, now time.Time) string {
+	switch {
+	case t.IsZero():
+		return ""
+	case t.YearDay() == now.YearDay() && t.Year() == now.Year():
+		return t.Format("15:04")
+	case t.Year() == now.Year():
+		return t.Format("2 Jan")
+	default:
+		return t.Format("2 Jan 2006")
+	}
+}
+
+// openBrowser opens a URL in the default browser or media player.
+func openBrowser(url string) error {
+	if url == "" {
+		return errors.New("no link")
+	}
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
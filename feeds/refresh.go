@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Result is the outcome of fetching a feed in a refresh. Done counts
+// the feeds fetched so far in the refresh, out of Total.
+type Result struct {
+	Feed        int64
+	URL         string
+	New         int
+	Err         error
+	Done, Total int
+}
+
+const (
+	// fetchTimeout bounds the time of fetching a feed.
+	fetchTimeout = 30 * time.Second
+	// maxFeedSize bounds the size of a feed document.
+	maxFeedSize = 16 << 20
+)
+
+// Refresher fetches the subscribed feeds into a store, a few at a time,
+// and reports the results on a channel.
+type Refresher struct {
+	store   *Store
+	client  *http.Client
+	workers int
+	results chan Result
+	trigger chan struct{}
+}
+
+func NewRefresher(s *Store, client *http.Client, workers int) *Refresher {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Refresher{
+		store:   s,
+		client:  client,
+		workers: workers,
+		results: make(chan Result, 16),
+		trigger: make(chan struct{}, 1),
+	}
+}
+
+// Results returns the channel of the results of fetches. It must be
+// drained, or refreshing stalls.
+func (r *Refresher) Results() <-chan Result {
+	return r.results
+}
+
+// Trigger asks Run for a refresh before the next scheduled one. A
+// refresh already running is followed by another.
+func (r *Refresher) Trigger() {
+	select {
+	case r.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Run refreshes the feeds right away and then at every interval or
+// trigger, until ctx is done.
+func (r *Refresher) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		r.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		case <-r.trigger:
+		}
+	}
+}
+
+// Refresh fetches every feed once.
+func (r *Refresher) Refresh(ctx context.Context) error {
+	feeds, err := r.store.Feeds()
+	if err != nil {
+		return err
+	}
+	sem := make(chan struct{}, r.workers)
+	var wg sync.WaitGroup
+	// mu orders the results, so that Done increases.
+	var mu sync.Mutex
+	done := 0
+loop:
+	for _, f := range feeds {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break loop
+		}
+		wg.Add(1)
+		go func(f FeedInfo) {
+			defer wg.Done()
+			res := r.fetch(ctx, f)
+			<-sem
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			res.Done, res.Total = done, len(feeds)
+			select {
+			case r.results <- res:
+			case <-ctx.Done():
+			}
+		}(f)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// fetch fetches a feed and saves its new items. The request is
+// conditional on the validators of the last response, so that unchanged
+// feeds cost little.
+func (r *Refresher) fetch(ctx context.Context, f FeedInfo) Result {
+	res := Result{Feed: f.ID, URL: f.URL}
+	feed, etag, modified, err := r.get(ctx, f)
+	now := time.Now()
+	if err != nil {
+		res.Err = err
+		if ctx.Err() == nil {
+			r.store.SaveError(f.ID, err, now)
+		}
+		return res
+	}
+	res.New, res.Err = r.store.SaveFetch(f.ID, feed, etag, modified, now)
+	return res
+}
+
+// get fetches and parses a feed. It returns a nil feed if the feed is
+// unchanged.
+func (r *Refresher) get(ctx context.Context, f FeedInfo) (*Feed, string, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", f.URL, nil)
+	if err != nil {
+		return nil, "", "", err
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+	if f.ETag != "" {
+		req.Header.Set("If-None-Match", f.ETag)
+	}
+	if f.Modified != "" {
+		req.Header.Set("If-Modified-Since", f.Modified)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, "", "", err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, f.ETag, f.Modified, nil
+	default:
+		return nil, "", "", fmt.Errorf("%s: %s", f.URL, resp.Status)
+	}
+	feed, err := ParseFeed(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return nil, "", "", err
+	}
+	return feed, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"), nil
+}
@@ -0,0 +1,192 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T) *Store {
+	s, err := OpenStore(filepath.Join(t.TempDir(), "feeds.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestStore(t *testing.T) {
+	s := openTestStore(t)
+	id, err := s.AddFeed("https://example.com/feed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id2, err := s.AddFeed(" https://example.com/feed "); err != nil || id2 != id {
+		t.Errorf("adding again: %d, %v; want %d", id2, err, id)
+	}
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	f := &Feed{Title: "Example", Items: []Item{
+		{GUID: "a", Title: "A", Published: now.Add(-time.Hour)},
+		{GUID: "b", Title: "B", Published: now.Add(-2 * time.Hour)},
+	}}
+	if n, err := s.SaveFetch(id, f, `"v1"`, "", now); err != nil || n != 2 {
+		t.Fatalf("first save: %d, %v", n, err)
+	}
+	items, err := s.Items(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].GUID != "a" || items[0].FeedTitle != "Example" {
+		t.Fatalf("items %+v", items)
+	}
+	if err := s.SetRead(items[0].ID, true); err != nil {
+		t.Fatal(err)
+	}
+	// Saving again adds only the new item, and keeps the read state.
+	f.Items = append(f.Items, Item{GUID: "c", Title: "C", Published: now})
+	f.Items[0].Title = "A changed"
+	if n, err := s.SaveFetch(id, f, `"v2"`, "", now); err != nil || n != 1 {
+		t.Fatalf("second save: %d, %v", n, err)
+	}
+	unread, err := s.Items(Query{Feed: id, UnreadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unread) != 2 || unread[0].GUID != "c" || unread[1].GUID != "b" {
+		t.Errorf("unread items %+v", unread)
+	}
+	if err := s.Archive(unread[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	feeds, err := s.Feeds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feeds) != 1 || feeds[0].Unread != 1 || feeds[0].ETag != `"v2"` || !feeds[0].Fetched.Equal(now) {
+		t.Errorf("feeds %+v", feeds)
+	}
+	if err := s.MarkAllRead(0); err != nil {
+		t.Fatal(err)
+	}
+	if items, _ := s.Items(Query{UnreadOnly: true}); len(items) != 0 {
+		t.Errorf("%d unread items after marking all read", len(items))
+	}
+	// Archived items are hidden but not added again.
+	if n, _ := s.SaveFetch(id, f, "", "", now); n != 0 {
+		t.Errorf("archived item added again")
+	}
+	if items, _ := s.Items(Query{}); len(items) != 2 {
+		t.Errorf("%d items, want 2", len(items))
+	}
+	if err := s.RemoveFeed(id); err != nil {
+		t.Fatal(err)
+	}
+	if items, _ := s.Items(Query{}); len(items) != 0 {
+		t.Errorf("%d items after removing the feed", len(items))
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	var requests, notModified int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch r.URL.Path {
+		case "/feed":
+			if r.Header.Get("If-None-Match") == `"1"` {
+				atomic.AddInt32(&notModified, 1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"1"`)
+			fmt.Fprint(w, rssDoc)
+		case "/atom":
+			fmt.Fprint(w, atomDoc)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	s := openTestStore(t)
+	for _, p := range []string{"/feed", "/atom", "/missing"} {
+		if _, err := s.AddFeed(srv.URL + p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := NewRefresher(s, srv.Client(), 2)
+	refresh := func() (added, failed int) {
+		errc := make(chan error, 1)
+		go func() { errc <- r.Refresh(context.Background()) }()
+		for i := 1; i <= 3; i++ {
+			res := <-r.Results()
+			if res.Done != i || res.Total != 3 {
+				t.Errorf("result %d of %d, want %d of 3", res.Done, res.Total, i)
+			}
+			added += res.New
+			if res.Err != nil {
+				failed++
+			}
+		}
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+		return added, failed
+	}
+	if added, failed := refresh(); added != 3 || failed != 1 {
+		t.Errorf("first refresh: %d added, %d failed; want 3, 1", added, failed)
+	}
+	if added, failed := refresh(); added != 0 || failed != 1 {
+		t.Errorf("second refresh: %d added, %d failed; want 0, 1", added, failed)
+	}
+	if requests != 6 || notModified != 1 {
+		t.Errorf("%d requests, %d not modified; want 6, 1", requests, notModified)
+	}
+	feeds, err := s.Feeds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range feeds {
+		failed := f.URL == srv.URL+"/missing"
+		if (f.Error != "") != failed {
+			t.Errorf("feed %s: error %q", f.URL, f.Error)
+		}
+	}
+}
+
+func TestRefreshCancel(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(block)
+
+	s := openTestStore(t)
+	if _, err := s.AddFeed(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRefresher(s, srv.Client(), 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- r.Refresh(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-errc:
+		if err != context.Canceled {
+			t.Errorf("refresh returned %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("refresh didn't stop when cancelled")
+	}
+}
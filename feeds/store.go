@@ -0,0 +1,269 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Store keeps the subscribed feeds and their items in an SQLite
+// database. It is safe for concurrent use; the database is in WAL mode
+// so that the queries of the UI don't wait for the refresher's writes.
+type Store struct {
+	db *sql.DB
+}
+
+// FeedInfo is a subscribed feed. ETag and Modified are the validators of
+// the last response, for conditional requests, and Error is the reason
+// the last fetch failed, if it did.
+type FeedInfo struct {
+	ID       int64
+	URL      string
+	Title    string
+	ETag     string
+	Modified string
+	Fetched  time.Time
+	Error    string
+	Unread   int
+}
+
+// Name returns the title of the feed, or its URL until it has been
+// fetched.
+func (f FeedInfo) Name() string {
+	if f.Title != "" {
+		return f.Title
+	}
+	return f.URL
+}
+
+// StoredItem is an item of a feed in the store.
+type StoredItem struct {
+	Item
+	ID        int64
+	FeedID    int64
+	FeedTitle string
+	Read      bool
+}
+
+// Query selects items. A zero Feed selects the items of all feeds.
+type Query struct {
+	Feed       int64
+	UnreadOnly bool
+	Limit      int
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS feeds (
+	id INTEGER PRIMARY KEY,
+	url TEXT NOT NULL UNIQUE,
+	title TEXT NOT NULL DEFAULT '',
+	etag TEXT NOT NULL DEFAULT '',
+	modified TEXT NOT NULL DEFAULT '',
+	fetched INTEGER NOT NULL DEFAULT 0,
+	error TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS items (
+	id INTEGER PRIMARY KEY,
+	feed_id INTEGER NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+	guid TEXT NOT NULL,
+	title TEXT NOT NULL,
+	link TEXT NOT NULL,
+	summary TEXT NOT NULL,
+	enclosure TEXT NOT NULL,
+	published INTEGER NOT NULL,
+	read INTEGER NOT NULL DEFAULT 0,
+	archived INTEGER NOT NULL DEFAULT 0,
+	UNIQUE (feed_id, guid)
+);
+CREATE INDEX IF NOT EXISTS items_published ON items (published);
+`
+
+// OpenStore opens or creates the database at path.
+func OpenStore(path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000&_journal_mode=WAL&_foreign_keys=on")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// AddFeed subscribes to the feed at url, returning its id. Adding a feed
+// twice is not an error.
+func (s *Store) AddFeed(url string) (int64, error) {
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return 0, errors.New("empty feed URL")
+	}
+	if _, err := s.db.Exec(`INSERT OR IGNORE INTO feeds (url) VALUES (?)`, url); err != nil {
+		return 0, err
+	}
+	var id int64
+	err := s.db.QueryRow(`SELECT id FROM feeds WHERE url = ?`, url).Scan(&id)
+	return id, err
+}
+
+// RemoveFeed unsubscribes from a feed and deletes its items.
+func (s *Store) RemoveFeed(id int64) error {
+	_, err := s.db.Exec(`DELETE FROM feeds WHERE id = ?`, id)
+	return err
+}
+
+// Feeds returns the subscribed feeds by title, with their unread counts.
+func (s *Store) Feeds() ([]FeedInfo, error) {
+	rows, err := s.db.Query(`
+		SELECT f.id, f.url, f.title, f.etag, f.modified, f.fetched, f.error,
+			(SELECT COUNT(*) FROM items i WHERE i.feed_id = f.id AND NOT i.read AND NOT i.archived)
+		FROM feeds f
+		ORDER BY CASE f.title WHEN '' THEN f.url ELSE f.title END COLLATE NOCASE`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var feeds []FeedInfo
+	for rows.Next() {
+		var f FeedInfo
+		var fetched int64
+		if err := rows.Scan(&f.ID, &f.URL, &f.Title, &f.ETag, &f.Modified, &fetched, &f.Error, &f.Unread); err != nil {
+			return nil, err
+		}
+		if fetched != 0 {
+			f.Fetched = time.Unix(fetched, 0)
+		}
+		feeds = append(feeds, f)
+	}
+	return feeds, rows.Err()
+}
+
+// SaveFetch records a successful fetch of a feed at time now, with the
+// validators of the response. It adds the items not already stored,
+// leaving the stored ones and their read state alone, and returns the
+// number of new items. A nil feed records a fetch of an unchanged feed.
+func (s *Store) SaveFetch(id int64, f *Feed, etag, modified string, now time.Time) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`UPDATE feeds SET etag = ?, modified = ?, fetched = ?, error = '' WHERE id = ?`,
+		etag, modified, now.Unix(), id); err != nil {
+		return 0, err
+	}
+	added := 0
+	if f != nil {
+		if f.Title != "" {
+			if _, err := tx.Exec(`UPDATE feeds SET title = ? WHERE id = ?`, f.Title, id); err != nil {
+				return 0, err
+			}
+		}
+		stmt, err := tx.Prepare(`INSERT OR IGNORE INTO items
+			(feed_id, guid, title, link, summary, enclosure, published)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`)
+		if err != nil {
+			return 0, err
+		}
+		defer stmt.Close()
+		for _, it := range f.Items {
+			pub := it.Published
+			if pub.IsZero() {
+				// Undated items are as new as the fetch that found them.
+				pub = now
+			}
+			res, err := stmt.Exec(id, it.GUID, it.Title, it.Link, it.Summary, it.Enclosure, pub.Unix())
+			if err != nil {
+				return 0, err
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				return 0, err
+			}
+			added += int(n)
+		}
+	}
+	return added, tx.Commit()
+}
+
+// SaveError records a failed fetch of a feed.
+func (s *Store) SaveError(id int64, ferr error, now time.Time) error {
+	_, err := s.db.Exec(`UPDATE feeds SET fetched = ?, error = ? WHERE id = ?`, now.Unix(), ferr.Error(), id)
+	return err
+}
+
+// Items returns the items selected by q that aren't archived, newest
+// first.
+func (s *Store) Items(q Query) ([]StoredItem, error) {
+	where := []string{"NOT i.archived"}
+	var args []interface{}
+	if q.Feed != 0 {
+		where = append(where, "i.feed_id = ?")
+		args = append(args, q.Feed)
+	}
+	if q.UnreadOnly {
+		where = append(where, "NOT i.read")
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	args = append(args, limit)
+	rows, err := s.db.Query(`
+		SELECT i.id, i.feed_id, CASE f.title WHEN '' THEN f.url ELSE f.title END,
+			i.guid, i.title, i.link, i.summary, i.enclosure, i.published, i.read
+		FROM items i JOIN feeds f ON f.id = i.feed_id
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY i.published DESC, i.id DESC
+		LIMIT ?`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StoredItem
+	for rows.Next() {
+		var it StoredItem
+		var pub int64
+		if err := rows.Scan(&it.ID, &it.FeedID, &it.FeedTitle, &it.GUID, &it.Title, &it.Link,
+			&it.Summary, &it.Enclosure, &pub, &it.Read); err != nil {
+			return nil, err
+		}
+		it.Published = time.Unix(pub, 0)
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// SetRead marks an item read or unread.
+func (s *Store) SetRead(id int64, read bool) error {
+	_, err := s.db.Exec(`UPDATE items SET read = ? WHERE id = ?`, read, id)
+	return err
+}
+
+// Archive hides an item. Archived items are kept so that later fetches
+// don't add them again.
+func (s *Store) Archive(id int64) error {
+	_, err := s.db.Exec(`UPDATE items SET archived = 1, read = 1 WHERE id = ?`, id)
+	return err
+}
+
+// MarkAllRead marks the items of a feed read, or the items of all feeds
+// for a zero feed.
+func (s *Store) MarkAllRead(feed int64) error {
+	var err error
+	if feed == 0 {
+		_, err = s.db.Exec(`UPDATE items SET read = 1 WHERE NOT read`)
+	} else {
+		_, err = s.db.Exec(`UPDATE items SET read = 1 WHERE NOT read AND feed_id = ?`, feed)
+	}
+	return err
+}
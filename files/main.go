@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates the navigation widgets of internal/nav in a
+// small file manager: a breadcrumb trail of the current directory that
+// collapses when the window is narrow, and a table of its entries split
+// into pages.
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/bytesize"
+	"gioui.org/example/internal/nav"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Files"),
+			app.Size(unit.Dp(800), unit.Dp(700)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+const pageSize = 20
+
+type entry struct {
+	info  fs.FileInfo
+	click widget.Clickable
+}
+
+// browser lists the entries of a directory.
+type browser struct {
+	dir     string
+	entries []*entry
+	err     error
+	crumbs  nav.Breadcrumb
+	pages   nav.Pagination
+}
+
+func (b *browser) open(dir string) {
+	b.dir = dir
+	b.entries = nil
+	b.pages.Page = 0
+	des, err := os.ReadDir(dir)
+	b.err = err
+	for _, de := range des {
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		b.entries = append(b.entries, &entry{info: info})
+	}
+	// Directories first, then by name.
+	sort.Slice(b.entries, func(i, j int) bool {
+		ei, ej := b.entries[i].info, b.entries[j].info
+		if ei.IsDir() != ej.IsDir() {
+			return ei.IsDir()
+		}
+		return strings.ToLower(ei.Name()) < strings.ToLower(ej.Name())
+	})
+	b.pages.Pages = (len(b.entries) + pageSize - 1) / pageSize
+	b.crumbs.Crumbs = pathElements(dir)
+}
+
+// pathElements splits a path into its elements, the first being the root
+// or volume.
+func pathElements(dir string) []string {
+	vol := filepath.VolumeName(dir)
+	rest := strings.Trim(dir[len(vol):], string(filepath.Separator))
+	elems := []string{vol + string(filepath.Separator)}
+	if rest != "" {
+		elems = append(elems, strings.Split(rest, string(filepath.Separator))...)
+	}
+	return elems
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	b := new(browser)
+	start, err := os.UserHomeDir()
+	if err != nil {
+		start = string(filepath.Separator)
+	}
+	if len(os.Args) > 1 {
+		start = os.Args[1]
+	}
+	b.open(start)
+	var (
+		ops  op.Ops
+		list = layout.List{Axis: layout.Vertical}
+	)
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			if i, ok := b.crumbs.Clicked(); ok {
+				b.open(filepath.Join(b.crumbs.Crumbs[:i+1]...))
+			}
+			for _, e := range b.entries {
+				for e.click.Clicked() {
+					if e.info.IsDir() {
+						b.open(filepath.Join(b.dir, e.info.Name()))
+						break
+					}
+				}
+			}
+			if b.pages.Changed() {
+				list.Position = layout.Position{}
+			}
+			lo := b.pages.Page * pageSize
+			hi := lo + pageSize
+			if hi > len(b.entries) {
+				hi = len(b.entries)
+			}
+			page := b.entries[lo:hi]
+
+			layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						return b.crumbs.Layout(gtx, th)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+					layout.Rigid(func(gtx C) D {
+						return row(gtx, th, "Name", "Size", "Modified", true)
+					}),
+					layout.Flexed(1, func(gtx C) D {
+						if b.err != nil {
+							return material.Body1(th, b.err.Error()).Layout(gtx)
+						}
+						if len(page) == 0 {
+							return material.Body1(th, "Empty directory").Layout(gtx)
+						}
+						return list.Layout(gtx, len(page), func(gtx C, i int) D {
+							info := page[i].info
+							name, size := info.Name(), bytesize.Format(info.Size())
+							if info.IsDir() {
+								name, size = name+string(filepath.Separator), "—"
+							}
+							return material.Clickable(gtx, &page[i].click, func(gtx C) D {
+								return row(gtx, th, name, size, info.ModTime().Format("2006-01-02 15:04"), false)
+							})
+						})
+					}),
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+							return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+								layout.Flexed(1, func(gtx C) D {
+									if len(b.entries) == 0 {
+										return D{}
+									}
+									return material.Body2(th, fmt.Sprintf("%d–%d of %d", lo+1, hi, len(b.entries))).Layout(gtx)
+								}),
+								layout.Rigid(func(gtx C) D {
+									return b.pages.Layout(gtx, th)
+								}),
+							)
+						})
+					}),
+				)
+			})
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+// row lays out a row of the table.
+func row(gtx C, th *material.Theme, name, size, modified string, header bool) D {
+	cell := func(s string) material.LabelStyle {
+		if header {
+			return material.Body1(th, s)
+		}
+		return material.Body2(th, s)
+	}
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	return layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(6), Left: unit.Dp(4), Right: unit.Dp(4)}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{}.Layout(gtx,
+			layout.Flexed(1, func(gtx C) D {
+				l := cell(name)
+				l.MaxLines = 1
+				return l.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx C) D {
+				gtx.Constraints.Min.X = gtx.Px(unit.Dp(96))
+				return cell(size).Layout(gtx)
+			}),
+			layout.Rigid(func(gtx C) D {
+				gtx.Constraints.Min.X = gtx.Px(unit.Dp(140))
+				return cell(modified).Layout(gtx)
+			}),
+		)
+	})
+}
@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates a filter panel for a product list, built from
+// a dual thumb range slider for the price, a star rating for the minimum
+// rating and a stepped slider with tick labels for the size.
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Filters"),
+			app.Size(unit.Dp(800), unit.Dp(600)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var sizes = []string{"XS", "S", "M", "L", "XL", "XXL"}
+
+type product struct {
+	name   string
+	price  float32
+	rating int
+	// size is an index into sizes.
+	size int
+}
+
+func products() []product {
+	r := rand.New(rand.NewSource(1))
+	adjectives := []string{"Classic", "Slim", "Relaxed", "Organic", "Vintage", "Sport"}
+	items := []string{"T-shirt", "Hoodie", "Jacket", "Shirt", "Sweater"}
+	var ps []product
+	for i := 0; i < 200; i++ {
+		ps = append(ps, product{
+			name:   adjectives[r.Intn(len(adjectives))] + " " + items[r.Intn(len(items))],
+			price:  float32(5 + r.Intn(196)),
+			rating: 1 + r.Intn(5),
+			size:   r.Intn(len(sizes)),
+		})
+	}
+	return ps
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	var (
+		ops    op.Ops
+		all    = products()
+		price  = &RangeSlider{Min: 0, Max: 200, Low: 20, High: 120}
+		rating = &Rating{Max: 5, Value: 3}
+		size   = &SteppedSlider{Labels: append([]string{"Any"}, sizes...)}
+		reset  widget.Clickable
+		list   = layout.List{Axis: layout.Vertical}
+	)
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			for reset.Clicked() {
+				price.Low, price.High = price.Min, price.Max
+				rating.Value = 0
+				size.Index = 0
+			}
+			var shown []product
+			for _, p := range all {
+				if p.price < price.Low || p.price > price.High || p.rating < rating.Value {
+					continue
+				}
+				// Index 0 of the slider is any size.
+				if size.Index > 0 && p.size != size.Index-1 {
+					continue
+				}
+				shown = append(shown, p)
+			}
+
+			section := func(title string, w layout.Widget) layout.FlexChild {
+				return layout.Rigid(func(gtx C) D {
+					return layout.Inset{Bottom: unit.Dp(24)}.Layout(gtx, func(gtx C) D {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(material.Body1(th, title).Layout),
+							layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+							layout.Rigid(w),
+						)
+					})
+				})
+			}
+			layout.Flex{}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					gtx.Constraints.Min.X = gtx.Px(unit.Dp(280))
+					gtx.Constraints.Max.X = gtx.Constraints.Min.X
+					return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							section(fmt.Sprintf("Price: $%.0f – $%.0f", price.Low, price.High), func(gtx C) D {
+								return price.Layout(gtx, th)
+							}),
+							section("Minimum rating", rating.Layout),
+							section("Size", func(gtx C) D {
+								return size.Layout(gtx, th)
+							}),
+							layout.Rigid(material.Button(th, &reset, "Reset filters").Layout),
+						)
+					})
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(material.H6(th, fmt.Sprintf("%d of %d products", len(shown), len(all))).Layout),
+							layout.Flexed(1, func(gtx C) D {
+								return list.Layout(gtx, len(shown), func(gtx C, i int) D {
+									p := shown[i]
+									return layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(6)}.Layout(gtx, func(gtx C) D {
+										return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+											layout.Flexed(1, material.Body1(th, p.name).Layout),
+											layout.Rigid(func(gtx C) D {
+												gtx.Constraints.Min.X = gtx.Px(unit.Dp(48))
+												return material.Body2(th, sizes[p.size]).Layout(gtx)
+											}),
+											layout.Rigid(func(gtx C) D {
+												gtx.Constraints.Min.X = gtx.Px(unit.Dp(72))
+												return material.Body2(th, strings.Repeat("★", p.rating)).Layout(gtx)
+											}),
+											layout.Rigid(material.Body1(th, fmt.Sprintf("$%.0f", p.price)).Layout),
+										)
+									})
+								})
+							}),
+						)
+					})
+				}),
+			)
+			// The filters are applied before the widgets handle their
+			// events; redraw to apply any change.
+			if price.Changed() || rating.Changed() || size.Changed() {
+				op.InvalidateOp{}.Add(gtx.Ops)
+			}
+			e.Frame(gtx.Ops)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+// star returns the outline of a five pointed star filling a square of
+// size sz.
+func star(ops *op.Ops, sz float32) clip.Op {
+	c := f32.Pt(sz/2, sz/2)
+	outer, inner := sz/2, sz/5
+	var p clip.Path
+	p.Begin(ops)
+	for i := 0; i < 10; i++ {
+		r := outer
+		if i%2 == 1 {
+			r = inner
+		}
+		a := float64(i)*math.Pi/5 - math.Pi/2
+		pt := c.Add(f32.Pt(r*float32(math.Cos(a)), r*float32(math.Sin(a))))
+		if i == 0 {
+			p.MoveTo(pt)
+		} else {
+			p.LineTo(pt)
+		}
+	}
+	p.Close()
+	return clip.Outline{Path: p.End()}.Op()
+}
+
+// Rating is a row of stars. Hovering previews a rating and clicking sets
+// it; clicking the current rating clears it.
+type Rating struct {
+	Value int
+	Max   int
+
+	hover   int
+	changed bool
+}
+
+// Changed reports whether the rating changed since the last call.
+func (r *Rating) Changed() bool {
+	c := r.changed
+	r.changed = false
+	return c
+}
+
+func (r *Rating) Layout(gtx layout.Context) layout.Dimensions {
+	size := gtx.Px(unit.Dp(28))
+	for _, e := range gtx.Events(r) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		n := int(e.Position.X)/size + 1
+		switch e.Type {
+		case pointer.Enter, pointer.Move:
+			r.hover = n
+		case pointer.Leave:
+			r.hover = 0
+		case pointer.Press:
+			if n == r.Value {
+				n = 0
+			}
+			r.Value = n
+			r.changed = true
+		}
+	}
+	shown := r.Value
+	if r.hover > 0 {
+		shown = r.hover
+	}
+	on := color.NRGBA{R: 0xff, G: 0xb3, B: 0x00, A: 0xff}
+	if r.hover > 0 {
+		on.A = 0xa0
+	}
+	off := color.NRGBA{A: 0x30}
+	for i := 0; i < r.Max; i++ {
+		stack := op.Save(gtx.Ops)
+		op.Offset(f32.Pt(float32(i*size), 0)).Add(gtx.Ops)
+		c := off
+		if i < shown {
+			c = on
+		}
+		paint.FillShape(gtx.Ops, c, star(gtx.Ops, float32(size)))
+		stack.Load()
+	}
+	sz := image.Pt(size*r.Max, size)
+	defer op.Save(gtx.Ops).Load()
+	pointer.Rect(image.Rectangle{Max: sz}).Add(gtx.Ops)
+	pointer.InputOp{Tag: r, Types: pointer.Press | pointer.Enter | pointer.Leave | pointer.Move}.Add(gtx.Ops)
+	pointer.CursorNameOp{Name: pointer.CursorPointer}.Add(gtx.Ops)
+	return layout.Dimensions{Size: sz}
+}
+
+// track draws a slider track of width w centered at height y, with the
+// part between x0 and x1 highlighted.
+func track(gtx layout.Context, th *material.Theme, w, y, x0, x1 int) {
+	h := gtx.Px(unit.Dp(4))
+	bg := th.Palette.ContrastBg
+	bg.A = 0x40
+	paint.FillShape(gtx.Ops, bg, clip.Rect(image.Rect(0, y-h/2, w, y+h/2)).Op())
+	paint.FillShape(gtx.Ops, th.Palette.ContrastBg, clip.Rect(image.Rect(x0, y-h/2, x1, y+h/2)).Op())
+}
+
+// thumb draws a slider thumb centered at c.
+func thumb(gtx layout.Context, th *material.Theme, c f32.Point, active bool) {
+	r := float32(gtx.Px(unit.Dp(8)))
+	if active {
+		r = float32(gtx.Px(unit.Dp(10)))
+	}
+	rect := f32.Rectangle{Min: c.Sub(f32.Pt(r, r)), Max: c.Add(f32.Pt(r, r))}
+	paint.FillShape(gtx.Ops, th.Palette.ContrastBg, clip.UniformRRect(rect, r).Op(gtx.Ops))
+}
+
+// RangeSlider selects a range with two thumbs. Pressing the track moves
+// the closest thumb.
+type RangeSlider struct {
+	Min, Max  float32
+	Low, High float32
+
+	dragging bool
+	// active is the dragged thumb: 0 for Low, 1 for High.
+	active  int
+	changed bool
+}
+
+// Changed reports whether the range changed since the last call.
+func (s *RangeSlider) Changed() bool {
+	c := s.changed
+	s.changed = false
+	return c
+}
+
+func (s *RangeSlider) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	width := gtx.Constraints.Max.X
+	height := gtx.Px(unit.Dp(32))
+	pad := gtx.Px(unit.Dp(10))
+	span := float32(width - 2*pad)
+	toX := func(v float32) float32 {
+		return float32(pad) + (v-s.Min)/(s.Max-s.Min)*span
+	}
+	toValue := func(x float32) float32 {
+		v := s.Min + (x-float32(pad))/span*(s.Max-s.Min)
+		return float32(math.Max(float64(s.Min), math.Min(float64(s.Max), float64(v))))
+	}
+	for _, e := range gtx.Events(s) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case pointer.Press:
+			x := e.Position.X
+			s.dragging = true
+			s.active = 0
+			if math.Abs(float64(x-toX(s.High))) < math.Abs(float64(x-toX(s.Low))) {
+				s.active = 1
+			}
+			fallthrough
+		case pointer.Drag:
+			if !s.dragging {
+				break
+			}
+			v := toValue(e.Position.X)
+			if s.active == 0 {
+				if v > s.High {
+					v = s.High
+				}
+				s.Low = v
+			} else {
+				if v < s.Low {
+					v = s.Low
+				}
+				s.High = v
+			}
+			s.changed = true
+		case pointer.Release, pointer.Cancel:
+			s.dragging = false
+		}
+	}
+	y := height / 2
+	lo, hi := toX(s.Low), toX(s.High)
+	track(gtx, th, width, y, int(lo), int(hi))
+	thumb(gtx, th, f32.Pt(lo, float32(y)), s.dragging && s.active == 0)
+	thumb(gtx, th, f32.Pt(hi, float32(y)), s.dragging && s.active == 1)
+
+	sz := image.Pt(width, height)
+	defer op.Save(gtx.Ops).Load()
+	pointer.Rect(image.Rectangle{Max: sz}).Add(gtx.Ops)
+	pointer.InputOp{
+		Tag:   s,
+		Grab:  s.dragging,
+		Types: pointer.Press | pointer.Drag | pointer.Release,
+	}.Add(gtx.Ops)
+	return layout.Dimensions{Size: sz}
+}
+
+// SteppedSlider selects one of a few labelled steps. The thumb snaps to
+// the closest step.
+type SteppedSlider struct {
+	Labels []string
+	Index  int
+
+	dragging bool
+	changed  bool
+}
+
+// Changed reports whether the step changed since the last call.
+func (s *SteppedSlider) Changed() bool {
+	c := s.changed
+	s.changed = false
+	return c
+}
+
+func (s *SteppedSlider) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	width := gtx.Constraints.Max.X
+	height := gtx.Px(unit.Dp(32))
+	pad := gtx.Px(unit.Dp(16))
+	steps := len(s.Labels) - 1
+	toX := func(i int) int {
+		return pad + i*(width-2*pad)/steps
+	}
+	for _, e := range gtx.Events(s) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case pointer.Press, pointer.Drag:
+			s.dragging = true
+			f := (e.Position.X - float32(pad)) / float32(width-2*pad)
+			i := int(math.Round(float64(f) * float64(steps)))
+			if i < 0 {
+				i = 0
+			}
+			if i > steps {
+				i = steps
+			}
+			if i != s.Index {
+				s.Index = i
+				s.changed = true
+			}
+		case pointer.Release, pointer.Cancel:
+			s.dragging = false
+		}
+	}
+	y := height / 2
+	track(gtx, th, width, y, toX(0), toX(s.Index))
+	tick := gtx.Px(unit.Dp(3))
+	for i := 0; i <= steps; i++ {
+		x := toX(i) - tick/2
+		paint.FillShape(gtx.Ops, th.Palette.Fg, clip.Rect(image.Rect(x, y+tick*2, x+tick, y+tick*4)).Op())
+	}
+	thumb(gtx, th, f32.Pt(float32(toX(s.Index)), float32(y)), s.dragging)
+
+	// The labels, centered below their ticks.
+	labelTop := y + tick*4
+	var labelHeight int
+	for i, l := range s.Labels {
+		macro := op.Record(gtx.Ops)
+		lgtx := gtx
+		lgtx.Constraints.Min = image.Point{}
+		dims := material.Caption(th, l).Layout(lgtx)
+		call := macro.Stop()
+		stack := op.Save(gtx.Ops)
+		op.Offset(f32.Pt(float32(toX(i)-dims.Size.X/2), float32(labelTop))).Add(gtx.Ops)
+		call.Add(gtx.Ops)
+		stack.Load()
+		if dims.Size.Y > labelHeight {
+			labelHeight = dims.Size.Y
+		}
+	}
+
+	sz := image.Pt(width, labelTop+labelHeight)
+	defer op.Save(gtx.Ops).Load()
+	pointer.Rect(image.Rect(0, 0, width, height)).Add(gtx.Ops)
+	pointer.InputOp{
+		Tag:   s,
+		Grab:  s.dragging,
+		Types: pointer.Press | pointer.Drag | pointer.Release,
+	}.Add(gtx.Ops)
+	return layout.Dimensions{Size: sz}
+}
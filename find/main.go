@@ -0,0 +1,214 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates searching a read-only document. Ctrl+F (Cmd+F
+// on macOS) opens a find bar; matches are highlighted as the query is
+// typed, and Enter or the arrow buttons move between them, scrolling each
+// into view. Outside the find bar, Ctrl+G and Ctrl+Shift+G do the same
+// and Escape closes it. Pass a file name to view it instead of the sample
+// text.
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/io/key"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	paras := sampleText()
+	if len(os.Args) > 1 {
+		data, err := ioutil.ReadFile(os.Args[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		paras = strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("Find"),
+			app.Size(unit.Dp(700), unit.Dp(800)),
+		)
+		if err := loop(w, paras); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// findBar is the state of the search.
+type findBar struct {
+	open      bool
+	query     widget.Editor
+	matchCase widget.Bool
+	prev      widget.Clickable
+	next      widget.Clickable
+	close     widget.Clickable
+
+	matches []match
+	current int
+}
+
+func loop(w *app.Window, paras []string) error {
+	th := material.NewTheme(gofont.Collection())
+	doc := &Document{Paragraphs: paras}
+	bar := &findBar{
+		query: widget.Editor{SingleLine: true, Submit: true},
+	}
+	search := func() {
+		bar.matches = findAll(paras, bar.query.Text(), bar.matchCase.Value)
+		bar.current = 0
+		if len(bar.matches) > 0 {
+			doc.ScrollTo(bar.matches[0].para)
+		}
+	}
+	step := func(delta int) {
+		n := len(bar.matches)
+		if n == 0 {
+			return
+		}
+		bar.current = (bar.current + delta + n) % n
+		doc.ScrollTo(bar.matches[bar.current].para)
+	}
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case key.Event:
+			if e.State != key.Press {
+				break
+			}
+			switch {
+			case e.Name == "F" && e.Modifiers.Contain(key.ModShortcut):
+				bar.open = true
+				bar.query.Focus()
+			case e.Name == "G" && e.Modifiers.Contain(key.ModShortcut):
+				if e.Modifiers.Contain(key.ModShift) {
+					step(-1)
+				} else {
+					step(1)
+				}
+			case e.Name == key.NameEscape && bar.open:
+				bar.open = false
+				bar.matches = nil
+			}
+			w.Invalidate()
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			for _, e := range bar.query.Events() {
+				switch e.(type) {
+				case widget.ChangeEvent:
+					search()
+				case widget.SubmitEvent:
+					step(1)
+				}
+			}
+			if bar.matchCase.Changed() {
+				search()
+			}
+			for bar.prev.Clicked() {
+				step(-1)
+			}
+			for bar.next.Clicked() {
+				step(1)
+			}
+			for bar.close.Clicked() {
+				bar.open = false
+				bar.matches = nil
+			}
+			layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					if !bar.open {
+						return D{}
+					}
+					return bar.Layout(gtx, th)
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+						return doc.Layout(gtx, th, bar.matches, bar.current)
+					})
+				}),
+			)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+func (b *findBar) Layout(gtx C, th *material.Theme) D {
+	button := func(c *widget.Clickable, label string) layout.FlexChild {
+		return layout.Rigid(func(gtx C) D {
+			if len(b.matches) == 0 && c != &b.close {
+				gtx = gtx.Disabled()
+			}
+			btn := material.Button(th, c, label)
+			btn.Inset = layout.UniformInset(unit.Dp(8))
+			return layout.Inset{Left: unit.Dp(4)}.Layout(gtx, btn.Layout)
+		})
+	}
+	count := "No results"
+	switch {
+	case b.query.Text() == "":
+		count = ""
+	case len(b.matches) > 0:
+		count = fmt.Sprintf("%d of %d", b.current+1, len(b.matches))
+	}
+	return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx C) D {
+				return widget.Border{
+					Color:        th.Palette.ContrastBg,
+					CornerRadius: unit.Dp(4),
+					Width:        unit.Dp(1),
+				}.Layout(gtx, func(gtx C) D {
+					return layout.UniformInset(unit.Dp(8)).Layout(gtx, material.Editor(th, &b.query, "Find").Layout)
+				})
+			}),
+			layout.Rigid(func(gtx C) D {
+				gtx.Constraints.Min.X = gtx.Px(unit.Dp(90))
+				return layout.Inset{Left: unit.Dp(8)}.Layout(gtx, material.Body2(th, count).Layout)
+			}),
+			layout.Rigid(material.CheckBox(th, &b.matchCase, "Match case").Layout),
+			button(&b.prev, "↑"),
+			button(&b.next, "↓"),
+			button(&b.close, "✕"),
+		)
+	})
+}
+
+func sampleText() []string {
+	const text = `Gio is a library for writing cross-platform immediate mode GUI-s in Go. Gio supports all the major platforms: Linux, macOS, Windows, Android, iOS, FreeBSD, OpenBSD and experimental support for browsers with WebAssembly.
+
+In immediate mode, the program draws the user interface anew for every frame from its own state. There is no tree of widgets to keep in sync with the program: a widget is simply a function that lays out and draws itself given the current constraints.
+
+Widgets describe what to draw through operations. Operations are recorded into a list, the list is passed to the window for drawing, and the window uses the GPU to render the operations efficiently.
+
+Input is delivered to handlers registered with the operations. A handler is identified by a tag, usually a pointer to the state of a widget, and the events for a tag are retrieved the next time the widget is laid out.
+
+Text is shaped into lines by a shaper that caches the layouts of recently drawn strings. Widgets such as labels and editors use the shaper to wrap text and draw the glyphs of each line.`
+	var paras []string
+	for i := 1; i <= 12; i++ {
+		paras = append(paras, fmt.Sprintf("Section %d", i))
+		paras = append(paras, strings.Split(text, "\n\n")...)
+	}
+	return paras
+}
@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"regexp"
+)
+
+// match is the position of a match in a paragraph.
+type match struct {
+	// para is the index of the paragraph.
+	para int
+	// start and end are the byte offsets of the match in the paragraph.
+	start, end int
+}
+
+// findAll returns the non-overlapping matches of query in paras, in
+// document order.
+func findAll(paras []string, query string, matchCase bool) []match {
+	if query == "" {
+		return nil
+	}
+	expr := regexp.QuoteMeta(query)
+	if !matchCase {
+		expr = "(?i)" + expr
+	}
+	re := regexp.MustCompile(expr)
+	var matches []match
+	for i, p := range paras {
+		for _, loc := range re.FindAllStringIndex(p, -1) {
+			matches = append(matches, match{para: i, start: loc[0], end: loc[1]})
+		}
+	}
+	return matches
+}
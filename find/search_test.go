@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindAll(t *testing.T) {
+	paras := []string{"Go is go.", "", "gogo", "Ærø ærø"}
+	tests := []struct {
+		query     string
+		matchCase bool
+		want      []match
+	}{
+		{"", false, nil},
+		{"go", false, []match{{0, 0, 2}, {0, 6, 8}, {2, 0, 2}, {2, 2, 4}}},
+		{"go", true, []match{{0, 6, 8}, {2, 0, 2}, {2, 2, 4}}},
+		{"go.", false, []match{{0, 6, 9}}},
+		{"ærø", false, []match{{3, 0, 5}, {3, 6, 11}}},
+	}
+	for _, test := range tests {
+		got := findAll(paras, test.query, test.matchCase)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("findAll(%q, %v) = %v, want %v", test.query, test.matchCase, got, test.want)
+		}
+	}
+}
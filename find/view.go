@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+	"golang.org/x/image/math/fixed"
+)
+
+var (
+	matchColor   = color.NRGBA{R: 0xff, G: 0xeb, B: 0x3b, A: 0x90}
+	currentColor = color.NRGBA{R: 0xff, G: 0x98, B: 0x00, A: 0xd0}
+)
+
+// Document is a read-only view of paragraphs of wrapped text, with
+// search matches highlighted.
+type Document struct {
+	Paragraphs []string
+
+	list layout.List
+	// last is the index of the last paragraph laid out.
+	last int
+}
+
+// ScrollTo scrolls the paragraph into view, unless it is already
+// visible.
+func (d *Document) ScrollTo(para int) {
+	if para > d.list.Position.First && para < d.last {
+		return
+	}
+	// Leave a paragraph of context above.
+	if para > 0 {
+		para--
+	}
+	d.list.Position.First = para
+	d.list.Position.Offset = 0
+}
+
+// Layout lays out the document with matches highlighted, matches[current]
+// in a stronger color.
+func (d *Document) Layout(gtx layout.Context, th *material.Theme, matches []match, current int) layout.Dimensions {
+	d.list.Axis = layout.Vertical
+	return d.list.Layout(gtx, len(d.Paragraphs), func(gtx layout.Context, i int) layout.Dimensions {
+		d.last = i
+		// The matches are ordered, so find the ones in this paragraph.
+		lo := 0
+		for lo < len(matches) && matches[lo].para < i {
+			lo++
+		}
+		hi := lo
+		for hi < len(matches) && matches[hi].para == i {
+			hi++
+		}
+		return layout.Inset{Bottom: unit.Dp(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layoutParagraph(gtx, th, d.Paragraphs[i], matches[lo:hi], current-lo)
+		})
+	})
+}
+
+// layoutParagraph shapes and draws s wrapped to the width of gtx, with the
+// highlighted matches drawn behind the text.
+func layoutParagraph(gtx layout.Context, th *material.Theme, s string, matches []match, current int) layout.Dimensions {
+	size := fixed.I(gtx.Px(th.TextSize))
+	if s == "" {
+		return layout.Dimensions{Size: image.Pt(gtx.Constraints.Max.X, size.Ceil())}
+	}
+	font := text.Font{}
+	lines := th.Shaper.LayoutString(font, size, gtx.Constraints.Max.X, s)
+	y, start := 0, 0
+	for _, l := range lines {
+		end := start + len(l.Layout.Text)
+		ascent, descent := l.Ascent.Ceil(), l.Descent.Ceil()
+		for i, m := range matches {
+			if m.end <= start || m.start >= end {
+				continue
+			}
+			x0 := advance(l.Layout, m.start-start)
+			x1 := advance(l.Layout, m.end-start)
+			c := matchColor
+			if i == current {
+				c = currentColor
+			}
+			r := image.Rect(x0.Floor(), y, x1.Ceil(), y+ascent+descent)
+			paint.FillShape(gtx.Ops, c, clip.Rect(r).Op())
+		}
+		stack := op.Save(gtx.Ops)
+		op.Offset(f32.Pt(0, float32(y+ascent))).Add(gtx.Ops)
+		th.Shaper.Shape(font, size, l.Layout).Add(gtx.Ops)
+		paint.ColorOp{Color: th.Palette.Fg}.Add(gtx.Ops)
+		paint.PaintOp{}.Add(gtx.Ops)
+		stack.Load()
+		y += ascent + descent
+		start = end
+	}
+	return layout.Dimensions{Size: image.Pt(gtx.Constraints.Max.X, y)}
+}
+
+// advance returns the width of the first n bytes of l. Offsets outside
+// the line are clamped to it.
+func advance(l text.Layout, n int) fixed.Int26_6 {
+	var x fixed.Int26_6
+	i := 0
+	for b := range l.Text {
+		if b >= n || i >= len(l.Advances) {
+			break
+		}
+		x += l.Advances[i]
+		i++
+	}
+	return x
+}
@@ -0,0 +1,437 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program adapts its layout to the orientation of the window and
+// the posture of foldable devices. A list of messages and the message
+// selected are shown one at a time in a narrow window, side by side in
+// a wide one or across a vertical fold, and in a tabletop layout on a
+// device half opened like a laptop: the message above the fold, the
+// controls below it. The layout is chosen anew every frame, so it
+// follows as the window is resized, rotated or folded.
+//
+// Gio doesn't report the hinge of foldable devices, so the device bar at
+// the bottom of the window simulates one: choose where the fold is,
+// whether the device is half opened and whether the hinge hides a gap
+// between two screens. Rotate turns the window and the fold with it.
+//
+// Usage:
+//
+//	go run ./foldable [-fold none|vertical|horizontal] [-folded] [-gap 24]
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/fakedata"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+var (
+	foldFlag   = flag.String("fold", "none", "simulated fold: none, vertical or horizontal")
+	foldedFlag = flag.Bool("folded", false, "simulate a device half opened")
+	gapFlag    = flag.Int("gap", 24, "width in dp of the hinge between two screens")
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	hingeColor    = color.NRGBA{R: 0x21, G: 0x21, B: 0x21, A: 0xff}
+	selectedColor = color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0x20}
+	barColor      = color.NRGBA{R: 0xec, G: 0xef, B: 0xf1, A: 0xff}
+)
+
+// minPane is the narrowest pane.
+var minPane = unit.Dp(360)
+
+func main() {
+	flag.Parse()
+	switch *foldFlag {
+	case "none", "vertical", "horizontal":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown fold %q\n", *foldFlag)
+		os.Exit(2)
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("Foldable"),
+			app.Size(unit.Dp(900), unit.Dp(640)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// Message is an entry of the list.
+type Message struct {
+	From    string
+	Subject string
+	Body    string
+	avatar  paint.ImageOp
+	click   widget.Clickable
+}
+
+func newMessages(n int) []*Message {
+	f := fakedata.New(1)
+	msgs := make([]*Message, n)
+	for i, name := range f.Names(n) {
+		msgs[i] = &Message{
+			From:    name,
+			Subject: f.Sentence(),
+			Body:    f.Paragraph(6) + "\n\n" + f.Paragraph(4),
+			avatar:  paint.NewImageOp(fakedata.Avatar(name, 96)),
+		}
+	}
+	return msgs
+}
+
+type App struct {
+	win      *app.Window
+	messages []*Message
+	// selected is the index of the message shown, or -1 for the list in
+	// single pane mode.
+	selected int
+	list     layout.List
+	body     layout.List
+
+	back, prev, next widget.Clickable
+
+	// The simulated device.
+	fold   widget.Enum
+	folded widget.Bool
+	gap    widget.Bool
+	rotate widget.Clickable
+	// size is the size of the window in the last frame.
+	size image.Point
+	mode Mode
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		win:      w,
+		messages: newMessages(40),
+		selected: -1,
+		list:     layout.List{Axis: layout.Vertical},
+		body:     layout.List{Axis: layout.Vertical},
+	}
+	a.fold.Value = *foldFlag
+	a.folded.Value = *foldedFlag
+	a.gap.Value = *gapFlag > 0
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			a.Layout(gtx, th)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+// posture returns the simulated posture of an app area of size: a fold
+// through its middle.
+func (a *App) posture(gtx C, size image.Point) Posture {
+	gap := 0
+	if a.gap.Value {
+		gap = gtx.Px(unit.Dp(float32(*gapFlag)))
+	}
+	p := Posture{Folded: a.folded.Value}
+	switch a.fold.Value {
+	case "vertical":
+		x := (size.X - gap) / 2
+		p.Hinge = image.Rect(x, 0, x+gap, size.Y)
+	case "horizontal":
+		y := (size.Y - gap) / 2
+		p.Hinge = image.Rect(0, y, size.X, y+gap)
+	}
+	return p
+}
+
+func (a *App) update(gtx C) {
+	if a.rotate.Clicked() {
+		// Turn the window, and the fold with it.
+		a.win.Option(app.Size(
+			unit.Px(float32(a.size.Y)),
+			unit.Px(float32(a.size.X)),
+		))
+		switch a.fold.Value {
+		case "vertical":
+			a.fold.Value = "horizontal"
+		case "horizontal":
+			a.fold.Value = "vertical"
+		}
+	}
+	for i, m := range a.messages {
+		if m.click.Clicked() {
+			a.selected = i
+			a.body.Position = layout.Position{}
+		}
+	}
+	if a.back.Clicked() {
+		a.selected = -1
+	}
+	if a.prev.Clicked() && a.selected > 0 {
+		a.selected--
+		a.body.Position = layout.Position{}
+	}
+	if a.next.Clicked() && a.selected < len(a.messages)-1 {
+		a.selected++
+		a.body.Position = layout.Position{}
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	a.size = gtx.Constraints.Max
+	a.update(gtx)
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return a.layoutApp(gtx, th)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return a.layoutDevice(gtx, th)
+		}),
+	)
+}
+
+// layoutApp lays out the panes of the arrangement for the posture and
+// draws the simulated hinge.
+func (a *App) layoutApp(gtx C, th *material.Theme) D {
+	size := gtx.Constraints.Max
+	p := a.posture(gtx, size)
+	arr := arrange(size, p, gtx.Px(minPane))
+	a.mode = arr.Mode
+
+	switch arr.Mode {
+	case SinglePane:
+		if a.selected >= 0 {
+			pane(gtx, arr.Panes[0], func(gtx C) D {
+				return a.layoutMessage(gtx, th, true)
+			})
+		} else {
+			pane(gtx, arr.Panes[0], func(gtx C) D {
+				return a.layoutList(gtx, th)
+			})
+		}
+	case TwoPane:
+		if a.selected < 0 {
+			a.selected = 0
+		}
+		pane(gtx, arr.Panes[0], func(gtx C) D {
+			return a.layoutList(gtx, th)
+		})
+		pane(gtx, arr.Panes[1], func(gtx C) D {
+			return a.layoutMessage(gtx, th, false)
+		})
+	case Tabletop:
+		if a.selected < 0 {
+			a.selected = 0
+		}
+		pane(gtx, arr.Panes[0], func(gtx C) D {
+			return a.layoutMessage(gtx, th, false)
+		})
+		pane(gtx, arr.Panes[1], func(gtx C) D {
+			return a.layoutControls(gtx, th)
+		})
+	}
+
+	if p.hasFold() {
+		hinge := p.Hinge
+		if hinge.Dx() == 0 {
+			hinge.Max.X++
+		}
+		if hinge.Dy() == 0 {
+			hinge.Max.Y++
+		}
+		paint.FillShape(gtx.Ops, hingeColor, clip.Rect(hinge).Op())
+	}
+	return D{Size: size}
+}
+
+// pane lays out w in the area r.
+func pane(gtx C, r image.Rectangle, w layout.Widget) {
+	if r.Empty() {
+		return
+	}
+	defer op.Save(gtx.Ops).Load()
+	op.Offset(layout.FPt(r.Min)).Add(gtx.Ops)
+	clip.Rect{Max: r.Size()}.Add(gtx.Ops)
+	gtx.Constraints = layout.Exact(r.Size())
+	w(gtx)
+}
+
+func (a *App) layoutList(gtx C, th *material.Theme) D {
+	return a.list.Layout(gtx, len(a.messages), func(gtx C, i int) D {
+		m := a.messages[i]
+		return material.Clickable(gtx, &m.click, func(gtx C) D {
+			if i == a.selected && a.mode != SinglePane {
+				defer op.Save(gtx.Ops).Load()
+				macro := op.Record(gtx.Ops)
+				dims := a.layoutItem(gtx, th, m)
+				call := macro.Stop()
+				paint.FillShape(gtx.Ops, selectedColor, clip.Rect{Max: dims.Size}.Op())
+				call.Add(gtx.Ops)
+				return dims
+			}
+			return a.layoutItem(gtx, th, m)
+		})
+	})
+}
+
+func (a *App) layoutItem(gtx C, th *material.Theme, m *Message) D {
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return avatar(gtx, m, unit.Dp(40))
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+			layout.Flexed(1, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						l := material.Body1(th, m.From)
+						l.Font.Weight = text.Bold
+						return l.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx C) D {
+						l := material.Body2(th, m.Subject)
+						l.MaxLines = 1
+						return l.Layout(gtx)
+					}),
+				)
+			}),
+		)
+	})
+}
+
+func avatar(gtx C, m *Message, size unit.Value) D {
+	px := gtx.Px(size)
+	return widget.Image{
+		Src:   m.avatar,
+		Scale: float32(px) / float32(m.avatar.Size().X) / gtx.Metric.PxPerDp,
+	}.Layout(gtx)
+}
+
+// layoutMessage shows the selected message, with a button back to the
+// list if back is set.
+func (a *App) layoutMessage(gtx C, th *material.Theme, back bool) D {
+	m := a.messages[a.selected]
+	header := func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				if !back {
+					return D{}
+				}
+				return layout.Inset{Right: unit.Dp(12)}.Layout(gtx, material.Button(th, &a.back, "‹ Back").Layout)
+			}),
+			layout.Rigid(func(gtx C) D {
+				return avatar(gtx, m, unit.Dp(48))
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+			layout.Flexed(1, material.H6(th, m.From).Layout),
+		)
+	}
+	subject := material.Body1(th, m.Subject)
+	subject.Font.Weight = text.Bold
+	parts := []layout.Widget{
+		header,
+		layout.Spacer{Height: unit.Dp(12)}.Layout,
+		subject.Layout,
+		layout.Spacer{Height: unit.Dp(12)}.Layout,
+		material.Body1(th, m.Body).Layout,
+	}
+	return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+		return a.body.Layout(gtx, len(parts), func(gtx C, i int) D {
+			return parts[i](gtx)
+		})
+	})
+}
+
+// layoutControls draws the controls below the fold of the tabletop
+// layout: the position in the list, buttons to step through it and the
+// list itself.
+func (a *App) layoutControls(gtx C, th *material.Theme) D {
+	paint.Fill(gtx.Ops, barColor)
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle, Spacing: layout.SpaceBetween}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						if a.selected == 0 {
+							gtx = gtx.Disabled()
+						}
+						return material.Button(th, &a.prev, "‹ Previous").Layout(gtx)
+					}),
+					layout.Rigid(material.Body2(th, fmt.Sprintf("%d of %d", a.selected+1, len(a.messages))).Layout),
+					layout.Rigid(func(gtx C) D {
+						if a.selected == len(a.messages)-1 {
+							gtx = gtx.Disabled()
+						}
+						return material.Button(th, &a.next, "Next ›").Layout(gtx)
+					}),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return a.layoutList(gtx, th)
+		}),
+	)
+}
+
+// layoutDevice draws the bar of the simulated device: the orientation
+// and layout chosen, and the controls of the fold.
+func (a *App) layoutDevice(gtx C, th *material.Theme) D {
+	macro := op.Record(gtx.Ops)
+	dims := layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				dp := func(px int) int { return int(float32(px) / gtx.Metric.PxPerDp) }
+				status := fmt.Sprintf("%s, %d×%d dp: %s", orientation(a.size), dp(a.size.X), dp(a.size.Y), a.mode)
+				l := material.Body2(th, status)
+				l.Font.Weight = text.Bold
+				return l.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(material.Body2(th, "Fold:").Layout),
+					layout.Rigid(material.RadioButton(th, &a.fold, "none", "None").Layout),
+					layout.Rigid(material.RadioButton(th, &a.fold, "vertical", "Vertical").Layout),
+					layout.Rigid(material.RadioButton(th, &a.fold, "horizontal", "Horizontal").Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+					layout.Rigid(material.CheckBox(th, &a.folded, "Half opened").Layout),
+					layout.Rigid(material.CheckBox(th, &a.gap, "Hinge gap").Layout),
+					layout.Flexed(1, layout.Spacer{}.Layout),
+					layout.Rigid(material.Button(th, &a.rotate, "Rotate").Layout),
+				)
+			}),
+		)
+	})
+	call := macro.Stop()
+	paint.FillShape(gtx.Ops, barColor, clip.Rect{Max: dims.Size}.Op())
+	call.Add(gtx.Ops)
+	return dims
+}
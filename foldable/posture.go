@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+)
+
+// Posture is the shape of the device the window is on.
+type Posture struct {
+	// Hinge is the area of the window at the fold, in pixels. It is
+	// empty without a fold, and a line for a seamless fold; a hinge
+	// between two screens covers the gap between them.
+	Hinge image.Rectangle
+	// Folded reports whether the device is half opened, like a book or
+	// a laptop, rather than flat.
+	Folded bool
+}
+
+// hasFold reports whether the posture has a fold at all.
+func (p Posture) hasFold() bool {
+	return p.Hinge != (image.Rectangle{})
+}
+
+// vertical reports whether the fold runs from top to bottom.
+func (p Posture) vertical() bool {
+	return p.Hinge.Dy() > p.Hinge.Dx()
+}
+
+// Orientation is the orientation of the window.
+type Orientation int
+
+const (
+	Portrait Orientation = iota
+	Landscape
+)
+
+func (o Orientation) String() string {
+	if o == Landscape {
+		return "Landscape"
+	}
+	return "Portrait"
+}
+
+// orientation returns the orientation of a window of size.
+func orientation(size image.Point) Orientation {
+	if size.X > size.Y {
+		return Landscape
+	}
+	return Portrait
+}
+
+// Mode is the layout of the example.
+type Mode int
+
+const (
+	// SinglePane shows the list or a message.
+	SinglePane Mode = iota
+	// TwoPane shows the list beside or above a message.
+	TwoPane
+	// Tabletop shows a message above the fold of a device half opened
+	// like a laptop, and the controls below it.
+	Tabletop
+)
+
+func (m Mode) String() string {
+	switch m {
+	case TwoPane:
+		return "Two panes"
+	case Tabletop:
+		return "Tabletop"
+	default:
+		return "Single pane"
+	}
+}
+
+// Arrangement is the layout chosen for a window: the mode and the areas
+// of its panes. Panes never overlap the hinge.
+type Arrangement struct {
+	Mode Mode
+	// Panes are the areas of the panes; the second is empty in single
+	// pane mode. In tabletop mode, the first is above the fold.
+	Panes [2]image.Rectangle
+}
+
+// arrange chooses the layout of a window of size in posture p, for
+// panes at least minPane pixels wide.
+//
+// A fold splits the window where it is: a vertical fold into the list
+// and a message, a horizontal fold of a half opened device into the
+// tabletop layout. A horizontal fold of a flat device is only split
+// around when its hinge hides part of the window. Without a fold, a
+// window wide enough for two panes shows both.
+func arrange(size image.Point, p Posture, minPane int) Arrangement {
+	full := image.Rectangle{Max: size}
+	// Not Intersect, which loses the position of a seamless fold.
+	h := image.Rectangle{Min: clampPt(p.Hinge.Min, size), Max: clampPt(p.Hinge.Max, size)}
+	switch {
+	case !p.hasFold():
+		if size.X >= 2*minPane {
+			// The list takes the smaller part, as long as it fits.
+			split := size.X * 2 / 5
+			if split < minPane {
+				split = minPane
+			}
+			return Arrangement{Mode: TwoPane, Panes: [2]image.Rectangle{
+				{Max: image.Pt(split, size.Y)},
+				{Min: image.Pt(split, 0), Max: size},
+			}}
+		}
+	case p.vertical():
+		return Arrangement{Mode: TwoPane, Panes: [2]image.Rectangle{
+			{Max: image.Pt(h.Min.X, size.Y)},
+			{Min: image.Pt(h.Max.X, 0), Max: size},
+		}}
+	case p.Folded || h.Dy() > 0:
+		mode := TwoPane
+		if p.Folded {
+			mode = Tabletop
+		}
+		return Arrangement{Mode: mode, Panes: [2]image.Rectangle{
+			{Max: image.Pt(size.X, h.Min.Y)},
+			{Min: image.Pt(0, h.Max.Y), Max: size},
+		}}
+	}
+	return Arrangement{Mode: SinglePane, Panes: [2]image.Rectangle{full}}
+}
+
+func clampPt(p, max image.Point) image.Point {
+	return image.Pt(clamp(p.X, max.X), clamp(p.Y, max.Y))
+}
+
+func clamp(v, max int) int {
+	switch {
+	case v < 0:
+		return 0
+	case v > max:
+		return max
+	default:
+		return v
+	}
+}
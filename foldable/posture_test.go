@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+func TestArrange(t *testing.T) {
+	const minPane = 300
+	tests := []struct {
+		name  string
+		size  image.Point
+		p     Posture
+		mode  Mode
+		panes [2]image.Rectangle
+	}{
+		{
+			name:  "narrow",
+			size:  image.Pt(400, 800),
+			mode:  SinglePane,
+			panes: [2]image.Rectangle{image.Rect(0, 0, 400, 800)},
+		},
+		{
+			name:  "wide",
+			size:  image.Pt(1000, 600),
+			mode:  TwoPane,
+			panes: [2]image.Rectangle{image.Rect(0, 0, 400, 600), image.Rect(400, 0, 1000, 600)},
+		},
+		{
+			name:  "wide enough for a list of the narrowest pane",
+			size:  image.Pt(600, 600),
+			mode:  TwoPane,
+			panes: [2]image.Rectangle{image.Rect(0, 0, 300, 600), image.Rect(300, 0, 600, 600)},
+		},
+		{
+			name:  "vertical hinge",
+			size:  image.Pt(500, 600),
+			p:     Posture{Hinge: image.Rect(240, 0, 260, 600)},
+			mode:  TwoPane,
+			panes: [2]image.Rectangle{image.Rect(0, 0, 240, 600), image.Rect(260, 0, 500, 600)},
+		},
+		{
+			name:  "seamless vertical fold",
+			size:  image.Pt(500, 600),
+			p:     Posture{Hinge: image.Rect(250, 0, 250, 600), Folded: true},
+			mode:  TwoPane,
+			panes: [2]image.Rectangle{image.Rect(0, 0, 250, 600), image.Rect(250, 0, 500, 600)},
+		},
+		{
+			name:  "half opened with a horizontal fold",
+			size:  image.Pt(1000, 600),
+			p:     Posture{Hinge: image.Rect(0, 300, 1000, 300), Folded: true},
+			mode:  Tabletop,
+			panes: [2]image.Rectangle{image.Rect(0, 0, 1000, 300), image.Rect(0, 300, 1000, 600)},
+		},
+		{
+			name:  "flat with a seamless horizontal fold",
+			size:  image.Pt(400, 800),
+			p:     Posture{Hinge: image.Rect(0, 400, 400, 400)},
+			mode:  SinglePane,
+			panes: [2]image.Rectangle{image.Rect(0, 0, 400, 800)},
+		},
+		{
+			name:  "flat with a horizontal hinge",
+			size:  image.Pt(400, 800),
+			p:     Posture{Hinge: image.Rect(0, 390, 400, 410)},
+			mode:  TwoPane,
+			panes: [2]image.Rectangle{image.Rect(0, 0, 400, 390), image.Rect(0, 410, 400, 800)},
+		},
+	}
+	for _, test := range tests {
+		got := arrange(test.size, test.p, minPane)
+		if got.Mode != test.mode || got.Panes != test.panes {
+			t.Errorf("%s: got %v %v, want %v %v", test.name, got.Mode, got.Panes, test.mode, test.panes)
+		}
+	}
+}
+
+func TestOrientation(t *testing.T) {
+	if o := orientation(image.Pt(800, 600)); o != Landscape {
+		t.Errorf("800×600 is %v", o)
+	}
+	if o := orientation(image.Pt(600, 600)); o != Portrait {
+		t.Errorf("600×600 is %v", o)
+	}
+}
@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates the formatted fields of internal/mask in a
+// form. The fields format their input as it is typed, keeping the
+// caret in place, and report invalid input once left. The form can be
+// submitted when every field is valid.
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/mask"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Form"),
+			app.Size(unit.Dp(480), unit.Dp(640)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+type field struct {
+	label, hint string
+	mask.Field
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	fields := []*field{
+		{label: "Phone", hint: "(555) 123-4567", Field: mask.Field{Formatter: mask.Phone}},
+		{label: "Card number", hint: "4111 1111 1111 1111", Field: mask.Field{Formatter: mask.Card{}}},
+		{label: "Amount (USD)", hint: "0.00", Field: mask.Field{Formatter: mask.Currency{}}},
+		{label: "Server address", hint: "192.168.0.1", Field: mask.Field{Formatter: mask.IPv4{}}},
+	}
+	var (
+		ops     op.Ops
+		list    = layout.List{Axis: layout.Vertical}
+		submit  widget.Clickable
+		summary string
+	)
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			valid := true
+			for _, f := range fields {
+				if f.Err() != nil {
+					valid = false
+				}
+			}
+			for submit.Clicked() {
+				summary = "Submitted:\n"
+				for _, f := range fields {
+					summary += fmt.Sprintf("%s: %s\n", f.label, f.Text())
+				}
+			}
+			layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+				return list.Layout(gtx, len(fields)+2, func(gtx C, i int) D {
+					switch {
+					case i < len(fields):
+						f := fields[i]
+						return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+							return f.Layout(gtx, th, f.label, f.hint)
+						})
+					case i == len(fields):
+						if !valid {
+							gtx = gtx.Disabled()
+						}
+						return material.Button(th, &submit, "Submit").Layout(gtx)
+					default:
+						return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, material.Body1(th, summary).Layout)
+					}
+				})
+			})
+			e.Frame(gtx.Ops)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates instrumentation widgets: circular gauges,
+// linear level meters with peak hold and rotary knobs. The knobs control a
+// simulated signal shown by the gauges and meters. Drag a knob up or down
+// or scroll over it; hold shift for fine adjustment.
+
+import (
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Gauges"),
+			app.Size(unit.Dp(640), unit.Dp(520)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	var (
+		ops   op.Ops
+		gain  = Knob{Value: .6}
+		speed = Knob{Value: .3}
+		noise = Knob{Value: .2}
+
+		load = Gauge{Label: "Load", Format: "%.0f%%", Max: 100, Warn: 80}
+		temp = Gauge{Label: "Temperature", Format: "%.1f °C", Value: 30, Min: 20, Max: 100, Warn: 85}
+		rpm  = Gauge{Label: "Fan", Format: "%.0f rpm", Max: 3000}
+
+		left, right Meter
+		phase       float64
+		last        time.Time
+		rnd         = rand.New(rand.NewSource(1))
+	)
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			dt := gtx.Now.Sub(last).Seconds()
+			if last.IsZero() || dt > .1 {
+				dt = 0
+			}
+			last = gtx.Now
+
+			// Simulate the signal.
+			phase += dt * (.5 + float64(speed.Value)*6)
+			n := float32(noise.Value)
+			level := func(offset float64) float32 {
+				s := float32(math.Abs(math.Sin(phase+offset))) * gain.Value
+				return clamp(s + (rnd.Float32()-.5)*n*.5)
+			}
+			left.Update(level(0), float32(dt)*.5)
+			right.Update(level(.7), float32(dt)*.5)
+			avg := (left.Value + right.Value) / 2
+			load.Value = avg * 100
+			// The temperature and fan follow the load with some lag.
+			target := 30 + avg*65
+			temp.Value += (target - temp.Value) * float32(dt)
+			rpm.Value = fraction(temp.Value, 40, 90) * 3000
+
+			layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						return layout.Flex{Spacing: layout.SpaceEvenly}.Layout(gtx,
+							layout.Rigid(gauge(th, &load).Layout),
+							layout.Rigid(gauge(th, &temp).Layout),
+							layout.Rigid(gauge(th, &rpm).Layout),
+						)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(24)}.Layout),
+					layout.Rigid(material.Body2(th, "L").Layout),
+					layout.Rigid(meter(&left).Layout),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+					layout.Rigid(material.Body2(th, "R").Layout),
+					layout.Rigid(meter(&right).Layout),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(24)}.Layout),
+					layout.Rigid(func(gtx C) D {
+						return layout.Flex{Spacing: layout.SpaceEvenly}.Layout(gtx,
+							layout.Rigid(knob(th, &gain, "Gain").Layout),
+							layout.Rigid(knob(th, &speed, "Speed").Layout),
+							layout.Rigid(knob(th, &noise, "Noise").Layout),
+						)
+					}),
+				)
+			})
+			// The signal is live.
+			op.InvalidateOp{}.Add(gtx.Ops)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
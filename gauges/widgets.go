@@ -0,0 +1,318 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+// The dials of gauges and knobs sweep 270 degrees, starting at the lower
+// left.
+const (
+	sweepStart = 3 * math.Pi / 4
+	sweep      = 3 * math.Pi / 2
+)
+
+// arc returns the outline of a band between the radii r0 and r1, from
+// angle a0 to a1 in radians, clockwise from the positive x axis.
+func arc(ops *op.Ops, c f32.Point, r0, r1, a0, a1 float32) clip.Op {
+	// Approximate with one segment per 3 degrees.
+	n := int(math.Ceil(math.Abs(float64(a1-a0)) / (math.Pi / 60)))
+	if n < 1 {
+		n = 1
+	}
+	pt := func(r, a float32) f32.Point {
+		s, co := math.Sincos(float64(a))
+		return c.Add(f32.Pt(r*float32(co), r*float32(s)))
+	}
+	var p clip.Path
+	p.Begin(ops)
+	p.MoveTo(pt(r1, a0))
+	for i := 1; i <= n; i++ {
+		p.LineTo(pt(r1, a0+(a1-a0)*float32(i)/float32(n)))
+	}
+	for i := n; i >= 0; i-- {
+		p.LineTo(pt(r0, a0+(a1-a0)*float32(i)/float32(n)))
+	}
+	p.Close()
+	return clip.Outline{Path: p.End()}.Op()
+}
+
+// needle returns the outline of a tapered needle from c to radius r at
+// angle a.
+func needle(ops *op.Ops, c f32.Point, r, width, a float32) clip.Op {
+	s, co := math.Sincos(float64(a))
+	dir := f32.Pt(float32(co), float32(s))
+	normal := f32.Pt(-dir.Y, dir.X).Mul(width / 2)
+	var p clip.Path
+	p.Begin(ops)
+	p.MoveTo(c.Add(normal))
+	p.LineTo(c.Add(dir.Mul(r)))
+	p.LineTo(c.Sub(normal))
+	p.LineTo(c.Sub(dir.Mul(width)))
+	p.Close()
+	return clip.Outline{Path: p.End()}.Op()
+}
+
+// Gauge is a circular gauge displaying a value in a range.
+type Gauge struct {
+	Value, Min, Max float32
+	Label           string
+	Format          string
+	// Warn is the value from which the value is drawn in the warning
+	// color.
+	Warn float32
+}
+
+// GaugeStyle lays out a Gauge.
+type GaugeStyle struct {
+	Gauge *Gauge
+	Theme *material.Theme
+	Size  unit.Value
+	Track color.NRGBA
+	Fill  color.NRGBA
+	Alert color.NRGBA
+}
+
+func gauge(th *material.Theme, g *Gauge) GaugeStyle {
+	return GaugeStyle{
+		Gauge: g,
+		Theme: th,
+		Size:  unit.Dp(140),
+		Track: color.NRGBA{A: 0x20},
+		Fill:  th.Palette.ContrastBg,
+		Alert: color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff},
+	}
+}
+
+func (s GaugeStyle) Layout(gtx layout.Context) layout.Dimensions {
+	g := s.Gauge
+	size := gtx.Px(s.Size)
+	r := float32(size) / 2
+	c := f32.Pt(r, r)
+	thick := r / 6
+	t := fraction(g.Value, g.Min, g.Max)
+
+	paint.FillShape(gtx.Ops, s.Track, arc(gtx.Ops, c, r-thick, r, sweepStart, sweepStart+sweep))
+	fill := s.Fill
+	if g.Warn != 0 && g.Value >= g.Warn {
+		fill = s.Alert
+	}
+	if t > 0 {
+		paint.FillShape(gtx.Ops, fill, arc(gtx.Ops, c, r-thick, r, sweepStart, sweepStart+sweep*t))
+	}
+	// Ticks every tenth of the range.
+	for i := 0; i <= 10; i++ {
+		a := sweepStart + sweep*float32(i)/10
+		paint.FillShape(gtx.Ops, s.Theme.Palette.Fg, arc(gtx.Ops, c, r-thick*1.6, r-thick*1.2, a-.01, a+.01))
+	}
+	paint.FillShape(gtx.Ops, s.Theme.Palette.Fg, needle(gtx.Ops, c, r-thick*1.4, thick/2, sweepStart+sweep*t))
+
+	// The value and label below the center.
+	stack := op.Save(gtx.Ops)
+	op.Offset(f32.Pt(0, r*1.15)).Add(gtx.Ops)
+	cgtx := gtx
+	cgtx.Constraints = layout.Exact(image.Pt(size, size-int(r*1.15)))
+	layout.N.Layout(cgtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(material.Body1(s.Theme, fmt.Sprintf(g.Format, g.Value)).Layout),
+			layout.Rigid(material.Caption(s.Theme, g.Label).Layout),
+		)
+	})
+	stack.Load()
+	return layout.Dimensions{Size: image.Pt(size, size)}
+}
+
+// Meter is a linear level meter with a peak hold indicator, in the style
+// of audio equipment.
+type Meter struct {
+	// Value and Peak are in [0, 1].
+	Value, Peak float32
+}
+
+// Update sets the value and lets the peak decay by the given amount.
+func (m *Meter) Update(v, decay float32) {
+	m.Value = v
+	m.Peak -= decay
+	if v > m.Peak {
+		m.Peak = v
+	}
+}
+
+// MeterStyle lays out a Meter.
+type MeterStyle struct {
+	Meter    *Meter
+	Segments int
+	Height   unit.Value
+}
+
+func meter(m *Meter) MeterStyle {
+	return MeterStyle{Meter: m, Segments: 24, Height: unit.Dp(12)}
+}
+
+func (s MeterStyle) Layout(gtx layout.Context) layout.Dimensions {
+	width := gtx.Constraints.Max.X
+	height := gtx.Px(s.Height)
+	gap := gtx.Px(unit.Dp(2))
+	seg := (width - gap*(s.Segments-1)) / s.Segments
+	lit := int(s.Meter.Value * float32(s.Segments))
+	peak := int(s.Meter.Peak*float32(s.Segments)) - 1
+	for i := 0; i < s.Segments; i++ {
+		col := segmentColor(float32(i) / float32(s.Segments))
+		if i >= lit && i != peak {
+			col.A = 0x30
+		}
+		x := i * (seg + gap)
+		paint.FillShape(gtx.Ops, col, clip.Rect(image.Rect(x, 0, x+seg, height)).Op())
+	}
+	return layout.Dimensions{Size: image.Pt(width, height)}
+}
+
+// segmentColor returns green for low levels, yellow from 70% and red from
+// 90%.
+func segmentColor(t float32) color.NRGBA {
+	switch {
+	case t >= .9:
+		return color.NRGBA{R: 0xe5, G: 0x39, B: 0x35, A: 0xff}
+	case t >= .7:
+		return color.NRGBA{R: 0xfd, G: 0xd8, B: 0x35, A: 0xff}
+	default:
+		return color.NRGBA{R: 0x43, G: 0xa0, B: 0x47, A: 0xff}
+	}
+}
+
+// Knob is a rotary input. Drag up or down, or scroll, to turn it; hold
+// shift for fine adjustment.
+type Knob struct {
+	// Value is in [0, 1].
+	Value float32
+
+	dragging bool
+	last     f32.Point
+	changed  bool
+}
+
+// Changed reports whether the value was changed since the last call.
+func (k *Knob) Changed() bool {
+	c := k.changed
+	k.changed = false
+	return c
+}
+
+// knobTravel is the drag distance for turning a knob from minimum to
+// maximum, and fineFactor the reduction of sensitivity while shift is
+// held.
+const (
+	knobTravel = 200
+	fineFactor = 10
+)
+
+func (k *Knob) update(gtx layout.Context) {
+	for _, e := range gtx.Events(k) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		var delta float32
+		switch e.Type {
+		case pointer.Press:
+			k.dragging = true
+			k.last = e.Position
+		case pointer.Drag:
+			delta = (k.last.Y - e.Position.Y) / float32(gtx.Px(unit.Dp(knobTravel)))
+			k.last = e.Position
+		case pointer.Release, pointer.Cancel:
+			k.dragging = false
+		case pointer.Scroll:
+			delta = -e.Scroll.Y / float32(gtx.Px(unit.Dp(knobTravel)))
+		}
+		if delta == 0 {
+			continue
+		}
+		if e.Modifiers.Contain(key.ModShift) {
+			delta /= fineFactor
+		}
+		k.Value = clamp(k.Value + delta)
+		k.changed = true
+	}
+}
+
+// KnobStyle lays out a Knob.
+type KnobStyle struct {
+	Knob  *Knob
+	Theme *material.Theme
+	Label string
+	Size  unit.Value
+}
+
+func knob(th *material.Theme, k *Knob, label string) KnobStyle {
+	return KnobStyle{Knob: k, Theme: th, Label: label, Size: unit.Dp(64)}
+}
+
+func (s KnobStyle) Layout(gtx layout.Context) layout.Dimensions {
+	k := s.Knob
+	k.update(gtx)
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			size := gtx.Px(s.Size)
+			r := float32(size) / 2
+			c := f32.Pt(r, r)
+			defer op.Save(gtx.Ops).Load()
+			pointer.Ellipse(image.Rect(0, 0, size, size)).Add(gtx.Ops)
+			pointer.InputOp{
+				Tag:   k,
+				Grab:  k.dragging,
+				Types: pointer.Press | pointer.Drag | pointer.Release | pointer.Scroll,
+				// The router clamps scroll distances to the bounds; the
+				// value is clamped instead.
+				ScrollBounds: image.Rect(0, -1e6, 0, 1e6),
+			}.Add(gtx.Ops)
+			pointer.CursorNameOp{Name: pointer.CursorGrab}.Add(gtx.Ops)
+
+			th := s.Theme
+			ring := r / 8
+			paint.FillShape(gtx.Ops, color.NRGBA{A: 0x20}, arc(gtx.Ops, c, r-ring, r, sweepStart, sweepStart+sweep))
+			a := sweepStart + sweep*k.Value
+			if k.Value > 0 {
+				paint.FillShape(gtx.Ops, th.Palette.ContrastBg, arc(gtx.Ops, c, r-ring, r, sweepStart, a))
+			}
+			body := r - ring*1.5
+			paint.FillShape(gtx.Ops, color.NRGBA{R: 0x42, G: 0x42, B: 0x42, A: 0xff},
+				clip.UniformRRect(f32.Rectangle{Min: c.Sub(f32.Pt(body, body)), Max: c.Add(f32.Pt(body, body))}, body).Op(gtx.Ops))
+			// The pointer line on the knob body.
+			paint.FillShape(gtx.Ops, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, arc(gtx.Ops, c, body*.3, body*.9, a-.06, a+.06))
+			return layout.Dimensions{Size: image.Pt(size, size)}
+		}),
+		layout.Rigid(material.Caption(s.Theme, fmt.Sprintf("%s %.0f%%", s.Label, k.Value*100)).Layout),
+	)
+}
+
+func fraction(v, min, max float32) float32 {
+	if max <= min {
+		return 0
+	}
+	return clamp((v - min) / (max - min))
+}
+
+func clamp(v float32) float32 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
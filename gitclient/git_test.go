@@ -0,0 +1,223 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestGraphMerge(t *testing.T) {
+	// m merges b into a, which both branch from r.
+	commits := []Commit{
+		{Hash: "m", Parents: []string{"a", "b"}},
+		{Hash: "b", Parents: []string{"r"}},
+		{Hash: "a", Parents: []string{"r"}},
+		{Hash: "r"},
+	}
+	rows := Graph(commits)
+	nodes := []int{rows[0].Node, rows[1].Node, rows[2].Node, rows[3].Node}
+	if want := []int{0, 1, 0, 0}; !reflect.DeepEqual(nodes, want) {
+		t.Errorf("got nodes %v, want %v", nodes, want)
+	}
+	if want := []Edge{{0, 0}, {0, 1}}; !reflect.DeepEqual(rows[0].Bottom, want) {
+		t.Errorf("merge row: got bottom %v, want %v", rows[0].Bottom, want)
+	}
+	if want := []Edge{{0, 0}, {1, 0}}; !reflect.DeepEqual(rows[3].Top, want) {
+		t.Errorf("root row: got top %v, want %v", rows[3].Top, want)
+	}
+	for i, r := range rows[:3] {
+		if r.Lanes != 2 {
+			t.Errorf("row %d: got %d lanes, want 2", i, r.Lanes)
+		}
+	}
+}
+
+func TestTopoOrder(t *testing.T) {
+	// c was committed with a clock behind that of its parent b.
+	commits := []Commit{
+		{Hash: "b", Parents: []string{"a"}},
+		{Hash: "c", Parents: []string{"b"}},
+		{Hash: "a"},
+	}
+	var got []string
+	for _, c := range topoOrder(commits) {
+		got = append(got, c.Hash)
+	}
+	if want := []string{"c", "b", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got order %v, want %v", got, want)
+	}
+}
+
+const twoHunks = `diff --git a/f b/f
+index 1111111..2222222 100644
+--- a/f
++++ b/f
+@@ -1,3 +1,4 @@ top
+ 1
++1.5
+ 2
+ 3
+@@ -10,2 +10,0 @@
+-10
+-11
+`
+
+func TestSelectHunks(t *testing.T) {
+	p, err := ParsePatch(twoHunks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Header) != 4 || len(p.Hunks) != 2 || p.Hunks[0].Section != "top" {
+		t.Fatalf("unexpected patch %+v", p)
+	}
+	got := p.Select([]bool{false, true})
+	want := strings.Join(p.Header, "\n") + "\n@@ -10,2 +9,0 @@\n-10\n-11\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got := p.Select([]bool{true, true}); got != twoHunks {
+		t.Errorf("selecting all hunks changed the patch:\n%s", got)
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	p, err := ParsePatch(twoHunks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var old strings.Builder
+	for i := 1; i <= 11; i++ {
+		fmt.Fprintf(&old, "%d\n", i)
+	}
+	p2, err := ParsePatch(p.Select([]bool{false, true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	staged, err := applyPatch(old.String(), p2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "1\n2\n3\n4\n5\n6\n7\n8\n9\n"; staged != want {
+		t.Errorf("got\n%s\nwant\n%s", staged, want)
+	}
+	all, err := applyPatch(old.String(), p, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reverted, err := applyPatch(all, p, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reverted != old.String() {
+		t.Errorf("got\n%s\nwant\n%s", reverted, old.String())
+	}
+}
+
+// TestStageHunk stages one of two hunks in a scratch repository.
+func TestStageHunk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gitclient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	gr, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := gr.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.User.Name = "Test"
+	cfg.User.Email = "test@example.com"
+	if err := gr.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenRepo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := func(content string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, "f"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n")
+	if err := r.Add("f"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Commit("Initial"); err != nil {
+		t.Fatal(err)
+	}
+	write("one\nuno\n2\n3\n4\n5\n6\n7\n8\n9\nten\n")
+	diff, err := r.Diff("f", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := ParsePatch(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Hunks) != 2 {
+		t.Fatalf("got %d hunks, want 2", len(p.Hunks))
+	}
+	if err := r.Apply("f", p.Select([]bool{false, true}), false); err != nil {
+		t.Fatal(err)
+	}
+	staged, err := r.Diff("f", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(staged, "+ten") || strings.Contains(staged, "+one") {
+		t.Errorf("unexpected staged changes:\n%s", staged)
+	}
+	// Unstage the second hunk of all changes, whose line numbers are off
+	// by the line the first one adds.
+	if err := r.Add("f"); err != nil {
+		t.Fatal(err)
+	}
+	if staged, err = r.Diff("f", true); err != nil {
+		t.Fatal(err)
+	}
+	sp, err := ParsePatch(staged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Apply("f", sp.Select([]bool{false, true}), true); err != nil {
+		t.Fatal(err)
+	}
+	if staged, err = r.Diff("f", true); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(staged, "+uno") || strings.Contains(staged, "+ten") {
+		t.Errorf("unexpected staged changes:\n%s", staged)
+	}
+	if err := r.Reset("f"); err != nil {
+		t.Fatal(err)
+	}
+	files, err := r.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Staged() || files[0].Work != 'M' {
+		t.Errorf("unstaging left %+v", files)
+	}
+	commits, err := r.Log(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 1 || commits[0].Subject != "Initial" {
+		t.Fatalf("unexpected log %+v", commits)
+	}
+	if want := []string{"HEAD -> master"}; !reflect.DeepEqual(commits[0].Refs, want) {
+		t.Errorf("got refs %q, want %q", commits[0].Refs, want)
+	}
+}
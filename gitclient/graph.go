@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// Edge is a line of the commit graph through half a row, from a lane at
+// one end to a lane at the other.
+type Edge struct {
+	From, To int
+}
+
+// GraphRow describes how the graph is drawn through the row of a commit.
+type GraphRow struct {
+	// Node is the lane of the commit.
+	Node int
+	// Top holds the edges from the top of the row to its middle, and
+	// Bottom those from the middle to the bottom.
+	Top, Bottom []Edge
+	// Lanes is the number of lanes crossing the row.
+	Lanes int
+}
+
+// Graph assigns lanes to commits in topological order, children before
+// their parents. A lane carries the line towards the next commit
+// expected in it; lanes keep their position until freed, so that lines
+// stay straight.
+func Graph(commits []Commit) []GraphRow {
+	var lanes []string
+	rows := make([]GraphRow, len(commits))
+	for i, c := range commits {
+		node := -1
+		for j, h := range lanes {
+			if h == c.Hash {
+				node = j
+				break
+			}
+		}
+		if node == -1 {
+			// A branch tip; take the first free lane.
+			node = free(lanes)
+			if node == len(lanes) {
+				lanes = append(lanes, "")
+			}
+		}
+		r := GraphRow{Node: node}
+		for j, h := range lanes {
+			switch {
+			case h == c.Hash:
+				r.Top = append(r.Top, Edge{j, node})
+				lanes[j] = ""
+			case h != "":
+				r.Top = append(r.Top, Edge{j, j})
+				r.Bottom = append(r.Bottom, Edge{j, j})
+			}
+		}
+		for k, p := range c.Parents {
+			lane := node
+			if k > 0 {
+				lane = -1
+				for j, h := range lanes {
+					if h == p {
+						lane = j
+						break
+					}
+				}
+				if lane == -1 {
+					lane = free(lanes)
+					if lane == len(lanes) {
+						lanes = append(lanes, "")
+					}
+				}
+			}
+			lanes[lane] = p
+			r.Bottom = append(r.Bottom, Edge{node, lane})
+		}
+		for len(lanes) > 0 && lanes[len(lanes)-1] == "" {
+			lanes = lanes[:len(lanes)-1]
+		}
+		r.Lanes = len(lanes)
+		for _, e := range r.Top {
+			if e.From+1 > r.Lanes {
+				r.Lanes = e.From + 1
+			}
+		}
+		if node+1 > r.Lanes {
+			r.Lanes = node + 1
+		}
+		rows[i] = r
+	}
+	return rows
+}
+
+// free returns the index of the first free lane, or len(lanes).
+func free(lanes []string) int {
+	for j, h := range lanes {
+		if h == "" {
+			return j
+		}
+	}
+	return len(lanes)
+}
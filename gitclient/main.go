@@ -0,0 +1,616 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program is a small Git client. It draws the history of all
+// branches as a graph, lists the changed files, stages and unstages
+// whole files or selected hunks of their diffs, and commits. It reads
+// and writes the repository with go-git and doesn't need the git command.
+//
+// Usage:
+//
+//	go run ./gitclient [repository]
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"strings"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/style"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+// historyLen is the number of commits shown.
+const historyLen = 1000
+
+func main() {
+	flag.Parse()
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	repo, err := OpenRepo(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("Git – "+repo.Dir),
+			app.Size(unit.Dp(1300), unit.Dp(800)),
+		)
+		if err := loop(w, repo); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var (
+	monoFont   = text.Font{Variant: "Mono"}
+	errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	selectedBg = color.NRGBA{R: 0xe3, G: 0xf2, B: 0xfd, A: 0xff}
+	deletedBg  = color.NRGBA{R: 0xff, G: 0xeb, B: 0xee, A: 0xff}
+	insertedBg = color.NRGBA{R: 0xe6, G: 0xf4, B: 0xea, A: 0xff}
+	hunkBg     = color.NRGBA{A: 0x0c}
+	// laneColors are cycled through by the lanes of the graph.
+	laneColors = []color.NRGBA{
+		{R: 0x1e, G: 0x88, B: 0xe5, A: 0xff},
+		{R: 0x43, G: 0xa0, B: 0x47, A: 0xff},
+		{R: 0xfb, G: 0x8c, B: 0x00, A: 0xff},
+		{R: 0x8e, G: 0x24, B: 0xaa, A: 0xff},
+		{R: 0xe5, G: 0x39, B: 0x35, A: 0xff},
+		{R: 0x00, G: 0x89, B: 0x7b, A: 0xff},
+	}
+)
+
+// fileKey identifies an entry of the file lists; a file with staged and
+// unstaged changes is in both.
+type fileKey struct {
+	path   string
+	staged bool
+}
+
+// snapshot is the state of the repository, loaded in the background.
+type snapshot struct {
+	branch  string
+	commits []Commit
+	graph   []GraphRow
+	lanes   int
+	files   []FileStatus
+	// patch is the diff of the selected file.
+	sel   fileKey
+	patch *Patch
+	err   error
+}
+
+// load reads the state of the repository after running op, if any.
+func load(r *Repo, op func() error, sel fileKey) *snapshot {
+	s := &snapshot{sel: sel}
+	if op != nil {
+		s.err = op()
+	}
+	var err error
+	if s.branch, err = r.Branch(); err != nil {
+		// No branch yet in an empty repository.
+		s.branch = ""
+	}
+	if s.commits, err = r.Log(historyLen); err != nil {
+		s.err = err
+		return s
+	}
+	s.graph = Graph(s.commits)
+	for _, g := range s.graph {
+		if g.Lanes > s.lanes {
+			s.lanes = g.Lanes
+		}
+	}
+	if s.files, err = r.Status(); err != nil {
+		s.err = err
+		return s
+	}
+	if sel.path != "" {
+		diff, err := r.Diff(sel.path, sel.staged)
+		if err == nil {
+			s.patch, err = ParsePatch(diff)
+		}
+		if err != nil && s.err == nil {
+			s.err = err
+		}
+	}
+	return s
+}
+
+type App struct {
+	repo *Repo
+	snap *snapshot
+	// busy is set while a command runs.
+	busy    bool
+	results chan *snapshot
+	// committing is set while a commit runs, to clear the message once
+	// it succeeds.
+	committing bool
+
+	sel   fileKey
+	hunks []widget.Bool
+	files map[fileKey]*widget.Clickable
+
+	refresh, commit                 widget.Clickable
+	stageFile, stageHunks           widget.Clickable
+	message                         widget.Editor
+	history, unstaged, staged, diff layout.List
+}
+
+func loop(w *app.Window, r *Repo) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		repo:     r,
+		snap:     new(snapshot),
+		results:  make(chan *snapshot, 1),
+		files:    make(map[fileKey]*widget.Clickable),
+		history:  layout.List{Axis: layout.Vertical},
+		unstaged: layout.List{Axis: layout.Vertical},
+		staged:   layout.List{Axis: layout.Vertical},
+		diff:     layout.List{Axis: layout.Vertical},
+	}
+	a.run(nil)
+	var ops op.Ops
+	for {
+		select {
+		case s := <-a.results:
+			a.busy = false
+			a.snap = s
+			if a.committing && s.err == nil {
+				a.message.SetText("")
+			}
+			a.committing = false
+			if s.sel != a.sel {
+				// The selection changed meanwhile.
+				a.run(nil)
+			}
+			a.hunks = nil
+			if s.patch != nil {
+				a.hunks = make([]widget.Bool, len(s.patch.Hunks))
+			}
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update()
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+// run runs op in the background and reloads the repository.
+func (a *App) run(op func() error) {
+	if a.busy {
+		return
+	}
+	a.busy = true
+	r, sel := a.repo, a.sel
+	go func() {
+		a.results <- load(r, op, sel)
+	}()
+}
+
+func (a *App) update() {
+	for k, c := range a.files {
+		for c.Clicked() {
+			a.sel = k
+			a.diff.Position = layout.Position{}
+			a.run(nil)
+		}
+	}
+	for a.refresh.Clicked() {
+		a.run(nil)
+	}
+	sel := a.sel
+	for a.stageFile.Clicked() {
+		if sel.staged {
+			a.run(func() error { return a.repo.Reset(sel.path) })
+		} else {
+			a.run(func() error { return a.repo.Add(sel.path) })
+		}
+	}
+	for a.stageHunks.Clicked() {
+		p := a.snap.patch
+		if p == nil || len(a.hunks) != len(p.Hunks) {
+			break
+		}
+		selected := make([]bool, len(a.hunks))
+		for i := range a.hunks {
+			selected[i] = a.hunks[i].Value
+		}
+		patch := p.Select(selected)
+		a.run(func() error { return a.repo.Apply(sel.path, patch, sel.staged) })
+	}
+	for a.commit.Clicked() {
+		msg := strings.TrimSpace(a.message.Text())
+		a.committing = true
+		a.run(func() error { return a.repo.Commit(msg + "\n") })
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						branch := a.snap.branch
+						if branch == "" {
+							branch = "no commits"
+						}
+						return material.H6(th, branch).Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+					layout.Flexed(1, func(gtx C) D {
+						l := material.Body2(th, a.repo.Dir)
+						if a.snap.err != nil {
+							l.Text = a.snap.err.Error()
+							l.Color = errorColor
+						}
+						return l.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx C) D {
+						if a.busy {
+							gtx = gtx.Disabled()
+						}
+						return style.TextButton(th, &a.refresh, "Refresh")(gtx)
+					}),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Flexed(0.45, func(gtx C) D {
+					return a.layoutHistory(gtx, th)
+				}),
+				layout.Rigid(separator),
+				layout.Flexed(0.55, func(gtx C) D {
+					return a.layoutChanges(gtx, th)
+				}),
+			)
+		}),
+	)
+}
+
+func separator(gtx C) D {
+	size := image.Pt(gtx.Px(unit.Dp(1)), gtx.Constraints.Max.Y)
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 0x30}, clip.Rect{Max: size}.Op())
+	return D{Size: size}
+}
+
+func (a *App) layoutHistory(gtx C, th *material.Theme) D {
+	s := a.snap
+	if len(s.commits) == 0 {
+		return layout.Center.Layout(gtx, material.Body1(th, "No commits yet.").Layout)
+	}
+	return a.history.Layout(gtx, len(s.commits), func(gtx C, i int) D {
+		c := s.commits[i]
+		rowH := gtx.Px(unit.Dp(28))
+		gtx.Constraints = layout.Exact(image.Pt(gtx.Constraints.Max.X, rowH))
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return layoutGraph(gtx, s.graph[i], s.lanes, len(c.Parents) > 1)
+			}),
+			layout.Rigid(func(gtx C) D {
+				return layoutRefs(gtx, th, c.Refs)
+			}),
+			layout.Flexed(1, func(gtx C) D {
+				l := material.Body2(th, c.Subject)
+				l.MaxLines = 1
+				return l.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx C) D {
+				return layout.Inset{Left: unit.Dp(8), Right: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+					l := material.Caption(th, c.Author+" · "+c.Time.Format("2006-01-02 15:04"))
+					l.Color = color.NRGBA{A: 0x90}
+					return l.Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx C) D {
+				l := material.Caption(th, c.Hash[:7])
+				l.Font = monoFont
+				return layout.Inset{Right: unit.Dp(8)}.Layout(gtx, l.Layout)
+			}),
+		)
+	})
+}
+
+// layoutGraph draws the lines and node of a row of the commit graph,
+// filling the height of the row.
+func layoutGraph(gtx C, r GraphRow, lanes int, merge bool) D {
+	lane := float32(gtx.Px(unit.Dp(14)))
+	h := float32(gtx.Constraints.Min.Y)
+	x := func(l int) float32 { return lane*float32(l) + lane/2 }
+	line := func(from, to f32.Point, l int) {
+		var p clip.Path
+		p.Begin(gtx.Ops)
+		p.MoveTo(from)
+		// Bend diagonal lines with a curve.
+		mid := (from.Y + to.Y) / 2
+		p.CubeTo(f32.Pt(from.X, mid), f32.Pt(to.X, mid), to)
+		paint.FillShape(gtx.Ops, laneColors[l%len(laneColors)], clip.Stroke{
+			Path:  p.End(),
+			Style: clip.StrokeStyle{Width: float32(gtx.Px(unit.Dp(2)))},
+		}.Op())
+	}
+	for _, e := range r.Top {
+		line(f32.Pt(x(e.From), 0), f32.Pt(x(e.To), h/2), e.From)
+	}
+	for _, e := range r.Bottom {
+		line(f32.Pt(x(e.From), h/2), f32.Pt(x(e.To), h), e.To)
+	}
+	c := f32.Pt(x(r.Node), h/2)
+	rad := float32(gtx.Px(unit.Dp(4)))
+	col := laneColors[r.Node%len(laneColors)]
+	paint.FillShape(gtx.Ops, col, clip.Circle{Center: c, Radius: rad}.Op(gtx.Ops))
+	if merge {
+		paint.FillShape(gtx.Ops, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, clip.Circle{Center: c, Radius: rad / 2}.Op(gtx.Ops))
+	}
+	if lanes > 12 {
+		lanes = 12
+	}
+	return D{Size: image.Pt(int(lane*float32(lanes)), int(h))}
+}
+
+func layoutRefs(gtx C, th *material.Theme, refs []string) D {
+	var children []layout.FlexChild
+	for _, ref := range refs {
+		ref := strings.TrimPrefix(ref, "HEAD -> ")
+		bg := color.NRGBA{R: 0xe8, G: 0xea, B: 0xf6, A: 0xff}
+		if strings.HasPrefix(ref, "tag: ") {
+			ref = strings.TrimPrefix(ref, "tag: ")
+			bg = color.NRGBA{R: 0xff, G: 0xf3, B: 0xe0, A: 0xff}
+		}
+		children = append(children, layout.Rigid(func(gtx C) D {
+			return layout.Inset{Right: unit.Dp(4)}.Layout(gtx, func(gtx C) D {
+				m := op.Record(gtx.Ops)
+				dims := layout.Inset{Left: unit.Dp(4), Right: unit.Dp(4)}.Layout(gtx, material.Caption(th, ref).Layout)
+				call := m.Stop()
+				rr := float32(gtx.Px(unit.Dp(3)))
+				bounds := f32.Rectangle{Max: layout.FPt(dims.Size)}
+				paint.FillShape(gtx.Ops, bg, clip.UniformRRect(bounds, rr).Op(gtx.Ops))
+				call.Add(gtx.Ops)
+				return dims
+			})
+		}))
+	}
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx, children...)
+}
+
+func (a *App) layoutChanges(gtx C, th *material.Theme) D {
+	var unstaged, staged []FileStatus
+	for _, f := range a.snap.files {
+		if f.Unstaged() {
+			unstaged = append(unstaged, f)
+		}
+		if f.Staged() {
+			staged = append(staged, f)
+		}
+	}
+	header := func(title string, n int) layout.FlexChild {
+		return layout.Rigid(func(gtx C) D {
+			return layout.Inset{Top: unit.Dp(8), Left: unit.Dp(8), Bottom: unit.Dp(4)}.Layout(gtx,
+				material.Body1(th, fmt.Sprintf("%s (%d)", title, n)).Layout)
+		})
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		header("Unstaged changes", len(unstaged)),
+		layout.Flexed(1, func(gtx C) D {
+			return a.layoutFiles(gtx, th, &a.unstaged, unstaged, false)
+		}),
+		header("Staged changes", len(staged)),
+		layout.Flexed(1, func(gtx C) D {
+			return a.layoutFiles(gtx, th, &a.staged, staged, true)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return a.layoutDiffBar(gtx, th)
+		}),
+		layout.Flexed(3, func(gtx C) D {
+			return a.layoutDiff(gtx, th)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Alignment: layout.End}.Layout(gtx,
+					layout.Flexed(1, func(gtx C) D {
+						gtx.Constraints.Min.X = gtx.Constraints.Max.X
+						gtx.Constraints.Min.Y = gtx.Px(unit.Dp(60))
+						return widget.Border{Color: color.NRGBA{A: 0x40}, CornerRadius: unit.Dp(4), Width: unit.Dp(1)}.Layout(gtx, func(gtx C) D {
+							return layout.UniformInset(unit.Dp(6)).Layout(gtx, material.Editor(th, &a.message, "Commit message").Layout)
+						})
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(func(gtx C) D {
+						if a.busy || len(staged) == 0 || strings.TrimSpace(a.message.Text()) == "" {
+							gtx = gtx.Disabled()
+						}
+						return material.Button(th, &a.commit, "Commit").Layout(gtx)
+					}),
+				)
+			})
+		}),
+	)
+}
+
+func (a *App) layoutFiles(gtx C, th *material.Theme, l *layout.List, files []FileStatus, staged bool) D {
+	return l.Layout(gtx, len(files), func(gtx C, i int) D {
+		f := files[i]
+		k := fileKey{f.Path, staged}
+		c := a.files[k]
+		if c == nil {
+			c = new(widget.Clickable)
+			a.files[k] = c
+		}
+		return material.Clickable(gtx, c, func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			if k == a.sel {
+				m := op.Record(gtx.Ops)
+				dims := a.layoutFile(gtx, th, f, staged)
+				call := m.Stop()
+				paint.FillShape(gtx.Ops, selectedBg, clip.Rect{Max: dims.Size}.Op())
+				call.Add(gtx.Ops)
+				return dims
+			}
+			return a.layoutFile(gtx, th, f, staged)
+		})
+	})
+}
+
+func (a *App) layoutFile(gtx C, th *material.Theme, f FileStatus, staged bool) D {
+	state := f.Work
+	if staged {
+		state = f.Index
+	}
+	col := map[byte]color.NRGBA{
+		'M': {R: 0xfb, G: 0x8c, B: 0x00, A: 0xff},
+		'A': {R: 0x43, G: 0xa0, B: 0x47, A: 0xff},
+		'?': {R: 0x43, G: 0xa0, B: 0x47, A: 0xff},
+		'D': errorColor,
+	}[state]
+	if col == (color.NRGBA{}) {
+		col = th.Palette.Fg
+	}
+	return layout.Inset{Left: unit.Dp(8), Top: unit.Dp(2), Bottom: unit.Dp(2)}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				gtx.Constraints.Min.X = gtx.Px(unit.Dp(20))
+				l := material.Body2(th, string(state))
+				l.Font = monoFont
+				l.Color = col
+				return l.Layout(gtx)
+			}),
+			layout.Rigid(material.Body2(th, f.Path).Layout),
+		)
+	})
+}
+
+// layoutDiffBar lays out the actions on the selected file.
+func (a *App) layoutDiffBar(gtx C, th *material.Theme) D {
+	if a.sel.path == "" {
+		return D{}
+	}
+	file, hunks := "Stage file", "Stage selected hunks"
+	if a.sel.staged {
+		file, hunks = "Unstage file", "Unstage selected hunks"
+	}
+	selected := false
+	for i := range a.hunks {
+		selected = selected || a.hunks[i].Value
+	}
+	return layout.Inset{Top: unit.Dp(8), Left: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+		if a.busy {
+			gtx = gtx.Disabled()
+		}
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx C) D {
+				l := material.Body1(th, a.sel.path)
+				l.Font.Weight = text.Bold
+				return l.Layout(gtx)
+			}),
+			layout.Rigid(style.TextButton(th, &a.stageFile, file)),
+			layout.Rigid(func(gtx C) D {
+				if !selected {
+					gtx = gtx.Disabled()
+				}
+				return style.TextButton(th, &a.stageHunks, hunks)(gtx)
+			}),
+		)
+	})
+}
+
+// layoutDiff lays out the hunks of the selected file, each with a check
+// box selecting it.
+func (a *App) layoutDiff(gtx C, th *material.Theme) D {
+	p := a.snap.patch
+	switch {
+	case a.sel.path == "":
+		return layout.Center.Layout(gtx, material.Body2(th, "Select a file to see its changes.").Layout)
+	case p == nil || a.snap.sel != a.sel:
+		return D{}
+	case len(p.Hunks) == 0:
+		msg := "No changes."
+		for _, l := range p.Header {
+			if strings.HasPrefix(l, "Binary files") {
+				msg = "Binary file."
+			}
+		}
+		if p.Header == nil && !a.sel.staged {
+			msg = "New file; stage it to see its contents."
+		}
+		return layout.Center.Layout(gtx, material.Body2(th, msg).Layout)
+	}
+	// Index every line of every hunk, with -1 for the hunk headers.
+	type item struct{ hunk, line int }
+	var items []item
+	for i, h := range p.Hunks {
+		items = append(items, item{i, -1})
+		for j := range h.Lines {
+			items = append(items, item{i, j})
+		}
+	}
+	return a.diff.Layout(gtx, len(items), func(gtx C, i int) D {
+		it := items[i]
+		h := p.Hunks[it.hunk]
+		if it.line == -1 {
+			return fill(gtx, hunkBg, func(gtx C) D {
+				label := fmt.Sprintf("@@ -%d,%d +%d,%d @@ %s", h.OldStart, h.OldLines, h.NewStart, h.NewLines, h.Section)
+				cb := material.CheckBox(th, &a.hunks[it.hunk], label)
+				cb.Font = monoFont
+				return layout.Inset{Left: unit.Dp(4)}.Layout(gtx, cb.Layout)
+			})
+		}
+		line := h.Lines[it.line]
+		var bg color.NRGBA
+		switch {
+		case strings.HasPrefix(line, "-"):
+			bg = deletedBg
+		case strings.HasPrefix(line, "+"):
+			bg = insertedBg
+		}
+		return fill(gtx, bg, func(gtx C) D {
+			l := material.Body2(th, strings.ReplaceAll(line, "\t", "    "))
+			l.Font = monoFont
+			l.MaxLines = 1
+			return layout.Inset{Left: unit.Dp(8)}.Layout(gtx, l.Layout)
+		})
+	})
+}
+
+// fill lays out w across the width on a background.
+func fill(gtx C, bg color.NRGBA, w layout.Widget) D {
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	m := op.Record(gtx.Ops)
+	dims := w(gtx)
+	call := m.Stop()
+	paint.FillShape(gtx.Ops, bg, clip.Rect{Max: dims.Size}.Op())
+	call.Add(gtx.Ops)
+	return dims
+}
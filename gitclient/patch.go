@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Patch is the diff of a file as printed by git diff.
+type Patch struct {
+	// Header holds the lines before the first hunk.
+	Header []string
+	Hunks  []Hunk
+}
+
+// Hunk is a run of changed lines with their context.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	// Section is the text following the range, often the enclosing
+	// function.
+	Section string
+	// Lines are prefixed with ' ', '-', '+', or '\' for the no newline
+	// marker.
+	Lines []string
+}
+
+// ParsePatch parses the diff of a single file. Binary files have no
+// hunks.
+func ParsePatch(diff string) (*Patch, error) {
+	p := new(Patch)
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	if diff == "" {
+		return p, nil
+	}
+	for _, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "@@ "):
+			h, err := parseHunkHeader(l)
+			if err != nil {
+				return nil, err
+			}
+			p.Hunks = append(p.Hunks, h)
+		case len(p.Hunks) == 0:
+			p.Header = append(p.Header, l)
+		default:
+			h := &p.Hunks[len(p.Hunks)-1]
+			h.Lines = append(h.Lines, l)
+		}
+	}
+	return p, nil
+}
+
+// parseHunkHeader parses a line such as "@@ -1,5 +1,6 @@ func main() {".
+func parseHunkHeader(l string) (Hunk, error) {
+	var h Hunk
+	end := strings.Index(l[3:], " @@")
+	if end == -1 {
+		return h, fmt.Errorf("bad hunk header %q", l)
+	}
+	ranges := strings.Fields(l[3 : 3+end])
+	if len(ranges) != 2 || !strings.HasPrefix(ranges[0], "-") || !strings.HasPrefix(ranges[1], "+") {
+		return h, fmt.Errorf("bad hunk header %q", l)
+	}
+	var err1, err2 error
+	h.OldStart, h.OldLines, err1 = parseRange(ranges[0][1:])
+	h.NewStart, h.NewLines, err2 = parseRange(ranges[1][1:])
+	if err1 != nil || err2 != nil {
+		return h, fmt.Errorf("bad hunk header %q", l)
+	}
+	h.Section = strings.TrimPrefix(l[3+end+3:], " ")
+	return h, nil
+}
+
+// parseRange parses "start,lines", where lines defaults to 1.
+func parseRange(s string) (start, lines int, err error) {
+	lines = 1
+	if i := strings.IndexByte(s, ','); i != -1 {
+		if lines, err = strconv.Atoi(s[i+1:]); err != nil {
+			return
+		}
+		s = s[:i]
+	}
+	start, err = strconv.Atoi(s)
+	return
+}
+
+// Select returns the patch of the selected hunks alone, for Repo.Apply.
+// The new line numbers of the hunks account for the hunks left out.
+func (p *Patch) Select(selected []bool) string {
+	var b strings.Builder
+	for _, l := range p.Header {
+		b.WriteString(l)
+		b.WriteByte('\n')
+	}
+	delta := 0
+	for i, h := range p.Hunks {
+		if !selected[i] {
+			continue
+		}
+		// Empty ranges start at the line before them.
+		start := h.OldStart + delta
+		switch {
+		case h.OldLines == 0:
+			start++
+		case h.NewLines == 0:
+			start--
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, start, h.NewLines)
+		if h.Section != "" {
+			b.WriteString(" " + h.Section)
+		}
+		b.WriteByte('\n')
+		for _, l := range h.Lines {
+			b.WriteString(l)
+			b.WriteByte('\n')
+		}
+		delta += h.NewLines - h.OldLines
+	}
+	return b.String()
+}
+
+// applyPatch applies the hunks of p to content, or reverts them if
+// reverse is set. Like git apply, it looks for the lines a hunk replaces
+// near its line numbers, which may be off when hunks were left out of
+// the patch.
+func applyPatch(content string, p *Patch, reverse bool) (string, error) {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	offset := 0
+	for _, h := range p.Hunks {
+		start, n := h.OldStart, h.OldLines
+		pre, post := h.sides()
+		if reverse {
+			start, n = h.NewStart, h.NewLines
+			pre, post = post, pre
+		}
+		// Empty ranges start at the line before them.
+		at := start + offset
+		if n > 0 {
+			at--
+		}
+		found := -1
+		for d := 0; d <= len(lines); d++ {
+			if i := at + d; matchLines(lines, i, pre) {
+				found = i
+				break
+			}
+			if i := at - d; matchLines(lines, i, pre) {
+				found = i
+				break
+			}
+		}
+		if found == -1 {
+			return "", fmt.Errorf("hunk %s does not apply", h.header())
+		}
+		rest := append(append([]string(nil), post...), lines[found+len(pre):]...)
+		lines = append(lines[:found], rest...)
+		offset += found - at + len(post) - len(pre)
+	}
+	return strings.Join(lines, ""), nil
+}
+
+// matchLines reports whether lines has want at line i.
+func matchLines(lines []string, i int, want []string) bool {
+	if i < 0 || i+len(want) > len(lines) {
+		return false
+	}
+	for j, l := range want {
+		if lines[i+j] != l {
+			return false
+		}
+	}
+	return true
+}
+
+// sides returns the lines of the hunk before and after the change,
+// with their line endings.
+func (h Hunk) sides() (old, new []string) {
+	for i, l := range h.Lines {
+		if l == "" || l[0] == '\\' {
+			continue
+		}
+		text := l[1:]
+		if i+1 == len(h.Lines) || !strings.HasPrefix(h.Lines[i+1], "\\") {
+			text += "\n"
+		}
+		switch l[0] {
+		case ' ':
+			old = append(old, text)
+			new = append(new, text)
+		case '-':
+			old = append(old, text)
+		case '+':
+			new = append(new, text)
+		}
+	}
+	return
+}
+
+func (h Hunk) header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+}
@@ -0,0 +1,539 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/utils/binary"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Repo is a git repository with a work tree.
+type Repo struct {
+	// Dir is the root of the work tree.
+	Dir  string
+	repo *git.Repository
+}
+
+// Commit is an entry of the history.
+type Commit struct {
+	Hash    string
+	Parents []string
+	Author  string
+	Time    time.Time
+	Subject string
+	// Refs are the branches and tags pointing to the commit, as printed
+	// by git log: "HEAD -> main", "origin/main", "tag: v1".
+	Refs []string
+}
+
+// FileStatus is the state of a changed file, in the letters of git
+// status: Index is the state of the staged changes, Work that of the
+// changes in the work tree, '?' for both if the file is untracked.
+type FileStatus struct {
+	Path        string
+	Index, Work byte
+}
+
+// Staged reports whether the file has staged changes.
+func (f FileStatus) Staged() bool {
+	return f.Index != ' ' && f.Index != '?'
+}
+
+// Unstaged reports whether the file has changes not staged.
+func (f FileStatus) Unstaged() bool {
+	return f.Work != ' '
+}
+
+// OpenRepo opens the repository containing dir.
+func OpenRepo(dir string) (*Repo, error) {
+	r, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, err
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	return &Repo{Dir: w.Filesystem.Root(), repo: r}, nil
+}
+
+// Branch returns the name of the current branch, or "HEAD" if no branch
+// is checked out.
+func (r *Repo) Branch() (string, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	if !head.Name().IsBranch() {
+		return "HEAD", nil
+	}
+	return head.Name().Short(), nil
+}
+
+// Log returns the last n commits of all branches, children before
+// parents.
+func (r *Repo) Log(n int) ([]Commit, error) {
+	refs, err := r.refs()
+	if err != nil {
+		return nil, err
+	}
+	iter, err := r.repo.Log(&git.LogOptions{All: true, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		// An empty repository has no history yet.
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer iter.Close()
+	var commits []Commit
+	err = iter.ForEach(func(c *object.Commit) error {
+		if len(commits) == n {
+			return storer.ErrStop
+		}
+		subject := c.Message
+		if i := strings.IndexByte(subject, '\n'); i != -1 {
+			subject = subject[:i]
+		}
+		cm := Commit{
+			Hash:    c.Hash.String(),
+			Author:  c.Author.Name,
+			Time:    c.Author.When,
+			Subject: subject,
+			Refs:    refs[c.Hash],
+		}
+		for _, p := range c.ParentHashes {
+			cm.Parents = append(cm.Parents, p.String())
+		}
+		commits = append(commits, cm)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return topoOrder(commits), nil
+}
+
+// refs maps commits to the names of the references pointing to them.
+func (r *Repo) refs() (map[plumbing.Hash][]string, error) {
+	head, err := r.repo.Head()
+	if err != nil && !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, err
+	}
+	refs := make(map[plumbing.Hash][]string)
+	if head != nil && !head.Name().IsBranch() {
+		refs[head.Hash()] = []string{"HEAD"}
+	}
+	iter, err := r.repo.References()
+	if err != nil {
+		return nil, err
+	}
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		name, hash := ref.Name(), ref.Hash()
+		var label string
+		switch {
+		case name.IsBranch():
+			label = name.Short()
+			if head != nil && head.Name() == name {
+				label = "HEAD -> " + label
+			}
+		case name.IsRemote():
+			label = name.Short()
+		case name.IsTag():
+			label = "tag: " + name.Short()
+			// Annotated tags point to a tag object.
+			if t, err := r.repo.TagObject(hash); err == nil {
+				hash = t.Target
+			}
+		default:
+			return nil
+		}
+		refs[hash] = append(refs[hash], label)
+		return nil
+	})
+	for _, labels := range refs {
+		sort.Slice(labels, func(i, j int) bool {
+			// The current branch goes first.
+			hi, hj := strings.HasPrefix(labels[i], "HEAD"), strings.HasPrefix(labels[j], "HEAD")
+			if hi != hj {
+				return hi
+			}
+			return labels[i] < labels[j]
+		})
+	}
+	return refs, err
+}
+
+// topoOrder reorders commits, newest first, so that children come before
+// their parents even when the clocks of their committers disagree.
+func topoOrder(commits []Commit) []Commit {
+	pos := make(map[string]int)
+	for i, c := range commits {
+		pos[c.Hash] = i
+	}
+	// children counts the children of each commit not yet ordered.
+	children := make([]int, len(commits))
+	for _, c := range commits {
+		for _, p := range c.Parents {
+			if i, ok := pos[p]; ok {
+				children[i]++
+			}
+		}
+	}
+	done := make([]bool, len(commits))
+	ordered := make([]Commit, 0, len(commits))
+	for len(ordered) < len(commits) {
+		for i, c := range commits {
+			if done[i] || children[i] > 0 {
+				continue
+			}
+			done[i] = true
+			ordered = append(ordered, c)
+			for _, p := range c.Parents {
+				if j, ok := pos[p]; ok {
+					children[j]--
+				}
+			}
+			break
+		}
+	}
+	return ordered
+}
+
+// Status returns the changed files.
+func (r *Repo) Status() ([]FileStatus, error) {
+	w, err := r.repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	st, err := w.Status()
+	if err != nil {
+		return nil, err
+	}
+	var files []FileStatus
+	for path, s := range st {
+		if s.Staging == git.Unmodified && s.Worktree == git.Unmodified {
+			continue
+		}
+		files = append(files, FileStatus{Path: path, Index: byte(s.Staging), Work: byte(s.Worktree)})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files, nil
+}
+
+// Diff returns the changes of a file in the work tree, or the staged
+// changes if staged is set, in the format of git diff.
+func (r *Repo) Diff(path string, staged bool) (string, error) {
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return "", err
+	}
+	e, err := idx.Entry(path)
+	if err != nil && err != index.ErrEntryNotFound {
+		return "", err
+	}
+	var from, to *diffFile
+	if staged {
+		if from, err = r.headFile(path); err != nil {
+			return "", err
+		}
+		if e != nil {
+			if to, err = r.blobFile(path, e.Hash, e.Mode); err != nil {
+				return "", err
+			}
+		}
+	} else {
+		// Like git diff, show no changes for untracked files.
+		if e == nil {
+			return "", nil
+		}
+		if from, err = r.blobFile(path, e.Hash, e.Mode); err != nil {
+			return "", err
+		}
+		if to, err = r.workFile(path); err != nil {
+			return "", err
+		}
+	}
+	if from == nil && to == nil || from != nil && to != nil && from.hash == to.hash {
+		return "", nil
+	}
+	var b strings.Builder
+	err = fdiff.NewUnifiedEncoder(&b, fdiff.DefaultContextLines).Encode(newFilePatch(from, to))
+	return b.String(), err
+}
+
+// Apply applies a patch of a file to the index, or removes it from the
+// index if reverse is set.
+func (r *Repo) Apply(path, patch string, reverse bool) error {
+	p, err := ParsePatch(patch)
+	if err != nil {
+		return err
+	}
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+	e, err := idx.Entry(path)
+	if err != nil {
+		return err
+	}
+	f, err := r.blobFile(path, e.Hash, e.Mode)
+	if err != nil {
+		return err
+	}
+	content, err := applyPatch(f.content, p, reverse)
+	if err != nil {
+		return err
+	}
+	if e.Hash, err = r.writeBlob(content); err != nil {
+		return err
+	}
+	e.Size = uint32(len(content))
+	// Clear the file time for git to compare the contents of the work
+	// tree file.
+	e.ModifiedAt = time.Time{}
+	return r.repo.Storer.SetIndex(idx)
+}
+
+// Add stages all changes of a file.
+func (r *Repo) Add(path string) error {
+	w, err := r.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	// Add stages deletions as well.
+	_, err = w.Add(path)
+	return err
+}
+
+// Reset unstages all changes of a file.
+func (r *Repo) Reset(path string) error {
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+	f, err := r.headFile(path)
+	if err != nil {
+		return err
+	}
+	if f == nil {
+		// The file is new.
+		if _, err := idx.Remove(path); err != nil && err != index.ErrEntryNotFound {
+			return err
+		}
+		return r.repo.Storer.SetIndex(idx)
+	}
+	e, err := idx.Entry(path)
+	switch {
+	case err == index.ErrEntryNotFound:
+		e = idx.Add(path)
+	case err != nil:
+		return err
+	}
+	e.Hash = f.hash
+	e.Mode = f.mode
+	e.Size = uint32(len(f.content))
+	e.ModifiedAt = time.Time{}
+	return r.repo.Storer.SetIndex(idx)
+}
+
+// Commit commits the staged changes as the user of the git
+// configuration.
+func (r *Repo) Commit(msg string) error {
+	w, err := r.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	_, err = w.Commit(msg, &git.CommitOptions{})
+	return err
+}
+
+// headFile returns a file of the current commit, or nil if the file or
+// the commit doesn't exist.
+func (r *Repo) headFile(path string) (*diffFile, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	c, err := r.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	f, err := c.File(path)
+	if err != nil {
+		if err == object.ErrFileNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	content, err := f.Contents()
+	if err != nil {
+		return nil, err
+	}
+	return &diffFile{path: path, mode: f.Mode, hash: f.Hash, content: content}, nil
+}
+
+func (r *Repo) blobFile(path string, h plumbing.Hash, mode filemode.FileMode) (*diffFile, error) {
+	b, err := r.repo.BlobObject(h)
+	if err != nil {
+		return nil, err
+	}
+	rd, err := b.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Close()
+	content, err := ioutil.ReadAll(rd)
+	if err != nil {
+		return nil, err
+	}
+	return &diffFile{path: path, mode: mode, hash: h, content: string(content)}, nil
+}
+
+// workFile returns a file of the work tree, or nil if it was deleted.
+func (r *Repo) workFile(path string) (*diffFile, error) {
+	name := filepath.Join(r.Dir, filepath.FromSlash(path))
+	fi, err := os.Lstat(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	mode, err := filemode.NewFromOSFileMode(fi.Mode())
+	if err != nil {
+		return nil, err
+	}
+	var content []byte
+	if fi.Mode()&os.ModeSymlink != 0 {
+		// Git stores the target of links.
+		target, err := os.Readlink(name)
+		if err != nil {
+			return nil, err
+		}
+		content = []byte(target)
+	} else if content, err = ioutil.ReadFile(name); err != nil {
+		return nil, err
+	}
+	h := plumbing.ComputeHash(plumbing.BlobObject, content)
+	return &diffFile{path: path, mode: mode, hash: h, content: string(content)}, nil
+}
+
+func (r *Repo) writeBlob(content string) (plumbing.Hash, error) {
+	obj := r.repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := io.WriteString(w, content); err != nil {
+		w.Close()
+		return plumbing.ZeroHash, err
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return r.repo.Storer.SetEncodedObject(obj)
+}
+
+// diffFile is a version of a file for the unified diff encoder.
+type diffFile struct {
+	path    string
+	mode    filemode.FileMode
+	hash    plumbing.Hash
+	content string
+}
+
+func (f *diffFile) Hash() plumbing.Hash     { return f.hash }
+func (f *diffFile) Mode() filemode.FileMode { return f.mode }
+func (f *diffFile) Path() string            { return f.path }
+
+// filePatch is the changes from one version of a file to another, nil
+// for a created or deleted file.
+type filePatch struct {
+	from, to *diffFile
+	chunks   []fdiff.Chunk
+	binary   bool
+}
+
+type chunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func newFilePatch(from, to *diffFile) *filePatch {
+	p := &filePatch{from: from, to: to}
+	var src, dst string
+	for _, f := range []*diffFile{from, to} {
+		if f == nil {
+			continue
+		}
+		if bin, _ := binary.IsBinary(strings.NewReader(f.content)); bin {
+			p.binary = true
+			return p
+		}
+	}
+	if from != nil {
+		src = from.content
+	}
+	if to != nil {
+		dst = to.content
+	}
+	for _, d := range diff.Do(src, dst) {
+		c := chunk{content: d.Text}
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			c.op = fdiff.Add
+		case diffmatchpatch.DiffDelete:
+			c.op = fdiff.Delete
+		default:
+			c.op = fdiff.Equal
+		}
+		p.chunks = append(p.chunks, c)
+	}
+	return p
+}
+
+func (p *filePatch) FilePatches() []fdiff.FilePatch { return []fdiff.FilePatch{p} }
+func (p *filePatch) Message() string                { return "" }
+func (p *filePatch) IsBinary() bool                 { return p.binary }
+func (p *filePatch) Chunks() []fdiff.Chunk          { return p.chunks }
+
+func (p *filePatch) Files() (from, to fdiff.File) {
+	// Leave missing files as nil interfaces.
+	if p.from != nil {
+		from = p.from
+	}
+	if p.to != nil {
+		to = p.to
+	}
+	return
+}
+
+func (c chunk) Content() string       { return c.content }
+func (c chunk) Type() fdiff.Operation { return c.op }
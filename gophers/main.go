@@ -3,9 +3,13 @@
 package main
 
 // A Gio program that displays Go contributors from GitHub. See https://gioui.org for more information.
+//
+// The -net-* flags simulate a slow or failing network; see
+// internal/netsim.
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"image"
@@ -16,6 +20,8 @@ import (
 	"golang.org/x/oauth2"
 
 	"gioui.org/app"
+	"gioui.org/example/internal/blurhash"
+	"gioui.org/example/internal/netsim"
 	"gioui.org/gesture"
 	"gioui.org/io/key"
 	"gioui.org/io/system"
@@ -36,12 +42,26 @@ type App struct {
 
 	ui *UI
 
-	updateUsers   chan []*user
-	commitsResult chan []*github.Commit
+	updateUsers   chan usersResult
+	userUpdates   chan func()
+	commitsResult chan commitsResult
 	ctx           context.Context
 	ctxCancel     context.CancelFunc
 }
 
+// usersResult is the outcome of fetching the contributors.
+type usersResult struct {
+	users []*user
+	err   error
+}
+
+// commitsResult is the outcome of fetching the commits of a user.
+type commitsResult struct {
+	login   string
+	commits []*github.Commit
+	err     error
+}
+
 var (
 	prof  = flag.Bool("profile", false, "serve profiling data at http://localhost:6060")
 	stats = flag.Bool("stats", false, "show rendering statistics")
@@ -50,6 +70,7 @@ var (
 
 func main() {
 	flag.Parse()
+	netsim.Install()
 	initProfiling()
 	if *token == "" {
 		fmt.Println("The quota for anonymous GitHub API access is very low. Specify a token with -token to avoid quota errors.")
@@ -82,13 +103,22 @@ func (a *App) run() error {
 	var ops op.Ops
 	for {
 		select {
-		case users := <-a.updateUsers:
-			a.ui.users = users
-			a.ui.userClicks = make([]gesture.Click, len(users))
+		case res := <-a.updateUsers:
+			a.ui.users, a.ui.usersErr = res.users, res.err
+			a.ui.userClicks = make([]gesture.Click, len(res.users))
 			a.w.Invalidate()
-		case commits := <-a.commitsResult:
-			a.ui.selectedUser.commits = commits
+		case update := <-a.userUpdates:
+			// User details arrive in the background, but are applied
+			// here to avoid racing with layout.
+			update()
 			a.w.Invalidate()
+		case res := <-a.commitsResult:
+			// The user may have gone back, or picked another user,
+			// while the commits loaded.
+			if up := a.ui.selectedUser; up != nil && up.user.login == res.login {
+				up.commits, up.commitsErr = res.commits, res.err
+				a.w.Invalidate()
+			}
 		case e := <-a.w.Events():
 			switch e := e.(type) {
 			case key.Event:
@@ -107,8 +137,15 @@ func (a *App) run() error {
 				if e.Stage >= system.StageRunning {
 					if a.ctxCancel == nil {
 						a.ctx, a.ctxCancel = context.WithCancel(context.Background())
+						// Load the commits again if pausing cancelled
+						// their fetch.
+						if up := a.ui.selectedUser; up != nil && up.commits == nil && up.commitsErr == nil {
+							a.fetchCommits(a.ctx, up.user.login)
+						}
 					}
 					if a.ui.users == nil {
+						// Retry after a failed fetch.
+						a.ui.usersErr = nil
 						go a.fetchContributors()
 					}
 				} else {
@@ -138,8 +175,9 @@ func (a *App) run() error {
 func newApp(w *app.Window) *App {
 	a := &App{
 		w:             w,
-		updateUsers:   make(chan []*user),
-		commitsResult: make(chan []*github.Commit, 1),
+		updateUsers:   make(chan usersResult),
+		userUpdates:   make(chan func()),
+		commitsResult: make(chan commitsResult, 1),
 	}
 	fetch := func(u string) {
 		a.fetchCommits(a.ctx, u)
@@ -162,11 +200,17 @@ func githubClient(ctx context.Context) *github.Client {
 func (a *App) fetchContributors() {
 	client := githubClient(a.ctx)
 	cons, _, err := client.Repositories.ListContributors(a.ctx, "golang", "go", nil)
+	if errors.Is(err, context.Canceled) {
+		// Paused; the contributors are fetched again on resume.
+		return
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "github: failed to fetch contributors: %v\n", err)
+		a.updateUsers <- usersResult{err: err}
 		return
 	}
-	var users []*user
+	hashes := loadHashCache()
+	users := []*user{}
 	userErrs := make(chan error, len(cons))
 	avatarErrs := make(chan error, len(cons))
 	for _, con := range cons {
@@ -178,6 +222,13 @@ func (a *App) fetchContributors() {
 		u := &user{
 			login: con.GetLogin(),
 		}
+		// Show a blurred preview from a previous run while the
+		// avatar downloads.
+		if h := hashes.Get(u.login); h != "" {
+			if img, err := blurhash.Decode(h, previewSize, previewSize, 1); err == nil {
+				u.preview = img
+			}
+		}
 		users = append(users, u)
 		go func() {
 			guser, _, err := client.Users.Get(a.ctx, u.login)
@@ -185,18 +236,27 @@ func (a *App) fetchContributors() {
 				avatarErrs <- err
 				return
 			}
-			u.name = guser.GetName()
-			u.company = guser.GetCompany()
+			name, company := guser.GetName(), guser.GetCompany()
+			a.userUpdates <- func() {
+				u.name = name
+				u.company = company
+			}
 			avatarErrs <- nil
 		}()
 		go func() {
-			a, err := fetchImage(avatar)
-			if a != nil {
-				u.avatar = a
+			img, err := fetchImage(avatar)
+			if img != nil {
+				if h, err := avatarHash(img); err == nil {
+					hashes.Put(u.login, h)
+				}
+				a.userUpdates <- func() {
+					u.avatar = img
+				}
 			}
 			userErrs <- err
 		}()
 	}
+	a.updateUsers <- usersResult{users: users}
 	for i := 0; i < len(cons); i++ {
 		if err := <-userErrs; err != nil {
 			fmt.Fprintf(os.Stderr, "github: failed to fetch user: %v\n", err)
@@ -205,13 +265,20 @@ func (a *App) fetchContributors() {
 			fmt.Fprintf(os.Stderr, "github: failed to fetch avatar: %v\n", err)
 		}
 	}
-	// Drop users with no avatar or name.
-	for i := len(users) - 1; i >= 0; i-- {
-		if u := users[i]; u.name == "" || u.avatar.Bounds().Size() == (image.Point{}) {
-			users = append(users[:i], users[i+1:]...)
+	if err := hashes.Save(); err != nil {
+		log.Printf("failed to save avatar previews: %v", err)
+	}
+	a.userUpdates <- func() {
+		// Drop users with no avatar or name.
+		users := a.ui.users
+		for i := len(users) - 1; i >= 0; i-- {
+			if u := users[i]; u.name == "" || u.avatar == nil || u.avatar.Bounds().Empty() {
+				users = append(users[:i], users[i+1:]...)
+			}
 		}
+		a.ui.users = users
+		a.ui.userClicks = make([]gesture.Click, len(users))
 	}
-	a.updateUsers <- users
 }
 
 func fetchImage(url string) (image.Image, error) {
@@ -233,16 +300,22 @@ func (a *App) fetchCommits(ctx context.Context, user string) {
 		repoCommits, _, err := gh.Repositories.ListCommits(ctx, "golang", "go", &github.CommitsListOptions{
 			Author: user,
 		})
+		if errors.Is(err, context.Canceled) {
+			// Paused; the commits are fetched again on resume.
+			return
+		}
 		if err != nil {
 			log.Printf("failed to fetch commits: %v", err)
+			a.commitsResult <- commitsResult{login: user, err: err}
 			return
 		}
-		var commits []*github.Commit
+		// An empty list, not nil, tells the page the commits loaded.
+		commits := []*github.Commit{}
 		for _, commit := range repoCommits {
 			if c := commit.GetCommit(); c != nil {
 				commits = append(commits, c)
 			}
 		}
-		a.commitsResult <- commits
+		a.commitsResult <- commitsResult{login: user, commits: commits}
 	}()
 }
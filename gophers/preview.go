@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"encoding/json"
+	"image"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/image/draw"
+
+	"gioui.org/example/internal/blurhash"
+)
+
+// previewSize is the size in pixels of decoded blurhash previews. The
+// previews are smooth, so they are scaled up without visible artifacts.
+const previewSize = 32
+
+// hashCache remembers the blurhash of every avatar between runs. A real
+// service would return the hash along with the image URL; GitHub doesn't,
+// so we compute and store it ourselves.
+type hashCache struct {
+	mu     sync.Mutex
+	path   string
+	hashes map[string]string
+}
+
+// loadHashCache loads the cache from the user cache directory. A missing
+// or corrupt cache results in an empty cache.
+func loadHashCache() *hashCache {
+	c := &hashCache{hashes: make(map[string]string)}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return c
+	}
+	c.path = filepath.Join(dir, "gio-gophers", "avatars.json")
+	if data, err := ioutil.ReadFile(c.path); err == nil {
+		json.Unmarshal(data, &c.hashes)
+	}
+	return c
+}
+
+// Get returns the hash for login, or the empty string.
+func (c *hashCache) Get(login string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hashes[login]
+}
+
+// Put records the hash for login.
+func (c *hashCache) Put(login, hash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hashes[login] = hash
+}
+
+// Save writes the cache to disk.
+func (c *hashCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(c.hashes)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, data, 0644)
+}
+
+// avatarHash computes the blurhash of img. The image is downscaled first,
+// because the hash only captures low frequencies anyway.
+func avatarHash(img image.Image) (string, error) {
+	small := image.NewRGBA(image.Rect(0, 0, previewSize, previewSize))
+	draw.ApproxBiLinear.Scale(small, small.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return blurhash.Encode(small, 4, 4)
+}
@@ -11,6 +11,7 @@ import (
 	"log"
 	"runtime"
 
+	"gioui.org/example/internal/shimmer"
 	"gioui.org/f32"
 	"gioui.org/font/gofont"
 	"gioui.org/gesture"
@@ -38,6 +39,7 @@ type UI struct {
 	fabIcon      *widget.Icon
 	usersList    *layout.List
 	users        []*user
+	usersErr     error
 	userClicks   []gesture.Click
 	selectedUser *userPage
 	edit, edit2  *widget.Editor
@@ -52,19 +54,30 @@ type UI struct {
 type userPage struct {
 	user        *user
 	commitsList *layout.List
-	commits     []*github.Commit
+	// commits is nil while they load, and commitsErr is the error of
+	// loading them.
+	commits    []*github.Commit
+	commitsErr error
 }
 
 type user struct {
-	name     string
-	login    string
-	company  string
-	avatar   image.Image
-	avatarOp paint.ImageOp
+	name    string
+	login   string
+	company string
+	avatar  image.Image
+	// preview is a blurred approximation of avatar, shown while
+	// avatar is loading.
+	preview image.Image
+
+	avatarOp  paint.ImageOp
+	avatarSrc image.Image
 }
 
 var theme *material.Theme
 
+// placeholder is used to draw skeleton rows while data is loading.
+var placeholder = shimmer.New()
+
 type (
 	C = layout.Context
 	D = layout.Dimensions
@@ -164,6 +177,19 @@ func (up *userPage) Layout(gtx layout.Context) {
 	if l.Dragging() {
 		key.SoftKeyboardOp{Show: false}.Add(gtx.Ops)
 	}
+	switch {
+	case up.commitsErr != nil:
+		layoutStatus(gtx, "Couldn't load the commits: "+up.commitsErr.Error(), true)
+		return
+	case up.commits == nil:
+		l.Layout(gtx, skeletonRows, func(gtx C, i int) D {
+			return layoutSkeletonRow(gtx, 3)
+		})
+		return
+	case len(up.commits) == 0:
+		layoutStatus(gtx, "No commits.", false)
+		return
+	}
 	l.Layout(gtx, len(up.commits), func(gtx C, i int) D {
 		return up.commit(gtx, i)
 	})
@@ -253,11 +279,52 @@ func (u *UI) layoutContributors(gtx layout.Context) layout.Dimensions {
 	if l.Dragging() {
 		key.SoftKeyboardOp{Show: false}.Add(gtx.Ops)
 	}
+	switch {
+	case u.usersErr != nil:
+		return layoutStatus(gtx, "Couldn't load the contributors: "+u.usersErr.Error(), true)
+	case u.users == nil:
+		return l.Layout(gtx, skeletonRows, func(gtx C, i int) D {
+			return layoutSkeletonRow(gtx, 2)
+		})
+	case len(u.users) == 0:
+		return layoutStatus(gtx, "No contributors.", false)
+	}
 	return l.Layout(gtx, len(u.users), func(gtx C, i int) D {
 		return u.user(gtx, i)
 	})
 }
 
+// layoutStatus lays out a row telling why there is nothing to list.
+func layoutStatus(gtx layout.Context, msg string, failed bool) layout.Dimensions {
+	lbl := material.Body2(theme, msg)
+	lbl.Color = rgb(0x888888)
+	if failed {
+		lbl.Color = rgb(0xd32f2f)
+	}
+	return layout.UniformInset(unit.Dp(16)).Layout(gtx, lbl.Layout)
+}
+
+// skeletonRows is the number of placeholder rows shown while loading.
+const skeletonRows = 8
+
+// layoutSkeletonRow lays out a placeholder resembling a user or commit row:
+// an avatar circle followed by the given number of text lines.
+func layoutSkeletonRow(gtx layout.Context, lines int) layout.Dimensions {
+	in := layout.UniformInset(unit.Dp(8))
+	return in.Layout(gtx, func(gtx C) D {
+		return centerRowOpts().Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return layout.Inset{Right: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+					return placeholder.Circle(gtx, unit.Dp(48))
+				})
+			}),
+			layout.Flexed(1, func(gtx C) D {
+				return placeholder.Lines(gtx, lines, unit.Dp(12))
+			}),
+		)
+	})
+}
+
 func (u *UI) user(gtx layout.Context, index int) layout.Dimensions {
 	user := u.users[index]
 	in := layout.UniformInset(unit.Dp(8))
@@ -279,7 +346,13 @@ func (u *UI) user(gtx layout.Context, index int) layout.Dimensions {
 				return column().Layout(gtx,
 					layout.Rigid(func(gtx C) D {
 						return baseline().Layout(gtx,
-							layout.Rigid(material.Body1(theme, user.name).Layout),
+							layout.Rigid(func(gtx C) D {
+								if user.name == "" {
+									gtx.Constraints.Max.X = gtx.Px(unit.Dp(120))
+									return placeholder.Line(gtx, unit.Dp(12), 1)
+								}
+								return material.Body1(theme, user.name).Layout(gtx)
+							}),
 							layout.Flexed(1, func(gtx C) D {
 								gtx.Constraints.Min.X = gtx.Constraints.Max.X
 								return layout.E.Layout(gtx, func(gtx C) D {
@@ -309,10 +382,18 @@ func (u *UI) user(gtx layout.Context, index int) layout.Dimensions {
 
 func (u *user) layoutAvatar(gtx layout.Context) layout.Dimensions {
 	sz := gtx.Constraints.Min.X
-	if u.avatarOp.Size().X != sz {
+	src := u.avatar
+	if src == nil {
+		src = u.preview
+	}
+	if src == nil {
+		return placeholder.Circle(gtx, unit.Px(float32(sz)))
+	}
+	if u.avatarOp.Size().X != sz || u.avatarSrc != src {
 		img := image.NewRGBA(image.Rectangle{Max: image.Point{X: sz, Y: sz}})
-		draw.ApproxBiLinear.Scale(img, img.Bounds(), u.avatar, u.avatar.Bounds(), draw.Src, nil)
+		draw.ApproxBiLinear.Scale(img, img.Bounds(), src, src.Bounds(), draw.Src, nil)
 		u.avatarOp = paint.NewImageOp(img)
+		u.avatarSrc = src
 	}
 	img := widget.Image{Src: u.avatarOp}
 	img.Scale = float32(sz) / float32(gtx.Px(unit.Dp(float32(sz))))
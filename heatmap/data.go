@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"math"
+	"time"
+
+	"gioui.org/example/internal/fakedata"
+	"gioui.org/example/internal/i18n"
+)
+
+// weeks is the number of weeks of the calendar.
+const weeks = 53
+
+// Calendar is a count of contributions per day, in weeks from the first
+// day of the week of the locale, ending with the week of today.
+type Calendar struct {
+	// Start is the first day of the first week.
+	Start time.Time
+	// Today is the last day counted.
+	Today  time.Time
+	Counts [weeks][7]int
+}
+
+// newCalendar makes up the contributions of the year up to today: more
+// on weekdays, with streaks of busy and quiet weeks. Weeks start on
+// first.
+func newCalendar(f *fakedata.Faker, today time.Time, first time.Weekday) *Calendar {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.Local)
+	offset := (int(today.Weekday()) - int(first) + 7) % 7
+	start := today.AddDate(0, 0, -offset-7*(weeks-1))
+	c := &Calendar{Start: start, Today: today}
+	busy := 0.5
+	for w := 0; w < weeks; w++ {
+		busy = math.Max(0.1, math.Min(1, busy+(f.Float64()-0.5)*0.4))
+		for d := 0; d < 7; d++ {
+			if !c.Valid(w, d) {
+				continue
+			}
+			p := busy
+			if wd := c.Day(w, d).Weekday(); wd == time.Saturday || wd == time.Sunday {
+				p /= 4
+			}
+			if f.Float64() < p {
+				c.Counts[w][d] = 1 + int(p*float64(f.Intn(12)))
+			}
+		}
+	}
+	return c
+}
+
+// Day returns the date of a day of a week, counted from the first day
+// of the week.
+func (c *Calendar) Day(week, day int) time.Time {
+	return c.Start.AddDate(0, 0, 7*week+day)
+}
+
+// Valid reports whether a day is counted, not after today.
+func (c *Calendar) Valid(week, day int) bool {
+	return !c.Day(week, day).After(c.Today)
+}
+
+// Max returns the largest count of a day.
+func (c *Calendar) Max() int {
+	max := 0
+	for _, w := range c.Counts {
+		for _, n := range w {
+			if n > max {
+				max = n
+			}
+		}
+	}
+	return max
+}
+
+// Total returns the count of all days.
+func (c *Calendar) Total() int {
+	total := 0
+	for _, w := range c.Counts {
+		for _, n := range w {
+			total += n
+		}
+	}
+	return total
+}
+
+// Weekday returns the weekday of a day of the weeks.
+func (c *Calendar) Weekday(day int) time.Weekday {
+	return c.Day(0, day).Weekday()
+}
+
+// MonthLabels returns the names of the months in a locale over the weeks
+// they start in, and empty strings elsewhere.
+func (c *Calendar) MonthLabels(l *i18n.Locale) []string {
+	labels := make([]string, weeks)
+	for w := range labels {
+		first := c.Day(w, 0)
+		if w == 0 || first.Month() != c.Day(w-1, 0).Month() {
+			labels[w] = l.Month(first.Month())
+		}
+	}
+	// The month started before the first week is cut short; leave it out
+	// unless it has room for its name.
+	if labels[1] != "" {
+		labels[0] = ""
+	}
+	return labels
+}
+
+// Metric is a series of samples of a measure.
+type Metric struct {
+	Name, Short string
+	Samples     []float64
+}
+
+// newMetrics makes up samples of server metrics that depend on the load
+// and on each other, so that some correlate and some don't.
+func newMetrics(f *fakedata.Faker, n int) []Metric {
+	noise := func(scale float64) float64 { return (f.Float64() - 0.5) * 2 * scale }
+	names := [][2]string{
+		{"Requests", "Req"}, {"CPU", "CPU"}, {"Memory", "Mem"}, {"Latency", "Lat"},
+		{"Errors", "Err"}, {"Cache hits", "Hit"}, {"Queue", "Que"}, {"Temperature", "Tmp"},
+	}
+	metrics := make([]Metric, len(names))
+	for i, nm := range names {
+		metrics[i] = Metric{Name: nm[0], Short: nm[1], Samples: make([]float64, n)}
+	}
+	load := 0.5
+	for i := 0; i < n; i++ {
+		load = math.Max(0, math.Min(1, load+noise(0.1)))
+		cpu := load + noise(0.1)
+		queue := math.Max(0, load-0.6) + noise(0.05)
+		latency := 0.2 + queue*2 + noise(0.1)
+		hits := 0.8 - latency/2 + noise(0.2)
+		values := []float64{
+			load,
+			cpu,
+			0.5 + noise(0.3), // Memory leaks slowly, unrelated to load.
+			latency,
+			latency*0.5 + noise(0.15),
+			hits,
+			queue,
+			cpu*0.6 + noise(0.2),
+		}
+		for j, v := range values {
+			metrics[j].Samples[i] = v
+		}
+	}
+	return metrics
+}
+
+// correlation returns the Pearson correlation coefficient of two series
+// of the same length, or 0 if either is constant.
+func correlation(a, b []float64) float64 {
+	n := float64(len(a))
+	var ma, mb float64
+	for i := range a {
+		ma += a[i]
+		mb += b[i]
+	}
+	ma, mb = ma/n, mb/n
+	var cov, va, vb float64
+	for i := range a {
+		da, db := a[i]-ma, b[i]-mb
+		cov += da * db
+		va += da * da
+		vb += db * db
+	}
+	if va == 0 || vb == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(va*vb)
+}
+
+// correlations returns the matrix of correlations of the metrics.
+func correlations(metrics []Metric) [][]float64 {
+	m := make([][]float64, len(metrics))
+	for i := range m {
+		m[i] = make([]float64, len(metrics))
+		for j := range m[i] {
+			m[i][j] = correlation(metrics[i].Samples, metrics[j].Samples)
+		}
+	}
+	return m
+}
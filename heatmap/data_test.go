@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"gioui.org/example/internal/fakedata"
+	"gioui.org/example/internal/i18n"
+)
+
+func TestCorrelation(t *testing.T) {
+	a := []float64{1, 2, 3, 4}
+	tests := []struct {
+		b    []float64
+		want float64
+	}{
+		{[]float64{2, 4, 6, 8}, 1},
+		{[]float64{4, 3, 2, 1}, -1},
+		{[]float64{1, -1, -1, 1}, 0},
+		{[]float64{5, 5, 5, 5}, 0},
+	}
+	for _, test := range tests {
+		if got := correlation(a, test.b); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("correlation(%v, %v) = %v, want %v", a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	today := time.Date(2026, time.March, 4, 15, 0, 0, 0, time.Local) // A Wednesday.
+	c := newCalendar(fakedata.New(1), today, time.Sunday)
+	if c.Start.Weekday() != time.Sunday {
+		t.Errorf("calendar starts on %v, want Sunday", c.Start.Weekday())
+	}
+	last := c.Day(weeks-1, 3)
+	if last.Year() != 2026 || last.Month() != time.March || last.Day() != 4 {
+		t.Errorf("Wednesday of the last week is %v, want today", last)
+	}
+	if !c.Valid(weeks-1, 3) || c.Valid(weeks-1, 4) {
+		t.Error("days after today are valid, or today is not")
+	}
+	for d := 4; d < 7; d++ {
+		if c.Counts[weeks-1][d] != 0 {
+			t.Errorf("contributions in the future, on day %d", d)
+		}
+	}
+	labels := c.MonthLabels(i18n.Find("en-US"))
+	months := 0
+	for _, l := range labels {
+		if l != "" {
+			months++
+		}
+	}
+	if months < 11 || months > 13 {
+		t.Errorf("%d month labels over a year: %q", months, labels)
+	}
+}
+
+func TestCalendarMonday(t *testing.T) {
+	today := time.Date(2026, time.March, 1, 15, 0, 0, 0, time.Local) // A Sunday.
+	c := newCalendar(fakedata.New(1), today, time.Monday)
+	if c.Start.Weekday() != time.Monday {
+		t.Errorf("calendar starts on %v, want Monday", c.Start.Weekday())
+	}
+	if got := c.Weekday(6); got != time.Sunday {
+		t.Errorf("last row is %v, want Sunday", got)
+	}
+	if !c.Valid(weeks-1, 6) {
+		t.Error("today, the last day of the week, is not valid")
+	}
+	// February starts in the week of Monday, February 2.
+	if l := c.MonthLabels(i18n.Find("de-DE")); l[weeks-4] != "Feb." {
+		t.Errorf("label of the first week of February is %q, want \"Feb.\"", l[weeks-4])
+	}
+}
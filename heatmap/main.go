@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates heatmaps: a calendar of contributions per day
+// over the last year and a correlation matrix of server metrics. Hover a
+// cell to see its value. Click a heatmap to focus it, move between its
+// cells with the arrow keys, Home and End, and press Tab to go to the
+// other heatmap.
+//
+// Dates and numbers are written the way the locale does, and the weeks
+// of the calendar start on the first day of the week of the locale.
+//
+// Usage:
+//
+//	go run ./heatmap [-locale de-DE]
+//
+// The locale defaults to the one of the environment, from LC_ALL or
+// LANG.
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"gioui.org/example/internal/fakedata"
+	"gioui.org/example/internal/heatmap"
+	"gioui.org/example/internal/i18n"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+// samples is the number of samples of the metrics.
+const samples = 500
+
+var localeFlag = flag.String("locale", "", "locale of dates and numbers, such as de-DE (default from the environment)")
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Heatmaps"),
+			app.Size(unit.Dp(1000), unit.Dp(800)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+type App struct {
+	locale  *i18n.Locale
+	cal     *Calendar
+	metrics []Metric
+	corr    [][]float64
+
+	calendar heatmap.Heatmap
+	matrix   heatmap.Heatmap
+	list     layout.List
+}
+
+func newApp(now time.Time, l *i18n.Locale) *App {
+	f := fakedata.New(1)
+	a := &App{
+		locale:  l,
+		cal:     newCalendar(f, now, l.FirstWeekday),
+		metrics: newMetrics(f, samples),
+	}
+	a.corr = correlations(a.metrics)
+
+	scale := heatmap.Greens
+	scale.Max = float64(a.cal.Max())
+	a.calendar = heatmap.Heatmap{
+		Rows:      7,
+		Cols:      weeks,
+		Scale:     scale,
+		RowLabels: a.weekdayLabels(),
+		ColLabels: a.cal.MonthLabels(l),
+		CellSize:  unit.Dp(14),
+		Gap:       unit.Dp(3),
+		Value: func(row, col int) (float64, bool) {
+			if !a.cal.Valid(col, row) {
+				return 0, false
+			}
+			return float64(a.cal.Counts[col][row]), true
+		},
+		Tooltip: func(row, col int) string {
+			n := a.cal.Counts[col][row]
+			day := l.LongDate(a.cal.Day(col, row))
+			switch n {
+			case 0:
+				return "No contributions on " + day
+			case 1:
+				return "1 contribution on " + day
+			}
+			return fmt.Sprintf("%s contributions on %s", l.Int(n), day)
+		},
+	}
+	var names, shorts []string
+	for _, m := range a.metrics {
+		names = append(names, m.Name)
+		shorts = append(shorts, m.Short)
+	}
+	a.matrix = heatmap.Heatmap{
+		Rows:      len(a.metrics),
+		Cols:      len(a.metrics),
+		Scale:     heatmap.Diverging,
+		RowLabels: names,
+		ColLabels: shorts,
+		CellSize:  unit.Dp(44),
+		Gap:       unit.Dp(2),
+		Value: func(row, col int) (float64, bool) {
+			return a.corr[row][col], true
+		},
+		Tooltip: func(row, col int) string {
+			return fmt.Sprintf("%s and %s: r = %s", a.metrics[row].Name, a.metrics[col].Name, l.Number(a.corr[row][col], 2))
+		},
+	}
+	// Start at today.
+	a.calendar.Row, a.calendar.Col = (int(a.cal.Today.Weekday())-int(l.FirstWeekday)+7)%7, weeks-1
+	a.calendar.Focus()
+	return a
+}
+
+// weekdayLabels labels every other row of the calendar with the name of
+// its weekday, starting with the second.
+func (a *App) weekdayLabels() []string {
+	labels := make([]string, 7)
+	for d := 1; d < 7; d += 2 {
+		labels[d] = a.locale.Weekday(a.cal.Weekday(d))
+	}
+	return labels
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	l := i18n.FromEnv()
+	if *localeFlag != "" {
+		l = i18n.Find(*localeFlag)
+	}
+	a := newApp(time.Now(), l)
+	a.list.Axis = layout.Vertical
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			a.update()
+			a.layout(gtx, th)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+// update moves the focus between the heatmaps on Tab. With two of them,
+// forwards and backwards are the same.
+func (a *App) update() {
+	if _, ok := a.calendar.Tabbed(); ok {
+		a.matrix.Focus()
+	}
+	if _, ok := a.matrix.Tabbed(); ok {
+		a.calendar.Focus()
+	}
+}
+
+func (a *App) layout(gtx C, th *material.Theme) D {
+	sections := []layout.Widget{
+		func(gtx C) D {
+			title := fmt.Sprintf("%s contributions in the last year", a.locale.Int(a.cal.Total()))
+			return a.section(gtx, th, title, &a.calendar, heatmap.Legend{Scale: a.calendar.Scale, Low: "Less", High: "More"})
+		},
+		func(gtx C) D {
+			title := fmt.Sprintf("Correlation of server metrics over %s samples", a.locale.Int(samples))
+			return a.section(gtx, th, title, &a.matrix, heatmap.Legend{Scale: a.matrix.Scale, Low: "-1", High: "1"})
+		},
+	}
+	return a.list.Layout(gtx, len(sections), func(gtx C, i int) D {
+		return layout.UniformInset(unit.Dp(24)).Layout(gtx, sections[i])
+	})
+}
+
+func (a *App) section(gtx C, th *material.Theme, title string, h *heatmap.Heatmap, legend heatmap.Legend) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(material.H6(th, title).Layout),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+		layout.Rigid(func(gtx C) D {
+			return h.Layout(gtx, th)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+		layout.Rigid(func(gtx C) D {
+			return legend.Layout(gtx, th)
+		}),
+	)
+}
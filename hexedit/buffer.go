@@ -0,0 +1,223 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"io"
+	"sort"
+)
+
+const (
+	pageSize = 4096
+	// maxPages limits the pages cached.
+	maxPages = 256
+)
+
+// Buffer is a view of the bytes of a file with edits on top. Only the
+// pages read are loaded, and edits are kept in memory until saved.
+type Buffer struct {
+	r    io.ReaderAt
+	size int64
+	// pages caches the pages read, oldest first in order.
+	pages map[int64][]byte
+	order []int64
+	err   error
+
+	edits      map[int64]byte
+	undo, redo []edit
+}
+
+// edit is a change of a byte.
+type edit struct {
+	off      int64
+	old, new byte
+}
+
+// NewBuffer returns a buffer of the size bytes of r.
+func NewBuffer(r io.ReaderAt, size int64) *Buffer {
+	return &Buffer{
+		r:     r,
+		size:  size,
+		pages: make(map[int64][]byte),
+		edits: make(map[int64]byte),
+	}
+}
+
+// Size returns the number of bytes.
+func (b *Buffer) Size() int64 {
+	return b.size
+}
+
+// Err returns the first read error, if any.
+func (b *Buffer) Err() error {
+	return b.err
+}
+
+func (b *Buffer) page(n int64) []byte {
+	if p, ok := b.pages[n]; ok {
+		return p
+	}
+	p := make([]byte, pageSize)
+	m, err := b.r.ReadAt(p, n*pageSize)
+	if err != nil && err != io.EOF && b.err == nil {
+		b.err = err
+	}
+	p = p[:m]
+	if len(b.order) >= maxPages {
+		delete(b.pages, b.order[0])
+		b.order = b.order[1:]
+	}
+	b.pages[n] = p
+	b.order = append(b.order, n)
+	return p
+}
+
+// original returns the byte at off as in the file.
+func (b *Buffer) original(off int64) byte {
+	p := b.page(off / pageSize)
+	if i := off % pageSize; i < int64(len(p)) {
+		return p[i]
+	}
+	return 0
+}
+
+// ByteAt returns the byte at off.
+func (b *Buffer) ByteAt(off int64) byte {
+	if v, ok := b.edits[off]; ok {
+		return v
+	}
+	return b.original(off)
+}
+
+// ReadAt reads the bytes from off into p, and returns the number read.
+func (b *Buffer) ReadAt(p []byte, off int64) (int, error) {
+	if off >= b.size {
+		return 0, io.EOF
+	}
+	n := 0
+	for n < len(p) && off < b.size {
+		pg := b.page(off / pageSize)
+		i := off % pageSize
+		if i >= int64(len(pg)) {
+			break
+		}
+		c := copy(p[n:], pg[i:])
+		n += c
+		off += int64(c)
+	}
+	if len(b.edits) > 0 {
+		start := off - int64(n)
+		for i := 0; i < n; i++ {
+			if v, ok := b.edits[start+int64(i)]; ok {
+				p[i] = v
+			}
+		}
+	}
+	var err error
+	if n < len(p) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// Edited reports whether the byte at off has unsaved changes.
+func (b *Buffer) Edited(off int64) bool {
+	_, ok := b.edits[off]
+	return ok
+}
+
+// Modified reports whether there are unsaved changes.
+func (b *Buffer) Modified() bool {
+	return len(b.edits) > 0
+}
+
+// Set changes the byte at off to v.
+func (b *Buffer) Set(off int64, v byte) {
+	old := b.ByteAt(off)
+	if old == v {
+		return
+	}
+	b.apply(off, v)
+	b.undo = append(b.undo, edit{off: off, old: old, new: v})
+	b.redo = b.redo[:0]
+}
+
+func (b *Buffer) apply(off int64, v byte) {
+	if v == b.original(off) {
+		delete(b.edits, off)
+	} else {
+		b.edits[off] = v
+	}
+}
+
+// Undo reverts the last change, and returns its offset.
+func (b *Buffer) Undo() (int64, bool) {
+	n := len(b.undo)
+	if n == 0 {
+		return 0, false
+	}
+	e := b.undo[n-1]
+	b.undo = b.undo[:n-1]
+	b.apply(e.off, e.old)
+	b.redo = append(b.redo, e)
+	return e.off, true
+}
+
+// Redo repeats the last change undone, and returns its offset.
+func (b *Buffer) Redo() (int64, bool) {
+	n := len(b.redo)
+	if n == 0 {
+		return 0, false
+	}
+	e := b.redo[n-1]
+	b.redo = b.redo[:n-1]
+	b.apply(e.off, e.new)
+	b.undo = append(b.undo, e)
+	return e.off, true
+}
+
+// Snapshot returns a reader of the current bytes that is independent of
+// later changes and safe to use from another goroutine, if the reader of
+// the buffer is.
+func (b *Buffer) Snapshot() io.ReaderAt {
+	edits := make(map[int64]byte, len(b.edits))
+	for off, v := range b.edits {
+		edits[off] = v
+	}
+	return &snapshot{r: b.r, edits: edits}
+}
+
+type snapshot struct {
+	r     io.ReaderAt
+	edits map[int64]byte
+}
+
+func (s *snapshot) ReadAt(p []byte, off int64) (int, error) {
+	n, err := s.r.ReadAt(p, off)
+	for i := 0; i < n && len(s.edits) > 0; i++ {
+		if v, ok := s.edits[off+int64(i)]; ok {
+			p[i] = v
+		}
+	}
+	return n, err
+}
+
+// Save writes the changes to w, which must write to the file read.
+func (b *Buffer) Save(w io.WriterAt) error {
+	offs := make([]int64, 0, len(b.edits))
+	for off := range b.edits {
+		offs = append(offs, off)
+	}
+	sort.Slice(offs, func(i, j int) bool { return offs[i] < offs[j] })
+	for _, off := range offs {
+		if _, err := w.WriteAt([]byte{b.edits[off]}, off); err != nil {
+			return err
+		}
+		// Update the cache to the saved byte.
+		if p, ok := b.pages[off/pageSize]; ok {
+			p[off%pageSize] = b.edits[off]
+		}
+		delete(b.edits, off)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+// memFile is an in-memory file.
+type memFile []byte
+
+func (m memFile) ReadAt(p []byte, off int64) (int, error) {
+	return bytes.NewReader(m).ReadAt(p, off)
+}
+
+func (m memFile) WriteAt(p []byte, off int64) (int, error) {
+	return copy(m[off:], p), nil
+}
+
+func TestBufferEdits(t *testing.T) {
+	data := make(memFile, 3*pageSize+10)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	b := NewBuffer(data, int64(len(data)))
+	b.Set(pageSize-1, 0xaa)
+	b.Set(pageSize, 0xbb)
+	p := make([]byte, 4)
+	if n, _ := b.ReadAt(p, pageSize-2); n != 4 || !bytes.Equal(p, []byte{0xfe, 0xaa, 0xbb, 0x01}) {
+		t.Errorf("read %x across pages, want feaabb01", p[:n])
+	}
+	if off, ok := b.Undo(); !ok || off != pageSize {
+		t.Errorf("undo returned %d, %v", off, ok)
+	}
+	if b.Edited(pageSize) || !b.Edited(pageSize-1) {
+		t.Error("undo didn't revert the last edit")
+	}
+	b.Redo()
+	if v := b.ByteAt(pageSize); v != 0xbb {
+		t.Errorf("redo: got %x, want bb", v)
+	}
+	// Setting a byte back to its original value clears the edit.
+	b.Set(pageSize, 0x00)
+	if b.Edited(pageSize) {
+		t.Error("edit back to the original byte is kept")
+	}
+	snap := b.Snapshot()
+	if err := b.Save(data); err != nil {
+		t.Fatal(err)
+	}
+	if b.Modified() || data[pageSize-1] != 0xaa {
+		t.Errorf("save: modified %v, byte %x", b.Modified(), data[pageSize-1])
+	}
+	b.Set(0, 0x11)
+	q := make([]byte, 1)
+	snap.ReadAt(q, 0)
+	if q[0] != 0 {
+		t.Error("snapshot sees later edits")
+	}
+	if n, _ := b.ReadAt(p, int64(len(data))-2); n != 2 {
+		t.Errorf("read %d bytes at the end, want 2", n)
+	}
+}
+
+func TestFind(t *testing.T) {
+	data := make(memFile, 3*searchChunk)
+	copy(data[searchChunk-2:], "PNG")
+	copy(data[10:], "PNG")
+	ctx := context.Background()
+	size := int64(len(data))
+	tests := []struct {
+		from int64
+		want int64
+	}{
+		{0, 10},
+		{11, searchChunk - 2},
+		// Wrap around.
+		{searchChunk, 10},
+	}
+	for _, test := range tests {
+		off, ok := Find(ctx, data, size, []byte("PNG"), test.from)
+		if !ok || off != test.want {
+			t.Errorf("Find from %d = %d, %v; want %d", test.from, off, ok, test.want)
+		}
+	}
+	if _, ok := Find(ctx, data, size, []byte("JPG"), 0); ok {
+		t.Error("found missing pattern")
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		q    string
+		want []byte
+		ok   bool
+	}{
+		{"ff d8 FF", []byte{0xff, 0xd8, 0xff}, true},
+		{"cafe", []byte{0xca, 0xfe}, true},
+		{`"GIF8"`, []byte("GIF8"), true},
+		{"abc", nil, false},
+		{"zz", nil, false},
+		{"", nil, false},
+	}
+	for _, test := range tests {
+		got, err := parseQuery(test.q)
+		if (err == nil) != test.ok || !bytes.Equal(got, test.want) {
+			t.Errorf("parseQuery(%q) = %x, %v", test.q, got, err)
+		}
+	}
+}
+
+func TestHitCol(t *testing.T) {
+	for _, p := range []pane{hexPane, textPane} {
+		for i := 0; i < bytesPerRow; i++ {
+			col := byteCol(p, i)
+			if gp, gi, ok := hitCol(col); !ok || gp != p || gi != i {
+				t.Errorf("hitCol(byteCol(%d, %d)) = %d, %d, %v", p, i, gp, gi, ok)
+			}
+		}
+	}
+	if _, _, ok := hitCol(0); ok {
+		t.Error("hit in the offset column")
+	}
+}
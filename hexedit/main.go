@@ -0,0 +1,295 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program views and edits binary files of any size in hex. Only the
+// rows on screen are read from disk. Select bytes by dragging in either
+// the hex or the text column, and type over them in the column with the
+// cursor; Tab switches columns. Edits stay in memory until saved, and
+// can be undone with Ctrl-Z. Searches take hex bytes or quoted text.
+//
+// Usage:
+//
+//	go run ./hexedit file.bin
+
+import (
+	"context"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/style"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: hexedit <file>")
+		os.Exit(2)
+	}
+	name := flag.Arg(0)
+	f, err := os.Open(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("Hex Editor"),
+			app.Size(unit.Dp(900), unit.Dp(700)),
+		)
+		if err := loop(w, name, NewBuffer(f, fi.Size())); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var (
+	monoFont   = text.Font{Variant: "Mono"}
+	errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+)
+
+type found struct {
+	off int64
+	ok  bool
+}
+
+type App struct {
+	name   string
+	view   HexView
+	status string
+	err    error
+	// search is the pattern searched for, and cancel stops the search
+	// in progress.
+	search  []byte
+	cancel  context.CancelFunc
+	results chan found
+
+	searchEd, gotoEd       widget.Editor
+	find, undo, redo, save widget.Clickable
+}
+
+func loop(w *app.Window, name string, buf *Buffer) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		name:     name,
+		view:     HexView{Buf: buf},
+		results:  make(chan found, 1),
+		searchEd: widget.Editor{SingleLine: true, Submit: true},
+		gotoEd:   widget.Editor{SingleLine: true, Submit: true},
+	}
+	a.view.Focus()
+	var ops op.Ops
+	for {
+		select {
+		case r := <-a.results:
+			a.cancel = nil
+			if r.ok {
+				a.view.Select(r.off, int64(len(a.search)))
+				a.status = fmt.Sprintf("Found at 0x%X.", r.off)
+			} else {
+				a.err = fmt.Errorf("%X not found", a.search)
+			}
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				if a.cancel != nil {
+					a.cancel()
+				}
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update()
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+func (a *App) update() {
+	v := &a.view
+	submitted := func(ed *widget.Editor) bool {
+		s := false
+		for _, e := range ed.Events() {
+			if _, ok := e.(widget.SubmitEvent); ok {
+				s = true
+			}
+		}
+		return s
+	}
+	find := submitted(&a.searchEd)
+	for a.find.Clicked() {
+		find = true
+	}
+	if find {
+		a.startSearch()
+	}
+	if submitted(&a.gotoEd) {
+		a.err = nil
+		s := strings.TrimSpace(a.gotoEd.Text())
+		off, err := strconv.ParseInt(s, 0, 64)
+		if err != nil || off < 0 || off >= v.Buf.Size() {
+			a.err = fmt.Errorf("%q is not an offset in the file; use 0x for hex", s)
+		} else {
+			v.SetCursor(off, false)
+			v.Focus()
+		}
+	}
+	for a.undo.Clicked() {
+		if off, ok := v.Buf.Undo(); ok {
+			v.SetCursor(off, false)
+		}
+	}
+	for a.redo.Clicked() {
+		if off, ok := v.Buf.Redo(); ok {
+			v.SetCursor(off, false)
+		}
+	}
+	for a.save.Clicked() {
+		a.err = a.saveFile()
+		if a.err == nil {
+			a.status = "Saved."
+		}
+	}
+}
+
+// startSearch searches for the query from after the cursor, in the
+// background.
+func (a *App) startSearch() {
+	if a.cancel != nil {
+		a.cancel()
+		a.cancel = nil
+	}
+	a.err = nil
+	a.status = ""
+	pat, err := parseQuery(a.searchEd.Text())
+	if err != nil {
+		a.err = err
+		return
+	}
+	a.search = pat
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	a.status = "Searching…"
+	buf := a.view.Buf
+	r, size, from := buf.Snapshot(), buf.Size(), a.view.Cursor()+1
+	go func() {
+		off, ok := Find(ctx, r, size, pat, from)
+		if ctx.Err() == nil {
+			a.results <- found{off, ok}
+		}
+	}()
+}
+
+func (a *App) saveFile() error {
+	f, err := os.OpenFile(a.name, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	if err := a.view.Buf.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(2, func(gtx C) D {
+						return style.Field(th, &a.searchEd, `Search hex (FF D8) or "text"`).Layout(gtx)
+					}),
+					layout.Rigid(style.TextButton(th, &a.find, "Find next")),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Flexed(1, func(gtx C) D {
+						return style.Field(th, &a.gotoEd, "Go to offset").Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(style.TextButton(th, &a.undo, "Undo")),
+					layout.Rigid(style.TextButton(th, &a.redo, "Redo")),
+					layout.Rigid(func(gtx C) D {
+						if !a.view.Buf.Modified() {
+							gtx = gtx.Disabled()
+						}
+						return material.Button(th, &a.save, "Save").Layout(gtx)
+					}),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Inset{Left: unit.Dp(8), Right: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+				return a.view.Layout(gtx, th)
+			})
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, a.layoutStatus(th))
+		}),
+	)
+}
+
+func (a *App) layoutStatus(th *material.Theme) layout.Widget {
+	v := &a.view
+	l := material.Caption(th, "")
+	switch {
+	case a.err != nil:
+		l.Text = a.err.Error()
+		l.Color = errorColor
+	case v.Buf.Err() != nil:
+		l.Text = v.Buf.Err().Error()
+		l.Color = errorColor
+	case v.Buf.Size() == 0:
+		l.Text = "The file is empty."
+	default:
+		off := v.Cursor()
+		start, end := v.Selection()
+		var b [4]byte
+		n, _ := v.Buf.ReadAt(b[:], off)
+		s := fmt.Sprintf("Offset 0x%X (%d) of %d", off, off, v.Buf.Size())
+		if end-start > 1 {
+			s += fmt.Sprintf(" · %d bytes selected", end-start)
+		}
+		s += fmt.Sprintf(" · u8 %d", b[0])
+		if n >= 2 {
+			s += fmt.Sprintf(" · u16 %d", binary.LittleEndian.Uint16(b[:]))
+		}
+		if n >= 4 {
+			s += fmt.Sprintf(" · u32 %d", binary.LittleEndian.Uint32(b[:]))
+		}
+		if v.Buf.Modified() {
+			s += " · modified"
+		}
+		if a.status != "" {
+			s += " · " + a.status
+		}
+		l.Text = s
+	}
+	return l.Layout
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+)
+
+// parseQuery parses a search for bytes. A query in quotes is text;
+// otherwise it is hex digits, optionally separated by spaces.
+func parseQuery(q string) ([]byte, error) {
+	q = strings.TrimSpace(q)
+	if len(q) >= 2 && q[0] == '"' && q[len(q)-1] == '"' {
+		q = q[1 : len(q)-1]
+		if q == "" {
+			return nil, errors.New("empty search")
+		}
+		return []byte(q), nil
+	}
+	digits := strings.Join(strings.Fields(q), "")
+	if digits == "" {
+		return nil, errors.New("empty search")
+	}
+	if len(digits)%2 == 1 {
+		return nil, errors.New("odd number of hex digits")
+	}
+	b, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil, errors.New(`not hex digits; quote text like "PNG"`)
+	}
+	return b, nil
+}
+
+// searchChunk is the number of bytes searched at a time.
+const searchChunk = 1 << 16
+
+// Find returns the offset of the first occurrence of pat at or after from,
+// wrapping around to the start.
+func Find(ctx context.Context, r io.ReaderAt, size int64, pat []byte, from int64) (int64, bool) {
+	if off, ok := find(ctx, r, pat, from, size); ok {
+		return off, true
+	}
+	// Wrap around, to just past the start.
+	end := from + int64(len(pat)) - 1
+	if end > size {
+		end = size
+	}
+	return find(ctx, r, pat, 0, end)
+}
+
+// find searches [from, end).
+func find(ctx context.Context, r io.ReaderAt, pat []byte, from, end int64) (int64, bool) {
+	if len(pat) == 0 {
+		return 0, false
+	}
+	// Overlap chunks to find matches across them.
+	buf := make([]byte, searchChunk+len(pat)-1)
+	for off := from; off < end; off += searchChunk {
+		if ctx.Err() != nil {
+			return 0, false
+		}
+		n, _ := r.ReadAt(buf, off)
+		chunk := buf[:n]
+		if max := end - off; int64(len(chunk)) > max {
+			chunk = chunk[:max]
+		}
+		if i := bytes.Index(chunk, pat); i >= 0 {
+			return off + int64(i), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,453 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"gioui.org/f32"
+	"gioui.org/gesture"
+	"gioui.org/io/clipboard"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/widget/material"
+)
+
+// pane is a column of bytes in a HexView.
+type pane int
+
+const (
+	hexPane pane = iota
+	textPane
+)
+
+const bytesPerRow = 16
+
+// The columns of a row, in characters: the offset, the bytes in hex with
+// a gap in the middle, and the bytes as text.
+const (
+	hexCol  = 10
+	textCol = hexCol + 3*bytesPerRow + 2
+	rowCols = textCol + bytesPerRow
+)
+
+// byteCol returns the column of byte i of a row in a pane.
+func byteCol(p pane, i int) int {
+	if p == textPane {
+		return textCol + i
+	}
+	c := hexCol + 3*i
+	if i >= bytesPerRow/2 {
+		c++
+	}
+	return c
+}
+
+// hitCol returns the pane and byte of a row at a column.
+func hitCol(col int) (pane, int, bool) {
+	switch {
+	case col >= textCol && col < textCol+bytesPerRow:
+		return textPane, col - textCol, true
+	case col >= hexCol && col < textCol-1:
+		c := col - hexCol
+		if c >= 3*bytesPerRow/2 {
+			c--
+		}
+		i := c / 3
+		if i >= bytesPerRow {
+			i = bytesPerRow - 1
+		}
+		return hexPane, i, true
+	}
+	return 0, 0, false
+}
+
+// printable returns the text column character of a byte.
+func printable(b byte) byte {
+	if b < 0x20 || b > 0x7e {
+		return '.'
+	}
+	return b
+}
+
+var (
+	selectionColor = color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0x40}
+	cursorColor    = color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0x90}
+	editedColor    = color.NRGBA{R: 0xff, G: 0x98, B: 0x00, A: 0x50}
+	offsetColor    = color.NRGBA{A: 0x80}
+)
+
+// HexView shows and edits the bytes of a Buffer as rows of hex digits
+// and text. Only the rows on screen are read and drawn.
+type HexView struct {
+	Buf *Buffer
+
+	// cursor is the byte edited, and anchor the other end of the
+	// selection.
+	cursor, anchor int64
+	pane           pane
+	// nibble is 1 after the high digit of the cursor byte is typed.
+	nibble int
+	// top is the first row shown.
+	top      int64
+	scroll   gesture.Scroll
+	scrollPx int
+	dragging bool
+	focus    bool
+	focused  bool
+	// rows is the number of rows shown in the last layout.
+	rows int
+	// char is the size of a character.
+	char image.Point
+}
+
+// Cursor returns the offset of the cursor.
+func (v *HexView) Cursor() int64 {
+	return v.cursor
+}
+
+// Selection returns the range of selected bytes, including the cursor.
+func (v *HexView) Selection() (start, end int64) {
+	start, end = v.anchor, v.cursor
+	if start > end {
+		start, end = end, start
+	}
+	end++
+	if size := v.Buf.Size(); end > size {
+		end = size
+	}
+	return start, end
+}
+
+// SetCursor moves the cursor to off and scrolls it into view. The
+// selection is extended if extend is set.
+func (v *HexView) SetCursor(off int64, extend bool) {
+	if size := v.Buf.Size(); off >= size {
+		off = size - 1
+	}
+	if off < 0 {
+		off = 0
+	}
+	v.cursor = off
+	if !extend {
+		v.anchor = off
+	}
+	v.nibble = 0
+	row := off / bytesPerRow
+	switch {
+	case row < v.top:
+		v.top = row
+	case v.rows > 0 && row >= v.top+int64(v.rows):
+		v.top = row - int64(v.rows) + 1
+	}
+}
+
+// Select selects n bytes from off, with the cursor at the start.
+func (v *HexView) Select(off, n int64) {
+	v.SetCursor(off+n-1, false)
+	v.SetCursor(off, false)
+	v.anchor = off + n - 1
+}
+
+// Focus requests the keyboard focus.
+func (v *HexView) Focus() {
+	v.focus = true
+}
+
+func (v *HexView) totalRows() int64 {
+	return (v.Buf.Size() + bytesPerRow - 1) / bytesPerRow
+}
+
+func (v *HexView) hit(pos f32.Point) (pane, int64, bool) {
+	if v.char.X == 0 || v.char.Y == 0 {
+		return 0, 0, false
+	}
+	row := v.top + int64(pos.Y)/int64(v.char.Y)
+	p, i, ok := hitCol(int(pos.X) / v.char.X)
+	if !ok {
+		return 0, 0, false
+	}
+	return p, row*bytesPerRow + int64(i), true
+}
+
+func (v *HexView) update(gtx layout.Context) {
+	for _, e := range gtx.Events(v) {
+		switch e := e.(type) {
+		case key.FocusEvent:
+			v.focused = e.Focus
+		case pointer.Event:
+			switch e.Type {
+			case pointer.Press:
+				v.focus = true
+				if p, off, ok := v.hit(e.Position); ok {
+					v.pane = p
+					v.SetCursor(off, e.Modifiers.Contain(key.ModShift))
+					v.dragging = true
+				}
+			case pointer.Drag:
+				if !v.dragging {
+					break
+				}
+				// Keep the pane of the press while dragging.
+				pos := e.Position
+				if pos.Y < 0 {
+					pos.Y = 0
+				}
+				if _, off, ok := v.hit(pos); ok {
+					v.SetCursor(off, true)
+				}
+			case pointer.Release, pointer.Cancel:
+				v.dragging = false
+			}
+		case key.EditEvent:
+			v.typeText(e.Text)
+		case key.Event:
+			if e.State == key.Press {
+				v.key(gtx, e)
+			}
+		}
+	}
+	if d := v.scroll.Scroll(gtx.Metric, gtx, gtx.Now, gesture.Vertical); d != 0 && v.char.Y > 0 {
+		v.scrollPx += d
+		rows := v.scrollPx / v.char.Y
+		v.scrollPx -= rows * v.char.Y
+		v.top += int64(rows)
+	}
+	if max := v.totalRows() - int64(v.rows) + 1; v.top > max {
+		v.top = max
+	}
+	if v.top < 0 {
+		v.top = 0
+	}
+}
+
+func (v *HexView) key(gtx layout.Context, e key.Event) {
+	shift := e.Modifiers.Contain(key.ModShift)
+	page := int64(v.rows-1) * bytesPerRow
+	if page < bytesPerRow {
+		page = bytesPerRow
+	}
+	switch e.Name {
+	case key.NameLeftArrow:
+		v.SetCursor(v.cursor-1, shift)
+	case key.NameRightArrow:
+		v.SetCursor(v.cursor+1, shift)
+	case key.NameUpArrow:
+		if v.cursor >= bytesPerRow {
+			v.SetCursor(v.cursor-bytesPerRow, shift)
+		}
+	case key.NameDownArrow:
+		if v.cursor+bytesPerRow < v.Buf.Size() {
+			v.SetCursor(v.cursor+bytesPerRow, shift)
+		}
+	case key.NamePageUp:
+		v.top -= page / bytesPerRow
+		v.SetCursor(v.cursor-page, shift)
+	case key.NamePageDown:
+		v.top += page / bytesPerRow
+		v.SetCursor(v.cursor+page, shift)
+	case key.NameHome:
+		if e.Modifiers.Contain(key.ModShortcut) {
+			v.SetCursor(0, shift)
+		} else {
+			v.SetCursor(v.cursor-v.cursor%bytesPerRow, shift)
+		}
+	case key.NameEnd:
+		if e.Modifiers.Contain(key.ModShortcut) {
+			v.SetCursor(v.Buf.Size()-1, shift)
+		} else {
+			v.SetCursor(v.cursor-v.cursor%bytesPerRow+bytesPerRow-1, shift)
+		}
+	case key.NameTab:
+		v.pane = 1 - v.pane
+		v.nibble = 0
+	case "Z", "Y":
+		if !e.Modifiers.Contain(key.ModShortcut) {
+			break
+		}
+		undo := v.Buf.Undo
+		if e.Name == "Y" || shift {
+			undo = v.Buf.Redo
+		}
+		if off, ok := undo(); ok {
+			v.SetCursor(off, false)
+		}
+	case "C":
+		if e.Modifiers.Contain(key.ModShortcut) {
+			clipboard.WriteOp{Text: v.copyText()}.Add(gtx.Ops)
+		}
+	}
+}
+
+// maxCopy limits the bytes copied to the clipboard.
+const maxCopy = 1 << 20
+
+// copyText returns the selection as hex digits or text, after the pane.
+func (v *HexView) copyText() string {
+	start, end := v.Selection()
+	if end-start > maxCopy {
+		end = start + maxCopy
+	}
+	b := make([]byte, end-start)
+	n, _ := v.Buf.ReadAt(b, start)
+	b = b[:n]
+	if v.pane == textPane {
+		for i := range b {
+			b[i] = printable(b[i])
+		}
+		return string(b)
+	}
+	return strings.ToUpper(hex.EncodeToString(b))
+}
+
+// typeText overwrites bytes from the cursor with hex digits or text,
+// after the pane.
+func (v *HexView) typeText(s string) {
+	for _, r := range s {
+		if v.cursor >= v.Buf.Size() {
+			return
+		}
+		switch v.pane {
+		case hexPane:
+			var d byte
+			switch {
+			case r >= '0' && r <= '9':
+				d = byte(r - '0')
+			case r >= 'a' && r <= 'f':
+				d = byte(r-'a') + 10
+			case r >= 'A' && r <= 'F':
+				d = byte(r-'A') + 10
+			default:
+				continue
+			}
+			b := v.Buf.ByteAt(v.cursor)
+			if v.nibble == 0 {
+				v.Buf.Set(v.cursor, b&0x0f|d<<4)
+				v.anchor = v.cursor
+				v.nibble = 1
+			} else {
+				v.Buf.Set(v.cursor, b&0xf0|d)
+				v.SetCursor(v.cursor+1, false)
+			}
+		case textPane:
+			if r < 0x20 || r > 0x7e {
+				continue
+			}
+			v.Buf.Set(v.cursor, byte(r))
+			v.SetCursor(v.cursor+1, false)
+		}
+	}
+}
+
+func (v *HexView) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	v.update(gtx)
+	label := func(s string) material.LabelStyle {
+		l := material.Body2(th, s)
+		l.Font = monoFont
+		l.MaxLines = 1
+		return l
+	}
+	// Measure a character of the monospaced font.
+	m := op.Record(gtx.Ops)
+	v.char = label("0").Layout(gtx).Size
+	m.Stop()
+
+	size := gtx.Constraints.Max
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect{Max: size}.Add(gtx.Ops)
+	v.rows = (size.Y + v.char.Y - 1) / v.char.Y
+	selStart, selEnd := v.Selection()
+	buf := make([]byte, bytesPerRow)
+	var hexText, text strings.Builder
+	for r := 0; r < v.rows; r++ {
+		row := v.top + int64(r)
+		off := row * bytesPerRow
+		if off >= v.Buf.Size() {
+			break
+		}
+		n, _ := v.Buf.ReadAt(buf, off)
+		y := r * v.char.Y
+		// Backgrounds of the selection, edits and cursor.
+		for i := 0; i < n; i++ {
+			b := off + int64(i)
+			var bg color.NRGBA
+			switch {
+			case b == v.cursor:
+				bg = cursorColor
+			case b >= selStart && b < selEnd:
+				bg = selectionColor
+			case v.Buf.Edited(b):
+				bg = editedColor
+			default:
+				continue
+			}
+			for _, p := range []pane{hexPane, textPane} {
+				col, w := byteCol(p, i), 1
+				if p == hexPane {
+					w = 2
+					if b == v.cursor && v.nibble == 1 && v.pane == hexPane {
+						col, w = col+1, 1
+					}
+				}
+				c := bg
+				if b == v.cursor && (p != v.pane || !v.focused) {
+					c = selectionColor
+				}
+				rect := image.Rect(col*v.char.X, y, (col+w)*v.char.X, y+v.char.Y)
+				paint.FillShape(gtx.Ops, c, clip.Rect(rect).Op())
+			}
+		}
+		hexText.Reset()
+		text.Reset()
+		for i := 0; i < bytesPerRow; i++ {
+			if i == bytesPerRow/2 {
+				hexText.WriteByte(' ')
+			}
+			if i < n {
+				fmt.Fprintf(&hexText, "%02X ", buf[i])
+				text.WriteByte(printable(buf[i]))
+			} else {
+				hexText.WriteString("   ")
+			}
+		}
+		cols := []struct {
+			col int
+			l   material.LabelStyle
+		}{
+			{0, label(fmt.Sprintf("%08X", off))},
+			{hexCol, label(hexText.String())},
+			{textCol, label(text.String())},
+		}
+		cols[0].l.Color = offsetColor
+		for _, c := range cols {
+			stack := op.Save(gtx.Ops)
+			op.Offset(layout.FPt(image.Pt(c.col*v.char.X, y))).Add(gtx.Ops)
+			gtx := gtx
+			gtx.Constraints = layout.Constraints{Max: image.Pt(rowCols*v.char.X, v.char.Y)}
+			c.l.Layout(gtx)
+			stack.Load()
+		}
+	}
+	pointer.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	pointer.CursorNameOp{Name: pointer.CursorText}.Add(gtx.Ops)
+	pointer.InputOp{
+		Tag:   v,
+		Grab:  v.dragging,
+		Types: pointer.Press | pointer.Drag | pointer.Release,
+	}.Add(gtx.Ops)
+	v.scroll.Add(gtx.Ops, image.Rect(0, -1e6, 0, 1e6))
+	key.InputOp{Tag: v}.Add(gtx.Ops)
+	if v.focus {
+		key.FocusOp{Tag: v}.Add(gtx.Ops)
+		v.focus = false
+	}
+	return layout.Dimensions{Size: size}
+}
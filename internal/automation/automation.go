@@ -0,0 +1,387 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package automation lets scripts drive a running example: with the
+// -automation flag, the example serves JSON-RPC on a local address, where
+// scripts list the widgets the example registered, read their state,
+// click them and type into the focused widget. Integration tests and
+// demo recordings can then run against the real program.
+//
+// An example registers its widgets by name and wraps its frames:
+//
+//	automation.Register("submit", &submitBtn)
+//	automation.Start(w.Invalidate)
+//	...
+//	case system.FrameEvent:
+//		gtx := automation.Context(layout.NewContext(&ops, e))
+//		a.Layout(gtx, th)
+//		automation.Frame(gtx)
+//		e.Frame(gtx.Ops)
+//
+// The functions do nothing without the flag. The server speaks JSON-RPC
+// 1.0, one request per line, such as
+//
+//	{"method": "Automation.Widgets", "params": [{}], "id": 1}
+//	{"method": "Automation.Click", "params": [{"Name": "submit"}], "id": 2}
+//	{"method": "Automation.Type", "params": [{"Text": "hello"}], "id": 3}
+//	{"method": "Automation.Press", "params": [{"Key": "⏎", "Modifiers": ""}], "id": 4}
+//
+// Requests are answered after the frame handling them, so a script sees
+// the effects of one request in the replies to the next.
+package automation
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"log"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gioui.org/example/internal/uitest"
+	"gioui.org/f32"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/widget"
+)
+
+var (
+	enabled = flag.Bool("automation", false, "serve the automation interface")
+	addr    = flag.String("automation-addr", "127.0.0.1:7070", "address of the automation interface")
+)
+
+// timeout limits the wait for the frames handling a request.
+const timeout = 10 * time.Second
+
+// server is the state shared by the RPC goroutines and the UI.
+var server struct {
+	mu      sync.Mutex
+	widgets map[string]interface{}
+	// requests are waiting for a frame to run them.
+	requests []*request
+	// sent are requests whose events the next frame delivers.
+	sent       []*request
+	invalidate func()
+	running    bool
+
+	// router holds the injected events. It is only used by frames.
+	router router.Router
+}
+
+type request struct {
+	run  func(f *frame) error
+	done chan error
+}
+
+// frame is the last frame, for running requests.
+type frame struct {
+	ops  *op.Ops
+	size image.Point
+}
+
+// Register names a widget for scripts. It may be a *widget.Clickable,
+// *widget.Editor, *widget.Bool, *widget.Float or any other widget state
+// whose input handlers are inside it. A *widget.Enum keeps its handlers
+// elsewhere; scripts can read its value but not click it.
+func Register(name string, w interface{}) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.widgets == nil {
+		server.widgets = make(map[string]interface{})
+	}
+	server.widgets[name] = w
+}
+
+// Start serves the automation interface if the -automation flag is set.
+// Invalidate is called to run the frames of requests, typically
+// app.Window.Invalidate.
+func Start(invalidate func()) {
+	if !*enabled {
+		return
+	}
+	if _, err := listen(*addr, invalidate); err != nil {
+		log.Printf("automation: %v", err)
+	}
+}
+
+func listen(addr string, invalidate func()) (net.Addr, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if host, _, err := net.SplitHostPort(l.Addr().String()); err == nil {
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			log.Printf("automation: warning: %s is reachable from other machines", l.Addr())
+		}
+	}
+	srv := rpc.NewServer()
+	if err := srv.Register(new(Automation)); err != nil {
+		l.Close()
+		return nil, err
+	}
+	server.mu.Lock()
+	server.invalidate = invalidate
+	server.running = true
+	server.mu.Unlock()
+	log.Printf("automation: serving on %s", l.Addr())
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				log.Printf("automation: %v", err)
+				return
+			}
+			go srv.ServeCodec(jsonrpc.NewServerCodec(c))
+		}
+	}()
+	return l.Addr(), nil
+}
+
+func running() bool {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	return server.running
+}
+
+// Context returns gtx with the events of scripts added to its queue.
+func Context(gtx layout.Context) layout.Context {
+	if !running() || gtx.Queue == nil {
+		return gtx
+	}
+	gtx.Queue = queue{gtx.Queue, &server.router}
+	return gtx
+}
+
+// queue merges the events of the window with the injected ones.
+type queue struct {
+	window, injected event.Queue
+}
+
+func (q queue) Events(t event.Tag) []event.Event {
+	evts := q.window.Events(t)
+	if inj := q.injected.Events(t); len(inj) > 0 {
+		evts = append(evts[:len(evts):len(evts)], inj...)
+	}
+	return evts
+}
+
+// Frame answers the requests handled by the frame of gtx and runs the
+// waiting ones. Call it after laying out the frame.
+func Frame(gtx layout.Context) {
+	if !running() {
+		return
+	}
+	server.router.Frame(gtx.Ops)
+	f := &frame{ops: gtx.Ops, size: gtx.Constraints.Max}
+	server.mu.Lock()
+	sent, reqs := server.sent, server.requests
+	server.sent, server.requests = nil, nil
+	server.mu.Unlock()
+	for _, r := range sent {
+		r.done <- nil
+	}
+	var next []*request
+	for _, r := range reqs {
+		if err := r.run(f); err != nil {
+			r.done <- err
+			continue
+		}
+		next = append(next, r)
+	}
+	if len(next) > 0 {
+		server.mu.Lock()
+		server.sent = append(server.sent, next...)
+		inv := server.invalidate
+		server.mu.Unlock()
+		inv()
+	}
+}
+
+// do runs a request in the next frame and waits for the frame after it.
+func do(run func(f *frame) error) error {
+	r := &request{run: run, done: make(chan error, 1)}
+	server.mu.Lock()
+	server.requests = append(server.requests, r)
+	inv := server.invalidate
+	server.mu.Unlock()
+	inv()
+	select {
+	case err := <-r.done:
+		return err
+	case <-time.After(timeout):
+		return errors.New("automation: no frame; is the window visible?")
+	}
+}
+
+func lookup(name string) (interface{}, error) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	w, ok := server.widgets[name]
+	if !ok {
+		return nil, fmt.Errorf("automation: no widget named %q", name)
+	}
+	return w, nil
+}
+
+// Automation is the RPC service.
+type Automation struct{}
+
+// Empty is the argument and reply of methods without any.
+type Empty struct{}
+
+// Widget describes a registered widget.
+type Widget struct {
+	Name string
+	// Kind is the type of the widget state, such as "widget.Editor".
+	Kind string
+	// Value is the text of editors, the value of bools, floats and
+	// enums, and empty for others.
+	Value string
+	// Visible reports whether the widget was in the last frame, and
+	// Area is where it was.
+	Visible bool
+	Area    image.Rectangle
+}
+
+// Widgets replies with the registered widgets, sorted by name.
+func (Automation) Widgets(_ Empty, reply *[]Widget) error {
+	server.mu.Lock()
+	names := make([]string, 0, len(server.widgets))
+	for n := range server.widgets {
+		names = append(names, n)
+	}
+	server.mu.Unlock()
+	sort.Strings(names)
+	var ws []Widget
+	err := do(func(f *frame) error {
+		for _, n := range names {
+			w, err := lookup(n)
+			if err != nil {
+				continue
+			}
+			area, visible := uitest.Locate(f.ops, f.size, w)
+			ws = append(ws, Widget{
+				Name:    n,
+				Kind:    strings.TrimPrefix(fmt.Sprintf("%T", w), "*"),
+				Value:   value(w),
+				Visible: visible,
+				Area:    area,
+			})
+		}
+		return nil
+	})
+	*reply = ws
+	return err
+}
+
+func value(w interface{}) string {
+	switch w := w.(type) {
+	case *widget.Editor:
+		return w.Text()
+	case *widget.Bool:
+		return fmt.Sprint(w.Value)
+	case *widget.Float:
+		return fmt.Sprint(w.Value)
+	case *widget.Enum:
+		return w.Value
+	case fmt.Stringer:
+		return w.String()
+	}
+	return ""
+}
+
+// Target names a widget.
+type Target struct {
+	Name string
+}
+
+// Click clicks the middle of a widget with the primary button.
+func (Automation) Click(args Target, _ *Empty) error {
+	w, err := lookup(args.Name)
+	if err != nil {
+		return err
+	}
+	return do(func(f *frame) error {
+		r, ok := uitest.Locate(f.ops, f.size, w)
+		if !ok {
+			return fmt.Errorf("automation: %q is not visible", args.Name)
+		}
+		c := r.Min.Add(r.Max).Div(2)
+		pos := f32.Pt(float32(c.X), float32(c.Y))
+		server.router.Queue(
+			pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: pos},
+			pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: pos},
+		)
+		return nil
+	})
+}
+
+// Text is text to type.
+type Text struct {
+	Text string
+}
+
+// Type types text into the focused widget.
+func (Automation) Type(args Text, _ *Empty) error {
+	return do(func(f *frame) error {
+		server.router.Queue(key.EditEvent{Text: args.Text})
+		return nil
+	})
+}
+
+// Key is a key press: a key name of package key, such as "⏎" or "A",
+// and modifiers such as "Ctrl" or "Shift+Shortcut".
+type Key struct {
+	Key       string
+	Modifiers string
+}
+
+// Press presses and releases a key of the focused widget.
+func (Automation) Press(args Key, _ *Empty) error {
+	mods, err := parseModifiers(args.Modifiers)
+	if err != nil {
+		return err
+	}
+	return do(func(f *frame) error {
+		server.router.Queue(
+			key.Event{Name: args.Key, Modifiers: mods, State: key.Press},
+			key.Event{Name: args.Key, Modifiers: mods, State: key.Release},
+		)
+		return nil
+	})
+}
+
+func parseModifiers(s string) (key.Modifiers, error) {
+	var mods key.Modifiers
+	if s == "" {
+		return 0, nil
+	}
+	for _, m := range strings.Split(s, "+") {
+		switch strings.ToLower(strings.TrimSpace(m)) {
+		case "ctrl":
+			mods |= key.ModCtrl
+		case "shift":
+			mods |= key.ModShift
+		case "alt":
+			mods |= key.ModAlt
+		case "super":
+			mods |= key.ModSuper
+		case "command", "cmd":
+			mods |= key.ModCommand
+		case "shortcut":
+			mods |= key.ModShortcut
+		default:
+			return 0, fmt.Errorf("automation: unknown modifier %q", m)
+		}
+	}
+	return mods, nil
+}
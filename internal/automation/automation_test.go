@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package automation
+
+import (
+	"image"
+	"net/rpc/jsonrpc"
+	"testing"
+
+	"gioui.org/font/gofont"
+	"gioui.org/io/key"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+func TestServer(t *testing.T) {
+	th := material.NewTheme(gofont.Collection())
+	var (
+		btn    widget.Clickable
+		ed     = &widget.Editor{SingleLine: true, Submit: true}
+		clicks int
+		submit string
+	)
+	Register("button", &btn)
+	Register("editor", ed)
+	frames := make(chan struct{}, 1)
+	addr, err := listen("127.0.0.1:0", func() {
+		select {
+		case frames <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Run the frames of a window.
+	go func() {
+		var (
+			ops op.Ops
+			win router.Router
+		)
+		for range frames {
+			ops.Reset()
+			gtx := Context(layout.Context{
+				Ops:         &ops,
+				Queue:       &win,
+				Constraints: layout.Exact(image.Pt(400, 200)),
+			})
+			for btn.Clicked() {
+				clicks++
+			}
+			for _, e := range ed.Events() {
+				if e, ok := e.(widget.SubmitEvent); ok {
+					submit = e.Text
+				}
+			}
+			layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(material.Button(th, &btn, "Button").Layout),
+				layout.Rigid(material.Editor(th, ed, "Editor").Layout),
+			)
+			Frame(gtx)
+			win.Frame(&ops)
+		}
+	}()
+
+	c, err := jsonrpc.Dial("tcp", addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	var ws []Widget
+	if err := c.Call("Automation.Widgets", Empty{}, &ws); err != nil {
+		t.Fatal(err)
+	}
+	if len(ws) != 2 || ws[0].Name != "button" || ws[1].Kind != "widget.Editor" || !ws[0].Visible {
+		t.Fatalf("widgets: %+v", ws)
+	}
+	if err := c.Call("Automation.Click", Target{Name: "button"}, new(Empty)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Call("Automation.Click", Target{Name: "editor"}, new(Empty)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Call("Automation.Type", Text{Text: "hello"}, new(Empty)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Call("Automation.Widgets", Empty{}, &ws); err != nil {
+		t.Fatal(err)
+	}
+	if ws[1].Value != "hello" {
+		t.Errorf("editor has %q, want the typed text", ws[1].Value)
+	}
+	if err := c.Call("Automation.Press", Key{Key: key.NameReturn}, new(Empty)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Call("Automation.Click", Target{Name: "missing"}, new(Empty)); err == nil {
+		t.Error("clicked a missing widget")
+	}
+	// Wait for a last frame to read the state safely.
+	if err := c.Call("Automation.Widgets", Empty{}, &ws); err != nil {
+		t.Fatal(err)
+	}
+	if clicks != 1 || submit != "hello" {
+		t.Errorf("%d clicks and submitted %q, want 1 and the typed text", clicks, submit)
+	}
+}
+
+func TestParseModifiers(t *testing.T) {
+	m, err := parseModifiers("Ctrl+shift")
+	if err != nil || m != key.ModCtrl|key.ModShift {
+		t.Errorf("parseModifiers = %v, %v", m, err)
+	}
+	if _, err := parseModifiers("Hyper"); err == nil {
+		t.Error("parsed an unknown modifier")
+	}
+}
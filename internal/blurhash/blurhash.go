@@ -0,0 +1,248 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package blurhash encodes and decodes BlurHash strings, compact
+// representations of an image placeholder. See https://blurha.sh for the
+// format description.
+package blurhash
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+)
+
+const chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// ErrInvalid is returned for malformed hashes.
+var ErrInvalid = errors.New("blurhash: invalid hash")
+
+// Components returns the number of horizontal and vertical components
+// encoded in hash.
+func Components(hash string) (x, y int, err error) {
+	if len(hash) < 6 {
+		return 0, 0, ErrInvalid
+	}
+	flag, err := decode83(hash[:1])
+	if err != nil {
+		return 0, 0, err
+	}
+	x, y = flag%9+1, flag/9+1
+	if len(hash) != 4+2*x*y {
+		return 0, 0, fmt.Errorf("blurhash: hash length %d, expected %d", len(hash), 4+2*x*y)
+	}
+	return x, y, nil
+}
+
+// Decode renders hash into an image of the given size. Punch adjusts the
+// contrast of the result; 1 is the neutral value. The size must be
+// positive.
+func Decode(hash string, width, height int, punch float64) (*image.NRGBA, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("blurhash: image size %dx%d out of range", width, height)
+	}
+	nx, ny, err := Components(hash)
+	if err != nil {
+		return nil, err
+	}
+	if punch <= 0 {
+		punch = 1
+	}
+	quantMax, err := decode83(hash[1:2])
+	if err != nil {
+		return nil, err
+	}
+	maxValue := float64(quantMax+1) / 166 * punch
+
+	colors := make([][3]float64, nx*ny)
+	for i := range colors {
+		if i == 0 {
+			v, err := decode83(hash[2:6])
+			if err != nil {
+				return nil, err
+			}
+			colors[i] = decodeDC(v)
+			continue
+		}
+		v, err := decode83(hash[4+i*2 : 6+i*2])
+		if err != nil {
+			return nil, err
+		}
+		colors[i] = decodeAC(v, maxValue)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	// Precompute the cosine bases, they are shared by every row or column.
+	cosX := basis(width, nx)
+	cosY := basis(height, ny)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var r, g, b float64
+			for j := 0; j < ny; j++ {
+				for i := 0; i < nx; i++ {
+					f := cosX[x*nx+i] * cosY[y*ny+j]
+					c := colors[i+j*nx]
+					r += c[0] * f
+					g += c[1] * f
+					b += c[2] * f
+				}
+			}
+			img.SetNRGBA(x, y, color.NRGBA{
+				R: linearToSRGB(r),
+				G: linearToSRGB(g),
+				B: linearToSRGB(b),
+				A: 0xff,
+			})
+		}
+	}
+	return img, nil
+}
+
+// Encode computes the hash of img using nx horizontal and ny vertical
+// components, each in the range [1, 9].
+func Encode(img image.Image, nx, ny int) (string, error) {
+	if nx < 1 || nx > 9 || ny < 1 || ny > 9 {
+		return "", fmt.Errorf("blurhash: components %dx%d out of range", nx, ny)
+	}
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w == 0 || h == 0 {
+		return "", errors.New("blurhash: empty image")
+	}
+	// Convert to linear light once instead of once per component.
+	linear := make([][3]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			linear[y*w+x] = [3]float64{sRGBToLinear(c.R), sRGBToLinear(c.G), sRGBToLinear(c.B)}
+		}
+	}
+	cosX := basis(w, nx)
+	cosY := basis(h, ny)
+	factors := make([][3]float64, nx*ny)
+	for j := 0; j < ny; j++ {
+		for i := 0; i < nx; i++ {
+			norm := 2.0
+			if i == 0 && j == 0 {
+				norm = 1
+			}
+			var f [3]float64
+			for y := 0; y < h; y++ {
+				for x := 0; x < w; x++ {
+					b := cosX[x*nx+i] * cosY[y*ny+j]
+					p := linear[y*w+x]
+					f[0] += b * p[0]
+					f[1] += b * p[1]
+					f[2] += b * p[2]
+				}
+			}
+			scale := norm / float64(w*h)
+			factors[i+j*nx] = [3]float64{f[0] * scale, f[1] * scale, f[2] * scale}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(encode83((nx-1)+(ny-1)*9, 1))
+	maxValue := 1.0
+	if len(factors) > 1 {
+		var actual float64
+		for _, f := range factors[1:] {
+			for _, v := range f {
+				actual = math.Max(actual, math.Abs(v))
+			}
+		}
+		quantMax := int(math.Max(0, math.Min(82, math.Floor(actual*166-.5))))
+		maxValue = float64(quantMax+1) / 166
+		sb.WriteString(encode83(quantMax, 1))
+	} else {
+		sb.WriteString(encode83(0, 1))
+	}
+	sb.WriteString(encode83(encodeDC(factors[0]), 4))
+	for _, f := range factors[1:] {
+		sb.WriteString(encode83(encodeAC(f, maxValue), 2))
+	}
+	return sb.String(), nil
+}
+
+// basis returns the cosine basis for n components over size pixels,
+// indexed by pixel*n+component.
+func basis(size, n int) []float64 {
+	b := make([]float64, size*n)
+	for p := 0; p < size; p++ {
+		for c := 0; c < n; c++ {
+			b[p*n+c] = math.Cos(math.Pi * float64(p) * float64(c) / float64(size))
+		}
+	}
+	return b
+}
+
+func decodeDC(v int) [3]float64 {
+	return [3]float64{
+		sRGBToLinear(uint8(v >> 16)),
+		sRGBToLinear(uint8(v >> 8)),
+		sRGBToLinear(uint8(v)),
+	}
+}
+
+func decodeAC(v int, maxValue float64) [3]float64 {
+	q := [3]int{v / (19 * 19), (v / 19) % 19, v % 19}
+	var c [3]float64
+	for i, q := range q {
+		c[i] = signPow(float64(q-9)/9, 2) * maxValue
+	}
+	return c
+}
+
+func encodeDC(c [3]float64) int {
+	return int(linearToSRGB(c[0]))<<16 | int(linearToSRGB(c[1]))<<8 | int(linearToSRGB(c[2]))
+}
+
+func encodeAC(c [3]float64, maxValue float64) int {
+	quant := func(v float64) int {
+		return int(math.Max(0, math.Min(18, math.Floor(signPow(v/maxValue, .5)*9+9.5))))
+	}
+	return quant(c[0])*19*19 + quant(c[1])*19 + quant(c[2])
+}
+
+func sRGBToLinear(v uint8) float64 {
+	f := float64(v) / 255
+	if f <= 0.04045 {
+		return f / 12.92
+	}
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) uint8 {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		return uint8(v*12.92*255 + .5)
+	}
+	return uint8((1.055*math.Pow(v, 1/2.4)-0.055)*255 + .5)
+}
+
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
+
+func decode83(s string) (int, error) {
+	v := 0
+	for _, r := range s {
+		i := strings.IndexRune(chars, r)
+		if i == -1 {
+			return 0, ErrInvalid
+		}
+		v = v*83 + i
+	}
+	return v, nil
+}
+
+func encode83(v, length int) string {
+	b := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		b[i] = chars[v%83]
+		v /= 83
+	}
+	return string(b)
+}
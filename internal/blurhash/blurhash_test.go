@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package blurhash
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	const hash = "LEHV6nWB2yk8pyo0adR*.7kCMdnj"
+	x, y, err := Components(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x != 4 || y != 3 {
+		t.Errorf("components: got %dx%d, want 4x3", x, y)
+	}
+	img, err := Decode(hash, 32, 32, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.Bounds().Size(); got != image.Pt(32, 32) {
+		t.Errorf("size: got %v", got)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, hash := range []string{"", "LEHV6", "LEHV6nWB2yk8pyo0adR*.7kCMdn", "LEHV6nWB2yk8pyo0adR*.7kCMdn\""} {
+		if _, err := Decode(hash, 4, 4, 1); err == nil {
+			t.Errorf("Decode(%q) succeeded", hash)
+		}
+	}
+	for _, size := range [][2]int{{0, 4}, {4, 0}, {-1, 4}, {4, -8}} {
+		if _, err := Decode("LEHV6nWB2yk8pyo0adR*.7kCMdnj", size[0], size[1], 1); err == nil {
+			t.Errorf("Decode at %dx%d succeeded", size[0], size[1])
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	want := color.NRGBA{R: 0x40, G: 0x80, B: 0xc0, A: 0xff}
+	src := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for i := 0; i < len(src.Pix); i += 4 {
+		src.Pix[i], src.Pix[i+1], src.Pix[i+2], src.Pix[i+3] = want.R, want.G, want.B, want.A
+	}
+	hash, err := Encode(src, 4, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := Decode(hash, 8, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := img.NRGBAAt(4, 4)
+	if diff(got.R, want.R) > 2 || diff(got.G, want.G) > 2 || diff(got.B, want.B) > 2 {
+		t.Errorf("round trip of %v: got %v (hash %q)", want, got, hash)
+	}
+}
+
+func diff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
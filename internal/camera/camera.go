@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package camera captures video frames from a webcam, in color for
+// showing and in gray for image analysis. Capture is implemented with
+// Video4Linux on 64-bit Linux; Open fails with ErrUnsupported elsewhere.
+package camera
+
+import (
+	"errors"
+	"image"
+	"time"
+)
+
+// ErrUnsupported is returned by Open where capture is not implemented.
+var ErrUnsupported = errors.New("camera: capture is not supported on this platform")
+
+// Frame is an image captured from a camera.
+type Frame struct {
+	RGBA *image.RGBA
+	// Gray is the luma of the image.
+	Gray *image.Gray
+	Time time.Time
+}
+
+// Reset makes the images of f the size sz, reusing them when possible.
+func (f *Frame) Reset(sz image.Point) {
+	r := image.Rectangle{Max: sz}
+	if f.RGBA == nil || f.RGBA.Rect != r {
+		f.RGBA = image.NewRGBA(r)
+		f.Gray = image.NewGray(r)
+	}
+}
+
+// convertYUYV converts an image of packed 4:2:2 YUV, where every pair of
+// pixels is 4 bytes Y0 U Y1 V, to f.
+func convertYUYV(f *Frame, src []byte, sz image.Point, stride int) {
+	f.Reset(sz)
+	for y := 0; y < sz.Y; y++ {
+		row := src[y*stride:]
+		rgba := f.RGBA.Pix[y*f.RGBA.Stride:]
+		gray := f.Gray.Pix[y*f.Gray.Stride:]
+		for x := 0; x+1 < sz.X; x += 2 {
+			y0, u, y1, v := row[x*2], row[x*2+1], row[x*2+2], row[x*2+3]
+			gray[x], gray[x+1] = y0, y1
+			setYCbCr(rgba[x*4:], y0, u, v)
+			setYCbCr(rgba[x*4+4:], y1, u, v)
+		}
+	}
+}
+
+// setYCbCr sets the RGBA pixel p to the color of BT.601 YCbCr in
+// studio range, as sent by webcams.
+func setYCbCr(p []byte, y, cb, cr uint8) {
+	c := (int32(y) - 16) * 298
+	d := int32(cb) - 128
+	e := int32(cr) - 128
+	p[0] = clamp((c + 409*e + 128) >> 8)
+	p[1] = clamp((c - 100*d - 208*e + 128) >> 8)
+	p[2] = clamp((c + 516*d + 128) >> 8)
+	p[3] = 0xff
+}
+
+func clamp(v int32) uint8 {
+	switch {
+	case v < 0:
+		return 0
+	case v > 0xff:
+		return 0xff
+	default:
+		return uint8(v)
+	}
+}
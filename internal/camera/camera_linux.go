@@ -0,0 +1,202 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build linux && (amd64 || arm64)
+// +build linux
+// +build amd64 arm64
+
+package camera
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"path/filepath"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// The Video4Linux ioctls and the layout of their structures on 64-bit
+// platforms, from linux/videodev2.h.
+const (
+	vidiocQuerycap  = 0x80685600
+	vidiocSFmt      = 0xc0d05605
+	vidiocReqbufs   = 0xc0145608
+	vidiocQuerybuf  = 0xc0585609
+	vidiocQbuf      = 0xc058560f
+	vidiocDqbuf     = 0xc0585611
+	vidiocStreamon  = 0x40045612
+	vidiocStreamoff = 0x40045613
+
+	capVideoCapture = 0x1
+	capStreaming    = 0x04000000
+	capDeviceCaps   = 0x80000000
+
+	bufTypeVideoCapture = 1
+	memoryMmap          = 1
+	fieldNone           = 1
+
+	sizeofCapability = 104
+	sizeofFormat     = 208
+	sizeofReqbufs    = 20
+	sizeofBuffer     = 88
+)
+
+var pixYUYV = fourcc("YUYV")
+
+func fourcc(s string) uint32 {
+	return uint32(s[0]) | uint32(s[1])<<8 | uint32(s[2])<<16 | uint32(s[3])<<24
+}
+
+// Camera is a video capture device.
+type Camera struct {
+	fd     int
+	bufs   [][]byte
+	size   image.Point
+	stride int
+}
+
+// Devices lists the video capture devices.
+func Devices() []string {
+	devs, _ := filepath.Glob("/dev/video*")
+	return devs
+}
+
+// Open starts capturing from device in frames of about size sz. The
+// camera may pick another size; see Size.
+func Open(device string, sz image.Point) (*Camera, error) {
+	fd, err := unix.Open(device, unix.O_RDWR|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("camera: %s: %w", device, err)
+	}
+	c := &Camera{fd: fd}
+	if err := c.start(sz); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("camera: %s: %w", device, err)
+	}
+	return c, nil
+}
+
+func ioctl(fd int, req uintptr, arg []byte) error {
+	for {
+		_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), req, uintptr(unsafe.Pointer(&arg[0])))
+		switch errno {
+		case 0:
+			return nil
+		case unix.EINTR:
+			continue
+		default:
+			return errno
+		}
+	}
+}
+
+func (c *Camera) start(sz image.Point) error {
+	le := binary.LittleEndian
+	capb := make([]byte, sizeofCapability)
+	if err := ioctl(c.fd, vidiocQuerycap, capb); err != nil {
+		return err
+	}
+	caps := le.Uint32(capb[84:])
+	if caps&capDeviceCaps != 0 {
+		caps = le.Uint32(capb[88:])
+	}
+	if caps&capVideoCapture == 0 || caps&capStreaming == 0 {
+		return errors.New("not a video capture device")
+	}
+
+	// Ask for YUYV, the format all webcams offer at modest sizes.
+	fmtb := make([]byte, sizeofFormat)
+	le.PutUint32(fmtb[0:], bufTypeVideoCapture)
+	le.PutUint32(fmtb[8:], uint32(sz.X))
+	le.PutUint32(fmtb[12:], uint32(sz.Y))
+	le.PutUint32(fmtb[16:], pixYUYV)
+	le.PutUint32(fmtb[20:], fieldNone)
+	if err := ioctl(c.fd, vidiocSFmt, fmtb); err != nil {
+		return err
+	}
+	if pf := le.Uint32(fmtb[16:]); pf != pixYUYV {
+		return fmt.Errorf("unsupported pixel format %q", string([]byte{byte(pf), byte(pf >> 8), byte(pf >> 16), byte(pf >> 24)}))
+	}
+	c.size = image.Pt(int(le.Uint32(fmtb[8:])), int(le.Uint32(fmtb[12:])))
+	c.stride = int(le.Uint32(fmtb[24:]))
+	if c.stride == 0 {
+		c.stride = c.size.X * 2
+	}
+
+	req := make([]byte, sizeofReqbufs)
+	le.PutUint32(req[0:], 4)
+	le.PutUint32(req[4:], bufTypeVideoCapture)
+	le.PutUint32(req[8:], memoryMmap)
+	if err := ioctl(c.fd, vidiocReqbufs, req); err != nil {
+		return err
+	}
+	n := int(le.Uint32(req[0:]))
+	if n == 0 {
+		return errors.New("no capture buffers")
+	}
+	for i := 0; i < n; i++ {
+		buf := newBuffer(i)
+		if err := ioctl(c.fd, vidiocQuerybuf, buf); err != nil {
+			return err
+		}
+		offset, length := le.Uint32(buf[64:]), le.Uint32(buf[72:])
+		mem, err := unix.Mmap(c.fd, int64(offset), int(length), unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+		if err != nil {
+			return err
+		}
+		c.bufs = append(c.bufs, mem)
+		if err := ioctl(c.fd, vidiocQbuf, buf); err != nil {
+			return err
+		}
+	}
+	typ := make([]byte, 4)
+	le.PutUint32(typ, bufTypeVideoCapture)
+	return ioctl(c.fd, vidiocStreamon, typ)
+}
+
+// newBuffer returns a struct v4l2_buffer for the mapped buffer i.
+func newBuffer(i int) []byte {
+	buf := make([]byte, sizeofBuffer)
+	binary.LittleEndian.PutUint32(buf[0:], uint32(i))
+	binary.LittleEndian.PutUint32(buf[4:], bufTypeVideoCapture)
+	binary.LittleEndian.PutUint32(buf[60:], memoryMmap)
+	return buf
+}
+
+// Size returns the size of the frames.
+func (c *Camera) Size() image.Point {
+	return c.size
+}
+
+// Read waits for the next frame and stores it in f.
+func (c *Camera) Read(f *Frame) error {
+	buf := newBuffer(0)
+	if err := ioctl(c.fd, vidiocDqbuf, buf); err != nil {
+		return fmt.Errorf("camera: %w", err)
+	}
+	i := int(binary.LittleEndian.Uint32(buf[0:]))
+	used := int(binary.LittleEndian.Uint32(buf[8:]))
+	data := c.bufs[i]
+	if used < c.stride*c.size.Y {
+		// A damaged frame; drop it.
+		return ioctl(c.fd, vidiocQbuf, buf)
+	}
+	convertYUYV(f, data, c.size, c.stride)
+	f.Time = time.Now()
+	return ioctl(c.fd, vidiocQbuf, buf)
+}
+
+// Close stops capturing.
+func (c *Camera) Close() error {
+	typ := make([]byte, 4)
+	binary.LittleEndian.PutUint32(typ, bufTypeVideoCapture)
+	ioctl(c.fd, vidiocStreamoff, typ)
+	for _, b := range c.bufs {
+		unix.Munmap(b)
+	}
+	c.bufs = nil
+	return unix.Close(c.fd)
+}
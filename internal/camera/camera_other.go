@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build !linux || !(amd64 || arm64)
+// +build !linux !amd64,!arm64
+
+package camera
+
+import "image"
+
+// Camera is a video capture device.
+type Camera struct{}
+
+// Devices lists the video capture devices.
+func Devices() []string {
+	return nil
+}
+
+// Open starts capturing from device in frames of about size sz.
+func Open(device string, sz image.Point) (*Camera, error) {
+	return nil, ErrUnsupported
+}
+
+// Size returns the size of the frames.
+func (c *Camera) Size() image.Point {
+	return image.Point{}
+}
+
+// Read waits for the next frame and stores it in f.
+func (c *Camera) Read(f *Frame) error {
+	return ErrUnsupported
+}
+
+// Close stops capturing.
+func (c *Camera) Close() error {
+	return nil
+}
@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package camera
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestConvertYUYV(t *testing.T) {
+	// White and black, then pure red of BT.601: Y 81, Cb 90, Cr 240.
+	src := []byte{
+		235, 128, 16, 128,
+		81, 90, 81, 240,
+		0, 0, 0, 0, // Padding to the stride.
+	}
+	var f Frame
+	convertYUYV(&f, src[:4], image.Pt(2, 1), 4)
+	if got := f.RGBA.RGBAAt(0, 0); got != (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}) {
+		t.Errorf("white is %v", got)
+	}
+	if got := f.RGBA.RGBAAt(1, 0); got != (color.RGBA{A: 0xff}) {
+		t.Errorf("black is %v", got)
+	}
+	if f.Gray.GrayAt(0, 0).Y != 235 || f.Gray.GrayAt(1, 0).Y != 16 {
+		t.Errorf("luma %v, want the Y of the pixels", f.Gray.Pix)
+	}
+
+	convertYUYV(&f, src[4:], image.Pt(2, 1), 8)
+	if r := f.RGBA.RGBAAt(0, 0); r.R < 0xf8 || r.G > 0x08 || r.B > 0x08 {
+		t.Errorf("red is %v", r)
+	}
+}
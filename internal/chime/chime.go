@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package chime synthesizes short alert sounds and plays them with the
+// audio player of the platform: a command line player on macOS, Linux and
+// the BSDs, the multimedia API on Windows and Web Audio in browsers.
+//
+// On Linux and the BSDs one of paplay (PulseAudio), pw-play (PipeWire) or
+// aplay (ALSA) must be installed; an in-process player would take cgo and
+// the ALSA headers to build. Android and iOS have no player. Without a
+// player, Load and Play fail with an error wrapping ErrUnsupported that
+// says what is missing, so that callers can turn their sounds off.
+package chime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+)
+
+// ErrUnsupported is wrapped by the errors of Load and Play when the
+// platform has no supported audio player.
+var ErrUnsupported = errors.New("chime: no audio player")
+
+const sampleRate = 44100
+
+// noteLength is the length of each note of a chime.
+const noteLength = 300 * time.Millisecond
+
+// Tones returns a WAV encoded chime of bell like notes of the
+// frequencies in Hz, one after the other.
+func Tones(freqs ...float64) []byte {
+	return Notes(noteLength, freqs...)
+}
+
+// Notes is like Tones, for notes of length d.
+func Notes(d time.Duration, freqs ...float64) []byte {
+	n := int(d.Seconds() * sampleRate)
+	// decay fades the notes by the same amount whatever their length.
+	decay := 1.8 / d.Seconds()
+	samples := make([]int16, 0, n*len(freqs))
+	for _, f := range freqs {
+		for i := 0; i < n; i++ {
+			t := float64(i) / sampleRate
+			// A fast attack avoids clicks, and the exponential decay
+			// makes the note ring.
+			env := math.Min(1, t/0.005) * math.Exp(-decay*t)
+			v := env * (math.Sin(2*math.Pi*f*t) + 0.3*math.Sin(4*math.Pi*f*t)) / 1.3
+			samples = append(samples, int16(v*0.6*math.MaxInt16))
+		}
+	}
+	return encodeWAV(samples)
+}
+
+// encodeWAV encodes 16 bit mono samples.
+func encodeWAV(samples []int16) []byte {
+	var b bytes.Buffer
+	size := uint32(2 * len(samples))
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, 36+size)
+	b.WriteString("WAVEfmt ")
+	binary.Write(&b, binary.LittleEndian, struct {
+		Size             uint32
+		Format, Channels uint16
+		Rate, ByteRate   uint32
+		Align, Bits      uint16
+	}{
+		Size:     16,
+		Format:   1, // PCM.
+		Channels: 1,
+		Rate:     sampleRate,
+		ByteRate: 2 * sampleRate,
+		Align:    2,
+		Bits:     16,
+	})
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, size)
+	binary.Write(&b, binary.LittleEndian, samples)
+	return b.Bytes()
+}
+
+// Play plays a WAV encoded sound and returns when it has finished. Run it
+// in a goroutine to keep the UI responsive.
+func Play(wav []byte) error {
+	s, err := Load(wav)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	return s.Play()
+}
+
+// Sound is a sound prepared for playing, for sounds played again and
+// again. Playing a loaded sound skips the preparation of Play, which
+// takes a file write on most platforms and decoding in browsers.
+type Sound struct {
+	p *player
+}
+
+// Load prepares a WAV encoded sound for playing.
+func Load(wav []byte) (*Sound, error) {
+	p, err := newPlayer(wav)
+	if err != nil {
+		return nil, err
+	}
+	return &Sound{p: p}, nil
+}
+
+// Play plays the sound and returns when it has finished. Sounds may
+// play at the same time, except on Windows where a sound stops the one
+// playing.
+func (s *Sound) Play() error {
+	return s.p.play()
+}
+
+// Close releases the resources of the sound.
+func (s *Sound) Close() error {
+	return s.p.close()
+}
@@ -0,0 +1,7 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package chime
+
+func newPlayer(wav []byte) (*player, error) {
+	return newFilePlayer(wav, "afplay")
+}
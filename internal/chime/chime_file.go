@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build darwin || (linux && !android) || freebsd || openbsd
+// +build darwin linux,!android freebsd openbsd
+
+package chime
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+)
+
+// player plays a sound saved to a temporary file with a command line
+// player.
+type player struct {
+	cmd, file string
+}
+
+func newFilePlayer(wav []byte, cmd string) (*player, error) {
+	f, err := ioutil.TempFile("", "chime-*.wav")
+	if err != nil {
+		return nil, err
+	}
+	_, err = f.Write(wav)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return &player{cmd: cmd, file: f.Name()}, nil
+}
+
+func (p *player) play() error {
+	return exec.Command(p.cmd, p.file).Run()
+}
+
+func (p *player) close() error {
+	return os.Remove(p.file)
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package chime
+
+import (
+	"errors"
+	"sync"
+	"syscall/js"
+)
+
+// player plays a sound decoded by Web Audio.
+type player struct {
+	buf js.Value
+}
+
+var (
+	ctxOnce  sync.Once
+	audioCtx js.Value
+)
+
+// context returns the audio context of the page, or undefined if the
+// browser has no Web Audio.
+func context() js.Value {
+	ctxOnce.Do(func() {
+		ctor := js.Global().Get("AudioContext")
+		if !ctor.Truthy() {
+			ctor = js.Global().Get("webkitAudioContext")
+		}
+		if ctor.Truthy() {
+			audioCtx = ctor.New()
+		}
+	})
+	return audioCtx
+}
+
+func newPlayer(wav []byte) (*player, error) {
+	ctx := context()
+	if !ctx.Truthy() {
+		return nil, ErrUnsupported
+	}
+	arr := js.Global().Get("Uint8Array").New(len(wav))
+	js.CopyBytesToJS(arr, wav)
+	type result struct {
+		buf js.Value
+		err error
+	}
+	done := make(chan result, 1)
+	ok := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		done <- result{buf: args[0]}
+		return nil
+	})
+	defer ok.Release()
+	fail := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		msg := "decoding failed"
+		if len(args) > 0 && args[0].Truthy() {
+			msg = args[0].Call("toString").String()
+		}
+		done <- result{err: errors.New("chime: " + msg)}
+		return nil
+	})
+	defer fail.Release()
+	ctx.Call("decodeAudioData", arr.Get("buffer"), ok, fail)
+	r := <-done
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &player{buf: r.buf}, nil
+}
+
+func (p *player) play() error {
+	ctx := context()
+	// Browsers suspend audio until the user interacts with the page.
+	if ctx.Get("state").String() == "suspended" {
+		ctx.Call("resume")
+	}
+	src := ctx.Call("createBufferSource")
+	src.Set("buffer", p.buf)
+	src.Call("connect", ctx.Get("destination"))
+	ended := make(chan struct{}, 1)
+	onEnded := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		ended <- struct{}{}
+		return nil
+	})
+	defer onEnded.Release()
+	src.Set("onended", onEnded)
+	src.Call("start")
+	<-ended
+	return nil
+}
+
+func (p *player) close() error {
+	return nil
+}
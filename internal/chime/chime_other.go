@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build !darwin && !windows && !js && !((linux && !android) || freebsd || openbsd)
+// +build !darwin
+// +build !windows
+// +build !js
+// +build !linux android
+// +build !freebsd
+// +build !openbsd
+
+package chime
+
+import (
+	"fmt"
+	"runtime"
+)
+
+type player struct{}
+
+func newPlayer(wav []byte) (*player, error) {
+	return nil, fmt.Errorf("%w on %s", ErrUnsupported, runtime.GOOS)
+}
+
+func (p *player) play() error {
+	return ErrUnsupported
+}
+
+func (p *player) close() error {
+	return nil
+}
@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package chime
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestTones(t *testing.T) {
+	wav := Tones(880, 660)
+	if string(wav[:4]) != "RIFF" || string(wav[8:16]) != "WAVEfmt " || string(wav[36:40]) != "data" {
+		t.Fatalf("bad WAV header %q", wav[:44])
+	}
+	if got, want := binary.LittleEndian.Uint32(wav[4:]), uint32(len(wav)-8); got != want {
+		t.Errorf("RIFF size %d, want %d", got, want)
+	}
+	data := binary.LittleEndian.Uint32(wav[40:])
+	if got, want := int(data), 2*2*int(noteLength.Seconds()*sampleRate); got != want {
+		t.Errorf("data size %d, want %d", got, want)
+	}
+	if int(data) != len(wav)-44 {
+		t.Errorf("data size %d, but %d bytes follow", data, len(wav)-44)
+	}
+}
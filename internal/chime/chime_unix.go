@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build (linux && !android) || freebsd || openbsd
+// +build linux,!android freebsd openbsd
+
+package chime
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// players are the command line players tried in order: PulseAudio,
+// PipeWire and ALSA.
+var players = []string{"paplay", "pw-play", "aplay"}
+
+func newPlayer(wav []byte) (*player, error) {
+	for _, p := range players {
+		if path, err := exec.LookPath(p); err == nil {
+			return newFilePlayer(wav, path)
+		}
+	}
+	return nil, fmt.Errorf("%w: install paplay, pw-play or aplay", ErrUnsupported)
+}
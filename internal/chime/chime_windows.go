@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package chime
+
+import (
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	winmm         = syscall.NewLazyDLL("winmm.dll")
+	procPlaySound = winmm.NewProc("PlaySoundW")
+)
+
+const (
+	_SND_SYNC      = 0x0000
+	_SND_NODEFAULT = 0x0002
+	_SND_MEMORY    = 0x0004
+)
+
+// player plays a sound from memory with PlaySound, which plays one sound
+// at a time.
+type player struct {
+	wav []byte
+}
+
+func newPlayer(wav []byte) (*player, error) {
+	if err := procPlaySound.Find(); err != nil {
+		return nil, ErrUnsupported
+	}
+	return &player{wav: wav}, nil
+}
+
+func (p *player) play() error {
+	r, _, err := procPlaySound.Call(uintptr(unsafe.Pointer(&p.wav[0])), 0, _SND_SYNC|_SND_MEMORY|_SND_NODEFAULT)
+	runtime.KeepAlive(p.wav)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+func (p *player) close() error {
+	return nil
+}
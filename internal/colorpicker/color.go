@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package colorpicker
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// HSV is a color in the hue, saturation and value model.
+type HSV struct {
+	// H is the hue in degrees, in [0, 360).
+	H float32
+	// S and V are the saturation and value, in [0, 1].
+	S, V float32
+}
+
+// ToHSV converts an RGB color to HSV, ignoring alpha.
+func ToHSV(c color.NRGBA) HSV {
+	r, g, b := float32(c.R)/255, float32(c.G)/255, float32(c.B)/255
+	max := float32(math.Max(float64(r), math.Max(float64(g), float64(b))))
+	min := float32(math.Min(float64(r), math.Min(float64(g), float64(b))))
+	d := max - min
+	hsv := HSV{V: max}
+	if max > 0 {
+		hsv.S = d / max
+	}
+	if d == 0 {
+		return hsv
+	}
+	switch max {
+	case r:
+		hsv.H = (g - b) / d
+		if hsv.H < 0 {
+			hsv.H += 6
+		}
+	case g:
+		hsv.H = (b-r)/d + 2
+	default:
+		hsv.H = (r-g)/d + 4
+	}
+	hsv.H *= 60
+	return hsv
+}
+
+// RGB converts the color to RGB with the given alpha.
+func (c HSV) RGB(alpha uint8) color.NRGBA {
+	h := float64(c.H) / 60
+	s, v := float64(c.S), float64(c.V)
+	i := math.Floor(h)
+	f := h - i
+	p := v * (1 - s)
+	q := v * (1 - s*f)
+	t := v * (1 - s*(1-f))
+	var r, g, b float64
+	switch int(i) % 6 {
+	case 0:
+		r, g, b = v, t, p
+	case 1:
+		r, g, b = q, v, p
+	case 2:
+		r, g, b = p, v, t
+	case 3:
+		r, g, b = p, q, v
+	case 4:
+		r, g, b = t, p, v
+	default:
+		r, g, b = v, p, q
+	}
+	return color.NRGBA{
+		R: uint8(math.Round(r * 255)),
+		G: uint8(math.Round(g * 255)),
+		B: uint8(math.Round(b * 255)),
+		A: alpha,
+	}
+}
+
+// ErrInvalidHex is returned by ParseHex for malformed colors.
+var ErrInvalidHex = errors.New("colorpicker: invalid hex color")
+
+// ParseHex parses a color in the #rgb, #rrggbb or #rrggbbaa notation. The
+// leading # is optional.
+func ParseHex(s string) (color.NRGBA, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	switch len(s) {
+	case 3:
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]}) + "ff"
+	case 6:
+		s += "ff"
+	case 8:
+	default:
+		return color.NRGBA{}, ErrInvalidHex
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, ErrInvalidHex
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// Hex formats c as #rrggbb, or #rrggbbaa if it is translucent.
+func Hex(c color.NRGBA) string {
+	if c.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package colorpicker
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestHSVRoundTrip(t *testing.T) {
+	for _, c := range []color.NRGBA{
+		{A: 0xff},
+		{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		{R: 0xff, A: 0xff},
+		{G: 0x80, A: 0xff},
+		{R: 0x12, G: 0x34, B: 0x56, A: 0xff},
+		{R: 0xde, G: 0xad, B: 0xbe, A: 0xff},
+	} {
+		if got := ToHSV(c).RGB(0xff); got != c {
+			t.Errorf("ToHSV(%v).RGB() = %v", c, got)
+		}
+	}
+}
+
+func TestParseHex(t *testing.T) {
+	tests := []struct {
+		in   string
+		want color.NRGBA
+	}{
+		{"#fff", color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}},
+		{"123456", color.NRGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xff}},
+		{"#12345678", color.NRGBA{R: 0x12, G: 0x34, B: 0x56, A: 0x78}},
+	}
+	for _, test := range tests {
+		got, err := ParseHex(test.in)
+		if err != nil {
+			t.Errorf("ParseHex(%q): %v", test.in, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseHex(%q) = %v, want %v", test.in, got, test.want)
+		}
+		if back, _ := ParseHex(Hex(got)); back != got {
+			t.Errorf("ParseHex(Hex(%v)) = %v", got, back)
+		}
+	}
+	for _, in := range []string{"", "#12", "#ggg", "#1234567"} {
+		if _, err := ParseHex(in); err == nil {
+			t.Errorf("ParseHex(%q) succeeded", in)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package colorpicker
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Palette is a list of saved colors.
+type Palette struct {
+	Colors []color.NRGBA
+
+	swatches []widget.Clickable
+	add      widget.Clickable
+}
+
+// PaletteStyle lays out a Palette for a picker State.
+type PaletteStyle struct {
+	Palette *Palette
+	State   *State
+	Theme   *material.Theme
+	// Size is the size of a swatch.
+	Size unit.Value
+}
+
+// PaletteOf returns a style for laying out p. Clicking a swatch selects
+// its color in s; the add button saves the color selected in s.
+func PaletteOf(th *material.Theme, p *Palette, s *State) PaletteStyle {
+	return PaletteStyle{Palette: p, State: s, Theme: th, Size: unit.Dp(28)}
+}
+
+// Layout lays out the swatches in a row, followed by the add button.
+func (p PaletteStyle) Layout(gtx C) D {
+	pal := p.Palette
+	for pal.add.Clicked() {
+		pal.Colors = append(pal.Colors, p.State.Color())
+	}
+	if n := len(pal.Colors); len(pal.swatches) < n {
+		pal.swatches = append(pal.swatches, make([]widget.Clickable, n-len(pal.swatches))...)
+	}
+	for i := range pal.Colors {
+		for pal.swatches[i].Clicked() {
+			c := pal.Colors[i]
+			p.State.set(ToHSV(c), c.A)
+			p.State.hex.SetText(Hex(c))
+		}
+	}
+	size := gtx.Px(p.Size)
+	children := make([]layout.FlexChild, 0, len(pal.Colors)+1)
+	for i := range pal.Colors {
+		i := i
+		children = append(children, layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(2)).Layout(gtx, func(gtx C) D {
+				return material.Clickable(gtx, &pal.swatches[i], func(gtx C) D {
+					return swatch(gtx, pal.Colors[i], size)
+				})
+			})
+		}))
+	}
+	children = append(children, layout.Rigid(func(gtx C) D {
+		return layout.UniformInset(unit.Dp(2)).Layout(gtx, func(gtx C) D {
+			return material.Clickable(gtx, &pal.add, func(gtx C) D {
+				gtx.Constraints = layout.Exact(image.Pt(size, size))
+				return layout.Center.Layout(gtx, material.Body1(p.Theme, "+").Layout)
+			})
+		})
+	}))
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx, children...)
+}
+
+// Eyedropper samples colors from an image while the picker State is
+// picking. Gio has no portable way to read the screen outside its own
+// windows, so the eyedropper is limited to images drawn by the program.
+type Eyedropper struct {
+	// hover is the position of the pointer over the image, if any.
+	hover  f32.Point
+	inside bool
+}
+
+// Layout lays out w, which is expected to draw img stretched to its size.
+// While s is picking, clicking selects the color below the pointer and
+// ends the picking.
+func (e *Eyedropper) Layout(gtx C, s *State, img image.Image, w layout.Widget) D {
+	dims := w(gtx)
+	size := dims.Size
+	bounds := img.Bounds()
+	sample := func(p f32.Point) color.NRGBA {
+		x := bounds.Min.X + int(p.X)*bounds.Dx()/size.X
+		y := bounds.Min.Y + int(p.Y)*bounds.Dy()/size.Y
+		return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+	}
+	for _, ev := range gtx.Events(e) {
+		ev, ok := ev.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch ev.Type {
+		case pointer.Enter, pointer.Move:
+			e.hover, e.inside = ev.Position, true
+		case pointer.Leave:
+			e.inside = false
+		case pointer.Press:
+			if !s.Picking || size.X == 0 || size.Y == 0 {
+				break
+			}
+			c := sample(ev.Position)
+			s.set(ToHSV(c), c.A)
+			s.hex.SetText(Hex(c))
+			s.Picking = false
+		}
+	}
+	defer op.Save(gtx.Ops).Load()
+	pointer.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	pointer.InputOp{
+		Tag:   e,
+		Types: pointer.Press | pointer.Enter | pointer.Leave | pointer.Move,
+	}.Add(gtx.Ops)
+	if s.Picking {
+		pointer.CursorNameOp{Name: pointer.CursorCrossHair}.Add(gtx.Ops)
+		if e.inside && size.X > 0 && size.Y > 0 {
+			// Preview the color under the pointer.
+			d := float32(gtx.Px(unit.Dp(12)))
+			op.Offset(e.hover.Add(f32.Pt(d, d))).Add(gtx.Ops)
+			swatch(gtx, sample(e.hover), gtx.Px(unit.Dp(32)))
+		}
+	}
+	return dims
+}
@@ -0,0 +1,290 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package colorpicker implements a color picker widget with an HSV wheel,
+// sliders, hexadecimal entry, saved palettes and an eyedropper.
+package colorpicker
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+// State is the state of a color picker.
+type State struct {
+	hsv   HSV
+	alpha uint8
+
+	changed  bool
+	dragging bool
+
+	hue, sat, val, opacity widget.Float
+	hex                    widget.Editor
+	eyedropper             widget.Clickable
+	// Picking is set while the eyedropper is active.
+	Picking bool
+
+	wheel     paint.ImageOp
+	wheelSize int
+	wheelV    float32
+}
+
+// Color returns the selected color.
+func (s *State) Color() color.NRGBA {
+	return s.hsv.RGB(s.alpha)
+}
+
+// SetColor selects a color.
+func (s *State) SetColor(c color.NRGBA) {
+	s.hsv = ToHSV(c)
+	s.alpha = c.A
+	s.hex.SetText(Hex(c))
+}
+
+// Changed reports whether the color was changed by the user since the
+// last call.
+func (s *State) Changed() bool {
+	c := s.changed
+	s.changed = false
+	return c
+}
+
+func (s *State) set(hsv HSV, alpha uint8) {
+	if hsv == s.hsv && alpha == s.alpha {
+		return
+	}
+	s.hsv, s.alpha = hsv, alpha
+	s.changed = true
+	if !s.hex.Focused() {
+		s.hex.SetText(Hex(s.Color()))
+	}
+}
+
+// PickerStyle lays out a State.
+type PickerStyle struct {
+	State *State
+	Theme *material.Theme
+	// WheelSize is the diameter of the HSV wheel.
+	WheelSize unit.Value
+}
+
+// Picker returns a style for laying out the picker of s.
+func Picker(th *material.Theme, s *State) PickerStyle {
+	if s.alpha == 0 && s.hex.Text() == "" {
+		s.SetColor(color.NRGBA{A: 0xff})
+	}
+	return PickerStyle{State: s, Theme: th, WheelSize: unit.Dp(200)}
+}
+
+// Layout lays out the wheel above the sliders and the hex entry.
+func (p PickerStyle) Layout(gtx C) D {
+	s, th := p.State, p.Theme
+	s.hex.SingleLine = true
+	s.hex.Submit = true
+	for _, e := range s.hex.Events() {
+		var txt string
+		switch e := e.(type) {
+		case widget.ChangeEvent:
+			txt = s.hex.Text()
+		case widget.SubmitEvent:
+			txt = e.Text
+		}
+		if c, err := ParseHex(txt); err == nil {
+			s.set(ToHSV(c), c.A)
+		}
+	}
+	for s.eyedropper.Clicked() {
+		s.Picking = !s.Picking
+	}
+
+	slider := func(label string, f *widget.Float, max float32) layout.FlexChild {
+		return layout.Rigid(func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					gtx.Constraints.Min.X = gtx.Px(unit.Dp(24))
+					return material.Body2(th, label).Layout(gtx)
+				}),
+				layout.Flexed(1, material.Slider(th, f, 0, max).Layout),
+			)
+		})
+	}
+	before := s.hsv
+	opacity := float32(s.alpha) / 255
+	s.hue.Value = before.H
+	s.sat.Value = before.S
+	s.val.Value = before.V
+	s.opacity.Value = opacity
+
+	dims := layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return p.layoutWheel(gtx)
+		}),
+		slider("H", &s.hue, 359.9),
+		slider("S", &s.sat, 1),
+		slider("V", &s.val, 1),
+		slider("A", &s.opacity, 1),
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					sz := gtx.Px(unit.Dp(32))
+					return swatch(gtx, s.Color(), sz)
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					return layout.UniformInset(unit.Dp(8)).Layout(gtx, material.Editor(th, &s.hex, "#rrggbb").Layout)
+				}),
+				layout.Rigid(func(gtx C) D {
+					label := "Eyedropper"
+					if s.Picking {
+						label = "Cancel"
+					}
+					return material.Button(th, &s.eyedropper, label).Layout(gtx)
+				}),
+			)
+		}),
+	)
+
+	// Apply the sliders only if they moved, so they don't undo changes
+	// made with the wheel during the same frame.
+	if hsv := (HSV{H: s.hue.Value, S: s.sat.Value, V: s.val.Value}); hsv != before {
+		s.set(hsv, s.alpha)
+	}
+	if s.opacity.Value != opacity {
+		s.set(s.hsv, uint8(math.Round(float64(s.opacity.Value)*255)))
+	}
+	return dims
+}
+
+// layoutWheel lays out the hue and saturation wheel at the current value.
+func (p PickerStyle) layoutWheel(gtx C) D {
+	s := p.State
+	size := gtx.Px(p.WheelSize)
+	if size > gtx.Constraints.Max.X {
+		size = gtx.Constraints.Max.X
+	}
+	radius := float32(size) / 2
+	center := f32.Pt(radius, radius)
+
+	for _, e := range gtx.Events(s) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case pointer.Press, pointer.Drag:
+			if e.Type == pointer.Press {
+				s.dragging = true
+			}
+			d := e.Position.Sub(center)
+			dist := float32(math.Hypot(float64(d.X), float64(d.Y)))
+			hue := float32(math.Atan2(float64(d.Y), float64(d.X)) * 180 / math.Pi)
+			if hue < 0 {
+				hue += 360
+			}
+			sat := dist / radius
+			if sat > 1 {
+				sat = 1
+			}
+			s.set(HSV{H: hue, S: sat, V: s.hsv.V}, s.alpha)
+		case pointer.Release, pointer.Cancel:
+			s.dragging = false
+		}
+	}
+
+	if s.wheelSize != size || s.wheelV != s.hsv.V {
+		s.wheel = paint.NewImageOp(wheelImage(size, s.hsv.V))
+		s.wheelSize = size
+		s.wheelV = s.hsv.V
+	}
+
+	defer op.Save(gtx.Ops).Load()
+	r := image.Rect(0, 0, size, size)
+	pointer.Ellipse(r).Add(gtx.Ops)
+	pointer.InputOp{
+		Tag:   s,
+		Grab:  s.dragging,
+		Types: pointer.Press | pointer.Drag | pointer.Release,
+	}.Add(gtx.Ops)
+	pointer.CursorNameOp{Name: pointer.CursorCrossHair}.Add(gtx.Ops)
+
+	stack := op.Save(gtx.Ops)
+	clip.Rect(r).Add(gtx.Ops)
+	s.wheel.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+	stack.Load()
+
+	// The marker of the selected color.
+	angle := float64(s.hsv.H) * math.Pi / 180
+	pos := center.Add(f32.Pt(
+		float32(math.Cos(angle))*s.hsv.S*radius,
+		float32(math.Sin(angle))*s.hsv.S*radius,
+	))
+	m := float32(gtx.Px(unit.Dp(7)))
+	marker := f32.Rectangle{Min: pos.Sub(f32.Pt(m, m)), Max: pos.Add(f32.Pt(m, m))}
+	paint.FillShape(gtx.Ops, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, clip.UniformRRect(marker, m).Op(gtx.Ops))
+	m -= float32(gtx.Px(unit.Dp(2)))
+	marker = f32.Rectangle{Min: pos.Sub(f32.Pt(m, m)), Max: pos.Add(f32.Pt(m, m))}
+	paint.FillShape(gtx.Ops, s.hsv.RGB(0xff), clip.UniformRRect(marker, m).Op(gtx.Ops))
+	return D{Size: r.Size()}
+}
+
+// wheelImage renders the wheel of hues and saturations with value v.
+func wheelImage(size int, v float32) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	r := float64(size) / 2
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)+.5-r, float64(y)+.5-r
+			dist := math.Hypot(dx, dy)
+			if dist > r {
+				continue
+			}
+			hue := math.Atan2(dy, dx) * 180 / math.Pi
+			if hue < 0 {
+				hue += 360
+			}
+			c := HSV{H: float32(hue), S: float32(dist / r), V: v}.RGB(0xff)
+			// Anti-alias the rim.
+			if e := r - dist; e < 1 {
+				c.A = uint8(e * 255)
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// swatch draws a square of color c over a checkerboard that shows its
+// transparency.
+func swatch(gtx C, c color.NRGBA, size int) D {
+	defer op.Save(gtx.Ops).Load()
+	r := image.Rect(0, 0, size, size)
+	clip.UniformRRect(layout.FRect(r), float32(size)/8).Add(gtx.Ops)
+	paint.Fill(gtx.Ops, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+	cell := size / 4
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if (x+y)%2 == 0 {
+				continue
+			}
+			cr := image.Rect(x*cell, y*cell, (x+1)*cell, (y+1)*cell)
+			paint.FillShape(gtx.Ops, color.NRGBA{R: 0xcc, G: 0xcc, B: 0xcc, A: 0xff}, clip.Rect(cr).Op())
+		}
+	}
+	paint.Fill(gtx.Ops, c)
+	return D{Size: r.Size()}
+}
@@ -0,0 +1,273 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package compare implements a before and after comparison of two
+// images: the images are drawn over each other, split by a divider that
+// is dragged across to reveal one or the other. In Difference mode, the
+// after side shows the difference between the images instead, which
+// makes subtle changes stand out.
+package compare
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"gioui.org/f32"
+	"gioui.org/gesture"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+// Mode is what the after side of the divider shows.
+type Mode int
+
+const (
+	// Split shows the after image.
+	Split Mode = iota
+	// Difference shows the difference between the images.
+	Difference
+)
+
+// Compare is the state of a comparison.
+type Compare struct {
+	// Pos is the position of the divider, from 0 at the left edge of the
+	// images to 1 at the right edge. Set it to .5 to start in the middle.
+	Pos  float32
+	Mode Mode
+	// Gain multiplies the differences shown in Difference mode. Zero
+	// means 1.
+	Gain float32
+
+	before, after     *image.RGBA
+	beforeOp, afterOp paint.ImageOp
+	// diffOp is the image of the differences, computed when first shown.
+	diffOp   paint.ImageOp
+	diffGain float32
+	hasDiff  bool
+
+	drag     gesture.Drag
+	dragging bool
+	// width is the width of the images on screen in the last frame.
+	width float32
+}
+
+// SetImages sets the images to compare. The after image is scaled to
+// the size of the before image if they differ.
+func (c *Compare) SetImages(before, after image.Image) {
+	c.before = toRGBA(before, before.Bounds().Size())
+	c.after = toRGBA(after, c.before.Bounds().Size())
+	c.beforeOp = paint.NewImageOp(c.before)
+	c.afterOp = paint.NewImageOp(c.after)
+	c.hasDiff = false
+}
+
+// SetAfter replaces the after image, as when previewing a change of the
+// before image.
+func (c *Compare) SetAfter(after image.Image) {
+	if c.before == nil {
+		c.SetImages(after, after)
+		return
+	}
+	c.after = toRGBA(after, c.before.Bounds().Size())
+	c.afterOp = paint.NewImageOp(c.after)
+	c.hasDiff = false
+}
+
+// Dragging reports whether the divider is being dragged.
+func (c *Compare) Dragging() bool {
+	return c.dragging
+}
+
+// toRGBA returns img as an RGBA image of a size at the origin, scaled
+// with nearest neighbor sampling if it is of another size.
+func toRGBA(img image.Image, size image.Point) *image.RGBA {
+	b := img.Bounds()
+	if rgba, ok := img.(*image.RGBA); ok && b == (image.Rectangle{Max: size}) && rgba.Stride == 4*size.X {
+		return rgba
+	}
+	dst := image.NewRGBA(image.Rectangle{Max: size})
+	if b.Size() == size {
+		draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+		return dst
+	}
+	for y := 0; y < size.Y; y++ {
+		sy := b.Min.Y + y*b.Dy()/size.Y
+		for x := 0; x < size.X; x++ {
+			sx := b.Min.X + x*b.Dx()/size.X
+			dst.Set(x, y, img.At(sx, sy))
+		}
+	}
+	return dst
+}
+
+// Diff returns the absolute differences of the channels of two images
+// of the same size, multiplied by gain, and opaque.
+func Diff(a, b *image.RGBA, gain float32) *image.RGBA {
+	r := a.Bounds().Intersect(b.Bounds())
+	dst := image.NewRGBA(image.Rectangle{Max: r.Size()})
+	for y := 0; y < r.Dy(); y++ {
+		pa := a.Pix[a.PixOffset(r.Min.X, r.Min.Y+y):]
+		pb := b.Pix[b.PixOffset(r.Min.X, r.Min.Y+y):]
+		pd := dst.Pix[dst.PixOffset(0, y):]
+		for i := 0; i < 4*r.Dx(); i += 4 {
+			for ch := 0; ch < 3; ch++ {
+				d := int(pa[i+ch]) - int(pb[i+ch])
+				if d < 0 {
+					d = -d
+				}
+				v := float32(d) * gain
+				if v > 0xff {
+					v = 0xff
+				}
+				pd[i+ch] = uint8(v)
+			}
+			pd[i+3] = 0xff
+		}
+	}
+	return dst
+}
+
+func (c *Compare) gain() float32 {
+	if c.Gain == 0 {
+		return 1
+	}
+	return c.Gain
+}
+
+func (c *Compare) update(gtx layout.Context) {
+	for _, e := range c.drag.Events(gtx.Metric, gtx, gesture.Horizontal) {
+		switch e.Type {
+		case pointer.Press, pointer.Drag:
+			c.dragging = true
+			if c.width > 0 {
+				c.Pos = clamp(e.Position.X / c.width)
+			}
+		case pointer.Release, pointer.Cancel:
+			c.dragging = false
+		}
+	}
+}
+
+// Layout draws the images as large as fit the constraints, keeping
+// their aspect ratio, and centered. Pressing anywhere on the images
+// moves the divider there.
+func (c *Compare) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	c.update(gtx)
+	if c.before == nil {
+		return layout.Dimensions{Size: gtx.Constraints.Min}
+	}
+	isz := layout.FPt(c.before.Bounds().Size())
+	max := layout.FPt(gtx.Constraints.Max)
+	scale := max.X / isz.X
+	if s := max.Y / isz.Y; s < scale {
+		scale = s
+	}
+	size := isz.Mul(scale)
+	c.width = size.X
+	dims := gtx.Constraints.Constrain(image.Pt(int(size.X+.5), int(size.Y+.5)))
+
+	defer op.Save(gtx.Ops).Load()
+	op.Offset(layout.FPt(dims.Sub(image.Pt(int(size.X), int(size.Y)))).Mul(.5)).Add(gtx.Ops)
+	area := op.Save(gtx.Ops)
+	pointer.Rect(image.Rect(0, 0, int(size.X), int(size.Y))).Add(gtx.Ops)
+	pointer.CursorNameOp{Name: pointer.CursorColResize}.Add(gtx.Ops)
+	c.drag.Add(gtx.Ops)
+	area.Load()
+
+	split := size.X * c.Pos
+	after := c.afterOp
+	if c.Mode == Difference {
+		if !c.hasDiff || c.diffGain != c.gain() {
+			c.diffOp = paint.NewImageOp(Diff(c.before, c.after, c.gain()))
+			c.diffGain, c.hasDiff = c.gain(), true
+		}
+		after = c.diffOp
+	}
+	drawImage := func(img paint.ImageOp, r f32.Rectangle) {
+		st := op.Save(gtx.Ops)
+		clip.RRect{Rect: r}.Add(gtx.Ops)
+		op.Affine(f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(scale, scale))).Add(gtx.Ops)
+		img.Add(gtx.Ops)
+		paint.PaintOp{}.Add(gtx.Ops)
+		st.Load()
+	}
+	drawImage(c.beforeOp, f32.Rectangle{Max: f32.Pt(split, size.Y)})
+	drawImage(after, f32.Rectangle{Min: f32.Pt(split, 0), Max: size})
+
+	c.layoutDivider(gtx, split, size.Y)
+	c.layoutLabels(gtx, th, size)
+	return layout.Dimensions{Size: dims}
+}
+
+var (
+	dividerColor = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	labelBg      = color.NRGBA{A: 0x90}
+)
+
+// layoutDivider draws the divider at x, with a round handle halfway.
+func (c *Compare) layoutDivider(gtx layout.Context, x, height float32) {
+	w := float32(gtx.Px(unit.Dp(2)))
+	paint.FillShape(gtx.Ops, dividerColor, clip.RRect{Rect: f32.Rect(x-w/2, 0, x+w/2, height)}.Op(gtx.Ops))
+	r := float32(gtx.Px(unit.Dp(16)))
+	if c.dragging {
+		r *= 1.15
+	}
+	center := f32.Pt(x, height/2)
+	circle := f32.Rectangle{Min: center.Sub(f32.Pt(r, r)), Max: center.Add(f32.Pt(r, r))}
+	paint.FillShape(gtx.Ops, dividerColor, clip.UniformRRect(circle, r).Op(gtx.Ops))
+	// Arrows pointing both ways.
+	a := r / 2.5
+	var p clip.Path
+	for _, dir := range []float32{-1, 1} {
+		tip := center.Add(f32.Pt(dir*r*0.7, 0))
+		p.Begin(gtx.Ops)
+		p.MoveTo(tip)
+		p.LineTo(tip.Add(f32.Pt(-dir*a, -a)))
+		p.LineTo(tip.Add(f32.Pt(-dir*a, a)))
+		p.Close()
+		paint.FillShape(gtx.Ops, color.NRGBA{A: 0xb0}, clip.Outline{Path: p.End()}.Op())
+	}
+}
+
+// layoutLabels labels the sides of the divider, in the top corners.
+func (c *Compare) layoutLabels(gtx layout.Context, th *material.Theme, size f32.Point) {
+	right := "After"
+	if c.Mode == Difference {
+		right = "Difference"
+	}
+	gtx.Constraints = layout.Exact(image.Pt(int(size.X), int(size.Y)))
+	label := func(txt string) layout.Widget {
+		return func(gtx layout.Context) layout.Dimensions {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				m := op.Record(gtx.Ops)
+				dims := layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					l := material.Caption(th, txt)
+					l.Color = dividerColor
+					return l.Layout(gtx)
+				})
+				call := m.Stop()
+				rr := float32(gtx.Px(unit.Dp(4)))
+				paint.FillShape(gtx.Ops, labelBg, clip.UniformRRect(f32.Rectangle{Max: layout.FPt(dims.Size)}, rr).Op(gtx.Ops))
+				call.Add(gtx.Ops)
+				return dims
+			})
+		}
+	}
+	layout.NW.Layout(gtx, label("Before"))
+	layout.NE.Layout(gtx, label(right))
+}
+
+func clamp(v float32) float32 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
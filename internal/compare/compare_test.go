@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package compare
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/widget/material"
+)
+
+func TestDiff(t *testing.T) {
+	a := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	b := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	a.SetRGBA(0, 0, color.RGBA{R: 100, G: 50, B: 0, A: 0xff})
+	b.SetRGBA(0, 0, color.RGBA{R: 90, G: 60, B: 200, A: 0xff})
+	a.SetRGBA(1, 0, color.RGBA{R: 7, G: 7, B: 7, A: 0xff})
+	b.SetRGBA(1, 0, color.RGBA{R: 7, G: 7, B: 7, A: 0xff})
+	d := Diff(a, b, 2)
+	if got, want := d.RGBAAt(0, 0), (color.RGBA{R: 20, G: 20, B: 0xff, A: 0xff}); got != want {
+		t.Errorf("difference %v, want %v", got, want)
+	}
+	if got, want := d.RGBAAt(1, 0), (color.RGBA{A: 0xff}); got != want {
+		t.Errorf("difference of equal pixels %v, want %v", got, want)
+	}
+}
+
+func TestScale(t *testing.T) {
+	small := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	small.Set(1, 1, color.NRGBA{R: 0xff, A: 0xff})
+	var c Compare
+	c.SetImages(image.NewRGBA(image.Rect(0, 0, 4, 4)), small)
+	if got := c.after.Bounds(); got != image.Rect(0, 0, 4, 4) {
+		t.Fatalf("after image scaled to %v, want the before size", got)
+	}
+	if got := c.after.RGBAAt(3, 3); got.R != 0xff {
+		t.Errorf("scaled pixel %v, want red", got)
+	}
+}
+
+func TestDrag(t *testing.T) {
+	th := material.NewTheme(gofont.Collection())
+	c := &Compare{Pos: .5}
+	c.SetImages(image.NewRGBA(image.Rect(0, 0, 100, 50)), image.NewRGBA(image.Rect(0, 0, 100, 50)))
+	var r router.Router
+	ops := new(op.Ops)
+	frame := func() layout.Dimensions {
+		ops.Reset()
+		gtx := layout.Context{Ops: ops, Queue: &r, Constraints: layout.Exact(image.Pt(400, 400))}
+		dims := c.Layout(gtx, th)
+		r.Frame(ops)
+		return dims
+	}
+	if dims := frame(); dims.Size != image.Pt(400, 400) {
+		t.Errorf("laid out at %v, want the constraints", dims.Size)
+	}
+	// The images are 400 by 200, centered vertically.
+	r.Queue(
+		pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(100, 200)},
+		pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: f32.Pt(100, 200)},
+	)
+	frame()
+	if c.Pos != .25 {
+		t.Errorf("divider at %v after a press at a quarter, want .25", c.Pos)
+	}
+	if c.Dragging() {
+		t.Error("dragging after the release")
+	}
+}
@@ -0,0 +1,335 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package complete implements typeahead completion for widget.Editor: a
+// popup of suggestions below the caret, fetched from an asynchronous
+// source as the user types.
+package complete
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"time"
+	"unicode/utf8"
+
+	"gioui.org/f32"
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Completer shows completions for the text of an editor.
+//
+// An editor receives all key presses while focused, so while the popup
+// is open the Completer takes the focus to handle the arrow keys, Tab,
+// Enter and Escape, and forwards text input and deletions to the editor.
+// Other keys close the popup and return the focus to the editor.
+//
+// The popup is drawn below the editor, outside its bounds. Lay out the
+// Completer after the widgets the popup may cover.
+type Completer struct {
+	Editor *widget.Editor
+	Source Source
+	// Token selects the text to complete. It defaults to All.
+	Token Token
+	// Delay is how long to wait after typing before querying the source.
+	Delay time.Duration
+	// MinLength is the shortest query completed.
+	MinLength int
+	// MaxVisible is the number of suggestions shown. It defaults to 8.
+	MaxVisible int
+	// Invalidate is called when the source returns, to redraw the window.
+	Invalidate func()
+
+	text string
+	// start and end are the rune range of the query.
+	start, end int
+	gen        int
+	cancel     context.CancelFunc
+	results    chan result
+	loading    bool
+
+	items    []Suggestion
+	selected int
+	open     bool
+	focused  bool
+	// refocus requests the focus for the completer.
+	refocus  bool
+	clicks   []widget.Clickable
+	accepted []Suggestion
+}
+
+type result struct {
+	gen   int
+	items []Suggestion
+	err   error
+}
+
+// Accepted returns the next suggestion accepted, if any.
+func (c *Completer) Accepted() (Suggestion, bool) {
+	if len(c.accepted) == 0 {
+		return Suggestion{}, false
+	}
+	s := c.accepted[0]
+	c.accepted = c.accepted[1:]
+	return s, true
+}
+
+// Loading reports whether the source is running.
+func (c *Completer) Loading() bool {
+	return c.loading
+}
+
+// Close closes the popup, returning the focus to the editor.
+func (c *Completer) Close() {
+	if !c.open {
+		return
+	}
+	c.open = false
+	c.focused = false
+	c.Editor.Focus()
+}
+
+func (c *Completer) update() {
+	if c.results == nil {
+		c.results = make(chan result, 1)
+	}
+	// Detect changes by comparing the text, leaving the events of the
+	// editor to its owner.
+	if txt := c.Editor.Text(); txt != c.text {
+		c.text = txt
+		c.query()
+	}
+	select {
+	case r := <-c.results:
+		if r.gen != c.gen {
+			break
+		}
+		c.loading = false
+		c.items = r.items
+		c.selected = 0
+		if len(c.clicks) < len(c.items) {
+			c.clicks = make([]widget.Clickable, len(c.items))
+		}
+		switch open := r.err == nil && len(c.items) > 0; {
+		case open && !c.open && c.Editor.Focused():
+			c.open = true
+			c.refocus = true
+		case !open:
+			c.Close()
+		}
+	default:
+	}
+	for i := range c.items {
+		for c.clicks[i].Clicked() {
+			c.accept(i)
+		}
+	}
+}
+
+// query starts the source for the token at the caret.
+func (c *Completer) query() {
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
+	c.gen++
+	c.loading = false
+	token := c.Token
+	if token == nil {
+		token = All
+	}
+	text := []rune(c.text)
+	caret, _ := c.Editor.Selection()
+	if caret > len(text) {
+		caret = len(text)
+	}
+	c.start, c.end = token(text, caret)
+	q := string(text[c.start:caret])
+	if utf8.RuneCountInString(q) < c.MinLength || q == "" {
+		c.Close()
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.loading = true
+	gen, delay, src, results, invalidate := c.gen, c.Delay, c.Source, c.results, c.Invalidate
+	go func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		items, err := src(ctx, q)
+		if ctx.Err() != nil {
+			return
+		}
+		// Replace a stale result not yet seen.
+		select {
+		case <-results:
+		default:
+		}
+		results <- result{gen: gen, items: items, err: err}
+		if invalidate != nil {
+			invalidate()
+		}
+	}()
+}
+
+// accept replaces the token with a suggestion.
+func (c *Completer) accept(i int) {
+	s := c.items[i]
+	text := []rune(c.Editor.Text())
+	if c.end > len(text) {
+		return
+	}
+	repl := []rune(s.Text)
+	newText := string(text[:c.start]) + s.Text + string(text[c.end:])
+	c.Editor.SetText(newText)
+	caret := c.start + len(repl)
+	c.Editor.SetCaret(caret, caret)
+	// Don't complete the accepted text.
+	c.text = newText
+	c.gen++
+	c.loading = false
+	c.accepted = append(c.accepted, s)
+	c.Close()
+}
+
+func (c *Completer) handleKeys(gtx layout.Context) {
+	for _, e := range gtx.Events(c) {
+		switch e := e.(type) {
+		case key.FocusEvent:
+			c.focused = e.Focus
+		case key.EditEvent:
+			c.Editor.Insert(e.Text)
+		case key.Event:
+			if e.State != key.Press {
+				break
+			}
+			n := len(c.items)
+			if n > c.maxVisible() {
+				n = c.maxVisible()
+			}
+			switch e.Name {
+			case key.NameUpArrow:
+				c.selected = (c.selected - 1 + n) % n
+			case key.NameDownArrow:
+				c.selected = (c.selected + 1) % n
+			case key.NameTab, key.NameReturn, key.NameEnter:
+				c.accept(c.selected)
+			case key.NameDeleteBackward:
+				c.Editor.Delete(-1)
+			case key.NameDeleteForward:
+				c.Editor.Delete(1)
+			default:
+				c.Close()
+			}
+		}
+	}
+}
+
+func (c *Completer) maxVisible() int {
+	if c.MaxVisible > 0 {
+		return c.MaxVisible
+	}
+	return 8
+}
+
+// Layout lays out the editor with w, and the popup of suggestions if
+// open.
+func (c *Completer) Layout(gtx layout.Context, th *material.Theme, w layout.Widget) layout.Dimensions {
+	c.update()
+	c.handleKeys(gtx)
+	dims := w(gtx)
+	if c.open && c.Editor.Focused() && !c.refocus {
+		// The editor was clicked.
+		c.open = false
+		c.focused = false
+	}
+	if !c.open {
+		return dims
+	}
+	key.InputOp{Tag: c}.Add(gtx.Ops)
+	if c.refocus {
+		key.FocusOp{Tag: c}.Add(gtx.Ops)
+		c.refocus = false
+	}
+	caret := c.Editor.CaretCoords()
+	lineHeight := float32(gtx.Px(th.TextSize))
+	if c.focused {
+		// The editor doesn't draw its caret while unfocused.
+		width := float32(gtx.Px(unit.Dp(1)))
+		r := f32.Rect(caret.X, caret.Y-lineHeight*0.8, caret.X+width, caret.Y+lineHeight*0.2)
+		paint.FillShape(gtx.Ops, th.Palette.Fg, clip.RRect{Rect: r}.Op(gtx.Ops))
+	}
+	defer op.Save(gtx.Ops).Load()
+	op.Offset(f32.Pt(caret.X, caret.Y+lineHeight*0.4)).Add(gtx.Ops)
+	c.layoutPopup(gtx, th)
+	return dims
+}
+
+func (c *Completer) layoutPopup(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	items := c.items
+	if len(items) > c.maxVisible() {
+		items = items[:c.maxVisible()]
+	}
+	gtx.Constraints.Min = image.Point{}
+	gtx.Constraints.Max.X = gtx.Px(unit.Dp(280))
+	children := make([]layout.FlexChild, len(items))
+	for i := range items {
+		i := i
+		children[i] = layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.Clickable(gtx, &c.clicks[i], func(gtx layout.Context) layout.Dimensions {
+				return c.layoutItem(gtx, th, i)
+			})
+		})
+	}
+	macro := op.Record(gtx.Ops)
+	dims := layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	})
+	call := macro.Stop()
+	rr := float32(gtx.Px(unit.Dp(4)))
+	bounds := f32.Rectangle{Max: layout.FPt(dims.Size)}
+	shadow := bounds
+	shadow.Min = shadow.Min.Add(f32.Pt(-1, -1))
+	shadow.Max = shadow.Max.Add(f32.Pt(1, 2))
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 0x40}, clip.UniformRRect(shadow, rr).Op(gtx.Ops))
+	paint.FillShape(gtx.Ops, th.Palette.Bg, clip.UniformRRect(bounds, rr).Op(gtx.Ops))
+	call.Add(gtx.Ops)
+	return dims
+}
+
+func (c *Completer) layoutItem(gtx layout.Context, th *material.Theme, i int) layout.Dimensions {
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	s := c.items[i]
+	macro := op.Record(gtx.Ops)
+	dims := layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4), Left: unit.Dp(12), Right: unit.Dp(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Alignment: layout.Baseline}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				l := material.Body1(th, s.Text)
+				l.MaxLines = 1
+				return l.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				l := material.Caption(th, s.Detail)
+				l.Color.A = 0x90
+				return l.Layout(gtx)
+			}),
+		)
+	})
+	call := macro.Stop()
+	if i == c.selected {
+		bg := th.Palette.ContrastBg
+		bg.A = 0x30
+		paint.FillShape(gtx.Ops, bg, clip.Rect(image.Rectangle{Max: dims.Size}).Op())
+	}
+	call.Add(gtx.Ops)
+	return dims
+}
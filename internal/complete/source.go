@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package complete
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Suggestion is a completion offered for a query.
+type Suggestion struct {
+	// Text replaces the query when the suggestion is accepted.
+	Text string
+	// Detail is shown dimmed next to the text, such as the kind of a
+	// symbol.
+	Detail string
+}
+
+// Source returns the suggestions for a query. It is called on a separate
+// goroutine and ctx is cancelled when the query changes before it
+// returns.
+type Source func(ctx context.Context, query string) ([]Suggestion, error)
+
+// Static returns a source suggesting the words with the query as prefix,
+// ignoring case, in alphabetical order. Exact matches are left out.
+func Static(words ...string) Source {
+	sorted := append([]string(nil), words...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return strings.ToLower(sorted[i]) < strings.ToLower(sorted[j])
+	})
+	return func(ctx context.Context, query string) ([]Suggestion, error) {
+		q := strings.ToLower(query)
+		var res []Suggestion
+		for _, w := range sorted {
+			lw := strings.ToLower(w)
+			if strings.HasPrefix(lw, q) && lw != q {
+				res = append(res, Suggestion{Text: w})
+			}
+		}
+		return res, nil
+	}
+}
+
+// Token returns the range of runes around the caret to complete.
+type Token func(text []rune, caret int) (start, end int)
+
+// All is a Token for completing the whole text, such as a search query.
+func All(text []rune, caret int) (start, end int) {
+	return 0, len(text)
+}
+
+// Identifier is a Token for completing the identifier ending at the
+// caret, such as in a code editor. The characters of the identifier
+// after the caret are replaced as well.
+func Identifier(text []rune, caret int) (start, end int) {
+	isIdent := func(r rune) bool {
+		return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	start, end = caret, caret
+	for start > 0 && isIdent(text[start-1]) {
+		start--
+	}
+	for end < len(text) && isIdent(text[end]) {
+		end++
+	}
+	return start, end
+}
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package complete
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestStatic(t *testing.T) {
+	src := Static("banana", "Apple", "apricot", "app")
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"ap", []string{"app", "Apple", "apricot"}},
+		{"APP", []string{"Apple"}},
+		{"c", nil},
+	}
+	for _, test := range tests {
+		res, err := src(context.Background(), test.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, s := range res {
+			got = append(got, s.Text)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Static(%q) = %q, want %q", test.query, got, test.want)
+		}
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	text := []rune("x := fmt.Spri(a_b1)")
+	tests := []struct {
+		caret      int
+		start, end int
+	}{
+		{0, 0, 1},
+		{2, 2, 2},
+		{12, 9, 13},
+		{13, 9, 13},
+		{16, 14, 18},
+		{19, 19, 19},
+	}
+	for _, test := range tests {
+		start, end := Identifier(text, test.caret)
+		if start != test.start || end != test.end {
+			t.Errorf("Identifier(%d) = %d, %d, want %d, %d", test.caret, start, end, test.start, test.end)
+		}
+	}
+}
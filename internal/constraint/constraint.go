@@ -0,0 +1,263 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package constraint implements a small anchor based layout, in the
+// spirit of Android's ConstraintLayout and Apple's Auto Layout. Instead of
+// nesting Flex and Stack layouts, each widget is positioned by pinning one
+// of its edges to an edge of the parent, a sibling or a guide.
+//
+// A typical use, placing a label to the right of an icon and centering
+// it vertically against the icon:
+//
+//	var l constraint.Layout
+//	icon := l.Add(iconWidget)
+//	label := l.Add(labelWidget)
+//	l.Align(icon.Left(), l.Parent().Left(), unit.Dp(16))
+//	l.Align(icon.Top(), l.Parent().Top(), unit.Dp(16))
+//	l.Align(label.Left(), icon.Right(), unit.Dp(8))
+//	l.Align(label.CenterY(), icon.CenterY(), unit.Value{})
+//	l.Layout(gtx)
+//
+// Layouts are cheap to build and meant to be rebuilt every frame.
+package constraint
+
+import (
+	"fmt"
+	"image"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+)
+
+// Edge is an edge or center line of an item.
+type Edge uint8
+
+// Edges of an item. Left, Right and CenterX can only be aligned with each
+// other, and likewise for Top, Bottom and CenterY.
+const (
+	Left Edge = iota
+	Right
+	CenterX
+	Top
+	Bottom
+	CenterY
+)
+
+func (e Edge) vertical() bool {
+	return e >= Top
+}
+
+// Anchor identifies an edge of an item.
+type Anchor struct {
+	item *Item
+	edge Edge
+}
+
+// Item is a widget or guide in a Layout.
+type Item struct {
+	w     layout.Widget
+	guide bool
+
+	call  op.CallOp
+	size  image.Point
+	pos   image.Point
+	rules [2]rule
+	// state tracks the resolution of each axis, to detect cycles.
+	state [2]uint8
+}
+
+type rule struct {
+	set    bool
+	edge   Edge
+	from   Anchor
+	to     Anchor
+	center bool
+	margin unit.Value
+}
+
+const (
+	unresolved uint8 = iota
+	resolving
+	resolved
+)
+
+// Left returns the anchor of the left edge of the item.
+func (it *Item) Left() Anchor { return Anchor{it, Left} }
+
+// Right returns the anchor of the right edge of the item.
+func (it *Item) Right() Anchor { return Anchor{it, Right} }
+
+// CenterX returns the anchor of the vertical center line of the item.
+func (it *Item) CenterX() Anchor { return Anchor{it, CenterX} }
+
+// Top returns the anchor of the top edge of the item.
+func (it *Item) Top() Anchor { return Anchor{it, Top} }
+
+// Bottom returns the anchor of the bottom edge of the item.
+func (it *Item) Bottom() Anchor { return Anchor{it, Bottom} }
+
+// CenterY returns the anchor of the horizontal center line of the item.
+func (it *Item) CenterY() Anchor { return Anchor{it, CenterY} }
+
+// Layout positions items according to their anchors. The zero value is
+// ready to use.
+type Layout struct {
+	parent Item
+	items  []*Item
+	guides []guide
+}
+
+type guide struct {
+	item     *Item
+	vertical bool
+	fraction float32
+}
+
+// Parent returns the item representing the maximum constraints. Anchoring
+// to the bottom or vertical center of the parent requires a bounded
+// maximum height.
+func (l *Layout) Parent() *Item {
+	return &l.parent
+}
+
+// Add adds a widget to the layout. Unanchored axes default to the top
+// left corner of the parent.
+func (l *Layout) Add(w layout.Widget) *Item {
+	it := &Item{w: w}
+	l.items = append(l.items, it)
+	return it
+}
+
+// VGuide returns a vertical guide line at a fraction of the parent width.
+func (l *Layout) VGuide(fraction float32) *Item {
+	it := &Item{guide: true}
+	l.guides = append(l.guides, guide{item: it, vertical: true, fraction: fraction})
+	return it
+}
+
+// HGuide returns a horizontal guide line at a fraction of the parent
+// height.
+func (l *Layout) HGuide(fraction float32) *Item {
+	it := &Item{guide: true}
+	l.guides = append(l.guides, guide{item: it, fraction: fraction})
+	return it
+}
+
+// Align places the edge a at the position of edge b plus margin.
+func (l *Layout) Align(a, b Anchor, margin unit.Value) {
+	l.setRule(a, rule{from: b, margin: margin})
+}
+
+// CenterBetween places the edge a halfway between the edges from and to.
+func (l *Layout) CenterBetween(a, from, to Anchor) {
+	l.setRule(a, rule{from: from, to: to, center: true})
+}
+
+func (l *Layout) setRule(a Anchor, r rule) {
+	if r.from.edge.vertical() != a.edge.vertical() || (r.center && r.to.edge.vertical() != a.edge.vertical()) {
+		panic(fmt.Sprintf("constraint: anchors on different axes: %v, %v", a.edge, r.from.edge))
+	}
+	r.set = true
+	r.edge = a.edge
+	axis := 0
+	if a.edge.vertical() {
+		axis = 1
+	}
+	a.item.rules[axis] = r
+}
+
+// Layout measures the widgets, resolves their positions and draws them.
+// The width of the result is the maximum width, the height is the bottom
+// of the lowest item.
+func (l *Layout) Layout(gtx layout.Context) layout.Dimensions {
+	max := gtx.Constraints.Max
+	l.parent.size = max
+	l.parent.state = [2]uint8{resolved, resolved}
+	for _, g := range l.guides {
+		g.item.state = [2]uint8{resolved, resolved}
+		if g.vertical {
+			g.item.pos.X = int(g.fraction * float32(max.X))
+		} else {
+			g.item.pos.Y = int(g.fraction * float32(max.Y))
+		}
+	}
+	cgtx := gtx
+	cgtx.Constraints.Min = image.Point{}
+	for _, it := range l.items {
+		m := op.Record(gtx.Ops)
+		it.size = it.w(cgtx).Size
+		it.call = m.Stop()
+		it.state = [2]uint8{}
+	}
+	height := 0
+	for _, it := range l.items {
+		l.resolve(gtx, it, 0)
+		l.resolve(gtx, it, 1)
+		if b := it.pos.Y + it.size.Y; b > height {
+			height = b
+		}
+		stack := op.Save(gtx.Ops)
+		op.Offset(layout.FPt(it.pos)).Add(gtx.Ops)
+		it.call.Add(gtx.Ops)
+		stack.Load()
+	}
+	return layout.Dimensions{Size: gtx.Constraints.Constrain(image.Pt(max.X, height))}
+}
+
+// resolve computes the position of it along axis.
+func (l *Layout) resolve(gtx layout.Context, it *Item, axis int) {
+	switch it.state[axis] {
+	case resolved:
+		return
+	case resolving:
+		panic("constraint: cyclic anchors")
+	}
+	it.state[axis] = resolving
+	r := it.rules[axis]
+	var p float32
+	if r.set {
+		p = l.position(gtx, r.from)
+		if r.center {
+			p = (p + l.position(gtx, r.to)) / 2
+		}
+		p += float32(gtx.Px(r.margin))
+		size := it.size.X
+		if axis == 1 {
+			size = it.size.Y
+		}
+		p -= offset(r.edge, size)
+	}
+	if axis == 0 {
+		it.pos.X = int(p + .5)
+	} else {
+		it.pos.Y = int(p + .5)
+	}
+	it.state[axis] = resolved
+}
+
+// position returns the resolved position of an anchor.
+func (l *Layout) position(gtx layout.Context, a Anchor) float32 {
+	axis := 0
+	if a.edge.vertical() {
+		axis = 1
+	}
+	l.resolve(gtx, a.item, axis)
+	pos := layout.FPt(a.item.pos)
+	size := layout.FPt(a.item.size)
+	if axis == 0 {
+		return pos.X + offset(a.edge, int(size.X))
+	}
+	return pos.Y + offset(a.edge, int(size.Y))
+}
+
+// offset returns the distance from the leading edge of an item to edge.
+func offset(e Edge, size int) float32 {
+	switch e {
+	case Right, Bottom:
+		return float32(size)
+	case CenterX, CenterY:
+		return float32(size) / 2
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package cue plays short sounds for feedback on user actions: a click, a
+// success and an error. Play returns at once and the sound plays in the
+// background, and SetMuted silences every cue of the program:
+//
+//	for button.Clicked() {
+//		cue.Play(cue.Click)
+//		...
+//	}
+//
+// The sounds are synthesized and prepared for playing the first time
+// they are played, which keeps later plays quick, and Close releases
+// them when the program exits.
+//
+// Sounds play through package internal/chime, which has no player on
+// Android and iOS, nor on Linux and the BSDs without paplay, pw-play or
+// aplay. There the first cue logs why and turns the cues off: later cues
+// are silent without trying again, and Err reports the error.
+package cue
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"gioui.org/example/internal/chime"
+)
+
+// Cue is a feedback sound.
+type Cue int
+
+const (
+	// Click acknowledges a press of a button.
+	Click Cue = iota
+	// Success announces a completed operation.
+	Success
+	// Error announces a failed operation.
+	Error
+)
+
+// sounds synthesizes the WAV encoding of the cues: a short tick, two
+// rising notes and two low falling notes.
+var sounds = [...]func() []byte{
+	Click:   func() []byte { return chime.Notes(25*time.Millisecond, 1760) },
+	Success: func() []byte { return chime.Notes(90*time.Millisecond, 784, 1175) },
+	Error:   func() []byte { return chime.Notes(140*time.Millisecond, 311, 233) },
+}
+
+var (
+	mu    sync.Mutex
+	muted bool
+	err   error
+	// off is set when the platform can't play the cues.
+	off bool
+
+	// loadMu guards loaded, apart from mu because preparing a sound
+	// takes a while.
+	loadMu sync.Mutex
+	loaded [len(sounds)]*chime.Sound
+)
+
+// SetMuted mutes or unmutes every cue.
+func SetMuted(m bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	muted = m
+}
+
+// Muted reports whether cues are muted.
+func Muted() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return muted
+}
+
+// Err returns the error of the latest cue that failed to play, if any.
+func Err() error {
+	mu.Lock()
+	defer mu.Unlock()
+	return err
+}
+
+// Play starts playing c, unless cues are muted or the platform can't
+// play them.
+func Play(c Cue) {
+	mu.Lock()
+	silent := muted || off
+	mu.Unlock()
+	if silent {
+		return
+	}
+	go func() {
+		s, e := load(c)
+		if e == nil {
+			e = s.Play()
+		}
+		if e != nil {
+			fail(e)
+		}
+	}()
+}
+
+// fail records the error of a cue, and turns the cues off if the
+// platform has no player.
+func fail(e error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if off {
+		return
+	}
+	// Log the first failure only, not every click.
+	if errors.Is(e, chime.ErrUnsupported) {
+		off = true
+		log.Printf("cue: sounds are off: %v", e)
+	} else if err == nil {
+		log.Printf("cue: %v", e)
+	}
+	err = e
+}
+
+// Close releases the prepared sounds, such as the temporary files of the
+// command line players. Call it when the program exits; a cue played
+// after Close prepares its sound again.
+func Close() error {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+	var first error
+	for i, s := range loaded {
+		if s == nil {
+			continue
+		}
+		if e := s.Close(); e != nil && first == nil {
+			first = e
+		}
+		loaded[i] = nil
+	}
+	return first
+}
+
+// load returns the sound of c, prepared the first time.
+func load(c Cue) (*chime.Sound, error) {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+	if s := loaded[c]; s != nil {
+		return s, nil
+	}
+	s, e := chime.Load(sounds[c]())
+	if e != nil {
+		return nil, e
+	}
+	loaded[c] = s
+	return s, nil
+}
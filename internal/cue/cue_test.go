@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package cue
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"gioui.org/example/internal/chime"
+)
+
+func TestSounds(t *testing.T) {
+	for c, synth := range sounds {
+		wav := synth()
+		if len(wav) < 44 || string(wav[:4]) != "RIFF" {
+			t.Fatalf("cue %d is not a WAV file", c)
+		}
+		// The cues must be short enough not to overlap the next
+		// action.
+		data := binary.LittleEndian.Uint32(wav[40:])
+		if d := float64(data) / 2 / 44100; d > 0.3 {
+			t.Errorf("cue %d lasts %.2fs", c, d)
+		}
+	}
+}
+
+func TestMuted(t *testing.T) {
+	SetMuted(true)
+	defer SetMuted(false)
+	if !Muted() {
+		t.Fatal("not muted after SetMuted(true)")
+	}
+	// A muted cue doesn't even load its sound.
+	Play(Click)
+	loadMu.Lock()
+	defer loadMu.Unlock()
+	if loaded[Click] != nil {
+		t.Error("a muted cue loaded its sound")
+	}
+}
+
+func TestClose(t *testing.T) {
+	loadMu.Lock()
+	loaded[Click] = nil
+	loadMu.Unlock()
+	s, err := load(Click)
+	if err != nil {
+		t.Skipf("no audio player: %v", err)
+	}
+	if s2, _ := load(Click); s2 != s {
+		t.Error("a loaded sound was prepared again")
+	}
+	if err := Close(); err != nil {
+		t.Fatal(err)
+	}
+	loadMu.Lock()
+	defer loadMu.Unlock()
+	for c, s := range loaded {
+		if s != nil {
+			t.Errorf("cue %d is still loaded after Close", c)
+		}
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	defer func() {
+		mu.Lock()
+		off, err = false, nil
+		mu.Unlock()
+	}()
+	loadMu.Lock()
+	loaded[Error] = nil
+	loadMu.Unlock()
+	fail(fmt.Errorf("%w on plan9", chime.ErrUnsupported))
+	if Err() == nil {
+		t.Fatal("no error after a failed cue")
+	}
+	// Cues are off for good; they don't try loading their sounds.
+	Play(Error)
+	loadMu.Lock()
+	defer loadMu.Unlock()
+	if loaded[Error] != nil {
+		t.Error("a cue loaded its sound after the player was found missing")
+	}
+}
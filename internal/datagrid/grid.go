@@ -0,0 +1,388 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package datagrid implements a virtualized table: only the visible rows
+// are laid out, so tables of millions of rows scroll as fast as small
+// ones. The header stays in place, columns can be resized by dragging
+// their edges and sorted by clicking their titles, and the columns scroll
+// horizontally when they don't fit.
+package datagrid
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Column describes a column of a Grid.
+type Column struct {
+	Title string
+	// Width is the initial width of the column.
+	Width unit.Value
+	// Alignment aligns the title.
+	Alignment text.Alignment
+}
+
+// Cell lays out the cell at a row and column.
+type Cell func(gtx layout.Context, row, col int) layout.Dimensions
+
+// Grid holds the state of a table.
+type Grid struct {
+	Columns []Column
+	// SortColumn is the column sorted by, or -1.
+	SortColumn int
+	// SortDesc reports whether the sort is in decreasing order.
+	SortDesc bool
+
+	list    layout.List
+	headers []widget.Clickable
+	resizes []resizer
+	// widths are the column widths in Dp, which scale with the interface.
+	widths []float32
+	// scrollX is the horizontal scroll offset.
+	scrollX int
+	hbar    hscroll
+	sorted  bool
+	selRow  int
+	selCol  int
+	// rowTags are the pointer tags of the visible rows.
+	rowTags map[int]*int
+}
+
+type resizer struct {
+	dragging   bool
+	start      float32
+	startWidth float32
+}
+
+// hscroll is a horizontal scrollbar.
+type hscroll struct {
+	dragging bool
+	grab     float32
+}
+
+// New returns a Grid of columns, unsorted and with no selection.
+func New(cols ...Column) *Grid {
+	return &Grid{
+		Columns:    cols,
+		SortColumn: -1,
+		selRow:     -1,
+		selCol:     -1,
+	}
+}
+
+// Sorted reports whether the sort order changed by a click on a column
+// title since the last call.
+func (g *Grid) Sorted() bool {
+	s := g.sorted
+	g.sorted = false
+	return s
+}
+
+// Selection returns the cell clicked last, or -1, -1.
+func (g *Grid) Selection() (row, col int) {
+	return g.selRow, g.selCol
+}
+
+// ScrollTo scrolls the row into view at the top.
+func (g *Grid) ScrollTo(row int) {
+	g.list.Position = layout.Position{First: row, BeforeEnd: true}
+}
+
+// Position returns the scroll position of the rows.
+func (g *Grid) Position() layout.Position {
+	return g.list.Position
+}
+
+func (g *Grid) init() {
+	n := len(g.Columns)
+	if len(g.widths) == n {
+		return
+	}
+	g.headers = make([]widget.Clickable, n)
+	g.resizes = make([]resizer, n)
+	g.widths = make([]float32, n)
+	for i, c := range g.Columns {
+		w := c.Width
+		if w.V == 0 {
+			w = unit.Dp(120)
+		}
+		g.widths[i] = w.V
+	}
+	g.list.Axis = layout.Vertical
+	if g.rowTags == nil {
+		g.rowTags = make(map[int]*int)
+	}
+}
+
+// Layout lays out the header and the visible rows of a table of rows
+// rows, with cells laid out by cell.
+func (g *Grid) Layout(gtx layout.Context, th *material.Theme, rows int, cell Cell) layout.Dimensions {
+	g.init()
+	g.update(gtx)
+	size := gtx.Constraints.Max
+	px := make([]int, len(g.widths))
+	total := 0
+	for i, w := range g.widths {
+		px[i] = gtx.Px(unit.Dp(w))
+		total += px[i]
+	}
+	barHeight := 0
+	if total > size.X {
+		barHeight = gtx.Px(unit.Dp(10))
+	}
+	if max := total - size.X; g.scrollX > max {
+		g.scrollX = max
+	}
+	if g.scrollX < 0 {
+		g.scrollX = 0
+	}
+
+	header := g.layoutHeader(gtx, th, px)
+	body := size.Y - header - barHeight
+	if body < 0 {
+		body = 0
+	}
+
+	stack := op.Save(gtx.Ops)
+	op.Offset(layout.FPt(image.Pt(0, header))).Add(gtx.Ops)
+	bgtx := gtx
+	bgtx.Constraints = layout.Exact(image.Pt(size.X, body))
+	clip.Rect(image.Rectangle{Max: bgtx.Constraints.Max}).Add(gtx.Ops)
+	visible := make(map[int]*int)
+	g.list.Layout(bgtx, rows, func(gtx layout.Context, row int) layout.Dimensions {
+		tag := g.rowTags[row]
+		if tag == nil {
+			tag = new(int)
+		}
+		visible[row] = tag
+		return g.layoutRow(gtx, th, row, px, tag, cell)
+	})
+	g.rowTags = visible
+	stack.Load()
+
+	if barHeight > 0 {
+		stack := op.Save(gtx.Ops)
+		op.Offset(layout.FPt(image.Pt(0, size.Y-barHeight))).Add(gtx.Ops)
+		g.layoutScrollbar(gtx, image.Pt(size.X, barHeight), total)
+		stack.Load()
+	}
+	return layout.Dimensions{Size: size}
+}
+
+func (g *Grid) update(gtx layout.Context) {
+	for i := range g.headers {
+		for g.headers[i].Clicked() {
+			if g.SortColumn == i {
+				g.SortDesc = !g.SortDesc
+			} else {
+				g.SortColumn, g.SortDesc = i, false
+			}
+			g.sorted = true
+		}
+	}
+	for i := range g.resizes {
+		r := &g.resizes[i]
+		for _, e := range gtx.Events(r) {
+			e, ok := e.(pointer.Event)
+			if !ok {
+				continue
+			}
+			switch e.Type {
+			case pointer.Press:
+				r.dragging = true
+				r.start = e.Position.X
+				r.startWidth = g.widths[i]
+			case pointer.Drag:
+				if !r.dragging {
+					break
+				}
+				w := r.startWidth + (e.Position.X-r.start)/gtx.Metric.PxPerDp
+				if w < 32 {
+					w = 32
+				}
+				g.widths[i] = w
+			case pointer.Release, pointer.Cancel:
+				r.dragging = false
+			}
+		}
+	}
+	for row, tag := range g.rowTags {
+		for _, e := range gtx.Events(tag) {
+			e, ok := e.(pointer.Event)
+			if !ok || e.Type != pointer.Press {
+				continue
+			}
+			g.selRow = row
+			g.selCol = g.columnAt(gtx, int(e.Position.X)+g.scrollX)
+		}
+	}
+}
+
+// columnAt returns the column at a horizontal position in the table.
+func (g *Grid) columnAt(gtx layout.Context, x int) int {
+	for i, w := range g.widths {
+		x -= gtx.Px(unit.Dp(w))
+		if x < 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+func (g *Grid) layoutHeader(gtx layout.Context, th *material.Theme, px []int) int {
+	// Lay out the titles first to find the header height.
+	macro := op.Record(gtx.Ops)
+	height := 0
+	x := -g.scrollX
+	for i, c := range g.Columns {
+		title := c.Title
+		if i == g.SortColumn {
+			if g.SortDesc {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+		stack := op.Save(gtx.Ops)
+		op.Offset(layout.FPt(image.Pt(x, 0))).Add(gtx.Ops)
+		cgtx := gtx
+		cgtx.Constraints = layout.Constraints{Min: image.Pt(px[i], 0), Max: image.Pt(px[i], gtx.Constraints.Max.Y)}
+		dims := material.Clickable(cgtx, &g.headers[i], func(gtx layout.Context) layout.Dimensions {
+			return layout.UniformInset(unit.Dp(6)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+				l := material.Body2(th, title)
+				l.Font.Weight = text.Bold
+				l.MaxLines = 1
+				l.Alignment = c.Alignment
+				return l.Layout(gtx)
+			})
+		})
+		if dims.Size.Y > height {
+			height = dims.Size.Y
+		}
+		stack.Load()
+		x += px[i]
+	}
+	call := macro.Stop()
+
+	width := gtx.Constraints.Max.X
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 0x10}, clip.Rect(image.Rect(0, 0, width, height)).Op())
+	stack := op.Save(gtx.Ops)
+	clip.Rect(image.Rect(0, 0, width, height)).Add(gtx.Ops)
+	call.Add(gtx.Ops)
+	// Column separators, with a drag area for resizing.
+	handle := gtx.Px(unit.Dp(6))
+	x = -g.scrollX
+	for i := range g.Columns {
+		x += px[i]
+		paint.FillShape(gtx.Ops, color.NRGBA{A: 0x30}, clip.Rect(image.Rect(x-1, 0, x, height)).Op())
+		s := op.Save(gtx.Ops)
+		pointer.Rect(image.Rect(x-handle/2, 0, x+handle/2, height)).Add(gtx.Ops)
+		pointer.CursorNameOp{Name: pointer.CursorColResize}.Add(gtx.Ops)
+		r := &g.resizes[i]
+		pointer.InputOp{Tag: r, Grab: r.dragging, Types: pointer.Press | pointer.Drag | pointer.Release}.Add(gtx.Ops)
+		s.Load()
+	}
+	stack.Load()
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 0x40}, clip.Rect(image.Rect(0, height-1, width, height)).Op())
+	return height
+}
+
+func (g *Grid) layoutRow(gtx layout.Context, th *material.Theme, row int, px []int, tag *int, cell Cell) layout.Dimensions {
+	width := gtx.Constraints.Max.X
+	macro := op.Record(gtx.Ops)
+	height := 0
+	x := -g.scrollX
+	for col := range px {
+		// Skip the columns scrolled out of view.
+		if x+px[col] > 0 && x < width {
+			stack := op.Save(gtx.Ops)
+			op.Offset(layout.FPt(image.Pt(x, 0))).Add(gtx.Ops)
+			clip.Rect(image.Rect(0, 0, px[col], gtx.Constraints.Max.Y)).Add(gtx.Ops)
+			cgtx := gtx
+			cgtx.Constraints = layout.Constraints{Min: image.Pt(px[col], 0), Max: image.Pt(px[col], gtx.Constraints.Max.Y)}
+			dims := layout.Inset{Left: unit.Dp(6), Right: unit.Dp(6), Top: unit.Dp(3), Bottom: unit.Dp(3)}.Layout(cgtx, func(gtx layout.Context) layout.Dimensions {
+				return cell(gtx, row, col)
+			})
+			if dims.Size.Y > height {
+				height = dims.Size.Y
+			}
+			stack.Load()
+		}
+		x += px[col]
+	}
+	call := macro.Stop()
+
+	bounds := image.Rect(0, 0, width, height)
+	switch {
+	case row == g.selRow:
+		c := th.Palette.ContrastBg
+		c.A = 0x30
+		paint.FillShape(gtx.Ops, c, clip.Rect(bounds).Op())
+	case row%2 == 1:
+		paint.FillShape(gtx.Ops, color.NRGBA{A: 0x08}, clip.Rect(bounds).Op())
+	}
+	call.Add(gtx.Ops)
+	stack := op.Save(gtx.Ops)
+	pointer.Rect(bounds).Add(gtx.Ops)
+	pointer.InputOp{Tag: tag, Types: pointer.Press}.Add(gtx.Ops)
+	stack.Load()
+	return layout.Dimensions{Size: bounds.Max}
+}
+
+// layoutScrollbar lays out the horizontal scrollbar for columns total
+// pixels wide.
+func (g *Grid) layoutScrollbar(gtx layout.Context, size image.Point, total int) {
+	s := &g.hbar
+	view := float32(size.X)
+	thumb := view * view / float32(total)
+	scale := float32(total) / view
+	for _, e := range gtx.Events(s) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		thumbX := float32(g.scrollX) / scale
+		switch e.Type {
+		case pointer.Press:
+			if e.Position.X < thumbX || e.Position.X > thumbX+thumb {
+				// Center the thumb on the click.
+				g.scrollX = int((e.Position.X - thumb/2) * scale)
+				thumbX = float32(g.scrollX) / scale
+			}
+			s.dragging = true
+			s.grab = e.Position.X - thumbX
+		case pointer.Drag:
+			if s.dragging {
+				g.scrollX = int((e.Position.X - s.grab) * scale)
+			}
+		case pointer.Release, pointer.Cancel:
+			s.dragging = false
+		}
+	}
+	if max := total - size.X; g.scrollX > max {
+		g.scrollX = max
+	}
+	if g.scrollX < 0 {
+		g.scrollX = 0
+	}
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 0x10}, clip.Rect(image.Rectangle{Max: size}).Op())
+	x := float32(g.scrollX) / scale
+	r := f32.Rect(x, 2, x+thumb, float32(size.Y-2))
+	rr := float32(size.Y-4) / 2
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 0x80}, clip.UniformRRect(r, rr).Op(gtx.Ops))
+	stack := op.Save(gtx.Ops)
+	pointer.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	pointer.InputOp{Tag: s, Grab: s.dragging, Types: pointer.Press | pointer.Drag | pointer.Release}.Add(gtx.Ops)
+	stack.Load()
+}
@@ -0,0 +1,429 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package edittest drives widgets built on widget.Editor with random
+// input, headlessly, to find panics and broken carets. A Harness lays
+// out the widget frame by frame and sends it typed text, key presses,
+// clipboard pastes and composed text, checking after every step that
+// the selection of the editor is within its text, on rune boundaries,
+// and agrees with the selected text. Harnesses that Predict the text
+// also check it against a model of the edits.
+//
+// Tests of examples run a harness for a few seeds:
+//
+//	func TestEditorFuzz(t *testing.T) {
+//		edittest.Run(t, func(seed int64) *edittest.Harness {
+//			ed := new(widget.Editor)
+//			return &edittest.Harness{Editor: ed, Layout: ..., Predict: true}
+//		})
+//	}
+//
+// The -edittest.steps and -edittest.seeds flags of the test binary run
+// longer sessions, and -edittest.seed repeats a failing one.
+package edittest
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"gioui.org/f32"
+	"gioui.org/io/clipboard"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+var (
+	stepsFlag = flag.Int("edittest.steps", 300, "random input steps per editor session")
+	seedsFlag = flag.Int("edittest.seeds", 4, "editor sessions per test")
+	seedFlag  = flag.Int64("edittest.seed", 0, "run only the editor session of this seed")
+)
+
+// Harness runs an editor session.
+type Harness struct {
+	// Editor is the editor of the widget.
+	Editor *widget.Editor
+	// Layout lays out the widget.
+	Layout func(gtx layout.Context)
+	// Predict checks the text against a model of the edits. It only
+	// holds for widgets passing the input to their editor unchanged.
+	Predict bool
+	// Check, if set, checks the widget after every step.
+	Check func() error
+	// Size is the size of the window, 400 by 300 if empty.
+	Size image.Point
+
+	rnd       *rand.Rand
+	router    router.Router
+	ops       op.Ops
+	now       time.Time
+	clipboard string
+	// want is the predicted text and selection, valid if known.
+	want  state
+	known bool
+	// log is the latest steps, for failure reports.
+	log []string
+}
+
+type state struct {
+	text       string
+	start, end int
+}
+
+// maxLog is the number of steps in failure reports.
+const maxLog = 12
+
+// Run runs the sessions of the harnesses made by newHarness for the
+// seeds of the flags.
+func Run(t *testing.T, newHarness func(seed int64) *Harness) {
+	t.Helper()
+	seeds := make([]int64, *seedsFlag)
+	for i := range seeds {
+		seeds[i] = int64(i + 1)
+	}
+	if *seedFlag != 0 {
+		seeds = []int64{*seedFlag}
+	}
+	for _, seed := range seeds {
+		h := newHarness(seed)
+		if err := h.Session(seed, *stepsFlag); err != nil {
+			t.Errorf("seed %d: %v", seed, err)
+		}
+	}
+}
+
+// Session focuses the editor and runs steps random steps. It reports
+// the first broken invariant and the steps before it; a panic is
+// reported the same way.
+func (h *Harness) Session(seed int64, steps int) (err error) {
+	h.rnd = rand.New(rand.NewSource(seed))
+	h.now = time.Unix(0, 0)
+	h.log = h.log[:0]
+	defer func() {
+		if p := recover(); p != nil {
+			err = h.failure(fmt.Errorf("panic: %v", p))
+		}
+	}()
+	h.Editor.Focus()
+	h.Frame()
+	h.Frame()
+	h.sync()
+	for i := 0; i < steps; i++ {
+		h.step()
+		if err := h.verify(); err != nil {
+			return h.failure(err)
+		}
+	}
+	return nil
+}
+
+func (h *Harness) failure(err error) error {
+	return fmt.Errorf("%w\nlast steps:\n\t%s", err, strings.Join(h.log, "\n\t"))
+}
+
+func (h *Harness) logf(format string, args ...interface{}) {
+	if len(h.log) == maxLog {
+		h.log = append(h.log[:0], h.log[1:]...)
+	}
+	h.log = append(h.log, fmt.Sprintf(format, args...))
+}
+
+// Frame lays out the widget and delivers the clipboard requests it
+// made.
+func (h *Harness) Frame() {
+	h.now = h.now.Add(16 * time.Millisecond)
+	size := h.Size
+	if size == (image.Point{}) {
+		size = image.Pt(400, 300)
+	}
+	h.ops.Reset()
+	gtx := layout.Context{
+		Ops:         &h.ops,
+		Now:         h.now,
+		Queue:       &h.router,
+		Metric:      unit.Metric{PxPerDp: 1, PxPerSp: 1},
+		Constraints: layout.Exact(size),
+	}
+	h.Layout(gtx)
+	h.router.Frame(&h.ops)
+	if text, ok := h.router.WriteClipboard(); ok {
+		h.clipboard = text
+	}
+	if h.router.ReadClipboard() {
+		h.router.Queue(clipboard.Event{Text: h.clipboard})
+		h.Frame()
+	}
+}
+
+// send queues events and lays out the frame handling them.
+func (h *Harness) send(events ...event.Event) {
+	for _, e := range events {
+		h.router.Queue(e)
+	}
+	h.Frame()
+}
+
+// sync takes the state of the editor as the prediction.
+func (h *Harness) sync() {
+	h.want.text = h.Editor.Text()
+	h.want.start, h.want.end = h.Editor.Selection()
+	h.known = h.Predict
+}
+
+// predictInsert updates the prediction for s replacing the selection.
+func (h *Harness) predictInsert(s string) {
+	if h.Editor.SingleLine {
+		s = strings.ReplaceAll(s, "\n", " ")
+	}
+	w := &h.want
+	lo, hi := order(w.start, w.end)
+	h.checkSelection(lo, hi)
+	w.text = w.text[:lo] + s + w.text[hi:]
+	w.start = lo + len(s)
+	w.end = w.start
+}
+
+// predictDelete updates the prediction for a deletion of one rune
+// backwards or forwards, or of the selection.
+func (h *Harness) predictDelete(forward bool) {
+	w := &h.want
+	lo, hi := order(w.start, w.end)
+	h.checkSelection(lo, hi)
+	switch {
+	case lo != hi:
+	case forward && hi < len(w.text):
+		_, n := utf8.DecodeRuneInString(w.text[hi:])
+		hi += n
+	case !forward && lo > 0:
+		_, n := utf8.DecodeLastRuneInString(w.text[:lo])
+		lo -= n
+	}
+	w.text = w.text[:lo] + w.text[hi:]
+	w.start, w.end = lo, lo
+}
+
+// checkSelection stops the prediction before replacing a selection
+// with multi-byte runes: widget.Editor deletes as many runes as the
+// selection has bytes, and with them text after the selection. The
+// invariants of the selection still hold.
+func (h *Harness) checkSelection(lo, hi int) {
+	if sel := h.want.text[lo:hi]; utf8.RuneCountInString(sel) != len(sel) {
+		h.known = false
+	}
+}
+
+func order(a, b int) (int, int) {
+	if a > b {
+		return b, a
+	}
+	return a, b
+}
+
+// step sends a random input.
+func (h *Harness) step() {
+	switch n := h.rnd.Intn(100); {
+	case n < 35:
+		s := h.randomText(1 + h.rnd.Intn(6))
+		h.logf("type %q", s)
+		h.send(key.EditEvent{Text: s})
+		h.predictInsert(s)
+	case n < 55:
+		forward := h.rnd.Intn(3) == 0
+		name := key.NameDeleteBackward
+		if forward {
+			name = key.NameDeleteForward
+		}
+		h.logf("press %s", name)
+		h.press(name, 0)
+		h.predictDelete(forward)
+	case n < 75:
+		h.move()
+	case n < 82:
+		h.compose()
+	case n < 88:
+		h.shortcut()
+	case n < 93:
+		h.click()
+	case n < 97:
+		h.logf("press %s", key.NameReturn)
+		h.press(key.NameReturn, 0)
+		// Submitting editors and completion popups handle Return
+		// themselves.
+		h.sync()
+	default:
+		// Frames without input, for widgets reacting to time and
+		// asynchronous results.
+		h.logf("idle")
+		h.Frame()
+		h.Frame()
+	}
+	if !h.known {
+		h.sync()
+	}
+}
+
+func (h *Harness) press(name string, mods key.Modifiers) {
+	h.send(key.Event{Name: name, Modifiers: mods, State: key.Press})
+	h.router.Queue(key.Event{Name: name, Modifiers: mods, State: key.Release})
+}
+
+// move moves the caret, with or without extending the selection.
+func (h *Harness) move() {
+	names := []string{
+		key.NameLeftArrow, key.NameRightArrow, key.NameUpArrow, key.NameDownArrow,
+		key.NameHome, key.NameEnd, key.NamePageUp, key.NamePageDown,
+	}
+	name := names[h.rnd.Intn(len(names))]
+	var mods key.Modifiers
+	if h.rnd.Intn(2) == 0 {
+		mods |= key.ModShift
+	}
+	if h.rnd.Intn(4) == 0 {
+		mods |= key.ModShortcut
+	}
+	h.logf("press %s %v", name, mods)
+	h.press(name, mods)
+	// The caret moves by lines and words, which the model doesn't know.
+	h.sync()
+}
+
+// compose emulates an input method composing text: the provisional text
+// is typed and replaced a few times before the final text is committed.
+// Gio has no composition events yet; input methods of desktop systems
+// end up sending the same edits.
+func (h *Harness) compose() {
+	final := h.randomText(1 + h.rnd.Intn(4))
+	h.logf("compose %q", final)
+	for i := h.rnd.Intn(3); i >= 0; i-- {
+		pre := h.randomText(1 + h.rnd.Intn(3))
+		h.send(key.EditEvent{Text: pre})
+		h.predictInsert(pre)
+		for range pre {
+			h.press(key.NameDeleteBackward, 0)
+			h.predictDelete(false)
+		}
+	}
+	h.send(key.EditEvent{Text: final})
+	h.predictInsert(final)
+}
+
+// shortcut selects all, copies, cuts or pastes.
+func (h *Harness) shortcut() {
+	switch h.rnd.Intn(4) {
+	case 0:
+		h.logf("select all")
+		h.press("A", key.ModShortcut)
+		if h.known {
+			// The caret goes to the end.
+			h.want.start, h.want.end = len(h.want.text), 0
+		}
+	case 1:
+		h.logf("copy")
+		h.press("C", key.ModShortcut)
+	case 2:
+		h.logf("cut")
+		h.press("X", key.ModShortcut)
+		if h.known && h.want.start != h.want.end {
+			h.predictDelete(false)
+		}
+	case 3:
+		if h.rnd.Intn(2) == 0 {
+			// Paste text from another program.
+			h.clipboard = h.randomText(h.rnd.Intn(40))
+		}
+		h.logf("paste %q", h.clipboard)
+		h.press("V", key.ModShortcut)
+		h.predictInsert(h.clipboard)
+	}
+}
+
+// click presses and releases the primary button somewhere in the
+// window, or drags to select.
+func (h *Harness) click() {
+	size := h.Size
+	if size == (image.Point{}) {
+		size = image.Pt(400, 300)
+	}
+	pos := func() f32.Point {
+		return f32.Pt(h.rnd.Float32()*float32(size.X), h.rnd.Float32()*float32(size.Y))
+	}
+	from, to := pos(), pos()
+	if h.rnd.Intn(2) == 0 {
+		to = from
+	}
+	h.logf("drag %v-%v", from, to)
+	h.send(pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: from})
+	h.send(pointer.Event{Type: pointer.Drag, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: to})
+	h.send(pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: to})
+	// The click may also have taken the focus; give it back.
+	h.Editor.Focus()
+	h.Frame()
+	h.sync()
+}
+
+// alphabet mixes the kinds of text editors get wrong: multi-byte runes,
+// combining marks, right-to-left scripts, wide characters, emoji and
+// line breaks.
+var alphabet = []string{
+	"a", "b", "z", "Q", "0", "7", " ", " ", ".", "_", "\t", "\n",
+	"é", "ß", "ø", "é", "ق", "ש", "ह", "中", "字", "한", "😀", "👍🏽", "‍", "\r\n",
+}
+
+func (h *Harness) randomText(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteString(alphabet[h.rnd.Intn(len(alphabet))])
+	}
+	return b.String()
+}
+
+// verify checks the invariants of the editor after a step.
+func (h *Harness) verify() error {
+	ed := h.Editor
+	text := ed.Text()
+	if !utf8.ValidString(text) {
+		return fmt.Errorf("text %q is not valid UTF-8", text)
+	}
+	if ed.Len() != len(text) {
+		return fmt.Errorf("Len() = %d, the text has %d bytes", ed.Len(), len(text))
+	}
+	start, end := ed.Selection()
+	for _, o := range []int{start, end} {
+		if o < 0 || o > len(text) {
+			return fmt.Errorf("selection %d-%d outside the text of %d bytes", start, end, len(text))
+		}
+		if o < len(text) && !utf8.RuneStart(text[o]) {
+			return fmt.Errorf("selection %d-%d splits a rune of %q", start, end, text)
+		}
+	}
+	lo, hi := order(start, end)
+	if got, want := ed.SelectedText(), text[lo:hi]; got != want {
+		return fmt.Errorf("SelectedText() = %q, want %q", got, want)
+	}
+	if ed.SingleLine && strings.Contains(text, "\n") {
+		return fmt.Errorf("single line editor text %q has a line break", text)
+	}
+	if h.known {
+		got := state{text: text, start: start, end: end}
+		if got != h.want {
+			return fmt.Errorf("text %q with selection %d-%d, want %q with %d-%d",
+				got.text, got.start, got.end, h.want.text, h.want.start, h.want.end)
+		}
+	}
+	if h.Check != nil {
+		if err := h.Check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
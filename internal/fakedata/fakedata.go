@@ -0,0 +1,214 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package fakedata generates made up data for examples: names,
+// procedural avatars, sentences and time series. The data depends only
+// on a seed, so examples run offline and look the same every time, and
+// tests can rely on it.
+package fakedata
+
+import (
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"gioui.org/example/internal/colorpicker"
+)
+
+var (
+	firstNames = []string{
+		"Ada", "Alan", "Amara", "Anders", "Aiko", "Barbara", "Bjarne", "Brian", "Carmen", "Chen",
+		"Claude", "Dana", "Dennis", "Diego", "Edsger", "Elena", "Emeka", "Farah", "Frances", "Grace",
+		"Hana", "Hedy", "Ibrahim", "Ines", "Ivan", "Jamal", "John", "Juno", "Ken", "Kirra",
+		"Lars", "Leila", "Linus", "Lucia", "Margaret", "Mateo", "Mei", "Nadia", "Niklaus", "Noor",
+		"Ole", "Olga", "Pablo", "Priya", "Radia", "Rob", "Rosa", "Sami", "Sophie", "Tariq",
+		"Tomas", "Ursula", "Valentina", "Vint", "Wei", "Xavier", "Yara", "Yukihiro", "Zara", "Zoltan",
+	}
+	lastNames = []string{
+		"Allen", "Andersen", "Backus", "Bakshi", "Cerf", "Costa", "Dijkstra", "Dubois", "Engelbart", "Eriksson",
+		"Floyd", "Fujita", "Goldberg", "Garcia", "Hamilton", "Haddad", "Iverson", "Ito", "Jensen", "Kernighan",
+		"Kowalski", "Liskov", "Lindqvist", "McCarthy", "Mendes", "Naur", "Nakamura", "Okafor", "Ousterhout", "Petrov",
+		"Pike", "Quinlan", "Ritchie", "Rossi", "Shannon", "Silva", "Thompson", "Tanaka", "Ullman", "Varga",
+		"Vixie", "Wirth", "Wong", "Xie", "Yao", "Yilmaz", "Zimmermann", "Zhou",
+	}
+	words = strings.Fields(`
+		the a an of to in for on with by from about over under between
+		build ship test deploy review merge refactor render draw layout scroll click type
+		window button list editor canvas frame shader texture pixel glyph font theme color
+		fast slow small large new old quiet bright simple clever careful early late
+		today tomorrow soon again always never maybe probably certainly
+		team release plan meeting coffee lunch weekend bug feature patch issue idea
+		we you they it this that our your their some every each other
+		is are was will can should might must looks works feels seems`)
+)
+
+// Faker generates data from a seed.
+type Faker struct {
+	r *rand.Rand
+}
+
+// New returns a Faker generating the data of seed.
+func New(seed int64) *Faker {
+	return &Faker{r: rand.New(rand.NewSource(seed))}
+}
+
+// Intn returns a number in [0, n).
+func (f *Faker) Intn(n int) int {
+	return f.r.Intn(n)
+}
+
+// Float64 returns a number in [0, 1).
+func (f *Faker) Float64() float64 {
+	return f.r.Float64()
+}
+
+func (f *Faker) pick(s []string) string {
+	return s[f.r.Intn(len(s))]
+}
+
+// FirstName returns a given name.
+func (f *Faker) FirstName() string {
+	return f.pick(firstNames)
+}
+
+// LastName returns a family name.
+func (f *Faker) LastName() string {
+	return f.pick(lastNames)
+}
+
+// Name returns a full name.
+func (f *Faker) Name() string {
+	return f.FirstName() + " " + f.LastName()
+}
+
+// Names returns n distinct full names. There are a few thousand
+// combinations; n must not exceed them.
+func (f *Faker) Names(n int) []string {
+	if max := len(firstNames) * len(lastNames); n > max {
+		panic(fmt.Sprintf("fakedata: %d names requested, only %d exist", n, max))
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for len(names) < n {
+		name := f.Name()
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Email returns an address for a name.
+func (f *Faker) Email(name string) string {
+	user := strings.ToLower(strings.Join(strings.Fields(name), "."))
+	return user + "@example.com"
+}
+
+// Word returns a word.
+func (f *Faker) Word() string {
+	return f.pick(words)
+}
+
+// Sentence returns a sentence of 4 to 12 words.
+func (f *Faker) Sentence() string {
+	n := 4 + f.r.Intn(9)
+	ws := make([]string, n)
+	for i := range ws {
+		ws[i] = f.Word()
+	}
+	s := strings.Join(ws, " ")
+	end := "."
+	switch f.r.Intn(8) {
+	case 0:
+		end = "?"
+	case 1:
+		end = "!"
+	}
+	return strings.ToUpper(s[:1]) + s[1:] + end
+}
+
+// Paragraph returns n sentences.
+func (f *Faker) Paragraph(n int) string {
+	ss := make([]string, n)
+	for i := range ss {
+		ss[i] = f.Sentence()
+	}
+	return strings.Join(ss, " ")
+}
+
+// Time returns a time in [from, to).
+func (f *Faker) Time(from, to time.Time) time.Time {
+	d := to.Sub(from)
+	if d <= 0 {
+		return from
+	}
+	return from.Add(time.Duration(f.r.Int63n(int64(d))))
+}
+
+// Point is a value at a time.
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// TimeSeries returns n points from start, step apart. The values wander
+// around 100 with a daily rhythm and noise, and never drop below 0.
+func (f *Faker) TimeSeries(start time.Time, step time.Duration, n int) []Point {
+	pts := make([]Point, n)
+	level := 100.0
+	for i := range pts {
+		t := start.Add(time.Duration(i) * step)
+		level += f.r.NormFloat64() * 2
+		// Pull the level back towards 100.
+		level += (100 - level) * 0.02
+		day := float64(t.Hour()*60+t.Minute()) / (24 * 60)
+		v := level + 15*math.Sin(2*math.Pi*(day-0.25)) + f.r.NormFloat64()*3
+		if v < 0 {
+			v = 0
+		}
+		pts[i] = Point{Time: t, Value: v}
+	}
+	return pts
+}
+
+// Avatar returns a size by size identicon for a key, such as a name: a
+// symmetric pattern of blocks in a color of its own. The same key always
+// gives the same avatar.
+func Avatar(key string, size int) *image.NRGBA {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	v := h.Sum64()
+	fg := colorpicker.HSV{H: float32(v % 360), S: 0.55, V: 0.8}.RGB(0xff)
+	bg := color.NRGBA{R: 0xf0, G: 0xf0, B: 0xf0, A: 0xff}
+	v /= 360
+	// A 5 by 5 grid mirrored around its middle column takes 15 bits.
+	const cells = 5
+	var on [cells][cells]bool
+	for y := 0; y < cells; y++ {
+		for x := 0; x < (cells+1)/2; x++ {
+			bit := v&1 == 1
+			v >>= 1
+			on[y][x], on[y][cells-1-x] = bit, bit
+		}
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	margin := size / 8
+	cell := float64(size-2*margin) / cells
+	for py := 0; py < size; py++ {
+		for px := 0; px < size; px++ {
+			c := bg
+			x := int(math.Floor(float64(px-margin) / cell))
+			y := int(math.Floor(float64(py-margin) / cell))
+			if x >= 0 && x < cells && y >= 0 && y < cells && on[y][x] {
+				c = fg
+			}
+			img.SetNRGBA(px, py, c)
+		}
+	}
+	return img
+}
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package fakedata
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeterministic(t *testing.T) {
+	gen := func(seed int64) []string {
+		f := New(seed)
+		return append(f.Names(20), f.Paragraph(3), f.Email(f.Name()))
+	}
+	if a, b := gen(1), gen(1); !reflect.DeepEqual(a, b) {
+		t.Error("the same seed generated different data")
+	}
+	if a, b := gen(1), gen(2); reflect.DeepEqual(a, b) {
+		t.Error("different seeds generated the same data")
+	}
+}
+
+func TestNames(t *testing.T) {
+	names := New(1).Names(500)
+	seen := make(map[string]bool)
+	for _, n := range names {
+		if seen[n] {
+			t.Fatalf("%q generated twice", n)
+		}
+		seen[n] = true
+		if len(strings.Fields(n)) != 2 {
+			t.Errorf("%q is not a first and last name", n)
+		}
+	}
+}
+
+func TestSentence(t *testing.T) {
+	f := New(1)
+	for i := 0; i < 100; i++ {
+		s := f.Sentence()
+		if s[:1] != strings.ToUpper(s[:1]) || !strings.ContainsAny(s[len(s)-1:], ".?!") {
+			t.Errorf("%q is not a sentence", s)
+		}
+		if n := len(strings.Fields(s)); n < 4 || n > 12 {
+			t.Errorf("%q has %d words", s, n)
+		}
+	}
+}
+
+func TestTimeSeries(t *testing.T) {
+	start := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	pts := New(1).TimeSeries(start, time.Hour, 24*30)
+	if len(pts) != 24*30 {
+		t.Fatalf("got %d points", len(pts))
+	}
+	for i, p := range pts {
+		if want := start.Add(time.Duration(i) * time.Hour); !p.Time.Equal(want) {
+			t.Fatalf("point %d at %v, want %v", i, p.Time, want)
+		}
+		if p.Value < 0 {
+			t.Errorf("point %d is negative", i)
+		}
+	}
+}
+
+func TestAvatar(t *testing.T) {
+	a := Avatar("Ada Lovelace", 40)
+	if !reflect.DeepEqual(a, Avatar("Ada Lovelace", 40)) {
+		t.Error("avatars of the same key differ")
+	}
+	if reflect.DeepEqual(a.Pix, Avatar("Alan Turing", 40).Pix) {
+		t.Error("avatars of different keys are the same")
+	}
+	// Avatars are mirrored.
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 20; x++ {
+			if a.NRGBAAt(x, y) != a.NRGBAAt(39-x, y) {
+				t.Fatalf("pixel %d,%d differs from its mirror", x, y)
+			}
+		}
+	}
+}
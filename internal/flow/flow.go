@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package flow implements a layout that places children in rows,
+// wrapping to a new row when a child doesn't fit.
+package flow
+
+import (
+	"image"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+)
+
+// Flow lays out children from left to right, wrapping them into as many
+// rows as needed.
+type Flow struct {
+	// Spacing is the horizontal space between children.
+	Spacing unit.Value
+	// LineSpacing is the vertical space between rows.
+	LineSpacing unit.Value
+	// Alignment aligns the children vertically within their row.
+	// Baseline aligns their baselines, for runs of text in different
+	// styles.
+	Alignment layout.Alignment
+}
+
+type child struct {
+	call op.CallOp
+	dims layout.Dimensions
+}
+
+// Layout lays out n children.
+func (f Flow) Layout(gtx layout.Context, n int, w layout.ListElement) layout.Dimensions {
+	maxWidth := gtx.Constraints.Max.X
+	spacing := gtx.Px(f.Spacing)
+	lineSpacing := gtx.Px(f.LineSpacing)
+
+	cgtx := gtx
+	cgtx.Constraints.Min = image.Point{}
+	var (
+		line   []child
+		width  int
+		y      int
+		widest int
+	)
+	flush := func() {
+		if len(line) == 0 {
+			return
+		}
+		height, ascent := 0, 0
+		for _, c := range line {
+			if h := c.dims.Size.Y; h > height {
+				height = h
+			}
+			if a := c.dims.Size.Y - c.dims.Baseline; a > ascent {
+				ascent = a
+			}
+		}
+		if f.Alignment == layout.Baseline {
+			height = 0
+			for _, c := range line {
+				if h := ascent + c.dims.Baseline; h > height {
+					height = h
+				}
+			}
+		}
+		x := 0
+		for _, c := range line {
+			var dy int
+			switch f.Alignment {
+			case layout.Middle:
+				dy = (height - c.dims.Size.Y) / 2
+			case layout.End:
+				dy = height - c.dims.Size.Y
+			case layout.Baseline:
+				dy = ascent - (c.dims.Size.Y - c.dims.Baseline)
+			}
+			stack := op.Save(gtx.Ops)
+			op.Offset(f32.Pt(float32(x), float32(y+dy))).Add(gtx.Ops)
+			c.call.Add(gtx.Ops)
+			stack.Load()
+			x += c.dims.Size.X + spacing
+		}
+		if width > widest {
+			widest = width
+		}
+		y += height + lineSpacing
+		line = line[:0]
+		width = 0
+	}
+	for i := 0; i < n; i++ {
+		m := op.Record(gtx.Ops)
+		dims := w(cgtx, i)
+		c := child{call: m.Stop(), dims: dims}
+		next := width + dims.Size.X
+		if len(line) > 0 {
+			next += spacing
+		}
+		if next > maxWidth && len(line) > 0 {
+			flush()
+			next = dims.Size.X
+		}
+		line = append(line, c)
+		width = next
+	}
+	flush()
+	if y > 0 {
+		y -= lineSpacing
+	}
+	return layout.Dimensions{Size: gtx.Constraints.Constrain(image.Pt(widest, y))}
+}
@@ -0,0 +1,460 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package heatmap implements a grid of cells colored by their values,
+// such as a calendar of daily activity or a correlation matrix, with a
+// legend of its color scale. Hovering a cell shows its tooltip. Clicking
+// the grid focuses it, after which the arrow keys move a focused cell
+// around and its tooltip is shown, so the values can be read without a
+// pointer.
+package heatmap
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Scale maps values to colors.
+type Scale struct {
+	Min, Max float64
+	// Colors are the stops of the scale, spread evenly from Min to Max.
+	Colors []color.NRGBA
+	// Steps, if not zero, quantizes the values into that many colors,
+	// the first of which is for Min only.
+	Steps int
+}
+
+var (
+	// Greens is a scale from light gray to dark green, for counts.
+	Greens = Scale{Min: 0, Max: 1, Steps: 5, Colors: []color.NRGBA{
+		{R: 0xeb, G: 0xed, B: 0xf0, A: 0xff},
+		{R: 0x9b, G: 0xe9, B: 0xa8, A: 0xff},
+		{R: 0x40, G: 0xc4, B: 0x63, A: 0xff},
+		{R: 0x30, G: 0xa1, B: 0x4e, A: 0xff},
+		{R: 0x21, G: 0x6e, B: 0x39, A: 0xff},
+	}}
+	// Diverging is a scale from blue through white to red, for values
+	// from -1 to 1 such as correlations.
+	Diverging = Scale{Min: -1, Max: 1, Colors: []color.NRGBA{
+		{R: 0x21, G: 0x66, B: 0xac, A: 0xff},
+		{R: 0xf7, G: 0xf7, B: 0xf7, A: 0xff},
+		{R: 0xb2, G: 0x18, B: 0x2b, A: 0xff},
+	}}
+)
+
+// Color returns the color of v, clamped to the scale.
+func (s Scale) Color(v float64) color.NRGBA {
+	n := len(s.Colors)
+	if n == 0 {
+		return color.NRGBA{}
+	}
+	t := 0.0
+	if s.Max > s.Min {
+		t = (v - s.Min) / (s.Max - s.Min)
+	}
+	t = math.Max(0, math.Min(1, t))
+	if s.Steps > 1 {
+		// The first step is Min alone; the rest share the range evenly.
+		steps := float64(s.Steps - 1)
+		t = math.Ceil(t*steps) / steps
+	}
+	if n == 1 {
+		return s.Colors[0]
+	}
+	f := t * float64(n-1)
+	i := int(f)
+	if i >= n-1 {
+		return s.Colors[n-1]
+	}
+	return lerp(s.Colors[i], s.Colors[i+1], float32(f-float64(i)))
+}
+
+func lerp(a, b color.NRGBA, t float32) color.NRGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8(float32(a) + (float32(b)-float32(a))*t + .5)
+	}
+	return color.NRGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: mix(a.A, b.A)}
+}
+
+// Heatmap is the state of a grid of Rows by Cols cells.
+type Heatmap struct {
+	Rows, Cols int
+	// Value returns the value of a cell, and false for a cell that is
+	// left out, such as a day in the future.
+	Value func(row, col int) (float64, bool)
+	// Tooltip returns the text shown for a cell.
+	Tooltip func(row, col int) string
+	Scale   Scale
+	// RowLabels and ColLabels label the rows to the left and the columns
+	// above the grid. Labels may be empty, to label some rows only.
+	RowLabels, ColLabels []string
+	// CellSize is the largest size of the cells; they shrink to fit the
+	// constraints. Zero means no limit.
+	CellSize unit.Value
+	// Gap is the space between cells.
+	Gap unit.Value
+
+	// Row and Col are the focused cell.
+	Row, Col int
+
+	focused, focus bool
+	tabbed         bool
+	tabBack        bool
+	hover          image.Point
+	hovering       bool
+	// origin and pitch locate the cells in the last frame.
+	origin image.Point
+	pitch  int
+}
+
+// Focus requests the keyboard focus.
+func (h *Heatmap) Focus() {
+	h.focus = true
+}
+
+// Focused reports whether the heatmap has the keyboard focus.
+func (h *Heatmap) Focused() bool {
+	return h.focused
+}
+
+// Tabbed reports whether tab was pressed while focused since the last
+// call, and whether shift was held to go backwards. The heatmap keeps
+// the focus; moving it along is up to the caller.
+func (h *Heatmap) Tabbed() (back, ok bool) {
+	ok, back = h.tabbed, h.tabBack
+	h.tabbed = false
+	return back, ok
+}
+
+func (h *Heatmap) valid(row, col int) bool {
+	if row < 0 || row >= h.Rows || col < 0 || col >= h.Cols {
+		return false
+	}
+	if h.Value == nil {
+		return true
+	}
+	_, ok := h.Value(row, col)
+	return ok
+}
+
+// hit returns the cell at a position.
+func (h *Heatmap) hit(p f32.Point) (image.Point, bool) {
+	if h.pitch == 0 {
+		return image.Point{}, false
+	}
+	x, y := int(p.X)-h.origin.X, int(p.Y)-h.origin.Y
+	if x < 0 || y < 0 {
+		return image.Point{}, false
+	}
+	c := image.Pt(x/h.pitch, y/h.pitch)
+	return c, h.valid(c.Y, c.X)
+}
+
+// moveFocus moves the focused cell by (dr, dc), skipping cells left out,
+// up to the edges of the grid.
+func (h *Heatmap) moveFocus(dr, dc int) {
+	r, c := h.Row+dr, h.Col+dc
+	for r >= 0 && r < h.Rows && c >= 0 && c < h.Cols {
+		if h.valid(r, c) {
+			h.Row, h.Col = r, c
+			return
+		}
+		r, c = r+dr, c+dc
+	}
+}
+
+// edge moves the focused cell to the first or last cell of its row.
+func (h *Heatmap) edge(last bool) {
+	for i := 0; i < h.Cols; i++ {
+		c := i
+		if last {
+			c = h.Cols - 1 - i
+		}
+		if h.valid(h.Row, c) {
+			h.Col = c
+			return
+		}
+	}
+}
+
+func (h *Heatmap) update(gtx layout.Context) {
+	for _, e := range gtx.Events(h) {
+		switch e := e.(type) {
+		case key.FocusEvent:
+			h.focused = e.Focus
+		case key.Event:
+			if e.State != key.Press {
+				break
+			}
+			switch e.Name {
+			case key.NameLeftArrow:
+				h.moveFocus(0, -1)
+			case key.NameRightArrow:
+				h.moveFocus(0, 1)
+			case key.NameUpArrow:
+				h.moveFocus(-1, 0)
+			case key.NameDownArrow:
+				h.moveFocus(1, 0)
+			case key.NameHome:
+				h.edge(false)
+			case key.NameEnd:
+				h.edge(true)
+			case key.NameTab:
+				h.tabbed, h.tabBack = true, e.Modifiers.Contain(key.ModShift)
+			}
+		case pointer.Event:
+			switch e.Type {
+			case pointer.Press:
+				h.focus = true
+				if c, ok := h.hit(e.Position); ok {
+					h.Row, h.Col = c.Y, c.X
+				}
+			case pointer.Move, pointer.Enter:
+				h.hover, h.hovering = h.hit(e.Position)
+			case pointer.Leave, pointer.Cancel:
+				h.hovering = false
+			}
+		}
+	}
+	if !h.valid(h.Row, h.Col) {
+		// Move the focused cell to the first cell that is not left out.
+		for i := 0; i < h.Rows*h.Cols; i++ {
+			if r, c := i/h.Cols, i%h.Cols; h.valid(r, c) {
+				h.Row, h.Col = r, c
+				break
+			}
+		}
+	}
+}
+
+// Layout draws the labels and the grid, and the tooltip of the hovered
+// cell, or the focused cell while focused.
+func (h *Heatmap) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	h.update(gtx)
+	if h.Rows == 0 || h.Cols == 0 {
+		return layout.Dimensions{Size: gtx.Constraints.Min}
+	}
+	labelColor := th.Palette.Fg
+	labelColor.A = 0xa0
+	// Measure the labels.
+	labelW := 0
+	for _, l := range h.RowLabels {
+		if l == "" {
+			continue
+		}
+		m := op.Record(gtx.Ops)
+		gtx := gtx
+		gtx.Constraints.Min = image.Point{}
+		dims := material.Caption(th, l).Layout(gtx)
+		m.Stop()
+		if w := dims.Size.X + gtx.Px(unit.Dp(6)); w > labelW {
+			labelW = w
+		}
+	}
+	labelH := 0
+	if len(h.ColLabels) > 0 {
+		labelH = gtx.Px(unit.Dp(18))
+	}
+
+	gap := gtx.Px(h.Gap)
+	avail := gtx.Constraints.Max.Sub(image.Pt(labelW, labelH))
+	pitch := (avail.X + gap) / h.Cols
+	if p := (avail.Y + gap) / h.Rows; p < pitch {
+		pitch = p
+	}
+	if max := gtx.Px(h.CellSize); max > 0 && pitch > max+gap {
+		pitch = max + gap
+	}
+	if pitch < gap+1 {
+		pitch = gap + 1
+	}
+	cell := pitch - gap
+	h.origin, h.pitch = image.Pt(labelW, labelH), pitch
+	size := gtx.Constraints.Constrain(h.origin.Add(image.Pt(h.Cols*pitch-gap, h.Rows*pitch-gap)))
+
+	label := func(txt string, r image.Rectangle, align text.Alignment) {
+		st := op.Save(gtx.Ops)
+		op.Offset(layout.FPt(r.Min)).Add(gtx.Ops)
+		gtx := gtx
+		gtx.Constraints = layout.Exact(r.Size())
+		l := material.Caption(th, txt)
+		l.Color = labelColor
+		l.Alignment = align
+		l.MaxLines = 1
+		layout.W.Layout(gtx, l.Layout)
+		st.Load()
+	}
+	for i, l := range h.RowLabels {
+		if l != "" && i < h.Rows {
+			label(l, image.Rect(0, labelH+i*pitch, labelW, labelH+i*pitch+cell), text.Start)
+		}
+	}
+	for i, l := range h.ColLabels {
+		if l == "" || i >= h.Cols {
+			continue
+		}
+		// Labels extend over the columns up to the next label, as month
+		// names over a calendar do.
+		n := 1
+		for i+n < h.Cols && (i+n >= len(h.ColLabels) || h.ColLabels[i+n] == "") {
+			n++
+		}
+		x := labelW + i*pitch
+		label(l, image.Rect(x, 0, x+n*pitch-gap, labelH), text.Start)
+	}
+
+	rr := float32(cell) / 6
+	for r := 0; r < h.Rows; r++ {
+		for c := 0; c < h.Cols; c++ {
+			v, ok := 0.0, true
+			if h.Value != nil {
+				v, ok = h.Value(r, c)
+			}
+			if !ok {
+				continue
+			}
+			min := h.origin.Add(image.Pt(c*pitch, r*pitch))
+			rect := f32.Rectangle{Min: layout.FPt(min), Max: layout.FPt(min.Add(image.Pt(cell, cell)))}
+			paint.FillShape(gtx.Ops, h.Scale.Color(v), clip.UniformRRect(rect, rr).Op(gtx.Ops))
+		}
+	}
+
+	defer op.Save(gtx.Ops).Load()
+	grid := image.Rectangle{Min: h.origin, Max: h.origin.Add(image.Pt(h.Cols*pitch, h.Rows*pitch))}
+	if h.focused {
+		h.outline(gtx, h.Row, h.Col, cell, th.Palette.ContrastBg)
+	}
+	tip, tipOK := h.hover, h.hovering
+	if !tipOK && h.focused {
+		tip, tipOK = image.Pt(h.Col, h.Row), true
+	}
+	if tipOK && h.Tooltip != nil {
+		h.layoutTooltip(gtx, th, tip, cell, size)
+	}
+
+	pointer.Rect(grid).Add(gtx.Ops)
+	pointer.InputOp{
+		Tag:   h,
+		Types: pointer.Press | pointer.Move | pointer.Enter | pointer.Leave,
+	}.Add(gtx.Ops)
+	key.InputOp{Tag: h}.Add(gtx.Ops)
+	if h.focus {
+		key.FocusOp{Tag: h}.Add(gtx.Ops)
+		h.focus = false
+	}
+	return layout.Dimensions{Size: size}
+}
+
+// outline draws the focus ring around a cell.
+func (h *Heatmap) outline(gtx layout.Context, row, col, cell int, c color.NRGBA) {
+	st := op.Save(gtx.Ops)
+	w := gtx.Px(unit.Dp(2))
+	min := h.origin.Add(image.Pt(col*h.pitch-w, row*h.pitch-w))
+	op.Offset(layout.FPt(min)).Add(gtx.Ops)
+	widget.Border{Color: c, Width: unit.Px(float32(w)), CornerRadius: unit.Px(float32(cell) / 4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Dimensions{Size: image.Pt(cell+2*w, cell+2*w)}
+	})
+	st.Load()
+}
+
+// layoutTooltip draws the tooltip of a cell below it, or above it near
+// the bottom, over everything else.
+func (h *Heatmap) layoutTooltip(gtx layout.Context, th *material.Theme, c image.Point, cell int, size image.Point) {
+	txt := h.Tooltip(c.Y, c.X)
+	if txt == "" {
+		return
+	}
+	m := op.Record(gtx.Ops)
+	gtx.Constraints.Min = image.Point{}
+	gtx.Constraints.Max.X = gtx.Px(unit.Dp(260))
+	m2 := op.Record(gtx.Ops)
+	dims := layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4), Left: unit.Dp(8), Right: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		l := material.Caption(th, txt)
+		l.Color = th.Palette.ContrastFg
+		return l.Layout(gtx)
+	})
+	body := m2.Stop()
+	bg := color.NRGBA{R: 0x30, G: 0x30, B: 0x30, A: 0xf0}
+	rect := f32.Rectangle{Max: layout.FPt(dims.Size)}
+	paint.FillShape(gtx.Ops, bg, clip.UniformRRect(rect, float32(gtx.Px(unit.Dp(4)))).Op(gtx.Ops))
+	body.Add(gtx.Ops)
+	call := m.Stop()
+
+	cellMin := h.origin.Add(image.Pt(c.X*h.pitch, c.Y*h.pitch))
+	pos := image.Pt(cellMin.X+cell/2-dims.Size.X/2, cellMin.Y+cell+gtx.Px(unit.Dp(4)))
+	if pos.Y+dims.Size.Y > gtx.Constraints.Max.Y && cellMin.Y-dims.Size.Y > 0 {
+		pos.Y = cellMin.Y - dims.Size.Y - gtx.Px(unit.Dp(4))
+	}
+	if pos.X+dims.Size.X > size.X {
+		pos.X = size.X - dims.Size.X
+	}
+	if pos.X < 0 {
+		pos.X = 0
+	}
+	st := op.Save(gtx.Ops)
+	op.Offset(layout.FPt(pos)).Add(gtx.Ops)
+	op.Defer(gtx.Ops, call)
+	st.Load()
+}
+
+// Legend is a key to a color scale.
+type Legend struct {
+	Scale Scale
+	// Low and High label the ends of the scale.
+	Low, High string
+}
+
+// Layout draws the legend in a row: the low label, a swatch per step of
+// the scale, or a gradient, and the high label.
+func (l Legend) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	labelColor := th.Palette.Fg
+	labelColor.A = 0xa0
+	caption := func(txt string) layout.Widget {
+		return func(gtx layout.Context) layout.Dimensions {
+			c := material.Caption(th, txt)
+			c.Color = labelColor
+			return c.Layout(gtx)
+		}
+	}
+	sz := gtx.Px(unit.Dp(12))
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(caption(l.Low)),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Left: unit.Dp(6), Right: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				if l.Scale.Steps > 0 {
+					gap := gtx.Px(unit.Dp(3))
+					rr := float32(sz) / 6
+					for i := 0; i < l.Scale.Steps; i++ {
+						v := l.Scale.Min
+						if l.Scale.Steps > 1 {
+							v += (l.Scale.Max - l.Scale.Min) * float64(i) / float64(l.Scale.Steps-1)
+						}
+						x := float32(i * (sz + gap))
+						r := f32.Rect(x, 0, x+float32(sz), float32(sz))
+						paint.FillShape(gtx.Ops, l.Scale.Color(v), clip.UniformRRect(r, rr).Op(gtx.Ops))
+					}
+					return layout.Dimensions{Size: image.Pt(l.Scale.Steps*(sz+gap)-gap, sz)}
+				}
+				w := gtx.Px(unit.Dp(120))
+				for x := 0; x < w; x++ {
+					v := l.Scale.Min + (l.Scale.Max-l.Scale.Min)*float64(x)/float64(w-1)
+					paint.FillShape(gtx.Ops, l.Scale.Color(v), clip.Rect(image.Rect(x, 0, x+1, sz)).Op())
+				}
+				return layout.Dimensions{Size: image.Pt(w, sz)}
+			})
+		}),
+		layout.Rigid(caption(l.High)),
+	)
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package heatmap
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/widget/material"
+)
+
+func TestScale(t *testing.T) {
+	black, white := color.NRGBA{A: 0xff}, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	s := Scale{Min: 0, Max: 10, Colors: []color.NRGBA{black, white}}
+	tests := []struct {
+		v    float64
+		want uint8
+	}{{0, 0}, {5, 0x80}, {10, 0xff}, {-3, 0}, {20, 0xff}}
+	for _, test := range tests {
+		if got := s.Color(test.v).R; got != test.want {
+			t.Errorf("Color(%v) = %#x, want %#x", test.v, got, test.want)
+		}
+	}
+	// Quantized into 3 steps: zero, up to half and up to full.
+	s.Steps = 3
+	for _, test := range []struct {
+		v    float64
+		want uint8
+	}{{0, 0}, {0.1, 0x80}, {5, 0x80}, {5.1, 0xff}, {10, 0xff}} {
+		if got := s.Color(test.v).R; got != test.want {
+			t.Errorf("stepped Color(%v) = %#x, want %#x", test.v, got, test.want)
+		}
+	}
+	if got := Greens.Color(0.3); got != Greens.Colors[2] {
+		t.Errorf("Greens.Color(0.3) = %v, want the third color", got)
+	}
+}
+
+func TestKeyboard(t *testing.T) {
+	th := material.NewTheme(gofont.Collection())
+	// A 3 by 4 grid missing its last cell.
+	h := &Heatmap{
+		Rows: 3, Cols: 4,
+		Value: func(row, col int) (float64, bool) {
+			return float64(row*4 + col), row != 2 || col != 3
+		},
+	}
+	var r router.Router
+	ops := new(op.Ops)
+	frame := func() {
+		ops.Reset()
+		gtx := layout.Context{Ops: ops, Queue: &r, Constraints: layout.Exact(image.Pt(400, 300))}
+		h.Layout(gtx, th)
+		r.Frame(ops)
+	}
+	h.Row, h.Col = 2, 3
+	frame()
+	if h.Row != 0 || h.Col != 0 {
+		t.Errorf("focused cell %d,%d in place of a missing cell, want 0,0", h.Row, h.Col)
+	}
+	// Click the cell at 1,1 of the 100 pixel cells to focus it.
+	r.Queue(
+		pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(150, 150)},
+		pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: f32.Pt(150, 150)},
+	)
+	frame()
+	frame()
+	if !h.Focused() {
+		t.Fatal("not focused after a click")
+	}
+	if h.Row != 1 || h.Col != 1 {
+		t.Errorf("focused cell %d,%d after a click, want 1,1", h.Row, h.Col)
+	}
+	press := func(name string) {
+		r.Queue(key.Event{Name: name, State: key.Press})
+		frame()
+	}
+	press(key.NameRightArrow)
+	press(key.NameRightArrow)
+	press(key.NameRightArrow)
+	if h.Col != 3 {
+		t.Errorf("column %d after moving past the edge, want 3", h.Col)
+	}
+	// The cell below is missing.
+	press(key.NameDownArrow)
+	if h.Row != 1 {
+		t.Errorf("row %d after moving to a missing cell, want 1", h.Row)
+	}
+	press(key.NameHome)
+	if h.Col != 0 {
+		t.Errorf("column %d after Home, want 0", h.Col)
+	}
+	if _, ok := h.Tabbed(); ok {
+		t.Error("tabbed before Tab")
+	}
+	press(key.NameTab)
+	if back, ok := h.Tabbed(); !ok || back {
+		t.Errorf("Tabbed() = %v, %v after Tab, want forwards", back, ok)
+	}
+}
@@ -0,0 +1,312 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package i18n reads and writes numbers, and writes currency amounts and
+// dates, the way a locale does:
+//
+//	l := i18n.Find("de-CH")
+//	l.Number(1234.5, 2)            // 1’234.50
+//	l.Format(1234.5678, 6)         // 1’234.57
+//	l.Parse("1’234.5")             // 1234.5, nil
+//	l.Amount(1234.5, l.Currency)   // CHF 1’234.50
+//	l.LongDate(t)                  // Mi., 4. März 2026
+//
+// Numbers and currency symbols come from golang.org/x/text, which has
+// the data of every locale. It has no API for dates nor for the place of
+// the currency symbol, so the names of the months and weekdays, the date
+// patterns and the currency patterns are tables of the supported locales
+// here.
+package i18n
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// Locale is the way a language and region writes numbers and dates.
+type Locale struct {
+	// Tag is the BCP 47 tag of the locale, such as "de-CH".
+	Tag string
+	// Name is the name of the locale in its language.
+	Name string
+	// Currency is the currency of the region.
+	Currency currency.Unit
+	// FirstWeekday is the day weeks start on in calendars.
+	FirstWeekday time.Weekday
+
+	names *names
+	// short and long are the date patterns, in the notation of CLDR:
+	// y is the year, M and MM the month number, MMM its name, d and dd
+	// the day, EEE the name of the weekday, and quoted text is literal.
+	short, long string
+	// money places the symbol, ¤, and the number, #, of amounts of
+	// money.
+	money string
+	// decimal separates the fraction of numbers, and group the groups
+	// of their integer part, as the printer writes them.
+	decimal, group string
+	printer        *message.Printer
+}
+
+// names are the abbreviated names of the months and weekdays of a
+// language, from January and Sunday.
+type names struct {
+	months   [12]string
+	weekdays [7]string
+}
+
+var (
+	english = &names{
+		months:   [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		weekdays: [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	}
+	german = &names{
+		months:   [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		weekdays: [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+	}
+	french = &names{
+		months:   [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		weekdays: [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+	}
+	spanish = &names{
+		months:   [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		weekdays: [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+	}
+	portuguese = &names{
+		months:   [12]string{"jan.", "fev.", "mar.", "abr.", "mai.", "jun.", "jul.", "ago.", "set.", "out.", "nov.", "dez."},
+		weekdays: [7]string{"dom.", "seg.", "ter.", "qua.", "qui.", "sex.", "sáb."},
+	}
+	japanese = &names{
+		months:   [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		weekdays: [7]string{"日", "月", "火", "水", "木", "金", "土"},
+	}
+)
+
+// Locales are the supported locales. The first is the default.
+var Locales = []*Locale{
+	{Tag: "en-US", Name: "English (United States)", Currency: currency.USD, FirstWeekday: time.Sunday,
+		names: english, short: "M/d/y", long: "EEE, MMM d, y", money: "¤#"},
+	{Tag: "en-GB", Name: "English (United Kingdom)", Currency: currency.GBP, FirstWeekday: time.Monday,
+		names: english, short: "dd/MM/y", long: "EEE d MMM y", money: "¤#"},
+	{Tag: "en-IN", Name: "English (India)", Currency: currency.INR, FirstWeekday: time.Sunday,
+		names: english, short: "dd/MM/y", long: "EEE, d MMM y", money: "¤#"},
+	{Tag: "de-DE", Name: "Deutsch (Deutschland)", Currency: currency.EUR, FirstWeekday: time.Monday,
+		names: german, short: "dd.MM.y", long: "EEE, d. MMM y", money: "#\u00a0¤"},
+	{Tag: "de-CH", Name: "Deutsch (Schweiz)", Currency: currency.CHF, FirstWeekday: time.Monday,
+		names: german, short: "dd.MM.y", long: "EEE, d. MMM y", money: "¤\u00a0#"},
+	{Tag: "fr-FR", Name: "Français (France)", Currency: currency.EUR, FirstWeekday: time.Monday,
+		names: french, short: "dd/MM/y", long: "EEE d MMM y", money: "#\u00a0¤"},
+	{Tag: "es-ES", Name: "Español (España)", Currency: currency.EUR, FirstWeekday: time.Monday,
+		names: spanish, short: "d/M/y", long: "EEE, d MMM y", money: "#\u00a0¤"},
+	{Tag: "pt-BR", Name: "Português (Brasil)", Currency: currency.BRL, FirstWeekday: time.Sunday,
+		names: portuguese, short: "dd/MM/y", long: "EEE, d 'de' MMM 'de' y", money: "¤\u00a0#"},
+	{Tag: "ja-JP", Name: "日本語 (日本)", Currency: currency.JPY, FirstWeekday: time.Sunday,
+		names: japanese, short: "y/MM/dd", long: "y年M月d日(EEE)", money: "¤#"},
+}
+
+func init() {
+	for _, l := range Locales {
+		l.printer = message.NewPrinter(language.MustParse(l.Tag))
+		// Read the separators off a number with both.
+		n := l.Number(1234.5, 1)
+		l.group = n[len("1"):strings.Index(n, "234")]
+		l.decimal = n[strings.Index(n, "234")+len("234") : len(n)-len("5")]
+	}
+}
+
+// Find returns the locale for a tag such as "de-CH" or "de_CH.UTF-8",
+// falling back to a locale of the same language, and to the first
+// locale.
+func Find(tag string) *Locale {
+	if i := strings.IndexAny(tag, ".@"); i >= 0 {
+		tag = tag[:i]
+	}
+	tag = strings.Replace(tag, "_", "-", -1)
+	for _, l := range Locales {
+		if strings.EqualFold(l.Tag, tag) {
+			return l
+		}
+	}
+	lang := strings.SplitN(tag, "-", 2)[0]
+	for _, l := range Locales {
+		if strings.EqualFold(strings.SplitN(l.Tag, "-", 2)[0], lang) {
+			return l
+		}
+	}
+	return Locales[0]
+}
+
+// FromEnv returns the locale of the environment, from LC_ALL or LANG.
+func FromEnv() *Locale {
+	for _, v := range []string{"LC_ALL", "LANG"} {
+		if tag := os.Getenv(v); tag != "" && tag != "C" && tag != "POSIX" {
+			return Find(tag)
+		}
+	}
+	return Locales[0]
+}
+
+// Number formats v with frac fraction digits.
+func (l *Locale) Number(v float64, frac int) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "–"
+	}
+	return l.printer.Sprint(number.Decimal(v, number.MinFractionDigits(frac), number.MaxFractionDigits(frac)))
+}
+
+// Format formats v rounded to digits significant digits, but no further
+// than units, and without trailing zeros. Values too large or small to
+// read are formatted in scientific notation, such as 1.5×10^-7.
+func (l *Locale) Format(v float64, digits int) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "–"
+	}
+	a := math.Abs(v)
+	if a != 0 && (a >= 1e15 || a < 1e-6) {
+		s := strconv.FormatFloat(v, 'e', digits-1, 64)
+		i := strings.IndexByte(s, 'e')
+		exp, _ := strconv.Atoi(s[i+1:])
+		return l.decimalString(s[:i]) + "×10^" + strconv.Itoa(exp)
+	}
+	dec := 0
+	if a != 0 {
+		dec = digits - 1 - int(math.Floor(math.Log10(a)))
+	}
+	if dec < 0 {
+		dec = 0
+	}
+	return l.decimalString(strconv.FormatFloat(v, 'f', dec, 64))
+}
+
+// decimalString localizes a number formatted by strconv in decimal
+// notation, without the trailing zeros of its fraction.
+func (l *Locale) decimalString(s string) string {
+	frac := 0
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		frac = len(strings.TrimRight(s[i+1:], "0"))
+	}
+	v, _ := strconv.ParseFloat(s, 64)
+	if v == 0 {
+		// No minus sign for zero.
+		v = 0
+	}
+	return l.Number(v, frac)
+}
+
+// Parse parses a number written in the locale, as formatted by Format
+// or in scientific notation. Group separators and spaces are ignored.
+func (l *Locale) Parse(s string) (float64, error) {
+	n := strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune(l.group, r), unicode.IsSpace(r):
+			return -1
+		case r == '\u2212':
+			return '-'
+		}
+		return r
+	}, s)
+	n = strings.Replace(n, "×10^", "e", 1)
+	if l.decimal != "." {
+		if strings.Contains(n, ".") {
+			return 0, fmt.Errorf("%q is not a number", s)
+		}
+		n = strings.Replace(n, l.decimal, ".", 1)
+	}
+	v, err := strconv.ParseFloat(n, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	return v, nil
+}
+
+// Int formats an integer.
+func (l *Locale) Int(n int) string {
+	return l.printer.Sprint(number.Decimal(n))
+}
+
+// Amount formats an amount of a currency, rounded to the usual
+// fraction digits of the currency, with the symbol the locale uses for
+// it.
+func (l *Locale) Amount(v float64, cur currency.Unit) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "–"
+	}
+	scale, _ := currency.Standard.Rounding(cur)
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	n := l.Number(v, scale)
+	if strings.Trim(n, "0.,") == "" {
+		sign = ""
+	}
+	sym := l.printer.Sprint(currency.Symbol(cur))
+	return sign + strings.NewReplacer("¤", sym, "#", n).Replace(l.money)
+}
+
+// Month returns the abbreviated name of a month.
+func (l *Locale) Month(m time.Month) string {
+	return l.names.months[m-1]
+}
+
+// Weekday returns the abbreviated name of a weekday.
+func (l *Locale) Weekday(d time.Weekday) string {
+	return l.names.weekdays[d]
+}
+
+// ShortDate formats the date of t in numbers, such as 3/4/2026.
+func (l *Locale) ShortDate(t time.Time) string {
+	return l.date(l.short, t)
+}
+
+// LongDate formats the date of t with the names of the weekday and
+// month, such as Wed, Mar 4, 2026.
+func (l *Locale) LongDate(t time.Time) string {
+	return l.date(l.long, t)
+}
+
+// date formats t with a date pattern.
+func (l *Locale) date(pattern string, t time.Time) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+		if c == '\'' {
+			end := strings.IndexByte(pattern[i+1:], '\'')
+			if end < 0 {
+				end = len(pattern) - i - 1
+			}
+			b.WriteString(pattern[i+1 : i+1+end])
+			i += end + 2
+			continue
+		}
+		n := 1
+		for i+n < len(pattern) && pattern[i+n] == c {
+			n++
+		}
+		switch {
+		case c == 'y':
+			b.WriteString(strconv.Itoa(t.Year()))
+		case c == 'M' && n >= 3:
+			b.WriteString(l.Month(t.Month()))
+		case c == 'M':
+			fmt.Fprintf(&b, "%0*d", n, t.Month())
+		case c == 'd':
+			fmt.Fprintf(&b, "%0*d", n, t.Day())
+		case c == 'E':
+			b.WriteString(l.Weekday(t.Weekday()))
+		default:
+			b.WriteString(pattern[i : i+n])
+		}
+		i += n
+	}
+	return b.String()
+}
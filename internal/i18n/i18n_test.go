@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package i18n
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"golang.org/x/text/currency"
+)
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		tag  string
+		v    float64
+		frac int
+		want string
+	}{
+		{"en-US", 1234567.891, 2, "1,234,567.89"},
+		{"en-US", 0.5, 0, "0"},
+		{"de-DE", 1234.5, 2, "1.234,50"},
+		{"de-CH", 1234567, 0, "1’234’567"},
+		{"en-IN", 12345678, 0, "1,23,45,678"},
+	}
+	for _, test := range tests {
+		if got := Find(test.tag).Number(test.v, test.frac); got != test.want {
+			t.Errorf("%v in %s: %q, want %q", test.v, test.tag, got, test.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		tag  string
+		v    float64
+		want string
+	}{
+		{"en-US", 1234567.891, "1,234,568"},
+		{"en-US", 1609.344, "1,609.34"},
+		{"en-US", 0.3048, "0.3048"},
+		{"en-US", -0.0000001, "-1×10^-7"},
+		{"en-US", 9.4607304725808e15, "9.46073×10^15"},
+		{"en-US", math.Copysign(0, -1), "0"},
+		{"de-DE", 1609.344, "1.609,34"},
+		{"de-DE", 2.5, "2,5"},
+		{"de-CH", 1234567, "1’234’567"},
+		{"fr-FR", 12345.5, "12\u00a0345,5"},
+		{"en-IN", 12345678, "1,23,45,678"},
+		{"es-ES", 12345, "12.345"},
+	}
+	for _, test := range tests {
+		if got := Find(test.tag).Format(test.v, 6); got != test.want {
+			t.Errorf("%v in %s: %q, want %q", test.v, test.tag, got, test.want)
+		}
+	}
+	if got := Find("en-IN").Format(12345678, 10); got != "1,23,45,678" {
+		t.Errorf("12345678 in en-IN: %q", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		tag, s string
+		want   float64
+		ok     bool
+	}{
+		{"en-US", "1,234.5", 1234.5, true},
+		{"en-US", " 2 ", 2, true},
+		{"en-US", "1e3", 1000, true},
+		{"en-US", "9.46073×10^15", 9.46073e15, true},
+		{"de-DE", "1.234,5", 1234.5, true},
+		{"de-DE", "−3,5", -3.5, true},
+		{"fr-FR", "12 345,5", 12345.5, true},
+		{"fr-FR", "12\u00a0345,5", 12345.5, true},
+		{"fr-FR", "1.5", 0, false},
+		{"de-CH", "1’000.25", 1000.25, true},
+		{"en-US", "", 0, false},
+		{"en-US", "abc", 0, false},
+		{"en-US", "Inf", 0, false},
+	}
+	for _, test := range tests {
+		got, err := Find(test.tag).Parse(test.s)
+		if ok := err == nil; ok != test.ok || got != test.want {
+			t.Errorf("parse %q in %s: %v, %v; want %v", test.s, test.tag, got, err, test.want)
+		}
+	}
+}
+
+func TestAmount(t *testing.T) {
+	tests := []struct {
+		tag  string
+		v    float64
+		cur  currency.Unit
+		want string
+	}{
+		{"en-US", 1234.5, currency.USD, "$1,234.50"},
+		{"en-US", -1234.5, currency.USD, "-$1,234.50"},
+		{"en-US", -0.001, currency.USD, "$0.00"},
+		{"de-DE", 1234.5, currency.EUR, "1.234,50\u00a0€"},
+		{"de-CH", 12.5, currency.CHF, "CHF\u00a012.50"},
+		{"en-GB", 3, currency.GBP, "£3.00"},
+		// The yen has no fraction digits.
+		{"en-US", 1234.4, currency.JPY, "¥1,234"},
+	}
+	for _, test := range tests {
+		if got := Find(test.tag).Amount(test.v, test.cur); got != test.want {
+			t.Errorf("%v %v in %s: %q, want %q", test.v, test.cur, test.tag, got, test.want)
+		}
+	}
+}
+
+func TestDate(t *testing.T) {
+	d := time.Date(2026, time.March, 4, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		tag         string
+		short, long string
+	}{
+		{"en-US", "3/4/2026", "Wed, Mar 4, 2026"},
+		{"en-GB", "04/03/2026", "Wed 4 Mar 2026"},
+		{"de-DE", "04.03.2026", "Mi., 4. März 2026"},
+		{"fr-FR", "04/03/2026", "mer. 4 mars 2026"},
+		{"pt-BR", "04/03/2026", "qua., 4 de mar. de 2026"},
+		{"ja-JP", "2026/03/04", "2026年3月4日(水)"},
+	}
+	for _, test := range tests {
+		l := Find(test.tag)
+		if got := l.ShortDate(d); got != test.short {
+			t.Errorf("short date in %s: %q, want %q", test.tag, got, test.short)
+		}
+		if got := l.LongDate(d); got != test.long {
+			t.Errorf("long date in %s: %q, want %q", test.tag, got, test.long)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	tests := map[string]string{
+		"de_CH.UTF-8": "de-CH",
+		"de_AT":       "de-DE",
+		"fr_CA@euro":  "fr-FR",
+		"ja":          "ja-JP",
+		"en-in":       "en-IN",
+		"nl_NL":       "en-US",
+	}
+	for tag, want := range tests {
+		if got := Find(tag).Tag; got != want {
+			t.Errorf("locale of %q: %s, want %s", tag, got, want)
+		}
+	}
+}
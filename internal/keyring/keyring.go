@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package keyring stores secrets, such as passwords and tokens, in the
+// credential store of the platform. A secret is identified by the service
+// it belongs to and a user name.
+package keyring
+
+import (
+	"errors"
+)
+
+// ErrNotFound is returned by Get and Delete for missing secrets.
+var ErrNotFound = errors.New("keyring: secret not found")
+
+// ErrUnsupported is returned when the platform has no supported
+// credential store.
+var ErrUnsupported = errors.New("keyring: no credential store on this platform")
+
+// Keyring is a store of secrets.
+type Keyring interface {
+	// Get returns the secret of a user for a service.
+	Get(service, user string) (string, error)
+	// Set stores a secret, replacing any previous one.
+	Set(service, user, secret string) error
+	// Delete removes a secret.
+	Delete(service, user string) error
+}
+
+// Default returns the credential store of the platform.
+func Default() Keyring {
+	return platform{}
+}
@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package keyring
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// platform stores generic passwords in the login keychain with the
+// security command.
+type platform struct{}
+
+// errItemNotFound is the exit status of security for missing items.
+const errItemNotFound = 44
+
+func (platform) Get(service, user string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", service, "-a", user, "-w").Output()
+	if err != nil {
+		return "", convertError(err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (platform) Set(service, user, secret string) error {
+	// Note that the secret is visible in the arguments of the process
+	// while it runs.
+	err := exec.Command("security", "add-generic-password", "-U", "-s", service, "-a", user, "-w", secret).Run()
+	return convertError(err)
+}
+
+func (platform) Delete(service, user string) error {
+	err := exec.Command("security", "delete-generic-password", "-s", service, "-a", user).Run()
+	return convertError(err)
+}
+
+func convertError(err error) error {
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == errItemNotFound {
+		return ErrNotFound
+	}
+	return err
+}
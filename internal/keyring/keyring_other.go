@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build !darwin && !windows && !((linux && !android) || freebsd || openbsd)
+// +build !darwin
+// +build !windows
+// +build !linux android
+// +build !freebsd
+// +build !openbsd
+
+package keyring
+
+type platform struct{}
+
+func (platform) Get(service, user string) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (platform) Set(service, user, secret string) error {
+	return ErrUnsupported
+}
+
+func (platform) Delete(service, user string) error {
+	return ErrUnsupported
+}
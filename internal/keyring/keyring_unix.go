@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build (linux && !android) || freebsd || openbsd
+// +build linux,!android freebsd openbsd
+
+package keyring
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// platform stores secrets with the Secret Service of the desktop, such as
+// GNOME Keyring or KWallet, through the secret-tool command of libsecret.
+type platform struct{}
+
+func (platform) Get(service, user string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", service, "username", user).Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			// secret-tool exits with an error and no output for missing
+			// secrets.
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return string(out), nil
+}
+
+func (platform) Set(service, user, secret string) error {
+	cmd := exec.Command("secret-tool", "store", "--label="+service+" ("+user+")", "service", service, "username", user)
+	cmd.Stdin = strings.NewReader(secret)
+	return cmd.Run()
+}
+
+func (platform) Delete(service, user string) error {
+	if _, err := (platform{}).Get(service, user); err != nil {
+		return err
+	}
+	return exec.Command("secret-tool", "clear", "service", service, "username", user).Run()
+}
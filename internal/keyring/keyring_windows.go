@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package keyring
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// platform stores secrets as generic credentials of the Windows
+// Credential Manager, which encrypts them with DPAPI.
+type platform struct{}
+
+var (
+	advapi32       = syscall.NewLazyDLL("advapi32.dll")
+	procCredRead   = advapi32.NewProc("CredReadW")
+	procCredWrite  = advapi32.NewProc("CredWriteW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+const (
+	_CRED_TYPE_GENERIC          = 1
+	_CRED_PERSIST_LOCAL_MACHINE = 2
+	_ERROR_NOT_FOUND            = 1168
+)
+
+// credential mirrors CREDENTIALW.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// target returns the name of the credential of a user for a service.
+func target(service, user string) (*uint16, error) {
+	return syscall.UTF16PtrFromString(service + ":" + user)
+}
+
+func credError(err error) error {
+	if err == syscall.Errno(_ERROR_NOT_FOUND) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (platform) Get(service, user string) (string, error) {
+	name, err := target(service, user)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	r, _, err := procCredRead.Call(uintptr(unsafe.Pointer(name)), _CRED_TYPE_GENERIC, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		return "", credError(err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+	n := cred.CredentialBlobSize
+	if n == 0 {
+		return "", nil
+	}
+	blob := (*[1 << 30]byte)(unsafe.Pointer(cred.CredentialBlob))[:n:n]
+	return string(blob), nil
+}
+
+func (platform) Set(service, user, secret string) error {
+	name, err := target(service, user)
+	if err != nil {
+		return err
+	}
+	userName, err := syscall.UTF16PtrFromString(user)
+	if err != nil {
+		return err
+	}
+	blob := []byte(secret)
+	cred := credential{
+		Type:               _CRED_TYPE_GENERIC,
+		TargetName:         name,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            _CRED_PERSIST_LOCAL_MACHINE,
+		UserName:           userName,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+	r, _, err := procCredWrite.Call(uintptr(unsafe.Pointer(&cred)), 0)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+func (platform) Delete(service, user string) error {
+	name, err := target(service, user)
+	if err != nil {
+		return err
+	}
+	r, _, err := procCredDelete.Call(uintptr(unsafe.Pointer(name)), _CRED_TYPE_GENERIC, 0)
+	if r == 0 {
+		return credError(err)
+	}
+	return nil
+}
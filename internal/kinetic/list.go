@@ -0,0 +1,431 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package kinetic implements a vertical list with tunable fling physics
+// and platform style overscroll effects. It is a drop-in alternative to
+// layout.List for examples that want control over scrolling feel.
+package kinetic
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+)
+
+// Overscroll selects the effect shown when scrolling past an edge.
+type Overscroll uint8
+
+const (
+	// Clamp stops scrolling at the edges.
+	Clamp Overscroll = iota
+	// Bounce lets the content be pulled past the edge and springs it
+	// back, in the style of macOS and iOS.
+	Bounce
+	// Glow shows a translucent glow at the edge whose strength depends
+	// on the overscroll, in the style of Android.
+	Glow
+)
+
+// String implements fmt.Stringer.
+func (o Overscroll) String() string {
+	switch o {
+	case Bounce:
+		return "Bounce"
+	case Glow:
+		return "Glow"
+	default:
+		return "Clamp"
+	}
+}
+
+// Physics tunes the fling behavior.
+type Physics struct {
+	// Friction is the exponential velocity decay rate per second. Higher
+	// values stop a fling sooner.
+	Friction float32
+	// MaxVelocity caps the fling velocity.
+	MaxVelocity unit.Value
+	// Spring is the rate at which bounced content returns to the edge.
+	Spring float32
+}
+
+var (
+	// IOS approximates the feel of scrolling on iOS.
+	IOS = Physics{Friction: 2, MaxVelocity: unit.Dp(8000), Spring: 10}
+	// Android approximates the feel of scrolling on Android.
+	Android = Physics{Friction: 3.5, MaxVelocity: unit.Dp(8000), Spring: 10}
+)
+
+// List is a virtualized vertical list. The zero value uses the Android
+// physics without overscroll effects.
+type List struct {
+	Physics    Physics
+	Overscroll Overscroll
+	// GlowColor is the color of the Glow effect.
+	GlowColor color.NRGBA
+	// Position is the scroll position.
+	Position layout.Position
+
+	dragging bool
+	grabbed  bool
+	start    f32.Point
+	last     float32
+	samples  tracker
+
+	velocity float32
+	// overscroll is the current signed distance past the start (negative)
+	// or end (positive) edge.
+	overscroll float32
+	lastFrame  time.Time
+
+	children []child
+}
+
+type child struct {
+	call op.CallOp
+	dims layout.Dimensions
+}
+
+// Dragging reports whether the list is being dragged.
+func (l *List) Dragging() bool {
+	return l.dragging
+}
+
+// Flinging reports whether the list is moving by itself.
+func (l *List) Flinging() bool {
+	return l.velocity != 0 || l.overscroll != 0
+}
+
+// Overscrolled returns the distance the content is pulled past the start
+// (negative) or end (positive) edge.
+func (l *List) Overscrolled() float32 {
+	return l.overscroll
+}
+
+// Stop halts any fling in progress.
+func (l *List) Stop() {
+	l.velocity = 0
+}
+
+// Layout lays out the elements of the list and processes input.
+func (l *List) Layout(gtx layout.Context, n int, w layout.ListElement) layout.Dimensions {
+	physics := l.Physics
+	if physics == (Physics{}) {
+		physics = Android
+	}
+	viewport := gtx.Constraints.Max
+	delta := l.update(gtx, physics)
+
+	var dt float32
+	if !l.lastFrame.IsZero() {
+		dt = float32(gtx.Now.Sub(l.lastFrame).Seconds())
+	}
+	l.lastFrame = gtx.Now
+	if !l.dragging && l.velocity != 0 {
+		delta += l.velocity * dt
+		l.velocity *= float32(math.Exp(float64(-physics.Friction * dt)))
+		if abs(l.velocity) < float32(gtx.Px(unit.Dp(10))) {
+			l.velocity = 0
+		}
+	}
+
+	excess := l.scroll(gtx, delta, n, w, viewport.Y)
+	l.applyExcess(gtx, excess, viewport.Y)
+	if !l.dragging && l.overscroll != 0 {
+		// Relax toward the edge.
+		l.overscroll *= float32(math.Exp(float64(-physics.Spring * dt)))
+		if abs(l.overscroll) < .5 {
+			l.overscroll = 0
+		}
+	}
+
+	defer op.Save(gtx.Ops).Load()
+	bounds := image.Rectangle{Max: viewport}
+	clip.Rect(bounds).Add(gtx.Ops)
+	pointer.Rect(bounds).Add(gtx.Ops)
+	pointer.InputOp{
+		Tag:   l,
+		Grab:  l.grabbed,
+		Types: pointer.Press | pointer.Drag | pointer.Release | pointer.Scroll,
+		// The router clamps scroll distances to the bounds; the list
+		// clamps its position itself.
+		ScrollBounds: image.Rect(0, -1e6, 0, 1e6),
+	}.Add(gtx.Ops)
+
+	content := op.Save(gtx.Ops)
+	y := float32(-l.Position.Offset)
+	if l.Overscroll == Bounce {
+		y -= l.overscroll
+	}
+	op.Offset(f32.Pt(0, y)).Add(gtx.Ops)
+	for _, c := range l.children {
+		c.call.Add(gtx.Ops)
+		op.Offset(f32.Pt(0, float32(c.dims.Size.Y))).Add(gtx.Ops)
+	}
+	content.Load()
+
+	if l.Overscroll == Glow && l.overscroll != 0 {
+		l.glow(gtx, viewport)
+	}
+	if l.Flinging() {
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+	return layout.Dimensions{Size: viewport}
+}
+
+// update processes pointer events and returns the distance scrolled by
+// them.
+func (l *List) update(gtx layout.Context, physics Physics) float32 {
+	var delta float32
+	slop := float32(gtx.Px(unit.Dp(4)))
+	for _, e := range gtx.Events(l) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case pointer.Press:
+			l.dragging = true
+			l.velocity = 0
+			l.start = e.Position
+			l.last = e.Position.Y
+			l.samples.reset()
+			l.samples.add(e.Time, e.Position.Y)
+		case pointer.Drag:
+			if !l.dragging {
+				break
+			}
+			if !l.grabbed && abs(e.Position.Y-l.start.Y) > slop {
+				l.grabbed = true
+			}
+			delta += l.last - e.Position.Y
+			l.last = e.Position.Y
+			l.samples.add(e.Time, e.Position.Y)
+		case pointer.Release, pointer.Cancel:
+			if l.dragging && e.Type == pointer.Release {
+				v := -l.samples.velocity()
+				max := float32(gtx.Px(physics.MaxVelocity))
+				if v > max {
+					v = max
+				} else if v < -max {
+					v = -max
+				}
+				l.velocity = v
+			}
+			l.dragging = false
+			l.grabbed = false
+		case pointer.Scroll:
+			delta += e.Scroll.Y
+		}
+	}
+	return delta
+}
+
+// scroll moves the position by delta, lays out the visible children and
+// returns the distance that could not be scrolled because an edge was
+// reached. Negative excess means the start edge.
+func (l *List) scroll(gtx layout.Context, delta float32, n int, w layout.ListElement, viewport int) float32 {
+	// Consume any overscroll before moving the content itself.
+	if l.overscroll != 0 && delta != 0 && (l.overscroll < 0) != (delta < 0) {
+		l.overscroll += delta
+		if (l.overscroll < 0) == (delta < 0) {
+			delta = l.overscroll
+			l.overscroll = 0
+		} else {
+			delta = 0
+		}
+	}
+	pos := &l.Position
+	pos.Offset += int(math.Round(float64(delta)))
+	if pos.First >= n {
+		pos.First = n - 1
+	}
+	if pos.First < 0 {
+		pos.First = 0
+	}
+
+	cs := gtx.Constraints
+	cs.Min = image.Point{X: cs.Max.X}
+	cs.Max.Y = math.MaxInt32
+	layoutChild := func(i int) child {
+		gtx := gtx
+		gtx.Constraints = cs
+		m := op.Record(gtx.Ops)
+		dims := w(gtx, i)
+		return child{call: m.Stop(), dims: dims}
+	}
+
+	var excess float32
+	// Move backwards while the offset is negative.
+	for pos.Offset < 0 && pos.First > 0 {
+		pos.First--
+		pos.Offset += layoutChild(pos.First).dims.Size.Y
+	}
+	if pos.Offset < 0 {
+		excess = float32(pos.Offset)
+		pos.Offset = 0
+	}
+
+	l.children = l.children[:0]
+	total := 0
+	i := pos.First
+	for ; i < n && total-pos.Offset < viewport; i++ {
+		c := layoutChild(i)
+		h := c.dims.Size.Y
+		// Skip children scrolled entirely out of view.
+		if len(l.children) == 0 && pos.Offset >= h && i < n-1 {
+			pos.First++
+			pos.Offset -= h
+			continue
+		}
+		l.children = append(l.children, c)
+		total += h
+	}
+	space := viewport - (total - pos.Offset)
+	pos.BeforeEnd = i < n || space < 0
+	if space <= 0 || i < n {
+		return excess
+	}
+	// The end is visible with room to spare; move back to fill the
+	// viewport.
+	pos.Offset -= space
+	for pos.Offset < 0 && pos.First > 0 {
+		pos.First--
+		c := layoutChild(pos.First)
+		l.children = append([]child{c}, l.children...)
+		pos.Offset += c.dims.Size.Y
+	}
+	if pos.Offset < 0 {
+		// The content is shorter than the viewport.
+		pos.Offset = 0
+	}
+	if delta > 0 {
+		excess = float32(space)
+		if excess > delta {
+			excess = delta
+		}
+	}
+	return excess
+}
+
+// applyExcess converts scrolling past an edge into overscroll.
+func (l *List) applyExcess(gtx layout.Context, excess float32, viewport int) {
+	if excess == 0 {
+		return
+	}
+	switch l.Overscroll {
+	case Clamp:
+		l.velocity = 0
+	case Bounce:
+		// Resist further pulling the farther the content is pulled.
+		resistance := 1 - abs(l.overscroll)/float32(viewport)
+		if resistance < .1 {
+			resistance = .1
+		}
+		if !l.dragging {
+			// A fling hitting the edge overshoots a little before
+			// springing back.
+			excess = l.velocity / 20
+			l.velocity = 0
+		}
+		l.overscroll += excess * resistance * .5
+	case Glow:
+		if !l.dragging {
+			excess = l.velocity / 10
+			l.velocity = 0
+		}
+		l.overscroll += excess
+		max := float32(gtx.Px(unit.Dp(200)))
+		if l.overscroll > max {
+			l.overscroll = max
+		} else if l.overscroll < -max {
+			l.overscroll = -max
+		}
+	}
+}
+
+// glow draws the Android style edge glow.
+func (l *List) glow(gtx layout.Context, viewport image.Point) {
+	defer op.Save(gtx.Ops).Load()
+	col := l.GlowColor
+	if col == (color.NRGBA{}) {
+		col = color.NRGBA{R: 0x40, G: 0x80, B: 0xc0, A: 0xff}
+	}
+	strength := abs(l.overscroll) / float32(gtx.Px(unit.Dp(200)))
+	col.A = uint8(float32(col.A) * .5 * strength)
+	transparent := col
+	transparent.A = 0
+	h := float32(gtx.Px(unit.Dp(96))) * strength
+	y0, y1 := float32(0), h
+	if l.overscroll > 0 {
+		y0, y1 = float32(viewport.Y), float32(viewport.Y)-h
+	}
+	r := f32.Rectangle{Min: f32.Pt(0, y0), Max: f32.Pt(float32(viewport.X), y1)}.Canon()
+	clip.UniformRRect(r, 0).Add(gtx.Ops)
+	paint.LinearGradientOp{
+		Stop1:  f32.Pt(0, y0),
+		Stop2:  f32.Pt(0, y1),
+		Color1: col,
+		Color2: transparent,
+	}.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+}
+
+// tracker estimates pointer velocity from recent samples.
+type tracker struct {
+	n       int
+	times   [8]time.Duration
+	offsets [8]float32
+}
+
+func (t *tracker) reset() {
+	t.n = 0
+}
+
+func (t *tracker) add(at time.Duration, pos float32) {
+	i := t.n % len(t.times)
+	t.times[i] = at
+	t.offsets[i] = pos
+	t.n++
+}
+
+// velocity returns the velocity in pixels per second over the samples
+// of the last 100 milliseconds.
+func (t *tracker) velocity() float32 {
+	if t.n < 2 {
+		return 0
+	}
+	last := (t.n - 1) % len(t.times)
+	first := last
+	count := t.n
+	if count > len(t.times) {
+		count = len(t.times)
+	}
+	for k := 1; k < count; k++ {
+		i := (t.n - 1 - k) % len(t.times)
+		if t.times[last]-t.times[i] > 100*time.Millisecond {
+			break
+		}
+		first = i
+	}
+	dt := (t.times[last] - t.times[first]).Seconds()
+	if dt <= 0 {
+		return 0
+	}
+	return (t.offsets[last] - t.offsets[first]) / float32(dt)
+}
+
+func abs(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package kinetic
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+)
+
+func TestListScroll(t *testing.T) {
+	var (
+		l   List
+		r   router.Router
+		ops op.Ops
+	)
+	row := func(gtx layout.Context, i int) layout.Dimensions {
+		return layout.Dimensions{Size: image.Pt(gtx.Constraints.Max.X, 20)}
+	}
+	now := time.Now()
+	frame := func() {
+		ops.Reset()
+		gtx := layout.Context{
+			Ops:         &ops,
+			Queue:       &r,
+			Now:         now,
+			Constraints: layout.Exact(image.Pt(100, 100)),
+		}
+		l.Layout(gtx, 100, row)
+		r.Frame(&ops)
+		now = now.Add(16 * time.Millisecond)
+	}
+	frame()
+	r.Queue(pointer.Event{
+		Type:     pointer.Scroll,
+		Source:   pointer.Mouse,
+		Position: f32.Pt(50, 50),
+		Scroll:   f32.Pt(0, 50),
+	})
+	frame()
+	if got := l.Position.First*20 + l.Position.Offset; got != 50 {
+		t.Errorf("scrolled to %d, want 50", got)
+	}
+}
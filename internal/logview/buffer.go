@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package logview
+
+import (
+	"strings"
+	"sync"
+)
+
+// Buffer keeps the last lines written to it, for a log streamed from a
+// connection or a process. It is safe to write from one goroutine while
+// another reads the lines.
+type Buffer struct {
+	// Max is the number of lines kept; older lines are dropped. Zero
+	// means no limit.
+	Max int
+
+	mu    sync.Mutex
+	lines []string
+	// dropped is the number of lines dropped from the front.
+	dropped int
+	// open reports whether the last line is not yet ended.
+	open bool
+	// cr reports whether the last byte written was a carriage return.
+	cr bool
+}
+
+// Write appends text, splitting it into lines at line feeds, carriage
+// returns, and the pairs of both. A line without its end yet is shown
+// as the last line, and continued by the next write.
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := string(p)
+	for len(s) > 0 {
+		if b.cr && s[0] == '\n' {
+			// The line feed of a CRLF pair, split between writes.
+			s = s[1:]
+			b.cr = false
+			continue
+		}
+		b.cr = false
+		end := strings.IndexAny(s, "\r\n")
+		if end == -1 {
+			b.appendText(s)
+			break
+		}
+		b.appendText(s[:end])
+		b.open = false
+		if s[end] == '\r' {
+			b.cr = true
+		}
+		s = s[end+1:]
+	}
+	b.trim()
+	return len(p), nil
+}
+
+// Println appends a line of its own, ending any line not yet ended.
+func (b *Buffer) Println(line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines = append(b.lines, line)
+	b.open, b.cr = false, false
+	b.trim()
+}
+
+func (b *Buffer) appendText(s string) {
+	if b.open {
+		b.lines[len(b.lines)-1] += s
+		return
+	}
+	b.lines = append(b.lines, s)
+	b.open = true
+}
+
+func (b *Buffer) trim() {
+	if b.Max > 0 && len(b.lines) > b.Max {
+		n := len(b.lines) - b.Max
+		// Copy to let the dropped lines be collected.
+		b.lines = append([]string(nil), b.lines[n:]...)
+		b.dropped += n
+	}
+}
+
+// Len returns the number of lines kept.
+func (b *Buffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.lines)
+}
+
+// Line returns the number, counted from 0 since the first line written,
+// and the text of the ith line kept.
+func (b *Buffer) Line(i int) (int, string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if i < 0 || i >= len(b.lines) {
+		return 0, "", false
+	}
+	return b.dropped + i, b.lines[i], true
+}
+
+// Last returns the number of the last line.
+func (b *Buffer) Last() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped + len(b.lines) - 1
+}
+
+// Clear removes the lines kept. The numbering of lines goes on.
+func (b *Buffer) Clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.dropped += len(b.lines)
+	b.lines = nil
+	b.open = false
+}
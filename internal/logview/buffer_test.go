@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package logview
+
+import (
+	"fmt"
+	"testing"
+)
+
+func lines(b *Buffer) []string {
+	var lines []string
+	for i := 0; i < b.Len(); i++ {
+		_, l, _ := b.Line(i)
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+func TestBuffer(t *testing.T) {
+	b := new(Buffer)
+	for _, w := range []string{"boot", "ing\r", "\nready\n", "> temp 21.5\r\n", "> hum", "id 40\rok\n"} {
+		b.Write([]byte(w))
+	}
+	want := []string{"booting", "ready", "> temp 21.5", "> humid 40", "ok"}
+	if got := lines(b); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("lines %q, want %q", got, want)
+	}
+	// An unfinished line is shown, and ended by Println.
+	b.Write([]byte("partial"))
+	b.Println("sent")
+	b.Write([]byte("more"))
+	if got := lines(b)[5:]; fmt.Sprint(got) != fmt.Sprint([]string{"partial", "sent", "more"}) {
+		t.Errorf("lines %q after Println", got)
+	}
+}
+
+func TestBufferMax(t *testing.T) {
+	b := &Buffer{Max: 3}
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(b, "line %d\n", i)
+	}
+	if n, l, _ := b.Line(0); n != 2 || l != "line 2" {
+		t.Errorf("first line %d %q, want 2 \"line 2\"", n, l)
+	}
+	if b.Last() != 4 {
+		t.Errorf("last line %d, want 4", b.Last())
+	}
+	b.Clear()
+	fmt.Fprintf(b, "again\n")
+	if n, l, _ := b.Line(0); n != 5 || l != "again" {
+		t.Errorf("line after Clear %d %q, want 5 \"again\"", n, l)
+	}
+}
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package logview implements a view of log lines: a scrolling list of
+// numbered lines colored by their level, which follows new lines as they
+// come, and a bounded buffer of lines for logs that are streamed rather
+// than read from a file.
+package logview
+
+import (
+	"image/color"
+	"strings"
+)
+
+// Level is the severity of a log line.
+type Level int
+
+const (
+	LevelNone Level = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// levelWords maps the words marking levels to their levels.
+var levelWords = map[string]Level{
+	"DEBUG":   LevelDebug,
+	"TRACE":   LevelDebug,
+	"INFO":    LevelInfo,
+	"WARN":    LevelWarn,
+	"WARNING": LevelWarn,
+	"ERROR":   LevelError,
+	"ERR":     LevelError,
+	"FATAL":   LevelError,
+	"PANIC":   LevelError,
+}
+
+// LevelOf returns the level of a line from the first level word among its
+// first few words, in upper case or followed by a colon.
+func LevelOf(line string) Level {
+	words := strings.FieldsFunc(line, func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z')
+	})
+	if len(words) > 8 {
+		words = words[:8]
+	}
+	for _, w := range words {
+		if l, ok := levelWords[strings.ToUpper(w)]; ok && (w == strings.ToUpper(w) || strings.Contains(line, w+":")) {
+			return l
+		}
+	}
+	return LevelNone
+}
+
+var (
+	errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	// Colors are the colors of lines by level. Lines of other levels
+	// are in the color of the theme.
+	Colors = map[Level]color.NRGBA{
+		LevelDebug: {R: 0x75, G: 0x75, B: 0x75, A: 0xff},
+		LevelWarn:  {R: 0xe6, G: 0x51, B: 0x00, A: 0xff},
+		LevelError: errorColor,
+	}
+)
@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package logview
+
+import "testing"
+
+func TestLevelOf(t *testing.T) {
+	tests := []struct {
+		line string
+		want Level
+	}{
+		{"2021-05-01 12:00:00 ERROR disk full", LevelError},
+		{"[2021-05-01T12:00:00Z] [WARN] slow request", LevelWarn},
+		{"level=info msg=started", LevelNone},
+		{"INFO: started", LevelInfo},
+		{"Error handling is fine here", LevelNone},
+		{"Error: not found", LevelError},
+	}
+	for _, test := range tests {
+		if got := LevelOf(test.line); got != test.want {
+			t.Errorf("LevelOf(%q) = %v, want %v", test.line, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package logview
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+var monoFont = text.Font{Variant: "Mono"}
+
+// View is a scrolling list of log lines.
+type View struct {
+	// Follow keeps the last line in view as lines are added. Lay it out
+	// as a check box to let the user turn it off.
+	Follow widget.Bool
+	// List is the list of lines. Set its Position to jump to a line,
+	// after turning off Follow.
+	List layout.List
+}
+
+// NewView returns a view following new lines.
+func NewView() *View {
+	return &View{
+		Follow: widget.Bool{Value: true},
+		List:   layout.List{Axis: layout.Vertical, ScrollToEnd: true},
+	}
+}
+
+// Stop stops following new lines, as before moving the List.
+func (v *View) Stop() {
+	v.Follow.Value = false
+	v.List.ScrollToEnd = false
+}
+
+// Layout lays out rows rows. The line function returns the number,
+// counted from 0, and the text of the line of a row, or false for a row
+// with no line. Line numbers are padded to the width of the number of
+// the last line, last.
+func (v *View) Layout(gtx layout.Context, th *material.Theme, rows, last int, line func(row int) (int, string, bool)) layout.Dimensions {
+	if v.Follow.Changed() && v.Follow.Value {
+		v.List.Position = layout.Position{BeforeEnd: false}
+	}
+	v.List.Axis = layout.Vertical
+	v.List.ScrollToEnd = v.Follow.Value
+	gutter := len(strconv.Itoa(last + 1))
+	return v.List.Layout(gtx, rows, func(gtx layout.Context, i int) layout.Dimensions {
+		n, txt, ok := line(i)
+		if !ok {
+			return layout.Dimensions{}
+		}
+		return layoutLine(gtx, th, n, gutter, txt)
+	})
+}
+
+func layoutLine(gtx layout.Context, th *material.Theme, n, gutter int, line string) layout.Dimensions {
+	return layout.Inset{Left: unit.Dp(8), Right: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				l := material.Body2(th, fmt.Sprintf("%*d ", gutter, n+1))
+				l.Font = monoFont
+				l.Color = color.NRGBA{A: 0x60}
+				return l.Layout(gtx)
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				l := material.Body2(th, line)
+				l.Font = monoFont
+				l.MaxLines = 1
+				if c, ok := Colors[LevelOf(line)]; ok {
+					l.Color = c
+				}
+				return l.Layout(gtx)
+			}),
+		)
+	})
+}
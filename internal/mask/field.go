@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package mask implements text fields that format their input as it is
+// typed, such as phone numbers, card numbers, amounts and addresses, and
+// validate the result.
+package mask
+
+import (
+	"image/color"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Field is a single line editor formatted by a Formatter.
+type Field struct {
+	Formatter Formatter
+
+	editor widget.Editor
+	err    error
+	// touched is set once the field loses the focus, after which errors
+	// are shown.
+	touched bool
+	focused bool
+}
+
+// Text returns the formatted text.
+func (f *Field) Text() string {
+	return f.editor.Text()
+}
+
+// Err returns the validation error of the text.
+func (f *Field) Err() error {
+	f.update()
+	return f.err
+}
+
+func (f *Field) update() {
+	f.editor.SingleLine = true
+	for _, e := range f.editor.Events() {
+		if _, ok := e.(widget.ChangeEvent); !ok {
+			continue
+		}
+		text := f.editor.Text()
+		caret, _ := f.editor.Selection()
+		formatted, caret := Reformat(f.Formatter, text, caret)
+		if formatted != text {
+			f.editor.SetText(formatted)
+			f.editor.SetCaret(caret, caret)
+		}
+	}
+	f.err = f.Formatter.Validate(f.editor.Text())
+	focused := f.editor.Focused()
+	if f.focused && !focused {
+		f.touched = true
+	}
+	f.focused = focused
+}
+
+// Layout lays out the field with a label above and the validation error,
+// if any, below. Errors are shown after the field was first left.
+func (f *Field) Layout(gtx layout.Context, th *material.Theme, label, hint string) layout.Dimensions {
+	f.update()
+	errColor := color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	showErr := f.err != nil && f.touched && !f.focused
+	border := color.NRGBA{A: 0x40}
+	switch {
+	case showErr:
+		border = errColor
+	case f.focused:
+		border = th.Palette.ContrastBg
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Bottom: unit.Dp(4)}.Layout(gtx, material.Body2(th, label).Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			return widget.Border{Color: border, CornerRadius: unit.Dp(4), Width: unit.Dp(1)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.UniformInset(unit.Dp(8)).Layout(gtx, material.Editor(th, &f.editor, hint).Layout)
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			msg := ""
+			if showErr {
+				msg = f.err.Error()
+			}
+			l := material.Caption(th, msg)
+			l.Color = errColor
+			return layout.Inset{Top: unit.Dp(2)}.Layout(gtx, l.Layout)
+		}),
+	)
+}
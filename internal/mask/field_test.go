@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package mask
+
+import (
+	"fmt"
+	"testing"
+
+	"gioui.org/example/internal/edittest"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/widget/material"
+)
+
+func TestFieldFuzz(t *testing.T) {
+	th := material.NewTheme(gofont.Collection())
+	formatters := []Formatter{Phone, Card{}, Currency{}, IPv4{}}
+	edittest.Run(t, func(seed int64) *edittest.Harness {
+		f := &Field{Formatter: formatters[int(seed)%len(formatters)]}
+		return &edittest.Harness{
+			Editor: &f.editor,
+			Layout: func(gtx layout.Context) {
+				f.Layout(gtx, th, "Label", "Hint")
+			},
+			// The field formats the text between frames.
+			Check: func() error {
+				f.Err()
+				text := f.Text()
+				if formatted, _ := Reformat(f.Formatter, text, 0); formatted != text {
+					return fmt.Errorf("%T: text %q, want %q", f.Formatter, text, formatted)
+				}
+				return nil
+			},
+		}
+	})
+}
@@ -0,0 +1,253 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package mask
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Formatter formats and validates the text of a field as it is typed.
+type Formatter interface {
+	// Format returns s formatted, dropping the characters not allowed.
+	// It must accept its own output.
+	Format(s string) string
+	// Significant reports whether r is a part of the value, as opposed to
+	// a separator inserted by Format.
+	Significant(r rune) bool
+	// Validate returns an error if the formatted s is not a complete,
+	// valid value.
+	Validate(s string) error
+}
+
+// Reformat formats text with f, moving the caret to stay after the same
+// number of significant characters and the separators following them. The
+// caret is a rune offset.
+func Reformat(f Formatter, text string, caret int) (string, int) {
+	rs := []rune(text)
+	if caret > len(rs) {
+		caret = len(rs)
+	}
+	out := []rune(f.Format(text))
+	if caret == len(rs) {
+		return string(out), len(out)
+	}
+	n := 0
+	for _, r := range rs[:caret] {
+		if f.Significant(r) {
+			n++
+		}
+	}
+	pos := 0
+	for n > 0 && pos < len(out) {
+		if f.Significant(out[pos]) {
+			n--
+		}
+		pos++
+	}
+	// Skip separators, so that typing continues after them.
+	for pos < len(out) && !f.Significant(out[pos]) {
+		pos++
+	}
+	return string(out), pos
+}
+
+// Pattern is a Formatter filling a fixed pattern, where '#' stands for a
+// digit, 'A' for a letter and '*' for either. Other characters of the
+// pattern are inserted as they are reached.
+type Pattern string
+
+func (p Pattern) Significant(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (p Pattern) Format(s string) string {
+	var in []rune
+	for _, r := range s {
+		if p.Significant(r) {
+			in = append(in, r)
+		}
+	}
+	var b strings.Builder
+	// lit are the pattern characters reached, written once input
+	// follows them.
+	var lit []rune
+	for _, pr := range p {
+		if len(in) == 0 {
+			break
+		}
+		switch pr {
+		case '#', 'A', '*':
+			// Skip input not matching the placeholder.
+			for len(in) > 0 && !placeholderMatch(pr, in[0]) {
+				in = in[1:]
+			}
+			if len(in) == 0 {
+				break
+			}
+			b.WriteString(string(lit))
+			lit = lit[:0]
+			b.WriteRune(in[0])
+			in = in[1:]
+		default:
+			lit = append(lit, pr)
+		}
+	}
+	return b.String()
+}
+
+func placeholderMatch(p, r rune) bool {
+	switch p {
+	case '#':
+		return unicode.IsDigit(r)
+	case 'A':
+		return unicode.IsLetter(r)
+	default:
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+}
+
+func (p Pattern) Validate(s string) error {
+	if len([]rune(s)) != len([]rune(string(p))) {
+		return errors.New("incomplete")
+	}
+	return nil
+}
+
+// Phone formats North American phone numbers.
+var Phone = Pattern("(###) ###-####")
+
+// Card formats payment card numbers in groups of four digits and checks
+// their Luhn check digit.
+type Card struct{}
+
+func (Card) Significant(r rune) bool { return unicode.IsDigit(r) }
+
+func (Card) Format(s string) string {
+	return Pattern("#### #### #### ####").Format(s)
+}
+
+func (c Card) Validate(s string) error {
+	if err := Pattern("#### #### #### ####").Validate(s); err != nil {
+		return err
+	}
+	if !luhn(strings.ReplaceAll(s, " ", "")) {
+		return errors.New("invalid card number")
+	}
+	return nil
+}
+
+// luhn reports whether the digits end in a valid Luhn check digit.
+func luhn(digits string) bool {
+	sum := 0
+	for i := range digits {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// Currency formats amounts with thousands separators and at most two
+// decimals, such as 1,234.5.
+type Currency struct{}
+
+func (Currency) Significant(r rune) bool { return unicode.IsDigit(r) || r == '.' }
+
+func (Currency) Format(s string) string {
+	var whole, frac []rune
+	point := false
+	for _, r := range s {
+		switch {
+		case r == '.' && !point:
+			point = true
+		case !unicode.IsDigit(r):
+		case point:
+			if len(frac) < 2 {
+				frac = append(frac, r)
+			}
+		default:
+			whole = append(whole, r)
+		}
+	}
+	// Drop leading zeros, but keep one before the point.
+	for len(whole) > 1 && whole[0] == '0' {
+		whole = whole[1:]
+	}
+	if len(whole) == 0 && point {
+		whole = []rune{'0'}
+	}
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteRune(',')
+		}
+		b.WriteRune(r)
+	}
+	if point {
+		b.WriteRune('.')
+		b.WriteString(string(frac))
+	}
+	return b.String()
+}
+
+func (Currency) Validate(s string) error {
+	if s == "" || s == "." {
+		return errors.New("enter an amount")
+	}
+	return nil
+}
+
+// IPv4 formats dotted IPv4 addresses, starting the next octet after three
+// digits.
+type IPv4 struct{}
+
+func (IPv4) Significant(r rune) bool { return unicode.IsDigit(r) }
+
+func (IPv4) Format(s string) string {
+	var b strings.Builder
+	octets, digits := 1, 0
+	for _, r := range s {
+		switch {
+		case r == '.':
+			if digits > 0 && octets < 4 {
+				b.WriteRune('.')
+				octets++
+				digits = 0
+			}
+		case unicode.IsDigit(r):
+			if digits == 3 {
+				if octets == 4 {
+					continue
+				}
+				b.WriteRune('.')
+				octets++
+				digits = 0
+			}
+			b.WriteRune(r)
+			digits++
+		}
+	}
+	return b.String()
+}
+
+func (IPv4) Validate(s string) error {
+	parts := strings.Split(s, ".")
+	if len(parts) != 4 || parts[3] == "" {
+		return errors.New("incomplete address")
+	}
+	for _, p := range parts {
+		if n, _ := strconv.Atoi(p); n > 255 {
+			return fmt.Errorf("%s is out of range", p)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package mask
+
+import (
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		f        Formatter
+		in, want string
+	}{
+		{Phone, "", ""},
+		{Phone, "5", "(5"},
+		{Phone, "555123", "(555) 123"},
+		{Phone, "(555) 1234567890", "(555) 123-4567"},
+		{Phone, "55a5", "(555"},
+		{Phone, "555a", "(555"},
+		{Phone, "a", ""},
+		{Card{}, "4111111111111111", "4111 1111 1111 1111"},
+		{Card{}, "7077 Q", "7077"},
+		{Currency{}, "1234567.891", "1,234,567.89"},
+		{Currency{}, "00012", "12"},
+		{Currency{}, ".5", "0.5"},
+		{Currency{}, "1,2,3", "123"},
+		{IPv4{}, "19216801", "192.168.01"},
+		{IPv4{}, "10.0.0.1", "10.0.0.1"},
+		{IPv4{}, "1..2", "1.2"},
+		{IPv4{}, "1.2.3.4.5", "1.2.3.45"},
+	}
+	for _, test := range tests {
+		if got := test.f.Format(test.in); got != test.want {
+			t.Errorf("%T.Format(%q) = %q, want %q", test.f, test.in, got, test.want)
+		}
+		if got := test.f.Format(test.want); got != test.want {
+			t.Errorf("%T.Format(%q) = %q, want it unchanged", test.f, test.want, got)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		f     Formatter
+		in    string
+		valid bool
+	}{
+		{Phone, "(555) 123-4567", true},
+		{Phone, "(555) 123", false},
+		{Card{}, "4111 1111 1111 1111", true},
+		{Card{}, "4111 1111 1111 1112", false},
+		{Currency{}, "0.5", true},
+		{Currency{}, "", false},
+		{IPv4{}, "192.168.0.1", true},
+		{IPv4{}, "192.168.0.", false},
+		{IPv4{}, "192.168.0.256", false},
+	}
+	for _, test := range tests {
+		if err := test.f.Validate(test.in); (err == nil) != test.valid {
+			t.Errorf("%T.Validate(%q) = %v, want valid %v", test.f, test.in, err, test.valid)
+		}
+	}
+}
+
+func TestReformat(t *testing.T) {
+	tests := []struct {
+		f         Formatter
+		in        string
+		caret     int
+		want      string
+		wantCaret int
+	}{
+		// Typing at the end moves the caret to the end.
+		{Phone, "(555) 1234", 10, "(555) 123-4", 11},
+		// Inserting a digit in the middle keeps the caret after it, and
+		// after the separators following it.
+		{Phone, "(5595) 123", 4, "(559) 512-3", 6},
+		{Phone, "(555) 123", 6, "(555) 123", 6},
+		// Deleting a separator in the middle.
+		{Currency{}, "1234,567", 4, "1,234,567", 6},
+		{Currency{}, "12345", 2, "12,345", 3},
+		{IPv4{}, "1921.1", 4, "192.1.1", 6},
+	}
+	for _, test := range tests {
+		got, caret := Reformat(test.f, test.in, test.caret)
+		if got != test.want || caret != test.wantCaret {
+			t.Errorf("Reformat(%T, %q, %d) = %q, %d, want %q, %d", test.f, test.in, test.caret, got, caret, test.want, test.wantCaret)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package memstats
+
+import (
+	"image"
+
+	"gioui.org/op"
+)
+
+// ImageUsage estimates the memory of the images of a frame.
+type ImageUsage struct {
+	// Count is the number of distinct images.
+	Count int
+	// Bytes is the size of their pixels. Gio keeps every image as an
+	// RGBA copy in memory and uploads it to a texture of the same size,
+	// so the images use about twice as many bytes in total.
+	Bytes int
+}
+
+// Images finds the images drawn by ops, including the ops they call.
+func Images(ops *op.Ops) ImageUsage {
+	var u ImageUsage
+	seen := make(map[interface{}]bool)
+	var scan func(ops *op.Ops)
+	scan = func(ops *op.Ops) {
+		if seen[ops] {
+			return
+		}
+		seen[ops] = true
+		// Image operations reference their pixels, and calls reference
+		// the operations they call.
+		for _, r := range ops.Refs() {
+			switch r := r.(type) {
+			case *image.RGBA:
+				if !seen[r] {
+					seen[r] = true
+					u.Count++
+					u.Bytes += len(r.Pix)
+				}
+			case *op.Ops:
+				scan(r)
+			}
+		}
+	}
+	scan(ops)
+	return u
+}
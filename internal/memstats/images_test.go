@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package memstats
+
+import (
+	"image"
+	"testing"
+
+	"gioui.org/op"
+	"gioui.org/op/paint"
+)
+
+func TestImages(t *testing.T) {
+	small := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	big := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	smallOp, bigOp := paint.NewImageOp(small), paint.NewImageOp(big)
+
+	// The big image is drawn through a call to other ops.
+	var other op.Ops
+	m := op.Record(&other)
+	bigOp.Add(&other)
+	paint.PaintOp{}.Add(&other)
+	call := m.Stop()
+
+	var ops op.Ops
+	smallOp.Add(&ops)
+	paint.PaintOp{}.Add(&ops)
+	// Images drawn twice count once.
+	smallOp.Add(&ops)
+	paint.PaintOp{}.Add(&ops)
+	call.Add(&ops)
+	paint.ColorOp{}.Add(&ops)
+
+	got := Images(&ops)
+	want := ImageUsage{Count: 2, Bytes: 4*4*4 + 10*10*4}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestSize(t *testing.T) {
+	for b, want := range map[uint64]string{
+		12:        "12 B",
+		1536:      "1.5 KiB",
+		3 << 20:   "3.0 MiB",
+		5<<30 + 1: "5.0 GiB",
+	} {
+		if got := Size(b); got != want {
+			t.Errorf("Size(%d) = %q, want %q", b, got, want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package memstats implements a debug overlay showing the memory use of
+// a program: the heap, the allocations since the frame before and an
+// estimate of the memory of the images drawn. Add it to the frames of
+// any example, after the rest of the layout:
+//
+//	var mem memstats.Overlay
+//	...
+//	a.Layout(gtx, th)
+//	mem.Layout(gtx, th)
+//	e.Frame(gtx.Ops)
+//
+// The overlay shows when the GIO_MEMSTATS environment variable is set,
+// so it can stay in the code of examples at no cost.
+package memstats
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"runtime"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Enabled reports whether overlays show. It is set from the GIO_MEMSTATS
+// environment variable.
+var Enabled = os.Getenv("GIO_MEMSTATS") != ""
+
+// maxFrames is the number of frames in the allocation graph.
+const maxFrames = 120
+
+var (
+	panelBg  = color.NRGBA{R: 0x21, G: 0x21, B: 0x21, A: 0xe0}
+	panelFg  = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	barColor = color.NRGBA{R: 0xff, G: 0xb3, B: 0x00, A: 0xff}
+)
+
+// Overlay shows memory statistics in the top right corner of a window.
+// Clicking it collapses it to the allocations of the last frame. The
+// allocations between frames include those of every goroutine, and the
+// ones of the overlay itself.
+type Overlay struct {
+	// Show shows the overlay even if not Enabled.
+	Show bool
+
+	prev    runtime.MemStats
+	started bool
+	// bytes and objects are the allocations of the latest frames.
+	bytes, objects []uint64
+	next           int
+
+	toggle    widget.Clickable
+	collapsed bool
+}
+
+func (o *Overlay) sample() (ms runtime.MemStats) {
+	runtime.ReadMemStats(&ms)
+	if o.started {
+		b, n := ms.TotalAlloc-o.prev.TotalAlloc, ms.Mallocs-o.prev.Mallocs
+		if len(o.bytes) < maxFrames {
+			o.bytes = append(o.bytes, b)
+			o.objects = append(o.objects, n)
+		} else {
+			o.bytes[o.next] = b
+			o.objects[o.next] = n
+			o.next = (o.next + 1) % maxFrames
+		}
+	}
+	o.prev = ms
+	o.started = true
+	return ms
+}
+
+// last returns the allocations of the last frame.
+func (o *Overlay) last() (bytes, objects uint64) {
+	if len(o.bytes) == 0 {
+		return 0, 0
+	}
+	i := len(o.bytes) - 1
+	if len(o.bytes) == maxFrames {
+		i = (o.next + maxFrames - 1) % maxFrames
+	}
+	return o.bytes[i], o.objects[i]
+}
+
+// Layout samples the memory statistics and draws the overlay over the
+// operations of gtx.
+func (o *Overlay) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	if !Enabled && !o.Show {
+		return layout.Dimensions{}
+	}
+	for o.toggle.Clicked() {
+		o.collapsed = !o.collapsed
+	}
+	ms := o.sample()
+	imgs := Images(gtx.Ops)
+	b, n := o.last()
+	var lines []string
+	if o.collapsed {
+		lines = []string{fmt.Sprintf("%s/frame", Size(b))}
+	} else {
+		var sum uint64
+		for _, b := range o.bytes {
+			sum += b
+		}
+		avg := uint64(0)
+		if len(o.bytes) > 0 {
+			avg = sum / uint64(len(o.bytes))
+		}
+		lines = []string{
+			fmt.Sprintf("Heap %s in use, %s from the OS", Size(ms.HeapInuse), Size(ms.Sys)),
+			fmt.Sprintf("%d objects live, %d GCs", ms.HeapObjects, ms.NumGC),
+			fmt.Sprintf("Frame: %d allocations, %s (avg %s)", n, Size(b), Size(avg)),
+			fmt.Sprintf("Images: %d, %s (and as much in textures)", imgs.Count, Size(uint64(imgs.Bytes))),
+		}
+	}
+	return layout.NE.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Stack{}.Layout(gtx,
+				layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+					return o.layoutPanel(gtx, th, lines)
+				}),
+				layout.Expanded(o.toggle.Layout),
+			)
+		})
+	})
+}
+
+func (o *Overlay) layoutPanel(gtx layout.Context, th *material.Theme, lines []string) layout.Dimensions {
+	m := op.Record(gtx.Ops)
+	dims := layout.UniformInset(unit.Dp(6)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		var children []layout.FlexChild
+		for _, l := range lines {
+			lbl := material.Caption(th, l)
+			lbl.Color = panelFg
+			lbl.Font.Variant = "Mono"
+			children = append(children, layout.Rigid(lbl.Layout))
+		}
+		if !o.collapsed {
+			children = append(children, layout.Rigid(o.layoutGraph))
+		}
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	})
+	call := m.Stop()
+	r := f32.Rectangle{Max: layout.FPt(dims.Size)}
+	paint.FillShape(gtx.Ops, panelBg, clip.UniformRRect(r, float32(gtx.Px(unit.Dp(4)))).Op(gtx.Ops))
+	call.Add(gtx.Ops)
+	return dims
+}
+
+// layoutGraph draws the bytes allocated per frame, scaled to the
+// largest.
+func (o *Overlay) layoutGraph(gtx layout.Context) layout.Dimensions {
+	sz := image.Pt(gtx.Px(unit.Dp(240)), gtx.Px(unit.Dp(32)))
+	values := append(append([]uint64(nil), o.bytes[o.next:]...), o.bytes[:o.next]...)
+	var max uint64
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	if max == 0 {
+		return layout.Dimensions{Size: sz}
+	}
+	w := float32(sz.X) / maxFrames
+	for i, v := range values {
+		h := float32(sz.Y) * float32(v) / float32(max)
+		r := f32.Rect(float32(i)*w, float32(sz.Y)-h, float32(i+1)*w, float32(sz.Y))
+		paint.FillShape(gtx.Ops, barColor, clip.UniformRRect(r, 0).Op(gtx.Ops))
+	}
+	return layout.Dimensions{Size: sz}
+}
+
+// Size formats a number of bytes.
+func Size(b uint64) string {
+	switch {
+	case b >= 1<<30:
+		return fmt.Sprintf("%.1f GiB", float64(b)/(1<<30))
+	case b >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(b)/(1<<20))
+	case b >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(b)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", b)
+}
@@ -0,0 +1,192 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package minimap implements an overview of a canvas too large for the
+// screen, such as a node graph or a whiteboard. The minimap draws the
+// canvas scaled down by calling a macro of its drawing operations, which
+// the canvas records once and reuses for its own view, and outlines the
+// area shown by the canvas. Dragging the outline, or pressing elsewhere
+// on the minimap, moves the area and the canvas follows.
+package minimap
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+)
+
+// Minimap is the state of a minimap.
+type Minimap struct {
+	// Bounds is the area of the canvas, in canvas coordinates.
+	Bounds f32.Rectangle
+	// View is the area of the canvas on screen, in canvas coordinates.
+	// Set it every frame from the pan and zoom of the canvas, and read
+	// it back when Changed reports that the user moved it.
+	View f32.Rectangle
+
+	Background color.NRGBA
+	ViewColor  color.NRGBA
+
+	dragging bool
+	// grab is the position of the pointer in the view when dragging
+	// started, in canvas coordinates.
+	grab    f32.Point
+	changed bool
+
+	// scale and origin map canvas coordinates to the minimap in the last
+	// frame.
+	scale  float32
+	origin f32.Point
+}
+
+var (
+	defaultBackground = color.NRGBA{R: 0xfa, G: 0xfa, B: 0xfa, A: 0xf0}
+	defaultViewColor  = color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0xff}
+)
+
+// Changed reports whether the user moved the view since the last call.
+func (m *Minimap) Changed() bool {
+	c := m.changed
+	m.changed = false
+	return c
+}
+
+// Dragging reports whether the view is being dragged.
+func (m *Minimap) Dragging() bool {
+	return m.dragging
+}
+
+// toCanvas converts a minimap position to canvas coordinates.
+func (m *Minimap) toCanvas(p f32.Point) f32.Point {
+	return p.Sub(m.origin).Mul(1 / m.scale).Add(m.Bounds.Min)
+}
+
+func (m *Minimap) update(gtx layout.Context) {
+	for _, e := range gtx.Events(m) {
+		e, ok := e.(pointer.Event)
+		if !ok || m.scale == 0 {
+			continue
+		}
+		p := m.toCanvas(e.Position)
+		switch e.Type {
+		case pointer.Press:
+			m.dragging = true
+			if p.In(m.View) {
+				m.grab = p.Sub(m.View.Min)
+			} else {
+				// Center the view on the press and drag from there.
+				m.grab = m.View.Size().Mul(.5)
+				m.moveTo(p)
+			}
+		case pointer.Drag:
+			if m.dragging {
+				m.moveTo(p)
+			}
+		case pointer.Release, pointer.Cancel:
+			m.dragging = false
+		}
+	}
+}
+
+// moveTo moves the view to place the grabbed point at p, keeping the
+// center of the view within the canvas.
+func (m *Minimap) moveTo(p f32.Point) {
+	size := m.View.Size()
+	min := p.Sub(m.grab)
+	c := min.Add(size.Mul(.5))
+	c.X = clamp(c.X, m.Bounds.Min.X, m.Bounds.Max.X)
+	c.Y = clamp(c.Y, m.Bounds.Min.Y, m.Bounds.Max.Y)
+	min = c.Sub(size.Mul(.5))
+	if min == m.View.Min {
+		return
+	}
+	m.View = f32.Rectangle{Min: min, Max: min.Add(size)}
+	m.changed = true
+}
+
+// Layout draws the canvas, by calling its macro recorded in canvas
+// coordinates, as large as fits the constraints and keeping its aspect
+// ratio. The macro is only transformed, so the minimap is cheap to draw
+// when the canvas caches its drawing.
+func (m *Minimap) Layout(gtx layout.Context, canvas op.CallOp) layout.Dimensions {
+	m.update(gtx)
+	bsz := m.Bounds.Size()
+	if bsz.X <= 0 || bsz.Y <= 0 {
+		return layout.Dimensions{Size: gtx.Constraints.Min}
+	}
+	max := layout.FPt(gtx.Constraints.Max)
+	m.scale = max.X / bsz.X
+	if s := max.Y / bsz.Y; s < m.scale {
+		m.scale = s
+	}
+	size := gtx.Constraints.Constrain(image.Pt(int(bsz.X*m.scale+.5), int(bsz.Y*m.scale+.5)))
+	m.origin = layout.FPt(size).Sub(bsz.Mul(m.scale)).Mul(.5)
+
+	bg, vc := m.Background, m.ViewColor
+	if bg == (color.NRGBA{}) {
+		bg = defaultBackground
+	}
+	if vc == (color.NRGBA{}) {
+		vc = defaultViewColor
+	}
+
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	paint.ColorOp{Color: bg}.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+
+	st := op.Save(gtx.Ops)
+	op.Affine(f32.Affine2D{}.
+		Offset(m.Bounds.Min.Mul(-1)).
+		Scale(f32.Point{}, f32.Pt(m.scale, m.scale)).
+		Offset(m.origin)).Add(gtx.Ops)
+	canvas.Add(gtx.Ops)
+	st.Load()
+
+	// The view, tinted and outlined.
+	view := f32.Rectangle{
+		Min: m.View.Min.Sub(m.Bounds.Min).Mul(m.scale).Add(m.origin),
+		Max: m.View.Max.Sub(m.Bounds.Min).Mul(m.scale).Add(m.origin),
+	}
+	tint := vc
+	tint.A = 0x30
+	paint.FillShape(gtx.Ops, tint, clip.RRect{Rect: view}.Op(gtx.Ops))
+	var p clip.Path
+	p.Begin(gtx.Ops)
+	p.MoveTo(view.Min)
+	p.LineTo(f32.Pt(view.Max.X, view.Min.Y))
+	p.LineTo(view.Max)
+	p.LineTo(f32.Pt(view.Min.X, view.Max.Y))
+	p.Close()
+	w := float32(gtx.Px(unit.Dp(1.5)))
+	paint.FillShape(gtx.Ops, vc, clip.Stroke{Path: p.End(), Style: clip.StrokeStyle{Width: w}}.Op())
+
+	pointer.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	pointer.InputOp{
+		Tag:   m,
+		Grab:  m.dragging,
+		Types: pointer.Press | pointer.Drag | pointer.Release,
+	}.Add(gtx.Ops)
+	cursor := pointer.CursorPointer
+	if m.dragging {
+		cursor = pointer.CursorGrab
+	}
+	pointer.CursorNameOp{Name: cursor}.Add(gtx.Ops)
+	return layout.Dimensions{Size: size}
+}
+
+func clamp(v, min, max float32) float32 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
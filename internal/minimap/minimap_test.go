@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package minimap
+
+import (
+	"image"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+)
+
+func TestDrag(t *testing.T) {
+	// A canvas of 1000 by 500 on a minimap of 200 by 100: a fifth.
+	m := &Minimap{
+		Bounds: f32.Rect(0, 0, 1000, 500),
+		View:   f32.Rect(0, 0, 200, 100),
+	}
+	var r router.Router
+	ops := new(op.Ops)
+	frame := func() layout.Dimensions {
+		ops.Reset()
+		gtx := layout.Context{Ops: ops, Queue: &r, Constraints: layout.Exact(image.Pt(200, 200))}
+		gtx.Constraints.Min = image.Point{}
+		dims := m.Layout(gtx, op.CallOp{})
+		r.Frame(ops)
+		return dims
+	}
+	if dims := frame(); dims.Size != image.Pt(200, 100) {
+		t.Fatalf("laid out at %v, want 200x100", dims.Size)
+	}
+	if m.Changed() {
+		t.Error("changed before any input")
+	}
+	// Grab the view in its middle and drag it right by 10 pixels, 50
+	// canvas units.
+	r.Queue(
+		pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(20, 10)},
+		pointer.Event{Type: pointer.Drag, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(30, 10)},
+	)
+	frame()
+	if want := f32.Rect(50, 0, 250, 100); m.View != want {
+		t.Errorf("view %v after dragging, want %v", m.View, want)
+	}
+	if !m.Changed() || m.Changed() {
+		t.Error("Changed did not report the drag once")
+	}
+	if !m.Dragging() {
+		t.Error("not dragging before the release")
+	}
+	r.Queue(pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: f32.Pt(30, 10)})
+	frame()
+	if m.Dragging() {
+		t.Error("dragging after the release")
+	}
+	// Pressing outside the view centers it on the press.
+	r.Queue(
+		pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(100, 50)},
+		pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: f32.Pt(100, 50)},
+	)
+	frame()
+	if want := f32.Rect(400, 200, 600, 300); m.View != want {
+		t.Errorf("view %v after a press, want %v", m.View, want)
+	}
+	// The center of the view stays on the canvas.
+	r.Queue(
+		pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(100, 50)},
+		pointer.Event{Type: pointer.Drag, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(400, 400)},
+	)
+	frame()
+	if want := f32.Rect(900, 450, 1100, 550); m.View != want {
+		t.Errorf("view %v dragged off the canvas, want %v", m.View, want)
+	}
+}
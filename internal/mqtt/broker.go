@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package mqtt
+
+import (
+	"bufio"
+	"net"
+	"sync"
+)
+
+// Broker is a minimal broker keeping everything in memory: it routes
+// messages at most once and keeps retained messages, but has no
+// persistent sessions, wills or authentication, and a slow subscriber
+// holds up the publishers. It is meant for demos and tests, not for real
+// devices.
+type Broker struct {
+	mu        sync.Mutex
+	listeners map[net.Listener]bool
+	conns     map[*brokerConn]bool
+	retained  map[string]Message
+	closed    bool
+}
+
+type brokerConn struct {
+	conn net.Conn
+	// mu serializes writes to conn.
+	mu sync.Mutex
+	// filters are the subscriptions, guarded by the mutex of the
+	// broker.
+	filters []string
+}
+
+// Serve accepts connections from l until the broker is closed.
+func (b *Broker) Serve(l net.Listener) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		l.Close()
+		return net.ErrClosed
+	}
+	if b.listeners == nil {
+		b.listeners = make(map[net.Listener]bool)
+	}
+	b.listeners[l] = true
+	b.mu.Unlock()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go b.ServeConn(conn)
+	}
+}
+
+// ServeConn serves a client connection until it ends.
+func (b *Broker) ServeConn(conn net.Conn) {
+	c := &brokerConn{conn: conn}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	p, err := readPacket(r)
+	if err != nil || p.typ != typeConnect {
+		return
+	}
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	if b.conns == nil {
+		b.conns = make(map[*brokerConn]bool)
+	}
+	b.conns[c] = true
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.conns, c)
+		b.mu.Unlock()
+	}()
+	if c.write(packet{typ: typeConnack, body: []byte{0, 0}}) != nil {
+		return
+	}
+	for {
+		p, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		switch p.typ {
+		case typePublish:
+			m, id, err := parsePublish(p)
+			if err != nil {
+				return
+			}
+			if id != 0 {
+				c.write(packet{typ: typePuback, body: []byte{byte(id >> 8), byte(id)}})
+			}
+			b.publish(m)
+		case typeSubscribe:
+			if !b.subscribe(c, p) {
+				return
+			}
+		case typePingreq:
+			c.write(packet{typ: typePingresp})
+		case typeDisconnect:
+			return
+		}
+	}
+}
+
+func (b *Broker) subscribe(c *brokerConn, p packet) bool {
+	d := &decoder{b: p.body}
+	id := d.uint16()
+	var filters []string
+	for len(d.b) > 0 && d.err == nil {
+		filters = append(filters, d.string())
+		d.byte()
+	}
+	if d.err != nil || len(filters) == 0 {
+		return false
+	}
+	// Grant QoS 0 for all.
+	ack := []byte{byte(id >> 8), byte(id)}
+	ack = append(ack, make([]byte, len(filters))...)
+	b.mu.Lock()
+	c.filters = append(c.filters, filters...)
+	var retained []Message
+	for _, m := range b.retained {
+		for _, f := range filters {
+			if Match(f, m.Topic) {
+				retained = append(retained, m)
+				break
+			}
+		}
+	}
+	b.mu.Unlock()
+	if c.write(packet{typ: typeSuback, body: ack}) != nil {
+		return false
+	}
+	for _, m := range retained {
+		if c.write(m.packet()) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// publish routes a message to the subscribers of its topic, and keeps
+// it if retained. A retained message with no payload removes the one
+// kept.
+func (b *Broker) publish(m Message) {
+	b.mu.Lock()
+	if m.Retained {
+		if b.retained == nil {
+			b.retained = make(map[string]Message)
+		}
+		if len(m.Payload) == 0 {
+			delete(b.retained, m.Topic)
+		} else {
+			b.retained[m.Topic] = m
+		}
+	}
+	var subs []*brokerConn
+	for c := range b.conns {
+		for _, f := range c.filters {
+			if Match(f, m.Topic) {
+				subs = append(subs, c)
+				break
+			}
+		}
+	}
+	b.mu.Unlock()
+	// Messages routed as published are not marked retained.
+	m.Retained = false
+	p := m.packet()
+	for _, c := range subs {
+		c.write(p)
+	}
+}
+
+func (c *brokerConn) write(p packet) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.conn.Write(p.encode())
+	return err
+}
+
+// Close stops the listeners and closes the connections.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for l := range b.listeners {
+		l.Close()
+	}
+	for c := range b.conns {
+		c.conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package mqtt
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// Options configure a client.
+type Options struct {
+	// ClientID identifies the client to the broker.
+	ClientID string
+	// KeepAlive is the interval of pings keeping the connection open
+	// while idle, and the time after which a silent broker is
+	// considered gone. Zero means 30 seconds.
+	KeepAlive time.Duration
+	// OnMessage is called with the messages of the subscriptions, from
+	// the goroutine reading the connection, in the order received.
+	OnMessage func(Message)
+}
+
+// ErrClosed is the error of operations on a closed client.
+var ErrClosed = errors.New("mqtt: client closed")
+
+// Client is a connection to a broker. Its methods can be called from any
+// goroutine.
+type Client struct {
+	conn      net.Conn
+	keepAlive time.Duration
+	onMessage func(Message)
+
+	mu     sync.Mutex
+	nextID uint16
+	err    error
+	done   chan struct{}
+}
+
+// Dial connects to the broker at addr, a host and port.
+func Dial(addr string, opts Options) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewClient(conn, opts)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewClient connects to the broker at the other end of conn, with a
+// clean session.
+func NewClient(conn net.Conn, opts Options) (*Client, error) {
+	c := &Client{
+		conn:      conn,
+		keepAlive: opts.KeepAlive,
+		onMessage: opts.OnMessage,
+		done:      make(chan struct{}),
+	}
+	if c.keepAlive == 0 {
+		c.keepAlive = 30 * time.Second
+	}
+	body := appendString(nil, "MQTT")
+	secs := int(c.keepAlive / time.Second)
+	// Protocol level 4, clean session.
+	body = append(body, 4, 0x02, byte(secs>>8), byte(secs))
+	body = appendString(body, opts.ClientID)
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	if _, err := conn.Write(packet{typ: typeConnect, body: body}.encode()); err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(conn)
+	p, err := readPacket(r)
+	if err != nil {
+		return nil, err
+	}
+	if p.typ != typeConnack || len(p.body) != 2 {
+		return nil, errMalformed
+	}
+	if rc := p.body[1]; rc != 0 {
+		return nil, ConnectError(rc)
+	}
+	conn.SetDeadline(time.Time{})
+	go c.read(r)
+	go c.ping()
+	return c, nil
+}
+
+func (c *Client) read(r *bufio.Reader) {
+	for {
+		// The broker answers pings, so silence for longer than the
+		// keep alive means the connection is gone.
+		c.conn.SetReadDeadline(time.Now().Add(c.keepAlive * 3 / 2))
+		p, err := readPacket(r)
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		switch p.typ {
+		case typePublish:
+			m, id, err := parsePublish(p)
+			if err != nil {
+				c.fail(err)
+				return
+			}
+			if id != 0 {
+				// Acknowledge messages sent at least once.
+				c.write(packet{typ: typePuback, body: []byte{byte(id >> 8), byte(id)}})
+			}
+			if c.onMessage != nil {
+				c.onMessage(m)
+			}
+		case typeSuback:
+			d := &decoder{b: p.body}
+			d.uint16()
+			for len(d.b) > 0 {
+				if d.byte() == 0x80 {
+					c.fail(errors.New("mqtt: subscription refused"))
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *Client) ping() {
+	t := time.NewTicker(c.keepAlive)
+	defer t.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-t.C:
+			c.write(packet{typ: typePingreq})
+		}
+	}
+}
+
+// fail closes the connection, recording the first error.
+func (c *Client) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	c.err = err
+	close(c.done)
+	c.conn.Close()
+}
+
+func (c *Client) write(p packet) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.done:
+		if c.err == nil {
+			return ErrClosed
+		}
+		return c.err
+	default:
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(c.keepAlive))
+	if _, err := c.conn.Write(p.encode()); err != nil {
+		c.err = err
+		close(c.done)
+		c.conn.Close()
+		return err
+	}
+	return nil
+}
+
+// Subscribe subscribes to the topics matching filters. The broker sends
+// the retained messages of the topics right away.
+func (c *Client) Subscribe(filters ...string) error {
+	c.mu.Lock()
+	c.nextID++
+	if c.nextID == 0 {
+		c.nextID = 1
+	}
+	id := c.nextID
+	c.mu.Unlock()
+	body := []byte{byte(id >> 8), byte(id)}
+	for _, f := range filters {
+		body = appendString(body, f)
+		body = append(body, 0)
+	}
+	return c.write(packet{typ: typeSubscribe, flags: flagsSubscribe, body: body})
+}
+
+// Publish publishes a message at most once.
+func (c *Client) Publish(m Message) error {
+	if !ValidTopic(m.Topic) {
+		return errors.New("mqtt: invalid topic " + m.Topic)
+	}
+	return c.write(m.packet())
+}
+
+// Done is closed when the connection ends.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the error ending the connection, or nil if Close ended it.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close disconnects from the broker.
+func (c *Client) Close() error {
+	c.write(packet{typ: typeDisconnect})
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.done:
+		return nil
+	default:
+	}
+	close(c.done)
+	return c.conn.Close()
+}
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		filter, topic string
+		want          bool
+	}{
+		{"home/light", "home/light", true},
+		{"home/light", "home/light/set", false},
+		{"home/+", "home/light", true},
+		{"home/+", "home", false},
+		{"home/+/set", "home/light/set", true},
+		{"home/#", "home", true},
+		{"home/#", "home/light/set", true},
+		{"#", "home/light", true},
+		{"+/+", "/light", true},
+		{"#", "$SYS/uptime", false},
+		{"$SYS/#", "$SYS/uptime", true},
+		{"home/#/set", "home/light/set", false},
+	}
+	for _, test := range tests {
+		if got := Match(test.filter, test.topic); got != test.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", test.filter, test.topic, got, test.want)
+		}
+	}
+}
+
+func TestPacket(t *testing.T) {
+	for _, n := range []int{0, 127, 128, 16383, 16384, 2097152} {
+		p := packet{typ: typePublish, flags: flagRetain, body: bytes.Repeat([]byte{'x'}, n)}
+		got, err := readPacket(bufio.NewReader(bytes.NewReader(p.encode())))
+		if err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+		if got.typ != p.typ || got.flags != p.flags || !bytes.Equal(got.body, p.body) {
+			t.Errorf("%d bytes: packet changed in encoding", n)
+		}
+	}
+	if _, err := readPacket(bufio.NewReader(bytes.NewReader([]byte{0x30, 0xff, 0xff, 0xff, 0xff, 0x7f}))); err != errMalformed {
+		t.Errorf("5 byte remaining length: got error %v, want %v", err, errMalformed)
+	}
+}
+
+func connect(t *testing.T, b *Broker, id string) (*Client, chan Message) {
+	t.Helper()
+	server, client := net.Pipe()
+	go b.ServeConn(server)
+	msgs := make(chan Message, 10)
+	c, err := NewClient(client, Options{ClientID: id, OnMessage: func(m Message) { msgs <- m }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, msgs
+}
+
+func receive(t *testing.T, msgs chan Message) Message {
+	t.Helper()
+	select {
+	case m := <-msgs:
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message")
+		return Message{}
+	}
+}
+
+func TestBroker(t *testing.T) {
+	b := new(Broker)
+	defer b.Close()
+	pub, own := connect(t, b, "pub")
+	defer pub.Close()
+	// Receive the retained message, to know the broker has it.
+	if err := pub.Subscribe("home/light"); err != nil {
+		t.Fatal(err)
+	}
+	if err := pub.Publish(Message{Topic: "home/light", Payload: []byte("ON"), Retained: true}); err != nil {
+		t.Fatal(err)
+	}
+	receive(t, own)
+
+	sub, msgs := connect(t, b, "sub")
+	defer sub.Close()
+	if err := sub.Subscribe("home/+"); err != nil {
+		t.Fatal(err)
+	}
+	if m := receive(t, msgs); m.Topic != "home/light" || string(m.Payload) != "ON" || !m.Retained {
+		t.Errorf("got %+v, want the retained light state", m)
+	}
+	pub.Publish(Message{Topic: "garden/pump", Payload: []byte("ON")})
+	pub.Publish(Message{Topic: "home/temp", Payload: []byte("21.5")})
+	if m := receive(t, msgs); m.Topic != "home/temp" || string(m.Payload) != "21.5" || m.Retained {
+		t.Errorf("got %+v, want the temperature", m)
+	}
+	if err := pub.Publish(Message{Topic: "home/#"}); err == nil {
+		t.Error("publishing to a wildcard topic succeeded")
+	}
+
+	b.Close()
+	select {
+	case <-sub.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("client still connected after closing the broker")
+	}
+	if sub.Err() == nil {
+		t.Error("lost connection has no error")
+	}
+}
@@ -0,0 +1,224 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package mqtt implements the parts of MQTT 3.1.1 the examples need: a
+// client subscribing to topics and publishing at most once (QoS 0), and a
+// small in-memory broker for running the examples without one at hand.
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Control packet types.
+const (
+	typeConnect     = 1
+	typeConnack     = 2
+	typePublish     = 3
+	typePuback      = 4
+	typeSubscribe   = 8
+	typeSuback      = 9
+	typePingreq     = 12
+	typePingresp    = 13
+	typeDisconnect  = 14
+	flagRetain      = 0x1
+	flagsSubscribe  = 0x2
+	maxRemainingLen = 268435455
+)
+
+var errMalformed = errors.New("mqtt: malformed packet")
+
+// packet is a control packet: its type, the flags of its fixed header
+// and the bytes after.
+type packet struct {
+	typ   byte
+	flags byte
+	body  []byte
+}
+
+func readPacket(r *bufio.Reader) (packet, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+	p := packet{typ: b >> 4, flags: b & 0xf}
+	n, shift := 0, 0
+	for i := 0; ; i++ {
+		if i == 4 {
+			return packet{}, errMalformed
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return packet{}, unexpectedEOF(err)
+		}
+		n |= int(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	p.body = make([]byte, n)
+	if _, err := io.ReadFull(r, p.body); err != nil {
+		return packet{}, unexpectedEOF(err)
+	}
+	return p, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// encode returns the bytes of p, with its fixed header.
+func (p packet) encode() []byte {
+	n := len(p.body)
+	if n > maxRemainingLen {
+		panic("mqtt: packet too large")
+	}
+	buf := []byte{p.typ<<4 | p.flags}
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+		if n == 0 {
+			break
+		}
+	}
+	return append(buf, p.body...)
+}
+
+func appendString(b []byte, s string) []byte {
+	b = append(b, byte(len(s)>>8), byte(len(s)))
+	return append(b, s...)
+}
+
+// decoder reads the fields of a packet body.
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) uint16() uint16 {
+	if len(d.b) < 2 {
+		d.err = errMalformed
+		return 0
+	}
+	v := binary.BigEndian.Uint16(d.b)
+	d.b = d.b[2:]
+	return v
+}
+
+func (d *decoder) byte() byte {
+	if len(d.b) < 1 {
+		d.err = errMalformed
+		return 0
+	}
+	v := d.b[0]
+	d.b = d.b[1:]
+	return v
+}
+
+func (d *decoder) string() string {
+	n := int(d.uint16())
+	if len(d.b) < n {
+		d.err = errMalformed
+		return ""
+	}
+	s := string(d.b[:n])
+	d.b = d.b[n:]
+	return s
+}
+
+// Message is a message published to a topic.
+type Message struct {
+	Topic   string
+	Payload []byte
+	// Retained reports whether the broker kept the message for new
+	// subscribers, and sent it on subscribing rather than as it was
+	// published.
+	Retained bool
+}
+
+func (m Message) packet() packet {
+	p := packet{typ: typePublish, body: appendString(nil, m.Topic)}
+	if m.Retained {
+		p.flags |= flagRetain
+	}
+	p.body = append(p.body, m.Payload...)
+	return p
+}
+
+// parsePublish parses a PUBLISH packet, returning its packet identifier
+// if its QoS is above 0.
+func parsePublish(p packet) (Message, uint16, error) {
+	d := &decoder{b: p.body}
+	m := Message{Topic: d.string(), Retained: p.flags&flagRetain != 0}
+	var id uint16
+	qos := p.flags >> 1 & 0x3
+	if qos > 0 {
+		id = d.uint16()
+	}
+	if d.err != nil {
+		return Message{}, 0, d.err
+	}
+	if qos > 2 {
+		return Message{}, 0, errMalformed
+	}
+	m.Payload = d.b
+	return m, id, nil
+}
+
+// ValidTopic reports whether topic is a topic to publish to: not empty,
+// and without wildcards.
+func ValidTopic(topic string) bool {
+	return topic != "" && !strings.ContainsAny(topic, "+#")
+}
+
+// Match reports whether a topic matches a subscription filter, where +
+// matches a level of the topic and a final # all remaining levels, if
+// any. As the specification requires, wildcards at the first level
+// don't match topics starting with $.
+func Match(filter, topic string) bool {
+	if strings.HasPrefix(topic, "$") && (strings.HasPrefix(filter, "+") || strings.HasPrefix(filter, "#")) {
+		return false
+	}
+	fs, ts := strings.Split(filter, "/"), strings.Split(topic, "/")
+	for i, f := range fs {
+		if f == "#" {
+			return i == len(fs)-1
+		}
+		if i >= len(ts) || f != "+" && f != ts[i] {
+			return false
+		}
+	}
+	return len(fs) == len(ts)
+}
+
+// ConnectError is the refusal of a connection by the broker.
+type ConnectError byte
+
+func (e ConnectError) Error() string {
+	switch e {
+	case 1:
+		return "mqtt: unacceptable protocol version"
+	case 2:
+		return "mqtt: client identifier rejected"
+	case 3:
+		return "mqtt: server unavailable"
+	case 4:
+		return "mqtt: bad user name or password"
+	case 5:
+		return "mqtt: not authorized"
+	default:
+		return fmt.Sprintf("mqtt: connection refused (%d)", byte(e))
+	}
+}
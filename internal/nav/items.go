@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package nav
+
+// ellipsis marks elided pages in the result of pageItems.
+const ellipsis = -1
+
+// pageItems returns the pages to show for total pages with the current
+// page selected: the first and last pages, the current page and its
+// siblings on either side, with ellipsis for the gaps between them. A gap
+// of a single page shows the page instead.
+func pageItems(current, total, siblings int) []int {
+	if total <= 0 {
+		return nil
+	}
+	lo, hi := current-siblings, current+siblings
+	if lo < 0 {
+		lo = 0
+	}
+	if hi > total-1 {
+		hi = total - 1
+	}
+	var items []int
+	if lo > 0 {
+		items = append(items, 0)
+	}
+	switch {
+	case lo == 2:
+		items = append(items, 1)
+	case lo > 2:
+		items = append(items, ellipsis)
+	}
+	for p := lo; p <= hi; p++ {
+		items = append(items, p)
+	}
+	switch {
+	case hi == total-3:
+		items = append(items, total-2)
+	case hi < total-3:
+		items = append(items, ellipsis)
+	}
+	if hi < total-1 {
+		items = append(items, total-1)
+	}
+	return items
+}
+
+// collapse returns the index of the first crumb shown after the root
+// crumb, given the crumb widths, the width of the separators between them
+// and of the ellipsis standing in for hidden crumbs. Crumbs 1 to the
+// result, exclusive, are hidden to fit max; the root and last crumbs are
+// always shown.
+func collapse(widths []int, sep, ellipsis, max int) int {
+	n := len(widths)
+	if n <= 2 {
+		return 1
+	}
+	total := 0
+	for i, w := range widths {
+		if i > 0 {
+			total += sep
+		}
+		total += w
+	}
+	if total <= max {
+		return 1
+	}
+	// Show the root, the ellipsis and as many trailing crumbs as fit.
+	used := widths[0] + sep + ellipsis + sep + widths[n-1]
+	first := n - 1
+	for first > 1 && used+widths[first-1]+sep <= max {
+		first--
+		used += widths[first] + sep
+	}
+	return first
+}
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package nav
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPageItems(t *testing.T) {
+	const e = ellipsis
+	tests := []struct {
+		current, total int
+		want           []int
+	}{
+		{0, 0, nil},
+		{0, 1, []int{0}},
+		{2, 5, []int{0, 1, 2, 3, 4}},
+		{0, 10, []int{0, 1, e, 9}},
+		{5, 10, []int{0, e, 4, 5, 6, e, 9}},
+		{3, 10, []int{0, 1, 2, 3, 4, e, 9}},
+		{6, 10, []int{0, e, 5, 6, 7, 8, 9}},
+		{9, 10, []int{0, e, 8, 9}},
+	}
+	for _, test := range tests {
+		if got := pageItems(test.current, test.total, 1); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("pageItems(%d, %d, 1) = %v, want %v", test.current, test.total, got, test.want)
+		}
+	}
+}
+
+func TestCollapse(t *testing.T) {
+	widths := []int{10, 20, 20, 20, 10}
+	tests := []struct {
+		max, want int
+	}{
+		// Everything fits: 80 plus 4 separators of 1.
+		{84, 1},
+		// Root, ellipsis (5), and the last two crumbs.
+		{60, 3},
+		// Only the root and the last crumb.
+		{30, 4},
+		// Too narrow for anything but the root and last crumb.
+		{0, 4},
+	}
+	for _, test := range tests {
+		if got := collapse(widths, 1, 5, test.max); got != test.want {
+			t.Errorf("collapse(%v, max %d) = %d, want %d", widths, test.max, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package nav implements navigation widgets: breadcrumb trails that
+// collapse to fit their width, and numbered pagination controls.
+package nav
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Breadcrumb is a trail of clickable path elements, the last being the
+// current location. When the trail doesn't fit, the crumbs after the
+// first are replaced by an ellipsis; clicking it selects the deepest
+// hidden crumb.
+type Breadcrumb struct {
+	Crumbs []string
+
+	clicks []widget.Clickable
+	more   widget.Clickable
+	// hidden is the index after the hidden crumbs.
+	hidden int
+}
+
+// Clicked returns the index of the crumb clicked, if any.
+func (b *Breadcrumb) Clicked() (int, bool) {
+	clicked := -1
+	for i := range b.clicks {
+		for b.clicks[i].Clicked() {
+			clicked = i
+		}
+	}
+	for b.more.Clicked() {
+		clicked = b.hidden - 1
+	}
+	return clicked, clicked >= 0
+}
+
+func (b *Breadcrumb) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	n := len(b.Crumbs)
+	if len(b.clicks) < n {
+		b.clicks = make([]widget.Clickable, n)
+	}
+	crumb := func(i int) layout.Widget {
+		return func(gtx layout.Context) layout.Dimensions {
+			l := material.Body1(th, b.Crumbs[i])
+			if i == n-1 {
+				l.Font.Weight = text.Bold
+				return pad(gtx, l.Layout)
+			}
+			l.Color = th.Palette.ContrastBg
+			return material.Clickable(gtx, &b.clicks[i], func(gtx layout.Context) layout.Dimensions {
+				return pad(gtx, l.Layout)
+			})
+		}
+	}
+	sep := func(gtx layout.Context) layout.Dimensions {
+		l := material.Body1(th, "›")
+		l.Color = color.NRGBA{A: 0x80}
+		return layout.Inset{Left: unit.Dp(2), Right: unit.Dp(2)}.Layout(gtx, l.Layout)
+	}
+	more := func(gtx layout.Context) layout.Dimensions {
+		return material.Clickable(gtx, &b.more, func(gtx layout.Context) layout.Dimensions {
+			return pad(gtx, material.Body1(th, "…").Layout)
+		})
+	}
+
+	widths := make([]int, n)
+	for i := range b.Crumbs {
+		widths[i] = measure(gtx, crumb(i))
+	}
+	first := collapse(widths, measure(gtx, sep), measure(gtx, more), gtx.Constraints.Max.X)
+	b.hidden = first
+
+	var children []layout.FlexChild
+	for i := 0; i < n; i++ {
+		if i == 1 && first > 1 {
+			children = append(children, layout.Rigid(more), layout.Rigid(sep))
+			i = first
+		}
+		children = append(children, layout.Rigid(crumb(i)))
+		if i < n-1 {
+			children = append(children, layout.Rigid(sep))
+		}
+	}
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx, children...)
+}
+
+// Pagination is a row of page buttons between previous and next buttons.
+// Pages far from the current page are elided.
+type Pagination struct {
+	// Page is the current page, counting from zero.
+	Page  int
+	Pages int
+	// Siblings is the number of pages shown on either side of the current
+	// page.
+	Siblings int
+
+	prev, next widget.Clickable
+	pages      []widget.Clickable
+	changed    bool
+}
+
+// Changed reports whether the page changed since the last call.
+func (p *Pagination) Changed() bool {
+	c := p.changed
+	p.changed = false
+	return c
+}
+
+func (p *Pagination) setPage(page int) {
+	if page < 0 || page >= p.Pages || page == p.Page {
+		return
+	}
+	p.Page = page
+	p.changed = true
+}
+
+func (p *Pagination) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	if len(p.pages) < p.Pages {
+		p.pages = make([]widget.Clickable, p.Pages)
+	}
+	for p.prev.Clicked() {
+		p.setPage(p.Page - 1)
+	}
+	for p.next.Clicked() {
+		p.setPage(p.Page + 1)
+	}
+	for i := 0; i < p.Pages; i++ {
+		for p.pages[i].Clicked() {
+			p.setPage(i)
+		}
+	}
+	siblings := p.Siblings
+	if siblings == 0 {
+		siblings = 1
+	}
+	button := func(c *widget.Clickable, label string, selected, disabled bool) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if disabled {
+				gtx = gtx.Disabled()
+			}
+			return layout.Inset{Left: unit.Dp(2), Right: unit.Dp(2)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return material.Clickable(gtx, c, func(gtx layout.Context) layout.Dimensions {
+					return pageButton(gtx, th, label, selected, disabled)
+				})
+			})
+		})
+	}
+	children := []layout.FlexChild{button(&p.prev, "‹", false, p.Page == 0)}
+	for _, page := range pageItems(p.Page, p.Pages, siblings) {
+		if page == ellipsis {
+			children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return pageButton(gtx, th, "…", false, true)
+			}))
+			continue
+		}
+		children = append(children, button(&p.pages[page], fmt.Sprint(page+1), page == p.Page, false))
+	}
+	children = append(children, button(&p.next, "›", false, p.Page >= p.Pages-1))
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx, children...)
+}
+
+// pageButton draws a page button at least as wide as it is tall.
+func pageButton(gtx layout.Context, th *material.Theme, label string, selected, disabled bool) layout.Dimensions {
+	l := material.Body2(th, label)
+	switch {
+	case selected:
+		l.Color = th.Palette.ContrastFg
+	case disabled:
+		l.Color = color.NRGBA{A: 0x60}
+	}
+	size := gtx.Px(unit.Dp(32))
+	macro := op.Record(gtx.Ops)
+	cgtx := gtx
+	cgtx.Constraints.Min = image.Point{}
+	dims := layout.Inset{Left: unit.Dp(8), Right: unit.Dp(8)}.Layout(cgtx, l.Layout)
+	call := macro.Stop()
+	w := dims.Size.X
+	if w < size {
+		w = size
+	}
+	sz := image.Pt(w, size)
+	if selected {
+		rr := float32(gtx.Px(unit.Dp(4)))
+		paint.FillShape(gtx.Ops, th.Palette.ContrastBg, clip.UniformRRect(f32.Rectangle{Max: layout.FPt(sz)}, rr).Op(gtx.Ops))
+	}
+	defer op.Save(gtx.Ops).Load()
+	op.Offset(layout.FPt(sz.Sub(dims.Size).Div(2))).Add(gtx.Ops)
+	call.Add(gtx.Ops)
+	return layout.Dimensions{Size: sz}
+}
+
+func pad(gtx layout.Context, w layout.Widget) layout.Dimensions {
+	return layout.Inset{Left: unit.Dp(4), Right: unit.Dp(4), Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx, w)
+}
+
+// measure returns the width of w.
+func measure(gtx layout.Context, w layout.Widget) int {
+	gtx.Constraints.Min = image.Point{}
+	macro := op.Record(gtx.Ops)
+	dims := w(gtx)
+	macro.Stop()
+	return dims.Size.X
+}
@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package netsim simulates slow and unreliable networks for the HTTP
+// requests of examples, so their loading and error states can be seen
+// and demonstrated without a bad connection at hand.
+//
+// Examples importing the package get its flags:
+//
+//	-net-latency 500ms   delay before every response
+//	-net-jitter 200ms    random extra delay, up to the given duration
+//	-net-bandwidth 64k   limit the bytes per second of every response
+//	-net-fail 0.2        fail a fraction of the requests
+//	-net-seed 1          choose another sequence of jitter and failures
+//
+// and call Install after flag.Parse. The jitter and failures of a
+// request depend only on the seed, its method and URL, and the number of
+// times the URL was requested before, so runs repeat exactly even when
+// requests race each other.
+package netsim
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Conditions describe a simulated network.
+type Conditions struct {
+	// Latency delays every response.
+	Latency time.Duration
+	// Jitter adds up to that much to the latency of a response.
+	Jitter time.Duration
+	// Bandwidth limits the bytes per second read from every response
+	// body, if positive.
+	Bandwidth int
+	// FailureRate is the fraction of requests failing with
+	// ErrSimulated.
+	FailureRate float64
+	// Seed selects the sequence of jitter and failures.
+	Seed int64
+}
+
+// ErrSimulated is the error of failed requests.
+var ErrSimulated = errors.New("netsim: simulated network failure")
+
+var flags = new(Conditions)
+
+func init() {
+	flag.DurationVar(&flags.Latency, "net-latency", 0, "simulated latency of HTTP responses")
+	flag.DurationVar(&flags.Jitter, "net-jitter", 0, "simulated random extra latency of HTTP responses")
+	flag.Var((*bandwidth)(&flags.Bandwidth), "net-bandwidth", "simulated bandwidth in bytes per second, with an optional k or m suffix")
+	flag.Float64Var(&flags.FailureRate, "net-fail", 0, "fraction of HTTP requests failing")
+	flag.Int64Var(&flags.Seed, "net-seed", 1, "seed of the simulated jitter and failures")
+}
+
+// Install simulates the conditions of the command line flags for every
+// request through http.DefaultTransport, if any flag is set.
+func Install() {
+	if *flags == (Conditions{Seed: flags.Seed}) {
+		return
+	}
+	http.DefaultTransport = &Transport{Base: http.DefaultTransport, Conditions: *flags}
+}
+
+// Transport is an http.RoundTripper simulating network conditions for
+// the requests it passes to Base.
+type Transport struct {
+	Base http.RoundTripper
+	Conditions
+
+	mu sync.Mutex
+	// counts is the number of requests of every method and URL.
+	counts map[string]int
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := req.Method + " " + req.URL.String()
+	t.mu.Lock()
+	if t.counts == nil {
+		t.counts = make(map[string]int)
+	}
+	n := t.counts[key]
+	t.counts[key]++
+	t.mu.Unlock()
+
+	jitter, fail := t.chances(key, n)
+	delay := t.Latency + time.Duration(jitter*float64(t.Jitter))
+	if err := sleep(req.Context(), delay); err != nil {
+		return nil, err
+	}
+	if fail < t.FailureRate {
+		return nil, fmt.Errorf("%s: %w", key, ErrSimulated)
+	}
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil || t.Bandwidth <= 0 {
+		return resp, err
+	}
+	resp.Body = &throttled{ctx: req.Context(), r: resp.Body, rate: t.Bandwidth, start: time.Now()}
+	return resp, nil
+}
+
+// chances returns two numbers in [0, 1) for the nth request of key.
+func (t *Transport) chances(key string, n int) (float64, float64) {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d %s %d", t.Seed, key, n)
+	// FNV spreads the last bytes to the high bits only; mix them.
+	v := h.Sum64()
+	v ^= v >> 33
+	v *= 0xff51afd7ed558ccd
+	v ^= v >> 33
+	return float64(v>>32) / (1 << 32), float64(v&0xffffffff) / (1 << 32)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// throttled limits the rate of reads from a response body.
+type throttled struct {
+	ctx   context.Context
+	r     io.ReadCloser
+	rate  int
+	start time.Time
+	read  int
+}
+
+func (t *throttled) Read(p []byte) (int, error) {
+	// Read at most a tenth of a second worth of bytes at a time, to
+	// deliver them smoothly.
+	if max := t.rate/10 + 1; len(p) > max {
+		p = p[:max]
+	}
+	n, err := t.r.Read(p)
+	t.read += n
+	due := t.start.Add(time.Duration(t.read) * time.Second / time.Duration(t.rate))
+	if serr := sleep(t.ctx, time.Until(due)); serr != nil {
+		return n, serr
+	}
+	return n, err
+}
+
+func (t *throttled) Close() error {
+	return t.r.Close()
+}
+
+// bandwidth is a flag.Value of bytes per second.
+type bandwidth int
+
+func (b *bandwidth) String() string {
+	if b == nil {
+		return "0"
+	}
+	return strconv.Itoa(int(*b))
+}
+
+func (b *bandwidth) Set(s string) error {
+	mul := 1
+	switch {
+	case strings.HasSuffix(s, "k"):
+		mul, s = 1<<10, strings.TrimSuffix(s, "k")
+	case strings.HasSuffix(s, "m"):
+		mul, s = 1<<20, strings.TrimSuffix(s, "m")
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*b = bandwidth(v * mul)
+	return nil
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package netsim
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func server(t *testing.T, body string) *httptest.Server {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// failures returns which of n requests to url fail.
+func failures(t *testing.T, c Conditions, url string, n int) []bool {
+	client := &http.Client{Transport: &Transport{Conditions: c}}
+	var res []bool
+	for i := 0; i < n; i++ {
+		resp, err := client.Get(url)
+		if err == nil {
+			resp.Body.Close()
+		} else if !errors.Is(err, ErrSimulated) {
+			t.Fatal(err)
+		}
+		res = append(res, err != nil)
+	}
+	return res
+}
+
+func TestFailures(t *testing.T) {
+	s := server(t, "ok")
+	c := Conditions{FailureRate: 0.5, Seed: 1}
+	first := failures(t, c, s.URL, 40)
+	failed := 0
+	for _, f := range first {
+		if f {
+			failed++
+		}
+	}
+	if failed < 8 || failed > 32 {
+		t.Errorf("%d of 40 requests failed, want about half", failed)
+	}
+	if again := failures(t, c, s.URL, 40); !equal(first, again) {
+		t.Error("failures of the same seed differ")
+	}
+	c.Seed = 2
+	if other := failures(t, c, s.URL, 40); equal(first, other) {
+		t.Error("failures of another seed are the same")
+	}
+}
+
+func equal(a, b []bool) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+func TestLatency(t *testing.T) {
+	s := server(t, "ok")
+	client := &http.Client{Transport: &Transport{Conditions: Conditions{Latency: 50 * time.Millisecond}}}
+	start := time.Now()
+	resp, err := client.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Errorf("response after %v, want at least 50ms", d)
+	}
+
+	// Canceling the request ends the wait.
+	client = &http.Client{Transport: &Transport{Conditions: Conditions{Latency: time.Hour}}}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", s.URL, nil)
+	if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want a deadline error", err)
+	}
+}
+
+func TestBandwidth(t *testing.T) {
+	body := strings.Repeat("x", 1000)
+	s := server(t, body)
+	client := &http.Client{Transport: &Transport{Conditions: Conditions{Bandwidth: 10000}}}
+	start := time.Now()
+	resp, err := client.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	got, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != body {
+		t.Errorf("body changed")
+	}
+	// 1000 bytes at 10000 bytes per second.
+	if d := time.Since(start); d < 100*time.Millisecond {
+		t.Errorf("read the body in %v, want at least 100ms", d)
+	}
+}
+
+func TestBandwidthFlag(t *testing.T) {
+	var b bandwidth
+	for s, want := range map[string]bandwidth{"512": 512, "64k": 64 << 10, "2m": 2 << 20} {
+		if err := b.Set(s); err != nil || b != want {
+			t.Errorf("Set(%q) gave %d, %v; want %d", s, b, err, want)
+		}
+	}
+	if err := b.Set("fast"); err == nil {
+		t.Error("no error for an invalid bandwidth")
+	}
+}
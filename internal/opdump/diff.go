@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package opdump
+
+// Change is a line of the difference between two dumps.
+type Change struct {
+	// Op is ' ' for lines in both dumps, '-' for lines of the first
+	// alone, and '+' for lines of the second alone.
+	Op   byte
+	Line string
+}
+
+// maxCells bounds the table of the longest common subsequence. Larger
+// differences are reported as the replacement of every line between the
+// common prefix and suffix.
+const maxCells = 1 << 22
+
+// Diff returns the lines of b compared to a.
+func Diff(a, b []string) []Change {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+	var changes []Change
+	for _, l := range a[:pre] {
+		changes = append(changes, Change{' ', l})
+	}
+	changes = append(changes, lcs(a[pre:len(a)-suf], b[pre:len(b)-suf])...)
+	for _, l := range a[len(a)-suf:] {
+		changes = append(changes, Change{' ', l})
+	}
+	return changes
+}
+
+// lcs returns the changes from a to b along their longest common
+// subsequence.
+func lcs(a, b []string) []Change {
+	var changes []Change
+	n, m := len(a), len(b)
+	if (n+1)*(m+1) > maxCells {
+		for _, l := range a {
+			changes = append(changes, Change{'-', l})
+		}
+		for _, l := range b {
+			changes = append(changes, Change{'+', l})
+		}
+		return changes
+	}
+	// l[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	w := m + 1
+	l := make([]int32, (n+1)*w)
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				l[i*w+j] = l[(i+1)*w+j+1] + 1
+			case l[(i+1)*w+j] >= l[i*w+j+1]:
+				l[i*w+j] = l[(i+1)*w+j]
+			default:
+				l[i*w+j] = l[i*w+j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			changes = append(changes, Change{' ', a[i]})
+			i++
+			j++
+		case l[(i+1)*w+j] >= l[i*w+j+1]:
+			changes = append(changes, Change{'-', a[i]})
+			i++
+		default:
+			changes = append(changes, Change{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		changes = append(changes, Change{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		changes = append(changes, Change{'+', b[j]})
+	}
+	return changes
+}
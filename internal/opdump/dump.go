@@ -0,0 +1,210 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package opdump prints the operations of a frame as text, one line per
+// operation, and compares the operations of consecutive frames. Seeing
+// what changed between two frames helps finding why a program lays out
+// or redraws more than expected.
+//
+// Gio doesn't export the encoding of its operations, so the decoder
+// mirrors that of the Gio version this module requires and must be
+// updated with it.
+package opdump
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"math"
+	"strings"
+
+	"gioui.org/op"
+)
+
+// The operation types, in the order of gioui.org/internal/opconst.
+const (
+	typeMacro byte = iota + 200
+	typeCall
+	typeDefer
+	typeTransform
+	typeInvalidate
+	typeImage
+	typePaint
+	typeColor
+	typeLinearGradient
+	typeArea
+	typePointerInput
+	typePass
+	typeClipboardRead
+	typeClipboardWrite
+	typeKeyInput
+	typeKeyFocus
+	typeKeySoftKeyboard
+	typeSave
+	typeLoad
+	typeAux
+	typeClip
+	typeProfile
+	typeCursor
+	typePath
+	typeStroke
+)
+
+// opSizes are the encoded sizes of the operation types, and opRefs
+// their number of references.
+var (
+	opSizes = [...]int{9, 9, 1, 25, 9, 1, 1, 5, 25, 18, 19, 2, 1, 1, 1, 1, 2, 5, 6, 1, 18, 1, 2, 1, 5}
+	opRefs  = [...]int{0, 1, 0, 0, 0, 2, 0, 0, 0, 0, 1, 0, 1, 1, 1, 1, 0, 0, 0, 0, 0, 1, 1, 0, 0}
+)
+
+// maxDepth bounds the nesting of macro calls, against cycles.
+const maxDepth = 64
+
+var bo = binary.LittleEndian
+
+// Dump returns the operations of ops, one per line. The operations of
+// called macros follow their call, indented.
+func Dump(ops *op.Ops) []string {
+	d := &dumper{}
+	d.dump(ops, 0, 0, len(ops.Data()), 0)
+	return d.lines
+}
+
+type dumper struct {
+	lines []string
+}
+
+func (d *dumper) printf(depth int, format string, args ...interface{}) {
+	d.lines = append(d.lines, strings.Repeat("  ", depth)+fmt.Sprintf(format, args...))
+}
+
+// dump decodes the operations of ops from the data and reference
+// positions pc and ref up to end.
+func (d *dumper) dump(ops *op.Ops, pc, ref, end, depth int) {
+	data, refs := ops.Data(), ops.Refs()
+	for pc < end {
+		t := data[pc]
+		if t < typeMacro || t > typeStroke {
+			d.printf(depth, "unknown op %d", t)
+			return
+		}
+		n, nrefs := opSizes[t-typeMacro], opRefs[t-typeMacro]
+		if pc+n > end || ref+nrefs > len(refs) {
+			d.printf(depth, "truncated op %d", t)
+			return
+		}
+		b := data[pc : pc+n]
+		r := refs[ref : ref+nrefs]
+		switch t {
+		case typeMacro:
+			// Macros are recorded in place but run where called.
+			pc, ref = int(bo.Uint32(b[1:])), int(bo.Uint32(b[5:]))
+			continue
+		case typeCall:
+			d.printf(depth, "call")
+			callee, ok := r[0].(*op.Ops)
+			if !ok || depth >= maxDepth {
+				break
+			}
+			mpc, mref := int(bo.Uint32(b[1:])), int(bo.Uint32(b[5:]))
+			cdata := callee.Data()
+			if mpc+opSizes[0] > len(cdata) || cdata[mpc] != typeMacro {
+				d.printf(depth+1, "invalid macro")
+				break
+			}
+			mend := int(bo.Uint32(cdata[mpc+1:]))
+			d.dump(callee, mpc+opSizes[0], mref, mend, depth+1)
+		case typeAux:
+			// Aux data fills the rest of its macro.
+			d.printf(depth, "aux %d bytes", end-pc-n)
+			return
+		default:
+			d.printf(depth, "%s", describe(t, b, r))
+		}
+		pc += n
+		ref += nrefs
+	}
+}
+
+// describe formats an operation other than a macro, call or aux.
+func describe(t byte, b []byte, refs []interface{}) string {
+	f32 := func(i int) float32 { return math.Float32frombits(bo.Uint32(b[i:])) }
+	rect := func(i int) image.Rectangle {
+		return image.Rect(int(int32(bo.Uint32(b[i:]))), int(int32(bo.Uint32(b[i+4:]))),
+			int(int32(bo.Uint32(b[i+8:]))), int(int32(bo.Uint32(b[i+12:]))))
+	}
+	switch t {
+	case typeDefer:
+		return "defer"
+	case typeTransform:
+		sx, hx, ox, hy, sy, oy := f32(1), f32(5), f32(9), f32(13), f32(17), f32(21)
+		if sx == 1 && hx == 0 && hy == 0 && sy == 1 {
+			return fmt.Sprintf("offset %g,%g", ox, oy)
+		}
+		return fmt.Sprintf("transform [%g %g %g; %g %g %g]", sx, hx, ox, hy, sy, oy)
+	case typeInvalidate:
+		if bo.Uint64(b[1:]) == 0 {
+			return "invalidate"
+		}
+		return "invalidate at"
+	case typeImage:
+		if img, ok := refs[0].(image.Image); ok {
+			return fmt.Sprintf("image %v", img.Bounds().Size())
+		}
+		return "image"
+	case typePaint:
+		return "paint"
+	case typeColor:
+		return fmt.Sprintf("color #%02x%02x%02x%02x", b[1], b[2], b[3], b[4])
+	case typeLinearGradient:
+		return fmt.Sprintf("linear gradient %g,%g-%g,%g", f32(1), f32(5), f32(9), f32(13))
+	case typeArea:
+		kind := "rect"
+		if b[1] == 1 {
+			kind = "ellipse"
+		}
+		return fmt.Sprintf("area %s %v", kind, rect(2))
+	case typePointerInput:
+		s := fmt.Sprintf("pointer input %s", tag(refs[0]))
+		if b[1] != 0 {
+			s += " grab"
+		}
+		return s
+	case typePass:
+		return fmt.Sprintf("pass %t", b[1] != 0)
+	case typeClipboardRead:
+		return fmt.Sprintf("clipboard read %s", tag(refs[0]))
+	case typeClipboardWrite:
+		return "clipboard write"
+	case typeKeyInput:
+		return fmt.Sprintf("key input %s", tag(refs[0]))
+	case typeKeyFocus:
+		return fmt.Sprintf("key focus %s", tag(refs[0]))
+	case typeKeySoftKeyboard:
+		return fmt.Sprintf("soft keyboard %t", b[1] != 0)
+	case typeSave:
+		return fmt.Sprintf("save %d", bo.Uint32(b[1:]))
+	case typeLoad:
+		return fmt.Sprintf("load %d", bo.Uint32(b[2:]))
+	case typeClip:
+		s := fmt.Sprintf("clip %v", rect(1))
+		if b[17] != 0 {
+			s += " outline"
+		}
+		return s
+	case typeProfile:
+		return "profile"
+	case typeCursor:
+		return fmt.Sprintf("cursor %v", refs[0])
+	case typePath:
+		return "path"
+	case typeStroke:
+		return fmt.Sprintf("stroke %g", f32(1))
+	}
+	return fmt.Sprintf("op %d", t)
+}
+
+// tag formats an event tag by its type alone; addresses change between
+// runs and would make every frame look different.
+func tag(t interface{}) string {
+	return fmt.Sprintf("%T", t)
+}
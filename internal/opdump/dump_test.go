@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package opdump
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+)
+
+func TestDump(t *testing.T) {
+	var ops op.Ops
+	m := op.Record(&ops)
+	paint.ColorOp{Color: color.NRGBA{R: 0xff, A: 0xff}}.Add(&ops)
+	paint.PaintOp{}.Add(&ops)
+	call := m.Stop()
+	st := op.Save(&ops)
+	op.Offset(f32.Pt(10, 20)).Add(&ops)
+	clip.Rect{Max: image.Pt(5, 6)}.Add(&ops)
+	call.Add(&ops)
+	st.Load()
+	pointer.InputOp{Tag: &ops}.Add(&ops)
+	got := Dump(&ops)
+	want := []string{
+		"save 1",
+		"offset 10,20",
+		"clip (0,0)-(5,6) outline",
+		"call",
+		"  color #ff0000ff",
+		"  paint",
+		"load 1",
+		"pointer input *op.Ops",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDumpPath(t *testing.T) {
+	var ops op.Ops
+	var p clip.Path
+	p.Begin(&ops)
+	p.LineTo(f32.Pt(10, 0))
+	p.LineTo(f32.Pt(10, 10))
+	p.Close()
+	clip.Outline{Path: p.End()}.Op().Add(&ops)
+	got := Dump(&ops)
+	if len(got) != 4 || got[0] != "path" || got[1] != "call" || got[3] != "clip (0,0)-(10,10) outline" {
+		t.Errorf("unexpected dump %q", got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := []string{"a", "b", "c", "d", "e"}
+	b := []string{"a", "c", "x", "d", "e", "f"}
+	var got []string
+	for _, c := range Diff(a, b) {
+		got = append(got, string(c.Op)+c.Line)
+	}
+	want := []string{" a", "-b", " c", "+x", " d", " e", "+f"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,279 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package opdump
+
+import (
+	"fmt"
+	"image/color"
+	"sync"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+// maxFrames is the number of frames kept by a Recorder.
+const maxFrames = 200
+
+// Frame is the dump of a frame.
+type Frame struct {
+	// N counts the frames recorded.
+	N     int
+	At    time.Time
+	Lines []string
+}
+
+// Recorder keeps the dumps of the last frames of a window and shows them
+// in a debug window. Add it to a program by calling Record with the ops
+// of every frame before passing them to FrameEvent.Frame, and run
+// Window in a new goroutine.
+type Recorder struct {
+	mu     sync.Mutex
+	frames []Frame
+	n      int
+	paused bool
+	// changed is called when a frame is recorded.
+	changed func()
+}
+
+// Record dumps the operations of a frame.
+func (r *Recorder) Record(ops *op.Ops) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.paused {
+		return
+	}
+	r.n++
+	r.frames = append(r.frames, Frame{N: r.n, At: time.Now(), Lines: Dump(ops)})
+	if len(r.frames) > maxFrames {
+		r.frames = append(r.frames[:0], r.frames[len(r.frames)-maxFrames:]...)
+	}
+	if r.changed != nil {
+		r.changed()
+	}
+}
+
+func (r *Recorder) snapshot() []Frame {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Frame(nil), r.frames...)
+}
+
+func (r *Recorder) setPaused(p bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paused = p
+}
+
+var (
+	monoFont  = text.Font{Variant: "Mono"}
+	deletedBg = color.NRGBA{R: 0xff, G: 0xeb, B: 0xee, A: 0xff}
+	addedBg   = color.NRGBA{R: 0xe6, G: 0xf4, B: 0xea, A: 0xff}
+	selectBg  = color.NRGBA{R: 0xe3, G: 0xf2, B: 0xfd, A: 0xff}
+)
+
+// debugView is the state of the debug window.
+type debugView struct {
+	frames []Frame
+	// sel is the frame number selected, or 0 to follow the last frame.
+	sel int
+	// changes is the difference of the selected frame to the one
+	// before it.
+	changes    []Change
+	changesFor int
+
+	pause, onlyChanges widget.Bool
+	follow             widget.Clickable
+	clicks             map[int]*widget.Clickable
+	frameList, opList  layout.List
+}
+
+// Window opens the debug window and returns when it is closed.
+func (r *Recorder) Window() error {
+	w := app.NewWindow(
+		app.Title("Frame Operations"),
+		app.Size(unit.Dp(900), unit.Dp(700)),
+	)
+	r.mu.Lock()
+	r.changed = func() {
+		w.Invalidate()
+	}
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.changed = nil
+		r.mu.Unlock()
+	}()
+	th := material.NewTheme(gofont.Collection())
+	v := &debugView{
+		clicks:    make(map[int]*widget.Clickable),
+		frameList: layout.List{Axis: layout.Vertical, ScrollToEnd: true},
+		opList:    layout.List{Axis: layout.Vertical},
+	}
+	v.onlyChanges.Value = true
+	var ops op.Ops
+	for e := range w.Events() {
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			if v.pause.Changed() {
+				r.setPaused(v.pause.Value)
+			}
+			v.frames = r.snapshot()
+			v.Layout(gtx, th)
+			e.Frame(gtx.Ops)
+		}
+	}
+	return nil
+}
+
+// selected returns the index of the selected frame, or -1.
+func (v *debugView) selected() int {
+	if len(v.frames) == 0 {
+		return -1
+	}
+	if v.sel != 0 {
+		for i, f := range v.frames {
+			if f.N == v.sel {
+				return i
+			}
+		}
+	}
+	return len(v.frames) - 1
+}
+
+func (v *debugView) Layout(gtx C, th *material.Theme) D {
+	for n, c := range v.clicks {
+		for c.Clicked() {
+			v.sel = n
+			v.opList.Position = layout.Position{}
+		}
+	}
+	for v.follow.Clicked() {
+		v.sel = 0
+	}
+	sel := v.selected()
+	if sel >= 0 && v.changesFor != v.frames[sel].N {
+		var prev []string
+		if sel > 0 {
+			prev = v.frames[sel-1].Lines
+		}
+		v.changes = Diff(prev, v.frames[sel].Lines)
+		v.changesFor = v.frames[sel].N
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(material.CheckBox(th, &v.pause, "Pause recording").Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+					layout.Rigid(material.CheckBox(th, &v.onlyChanges, "Only changes").Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+					layout.Rigid(func(gtx C) D {
+						if v.sel == 0 {
+							gtx = gtx.Disabled()
+						}
+						return material.Button(th, &v.follow, "Follow last frame").Layout(gtx)
+					}),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					gtx.Constraints.Min.X = gtx.Px(unit.Dp(240))
+					gtx.Constraints.Max.X = gtx.Constraints.Min.X
+					return v.layoutFrames(gtx, th, sel)
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					return v.layoutOps(gtx, th)
+				}),
+			)
+		}),
+	)
+}
+
+func (v *debugView) layoutFrames(gtx C, th *material.Theme, sel int) D {
+	return v.frameList.Layout(gtx, len(v.frames), func(gtx C, i int) D {
+		f := v.frames[i]
+		c := v.clicks[f.N]
+		if c == nil {
+			c = new(widget.Clickable)
+			v.clicks[f.N] = c
+		}
+		// Forget the clickables of frames gone.
+		if i == 0 {
+			for n := range v.clicks {
+				if n < f.N {
+					delete(v.clicks, n)
+				}
+			}
+		}
+		label := fmt.Sprintf("#%d  %s  %d ops", f.N, f.At.Format("15:04:05.000"), len(f.Lines))
+		if i > 0 {
+			label += fmt.Sprintf("  %+d", len(f.Lines)-len(v.frames[i-1].Lines))
+		}
+		return material.Clickable(gtx, c, func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			m := op.Record(gtx.Ops)
+			dims := layout.UniformInset(unit.Dp(4)).Layout(gtx, material.Caption(th, label).Layout)
+			call := m.Stop()
+			if i == sel {
+				paint.FillShape(gtx.Ops, selectBg, clip.Rect{Max: dims.Size}.Op())
+			}
+			call.Add(gtx.Ops)
+			return dims
+		})
+	})
+}
+
+func (v *debugView) layoutOps(gtx C, th *material.Theme) D {
+	changes := v.changes
+	if v.onlyChanges.Value {
+		changes = nil
+		for _, c := range v.changes {
+			if c.Op != ' ' {
+				changes = append(changes, c)
+			}
+		}
+		if len(changes) == 0 {
+			return layout.Center.Layout(gtx, material.Body2(th, "No changes from the frame before.").Layout)
+		}
+	}
+	return v.opList.Layout(gtx, len(changes), func(gtx C, i int) D {
+		c := changes[i]
+		var bg color.NRGBA
+		switch c.Op {
+		case '-':
+			bg = deletedBg
+		case '+':
+			bg = addedBg
+		}
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		m := op.Record(gtx.Ops)
+		l := material.Caption(th, string(c.Op)+" "+c.Line)
+		l.Font = monoFont
+		l.MaxLines = 1
+		dims := layout.Inset{Left: unit.Dp(4)}.Layout(gtx, l.Layout)
+		call := m.Stop()
+		paint.FillShape(gtx.Ops, bg, clip.Rect{Max: dims.Size}.Op())
+		call.Add(gtx.Ops)
+		return dims
+	})
+}
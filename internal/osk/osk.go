@@ -0,0 +1,494 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package osk implements an on-screen keyboard for touch screens without
+// a physical keyboard, such as kiosks and terminals. The keyboard types
+// into a widget.Editor: keys insert and delete text and move the caret
+// of the editor, which keeps the input focus, so editors behave the same
+// with and without a physical keyboard.
+package osk
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+// Kind is the kind of a key.
+type Kind int
+
+const (
+	// Char keys type their text.
+	Char Kind = iota
+	// Shift shifts the next key. Two taps in a row lock it.
+	Shift
+	// Switch switches between the letters and the symbols.
+	Switch
+	Backspace
+	Enter
+	Left
+	Right
+)
+
+// Key is a key of a layer.
+type Key struct {
+	Kind Kind
+	// Text is the text typed by a Char key, and Shifted the text typed
+	// while shifted. Keys without Shifted text type Text.
+	Text, Shifted string
+	// Label replaces the text shown on Char and Switch keys.
+	Label string
+	// Width is the width of the key relative to a letter. Zero means 1.
+	Width float32
+}
+
+// Layer is the rows of keys shown at once.
+type Layer [][]Key
+
+// QWERTY is the default letters layer.
+var QWERTY = Layer{
+	chars("qwertyuiop"),
+	chars("asdfghjkl"),
+	append(append([]Key{{Kind: Shift, Width: 1.5}}, chars("zxcvbnm")...), Key{Kind: Backspace, Width: 1.5}),
+	bottomRow("?123"),
+}
+
+// Symbols is the default symbols layer.
+var Symbols = Layer{
+	chars("1234567890"),
+	chars("@#$%&*-+()"),
+	append(append([]Key{{Kind: Char, Text: "_", Width: 1.5}}, chars(`!"':;/?`)...), Key{Kind: Backspace, Width: 1.5}),
+	bottomRow("ABC"),
+}
+
+// chars returns a Char key for every rune of s, shifted to upper case.
+func chars(s string) []Key {
+	var keys []Key
+	for _, r := range s {
+		k := Key{Kind: Char, Text: string(r)}
+		if r >= 'a' && r <= 'z' {
+			k.Shifted = string(r - 'a' + 'A')
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func bottomRow(layer string) []Key {
+	return []Key{
+		{Kind: Switch, Label: layer, Width: 1.5},
+		{Kind: Char, Text: ","},
+		{Kind: Left},
+		{Kind: Char, Text: " ", Label: "space", Width: 3},
+		{Kind: Right},
+		{Kind: Char, Text: "."},
+		{Kind: Enter, Width: 1.5},
+	}
+}
+
+func (k *Key) width() float32 {
+	if k.Width == 0 {
+		return 1
+	}
+	return k.Width
+}
+
+// repeats reports whether the key repeats while held.
+func (k *Key) repeats() bool {
+	switch k.Kind {
+	case Char, Backspace, Left, Right:
+		return true
+	}
+	return false
+}
+
+type shiftState int
+
+const (
+	shiftOff shiftState = iota
+	shiftOnce
+	shiftLocked
+)
+
+const (
+	defaultDelay    = 400 * time.Millisecond
+	defaultInterval = 60 * time.Millisecond
+	// doubleTap is the longest time between the taps on Shift that lock it.
+	doubleTap = 400 * time.Millisecond
+	// pressIn and fadeOut are the durations of the press animation of
+	// keys, in and out.
+	pressIn = 50 * time.Millisecond
+	fadeOut = 150 * time.Millisecond
+)
+
+// Keyboard is an on-screen keyboard. Use Track to type into the focused
+// editor of a form.
+type Keyboard struct {
+	// Letters and Symbols are the layers of keys. They default to QWERTY
+	// and Symbols.
+	Letters, Symbols Layer
+	// Delay is how long a key is held before it repeats, and Interval
+	// the time between repeats. They default to 400ms and 60ms.
+	Delay, Interval time.Duration
+
+	target    *widget.Editor
+	symbols   bool
+	shift     shiftState
+	lastShift time.Time
+	keys      map[*Key]*keyState
+	submitted []*widget.Editor
+}
+
+type keyState struct {
+	pressed bool
+	// down and up are when the key was pressed and released, for the
+	// press animation.
+	down, up time.Time
+	// next is when the pressed key repeats next.
+	next time.Time
+}
+
+// Track makes the keyboard type into the focused editor among editors,
+// if any. The keyboard keeps typing into an editor that loses the focus
+// until another editor takes it. Call Track after laying out the
+// editors.
+func (k *Keyboard) Track(editors ...*widget.Editor) {
+	for _, e := range editors {
+		if e.Focused() {
+			k.target = e
+			return
+		}
+	}
+}
+
+// SetTarget sets the editor the keyboard types into. A nil target
+// disables the keys that type.
+func (k *Keyboard) SetTarget(e *widget.Editor) {
+	k.target = e
+}
+
+func (k *Keyboard) Target() *widget.Editor {
+	return k.target
+}
+
+// Submitted returns the next editor submitted with the Enter key, if
+// any. Enter submits editors with Submit set, inserts a line break into
+// multi-line editors and does nothing in other single-line editors.
+func (k *Keyboard) Submitted() (*widget.Editor, bool) {
+	if len(k.submitted) == 0 {
+		return nil, false
+	}
+	e := k.submitted[0]
+	k.submitted = k.submitted[1:]
+	return e, true
+}
+
+func (k *Keyboard) layer() Layer {
+	if k.symbols {
+		if k.Symbols == nil {
+			return Symbols
+		}
+		return k.Symbols
+	}
+	if k.Letters == nil {
+		return QWERTY
+	}
+	return k.Letters
+}
+
+func (k *Keyboard) delay() time.Duration {
+	if k.Delay == 0 {
+		return defaultDelay
+	}
+	return k.Delay
+}
+
+func (k *Keyboard) interval() time.Duration {
+	if k.Interval == 0 {
+		return defaultInterval
+	}
+	return k.Interval
+}
+
+func (k *Keyboard) shifted() bool {
+	return k.shift != shiftOff && !k.symbols
+}
+
+// text returns the text typed by a Char key.
+func (k *Keyboard) text(kk *Key) string {
+	if k.shifted() && kk.Shifted != "" {
+		return kk.Shifted
+	}
+	return kk.Text
+}
+
+// press acts on a key press or repeat.
+func (k *Keyboard) press(kk *Key, now time.Time) {
+	switch kk.Kind {
+	case Shift:
+		switch {
+		case k.shift == shiftOnce && now.Sub(k.lastShift) < doubleTap:
+			k.shift = shiftLocked
+		case k.shift == shiftOff:
+			k.shift = shiftOnce
+		default:
+			k.shift = shiftOff
+		}
+		k.lastShift = now
+		return
+	case Switch:
+		k.symbols = !k.symbols
+		k.shift = shiftOff
+		// The keys of the other layer are no longer laid out and won't
+		// see their releases.
+		for _, st := range k.keys {
+			if st.pressed {
+				st.pressed = false
+				st.up = now
+			}
+		}
+		return
+	}
+	e := k.target
+	if e == nil {
+		return
+	}
+	switch kk.Kind {
+	case Char:
+		e.Insert(k.text(kk))
+		if k.shift == shiftOnce {
+			k.shift = shiftOff
+		}
+	case Backspace:
+		e.Delete(-1)
+	case Left:
+		e.MoveCaret(-1, -1)
+	case Right:
+		e.MoveCaret(1, 1)
+	case Enter:
+		switch {
+		case e.Submit:
+			k.submitted = append(k.submitted, e)
+		case !e.SingleLine:
+			e.Insert("\n")
+		}
+	}
+}
+
+// Layout lays out the keyboard across the width of the constraints.
+func (k *Keyboard) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	if k.keys == nil {
+		k.keys = make(map[*Key]*keyState)
+	}
+	// Focused editors ask for the keyboard of the system, if any; the
+	// on-screen keyboard replaces it.
+	key.SoftKeyboardOp{Show: false}.Add(gtx.Ops)
+
+	layer := k.layer()
+	var cols float32
+	for _, row := range layer {
+		var w float32
+		for i := range row {
+			w += row[i].width()
+		}
+		if w > cols {
+			cols = w
+		}
+	}
+	width := gtx.Constraints.Max.X
+	unitW := float32(width) / cols
+	rowH := gtx.Px(unit.Dp(52))
+	if h := int(unitW * 1.3); h < rowH {
+		rowH = h
+	}
+	pad := gtx.Px(unit.Dp(4))
+	height := len(layer)*rowH + 2*pad
+	paint.FillShape(gtx.Ops, keyboardBg, clip.Rect{Max: image.Pt(width, height)}.Op())
+
+	animating := false
+	for r, row := range layer {
+		var w float32
+		for i := range row {
+			w += row[i].width()
+		}
+		x := (float32(width) - w*unitW) / 2
+		y := pad + r*rowH
+		for i := range row {
+			kk := &row[i]
+			kw := kk.width() * unitW
+			bounds := image.Rect(int(x), y, int(x+kw), y+rowH)
+			if k.layoutKey(gtx, th, kk, bounds) {
+				animating = true
+			}
+			x += kw
+		}
+	}
+	if animating {
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+	return layout.Dimensions{Size: image.Pt(width, height)}
+}
+
+// layoutKey handles the input of a key and draws it in bounds. It
+// reports whether the key is animating.
+func (k *Keyboard) layoutKey(gtx layout.Context, th *material.Theme, kk *Key, bounds image.Rectangle) bool {
+	st := k.keys[kk]
+	if st == nil {
+		st = new(keyState)
+		k.keys[kk] = st
+	}
+	for _, ev := range gtx.Events(st) {
+		e, ok := ev.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case pointer.Press:
+			if st.pressed {
+				break
+			}
+			st.pressed = true
+			st.down = gtx.Now
+			st.next = gtx.Now.Add(k.delay())
+			k.press(kk, gtx.Now)
+		case pointer.Release, pointer.Cancel:
+			if st.pressed {
+				st.pressed = false
+				st.up = gtx.Now
+			}
+		}
+	}
+	if st.pressed && kk.repeats() {
+		if !gtx.Now.Before(st.next) {
+			k.press(kk, gtx.Now)
+			st.next = gtx.Now.Add(k.interval())
+		}
+		op.InvalidateOp{At: st.next}.Add(gtx.Ops)
+	}
+
+	// a is the progress of the press animation, from 0 at rest to 1
+	// fully pressed.
+	var a float32
+	animating := false
+	switch {
+	case st.pressed:
+		a = float32(gtx.Now.Sub(st.down)) / float32(pressIn)
+		if a < 1 {
+			animating = true
+		} else {
+			a = 1
+		}
+	case gtx.Now.Sub(st.up) < fadeOut:
+		a = 1 - float32(gtx.Now.Sub(st.up))/float32(fadeOut)
+		animating = true
+	}
+
+	defer op.Save(gtx.Ops).Load()
+	op.Offset(layout.FPt(bounds.Min)).Add(gtx.Ops)
+	size := bounds.Size()
+	area := op.Save(gtx.Ops)
+	pointer.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	pointer.InputOp{Tag: st, Types: pointer.Press | pointer.Release}.Add(gtx.Ops)
+	area.Load()
+
+	// Pressed keys shrink a little towards their center.
+	inset := gtx.Px(unit.Dp(3))
+	face := image.Rectangle{Min: image.Pt(inset, inset), Max: size.Sub(image.Pt(inset, inset))}
+	if a > 0 {
+		s := 1 - 0.06*a
+		c := layout.FPt(size).Mul(.5)
+		op.Affine(f32.Affine2D{}.Scale(c, f32.Pt(s, s))).Add(gtx.Ops)
+	}
+	bg, fg := keyFace, th.Fg
+	if kk.Kind != Char {
+		bg = specialFace
+	}
+	if kk.Kind == Shift && k.shifted() {
+		bg, fg = th.ContrastBg, th.ContrastFg
+	}
+	bg = mix(bg, pressedFace, a)
+	rr := float32(gtx.Px(unit.Dp(6)))
+	paint.FillShape(gtx.Ops, bg, clip.UniformRRect(layout.FRect(face), rr).Op(gtx.Ops))
+
+	gtx.Constraints = layout.Exact(face.Size())
+	op.Offset(layout.FPt(face.Min)).Add(gtx.Ops)
+	layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		if ic := k.icon(kk); ic != nil {
+			ic.Color = fg
+			return ic.Layout(gtx, unit.Dp(24))
+		}
+		txt, sz := kk.Label, unit.Sp(14)
+		if txt == "" {
+			txt, sz = k.text(kk), unit.Sp(20)
+		}
+		l := material.Label(th, sz, txt)
+		l.Color = fg
+		l.Alignment = text.Middle
+		return l.Layout(gtx)
+	})
+	return animating
+}
+
+// icon returns the icon of a special key.
+func (k *Keyboard) icon(kk *Key) *widget.Icon {
+	switch kk.Kind {
+	case Shift:
+		if k.shift == shiftLocked {
+			return capsLockIcon
+		}
+		return shiftIcon
+	case Backspace:
+		return backspaceIcon
+	case Enter:
+		return enterIcon
+	case Left:
+		return leftIcon
+	case Right:
+		return rightIcon
+	}
+	return nil
+}
+
+var (
+	keyboardBg  = color.NRGBA{R: 0xd5, G: 0xd8, B: 0xde, A: 0xff}
+	keyFace     = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	specialFace = color.NRGBA{R: 0xad, G: 0xb3, B: 0xbc, A: 0xff}
+	pressedFace = color.NRGBA{R: 0x8a, G: 0x91, B: 0x9c, A: 0xff}
+)
+
+var (
+	shiftIcon     = mustIcon(icons.HardwareKeyboardArrowUp)
+	capsLockIcon  = mustIcon(icons.HardwareKeyboardCapslock)
+	backspaceIcon = mustIcon(icons.HardwareKeyboardBackspace)
+	enterIcon     = mustIcon(icons.HardwareKeyboardReturn)
+	leftIcon      = mustIcon(icons.HardwareKeyboardArrowLeft)
+	rightIcon     = mustIcon(icons.HardwareKeyboardArrowRight)
+)
+
+func mustIcon(data []byte) *widget.Icon {
+	ic, err := widget.NewIcon(data)
+	if err != nil {
+		panic(err)
+	}
+	return ic
+}
+
+// mix interpolates between the colors a and b.
+func mix(a, b color.NRGBA, t float32) color.NRGBA {
+	m := func(x, y uint8) uint8 {
+		return uint8(float32(x) + (float32(y)-float32(x))*t)
+	}
+	return color.NRGBA{R: m(a.R, b.R), G: m(a.G, b.G), B: m(a.B, b.B), A: m(a.A, b.A)}
+}
@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package osk
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// find returns the key of a layer typing text, or of a kind.
+func find(l Layer, kind Kind, text string) *Key {
+	for _, row := range l {
+		for i := range row {
+			if k := &row[i]; k.Kind == kind && k.Text == text {
+				return k
+			}
+		}
+	}
+	return nil
+}
+
+func TestTyping(t *testing.T) {
+	var k Keyboard
+	ed := &widget.Editor{SingleLine: true, Submit: true}
+	k.SetTarget(ed)
+	now := time.Now()
+	tap := func(kind Kind, text string) {
+		t.Helper()
+		kk := find(k.layer(), kind, text)
+		if kk == nil {
+			t.Fatalf("no key %v %q", kind, text)
+		}
+		k.press(kk, now)
+		now = now.Add(time.Second)
+	}
+	tap(Shift, "")
+	tap(Char, "h")
+	tap(Char, "i")
+	tap(Switch, "")
+	tap(Char, "1")
+	tap(Char, "?")
+	tap(Switch, "")
+	tap(Backspace, "")
+	tap(Left, "")
+	tap(Char, ",")
+	if got, want := ed.Text(), "Hi,1"; got != want {
+		t.Errorf("typed %q, want %q", got, want)
+	}
+
+	// Two quick taps lock the shift.
+	k.press(find(QWERTY, Shift, ""), now)
+	k.press(find(QWERTY, Shift, ""), now.Add(doubleTap/2))
+	ed.SetText("")
+	tap(Char, "o")
+	tap(Char, "k")
+	tap(Shift, "")
+	tap(Char, "a")
+	if got, want := ed.Text(), "OKa"; got != want {
+		t.Errorf("typed %q with caps lock, want %q", got, want)
+	}
+
+	tap(Enter, "")
+	if e, ok := k.Submitted(); !ok || e != ed {
+		t.Error("enter didn't submit the editor")
+	}
+	ed.Submit = false
+	ed.SingleLine = false
+	tap(Enter, "")
+	if _, ok := k.Submitted(); ok || ed.Text() != "OKa\n" {
+		t.Errorf("enter typed %q into a multi-line editor", ed.Text())
+	}
+}
+
+func TestRepeat(t *testing.T) {
+	th := material.NewTheme(gofont.Collection())
+	k := &Keyboard{Delay: 400 * time.Millisecond, Interval: 50 * time.Millisecond}
+	ed := new(widget.Editor)
+	k.SetTarget(ed)
+	var r router.Router
+	ops := new(op.Ops)
+	now := time.Now()
+	frame := func(d time.Duration) {
+		now = now.Add(d)
+		ops.Reset()
+		gtx := layout.Context{
+			Ops:         ops,
+			Now:         now,
+			Queue:       &r,
+			Constraints: layout.Exact(image.Pt(500, 300)),
+		}
+		k.Layout(gtx, th)
+		r.Frame(ops)
+	}
+	frame(0)
+	// The second row starts half a key in, and the keys are 50 pixels
+	// wide and at most 52 tall: the first key is "a".
+	pos := f32.Pt(40, 4+52+20)
+	r.Queue(pointer.Event{Type: pointer.Press, Source: pointer.Touch, Position: pos})
+	frame(0)
+	frame(300 * time.Millisecond)
+	if got := ed.Text(); got != "a" {
+		t.Fatalf("typed %q before the delay, want a", got)
+	}
+	frame(100 * time.Millisecond)
+	frame(50 * time.Millisecond)
+	frame(50 * time.Millisecond)
+	r.Queue(pointer.Event{Type: pointer.Release, Source: pointer.Touch, Position: pos})
+	frame(0)
+	frame(time.Second)
+	if got := ed.Text(); got != "aaaa" {
+		t.Errorf("typed %q holding the key, want aaaa", got)
+	}
+}
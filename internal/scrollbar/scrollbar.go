@@ -0,0 +1,224 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package scrollbar implements a desktop style vertical scrollbar with a
+// draggable thumb, page jumps when clicking the track and an optional
+// minimap drawn behind the thumb.
+package scrollbar
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+)
+
+// Scrollbar holds the state of a scrollbar.
+type Scrollbar struct {
+	hovered  bool
+	dragging bool
+	// grab is the pointer position within the thumb when a drag started,
+	// in pixels.
+	grab float32
+	// delta accumulates the scroll requested by the user, as a fraction
+	// of the content length.
+	delta float32
+	// expand animates the hover width between 0 and 1.
+	expand    float32
+	lastFrame time.Time
+}
+
+// Scrolled returns the distance the user requested to scroll since the
+// last call, as a fraction of the total content length.
+func (s *Scrollbar) Scrolled() float32 {
+	d := s.delta
+	s.delta = 0
+	return d
+}
+
+// Dragging reports whether the thumb is being dragged.
+func (s *Scrollbar) Dragging() bool {
+	return s.dragging
+}
+
+// Style describes the look of a Scrollbar.
+type Style struct {
+	Scrollbar *Scrollbar
+	// Width is the resting width of the bar.
+	Width unit.Value
+	// HoverWidth is the width of the bar while hovered or dragged.
+	HoverWidth unit.Value
+	// Thumb and Track are the colors of the thumb and the track.
+	Thumb, Track color.NRGBA
+	// Minimap, if set, is drawn scaled to fill the track and the thumb is
+	// drawn as a translucent overlay on top of it.
+	Minimap layout.Widget
+}
+
+// New returns a Style with default dimensions and colors.
+func New(s *Scrollbar) Style {
+	return Style{
+		Scrollbar:  s,
+		Width:      unit.Dp(6),
+		HoverWidth: unit.Dp(12),
+		Thumb:      color.NRGBA{A: 0x80},
+		Track:      color.NRGBA{A: 0x10},
+	}
+}
+
+// Layout lays out the scrollbar along the right side of the maximum
+// constraints. Start and end are the visible part of the content as
+// fractions of its total length.
+func (st Style) Layout(gtx layout.Context, start, end float32) layout.Dimensions {
+	s := st.Scrollbar
+	length := float32(gtx.Constraints.Max.Y)
+	start, end = clampRange(start, end)
+	minThumb := float32(gtx.Px(unit.Dp(16)))
+	s.update(gtx, length, minThumb, start, end)
+
+	var dt float32
+	if !s.lastFrame.IsZero() {
+		dt = float32(gtx.Now.Sub(s.lastFrame).Seconds())
+	}
+	s.lastFrame = gtx.Now
+	target := float32(0)
+	if s.hovered || s.dragging {
+		target = 1
+	}
+	if s.expand != target {
+		const speed = 8
+		if s.expand < target {
+			s.expand += dt * speed
+			if s.expand > target {
+				s.expand = target
+			}
+		} else {
+			s.expand -= dt * speed
+			if s.expand < target {
+				s.expand = target
+			}
+		}
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+
+	w0, w1 := float32(gtx.Px(st.Width)), float32(gtx.Px(st.HoverWidth))
+	width := int(w0 + (w1-w0)*s.expand + .5)
+	if st.Minimap != nil {
+		width = int(w1)
+	}
+	size := image.Pt(width, gtx.Constraints.Max.Y)
+
+	defer op.Save(gtx.Ops).Load()
+	bounds := image.Rectangle{Max: size}
+	clip.Rect(bounds).Add(gtx.Ops)
+	paint.ColorOp{Color: st.Track}.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+
+	if st.Minimap != nil {
+		mgtx := gtx
+		mgtx.Constraints = layout.Exact(size)
+		st.Minimap(mgtx)
+	}
+
+	top, thumbLen := thumbSpan(length, minThumb, start, end)
+	thumb := f32.Rectangle{
+		Min: f32.Pt(0, top),
+		Max: f32.Pt(float32(width), top+thumbLen),
+	}
+	col := st.Thumb
+	if st.Minimap != nil {
+		col.A /= 2
+	}
+	r := float32(width) * .5
+	if st.Minimap != nil {
+		r = 0
+	}
+	paint.FillShape(gtx.Ops, col, clip.UniformRRect(thumb, r).Op(gtx.Ops))
+
+	pointer.Rect(bounds).Add(gtx.Ops)
+	pointer.InputOp{
+		Tag:   s,
+		Grab:  s.dragging,
+		Types: pointer.Press | pointer.Drag | pointer.Release | pointer.Enter | pointer.Leave,
+	}.Add(gtx.Ops)
+	pointer.CursorNameOp{Name: pointer.CursorDefault}.Add(gtx.Ops)
+	return layout.Dimensions{Size: size}
+}
+
+// thumbSpan returns the top and length of the thumb in a track of length
+// pixels. The thumb is at least minLen long, to be large enough to grab,
+// and travels the track less its length as start goes from 0 to its
+// largest value.
+func thumbSpan(length, minLen, start, end float32) (top, size float32) {
+	size = (end - start) * length
+	if size < minLen {
+		size = minLen
+	}
+	if size > length {
+		size = length
+	}
+	if rest := 1 - (end - start); rest > 0 {
+		top = start / rest * (length - size)
+	}
+	return top, size
+}
+
+// update processes pointer events.
+func (s *Scrollbar) update(gtx layout.Context, length, minThumb, start, end float32) {
+	if length <= 0 {
+		return
+	}
+	top, size := thumbSpan(length, minThumb, start, end)
+	for _, e := range gtx.Events(s) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		y := e.Position.Y
+		switch e.Type {
+		case pointer.Enter:
+			s.hovered = true
+		case pointer.Leave:
+			s.hovered = false
+		case pointer.Press:
+			switch {
+			case y < top:
+				// Jump one page toward the pointer.
+				s.delta -= end - start
+			case y > top+size:
+				s.delta += end - start
+			default:
+				s.dragging = true
+				s.grab = y - top
+			}
+		case pointer.Drag:
+			// The thumb travels the track less its own length, which
+			// stands for the content less the visible part.
+			if travel := length - size; s.dragging && travel > 0 {
+				s.delta = (y-s.grab)/travel*(1-(end-start)) - start
+			}
+		case pointer.Release, pointer.Cancel:
+			s.dragging = false
+		}
+	}
+}
+
+// clampRange limits the visible range to [0, 1].
+func clampRange(start, end float32) (float32, float32) {
+	if start < 0 {
+		start = 0
+	}
+	if end > 1 {
+		end = 1
+	}
+	if end < start {
+		end = start
+	}
+	return start, end
+}
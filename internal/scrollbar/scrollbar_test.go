@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package scrollbar
+
+import (
+	"image"
+	"math"
+	"testing"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+)
+
+func TestDragSmallThumb(t *testing.T) {
+	var (
+		s   Scrollbar
+		r   router.Router
+		ops op.Ops
+	)
+	// A visible fraction of 3e-5 draws the thumb at its 16 pixel
+	// minimum, at pixels 492 to 508 of the track.
+	const start, end = 0.5, 0.50003
+	frame := func() {
+		ops.Reset()
+		gtx := layout.Context{
+			Ops:         &ops,
+			Queue:       &r,
+			Now:         time.Now(),
+			Constraints: layout.Exact(image.Pt(20, 1000)),
+		}
+		New(&s).Layout(gtx, start, end)
+		r.Frame(&ops)
+	}
+	frame()
+	r.Queue(pointer.Event{
+		Type:     pointer.Press,
+		Source:   pointer.Mouse,
+		Buttons:  pointer.ButtonPrimary,
+		Position: f32.Pt(3, 504),
+	})
+	frame()
+	if !s.Dragging() {
+		t.Fatal("pressing the thumb didn't start a drag")
+	}
+	if d := s.Scrolled(); d != 0 {
+		t.Fatalf("pressing the thumb scrolled %v", d)
+	}
+	// Dragging the thumb to the top of the track scrolls to the start.
+	r.Queue(pointer.Event{
+		Type:     pointer.Move,
+		Source:   pointer.Mouse,
+		Buttons:  pointer.ButtonPrimary,
+		Position: f32.Pt(3, 12),
+	})
+	frame()
+	if d := s.Scrolled(); math.Abs(float64(d+start)) > 1e-4 {
+		t.Errorf("dragging to the top scrolled %v, want %v", d, -start)
+	}
+}
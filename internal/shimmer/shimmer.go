@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package shimmer implements animated skeleton placeholders that can be
+// shown in place of content that is still loading.
+package shimmer
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+)
+
+// Style describes the look of a shimmering placeholder. The zero value
+// is not useful; use New for reasonable defaults.
+type Style struct {
+	// Base is the resting color of the placeholder shapes.
+	Base color.NRGBA
+	// Highlight is the color of the band sweeping across the shapes.
+	Highlight color.NRGBA
+	// Period is the time it takes the band to sweep across once.
+	Period time.Duration
+	// CornerRadius rounds the corners of rectangles.
+	CornerRadius unit.Value
+}
+
+// New returns a Style with a light grey base and a white highlight.
+func New() Style {
+	return Style{
+		Base:         color.NRGBA{R: 0xe6, G: 0xe6, B: 0xe6, A: 0xff},
+		Highlight:    color.NRGBA{R: 0xf6, G: 0xf6, B: 0xf6, A: 0xff},
+		Period:       1200 * time.Millisecond,
+		CornerRadius: unit.Dp(4),
+	}
+}
+
+// Rect lays out a rounded rectangle placeholder filling the minimum
+// constraints.
+func (s Style) Rect(gtx layout.Context) layout.Dimensions {
+	size := gtx.Constraints.Min
+	r := float32(gtx.Px(s.CornerRadius))
+	s.paint(gtx, size, r)
+	return layout.Dimensions{Size: size}
+}
+
+// Circle lays out a circular placeholder, such as for an avatar, with
+// the given diameter.
+func (s Style) Circle(gtx layout.Context, diameter unit.Value) layout.Dimensions {
+	d := gtx.Px(diameter)
+	size := gtx.Constraints.Constrain(image.Pt(d, d))
+	s.paint(gtx, size, float32(size.X)*.5)
+	return layout.Dimensions{Size: size}
+}
+
+// Line lays out a single placeholder text line of the given height. The
+// line fills fraction of the available width, which makes a stack of
+// lines look more like a paragraph.
+func (s Style) Line(gtx layout.Context, height unit.Value, fraction float32) layout.Dimensions {
+	size := image.Point{
+		X: int(float32(gtx.Constraints.Max.X) * fraction),
+		Y: gtx.Px(height),
+	}
+	size = gtx.Constraints.Constrain(size)
+	s.paint(gtx, size, float32(size.Y)*.5)
+	return layout.Dimensions{Size: size}
+}
+
+// Lines lays out n placeholder text lines separated by a gap of half the
+// line height. The last line is shortened.
+func (s Style) Lines(gtx layout.Context, n int, height unit.Value) layout.Dimensions {
+	children := make([]layout.FlexChild, 0, n)
+	for i := 0; i < n; i++ {
+		fraction := float32(1)
+		if i == n-1 && n > 1 {
+			fraction = .6
+		}
+		top := unit.Value{}
+		if i > 0 {
+			top = height.Scale(.5)
+		}
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: top}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return s.Line(gtx, height, fraction)
+			})
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+// paint fills a rounded rectangle of size with the base color and sweeps
+// the highlight band across it.
+func (s Style) paint(gtx layout.Context, size image.Point, radius float32) {
+	if size.X <= 0 || size.Y <= 0 {
+		return
+	}
+	defer op.Save(gtx.Ops).Load()
+	bounds := f32.Rectangle{Max: layout.FPt(size)}
+	clip.UniformRRect(bounds, radius).Add(gtx.Ops)
+	paint.ColorOp{Color: s.Base}.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+
+	period := s.Period
+	if period <= 0 {
+		period = time.Second
+	}
+	// Derive the band position from the absolute time so every placeholder
+	// on screen shimmers in unison.
+	t := float32(gtx.Now.UnixNano()%int64(period)) / float32(period)
+	w := float32(size.X)
+	band := w * .4
+	if min := float32(gtx.Px(unit.Dp(48))); band < min {
+		band = min
+	}
+	center := -band + t*(w+2*band)
+
+	s.gradient(gtx, size.Y, center-band/2, center, s.Base, s.Highlight)
+	s.gradient(gtx, size.Y, center, center+band/2, s.Highlight, s.Base)
+
+	op.InvalidateOp{}.Add(gtx.Ops)
+}
+
+// gradient paints a horizontal gradient between x0 and x1.
+func (s Style) gradient(gtx layout.Context, height int, x0, x1 float32, c0, c1 color.NRGBA) {
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect(image.Rect(int(x0), 0, int(x1+.5), height)).Add(gtx.Ops)
+	paint.LinearGradientOp{
+		Stop1:  f32.Pt(x0, 0),
+		Stop2:  f32.Pt(x1, 0),
+		Color1: c0,
+		Color2: c1,
+	}.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+}
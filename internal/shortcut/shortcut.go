@@ -0,0 +1,234 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package shortcut maps keyboard shortcuts to named actions. Bindings are
+// declared with platform neutral names, displayed with the conventions of
+// the platform and can be changed at runtime, with conflicting bindings
+// reported.
+package shortcut
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"gioui.org/io/key"
+)
+
+// Binding is a key combination.
+type Binding struct {
+	Modifiers key.Modifiers
+	Name      string
+}
+
+// Action is a named operation with a default binding.
+type Action struct {
+	ID          string
+	Group       string
+	Description string
+	// Default is the default binding in the format accepted by Parse, or
+	// empty for no binding.
+	Default string
+}
+
+// Map holds the current bindings of a set of actions.
+type Map struct {
+	actions  []Action
+	defaults map[string]Binding
+	bindings map[string]Binding
+}
+
+// mac reports whether the platform names modifiers the Apple way.
+var mac = runtime.GOOS == "darwin" || runtime.GOOS == "ios"
+
+// aliases maps the key names accepted by Parse to the names of package
+// key.
+var aliases = map[string]string{
+	"Up":        key.NameUpArrow,
+	"Down":      key.NameDownArrow,
+	"Left":      key.NameLeftArrow,
+	"Right":     key.NameRightArrow,
+	"Home":      key.NameHome,
+	"End":       key.NameEnd,
+	"PageUp":    key.NamePageUp,
+	"PageDown":  key.NamePageDown,
+	"Enter":     key.NameReturn,
+	"Return":    key.NameReturn,
+	"Esc":       key.NameEscape,
+	"Escape":    key.NameEscape,
+	"Tab":       key.NameTab,
+	"Backspace": key.NameDeleteBackward,
+	"Delete":    key.NameDeleteForward,
+}
+
+// Parse parses a binding such as "Shortcut+Shift+P": modifiers followed by
+// a key name, separated by plus signs. The Shortcut modifier is Cmd on
+// Apple platforms and Ctrl elsewhere.
+func Parse(s string) (Binding, error) {
+	var b Binding
+	parts := strings.Split(s, "+")
+	// A trailing "++" binds the plus key.
+	if strings.HasSuffix(s, "++") {
+		parts = append(parts[:len(parts)-2], "+")
+	}
+	for _, p := range parts[:len(parts)-1] {
+		switch p {
+		case "Shortcut":
+			b.Modifiers |= key.ModShortcut
+		case "Ctrl":
+			b.Modifiers |= key.ModCtrl
+		case "Cmd":
+			b.Modifiers |= key.ModCommand
+		case "Alt", "Option":
+			b.Modifiers |= key.ModAlt
+		case "Shift":
+			b.Modifiers |= key.ModShift
+		case "Super":
+			b.Modifiers |= key.ModSuper
+		default:
+			return Binding{}, fmt.Errorf("shortcut: unknown modifier %q in %q", p, s)
+		}
+	}
+	name := parts[len(parts)-1]
+	if name == "" {
+		return Binding{}, fmt.Errorf("shortcut: missing key in %q", s)
+	}
+	if n, ok := aliases[name]; ok {
+		name = n
+	}
+	// Letter keys are named in upper case.
+	b.Name = strings.ToUpper(name)
+	return b, nil
+}
+
+// Match reports whether e is a press of b.
+func (b Binding) Match(e key.Event) bool {
+	return b.Name != "" && e.State == key.Press && e.Name == b.Name && e.Modifiers == b.Modifiers
+}
+
+// String formats b for the current platform.
+func (b Binding) String() string {
+	return b.Format(mac)
+}
+
+// Format formats b with modifier symbols in the Apple order if mac is
+// set, or with modifier names joined by plus signs otherwise.
+func (b Binding) Format(mac bool) string {
+	if b.Name == "" {
+		return ""
+	}
+	type modifier struct {
+		mod       key.Modifiers
+		name, sym string
+	}
+	mods := []modifier{
+		{key.ModCtrl, "Ctrl", "⌃"},
+		{key.ModAlt, "Alt", "⌥"},
+		{key.ModShift, "Shift", "⇧"},
+		{key.ModCommand, "Cmd", "⌘"},
+		{key.ModSuper, "Super", "Super+"},
+	}
+	var s strings.Builder
+	for _, m := range mods {
+		if !b.Modifiers.Contain(m.mod) {
+			continue
+		}
+		if mac {
+			s.WriteString(m.sym)
+		} else {
+			s.WriteString(m.name + "+")
+		}
+	}
+	s.WriteString(b.Name)
+	return s.String()
+}
+
+// NewMap returns a map of the actions bound to their defaults.
+func NewMap(actions []Action) (*Map, error) {
+	m := &Map{
+		actions:  actions,
+		defaults: make(map[string]Binding),
+		bindings: make(map[string]Binding),
+	}
+	for _, a := range actions {
+		if _, dup := m.defaults[a.ID]; dup {
+			return nil, fmt.Errorf("shortcut: duplicate action %q", a.ID)
+		}
+		var b Binding
+		if a.Default != "" {
+			var err error
+			b, err = Parse(a.Default)
+			if err != nil {
+				return nil, err
+			}
+		}
+		m.defaults[a.ID] = b
+	}
+	m.Reset()
+	return m, nil
+}
+
+// Actions returns the actions in the order they were declared.
+func (m *Map) Actions() []Action {
+	return m.actions
+}
+
+// Groups returns the action groups in the order of their first action.
+func (m *Map) Groups() []string {
+	var groups []string
+	seen := make(map[string]bool)
+	for _, a := range m.actions {
+		if !seen[a.Group] {
+			seen[a.Group] = true
+			groups = append(groups, a.Group)
+		}
+	}
+	return groups
+}
+
+// Binding returns the binding of an action, which is zero if the action
+// is unbound.
+func (m *Map) Binding(id string) Binding {
+	return m.bindings[id]
+}
+
+// Bind changes the binding of an action. A zero binding unbinds it.
+func (m *Map) Bind(id string, b Binding) {
+	if _, ok := m.defaults[id]; !ok {
+		panic(fmt.Errorf("shortcut: unknown action %q", id))
+	}
+	m.bindings[id] = b
+}
+
+// Reset restores the default bindings.
+func (m *Map) Reset() {
+	for id, b := range m.defaults {
+		m.bindings[id] = b
+	}
+}
+
+// Lookup returns the action bound to the key press e. If several actions
+// share the binding, the first declared wins.
+func (m *Map) Lookup(e key.Event) (id string, ok bool) {
+	for _, a := range m.actions {
+		if m.bindings[a.ID].Match(e) {
+			return a.ID, true
+		}
+	}
+	return "", false
+}
+
+// Conflicts returns the other actions bound to the binding of the action
+// id.
+func (m *Map) Conflicts(id string) []string {
+	b := m.bindings[id]
+	if b.Name == "" {
+		return nil
+	}
+	var ids []string
+	for _, a := range m.actions {
+		if a.ID != id && m.bindings[a.ID] == b {
+			ids = append(ids, a.ID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package shortcut
+
+import (
+	"reflect"
+	"testing"
+
+	"gioui.org/io/key"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in        string
+		b         Binding
+		pc, apple string
+	}{
+		{"Ctrl+S", Binding{key.ModCtrl, "S"}, "Ctrl+S", "⌃S"},
+		{"Cmd+Shift+p", Binding{key.ModCommand | key.ModShift, "P"}, "Shift+Cmd+P", "⇧⌘P"},
+		{"Alt+Up", Binding{key.ModAlt, key.NameUpArrow}, "Alt+" + key.NameUpArrow, "⌥" + key.NameUpArrow},
+		{"Ctrl++", Binding{key.ModCtrl, "+"}, "Ctrl++", "⌃+"},
+		{"Esc", Binding{0, key.NameEscape}, key.NameEscape, key.NameEscape},
+	}
+	for _, test := range tests {
+		b, err := Parse(test.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.in, err)
+			continue
+		}
+		if b != test.b {
+			t.Errorf("Parse(%q) = %+v, want %+v", test.in, b, test.b)
+		}
+		if got := b.Format(false); got != test.pc {
+			t.Errorf("%q formatted as %q, want %q", test.in, got, test.pc)
+		}
+		if got := b.Format(true); got != test.apple {
+			t.Errorf("%q formatted for Apple as %q, want %q", test.in, got, test.apple)
+		}
+	}
+	for _, bad := range []string{"", "Ctrl+", "Hyper+K"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestMap(t *testing.T) {
+	m, err := NewMap([]Action{
+		{ID: "save", Group: "File", Default: "Ctrl+S"},
+		{ID: "open", Group: "File", Default: "Ctrl+O"},
+		{ID: "find", Group: "Edit", Default: "Ctrl+F"},
+		{ID: "replace", Group: "Edit"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m.Groups(), []string{"File", "Edit"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Groups() = %v, want %v", got, want)
+	}
+	press := key.Event{Name: "O", Modifiers: key.ModCtrl, State: key.Press}
+	if id, ok := m.Lookup(press); !ok || id != "open" {
+		t.Errorf("Lookup(Ctrl+O) = %q, %v, want open", id, ok)
+	}
+	if _, ok := m.Lookup(key.Event{Name: "O", Modifiers: key.ModCtrl | key.ModShift, State: key.Press}); ok {
+		t.Error("Ctrl+Shift+O matched Ctrl+O")
+	}
+	if c := m.Conflicts("open"); c != nil {
+		t.Errorf("unexpected conflicts %v", c)
+	}
+	m.Bind("find", m.Binding("open"))
+	if got, want := m.Conflicts("open"), []string{"find"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Conflicts(open) = %v, want %v", got, want)
+	}
+	if c := m.Conflicts("replace"); c != nil {
+		t.Errorf("unbound action conflicts with %v", c)
+	}
+	m.Reset()
+	if id, _ := m.Lookup(key.Event{Name: "F", Modifiers: key.ModCtrl, State: key.Press}); id != "find" {
+		t.Errorf("Reset didn't restore the default binding of find")
+	}
+	if _, err := NewMap([]Action{{ID: "a"}, {ID: "a"}}); err == nil {
+		t.Error("duplicate actions accepted")
+	}
+}
@@ -0,0 +1,196 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package spell implements a simple spell checker over a word list. It
+// finds the words of a text, checks them against the list and suggests
+// corrections within two edits of a misspelled word.
+package spell
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Checker checks words against a dictionary.
+type Checker struct {
+	words map[string]bool
+	// letters are the runes used to generate suggestions.
+	letters []rune
+}
+
+// Word is a word of a text.
+type Word struct {
+	// Start and End are the byte offsets of the word in the text.
+	Start, End int
+	Text       string
+}
+
+// New returns a checker for a dictionary of words.
+func New(words []string) *Checker {
+	c := &Checker{words: make(map[string]bool)}
+	for _, w := range words {
+		c.Add(w)
+	}
+	return c
+}
+
+// Load returns a checker for a dictionary of words read one per line
+// from r, such as /usr/share/dict/words. Hunspell .dic files are read as
+// well, ignoring their affix flags and leading word count.
+func Load(r io.Reader) (*Checker, error) {
+	c := New(nil)
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		w := s.Text()
+		if i := strings.IndexByte(w, '/'); i >= 0 {
+			w = w[:i]
+		}
+		w = strings.TrimSpace(w)
+		if w == "" || unicode.IsDigit([]rune(w)[0]) {
+			continue
+		}
+		c.Add(w)
+	}
+	return c, s.Err()
+}
+
+// Add adds a word to the dictionary.
+func (c *Checker) Add(word string) {
+	word = strings.ToLower(word)
+	if c.words[word] {
+		return
+	}
+	c.words[word] = true
+	for _, r := range word {
+		i := sort.Search(len(c.letters), func(i int) bool { return c.letters[i] >= r })
+		if i == len(c.letters) || c.letters[i] != r {
+			c.letters = append(c.letters, 0)
+			copy(c.letters[i+1:], c.letters[i:])
+			c.letters[i] = r
+		}
+	}
+}
+
+// Correct reports whether word is in the dictionary. Case is ignored, and
+// so are words containing digits.
+func (c *Checker) Correct(word string) bool {
+	for _, r := range word {
+		if unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return c.words[strings.ToLower(word)]
+}
+
+// Misspelled returns the words of text not in the dictionary.
+func (c *Checker) Misspelled(text string) []Word {
+	var bad []Word
+	for _, w := range Words(text) {
+		if !c.Correct(w.Text) {
+			bad = append(bad, w)
+		}
+	}
+	return bad
+}
+
+// Suggest returns at most n corrections of word, the ones a single edit
+// away first, each group sorted alphabetically. An edit is the deletion,
+// insertion or replacement of a letter, or the swap of two adjacent
+// letters. Suggestions are capitalized like word.
+func (c *Checker) Suggest(word string, n int) []string {
+	lower := strings.ToLower(word)
+	seen := map[string]bool{lower: true}
+	var found []string
+	collect := func(cands []string) []string {
+		var res []string
+		for _, w := range cands {
+			if c.words[w] && !seen[w] {
+				seen[w] = true
+				res = append(res, w)
+			}
+		}
+		sort.Strings(res)
+		return res
+	}
+	e1 := c.edits(lower)
+	found = append(found, collect(e1)...)
+	if len(found) < n {
+		var e2 []string
+		for _, w := range e1 {
+			e2 = append(e2, c.edits(w)...)
+		}
+		found = append(found, collect(e2)...)
+	}
+	if len(found) > n {
+		found = found[:n]
+	}
+	for i, w := range found {
+		found[i] = matchCase(w, word)
+	}
+	return found
+}
+
+// edits returns the strings one edit away from w.
+func (c *Checker) edits(w string) []string {
+	rs := []rune(w)
+	var res []string
+	for i := 0; i <= len(rs); i++ {
+		head, tail := string(rs[:i]), rs[i:]
+		if len(tail) > 0 {
+			res = append(res, head+string(tail[1:]))
+		}
+		if len(tail) > 1 {
+			res = append(res, head+string(tail[1])+string(tail[0])+string(tail[2:]))
+		}
+		for _, l := range c.letters {
+			if len(tail) > 0 {
+				res = append(res, head+string(l)+string(tail[1:]))
+			}
+			res = append(res, head+string(l)+string(tail))
+		}
+	}
+	return res
+}
+
+// matchCase returns the lower case word w capitalized like model: upper
+// case if model is, or with its first letter in upper case if model's
+// is.
+func matchCase(w, model string) string {
+	switch {
+	case model == strings.ToUpper(model) && utf8.RuneCountInString(model) > 1:
+		return strings.ToUpper(w)
+	case unicode.IsUpper([]rune(model)[0]):
+		r, n := utf8.DecodeRuneInString(w)
+		return string(unicode.ToUpper(r)) + w[n:]
+	}
+	return w
+}
+
+// Words splits text into words: runs of letters, possibly joined by
+// apostrophes.
+func Words(text string) []Word {
+	var words []Word
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if r == '\'' || r == '’' {
+			// An apostrophe continues a word if a letter follows.
+			next, _ := utf8.DecodeRuneInString(text[i+utf8.RuneLen(r):])
+			inWord = start >= 0 && unicode.IsLetter(next)
+		}
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			words = append(words, Word{Start: start, End: i, Text: text[start:i]})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, Word{Start: start, End: len(text), Text: text[start:]})
+	}
+	return words
+}
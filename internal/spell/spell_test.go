@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package spell
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	got := Words("Don't stop, 'quoted' naïve-words x2.")
+	var texts []string
+	for _, w := range got {
+		if w.Text != "Don't stop, 'quoted' naïve-words x2."[w.Start:w.End] {
+			t.Errorf("word %q doesn't match its offsets %d-%d", w.Text, w.Start, w.End)
+		}
+		texts = append(texts, w.Text)
+	}
+	want := []string{"Don't", "stop", "quoted", "naïve", "words", "x2"}
+	if !reflect.DeepEqual(texts, want) {
+		t.Errorf("Words = %q, want %q", texts, want)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	c, err := Load(strings.NewReader("3\nhello/M\nworld\nword\nwords\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := c.Misspelled("Hello wrold, 42 words")
+	if len(bad) != 1 || bad[0].Text != "wrold" || bad[0].Start != 6 {
+		t.Errorf("Misspelled = %v, want [wrold at 6]", bad)
+	}
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"wrold", []string{"world", "word"}},
+		{"Wrod", []string{"Word", "Words", "World"}},
+		{"WORLDS", []string{"WORDS", "WORLD", "WORD"}},
+		{"xyzzy", nil},
+	}
+	for _, test := range tests {
+		if got := c.Suggest(test.word, 5); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Suggest(%q) = %q, want %q", test.word, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package style has the small material widgets the examples share.
+package style
+
+import (
+	"image/color"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// FieldStyle is a text editor in a rounded border filling the width of
+// its constraints.
+type FieldStyle struct {
+	Editor material.EditorStyle
+	// Inset is the space between the border and the text.
+	Inset  layout.Inset
+	Border widget.Border
+}
+
+// TextButton returns a button drawn as its label alone.
+func TextButton(th *material.Theme, c *widget.Clickable, label string) layout.Widget {
+	return func(gtx layout.Context) layout.Dimensions {
+		b := material.Button(th, c, label)
+		b.Background = color.NRGBA{}
+		b.Color = th.Palette.ContrastBg
+		return b.Layout(gtx)
+	}
+}
+
+// Field returns a bordered editor showing hint while it is empty.
+func Field(th *material.Theme, ed *widget.Editor, hint string) FieldStyle {
+	return FieldStyle{
+		Editor: material.Editor(th, ed, hint),
+		Inset:  layout.UniformInset(unit.Dp(6)),
+		Border: widget.Border{Color: color.NRGBA{A: 0x40}, CornerRadius: unit.Dp(4), Width: unit.Dp(1)},
+	}
+}
+
+func (f FieldStyle) Layout(gtx layout.Context) layout.Dimensions {
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	return f.Border.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return f.Inset.Layout(gtx, f.Editor.Layout)
+	})
+}
+
+// MulAlpha scales the alpha of c by alpha/255.
+func MulAlpha(c color.NRGBA, alpha uint8) color.NRGBA {
+	c.A = uint8(uint32(c.A) * uint32(alpha) / 0xff)
+	return c
+}
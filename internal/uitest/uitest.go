@@ -0,0 +1,366 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package uitest drives the user interfaces of examples in tests,
+// without a window: a Driver lays out frames of a layout function, sends
+// them pointer and key events, runs the frames widgets ask for and
+// renders them for assertions on pixels.
+//
+// Widgets are found by their state, such as a *widget.Clickable or a
+// *widget.Editor: Locate finds the area of the window where its input
+// handlers receive pointer events, and Click clicks in the middle of it.
+//
+//	d := uitest.New(image.Pt(800, 600), func(gtx layout.Context) {
+//		material.Button(th, &btn, "OK").Layout(gtx)
+//	})
+//	d.Click(&btn)
+//	if !clicked { ... }
+//
+// Examples are driven through the function laying out their frames, as
+// their event loops need a real window.
+package uitest
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"reflect"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/gpu/headless"
+	"gioui.org/io/clipboard"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+)
+
+// maxSettle is the most frames Settle runs.
+const maxSettle = 100
+
+// probeStep is the distance in pixels between the points probed by
+// Locate.
+const probeStep = 4
+
+// Driver runs the frames of a layout function.
+type Driver struct {
+	// Metric is the metric of the frames, a pixel per dp by default.
+	Metric unit.Metric
+	// Clipboard is the content of the simulated clipboard.
+	Clipboard string
+
+	size        image.Point
+	layout      func(gtx layout.Context)
+	now         time.Time
+	router      router.Router
+	ops         op.Ops
+	window      *headless.Window
+	invalidated chan struct{}
+}
+
+// New returns a driver for frames of size laid out by lay. The first
+// frame is laid out immediately.
+func New(size image.Point, lay func(gtx layout.Context)) *Driver {
+	d := &Driver{
+		Metric:      unit.Metric{PxPerDp: 1, PxPerSp: 1},
+		size:        size,
+		layout:      lay,
+		now:         time.Unix(0, 0),
+		invalidated: make(chan struct{}, 1),
+	}
+	d.Frame()
+	return d
+}
+
+// Now returns the time of the last frame. The clock of the driver
+// starts at the Unix epoch and only moves forward with frames.
+func (d *Driver) Now() time.Time {
+	return d.now
+}
+
+// Frame lays out a frame, 16 milliseconds after the one before.
+func (d *Driver) Frame() {
+	d.frameAt(d.now.Add(16 * time.Millisecond))
+}
+
+func (d *Driver) frameAt(now time.Time) {
+	d.now = now
+	d.ops.Reset()
+	gtx := layout.Context{
+		Ops:         &d.ops,
+		Now:         d.now,
+		Queue:       &d.router,
+		Metric:      d.Metric,
+		Constraints: layout.Exact(d.size),
+	}
+	d.layout(gtx)
+	d.router.Frame(&d.ops)
+	if text, ok := d.router.WriteClipboard(); ok {
+		d.Clipboard = text
+	}
+	if d.router.ReadClipboard() {
+		d.router.Queue(clipboard.Event{Text: d.Clipboard})
+	}
+}
+
+// Settle runs frames while the widgets ask for them, moving the clock
+// forward to the times they ask for, and reports whether they stopped
+// asking. Animations running forever keep asking.
+func (d *Driver) Settle() bool {
+	for i := 0; i < maxSettle; i++ {
+		at, ok := d.router.WakeupTime()
+		if !ok {
+			return true
+		}
+		if at.Before(d.now.Add(16 * time.Millisecond)) {
+			at = d.now.Add(16 * time.Millisecond)
+		}
+		d.frameAt(at)
+	}
+	return false
+}
+
+// Invalidate asks for a frame, like app.Window.Invalidate. It may be
+// called from any goroutine, such as from the callbacks of background
+// work.
+func (d *Driver) Invalidate() {
+	select {
+	case d.invalidated <- struct{}{}:
+	default:
+	}
+}
+
+// Wait waits up to timeout for a call to Invalidate, then runs the
+// frames it asked for. It reports whether Invalidate was called.
+func (d *Driver) Wait(timeout time.Duration) bool {
+	select {
+	case <-d.invalidated:
+	case <-time.After(timeout):
+		return false
+	}
+	d.Frame()
+	d.Settle()
+	return true
+}
+
+// send queues events and runs the frames handling them.
+func (d *Driver) send(events ...event.Event) {
+	d.router.Queue(events...)
+	d.Frame()
+	d.Settle()
+}
+
+// Locate returns the area of the window where the input handlers of w
+// receive pointer events. W is the state of a widget, such as a
+// *widget.Clickable, or a tag of its input operations.
+func (d *Driver) Locate(w interface{}) (image.Rectangle, bool) {
+	return Locate(&d.ops, d.size, w)
+}
+
+// Locate returns the area where the input handlers of w receive pointer
+// events in a frame of ops and size. The area is found by probing the
+// frame every few pixels, without touching the state of the widgets.
+func Locate(ops *op.Ops, size image.Point, w interface{}) (image.Rectangle, bool) {
+	tags := tags(ops, w)
+	if len(tags) == 0 {
+		return image.Rectangle{}, false
+	}
+	var probe router.Router
+	var area image.Rectangle
+	found := false
+	for y := probeStep / 2; y < size.Y; y += probeStep {
+		// Start every row afresh, dropping the events of the other
+		// handlers.
+		probe.Frame(ops)
+		for x := probeStep / 2; x < size.X; x += probeStep {
+			pos := f32.Pt(float32(x), float32(y))
+			probe.Queue(pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: pos})
+			hit := false
+			for _, t := range tags {
+				for _, e := range probe.Events(t) {
+					if e, ok := e.(pointer.Event); ok && e.Type == pointer.Press {
+						hit = true
+					}
+				}
+			}
+			probe.Queue(pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: pos})
+			if !hit {
+				continue
+			}
+			r := image.Rect(x-probeStep/2, y-probeStep/2, x+probeStep/2, y+probeStep/2)
+			if found {
+				area = area.Union(r)
+			} else {
+				area, found = r, true
+			}
+		}
+	}
+	return area, found
+}
+
+// tags returns the tags of ops belonging to w: w itself, or pointers into
+// the memory of w, such as the gesture state inside a widget.
+func tags(root *op.Ops, w interface{}) []event.Tag {
+	wv := reflect.ValueOf(w)
+	var lo, hi uintptr
+	if wv.Kind() == reflect.Ptr && !wv.IsNil() {
+		lo = wv.Pointer()
+		hi = lo + wv.Type().Elem().Size()
+	}
+	var tags []event.Tag
+	seen := make(map[interface{}]bool)
+	var scan func(ops *op.Ops)
+	scan = func(ops *op.Ops) {
+		if seen[ops] {
+			return
+		}
+		seen[ops] = true
+		for _, r := range ops.Refs() {
+			if r == nil {
+				continue
+			}
+			if ops, ok := r.(*op.Ops); ok {
+				scan(ops)
+				continue
+			}
+			rv := reflect.ValueOf(r)
+			if !rv.Type().Comparable() || seen[r] {
+				continue
+			}
+			match := r == w
+			if !match && rv.Kind() == reflect.Ptr && lo != hi {
+				p := rv.Pointer()
+				match = p >= lo && p < hi
+			}
+			if match {
+				seen[r] = true
+				tags = append(tags, r)
+			}
+		}
+	}
+	scan(root)
+	return tags
+}
+
+// Center returns the middle of the area of w.
+func (d *Driver) Center(w interface{}) (image.Point, error) {
+	r, ok := d.Locate(w)
+	if !ok {
+		return image.Point{}, fmt.Errorf("uitest: %T not found in the frame", w)
+	}
+	return r.Min.Add(r.Max).Div(2), nil
+}
+
+// Click clicks the middle of the area of w with the primary button.
+func (d *Driver) Click(w interface{}) error {
+	p, err := d.Center(w)
+	if err != nil {
+		return err
+	}
+	d.ClickAt(p)
+	return nil
+}
+
+// ClickAt clicks at p with the primary button.
+func (d *Driver) ClickAt(p image.Point) {
+	d.Drag(p, p)
+}
+
+// Drag presses the primary button at from, moves to to and releases it.
+func (d *Driver) Drag(from, to image.Point) {
+	pos := func(p image.Point) f32.Point {
+		return f32.Pt(float32(p.X), float32(p.Y))
+	}
+	d.send(pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: pos(from)})
+	if to != from {
+		d.send(pointer.Event{Type: pointer.Move, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: pos(to)})
+	}
+	d.send(pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: pos(to)})
+}
+
+// Scroll turns the mouse wheel at p by dist pixels; positive distances
+// scroll down and right. The distance is sent in steps, like a wheel
+// does, as lists only scroll past the children they have laid out.
+func (d *Driver) Scroll(p image.Point, dist f32.Point) {
+	const step = 40
+	for dist != (f32.Point{}) {
+		s := f32.Pt(clamp(dist.X, step), clamp(dist.Y, step))
+		d.send(pointer.Event{
+			Type:     pointer.Scroll,
+			Source:   pointer.Mouse,
+			Position: f32.Pt(float32(p.X), float32(p.Y)),
+			Scroll:   s,
+		})
+		dist = dist.Sub(s)
+	}
+}
+
+func clamp(v, max float32) float32 {
+	switch {
+	case v > max:
+		return max
+	case v < -max:
+		return -max
+	}
+	return v
+}
+
+// Type sends text to the focused widget, as if typed.
+func (d *Driver) Type(text string) {
+	d.send(key.EditEvent{Text: text})
+}
+
+// Press presses and releases a key with modifiers, such as key.NameReturn
+// or "V" with key.ModShortcut to paste the Clipboard.
+func (d *Driver) Press(name string, mods key.Modifiers) {
+	d.send(
+		key.Event{Name: name, Modifiers: mods, State: key.Press},
+		key.Event{Name: name, Modifiers: mods, State: key.Release},
+	)
+}
+
+// ErrNoGPU is returned by Render where no GPU context can be created,
+// such as on machines without a display. Tests should skip then.
+var ErrNoGPU = errors.New("uitest: no GPU for rendering")
+
+// Render renders the last frame.
+func (d *Driver) Render() (*image.RGBA, error) {
+	if d.window == nil {
+		w, err := headless.NewWindow(d.size.X, d.size.Y)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNoGPU, err)
+		}
+		d.window = w
+	}
+	if err := d.window.Frame(&d.ops); err != nil {
+		return nil, err
+	}
+	return d.window.Screenshot()
+}
+
+// Close releases the resources of Render.
+func (d *Driver) Close() {
+	if d.window != nil {
+		d.window.Release()
+		d.window = nil
+	}
+}
+
+// Near reports whether the channels of two colors differ by at most
+// tol, to allow for antialiasing and rounding in the renderer.
+func Near(a, b color.Color, tol uint8) bool {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	near := func(x, y uint32) bool {
+		x, y = x>>8, y>>8
+		if x > y {
+			x, y = y, x
+		}
+		return y-x <= uint32(tol)
+	}
+	return near(ar, br) && near(ag, bg) && near(ab, bb) && near(aa, ba)
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package uitest
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/widget"
+)
+
+func TestLocateAndClick(t *testing.T) {
+	var btn widget.Clickable
+	clicks := 0
+	d := New(image.Pt(200, 100), func(gtx layout.Context) {
+		for btn.Clicked() {
+			clicks++
+		}
+		op.Offset(layout.FPt(image.Pt(40, 20))).Add(gtx.Ops)
+		gtx.Constraints = layout.Exact(image.Pt(80, 40))
+		btn.Layout(gtx)
+	})
+	r, ok := d.Locate(&btn)
+	if !ok {
+		t.Fatal("button not found")
+	}
+	want := image.Rect(40, 20, 120, 60)
+	if r != want {
+		t.Errorf("button at %v, want %v", r, want)
+	}
+	if err := d.Click(&btn); err != nil {
+		t.Fatal(err)
+	}
+	if clicks != 1 {
+		t.Errorf("%d clicks, want 1", clicks)
+	}
+	var other widget.Clickable
+	if _, ok := d.Locate(&other); ok {
+		t.Error("found a widget not laid out")
+	}
+}
+
+func TestSettle(t *testing.T) {
+	frames := 0
+	d := New(image.Pt(10, 10), func(gtx layout.Context) {
+		frames++
+		if frames < 5 {
+			op.InvalidateOp{At: gtx.Now.Add(time.Second)}.Add(gtx.Ops)
+		}
+	})
+	start := d.Now()
+	if !d.Settle() {
+		t.Fatal("didn't settle")
+	}
+	if frames != 5 {
+		t.Errorf("%d frames, want 5", frames)
+	}
+	if got := d.Now().Sub(start); got != 4*time.Second {
+		t.Errorf("clock moved %v, want 4s", got)
+	}
+	go d.Invalidate()
+	if !d.Wait(time.Second) || frames != 6 {
+		t.Errorf("%d frames after Invalidate, want 6", frames)
+	}
+}
+
+func TestNear(t *testing.T) {
+	a := color.NRGBA{R: 100, G: 100, B: 100, A: 255}
+	if !Near(a, color.NRGBA{R: 104, G: 97, B: 100, A: 255}, 4) {
+		t.Error("close colors not near")
+	}
+	if Near(a, color.NRGBA{R: 110, G: 100, B: 100, A: 255}, 4) {
+		t.Error("distant colors near")
+	}
+}
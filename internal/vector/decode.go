@@ -0,0 +1,470 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package vector exports frames of Gio operations as vector graphics.
+// Decode replays the operations of a frame into a Scene of filled and
+// stroked shapes, clip paths, gradients and images, which WriteSVG and
+// WritePDF write as documents that scale without loss. Text is drawn by
+// Gio as glyph outlines, so it exports as paths too.
+//
+// Decode reads the encoding of operations of the Gio version in go.mod;
+// Gio offers no public way to read operations back. The renderer of Gio
+// is the reference for the meaning of every operation.
+package vector
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/op"
+)
+
+// The operation types and sizes of the encoding, from the internal
+// opconst package of Gio.
+const (
+	typeMacro byte = iota + 200
+	typeCall
+	typeDefer
+	typeTransform
+	typeInvalidate
+	typeImage
+	typePaint
+	typeColor
+	typeLinearGradient
+	typeArea
+	typePointerInput
+	typePass
+	typeClipboardRead
+	typeClipboardWrite
+	typeKeyInput
+	typeKeyFocus
+	typeKeySoftKeyboard
+	typeSave
+	typeLoad
+	typeAux
+	typeClip
+	typeProfile
+	typeCursor
+	typePath
+	typeStroke
+)
+
+var opSizes = [...]int{
+	typeMacro - typeMacro:           1 + 4 + 4,
+	typeCall - typeMacro:            1 + 4 + 4,
+	typeDefer - typeMacro:           1,
+	typeTransform - typeMacro:       1 + 4*6,
+	typeInvalidate - typeMacro:      1 + 8,
+	typeImage - typeMacro:           1,
+	typePaint - typeMacro:           1,
+	typeColor - typeMacro:           1 + 4,
+	typeLinearGradient - typeMacro:  1 + 8*2 + 4*2,
+	typeArea - typeMacro:            1 + 1 + 4*4,
+	typePointerInput - typeMacro:    1 + 1 + 1 + 2*4 + 2*4,
+	typePass - typeMacro:            1 + 1,
+	typeClipboardRead - typeMacro:   1,
+	typeClipboardWrite - typeMacro:  1,
+	typeKeyInput - typeMacro:        1,
+	typeKeyFocus - typeMacro:        1,
+	typeKeySoftKeyboard - typeMacro: 1 + 1,
+	typeSave - typeMacro:            1 + 4,
+	typeLoad - typeMacro:            1 + 1 + 4,
+	typeAux - typeMacro:             1,
+	typeClip - typeMacro:            1 + 4*4 + 1,
+	typeProfile - typeMacro:         1,
+	typeCursor - typeMacro:          1 + 1,
+	typePath - typeMacro:            1,
+	typeStroke - typeMacro:          1 + 4,
+}
+
+func opSize(t byte) int {
+	if t < typeMacro || int(t-typeMacro) >= len(opSizes) {
+		panic(fmt.Sprintf("vector: unknown operation %d", t))
+	}
+	return opSizes[t-typeMacro]
+}
+
+func opRefs(t byte) int {
+	switch t {
+	case typeKeyInput, typeKeyFocus, typePointerInput, typeProfile, typeCall, typeClipboardRead, typeClipboardWrite, typeCursor:
+		return 1
+	case typeImage:
+		return 2
+	}
+	return 0
+}
+
+// transformState is the state mask of loads restoring the transform
+// only.
+const transformState = 1
+
+// segmentSize is the size of a path segment: a contour index and a
+// command of the compute renderer.
+const segmentSize = 4 + 36
+
+// The commands of path segments.
+const (
+	cmdLine  = 1
+	cmdQuad  = 2
+	cmdCubic = 3
+)
+
+var bo = binary.LittleEndian
+
+// Scene is a frame of shapes.
+type Scene struct {
+	Size  image.Point
+	Items []Item
+}
+
+// Item paints a shape, or the whole area of its clips, with a material.
+type Item struct {
+	// Clips are the clip areas of the item, outermost first. Items
+	// share the clips of their common ancestors.
+	Clips []*Clip
+	Material
+}
+
+// Clip is the area of a path, or of its stroke.
+type Clip struct {
+	Path Path
+	// Stroke is the width of the stroke, or zero for the area inside
+	// the path. Strokes have round caps and joins; Gio turns other
+	// strokes into outlines.
+	Stroke float32
+
+	parent *Clip
+}
+
+// Path is a path in window coordinates.
+type Path []Segment
+
+// Segment is a part of a path.
+type Segment struct {
+	Op SegmentOp
+	// Pts are the control points and end point of the segment; the
+	// start is the end of the segment before.
+	Pts [3]f32.Point
+}
+
+// SegmentOp is the kind of a segment.
+type SegmentOp uint8
+
+const (
+	MoveTo SegmentOp = iota
+	LineTo
+	QuadTo
+	CubeTo
+	Close
+)
+
+// MaterialKind is the kind of material of an item.
+type MaterialKind uint8
+
+const (
+	MaterialColor MaterialKind = iota
+	MaterialGradient
+	MaterialImage
+)
+
+// Material is the paint of an item.
+type Material struct {
+	Kind  MaterialKind
+	Color color.NRGBA
+	// Stop1 and Stop2 are the ends of a linear gradient from Color to
+	// Color2, and the corners of an image, before Transform.
+	Stop1, Stop2 f32.Point
+	Color2       color.NRGBA
+	Image        *image.RGBA
+	// Transform maps the gradient or image to window coordinates.
+	Transform f32.Affine2D
+}
+
+// state is the drawing state saved and loaded by operations.
+type state struct {
+	t    f32.Affine2D
+	clip *Clip
+	mat  Material
+}
+
+// Decode replays the operations of a frame of size.
+func Decode(ops *op.Ops, size image.Point) (sc *Scene, err error) {
+	defer func() {
+		// Report operations out of the expected encoding, such as from
+		// another Gio version.
+		if p := recover(); p != nil {
+			err = fmt.Errorf("vector: can't decode operations: %v", p)
+		}
+	}()
+	sc = &Scene{Size: size}
+	var (
+		r      reader
+		st     = state{t: f32.Affine2D{}}
+		states = map[int]state{0: st}
+		path   []byte
+		stroke float32
+	)
+	r.reset(ops)
+	for {
+		data, refs, ok := r.decode()
+		if !ok {
+			break
+		}
+		switch data[0] {
+		case typeTransform:
+			st.t = st.t.Mul(decodeAffine(data[1:]))
+		case typeStroke:
+			stroke = math.Float32frombits(bo.Uint32(data[1:]))
+		case typePath:
+			aux, _, ok := r.decode()
+			if !ok || aux[0] != typeAux {
+				return nil, fmt.Errorf("vector: path without data")
+			}
+			path = aux[1:]
+		case typeClip:
+			c := &Clip{parent: st.clip}
+			if path != nil {
+				c.Path = decodePath(path, st.t)
+				if stroke > 0 && data[17] == 0 {
+					c.Stroke = stroke * scaleOf(st.t)
+				}
+			} else {
+				b := image.Rect(
+					int(int32(bo.Uint32(data[1:]))), int(int32(bo.Uint32(data[5:]))),
+					int(int32(bo.Uint32(data[9:]))), int(int32(bo.Uint32(data[13:]))),
+				)
+				c.Path = rectPath(layoutFRect(b), st.t)
+			}
+			st.clip = c
+			path, stroke = nil, 0
+		case typeColor:
+			st.mat = Material{Kind: MaterialColor, Color: color.NRGBA{R: data[1], G: data[2], B: data[3], A: data[4]}}
+		case typeLinearGradient:
+			st.mat = Material{
+				Kind:   MaterialGradient,
+				Stop1:  f32.Pt(math.Float32frombits(bo.Uint32(data[1:])), math.Float32frombits(bo.Uint32(data[5:]))),
+				Stop2:  f32.Pt(math.Float32frombits(bo.Uint32(data[9:])), math.Float32frombits(bo.Uint32(data[13:]))),
+				Color:  color.NRGBA{R: data[17], G: data[18], B: data[19], A: data[20]},
+				Color2: color.NRGBA{R: data[21], G: data[22], B: data[23], A: data[24]},
+			}
+		case typeImage:
+			st.mat = Material{Kind: MaterialImage}
+			if refs[1] != nil {
+				img := refs[0].(*image.RGBA)
+				st.mat.Image = img
+				st.mat.Stop1 = layoutFPt(img.Rect.Min)
+				st.mat.Stop2 = layoutFPt(img.Rect.Max)
+			}
+		case typePaint:
+			if st.mat.Kind == MaterialImage && st.mat.Image == nil {
+				break
+			}
+			it := Item{Material: st.mat}
+			it.Transform = st.t
+			for c := st.clip; c != nil; c = c.parent {
+				it.Clips = append(it.Clips, c)
+			}
+			for i, j := 0, len(it.Clips)-1; i < j; i, j = i+1, j-1 {
+				it.Clips[i], it.Clips[j] = it.Clips[j], it.Clips[i]
+			}
+			sc.Items = append(sc.Items, it)
+		case typeSave:
+			states[int(bo.Uint32(data[1:]))] = st
+		case typeLoad:
+			s := states[int(bo.Uint32(data[2:]))]
+			if data[1]&transformState != 0 {
+				st.t = s.t
+			}
+			if data[1]&^transformState != 0 {
+				st = s
+			}
+		}
+	}
+	return sc, nil
+}
+
+func decodeAffine(d []byte) f32.Affine2D {
+	var v [6]float32
+	for i := range v {
+		v[i] = math.Float32frombits(bo.Uint32(d[i*4:]))
+	}
+	return f32.NewAffine2D(v[0], v[1], v[2], v[3], v[4], v[5])
+}
+
+// scaleOf returns the average scale of a transform, for stroke widths.
+func scaleOf(t f32.Affine2D) float32 {
+	sx, hx, _, hy, sy, _ := t.Elems()
+	return float32(math.Sqrt(math.Abs(float64(sx*sy - hx*hy))))
+}
+
+func decodePath(d []byte, t f32.Affine2D) Path {
+	var (
+		p       Path
+		pen     f32.Point
+		start   f32.Point
+		contour = -1
+	)
+	closeContour := func() {
+		if len(p) > 0 && p[len(p)-1].Op != MoveTo && pen == start {
+			p = append(p, Segment{Op: Close})
+		}
+	}
+	pt := func(s []byte, i int) f32.Point {
+		return f32.Pt(math.Float32frombits(bo.Uint32(s[4*i:])), math.Float32frombits(bo.Uint32(s[4*i+4:])))
+	}
+	for ; len(d) >= segmentSize; d = d[segmentSize:] {
+		c := int(bo.Uint32(d))
+		cmd := d[4:]
+		from := pt(cmd, 1)
+		if c != contour || from != pen {
+			closeContour()
+			contour = c
+			start = from
+			p = append(p, Segment{Op: MoveTo, Pts: [3]f32.Point{t.Transform(from)}})
+		}
+		var s Segment
+		switch bo.Uint32(cmd) {
+		case cmdLine:
+			pen = pt(cmd, 3)
+			s = Segment{Op: LineTo, Pts: [3]f32.Point{t.Transform(pen)}}
+		case cmdQuad:
+			pen = pt(cmd, 5)
+			s = Segment{Op: QuadTo, Pts: [3]f32.Point{t.Transform(pt(cmd, 3)), t.Transform(pen)}}
+		case cmdCubic:
+			pen = pt(cmd, 7)
+			s = Segment{Op: CubeTo, Pts: [3]f32.Point{t.Transform(pt(cmd, 3)), t.Transform(pt(cmd, 5)), t.Transform(pen)}}
+		default:
+			continue
+		}
+		p = append(p, s)
+	}
+	closeContour()
+	return p
+}
+
+// rectPath returns the path of r transformed by t.
+func rectPath(r f32.Rectangle, t f32.Affine2D) Path {
+	pts := []f32.Point{r.Min, {X: r.Max.X, Y: r.Min.Y}, r.Max, {X: r.Min.X, Y: r.Max.Y}}
+	p := Path{{Op: MoveTo, Pts: [3]f32.Point{t.Transform(pts[0])}}}
+	for _, q := range pts[1:] {
+		p = append(p, Segment{Op: LineTo, Pts: [3]f32.Point{t.Transform(q)}})
+	}
+	return append(p, Segment{Op: Close})
+}
+
+func layoutFPt(p image.Point) f32.Point {
+	return f32.Pt(float32(p.X), float32(p.Y))
+}
+
+func layoutFRect(r image.Rectangle) f32.Rectangle {
+	return f32.Rectangle{Min: layoutFPt(r.Min), Max: layoutFPt(r.Max)}
+}
+
+// pc is a position in an operation list.
+type pc struct {
+	data, refs int
+}
+
+type macro struct {
+	ops      *op.Ops
+	ret, end pc
+}
+
+// reader walks operation lists, following macro calls and running
+// deferred macros last, like the reader of Gio.
+type reader struct {
+	pc        pc
+	stack     []macro
+	ops       *op.Ops
+	deferOps  op.Ops
+	deferDone bool
+}
+
+func (r *reader) reset(ops *op.Ops) {
+	r.ops = ops
+	r.pc = pc{}
+	r.stack = r.stack[:0]
+	r.deferOps.Reset()
+	r.deferDone = false
+}
+
+func (r *reader) decode() ([]byte, []interface{}, bool) {
+	deferring := false
+	for {
+		if n := len(r.stack); n > 0 && r.pc == r.stack[n-1].end {
+			b := r.stack[n-1]
+			r.ops, r.pc = b.ops, b.ret
+			r.stack = r.stack[:n-1]
+			continue
+		}
+		data := r.ops.Data()[r.pc.data:]
+		if len(data) == 0 {
+			if r.deferDone {
+				return nil, nil, false
+			}
+			r.deferDone = true
+			r.ops = &r.deferOps
+			r.pc = pc{}
+			continue
+		}
+		t := data[0]
+		n, nrefs := opSize(t), opRefs(t)
+		data = data[:n]
+		refs := r.ops.Refs()[r.pc.refs:][:nrefs]
+		switch t {
+		case typeDefer:
+			deferring = true
+			r.pc.data += n
+			r.pc.refs += nrefs
+			continue
+		case typeAux:
+			// Aux data fills the rest of its macro.
+			end := r.stack[len(r.stack)-1].end
+			data = r.ops.Data()[r.pc.data:end.data]
+			n = len(data)
+		case typeCall:
+			if deferring {
+				deferring = false
+				copy(r.deferOps.Write1(n, refs[0]), data)
+				r.pc.data += n
+				r.pc.refs += nrefs
+				continue
+			}
+			ops := refs[0].(*op.Ops)
+			target := pc{data: int(int32(bo.Uint32(data[1:]))), refs: int(int32(bo.Uint32(data[5:])))}
+			mdata := ops.Data()[target.data:]
+			if mdata[0] != typeMacro {
+				panic("call of a non-macro")
+			}
+			end := pc{data: int(int32(bo.Uint32(mdata[1:]))), refs: int(int32(bo.Uint32(mdata[5:])))}
+			r.stack = append(r.stack, macro{
+				ops: r.ops,
+				ret: pc{data: r.pc.data + n, refs: r.pc.refs + nrefs},
+				end: end,
+			})
+			r.ops = ops
+			r.pc = pc{data: target.data + opSize(typeMacro), refs: target.refs}
+			continue
+		case typeMacro:
+			r.pc = pc{data: int(int32(bo.Uint32(data[1:]))), refs: int(int32(bo.Uint32(data[5:])))}
+			continue
+		}
+		r.pc.data += n
+		r.pc.refs += nrefs
+		return data, refs, true
+	}
+}
+
+// shape splits the clips of an item into the clips around it and its
+// shape: the innermost clip, unless the item is an image or has no
+// clips.
+func (it Item) shape() (outer []*Clip, shape *Clip) {
+	outer = it.Clips
+	if n := len(outer); n > 0 && it.Kind != MaterialImage {
+		outer, shape = outer[:n-1], outer[n-1]
+	}
+	return outer, shape
+}
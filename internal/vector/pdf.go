@@ -0,0 +1,287 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package vector
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+
+	"gioui.org/f32"
+)
+
+// WritePDF writes the scene as a single page PDF document, with a point
+// per pixel.
+//
+// PDF can't clip with strokes: stroked clips around other clips clip to
+// their bounds instead. Gradients interpolate in sRGB and use the opacity
+// of their first color.
+func (s *Scene) WritePDF(w io.Writer) error {
+	pw := &pdfWriter{
+		images: make(map[*image.RGBA]string),
+		alphas: make(map[uint8]string),
+	}
+	// Flip the page to the coordinates of Gio, with the origin at the
+	// top left.
+	fmt.Fprintf(&pw.content, "1 0 0 -1 0 %d cm\n", s.Size.Y)
+	for _, it := range s.Items {
+		pw.item(s, it)
+	}
+	return pw.write(w, s.Size)
+}
+
+type pdfWriter struct {
+	content bytes.Buffer
+	// objs are the resource objects, numbered from pdfFirstResource.
+	objs []string
+	// The entries of the resource dictionaries of the page.
+	gstates, shadings, xobjects []string
+	images                      map[*image.RGBA]string
+	alphas                      map[uint8]string
+}
+
+// Object numbers of the fixed objects.
+const (
+	pdfCatalog = 1 + iota
+	pdfPages
+	pdfPage
+	pdfContent
+	pdfFirstResource
+)
+
+func (pw *pdfWriter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&pw.content, format, args...)
+}
+
+// object adds a resource object and returns its number.
+func (pw *pdfWriter) object(obj string) int {
+	pw.objs = append(pw.objs, obj)
+	return pdfFirstResource + len(pw.objs) - 1
+}
+
+func (pw *pdfWriter) item(s *Scene, it Item) {
+	outer, shape := it.shape()
+	pw.printf("q\n")
+	for _, c := range outer {
+		if c.Stroke > 0 {
+			b := pathBounds(c.Path)
+			hw := c.Stroke / 2
+			b.Min = b.Min.Sub(f32.Pt(hw, hw))
+			b.Max = b.Max.Add(f32.Pt(hw, hw))
+			pw.path(rectPath(b, f32.Affine2D{}))
+		} else {
+			pw.path(c.Path)
+		}
+		pw.printf("W n\n")
+	}
+	switch it.Kind {
+	case MaterialColor:
+		pw.alpha(it.Color.A)
+		r, g, b := pdfColor(it.Color)
+		switch {
+		case shape == nil:
+			pw.printf("%s %s %s rg 0 0 %d %d re f\n", r, g, b, s.Size.X, s.Size.Y)
+		case shape.Stroke > 0:
+			pw.printf("%s %s %s RG %s w 1 J 1 j\n", r, g, b, num(shape.Stroke))
+			pw.path(shape.Path)
+			pw.printf("S\n")
+		default:
+			pw.printf("%s %s %s rg\n", r, g, b)
+			pw.path(shape.Path)
+			pw.printf("f\n")
+		}
+	case MaterialGradient:
+		if shape != nil {
+			if shape.Stroke > 0 {
+				b := pathBounds(shape.Path)
+				pw.path(rectPath(b, f32.Affine2D{}))
+			} else {
+				pw.path(shape.Path)
+			}
+			pw.printf("W n\n")
+		}
+		pw.alpha(it.Color.A)
+		r1, g1, b1 := pdfColor(it.Color)
+		r2, g2, b2 := pdfColor(it.Color2)
+		n := pw.object(fmt.Sprintf("<< /ShadingType 2 /ColorSpace /DeviceRGB /Coords [%s %s %s %s] "+
+			"/Function << /FunctionType 2 /Domain [0 1] /C0 [%s %s %s] /C1 [%s %s %s] /N 1 >> /Extend [true true] >>",
+			num(it.Stop1.X), num(it.Stop1.Y), num(it.Stop2.X), num(it.Stop2.Y), r1, g1, b1, r2, g2, b2))
+		name := fmt.Sprintf("Sh%d", n)
+		pw.shadings = append(pw.shadings, fmt.Sprintf("/%s %d 0 R", name, n))
+		pw.printf("%s cm /%s sh\n", pdfMatrix(it.Transform), name)
+	case MaterialImage:
+		name := pw.image(it.Image)
+		sz := it.Stop2.Sub(it.Stop1)
+		// Images fill the unit square upwards; turn them upright.
+		pw.printf("%s cm %s 0 0 %s %s %s cm /%s Do\n", pdfMatrix(it.Transform),
+			num(sz.X), num(-sz.Y), num(it.Stop1.X), num(it.Stop2.Y), name)
+	}
+	pw.printf("Q\n")
+}
+
+// alpha sets the opacity of fills and strokes.
+func (pw *pdfWriter) alpha(a uint8) {
+	if a == 0xff {
+		return
+	}
+	name, ok := pw.alphas[a]
+	if !ok {
+		v := colorNum(a)
+		n := pw.object(fmt.Sprintf("<< /Type /ExtGState /ca %s /CA %s >>", v, v))
+		name = fmt.Sprintf("GS%d", n)
+		pw.alphas[a] = name
+		pw.gstates = append(pw.gstates, fmt.Sprintf("/%s %d 0 R", name, n))
+	}
+	pw.printf("/%s gs\n", name)
+}
+
+// image adds an image object, with its alpha channel as a soft mask.
+func (pw *pdfWriter) image(img *image.RGBA) string {
+	if name, ok := pw.images[img]; ok {
+		return name
+	}
+	b := img.Bounds()
+	rgb := make([]byte, 0, b.Dx()*b.Dy()*3)
+	alpha := make([]byte, 0, b.Dx()*b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.RGBAAt(x, y)).(color.NRGBA)
+			rgb = append(rgb, c.R, c.G, c.B)
+			alpha = append(alpha, c.A)
+		}
+	}
+	mask := pw.object(pdfStream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8", b.Dx(), b.Dy()), alpha))
+	n := pw.object(pdfStream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /SMask %d 0 R", b.Dx(), b.Dy(), mask), rgb))
+	name := fmt.Sprintf("Im%d", n)
+	pw.images[img] = name
+	pw.xobjects = append(pw.xobjects, fmt.Sprintf("/%s %d 0 R", name, n))
+	return name
+}
+
+func (pw *pdfWriter) path(p Path) {
+	pt := func(p f32.Point) string {
+		return num(p.X) + " " + num(p.Y)
+	}
+	var pen f32.Point
+	for _, s := range p {
+		switch s.Op {
+		case MoveTo:
+			pw.printf("%s m\n", pt(s.Pts[0]))
+			pen = s.Pts[0]
+		case LineTo:
+			pw.printf("%s l\n", pt(s.Pts[0]))
+			pen = s.Pts[0]
+		case QuadTo:
+			// PDF has no quadratic curves; raise the degree.
+			c0 := pen.Add(s.Pts[0].Sub(pen).Mul(2.0 / 3))
+			c1 := s.Pts[1].Add(s.Pts[0].Sub(s.Pts[1]).Mul(2.0 / 3))
+			pw.printf("%s %s %s c\n", pt(c0), pt(c1), pt(s.Pts[1]))
+			pen = s.Pts[1]
+		case CubeTo:
+			pw.printf("%s %s %s c\n", pt(s.Pts[0]), pt(s.Pts[1]), pt(s.Pts[2]))
+			pen = s.Pts[2]
+		case Close:
+			pw.printf("h\n")
+		}
+	}
+}
+
+// write writes the document around the content and resources.
+func (pw *pdfWriter) write(w io.Writer, size image.Point) error {
+	var res []string
+	for _, d := range []struct {
+		name    string
+		entries []string
+	}{
+		{"ExtGState", pw.gstates},
+		{"Shading", pw.shadings},
+		{"XObject", pw.xobjects},
+	} {
+		if len(d.entries) > 0 {
+			res = append(res, "/"+d.name+" << "+strings.Join(d.entries, " ")+" >>")
+		}
+	}
+	objs := []string{
+		pdfCatalog: fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPages),
+		pdfPages:   fmt.Sprintf("<< /Type /Pages /Kids [%d 0 R] /Count 1 >>", pdfPage),
+		pdfPage: fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Resources << %s >> /Contents %d 0 R >>",
+			pdfPages, size.X, size.Y, strings.Join(res, " "), pdfContent),
+		pdfContent: pdfStream("", pw.content.Bytes()),
+	}
+	objs = append(objs, pw.objs...)
+
+	bw := bufio.NewWriter(w)
+	offset := 0
+	write := func(s string) {
+		n, _ := bw.WriteString(s)
+		offset += n
+	}
+	write("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objs))
+	for i := 1; i < len(objs); i++ {
+		offsets[i] = offset
+		write(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", i, objs[i]))
+	}
+	xref := offset
+	write(fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", len(objs)))
+	for _, o := range offsets[1:] {
+		write(fmt.Sprintf("%010d 00000 n \n", o))
+	}
+	write(fmt.Sprintf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objs), pdfCatalog, xref))
+	return bw.Flush()
+}
+
+// pdfStream returns a stream object of data compressed with the
+// dictionary entries dict.
+func pdfStream(dict string, data []byte) string {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	return fmt.Sprintf("<< %s /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", dict, buf.Len(), buf.Bytes())
+}
+
+func pdfColor(c color.NRGBA) (r, g, b string) {
+	return colorNum(c.R), colorNum(c.G), colorNum(c.B)
+}
+
+// colorNum formats a color channel in the range 0-1.
+func colorNum(v uint8) string {
+	return trimNum(strconv.FormatFloat(float64(v)/255, 'f', 3, 32))
+}
+
+func pdfMatrix(t f32.Affine2D) string {
+	sx, hx, ox, hy, sy, oy := t.Elems()
+	return fmt.Sprintf("%s %s %s %s %s %s", matNum(sx), matNum(hy), matNum(hx), matNum(sy), num(ox), num(oy))
+}
+
+func pathBounds(p Path) f32.Rectangle {
+	var b f32.Rectangle
+	first := true
+	for _, s := range p {
+		n := 0
+		switch s.Op {
+		case MoveTo, LineTo:
+			n = 1
+		case QuadTo:
+			n = 2
+		case CubeTo:
+			n = 3
+		}
+		for _, q := range s.Pts[:n] {
+			r := f32.Rectangle{Min: q, Max: q}
+			if first {
+				b, first = r, false
+			} else {
+				b = b.Union(r)
+			}
+		}
+	}
+	return b
+}
@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package vector
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"gioui.org/f32"
+)
+
+// WriteSVG writes the scene as an SVG document.
+//
+// Clip areas become clip paths, except strokes, which SVG can't clip with
+// and become masks. Gradients interpolate in linear RGB, like Gio.
+func (s *Scene) WriteSVG(w io.Writer) error {
+	sw := &svgWriter{
+		w:   bufio.NewWriter(w),
+		ids: make(map[*Clip]string),
+	}
+	sw.printf(`<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		s.Size.X, s.Size.Y, s.Size.X, s.Size.Y)
+	for _, it := range s.Items {
+		if err := sw.item(s, it); err != nil {
+			return err
+		}
+	}
+	sw.groups(nil)
+	sw.printf("</svg>\n")
+	if sw.err != nil {
+		return sw.err
+	}
+	return sw.w.Flush()
+}
+
+type svgWriter struct {
+	w   *bufio.Writer
+	err error
+	// open are the clips of the open groups, outermost first.
+	open []*Clip
+	ids  map[*Clip]string
+	n    int
+}
+
+func (sw *svgWriter) printf(format string, args ...interface{}) {
+	if sw.err != nil {
+		return
+	}
+	_, sw.err = fmt.Fprintf(sw.w, format, args...)
+}
+
+func (sw *svgWriter) id(prefix string) string {
+	sw.n++
+	return prefix + strconv.Itoa(sw.n)
+}
+
+// item writes an item inside groups of its clips.
+func (sw *svgWriter) item(s *Scene, it Item) error {
+	groups, shape := it.shape()
+	sw.groups(groups)
+	var paint, opacity string
+	switch it.Kind {
+	case MaterialColor:
+		paint = hexColor(it.Color)
+		if it.Color.A != 0xff {
+			opacity = colorNum(it.Color.A)
+		}
+	case MaterialGradient:
+		id := sw.id("g")
+		sw.printf(`<linearGradient id="%s" gradientUnits="userSpaceOnUse" x1="%s" y1="%s" x2="%s" y2="%s" gradientTransform="%s" color-interpolation="linearRGB">`+
+			`<stop offset="0" stop-color="%s" stop-opacity="%s"/><stop offset="1" stop-color="%s" stop-opacity="%s"/></linearGradient>`+"\n",
+			id, num(it.Stop1.X), num(it.Stop1.Y), num(it.Stop2.X), num(it.Stop2.Y), svgMatrix(it.Transform),
+			hexColor(it.Color), colorNum(it.Color.A), hexColor(it.Color2), colorNum(it.Color2.A))
+		paint = "url(#" + id + ")"
+	case MaterialImage:
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, it.Image); err != nil {
+			return err
+		}
+		sz := it.Stop2.Sub(it.Stop1)
+		sw.printf(`<image transform="%s" x="%s" y="%s" width="%s" height="%s" preserveAspectRatio="none" xlink:href="data:image/png;base64,%s"/>`+"\n",
+			svgMatrix(it.Transform), num(it.Stop1.X), num(it.Stop1.Y), num(sz.X), num(sz.Y),
+			base64.StdEncoding.EncodeToString(buf.Bytes()))
+		return sw.err
+	}
+	switch {
+	case shape == nil:
+		sw.printf(`<rect width="%d" height="%d" fill="%s"%s/>`+"\n", s.Size.X, s.Size.Y, paint, attr("fill-opacity", opacity))
+	case shape.Stroke > 0:
+		sw.printf(`<path d="%s" fill="none" stroke="%s"%s stroke-width="%s" stroke-linecap="round" stroke-linejoin="round"/>`+"\n",
+			svgPath(shape.Path), paint, attr("stroke-opacity", opacity), num(shape.Stroke))
+	default:
+		sw.printf(`<path d="%s" fill="%s"%s/>`+"\n", svgPath(shape.Path), paint, attr("fill-opacity", opacity))
+	}
+	return sw.err
+}
+
+func attr(name, value string) string {
+	if value == "" {
+		return ""
+	}
+	return " " + name + `="` + value + `"`
+}
+
+// groups closes and opens groups to match the clips.
+func (sw *svgWriter) groups(clips []*Clip) {
+	n := 0
+	for n < len(sw.open) && n < len(clips) && sw.open[n] == clips[n] {
+		n++
+	}
+	for i := len(sw.open); i > n; i-- {
+		sw.printf("</g>\n")
+	}
+	sw.open = append(sw.open[:n], clips[n:]...)
+	for _, c := range clips[n:] {
+		id, ok := sw.ids[c]
+		if c.Stroke > 0 {
+			if !ok {
+				id = sw.id("m")
+				sw.ids[c] = id
+				sw.printf(`<mask id="%s" maskUnits="userSpaceOnUse"><path d="%s" fill="none" stroke="#fff" stroke-width="%s" stroke-linecap="round" stroke-linejoin="round"/></mask>`+"\n",
+					id, svgPath(c.Path), num(c.Stroke))
+			}
+			sw.printf(`<g mask="url(#%s)">`+"\n", id)
+			continue
+		}
+		if !ok {
+			id = sw.id("c")
+			sw.ids[c] = id
+			sw.printf(`<clipPath id="%s"><path d="%s"/></clipPath>`+"\n", id, svgPath(c.Path))
+		}
+		sw.printf(`<g clip-path="url(#%s)">`+"\n", id)
+	}
+}
+
+func svgPath(p Path) string {
+	var b strings.Builder
+	pt := func(p f32.Point) {
+		b.WriteString(num(p.X))
+		b.WriteByte(' ')
+		b.WriteString(num(p.Y))
+	}
+	for _, s := range p {
+		switch s.Op {
+		case MoveTo:
+			b.WriteString("M")
+			pt(s.Pts[0])
+		case LineTo:
+			b.WriteString("L")
+			pt(s.Pts[0])
+		case QuadTo:
+			b.WriteString("Q")
+			pt(s.Pts[0])
+			b.WriteByte(' ')
+			pt(s.Pts[1])
+		case CubeTo:
+			b.WriteString("C")
+			pt(s.Pts[0])
+			b.WriteByte(' ')
+			pt(s.Pts[1])
+			b.WriteByte(' ')
+			pt(s.Pts[2])
+		case Close:
+			b.WriteString("Z")
+		}
+	}
+	return b.String()
+}
+
+func hexColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func svgMatrix(t f32.Affine2D) string {
+	sx, hx, ox, hy, sy, oy := t.Elems()
+	return fmt.Sprintf("matrix(%s %s %s %s %s %s)", matNum(sx), matNum(hy), matNum(hx), matNum(sy), num(ox), num(oy))
+}
+
+// num formats a coordinate with the precision of a hundredth of a pixel.
+func num(v float32) string {
+	return trimNum(strconv.FormatFloat(float64(v), 'f', 2, 32))
+}
+
+// matNum formats a scale or shear factor of a matrix.
+func matNum(v float32) string {
+	return trimNum(strconv.FormatFloat(float64(v), 'f', 5, 32))
+}
+
+// trimNum trims the trailing zeros of a formatted number.
+func trimNum(s string) string {
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package vector
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+)
+
+var (
+	red  = color.NRGBA{R: 0xff, A: 0xff}
+	blue = color.NRGBA{B: 0xff, A: 0x80}
+)
+
+func decode(t *testing.T, ops *op.Ops) *Scene {
+	t.Helper()
+	sc, err := Decode(ops, image.Pt(100, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sc
+}
+
+func TestDecodeRect(t *testing.T) {
+	var ops op.Ops
+	op.Offset(f32.Pt(10, 20)).Add(&ops)
+	paint.FillShape(&ops, red, clip.Rect(image.Rect(0, 0, 30, 40)).Op())
+	sc := decode(t, &ops)
+	if len(sc.Items) != 1 {
+		t.Fatalf("got %d items, want 1", len(sc.Items))
+	}
+	it := sc.Items[0]
+	if it.Kind != MaterialColor || it.Color != red {
+		t.Errorf("got material %+v, want %v", it.Material, red)
+	}
+	if len(it.Clips) != 1 {
+		t.Fatalf("got %d clips, want 1", len(it.Clips))
+	}
+	if got, want := svgPath(it.Clips[0].Path), "M10 20L40 20L40 60L10 60Z"; got != want {
+		t.Errorf("got path %q, want %q", got, want)
+	}
+}
+
+func TestDecodePath(t *testing.T) {
+	var ops op.Ops
+	var p clip.Path
+	p.Begin(&ops)
+	p.MoveTo(f32.Pt(10, 10))
+	p.LineTo(f32.Pt(60, 10))
+	p.QuadTo(f32.Pt(60, 50), f32.Pt(10, 10))
+	p.Close()
+	paint.FillShape(&ops, red, clip.Outline{Path: p.End()}.Op())
+
+	p.Begin(&ops)
+	p.MoveTo(f32.Pt(0, 90))
+	p.LineTo(f32.Pt(100, 90))
+	paint.FillShape(&ops, blue, clip.Stroke{Path: p.End(), Style: clip.StrokeStyle{Width: 4, Cap: clip.RoundCap, Join: clip.RoundJoin}}.Op())
+
+	sc := decode(t, &ops)
+	if len(sc.Items) != 2 {
+		t.Fatalf("got %d items, want 2", len(sc.Items))
+	}
+	if got, want := svgPath(sc.Items[0].Clips[0].Path), "M10 10L60 10Q60 50 10 10Z"; got != want {
+		t.Errorf("got fill path %q, want %q", got, want)
+	}
+	stroke := sc.Items[1].Clips[0]
+	if got, want := svgPath(stroke.Path), "M0 90L100 90"; got != want || stroke.Stroke != 4 {
+		t.Errorf("got stroke %q width %v, want %q width 4", got, stroke.Stroke, want)
+	}
+}
+
+func TestDecodeState(t *testing.T) {
+	var ops op.Ops
+	outer := op.Save(&ops)
+	clip.Rect(image.Rect(0, 0, 50, 50)).Add(&ops)
+
+	// A macro, called from a deferred macro.
+	m := op.Record(&ops)
+	paint.ColorOp{Color: red}.Add(&ops)
+	paint.PaintOp{}.Add(&ops)
+	call := m.Stop()
+	m = op.Record(&ops)
+	op.Offset(f32.Pt(5, 5)).Add(&ops)
+	call.Add(&ops)
+	op.Defer(&ops, m.Stop())
+
+	paint.LinearGradientOp{Stop1: f32.Pt(0, 0), Color1: red, Stop2: f32.Pt(10, 0), Color2: blue}.Add(&ops)
+	paint.PaintOp{}.Add(&ops)
+	outer.Load()
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	op.Offset(f32.Pt(60, 60)).Add(&ops)
+	paint.NewImageOp(img).Add(&ops)
+	paint.PaintOp{}.Add(&ops)
+
+	sc := decode(t, &ops)
+	if len(sc.Items) != 3 {
+		t.Fatalf("got %d items, want 3", len(sc.Items))
+	}
+	grad, img2, deferred := sc.Items[0], sc.Items[1], sc.Items[2]
+	if grad.Kind != MaterialGradient || grad.Stop2 != f32.Pt(10, 0) || grad.Color2 != blue || len(grad.Clips) != 1 {
+		t.Errorf("got gradient %+v", grad)
+	}
+	if img2.Kind != MaterialImage || img2.Image != img || len(img2.Clips) != 0 {
+		t.Errorf("got image %+v, want unclipped image", img2)
+	}
+	if got := img2.Transform.Transform(f32.Pt(0, 0)); got != f32.Pt(60, 60) {
+		t.Errorf("got image at %v, want (60,60)", got)
+	}
+	// Deferred macros run last, with the clip and transform of their
+	// definition.
+	if deferred.Kind != MaterialColor || len(deferred.Clips) != 0 {
+		t.Errorf("got deferred item %+v, want unclipped color", deferred)
+	}
+	if got := deferred.Transform.Transform(f32.Pt(0, 0)); got != f32.Pt(5, 5) {
+		t.Errorf("got deferred item at %v, want (5,5)", got)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	var ops op.Ops
+	clip.Rect(image.Rect(0, 0, 50, 50)).Add(&ops)
+	paint.FillShape(&ops, red, clip.Rect(image.Rect(10, 10, 20, 20)).Op())
+	paint.FillShape(&ops, blue, clip.Rect(image.Rect(30, 30, 40, 40)).Op())
+	var buf bytes.Buffer
+	if err := decode(t, &ops).WriteSVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	for _, want := range []string{
+		`<clipPath id="c1"><path d="M0 0L50 0L50 50L0 50Z"/></clipPath>`,
+		`<path d="M10 10L20 10L20 20L10 20Z" fill="#ff0000"/>`,
+		`<path d="M30 30L40 30L40 40L30 40Z" fill="#0000ff" fill-opacity="0.502"/>`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG lacks %s:\n%s", want, svg)
+		}
+	}
+	// The items share the group of their common clip.
+	if n := strings.Count(svg, "<g "); n != 1 {
+		t.Errorf("got %d groups, want 1:\n%s", n, svg)
+	}
+	if strings.Count(svg, "<g ") != strings.Count(svg, "</g>") {
+		t.Errorf("unbalanced groups:\n%s", svg)
+	}
+}
+
+func TestPDF(t *testing.T) {
+	var ops op.Ops
+	paint.FillShape(&ops, blue, clip.Rect(image.Rect(10, 10, 20, 20)).Op())
+	paint.NewImageOp(image.NewRGBA(image.Rect(0, 0, 2, 2))).Add(&ops)
+	paint.PaintOp{}.Add(&ops)
+	var buf bytes.Buffer
+	if err := decode(t, &ops).WritePDF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	pdf := buf.Bytes()
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatalf("not a PDF document:\n%s", pdf)
+	}
+	// Every object must be where the cross-reference table says.
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1)
+	if len(entries) < 5 {
+		t.Fatalf("got %d objects, want at least 5", len(entries))
+	}
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(pdf[off:], []byte(want)) {
+			t.Errorf("object %d not at offset %d", i+1, off)
+		}
+	}
+	for _, want := range []string{"/ExtGState", "/XObject", "/SMask"} {
+		if !bytes.Contains(pdf, []byte(want)) {
+			t.Errorf("PDF lacks %s", want)
+		}
+	}
+}
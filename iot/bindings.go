@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"gioui.org/widget"
+)
+
+// The topics of the dashboard. Switches are set by publishing ON or OFF
+// to their topic followed by /set, and the devices publish their state,
+// retained, to the topic itself.
+const (
+	topicTemp     = "home/livingroom/temperature"
+	topicHumidity = "home/livingroom/humidity"
+	topicLight    = "home/livingroom/light"
+	topicSoil     = "garden/soil/moisture"
+	topicPump     = "garden/pump"
+	topicPower    = "home/power"
+)
+
+// kind is the kind of widget a topic is bound to.
+type kind int
+
+const (
+	gaugeKind kind = iota
+	switchKind
+	chartKind
+)
+
+// historyLen is the number of values kept for charts.
+const historyLen = 120
+
+// binding binds the payloads of a topic to a widget.
+type binding struct {
+	kind  kind
+	title string
+	topic string
+	unit  string
+	// min and max are the range of gauges.
+	min, max float64
+	// format formats values.
+	format string
+
+	// value is the last value received, if has.
+	value float64
+	has   bool
+	// updated is the time of the last value.
+	updated time.Time
+	// history is the values of charts, oldest first.
+	history []float64
+	// sw is the switch of switches. pending is set between publishing a
+	// change and the device confirming it.
+	sw      widget.Bool
+	pending bool
+}
+
+func newBindings() []*binding {
+	return []*binding{
+		{kind: gaugeKind, title: "Living room", topic: topicTemp, unit: "°C", min: 10, max: 35, format: "%.1f"},
+		{kind: gaugeKind, title: "Humidity", topic: topicHumidity, unit: "%", min: 0, max: 100, format: "%.0f"},
+		{kind: gaugeKind, title: "Soil moisture", topic: topicSoil, unit: "%", min: 0, max: 100, format: "%.0f"},
+		{kind: switchKind, title: "Living room light", topic: topicLight},
+		{kind: switchKind, title: "Garden pump", topic: topicPump},
+		{kind: chartKind, title: "Power", topic: topicPower, unit: "W", format: "%.0f"},
+		{kind: chartKind, title: "Living room temperature", topic: topicTemp, unit: "°C", format: "%.1f"},
+	}
+}
+
+// filters returns the topics to subscribe to.
+func filters(bs []*binding) []string {
+	var fs []string
+	seen := make(map[string]bool)
+	for _, b := range bs {
+		if !seen[b.topic] {
+			seen[b.topic] = true
+			fs = append(fs, b.topic)
+		}
+	}
+	return fs
+}
+
+// receive updates the binding with a payload of its topic, reporting
+// whether the payload was understood.
+func (b *binding) receive(payload []byte, now time.Time) bool {
+	if b.kind == switchKind {
+		on, ok := parseSwitch(payload)
+		if !ok {
+			return false
+		}
+		b.sw.Value = on
+		b.pending = false
+		b.value, b.has, b.updated = 0, true, now
+		if on {
+			b.value = 1
+		}
+		return true
+	}
+	v, ok := parseValue(payload)
+	if !ok {
+		return false
+	}
+	b.value, b.has, b.updated = v, true, now
+	if b.kind == chartKind {
+		if len(b.history) == historyLen {
+			copy(b.history, b.history[1:])
+			b.history = b.history[:historyLen-1]
+		}
+		b.history = append(b.history, v)
+	}
+	return true
+}
+
+// parseValue parses a number, either plain or the value field of a JSON
+// object as many devices publish.
+func parseValue(payload []byte) (float64, bool) {
+	s := strings.TrimSpace(string(payload))
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v, true
+	}
+	var obj struct {
+		Value *float64 `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(s), &obj); err != nil || obj.Value == nil {
+		return 0, false
+	}
+	return *obj.Value, true
+}
+
+// parseSwitch parses the state of a switch: ON or OFF, true or false, or
+// 1 or 0.
+func parseSwitch(payload []byte) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(string(payload))) {
+	case "on", "true", "1":
+		return true, true
+	case "off", "false", "0":
+		return false, true
+	}
+	return false, false
+}
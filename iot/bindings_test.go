@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	values := []struct {
+		payload string
+		want    float64
+		ok      bool
+	}{
+		{"21.5", 21.5, true},
+		{" -3 \n", -3, true},
+		{`{"value": 48.2, "unit": "%"}`, 48.2, true},
+		{`{"unit": "%"}`, 0, false},
+		{"warm", 0, false},
+	}
+	for _, test := range values {
+		got, ok := parseValue([]byte(test.payload))
+		if got != test.want || ok != test.ok {
+			t.Errorf("parseValue(%q) = %v, %v, want %v, %v", test.payload, got, ok, test.want, test.ok)
+		}
+	}
+	switches := []struct {
+		payload string
+		want    bool
+		ok      bool
+	}{
+		{"ON", true, true},
+		{"off", false, true},
+		{"true", true, true},
+		{"0", false, true},
+		{"dim", false, false},
+	}
+	for _, test := range switches {
+		got, ok := parseSwitch([]byte(test.payload))
+		if got != test.want || ok != test.ok {
+			t.Errorf("parseSwitch(%q) = %v, %v, want %v, %v", test.payload, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestReceive(t *testing.T) {
+	b := &binding{kind: chartKind}
+	for i := 0; i < historyLen+10; i++ {
+		if !b.receive([]byte("1"), time.Now()) {
+			t.Fatal("payload not understood")
+		}
+	}
+	if len(b.history) != historyLen {
+		t.Errorf("history has %d values, want %d", len(b.history), historyLen)
+	}
+	sw := &binding{kind: switchKind, pending: true}
+	if sw.receive([]byte("dim"), time.Now()) || !sw.pending {
+		t.Error("invalid switch state accepted")
+	}
+	if !sw.receive([]byte("ON"), time.Now()) || !sw.sw.Value || sw.pending {
+		t.Error("switch state not applied")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for failures := 1; failures < 100; failures++ {
+		want := maxBackoff
+		if failures < 10 {
+			if d := minBackoff << (failures - 1); d < want {
+				want = d
+			}
+		}
+		d := backoff(failures, rnd)
+		if d > want || d < want*4/5 {
+			t.Errorf("backoff(%d) = %v, want %v less at most a fifth", failures, d, want)
+		}
+	}
+}
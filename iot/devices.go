@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"gioui.org/example/internal/mqtt"
+)
+
+// demoBroker is the broker started when no broker is given, with
+// simulated devices publishing to it. It can be stopped and started again
+// on the same address to see the dashboard lose and regain its
+// connection.
+type demoBroker struct {
+	addr string
+
+	mu     sync.Mutex
+	broker *mqtt.Broker
+}
+
+func startDemoBroker() (*demoBroker, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	d := &demoBroker{addr: l.Addr().String()}
+	d.serve(l)
+	go runDevices(d.addr)
+	return d, nil
+}
+
+func (d *demoBroker) serve(l net.Listener) {
+	b := new(mqtt.Broker)
+	d.broker = b
+	go b.Serve(l)
+}
+
+// Running reports whether the broker is running.
+func (d *demoBroker) Running() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.broker != nil
+}
+
+// Stop stops the broker, dropping its clients and retained messages.
+func (d *demoBroker) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.broker != nil {
+		d.broker.Close()
+		d.broker = nil
+	}
+}
+
+// Start starts the broker again.
+func (d *demoBroker) Start() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.broker != nil {
+		return nil
+	}
+	l, err := net.Listen("tcp", d.addr)
+	if err != nil {
+		return err
+	}
+	d.serve(l)
+	return nil
+}
+
+// home is the simulated house: a living room with a light and a climate
+// sensor, and a garden with a pump watering the soil.
+type home struct {
+	mu     sync.Mutex
+	rnd    *rand.Rand
+	light  bool
+	pump   bool
+	temp   float64
+	soil   float64
+	ticks  int
+	client *mqtt.Client
+}
+
+// runDevices connects the simulated devices to the broker at addr,
+// reconnecting every second while it is down.
+func runDevices(addr string) {
+	h := &home{rnd: rand.New(rand.NewSource(1)), temp: 21, soil: 35}
+	for {
+		c, err := mqtt.Dial(addr, mqtt.Options{
+			ClientID:  "demo-devices",
+			KeepAlive: 10 * time.Second,
+			OnMessage: h.command,
+		})
+		if err != nil {
+			time.Sleep(time.Second)
+			continue
+		}
+		h.mu.Lock()
+		h.client = c
+		h.mu.Unlock()
+		if c.Subscribe("+/+/set", "+/+/+/set") == nil {
+			h.publishStates()
+			t := time.NewTicker(time.Second)
+		loop:
+			for {
+				select {
+				case <-c.Done():
+					break loop
+				case <-t.C:
+					h.tick()
+				}
+			}
+			t.Stop()
+		}
+		c.Close()
+	}
+}
+
+// command handles a message to a set topic.
+func (h *home) command(m mqtt.Message) {
+	on, ok := parseSwitch(m.Payload)
+	if !ok {
+		return
+	}
+	h.mu.Lock()
+	switch strings.TrimSuffix(m.Topic, "/set") {
+	case topicLight:
+		h.light = on
+	case topicPump:
+		h.pump = on
+	}
+	h.mu.Unlock()
+	h.publishStates()
+}
+
+// publishStates publishes the retained states of the switches.
+func (h *home) publishStates() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.publish(topicLight, onOff(h.light), true)
+	h.publish(topicPump, onOff(h.pump), true)
+}
+
+// tick publishes the readings of a second.
+func (h *home) tick() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ticks++
+	// The room warms up slowly in the light.
+	target := 20.5
+	if h.light {
+		target = 23.5
+	}
+	h.temp += (target-h.temp)*0.02 + h.rnd.NormFloat64()*0.05
+	if h.pump {
+		h.soil += 1.5
+	} else {
+		h.soil -= 0.1
+	}
+	h.soil = math.Max(5, math.Min(h.soil, 95))
+	humidity := 45 + 8*math.Sin(float64(h.ticks)/40) + h.rnd.NormFloat64()
+	power := 180 + 30*h.rnd.Float64()
+	if h.light {
+		power += 60
+	}
+	if h.pump {
+		power += 450
+	}
+	h.publish(topicTemp, fmt.Sprintf("%.2f", h.temp), false)
+	h.publish(topicHumidity, fmt.Sprintf(`{"value": %.1f, "unit": "%%"}`, humidity), false)
+	h.publish(topicSoil, fmt.Sprintf("%.1f", h.soil), false)
+	h.publish(topicPower, fmt.Sprintf("%.0f", power), false)
+}
+
+func (h *home) publish(topic, payload string, retain bool) {
+	h.client.Publish(mqtt.Message{Topic: topic, Payload: []byte(payload), Retained: retain})
+}
+
+func onOff(on bool) string {
+	if on {
+		return "ON"
+	}
+	return "OFF"
+}
@@ -0,0 +1,292 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program is a dashboard for home automation devices publishing to
+// an MQTT broker. The payloads of topics are bound to gauges, switches
+// and charts; toggling a switch publishes ON or OFF to its topic
+// followed by /set. The connection status is shown at the top, and a lost
+// connection is retried with growing waits.
+//
+// Without -broker, the dashboard starts a broker of its own with
+// simulated devices. Stop and start it from the top bar to see the
+// reconnection.
+//
+// Usage:
+//
+//	go run ./iot [-broker localhost:1883]
+//
+// To drive the dashboard from another MQTT client, publish numbers or
+// JSON objects with a value field to
+//
+//	home/livingroom/temperature
+//	home/livingroom/humidity
+//	garden/soil/moisture
+//	home/power
+//
+// and ON or OFF to home/livingroom/light and garden/pump.
+
+import (
+	"flag"
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/mqtt"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+var (
+	brokerFlag = flag.String("broker", "", "address of the MQTT broker, host:port (default a demo broker with simulated devices)")
+	idFlag     = flag.String("id", "gio-dashboard", "client identifier")
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	errorColor     = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	connectedColor = color.NRGBA{R: 0x2e, G: 0x7d, B: 0x32, A: 0xff}
+	waitingColor   = color.NRGBA{R: 0xf9, G: 0xa8, B: 0x25, A: 0xff}
+)
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("IoT Dashboard"),
+			app.Size(unit.Dp(960), unit.Dp(680)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+type App struct {
+	addr     string
+	demo     *demoBroker
+	bindings []*binding
+
+	status statusEvent
+	// client is the connected client, or nil.
+	client *mqtt.Client
+	// err is the last error publishing or running the demo broker.
+	err error
+
+	brokerBtn widget.Clickable
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{addr: *brokerFlag, bindings: newBindings()}
+	if a.addr == "" {
+		d, err := startDemoBroker()
+		if err != nil {
+			return err
+		}
+		a.demo, a.addr = d, d.addr
+	}
+	events := make(chan interface{})
+	stop := make(chan struct{})
+	defer close(stop)
+	go session(a.addr, *idFlag, filters(a.bindings), events, stop)
+	var ops op.Ops
+	for {
+		select {
+		case e := <-events:
+			a.handle(e)
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update()
+				a.Layout(gtx, th)
+				// Refresh the ages of values and the wait to reconnect.
+				op.InvalidateOp{At: gtx.Now.Truncate(time.Second).Add(time.Second)}.Add(gtx.Ops)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+func (a *App) handle(e interface{}) {
+	switch e := e.(type) {
+	case statusEvent:
+		a.status = e
+		a.client = e.client
+		if e.state != connected {
+			// Changes can't be confirmed without a connection.
+			for _, b := range a.bindings {
+				if b.pending {
+					b.pending = false
+					b.sw.Value = b.value == 1
+				}
+			}
+		}
+	case messageEvent:
+		now := time.Now()
+		for _, b := range a.bindings {
+			if b.topic == e.msg.Topic {
+				b.receive(e.msg.Payload, now)
+			}
+		}
+	}
+}
+
+func (a *App) update() {
+	for _, b := range a.bindings {
+		if b.kind != switchKind || !b.sw.Changed() {
+			continue
+		}
+		if a.client == nil {
+			b.sw.Value = !b.sw.Value
+			continue
+		}
+		err := a.client.Publish(mqtt.Message{Topic: b.topic + "/set", Payload: []byte(onOff(b.sw.Value))})
+		if err != nil {
+			a.err = err
+			b.sw.Value = !b.sw.Value
+			continue
+		}
+		a.err = nil
+		b.pending = true
+	}
+	if a.demo != nil && a.brokerBtn.Clicked() {
+		a.err = nil
+		if a.demo.Running() {
+			a.demo.Stop()
+		} else {
+			a.err = a.demo.Start()
+		}
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	var gauges, switches, charts []layout.FlexChild
+	for _, b := range a.bindings {
+		b := b
+		var w layout.Widget
+		var row *[]layout.FlexChild
+		switch b.kind {
+		case gaugeKind:
+			row, w = &gauges, func(gtx C) D { return a.layoutGauge(gtx, th, b) }
+		case switchKind:
+			row, w = &switches, func(gtx C) D { return a.layoutSwitch(gtx, th, b) }
+		case chartKind:
+			row, w = &charts, func(gtx C) D { return a.layoutChart(gtx, th, b) }
+		}
+		*row = append(*row, layout.Flexed(1, func(gtx C) D {
+			return layout.UniformInset(unit.Dp(6)).Layout(gtx, func(gtx C) D {
+				return card(gtx, th, b, w)
+			})
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return a.layoutStatus(gtx, th)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{}.Layout(gtx, gauges...)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{}.Layout(gtx, switches...)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Flex{}.Layout(gtx, charts...)
+		}),
+	)
+}
+
+func (a *App) layoutStatus(gtx C, th *material.Theme) D {
+	s := a.status
+	col := waitingColor
+	var txt string
+	switch s.state {
+	case connecting:
+		txt = "Connecting to " + a.addr + "…"
+	case connected:
+		col = connectedColor
+		txt = "Connected to " + a.addr
+	case waiting:
+		col = errorColor
+		wait := s.retry.Sub(gtx.Now).Round(time.Second)
+		if wait < 0 {
+			wait = 0
+		}
+		txt = fmt.Sprintf("Disconnected: %v. Retrying in %v", s.err, wait)
+	}
+	return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+		children := []layout.FlexChild{
+			layout.Rigid(func(gtx C) D {
+				return dot(gtx, col)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+			layout.Flexed(1, func(gtx C) D {
+				l := material.Body1(th, txt)
+				l.MaxLines = 1
+				return l.Layout(gtx)
+			}),
+		}
+		if a.err != nil {
+			children = append(children, layout.Rigid(func(gtx C) D {
+				l := material.Caption(th, a.err.Error())
+				l.Color = errorColor
+				return layout.Inset{Left: unit.Dp(8), Right: unit.Dp(8)}.Layout(gtx, l.Layout)
+			}))
+		}
+		if a.demo != nil {
+			label := "Start demo broker"
+			if a.demo.Running() {
+				label = "Stop demo broker"
+			}
+			children = append(children, layout.Rigid(material.Button(th, &a.brokerBtn, label).Layout))
+		}
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx, children...)
+	})
+}
+
+func (a *App) layoutGauge(gtx C, th *material.Theme, b *binding) D {
+	return layout.Center.Layout(gtx, func(gtx C) D {
+		return gauge(gtx, th, b, a.client == nil)
+	})
+}
+
+func (a *App) layoutSwitch(gtx C, th *material.Theme, b *binding) D {
+	state := "unknown"
+	switch {
+	case b.pending:
+		state = "switching…"
+	case b.has:
+		state = onOff(b.sw.Value)
+	}
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, material.Body1(th, state).Layout),
+		layout.Rigid(func(gtx C) D {
+			if a.client == nil || !b.has {
+				gtx = gtx.Disabled()
+			}
+			return material.Switch(th, &b.sw).Layout(gtx)
+		}),
+	)
+}
+
+func (a *App) layoutChart(gtx C, th *material.Theme, b *binding) D {
+	return chart(gtx, th, b, a.client == nil)
+}
@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"time"
+
+	"gioui.org/example/internal/mqtt"
+)
+
+// The backoff between reconnection attempts doubles from minBackoff up
+// to maxBackoff.
+const (
+	minBackoff = 500 * time.Millisecond
+	maxBackoff = 30 * time.Second
+)
+
+var errClosedByBroker = errors.New("connection closed by the broker")
+
+// state is the state of the connection to the broker.
+type state int
+
+const (
+	connecting state = iota
+	connected
+	waiting
+)
+
+// statusEvent reports a change of the connection. The client is set
+// while connected, and retry while waiting to reconnect.
+type statusEvent struct {
+	state  state
+	client *mqtt.Client
+	err    error
+	retry  time.Time
+}
+
+// messageEvent is a message received on a subscription.
+type messageEvent struct {
+	msg mqtt.Message
+}
+
+// session keeps a connection to the broker at addr subscribed to
+// filters, reconnecting with backoff when it fails or is lost, until
+// stop is closed. It sends the messages and the changes of the
+// connection to events.
+func session(addr, id string, filters []string, events chan<- interface{}, stop <-chan struct{}) {
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	send := func(e interface{}) bool {
+		select {
+		case events <- e:
+			return true
+		case <-stop:
+			return false
+		}
+	}
+	failures := 0
+	for {
+		if !send(statusEvent{state: connecting}) {
+			return
+		}
+		c, err := mqtt.Dial(addr, mqtt.Options{
+			ClientID:  id,
+			KeepAlive: 10 * time.Second,
+			OnMessage: func(m mqtt.Message) {
+				send(messageEvent{msg: m})
+			},
+		})
+		if err == nil {
+			err = c.Subscribe(filters...)
+			if err != nil {
+				c.Close()
+			}
+		}
+		if err == nil {
+			failures = 0
+			if !send(statusEvent{state: connected, client: c}) {
+				c.Close()
+				return
+			}
+			select {
+			case <-stop:
+				c.Close()
+				return
+			case <-c.Done():
+				err = c.Err()
+				if err == io.EOF {
+					err = errClosedByBroker
+				}
+			}
+		}
+		failures++
+		d := backoff(failures, rnd)
+		if !send(statusEvent{state: waiting, err: err, retry: time.Now().Add(d)}) {
+			return
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(d):
+		}
+	}
+}
+
+// backoff returns the wait before reconnecting after a number of
+// failures in a row. The wait doubles with every failure up to
+// maxBackoff, less a random fifth, so that clients dropped together
+// don't come back together.
+func backoff(failures int, rnd *rand.Rand) time.Duration {
+	d := maxBackoff
+	if failures < 16 {
+		if e := minBackoff << (failures - 1); e < d {
+			d = e
+		}
+	}
+	return d - time.Duration(rnd.Int63n(int64(d/5)+1))
+}
@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+var (
+	trackColor = color.NRGBA{A: 0x20}
+	staleColor = color.NRGBA{A: 0x60}
+)
+
+// card frames the widget of a binding with its title, topic and the age
+// of its value.
+func card(gtx C, th *material.Theme, b *binding, w layout.Widget) D {
+	return widget.Border{Color: color.NRGBA{A: 0x30}, CornerRadius: unit.Dp(4), Width: unit.Dp(1)}.Layout(gtx, func(gtx C) D {
+		return layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			if b.kind == chartKind {
+				gtx.Constraints.Min.Y = gtx.Constraints.Max.Y
+			}
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					return layout.Flex{Alignment: layout.Baseline}.Layout(gtx,
+						layout.Flexed(1, material.Body2(th, b.title).Layout),
+						layout.Rigid(material.Caption(th, age(b, gtx.Now)).Layout),
+					)
+				}),
+				layout.Rigid(func(gtx C) D {
+					l := material.Caption(th, b.topic)
+					l.Color = staleColor
+					return l.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+				layout.Flexed(1, w),
+			)
+		})
+	})
+}
+
+// age describes how long ago the value of a binding was received.
+func age(b *binding, now time.Time) string {
+	if !b.has {
+		return "no data"
+	}
+	d := now.Sub(b.updated)
+	switch {
+	case d < 2*time.Second:
+		return "now"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", int(d/time.Second))
+	default:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	}
+}
+
+func dot(gtx C, col color.NRGBA) D {
+	size := gtx.Px(unit.Dp(10))
+	r := float32(size) / 2
+	paint.FillShape(gtx.Ops, col, clip.UniformRRect(f32.Rectangle{Max: f32.Pt(float32(size), float32(size))}, r).Op(gtx.Ops))
+	return D{Size: image.Pt(size, size)}
+}
+
+// gauge draws the value of a binding on a 270 degree dial, in gray when
+// stale.
+func gauge(gtx C, th *material.Theme, b *binding, stale bool) D {
+	size := gtx.Px(unit.Dp(120))
+	width := float32(size) / 10
+	c := f32.Pt(float32(size)/2, float32(size)/2)
+	r := float32(size)/2 - width/2
+	const start, sweep = 3 * math.Pi / 4, 3 * math.Pi / 2
+	paint.FillShape(gtx.Ops, trackColor, dial(gtx.Ops, c, r, width, start, sweep))
+	col := th.Palette.ContrastBg
+	if stale {
+		col = staleColor
+	}
+	if b.has {
+		t := (b.value - b.min) / (b.max - b.min)
+		t = math.Max(0, math.Min(t, 1))
+		if t > 0 {
+			paint.FillShape(gtx.Ops, col, dial(gtx.Ops, c, r, width, start, sweep*t))
+		}
+	}
+	txt := "–"
+	if b.has {
+		txt = fmt.Sprintf(b.format, b.value) + b.unit
+	}
+	cgtx := gtx
+	cgtx.Constraints = layout.Exact(image.Pt(size, size))
+	layout.Center.Layout(cgtx, func(gtx C) D {
+		l := material.H6(th, txt)
+		if stale {
+			l.Color = staleColor
+		}
+		return l.Layout(gtx)
+	})
+	return D{Size: image.Pt(size, size)}
+}
+
+// dial returns the stroke of an arc of sweep radians from the angle
+// start, clockwise from the positive x axis.
+func dial(ops *op.Ops, c f32.Point, r, width float32, start, sweep float64) clip.Op {
+	// One segment per 3 degrees.
+	n := int(math.Ceil(sweep / (math.Pi / 60)))
+	var p clip.Path
+	p.Begin(ops)
+	for i := 0; i <= n; i++ {
+		s, co := math.Sincos(start + sweep*float64(i)/float64(n))
+		pt := c.Add(f32.Pt(r*float32(co), r*float32(s)))
+		if i == 0 {
+			p.MoveTo(pt)
+		} else {
+			p.LineTo(pt)
+		}
+	}
+	return clip.Stroke{Path: p.End(), Style: clip.StrokeStyle{Width: width, Cap: clip.FlatCap}}.Op()
+}
+
+// chart draws the history of a binding as a line over a filled area,
+// scaled to the range of the values.
+func chart(gtx C, th *material.Theme, b *binding, stale bool) D {
+	size := gtx.Constraints.Max
+	vals := b.history
+	if len(vals) < 2 || size.X <= 0 || size.Y <= 0 {
+		return D{Size: size}
+	}
+	lo, hi := vals[0], vals[0]
+	for _, v := range vals {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	// Leave a margin, and a minimum range for nearly constant values.
+	pad := math.Max((hi-lo)*0.1, math.Max(math.Abs(hi)*0.01, 0.5))
+	lo, hi = lo-pad, hi+pad
+	step := float32(size.X) / float32(historyLen-1)
+	x0 := float32(size.X) - step*float32(len(vals)-1)
+	pt := func(i int) f32.Point {
+		y := float32(size.Y) * float32((hi-vals[i])/(hi-lo))
+		return f32.Pt(x0+float32(i)*step, y)
+	}
+	col := th.Palette.ContrastBg
+	if stale {
+		col = staleColor
+	}
+	var p clip.Path
+	p.Begin(gtx.Ops)
+	p.MoveTo(f32.Pt(x0, float32(size.Y)))
+	for i := range vals {
+		p.LineTo(pt(i))
+	}
+	p.LineTo(layout.FPt(size))
+	p.Close()
+	fill := col
+	fill.A = 0x40
+	paint.FillShape(gtx.Ops, fill, clip.Outline{Path: p.End()}.Op())
+	p.Begin(gtx.Ops)
+	p.MoveTo(pt(0))
+	for i := 1; i < len(vals); i++ {
+		p.LineTo(pt(i))
+	}
+	paint.FillShape(gtx.Ops, col, clip.Stroke{Path: p.End(), Style: clip.StrokeStyle{Width: float32(gtx.Px(unit.Dp(2)))}}.Op())
+
+	// The range at the left and the last value at the right.
+	label := func(dir layout.Direction, txt string) {
+		cgtx := gtx
+		cgtx.Constraints = layout.Exact(size)
+		dir.Layout(cgtx, func(gtx C) D {
+			l := material.Caption(th, txt)
+			l.Color = staleColor
+			return l.Layout(gtx)
+		})
+	}
+	label(layout.NW, fmt.Sprintf(b.format, hi)+b.unit)
+	label(layout.SW, fmt.Sprintf(b.format, lo)+b.unit)
+	label(layout.NE, fmt.Sprintf(b.format, b.value)+b.unit)
+	return D{Size: size}
+}
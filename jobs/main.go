@@ -0,0 +1,276 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates a pool of background jobs feeding the UI. Jobs
+// are simulated file conversions run by a Queue with a fixed number of
+// workers, which report their progress through a channel that the event
+// loop selects on along with the window events. Jobs can be canceled and
+// failed jobs retried. When the queue drains, a system notification
+// summarizes the results.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image/color"
+	"log"
+	"math/rand"
+	"os"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/style"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/notify"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+// workers is the number of jobs running at once.
+const workers = 3
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Jobs"),
+			app.Size(unit.Dp(520), unit.Dp(600)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// convert simulates converting a file: it advances in steps of random
+// duration and fails now and then.
+func convert(ctx context.Context, name string, progress func(float32)) error {
+	const steps = 20
+	failAt := -1
+	if rand.Intn(5) == 0 {
+		failAt = rand.Intn(steps)
+	}
+	for i := 0; i < steps; i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(50+rand.Intn(200)) * time.Millisecond):
+		}
+		if i == failAt {
+			return errors.New("unsupported codec")
+		}
+		progress(float32(i+1) / steps)
+	}
+	return nil
+}
+
+// job is the UI state of a job.
+type job struct {
+	id       int
+	name     string
+	state    State
+	progress float32
+	err      error
+
+	cancel, retry widget.Clickable
+}
+
+type App struct {
+	queue  *Queue
+	jobs   []*job
+	byID   map[int]*job
+	next   int
+	notes  chan<- string
+	status string
+
+	add, clear widget.Clickable
+	list       layout.List
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	notes := make(chan string, 1)
+	go notifier(notes)
+	a := &App{
+		queue: NewQueue(workers, convert),
+		byID:  make(map[int]*job),
+		notes: notes,
+		list:  layout.List{Axis: layout.Vertical},
+	}
+	// Cancel the jobs and wait for the workers on exit.
+	defer a.queue.Close()
+	var ops op.Ops
+	for {
+		select {
+		case u := <-a.queue.Updates():
+			a.apply(u)
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update()
+				layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+					return a.Layout(gtx, th)
+				})
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+// notifier sends system notifications for the messages received.
+func notifier(notes <-chan string) {
+	mgr, err := notify.NewManager()
+	if err != nil {
+		log.Printf("notifications unavailable: %v", err)
+	}
+	for msg := range notes {
+		if mgr == nil {
+			continue
+		}
+		if _, err := mgr.CreateNotification("Conversions finished", msg); err != nil {
+			log.Printf("notification failed: %v", err)
+		}
+	}
+}
+
+// apply applies a job update and notifies when the last job finishes.
+func (a *App) apply(u Update) {
+	j, ok := a.byID[u.ID]
+	if !ok {
+		return
+	}
+	j.state, j.progress, j.err = u.State, u.Progress, u.Err
+	if !u.State.Finished() {
+		return
+	}
+	done, failed := 0, 0
+	for _, j := range a.jobs {
+		switch j.state {
+		case Queued, Running:
+			return
+		case Done:
+			done++
+		case Failed:
+			failed++
+		}
+	}
+	a.status = fmt.Sprintf("%d converted, %d failed.", done, failed)
+	select {
+	case a.notes <- a.status:
+	default:
+	}
+}
+
+func (a *App) update() {
+	for a.add.Clicked() {
+		for i := 0; i < 5; i++ {
+			a.next++
+			name := fmt.Sprintf("clip-%02d.mov → clip-%02d.mp4", a.next, a.next)
+			j := &job{id: a.queue.Add(name), name: name}
+			a.jobs = append(a.jobs, j)
+			a.byID[j.id] = j
+		}
+		a.status = ""
+	}
+	for a.clear.Clicked() {
+		jobs := a.jobs[:0]
+		for _, j := range a.jobs {
+			if j.state.Finished() {
+				delete(a.byID, j.id)
+			} else {
+				jobs = append(jobs, j)
+			}
+		}
+		a.jobs = jobs
+	}
+	for _, j := range a.jobs {
+		for j.cancel.Clicked() {
+			if a.queue.Cancel(j.id) {
+				j.state = Canceled
+			}
+		}
+		for j.retry.Clicked() {
+			if a.queue.Retry(j.id) {
+				j.state, j.progress, j.err = Queued, 0, nil
+				a.status = ""
+			}
+		}
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(material.Button(th, &a.add, "Convert 5 files").Layout),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+				layout.Rigid(style.TextButton(th, &a.clear, "Clear finished")),
+				layout.Flexed(1, func(gtx C) D {
+					return layout.E.Layout(gtx, material.Body2(th, a.status).Layout)
+				}),
+			)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+		layout.Flexed(1, func(gtx C) D {
+			return a.list.Layout(gtx, len(a.jobs), func(gtx C, i int) D {
+				return a.jobs[i].Layout(gtx, th)
+			})
+		}),
+	)
+}
+
+func (j *job) Layout(gtx C, th *material.Theme) D {
+	status := j.state.String()
+	switch j.state {
+	case Running:
+		status = fmt.Sprintf("%.0f%%", j.progress*100)
+	case Failed:
+		status = fmt.Sprintf("Failed: %v", j.err)
+	}
+	return layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(6)}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(material.Body1(th, j.name).Layout),
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx,
+							material.ProgressBar(th, j.progress).Layout)
+					}),
+					layout.Rigid(func(gtx C) D {
+						l := material.Caption(th, status)
+						if j.state == Failed {
+							l.Color = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+						}
+						return l.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+			layout.Rigid(func(gtx C) D {
+				switch j.state {
+				case Queued, Running:
+					return style.TextButton(th, &j.cancel, "Cancel")(gtx)
+				case Failed, Canceled:
+					return style.TextButton(th, &j.retry, "Retry")(gtx)
+				default:
+					return D{}
+				}
+			}),
+		)
+	})
+}
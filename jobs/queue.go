@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// State is the state of a job.
+type State int
+
+const (
+	Queued State = iota
+	Running
+	Done
+	Failed
+	Canceled
+)
+
+func (s State) String() string {
+	switch s {
+	case Queued:
+		return "Queued"
+	case Running:
+		return "Running"
+	case Done:
+		return "Done"
+	case Failed:
+		return "Failed"
+	case Canceled:
+		return "Canceled"
+	default:
+		panic("invalid state")
+	}
+}
+
+// Finished reports whether s is a final state.
+func (s State) Finished() bool {
+	return s == Done || s == Failed || s == Canceled
+}
+
+// Work performs a job, reporting its progress from 0 to 1.
+type Work func(ctx context.Context, name string, progress func(float32)) error
+
+// Update is a change of the state or progress of a job.
+type Update struct {
+	ID       int
+	State    State
+	Progress float32
+	Err      error
+}
+
+// Queue runs jobs on a fixed number of workers. The workers live as long
+// as the queue; Close cancels the running jobs and waits for the workers
+// to exit, so no goroutine outlives the queue.
+type Queue struct {
+	work    Work
+	updates chan Update
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	nextID  int
+	pending []*task
+	tasks   map[int]*task
+}
+
+type task struct {
+	id   int
+	name string
+	// cancel cancels the job while it runs.
+	cancel context.CancelFunc
+}
+
+// NewQueue starts a queue running work on the given number of workers.
+func NewQueue(workers int, work Work) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{
+		work: work,
+		// Buffer the updates so that workers rarely wait for the UI.
+		updates: make(chan Update, 100),
+		ctx:     ctx,
+		cancel:  cancel,
+		tasks:   make(map[int]*task),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.worker()
+	}
+	return q
+}
+
+// Updates returns the channel of job updates. The channel is closed by
+// Close.
+func (q *Queue) Updates() <-chan Update {
+	return q.updates
+}
+
+// Add queues a job and returns its ID. The job is in the Queued state
+// until an update reports otherwise.
+func (q *Queue) Add(name string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.nextID++
+	id := q.nextID
+	q.enqueue(&task{id: id, name: name})
+	return id
+}
+
+// Retry queues a finished job again, and reports whether it did.
+func (q *Queue) Retry(id int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	t, ok := q.tasks[id]
+	if !ok || t.cancel != nil {
+		return false
+	}
+	for _, p := range q.pending {
+		if p == t {
+			return false
+		}
+	}
+	q.enqueue(&task{id: id, name: t.name})
+	return true
+}
+
+func (q *Queue) enqueue(t *task) {
+	q.tasks[t.id] = t
+	q.pending = append(q.pending, t)
+	q.cond.Signal()
+}
+
+// Cancel cancels a queued or running job. It reports whether the job was
+// removed from the queue before it ran, in which case there are no more
+// updates for it; a running job reports its cancellation by an update.
+func (q *Queue) Cancel(id int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	t, ok := q.tasks[id]
+	if !ok {
+		return false
+	}
+	if t.cancel != nil {
+		t.cancel()
+		return false
+	}
+	for i, p := range q.pending {
+		if p == t {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Close cancels all jobs, waits for the workers to exit and closes the
+// update channel.
+func (q *Queue) Close() {
+	q.mu.Lock()
+	q.cancel()
+	q.cond.Broadcast()
+	q.mu.Unlock()
+	q.wg.Wait()
+	close(q.updates)
+}
+
+// next waits for a pending task, or returns nil when the queue is closed.
+func (q *Queue) next() (*task, context.Context) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.pending) == 0 && q.ctx.Err() == nil {
+		q.cond.Wait()
+	}
+	if q.ctx.Err() != nil {
+		return nil, nil
+	}
+	t := q.pending[0]
+	q.pending = q.pending[1:]
+	ctx, cancel := context.WithCancel(q.ctx)
+	t.cancel = cancel
+	return t, ctx
+}
+
+func (q *Queue) worker() {
+	defer q.wg.Done()
+	for {
+		t, ctx := q.next()
+		if t == nil {
+			return
+		}
+		q.send(Update{ID: t.id, State: Running})
+		err := q.work(ctx, t.name, func(p float32) {
+			q.send(Update{ID: t.id, State: Running, Progress: p})
+		})
+		u := Update{ID: t.id, State: Done, Progress: 1}
+		switch {
+		case ctx.Err() != nil:
+			u = Update{ID: t.id, State: Canceled}
+		case err != nil:
+			u = Update{ID: t.id, State: Failed, Err: err}
+		}
+		q.mu.Lock()
+		t.cancel()
+		t.cancel = nil
+		q.mu.Unlock()
+		q.send(u)
+	}
+}
+
+// send delivers an update unless the queue is closed.
+func (q *Queue) send(u Update) {
+	select {
+	case q.updates <- u:
+	case <-q.ctx.Done():
+	}
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// collect returns the final states of n jobs.
+func collect(t *testing.T, q *Queue, n int) map[int]Update {
+	t.Helper()
+	final := make(map[int]Update)
+	for len(final) < n {
+		u := <-q.Updates()
+		if u.State.Finished() {
+			final[u.ID] = u
+		}
+	}
+	return final
+}
+
+func TestQueue(t *testing.T) {
+	q := NewQueue(2, func(ctx context.Context, name string, progress func(float32)) error {
+		progress(0.5)
+		if name == "bad" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	defer q.Close()
+	good := q.Add("good")
+	bad := q.Add("bad")
+	final := collect(t, q, 2)
+	if s := final[good].State; s != Done {
+		t.Errorf("good job: got %v, want Done", s)
+	}
+	if u := final[bad]; u.State != Failed || u.Err == nil {
+		t.Errorf("bad job: got %v (%v), want Failed", u.State, u.Err)
+	}
+	if !q.Retry(bad) {
+		t.Fatal("failed job not retried")
+	}
+	if s := collect(t, q, 1)[bad].State; s != Failed {
+		t.Errorf("retried job: got %v, want Failed", s)
+	}
+}
+
+func TestQueueCancel(t *testing.T) {
+	started := make(chan struct{})
+	q := NewQueue(1, func(ctx context.Context, name string, progress func(float32)) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	defer q.Close()
+	running := q.Add("running")
+	queued := q.Add("queued")
+	<-started
+	if !q.Cancel(queued) {
+		t.Error("queued job not removed")
+	}
+	if q.Cancel(running) {
+		t.Error("running job removed from the queue")
+	}
+	if s := collect(t, q, 1)[running].State; s != Canceled {
+		t.Errorf("canceled job: got %v, want Canceled", s)
+	}
+}
+
+func TestQueueClose(t *testing.T) {
+	q := NewQueue(3, func(ctx context.Context, name string, progress func(float32)) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	for i := 0; i < 5; i++ {
+		q.Add("job")
+	}
+	q.Close()
+	for range q.Updates() {
+	}
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import "strings"
+
+// tokenKind classifies the text of a span of JSON.
+type tokenKind int
+
+const (
+	tokPunct tokenKind = iota
+	tokKey
+	tokString
+	tokNumber
+	tokLiteral
+)
+
+// span is a run of text of a kind.
+type span struct {
+	kind tokenKind
+	text string
+}
+
+// highlight splits a line of JSON into spans for syntax coloring. Keys are
+// strings followed by a colon. Invalid text is returned as punctuation.
+func highlight(line string) []span {
+	var spans []span
+	add := func(k tokenKind, s string) {
+		if n := len(spans); n > 0 && spans[n-1].kind == k && k == tokPunct {
+			spans[n-1].text += s
+			return
+		}
+		spans = append(spans, span{k, s})
+	}
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == '"':
+			j := i + 1
+			for j < len(line) && line[j] != '"' {
+				if line[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j < len(line) {
+				j++
+			} else {
+				j = len(line)
+			}
+			k := tokString
+			if strings.HasPrefix(strings.TrimLeft(line[j:], " \t"), ":") {
+				k = tokKey
+			}
+			add(k, line[i:j])
+			i = j
+		case c == '-' || c >= '0' && c <= '9':
+			j := i + 1
+			for j < len(line) && strings.IndexByte("0123456789.eE+-", line[j]) >= 0 {
+				j++
+			}
+			add(tokNumber, line[i:j])
+			i = j
+		case c >= 'a' && c <= 'z':
+			j := i + 1
+			for j < len(line) && line[j] >= 'a' && line[j] <= 'z' {
+				j++
+			}
+			add(tokLiteral, line[i:j])
+			i = j
+		default:
+			add(tokPunct, line[i:i+1])
+			i++
+		}
+	}
+	return spans
+}
@@ -0,0 +1,491 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program inspects JSON documents. The document, given as argument,
+// is shown as a collapsible tree next to its syntax colored text. Values
+// can be searched for, their JSONPath copied, and both single values and
+// the whole text edited, with syntax errors reported by line and column.
+//
+// Usage:
+//
+//	go run ./jsontree data.json
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/style"
+	"gioui.org/font/gofont"
+	"gioui.org/io/clipboard"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+// sample is shown when no file is given.
+const sample = `{
+  "name": "gio-example",
+  "version": 3,
+  "private": false,
+  "license": null,
+  "authors": [
+    {"name": "Elias Naur", "roles": ["maintainer"]},
+    {"name": "Chris Waldon", "roles": ["maintainer", "x/component"]}
+  ],
+  "dependencies": {
+    "gioui.org": "v0.0.0-20210520085948",
+    "golang.org/x/exp": "v0.0.0-20191002040644"
+  },
+  "tags with spaces": {"it's": [1.5, 2, -3e2]}
+}`
+
+func main() {
+	flag.Parse()
+	data := []byte(sample)
+	if name := flag.Arg(0); name != "" {
+		var err error
+		data, err = ioutil.ReadFile(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("JSON Inspector"),
+			app.Size(unit.Dp(1100), unit.Dp(700)),
+		)
+		if err := loop(w, data); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var (
+	monoFont   = text.Font{Variant: "Mono"}
+	errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	tokColors  = map[tokenKind]color.NRGBA{
+		tokPunct:   {A: 0x99},
+		tokKey:     {R: 0x15, G: 0x65, B: 0xc0, A: 0xff},
+		tokString:  {R: 0x2e, G: 0x7d, B: 0x32, A: 0xff},
+		tokNumber:  {R: 0xe6, G: 0x51, B: 0x00, A: 0xff},
+		tokLiteral: {R: 0x6a, G: 0x1b, B: 0x9a, A: 0xff},
+	}
+)
+
+type App struct {
+	root *Node
+	// rows are the visible nodes of the tree.
+	rows     []*Node
+	clicks   map[*Node]*widget.Clickable
+	selected *Node
+	// lines are the formatted document.
+	lines []string
+	err   string
+
+	search  widget.Editor
+	matches []*Node
+	match   int
+
+	value    widget.Editor
+	valueErr string
+
+	editing bool
+	raw     widget.Editor
+	rawErr  string
+
+	tree, text                            layout.List
+	prev, next, expand, collapse, copyBtn widget.Clickable
+	set, edit, apply, cancel              widget.Clickable
+}
+
+func loop(w *app.Window, data []byte) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		clicks: make(map[*Node]*widget.Clickable),
+		search: widget.Editor{SingleLine: true, Submit: true},
+		value:  widget.Editor{SingleLine: true, Submit: true},
+		tree:   layout.List{Axis: layout.Vertical},
+		text:   layout.List{Axis: layout.Vertical},
+	}
+	root, err := Parse(data)
+	if err != nil {
+		// Open the text for fixing.
+		a.editing = true
+		a.raw.SetText(string(data))
+		a.rawErr = describe(data, err)
+		root = &Node{Kind: Null, Value: "null", Index: -1}
+	}
+	a.setRoot(root)
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			a.update(gtx)
+			a.Layout(gtx, th)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+func (a *App) setRoot(n *Node) {
+	a.root = n
+	a.clicks = make(map[*Node]*widget.Clickable)
+	a.matches = nil
+	a.selectNode(nil)
+	a.changed()
+}
+
+// changed updates the tree rows and text after a change.
+func (a *App) changed() {
+	a.rows = Visible(a.root)
+	a.lines = strings.Split(a.root.Format(), "\n")
+}
+
+func (a *App) selectNode(n *Node) {
+	a.selected = n
+	a.valueErr = ""
+	if n != nil {
+		a.value.SetText(strings.ReplaceAll(n.Format(), "\n", " "))
+	} else {
+		a.value.SetText("")
+	}
+}
+
+// scrollTo reveals n and scrolls the tree to it.
+func (a *App) scrollTo(n *Node) {
+	n.Reveal()
+	a.rows = Visible(a.root)
+	for i, r := range a.rows {
+		if r == n {
+			a.tree.Position = layout.Position{First: i, Offset: 0}
+			if i > 3 {
+				a.tree.Position.First = i - 3
+			}
+			break
+		}
+	}
+	a.selectNode(n)
+}
+
+func (a *App) update(gtx C) {
+	for _, n := range a.rows {
+		if c, ok := a.clicks[n]; ok {
+			for c.Clicked() {
+				if n.Container() {
+					n.Expanded = !n.Expanded
+					a.rows = Visible(a.root)
+				}
+				a.selectNode(n)
+			}
+		}
+	}
+	for _, e := range a.search.Events() {
+		if _, ok := e.(widget.SubmitEvent); ok {
+			a.matches = Search(a.root, strings.TrimSpace(a.search.Text()))
+			a.match = 0
+			if len(a.matches) > 0 {
+				a.scrollTo(a.matches[0])
+			}
+		}
+	}
+	if n := len(a.matches); n > 0 {
+		for a.next.Clicked() {
+			a.match = (a.match + 1) % n
+			a.scrollTo(a.matches[a.match])
+		}
+		for a.prev.Clicked() {
+			a.match = (a.match + n - 1) % n
+			a.scrollTo(a.matches[a.match])
+		}
+	}
+	for a.expand.Clicked() {
+		a.root.SetExpanded(true)
+		a.rows = Visible(a.root)
+	}
+	for a.collapse.Clicked() {
+		a.root.SetExpanded(false)
+		a.root.Expanded = true
+		a.rows = Visible(a.root)
+	}
+	if n := a.selected; n != nil {
+		for a.copyBtn.Clicked() {
+			clipboard.WriteOp{Text: n.Path()}.Add(gtx.Ops)
+		}
+		submit := false
+		for _, e := range a.value.Events() {
+			if _, ok := e.(widget.SubmitEvent); ok {
+				submit = true
+			}
+		}
+		for a.set.Clicked() {
+			submit = true
+		}
+		if submit {
+			data := []byte(a.value.Text())
+			if err := n.Set(data); err != nil {
+				a.valueErr = describe(data, err)
+			} else {
+				a.valueErr = ""
+				a.matches = nil
+				a.changed()
+			}
+		}
+	}
+	for a.edit.Clicked() {
+		a.editing = true
+		a.raw.SetText(a.root.Format())
+		a.rawErr = ""
+	}
+	for _, e := range a.raw.Events() {
+		if _, ok := e.(widget.ChangeEvent); ok {
+			// Validate as the text is typed.
+			data := []byte(a.raw.Text())
+			a.rawErr = ""
+			if _, err := Parse(data); err != nil {
+				a.rawErr = describe(data, err)
+			}
+		}
+	}
+	for a.apply.Clicked() {
+		data := []byte(a.raw.Text())
+		if root, err := Parse(data); err != nil {
+			a.rawErr = describe(data, err)
+		} else {
+			a.editing = false
+			a.setRoot(root)
+		}
+	}
+	for a.cancel.Clicked() {
+		a.editing = false
+		a.rawErr = ""
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return a.layoutToolbar(gtx, th)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Flexed(1, func(gtx C) D {
+					return a.tree.Layout(gtx, len(a.rows), func(gtx C, i int) D {
+						return a.layoutRow(gtx, th, a.rows[i])
+					})
+				}),
+				layout.Rigid(func(gtx C) D {
+					size := image.Pt(gtx.Px(unit.Dp(1)), gtx.Constraints.Max.Y)
+					paint.FillShape(gtx.Ops, color.NRGBA{A: 0x20}, clip.Rect{Max: size}.Op())
+					return D{Size: size}
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+						return a.layoutText(gtx, th)
+					})
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return a.layoutSelection(gtx, th)
+			})
+		}),
+	)
+}
+
+func (a *App) layoutToolbar(gtx C, th *material.Theme) D {
+	count := ""
+	if n := len(a.matches); n > 0 {
+		count = fmt.Sprintf("%d of %d", a.match+1, n)
+	} else if a.search.Text() != "" {
+		count = "No matches"
+	}
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return style.Field(th, &a.search, "Search keys and values (Enter)").Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+		layout.Rigid(material.Body2(th, count).Layout),
+		layout.Rigid(style.TextButton(th, &a.prev, "Previous")),
+		layout.Rigid(style.TextButton(th, &a.next, "Next")),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+		layout.Rigid(style.TextButton(th, &a.expand, "Expand all")),
+		layout.Rigid(style.TextButton(th, &a.collapse, "Collapse all")),
+	)
+}
+
+func (a *App) layoutRow(gtx C, th *material.Theme, n *Node) D {
+	c, ok := a.clicks[n]
+	if !ok {
+		c = new(widget.Clickable)
+		a.clicks[n] = c
+	}
+	current := len(a.matches) > 0 && a.matches[a.match] == n
+	return material.Clickable(gtx, c, func(gtx C) D {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		m := op.Record(gtx.Ops)
+		dims := layout.Inset{
+			Top: unit.Dp(3), Bottom: unit.Dp(3), Right: unit.Dp(8),
+			Left: unit.Dp(float32(8 + 16*n.Depth())),
+		}.Layout(gtx, func(gtx C) D {
+			var spans []span
+			if n.Container() {
+				arrow := "▸ "
+				if n.Expanded {
+					arrow = "▾ "
+				}
+				spans = append(spans, span{tokPunct, arrow})
+			} else {
+				spans = append(spans, span{tokPunct, "  "})
+			}
+			switch {
+			case n.Parent == nil:
+				spans = append(spans, span{tokPunct, "$ "})
+			case n.Parent.Kind == Array:
+				spans = append(spans, span{tokPunct, fmt.Sprintf("%d: ", n.Index)})
+			default:
+				spans = append(spans, span{tokKey, n.Key}, span{tokPunct, ": "})
+			}
+			k := map[Kind]tokenKind{String: tokString, Number: tokNumber, Bool: tokLiteral, Null: tokLiteral}[n.Kind]
+			spans = append(spans, span{k, n.Summary()})
+			return layoutSpans(gtx, th, spans)
+		})
+		call := m.Stop()
+		switch {
+		case n == a.selected:
+			paint.FillShape(gtx.Ops, color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0x30}, clip.Rect{Max: dims.Size}.Op())
+		case current:
+			paint.FillShape(gtx.Ops, color.NRGBA{R: 0xff, G: 0xeb, B: 0x3b, A: 0x60}, clip.Rect{Max: dims.Size}.Op())
+		}
+		call.Add(gtx.Ops)
+		return dims
+	})
+}
+
+func (a *App) layoutText(gtx C, th *material.Theme) D {
+	if !a.editing {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, material.Body1(th, "Document").Layout),
+					layout.Rigid(style.TextButton(th, &a.edit, "Edit")),
+				)
+			}),
+			layout.Flexed(1, func(gtx C) D {
+				return a.text.Layout(gtx, len(a.lines), func(gtx C, i int) D {
+					return layoutSpans(gtx, th, highlight(a.lines[i]))
+				})
+			}),
+		)
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, func(gtx C) D {
+					l := material.Body2(th, "Valid JSON")
+					if a.rawErr != "" {
+						l.Text = a.rawErr
+						l.Color = errorColor
+					}
+					return l.Layout(gtx)
+				}),
+				layout.Rigid(style.TextButton(th, &a.cancel, "Cancel")),
+				layout.Rigid(func(gtx C) D {
+					if a.rawErr != "" {
+						gtx = gtx.Disabled()
+					}
+					return style.TextButton(th, &a.apply, "Apply")(gtx)
+				}),
+			)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			gtx.Constraints.Min = gtx.Constraints.Max
+			return widget.Border{Color: color.NRGBA{A: 0x40}, CornerRadius: unit.Dp(4), Width: unit.Dp(1)}.Layout(gtx, func(gtx C) D {
+				return layout.UniformInset(unit.Dp(6)).Layout(gtx, func(gtx C) D {
+					ed := material.Editor(th, &a.raw, "")
+					ed.Font = monoFont
+					ed.TextSize = unit.Sp(13)
+					return ed.Layout(gtx)
+				})
+			})
+		}),
+	)
+}
+
+func (a *App) layoutSelection(gtx C, th *material.Theme) D {
+	n := a.selected
+	if n == nil {
+		return material.Body2(th, "Select a value to copy its path or edit it.").Layout(gtx)
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, func(gtx C) D {
+					l := material.Body2(th, n.Path())
+					l.Font = monoFont
+					return l.Layout(gtx)
+				}),
+				layout.Rigid(style.TextButton(th, &a.copyBtn, "Copy path")),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, func(gtx C) D {
+					return style.Field(th, &a.value, "Value as JSON").Layout(gtx)
+				}),
+				layout.Rigid(style.TextButton(th, &a.set, "Set")),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			if a.valueErr == "" {
+				return D{}
+			}
+			l := material.Caption(th, a.valueErr)
+			l.Color = errorColor
+			return l.Layout(gtx)
+		}),
+	)
+}
+
+// layoutSpans lays out a line of colored text.
+func layoutSpans(gtx C, th *material.Theme, spans []span) D {
+	children := make([]layout.FlexChild, len(spans))
+	for i, s := range spans {
+		s := s
+		children[i] = layout.Rigid(func(gtx C) D {
+			l := material.Body2(th, s.text)
+			l.Font = monoFont
+			l.Color = tokColors[s.kind]
+			l.MaxLines = 1
+			return l.Layout(gtx)
+		})
+	}
+	return layout.Flex{}.Layout(gtx, children...)
+}
@@ -0,0 +1,307 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Kind is the type of a JSON value.
+type Kind int
+
+const (
+	Object Kind = iota
+	Array
+	String
+	Number
+	Bool
+	Null
+)
+
+// Node is a JSON value in a tree that keeps the order of object members.
+type Node struct {
+	// Key is the member name of a value in an object.
+	Key string
+	// Index is the position of a value in an array, or -1.
+	Index int
+	Kind  Kind
+	// Value is the JSON text of a scalar.
+	Value    string
+	Children []*Node
+	Parent   *Node
+	Expanded bool
+}
+
+// Parse parses a JSON document. Syntax errors are *json.SyntaxError.
+func Parse(data []byte) (*Node, error) {
+	// Validate first for errors with an offset.
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	n, err := parseValue(d)
+	if err != nil {
+		return nil, err
+	}
+	n.Index = -1
+	n.Expanded = true
+	return n, nil
+}
+
+func parseValue(d *json.Decoder) (*Node, error) {
+	tok, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	n := &Node{Index: -1}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			n.Kind = Object
+			for d.More() {
+				k, err := d.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, ok := k.(string)
+				if !ok {
+					return nil, fmt.Errorf("unexpected %v", k)
+				}
+				c, err := parseValue(d)
+				if err != nil {
+					return nil, err
+				}
+				c.Key = key
+				c.Parent = n
+				n.Children = append(n.Children, c)
+			}
+		case '[':
+			n.Kind = Array
+			for d.More() {
+				c, err := parseValue(d)
+				if err != nil {
+					return nil, err
+				}
+				c.Index = len(n.Children)
+				c.Parent = n
+				n.Children = append(n.Children, c)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %v", t)
+		}
+		// Consume the closing delimiter.
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+	case string:
+		n.Kind = String
+		n.Value = quote(t)
+	case json.Number:
+		n.Kind = Number
+		n.Value = string(t)
+	case bool:
+		n.Kind = Bool
+		n.Value = strconv.FormatBool(t)
+	case nil:
+		n.Kind = Null
+		n.Value = "null"
+	}
+	return n, nil
+}
+
+// quote returns s as a JSON string, without escaping HTML.
+func quote(s string) string {
+	var b strings.Builder
+	e := json.NewEncoder(&b)
+	e.SetEscapeHTML(false)
+	e.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Set replaces the value of n by the JSON document data, keeping its
+// place in the tree.
+func (n *Node) Set(data []byte) error {
+	v, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	n.Kind, n.Value, n.Children = v.Kind, v.Value, v.Children
+	for _, c := range n.Children {
+		c.Parent = n
+	}
+	return nil
+}
+
+// Container reports whether n is an object or array.
+func (n *Node) Container() bool {
+	return n.Kind == Object || n.Kind == Array
+}
+
+// Depth returns the number of ancestors of n.
+func (n *Node) Depth() int {
+	d := 0
+	for p := n.Parent; p != nil; p = p.Parent {
+		d++
+	}
+	return d
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Path returns the JSONPath of n from the root.
+func (n *Node) Path() string {
+	if n.Parent == nil {
+		return "$"
+	}
+	p := n.Parent.Path()
+	switch {
+	case n.Parent.Kind == Array:
+		return fmt.Sprintf("%s[%d]", p, n.Index)
+	case identifier.MatchString(n.Key):
+		return p + "." + n.Key
+	default:
+		key := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(n.Key)
+		return p + "['" + key + "']"
+	}
+}
+
+// Summary describes n in a line: the value of a scalar, or the size of a
+// container.
+func (n *Node) Summary() string {
+	switch n.Kind {
+	case Object:
+		return fmt.Sprintf("{%d}", len(n.Children))
+	case Array:
+		return fmt.Sprintf("[%d]", len(n.Children))
+	default:
+		return n.Value
+	}
+}
+
+// SetExpanded expands or collapses n and all its descendants.
+func (n *Node) SetExpanded(expanded bool) {
+	n.Expanded = expanded
+	for _, c := range n.Children {
+		c.SetExpanded(expanded)
+	}
+}
+
+// Reveal expands the ancestors of n.
+func (n *Node) Reveal() {
+	for p := n.Parent; p != nil; p = p.Parent {
+		p.Expanded = true
+	}
+}
+
+// Visible returns the nodes shown in the tree, in order.
+func Visible(root *Node) []*Node {
+	var rows []*Node
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		rows = append(rows, n)
+		if n.Expanded {
+			for _, c := range n.Children {
+				walk(c)
+			}
+		}
+	}
+	walk(root)
+	return rows
+}
+
+// Search returns the nodes whose key or scalar value contains query,
+// ignoring case, in document order.
+func Search(root *Node, query string) []*Node {
+	query = strings.ToLower(query)
+	if query == "" {
+		return nil
+	}
+	var matches []*Node
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		text := n.Value
+		if n.Kind == String {
+			text, _ = strconv.Unquote(text)
+		}
+		if strings.Contains(strings.ToLower(n.Key), query) || strings.Contains(strings.ToLower(text), query) {
+			matches = append(matches, n)
+		}
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	walk(root)
+	return matches
+}
+
+// Format returns n as indented JSON in member order.
+func (n *Node) Format() string {
+	var b strings.Builder
+	n.format(&b, 0)
+	return b.String()
+}
+
+func (n *Node) format(w io.StringWriter, depth int) {
+	if !n.Container() {
+		w.WriteString(n.Value)
+		return
+	}
+	open, close := "{", "}"
+	if n.Kind == Array {
+		open, close = "[", "]"
+	}
+	if len(n.Children) == 0 {
+		w.WriteString(open + close)
+		return
+	}
+	w.WriteString(open + "\n")
+	indent := strings.Repeat("  ", depth+1)
+	for i, c := range n.Children {
+		w.WriteString(indent)
+		if n.Kind == Object {
+			w.WriteString(quote(c.Key) + ": ")
+		}
+		c.format(w, depth+1)
+		if i < len(n.Children)-1 {
+			w.WriteString(",")
+		}
+		w.WriteString("\n")
+	}
+	w.WriteString(strings.Repeat("  ", depth) + close)
+}
+
+// describe formats a parse error of data with its line and column.
+func describe(data []byte, err error) string {
+	var serr *json.SyntaxError
+	off := int64(-1)
+	if errors.As(err, &serr) {
+		off = serr.Offset
+	}
+	var terr *json.UnmarshalTypeError
+	if errors.As(err, &terr) {
+		off = terr.Offset
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		off = int64(len(data))
+	}
+	if off < 0 {
+		return err.Error()
+	}
+	if off > int64(len(data)) {
+		off = int64(len(data))
+	}
+	before := data[:off]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n')
+	return fmt.Sprintf("line %d, column %d: %v", line, col, err)
+}
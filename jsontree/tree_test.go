@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const doc = `{"name": "gopher", "tags": ["a", "b"], "odd key": {"it's": 1.50}, "ok": true, "none": null}`
+
+func TestParseOrder(t *testing.T) {
+	root, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, c := range root.Children {
+		keys = append(keys, c.Key)
+	}
+	if want := []string{"name", "tags", "odd key", "ok", "none"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("got keys %q, want %q", keys, want)
+	}
+	if v := root.Children[2].Children[0].Value; v != "1.50" {
+		t.Errorf("number %q not preserved", v)
+	}
+}
+
+func TestPath(t *testing.T) {
+	root, _ := Parse([]byte(doc))
+	tests := []struct {
+		n    *Node
+		path string
+	}{
+		{root, "$"},
+		{root.Children[0], "$.name"},
+		{root.Children[1].Children[1], "$.tags[1]"},
+		{root.Children[2].Children[0], `$['odd key']['it\'s']`},
+	}
+	for _, test := range tests {
+		if got := test.n.Path(); got != test.path {
+			t.Errorf("got path %s, want %s", got, test.path)
+		}
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	root, _ := Parse([]byte(doc))
+	out := root.Format()
+	var got, want interface{}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("%v:\n%s", err, out)
+	}
+	json.Unmarshal([]byte(doc), &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if !strings.HasPrefix(out, "{\n  \"name\": \"gopher\",\n") {
+		t.Errorf("unexpected format:\n%s", out)
+	}
+}
+
+func TestSearchAndVisible(t *testing.T) {
+	root, _ := Parse([]byte(doc))
+	if n := len(Visible(root)); n != 6 {
+		t.Errorf("got %d visible rows, want 6", n)
+	}
+	matches := Search(root, "B")
+	if len(matches) != 1 || matches[0].Path() != "$.tags[1]" {
+		t.Fatalf("unexpected matches %v", matches)
+	}
+	matches[0].Reveal()
+	if n := len(Visible(root)); n != 8 {
+		t.Errorf("got %d visible rows after reveal, want 8", n)
+	}
+}
+
+func TestSet(t *testing.T) {
+	root, _ := Parse([]byte(doc))
+	n := root.Children[0]
+	if err := n.Set([]byte(`[1, 2]`)); err != nil {
+		t.Fatal(err)
+	}
+	if n.Kind != Array || n.Children[1].Path() != "$.name[1]" {
+		t.Errorf("unexpected node after Set: %+v", n)
+	}
+	if err := n.Set([]byte(`{"a": }`)); err == nil {
+		t.Error("invalid JSON accepted")
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	data := []byte("{\n  \"a\": 1,\n  \"b\": ]\n}")
+	_, err := Parse(data)
+	if err == nil {
+		t.Fatal("invalid JSON accepted")
+	}
+	if got := describe(data, err); !strings.HasPrefix(got, "line 3, column 9:") {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	got := highlight(`  "k\"ey": [-1.5e3, "v", true],`)
+	want := []span{
+		{tokPunct, "  "},
+		{tokKey, `"k\"ey"`},
+		{tokPunct, ": ["},
+		{tokNumber, "-1.5e3"},
+		{tokPunct, ", "},
+		{tokString, `"v"`},
+		{tokPunct, ", "},
+		{tokLiteral, "true"},
+		{tokPunct, "],"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates tunable fling physics and overscroll effects
+// for lists. The list implementation lives in internal/kinetic so that
+// other examples can opt in to it.
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/kinetic"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Kinetic scrolling"),
+			app.Size(unit.Dp(400), unit.Dp(700)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var (
+	list     kinetic.List
+	style    = widget.Enum{Value: kinetic.Glow.String()}
+	preset   = widget.Enum{Value: "android"}
+	friction = widget.Float{Value: kinetic.Android.Friction}
+	spring   = widget.Float{Value: kinetic.Android.Spring}
+
+	rowShade = color.NRGBA{A: 0x10}
+)
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			update()
+			layoutUI(gtx, th)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+// update applies the settings to the list.
+func update() {
+	if preset.Changed() {
+		p := kinetic.Android
+		style.Value = kinetic.Glow.String()
+		if preset.Value == "ios" {
+			p = kinetic.IOS
+			style.Value = kinetic.Bounce.String()
+		}
+		friction.Value = p.Friction
+		spring.Value = p.Spring
+	}
+	for _, o := range []kinetic.Overscroll{kinetic.Clamp, kinetic.Bounce, kinetic.Glow} {
+		if style.Value == o.String() {
+			list.Overscroll = o
+		}
+	}
+	list.Physics = kinetic.Physics{
+		Friction:    friction.Value,
+		Spring:      spring.Value,
+		MaxVelocity: unit.Dp(8000),
+	}
+}
+
+func layoutUI(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return layoutSettings(gtx, th)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return list.Layout(gtx, 200, func(gtx C, i int) D {
+				return layoutRow(gtx, th, i)
+			})
+		}),
+	)
+}
+
+func layoutSettings(gtx C, th *material.Theme) D {
+	row := func(label string, w layout.Widget) layout.FlexChild {
+		return layout.Rigid(func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					gtx.Constraints.Min.X = gtx.Px(unit.Dp(90))
+					return material.Body2(th, label).Layout(gtx)
+				}),
+				layout.Flexed(1, w),
+			)
+		})
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		row("Preset", func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Rigid(material.RadioButton(th, &preset, "android", "Android").Layout),
+				layout.Rigid(material.RadioButton(th, &preset, "ios", "iOS").Layout),
+			)
+		}),
+		row("Overscroll", func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Rigid(material.RadioButton(th, &style, kinetic.Clamp.String(), "Clamp").Layout),
+				layout.Rigid(material.RadioButton(th, &style, kinetic.Bounce.String(), "Bounce").Layout),
+				layout.Rigid(material.RadioButton(th, &style, kinetic.Glow.String(), "Glow").Layout),
+			)
+		}),
+		row(fmt.Sprintf("Friction %.1f", friction.Value), material.Slider(th, &friction, 0.5, 10).Layout),
+		row(fmt.Sprintf("Spring %.1f", spring.Value), material.Slider(th, &spring, 2, 30).Layout),
+	)
+}
+
+func layoutRow(gtx C, th *material.Theme, i int) D {
+	return layout.Stack{}.Layout(gtx,
+		layout.Expanded(func(gtx C) D {
+			if i%2 == 1 {
+				return D{}
+			}
+			sz := gtx.Constraints.Min
+			paint.FillShape(gtx.Ops, rowShade, clip.Rect(image.Rectangle{Max: sz}).Op())
+			return D{Size: sz}
+		}),
+		layout.Stacked(func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			return layout.UniformInset(unit.Dp(16)).Layout(gtx,
+				material.Body1(th, fmt.Sprintf("Row %d", i)).Layout,
+			)
+		}),
+	)
+}
@@ -0,0 +1,271 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"time"
+
+	"gioui.org/example/internal/fakedata"
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// cachingRows is the number of rows of the caching page.
+const cachingRows = 5000
+
+// cachingRow is the content of a row of the caching page.
+type cachingRow struct {
+	name, email string
+	values      []float64
+	amount      float64
+}
+
+var (
+	cacheRows   = &widget.Bool{Value: true}
+	autoScroll  = new(widget.Bool)
+	cachingList = &layout.List{Axis: layout.Vertical}
+	rows        []cachingRow
+	rowCaches   []rowCache
+	// layoutTimes are the times to lay out the list, uncached and
+	// cached.
+	layoutTimes [2]movingAverage
+)
+
+// rowCache is the recorded operations of a row. Rows are drawn by
+// replaying their recording, and only recorded again when the
+// constraints or metric they were recorded for change.
+//
+// A recording replays the operations as they were, so the content must
+// not depend on anything else, such as the time or the state of a
+// widget. Rows with input handlers can still be cached, but their
+// events must be handled before replaying them, as the recording doesn't
+// run the code.
+type rowCache struct {
+	ops   op.Ops
+	call  op.CallOp
+	dims  D
+	valid bool
+	// cs and metric are the constraints and metric of the recording.
+	cs     layout.Constraints
+	metric unit.Metric
+}
+
+// layout replays the recording of w, recording it first if it's missing
+// or out of date.
+func (r *rowCache) layout(gtx C, w layout.Widget) D {
+	if !r.valid || r.cs != gtx.Constraints || r.metric != gtx.Metric {
+		r.ops.Reset()
+		rgtx := gtx
+		rgtx.Ops = &r.ops
+		m := op.Record(&r.ops)
+		r.dims = w(rgtx)
+		r.call = m.Stop()
+		r.cs, r.metric, r.valid = gtx.Constraints, gtx.Metric, true
+	}
+	r.call.Add(gtx.Ops)
+	return r.dims
+}
+
+// movingAverage averages the durations of the latest frames.
+type movingAverage struct {
+	avg time.Duration
+	n   int
+}
+
+func (m *movingAverage) add(d time.Duration) {
+	if m.n == 0 {
+		m.avg = d
+	} else {
+		m.avg += (d - m.avg) / 16
+	}
+	m.n++
+}
+
+func (m movingAverage) String() string {
+	if m.n == 0 {
+		return "–"
+	}
+	return fmt.Sprintf("%d µs", m.avg.Microseconds())
+}
+
+func makeCachingRows() {
+	f := fakedata.New(1)
+	start := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	rows = make([]cachingRow, cachingRows)
+	for i := range rows {
+		name := f.Name()
+		pts := f.TimeSeries(start, time.Hour, 32)
+		values := make([]float64, len(pts))
+		for j, p := range pts {
+			values[j] = p.Value
+		}
+		rows[i] = cachingRow{
+			name:   name,
+			email:  f.Email(name),
+			values: values,
+			amount: f.Float64() * 10000,
+		}
+	}
+	rowCaches = make([]rowCache, cachingRows)
+}
+
+// cachingPage lays out a long list of rows, drawn from recordings of
+// their operations or from scratch every frame, and compares the time
+// to lay out the list either way. Scroll the list, or let it scroll by
+// itself, to see the difference.
+func cachingPage(gtx C, th *material.Theme) D {
+	if rows == nil {
+		makeCachingRows()
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+							layout.Rigid(material.Switch(th, cacheRows).Layout),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+							layout.Rigid(material.Body1(th, "Cache rows").Layout),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(24)}.Layout),
+							layout.Rigid(material.Switch(th, autoScroll).Layout),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+							layout.Rigid(material.Body1(th, "Scroll by itself").Layout),
+						)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+					layout.Rigid(material.Body2(th, layoutTimesText()).Layout),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return layoutCachingList(gtx, th)
+		}),
+	)
+}
+
+func layoutTimesText() string {
+	uncached, cached := layoutTimes[0], layoutTimes[1]
+	s := fmt.Sprintf("Time to lay out the list: %s uncached, %s cached", uncached, cached)
+	if uncached.n > 0 && cached.n > 0 && cached.avg > 0 {
+		s += fmt.Sprintf(", %.1f× faster", float64(uncached.avg)/float64(cached.avg))
+	}
+	return s
+}
+
+func layoutCachingList(gtx C, th *material.Theme) D {
+	if autoScroll.Value {
+		cachingList.Position.Offset += gtx.Px(unit.Dp(3))
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+	cached := cacheRows.Value
+	start := time.Now()
+	dims := cachingList.Layout(gtx, cachingRows, func(gtx C, i int) D {
+		row := func(gtx C) D {
+			return layoutCachingRow(gtx, th, i)
+		}
+		if cached {
+			return rowCaches[i].layout(gtx, row)
+		}
+		return row(gtx)
+	})
+	idx := 0
+	if cached {
+		idx = 1
+	}
+	layoutTimes[idx].add(time.Since(start))
+	if autoScroll.Value && !cachingList.Position.BeforeEnd {
+		// Start over from the top.
+		cachingList.Position = layout.Position{}
+	}
+	return dims
+}
+
+// layoutCachingRow lays out row i: an avatar, the name and address, a
+// chart of the values and the amount.
+func layoutCachingRow(gtx C, th *material.Theme, i int) D {
+	r := rows[i]
+	return layout.Inset{Left: unit.Dp(16), Right: unit.Dp(16), Top: unit.Dp(6), Bottom: unit.Dp(6)}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return layoutAvatar(gtx, th, r.name)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+			layout.Flexed(1, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(material.Body1(th, fmt.Sprintf("%d. %s", i+1, r.name)).Layout),
+					layout.Rigid(material.Caption(th, r.email).Layout),
+				)
+			}),
+			layout.Rigid(func(gtx C) D {
+				return layoutSparkline(gtx, th, r.values)
+			}),
+			layout.Rigid(func(gtx C) D {
+				gtx.Constraints.Min.X = gtx.Px(unit.Dp(96))
+				l := material.Body2(th, fmt.Sprintf("$%.2f", r.amount))
+				l.Alignment = text.End
+				return l.Layout(gtx)
+			}),
+		)
+	})
+}
+
+func layoutAvatar(gtx C, th *material.Theme, name string) D {
+	sz := gtx.Px(unit.Dp(36))
+	var h uint32
+	for _, c := range name {
+		h = h*31 + uint32(c)
+	}
+	bg := color.NRGBA{R: 0x40 + uint8(h), G: 0x40 + uint8(h>>8)%0x80, B: 0x40 + uint8(h>>16)%0x80, A: 0xff}
+	paint.FillShape(gtx.Ops, bg, clip.UniformRRect(f32.Rectangle{Max: layout.FPt(image.Pt(sz, sz))}, float32(sz)/2).Op(gtx.Ops))
+	gtx.Constraints = layout.Exact(image.Pt(sz, sz))
+	return layout.Center.Layout(gtx, func(gtx C) D {
+		l := material.Body1(th, name[:1])
+		l.Color = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+		return l.Layout(gtx)
+	})
+}
+
+// layoutSparkline draws values as a line.
+func layoutSparkline(gtx C, th *material.Theme, values []float64) D {
+	sz := image.Pt(gtx.Px(unit.Dp(96)), gtx.Px(unit.Dp(28)))
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+	if hi == lo {
+		hi = lo + 1
+	}
+	var p clip.Path
+	p.Begin(gtx.Ops)
+	for i, v := range values {
+		pt := f32.Pt(
+			float32(i)*float32(sz.X)/float32(len(values)-1),
+			float32(sz.Y)*float32(1-(v-lo)/(hi-lo)),
+		)
+		if i == 0 {
+			p.MoveTo(pt)
+		} else {
+			p.LineTo(pt)
+		}
+	}
+	paint.FillShape(gtx.Ops, th.Palette.ContrastBg, clip.Stroke{
+		Path:  p.End(),
+		Style: clip.StrokeStyle{Width: float32(gtx.Px(unit.Dp(2)))},
+	}.Op())
+	return D{Size: sz}
+}
@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"testing"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+)
+
+func TestRowCache(t *testing.T) {
+	var cache rowCache
+	recorded := 0
+	w := func(gtx C) D {
+		recorded++
+		return D{Size: gtx.Constraints.Max}
+	}
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(300, 40)),
+		Metric:      unit.Metric{PxPerDp: 1, PxPerSp: 1},
+	}
+	for i := 0; i < 3; i++ {
+		gtx.Ops.Reset()
+		if d := cache.layout(gtx, w); d.Size != image.Pt(300, 40) {
+			t.Fatalf("got size %v", d.Size)
+		}
+	}
+	if recorded != 1 {
+		t.Errorf("recorded %d times for the same constraints, want 1", recorded)
+	}
+	gtx.Constraints = layout.Exact(image.Pt(400, 40))
+	if d := cache.layout(gtx, w); d.Size != image.Pt(400, 40) {
+		t.Errorf("got size %v after resizing", d.Size)
+	}
+	gtx.Metric.PxPerDp = 2
+	cache.layout(gtx, w)
+	if recorded != 3 {
+		t.Errorf("recorded %d times, want again after the constraints and the metric changed", recorded)
+	}
+}
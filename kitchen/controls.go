@@ -0,0 +1,297 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"gioui.org/f32"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// focus tracks the keyboard focus of a control. Clicking the control
+// focuses it, after which it receives key events.
+type focus struct {
+	focused bool
+	request bool
+}
+
+// events returns the key presses delivered to tag, updating the focus
+// state.
+func (f *focus) events(gtx C, tag interface{}) []key.Event {
+	var keys []key.Event
+	for _, e := range gtx.Events(tag) {
+		switch e := e.(type) {
+		case key.FocusEvent:
+			f.focused = e.Focus
+		case key.Event:
+			if e.State == key.Press {
+				keys = append(keys, e)
+			}
+		case pointer.Event:
+			if e.Type == pointer.Press {
+				f.request = true
+			}
+		}
+	}
+	return keys
+}
+
+// add registers tag for key and pointer events over an area of size sz.
+// It must be called after laying out the control, to be above it; pointer
+// events pass through to the control.
+func (f *focus) add(gtx C, tag interface{}, sz image.Point) {
+	defer op.Save(gtx.Ops).Load()
+	pointer.Rect(image.Rectangle{Max: sz}).Add(gtx.Ops)
+	pointer.PassOp{Pass: true}.Add(gtx.Ops)
+	pointer.InputOp{Tag: tag, Types: pointer.Press}.Add(gtx.Ops)
+	key.InputOp{Tag: tag}.Add(gtx.Ops)
+	if f.request {
+		key.FocusOp{Tag: tag}.Add(gtx.Ops)
+		f.request = false
+	}
+}
+
+// ring draws the focus indicator around an area of size sz.
+func (f *focus) ring(gtx C, th *material.Theme, sz image.Point, radius unit.Value) {
+	if !f.focused {
+		return
+	}
+	c := th.Palette.ContrastBg
+	c.A = 0x80
+	widget.Border{Color: c, CornerRadius: radius, Width: unit.Dp(2)}.Layout(gtx, func(gtx C) D {
+		return D{Size: sz}
+	})
+}
+
+// Stepper is a numeric input with decrement and increment buttons. The
+// up and down arrow keys step the value while it is focused.
+type Stepper struct {
+	Value, Min, Max, Step int
+
+	dec, inc widget.Clickable
+	focus    focus
+}
+
+func (s *Stepper) add(n int) {
+	v := s.Value + n*s.Step
+	if v < s.Min {
+		v = s.Min
+	}
+	if v > s.Max {
+		v = s.Max
+	}
+	s.Value = v
+}
+
+// Layout lays out the stepper.
+func (s *Stepper) Layout(gtx C, th *material.Theme, format string) D {
+	for _, e := range s.focus.events(gtx, s) {
+		switch e.Name {
+		case key.NameUpArrow, key.NameRightArrow:
+			s.add(1)
+		case key.NameDownArrow, key.NameLeftArrow:
+			s.add(-1)
+		}
+	}
+	for s.dec.Clicked() {
+		s.add(-1)
+	}
+	for s.inc.Clicked() {
+		s.add(1)
+	}
+	button := func(c *widget.Clickable, label string, enabled bool) layout.FlexChild {
+		return layout.Rigid(func(gtx C) D {
+			if !enabled {
+				gtx = gtx.Disabled()
+			}
+			b := material.Button(th, c, label)
+			b.Inset = layout.UniformInset(unit.Dp(8))
+			return b.Layout(gtx)
+		})
+	}
+	dims := layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		button(&s.dec, "−", s.Value > s.Min),
+		layout.Rigid(func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Px(unit.Dp(64))
+			return layout.Center.Layout(gtx, material.Body1(th, fmt.Sprintf(format, s.Value)).Layout)
+		}),
+		button(&s.inc, "+", s.Value < s.Max),
+	)
+	s.focus.add(gtx, s, dims.Size)
+	s.focus.ring(gtx, th, dims.Size, unit.Dp(4))
+	return dims
+}
+
+// Segmented is an iOS style segmented control: a row of joined segments
+// of which exactly one is selected. The left and right arrow keys move the
+// selection while it is focused.
+type Segmented struct {
+	Options  []string
+	Selected int
+
+	clicks  []widget.Clickable
+	focus   focus
+	changed bool
+}
+
+// Changed reports whether the selection changed in the last layout.
+func (s *Segmented) Changed() bool {
+	return s.changed
+}
+
+// Layout lays out the control.
+func (s *Segmented) Layout(gtx C, th *material.Theme) D {
+	s.changed = false
+	prev := s.Selected
+	for _, e := range s.focus.events(gtx, s) {
+		switch e.Name {
+		case key.NameLeftArrow:
+			if s.Selected > 0 {
+				s.Selected--
+			}
+		case key.NameRightArrow:
+			if s.Selected < len(s.Options)-1 {
+				s.Selected++
+			}
+		}
+	}
+	if len(s.clicks) < len(s.Options) {
+		s.clicks = make([]widget.Clickable, len(s.Options))
+	}
+	for i := range s.Options {
+		for s.clicks[i].Clicked() {
+			s.Selected = i
+		}
+	}
+	s.changed = s.Selected != prev
+
+	radius := unit.Dp(8)
+	rr := float32(gtx.Px(radius))
+	children := make([]layout.FlexChild, len(s.Options))
+	for i := range s.Options {
+		i := i
+		children[i] = layout.Flexed(1, func(gtx C) D {
+			return material.Clickable(gtx, &s.clicks[i], func(gtx C) D {
+				l := material.Body2(th, s.Options[i])
+				selected := i == s.Selected
+				if selected {
+					l.Color = th.Palette.ContrastFg
+				}
+				return layout.Stack{}.Layout(gtx,
+					layout.Expanded(func(gtx C) D {
+						sz := gtx.Constraints.Min
+						if selected {
+							// Only the outer corners of the end segments
+							// are rounded.
+							var sw, se, nw, ne float32
+							if i == 0 {
+								nw, sw = rr, rr
+							}
+							if i == len(s.Options)-1 {
+								ne, se = rr, rr
+							}
+							bounds := f32.Rectangle{Max: layout.FPt(sz)}
+							paint.FillShape(gtx.Ops, th.Palette.ContrastBg, clip.RRect{Rect: bounds, SE: se, SW: sw, NW: nw, NE: ne}.Op(gtx.Ops))
+						}
+						return D{Size: sz}
+					}),
+					layout.Stacked(func(gtx C) D {
+						gtx.Constraints.Min.X = gtx.Constraints.Max.X
+						return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+							return layout.Center.Layout(gtx, l.Layout)
+						})
+					}),
+				)
+			})
+		})
+	}
+	dims := widget.Border{Color: th.Palette.ContrastBg, CornerRadius: radius, Width: unit.Dp(1)}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{}.Layout(gtx, children...)
+	})
+	s.focus.add(gtx, s, dims.Size)
+	s.focus.ring(gtx, th, dims.Size, radius)
+	return dims
+}
+
+// ToggleGroup is a row of toggle buttons of which at most one is on.
+// Clicking the button that is on turns it off. While focused, the left and
+// right arrow keys move the cursor and enter toggles the button under it.
+type ToggleGroup struct {
+	Options []string
+	// Selected is the index of the button that is on, or -1.
+	Selected int
+
+	clicks []widget.Clickable
+	cursor int
+	focus  focus
+}
+
+func (t *ToggleGroup) toggle(i int) {
+	if t.Selected == i {
+		t.Selected = -1
+	} else {
+		t.Selected = i
+	}
+	t.cursor = i
+}
+
+// Layout lays out the group.
+func (t *ToggleGroup) Layout(gtx C, th *material.Theme) D {
+	for _, e := range t.focus.events(gtx, t) {
+		switch e.Name {
+		case key.NameLeftArrow:
+			if t.cursor > 0 {
+				t.cursor--
+			}
+		case key.NameRightArrow:
+			if t.cursor < len(t.Options)-1 {
+				t.cursor++
+			}
+		case key.NameReturn, key.NameEnter:
+			t.toggle(t.cursor)
+		}
+	}
+	if len(t.clicks) < len(t.Options) {
+		t.clicks = make([]widget.Clickable, len(t.Options))
+	}
+	for i := range t.Options {
+		for t.clicks[i].Clicked() {
+			t.toggle(i)
+		}
+	}
+
+	children := make([]layout.FlexChild, len(t.Options))
+	for i := range t.Options {
+		i := i
+		children[i] = layout.Rigid(func(gtx C) D {
+			return layout.Inset{Right: unit.Dp(4)}.Layout(gtx, func(gtx C) D {
+				b := material.Button(th, &t.clicks[i], t.Options[i])
+				if i != t.Selected {
+					b.Background = color.NRGBA{A: 0x18}
+					b.Color = th.Palette.Fg
+				}
+				dims := b.Layout(gtx)
+				if t.focus.focused && i == t.cursor {
+					// Underline the button under the cursor.
+					h := gtx.Px(unit.Dp(2))
+					paint.FillShape(gtx.Ops, th.Palette.ContrastBg, clip.Rect(image.Rect(0, dims.Size.Y-h, dims.Size.X, dims.Size.Y)).Op())
+				}
+				return dims
+			})
+		})
+	}
+	dims := layout.Flex{}.Layout(gtx, children...)
+	t.focus.add(gtx, t, dims.Size)
+	return dims
+}
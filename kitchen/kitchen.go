@@ -3,6 +3,12 @@
 package main
 
 // A Gio program that demonstrates Gio widgets. See https://gioui.org for more information.
+//
+// With -automation, scripts can click the widgets and type into them
+// over JSON-RPC; see package gioui.org/example/internal/automation.
+//
+// The buttons click and submitting the line editor chimes, with the
+// sounds of package gioui.org/example/internal/cue; -mute silences them.
 
 import (
 	"bytes"
@@ -18,6 +24,10 @@ import (
 	"time"
 
 	"gioui.org/app"
+	"gioui.org/example/internal/automation"
+	"gioui.org/example/internal/constraint"
+	"gioui.org/example/internal/cue"
+	"gioui.org/example/internal/flow"
 	"gioui.org/f32"
 	"gioui.org/font/gofont"
 	"gioui.org/gpu/headless"
@@ -38,6 +48,7 @@ import (
 
 var screenshot = flag.String("screenshot", "", "save a screenshot to a file and exit")
 var disable = flag.Bool("disable", false, "disable all widgets")
+var mute = flag.Bool("mute", false, "mute the sounds of the widgets")
 
 type iconAndTextButton struct {
 	theme  *material.Theme
@@ -48,6 +59,7 @@ type iconAndTextButton struct {
 
 func main() {
 	flag.Parse()
+	cue.SetMuted(*mute)
 	editor.SetText(longText)
 	ic, err := widget.NewIcon(icons.ContentAdd)
 	if err != nil {
@@ -70,9 +82,13 @@ func main() {
 		}
 	}()
 
+	registerWidgets()
 	go func() {
 		w := app.NewWindow(app.Size(unit.Dp(800), unit.Dp(700)))
-		if err := loop(w); err != nil {
+		automation.Start(w.Invalidate)
+		err := loop(w)
+		cue.Close()
+		if err != nil {
 			log.Fatal(err)
 		}
 		os.Exit(0)
@@ -80,6 +96,22 @@ func main() {
 	app.Main()
 }
 
+// registerWidgets names the widgets for automation scripts.
+func registerWidgets() {
+	automation.Register("editor", editor)
+	automation.Register("lineEditor", lineEditor)
+	automation.Register("button", button)
+	automation.Register("colorButton", greenButton)
+	automation.Register("iconTextButton", iconTextButton)
+	automation.Register("iconButton", iconButton)
+	automation.Register("flatButton", flatBtn)
+	automation.Register("disableButton", disableBtn)
+	automation.Register("transform", checkbox)
+	automation.Register("switch", swtch)
+	automation.Register("radio", radioButtonsGroup)
+	automation.Register("slider", float)
+}
+
 func saveScreenshot(f string) error {
 	const scale = 1.5
 	sz := image.Point{X: 800 * scale, Y: 600 * scale}
@@ -121,7 +153,7 @@ func loop(w *app.Window) error {
 			case system.DestroyEvent:
 				return e.Err
 			case system.FrameEvent:
-				gtx := layout.NewContext(&ops, e)
+				gtx := automation.Context(layout.NewContext(&ops, e))
 				if *disable {
 					gtx = gtx.Disabled()
 				}
@@ -133,6 +165,7 @@ func loop(w *app.Window) error {
 					}
 				}
 				transformedKitchen(gtx, th)
+				automation.Frame(gtx)
 				e.Frame(gtx.Ops)
 			}
 		case p := <-progressIncrementer:
@@ -166,7 +199,7 @@ func transformedKitchen(gtx layout.Context, th *material.Theme) layout.Dimension
 		op.Affine(tr).Add(gtx.Ops)
 	}
 
-	return kitchen(gtx, th)
+	return layoutPages(gtx, th)
 }
 
 var (
@@ -237,6 +270,11 @@ func (b iconAndTextButton) Layout(gtx layout.Context) layout.Dimensions {
 func kitchen(gtx layout.Context, th *material.Theme) layout.Dimensions {
 	for _, e := range lineEditor.Events() {
 		if e, ok := e.(widget.SubmitEvent); ok {
+			if e.Text == "" {
+				cue.Play(cue.Error)
+			} else {
+				cue.Play(cue.Success)
+			}
 			topLabel = e.Text
 			lineEditor.SetText("")
 		}
@@ -274,51 +312,9 @@ func kitchen(gtx layout.Context, th *material.Theme) layout.Dimensions {
 			}
 		},
 		func(gtx C) D {
-			in := layout.UniformInset(unit.Dp(8))
-			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
-				layout.Rigid(func(gtx C) D {
-					return in.Layout(gtx, material.IconButton(th, iconButton, icon).Layout)
-				}),
-				layout.Rigid(func(gtx C) D {
-					return in.Layout(gtx, iconAndTextButton{theme: th, icon: icon, word: "Icon", button: iconTextButton}.Layout)
-				}),
-				layout.Rigid(func(gtx C) D {
-					return in.Layout(gtx, func(gtx C) D {
-						for button.Clicked() {
-							green = !green
-						}
-						dims := material.Button(th, button, "Click me!").Layout(gtx)
-						pointer.CursorNameOp{Name: pointer.CursorPointer}.Add(gtx.Ops)
-						return dims
-					})
-				}),
-				layout.Rigid(func(gtx C) D {
-					return in.Layout(gtx, func(gtx C) D {
-						l := "Green"
-						if !green {
-							l = "Blue"
-						}
-						btn := material.Button(th, greenButton, l)
-						if green {
-							btn.Background = color.NRGBA{A: 0xff, R: 0x9e, G: 0x9d, B: 0x24}
-						}
-						return btn.Layout(gtx)
-					})
-				}),
-				layout.Rigid(func(gtx C) D {
-					return in.Layout(gtx, func(gtx C) D {
-						return material.Clickable(gtx, flatBtn, func(gtx C) D {
-							return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
-								flatBtnText := material.Body1(th, "Flat")
-								if gtx.Queue == nil {
-									flatBtnText.Color.A = 150
-								}
-								return layout.Center.Layout(gtx, flatBtnText.Layout)
-							})
-						})
-					})
-				}),
-			)
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return layoutButtons(gtx, th)
+			})
 		},
 		material.ProgressBar(th, progress).Layout,
 		func(gtx C) D {
@@ -333,6 +329,9 @@ func kitchen(gtx layout.Context, th *material.Theme) layout.Dimensions {
 				}),
 				layout.Rigid(func(gtx C) D {
 					return layout.Inset{Left: unit.Dp(16)}.Layout(gtx, func(gtx C) D {
+						for disableBtn.Clicked() {
+							cue.Play(cue.Click)
+						}
 						text := "enabled"
 						if !swtch.Value {
 							text = "disabled"
@@ -353,11 +352,15 @@ func kitchen(gtx layout.Context, th *material.Theme) layout.Dimensions {
 			)
 		},
 		func(gtx C) D {
-			return layout.Flex{}.Layout(gtx,
-				layout.Rigid(material.RadioButton(th, radioButtonsGroup, "r1", "RadioButton1").Layout),
-				layout.Rigid(material.RadioButton(th, radioButtonsGroup, "r2", "RadioButton2").Layout),
-				layout.Rigid(material.RadioButton(th, radioButtonsGroup, "r3", "RadioButton3").Layout),
-			)
+			// The flow wraps the buttons on narrow windows.
+			radios := []material.RadioButtonStyle{
+				material.RadioButton(th, radioButtonsGroup, "r1", "RadioButton1"),
+				material.RadioButton(th, radioButtonsGroup, "r2", "RadioButton2"),
+				material.RadioButton(th, radioButtonsGroup, "r3", "RadioButton3"),
+			}
+			return flow.Flow{Spacing: unit.Dp(8)}.Layout(gtx, len(radios), func(gtx C, i int) D {
+				return radios[i].Layout(gtx)
+			})
 		},
 		func(gtx C) D {
 			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
@@ -431,3 +434,58 @@ esteemed his fine skill and ability in teaching others the principles
 of philosophy as the least of his endowments. It was from him that I
 learned how to receive from friends what are thought favours without
 seeming humbled by the giver or insensible to the gift.`
+
+// layoutButtons lays out the row of buttons with a constraint layout: the
+// icon button is pinned to the top left corner, every other button follows
+// the trailing edge of its predecessor and is centered vertically on the
+// icon button.
+func layoutButtons(gtx C, th *material.Theme) D {
+	for _, b := range []*widget.Clickable{iconButton, iconTextButton, greenButton, flatBtn} {
+		for b.Clicked() {
+			cue.Play(cue.Click)
+		}
+	}
+	var l constraint.Layout
+	iconBtn := l.Add(material.IconButton(th, iconButton, icon).Layout)
+	textBtn := l.Add(iconAndTextButton{theme: th, icon: icon, word: "Icon", button: iconTextButton}.Layout)
+	clickBtn := l.Add(func(gtx C) D {
+		for button.Clicked() {
+			cue.Play(cue.Click)
+			green = !green
+		}
+		dims := material.Button(th, button, "Click me!").Layout(gtx)
+		pointer.CursorNameOp{Name: pointer.CursorPointer}.Add(gtx.Ops)
+		return dims
+	})
+	colorBtn := l.Add(func(gtx C) D {
+		txt := "Green"
+		if !green {
+			txt = "Blue"
+		}
+		btn := material.Button(th, greenButton, txt)
+		if green {
+			btn.Background = color.NRGBA{A: 0xff, R: 0x9e, G: 0x9d, B: 0x24}
+		}
+		return btn.Layout(gtx)
+	})
+	flat := l.Add(func(gtx C) D {
+		return material.Clickable(gtx, flatBtn, func(gtx C) D {
+			return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+				flatBtnText := material.Body1(th, "Flat")
+				if gtx.Queue == nil {
+					flatBtnText.Color.A = 150
+				}
+				return layout.Center.Layout(gtx, flatBtnText.Layout)
+			})
+		})
+	})
+
+	gap := unit.Dp(16)
+	prev := iconBtn
+	for _, it := range []*constraint.Item{textBtn, clickBtn, colorBtn, flat} {
+		l.Align(it.Left(), prev.Right(), gap)
+		l.Align(it.CenterY(), iconBtn.CenterY(), unit.Value{})
+		prev = it
+	}
+	return l.Layout(gtx)
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+// page is a page of the kitchen, selected with the tabs at the top.
+type page struct {
+	name   string
+	layout func(gtx C, th *material.Theme) D
+}
+
+var pages = []page{
+	{"Widgets", kitchen},
+	{"Controls", controlsPage},
+	{"Caching", cachingPage},
+}
+
+var pageTabs = &Segmented{}
+
+// layoutPages lays out the page tabs above the selected page.
+func layoutPages(gtx C, th *material.Theme) D {
+	if len(pageTabs.Options) == 0 {
+		for _, p := range pages {
+			pageTabs.Options = append(pageTabs.Options, p.name)
+		}
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				gtx.Constraints.Max.X = gtx.Px(unit.Dp(360))
+				return pageTabs.Layout(gtx, th)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return pages[pageTabs.Selected].layout(gtx, th)
+		}),
+	)
+}
+
+var (
+	quantity = &Stepper{Value: 1, Min: 0, Max: 99, Step: 1}
+	fontSize = &Stepper{Value: 16, Min: 8, Max: 72, Step: 2}
+	period   = &Segmented{Options: []string{"Day", "Week", "Month", "Year"}, Selected: 1}
+	align    = &ToggleGroup{Options: []string{"Left", "Center", "Right", "Justify"}, Selected: 0}
+)
+
+// controlsPage demonstrates the steppers, segmented controls and toggle
+// groups of controls.go. Click a control to focus it for keyboard input.
+func controlsPage(gtx C, th *material.Theme) D {
+	alignment := "none"
+	if align.Selected >= 0 {
+		alignment = align.Options[align.Selected]
+	}
+	row := func(label string, w layout.Widget) layout.FlexChild {
+		return layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(material.Caption(th, label).Layout),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+					layout.Rigid(w),
+				)
+			})
+		})
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		row("Stepper", func(gtx C) D {
+			return quantity.Layout(gtx, th, "%d items")
+		}),
+		row("Stepper with a step of 2", func(gtx C) D {
+			return fontSize.Layout(gtx, th, "%d pt")
+		}),
+		row("Segmented control", func(gtx C) D {
+			gtx.Constraints.Max.X = gtx.Px(unit.Dp(360))
+			return period.Layout(gtx, th)
+		}),
+		row("Toggle group", func(gtx C) D {
+			return align.Layout(gtx, th)
+		}),
+		row("Result", material.Body1(th, fmt.Sprintf("%d items, %d pt, per %s, alignment %s",
+			quantity.Value, fontSize.Value, period.Options[period.Selected], alignment)).Layout),
+	)
+}
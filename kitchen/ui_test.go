@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"errors"
+	"image"
+	"math"
+	"testing"
+	"time"
+
+	"gioui.org/example/internal/cue"
+	"gioui.org/example/internal/uitest"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+func newDriver(t *testing.T) (*uitest.Driver, *material.Theme) {
+	th := material.NewTheme(gofont.Collection())
+	resetState()
+	cue.SetMuted(true)
+	d := uitest.New(image.Pt(800, 400), func(gtx layout.Context) {
+		transformedKitchen(gtx, th)
+	})
+	t.Cleanup(d.Close)
+	return d, th
+}
+
+// resetState restores the widgets to their initial values, for the
+// tests not to depend on the order they run in. The editor is left
+// empty, without the text main sets.
+func resetState() {
+	editor = new(widget.Editor)
+	lineEditor = &widget.Editor{SingleLine: true, Submit: true}
+	button = new(widget.Clickable)
+	greenButton = new(widget.Clickable)
+	iconTextButton = new(widget.Clickable)
+	iconButton = new(widget.Clickable)
+	flatBtn = new(widget.Clickable)
+	disableBtn = new(widget.Clickable)
+	radioButtonsGroup = new(widget.Enum)
+	list = &layout.List{Axis: layout.Vertical}
+	progress = 0
+	green = true
+	topLabel = "Hello, Gio"
+	checkbox = new(widget.Bool)
+	swtch = new(widget.Bool)
+	transformTime = time.Time{}
+	float = new(widget.Float)
+	pageTabs.Selected = 0
+	quantity = &Stepper{Value: 1, Min: 0, Max: 99, Step: 1}
+	fontSize = &Stepper{Value: 16, Min: 8, Max: 72, Step: 2}
+	period = &Segmented{Options: []string{"Day", "Week", "Month", "Year"}, Selected: 1}
+	align = &ToggleGroup{Options: []string{"Left", "Center", "Right", "Justify"}, Selected: 0}
+}
+
+func TestButton(t *testing.T) {
+	d, th := newDriver(t)
+	green = true
+	if err := d.Click(button); err != nil {
+		t.Fatal(err)
+	}
+	if green {
+		t.Fatal("clicking the button didn't toggle the color")
+	}
+	img, err := d.Render()
+	if errors.Is(err, uitest.ErrNoGPU) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, ok := d.Locate(greenButton)
+	if !ok {
+		t.Fatal("color button not found")
+	}
+	// Sample the background left of the label.
+	p := image.Pt(r.Min.X+4, (r.Min.Y+r.Max.Y)/2)
+	if c := img.At(p.X, p.Y); !uitest.Near(c, th.Palette.ContrastBg, 8) {
+		t.Errorf("color button is %v, want %v", c, th.Palette.ContrastBg)
+	}
+}
+
+func TestEditor(t *testing.T) {
+	d, _ := newDriver(t)
+	if err := d.Click(lineEditor); err != nil {
+		t.Fatal(err)
+	}
+	d.Type("Hello, test")
+	d.Press(key.NameReturn, 0)
+	if topLabel != "Hello, test" {
+		t.Errorf("label is %q after submitting the editor", topLabel)
+	}
+	if txt := lineEditor.Text(); txt != "" {
+		t.Errorf("editor has %q after submitting, want it cleared", txt)
+	}
+
+	editor.SetText("some text")
+	if err := d.Click(editor); err != nil {
+		t.Fatal(err)
+	}
+	d.Press("A", key.ModShortcut)
+	d.Press("C", key.ModShortcut)
+	if d.Clipboard != "some text" {
+		t.Errorf("copied %q, want the text of the editor", d.Clipboard)
+	}
+}
+
+func TestList(t *testing.T) {
+	d, _ := newDriver(t)
+	if _, ok := d.Locate(float); ok {
+		t.Fatal("slider visible before scrolling")
+	}
+	r, ok := d.Locate(list)
+	if !ok {
+		t.Fatal("list not found")
+	}
+	// Scroll in the left margin, outside the editor scrolling on its
+	// own.
+	d.Scroll(image.Pt(r.Min.X+4, (r.Min.Y+r.Max.Y)/2), f32.Pt(0, 2000))
+	if list.Position.First == 0 {
+		t.Fatal("scrolling didn't move the list")
+	}
+	r, ok = d.Locate(float)
+	if !ok {
+		t.Fatal("slider not visible at the end of the list")
+	}
+	d.ClickAt(image.Pt((r.Min.X+r.Max.X)/2, (r.Min.Y+r.Max.Y)/2))
+	if v := float64(float.Value); math.Abs(v-math.Pi) > 0.3 {
+		t.Errorf("slider is %.2f after clicking its middle, want about π", v)
+	}
+}
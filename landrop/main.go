@@ -0,0 +1,784 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program sends files to other instances of itself on the local
+// network. Instances find each other with multicast DNS and are shown
+// as avatars; drag a file onto one to send it. The receiver accepts or
+// declines, and both ends show the progress.
+//
+// Gio has no API for files dropped onto the window from other programs,
+// so files to send are given on the command line, or by typing or
+// pasting their paths; file managers copy files as their paths or
+// file:// URLs.
+//
+// Where multicast is blocked, name the peers with -peer and a fixed
+// -port.
+//
+// Usage:
+//
+//	go run ./landrop [-name Ada] [-dir ~/Downloads] [-port 0] [-peer host:port,...] [files...]
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/bytesize"
+	"gioui.org/example/internal/style"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/pointer"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+var (
+	nameFlag = flag.String("name", defaultName(), "name shown to the peers")
+	dirFlag  = flag.String("dir", defaultDir(), "folder to save the files received to")
+	portFlag = flag.Int("port", 0, "port to receive files on (default any free port)")
+	peerFlag = flag.String("peer", "", "comma separated addresses of peers to show without discovery")
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	chipColor  = color.NRGBA{R: 0xee, G: 0xee, B: 0xee, A: 0xff}
+	white      = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+)
+
+// refreshInterval is the time between redraws while files are sent or
+// received.
+const refreshInterval = 100 * time.Millisecond
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("LAN Drop"),
+			app.Size(unit.Dp(640), unit.Dp(620)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+func defaultName() string {
+	if h, err := os.Hostname(); err == nil {
+		return strings.TrimSuffix(h, ".local")
+	}
+	return "Gopher"
+}
+
+func defaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return os.TempDir()
+	}
+	return filepath.Join(home, "Downloads")
+}
+
+// file is a file to send.
+type file struct {
+	path string
+	name string
+	size int64
+
+	remove widget.Clickable
+}
+
+// item is a transfer in the list.
+type item struct {
+	*Transfer
+	// peer is the ID of the peer a file is sent to.
+	peer string
+
+	accept, decline, cancel, remove widget.Clickable
+}
+
+// discoveryError reports discovery failing.
+type discoveryError struct {
+	err error
+}
+
+type App struct {
+	w     *app.Window
+	self  Peer
+	peers map[string]Peer
+	// discoverErr is the error of discovery, if it failed.
+	discoverErr error
+
+	files []*file
+	items []*item
+	err   string
+
+	path widget.Editor
+	add  widget.Clickable
+	list layout.List
+
+	// fileRects and peerRects are the areas of the files and peers in
+	// the window, from the last frame, for dragging files onto peers.
+	fileRects []image.Rectangle
+	peerRects map[string]image.Rectangle
+	drag      fileDrag
+}
+
+// fileDrag is the state of a file dragged onto a peer.
+type fileDrag struct {
+	// file is the file pressed, or nil.
+	file *file
+	// dragging reports whether the pointer moved far enough from the
+	// press to drag.
+	dragging bool
+	// start and pos are the press and current position, and grab the
+	// position in the file chip pressed.
+	start, pos, grab f32.Point
+	// target is the ID of the peer under the pointer.
+	target string
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	name := *nameFlag
+	if r := []rune(name); len(r) > 40 {
+		name = string(r[:40])
+	}
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return err
+	}
+	a := &App{
+		w:         w,
+		self:      Peer{ID: hex.EncodeToString(id[:]), Name: name},
+		peers:     make(map[string]Peer),
+		peerRects: make(map[string]image.Rectangle),
+		path:      widget.Editor{SingleLine: true, Submit: true},
+		list:      layout.List{Axis: layout.Vertical},
+	}
+	for _, addr := range strings.Split(*peerFlag, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			a.peers[addr] = Peer{ID: addr, Name: addr, Addr: addr}
+		}
+	}
+	if err := a.addFiles(strings.Join(flag.Args(), "\n")); err != nil {
+		a.err = err.Error()
+	}
+	if err := os.MkdirAll(*dirFlag, 0755); err != nil {
+		return err
+	}
+	l, err := net.Listen("tcp", ":"+strconv.Itoa(*portFlag))
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	incoming := make(chan *Transfer)
+	go newReceiver(l, *dirFlag, incoming, w.Invalidate).Serve()
+
+	events := make(chan interface{})
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		if err := discover(a.self, l.Addr().(*net.TCPAddr).Port, events, stop); err != nil {
+			select {
+			case events <- discoveryError{err}:
+			case <-stop:
+			}
+		}
+	}()
+	defer func() {
+		// Wait for the goodbye to the peers.
+		close(stop)
+		<-stopped
+	}()
+
+	var ops op.Ops
+	for {
+		select {
+		case t := <-incoming:
+			a.items = append([]*item{{Transfer: t}}, a.items...)
+			w.Invalidate()
+		case e := <-events:
+			a.handle(e)
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				for _, it := range a.items {
+					it.Cancel()
+				}
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update(gtx)
+				a.Layout(gtx, th)
+				for _, it := range a.items {
+					if it.Progress().Status == Active {
+						op.InvalidateOp{At: gtx.Now.Add(refreshInterval)}.Add(gtx.Ops)
+						break
+					}
+				}
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+func (a *App) handle(e interface{}) {
+	switch e := e.(type) {
+	case peerEvent:
+		if e.Gone {
+			delete(a.peers, e.Peer.ID)
+		} else {
+			a.peers[e.Peer.ID] = e.Peer
+		}
+	case discoveryError:
+		a.discoverErr = e.err
+	}
+}
+
+// addFiles adds the files of paths, one per line, as typed or pasted from
+// a file manager. The path field is a single line, so several file://
+// URLs pasted into it are separated by spaces instead.
+func (a *App) addFiles(paths string) error {
+	paths = strings.ReplaceAll(paths, " file://", "\nfile://")
+	for _, p := range strings.Split(paths, "\n") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if strings.HasPrefix(p, "file://") {
+			u, err := url.Parse(p)
+			if err != nil {
+				return err
+			}
+			p = filepath.FromSlash(u.Path)
+		}
+		st, err := os.Stat(p)
+		if err != nil {
+			return err
+		}
+		if !st.Mode().IsRegular() {
+			return fmt.Errorf("%s is not a file", p)
+		}
+		if abs, err := filepath.Abs(p); err == nil {
+			p = abs
+		}
+		dup := false
+		for _, f := range a.files {
+			dup = dup || f.path == p
+		}
+		if !dup {
+			a.files = append(a.files, &file{path: p, name: filepath.Base(p), size: st.Size()})
+		}
+	}
+	return nil
+}
+
+func (a *App) update(gtx C) {
+	submitted := false
+	for _, e := range a.path.Events() {
+		if _, ok := e.(widget.SubmitEvent); ok {
+			submitted = true
+		}
+	}
+	for a.add.Clicked() {
+		submitted = true
+	}
+	if submitted {
+		if err := a.addFiles(a.path.Text()); err != nil {
+			a.err = err.Error()
+		} else {
+			a.err = ""
+			a.path.SetText("")
+		}
+	}
+	for i := 0; i < len(a.files); i++ {
+		if a.files[i].remove.Clicked() {
+			a.files = append(a.files[:i], a.files[i+1:]...)
+			i--
+		}
+	}
+	for i := 0; i < len(a.items); i++ {
+		it := a.items[i]
+		for it.accept.Clicked() {
+			it.Answer(true)
+		}
+		for it.decline.Clicked() {
+			it.Answer(false)
+		}
+		for it.cancel.Clicked() {
+			it.Cancel()
+		}
+		if it.remove.Clicked() {
+			a.items = append(a.items[:i], a.items[i+1:]...)
+			i--
+		}
+	}
+	a.updateDrag(gtx)
+}
+
+// updateDrag follows a file chip dragged across the window, sending the
+// file to the peer it is released on.
+func (a *App) updateDrag(gtx C) {
+	d := &a.drag
+	for _, e := range gtx.Events(d) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case pointer.Press:
+			*d = fileDrag{}
+			for i, r := range a.fileRects {
+				if i < len(a.files) && e.Position.In(layout.FRect(r)) {
+					d.file = a.files[i]
+					d.start, d.pos = e.Position, e.Position
+					d.grab = e.Position.Sub(layout.FPt(r.Min))
+				}
+			}
+		case pointer.Drag:
+			if d.file == nil {
+				continue
+			}
+			d.pos = e.Position
+			if !d.dragging {
+				delta := d.pos.Sub(d.start)
+				slop := float32(gtx.Px(unit.Dp(4)))
+				d.dragging = delta.X*delta.X+delta.Y*delta.Y > slop*slop
+			}
+			d.target = ""
+			for id, r := range a.peerRects {
+				if d.pos.In(layout.FRect(r)) {
+					d.target = id
+				}
+			}
+		case pointer.Release:
+			if p, ok := a.peers[d.target]; ok && d.dragging {
+				a.send(p, d.file)
+			}
+			*d = fileDrag{}
+		case pointer.Cancel:
+			*d = fileDrag{}
+		}
+	}
+}
+
+func (a *App) send(p Peer, f *file) {
+	t, err := send(p.Addr, p.Name, a.self.Name, f.path, a.w.Invalidate)
+	if err != nil {
+		a.err = fmt.Sprintf("Sending %s to %s: %v", f.name, p.Name, err)
+		return
+	}
+	a.err = ""
+	a.items = append([]*item{{Transfer: t, peer: p.ID}}, a.items...)
+}
+
+// sortedPeers returns the peers by name.
+func (a *App) sortedPeers() []Peer {
+	var peers []Peer
+	for _, p := range a.peers {
+		peers = append(peers, p)
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		if peers[i].Name != peers[j].Name {
+			return peers[i].Name < peers[j].Name
+		}
+		return peers[i].ID < peers[j].ID
+	})
+	return peers
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	size := gtx.Constraints.Max
+	paint.Fill(gtx.Ops, th.Palette.Bg)
+	// The sections are placed one below the other by hand, to know where
+	// the files and peers are in the window.
+	inset := gtx.Px(unit.Dp(16))
+	y := inset
+	place := func(w func(gtx C, origin image.Point) D) {
+		stack := op.Save(gtx.Ops)
+		origin := image.Pt(inset, y)
+		op.Offset(layout.FPt(origin)).Add(gtx.Ops)
+		cgtx := gtx
+		cgtx.Constraints.Min = image.Point{}
+		cgtx.Constraints.Max.X = size.X - 2*inset
+		cgtx.Constraints.Max.Y = size.Y - y - inset
+		if cgtx.Constraints.Max.Y < 0 {
+			cgtx.Constraints.Max.Y = 0
+		}
+		y += w(cgtx, origin).Size.Y
+		stack.Load()
+	}
+	gap := func(v unit.Value) {
+		y += gtx.Px(v)
+	}
+	place(func(gtx C, _ image.Point) D {
+		return a.layoutStatus(gtx, th)
+	})
+	gap(unit.Dp(16))
+	place(func(gtx C, _ image.Point) D {
+		return material.H6(th, "Nearby").Layout(gtx)
+	})
+	gap(unit.Dp(8))
+	place(func(gtx C, origin image.Point) D {
+		return a.layoutPeers(gtx, th, origin)
+	})
+	gap(unit.Dp(16))
+	place(func(gtx C, _ image.Point) D {
+		return material.H6(th, "Files").Layout(gtx)
+	})
+	gap(unit.Dp(8))
+	place(func(gtx C, _ image.Point) D {
+		return a.layoutAdd(gtx, th)
+	})
+	gap(unit.Dp(8))
+	place(func(gtx C, origin image.Point) D {
+		return a.layoutFiles(gtx, th, origin)
+	})
+	gap(unit.Dp(16))
+	place(func(gtx C, _ image.Point) D {
+		return material.H6(th, "Transfers").Layout(gtx)
+	})
+	gap(unit.Dp(4))
+	place(func(gtx C, _ image.Point) D {
+		if len(a.items) == 0 {
+			l := material.Body2(th, "No files sent or received yet.")
+			l.Color = color.NRGBA{A: 0x80}
+			return l.Layout(gtx)
+		}
+		gtx.Constraints.Min = gtx.Constraints.Max
+		return a.list.Layout(gtx, len(a.items), func(gtx C, i int) D {
+			return a.items[i].Layout(gtx, th)
+		})
+	})
+
+	// Follow presses on top of everything, passing them on to the
+	// widgets below.
+	stack := op.Save(gtx.Ops)
+	pointer.PassOp{Pass: true}.Add(gtx.Ops)
+	pointer.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	pointer.InputOp{Tag: &a.drag, Types: pointer.Press | pointer.Drag | pointer.Release | pointer.Cancel}.Add(gtx.Ops)
+	stack.Load()
+
+	if d := a.drag; d.dragging {
+		stack := op.Save(gtx.Ops)
+		op.Offset(d.pos.Sub(d.grab)).Add(gtx.Ops)
+		cgtx := gtx
+		cgtx.Constraints.Min = image.Point{}
+		layoutChip(cgtx, th, d.file, nil)
+		stack.Load()
+	}
+	return D{Size: size}
+}
+
+func (a *App) layoutStatus(gtx C, th *material.Theme) D {
+	if a.discoverErr != nil {
+		l := material.Body2(th, fmt.Sprintf("Can't find peers on the network: %v. Name them with -peer.", a.discoverErr))
+		l.Color = errorColor
+		return l.Layout(gtx)
+	}
+	return material.Body2(th, fmt.Sprintf("Visible as %s on the local network · Saving to %s", a.self.Name, *dirFlag)).Layout(gtx)
+}
+
+// layoutPeers lays out the avatars of the peers in a row, recording their
+// areas in the window from the origin of the row.
+func (a *App) layoutPeers(gtx C, th *material.Theme, origin image.Point) D {
+	for id := range a.peerRects {
+		delete(a.peerRects, id)
+	}
+	peers := a.sortedPeers()
+	if len(peers) == 0 {
+		l := material.Body2(th, "Looking for peers on the local network…")
+		l.Color = color.NRGBA{A: 0x80}
+		return l.Layout(gtx)
+	}
+	cell := gtx.Px(unit.Dp(96))
+	x, height := 0, 0
+	for _, p := range peers {
+		if x+cell > gtx.Constraints.Max.X && x > 0 {
+			// No room left; the window is too narrow for all peers.
+			break
+		}
+		stack := op.Save(gtx.Ops)
+		op.Offset(layout.FPt(image.Pt(x, 0))).Add(gtx.Ops)
+		cgtx := gtx
+		cgtx.Constraints = layout.Exact(image.Pt(cell, 0))
+		cgtx.Constraints.Max.Y = gtx.Constraints.Max.Y
+		dims := a.layoutPeer(cgtx, th, p)
+		stack.Load()
+		a.peerRects[p.ID] = image.Rectangle{Max: dims.Size}.Add(origin.Add(image.Pt(x, 0)))
+		if dims.Size.Y > height {
+			height = dims.Size.Y
+		}
+		x += cell
+	}
+	return D{Size: image.Pt(x, height)}
+}
+
+func (a *App) layoutPeer(gtx C, th *material.Theme, p Peer) D {
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			d := gtx.Px(unit.Dp(56))
+			ring := gtx.Px(unit.Dp(4))
+			sz := image.Pt(d+2*ring, d+2*ring)
+			if a.drag.dragging && a.drag.target == p.ID {
+				paint.FillShape(gtx.Ops, th.Palette.ContrastBg, clip.UniformRRect(f32.Rectangle{Max: layout.FPt(sz)}, float32(sz.X)/2).Op(gtx.Ops))
+			}
+			stack := op.Save(gtx.Ops)
+			op.Offset(f32.Pt(float32(ring), float32(ring))).Add(gtx.Ops)
+			clip.UniformRRect(f32.Rectangle{Max: f32.Pt(float32(d), float32(d))}, float32(d)/2).Add(gtx.Ops)
+			paint.Fill(gtx.Ops, nameColor(p.ID))
+			cgtx := gtx
+			cgtx.Constraints = layout.Exact(image.Pt(d, d))
+			l := material.H6(th, initials(p.Name))
+			l.Color = white
+			layout.Center.Layout(cgtx, l.Layout)
+			stack.Load()
+			return D{Size: sz}
+		}),
+		layout.Rigid(func(gtx C) D {
+			l := material.Body2(th, p.Name)
+			l.MaxLines = 1
+			l.Alignment = text.Middle
+			return l.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx C) D {
+			// The progress of the files sent to the peer.
+			var done, total int64
+			for _, it := range a.items {
+				if pr := it.Progress(); it.peer == p.ID && pr.Status == Active {
+					done += pr.Done
+					total += it.Size
+				}
+			}
+			if total == 0 {
+				return D{}
+			}
+			l := material.Caption(th, fmt.Sprintf("Sending %d%%", done*100/total))
+			l.Color = th.Palette.ContrastBg
+			return l.Layout(gtx)
+		}),
+	)
+}
+
+func (a *App) layoutAdd(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, func(gtx C) D {
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					return widget.Border{Color: color.NRGBA{A: 0x40}, CornerRadius: unit.Dp(4), Width: unit.Dp(1)}.Layout(gtx, func(gtx C) D {
+						return layout.UniformInset(unit.Dp(8)).Layout(gtx, material.Editor(th, &a.path, "Path of a file to send").Layout)
+					})
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+				layout.Rigid(material.Button(th, &a.add, "Add").Layout),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			if a.err == "" {
+				return D{}
+			}
+			l := material.Caption(th, a.err)
+			l.Color = errorColor
+			return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, l.Layout)
+		}),
+	)
+}
+
+// layoutFiles lays out the files to send as chips, wrapping them into
+// rows, and records their areas in the window from the origin.
+func (a *App) layoutFiles(gtx C, th *material.Theme, origin image.Point) D {
+	a.fileRects = a.fileRects[:0]
+	if len(a.files) == 0 {
+		l := material.Body2(th, "Add files to send, then drag them onto a peer.")
+		l.Color = color.NRGBA{A: 0x80}
+		return l.Layout(gtx)
+	}
+	spacing := gtx.Px(unit.Dp(8))
+	var x, y, rowHeight, width int
+	for _, f := range a.files {
+		macro := op.Record(gtx.Ops)
+		dims := layoutChip(gtx, th, f, &f.remove)
+		call := macro.Stop()
+		if x > 0 && x+dims.Size.X > gtx.Constraints.Max.X {
+			x, y, rowHeight = 0, y+rowHeight+spacing, 0
+		}
+		stack := op.Save(gtx.Ops)
+		op.Offset(layout.FPt(image.Pt(x, y))).Add(gtx.Ops)
+		call.Add(gtx.Ops)
+		stack.Load()
+		a.fileRects = append(a.fileRects, image.Rectangle{Max: dims.Size}.Add(origin.Add(image.Pt(x, y))))
+		x += dims.Size.X + spacing
+		if dims.Size.Y > rowHeight {
+			rowHeight = dims.Size.Y
+		}
+		if x > width {
+			width = x
+		}
+	}
+	return D{Size: image.Pt(width, y+rowHeight)}
+}
+
+// layoutChip lays out a file to send, with a button to remove it if
+// remove is not nil.
+func layoutChip(gtx C, th *material.Theme, f *file, remove *widget.Clickable) D {
+	macro := op.Record(gtx.Ops)
+	dims := layout.Inset{Left: unit.Dp(12), Right: unit.Dp(4), Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(material.Body2(th, f.name).Layout),
+			layout.Rigid(func(gtx C) D {
+				l := material.Caption(th, " "+bytesize.Format(f.size))
+				l.Color = color.NRGBA{A: 0x80}
+				return l.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx C) D {
+				if remove == nil {
+					return layout.Spacer{Width: unit.Dp(8)}.Layout(gtx)
+				}
+				return material.Clickable(gtx, remove, func(gtx C) D {
+					return layout.UniformInset(unit.Dp(4)).Layout(gtx, material.Body2(th, "×").Layout)
+				})
+			}),
+		)
+	})
+	call := macro.Stop()
+	r := float32(dims.Size.Y) / 2
+	paint.FillShape(gtx.Ops, chipColor, clip.UniformRRect(f32.Rectangle{Max: layout.FPt(dims.Size)}, r).Op(gtx.Ops))
+	call.Add(gtx.Ops)
+	return dims
+}
+
+func (it *item) Layout(gtx C, th *material.Theme) D {
+	p := it.Progress()
+	var frac float32
+	if it.Size > 0 {
+		frac = float32(p.Done) / float32(it.Size)
+	}
+	title := "To " + it.Peer + ": " + it.Name
+	if it.Incoming {
+		title = "From " + it.Peer + ": " + it.Name
+	}
+	var status string
+	switch p.Status {
+	case Asking:
+		if it.Incoming {
+			status = fmt.Sprintf("%s wants to send you %s", it.Peer, bytesize.Format(it.Size))
+		} else {
+			status = fmt.Sprintf("Waiting for %s to accept", it.Peer)
+		}
+	case Active:
+		status = fmt.Sprintf("%s of %s · %s/s", bytesize.Format(p.Done), bytesize.Format(it.Size), bytesize.Format(int64(p.BytesPerSecond)))
+	case Completed:
+		status = "Sent " + bytesize.Format(it.Size)
+		if it.Incoming {
+			status = "Saved to " + p.Saved
+		}
+	case Declined:
+		status = "Declined"
+	case Failed:
+		status = fmt.Sprintf("Failed: %v", p.Err)
+		if errors.Is(p.Err, errCancelled) {
+			status = "Cancelled"
+		}
+	}
+	var buttons []layout.FlexChild
+	switch {
+	case p.Status == Asking && it.Incoming:
+		buttons = append(buttons,
+			layout.Rigid(style.TextButton(th, &it.accept, "Accept")),
+			layout.Rigid(style.TextButton(th, &it.decline, "Decline")),
+		)
+	case p.Status == Asking || p.Status == Active:
+		buttons = append(buttons, layout.Rigid(style.TextButton(th, &it.cancel, "Cancel")))
+	default:
+		buttons = append(buttons, layout.Rigid(style.TextButton(th, &it.remove, "Remove")))
+	}
+	return layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(6)}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx, append([]layout.FlexChild{
+			layout.Flexed(1, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						l := material.Body1(th, title)
+						l.MaxLines = 1
+						return l.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx C) D {
+						if p.Status != Active {
+							return D{}
+						}
+						return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx,
+							material.ProgressBar(th, frac).Layout)
+					}),
+					layout.Rigid(func(gtx C) D {
+						l := material.Caption(th, status)
+						if p.Status == Failed && !errors.Is(p.Err, errCancelled) {
+							l.Color = errorColor
+						}
+						return l.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+		}, buttons...)...)
+	})
+}
+
+// initials returns the first letters of the first and last word of name.
+func initials(name string) string {
+	words := strings.Fields(name)
+	if len(words) == 0 {
+		return "?"
+	}
+	s := string([]rune(words[0])[:1])
+	if len(words) > 1 {
+		s += string([]rune(words[len(words)-1])[:1])
+	}
+	return strings.ToUpper(s)
+}
+
+// nameColor derives a stable color from a name.
+func nameColor(name string) color.NRGBA {
+	palette := []color.NRGBA{
+		{R: 0xe5, G: 0x73, B: 0x73, A: 0xff},
+		{R: 0xba, G: 0x68, B: 0xc8, A: 0xff},
+		{R: 0x79, G: 0x86, B: 0xcb, A: 0xff},
+		{R: 0x4f, G: 0xc3, B: 0xf7, A: 0xff},
+		{R: 0x4d, G: 0xb6, B: 0xac, A: 0xff},
+		{R: 0xff, G: 0xb7, B: 0x4d, A: 0xff},
+	}
+	var h uint32
+	for _, c := range name {
+		h = h*31 + uint32(c)
+	}
+	return palette[h%uint32(len(palette))]
+}
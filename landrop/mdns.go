@@ -0,0 +1,429 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The peers are found with multicast DNS service discovery (RFC 6762 and
+// RFC 6763): every instance answers the queries for the service with
+// the records of its instance, and queries every few seconds.
+const (
+	service = "_giodrop._tcp.local."
+	// queryInterval is the time between queries for the peers.
+	queryInterval = 5 * time.Second
+	// peerTTL is the time a peer is shown without being heard of.
+	peerTTL = 3 * queryInterval
+)
+
+var mdnsAddr = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// DNS record types.
+const (
+	typePTR uint16 = 12
+	typeTXT uint16 = 16
+	typeSRV uint16 = 33
+)
+
+const (
+	classIN = 1
+	// cacheFlush marks the records that only the sender has.
+	cacheFlush = 0x8000
+	// flagResponse marks an authoritative answer.
+	flagResponse = 0x8400
+)
+
+// Peer is an instance on the network.
+type Peer struct {
+	// ID tells instances apart, for they may share a name.
+	ID   string
+	Name string
+	// Addr is the address to send files to.
+	Addr string
+}
+
+type question struct {
+	Name string
+	Type uint16
+}
+
+type record struct {
+	Name string
+	Type uint16
+	TTL  uint32
+	// Target is the name of a PTR record.
+	Target string
+	// Port is the port of a SRV record.
+	Port uint16
+	// Text is the strings of a TXT record.
+	Text []string
+}
+
+type dnsMessage struct {
+	Response  bool
+	Questions []question
+	Answers   []record
+}
+
+var errMalformed = errors.New("mdns: malformed message")
+
+func (m dnsMessage) encode() []byte {
+	b := make([]byte, 12)
+	if m.Response {
+		binary.BigEndian.PutUint16(b[2:], flagResponse)
+	}
+	binary.BigEndian.PutUint16(b[4:], uint16(len(m.Questions)))
+	binary.BigEndian.PutUint16(b[6:], uint16(len(m.Answers)))
+	for _, q := range m.Questions {
+		b = appendName(b, q.Name)
+		b = appendUint16(b, q.Type)
+		b = appendUint16(b, classIN)
+	}
+	for _, r := range m.Answers {
+		b = appendName(b, r.Name)
+		b = appendUint16(b, r.Type)
+		class := uint16(classIN)
+		if r.Type != typePTR {
+			class |= cacheFlush
+		}
+		b = appendUint16(b, class)
+		b = appendUint16(b, uint16(r.TTL>>16))
+		b = appendUint16(b, uint16(r.TTL))
+		var data []byte
+		switch r.Type {
+		case typePTR:
+			data = appendName(nil, r.Target)
+		case typeSRV:
+			// Priority, weight, port and the host, left to the address
+			// the message is sent from.
+			data = append(data, 0, 0, 0, 0)
+			data = appendUint16(data, r.Port)
+			data = appendName(data, "local.")
+		case typeTXT:
+			for _, s := range r.Text {
+				data = append(data, byte(len(s)))
+				data = append(data, s...)
+			}
+		}
+		b = appendUint16(b, uint16(len(data)))
+		b = append(b, data...)
+	}
+	return b
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+// appendName appends a domain name of labels separated by dots. Labels
+// are truncated to the 63 bytes allowed.
+func appendName(b []byte, name string) []byte {
+	for _, l := range splitName(name) {
+		if len(l) > 63 {
+			l = l[:63]
+		}
+		b = append(b, byte(len(l)))
+		b = append(b, l...)
+	}
+	return append(b, 0)
+}
+
+// splitName splits a name into labels, at the dots not escaped with a
+// backslash.
+func splitName(name string) []string {
+	var labels []string
+	var l []byte
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; {
+		case c == '\\' && i+1 < len(name):
+			i++
+			l = append(l, name[i])
+		case c == '.':
+			if len(l) > 0 {
+				labels = append(labels, string(l))
+			}
+			l = l[:0]
+		default:
+			l = append(l, c)
+		}
+	}
+	if len(l) > 0 {
+		labels = append(labels, string(l))
+	}
+	return labels
+}
+
+// escapeLabel escapes the dots and backslashes of a label.
+func escapeLabel(l string) string {
+	l = strings.ReplaceAll(l, `\`, `\\`)
+	return strings.ReplaceAll(l, ".", `\.`)
+}
+
+func parseMessage(b []byte) (dnsMessage, error) {
+	var m dnsMessage
+	if len(b) < 12 {
+		return m, errMalformed
+	}
+	m.Response = b[2]&0x80 != 0
+	qd := int(binary.BigEndian.Uint16(b[4:]))
+	// The authority and additional records are answers as well.
+	an := int(binary.BigEndian.Uint16(b[6:])) + int(binary.BigEndian.Uint16(b[8:])) + int(binary.BigEndian.Uint16(b[10:]))
+	off := 12
+	for i := 0; i < qd; i++ {
+		name, n, err := parseName(b, off)
+		if err != nil {
+			return m, err
+		}
+		off = n
+		if off+4 > len(b) {
+			return m, errMalformed
+		}
+		m.Questions = append(m.Questions, question{Name: name, Type: binary.BigEndian.Uint16(b[off:])})
+		off += 4
+	}
+	for i := 0; i < an; i++ {
+		name, n, err := parseName(b, off)
+		if err != nil {
+			return m, err
+		}
+		off = n
+		if off+10 > len(b) {
+			return m, errMalformed
+		}
+		r := record{
+			Name: name,
+			Type: binary.BigEndian.Uint16(b[off:]),
+			TTL:  binary.BigEndian.Uint32(b[off+4:]),
+		}
+		size := int(binary.BigEndian.Uint16(b[off+8:]))
+		off += 10
+		if off+size > len(b) {
+			return m, errMalformed
+		}
+		data := b[off : off+size]
+		switch r.Type {
+		case typePTR:
+			if r.Target, _, err = parseName(b, off); err != nil {
+				return m, err
+			}
+		case typeSRV:
+			if len(data) < 6 {
+				return m, errMalformed
+			}
+			r.Port = binary.BigEndian.Uint16(data[4:])
+		case typeTXT:
+			for len(data) > 0 {
+				n := int(data[0])
+				if 1+n > len(data) {
+					return m, errMalformed
+				}
+				r.Text = append(r.Text, string(data[1:1+n]))
+				data = data[1+n:]
+			}
+		}
+		off += size
+		m.Answers = append(m.Answers, r)
+	}
+	return m, nil
+}
+
+// parseName parses the name at off, following compression pointers, and
+// returns it with the offset after it.
+func parseName(b []byte, off int) (string, int, error) {
+	var labels []string
+	end := -1
+	for jumps := 0; ; {
+		if off >= len(b) {
+			return "", 0, errMalformed
+		}
+		n := int(b[off])
+		switch {
+		case n == 0:
+			if end == -1 {
+				end = off + 1
+			}
+			return strings.Join(labels, ".") + ".", end, nil
+		case n&0xc0 == 0xc0:
+			if off+1 >= len(b) || jumps > 16 {
+				return "", 0, errMalformed
+			}
+			if end == -1 {
+				end = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(b[off:]) & 0x3fff)
+			jumps++
+		default:
+			if off+1+n > len(b) {
+				return "", 0, errMalformed
+			}
+			labels = append(labels, escapeLabel(string(b[off+1:off+1+n])))
+			off += 1 + n
+		}
+	}
+}
+
+// announcement returns the answer describing self, with ttl in seconds.
+// A ttl of zero says goodbye.
+func announcement(self Peer, port int, ttl uint32) dnsMessage {
+	instance := escapeLabel(self.Name) + "." + service
+	return dnsMessage{
+		Response: true,
+		Answers: []record{
+			{Name: service, Type: typePTR, TTL: ttl, Target: instance},
+			{Name: instance, Type: typeSRV, TTL: ttl, Port: uint16(port)},
+			{Name: instance, Type: typeTXT, TTL: ttl, Text: []string{"id=" + self.ID, "name=" + self.Name}},
+		},
+	}
+}
+
+func browseQuery() dnsMessage {
+	return dnsMessage{Questions: []question{{Name: service, Type: typePTR}}}
+}
+
+// asks reports whether m asks for the instances of the service.
+func (m dnsMessage) asks() bool {
+	if m.Response {
+		return false
+	}
+	for _, q := range m.Questions {
+		if strings.EqualFold(q.Name, service) && (q.Type == typePTR || q.Type == 255) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerEvent reports a peer found, or gone.
+type peerEvent struct {
+	Peer Peer
+	Gone bool
+}
+
+// peers returns the peers announced by m, sent from ip.
+func (m dnsMessage) peers(ip net.IP) []peerEvent {
+	if !m.Response {
+		return nil
+	}
+	var events []peerEvent
+	for _, ptr := range m.Answers {
+		if ptr.Type != typePTR || !strings.EqualFold(ptr.Name, service) {
+			continue
+		}
+		var port uint16
+		var id, name string
+		for _, r := range m.Answers {
+			if !strings.EqualFold(r.Name, ptr.Target) {
+				continue
+			}
+			switch r.Type {
+			case typeSRV:
+				port = r.Port
+			case typeTXT:
+				for _, kv := range r.Text {
+					switch {
+					case strings.HasPrefix(kv, "id="):
+						id = kv[len("id="):]
+					case strings.HasPrefix(kv, "name="):
+						name = kv[len("name="):]
+					}
+				}
+			}
+		}
+		if port == 0 || id == "" {
+			continue
+		}
+		events = append(events, peerEvent{
+			Peer: Peer{ID: id, Name: name, Addr: net.JoinHostPort(ip.String(), strconv.Itoa(int(port)))},
+			Gone: ptr.TTL == 0,
+		})
+	}
+	return events
+}
+
+// discover announces self, listening for files on port, and sends the
+// peers found and gone to events until stop is closed, when it says
+// goodbye. A peer not heard of for peerTTL is gone.
+func discover(self Peer, port int, events chan<- interface{}, stop <-chan struct{}) error {
+	conn, err := net.ListenMulticastUDP("udp4", nil, mdnsAddr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	send := func(m dnsMessage) {
+		conn.WriteToUDP(m.encode(), mdnsAddr)
+	}
+	ttl := uint32(peerTTL / time.Second)
+	received := make(chan peerEvent)
+	go func() {
+		buf := make([]byte, 9000)
+		for {
+			n, from, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				close(received)
+				return
+			}
+			m, err := parseMessage(buf[:n])
+			if err != nil {
+				continue
+			}
+			if m.asks() {
+				// Spread the answers of many peers.
+				time.Sleep(time.Duration(20+rand.Intn(100)) * time.Millisecond)
+				send(announcement(self, port, ttl))
+			}
+			for _, e := range m.peers(from.IP) {
+				if e.Peer.ID == self.ID {
+					continue
+				}
+				select {
+				case received <- e:
+				case <-stop:
+					return
+				}
+			}
+		}
+	}()
+	send(announcement(self, port, ttl))
+	send(browseQuery())
+	t := time.NewTicker(queryInterval)
+	defer t.Stop()
+	seen := make(map[string]time.Time)
+	for {
+		select {
+		case <-stop:
+			send(announcement(self, port, 0))
+			return nil
+		case e, ok := <-received:
+			if !ok {
+				return errors.New("mdns: connection closed")
+			}
+			if e.Gone {
+				delete(seen, e.Peer.ID)
+			} else {
+				seen[e.Peer.ID] = time.Now()
+			}
+			select {
+			case events <- e:
+			case <-stop:
+			}
+		case now := <-t.C:
+			send(browseQuery())
+			for id, last := range seen {
+				if now.Sub(last) > peerTTL {
+					delete(seen, id)
+					select {
+					case events <- peerEvent{Peer: Peer{ID: id}, Gone: true}:
+					case <-stop:
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestAnnouncement(t *testing.T) {
+	self := Peer{ID: "0123abcd", Name: "Ada's laptop.home"}
+	m, err := parseMessage(announcement(self, 4242, 15).encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := m.peers(net.IPv4(192, 168, 1, 7))
+	want := []peerEvent{{Peer: Peer{ID: self.ID, Name: self.Name, Addr: "192.168.1.7:4242"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("peers %+v, want %+v", got, want)
+	}
+
+	m, err = parseMessage(announcement(self, 4242, 0).encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.peers(net.IPv4(192, 168, 1, 7)); len(got) != 1 || !got[0].Gone {
+		t.Errorf("goodbye peers %+v, want one gone", got)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	m, err := parseMessage(browseQuery().encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.asks() {
+		t.Errorf("query %+v doesn't ask for the service", m)
+	}
+	if p := m.peers(net.IPv4(10, 0, 0, 1)); len(p) != 0 {
+		t.Errorf("query has peers %+v", p)
+	}
+}
+
+func TestCompressedNames(t *testing.T) {
+	// An answer from another implementation, naming the instance with a
+	// pointer to the service name of the PTR record.
+	b := []byte{
+		0, 0, 0x84, 0, 0, 0, 0, 2, 0, 0, 0, 0,
+		// _giodrop._tcp.local. PTR, at offset 12.
+		8, '_', 'g', 'i', 'o', 'd', 'r', 'o', 'p', 4, '_', 't', 'c', 'p', 5, 'l', 'o', 'c', 'a', 'l', 0,
+		0, 12, 0, 1, 0, 0, 0, 120, 0, 6,
+		3, 'B', 'o', 'b', 0xc0, 12,
+		// Bob._giodrop._tcp.local. SRV, named by a pointer to the target
+		// of the PTR record.
+		0xc0, 43,
+		0, 33, 0x80, 1, 0, 0, 0, 120, 0, 8,
+		0, 0, 0, 0, 0x1f, 0x90, 0xc0, 26,
+	}
+	m, err := parseMessage(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Answers) != 2 {
+		t.Fatalf("%d answers, want 2", len(m.Answers))
+	}
+	ptr, srv := m.Answers[0], m.Answers[1]
+	if ptr.Target != "Bob."+service || srv.Name != ptr.Target || srv.Port != 8080 {
+		t.Errorf("answers %+v", m.Answers)
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	b := announcement(Peer{ID: "1", Name: "x"}, 1, 1).encode()
+	for n := 0; n < len(b); n++ {
+		if _, err := parseMessage(b[:n]); err == nil {
+			t.Errorf("parsed a message cut to %d of %d bytes", n, len(b))
+		}
+	}
+	// A name pointing to itself.
+	loop := []byte{0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0xc0, 12, 0, 12, 0, 1}
+	if _, err := parseMessage(loop); err == nil {
+		t.Error("parsed a name pointing to itself")
+	}
+}
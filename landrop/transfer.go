@@ -0,0 +1,387 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A transfer is a TCP connection from the sender to the receiver. The
+// sender writes a JSON header line, the receiver answers with a line of
+// accept or decline, and after accept the sender writes the content and
+// the receiver confirms the file saved with a line of done.
+const (
+	replyAccept  = "accept"
+	replyDecline = "decline"
+	replyDone    = "done"
+)
+
+type header struct {
+	From string `json:"from"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// Status is the status of a transfer.
+type Status int
+
+const (
+	// Asking is a transfer waiting for the receiver to accept it.
+	Asking Status = iota
+	Active
+	Completed
+	Declined
+	Failed
+)
+
+var (
+	errCancelled = errors.New("cancelled")
+	errCutOff    = errors.New("the connection was cut off")
+)
+
+// Transfer is a file sent or received.
+type Transfer struct {
+	// Incoming reports whether the file is received.
+	Incoming bool
+	// Peer is the name of the other end.
+	Peer string
+	// Name is the name of the file, and Size its size.
+	Name string
+	Size int64
+	// Path is the file sent.
+	Path string
+
+	conn    net.Conn
+	answer  chan bool
+	changed func()
+
+	mu     sync.Mutex
+	status Status
+	done   int64
+	start  time.Time
+	err    error
+	saved  string
+}
+
+// Progress is a snapshot of the state of a transfer.
+type Progress struct {
+	Status Status
+	Done   int64
+	Err    error
+	// Saved is the path of a file received.
+	Saved string
+	// BytesPerSecond is the average rate since the transfer started.
+	BytesPerSecond float64
+}
+
+// Progress returns the current state of the transfer.
+func (t *Transfer) Progress() Progress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p := Progress{Status: t.status, Done: t.done, Err: t.err, Saved: t.saved}
+	if d := time.Since(t.start).Seconds(); t.status == Active && d > 0 {
+		p.BytesPerSecond = float64(t.done) / d
+	}
+	return p
+}
+
+// Answer accepts or declines an incoming transfer.
+func (t *Transfer) Answer(accept bool) {
+	select {
+	case t.answer <- accept:
+	default:
+	}
+}
+
+// Cancel stops the transfer at either end.
+func (t *Transfer) Cancel() {
+	t.mu.Lock()
+	if t.status == Asking || t.status == Active {
+		t.status, t.err = Failed, errCancelled
+	}
+	t.mu.Unlock()
+	t.conn.Close()
+}
+
+func (t *Transfer) setStatus(s Status) {
+	t.mu.Lock()
+	if t.err != errCancelled {
+		t.status = s
+		if s == Active {
+			t.start = time.Now()
+		}
+	}
+	t.mu.Unlock()
+	t.changed()
+}
+
+func (t *Transfer) fail(err error) {
+	t.mu.Lock()
+	if t.err != errCancelled {
+		t.status, t.err = Failed, err
+	}
+	t.mu.Unlock()
+	t.conn.Close()
+	t.changed()
+}
+
+// copy copies the content, counting the bytes done.
+func (t *Transfer) copy(w io.Writer, r io.Reader) error {
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+			t.mu.Lock()
+			t.done += int64(n)
+			t.mu.Unlock()
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// send starts sending the file at path to addr, on behalf of from.
+func send(addr, peer, from, path string, changed func()) (*Transfer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if st.IsDir() {
+		f.Close()
+		return nil, fmt.Errorf("%s is a folder", filepath.Base(path))
+	}
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	t := &Transfer{
+		Peer:    peer,
+		Name:    filepath.Base(path),
+		Size:    st.Size(),
+		Path:    path,
+		conn:    conn,
+		changed: changed,
+	}
+	go func() {
+		defer f.Close()
+		if err := t.send(f, from); err != nil {
+			t.fail(err)
+			return
+		}
+		t.conn.Close()
+	}()
+	return t, nil
+}
+
+func (t *Transfer) send(f io.Reader, from string) error {
+	h, err := json.Marshal(header{From: from, Name: t.Name, Size: t.Size})
+	if err != nil {
+		return err
+	}
+	if _, err := t.conn.Write(append(h, '\n')); err != nil {
+		return err
+	}
+	r := bufio.NewReader(t.conn)
+	switch reply, err := readLine(r); {
+	case err != nil:
+		return err
+	case reply == replyDecline:
+		t.setStatus(Declined)
+		return nil
+	case reply != replyAccept:
+		return fmt.Errorf("unexpected reply %q", reply)
+	}
+	t.setStatus(Active)
+	if err := t.copy(t.conn, f); err != nil {
+		return err
+	}
+	if reply, err := readLine(r); err != nil || reply != replyDone {
+		return errCutOff
+	}
+	t.setStatus(Completed)
+	return nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err == io.EOF {
+		err = errCutOff
+	}
+	return strings.TrimSuffix(line, "\n"), err
+}
+
+// receiver accepts transfers and saves the files accepted to a folder.
+type receiver struct {
+	l   net.Listener
+	dir string
+	// incoming is sent the transfers asking to be accepted.
+	incoming chan<- *Transfer
+	changed  func()
+
+	mu sync.Mutex
+	// saving is the paths being saved to, to not save two files to the
+	// same path.
+	saving map[string]bool
+}
+
+func newReceiver(l net.Listener, dir string, incoming chan<- *Transfer, changed func()) *receiver {
+	return &receiver{l: l, dir: dir, incoming: incoming, changed: changed, saving: make(map[string]bool)}
+}
+
+// Serve accepts transfers until the listener is closed.
+func (r *receiver) Serve() error {
+	for {
+		conn, err := r.l.Accept()
+		if err != nil {
+			return err
+		}
+		go r.receive(conn)
+	}
+}
+
+func (r *receiver) receive(conn net.Conn) {
+	br := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	line, err := br.ReadBytes('\n')
+	conn.SetReadDeadline(time.Time{})
+	var h header
+	if err != nil || json.Unmarshal(line, &h) != nil || h.Size < 0 {
+		conn.Close()
+		return
+	}
+	t := &Transfer{
+		Incoming: true,
+		Peer:     h.From,
+		Name:     cleanName(h.Name),
+		Size:     h.Size,
+		conn:     conn,
+		answer:   make(chan bool, 1),
+		changed:  r.changed,
+	}
+	r.incoming <- t
+	// Wait for the answer, noticing the sender giving up meanwhile.
+	gone := make(chan struct{})
+	go func() {
+		br.Peek(1)
+		close(gone)
+	}()
+	var accept bool
+	select {
+	case accept = <-t.answer:
+		// Stop waiting before reading the content.
+		conn.SetReadDeadline(time.Now())
+		<-gone
+		conn.SetReadDeadline(time.Time{})
+	case <-gone:
+		t.fail(errCancelled)
+		return
+	}
+	if !accept {
+		conn.Write([]byte(replyDecline + "\n"))
+		conn.Close()
+		t.setStatus(Declined)
+		return
+	}
+	if err := r.save(t, br); err != nil {
+		t.fail(err)
+		return
+	}
+	conn.Close()
+}
+
+// save saves the content of t to a partial file, renamed once complete.
+func (r *receiver) save(t *Transfer, content io.Reader) error {
+	path := r.reserve(t.Name)
+	defer r.release(path)
+	part := path + ".part"
+	f, err := os.Create(part)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(part)
+	if _, err := t.conn.Write([]byte(replyAccept + "\n")); err != nil {
+		f.Close()
+		return err
+	}
+	t.setStatus(Active)
+	err = t.copy(f, io.LimitReader(content, t.Size))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if t.Progress().Done != t.Size {
+		return errCutOff
+	}
+	if err := os.Rename(part, path); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.saved = path
+	t.mu.Unlock()
+	if _, err := t.conn.Write([]byte(replyDone + "\n")); err != nil {
+		return err
+	}
+	t.setStatus(Completed)
+	return nil
+}
+
+// reserve returns a path in the folder for name that doesn't clash with
+// existing files or files being saved.
+func (r *receiver) reserve(name string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; ; i++ {
+		p := filepath.Join(r.dir, name)
+		_, err := os.Stat(p)
+		if !r.saving[p] && os.IsNotExist(err) {
+			r.saving[p] = true
+			return p
+		}
+		name = base + " (" + strconv.Itoa(i) + ")" + ext
+	}
+}
+
+func (r *receiver) release(path string) {
+	r.mu.Lock()
+	delete(r.saving, path)
+	r.mu.Unlock()
+}
+
+// cleanName makes the name sent by a peer safe to save to, dropping any
+// folders.
+func cleanName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	switch name {
+	case ".", "..", "/", "":
+		return "file"
+	}
+	return name
+}
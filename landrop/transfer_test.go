@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// startReceiver receives files into a temporary folder, answering every
+// transfer with accept.
+func startReceiver(t *testing.T, accept bool) (addr, dir string, received <-chan *Transfer) {
+	t.Helper()
+	dir = t.TempDir()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	incoming := make(chan *Transfer)
+	out := make(chan *Transfer, 1)
+	go newReceiver(l, dir, incoming, func() {}).Serve()
+	go func() {
+		for tr := range incoming {
+			tr.Answer(accept)
+			out <- tr
+		}
+	}()
+	return l.Addr().String(), dir, out
+}
+
+func waitFor(t *testing.T, tr *Transfer, s Status) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for tr.Progress().Status != s {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s: status %v, want %v (%v)", tr.Name, tr.Progress().Status, s, tr.Progress().Err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	addr, dir, received := startReceiver(t, true)
+	content := bytes.Repeat([]byte("gopher"), 100000)
+	src := filepath.Join(t.TempDir(), "report.txt")
+	if err := ioutil.WriteFile(src, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A file of the same name, not to be overwritten.
+	if err := ioutil.WriteFile(filepath.Join(dir, "report.txt"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	sent, err := send(addr, "B", "A", src, func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, sent, Completed)
+	got := <-received
+	waitFor(t, got, Completed)
+	if got.Peer != "A" || got.Name != "report.txt" || got.Size != int64(len(content)) {
+		t.Errorf("received %s from %s, %d bytes", got.Name, got.Peer, got.Size)
+	}
+	p := got.Progress()
+	if want := filepath.Join(dir, "report (1).txt"); p.Saved != want {
+		t.Errorf("saved to %s, want %s", p.Saved, want)
+	}
+	data, err := ioutil.ReadFile(p.Saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Errorf("received %d bytes differing from the %d sent", len(data), len(content))
+	}
+	if d := sent.Progress().Done; d != int64(len(content)) {
+		t.Errorf("sent %d bytes, want %d", d, len(content))
+	}
+}
+
+func TestDecline(t *testing.T) {
+	addr, dir, received := startReceiver(t, false)
+	src := filepath.Join(t.TempDir(), "secret.txt")
+	if err := ioutil.WriteFile(src, []byte("no"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sent, err := send(addr, "B", "A", src, func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, sent, Declined)
+	waitFor(t, <-received, Declined)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("%d files saved after declining", len(files))
+	}
+}
+
+func TestCleanName(t *testing.T) {
+	tests := map[string]string{
+		"photo.jpg":        "photo.jpg",
+		"../../etc/passwd": "passwd",
+		`..\..\boot.ini`:   "boot.ini",
+		"/":                "file",
+		"..":               "file",
+		"":                 "file",
+	}
+	for name, want := range tests {
+		if got := cleanName(name); got != want {
+			t.Errorf("cleanName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
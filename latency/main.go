@@ -0,0 +1,453 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// GLFW doesn't build on OpenBSD and FreeBSD.
+// +build !openbsd,!freebsd,!android,!ios,!js
+
+// The latency example measures the time from input events to the
+// presentation of the first frame showing them, and plots it. Move the
+// mouse over the window: a square follows the cursor, and every frame
+// records how long ago the oldest of its mouse events arrived.
+//
+// Like the glfw example, it renders Gio with its own OpenGL context,
+// which gives control over presentation. The modes compare a vsynced
+// FIFO swap chain, immediate swaps that tear, and adaptive vsync that
+// tears only late frames. OpenGL has no mailbox mode; immediate swaps
+// come closest in latency. Latencies are measured from the delivery of
+// events to the program, after GL has finished the frame; the time in
+// the operating system and the display adds to them.
+//
+// Keys 1 to 3 select the mode, W adds simulated work to every frame
+// and C clears the measurements.
+//
+// See the go-glfw package for installation of the native
+// dependencies:
+//
+// https://github.com/go-gl/glfw
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"runtime"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/gpu"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/go-gl/gl/v3.1/gles2"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+// desktopGL is true when the (core, desktop) OpenGL should
+// be used, false for OpenGL ES.
+const desktopGL = runtime.GOOS == "darwin"
+
+// mode is a way of presenting frames.
+type mode struct {
+	name string
+	// interval is the swap interval: the number of vertical blanks to
+	// wait for, negative to swap immediately when late.
+	interval int
+	// ext is the extension required, if any.
+	ext []string
+}
+
+var modes = []mode{
+	{name: "FIFO (vsync)", interval: 1},
+	{name: "Immediate (no vsync)", interval: 0},
+	{name: "Adaptive (tears when late)", interval: -1, ext: []string{"GLX_EXT_swap_control_tear", "WGL_EXT_swap_control_tear"}},
+}
+
+// maxWork is the most simulated work per frame.
+const maxWork = 30 * time.Millisecond
+
+var (
+	cursorColor = color.NRGBA{R: 0xe5, G: 0x39, B: 0x35, A: 0xff}
+	plotColor   = color.NRGBA{R: 0x1e, G: 0x88, B: 0xe5, A: 0xff}
+	gridColor   = color.NRGBA{A: 0x20}
+)
+
+// App is the state of the measurements.
+type App struct {
+	mode      int
+	supported []bool
+	latency   []series
+	frames    []series
+	// pending holds the arrival times of the events not shown yet.
+	pending []time.Time
+	cursor  f32.Point
+	work    time.Duration
+
+	modeButtons []widget.Clickable
+	more, less  widget.Clickable
+	clear       widget.Clickable
+}
+
+func main() {
+	// Required by the OpenGL threading model.
+	runtime.LockOSThread()
+
+	err := glfw.Init()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer glfw.Terminate()
+	// Gio assumes a sRGB backbuffer.
+	glfw.WindowHint(glfw.SRGBCapable, glfw.True)
+	glfw.WindowHint(glfw.ScaleToMonitor, glfw.True)
+	glfw.WindowHint(glfw.CocoaRetinaFramebuffer, glfw.True)
+	if desktopGL {
+		glfw.WindowHint(glfw.ContextVersionMajor, 3)
+		glfw.WindowHint(glfw.ContextVersionMinor, 3)
+		glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+		glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+	} else {
+		glfw.WindowHint(glfw.ContextCreationAPI, glfw.EGLContextAPI)
+		glfw.WindowHint(glfw.ClientAPI, glfw.OpenGLESAPI)
+		glfw.WindowHint(glfw.ContextVersionMajor, 3)
+		glfw.WindowHint(glfw.ContextVersionMinor, 0)
+	}
+
+	window, err := glfw.CreateWindow(900, 700, "Input Latency", nil, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	window.MakeContextCurrent()
+
+	if desktopGL {
+		err = gl.Init()
+	} else {
+		err = gles2.Init()
+	}
+	if err != nil {
+		log.Fatalf("gl.Init failed: %v", err)
+	}
+	if desktopGL {
+		// Enable sRGB.
+		gl.Enable(gl.FRAMEBUFFER_SRGB)
+		// Set up default VBA, required for the forward-compatible core profile.
+		var defVBA uint32
+		gl.GenVertexArrays(1, &defVBA)
+		gl.BindVertexArray(defVBA)
+	}
+
+	a := &App{
+		supported:   make([]bool, len(modes)),
+		latency:     make([]series, len(modes)),
+		frames:      make([]series, len(modes)),
+		modeButtons: make([]widget.Clickable, len(modes)),
+	}
+	for i, m := range modes {
+		a.supported[i] = len(m.ext) == 0
+		for _, ext := range m.ext {
+			if glfw.ExtensionSupported(ext) {
+				a.supported[i] = true
+			}
+		}
+	}
+	a.setMode(0)
+
+	var queue router.Router
+	var ops op.Ops
+	th := material.NewTheme(gofont.Collection())
+	gpu, err := gpu.New(gpu.OpenGL{ES: !desktopGL})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer gpu.Release()
+
+	a.registerCallbacks(window, &queue)
+	last := time.Now()
+	for !window.ShouldClose() {
+		glfw.PollEvents()
+		scale, _ := window.GetContentScale()
+		width, height := window.GetFramebufferSize()
+		sz := image.Point{X: width, Y: height}
+		ops.Reset()
+		gtx := layout.Context{
+			Ops:   &ops,
+			Now:   time.Now(),
+			Queue: &queue,
+			Metric: unit.Metric{
+				PxPerDp: scale,
+				PxPerSp: scale,
+			},
+			Constraints: layout.Exact(sz),
+		}
+		clearOpenGL()
+		a.Layout(gtx, th)
+		time.Sleep(a.work)
+		gpu.Collect(sz, gtx.Ops)
+		gpu.Frame()
+		queue.Frame(gtx.Ops)
+		window.SwapBuffers()
+		finishOpenGL()
+		now := time.Now()
+		if len(a.pending) > 0 {
+			a.latency[a.mode].add(now.Sub(a.pending[0]))
+			a.pending = a.pending[:0]
+		}
+		a.frames[a.mode].add(now.Sub(last))
+		last = now
+	}
+}
+
+// setMode selects a presentation mode. It must be called with the GL
+// context current.
+func (a *App) setMode(i int) {
+	if !a.supported[i] {
+		return
+	}
+	a.mode = i
+	glfw.SwapInterval(modes[i].interval)
+	a.pending = a.pending[:0]
+}
+
+func clearOpenGL() {
+	if desktopGL {
+		gl.ClearColor(1, 1, 1, 1)
+		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	} else {
+		gles2.ClearColor(1, 1, 1, 1)
+		gles2.Clear(gles2.COLOR_BUFFER_BIT | gles2.DEPTH_BUFFER_BIT)
+	}
+}
+
+// finishOpenGL waits for the frame to complete.
+func finishOpenGL() {
+	if desktopGL {
+		gl.Finish()
+	} else {
+		gles2.Finish()
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	for i := range a.modeButtons {
+		for a.modeButtons[i].Clicked() {
+			a.setMode(i)
+		}
+	}
+	for a.more.Clicked() {
+		if a.work < maxWork {
+			a.work += 2 * time.Millisecond
+		}
+	}
+	for a.less.Clicked() {
+		if a.work > 0 {
+			a.work -= 2 * time.Millisecond
+		}
+	}
+	for a.clear.Clicked() {
+		a.clearStats()
+	}
+	dims := layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(material.H5(th, "Input to present latency").Layout),
+			layout.Rigid(material.Body2(th, "Move the mouse over the window. Keys: 1–3 select the mode, W adds work, C clears.").Layout),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+			layout.Rigid(a.layoutModes(th)),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+			layout.Rigid(func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(material.Body1(th, fmt.Sprintf("Simulated work: %d ms per frame", a.work/time.Millisecond)).Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(material.Button(th, &a.less, "−").Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+					layout.Rigid(material.Button(th, &a.more, "+").Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+					layout.Rigid(material.Button(th, &a.clear, "Clear").Layout),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+			layout.Rigid(a.layoutStats(th)),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+			layout.Flexed(1, func(gtx C) D {
+				return a.layoutPlot(gtx, th)
+			}),
+		)
+	})
+	// The square following the cursor, above everything.
+	size := float32(gtx.Px(unit.Dp(24)))
+	r := f32.Rectangle{Min: a.cursor.Sub(f32.Pt(size/2, size/2)), Max: a.cursor.Add(f32.Pt(size/2, size/2))}
+	paint.FillShape(gtx.Ops, cursorColor, clip.UniformRRect(r, 0).Op(gtx.Ops))
+	return dims
+}
+
+func (a *App) layoutModes(th *material.Theme) layout.Widget {
+	return func(gtx C) D {
+		var children []layout.FlexChild
+		for i, m := range modes {
+			i, m := i, m
+			children = append(children, layout.Rigid(func(gtx C) D {
+				return layout.Inset{Right: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+					if !a.supported[i] {
+						gtx = gtx.Disabled()
+					}
+					b := material.Button(th, &a.modeButtons[i], fmt.Sprintf("%d. %s", i+1, m.name))
+					if i != a.mode {
+						b.Background = color.NRGBA{A: 0x18}
+						b.Color = th.Palette.Fg
+					}
+					return b.Layout(gtx)
+				})
+			}))
+		}
+		return layout.Flex{}.Layout(gtx, children...)
+	}
+}
+
+func (a *App) layoutStats(th *material.Theme) layout.Widget {
+	ms := func(d time.Duration) string {
+		return fmt.Sprintf("%.1f", float64(d)/float64(time.Millisecond))
+	}
+	return func(gtx C) D {
+		var children []layout.FlexChild
+		for i, m := range modes {
+			s := a.latency[i].summary()
+			f := a.frames[i].summary()
+			line := fmt.Sprintf("%-28s no samples", m.name)
+			if s.n > 0 {
+				line = fmt.Sprintf("%-28s %4d samples  mean %5s  p50 %5s  p95 %5s  max %5s ms  frame %5s ms",
+					m.name, s.n, ms(s.mean), ms(s.p50), ms(s.p95), ms(s.max), ms(f.mean))
+			}
+			l := material.Body2(th, line)
+			l.Font.Variant = "Mono"
+			if i == a.mode {
+				l.Color = plotColor
+			}
+			children = append(children, layout.Rigid(l.Layout))
+		}
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	}
+}
+
+// layoutPlot plots the latencies of the current mode, oldest first, with
+// grid lines every 10 milliseconds.
+func (a *App) layoutPlot(gtx C, th *material.Theme) D {
+	size := gtx.Constraints.Max
+	values := a.latency[a.mode].values()
+	top := 50 * time.Millisecond
+	for _, v := range values {
+		for v > top {
+			top += 50 * time.Millisecond
+		}
+	}
+	y := func(d time.Duration) float32 {
+		return float32(size.Y) * (1 - float32(d)/float32(top))
+	}
+	for d := time.Duration(0); d <= top; d += 10 * time.Millisecond {
+		gy := int(y(d))
+		paint.FillShape(gtx.Ops, gridColor, clip.Rect{Min: image.Pt(0, gy), Max: image.Pt(size.X, gy+1)}.Op())
+		if d%(50*time.Millisecond) == 0 {
+			stack := op.Save(gtx.Ops)
+			op.Offset(f32.Pt(0, float32(gy))).Add(gtx.Ops)
+			material.Caption(th, fmt.Sprintf("%d ms", d/time.Millisecond)).Layout(gtx)
+			stack.Load()
+		}
+	}
+	if len(values) > 1 {
+		dx := float32(size.X) / float32(maxSamples-1)
+		var p clip.Path
+		p.Begin(gtx.Ops)
+		p.MoveTo(f32.Pt(0, y(values[0])))
+		for i, v := range values[1:] {
+			p.LineTo(f32.Pt(float32(i+1)*dx, y(v)))
+		}
+		paint.FillShape(gtx.Ops, plotColor, clip.Stroke{
+			Path:  p.End(),
+			Style: clip.StrokeStyle{Width: float32(gtx.Px(unit.Dp(1.5)))},
+		}.Op())
+	}
+	return D{Size: size}
+}
+
+func (a *App) clearStats() {
+	for i := range a.latency {
+		a.latency[i].reset()
+		a.frames[i].reset()
+	}
+}
+
+func (a *App) registerCallbacks(window *glfw.Window, q *router.Router) {
+	var btns pointer.Buttons
+	beginning := time.Now()
+	window.SetCursorPosCallback(func(w *glfw.Window, xpos float64, ypos float64) {
+		a.pending = append(a.pending, time.Now())
+		scale := float32(1)
+		if runtime.GOOS == "darwin" {
+			// macOS cursor positions are not scaled to the underlying framebuffer
+			// size when CocoaRetinaFramebuffer is true.
+			scale, _ = w.GetContentScale()
+		}
+		a.cursor = f32.Point{X: float32(xpos) * scale, Y: float32(ypos) * scale}
+		q.Queue(pointer.Event{
+			Type:     pointer.Move,
+			Position: a.cursor,
+			Source:   pointer.Mouse,
+			Time:     time.Since(beginning),
+			Buttons:  btns,
+		})
+	})
+	window.SetMouseButtonCallback(func(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+		var btn pointer.Buttons
+		switch button {
+		case glfw.MouseButton1:
+			btn = pointer.ButtonPrimary
+		case glfw.MouseButton2:
+			btn = pointer.ButtonSecondary
+		case glfw.MouseButton3:
+			btn = pointer.ButtonTertiary
+		}
+		var typ pointer.Type
+		switch action {
+		case glfw.Release:
+			typ = pointer.Release
+			btns &^= btn
+		case glfw.Press:
+			typ = pointer.Press
+			btns |= btn
+		}
+		q.Queue(pointer.Event{
+			Type:     typ,
+			Source:   pointer.Mouse,
+			Time:     time.Since(beginning),
+			Position: a.cursor,
+			Buttons:  btns,
+		})
+	})
+	window.SetKeyCallback(func(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+		if action != glfw.Press {
+			return
+		}
+		switch key {
+		case glfw.Key1, glfw.Key2, glfw.Key3:
+			a.setMode(int(key - glfw.Key1))
+		case glfw.KeyW:
+			a.work += 2 * time.Millisecond
+			if a.work > maxWork {
+				a.work = 0
+			}
+		case glfw.KeyC:
+			a.clearStats()
+		}
+	})
+}
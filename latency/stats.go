@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// +build !openbsd,!freebsd,!android,!ios,!js
+
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// maxSamples is the number of latencies kept for each mode.
+const maxSamples = 600
+
+// series holds the latest latency samples of a presentation mode.
+type series struct {
+	samples []time.Duration
+	// next is the index the next sample replaces once the series is
+	// full.
+	next int
+}
+
+func (s *series) add(d time.Duration) {
+	if len(s.samples) < maxSamples {
+		s.samples = append(s.samples, d)
+		return
+	}
+	s.samples[s.next] = d
+	s.next = (s.next + 1) % maxSamples
+}
+
+// values returns the samples from the oldest.
+func (s *series) values() []time.Duration {
+	return append(append([]time.Duration(nil), s.samples[s.next:]...), s.samples[:s.next]...)
+}
+
+func (s *series) reset() {
+	s.samples = s.samples[:0]
+	s.next = 0
+}
+
+// summary describes the samples of a series.
+type summary struct {
+	n                   int
+	mean, p50, p95, max time.Duration
+}
+
+func (s *series) summary() summary {
+	n := len(s.samples)
+	if n == 0 {
+		return summary{}
+	}
+	sorted := append([]time.Duration(nil), s.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	at := func(p float64) time.Duration {
+		return sorted[int(p*float64(n-1)+0.5)]
+	}
+	return summary{
+		n:    n,
+		mean: sum / time.Duration(n),
+		p50:  at(0.5),
+		p95:  at(0.95),
+		max:  sorted[n-1],
+	}
+}
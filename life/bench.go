@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"math/rand"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+)
+
+// benchSizes are the board sizes of the benchmark, in cells.
+var benchSizes = []image.Point{
+	image.Pt(64, 64),
+	image.Pt(256, 256),
+	image.Pt(1024, 1024),
+}
+
+// benchSeed seeds the boards of the benchmark, for the same boards in
+// every run.
+const benchSeed = 1
+
+// benchCellSize is the cell size in pixels when recording frames.
+const benchCellSize = 5
+
+// benchResult is the measurement of a number of generations.
+type benchResult struct {
+	gens    int
+	elapsed time.Duration
+	// allocs and bytes are the allocations of all generations.
+	allocs, bytes uint64
+}
+
+// bench runs gens generations of each benchmark board without a window
+// and writes a table of the results to w. Each board is measured twice:
+// advancing alone, and advancing while recording the operations of a
+// frame the way the window does.
+func bench(w io.Writer, gens int) error {
+	fmt.Fprintf(w, "%s %s/%s GOMAXPROCS=%d, %d generations\n\n",
+		runtime.Version(), runtime.GOOS, runtime.GOARCH, runtime.GOMAXPROCS(0), gens)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "board\tphase\tgen/s\tns/gen\tallocs/gen\tB/gen\t")
+	for _, size := range benchSizes {
+		board := NewBoard(size)
+		board.randomize(rand.New(rand.NewSource(benchSeed)).Read)
+		advance := measure(gens, board.Advance)
+
+		board = NewBoard(size)
+		board.randomize(rand.New(rand.NewSource(benchSeed)).Read)
+		var ops op.Ops
+		style := BoardStyle{CellSizePx: benchCellSize, Board: board}
+		frame := measure(gens, func() {
+			board.Advance()
+			ops.Reset()
+			gtx := layout.Context{
+				Ops:         &ops,
+				Constraints: layout.Exact(size.Mul(benchCellSize)),
+			}
+			style.Layout(gtx)
+		})
+
+		name := fmt.Sprintf("%d×%d", size.X, size.Y)
+		for _, r := range []struct {
+			phase string
+			res   benchResult
+		}{{"advance", advance}, {"frame", frame}} {
+			n := float64(r.res.gens)
+			fmt.Fprintf(tw, "%s\t%s\t%.1f\t%.0f\t%.1f\t%.0f\t\n",
+				name, r.phase,
+				n/r.res.elapsed.Seconds(),
+				float64(r.res.elapsed.Nanoseconds())/n,
+				float64(r.res.allocs)/n,
+				float64(r.res.bytes)/n,
+			)
+		}
+	}
+	return tw.Flush()
+}
+
+// measure runs step gens times after a warm up run.
+func measure(gens int, step func()) benchResult {
+	step()
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < gens; i++ {
+		step()
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return benchResult{
+		gens:    gens,
+		elapsed: elapsed,
+		allocs:  after.Mallocs - before.Mallocs,
+		bytes:   after.TotalAlloc - before.TotalAlloc,
+	}
+}
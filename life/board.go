@@ -30,7 +30,12 @@ func NewBoard(size image.Point) *Board {
 
 // Randomize randomizes each cell state.
 func (b *Board) Randomize() {
-	rand.Read(b.Cells)
+	b.randomize(rand.Read)
+}
+
+// randomize sets the cell states from the random bytes of read.
+func (b *Board) randomize(read func([]byte) (int, error)) {
+	read(b.Cells)
 	for i, v := range b.Cells {
 		if v < 0x30 {
 			b.Cells[i] = 1
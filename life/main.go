@@ -2,7 +2,23 @@
 
 package main
 
+// This program is Conway's Game of Life. Drag over the board to bring
+// cells to life.
+//
+// Usage:
+//
+//	go run ./life [-bench] [-generations 200]
+//
+// With -bench, the program runs the generations without a window at
+// several board sizes and prints the generations per second and the
+// allocations of each, advancing the board alone and together with
+// recording the operations of a frame. The boards are the same in every
+// run, which makes the results comparable between machines and versions
+// of Gio.
+
 import (
+	"flag"
+	"fmt"
 	"image"
 	"log"
 	"os"
@@ -23,7 +39,21 @@ var (
 	boardSize = image.Pt(50, 50)
 )
 
+var (
+	benchFlag       = flag.Bool("bench", false, "run the benchmark without a window, print the results and exit")
+	generationsFlag = flag.Int("generations", 200, "number of generations for each board of the benchmark")
+)
+
 func main() {
+	flag.Parse()
+	if *benchFlag {
+		if err := bench(os.Stdout, *generationsFlag); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// The ui loop is separated from the application window creation
 	// such that it can be used for testing.
 	ui := NewUI()
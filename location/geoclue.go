@@ -0,0 +1,181 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build (linux && !android) || freebsd || openbsd
+// +build linux,!android freebsd openbsd
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// geoclue reports the location from GeoClue, the location service of
+// desktop systems. GeoClue asks the user, through the agent of the
+// desktop, whether an application may see the location when it starts.
+type geoclue struct {
+	conn    *dbus.Conn
+	client  dbus.BusObject
+	path    dbus.ObjectPath
+	fixes   chan Fix
+	signals chan *dbus.Signal
+
+	mu      sync.Mutex
+	running bool
+}
+
+const (
+	geoclueName     = "org.freedesktop.GeoClue2"
+	geoclueManager  = "org.freedesktop.GeoClue2.Manager"
+	geoclueClient   = "org.freedesktop.GeoClue2.Client"
+	geoclueLocation = "org.freedesktop.GeoClue2.Location"
+
+	// accuracyExact is GCLUE_ACCURACY_LEVEL_EXACT, asking for satellite
+	// positioning where there is a receiver.
+	accuracyExact = 8
+)
+
+func newSystemProvider() (Provider, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, fmt.Errorf("geoclue: %w", err)
+	}
+	g := &geoclue{
+		conn:    conn,
+		fixes:   make(chan Fix, 1),
+		signals: make(chan *dbus.Signal, 8),
+	}
+	if err := g.init(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("geoclue: %w", err)
+	}
+	return g, nil
+}
+
+func (g *geoclue) init() error {
+	mgr := g.conn.Object(geoclueName, "/org/freedesktop/GeoClue2/Manager")
+	if err := mgr.Call(geoclueManager+".GetClient", 0).Store(&g.path); err != nil {
+		return err
+	}
+	g.client = g.conn.Object(geoclueName, g.path)
+	// The desktop id names the application in the question to the user
+	// and in the permissions they grant.
+	if err := g.client.SetProperty(geoclueClient+".DesktopId", dbus.MakeVariant("gio-location")); err != nil {
+		return err
+	}
+	if err := g.client.SetProperty(geoclueClient+".RequestedAccuracyLevel", dbus.MakeVariant(uint32(accuracyExact))); err != nil {
+		return err
+	}
+	err := g.conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(g.path),
+		dbus.WithMatchInterface(geoclueClient),
+		dbus.WithMatchMember("LocationUpdated"),
+	)
+	if err != nil {
+		return err
+	}
+	g.conn.Signal(g.signals)
+	go g.watch()
+	return nil
+}
+
+// watch turns LocationUpdated signals into fixes.
+func (g *geoclue) watch() {
+	for s := range g.signals {
+		if s.Path != g.path || s.Name != geoclueClient+".LocationUpdated" || len(s.Body) != 2 {
+			continue
+		}
+		loc, ok := s.Body[1].(dbus.ObjectPath)
+		if !ok {
+			continue
+		}
+		f, err := g.location(loc)
+		if err != nil {
+			continue
+		}
+		select {
+		case <-g.fixes:
+		default:
+		}
+		g.fixes <- f
+	}
+}
+
+// location reads the fix of a Location object.
+func (g *geoclue) location(path dbus.ObjectPath) (Fix, error) {
+	obj := g.conn.Object(geoclueName, path)
+	var props map[string]dbus.Variant
+	if err := obj.Call("org.freedesktop.DBus.Properties.GetAll", 0, geoclueLocation).Store(&props); err != nil {
+		return Fix{}, err
+	}
+	num := func(name string) float64 {
+		v, _ := props[name].Value().(float64)
+		return v
+	}
+	f := Fix{
+		LatLng:   LatLng{Lat: num("Latitude"), Lng: num("Longitude")},
+		Accuracy: num("Accuracy"),
+		Heading:  num("Heading"),
+		Speed:    num("Speed"),
+		Time:     time.Now(),
+	}
+	// GeoClue reports unknown heading and speed as -1.
+	if f.Heading < 0 {
+		f.Heading = math.NaN()
+	}
+	if f.Speed < 0 {
+		f.Speed = math.NaN()
+	}
+	// The timestamp is seconds and microseconds since the epoch.
+	if ts, ok := props["Timestamp"].Value().([]interface{}); ok && len(ts) == 2 {
+		sec, _ := ts[0].(uint64)
+		usec, _ := ts[1].(uint64)
+		if sec > 0 {
+			f.Time = time.Unix(int64(sec), int64(usec)*1000)
+		}
+	}
+	return f, nil
+}
+
+func (g *geoclue) Start() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.running {
+		return nil
+	}
+	if err := g.client.Call(geoclueClient+".Start", 0).Err; err != nil {
+		if e, ok := err.(dbus.Error); ok && e.Name == "org.freedesktop.DBus.Error.AccessDenied" {
+			return ErrDenied
+		}
+		return fmt.Errorf("geoclue: %w", err)
+	}
+	g.running = true
+	return nil
+}
+
+func (g *geoclue) Stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.running {
+		return
+	}
+	g.client.Call(geoclueClient+".Stop", 0)
+	g.running = false
+}
+
+func (g *geoclue) Fixes() <-chan Fix {
+	return g.fixes
+}
+
+func (g *geoclue) Close() error {
+	g.Stop()
+	g.conn.RemoveSignal(g.signals)
+	close(g.signals)
+	mgr := g.conn.Object(geoclueName, "/org/freedesktop/GeoClue2/Manager")
+	mgr.Call(geoclueManager+".DeleteClient", 0, g.path)
+	return g.conn.Close()
+}
@@ -0,0 +1,14 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build !((linux && !android) || freebsd || openbsd)
+// +build !linux android
+// +build !freebsd
+// +build !openbsd
+
+package main
+
+import "errors"
+
+func newSystemProvider() (Provider, error) {
+	return nil, errors.New("location: no location service on this platform")
+}
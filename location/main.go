@@ -0,0 +1,584 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program shows where you are on a map: the position reported by
+// the location service, a circle of its accuracy and a cone pointing in
+// the direction of travel. The map follows the position until you drag
+// it; Follow brings it back.
+//
+// The location is asked for when the window comes to the foreground and
+// given up when it goes to the background, for a location service keeps
+// the radios of a phone busy. The service asks the user for permission
+// when it starts; if they refuse, the map says so and offers to ask
+// again.
+//
+// The location comes from GeoClue on Linux and BSD desktops. Gio has no
+// location service or permission of its own, so elsewhere, or with
+// -simulate, a simulated walk around a park stands in for it.
+//
+// The map is drawn from OpenStreetMap tiles, or plain tiles with
+// -tiles "".
+//
+// Usage:
+//
+//	go run ./location [-simulate] [-tiles url]
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/pointer"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+var (
+	simulateFlag = flag.Bool("simulate", false, "simulate a walk instead of using the location service")
+	tilesFlag    = flag.String("tiles", "https://tile.openstreetmap.org/{z}/{x}/{y}.png", "URL template of the map tiles, or empty for plain tiles")
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	errorColor  = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	markerColor = color.NRGBA{R: 0x1e, G: 0x88, B: 0xe5, A: 0xff}
+	staleColor  = color.NRGBA{R: 0x90, G: 0x90, B: 0x90, A: 0xff}
+)
+
+const (
+	minZoom = 2
+	maxZoom = 19
+	// followZoom is the zoom the map jumps to at the first fix.
+	followZoom = 16
+	// staleAfter is the age of a fix shown as out of date.
+	staleAfter = 10 * time.Second
+	// maxTiles is the number of tiles kept in memory.
+	maxTiles = 256
+)
+
+// simCenter is the middle of the simulated walk, the King's Garden in
+// Copenhagen.
+var simCenter = LatLng{Lat: 55.6853, Lng: 12.5794}
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Location"),
+			app.Size(unit.Dp(900), unit.Dp(700)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// openProvider opens the location service, falling back to the
+// simulator. It returns a description of the provider.
+func openProvider() (Provider, string, error) {
+	if *simulateFlag {
+		return newSimulator(simCenter), "Simulated walk", nil
+	}
+	p, err := newSystemProvider()
+	if err != nil {
+		return newSimulator(simCenter), "Simulated walk", err
+	}
+	return p, "Location service", nil
+}
+
+type App struct {
+	prov   Provider
+	source string
+	// started receives the result of starting the provider.
+	started chan error
+	// foreground tracks the stage of the window; starting is set while
+	// the provider starts and live while it reports fixes.
+	foreground, starting, live bool
+	denied                     bool
+	err                        error
+
+	fix    Fix
+	hasFix bool
+
+	tiles *Tiles
+	view  mapView
+
+	zoomIn, zoomOut, follow, retry widget.Clickable
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	prov, desc, err := openProvider()
+	defer prov.Close()
+	a := &App{
+		prov:    prov,
+		source:  desc,
+		err:     err,
+		started: make(chan error, 1),
+		tiles:   NewTiles(*tilesFlag, w.Invalidate),
+		view:    mapView{cx: 0.5, cy: 0.5, zoom: minZoom, following: true},
+	}
+	var ops op.Ops
+	for {
+		select {
+		case f := <-prov.Fixes():
+			a.update(f)
+			w.Invalidate()
+		case err := <-a.started:
+			a.startDone(err)
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.StageEvent:
+				if e.Stage >= system.StageRunning {
+					a.resume()
+				} else {
+					a.pause()
+				}
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+// resume starts the provider when the window comes to the foreground,
+// unless the user refused.
+func (a *App) resume() {
+	a.foreground = true
+	if a.starting || a.live || a.denied {
+		return
+	}
+	a.starting = true
+	go func() {
+		a.started <- a.prov.Start()
+	}()
+}
+
+// pause stops the provider when the window goes to the background. A
+// provider still starting is stopped when it is done.
+func (a *App) pause() {
+	a.foreground = false
+	if a.live {
+		a.prov.Stop()
+		a.live = false
+	}
+}
+
+func (a *App) startDone(err error) {
+	a.starting = false
+	switch {
+	case errors.Is(err, ErrDenied):
+		a.denied = true
+	case err != nil:
+		a.err = err
+	case !a.foreground:
+		a.prov.Stop()
+	default:
+		a.live = true
+		a.err = nil
+	}
+}
+
+// update shows a new fix, moving the map along when it follows.
+func (a *App) update(f Fix) {
+	if !a.live {
+		// The last fix of a provider just stopped.
+		return
+	}
+	first := !a.hasFix
+	a.fix, a.hasFix = f, true
+	if a.view.following {
+		a.view.cx, a.view.cy = project(f.LatLng)
+		if first {
+			a.view.zoom = followZoom
+		}
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	if a.zoomIn.Clicked() {
+		a.view.zoomBy(1, f32.Point{})
+	}
+	if a.zoomOut.Clicked() {
+		a.view.zoomBy(-1, f32.Point{})
+	}
+	if a.follow.Clicked() {
+		a.view.following = true
+		if a.hasFix {
+			a.view.cx, a.view.cy = project(a.fix.LatLng)
+			if a.view.zoom < followZoom-2 {
+				a.view.zoom = followZoom
+			}
+		}
+	}
+	if a.retry.Clicked() {
+		a.denied, a.err = false, nil
+		if a.foreground {
+			a.resume()
+		}
+	}
+
+	var fix *Fix
+	if a.hasFix {
+		fix = &a.fix
+		// Redraw as the fix ages.
+		op.InvalidateOp{At: gtx.Now.Add(time.Second)}.Add(gtx.Ops)
+	}
+	stale := !a.live || gtx.Now.Sub(a.fix.Time) > staleAfter
+	a.view.Layout(gtx, a.tiles, fix, stale)
+
+	layout.NE.Layout(gtx, func(gtx C) D {
+		return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical, Alignment: layout.End}.Layout(gtx,
+				layout.Rigid(material.Button(th, &a.zoomIn, "+").Layout),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+				layout.Rigid(material.Button(th, &a.zoomOut, "−").Layout),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+				layout.Rigid(func(gtx C) D {
+					b := material.Button(th, &a.follow, "Follow")
+					if a.view.following {
+						b.Background = markerColor
+					} else {
+						b.Background = staleColor
+					}
+					return b.Layout(gtx)
+				}),
+			)
+		})
+	})
+	layout.SW.Layout(gtx, func(gtx C) D {
+		return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+			return a.layoutStatus(gtx, th)
+		})
+	})
+	if strings.Contains(*tilesFlag, "openstreetmap.org") {
+		layout.SE.Layout(gtx, func(gtx C) D {
+			macro := op.Record(gtx.Ops)
+			dims := layout.Inset{Left: unit.Dp(4), Right: unit.Dp(4)}.Layout(gtx,
+				material.Caption(th, "© OpenStreetMap contributors").Layout)
+			call := macro.Stop()
+			paint.FillShape(gtx.Ops, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xb0}, clip.Rect{Max: dims.Size}.Op())
+			call.Add(gtx.Ops)
+			return dims
+		})
+	}
+	return D{Size: gtx.Constraints.Max}
+}
+
+// layoutStatus draws a card with the state of the provider and the
+// latest fix.
+func (a *App) layoutStatus(gtx C, th *material.Theme) D {
+	var state string
+	var stateColor color.NRGBA
+	switch {
+	case a.denied:
+		state, stateColor = "Permission to see the location was refused", errorColor
+	case a.err != nil:
+		state, stateColor = a.err.Error(), errorColor
+	case a.starting:
+		state = "Asking for the location…"
+	case a.live:
+		state = "Live"
+	default:
+		state = "Paused in the background"
+	}
+	gtx.Constraints.Max.X = gtx.Px(unit.Dp(320))
+	macro := op.Record(gtx.Ops)
+	dims := layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				l := material.Body1(th, state)
+				if stateColor != (color.NRGBA{}) {
+					l.Color = stateColor
+				}
+				return l.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx C) D {
+				if !a.hasFix {
+					return D{}
+				}
+				f := a.fix
+				lines := []string{
+					fmt.Sprintf("%.5f°, %.5f° ± %.0f m", f.Lat, f.Lng, f.Accuracy),
+				}
+				var motion []string
+				if !math.IsNaN(f.Heading) {
+					motion = append(motion, fmt.Sprintf("heading %.0f°", f.Heading))
+				}
+				if !math.IsNaN(f.Speed) {
+					motion = append(motion, fmt.Sprintf("%.1f m/s", f.Speed))
+				}
+				if len(motion) > 0 {
+					lines = append(lines, strings.Join(motion, ", "))
+				}
+				lines = append(lines, "Updated "+age(gtx.Now.Sub(f.Time)))
+				return material.Body2(th, strings.Join(lines, "\n")).Layout(gtx)
+			}),
+			layout.Rigid(func(gtx C) D {
+				l := material.Caption(th, a.source)
+				l.Color = color.NRGBA{A: 0x90}
+				return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, l.Layout)
+			}),
+			layout.Rigid(func(gtx C) D {
+				if !a.denied && a.err == nil {
+					return D{}
+				}
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, material.Button(th, &a.retry, "Ask again").Layout)
+			}),
+		)
+	})
+	call := macro.Stop()
+	rr := float32(gtx.Px(unit.Dp(6)))
+	paint.FillShape(gtx.Ops, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xe8},
+		clip.UniformRRect(f32.Rectangle{Max: layout.FPt(dims.Size)}, rr).Op(gtx.Ops))
+	call.Add(gtx.Ops)
+	return dims
+}
+
+// age formats the age of a fix.
+func age(d time.Duration) string {
+	switch {
+	case d < 2*time.Second:
+		return "just now"
+	case d < time.Minute:
+		return fmt.Sprintf("%d s ago", int(d.Seconds()))
+	default:
+		return fmt.Sprintf("%d min ago", int(d.Minutes()))
+	}
+}
+
+// mapView is a slippy map: a Web Mercator map of tiles, panned by
+// dragging and zoomed by scrolling.
+type mapView struct {
+	// cx and cy are the world position at the center of the view.
+	cx, cy float64
+	zoom   float64
+	// following keeps the fix in the center until the map is dragged.
+	following bool
+
+	dragging bool
+	last     f32.Point
+	size     image.Point
+	// tilePx is the size of tiles at whole zoom levels, in pixels.
+	tilePx float64
+}
+
+// Layout draws the map and the fix, if any. A stale fix is drawn gray
+// and without heading.
+func (v *mapView) Layout(gtx C, tiles *Tiles, fix *Fix, stale bool) D {
+	v.size = gtx.Constraints.Max
+	v.tilePx = float64(gtx.Px(unit.Dp(tileSize)))
+	v.events(gtx)
+
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect{Max: v.size}.Add(gtx.Ops)
+	pointer.Rect(image.Rectangle{Max: v.size}).Add(gtx.Ops)
+	pointer.InputOp{
+		Tag:   v,
+		Grab:  v.dragging,
+		Types: pointer.Press | pointer.Drag | pointer.Release | pointer.Scroll,
+		// The router clamps scroll distances to the bounds; the zoom is
+		// clamped instead.
+		ScrollBounds: image.Rect(0, -1e6, 0, 1e6),
+	}.Add(gtx.Ops)
+	paint.Fill(gtx.Ops, color.NRGBA{R: 0xaa, G: 0xd3, B: 0xdf, A: 0xff})
+
+	tiles.Frame()
+	// Draw the tiles of the nearest whole zoom level, scaled.
+	z := int(math.Round(v.zoom))
+	n := 1 << z
+	worldPx := v.worldPx()
+	tilePx := worldPx / float64(n)
+	left := v.cx*worldPx - float64(v.size.X)/2
+	top := v.cy*worldPx - float64(v.size.Y)/2
+	x0, x1 := int(math.Floor(left/tilePx)), int(math.Floor((left+float64(v.size.X))/tilePx))
+	y0, y1 := int(math.Floor(top/tilePx)), int(math.Floor((top+float64(v.size.Y))/tilePx))
+	for y := y0; y <= y1; y++ {
+		if y < 0 || y >= n {
+			continue
+		}
+		for x := x0; x <= x1; x++ {
+			// The world repeats east and west.
+			k := tileKey{z: z, x: ((x % n) + n) % n, y: y}
+			pos := f32.Pt(float32(float64(x)*tilePx-left), float32(float64(y)*tilePx-top))
+			v.drawTile(gtx, tiles, k, pos, float32(tilePx))
+		}
+	}
+	tiles.Evict(maxTiles)
+
+	if fix != nil {
+		wx, wy := project(fix.LatLng)
+		// The copy of the world nearest the center.
+		wx += math.Round(v.cx - wx)
+		p := f32.Pt(float32((wx-v.cx)*worldPx+float64(v.size.X)/2), float32((wy-v.cy)*worldPx+float64(v.size.Y)/2))
+		r := float32(fix.Accuracy / metersPerWorld(fix.Lat) * worldPx)
+		drawMarker(gtx, p, r, fix.Heading, stale)
+	}
+	return D{Size: v.size}
+}
+
+// worldPx returns the size of the world at the zoom of the view, in
+// pixels.
+func (v *mapView) worldPx() float64 {
+	return v.tilePx * math.Exp2(v.zoom)
+}
+
+// drawTile draws the tile k at pos, or the nearest loaded tile of a
+// lower level scaled up and clipped to the area of k.
+func (v *mapView) drawTile(gtx C, tiles *Tiles, k tileKey, pos f32.Point, size float32) {
+	img, ok := tiles.Get(k)
+	src := k
+	for !ok && src.z > 0 && k.z-src.z < 4 {
+		src = tileKey{z: src.z - 1, x: src.x / 2, y: src.y / 2}
+		img, ok = tiles.Peek(src)
+	}
+	if !ok {
+		return
+	}
+	defer op.Save(gtx.Ops).Load()
+	round := func(v float32) int { return int(math.Round(float64(v))) }
+	clip.Rect{
+		Min: image.Pt(round(pos.X), round(pos.Y)),
+		Max: image.Pt(round(pos.X+size), round(pos.Y+size)),
+	}.Add(gtx.Ops)
+	// The position of src relative to k, in tiles.
+	d := k.z - src.z
+	scale := float32(int(1) << d)
+	off := f32.Pt(
+		float32(k.x-src.x<<d),
+		float32(k.y-src.y<<d),
+	).Mul(size)
+	s := size * scale / tileSize
+	op.Affine(f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(s, s)).Offset(pos.Sub(off))).Add(gtx.Ops)
+	img.Add(gtx.Ops)
+	clip.Rect{Max: image.Pt(tileSize, tileSize)}.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+}
+
+// drawMarker draws the fix at p with its accuracy circle of radius r
+// and, if known, a cone in the direction of heading.
+func drawMarker(gtx C, p f32.Point, r float32, heading float64, stale bool) {
+	col := markerColor
+	if stale {
+		col = staleColor
+	}
+	dot := float32(gtx.Px(unit.Dp(7)))
+	if r > dot {
+		fill := col
+		fill.A = 0x30
+		paint.FillShape(gtx.Ops, fill, clip.Circle{Center: p, Radius: r}.Op(gtx.Ops))
+		edge := col
+		edge.A = 0x90
+		paint.FillShape(gtx.Ops, edge, clip.Stroke{
+			Path:  clip.Circle{Center: p, Radius: r}.Path(gtx.Ops),
+			Style: clip.StrokeStyle{Width: float32(gtx.Px(unit.Dp(1)))},
+		}.Op())
+	}
+	if !stale && !math.IsNaN(heading) {
+		// A cone of 60°, fading out with distance.
+		length := float32(gtx.Px(unit.Dp(48)))
+		dir := func(deg, l float64) f32.Point {
+			s, c := math.Sincos(deg * math.Pi / 180)
+			// Screen y grows downwards, and bearings clockwise from
+			// north.
+			return p.Add(f32.Pt(float32(s*l), float32(-c*l)))
+		}
+		var path clip.Path
+		path.Begin(gtx.Ops)
+		path.MoveTo(p)
+		path.LineTo(dir(heading-30, float64(length)))
+		path.QuadTo(dir(heading, float64(length)*1.15), dir(heading+30, float64(length)))
+		path.Close()
+		stack := op.Save(gtx.Ops)
+		clip.Outline{Path: path.End()}.Op().Add(gtx.Ops)
+		far := col
+		far.A = 0
+		near := col
+		near.A = 0xa0
+		paint.LinearGradientOp{Stop1: p, Color1: near, Stop2: dir(heading, float64(length)), Color2: far}.Add(gtx.Ops)
+		paint.PaintOp{}.Add(gtx.Ops)
+		stack.Load()
+	}
+	ring := float32(gtx.Px(unit.Dp(2)))
+	paint.FillShape(gtx.Ops, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, clip.Circle{Center: p, Radius: dot + ring}.Op(gtx.Ops))
+	paint.FillShape(gtx.Ops, col, clip.Circle{Center: p, Radius: dot}.Op(gtx.Ops))
+}
+
+func (v *mapView) events(gtx C) {
+	for _, e := range gtx.Events(v) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case pointer.Press:
+			v.dragging = true
+			v.last = e.Position
+		case pointer.Drag:
+			d := e.Position.Sub(v.last)
+			v.last = e.Position
+			if d == (f32.Point{}) {
+				continue
+			}
+			v.cx -= float64(d.X) / v.worldPx()
+			v.cy -= float64(d.Y) / v.worldPx()
+			v.clamp()
+			v.following = false
+		case pointer.Release, pointer.Cancel:
+			v.dragging = false
+		case pointer.Scroll:
+			// Zoom around the pointer, or around the fix when following.
+			at := e.Position.Sub(layout.FPt(v.size).Mul(.5))
+			if v.following {
+				at = f32.Point{}
+			}
+			v.zoomBy(-float64(e.Scroll.Y)/100, at)
+		}
+	}
+}
+
+// zoomBy zooms by steps levels, keeping the map position at offset at
+// from the center in place.
+func (v *mapView) zoomBy(steps float64, at f32.Point) {
+	zoom := math.Max(minZoom, math.Min(maxZoom, v.zoom+steps))
+	// The offset in world units shrinks by the change of scale.
+	f := 1 - math.Exp2(v.zoom-zoom)
+	v.cx += float64(at.X) / v.worldPx() * f
+	v.cy += float64(at.Y) / v.worldPx() * f
+	v.zoom = zoom
+	v.clamp()
+}
+
+// clamp keeps the center on the map, wrapping around east and west.
+func (v *mapView) clamp() {
+	v.cx -= math.Floor(v.cx)
+	v.cy = math.Max(0, math.Min(1, v.cy))
+}
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"math"
+)
+
+// LatLng is a position on the earth, in degrees.
+type LatLng struct {
+	Lat, Lng float64
+}
+
+const (
+	// earthRadius is the radius of the sphere of Web Mercator, in
+	// meters.
+	earthRadius = 6378137
+	// maxLat is the latitude of the top and bottom edges of the map,
+	// where the world becomes a square.
+	maxLat = 85.0511287798066
+)
+
+// project returns the Web Mercator position of p, in world units: the
+// world spans 0 to 1, with (0, 0) at its top left corner. A world at zoom
+// level z is 2^z tiles across.
+func project(p LatLng) (x, y float64) {
+	lat := math.Max(-maxLat, math.Min(maxLat, p.Lat)) * math.Pi / 180
+	x = (p.Lng + 180) / 360
+	y = (1 - math.Log(math.Tan(lat)+1/math.Cos(lat))/math.Pi) / 2
+	return x, y
+}
+
+// unproject is the inverse of project.
+func unproject(x, y float64) LatLng {
+	lat := math.Atan(math.Sinh(math.Pi * (1 - 2*y)))
+	return LatLng{Lat: lat * 180 / math.Pi, Lng: x*360 - 180}
+}
+
+// metersPerWorld returns the number of meters a world unit spans at
+// latitude lat. Mercator stretches the map away from the equator, so
+// the scale depends on the latitude.
+func metersPerWorld(lat float64) float64 {
+	return 2 * math.Pi * earthRadius * math.Cos(lat*math.Pi/180)
+}
+
+// move returns the position dist meters from p in the direction of
+// bearing, in degrees clockwise from north.
+func move(p LatLng, bearing, dist float64) LatLng {
+	lat1, lng1 := p.Lat*math.Pi/180, p.Lng*math.Pi/180
+	b := bearing * math.Pi / 180
+	d := dist / earthRadius
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(b))
+	lng2 := lng1 + math.Atan2(math.Sin(b)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+	return LatLng{Lat: lat2 * 180 / math.Pi, Lng: lng2 * 180 / math.Pi}
+}
+
+// distance returns the great circle distance between a and b in meters.
+func distance(a, b LatLng) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dlat := lat2 - lat1
+	dlng := (b.Lng - a.Lng) * math.Pi / 180
+	h := math.Sin(dlat/2)*math.Sin(dlat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dlng/2)*math.Sin(dlng/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// bearing returns the initial direction from a to b, in degrees clockwise
+// from north.
+func bearing(a, b LatLng) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dlng := (b.Lng - a.Lng) * math.Pi / 180
+	y := math.Sin(dlng) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dlng)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"math"
+	"testing"
+
+	"gioui.org/f32"
+)
+
+func TestProject(t *testing.T) {
+	tests := []struct {
+		p    LatLng
+		x, y float64
+	}{
+		{LatLng{0, 0}, 0.5, 0.5},
+		{LatLng{maxLat, -180}, 0, 0},
+		{LatLng{-maxLat, 180}, 1, 1},
+		// Beyond the edges of the map.
+		{LatLng{90, 0}, 0.5, 0},
+	}
+	for _, test := range tests {
+		x, y := project(test.p)
+		if math.Abs(x-test.x) > 1e-9 || math.Abs(y-test.y) > 1e-9 {
+			t.Errorf("project(%v) = %v, %v, want %v, %v", test.p, x, y, test.x, test.y)
+		}
+	}
+	for _, p := range []LatLng{{55.6853, 12.5794}, {-33.8568, 151.2153}, {0, -179.5}} {
+		got := unproject(project(p))
+		if math.Abs(got.Lat-p.Lat) > 1e-9 || math.Abs(got.Lng-p.Lng) > 1e-9 {
+			t.Errorf("unproject(project(%v)) = %v", p, got)
+		}
+	}
+}
+
+func TestMove(t *testing.T) {
+	start := LatLng{55.6853, 12.5794}
+	for _, b := range []float64{0, 45, 90, 200, 315} {
+		end := move(start, b, 1000)
+		if d := distance(start, end); math.Abs(d-1000) > 1e-6 {
+			t.Errorf("moved 1000 m towards %v°, but distance is %v m", b, d)
+		}
+		if got := bearing(start, end); math.Abs(got-b) > 1e-3 {
+			t.Errorf("moved towards %v°, but bearing is %v°", b, got)
+		}
+	}
+	// A meter on the map is a meter at the equator.
+	x0, _ := project(LatLng{0, 0})
+	x1, _ := project(move(LatLng{0, 0}, 90, 1000))
+	if got := (x1 - x0) * metersPerWorld(0); math.Abs(got-1000) > 1e-6 {
+		t.Errorf("1000 m east spans %v m of the map", got)
+	}
+}
+
+func TestZoomBy(t *testing.T) {
+	v := &mapView{cx: 0.3, cy: 0.6, zoom: 10, tilePx: tileSize}
+	at := f32.Pt(120, -80)
+	pos := func() (float64, float64) {
+		return v.cx + float64(at.X)/v.worldPx(), v.cy + float64(at.Y)/v.worldPx()
+	}
+	x0, y0 := pos()
+	v.zoomBy(1.5, at)
+	x1, y1 := pos()
+	if math.Abs(x1-x0) > 1e-12 || math.Abs(y1-y0) > 1e-12 {
+		t.Errorf("the point zoomed around moved from %v, %v to %v, %v", x0, y0, x1, y1)
+	}
+	v.zoomBy(100, at)
+	if v.zoom != maxZoom {
+		t.Errorf("zoom %v beyond the maximum %v", v.zoom, maxZoom)
+	}
+}
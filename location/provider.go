@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Fix is a position reported by a location provider.
+type Fix struct {
+	LatLng
+	// Accuracy is the radius in meters around the position the true
+	// position is likely within.
+	Accuracy float64
+	// Heading is the direction of travel in degrees clockwise from
+	// north, or NaN when unknown.
+	Heading float64
+	// Speed is in meters per second, or NaN when unknown.
+	Speed float64
+	Time  time.Time
+}
+
+// Provider reports the position of the device.
+type Provider interface {
+	// Start asks for permission to use the location, if needed, and
+	// starts reporting fixes. It may block while the user is asked, and
+	// returns ErrDenied if they refuse.
+	Start() error
+	// Stop stops reporting fixes until the next Start.
+	Stop()
+	// Fixes returns the channel of fixes.
+	Fixes() <-chan Fix
+	Close() error
+}
+
+// ErrDenied is returned by Start when the user or the system refuses
+// access to the location.
+var ErrDenied = errors.New("location: permission denied")
+
+// simulator walks around a park, reporting fixes once a second with the
+// noise and varying accuracy of a real receiver.
+type simulator struct {
+	center LatLng
+	fixes  chan Fix
+	start  time.Time
+	rnd    *rand.Rand
+
+	mu   sync.Mutex
+	stop chan struct{}
+}
+
+const (
+	// simRadius is the radius of the walk, in meters.
+	simRadius = 250
+	// simSpeed is the walking speed, in meters per second.
+	simSpeed = 1.4
+)
+
+func newSimulator(center LatLng) *simulator {
+	return &simulator{
+		center: center,
+		fixes:  make(chan Fix, 1),
+		start:  time.Now(),
+		rnd:    rand.New(rand.NewSource(1)),
+	}
+}
+
+func (s *simulator) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return nil
+	}
+	s.stop = make(chan struct{})
+	go s.run(s.stop)
+	return nil
+}
+
+func (s *simulator) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+func (s *simulator) Fixes() <-chan Fix {
+	return s.fixes
+}
+
+func (s *simulator) Close() error {
+	s.Stop()
+	return nil
+}
+
+func (s *simulator) run(stop <-chan struct{}) {
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+	for {
+		f := s.fix(time.Now())
+		// Replace a fix not yet taken.
+		select {
+		case <-s.fixes:
+		default:
+		}
+		s.fixes <- f
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// fix returns the fix at time now. The walk goes on while the simulator
+// is stopped, as the user would.
+func (s *simulator) fix(now time.Time) Fix {
+	t := now.Sub(s.start).Seconds()
+	pos := s.position(t)
+	// Accuracy worsens now and then, as under trees.
+	acc := 8 + 6*math.Sin(t/7)
+	if math.Mod(t, 60) > 45 {
+		acc += 35
+	}
+	// Scatter the reported position by about the accuracy. The walk of
+	// a stopped simulator may still be sending its last fix.
+	s.mu.Lock()
+	noisy := move(pos, s.rnd.Float64()*360, s.rnd.NormFloat64()*acc/3)
+	s.mu.Unlock()
+	return Fix{
+		LatLng:   noisy,
+		Accuracy: acc,
+		Heading:  bearing(pos, s.position(t+1)),
+		Speed:    simSpeed,
+		Time:     now,
+	}
+}
+
+// position returns the true position t seconds into the walk.
+func (s *simulator) position(t float64) LatLng {
+	// The angle around the park, and a wobble of the path.
+	a := t * simSpeed / simRadius
+	r := simRadius + 40*math.Sin(a*5)
+	return move(s.center, a*180/math.Pi, r)
+}
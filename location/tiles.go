@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gioui.org/op/paint"
+)
+
+// tileSize is the size of map tiles, in pixels.
+const tileSize = 256
+
+// tileKey identifies a map tile by its zoom level, column and row.
+type tileKey struct {
+	z, x, y int
+}
+
+type tile struct {
+	img    paint.ImageOp
+	loaded bool
+	// used is the frame number the tile was last drawn.
+	used int
+}
+
+// Tiles loads map tiles in the background, from a tile server or drawn
+// locally, and keeps the most recently used in memory.
+type Tiles struct {
+	// url is the template of tile URLs with {z}, {x} and {y} in place
+	// of the tile key. Tiles are drawn locally when it is empty.
+	url     string
+	changed func()
+	// sem limits the number of tiles loading at once.
+	sem chan struct{}
+
+	mu    sync.Mutex
+	tiles map[tileKey]*tile
+	frame int
+	err   error
+}
+
+func NewTiles(url string, changed func()) *Tiles {
+	return &Tiles{
+		url:     url,
+		changed: changed,
+		sem:     make(chan struct{}, 2),
+		tiles:   make(map[tileKey]*tile),
+	}
+}
+
+// Frame starts a new frame.
+func (t *Tiles) Frame() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.frame++
+}
+
+// Get returns the tile if it is loaded, and starts loading it if not.
+func (t *Tiles) Get(k tileKey) (paint.ImageOp, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tl, ok := t.tiles[k]
+	if !ok {
+		tl = &tile{}
+		t.tiles[k] = tl
+		go t.load(k, tl)
+	}
+	tl.used = t.frame
+	return tl.img, tl.loaded
+}
+
+// Peek returns the tile if it is loaded, without loading it.
+func (t *Tiles) Peek(k tileKey) (paint.ImageOp, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tl, ok := t.tiles[k]
+	if !ok || !tl.loaded {
+		return paint.ImageOp{}, false
+	}
+	tl.used = t.frame
+	return tl.img, true
+}
+
+// Err returns the last error loading a tile.
+func (t *Tiles) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// Evict drops the least recently used tiles until at most max remain.
+// Tiles used in the current frame are kept.
+func (t *Tiles) Evict(max int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for len(t.tiles) > max {
+		var (
+			oldest tileKey
+			found  bool
+			used   = t.frame
+		)
+		for k, tl := range t.tiles {
+			if tl.used < used {
+				oldest, used, found = k, tl.used, true
+			}
+		}
+		if !found {
+			return
+		}
+		delete(t.tiles, oldest)
+	}
+}
+
+func (t *Tiles) load(k tileKey, tl *tile) {
+	t.sem <- struct{}{}
+	defer func() { <-t.sem }()
+	var img image.Image
+	var err error
+	if t.url != "" {
+		img, err = t.fetch(k)
+	}
+	if img == nil {
+		// Stand in a drawn tile for one that failed to load.
+		img = drawTile(k)
+	}
+	t.mu.Lock()
+	if err != nil {
+		t.err = err
+	}
+	tl.img = paint.NewImageOp(img)
+	tl.loaded = true
+	t.mu.Unlock()
+	t.changed()
+}
+
+func (t *Tiles) fetch(k tileKey) (image.Image, error) {
+	url := strings.NewReplacer(
+		"{z}", strconv.Itoa(k.z),
+		"{x}", strconv.Itoa(k.x),
+		"{y}", strconv.Itoa(k.y),
+	).Replace(t.url)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Tile servers such as OpenStreetMap's refuse clients that don't
+	// identify themselves.
+	req.Header.Set("User-Agent", "gio-example-location/1.0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tile %d/%d/%d: %s", k.z, k.x, k.y, resp.Status)
+	}
+	img, _, err := image.Decode(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("tile %d/%d/%d: %w", k.z, k.x, k.y, err)
+	}
+	return img, nil
+}
+
+// drawTile draws a plain tile of a checkerboard, with the lines of its
+// children for a sense of scale.
+func drawTile(k tileKey) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
+	bg := color.RGBA{R: 0xee, G: 0xec, B: 0xe6, A: 0xff}
+	if (k.x+k.y)%2 == 1 {
+		bg = color.RGBA{R: 0xe4, G: 0xe2, B: 0xdb, A: 0xff}
+	}
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: bg}, image.Point{}, draw.Src)
+	line := color.RGBA{R: 0xc8, G: 0xc6, B: 0xbf, A: 0xff}
+	for i := 0; i < tileSize; i += tileSize / 4 {
+		draw.Draw(img, image.Rect(i, 0, i+1, tileSize), &image.Uniform{C: line}, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(0, i, tileSize, i+1), &image.Uniform{C: line}, image.Point{}, draw.Src)
+	}
+	return img
+}
@@ -0,0 +1,235 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"time"
+	"unicode"
+
+	"gioui.org/f32"
+	"gioui.org/io/clipboard"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+// shakeDuration is the length of the error animation.
+const shakeDuration = 400 * time.Millisecond
+
+var errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+
+// CodeInput is an entry for one-time codes and PINs, with a box for each
+// digit. Typing fills the boxes from the current one and moves on,
+// backspace clears the current box or the one before it, and pasting a
+// code fills all the boxes.
+type CodeInput struct {
+	Length int
+
+	digits   []rune
+	cursor   int
+	focused  bool
+	focus    bool
+	complete bool
+	// shakeStart is when the error animation started.
+	shakeStart time.Time
+	// boxes is the width of a box and the gap after it.
+	boxes int
+}
+
+// Code returns the digits entered.
+func (c *CodeInput) Code() string {
+	var s []rune
+	for _, d := range c.digits {
+		if d != 0 {
+			s = append(s, d)
+		}
+	}
+	return string(s)
+}
+
+// Completed returns the code the first time all the boxes are filled.
+func (c *CodeInput) Completed() (string, bool) {
+	if !c.complete {
+		return "", false
+	}
+	c.complete = false
+	return c.Code(), true
+}
+
+// Clear empties the boxes.
+func (c *CodeInput) Clear() {
+	c.digits = make([]rune, c.Length)
+	c.cursor = 0
+	c.complete = false
+}
+
+// Focus requests the input focus.
+func (c *CodeInput) Focus() {
+	c.focus = true
+}
+
+// Reject shakes the boxes to signal a wrong code, and clears them.
+func (c *CodeInput) Reject(now time.Time) {
+	c.shakeStart = now
+	c.Clear()
+}
+
+// fill writes the digits of s into the boxes from pos, ignoring other
+// characters, and returns the position after the last digit written.
+func fill(digits []rune, pos int, s string) int {
+	for _, r := range s {
+		if pos >= len(digits) {
+			break
+		}
+		if r < '0' || r > '9' {
+			continue
+		}
+		digits[pos] = r
+		pos++
+	}
+	return pos
+}
+
+func (c *CodeInput) input(s string) {
+	c.cursor = fill(c.digits, c.cursor, s)
+	if c.cursor >= c.Length {
+		c.cursor = c.Length - 1
+	}
+	if len(c.Code()) == c.Length {
+		c.complete = true
+	}
+}
+
+func (c *CodeInput) update(gtx layout.Context) {
+	if len(c.digits) != c.Length {
+		c.Clear()
+	}
+	for _, e := range gtx.Events(c) {
+		switch e := e.(type) {
+		case key.FocusEvent:
+			c.focused = e.Focus
+		case key.EditEvent:
+			c.input(e.Text)
+		case clipboard.Event:
+			// Paste a whole code from the start.
+			digits := 0
+			for _, r := range e.Text {
+				if unicode.IsDigit(r) {
+					digits++
+				}
+			}
+			if digits >= c.Length {
+				c.cursor = 0
+			}
+			c.input(e.Text)
+		case pointer.Event:
+			if e.Type != pointer.Press {
+				break
+			}
+			c.focus = true
+			if c.boxes > 0 {
+				i := int(e.Position.X) / c.boxes
+				if i >= 0 && i < c.Length {
+					c.cursor = i
+				}
+			}
+		case key.Event:
+			if e.State != key.Press {
+				break
+			}
+			switch {
+			case e.Name == "V" && e.Modifiers.Contain(key.ModShortcut):
+				clipboard.ReadOp{Tag: c}.Add(gtx.Ops)
+			case e.Name == key.NameDeleteBackward:
+				if c.digits[c.cursor] == 0 && c.cursor > 0 {
+					c.cursor--
+				}
+				c.digits[c.cursor] = 0
+			case e.Name == key.NameDeleteForward:
+				c.digits[c.cursor] = 0
+			case e.Name == key.NameLeftArrow && c.cursor > 0:
+				c.cursor--
+			case e.Name == key.NameRightArrow && c.cursor < c.Length-1:
+				c.cursor++
+			}
+		}
+	}
+	if c.complete {
+		// Redraw for the owner to see the completed code.
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+}
+
+func (c *CodeInput) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	c.update(gtx)
+	size := gtx.Px(unit.Dp(44))
+	gap := gtx.Px(unit.Dp(8))
+	c.boxes = size + gap
+	width := c.Length*c.boxes - gap
+
+	defer op.Save(gtx.Ops).Load()
+	if t := gtx.Now.Sub(c.shakeStart); t < shakeDuration {
+		// A damped oscillation.
+		p := float64(t) / float64(shakeDuration)
+		dx := float32(gtx.Px(unit.Dp(10))) * float32(math.Sin(p*6*math.Pi)*(1-p))
+		op.Offset(f32.Pt(dx, 0)).Add(gtx.Ops)
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+	// Keep the error border a while after the shake.
+	shaking := gtx.Now.Sub(c.shakeStart) < 2*shakeDuration
+	if shaking {
+		op.InvalidateOp{At: c.shakeStart.Add(2 * shakeDuration)}.Add(gtx.Ops)
+	}
+	for i := 0; i < c.Length; i++ {
+		stack := op.Save(gtx.Ops)
+		op.Offset(f32.Pt(float32(i*c.boxes), 0)).Add(gtx.Ops)
+		c.layoutBox(gtx, th, i, size, shaking)
+		stack.Load()
+	}
+	sz := image.Pt(width, size)
+	pointer.Rect(image.Rectangle{Max: sz}).Add(gtx.Ops)
+	pointer.CursorNameOp{Name: pointer.CursorText}.Add(gtx.Ops)
+	pointer.InputOp{Tag: c, Types: pointer.Press}.Add(gtx.Ops)
+	key.InputOp{Tag: c}.Add(gtx.Ops)
+	if c.focus {
+		key.FocusOp{Tag: c}.Add(gtx.Ops)
+		key.SoftKeyboardOp{Show: true}.Add(gtx.Ops)
+		c.focus = false
+	}
+	return layout.Dimensions{Size: sz}
+}
+
+func (c *CodeInput) layoutBox(gtx layout.Context, th *material.Theme, i, size int, shaking bool) {
+	rr := float32(gtx.Px(unit.Dp(6)))
+	bounds := f32.Rectangle{Max: f32.Pt(float32(size), float32(size))}
+	border := color.NRGBA{A: 0x40}
+	width := float32(gtx.Px(unit.Dp(1)))
+	switch {
+	case shaking:
+		border = errorColor
+		width *= 2
+	case c.focused && i == c.cursor:
+		border = th.Palette.ContrastBg
+		width *= 2
+	}
+	paint.FillShape(gtx.Ops, border, clip.UniformRRect(bounds, rr).Op(gtx.Ops))
+	inner := bounds
+	inner.Min = inner.Min.Add(f32.Pt(width, width))
+	inner.Max = inner.Max.Sub(f32.Pt(width, width))
+	paint.FillShape(gtx.Ops, th.Palette.Bg, clip.UniformRRect(inner, rr-width).Op(gtx.Ops))
+	if d := c.digits[i]; d != 0 {
+		gtx.Constraints = layout.Exact(image.Pt(size, size))
+		l := material.H5(th, string(d))
+		l.Alignment = text.Middle
+		layout.Center.Layout(gtx, l.Layout)
+	}
+}
@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"testing"
+)
+
+func TestFill(t *testing.T) {
+	tests := []struct {
+		start   string
+		pos     int
+		input   string
+		want    string
+		wantPos int
+	}{
+		{"______", 0, "12", "12____", 2},
+		{"12____", 2, "3a4", "1234__", 4},
+		{"______", 0, "123 456", "123456", 6},
+		{"1234__", 4, "56789", "123456", 6},
+		{"123456", 2, "0", "120456", 3},
+	}
+	for _, test := range tests {
+		digits := []rune(test.start)
+		for i, d := range digits {
+			if d == '_' {
+				digits[i] = 0
+			}
+		}
+		pos := fill(digits, test.pos, test.input)
+		got := []rune(test.want)
+		for i, d := range digits {
+			if d == 0 {
+				digits[i] = '_'
+			}
+		}
+		if string(digits) != string(got) || pos != test.wantPos {
+			t.Errorf("fill(%q, %d, %q) = %q, %d, want %q, %d", test.start, test.pos, test.input, string(digits), pos, test.want, test.wantPos)
+		}
+	}
+}
+
+func TestCodeInputComplete(t *testing.T) {
+	c := &CodeInput{Length: 4}
+	c.Clear()
+	c.input("12")
+	if _, ok := c.Completed(); ok {
+		t.Fatal("completed after 2 of 4 digits")
+	}
+	c.input("345")
+	code, ok := c.Completed()
+	if !ok || code != "1234" {
+		t.Fatalf("Completed() = %q, %v, want \"1234\", true", code, ok)
+	}
+	if _, ok := c.Completed(); ok {
+		t.Error("completed twice")
+	}
+	if c.cursor != 3 {
+		t.Errorf("cursor = %d, want it on the last box", c.cursor)
+	}
+}
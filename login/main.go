@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates a two-step sign in: a user name and password,
+// followed by a one-time code entered in a CodeInput. The code is checked
+// as soon as it is complete; a wrong code shakes the boxes. The correct
+// code is 123456.
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/style"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Sign In"),
+			app.Size(unit.Dp(420), unit.Dp(520)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+const validCode = "123456"
+
+type step int
+
+const (
+	credentials step = iota
+	verification
+	signedIn
+)
+
+type UI struct {
+	step     step
+	user     widget.Editor
+	password widget.Editor
+	signIn   widget.Clickable
+	code     CodeInput
+	resend   widget.Clickable
+	back     widget.Clickable
+	message  string
+	failed   bool
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	ui := &UI{
+		user:     widget.Editor{SingleLine: true, Submit: true},
+		password: widget.Editor{SingleLine: true, Submit: true, Mask: '•'},
+		code:     CodeInput{Length: len(validCode)},
+	}
+	ui.user.Focus()
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			ui.update(gtx)
+			layout.UniformInset(unit.Dp(24)).Layout(gtx, func(gtx C) D {
+				switch ui.step {
+				case credentials:
+					return ui.layoutCredentials(gtx, th)
+				case verification:
+					return ui.layoutVerification(gtx, th)
+				default:
+					return layout.Center.Layout(gtx, material.H5(th, fmt.Sprintf("Welcome, %s!", ui.user.Text())).Layout)
+				}
+			})
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+func (ui *UI) update(gtx C) {
+	submitted := false
+	for _, ed := range []*widget.Editor{&ui.user, &ui.password} {
+		for _, e := range ed.Events() {
+			if _, ok := e.(widget.SubmitEvent); ok {
+				submitted = true
+			}
+		}
+	}
+	for ui.signIn.Clicked() {
+		submitted = true
+	}
+	if submitted && ui.step == credentials && ui.user.Text() != "" && ui.password.Text() != "" {
+		ui.step = verification
+		ui.message = ""
+		ui.failed = false
+		ui.code.Clear()
+		ui.code.Focus()
+	}
+	if code, ok := ui.code.Completed(); ok {
+		if code == validCode {
+			ui.step = signedIn
+		} else {
+			ui.message = "That code is incorrect. Try again."
+			ui.failed = true
+			ui.code.Reject(gtx.Now)
+			ui.code.Focus()
+		}
+	}
+	for ui.resend.Clicked() {
+		ui.message = "A new code was sent."
+		ui.failed = false
+		ui.code.Clear()
+		ui.code.Focus()
+	}
+	for ui.back.Clicked() {
+		ui.step = credentials
+	}
+}
+
+func (ui *UI) layoutCredentials(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(material.H5(th, "Sign in").Layout),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(24)}.Layout),
+		layout.Rigid(func(gtx C) D {
+			f := style.Field(th, &ui.user, "User name")
+			f.Inset = layout.UniformInset(unit.Dp(10))
+			return f.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+		layout.Rigid(func(gtx C) D {
+			f := style.Field(th, &ui.password, "Password")
+			f.Inset = layout.UniformInset(unit.Dp(10))
+			return f.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(24)}.Layout),
+		layout.Rigid(func(gtx C) D {
+			if ui.user.Text() == "" || ui.password.Text() == "" {
+				gtx = gtx.Disabled()
+			}
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			return material.Button(th, &ui.signIn, "Continue").Layout(gtx)
+		}),
+	)
+}
+
+func (ui *UI) layoutVerification(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(material.H5(th, "Verify it's you").Layout),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+		layout.Rigid(material.Body1(th, fmt.Sprintf("Enter the %d-digit code sent to your phone.", ui.code.Length)).Layout),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(24)}.Layout),
+		layout.Rigid(func(gtx C) D {
+			return ui.code.Layout(gtx, th)
+		}),
+		layout.Rigid(func(gtx C) D {
+			l := material.Body2(th, ui.message)
+			if ui.failed {
+				l.Color = errorColor
+			}
+			return layout.Inset{Top: unit.Dp(12)}.Layout(gtx, l.Layout)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(24)}.Layout),
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Rigid(style.TextButton(th, &ui.back, "Back")),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+				layout.Rigid(style.TextButton(th, &ui.resend, "Resend code")),
+			)
+		}),
+	)
+}
@@ -0,0 +1,204 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// pageLines is the number of lines between index checkpoints, and the
+	// unit of reading and caching.
+	pageLines = 256
+	// cachePages is the number of pages kept in memory.
+	cachePages = 64
+	// maxLineLen is the length lines are shortened to for display.
+	maxLineLen = 2000
+)
+
+// LogFile is a view of a log file that is never loaded into memory. A
+// sparse index records the offset of every pageLines line, so any line can
+// be read by seeking to the page that contains it. The index is built and
+// extended by Follow as the file grows.
+type LogFile struct {
+	f *os.File
+
+	mu sync.Mutex
+	// checkpoints are the offsets of the lines k*pageLines.
+	checkpoints []int64
+	lines       int
+	// indexed is the offset after the last complete line.
+	indexed int64
+	cache   map[int][]string
+	// order lists the cached pages from the oldest.
+	order []int
+}
+
+// Open opens a log file. Its lines are available as Follow indexes them.
+func Open(path string) (*LogFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &LogFile{f: f, cache: make(map[int][]string)}, nil
+}
+
+func (l *LogFile) Close() error {
+	return l.f.Close()
+}
+
+// Lines returns the number of lines indexed.
+func (l *LogFile) Lines() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lines
+}
+
+// Size returns the number of bytes indexed.
+func (l *LogFile) Size() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.indexed
+}
+
+// Follow indexes the file and then polls it for new lines every interval,
+// until ctx is done. It calls changed when lines are added or the file is
+// truncated.
+func (l *LogFile) Follow(ctx context.Context, interval time.Duration, changed func()) error {
+	for {
+		if err := l.index(ctx, changed); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// index indexes the lines added since the last call.
+func (l *LogFile) index(ctx context.Context, changed func()) error {
+	fi, err := l.f.Stat()
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	// A truncated file, as by log rotation, is indexed anew.
+	truncated := fi.Size() < l.indexed
+	if truncated {
+		l.checkpoints, l.lines, l.indexed = nil, 0, 0
+		l.cache, l.order = make(map[int][]string), nil
+	}
+	off := l.indexed
+	l.mu.Unlock()
+	if truncated {
+		changed()
+	}
+	buf := make([]byte, 1<<20)
+	for ctx.Err() == nil {
+		n, err := l.f.ReadAt(buf, off)
+		chunk := buf[:n]
+		l.mu.Lock()
+		added := false
+		for {
+			i := bytes.IndexByte(chunk, '\n')
+			if i == -1 {
+				break
+			}
+			if l.lines%pageLines == 0 {
+				l.checkpoints = append(l.checkpoints, l.indexed)
+			}
+			l.lines++
+			l.indexed += int64(i + 1)
+			chunk = chunk[i+1:]
+			added = true
+		}
+		off = l.indexed
+		if added {
+			// The last page has new lines.
+			delete(l.cache, len(l.checkpoints)-1)
+		}
+		l.mu.Unlock()
+		if added {
+			changed()
+		}
+		if err == io.EOF || n == 0 {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !added && n == len(buf) {
+			// A line longer than the buffer; grow it.
+			buf = make([]byte, 2*len(buf))
+		}
+	}
+	return ctx.Err()
+}
+
+// Line returns line i, or the empty string if it is not indexed.
+func (l *LogFile) Line(i int) string {
+	p := l.page(i / pageLines)
+	if j := i % pageLines; j < len(p) {
+		return p[j]
+	}
+	return ""
+}
+
+// page returns the lines of page k.
+func (l *LogFile) page(k int) []string {
+	l.mu.Lock()
+	if p, ok := l.cache[k]; ok {
+		l.mu.Unlock()
+		return p
+	}
+	if k < 0 || k >= len(l.checkpoints) {
+		l.mu.Unlock()
+		return nil
+	}
+	start, end := l.checkpoints[k], l.indexed
+	if k+1 < len(l.checkpoints) {
+		end = l.checkpoints[k+1]
+	}
+	l.mu.Unlock()
+
+	buf := make([]byte, end-start)
+	if _, err := l.f.ReadAt(buf, start); err != nil && err != io.EOF {
+		return nil
+	}
+	text := strings.TrimSuffix(string(buf), "\n")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		if len(line) > maxLineLen {
+			line = line[:maxLineLen]
+			for !utf8.ValidString(line) {
+				line = line[:len(line)-1]
+			}
+			line += "…"
+		}
+		lines[i] = line
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.checkpoints) > k && l.checkpoints[k] == start {
+		if _, ok := l.cache[k]; !ok {
+			l.order = append(l.order, k)
+		}
+		l.cache[k] = lines
+		if len(l.order) > cachePages {
+			delete(l.cache, l.order[0])
+			l.order = l.order[1:]
+		}
+	}
+	return lines
+}
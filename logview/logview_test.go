@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeLog writes n lines with increasing timestamps from start, every
+// tenth an error.
+func writeLog(t *testing.T, f *os.File, start time.Time, from, n int) {
+	t.Helper()
+	var b strings.Builder
+	for i := from; i < from+n; i++ {
+		level := "INFO"
+		if i%10 == 0 {
+			level = "ERROR"
+		}
+		ts := start.Add(time.Duration(i) * time.Second).Format("2006-01-02 15:04:05")
+		fmt.Fprintf(&b, "%s %s line %d\n", ts, level, i)
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLogFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	w, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	start := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	writeLog(t, w, start, 0, 1000)
+	// An incomplete line isn't indexed.
+	w.WriteString("2021-05-01 13:00:00 INFO partial")
+
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	ctx := context.Background()
+	if err := l.index(ctx, func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if n := l.Lines(); n != 1000 {
+		t.Fatalf("got %d lines, want 1000", n)
+	}
+	for _, i := range []int{0, 255, 256, 999} {
+		if got, want := l.Line(i), fmt.Sprintf("line %d", i); !strings.HasSuffix(got, want) {
+			t.Errorf("line %d: got %q, want suffix %q", i, got, want)
+		}
+	}
+	if i := SearchTime(l, start.Add(500*time.Second)); i != 500 {
+		t.Errorf("got line %d for timestamp, want 500", i)
+	}
+
+	// Complete the line and append more; the last page must be reread.
+	w.WriteString(" done\n")
+	writeLog(t, w, start, 1001, 23)
+	if err := l.index(ctx, func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if n := l.Lines(); n != 1024 {
+		t.Fatalf("got %d lines after appending, want 1024", n)
+	}
+	if got := l.Line(1000); !strings.HasSuffix(got, "partial done") {
+		t.Errorf("got %q for the completed line", got)
+	}
+
+	f := NewFilter(regexp.MustCompile("ERROR"))
+	ctx, cancel := context.WithCancel(ctx)
+	f.Run(ctx, l, time.Millisecond, func() {
+		if f.Scanned() == l.Lines() {
+			cancel()
+		}
+	})
+	if n := f.Len(); n != 102 {
+		t.Errorf("got %d matches, want 102", n)
+	}
+	if m, _ := f.Match(1); m != 10 {
+		t.Errorf("got second match at line %d, want 10", m)
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	want := time.Date(2021, 5, 1, 12, 30, 15, 0, time.UTC)
+	for _, s := range []string{
+		"2021-05-01T12:30:15Z GET /",
+		"2021-05-01 12:30:15 INFO x",
+		"[2021/05/01 12:30:15] x",
+		"2021-05-01 12:30:15",
+	} {
+		got, ok := parseTimestamp(s)
+		if !ok || !got.Equal(want) {
+			t.Errorf("parseTimestamp(%q) = %v, %v, want %v", s, got, ok, want)
+		}
+	}
+	if _, ok := parseTimestamp("no time here"); ok {
+		t.Error("parsed a timestamp from plain text")
+	}
+}
@@ -0,0 +1,253 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program views log files of any size. Only the lines on screen are
+// read from disk, through a sparse index built in the background, and new
+// lines are picked up as the file grows, like tail -f. Lines are colored
+// by level, can be filtered by a regular expression, and the view can
+// jump to a line number or a timestamp.
+//
+// Set GIO_MEMSTATS=1 to see the memory use of the virtualized list.
+//
+// Usage:
+//
+//	go run ./logview /var/log/syslog
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/bytesize"
+	"gioui.org/example/internal/logview"
+	"gioui.org/example/internal/memstats"
+	"gioui.org/example/internal/style"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// pollInterval is how often the file is checked for new lines.
+const pollInterval = 500 * time.Millisecond
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: logview <file>")
+		os.Exit(2)
+	}
+	l, err := Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("Log Viewer"),
+			app.Size(unit.Dp(1000), unit.Dp(700)),
+		)
+		if err := loop(w, l); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+
+type App struct {
+	w    *app.Window
+	file *LogFile
+	// filter is the active filter, or nil.
+	filter       *Filter
+	cancelFilter context.CancelFunc
+	err          error
+	status       string
+
+	filterEd, jumpEd widget.Editor
+	view             *logview.View
+}
+
+func loop(w *app.Window, l *LogFile) error {
+	th := material.NewTheme(gofont.Collection())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- l.Follow(ctx, pollInterval, w.Invalidate)
+	}()
+	a := &App{
+		w:        w,
+		file:     l,
+		filterEd: widget.Editor{SingleLine: true, Submit: true},
+		jumpEd:   widget.Editor{SingleLine: true, Submit: true},
+		view:     logview.NewView(),
+	}
+	var mem memstats.Overlay
+	var ops op.Ops
+	for {
+		select {
+		case err := <-errs:
+			if !errors.Is(err, context.Canceled) {
+				a.err = err
+			}
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				if a.cancelFilter != nil {
+					a.cancelFilter()
+				}
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update()
+				a.Layout(gtx, th)
+				mem.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+func (a *App) update() {
+	for _, e := range a.filterEd.Events() {
+		if _, ok := e.(widget.SubmitEvent); ok {
+			a.setFilter(a.filterEd.Text())
+		}
+	}
+	for _, e := range a.jumpEd.Events() {
+		if _, ok := e.(widget.SubmitEvent); ok {
+			a.jump(strings.TrimSpace(a.jumpEd.Text()))
+		}
+	}
+}
+
+// setFilter replaces the filter by one for the regular expression expr,
+// or removes it if expr is empty.
+func (a *App) setFilter(expr string) {
+	if a.cancelFilter != nil {
+		a.cancelFilter()
+		a.cancelFilter = nil
+	}
+	a.filter = nil
+	a.status = ""
+	a.view.List.Position = layout.Position{}
+	if expr == "" {
+		return
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		a.status = err.Error()
+		return
+	}
+	f := NewFilter(re)
+	ctx, cancel := context.WithCancel(context.Background())
+	a.filter, a.cancelFilter = f, cancel
+	go f.Run(ctx, a.file, pollInterval, a.w.Invalidate)
+}
+
+// jump scrolls to a line number or the first line at or after a
+// timestamp.
+func (a *App) jump(target string) {
+	a.status = ""
+	var line int
+	if n, err := strconv.Atoi(target); err == nil {
+		line = n - 1
+	} else if t, ok := parseTimestamp(target); ok {
+		line = SearchTime(a.file, t)
+	} else {
+		a.status = fmt.Sprintf("%q is neither a line number nor a timestamp", target)
+		return
+	}
+	row := line
+	if f := a.filter; f != nil {
+		// Jump to the first match from the line.
+		row = sort.Search(f.Len(), func(i int) bool {
+			m, ok := f.Match(i)
+			return !ok || m >= line
+		})
+	}
+	if row < 0 {
+		row = 0
+	}
+	a.view.Stop()
+	a.view.List.Position = layout.Position{First: row, BeforeEnd: true}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	lines := a.file.Lines()
+	rows := lines
+	if a.filter != nil {
+		rows = a.filter.Len()
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(2, func(gtx C) D {
+						return style.Field(th, &a.filterEd, "Filter (regular expression, Enter to apply)").Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Flexed(1, func(gtx C) D {
+						return style.Field(th, &a.jumpEd, "Go to line or time").Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(material.CheckBox(th, &a.view.Follow, "Follow").Layout),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return a.view.Layout(gtx, th, rows, lines-1, func(i int) (int, string, bool) {
+				n := i
+				if a.filter != nil {
+					m, ok := a.filter.Match(i)
+					if !ok {
+						return 0, "", false
+					}
+					n = m
+				}
+				return n, a.file.Line(n), true
+			})
+		}),
+		layout.Rigid(func(gtx C) D {
+			status := fmt.Sprintf("%d lines, %s", lines, bytesize.Format(a.file.Size()))
+			if f := a.filter; f != nil {
+				status += fmt.Sprintf(" · %d matches", f.Len())
+				if s := f.Scanned(); s < lines {
+					status += fmt.Sprintf(" (searched %d%%)", s*100/lines)
+				}
+			}
+			l := material.Caption(th, status)
+			switch {
+			case a.err != nil:
+				l.Text = a.err.Error()
+				l.Color = errorColor
+			case a.status != "":
+				l.Text = a.status
+				l.Color = errorColor
+			}
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, l.Layout)
+		}),
+	)
+}
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Filter finds the lines of a LogFile matching a regular expression, in
+// the background and as the file grows.
+type Filter struct {
+	re *regexp.Regexp
+
+	mu      sync.Mutex
+	matches []int
+	scanned int
+}
+
+func NewFilter(re *regexp.Regexp) *Filter {
+	return &Filter{re: re}
+}
+
+// Len returns the number of matches found.
+func (f *Filter) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.matches)
+}
+
+// Match returns the line number of match i. It returns false if there
+// is no such match, as when the file was truncated since Len.
+func (f *Filter) Match(i int) (int, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if i >= len(f.matches) {
+		return 0, false
+	}
+	return f.matches[i], true
+}
+
+// Scanned returns the number of lines searched.
+func (f *Filter) Scanned() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.scanned
+}
+
+// Run searches the lines of l until ctx is done, polling for new lines
+// every interval once all are searched. It calls changed at most every
+// interval while searching.
+func (f *Filter) Run(ctx context.Context, l *LogFile, interval time.Duration, changed func()) {
+	last := time.Now()
+	for ctx.Err() == nil {
+		n := l.Lines()
+		f.mu.Lock()
+		scanned := f.scanned
+		f.mu.Unlock()
+		if n < scanned {
+			// The file was truncated.
+			f.mu.Lock()
+			f.matches, f.scanned = nil, 0
+			f.mu.Unlock()
+			changed()
+			continue
+		}
+		if scanned == n {
+			select {
+			case <-ctx.Done():
+			case <-time.After(interval):
+			}
+			continue
+		}
+		k := scanned / pageLines
+		page := l.page(k)
+		if page == nil {
+			// Truncated meanwhile.
+			continue
+		}
+		end := k*pageLines + len(page)
+		if end > n {
+			end = n
+		}
+		var found []int
+		for i := scanned; i < end; i++ {
+			if f.re.MatchString(page[i-k*pageLines]) {
+				found = append(found, i)
+			}
+		}
+		f.mu.Lock()
+		f.matches = append(f.matches, found...)
+		f.scanned = end
+		f.mu.Unlock()
+		if now := time.Now(); len(found) > 0 && now.Sub(last) >= interval || end == n {
+			last = now
+			changed()
+		}
+	}
+}
+
+// SearchTime returns the first line with a timestamp at or after t,
+// assuming the lines are in chronological order. Lines without a
+// timestamp are skipped.
+func SearchTime(l *LogFile, t time.Time) int {
+	n := l.Lines()
+	lo, hi := 0, n
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		// Find the closest line with a timestamp.
+		ts, ok := time.Time{}, false
+		i := mid
+		for ; i < hi && i < mid+100; i++ {
+			if ts, ok = parseTimestamp(l.Line(i)); ok {
+				break
+			}
+		}
+		switch {
+		case !ok:
+			hi = mid
+		case ts.Before(t):
+			lo = i + 1
+		default:
+			hi = mid
+		}
+	}
+	return lo
+}
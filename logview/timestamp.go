@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// timeLayouts are the timestamp formats recognized at the start of lines.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006/01/02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseTimestamp parses the timestamp at the start of s, which ends at a
+// space or a closing bracket.
+func parseTimestamp(s string) (time.Time, bool) {
+	s = strings.TrimLeft(s, "[ ")
+	// The timestamp may contain a space, so try the longer prefixes first.
+	var ends []int
+	for i := 0; i < len(s) && i < 40 && len(ends) < 2; i++ {
+		if s[i] == ' ' || s[i] == ']' {
+			ends = append(ends, i)
+		}
+	}
+	if len(s) < 40 && len(ends) < 2 {
+		ends = append(ends, len(s))
+	}
+	for i := len(ends) - 1; i >= 0; i-- {
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, s[:ends[i]]); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"gioui.org/example/internal/shortcut"
+)
+
+// Action is a command shown in the menus. Its accelerator is the binding
+// of its ID in the shortcut map.
+type Action struct {
+	ID    string
+	Label string
+	// Checked is non-nil for checkable actions.
+	Checked *bool
+	Run     func()
+}
+
+// Separator is the menu item separating groups of actions.
+const Separator = "-"
+
+// Menu is a titled list of action IDs and separators.
+type Menu struct {
+	Title string
+	Items []string
+}
+
+// Actions is the action layer shared by the native and in-window menus
+// and the keyboard.
+type Actions struct {
+	list      []*Action
+	byID      map[string]*Action
+	Shortcuts *shortcut.Map
+}
+
+func NewActions(acts []*Action, keys []shortcut.Action) (*Actions, error) {
+	m, err := shortcut.NewMap(keys)
+	if err != nil {
+		return nil, err
+	}
+	a := &Actions{list: acts, byID: make(map[string]*Action), Shortcuts: m}
+	for _, act := range acts {
+		a.byID[act.ID] = act
+	}
+	return a, nil
+}
+
+// Lookup returns the action with the id.
+func (a *Actions) Lookup(id string) *Action {
+	return a.byID[id]
+}
+
+// Run runs an action, toggling it if it is checkable.
+func (a *Actions) Run(id string) {
+	act := a.byID[id]
+	if act == nil {
+		return
+	}
+	if act.Checked != nil {
+		*act.Checked = !*act.Checked
+	}
+	if act.Run != nil {
+		act.Run()
+	}
+}
+
+// Accelerator returns the key binding of an action, if any.
+func (a *Actions) Accelerator(id string) shortcut.Binding {
+	return a.Shortcuts.Binding(id)
+}
@@ -0,0 +1,251 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates application menus. On macOS the File, Edit
+// and View menus are added to the native menu bar; elsewhere they are
+// drawn in the window. Both run the same actions, which are also bound to
+// keyboard accelerators.
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/shortcut"
+	"gioui.org/font/gofont"
+	"gioui.org/io/key"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Menus"),
+			app.Size(unit.Dp(720), unit.Dp(520)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+const sample = `Gio is a library for writing cross-platform immediate mode GUI-s in Go.
+Gio supports all the major platforms: Linux, macOS, Windows, Android, iOS,
+FreeBSD, OpenBSD and experimental support for browsers with WebAssembly.
+
+Gio is designed to work with very few dependencies.`
+
+// document is the state changed by the actions.
+type document struct {
+	lines []string
+	// history holds the previous lines for undo, future the undone lines
+	// for redo.
+	history, future [][]string
+	lineNumbers     bool
+	statusBar       bool
+	zoom            float32
+	status          string
+	quit            bool
+}
+
+func (d *document) edit(name string, f func(s string) string) func() {
+	return func() {
+		d.history = append(d.history, d.lines)
+		d.future = nil
+		lines := make([]string, len(d.lines))
+		for i, l := range d.lines {
+			lines[i] = f(l)
+		}
+		d.lines = lines
+		d.status = name
+	}
+}
+
+func (d *document) undo() {
+	if n := len(d.history); n > 0 {
+		d.future = append(d.future, d.lines)
+		d.lines = d.history[n-1]
+		d.history = d.history[:n-1]
+		d.status = "Undo"
+	}
+}
+
+func (d *document) redo() {
+	if n := len(d.future); n > 0 {
+		d.history = append(d.history, d.lines)
+		d.lines = d.future[n-1]
+		d.future = d.future[:n-1]
+		d.status = "Redo"
+	}
+}
+
+func (d *document) actions() []*Action {
+	zoom := func(name string, f func(z float32) float32) func() {
+		return func() {
+			d.zoom = f(d.zoom)
+			d.status = fmt.Sprintf("%s: %.0f%%", name, d.zoom*100)
+		}
+	}
+	return []*Action{
+		{ID: "new", Label: "New", Run: func() {
+			d.history = append(d.history, d.lines)
+			d.lines = nil
+			d.status = "New document"
+		}},
+		{ID: "open", Label: "Open Sample", Run: func() {
+			d.history = append(d.history, d.lines)
+			d.lines = strings.Split(sample, "\n")
+			d.status = "Opened sample"
+		}},
+		{ID: "save", Label: "Save", Run: func() { d.status = fmt.Sprintf("Saved %d lines", len(d.lines)) }},
+		{ID: "quit", Label: "Quit", Run: func() { d.quit = true }},
+		{ID: "undo", Label: "Undo", Run: d.undo},
+		{ID: "redo", Label: "Redo", Run: d.redo},
+		{ID: "upper", Label: "Uppercase", Run: d.edit("Uppercase", strings.ToUpper)},
+		{ID: "lower", Label: "Lowercase", Run: d.edit("Lowercase", strings.ToLower)},
+		{ID: "linenumbers", Label: "Line Numbers", Checked: &d.lineNumbers},
+		{ID: "statusbar", Label: "Status Bar", Checked: &d.statusBar},
+		{ID: "zoomin", Label: "Zoom In", Run: zoom("Zoom In", func(z float32) float32 { return z * 1.25 })},
+		{ID: "zoomout", Label: "Zoom Out", Run: zoom("Zoom Out", func(z float32) float32 { return z / 1.25 })},
+		{ID: "zoomreset", Label: "Actual Size", Run: zoom("Actual Size", func(float32) float32 { return 1 })},
+	}
+}
+
+// accelerators are the key bindings of the actions.
+var accelerators = []shortcut.Action{
+	{ID: "new", Default: "Shortcut+N"},
+	{ID: "open", Default: "Shortcut+O"},
+	{ID: "save", Default: "Shortcut+S"},
+	{ID: "quit", Default: "Shortcut+Q"},
+	{ID: "undo", Default: "Shortcut+Z"},
+	{ID: "redo", Default: "Shortcut+Shift+Z"},
+	{ID: "upper", Default: "Shortcut+U"},
+	{ID: "lower", Default: "Shortcut+L"},
+	{ID: "linenumbers", Default: "Shortcut+Shift+L"},
+	{ID: "statusbar", Default: "Shortcut+Shift+S"},
+	{ID: "zoomin", Default: "Shortcut+Up"},
+	{ID: "zoomout", Default: "Shortcut+Down"},
+	{ID: "zoomreset", Default: "Shortcut+0"},
+}
+
+var menus = []Menu{
+	{Title: "File", Items: []string{"new", "open", Separator, "save", Separator, "quit"}},
+	{Title: "Edit", Items: []string{"undo", "redo", Separator, "upper", "lower"}},
+	{Title: "View", Items: []string{"linenumbers", "statusbar", Separator, "zoomin", "zoomout", "zoomreset"}},
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	doc := &document{lines: strings.Split(sample, "\n"), statusBar: true, zoom: 1}
+	acts, err := NewActions(doc.actions(), accelerators)
+	if err != nil {
+		return err
+	}
+	bar := NewMenuBar(menus)
+	var (
+		ops        op.Ops
+		installed  bool
+		native     bool
+		focusSet   bool
+		rootHandle = new(int)
+	)
+	for {
+		select {
+		case id := <-nativeItems:
+			acts.Run(id)
+			updateNativeMenus(acts)
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				if !installed {
+					native = installNativeMenus(menus, acts)
+					installed = true
+				}
+				gtx := layout.NewContext(&ops, e)
+				// Key presses not consumed by a native menu arrive at the
+				// root handler, which navigates the open in-window menu or
+				// runs the action bound to them.
+				for _, e := range gtx.Events(rootHandle) {
+					e, ok := e.(key.Event)
+					if !ok || bar.Key(e, acts) {
+						continue
+					}
+					if id, ok := acts.Shortcuts.Lookup(e); ok {
+						acts.Run(id)
+						updateNativeMenus(acts)
+					}
+				}
+				key.InputOp{Tag: rootHandle}.Add(gtx.Ops)
+				if !focusSet {
+					key.FocusOp{Tag: rootHandle}.Add(gtx.Ops)
+					focusSet = true
+				}
+				th.TextSize = unit.Sp(16 * doc.zoom)
+				layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						if native {
+							return D{}
+						}
+						return bar.Layout(gtx, th)
+					}),
+					layout.Flexed(1, func(gtx C) D {
+						return layoutDocument(gtx, th, doc)
+					}),
+					layout.Rigid(func(gtx C) D {
+						if !doc.statusBar {
+							return D{}
+						}
+						return layout.UniformInset(unit.Dp(8)).Layout(gtx,
+							material.Caption(th, fmt.Sprintf("%d lines. %s", len(doc.lines), doc.status)).Layout)
+					}),
+				)
+				bar.LayoutMenu(gtx, th, acts)
+				e.Frame(gtx.Ops)
+				if doc.quit {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+func layoutDocument(gtx C, th *material.Theme, doc *document) D {
+	return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+		children := make([]layout.FlexChild, len(doc.lines))
+		for i, l := range doc.lines {
+			i, l := i, l
+			children[i] = layout.Rigid(func(gtx C) D {
+				return layout.Flex{}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						if !doc.lineNumbers {
+							return D{}
+						}
+						gtx.Constraints.Min.X = gtx.Px(unit.Dp(40))
+						n := material.Body1(th, fmt.Sprint(i+1))
+						n.Color.A = 0x80
+						return n.Layout(gtx)
+					}),
+					layout.Rigid(material.Body1(th, l).Layout),
+				)
+			})
+		}
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	})
+}
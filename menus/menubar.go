@@ -0,0 +1,317 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/f32"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// MenuBar is a menu bar drawn inside the window, for platforms without a
+// native one. Clicking a title opens its menu; while a menu is open,
+// hovering another title switches to it and the arrow keys, enter and
+// escape navigate.
+type MenuBar struct {
+	Menus []Menu
+
+	// open is the index of the open menu, or -1.
+	open int
+	// hovered is the highlighted item of the open menu, or -1.
+	hovered int
+	titles  []widget.Clickable
+	// offsets are the horizontal offsets of the titles.
+	offsets []int
+	height  int
+	items   map[string]*widget.Clickable
+	// scrim and hover are pointer tags.
+	scrim int
+	hover []int
+}
+
+func NewMenuBar(menus []Menu) *MenuBar {
+	return &MenuBar{
+		Menus:   menus,
+		open:    -1,
+		hovered: -1,
+		titles:  make([]widget.Clickable, len(menus)),
+		offsets: make([]int, len(menus)),
+		items:   make(map[string]*widget.Clickable),
+	}
+}
+
+// Opened reports whether a menu is open.
+func (b *MenuBar) Opened() bool {
+	return b.open >= 0
+}
+
+func (b *MenuBar) openMenu(i int) {
+	b.open = (i + len(b.Menus)) % len(b.Menus)
+	b.hovered = -1
+}
+
+func (b *MenuBar) Close() {
+	b.open = -1
+}
+
+// Key handles a key press while a menu is open and reports whether it
+// was consumed.
+func (b *MenuBar) Key(e key.Event, acts *Actions) bool {
+	if !b.Opened() || e.State != key.Press {
+		return false
+	}
+	items := b.Menus[b.open].Items
+	step := func(dir int) {
+		// Skip separators.
+		for n := 0; n < len(items); n++ {
+			b.hovered = (b.hovered + dir + len(items)) % len(items)
+			if items[b.hovered] != Separator {
+				return
+			}
+		}
+	}
+	switch e.Name {
+	case key.NameEscape:
+		b.Close()
+	case key.NameLeftArrow:
+		b.openMenu(b.open - 1)
+	case key.NameRightArrow:
+		b.openMenu(b.open + 1)
+	case key.NameUpArrow:
+		step(-1)
+	case key.NameDownArrow:
+		step(1)
+	case key.NameReturn, key.NameEnter:
+		if b.hovered >= 0 {
+			id := items[b.hovered]
+			b.Close()
+			acts.Run(id)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// Layout lays out the bar. The open menu is laid out separately by
+// LayoutMenu, above the rest of the window.
+func (b *MenuBar) Layout(gtx C, th *material.Theme) D {
+	for i := range b.titles {
+		for b.titles[i].Clicked() {
+			if b.open == i {
+				b.Close()
+			} else {
+				b.openMenu(i)
+			}
+		}
+	}
+	children := make([]layout.FlexChild, len(b.Menus))
+	x := 0
+	for i, m := range b.Menus {
+		i, m := i, m
+		children[i] = layout.Rigid(func(gtx C) D {
+			b.offsets[i] = x
+			dims := material.Clickable(gtx, &b.titles[i], func(gtx C) D {
+				return layout.Stack{}.Layout(gtx,
+					layout.Expanded(func(gtx C) D {
+						if b.open == i {
+							paint.FillShape(gtx.Ops, highlight(th), clip.Rect(image.Rectangle{Max: gtx.Constraints.Min}).Op())
+						}
+						return D{Size: gtx.Constraints.Min}
+					}),
+					layout.Stacked(func(gtx C) D {
+						return layout.Inset{Left: unit.Dp(10), Right: unit.Dp(10), Top: unit.Dp(6), Bottom: unit.Dp(6)}.Layout(gtx, material.Body2(th, m.Title).Layout)
+					}),
+				)
+			})
+			x += dims.Size.X
+			return dims
+		})
+	}
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	macro := op.Record(gtx.Ops)
+	dims := layout.Flex{}.Layout(gtx, children...)
+	call := macro.Stop()
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 0x10}, clip.Rect(image.Rectangle{Max: dims.Size}).Op())
+	call.Add(gtx.Ops)
+	b.height = dims.Size.Y
+	// While a menu is open, hovering a title switches to its menu.
+	if b.Opened() {
+		if len(b.hover) < len(b.Menus) {
+			b.hover = make([]int, len(b.Menus))
+		}
+		for i := range b.Menus {
+			for _, e := range gtx.Events(&b.hover[i]) {
+				if e, ok := e.(pointer.Event); ok && e.Type == pointer.Enter && b.open != i {
+					b.openMenu(i)
+				}
+			}
+			end := dims.Size.X
+			if i+1 < len(b.offsets) {
+				end = b.offsets[i+1]
+			}
+			stack := op.Save(gtx.Ops)
+			pointer.Rect(image.Rect(b.offsets[i], 0, end, dims.Size.Y)).Add(gtx.Ops)
+			pointer.PassOp{Pass: true}.Add(gtx.Ops)
+			pointer.InputOp{Tag: &b.hover[i], Types: pointer.Enter}.Add(gtx.Ops)
+			stack.Load()
+		}
+	}
+	return dims
+}
+
+// LayoutMenu lays out the open menu below its title, over a transparent
+// scrim that closes the menu when clicked.
+func (b *MenuBar) LayoutMenu(gtx C, th *material.Theme, acts *Actions) D {
+	if !b.Opened() {
+		return D{}
+	}
+	for _, e := range gtx.Events(&b.scrim) {
+		if e, ok := e.(pointer.Event); ok && e.Type == pointer.Press {
+			b.Close()
+		}
+	}
+	items := b.Menus[b.open].Items
+	for _, id := range items {
+		if c := b.items[id]; c != nil {
+			for c.Clicked() {
+				b.Close()
+				acts.Run(id)
+			}
+		}
+	}
+	if !b.Opened() {
+		op.InvalidateOp{}.Add(gtx.Ops)
+		return D{}
+	}
+	size := gtx.Constraints.Max
+	stack := op.Save(gtx.Ops)
+	// The scrim leaves the bar uncovered, for switching menus.
+	pointer.Rect(image.Rect(0, b.height, size.X, size.Y)).Add(gtx.Ops)
+	pointer.InputOp{Tag: &b.scrim, Types: pointer.Press}.Add(gtx.Ops)
+	stack.Load()
+
+	defer op.Save(gtx.Ops).Load()
+	op.Offset(f32.Pt(float32(b.offsets[b.open]), float32(b.height))).Add(gtx.Ops)
+	gtx.Constraints.Min = image.Point{}
+	// Measure the columns so the labels and accelerators line up.
+	var cols columns
+	for _, id := range items {
+		if id == Separator {
+			continue
+		}
+		if w := measure(gtx, material.Body2(th, acts.Lookup(id).Label).Layout); w > cols.label {
+			cols.label = w
+		}
+		if w := measure(gtx, material.Body2(th, acts.Accelerator(id).String()).Layout); w > cols.accel {
+			cols.accel = w
+		}
+	}
+	macro := op.Record(gtx.Ops)
+	dims := layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx C) D {
+		children := make([]layout.FlexChild, len(items))
+		for i, id := range items {
+			i, id := i, id
+			children[i] = layout.Rigid(func(gtx C) D {
+				return b.layoutItem(gtx, th, acts, cols, i, id)
+			})
+		}
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	})
+	call := macro.Stop()
+	rr := float32(gtx.Px(unit.Dp(4)))
+	rect := f32.Rectangle{Max: layout.FPt(dims.Size)}
+	// A soft shadow, then the background.
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 0x30}, clip.UniformRRect(rect.Add(f32.Pt(0, 2)), rr).Op(gtx.Ops))
+	paint.FillShape(gtx.Ops, th.Palette.Bg, clip.UniformRRect(rect, rr).Op(gtx.Ops))
+	stack = op.Save(gtx.Ops)
+	pointer.Rect(image.Rectangle{Max: dims.Size}).Add(gtx.Ops)
+	pointer.InputOp{Tag: &b.open, Types: pointer.Press}.Add(gtx.Ops)
+	stack.Load()
+	call.Add(gtx.Ops)
+	return dims
+}
+
+// columns are the widths of the label and accelerator columns of a menu.
+type columns struct {
+	label, accel int
+}
+
+// measure returns the width of w.
+func measure(gtx C, w layout.Widget) int {
+	gtx.Constraints.Min = image.Point{}
+	macro := op.Record(gtx.Ops)
+	dims := w(gtx)
+	macro.Stop()
+	return dims.Size.X
+}
+
+func (b *MenuBar) layoutItem(gtx C, th *material.Theme, acts *Actions, cols columns, i int, id string) D {
+	check, gap := gtx.Px(unit.Dp(20)), gtx.Px(unit.Dp(32))
+	left, right := unit.Dp(8), unit.Dp(12)
+	if id == Separator {
+		h := gtx.Px(unit.Dp(9))
+		w := gtx.Px(left) + check + cols.label + gap + cols.accel + gtx.Px(right)
+		paint.FillShape(gtx.Ops, color.NRGBA{A: 0x20}, clip.Rect(image.Rect(0, h/2, w, h/2+gtx.Px(unit.Dp(1)))).Op())
+		return D{Size: image.Pt(w, h)}
+	}
+	act := acts.Lookup(id)
+	click := b.items[id]
+	if click == nil {
+		click = new(widget.Clickable)
+		b.items[id] = click
+	}
+	if click.Hovered() {
+		b.hovered = i
+	}
+	return material.Clickable(gtx, click, func(gtx C) D {
+		return layout.Stack{}.Layout(gtx,
+			layout.Expanded(func(gtx C) D {
+				if b.hovered == i {
+					paint.FillShape(gtx.Ops, highlight(th), clip.Rect(image.Rectangle{Max: gtx.Constraints.Min}).Op())
+				}
+				return D{Size: gtx.Constraints.Min}
+			}),
+			layout.Stacked(func(gtx C) D {
+				return layout.Inset{Left: left, Right: right, Top: unit.Dp(6), Bottom: unit.Dp(6)}.Layout(gtx, func(gtx C) D {
+					column := func(width int, w layout.Widget) layout.FlexChild {
+						return layout.Rigid(func(gtx C) D {
+							gtx.Constraints.Min.X = width
+							return w(gtx)
+						})
+					}
+					return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+						column(check, func(gtx C) D {
+							if act.Checked == nil || !*act.Checked {
+								return D{Size: gtx.Constraints.Min}
+							}
+							return material.Body2(th, "✓").Layout(gtx)
+						}),
+						column(cols.label+gap, material.Body2(th, act.Label).Layout),
+						column(cols.accel, func(gtx C) D {
+							l := material.Body2(th, acts.Accelerator(id).String())
+							l.Color = color.NRGBA{A: 0x90}
+							return l.Layout(gtx)
+						}),
+					)
+				})
+			}),
+		)
+	})
+}
+
+func highlight(th *material.Theme) color.NRGBA {
+	c := th.Palette.ContrastBg
+	c.A = 0x30
+	return c
+}
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build darwin && !ios
+// +build darwin,!ios
+
+package main
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AppKit
+
+#include <stdint.h>
+#include <stdlib.h>
+
+void gio_addMenu(const char *title);
+void gio_addMenuItem(const char *title, int tag, const char *key, uint64_t mods);
+void gio_addMenuSeparator(void);
+void gio_setMenuItemChecked(int tag, int checked);
+*/
+import "C"
+
+import (
+	"strings"
+	"unsafe"
+
+	"gioui.org/example/internal/shortcut"
+	"gioui.org/io/key"
+)
+
+// nativeItems receives the actions of the native menu items clicked.
+var nativeItems = make(chan string, 16)
+
+// nativeIDs maps native menu item tags to action IDs.
+var nativeIDs []string
+
+// Modifier flags of NSEvent.
+const (
+	nsShift   = 1 << 17
+	nsControl = 1 << 18
+	nsOption  = 1 << 19
+	nsCommand = 1 << 20
+)
+
+// installNativeMenus adds the menus to the macOS menu bar and reports
+// whether it succeeded. It must be called after the application finished
+// launching, or the menu bar set up by Gio replaces the menus.
+func installNativeMenus(menus []Menu, acts *Actions) bool {
+	for _, m := range menus {
+		title := C.CString(m.Title)
+		C.gio_addMenu(title)
+		C.free(unsafe.Pointer(title))
+		for _, id := range m.Items {
+			if id == Separator {
+				C.gio_addMenuSeparator()
+				continue
+			}
+			tag := len(nativeIDs)
+			nativeIDs = append(nativeIDs, id)
+			k, mods := keyEquivalent(acts.Accelerator(id))
+			label, ckey := C.CString(acts.Lookup(id).Label), C.CString(k)
+			C.gio_addMenuItem(label, C.int(tag), ckey, C.uint64_t(mods))
+			C.free(unsafe.Pointer(label))
+			C.free(unsafe.Pointer(ckey))
+		}
+	}
+	updateNativeMenus(acts)
+	return true
+}
+
+// updateNativeMenus updates the check marks of the native menu items.
+func updateNativeMenus(acts *Actions) {
+	for tag, id := range nativeIDs {
+		if c := acts.Lookup(id).Checked; c != nil {
+			checked := 0
+			if *c {
+				checked = 1
+			}
+			C.gio_setMenuItemChecked(C.int(tag), C.int(checked))
+		}
+	}
+}
+
+// keyEquivalent converts a binding to the key equivalent and modifier mask
+// of a native menu item.
+func keyEquivalent(b shortcut.Binding) (string, uint64) {
+	var mods uint64
+	for _, m := range []struct {
+		mod key.Modifiers
+		ns  uint64
+	}{
+		{key.ModShift, nsShift},
+		{key.ModCtrl, nsControl},
+		{key.ModAlt, nsOption},
+		{key.ModCommand, nsCommand},
+	} {
+		if b.Modifiers.Contain(m.mod) {
+			mods |= m.ns
+		}
+	}
+	// The function key characters of NSEvent.
+	special := map[string]rune{
+		key.NameUpArrow:        0xf700,
+		key.NameDownArrow:      0xf701,
+		key.NameLeftArrow:      0xf702,
+		key.NameRightArrow:     0xf703,
+		key.NameHome:           0xf729,
+		key.NameEnd:            0xf72b,
+		key.NameDeleteForward:  0xf728,
+		key.NameDeleteBackward: 0x08,
+		key.NameReturn:         '\r',
+		key.NameEscape:         0x1b,
+	}
+	if r, ok := special[b.Name]; ok {
+		return string(r), mods
+	}
+	if len([]rune(b.Name)) != 1 {
+		// No equivalent; the key press reaches the window instead.
+		return "", 0
+	}
+	return strings.ToLower(b.Name), mods
+}
+
+//export gio_onMenuItem
+func gio_onMenuItem(tag C.int) {
+	select {
+	case nativeItems <- nativeIDs[tag]:
+	default:
+	}
+}
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build darwin && !ios
+// +build darwin,!ios
+
+#import <AppKit/AppKit.h>
+
+#include "_cgo_export.h"
+
+@interface GioMenuTarget : NSObject
+- (void)itemClicked:(NSMenuItem *)sender;
+@end
+
+@implementation GioMenuTarget
+- (void)itemClicked:(NSMenuItem *)sender {
+	gio_onMenuItem((int)sender.tag);
+}
+@end
+
+// The menu state is only accessed from the main thread.
+static GioMenuTarget *target;
+static NSMenu *current;
+static NSMutableDictionary<NSNumber *, NSMenuItem *> *items;
+
+void gio_addMenu(const char *title) {
+	NSString *t = [NSString stringWithUTF8String:title];
+	dispatch_async(dispatch_get_main_queue(), ^{
+		if (target == nil) {
+			target = [GioMenuTarget new];
+			items = [NSMutableDictionary new];
+		}
+		NSMenu *bar = [NSApp mainMenu];
+		if (bar == nil) {
+			bar = [NSMenu new];
+			[NSApp setMainMenu:bar];
+		}
+		current = [[NSMenu alloc] initWithTitle:t];
+		NSMenuItem *item = [NSMenuItem new];
+		item.submenu = current;
+		[bar addItem:item];
+	});
+}
+
+void gio_addMenuItem(const char *title, int tag, const char *key, uint64_t mods) {
+	NSString *t = [NSString stringWithUTF8String:title];
+	NSString *k = [NSString stringWithUTF8String:key];
+	dispatch_async(dispatch_get_main_queue(), ^{
+		NSMenuItem *item = [[NSMenuItem alloc] initWithTitle:t action:@selector(itemClicked:) keyEquivalent:k];
+		item.target = target;
+		item.tag = tag;
+		item.keyEquivalentModifierMask = mods;
+		[current addItem:item];
+		items[@(tag)] = item;
+	});
+}
+
+void gio_addMenuSeparator(void) {
+	dispatch_async(dispatch_get_main_queue(), ^{
+		[current addItem:[NSMenuItem separatorItem]];
+	});
+}
+
+void gio_setMenuItemChecked(int tag, int checked) {
+	dispatch_async(dispatch_get_main_queue(), ^{
+		items[@(tag)].state = checked ? NSControlStateValueOn : NSControlStateValueOff;
+	});
+}
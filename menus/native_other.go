@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build !darwin || ios
+// +build !darwin ios
+
+package main
+
+// nativeItems receives the actions of the native menu items clicked.
+var nativeItems chan string
+
+// installNativeMenus reports false; only macOS has a native menu bar.
+func installNativeMenus(menus []Menu, acts *Actions) bool {
+	return false
+}
+
+func updateNativeMenus(acts *Actions) {}
@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"strings"
+	"time"
+
+	"gioui.org/example/internal/fakedata"
+)
+
+// Track is a track of the library.
+type Track struct {
+	// ID is the index of the track in the library.
+	ID                   int
+	Title, Artist, Album string
+	Length               time.Duration
+}
+
+// GenerateLibrary returns the tracks of a made up library of albums.
+func GenerateLibrary(seed int64, albums int) []Track {
+	f := fakedata.New(seed)
+	var tracks []Track
+	for i := 0; i < albums; i++ {
+		artist := f.Name()
+		album := title(f, 1+f.Intn(3))
+		n := 6 + f.Intn(8)
+		for j := 0; j < n; j++ {
+			tracks = append(tracks, Track{
+				ID:     len(tracks),
+				Title:  title(f, 1+f.Intn(4)),
+				Artist: artist,
+				Album:  album,
+				Length: time.Duration(90+f.Intn(300)) * time.Second,
+			})
+		}
+	}
+	return tracks
+}
+
+// title returns a title of n words.
+func title(f *fakedata.Faker, n int) string {
+	ws := make([]string, n)
+	for i := range ws {
+		w := f.Word()
+		ws[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(ws, " ")
+}
@@ -0,0 +1,445 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program is the user interface of a music player: a library, a
+// play queue, a seek bar and album art. It publishes what is playing to
+// the system and takes commands from the media keys and the media
+// controls of the desktop: MPRIS over D-Bus on Linux and BSD,
+// MPNowPlayingInfoCenter and MPRemoteCommandCenter on macOS, and media
+// key hotkeys on Windows. The library is made up and playback is
+// simulated by the clock; the example is about the integration, not
+// audio decoding.
+//
+// In the window, space plays and pauses, and the left and right arrows
+// seek.
+//
+// Usage:
+//
+//	go run ./music [-albums 40]
+//
+// On Linux, try playerctl metadata or playerctl next while it runs.
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/fakedata"
+	"gioui.org/example/internal/style"
+	"gioui.org/font/gofont"
+	"gioui.org/io/key"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var albumsFlag = flag.Int("albums", 40, "number of albums in the library")
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Music"),
+			app.Size(unit.Dp(960), unit.Dp(640)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+const (
+	artSize   = 256
+	thumbSize = 40
+	// seekStep is the step of the arrow keys.
+	seekStep = 10 * time.Second
+)
+
+var (
+	selectedBg = color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0x30}
+	errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+)
+
+// row is a clickable row of the library or the queue.
+type row struct {
+	click, add widget.Clickable
+}
+
+// art is the album art of an album.
+type art struct {
+	img          image.Image
+	large, thumb paint.ImageOp
+}
+
+type App struct {
+	tracks []Track
+	player *Player
+	// session is the media integration of the system, or sessionErr the
+	// reason there is none.
+	session    Session
+	sessionErr error
+	// seeks is the seek count last published.
+	seeks int
+
+	library, queue         layout.List
+	libraryRows, queueRows []row
+	prev, play, next       widget.Clickable
+	seek                   widget.Float
+	// scrubbing is set while the seek bar is dragged.
+	scrubbing bool
+	arts      map[string]*art
+	keyTag    struct{}
+	focused   bool
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	tracks := GenerateLibrary(1, *albumsFlag)
+	a := &App{
+		tracks:      tracks,
+		player:      NewPlayer(tracks),
+		library:     layout.List{Axis: layout.Vertical},
+		queue:       layout.List{Axis: layout.Vertical},
+		libraryRows: make([]row, len(tracks)),
+		arts:        make(map[string]*art),
+	}
+	a.session, a.sessionErr = newSession()
+	var cmds <-chan Command
+	if a.session != nil {
+		defer a.session.Close()
+		cmds = a.session.Commands()
+	}
+	var ops op.Ops
+	for {
+		select {
+		case c := <-cmds:
+			a.command(c, time.Now())
+			a.publish(time.Now())
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.Layout(gtx, th)
+				a.publish(gtx.Now)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+// command runs a command of the media keys or controls.
+func (a *App) command(c Command, now time.Time) {
+	p := a.player
+	switch c.Op {
+	case OpPlay:
+		p.Play(now)
+	case OpPause:
+		p.Pause(now)
+	case OpToggle:
+		p.Toggle(now)
+	case OpStop:
+		p.Stop(now)
+	case OpNext:
+		p.Next(now)
+	case OpPrevious:
+		p.Previous(now)
+	case OpSeekTo:
+		p.Seek(c.Position, now)
+	case OpSeekBy:
+		p.Seek(p.Position(now)+c.Position, now)
+	}
+}
+
+// publish sends the state of the player to the system.
+func (a *App) publish(now time.Time) {
+	if a.session == nil {
+		return
+	}
+	t, ok := a.player.Current()
+	np := NowPlaying{
+		Active:   ok,
+		Track:    t,
+		Playing:  a.player.Playing(),
+		Position: a.player.Position(now),
+		Seeked:   a.player.Seeks() != a.seeks,
+		HasNext:  a.player.HasNext(),
+	}
+	if ok {
+		np.Art = a.art(t.Album).img
+	}
+	a.seeks = a.player.Seeks()
+	a.session.Update(np)
+}
+
+// art returns the art of an album, creating it the first time.
+func (a *App) art(album string) *art {
+	if ar, ok := a.arts[album]; ok {
+		return ar
+	}
+	img := fakedata.Avatar(album, artSize)
+	ar := &art{
+		img:   img,
+		large: paint.NewImageOp(img),
+		thumb: paint.NewImageOp(fakedata.Avatar(album, thumbSize)),
+	}
+	a.arts[album] = ar
+	return ar
+}
+
+func (a *App) update(gtx C) {
+	now := gtx.Now
+	p := a.player
+	for _, e := range gtx.Events(&a.keyTag) {
+		switch e := e.(type) {
+		case key.FocusEvent:
+			a.focused = e.Focus
+		case key.Event:
+			if e.State != key.Press {
+				continue
+			}
+			switch e.Name {
+			case key.NameSpace:
+				p.Toggle(now)
+			case key.NameLeftArrow:
+				p.Seek(p.Position(now)-seekStep, now)
+			case key.NameRightArrow:
+				p.Seek(p.Position(now)+seekStep, now)
+			}
+		}
+	}
+	for i := range a.libraryRows {
+		r := &a.libraryRows[i]
+		for r.click.Clicked() {
+			p.PlayNow(i, now)
+		}
+		for r.add.Clicked() {
+			p.Enqueue(i)
+		}
+	}
+	for i := range a.queueRows {
+		for a.queueRows[i].click.Clicked() {
+			p.Select(i, now)
+		}
+	}
+	for a.prev.Clicked() {
+		p.Previous(now)
+	}
+	for a.play.Clicked() {
+		p.Toggle(now)
+	}
+	for a.next.Clicked() {
+		p.Next(now)
+	}
+	if a.seek.Changed() {
+		a.scrubbing = true
+	}
+	if a.scrubbing && !a.seek.Dragging() {
+		a.scrubbing = false
+		p.Seek(time.Duration(a.seek.Value*float32(time.Second)), now)
+	}
+	p.Update(now)
+	if q, _ := p.Queue(); len(a.queueRows) < len(q) {
+		a.queueRows = append(a.queueRows, make([]row, len(q)-len(a.queueRows))...)
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	a.update(gtx)
+	// Take the keyboard focus for the player keys.
+	key.InputOp{Tag: &a.keyTag}.Add(gtx.Ops)
+	if !a.focused {
+		key.FocusOp{Tag: &a.keyTag}.Add(gtx.Ops)
+	}
+	if a.player.Playing() {
+		// Update the position a few times a second.
+		op.InvalidateOp{At: gtx.Now.Add(250 * time.Millisecond)}.Add(gtx.Ops)
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Flexed(1, func(gtx C) D {
+					return a.layoutLibrary(gtx, th)
+				}),
+				layout.Rigid(func(gtx C) D {
+					gtx.Constraints.Min.X = gtx.Px(unit.Dp(320))
+					gtx.Constraints.Max.X = gtx.Constraints.Min.X
+					return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+						return a.layoutNowPlaying(gtx, th)
+					})
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, a.layoutStatus(th))
+		}),
+	)
+}
+
+func (a *App) layoutLibrary(gtx C, th *material.Theme) D {
+	cur, playing := a.player.Current()
+	return a.library.Layout(gtx, len(a.tracks), func(gtx C, i int) D {
+		t := a.tracks[i]
+		r := &a.libraryRows[i]
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx C) D {
+				return material.Clickable(gtx, &r.click, func(gtx C) D {
+					return layout.Stack{}.Layout(gtx,
+						layout.Expanded(func(gtx C) D {
+							if playing && cur.ID == t.ID {
+								paint.FillShape(gtx.Ops, selectedBg, clip.Rect{Max: gtx.Constraints.Min}.Op())
+							}
+							return D{Size: gtx.Constraints.Min}
+						}),
+						layout.Stacked(func(gtx C) D {
+							return layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx C) D {
+								gtx.Constraints.Min.X = gtx.Constraints.Max.X
+								return a.layoutTrack(gtx, th, t)
+							})
+						}),
+					)
+				})
+			}),
+			layout.Rigid(func(gtx C) D {
+				return layout.Inset{Left: unit.Dp(8), Right: unit.Dp(8)}.Layout(gtx,
+					material.Button(th, &r.add, "Queue").Layout)
+			}),
+		)
+	})
+}
+
+// layoutTrack lays out a thumbnail, the title, the artist and album and
+// the length of a track.
+func (a *App) layoutTrack(gtx C, th *material.Theme, t Track) D {
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			sz := gtx.Px(unit.Dp(thumbSize))
+			gtx.Constraints = layout.Exact(image.Pt(sz, sz))
+			return widget.Image{Src: a.art(t.Album).thumb, Fit: widget.Fill}.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(material.Body1(th, t.Title).Layout),
+				layout.Rigid(material.Caption(th, t.Artist+" — "+t.Album).Layout),
+			)
+		}),
+		layout.Rigid(material.Body2(th, formatTime(t.Length)).Layout),
+	)
+}
+
+func (a *App) layoutNowPlaying(gtx C, th *material.Theme) D {
+	t, ok := a.player.Current()
+	pos := a.player.Position(gtx.Now)
+	if !a.scrubbing {
+		a.seek.Value = float32(pos.Seconds())
+	}
+	playLabel := "Play"
+	if a.player.Playing() {
+		playLabel = "Pause"
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			sz := gtx.Constraints.Max.X
+			gtx.Constraints = layout.Exact(image.Pt(sz, sz))
+			if !ok {
+				paint.FillShape(gtx.Ops, color.NRGBA{A: 0x20}, clip.Rect{Max: gtx.Constraints.Max}.Op())
+				return D{Size: gtx.Constraints.Max}
+			}
+			return widget.Image{Src: a.art(t.Album).large, Fit: widget.Fill}.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+		layout.Rigid(func(gtx C) D {
+			title, sub := "Nothing playing", "Click a track to play it."
+			if ok {
+				title, sub = t.Title, t.Artist+" — "+t.Album
+			}
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(material.H6(th, title).Layout),
+				layout.Rigid(material.Body2(th, sub).Layout),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			if !ok {
+				return D{}
+			}
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(material.Caption(th, formatTime(pos)).Layout),
+				layout.Flexed(1, material.Slider(th, &a.seek, 0, float32(t.Length.Seconds())).Layout),
+				layout.Rigid(material.Caption(th, formatTime(t.Length)).Layout),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{Spacing: layout.SpaceSides}.Layout(gtx,
+				layout.Rigid(material.Button(th, &a.prev, "Prev").Layout),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+				layout.Rigid(material.Button(th, &a.play, playLabel).Layout),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+				layout.Rigid(material.Button(th, &a.next, "Next").Layout),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Top: unit.Dp(16), Bottom: unit.Dp(4)}.Layout(gtx, material.Body1(th, "Queue").Layout)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return a.layoutQueue(gtx, th)
+		}),
+	)
+}
+
+func (a *App) layoutQueue(gtx C, th *material.Theme) D {
+	q, cur := a.player.Queue()
+	return a.queue.Layout(gtx, len(q), func(gtx C, i int) D {
+		t := a.tracks[q[i]]
+		return material.Clickable(gtx, &a.queueRows[i].click, func(gtx C) D {
+			return layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx C) D {
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+				l := material.Body2(th, fmt.Sprintf("%d. %s", i+1, t.Title))
+				if i == cur {
+					l.Font.Weight = text.Bold
+				} else if i < cur {
+					l.Color = style.MulAlpha(l.Color, 0x80)
+				}
+				return l.Layout(gtx)
+			})
+		})
+	})
+}
+
+func (a *App) layoutStatus(th *material.Theme) layout.Widget {
+	return func(gtx C) D {
+		if a.sessionErr != nil {
+			l := material.Caption(th, "Media keys: "+a.sessionErr.Error())
+			l.Color = errorColor
+			return l.Layout(gtx)
+		}
+		return material.Caption(th, fmt.Sprintf("%d tracks. Media keys: %s.", len(a.tracks), a.session.Name())).Layout(gtx)
+	}
+}
+
+// formatTime formats a duration as minutes and seconds.
+func formatTime(d time.Duration) string {
+	s := int(d / time.Second)
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
@@ -0,0 +1,205 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import "time"
+
+// Player is the playback state: a queue of tracks and the position in
+// the current one. Playback is simulated by the clock, and the methods
+// take the current time to keep them simple to test.
+type Player struct {
+	tracks []Track
+	// queue are the queued tracks by ID, and current the index of the
+	// current one, or -1.
+	queue   []int
+	current int
+	playing bool
+	// start is the time playback last started, and offset the position
+	// then.
+	start  time.Time
+	offset time.Duration
+	// seeks counts the jumps of the position.
+	seeks int
+}
+
+// restartLimit is the position after which Previous restarts the
+// current track instead of going back.
+const restartLimit = 3 * time.Second
+
+func NewPlayer(tracks []Track) *Player {
+	return &Player{tracks: tracks, current: -1}
+}
+
+// Current returns the current track.
+func (p *Player) Current() (Track, bool) {
+	if p.current == -1 {
+		return Track{}, false
+	}
+	return p.tracks[p.queue[p.current]], true
+}
+
+// Queue returns the queued track IDs and the index of the current one.
+func (p *Player) Queue() ([]int, int) {
+	return p.queue, p.current
+}
+
+func (p *Player) Playing() bool {
+	return p.playing
+}
+
+// Seeks returns the number of jumps of the position so far, for
+// detecting them.
+func (p *Player) Seeks() int {
+	return p.seeks
+}
+
+// HasNext reports whether a track follows the current one.
+func (p *Player) HasNext() bool {
+	return p.current+1 < len(p.queue)
+}
+
+// Enqueue adds a track to the end of the queue.
+func (p *Player) Enqueue(id int) {
+	p.queue = append(p.queue, id)
+}
+
+// PlayNow queues a track after the current one and plays it.
+func (p *Player) PlayNow(id int, now time.Time) {
+	i := p.current + 1
+	p.queue = append(p.queue, 0)
+	copy(p.queue[i+1:], p.queue[i:])
+	p.queue[i] = id
+	p.jump(i, now)
+	p.Play(now)
+}
+
+// Select plays the queued track at index i.
+func (p *Player) Select(i int, now time.Time) {
+	p.jump(i, now)
+	p.Play(now)
+}
+
+// jump makes the queued track at i current, from its start.
+func (p *Player) jump(i int, now time.Time) {
+	p.current = i
+	p.offset = 0
+	p.start = now
+}
+
+// Play starts playback, from the start of the queue if no track is
+// current.
+func (p *Player) Play(now time.Time) {
+	if p.playing {
+		return
+	}
+	if p.current == -1 {
+		if len(p.queue) == 0 {
+			return
+		}
+		p.jump(0, now)
+	}
+	if t, _ := p.Current(); p.offset >= t.Length {
+		// Replay a finished track.
+		p.offset = 0
+	}
+	p.playing = true
+	p.start = now
+}
+
+func (p *Player) Pause(now time.Time) {
+	if !p.playing {
+		return
+	}
+	p.offset = p.Position(now)
+	p.playing = false
+}
+
+// Toggle plays or pauses.
+func (p *Player) Toggle(now time.Time) {
+	if p.playing {
+		p.Pause(now)
+	} else {
+		p.Play(now)
+	}
+}
+
+// Stop pauses and rewinds the current track.
+func (p *Player) Stop(now time.Time) {
+	p.Pause(now)
+	p.Seek(0, now)
+}
+
+// Position returns the position in the current track.
+func (p *Player) Position(now time.Time) time.Duration {
+	t, ok := p.Current()
+	if !ok {
+		return 0
+	}
+	pos := p.offset
+	if p.playing {
+		pos += now.Sub(p.start)
+	}
+	if pos > t.Length {
+		pos = t.Length
+	}
+	return pos
+}
+
+// Seek moves to a position in the current track.
+func (p *Player) Seek(pos time.Duration, now time.Time) {
+	t, ok := p.Current()
+	if !ok {
+		return
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > t.Length {
+		pos = t.Length
+	}
+	p.offset = pos
+	p.start = now
+	p.seeks++
+}
+
+// Next skips to the next track, or stops at the end of the queue.
+func (p *Player) Next(now time.Time) {
+	if !p.HasNext() {
+		p.Stop(now)
+		return
+	}
+	p.jump(p.current+1, now)
+}
+
+// Previous restarts the current track, or goes back to the one before
+// near its start.
+func (p *Player) Previous(now time.Time) {
+	if p.current > 0 && p.Position(now) < restartLimit {
+		p.jump(p.current-1, now)
+		return
+	}
+	p.Seek(0, now)
+}
+
+// Update moves on to the next tracks when the current one has ended,
+// and reports whether the current track changed.
+func (p *Player) Update(now time.Time) bool {
+	changed := false
+	for p.playing {
+		t, _ := p.Current()
+		end := p.start.Add(t.Length - p.offset)
+		if now.Before(end) {
+			break
+		}
+		if !p.HasNext() {
+			p.playing = false
+			p.offset = t.Length
+			break
+		}
+		// Continue where the track ended, for time skipped while the
+		// window wasn't drawn.
+		p.jump(p.current+1, end)
+		changed = true
+	}
+	return changed
+}
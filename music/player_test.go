@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func testTracks() []Track {
+	return []Track{
+		{ID: 0, Title: "One", Length: 100 * time.Second},
+		{ID: 1, Title: "Two", Length: 50 * time.Second},
+		{ID: 2, Title: "Three", Length: 60 * time.Second},
+	}
+}
+
+func TestPlayerQueue(t *testing.T) {
+	t0 := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return t0.Add(time.Duration(s) * time.Second) }
+	p := NewPlayer(testTracks())
+	p.Enqueue(0)
+	p.Enqueue(1)
+	p.Play(at(0))
+	if tr, ok := p.Current(); !ok || tr.ID != 0 || !p.Playing() {
+		t.Fatalf("playing %v, %v", tr, ok)
+	}
+	// Playing a track now inserts it after the current one.
+	p.PlayNow(2, at(10))
+	if q, cur := p.Queue(); len(q) != 3 || q[1] != 2 || cur != 1 {
+		t.Fatalf("queue %v at %d, want [0 2 1] at 1", q, cur)
+	}
+	// The end of a track moves on to the next, continuing from its end
+	// even for skipped frames.
+	if !p.Update(at(75)) {
+		t.Error("track didn't change at its end")
+	}
+	if tr, _ := p.Current(); tr.ID != 1 {
+		t.Errorf("playing %d after the end, want 1", tr.ID)
+	}
+	if got := p.Position(at(75)); got != 5*time.Second {
+		t.Errorf("position %v, want 5s", got)
+	}
+	// The queue stops at its end.
+	p.Update(at(200))
+	if p.Playing() {
+		t.Error("playing past the end of the queue")
+	}
+	if got := p.Position(at(300)); got != 50*time.Second {
+		t.Errorf("stopped at %v, want the end", got)
+	}
+	// Playing again replays the last track.
+	p.Play(at(300))
+	if got := p.Position(at(301)); got != time.Second {
+		t.Errorf("replaying at %v, want 1s", got)
+	}
+}
+
+func TestPlayerSeek(t *testing.T) {
+	t0 := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return t0.Add(time.Duration(s) * time.Second) }
+	p := NewPlayer(testTracks())
+	p.Enqueue(0)
+	p.Enqueue(1)
+	p.Play(at(0))
+	p.Pause(at(20))
+	if got := p.Position(at(100)); got != 20*time.Second {
+		t.Errorf("paused at %v, want 20s", got)
+	}
+	seeks := p.Seeks()
+	p.Seek(150*time.Second, at(100))
+	if got := p.Position(at(100)); got != 100*time.Second {
+		t.Errorf("seeked past the end to %v, want the end", got)
+	}
+	if p.Seeks() != seeks+1 {
+		t.Error("seek not counted")
+	}
+	p.Next(at(100))
+	p.Play(at(100))
+	// Previous restarts a track after its first seconds, and goes back
+	// before.
+	p.Previous(at(110))
+	if tr, _ := p.Current(); tr.ID != 1 || p.Position(at(110)) != 0 {
+		t.Errorf("previous after 10s: track %d at %v, want track 1 restarted", tr.ID, p.Position(at(110)))
+	}
+	p.Previous(at(111))
+	if tr, _ := p.Current(); tr.ID != 0 {
+		t.Errorf("previous near the start: track %d, want 0", tr.ID)
+	}
+	p.Stop(at(120))
+	if p.Playing() || p.Position(at(130)) != 0 {
+		t.Error("stop didn't pause and rewind")
+	}
+}
+
+func TestGenerateLibrary(t *testing.T) {
+	tracks := GenerateLibrary(1, 5)
+	albums := make(map[string]bool)
+	for i, tr := range tracks {
+		if tr.ID != i {
+			t.Errorf("track %d has ID %d", i, tr.ID)
+		}
+		if tr.Title == "" || tr.Artist == "" || tr.Length <= 0 {
+			t.Errorf("incomplete track %+v", tr)
+		}
+		albums[tr.Album] = true
+	}
+	if len(albums) < 4 {
+		t.Errorf("%d albums, want 5", len(albums))
+	}
+}
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"time"
+)
+
+// errUnsupported is returned by newSession on platforms without media
+// controls support.
+var errUnsupported = errors.New("media controls are not supported on this platform")
+
+// Op is a command of the system media controls.
+type Op int
+
+const (
+	OpPlay Op = iota
+	OpPause
+	OpToggle
+	OpStop
+	OpNext
+	OpPrevious
+	// OpSeekTo moves to the Position of the command, and OpSeekBy by it.
+	OpSeekTo
+	OpSeekBy
+)
+
+// Command is a command from media keys or the media controls of the
+// system, such as those of a lock screen or a desktop panel.
+type Command struct {
+	Op       Op
+	Position time.Duration
+}
+
+// NowPlaying is the state published to the system.
+type NowPlaying struct {
+	// Track is valid if Active is set.
+	Active  bool
+	Track   Track
+	Art     image.Image
+	Playing bool
+	// Position is the position at the time of the update, and Seeked
+	// is set when it jumped since the update before.
+	Position time.Duration
+	Seeked   bool
+	HasNext  bool
+}
+
+// Session publishes the state of the player to the system and receives
+// its commands. newSession returns the session of the platform:
+// MPRIS on Linux and BSD, MPNowPlayingInfoCenter on macOS, and media key
+// hotkeys on Windows.
+type Session interface {
+	// Update publishes the state. Sessions skip unchanged state, so it
+	// is cheap to call every frame.
+	Update(np NowPlaying)
+	// Commands returns the channel of commands.
+	Commands() <-chan Command
+	// Name describes the integration.
+	Name() string
+	Close() error
+}
+
+// encodeArt encodes album art for the system.
+func encodeArt(img image.Image) ([]byte, error) {
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// sendCommand sends a command without blocking the system, dropping it
+// if the UI is behind.
+func sendCommand(c chan<- Command, cmd Command) {
+	select {
+	case c <- cmd:
+	default:
+	}
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build darwin && !ios
+// +build darwin,!ios
+
+package main
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AppKit -framework MediaPlayer
+
+#include <stdint.h>
+#include <stdlib.h>
+
+int gio_mediaInit(void);
+void gio_mediaUpdate(const char *title, const char *artist, const char *album, double length, double position, int playing, const void *art, int artLen);
+void gio_mediaClear(void);
+*/
+import "C"
+
+import (
+	"errors"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// nowPlaying publishes to MPNowPlayingInfoCenter, shown by the Now
+// Playing menu of Control Center and the Touch Bar, and receives the
+// commands of MPRemoteCommandCenter, which include the media keys.
+type nowPlaying struct {
+	mu   sync.Mutex
+	last NowPlaying
+	// arts caches the encoded album art by album.
+	arts map[string][]byte
+}
+
+// mediaCommands receives the commands of the remote command center.
+var mediaCommands = make(chan Command, 16)
+
+//export gio_onMediaCommand
+func gio_onMediaCommand(op C.int, pos C.double) {
+	sendCommand(mediaCommands, Command{Op: Op(op), Position: time.Duration(float64(pos) * float64(time.Second))})
+}
+
+func newSession() (Session, error) {
+	if C.gio_mediaInit() == 0 {
+		return nil, errors.New("MPNowPlayingInfoCenter needs macOS 10.12.2")
+	}
+	return &nowPlaying{arts: make(map[string][]byte)}, nil
+}
+
+func (s *nowPlaying) Name() string {
+	return "Now Playing"
+}
+
+func (s *nowPlaying) Commands() <-chan Command {
+	return mediaCommands
+}
+
+func (s *nowPlaying) Update(np NowPlaying) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	last := s.last
+	s.last = np
+	if !np.Active {
+		if last.Active {
+			C.gio_mediaClear()
+		}
+		return
+	}
+	// The info center extrapolates the position from the playback
+	// rate, so only changes need publishing.
+	if np.Track == last.Track && np.Playing == last.Playing && !np.Seeked {
+		return
+	}
+	art, ok := s.arts[np.Track.Album]
+	if !ok && np.Art != nil {
+		art, _ = encodeArt(np.Art)
+		s.arts[np.Track.Album] = art
+	}
+	title, artist, album := C.CString(np.Track.Title), C.CString(np.Track.Artist), C.CString(np.Track.Album)
+	defer C.free(unsafe.Pointer(title))
+	defer C.free(unsafe.Pointer(artist))
+	defer C.free(unsafe.Pointer(album))
+	var artPtr unsafe.Pointer
+	if len(art) > 0 {
+		artPtr = C.CBytes(art)
+		defer C.free(artPtr)
+	}
+	playing := C.int(0)
+	if np.Playing {
+		playing = 1
+	}
+	C.gio_mediaUpdate(title, artist, album, C.double(np.Track.Length.Seconds()), C.double(np.Position.Seconds()), playing, artPtr, C.int(len(art)))
+}
+
+func (s *nowPlaying) Close() error {
+	C.gio_mediaClear()
+	return nil
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build darwin && !ios
+// +build darwin,!ios
+
+#import <AppKit/AppKit.h>
+#import <MediaPlayer/MediaPlayer.h>
+
+#include "_cgo_export.h"
+
+// The values of Op in session.go.
+enum {
+	opPlay,
+	opPause,
+	opToggle,
+	opStop,
+	opNext,
+	opPrevious,
+	opSeekTo,
+	opSeekBy,
+};
+
+static void addCommand(MPRemoteCommand *cmd, int op) API_AVAILABLE(macos(10.12.2)) {
+	cmd.enabled = YES;
+	[cmd addTargetWithHandler:^MPRemoteCommandHandlerStatus(MPRemoteCommandEvent *e) {
+		gio_onMediaCommand(op, 0);
+		return MPRemoteCommandHandlerStatusSuccess;
+	}];
+}
+
+int gio_mediaInit(void) {
+	if (@available(macOS 10.12.2, *)) {
+		dispatch_async(dispatch_get_main_queue(), ^{
+			MPRemoteCommandCenter *c = [MPRemoteCommandCenter sharedCommandCenter];
+			addCommand(c.playCommand, opPlay);
+			addCommand(c.pauseCommand, opPause);
+			addCommand(c.togglePlayPauseCommand, opToggle);
+			addCommand(c.stopCommand, opStop);
+			addCommand(c.nextTrackCommand, opNext);
+			addCommand(c.previousTrackCommand, opPrevious);
+			c.changePlaybackPositionCommand.enabled = YES;
+			[c.changePlaybackPositionCommand addTargetWithHandler:^MPRemoteCommandHandlerStatus(MPRemoteCommandEvent *e) {
+				MPChangePlaybackPositionCommandEvent *pe = (MPChangePlaybackPositionCommandEvent *)e;
+				gio_onMediaCommand(opSeekTo, pe.positionTime);
+				return MPRemoteCommandHandlerStatusSuccess;
+			}];
+		});
+		return 1;
+	}
+	return 0;
+}
+
+void gio_mediaUpdate(const char *title, const char *artist, const char *album, double length, double position, int playing, const void *art, int artLen) {
+	NSMutableDictionary *info = [NSMutableDictionary new];
+	info[MPMediaItemPropertyTitle] = [NSString stringWithUTF8String:title];
+	info[MPMediaItemPropertyArtist] = [NSString stringWithUTF8String:artist];
+	info[MPMediaItemPropertyAlbumTitle] = [NSString stringWithUTF8String:album];
+	info[MPMediaItemPropertyPlaybackDuration] = @(length);
+	info[MPNowPlayingInfoPropertyElapsedPlaybackTime] = @(position);
+	info[MPNowPlayingInfoPropertyPlaybackRate] = @(playing ? 1.0 : 0.0);
+	if (@available(macOS 10.13.2, *)) {
+		if (art != NULL) {
+			NSImage *img = [[NSImage alloc] initWithData:[NSData dataWithBytes:art length:artLen]];
+			if (img != nil) {
+				info[MPMediaItemPropertyArtwork] = [[MPMediaItemArtwork alloc] initWithBoundsSize:img.size requestHandler:^NSImage *(CGSize size) {
+					return img;
+				}];
+			}
+		}
+	}
+	dispatch_async(dispatch_get_main_queue(), ^{
+		if (@available(macOS 10.12.2, *)) {
+			MPNowPlayingInfoCenter *c = [MPNowPlayingInfoCenter defaultCenter];
+			c.nowPlayingInfo = info;
+			c.playbackState = playing ? MPNowPlayingPlaybackStatePlaying : MPNowPlayingPlaybackStatePaused;
+		}
+	});
+}
+
+void gio_mediaClear(void) {
+	dispatch_async(dispatch_get_main_queue(), ^{
+		if (@available(macOS 10.12.2, *)) {
+			MPNowPlayingInfoCenter *c = [MPNowPlayingInfoCenter defaultCenter];
+			c.nowPlayingInfo = nil;
+			c.playbackState = MPNowPlayingPlaybackStateStopped;
+		}
+	});
+}
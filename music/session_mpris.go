@@ -0,0 +1,318 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build (linux && !android) || freebsd || openbsd
+// +build linux,!android freebsd openbsd
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+// mpris implements the MPRIS D-Bus interface of media players, which
+// desktop panels, lock screens, media key daemons and playerctl use to
+// show and control what is playing.
+type mpris struct {
+	conn  *dbus.Conn
+	name  string
+	props *prop.Properties
+	cmds  chan Command
+	// artDir holds the album art files referenced by the metadata.
+	artDir string
+
+	mu   sync.Mutex
+	last NowPlaying
+	// arts maps albums to the URLs of their art files.
+	arts map[string]string
+}
+
+const (
+	mprisPath   = "/org/mpris/MediaPlayer2"
+	mprisRoot   = "org.mpris.MediaPlayer2"
+	mprisPlayer = "org.mpris.MediaPlayer2.Player"
+)
+
+func newSession() (Session, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := ioutil.TempDir("", "gio-music")
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	m := &mpris{
+		conn: conn,
+		// Instance names let several players run at once.
+		name:   fmt.Sprintf("%s.giomusic.instance%d", mprisRoot, os.Getpid()),
+		cmds:   make(chan Command, 16),
+		artDir: dir,
+		arts:   make(map[string]string),
+	}
+	if err := m.export(); err != nil {
+		m.Close()
+		return nil, err
+	}
+	reply, err := conn.RequestName(m.name, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		m.Close()
+		return nil, err
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		m.Close()
+		return nil, fmt.Errorf("mpris: name %s taken", m.name)
+	}
+	return m, nil
+}
+
+func (m *mpris) export() error {
+	root, player := m.rootMethods(), m.playerMethods()
+	if err := m.conn.ExportMethodTable(methodTable(root), mprisPath, mprisRoot); err != nil {
+		return err
+	}
+	if err := m.conn.ExportMethodTable(methodTable(player), mprisPath, mprisPlayer); err != nil {
+		return err
+	}
+	props, err := prop.Export(m.conn, mprisPath, prop.Map{
+		mprisRoot: {
+			"CanQuit":             {Value: false, Emit: prop.EmitConst},
+			"CanRaise":            {Value: false, Emit: prop.EmitConst},
+			"HasTrackList":        {Value: false, Emit: prop.EmitConst},
+			"Identity":            {Value: "Gio Music", Emit: prop.EmitConst},
+			"SupportedUriSchemes": {Value: []string{}, Emit: prop.EmitConst},
+			"SupportedMimeTypes":  {Value: []string{}, Emit: prop.EmitConst},
+		},
+		mprisPlayer: {
+			"PlaybackStatus": {Value: "Stopped", Emit: prop.EmitTrue},
+			"Metadata":       {Value: map[string]dbus.Variant{}, Emit: prop.EmitTrue},
+			// Clients compute the position from the last value and the
+			// rate, so it changes without signals.
+			"Position":      {Value: int64(0), Emit: prop.EmitFalse},
+			"Rate":          {Value: 1.0, Emit: prop.EmitConst},
+			"MinimumRate":   {Value: 1.0, Emit: prop.EmitConst},
+			"MaximumRate":   {Value: 1.0, Emit: prop.EmitConst},
+			"Volume":        {Value: 1.0, Emit: prop.EmitConst},
+			"CanGoNext":     {Value: false, Emit: prop.EmitTrue},
+			"CanGoPrevious": {Value: false, Emit: prop.EmitTrue},
+			"CanPlay":       {Value: false, Emit: prop.EmitTrue},
+			"CanPause":      {Value: false, Emit: prop.EmitTrue},
+			"CanSeek":       {Value: false, Emit: prop.EmitTrue},
+			"CanControl":    {Value: true, Emit: prop.EmitConst},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	m.props = props
+	node := &introspect.Node{
+		Name: mprisPath,
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			prop.IntrospectData,
+			{Name: mprisRoot, Methods: introspection(root), Properties: props.Introspection(mprisRoot)},
+			{
+				Name:       mprisPlayer,
+				Methods:    introspection(player),
+				Properties: props.Introspection(mprisPlayer),
+				Signals: []introspect.Signal{{
+					Name: "Seeked",
+					Args: []introspect.Arg{{Name: "Position", Type: "x"}},
+				}},
+			},
+		},
+	}
+	return m.conn.Export(introspect.NewIntrospectable(node), mprisPath, "org.freedesktop.DBus.Introspectable")
+}
+
+func (m *mpris) Name() string {
+	return "MPRIS as " + m.name
+}
+
+func (m *mpris) Commands() <-chan Command {
+	return m.cmds
+}
+
+func (m *mpris) Close() error {
+	os.RemoveAll(m.artDir)
+	return m.conn.Close()
+}
+
+func (m *mpris) Update(np NowPlaying) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	last := m.last
+	m.last = np
+	m.props.SetMust(mprisPlayer, "Position", micros(np.Position))
+	if np.Seeked {
+		m.conn.Emit(mprisPath, mprisPlayer+".Seeked", micros(np.Position))
+	}
+	if np.Active != last.Active || np.Track != last.Track {
+		m.props.SetMust(mprisPlayer, "Metadata", m.metadata(np))
+	}
+	set := func(name string, v, old interface{}) {
+		if v != old {
+			m.props.SetMust(mprisPlayer, name, v)
+		}
+	}
+	set("PlaybackStatus", status(np), status(last))
+	set("CanGoNext", np.HasNext, last.HasNext)
+	set("CanGoPrevious", np.Active, last.Active)
+	set("CanPlay", np.Active, last.Active)
+	set("CanPause", np.Active, last.Active)
+	set("CanSeek", np.Active, last.Active)
+}
+
+func status(np NowPlaying) string {
+	switch {
+	case !np.Active:
+		return "Stopped"
+	case np.Playing:
+		return "Playing"
+	default:
+		return "Paused"
+	}
+}
+
+func micros(d time.Duration) int64 {
+	return int64(d / time.Microsecond)
+}
+
+func trackPath(t Track) dbus.ObjectPath {
+	return dbus.ObjectPath(fmt.Sprintf("/org/gioui/music/track/%d", t.ID))
+}
+
+func (m *mpris) metadata(np NowPlaying) map[string]dbus.Variant {
+	if !np.Active {
+		return map[string]dbus.Variant{}
+	}
+	t := np.Track
+	md := map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(trackPath(t)),
+		"mpris:length":  dbus.MakeVariant(micros(t.Length)),
+		"xesam:title":   dbus.MakeVariant(t.Title),
+		"xesam:artist":  dbus.MakeVariant([]string{t.Artist}),
+		"xesam:album":   dbus.MakeVariant(t.Album),
+	}
+	if u := m.artURL(t.Album, np); u != "" {
+		md["mpris:artUrl"] = dbus.MakeVariant(u)
+	}
+	return md
+}
+
+// artURL returns the file URL of the art of an album, writing the file
+// the first time.
+func (m *mpris) artURL(album string, np NowPlaying) string {
+	if u, ok := m.arts[album]; ok || np.Art == nil {
+		return u
+	}
+	data, err := encodeArt(np.Art)
+	if err != nil {
+		return ""
+	}
+	file := filepath.Join(m.artDir, fmt.Sprintf("art%d.png", len(m.arts)))
+	if err := ioutil.WriteFile(file, data, 0600); err != nil {
+		return ""
+	}
+	u := (&url.URL{Scheme: "file", Path: file}).String()
+	m.arts[album] = u
+	return u
+}
+
+func (m *mpris) send(op Op, pos time.Duration) *dbus.Error {
+	sendCommand(m.cmds, Command{Op: op, Position: pos})
+	return nil
+}
+
+// method is a method of an interface, with its arguments for
+// introspection. The methods are exported as tables because the Seek
+// method of MPRIS doesn't match io.Seeker.
+type method struct {
+	name string
+	args []introspect.Arg
+	fn   interface{}
+}
+
+func methodTable(ms []method) map[string]interface{} {
+	t := make(map[string]interface{})
+	for _, m := range ms {
+		t[m.name] = m.fn
+	}
+	return t
+}
+
+func introspection(ms []method) []introspect.Method {
+	var im []introspect.Method
+	for _, m := range ms {
+		im = append(im, introspect.Method{Name: m.name, Args: m.args})
+	}
+	return im
+}
+
+// rootMethods returns the methods of org.mpris.MediaPlayer2. The
+// player can be neither raised nor quit, as its properties say.
+func (m *mpris) rootMethods() []method {
+	nop := func() *dbus.Error { return nil }
+	return []method{
+		{name: "Raise", fn: nop},
+		{name: "Quit", fn: nop},
+	}
+}
+
+// playerMethods returns the methods of org.mpris.MediaPlayer2.Player.
+func (m *mpris) playerMethods() []method {
+	cmd := func(op Op) func() *dbus.Error {
+		return func() *dbus.Error { return m.send(op, 0) }
+	}
+	return []method{
+		{name: "Next", fn: cmd(OpNext)},
+		{name: "Previous", fn: cmd(OpPrevious)},
+		{name: "Pause", fn: cmd(OpPause)},
+		{name: "PlayPause", fn: cmd(OpToggle)},
+		{name: "Stop", fn: cmd(OpStop)},
+		{name: "Play", fn: cmd(OpPlay)},
+		{
+			// Seek moves by an offset in microseconds.
+			name: "Seek",
+			args: []introspect.Arg{{Name: "Offset", Type: "x", Direction: "in"}},
+			fn: func(offset int64) *dbus.Error {
+				return m.send(OpSeekBy, time.Duration(offset)*time.Microsecond)
+			},
+		},
+		{
+			// SetPosition moves to a position in microseconds, if the
+			// track is still current.
+			name: "SetPosition",
+			args: []introspect.Arg{
+				{Name: "TrackId", Type: "o", Direction: "in"},
+				{Name: "Position", Type: "x", Direction: "in"},
+			},
+			fn: func(track dbus.ObjectPath, pos int64) *dbus.Error {
+				m.mu.Lock()
+				last := m.last
+				m.mu.Unlock()
+				if !last.Active || track != trackPath(last.Track) {
+					return nil
+				}
+				return m.send(OpSeekTo, time.Duration(pos)*time.Microsecond)
+			},
+		},
+		{
+			name: "OpenUri",
+			args: []introspect.Arg{{Name: "Uri", Type: "s", Direction: "in"}},
+			fn: func(uri string) *dbus.Error {
+				return dbus.MakeFailedError(fmt.Errorf("opening %s is not supported", uri))
+			},
+		},
+	}
+}
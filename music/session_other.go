@@ -0,0 +1,14 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build !(darwin && !ios) && !windows && !((linux && !android) || freebsd || openbsd)
+// +build !darwin ios
+// +build !windows
+// +build !linux android
+// +build !freebsd
+// +build !openbsd
+
+package main
+
+func newSession() (Session, error) {
+	return nil, errUnsupported
+}
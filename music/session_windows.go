@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// hotkeys receives the media keys of the keyboard as system wide hotkeys.
+// Publishing what is playing to the System Media Transport Controls
+// needs the Windows Runtime, which is out of reach of syscall alone, so
+// Update does nothing.
+type hotkeys struct {
+	cmds   chan Command
+	thread uint32
+	done   chan struct{}
+}
+
+var (
+	user32                 = syscall.NewLazyDLL("user32.dll")
+	kernel32               = syscall.NewLazyDLL("kernel32.dll")
+	procRegisterHotKey     = user32.NewProc("RegisterHotKey")
+	procUnregisterHotKey   = user32.NewProc("UnregisterHotKey")
+	procGetMessage         = user32.NewProc("GetMessageW")
+	procPostThreadMessage  = user32.NewProc("PostThreadMessageW")
+	procGetCurrentThreadId = kernel32.NewProc("GetCurrentThreadId")
+)
+
+const (
+	_MOD_NOREPEAT = 0x4000
+	_WM_QUIT      = 0x0012
+	_WM_HOTKEY    = 0x0312
+
+	_VK_MEDIA_NEXT_TRACK = 0xB0
+	_VK_MEDIA_PREV_TRACK = 0xB1
+	_VK_MEDIA_STOP       = 0xB2
+	_VK_MEDIA_PLAY_PAUSE = 0xB3
+)
+
+// mediaKeys maps the virtual keys to commands; the hotkey IDs are the
+// indices.
+var mediaKeys = []struct {
+	vk uintptr
+	op Op
+}{
+	{_VK_MEDIA_PLAY_PAUSE, OpToggle},
+	{_VK_MEDIA_NEXT_TRACK, OpNext},
+	{_VK_MEDIA_PREV_TRACK, OpPrevious},
+	{_VK_MEDIA_STOP, OpStop},
+}
+
+// msg mirrors MSG.
+type msg struct {
+	hwnd    syscall.Handle
+	message uint32
+	wParam  uintptr
+	lParam  uintptr
+	time    uint32
+	pt      [2]int32
+}
+
+func newSession() (Session, error) {
+	h := &hotkeys{
+		cmds: make(chan Command, 16),
+		done: make(chan struct{}),
+	}
+	errs := make(chan error, 1)
+	go h.run(errs)
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// run registers the hotkeys and receives them. Hotkeys are delivered to
+// the message queue of the thread that registered them.
+func (h *hotkeys) run(errs chan<- error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer close(h.done)
+	tid, _, _ := procGetCurrentThreadId.Call()
+	h.thread = uint32(tid)
+	for id, k := range mediaKeys {
+		r, _, err := procRegisterHotKey.Call(0, uintptr(id), _MOD_NOREPEAT, k.vk)
+		if r == 0 {
+			// Another application, such as a media player, has the keys.
+			for i := 0; i < id; i++ {
+				procUnregisterHotKey.Call(0, uintptr(i))
+			}
+			errs <- fmt.Errorf("registering media keys: %v", err)
+			return
+		}
+	}
+	errs <- nil
+	var m msg
+	for {
+		r, _, _ := procGetMessage.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0)
+		if int32(r) <= 0 {
+			break
+		}
+		if m.message == _WM_HOTKEY && int(m.wParam) < len(mediaKeys) {
+			sendCommand(h.cmds, Command{Op: mediaKeys[m.wParam].op})
+		}
+	}
+	for id := range mediaKeys {
+		procUnregisterHotKey.Call(0, uintptr(id))
+	}
+}
+
+func (h *hotkeys) Name() string {
+	return "media key hotkeys"
+}
+
+func (h *hotkeys) Update(np NowPlaying) {}
+
+func (h *hotkeys) Commands() <-chan Command {
+	return h.cmds
+}
+
+func (h *hotkeys) Close() error {
+	procPostThreadMessage.Call(uintptr(h.thread), _WM_QUIT, 0, 0)
+	<-h.done
+	return nil
+}
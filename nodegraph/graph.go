@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math/rand"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+// Node is a box of the graph, in canvas coordinates.
+type Node struct {
+	Title string
+	Kind  int
+	Pos   f32.Point
+}
+
+// Edge connects the output of a node to the input of a later node.
+type Edge struct {
+	From, To int
+}
+
+// Graph is a layered graph, every edge going from one layer to the next.
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+	// Bounds is the area covered by the nodes.
+	Bounds f32.Rectangle
+}
+
+// The size of nodes and the spacing of layers and rows, in canvas
+// coordinates.
+const (
+	nodeWidth  = 180
+	nodeHeight = 80
+	layerGap   = 320
+	rowGap     = 130
+)
+
+var kinds = []struct {
+	Name  string
+	Color color.NRGBA
+}{
+	{"Source", color.NRGBA{R: 0x43, G: 0xa0, B: 0x47, A: 0xff}},
+	{"Filter", color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0xff}},
+	{"Merge", color.NRGBA{R: 0xfb, G: 0x8c, B: 0x00, A: 0xff}},
+	{"Output", color.NRGBA{R: 0x8e, G: 0x24, B: 0xaa, A: 0xff}},
+}
+
+// newGraph generates a graph of layers of up to rows nodes, each
+// connected to one or two nodes of the next layer.
+func newGraph(layers, rows int, seed int64) *Graph {
+	rnd := rand.New(rand.NewSource(seed))
+	g := new(Graph)
+	var prev []int
+	for l := 0; l < layers; l++ {
+		var layer []int
+		for r := 0; r < rows; r++ {
+			// Leave gaps, as real graphs have.
+			if rnd.Intn(4) == 0 {
+				continue
+			}
+			kind := 1 + rnd.Intn(2)
+			switch l {
+			case 0:
+				kind = 0
+			case layers - 1:
+				kind = 3
+			}
+			jitter := f32.Pt(rnd.Float32()*60-30, rnd.Float32()*30-15)
+			layer = append(layer, len(g.Nodes))
+			g.Nodes = append(g.Nodes, Node{
+				Title: fmt.Sprintf("%s %d", kinds[kind].Name, len(g.Nodes)+1),
+				Kind:  kind,
+				Pos:   f32.Pt(float32(l*layerGap), float32(r*rowGap)).Add(jitter),
+			})
+		}
+		for _, n := range prev {
+			if len(layer) == 0 {
+				break
+			}
+			for i := rnd.Intn(2); i < 2; i++ {
+				g.Edges = append(g.Edges, Edge{From: n, To: layer[rnd.Intn(len(layer))]})
+			}
+		}
+		if len(layer) > 0 {
+			prev = layer
+		}
+	}
+	for i, n := range g.Nodes {
+		r := f32.Rectangle{Min: n.Pos, Max: n.Pos.Add(f32.Pt(nodeWidth, nodeHeight))}
+		if i == 0 {
+			g.Bounds = r
+		} else {
+			g.Bounds = g.Bounds.Union(r)
+		}
+	}
+	return g
+}
+
+// port returns the position of the output or input of a node.
+func (n Node) port(output bool) f32.Point {
+	if output {
+		return n.Pos.Add(f32.Pt(nodeWidth, nodeHeight/2))
+	}
+	return n.Pos.Add(f32.Pt(0, nodeHeight/2))
+}
+
+var (
+	edgeColor   = color.NRGBA{R: 0x90, G: 0x90, B: 0x90, A: 0xff}
+	nodeColor   = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	borderColor = color.NRGBA{R: 0x60, G: 0x60, B: 0x60, A: 0xff}
+)
+
+// recordShapes records the edges and boxes of the graph in canvas
+// coordinates.
+func (g *Graph) recordShapes(ops *op.Ops) op.CallOp {
+	m := op.Record(ops)
+	var p clip.Path
+	p.Begin(ops)
+	for _, e := range g.Edges {
+		from, to := g.Nodes[e.From].port(true), g.Nodes[e.To].port(false)
+		dx := (to.X - from.X) / 2
+		p.MoveTo(from)
+		p.CubeTo(from.Add(f32.Pt(dx, 0)), to.Sub(f32.Pt(dx, 0)), to)
+	}
+	paint.FillShape(ops, edgeColor, clip.Stroke{Path: p.End(), Style: clip.StrokeStyle{Width: 2}}.Op())
+	header := float32(24)
+	for _, n := range g.Nodes {
+		r := f32.Rectangle{Min: n.Pos, Max: n.Pos.Add(f32.Pt(nodeWidth, nodeHeight))}
+		paint.FillShape(ops, borderColor, clip.UniformRRect(r, 8).Op(ops))
+		inner := f32.Rectangle{Min: r.Min.Add(f32.Pt(1, 1)), Max: r.Max.Sub(f32.Pt(1, 1))}
+		paint.FillShape(ops, nodeColor, clip.UniformRRect(inner, 7).Op(ops))
+		head := inner
+		head.Max.Y = head.Min.Y + header
+		paint.FillShape(ops, kinds[n.Kind].Color, clip.RRect{Rect: head, NW: 7, NE: 7}.Op(ops))
+	}
+	return m.Stop()
+}
+
+// recordLabels records the titles of the nodes, laid out at one pixel
+// per canvas unit. Text is drawn from outlines, so it stays sharp when
+// the canvas is zoomed.
+func (g *Graph) recordLabels(ops *op.Ops, th *material.Theme) op.CallOp {
+	m := op.Record(ops)
+	gtx := layout.Context{
+		Ops:    ops,
+		Metric: unit.Metric{PxPerDp: 1, PxPerSp: 1},
+	}
+	inputs := make([]int, len(g.Nodes))
+	for _, e := range g.Edges {
+		inputs[e.To]++
+	}
+	for i, n := range g.Nodes {
+		st := op.Save(ops)
+		op.Offset(n.Pos).Add(ops)
+		gtx.Constraints = layout.Exact(image.Pt(nodeWidth, nodeHeight))
+		layout.Inset{Left: unit.Dp(10), Top: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			l := material.Body2(th, n.Title)
+			l.Color = nodeColor
+			return l.Layout(gtx)
+		})
+		layout.Inset{Left: unit.Dp(10), Top: unit.Dp(40)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			l := material.Caption(th, fmt.Sprintf("%d inputs", inputs[i]))
+			l.Color = borderColor
+			return l.Layout(gtx)
+		})
+		st.Load()
+	}
+	return m.Stop()
+}
@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"testing"
+
+	"gioui.org/f32"
+)
+
+func TestGraph(t *testing.T) {
+	g := newGraph(6, 10, 1)
+	if len(g.Nodes) == 0 || len(g.Edges) == 0 {
+		t.Fatalf("%d nodes and %d edges, want some", len(g.Nodes), len(g.Edges))
+	}
+	for _, e := range g.Edges {
+		from, to := g.Nodes[e.From], g.Nodes[e.To]
+		if to.Pos.X-from.Pos.X < layerGap/2 {
+			t.Errorf("edge from %s to %s does not go to a later layer", from.Title, to.Title)
+		}
+	}
+	for _, n := range g.Nodes {
+		r := f32.Rectangle{Min: n.Pos, Max: n.Pos.Add(f32.Pt(nodeWidth, nodeHeight))}
+		if r.Union(g.Bounds) != g.Bounds {
+			t.Errorf("%s at %v outside the bounds %v", n.Title, r, g.Bounds)
+		}
+	}
+	if g2 := newGraph(6, 10, 1); len(g2.Nodes) != len(g.Nodes) || len(g2.Edges) != len(g.Edges) {
+		t.Error("graphs of the same seed differ")
+	}
+}
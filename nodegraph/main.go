@@ -0,0 +1,234 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates navigating a canvas far larger than the
+// window with a minimap. The node graph is panned by dragging and zoomed
+// by scrolling; the minimap in the corner shows the whole graph and the
+// area in view, which can be dragged to jump around the graph.
+//
+// The graph is recorded into a macro once. The canvas and the minimap
+// both call the macro, transformed, so neither lays out the graph again
+// when the view moves.
+//
+// Usage:
+//
+//	go run ./nodegraph [-layers 24] [-rows 30]
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/pointer"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"gioui.org/example/internal/minimap"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	layersFlag = flag.Int("layers", 24, "number of layers of the graph")
+	rowsFlag   = flag.Int("rows", 30, "number of nodes per layer, at most")
+)
+
+// The zoom range, in dp per canvas unit. Labels are left out below labelZoom, where they are too small
+// to read.
+const (
+	minZoom   = 0.05
+	maxZoom   = 4
+	labelZoom = 0.3
+)
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Node graph"),
+			app.Size(unit.Dp(1000), unit.Dp(700)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// Canvas is a pannable and zoomable view of the graph.
+type Canvas struct {
+	graph *Graph
+	// shapes and labels are the cached macros of the graph.
+	shapesOps, labelsOps op.Ops
+	shapes, labels       op.CallOp
+
+	// zoom is the number of dp per canvas unit, and origin the canvas
+	// position at the top left corner of the view.
+	zoom   float32
+	origin f32.Point
+	// size is the size of the view, in pixels, in the last frame.
+	size image.Point
+
+	dragging bool
+	last     f32.Point
+
+	minimap minimap.Minimap
+}
+
+func newCanvas(g *Graph, th *material.Theme) *Canvas {
+	c := &Canvas{graph: g, zoom: 1}
+	c.shapes = g.recordShapes(&c.shapesOps)
+	c.labels = g.recordLabels(&c.labelsOps, th)
+	// Leave room around the graph on the minimap.
+	c.minimap.Bounds = f32.Rectangle{
+		Min: g.Bounds.Min.Sub(f32.Pt(layerGap/2, rowGap)),
+		Max: g.Bounds.Max.Add(f32.Pt(layerGap/2, rowGap)),
+	}
+	c.origin = c.minimap.Bounds.Min
+	return c
+}
+
+// scale returns the number of pixels per canvas unit.
+func (c *Canvas) scale(gtx C) float32 {
+	return c.zoom * gtx.Metric.PxPerDp
+}
+
+// view returns the area of the canvas in view.
+func (c *Canvas) view(gtx C) f32.Rectangle {
+	return f32.Rectangle{
+		Min: c.origin,
+		Max: c.origin.Add(layout.FPt(c.size).Mul(1 / c.scale(gtx))),
+	}
+}
+
+func (c *Canvas) events(gtx C) {
+	for _, e := range gtx.Events(c) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case pointer.Press:
+			c.dragging = true
+			c.last = e.Position
+		case pointer.Drag:
+			c.origin = c.origin.Sub(e.Position.Sub(c.last).Mul(1 / c.scale(gtx)))
+			c.last = e.Position
+		case pointer.Release, pointer.Cancel:
+			c.dragging = false
+		case pointer.Scroll:
+			// Zoom around the pointer, keeping the canvas position under
+			// it fixed.
+			at := c.origin.Add(e.Position.Mul(1 / c.scale(gtx)))
+			zoom := c.zoom * float32(math.Pow(2, -float64(e.Scroll.Y)/200))
+			if zoom < minZoom {
+				zoom = minZoom
+			}
+			if zoom > maxZoom {
+				zoom = maxZoom
+			}
+			c.zoom = zoom
+			c.origin = at.Sub(e.Position.Mul(1 / c.scale(gtx)))
+		}
+	}
+}
+
+// Layout draws the canvas, filling the constraints, with the minimap in
+// the bottom right corner.
+func (c *Canvas) Layout(gtx C) D {
+	c.events(gtx)
+	c.size = gtx.Constraints.Max
+	gtx.Constraints.Min = c.size
+
+	// Lay out the minimap first, so that a move of its view applies to
+	// this frame, but draw it over the canvas.
+	c.minimap.View = c.view(gtx)
+	m := op.Record(gtx.Ops)
+	layout.SE.Layout(gtx, func(gtx C) D {
+		return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+			gtx.Constraints.Max = image.Pt(gtx.Px(unit.Dp(240)), gtx.Px(unit.Dp(160)))
+			gtx.Constraints.Min = image.Point{}
+			border := widget.Border{Color: color.NRGBA{A: 0x60}, Width: unit.Dp(1)}
+			return border.Layout(gtx, func(gtx C) D {
+				return c.minimap.Layout(gtx, c.shapes)
+			})
+		})
+	})
+	minimapCall := m.Stop()
+	if c.minimap.Changed() {
+		c.origin = c.minimap.View.Min
+	}
+
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect(image.Rectangle{Max: c.size}).Add(gtx.Ops)
+	paint.Fill(gtx.Ops, color.NRGBA{R: 0xec, G: 0xef, B: 0xf1, A: 0xff})
+
+	pointer.Rect(image.Rectangle{Max: c.size}).Add(gtx.Ops)
+	pointer.InputOp{
+		Tag:   c,
+		Grab:  c.dragging,
+		Types: pointer.Press | pointer.Drag | pointer.Release | pointer.Scroll,
+		// Zooming has no bounds; the zoom is clamped instead.
+		ScrollBounds: image.Rect(0, -1e6, 0, 1e6),
+	}.Add(gtx.Ops)
+	if c.dragging {
+		pointer.CursorNameOp{Name: pointer.CursorGrab}.Add(gtx.Ops)
+	}
+
+	st := op.Save(gtx.Ops)
+	s := c.scale(gtx)
+	op.Affine(f32.Affine2D{}.
+		Offset(c.origin.Mul(-1)).
+		Scale(f32.Point{}, f32.Pt(s, s))).Add(gtx.Ops)
+	c.shapes.Add(gtx.Ops)
+	if c.zoom >= labelZoom {
+		c.labels.Add(gtx.Ops)
+	}
+	st.Load()
+
+	minimapCall.Add(gtx.Ops)
+	return D{Size: c.size}
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	g := newGraph(*layersFlag, *rowsFlag, 1)
+	canvas := newCanvas(g, th)
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Flexed(1, canvas.Layout),
+				layout.Rigid(func(gtx C) D {
+					txt := fmt.Sprintf("%d nodes, %d edges. Zoom %.0f%%. Drag to pan, scroll to zoom, or drag the minimap.",
+						len(g.Nodes), len(g.Edges), canvas.zoom*100)
+					return layout.UniformInset(unit.Dp(8)).Layout(gtx, material.Caption(th, txt).Layout)
+				}),
+			)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// deviceCode is the response to a device authorization request, as
+// specified by RFC 8628.
+type deviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// deviceEndpoint is the endpoint of GitHub for device authorization.
+const deviceEndpoint = "https://github.com/login/device/code"
+
+// deviceFlow runs the device authorization flow, for when the browser
+// can't be redirected to the application: the user enters the code passed
+// to show on a web page, possibly on another device, while the token
+// endpoint is polled.
+func deviceFlow(ctx context.Context, conf oauth2.Config, show func(deviceCode)) (*oauth2.Token, error) {
+	var code deviceCode
+	err := post(ctx, deviceEndpoint, url.Values{
+		"client_id": {conf.ClientID},
+		"scope":     {strings.Join(conf.Scopes, " ")},
+	}, &code)
+	if err != nil {
+		return nil, err
+	}
+	show(code)
+	interval := time.Duration(code.Interval) * time.Second
+	if interval == 0 {
+		interval = 5 * time.Second
+	}
+	expiry := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+		var resp struct {
+			AccessToken string `json:"access_token"`
+			TokenType   string `json:"token_type"`
+			Error       string `json:"error"`
+		}
+		err := post(ctx, conf.Endpoint.TokenURL, url.Values{
+			"client_id":   {conf.ClientID},
+			"device_code": {code.DeviceCode},
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		switch resp.Error {
+		case "":
+			return &oauth2.Token{AccessToken: resp.AccessToken, TokenType: resp.TokenType}, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		default:
+			return nil, fmt.Errorf("oauth: device authorization failed: %s", resp.Error)
+		}
+		if time.Now().After(expiry) {
+			return nil, fmt.Errorf("oauth: device code expired")
+		}
+	}
+}
+
+// post posts a form and decodes the JSON response into v.
+func post(ctx context.Context, endpoint string, form url.Values, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oauth: %s: %s", endpoint, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,333 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates signing in to GitHub with OAuth2. With a
+// client secret, the user signs in in the system browser, which redirects
+// back to a server on the loopback interface. Without one, or when the
+// browser can't reach the program, the device flow shows a code to enter
+// on the GitHub web site. The token is stored in the credential store of
+// the platform, so the program remains signed in between runs.
+//
+// Register an OAuth app at https://github.com/settings/developers, with
+// http://127.0.0.1 as its callback URL and the device flow enabled, and
+// run with
+//
+//	go run ./oauth -client-id <id> [-client-secret <secret>]
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"image/color"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/keyring"
+	"gioui.org/example/internal/style"
+	"gioui.org/font/gofont"
+	"gioui.org/io/clipboard"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+
+	gh "github.com/google/go-github/v24/github"
+)
+
+var (
+	clientID     = flag.String("client-id", "", "OAuth client ID")
+	clientSecret = flag.String("client-secret", "", "OAuth client secret, for the browser flow")
+)
+
+// The keyring entry of the token.
+const (
+	keyringService = "gioui.org/example/oauth"
+	keyringUser    = "github"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	flag.Parse()
+	if *clientID == "" {
+		fmt.Fprintln(os.Stderr, "specify an OAuth client ID with -client-id")
+		os.Exit(2)
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("OAuth"),
+			app.Size(unit.Dp(480), unit.Dp(400)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+type authState int
+
+const (
+	// checking is the state while a stored token is verified.
+	checking authState = iota
+	signedOut
+	waitingBrowser
+	waitingDevice
+	signedIn
+)
+
+// result is the outcome of a sign in.
+type result struct {
+	token *oauth2.Token
+	login string
+	err   error
+}
+
+type App struct {
+	w     *app.Window
+	conf  oauth2.Config
+	keys  keyring.Keyring
+	state authState
+	login string
+	err   error
+	// warning is shown when the token can't be stored.
+	warning string
+	device  deviceCode
+	cancel  context.CancelFunc
+
+	results chan result
+	codes   chan deviceCode
+
+	browser, deviceBtn, cancelBtn widget.Clickable
+	openPage, copyCode, signOut   widget.Clickable
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		w: w,
+		conf: oauth2.Config{
+			ClientID:     *clientID,
+			ClientSecret: *clientSecret,
+			Endpoint:     github.Endpoint,
+			Scopes:       []string{"read:user"},
+		},
+		keys:    keyring.Default(),
+		results: make(chan result, 1),
+		codes:   make(chan deviceCode, 1),
+	}
+	a.restore()
+	var ops op.Ops
+	for {
+		select {
+		case res := <-a.results:
+			a.finish(res)
+			w.Invalidate()
+		case code := <-a.codes:
+			a.device = code
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				if a.cancel != nil {
+					a.cancel()
+				}
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update(gtx)
+				layout.UniformInset(unit.Dp(24)).Layout(gtx, func(gtx C) D {
+					return a.Layout(gtx, th)
+				})
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+// restore verifies the token stored by a previous run, if any.
+func (a *App) restore() {
+	data, err := a.keys.Get(keyringService, keyringUser)
+	if err != nil {
+		if !errors.Is(err, keyring.ErrNotFound) {
+			a.warning = err.Error()
+		}
+		a.state = signedOut
+		return
+	}
+	tok := new(oauth2.Token)
+	if err := json.Unmarshal([]byte(data), tok); err != nil {
+		a.state = signedOut
+		return
+	}
+	a.run(checking, func(ctx context.Context) (*oauth2.Token, error) {
+		return tok, nil
+	})
+}
+
+// run starts a sign in in the background, in the given state. The token
+// returned by flow is verified by fetching the user it belongs to.
+func (a *App) run(state authState, flow func(ctx context.Context) (*oauth2.Token, error)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.state = state
+	a.cancel = cancel
+	a.err = nil
+	a.device = deviceCode{}
+	go func() {
+		tok, err := flow(ctx)
+		res := result{token: tok, err: err}
+		if err == nil {
+			client := gh.NewClient(a.conf.Client(ctx, tok))
+			var u *gh.User
+			u, _, err = client.Users.Get(ctx, "")
+			res.err = err
+			res.login = u.GetLogin()
+		}
+		a.results <- res
+	}()
+}
+
+// finish handles the result of a sign in.
+func (a *App) finish(res result) {
+	a.cancel()
+	a.cancel = nil
+	wasChecking := a.state == checking
+	if res.err != nil {
+		a.state = signedOut
+		var rerr *gh.ErrorResponse
+		if errors.As(res.err, &rerr) && rerr.Response.StatusCode == http.StatusUnauthorized {
+			// The stored token was revoked.
+			a.keys.Delete(keyringService, keyringUser)
+			return
+		}
+		if !errors.Is(res.err, context.Canceled) {
+			a.err = res.err
+		}
+		return
+	}
+	a.state = signedIn
+	a.login = res.login
+	if wasChecking {
+		return
+	}
+	data, err := json.Marshal(res.token)
+	if err == nil {
+		err = a.keys.Set(keyringService, keyringUser, string(data))
+	}
+	if err != nil {
+		a.warning = fmt.Sprintf("The token was not saved: %v", err)
+	}
+}
+
+func (a *App) update(gtx C) {
+	for a.browser.Clicked() {
+		conf := a.conf
+		a.run(waitingBrowser, func(ctx context.Context) (*oauth2.Token, error) {
+			return browserFlow(ctx, conf)
+		})
+	}
+	for a.deviceBtn.Clicked() {
+		conf, codes := a.conf, a.codes
+		a.run(waitingDevice, func(ctx context.Context) (*oauth2.Token, error) {
+			return deviceFlow(ctx, conf, func(code deviceCode) {
+				codes <- code
+			})
+		})
+	}
+	for a.cancelBtn.Clicked() {
+		if a.cancel != nil {
+			a.cancel()
+		}
+	}
+	for a.openPage.Clicked() {
+		if err := openBrowser(a.device.VerificationURI); err != nil {
+			a.err = err
+		}
+	}
+	for a.copyCode.Clicked() {
+		clipboard.WriteOp{Text: a.device.UserCode}.Add(gtx.Ops)
+	}
+	for a.signOut.Clicked() {
+		if err := a.keys.Delete(keyringService, keyringUser); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			a.warning = err.Error()
+		}
+		a.state = signedOut
+		a.login = ""
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	var children []layout.FlexChild
+	add := func(w layout.Widget) {
+		children = append(children, layout.Rigid(func(gtx C) D {
+			return layout.Inset{Bottom: unit.Dp(16)}.Layout(gtx, w)
+		}))
+	}
+	switch a.state {
+	case checking:
+		add(material.H5(th, "Signing in…").Layout)
+	case signedOut:
+		add(material.H5(th, "Sign in to GitHub").Layout)
+		add(func(gtx C) D {
+			if a.conf.ClientSecret == "" {
+				gtx = gtx.Disabled()
+			}
+			return material.Button(th, &a.browser, "Sign in with browser").Layout(gtx)
+		})
+		add(style.TextButton(th, &a.deviceBtn, "Sign in with a code"))
+	case waitingBrowser:
+		add(material.H5(th, "Continue in your browser").Layout)
+		add(material.Body1(th, "Sign in on the page opened in your browser. This window updates once you are done.").Layout)
+		add(style.TextButton(th, &a.cancelBtn, "Cancel"))
+	case waitingDevice:
+		add(material.H5(th, "Enter the code").Layout)
+		if a.device.UserCode == "" {
+			add(material.Body1(th, "Requesting a code…").Layout)
+		} else {
+			add(material.Body1(th, fmt.Sprintf("Visit %s and enter the code:", a.device.VerificationURI)).Layout)
+			add(material.H3(th, a.device.UserCode).Layout)
+			add(func(gtx C) D {
+				return layout.Flex{}.Layout(gtx,
+					layout.Rigid(material.Button(th, &a.openPage, "Open page").Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(style.TextButton(th, &a.copyCode, "Copy code")),
+				)
+			})
+		}
+		add(style.TextButton(th, &a.cancelBtn, "Cancel"))
+	case signedIn:
+		add(material.H5(th, fmt.Sprintf("Signed in as %s", a.login)).Layout)
+		add(material.Button(th, &a.signOut, "Sign out").Layout)
+	}
+	for _, msg := range []string{errString(a.err), a.warning} {
+		if msg == "" {
+			continue
+		}
+		l := material.Body2(th, strings.TrimSpace(msg))
+		l.Color = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+		add(l.Layout)
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
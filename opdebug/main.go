@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program shows the operations of its frames in a second window,
+// with the difference to the frame before. The widgets on the left each
+// cause redraws of their own: the blinking caret of a focused editor,
+// the hover shading of a button and a spinning loader. Watch which
+// operations each of them changes.
+//
+// Any program can open the same window with internal/opdump:
+//
+//	var rec opdump.Recorder
+//	go rec.Window()
+//	...
+//	rec.Record(gtx.Ops)
+//	e.Frame(gtx.Ops)
+//
+// Usage:
+//
+//	go run ./opdebug
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/opdump"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	rec := new(opdump.Recorder)
+	go func() {
+		if err := rec.Window(); err != nil {
+			log.Fatal(err)
+		}
+	}()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Op Debugging"),
+			app.Size(unit.Dp(420), unit.Dp(360)),
+		)
+		if err := loop(w, rec); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+type App struct {
+	button  widget.Clickable
+	clicks  int
+	spinner widget.Bool
+	editor  widget.Editor
+}
+
+func loop(w *app.Window, rec *opdump.Recorder) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{editor: widget.Editor{SingleLine: true}}
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			a.Layout(gtx, th)
+			rec.Record(gtx.Ops)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	for a.button.Clicked() {
+		a.clicks++
+	}
+	widgets := []layout.Widget{
+		material.Body1(th, "Focus the editor, hover the button or start the spinner, and see the operations of each frame change.").Layout,
+		func(gtx C) D {
+			return material.Editor(th, &a.editor, "Type here").Layout(gtx)
+		},
+		func(gtx C) D {
+			return material.Button(th, &a.button, fmt.Sprintf("Clicked %d times", a.clicks)).Layout(gtx)
+		},
+		material.CheckBox(th, &a.spinner, "Spinner").Layout,
+		func(gtx C) D {
+			if !a.spinner.Value {
+				return D{}
+			}
+			gtx.Constraints.Max.X = gtx.Px(unit.Dp(32))
+			return material.Loader(th).Layout(gtx)
+		},
+	}
+	return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+		var children []layout.FlexChild
+		for _, w := range widgets {
+			children = append(children,
+				layout.Rigid(w),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+			)
+		}
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	})
+}
@@ -0,0 +1,205 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates an on-screen keyboard for touch terminals: a
+// visitor sign-in form typed with the keyboard of package osk. The
+// keyboard types into the focused field; Enter moves to the next field,
+// and breaks lines in the note. Hold a key to repeat it, and tap Shift
+// twice to lock it.
+//
+// Usage:
+//
+//	go run ./osk [-fullscreen]
+//
+// The -fullscreen flag runs the form full screen, as on a kiosk.
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"gioui.org/example/internal/osk"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var fullscreenFlag = flag.Bool("fullscreen", false, "run full screen")
+
+func main() {
+	flag.Parse()
+	go func() {
+		opts := []app.Option{
+			app.Title("On-Screen Keyboard"),
+			app.Size(unit.Dp(720), unit.Dp(640)),
+		}
+		if *fullscreenFlag {
+			opts = append(opts, app.Fullscreen)
+		}
+		w := app.NewWindow(opts...)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+type UI struct {
+	name     widget.Editor
+	host     widget.Editor
+	note     widget.Editor
+	submit   widget.Clickable
+	clear    widget.Clickable
+	keyboard osk.Keyboard
+	list     layout.List
+	message  string
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	ui := &UI{
+		name: widget.Editor{SingleLine: true, Submit: true},
+		host: widget.Editor{SingleLine: true, Submit: true},
+		list: layout.List{Axis: layout.Vertical},
+	}
+	ui.name.Focus()
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			ui.update()
+			ui.layout(gtx, th)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+func (ui *UI) update() {
+	submitted := func(ed *widget.Editor) bool {
+		for _, e := range ed.Events() {
+			if _, ok := e.(widget.SubmitEvent); ok {
+				return true
+			}
+		}
+		return false
+	}
+	// Enter submits the single-line fields, from the keyboard or a
+	// physical keyboard.
+	next := map[*widget.Editor]*widget.Editor{&ui.name: &ui.host, &ui.host: &ui.note}
+	for ed, to := range next {
+		if submitted(ed) {
+			to.Focus()
+		}
+	}
+	for {
+		ed, ok := ui.keyboard.Submitted()
+		if !ok {
+			break
+		}
+		if to := next[ed]; to != nil {
+			to.Focus()
+		}
+	}
+	ui.note.Events()
+	// Type into the field focused in the last frame.
+	ui.keyboard.Track(&ui.name, &ui.host, &ui.note)
+	for ui.submit.Clicked() {
+		if ui.name.Text() == "" || ui.host.Text() == "" {
+			ui.message = "Enter your name and who you are visiting."
+			break
+		}
+		ui.message = fmt.Sprintf("Welcome, %s! %s has been told you are here.", ui.name.Text(), ui.host.Text())
+		ui.reset()
+	}
+	for ui.clear.Clicked() {
+		ui.message = ""
+		ui.reset()
+	}
+}
+
+func (ui *UI) reset() {
+	for _, ed := range []*widget.Editor{&ui.name, &ui.host, &ui.note} {
+		ed.SetText("")
+	}
+	ui.name.Focus()
+}
+
+func (ui *UI) layout(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return ui.layoutForm(gtx, th)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return ui.keyboard.Layout(gtx, th)
+		}),
+	)
+}
+
+func (ui *UI) layoutForm(gtx C, th *material.Theme) D {
+	widgets := []layout.Widget{
+		material.H5(th, "Visitor sign-in").Layout,
+		func(gtx C) D {
+			return field(gtx, th, &ui.name, "Your name")
+		},
+		func(gtx C) D {
+			return field(gtx, th, &ui.host, "Visiting")
+		},
+		func(gtx C) D {
+			gtx.Constraints.Min.Y = gtx.Px(unit.Dp(96))
+			return field(gtx, th, &ui.note, "Note (optional)")
+		},
+		func(gtx C) D {
+			return layout.Flex{Spacing: layout.SpaceStart}.Layout(gtx,
+				layout.Rigid(material.Button(th, &ui.clear, "Clear").Layout),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+				layout.Rigid(material.Button(th, &ui.submit, "Sign in").Layout),
+			)
+		},
+		material.Body1(th, ui.message).Layout,
+	}
+	return layout.UniformInset(unit.Dp(24)).Layout(gtx, func(gtx C) D {
+		return ui.list.Layout(gtx, len(widgets), func(gtx C, i int) D {
+			return layout.Inset{Bottom: unit.Dp(16)}.Layout(gtx, widgets[i])
+		})
+	})
+}
+
+// field lays out an outlined editor, highlighted while focused.
+func field(gtx C, th *material.Theme, ed *widget.Editor, hint string) D {
+	col := th.Fg
+	col.A = 0x60
+	if ed.Focused() {
+		col = th.ContrastBg
+	}
+	border := widget.Border{Color: col, CornerRadius: unit.Dp(4), Width: unit.Dp(1)}
+	if ed.Focused() {
+		border.Width = unit.Dp(2)
+	}
+	return border.Layout(gtx, func(gtx C) D {
+		return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+			gtx.Constraints.Min.Y -= gtx.Px(unit.Dp(24))
+			if gtx.Constraints.Min.Y < 0 {
+				gtx.Constraints.Min.Y = 0
+			}
+			return material.Editor(th, ed, hint).Layout(gtx)
+		})
+	})
+}
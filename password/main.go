@@ -0,0 +1,201 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates secure text entry: a password field that
+// obscures its glyphs unless revealed, warns when caps lock seems to be
+// on and rates the password with a strength meter, and a confirmation
+// field that must match it.
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Password"),
+			app.Size(unit.Dp(420), unit.Dp(480)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// maskRune replaces the glyphs of obscured passwords.
+const maskRune = '•'
+
+var (
+	warnColor = color.NRGBA{R: 0xf5, G: 0x7c, B: 0x00, A: 0xff}
+	// meterColors are the colors of the strength ratings.
+	meterColors = [...]color.NRGBA{
+		{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff},
+		{R: 0xf5, G: 0x7c, B: 0x00, A: 0xff},
+		{R: 0xfb, G: 0xc0, B: 0x2d, A: 0xff},
+		{R: 0x7c, G: 0xb3, B: 0x42, A: 0xff},
+		{R: 0x38, G: 0x8e, B: 0x3c, A: 0xff},
+	}
+)
+
+// passwordField is an obscured editor with a button to reveal it.
+type passwordField struct {
+	editor widget.Editor
+	reveal widget.Clickable
+	shown  bool
+}
+
+func newPasswordField() *passwordField {
+	return &passwordField{
+		editor: widget.Editor{SingleLine: true, Mask: maskRune},
+	}
+}
+
+func (p *passwordField) Layout(gtx C, th *material.Theme, hint string) D {
+	for p.reveal.Clicked() {
+		p.shown = !p.shown
+	}
+	p.editor.Mask = maskRune
+	label := "Show"
+	if p.shown {
+		p.editor.Mask = 0
+		label = "Hide"
+	}
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	return widget.Border{Color: color.NRGBA{A: 0x40}, CornerRadius: unit.Dp(4), Width: unit.Dp(1)}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx C) D {
+				return layout.UniformInset(unit.Dp(8)).Layout(gtx, material.Editor(th, &p.editor, hint).Layout)
+			}),
+			layout.Rigid(func(gtx C) D {
+				b := material.Button(th, &p.reveal, label)
+				b.Background = color.NRGBA{}
+				b.Color = th.Palette.ContrastBg
+				b.Inset = layout.UniformInset(unit.Dp(8))
+				return b.Layout(gtx)
+			}),
+		)
+	})
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	var (
+		ops      op.Ops
+		password = newPasswordField()
+		confirm  = newPasswordField()
+		submit   widget.Clickable
+		status   string
+	)
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			pw := password.editor.Text()
+			rating := rate(pw)
+			mismatch := confirm.editor.Text() != "" && confirm.editor.Text() != pw
+			ok := rating >= fair && !mismatch && confirm.editor.Text() != ""
+			for submit.Clicked() {
+				status = "Password changed."
+				password.editor.SetText("")
+				confirm.editor.SetText("")
+			}
+			warning := func(show bool, msg string) layout.FlexChild {
+				return layout.Rigid(func(gtx C) D {
+					if !show {
+						return D{}
+					}
+					l := material.Caption(th, msg)
+					l.Color = warnColor
+					return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, l.Layout)
+				})
+			}
+			layout.UniformInset(unit.Dp(24)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(material.H6(th, "Choose a password").Layout),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+					layout.Rigid(func(gtx C) D {
+						return password.Layout(gtx, th, "New password")
+					}),
+					warning(capsLockLikely(pw), "Caps Lock may be on"),
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+							return meter(gtx, th, pw != "", rating)
+						})
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+					layout.Rigid(func(gtx C) D {
+						return confirm.Layout(gtx, th, "Confirm password")
+					}),
+					warning(capsLockLikely(confirm.editor.Text()), "Caps Lock may be on"),
+					warning(mismatch, "The passwords don't match"),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(24)}.Layout),
+					layout.Rigid(func(gtx C) D {
+						if !ok {
+							gtx = gtx.Disabled()
+						}
+						return material.Button(th, &submit, "Change password").Layout(gtx)
+					}),
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, material.Body2(th, status).Layout)
+					}),
+				)
+			})
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+// meter draws a bar of segments filled up to the rating, and its name.
+func meter(gtx C, th *material.Theme, rated bool, s strength) D {
+	segments := len(meterColors)
+	gap := gtx.Px(unit.Dp(4))
+	h := gtx.Px(unit.Dp(6))
+	width := gtx.Constraints.Max.X
+	segWidth := (width - gap*(segments-1)) / segments
+	for i := 0; i < segments; i++ {
+		c := color.NRGBA{A: 0x20}
+		if rated && i <= int(s) {
+			c = meterColors[s]
+		}
+		x := i * (segWidth + gap)
+		paint.FillShape(gtx.Ops, c, clip.Rect(image.Rect(x, 0, x+segWidth, h)).Op())
+	}
+	name := ""
+	if rated {
+		name = s.String()
+	}
+	stack := op.Save(gtx.Ops)
+	op.Offset(layout.FPt(image.Pt(0, h+gap))).Add(gtx.Ops)
+	l := material.Caption(th, name)
+	if rated {
+		l.Color = meterColors[s]
+	}
+	dims := l.Layout(gtx)
+	stack.Load()
+	return D{Size: image.Pt(width, h+gap+dims.Size.Y)}
+}
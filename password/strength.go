@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// strength is a rating of a password.
+type strength int
+
+const (
+	veryWeak strength = iota
+	weak
+	fair
+	strong
+	veryStrong
+)
+
+func (s strength) String() string {
+	return [...]string{"Very weak", "Weak", "Fair", "Strong", "Very strong"}[s]
+}
+
+// common are passwords found at the top of leaked password lists.
+var common = map[string]bool{
+	"123456": true, "password": true, "123456789": true, "12345678": true,
+	"12345": true, "qwerty": true, "abc123": true, "password1": true,
+	"111111": true, "123123": true, "letmein": true, "welcome": true,
+	"iloveyou": true, "admin": true, "monkey": true, "dragon": true,
+	"sunshine": true, "princess": true, "football": true, "qwertyuiop": true,
+}
+
+// entropy estimates the bits of entropy of a password from the size of
+// the character classes it uses and its length. Characters repeating or
+// continuing a sequence from the previous one count for a single bit,
+// since they add little to the cost of guessing.
+func entropy(pw string) float64 {
+	if common[strings.ToLower(pw)] {
+		return 0
+	}
+	var lower, upper, digit, symbol, other bool
+	for _, r := range pw {
+		switch {
+		case r > unicode.MaxASCII:
+			other = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	pool := 0
+	for _, c := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if c.used {
+			pool += c.size
+		}
+	}
+	if pool == 0 {
+		return 0
+	}
+	perChar := math.Log2(float64(pool))
+	bits := 0.0
+	prev := rune(-1)
+	for _, r := range pw {
+		d := r - prev
+		if d == 0 || d == 1 || d == -1 {
+			bits++
+		} else {
+			bits += perChar
+		}
+		prev = r
+	}
+	return bits
+}
+
+// rate rates a password by its entropy.
+func rate(pw string) strength {
+	switch e := entropy(pw); {
+	case e < 28:
+		return veryWeak
+	case e < 36:
+		return weak
+	case e < 60:
+		return fair
+	case e < 128:
+		return strong
+	default:
+		return veryStrong
+	}
+}
+
+// capsLockLikely reports whether s looks typed with caps lock on, that is
+// whether its last three or more letters are upper case. Gio doesn't
+// report the state of caps lock, so this is a guess.
+func capsLockLikely(s string) bool {
+	const run = 3
+	n := 0
+	rs := []rune(s)
+	for i := len(rs) - 1; i >= 0; i-- {
+		r := rs[i]
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if !unicode.IsUpper(r) {
+			break
+		}
+		n++
+	}
+	return n >= run
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"testing"
+)
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		pw   string
+		want strength
+	}{
+		{"", veryWeak},
+		{"Password", veryWeak},
+		{"aaaaaaaaaaaa", veryWeak},
+		{"abcdefghijkl", veryWeak},
+		{"gopher", veryWeak},
+		{"gophers7", weak},
+		{"Gophers7!", fair},
+		{"Tr0ub4dor&3", strong},
+		{"correct horse battery staple", veryStrong},
+		{"x8#Lq!2vR@9z^Km4&Tw7$Pb3*Hn6%Yc", veryStrong},
+	}
+	for _, test := range tests {
+		if got := rate(test.pw); got != test.want {
+			t.Errorf("rate(%q) = %v (%.1f bits), want %v", test.pw, got, entropy(test.pw), test.want)
+		}
+	}
+}
+
+func TestCapsLockLikely(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"", false},
+		{"Hello", false},
+		{"hELLO", true},
+		{"AB1", false},
+		{"ab12CD3E", true},
+	}
+	for _, test := range tests {
+		if got := capsLockLikely(test.s); got != test.want {
+			t.Errorf("capsLockLikely(%q) = %v, want %v", test.s, got, test.want)
+		}
+	}
+}
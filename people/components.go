@@ -0,0 +1,258 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// circle fills a circle of diameter d with c.
+func circle(gtx C, c color.NRGBA, d int) D {
+	r := float32(d) / 2
+	paint.FillShape(gtx.Ops, c, clip.UniformRRect(f32.Rectangle{Max: f32.Pt(float32(d), float32(d))}, r).Op(gtx.Ops))
+	return D{Size: image.Pt(d, d)}
+}
+
+// Badge is a count displayed in a small pill, typically over the corner of
+// another widget.
+type Badge struct {
+	Count int
+	// Max is the largest count displayed as is; larger counts are shown
+	// as Max+.
+	Max   int
+	Color color.NRGBA
+}
+
+// Layout lays out the badge, or nothing if the count is zero.
+func (b Badge) Layout(gtx C, th *material.Theme) D {
+	if b.Count <= 0 {
+		return D{}
+	}
+	txt := fmt.Sprint(b.Count)
+	if b.Max > 0 && b.Count > b.Max {
+		txt = fmt.Sprintf("%d+", b.Max)
+	}
+	l := material.Caption(th, txt)
+	l.Color = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	macro := op.Record(gtx.Ops)
+	dims := layout.Inset{Left: unit.Dp(5), Right: unit.Dp(5)}.Layout(gtx, l.Layout)
+	call := macro.Stop()
+	// Never narrower than tall, so single digits are round.
+	var dx float32
+	if dims.Size.X < dims.Size.Y {
+		dx = float32(dims.Size.Y-dims.Size.X) / 2
+		dims.Size.X = dims.Size.Y
+	}
+	r := float32(dims.Size.Y) / 2
+	paint.FillShape(gtx.Ops, b.Color, clip.UniformRRect(f32.Rectangle{Max: layout.FPt(dims.Size)}, r).Op(gtx.Ops))
+	defer op.Save(gtx.Ops).Load()
+	op.Offset(f32.Pt(dx, 0)).Add(gtx.Ops)
+	call.Add(gtx.Ops)
+	return dims
+}
+
+// Presence is the online status of a person.
+type Presence uint8
+
+const (
+	Offline Presence = iota
+	Away
+	Online
+)
+
+func (p Presence) color() color.NRGBA {
+	switch p {
+	case Online:
+		return color.NRGBA{R: 0x43, G: 0xa0, B: 0x47, A: 0xff}
+	case Away:
+		return color.NRGBA{R: 0xff, G: 0xa0, B: 0x00, A: 0xff}
+	default:
+		return color.NRGBA{R: 0x9e, G: 0x9e, B: 0x9e, A: 0xff}
+	}
+}
+
+// Avatar is a round picture of a person, showing their initials when
+// there is no image, and their presence as a dot at the lower right.
+type Avatar struct {
+	Name     string
+	Image    *paint.ImageOp
+	Presence Presence
+	Size     unit.Value
+}
+
+// initials returns the first letters of the first and last word of name.
+func initials(name string) string {
+	words := strings.Fields(name)
+	if len(words) == 0 {
+		return ""
+	}
+	s := words[0][:1]
+	if len(words) > 1 {
+		s += words[len(words)-1][:1]
+	}
+	return strings.ToUpper(s)
+}
+
+// nameColor derives a stable background color from a name.
+func nameColor(name string) color.NRGBA {
+	palette := []color.NRGBA{
+		{R: 0xe5, G: 0x73, B: 0x73, A: 0xff},
+		{R: 0xba, G: 0x68, B: 0xc8, A: 0xff},
+		{R: 0x79, G: 0x86, B: 0xcb, A: 0xff},
+		{R: 0x4f, G: 0xc3, B: 0xf7, A: 0xff},
+		{R: 0x4d, G: 0xb6, B: 0xac, A: 0xff},
+		{R: 0xff, G: 0xb7, B: 0x4d, A: 0xff},
+	}
+	var h uint32
+	for _, c := range name {
+		h = h*31 + uint32(c)
+	}
+	return palette[h%uint32(len(palette))]
+}
+
+func (a Avatar) Layout(gtx C, th *material.Theme) D {
+	d := gtx.Px(a.Size)
+	sz := image.Pt(d, d)
+	stack := op.Save(gtx.Ops)
+	clip.UniformRRect(f32.Rectangle{Max: layout.FPt(sz)}, float32(d)/2).Add(gtx.Ops)
+	if a.Image != nil {
+		isz := a.Image.Size()
+		op.Affine(f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(float32(d)/float32(isz.X), float32(d)/float32(isz.Y)))).Add(gtx.Ops)
+		a.Image.Add(gtx.Ops)
+		paint.PaintOp{}.Add(gtx.Ops)
+	} else {
+		paint.Fill(gtx.Ops, nameColor(a.Name))
+		cgtx := gtx
+		cgtx.Constraints = layout.Exact(sz)
+		l := material.Body1(th, initials(a.Name))
+		l.Color = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+		layout.Center.Layout(cgtx, l.Layout)
+	}
+	stack.Load()
+
+	// The presence dot, ringed with the background color.
+	dot := d * 3 / 10
+	ring := gtx.Px(unit.Dp(2))
+	stack = op.Save(gtx.Ops)
+	op.Offset(layout.FPt(sz.Sub(image.Pt(dot, dot)))).Add(gtx.Ops)
+	circle(gtx, th.Palette.Bg, dot)
+	op.Offset(f32.Pt(float32(ring), float32(ring))).Add(gtx.Ops)
+	circle(gtx, a.Presence.color(), dot-2*ring)
+	stack.Load()
+	return D{Size: sz}
+}
+
+// chip draws a pill with a label and an optional trailing widget.
+func chip(gtx C, th *material.Theme, label string, selected bool, trailing layout.Widget) D {
+	bg := color.NRGBA{A: 0x14}
+	fg := th.Palette.Fg
+	if selected {
+		bg = th.Palette.ContrastBg
+		fg = th.Palette.ContrastFg
+	}
+	return layout.Stack{}.Layout(gtx,
+		layout.Expanded(func(gtx C) D {
+			sz := gtx.Constraints.Min
+			paint.FillShape(gtx.Ops, bg, clip.UniformRRect(f32.Rectangle{Max: layout.FPt(sz)}, float32(sz.Y)/2).Op(gtx.Ops))
+			return D{Size: sz}
+		}),
+		layout.Stacked(func(gtx C) D {
+			right := unit.Dp(12)
+			if trailing != nil {
+				right = unit.Dp(4)
+			}
+			return layout.Inset{Left: unit.Dp(12), Right: right, Top: unit.Dp(6), Bottom: unit.Dp(6)}.Layout(gtx, func(gtx C) D {
+				l := material.Body2(th, label)
+				l.Color = fg
+				if trailing == nil {
+					return l.Layout(gtx)
+				}
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(l.Layout),
+					layout.Rigid(trailing),
+				)
+			})
+		}),
+	)
+}
+
+// InputChip represents an entered value, such as a recipient, that can be
+// removed.
+type InputChip struct {
+	Label  string
+	remove widget.Clickable
+}
+
+// Removed reports whether the remove button was clicked.
+func (c *InputChip) Removed() bool {
+	return c.remove.Clicked()
+}
+
+func (c *InputChip) Layout(gtx C, th *material.Theme) D {
+	return chip(gtx, th, c.Label, false, func(gtx C) D {
+		return material.Clickable(gtx, &c.remove, func(gtx C) D {
+			return layout.Inset{Left: unit.Dp(6), Right: unit.Dp(6)}.Layout(gtx, material.Body2(th, "✕").Layout)
+		})
+	})
+}
+
+// ChoiceChips is a set of chips of which exactly one is selected.
+type ChoiceChips struct {
+	Options  []string
+	Selected int
+	clicks   []widget.Clickable
+}
+
+func (c *ChoiceChips) Layout(gtx C, th *material.Theme) D {
+	if len(c.clicks) < len(c.Options) {
+		c.clicks = make([]widget.Clickable, len(c.Options))
+	}
+	children := make([]layout.FlexChild, len(c.Options))
+	for i := range c.Options {
+		i := i
+		for c.clicks[i].Clicked() {
+			c.Selected = i
+		}
+		children[i] = layout.Rigid(func(gtx C) D {
+			return layout.Inset{Right: unit.Dp(6)}.Layout(gtx, func(gtx C) D {
+				return material.Clickable(gtx, &c.clicks[i], func(gtx C) D {
+					return chip(gtx, th, c.Options[i], i == c.Selected, nil)
+				})
+			})
+		})
+	}
+	return layout.Flex{}.Layout(gtx, children...)
+}
+
+// FilterChip is a chip that toggles a filter on and off, showing a check
+// mark while on.
+type FilterChip struct {
+	Label string
+	On    bool
+	click widget.Clickable
+}
+
+func (c *FilterChip) Layout(gtx C, th *material.Theme) D {
+	for c.click.Clicked() {
+		c.On = !c.On
+	}
+	label := c.Label
+	if c.On {
+		label = "✓ " + label
+	}
+	return material.Clickable(gtx, &c.click, func(gtx C) D {
+		return chip(gtx, th, label, c.On, nil)
+	})
+}
@@ -0,0 +1,202 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates badges, avatars and chips composed into a
+// contact list: avatars show initials or a picture with a presence dot,
+// badges count unread messages, choice and filter chips narrow the list
+// and tapping a contact adds it as a removable recipient chip.
+
+import (
+	"image/color"
+	"log"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/fakedata"
+	"gioui.org/example/internal/flow"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("People"),
+			app.Size(unit.Dp(420), unit.Dp(700)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var groups = []string{"All", "Friends", "Work", "Family"}
+
+type person struct {
+	name     string
+	group    int
+	presence Presence
+	unread   int
+	picture  *paint.ImageOp
+	click    widget.Clickable
+}
+
+func people() []*person {
+	f := fakedata.New(1)
+	var ps []*person
+	for i, n := range f.Names(15) {
+		p := &person{
+			name:     n,
+			group:    1 + f.Intn(len(groups)-1),
+			presence: Presence(f.Intn(3)),
+		}
+		if f.Intn(3) == 0 {
+			p.unread = 1 + f.Intn(120)
+		}
+		// Every third person has a picture.
+		if i%3 == 0 {
+			img := paint.NewImageOp(fakedata.Avatar(n, 64))
+			p.picture = &img
+		}
+		ps = append(ps, p)
+	}
+	return ps
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	var (
+		ops        op.Ops
+		all        = people()
+		group      = &ChoiceChips{Options: groups}
+		online     = &FilterChip{Label: "Online"}
+		unread     = &FilterChip{Label: "Unread"}
+		recipients []*InputChip
+		list       = layout.List{Axis: layout.Vertical}
+		badgeColor = color.NRGBA{R: 0xe5, G: 0x39, B: 0x35, A: 0xff}
+	)
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			for _, p := range all {
+				for p.click.Clicked() {
+					added := false
+					for _, r := range recipients {
+						added = added || r.Label == p.name
+					}
+					if !added {
+						recipients = append(recipients, &InputChip{Label: p.name})
+					}
+					p.unread = 0
+				}
+			}
+			kept := recipients[:0]
+			for _, r := range recipients {
+				if !r.Removed() {
+					kept = append(kept, r)
+				}
+			}
+			recipients = kept
+
+			type filter struct {
+				group          int
+				online, unread bool
+			}
+			before := filter{group.Selected, online.On, unread.On}
+			var shown []*person
+			for _, p := range all {
+				if group.Selected > 0 && p.group != group.Selected {
+					continue
+				}
+				if online.On && p.presence != Online {
+					continue
+				}
+				if unread.On && p.unread == 0 {
+					continue
+				}
+				shown = append(shown, p)
+			}
+
+			layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+						if len(recipients) == 0 {
+							return material.Body2(th, "Tap a contact to add a recipient").Layout(gtx)
+						}
+						return flow.Flow{Spacing: unit.Dp(6), LineSpacing: unit.Dp(6)}.Layout(gtx, len(recipients), func(gtx C, i int) D {
+							return recipients[i].Layout(gtx, th)
+						})
+					})
+				}),
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Left: unit.Dp(8), Right: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+						return group.Layout(gtx, th)
+					})
+				}),
+				layout.Rigid(func(gtx C) D {
+					return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+						return layout.Flex{}.Layout(gtx,
+							layout.Rigid(func(gtx C) D {
+								return layout.Inset{Right: unit.Dp(6)}.Layout(gtx, func(gtx C) D {
+									return online.Layout(gtx, th)
+								})
+							}),
+							layout.Rigid(func(gtx C) D {
+								return unread.Layout(gtx, th)
+							}),
+						)
+					})
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					return list.Layout(gtx, len(shown), func(gtx C, i int) D {
+						p := shown[i]
+						return material.Clickable(gtx, &p.click, func(gtx C) D {
+							gtx.Constraints.Min.X = gtx.Constraints.Max.X
+							return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+								return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+									layout.Rigid(func(gtx C) D {
+										return Avatar{Name: p.name, Image: p.picture, Presence: p.presence, Size: unit.Dp(44)}.Layout(gtx, th)
+									}),
+									layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+									layout.Flexed(1, func(gtx C) D {
+										return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+											layout.Rigid(material.Body1(th, p.name).Layout),
+											layout.Rigid(material.Caption(th, groups[p.group]).Layout),
+										)
+									}),
+									layout.Rigid(func(gtx C) D {
+										return Badge{Count: p.unread, Max: 99, Color: badgeColor}.Layout(gtx, th)
+									}),
+								)
+							})
+						})
+					})
+				}),
+			)
+			// The chips handle their clicks during layout; redraw to apply
+			// any change to the filters.
+			if before != (filter{group.Selected, online.On, unread.On}) {
+				op.InvalidateOp{}.Add(gtx.Ops)
+			}
+			e.Frame(gtx.Ops)
+		}
+	}
+}
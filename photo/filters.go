@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Filter is an image filter.
+type Filter struct {
+	Name string
+	// apply returns the full effect of the filter on an image.
+	apply func(src *image.RGBA) *image.RGBA
+}
+
+var filters = []Filter{
+	{Name: "Grayscale", apply: pixelFilter(grayscale)},
+	{Name: "Sepia", apply: pixelFilter(sepia)},
+	{Name: "Invert", apply: pixelFilter(invert)},
+	{Name: "Brighten", apply: pixelFilter(brighten)},
+	{Name: "Contrast", apply: pixelFilter(contrast)},
+	{Name: "Blur", apply: blur},
+}
+
+// Apply applies a filter to an image, mixing the result with the image
+// by an amount from 0, the image unchanged, to 1, the full effect.
+func Apply(f Filter, src *image.RGBA, amount float32) *image.RGBA {
+	dst := f.apply(src)
+	if amount >= 1 {
+		return dst
+	}
+	for i := range dst.Pix {
+		s, d := float32(src.Pix[i]), float32(dst.Pix[i])
+		dst.Pix[i] = uint8(s + (d-s)*amount + .5)
+	}
+	return dst
+}
+
+// toRGBA returns img as an RGBA image at the origin.
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	dst := image.NewRGBA(image.Rectangle{Max: img.Bounds().Size()})
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Src)
+	return dst
+}
+
+// pixelFilter returns a filter changing every pixel on its own. The
+// colors are premultiplied by alpha, in [0, 1].
+func pixelFilter(f func(r, g, b, a float32) (float32, float32, float32)) func(src *image.RGBA) *image.RGBA {
+	return func(src *image.RGBA) *image.RGBA {
+		dst := image.NewRGBA(src.Rect)
+		for i := 0; i < len(src.Pix); i += 4 {
+			p := src.Pix[i : i+4]
+			a := float32(p[3]) / 0xff
+			r, g, b := f(float32(p[0])/0xff, float32(p[1])/0xff, float32(p[2])/0xff, a)
+			dst.Pix[i+0] = channel(r, a)
+			dst.Pix[i+1] = channel(g, a)
+			dst.Pix[i+2] = channel(b, a)
+			dst.Pix[i+3] = p[3]
+		}
+		return dst
+	}
+}
+
+// channel converts a premultiplied channel to a byte, clamped to alpha.
+func channel(v, a float32) uint8 {
+	if v < 0 {
+		v = 0
+	}
+	if v > a {
+		v = a
+	}
+	return uint8(v*0xff + .5)
+}
+
+func luma(r, g, b float32) float32 {
+	return 0.2126*r + 0.7152*g + 0.0722*b
+}
+
+func grayscale(r, g, b, a float32) (float32, float32, float32) {
+	y := luma(r, g, b)
+	return y, y, y
+}
+
+func sepia(r, g, b, a float32) (float32, float32, float32) {
+	return 0.393*r + 0.769*g + 0.189*b,
+		0.349*r + 0.686*g + 0.168*b,
+		0.272*r + 0.534*g + 0.131*b
+}
+
+func invert(r, g, b, a float32) (float32, float32, float32) {
+	return a - r, a - g, a - b
+}
+
+func brighten(r, g, b, a float32) (float32, float32, float32) {
+	return r * 1.4, g * 1.4, b * 1.4
+}
+
+// contrast doubles the distance of the channels from the middle gray.
+func contrast(r, g, b, a float32) (float32, float32, float32) {
+	c := func(v float32) float32 {
+		return (v-a/2)*2 + a/2
+	}
+	return c(r), c(g), c(b)
+}
+
+// blur blurs an image by three box blurs, which approximate a Gaussian
+// blur, of a radius of a hundredth of the image.
+func blur(src *image.RGBA) *image.RGBA {
+	size := src.Rect.Size()
+	radius := size.X
+	if size.Y > radius {
+		radius = size.Y
+	}
+	radius /= 100
+	if radius < 1 {
+		radius = 1
+	}
+	dst := image.NewRGBA(src.Rect)
+	copy(dst.Pix, src.Pix)
+	tmp := image.NewRGBA(src.Rect)
+	for i := 0; i < 3; i++ {
+		boxBlur(tmp, dst, radius, true)
+		boxBlur(dst, tmp, radius, false)
+	}
+	return dst
+}
+
+// boxBlur blurs the rows or columns of src into dst, of the same size,
+// with a moving average. Pixels past the edges repeat the edge pixels.
+func boxBlur(dst, src *image.RGBA, radius int, rows bool) {
+	size := src.Rect.Size()
+	n, lines := size.X, size.Y
+	if !rows {
+		n, lines = size.Y, size.X
+	}
+	offset := func(line, i int) int {
+		if i < 0 {
+			i = 0
+		} else if i >= n {
+			i = n - 1
+		}
+		if rows {
+			return line*src.Stride + i*4
+		}
+		return i*src.Stride + line*4
+	}
+	div := 2*radius + 1
+	for l := 0; l < lines; l++ {
+		var sum [4]int
+		for i := -radius; i <= radius; i++ {
+			o := offset(l, i)
+			for c := range sum {
+				sum[c] += int(src.Pix[o+c])
+			}
+		}
+		for i := 0; i < n; i++ {
+			o := offset(l, i)
+			for c := range sum {
+				dst.Pix[o+c] = uint8(sum[c] / div)
+			}
+			in, out := offset(l, i+radius+1), offset(l, i-radius)
+			for c := range sum {
+				sum[c] += int(src.Pix[in+c]) - int(src.Pix[out+c])
+			}
+		}
+	}
+}
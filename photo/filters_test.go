@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	src.SetRGBA(0, 0, color.RGBA{R: 200, G: 100, B: 0, A: 0xff})
+	find := func(name string) Filter {
+		for _, f := range filters {
+			if f.Name == name {
+				return f
+			}
+		}
+		t.Fatalf("no filter %s", name)
+		return Filter{}
+	}
+	tests := []struct {
+		filter string
+		amount float32
+		want   color.RGBA
+	}{
+		{"Invert", 1, color.RGBA{R: 55, G: 155, B: 255, A: 0xff}},
+		{"Invert", .5, color.RGBA{R: 128, G: 128, B: 128, A: 0xff}},
+		{"Invert", 0, color.RGBA{R: 200, G: 100, B: 0, A: 0xff}},
+		{"Brighten", 1, color.RGBA{R: 255, G: 140, B: 0, A: 0xff}},
+		{"Contrast", 1, color.RGBA{R: 255, G: 73, B: 0, A: 0xff}},
+		{"Grayscale", 1, color.RGBA{R: 114, G: 114, B: 114, A: 0xff}},
+	}
+	for _, test := range tests {
+		got := Apply(find(test.filter), src, test.amount).RGBAAt(0, 0)
+		if got != test.want {
+			t.Errorf("%s by %v = %v, want %v", test.filter, test.amount, got, test.want)
+		}
+	}
+	if got := src.RGBAAt(0, 0); got != (color.RGBA{R: 200, G: 100, B: 0, A: 0xff}) {
+		t.Errorf("filters changed the source to %v", got)
+	}
+}
+
+func TestPremultiplied(t *testing.T) {
+	// Half transparent white stays within its alpha when brightened and
+	// inverts to transparent black.
+	src := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	src.SetRGBA(0, 0, color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0x80})
+	for _, f := range filters {
+		got := Apply(f, src, 1).RGBAAt(0, 0)
+		if got.A != 0x80 || got.R > got.A || got.G > got.A || got.B > got.A {
+			t.Errorf("%s = %v, not premultiplied alpha 0x80", f.Name, got)
+		}
+	}
+}
+
+func TestPreview(t *testing.T) {
+	img := testImage(3300, 1000)
+	p := preview(img)
+	if got := p.Rect.Size(); got != image.Pt(1100, 333) {
+		t.Errorf("preview of %v, want 1100x333", got)
+	}
+	if p := preview(p); p.Rect.Size() != image.Pt(1100, 333) {
+		t.Error("preview scaled down a small image")
+	}
+}
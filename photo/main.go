@@ -0,0 +1,305 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates previewing image filters with a before and
+// after comparison: pick a filter and its strength, and drag the divider
+// across the photo to compare the result with the original. Difference
+// mode shows what the filter changed, amplified by the gain.
+//
+// Usage:
+//
+//	go run ./photo [-o filtered.png] [image]
+//
+// Without an image, a generated test picture is shown. Save applies the
+// filter to the image at full size and writes it to the -o file as PNG.
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"log"
+	"math"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"gioui.org/example/internal/compare"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var outFlag = flag.String("o", "filtered.png", "file the filtered image is saved to")
+
+// previewSize bounds the size of the preview, to keep filtering fast.
+const previewSize = 1600
+
+func main() {
+	flag.Parse()
+	var img image.Image
+	if flag.NArg() > 0 {
+		var err error
+		img, err = loadImage(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		img = testImage(960, 640)
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("Photo Filters"),
+			app.Size(unit.Dp(1100), unit.Dp(720)),
+		)
+		if err := loop(w, toRGBA(img)); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+func loadImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+// testImage draws a picture of color gradients and discs.
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			fx, fy := float64(x)/float64(w), float64(y)/float64(h)
+			c := color.RGBA{R: uint8(255 * fx), G: uint8(200 * fy), B: uint8(255 * (1 - fx*fy)), A: 0xff}
+			for i, d := range []struct{ x, y, r float64 }{{.3, .4, .18}, {.7, .6, .22}, {.55, .25, .08}} {
+				if math.Hypot(fx-d.x, (fy-d.y)*float64(h)/float64(w)) < d.r {
+					v := uint8(80 * i)
+					c = color.RGBA{R: 0xff - v, G: 0xe0 - v/2, B: v, A: 0xff}
+				}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// preview returns img scaled down to at most previewSize pixels wide
+// and tall.
+func preview(img *image.RGBA) *image.RGBA {
+	size := img.Rect.Size()
+	n := (size.X + previewSize - 1) / previewSize
+	if m := (size.Y + previewSize - 1) / previewSize; m > n {
+		n = m
+	}
+	if n <= 1 {
+		return img
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, size.X/n, size.Y/n))
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			// Average the block of pixels, premultiplied.
+			var sum [4]int
+			for dy := 0; dy < n; dy++ {
+				o := img.PixOffset(x*n, y*n+dy)
+				for dx := 0; dx < 4*n; dx += 4 {
+					for c := range sum {
+						sum[c] += int(img.Pix[o+dx+c])
+					}
+				}
+			}
+			o := dst.PixOffset(x, y)
+			for c := range sum {
+				dst.Pix[o+c] = uint8(sum[c] / (n * n))
+			}
+		}
+	}
+	return dst
+}
+
+type filtered struct {
+	gen int
+	img *image.RGBA
+}
+
+type App struct {
+	orig, preview *image.RGBA
+	compare       compare.Compare
+
+	filter     widget.Enum
+	amount     widget.Float
+	difference widget.Bool
+	gain       widget.Float
+	save       widget.Clickable
+
+	// gen counts the changes of the filter, to drop stale results, and
+	// filterOf and amountOf are the filter and amount last applied.
+	gen      int
+	filterOf string
+	amountOf float32
+	results  chan filtered
+
+	saved  chan error
+	saving bool
+	status string
+}
+
+func loop(w *app.Window, img *image.RGBA) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		orig:    img,
+		preview: preview(img),
+		results: make(chan filtered, 1),
+		saved:   make(chan error, 1),
+	}
+	a.compare.Pos = .5
+	a.filter.Value = filters[0].Name
+	a.amount.Value = 1
+	a.gain.Value = 4
+	a.compare.SetImages(a.preview, a.preview)
+	var ops op.Ops
+	for {
+		select {
+		case r := <-a.results:
+			if r.gen == a.gen {
+				a.compare.SetAfter(r.img)
+				w.Invalidate()
+			}
+		case err := <-a.saved:
+			a.saving = false
+			if err != nil {
+				a.status = err.Error()
+			} else {
+				a.status = "Saved " + *outFlag
+			}
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update(gtx)
+				a.layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+func (a *App) current() Filter {
+	for _, f := range filters {
+		if f.Name == a.filter.Value {
+			return f
+		}
+	}
+	return filters[0]
+}
+
+func (a *App) update(gtx C) {
+	// Filter the preview when the filter or amount changes, off the UI
+	// goroutine.
+	if a.filter.Value != a.filterOf || a.amount.Value != a.amountOf {
+		a.filterOf, a.amountOf = a.filter.Value, a.amount.Value
+		a.gen++
+		gen, f, amount, src := a.gen, a.current(), a.amount.Value, a.preview
+		go func() {
+			a.results <- filtered{gen, Apply(f, src, amount)}
+		}()
+	}
+	if a.difference.Value {
+		a.compare.Mode = compare.Difference
+	} else {
+		a.compare.Mode = compare.Split
+	}
+	a.compare.Gain = a.gain.Value
+	for a.save.Clicked() {
+		if a.saving {
+			break
+		}
+		a.saving = true
+		a.status = "Saving…"
+		f, amount, src := a.current(), a.amount.Value, a.orig
+		go func() {
+			a.saved <- saveImage(*outFlag, Apply(f, src, amount))
+		}()
+	}
+}
+
+func saveImage(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (a *App) layout(gtx C, th *material.Theme) D {
+	return layout.Flex{}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Px(unit.Dp(240))
+			gtx.Constraints.Max.X = gtx.Constraints.Min.X
+			return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+				return a.layoutControls(gtx, th)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+				gtx.Constraints.Min = gtx.Constraints.Max
+				return a.compare.Layout(gtx, th)
+			})
+		}),
+	)
+}
+
+func (a *App) layoutControls(gtx C, th *material.Theme) D {
+	children := []layout.FlexChild{
+		layout.Rigid(material.H6(th, "Filter").Layout),
+	}
+	for _, f := range filters {
+		children = append(children, layout.Rigid(material.RadioButton(th, &a.filter, f.Name, f.Name).Layout))
+	}
+	children = append(children,
+		layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+		layout.Rigid(material.Body2(th, fmt.Sprintf("Amount %.0f%%", a.amount.Value*100)).Layout),
+		layout.Rigid(material.Slider(th, &a.amount, 0, 1).Layout),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+		layout.Rigid(material.CheckBox(th, &a.difference, "Difference").Layout),
+		layout.Rigid(func(gtx C) D {
+			if !a.difference.Value {
+				return D{}
+			}
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(material.Body2(th, fmt.Sprintf("Gain ×%.0f", a.gain.Value)).Layout),
+				layout.Rigid(material.Slider(th, &a.gain, 1, 16).Layout),
+			)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(24)}.Layout),
+		layout.Rigid(material.Button(th, &a.save, "Save").Layout),
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, material.Caption(th, a.status).Layout)
+		}),
+	)
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
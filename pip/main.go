@@ -0,0 +1,228 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program is a video player with a mini player, in the way of
+// picture-in-picture: Mini player shrinks the window to the video alone,
+// kept above other windows with a play button and a progress bar, and a
+// tap on the video expands it back to the full player where it was.
+//
+// Keeping the window on top uses the window handle on Windows, the
+// floating window level on macOS and the wmctrl tool on X11. Gio doesn't
+// implement picture-in-picture on Android, and gogio can't declare the
+// support for it that the manifest needs, so the mini player is a
+// desktop feature.
+//
+// Usage:
+//
+//	go run ./pip [-length 2m]
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+var lengthFlag = flag.Duration("length", 2*time.Minute, "length of the clip")
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	errorColor  = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	playerColor = color.NRGBA{R: 0x12, G: 0x12, B: 0x12, A: 0xff}
+	textColor   = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xe0}
+)
+
+// title is the title of the window in both modes, for finding the
+// window by it.
+const title = "Picture in picture"
+
+// miniSize is the size of the mini player.
+var miniSize = [2]unit.Value{unit.Dp(320), unit.Dp(180)}
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title(title),
+			app.Size(unit.Dp(900), unit.Dp(620)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+type App struct {
+	win  *app.Window
+	play Playback
+	mini bool
+	// full is the size of the window before it shrank to the mini
+	// player.
+	full image.Point
+	// size is the size of the window in the last frame.
+	size   image.Point
+	top    onTop
+	topErr error
+
+	toggle, shrink, expand widget.Clickable
+	seek                   widget.Float
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		win:  w,
+		play: Playback{Length: *lengthFlag},
+	}
+	a.play.Play(time.Now())
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		a.top.event(e)
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			a.size = e.Size
+			a.update(gtx)
+			if a.mini {
+				a.layoutMini(gtx, th)
+			} else {
+				a.layoutFull(gtx, th)
+			}
+			if a.play.Playing(gtx.Now) {
+				op.InvalidateOp{}.Add(gtx.Ops)
+			}
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+func (a *App) update(gtx C) {
+	now := gtx.Now
+	for a.toggle.Clicked() {
+		a.play.Toggle(now)
+	}
+	if a.seek.Changed() {
+		a.play.Seek(time.Duration(float64(a.seek.Value)*float64(a.play.Length)), now)
+	}
+	if a.shrink.Clicked() && !a.mini {
+		a.full = a.size
+		a.mini = true
+		a.win.Option(app.Size(miniSize[0], miniSize[1]))
+		a.topErr = a.top.set(a.win, true)
+	}
+	if a.expand.Clicked() && a.mini {
+		a.mini = false
+		a.top.set(a.win, false)
+		a.win.Option(app.Size(unit.Px(float32(a.full.X)), unit.Px(float32(a.full.Y))))
+	}
+}
+
+// layoutVideo draws the clip as large as fits, centered.
+func (a *App) layoutVideo(gtx C) D {
+	size := layout.FPt(gtx.Constraints.Max)
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 0xff}, clip.Rect{Max: gtx.Constraints.Max}.Op())
+	vsz := f32.Pt(size.X, size.X/aspect)
+	if vsz.Y > size.Y {
+		vsz = f32.Pt(size.Y*aspect, size.Y)
+	}
+	defer op.Save(gtx.Ops).Load()
+	op.Offset(size.Sub(vsz).Mul(.5)).Add(gtx.Ops)
+	drawScene(gtx, vsz, a.play.Position(gtx.Now), a.play.Length)
+	return D{Size: gtx.Constraints.Max}
+}
+
+// layoutFull draws the full player: the video above its controls.
+func (a *App) layoutFull(gtx C, th *material.Theme) D {
+	paint.Fill(gtx.Ops, playerColor)
+	pos := a.play.Position(gtx.Now)
+	if !a.seek.Dragging() {
+		a.seek.Value = float32(pos.Seconds() / a.play.Length.Seconds())
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Flexed(1, a.layoutVideo),
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(material.Button(th, &a.toggle, playLabel(a.play.Playing(gtx.Now))).Layout),
+					layout.Rigid(func(gtx C) D {
+						l := material.Body2(th, fmt.Sprintf("%s / %s", clock(pos), clock(a.play.Length)))
+						l.Color = textColor
+						return layout.Inset{Left: unit.Dp(12), Right: unit.Dp(12)}.Layout(gtx, l.Layout)
+					}),
+					layout.Flexed(1, material.Slider(th, &a.seek, 0, 1).Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+					layout.Rigid(material.Button(th, &a.shrink, "Mini player").Layout),
+				)
+			})
+		}),
+		layout.Rigid(func(gtx C) D {
+			if a.topErr == nil {
+				return D{}
+			}
+			l := material.Caption(th, "The mini player couldn't stay on top: "+a.topErr.Error())
+			l.Color = errorColor
+			return layout.Inset{Left: unit.Dp(12), Right: unit.Dp(12), Bottom: unit.Dp(8)}.Layout(gtx, l.Layout)
+		}),
+	)
+}
+
+// layoutMini draws the mini player: the video, which expands the player
+// when tapped, with a play button and a progress bar over it.
+func (a *App) layoutMini(gtx C, th *material.Theme) D {
+	size := gtx.Constraints.Max
+	a.expand.Layout(gtx)
+	a.layoutVideo(gtx)
+	// The play button is above the area of expand, and gets its taps.
+	layout.SW.Layout(gtx, func(gtx C) D {
+		return layout.UniformInset(unit.Dp(6)).Layout(gtx, func(gtx C) D {
+			b := material.Button(th, &a.toggle, playLabel(a.play.Playing(gtx.Now)))
+			b.Background = color.NRGBA{A: 0xa0}
+			b.TextSize = unit.Sp(12)
+			b.Inset = layout.UniformInset(unit.Dp(6))
+			return b.Layout(gtx)
+		})
+	})
+	frac := a.play.Position(gtx.Now).Seconds() / a.play.Length.Seconds()
+	h := gtx.Px(unit.Dp(3))
+	bar := image.Rect(0, size.Y-h, int(float64(size.X)*frac), size.Y)
+	paint.FillShape(gtx.Ops, th.Palette.ContrastBg, clip.Rect(bar).Op())
+	return D{Size: size}
+}
+
+func playLabel(playing bool) string {
+	if playing {
+		return "Pause"
+	}
+	return "Play"
+}
+
+// clock formats a position as minutes and seconds.
+func clock(d time.Duration) string {
+	s := int(d.Seconds())
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build darwin && !ios
+// +build darwin,!ios
+
+package main
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AppKit
+
+#include <stdint.h>
+
+void gio_setFloating(uintptr_t view, int on);
+*/
+import "C"
+
+import (
+	"errors"
+
+	"gioui.org/app"
+	"gioui.org/io/event"
+)
+
+// onTop keeps the window above others by raising it to the level of
+// floating windows.
+type onTop struct {
+	view uintptr
+}
+
+func (t *onTop) event(e event.Event) {
+	if e, ok := e.(app.ViewEvent); ok {
+		t.view = e.View
+	}
+}
+
+func (t *onTop) set(w *app.Window, on bool) error {
+	if t.view == 0 {
+		return errors.New("the window has no view")
+	}
+	flag := C.int(0)
+	if on {
+		flag = 1
+	}
+	// AppKit must be called from the main thread.
+	w.Run(func() {
+		C.gio_setFloating(C.uintptr_t(t.view), flag)
+	})
+	return nil
+}
@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build darwin && !ios
+// +build darwin,!ios
+
+#import <AppKit/AppKit.h>
+
+#include "_cgo_export.h"
+
+void gio_setFloating(uintptr_t view, int on) {
+	NSWindow *window = [(__bridge NSView *)(void *)view window];
+	window.level = on ? NSFloatingWindowLevel : NSNormalWindowLevel;
+	// Stay in view over full screen windows and on every space.
+	if (on) {
+		window.collectionBehavior |= NSWindowCollectionBehaviorCanJoinAllSpaces | NSWindowCollectionBehaviorFullScreenAuxiliary;
+	} else {
+		window.collectionBehavior &= ~(NSWindowCollectionBehaviorCanJoinAllSpaces | NSWindowCollectionBehaviorFullScreenAuxiliary);
+	}
+}
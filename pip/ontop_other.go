@@ -0,0 +1,26 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build !windows && !(darwin && !ios) && !((linux && !android) || freebsd || openbsd)
+// +build !windows
+// +build !darwin ios
+// +build !linux android
+// +build !freebsd
+// +build !openbsd
+
+package main
+
+import (
+	"errors"
+
+	"gioui.org/app"
+	"gioui.org/io/event"
+)
+
+// onTop can't keep windows on top on this platform.
+type onTop struct{}
+
+func (t *onTop) event(e event.Event) {}
+
+func (t *onTop) set(w *app.Window, on bool) error {
+	return errors.New("windows can't stay on top on this platform")
+}
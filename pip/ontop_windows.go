@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"errors"
+	"syscall"
+
+	"gioui.org/app"
+	"gioui.org/io/event"
+)
+
+// onTop keeps the window above others by making it topmost.
+type onTop struct {
+	hwnd uintptr
+}
+
+var (
+	user32           = syscall.NewLazyDLL("user32.dll")
+	procSetWindowPos = user32.NewProc("SetWindowPos")
+)
+
+const (
+	// _HWND_TOPMOST and _HWND_NOTOPMOST are -1 and -2.
+	_HWND_TOPMOST   = ^uintptr(0)
+	_HWND_NOTOPMOST = ^uintptr(1)
+
+	_SWP_NOSIZE     = 0x0001
+	_SWP_NOMOVE     = 0x0002
+	_SWP_NOACTIVATE = 0x0010
+)
+
+func (t *onTop) event(e event.Event) {
+	if e, ok := e.(app.ViewEvent); ok {
+		t.hwnd = e.HWND
+	}
+}
+
+func (t *onTop) set(w *app.Window, on bool) error {
+	if t.hwnd == 0 {
+		return errors.New("the window has no handle")
+	}
+	after := _HWND_NOTOPMOST
+	if on {
+		after = _HWND_TOPMOST
+	}
+	r, _, err := procSetWindowPos.Call(t.hwnd, after, 0, 0, 0, 0, _SWP_NOSIZE|_SWP_NOMOVE|_SWP_NOACTIVATE)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
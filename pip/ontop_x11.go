@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build (linux && !android) || freebsd || openbsd
+// +build linux,!android freebsd openbsd
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"gioui.org/app"
+	"gioui.org/io/event"
+)
+
+// onTop asks the window manager to keep the window above others, with
+// the wmctrl tool. Gio doesn't hand out the X11 window, so it is found by
+// its title; Wayland has no way for clients to stay on top at all.
+type onTop struct{}
+
+func (t *onTop) event(e event.Event) {}
+
+func (t *onTop) set(w *app.Window, on bool) error {
+	op := "remove"
+	if on {
+		op = "add"
+	}
+	out, err := exec.Command("wmctrl", "-F", "-r", title, "-b", op+",above").CombinedOutput()
+	if err != nil && len(out) > 0 {
+		err = fmt.Errorf("wmctrl: %s", strings.TrimSpace(string(out)))
+	}
+	return err
+}
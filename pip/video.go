@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+)
+
+// Playback is the clock of a clip: its position and whether it plays.
+type Playback struct {
+	Length time.Duration
+
+	playing bool
+	// pos is the position at since, the time the clock last changed.
+	pos   time.Duration
+	since time.Time
+}
+
+// Position returns the position at now. The clip stops at its end.
+func (p *Playback) Position(now time.Time) time.Duration {
+	pos := p.pos
+	if p.playing {
+		pos += now.Sub(p.since)
+	}
+	if pos > p.Length {
+		pos = p.Length
+	}
+	return pos
+}
+
+// Playing reports whether the clip plays at now.
+func (p *Playback) Playing(now time.Time) bool {
+	return p.playing && p.Position(now) < p.Length
+}
+
+func (p *Playback) Play(now time.Time) {
+	pos := p.Position(now)
+	if pos >= p.Length {
+		// Start over.
+		pos = 0
+	}
+	p.pos, p.since, p.playing = pos, now, true
+}
+
+func (p *Playback) Pause(now time.Time) {
+	p.pos, p.since, p.playing = p.Position(now), now, false
+}
+
+func (p *Playback) Toggle(now time.Time) {
+	if p.Playing(now) {
+		p.Pause(now)
+	} else {
+		p.Play(now)
+	}
+}
+
+// Seek moves to pos, clamped to the clip.
+func (p *Playback) Seek(pos time.Duration, now time.Time) {
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > p.Length {
+		pos = p.Length
+	}
+	p.pos, p.since = pos, now
+}
+
+// aspect is the aspect ratio of the clip.
+const aspect = 16.0 / 9
+
+// drawScene draws the clip at position pos, filling size: a day passing
+// over hills by the sea, with a boat sailing across.
+func drawScene(gtx layout.Context, size f32.Point, pos, length time.Duration) {
+	// day runs from 0 at dawn to 1 at dusk.
+	day := pos.Seconds() / length.Seconds()
+	w, h := size.X, size.Y
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect{Max: image.Pt(int(w), int(h))}.Add(gtx.Ops)
+
+	// The sky, bright at noon and red at dawn and dusk.
+	noon := float32(math.Sin(day * math.Pi))
+	top := mix(color.NRGBA{R: 0x1a, G: 0x23, B: 0x7e, A: 0xff}, color.NRGBA{R: 0x21, G: 0x96, B: 0xf3, A: 0xff}, noon)
+	horizon := mix(color.NRGBA{R: 0xff, G: 0x70, B: 0x43, A: 0xff}, color.NRGBA{R: 0xbb, G: 0xde, B: 0xfb, A: 0xff}, noon)
+	paint.LinearGradientOp{Stop1: f32.Pt(0, 0), Color1: top, Stop2: f32.Pt(0, h*0.65), Color2: horizon}.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+
+	// The sun, along an arc from left to right.
+	a := math.Pi * (1 - day)
+	sun := f32.Pt(w/2+float32(math.Cos(a))*w*0.4, h*0.62-float32(math.Sin(a))*h*0.5)
+	sunColor := mix(color.NRGBA{R: 0xff, G: 0x8a, B: 0x65, A: 0xff}, color.NRGBA{R: 0xff, G: 0xf5, B: 0x9d, A: 0xff}, noon)
+	paint.FillShape(gtx.Ops, sunColor, clip.Circle{Center: sun, Radius: h * 0.07}.Op(gtx.Ops))
+
+	// The sea.
+	sea := mix(color.NRGBA{R: 0x0d, G: 0x47, B: 0xa1, A: 0xff}, color.NRGBA{R: 0x19, G: 0x76, B: 0xd2, A: 0xff}, noon)
+	paint.FillShape(gtx.Ops, sea, clip.Rect(image.Rect(0, int(h*0.65), int(w), int(h))).Op())
+
+	// The boat, bobbing on the waves.
+	t := pos.Seconds()
+	bx := -w*0.1 + float32(day)*w*1.2
+	by := h*0.72 + float32(math.Sin(t*2))*h*0.008
+	var boat clip.Path
+	boat.Begin(gtx.Ops)
+	boat.MoveTo(f32.Pt(bx-w*0.05, by))
+	boat.LineTo(f32.Pt(bx+w*0.05, by))
+	boat.LineTo(f32.Pt(bx+w*0.035, by+h*0.03))
+	boat.LineTo(f32.Pt(bx-w*0.035, by+h*0.03))
+	boat.Close()
+	paint.FillShape(gtx.Ops, color.NRGBA{R: 0x4e, G: 0x34, B: 0x2e, A: 0xff}, clip.Outline{Path: boat.End()}.Op())
+	var sail clip.Path
+	sail.Begin(gtx.Ops)
+	sail.MoveTo(f32.Pt(bx, by-h*0.005))
+	sail.LineTo(f32.Pt(bx, by-h*0.13))
+	sail.LineTo(f32.Pt(bx+w*0.04, by-h*0.005))
+	sail.Close()
+	paint.FillShape(gtx.Ops, color.NRGBA{R: 0xfa, G: 0xfa, B: 0xfa, A: 0xff}, clip.Outline{Path: sail.End()}.Op())
+
+	// Hills in front.
+	hill := mix(color.NRGBA{R: 0x1b, G: 0x3a, B: 0x1d, A: 0xff}, color.NRGBA{R: 0x38, G: 0x8e, B: 0x3c, A: 0xff}, noon)
+	var hills clip.Path
+	hills.Begin(gtx.Ops)
+	hills.MoveTo(f32.Pt(0, h))
+	hills.LineTo(f32.Pt(0, h*0.8))
+	hills.QuadTo(f32.Pt(w*0.2, h*0.6), f32.Pt(w*0.4, h*0.85))
+	hills.QuadTo(f32.Pt(w*0.7, h*0.7), f32.Pt(w, h*0.78))
+	hills.LineTo(f32.Pt(w, h))
+	hills.Close()
+	paint.FillShape(gtx.Ops, hill, clip.Outline{Path: hills.End()}.Op())
+}
+
+// mix blends from a to b by f in [0, 1].
+func mix(a, b color.NRGBA, f float32) color.NRGBA {
+	l := func(x, y uint8) uint8 {
+		return uint8(float32(x) + (float32(y)-float32(x))*f)
+	}
+	return color.NRGBA{R: l(a.R, b.R), G: l(a.G, b.G), B: l(a.B, b.B), A: l(a.A, b.A)}
+}
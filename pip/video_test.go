@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlayback(t *testing.T) {
+	t0 := time.Now()
+	at := func(d time.Duration) time.Time { return t0.Add(d) }
+	p := Playback{Length: 10 * time.Second}
+	if p.Playing(t0) || p.Position(at(time.Second)) != 0 {
+		t.Fatal("a new clip plays")
+	}
+	p.Play(t0)
+	if got := p.Position(at(3 * time.Second)); got != 3*time.Second {
+		t.Errorf("position after 3s: got %v", got)
+	}
+	p.Pause(at(3 * time.Second))
+	if got := p.Position(at(5 * time.Second)); got != 3*time.Second {
+		t.Errorf("position while paused: got %v", got)
+	}
+	p.Seek(8*time.Second, at(5*time.Second))
+	if got := p.Position(at(6 * time.Second)); got != 8*time.Second {
+		t.Errorf("position after seek: got %v", got)
+	}
+	p.Toggle(at(6 * time.Second))
+	if got := p.Position(at(20 * time.Second)); got != p.Length {
+		t.Errorf("position past the end: got %v", got)
+	}
+	if p.Playing(at(20 * time.Second)) {
+		t.Error("the clip plays past its end")
+	}
+	p.Play(at(20 * time.Second))
+	if got := p.Position(at(21 * time.Second)); got != time.Second {
+		t.Errorf("position after starting over: got %v", got)
+	}
+	p.Seek(-time.Second, at(21*time.Second))
+	if got := p.Position(at(21 * time.Second)); got != 0 {
+		t.Errorf("seek before the start: got %v", got)
+	}
+}
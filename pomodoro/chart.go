@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"gioui.org/example/internal/style"
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+// ChartStyle draws the focus sessions of days as bars, labelled with
+// their counts above and their weekdays below. The last day is drawn
+// in full color, the others faded.
+type ChartStyle struct {
+	Days  []Day
+	Color color.NRGBA
+	Theme *material.Theme
+}
+
+func weekChart(th *material.Theme, days []Day, col color.NRGBA) ChartStyle {
+	return ChartStyle{Days: days, Color: col, Theme: th}
+}
+
+// chartMax returns the count of the top of the chart: the largest count,
+// but at least 4 so that a single session doesn't fill the chart.
+func chartMax(days []Day) int {
+	max := 4
+	for _, d := range days {
+		if d.Sessions > max {
+			max = d.Sessions
+		}
+	}
+	return max
+}
+
+func (c ChartStyle) Layout(gtx layout.Context) layout.Dimensions {
+	size := gtx.Constraints.Max
+	n := len(c.Days)
+	if n == 0 {
+		return layout.Dimensions{Size: size}
+	}
+	th := c.Theme
+	labelH := gtx.Px(unit.Dp(18))
+	top, bottom := float32(labelH), float32(size.Y-labelH)
+	slot := float32(size.X) / float32(n)
+	max := float32(chartMax(c.Days))
+	label := func(txt string, x, y float32, col color.NRGBA) {
+		st := op.Save(gtx.Ops)
+		op.Offset(f32.Pt(x, y)).Add(gtx.Ops)
+		gtx := gtx
+		gtx.Constraints = layout.Exact(image.Pt(int(slot), labelH))
+		l := material.Caption(th, txt)
+		l.Color = col
+		l.Alignment = text.Middle
+		l.MaxLines = 1
+		l.Layout(gtx)
+		st.Load()
+	}
+	rr := float32(gtx.Px(unit.Dp(3)))
+	for i, d := range c.Days {
+		x := float32(i) * slot
+		col := style.MulAlpha(c.Color, 0x80)
+		if i == n-1 {
+			col = c.Color
+		}
+		h := float32(d.Sessions) / max * (bottom - top)
+		if d.Sessions > 0 {
+			r := f32.Rect(x+slot*0.2, bottom-h, x+slot*0.8, bottom)
+			paint.FillShape(gtx.Ops, col, clip.RRect{Rect: r, NE: rr, NW: rr}.Op(gtx.Ops))
+			label(fmt.Sprint(d.Sessions), x, bottom-h-float32(labelH), th.Palette.Fg)
+		}
+		label(d.Date.Format("Mon"), x, bottom, style.MulAlpha(th.Palette.Fg, 0xa0))
+	}
+	// The baseline.
+	base := f32.Rect(0, bottom-1, float32(size.X), bottom)
+	paint.FillShape(gtx.Ops, style.MulAlpha(th.Palette.Fg, 0x40), clip.RRect{Rect: base}.Op(gtx.Ops))
+	return layout.Dimensions{Size: size}
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/example/internal/style"
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+// DialStyle draws the progress of a phase as a ring filling clockwise
+// from the top, around the time left and the name of the phase.
+type DialStyle struct {
+	Progress float32
+	Color    color.NRGBA
+	Track    color.NRGBA
+	// Width is the width of the ring, relative to the diameter.
+	Width   float32
+	Time    string
+	Caption string
+	Theme   *material.Theme
+}
+
+func progressDial(th *material.Theme, progress float32, col color.NRGBA, time, caption string) DialStyle {
+	return DialStyle{
+		Progress: progress,
+		Color:    col,
+		Track:    style.MulAlpha(th.Palette.Fg, 0x20),
+		Width:    0.05,
+		Time:     time,
+		Caption:  caption,
+		Theme:    th,
+	}
+}
+
+// turnPoint returns the point at radius r and a fraction of a turn
+// clockwise from the top.
+func turnPoint(c f32.Point, r, turn float32) f32.Point {
+	s, co := math.Sincos(2*math.Pi*float64(turn) - math.Pi/2)
+	return c.Add(f32.Pt(r*float32(co), r*float32(s)))
+}
+
+// arc returns the stroke of the arc of a circle from the top to a
+// fraction of a turn.
+func arc(ops *op.Ops, c f32.Point, r, turn, width float32) clip.Op {
+	// Approximate with one segment per 3 degrees.
+	n := int(math.Ceil(float64(turn) * 120))
+	var p clip.Path
+	p.Begin(ops)
+	p.MoveTo(turnPoint(c, r, 0))
+	for i := 1; i <= n; i++ {
+		p.LineTo(turnPoint(c, r, turn*float32(i)/float32(n)))
+	}
+	return clip.Stroke{Path: p.End(), Style: clip.StrokeStyle{Width: width}}.Op()
+}
+
+// Layout draws the dial as large as the constraints allow.
+func (d DialStyle) Layout(gtx layout.Context) layout.Dimensions {
+	size := gtx.Constraints.Max.X
+	if gtx.Constraints.Max.Y < size {
+		size = gtx.Constraints.Max.Y
+	}
+	sz := float32(size)
+	c := f32.Pt(sz/2, sz/2)
+	w := sz * d.Width
+	r := sz/2 - w
+	paint.FillShape(gtx.Ops, d.Track, clip.Stroke{
+		Path:  clip.Circle{Center: c, Radius: r}.Path(gtx.Ops),
+		Style: clip.StrokeStyle{Width: w},
+	}.Op())
+	if p := d.Progress; p > 0 {
+		if p > 1 {
+			p = 1
+		}
+		paint.FillShape(gtx.Ops, d.Color, arc(gtx.Ops, c, r, p, w))
+		// Round the ends of the ring.
+		paint.FillShape(gtx.Ops, d.Color, clip.Circle{Center: turnPoint(c, r, 0), Radius: w / 2}.Op(gtx.Ops))
+		paint.FillShape(gtx.Ops, d.Color, clip.Circle{Center: turnPoint(c, r, p), Radius: w}.Op(gtx.Ops))
+	}
+
+	gtx.Constraints = layout.Exact(image.Pt(size, size))
+	layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				l := material.H2(d.Theme, d.Time)
+				l.Alignment = text.Middle
+				return l.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				l := material.Body1(d.Theme, d.Caption)
+				l.Color = d.Color
+				l.TextSize = unit.Sp(18)
+				return l.Layout(gtx)
+			}),
+		)
+	})
+	return layout.Dimensions{Size: image.Pt(size, size)}
+}
@@ -0,0 +1,438 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program is a pomodoro timer: focus sessions separated by short
+// breaks, with a long break after every few sessions. The end of each
+// phase is announced with a system notification and a chime, and a tray
+// icon shows the progress while the window is hidden; clicking the icon
+// starts or pauses the timer, and middle clicking it skips the phase.
+//
+// Completed focus sessions are saved, and the sessions of the last seven
+// days are charted.
+//
+// Usage:
+//
+//	go run ./pomodoro [-focus 25m] [-short 5m] [-long 15m] [-every 4] [-auto]
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/chime"
+	"gioui.org/example/internal/style"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/notify"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	focusFlag = flag.Duration("focus", 25*time.Minute, "length of focus sessions")
+	shortFlag = flag.Duration("short", 5*time.Minute, "length of short breaks")
+	longFlag  = flag.Duration("long", 15*time.Minute, "length of long breaks")
+	everyFlag = flag.Int("every", 4, "number of focus sessions before a long break")
+	autoFlag  = flag.Bool("auto", false, "start the next phase automatically")
+	statsFlag = flag.String("stats", "", "statistics `file` (default stats.json in the user config directory)")
+)
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Pomodoro"),
+			app.Size(unit.Dp(420), unit.Dp(720)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var (
+	phaseColors = map[Phase]color.NRGBA{
+		Focus:      {R: 0xe5, G: 0x39, B: 0x35, A: 0xff},
+		ShortBreak: {R: 0x43, G: 0xa0, B: 0x47, A: 0xff},
+		LongBreak:  {R: 0x1e, G: 0x88, B: 0xe5, A: 0xff},
+	}
+	errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+)
+
+// The notes of the chimes of the end of focus sessions, rising, and of
+// breaks, falling.
+var (
+	focusNotes = []float64{523, 659, 784, 1047}
+	breakNotes = []float64{784, 659, 523}
+)
+
+// alert is an announcement of the end of a phase.
+type alert struct {
+	title, body string
+	notes       []float64
+}
+
+type App struct {
+	cycle Cycle
+	timer *Timer
+	stats *Stats
+	// alarm fires when the running phase ends.
+	alarm  *time.Timer
+	alerts chan<- alert
+
+	tray    Tray
+	trayErr error
+
+	run, reset, skip widget.Clickable
+
+	// err is the last error of saving the statistics.
+	err error
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	path := *statsFlag
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(dir, "gio-pomodoro", "stats.json")
+	}
+	stats, err := LoadStats(path)
+	if err != nil {
+		return err
+	}
+	alerts := make(chan alert, 1)
+	go alerter(alerts)
+	cycle := Cycle{Focus: *focusFlag, Short: *shortFlag, Long: *longFlag, LongEvery: *everyFlag}
+	a := &App{
+		cycle:  cycle,
+		timer:  NewTimer(cycle),
+		stats:  stats,
+		alerts: alerts,
+	}
+	a.tray, a.trayErr = newTray()
+	if a.tray != nil {
+		defer a.tray.Close()
+	}
+	// The tray is updated every second, whether the window is visible
+	// or not.
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	a.updateTray(time.Now())
+	var ops op.Ops
+	for {
+		select {
+		case <-a.alarmC():
+			a.finish(time.Now())
+			w.Invalidate()
+		case cmd := <-a.trayC():
+			a.command(cmd, time.Now())
+			w.Invalidate()
+		case now := <-ticker.C:
+			a.updateTray(now)
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+// alerter announces the alerts received with a system notification and
+// a chime.
+func alerter(alerts <-chan alert) {
+	mgr, err := notify.NewManager()
+	if err != nil {
+		log.Printf("notifications unavailable: %v", err)
+	}
+	for al := range alerts {
+		if mgr != nil {
+			if _, err := mgr.CreateNotification(al.title, al.body); err != nil {
+				log.Printf("notification failed: %v", err)
+			}
+		}
+		if err := chime.Play(chime.Tones(al.notes...)); err != nil {
+			log.Printf("chime failed: %v", err)
+		}
+	}
+}
+
+// alarmC returns the channel of the alarm, or nil when the timer isn't
+// running.
+func (a *App) alarmC() <-chan time.Time {
+	if a.alarm == nil {
+		return nil
+	}
+	return a.alarm.C
+}
+
+// trayC returns the channel of the commands of the tray, or nil without
+// a tray.
+func (a *App) trayC() <-chan Command {
+	if a.tray == nil {
+		return nil
+	}
+	return a.tray.Commands()
+}
+
+func (a *App) stopAlarm() {
+	if a.alarm != nil {
+		a.alarm.Stop()
+		a.alarm = nil
+	}
+}
+
+func (a *App) start(now time.Time) {
+	a.stopAlarm()
+	a.alarm = time.NewTimer(a.timer.Start(now))
+	a.updateTray(now)
+}
+
+func (a *App) toggle(now time.Time) {
+	if a.timer.Running() {
+		a.timer.Pause(now)
+		a.stopAlarm()
+		a.updateTray(now)
+	} else {
+		a.start(now)
+	}
+}
+
+func (a *App) command(cmd Command, now time.Time) {
+	switch cmd {
+	case CmdToggle:
+		a.toggle(now)
+	case CmdSkip:
+		a.stopAlarm()
+		a.timer.Advance(false)
+		a.updateTray(now)
+	}
+}
+
+// finish ends the running phase, records it if it was a focus session,
+// and announces the next phase.
+func (a *App) finish(now time.Time) {
+	a.alarm = nil
+	ended := a.timer.Advance(true)
+	next := a.timer.Phase()
+	al := alert{title: next.String(), notes: breakNotes}
+	switch ended {
+	case Focus:
+		a.err = a.stats.Record(now, a.cycle.Focus)
+		al.notes = focusNotes
+		al.body = fmt.Sprintf("Focus session done. Take %s.", formatLength(a.cycle.duration(next)))
+	default:
+		al.body = "Break over. Back to focus."
+	}
+	select {
+	case a.alerts <- al:
+	default:
+	}
+	if *autoFlag {
+		a.start(now)
+	} else {
+		a.updateTray(now)
+	}
+}
+
+func (a *App) updateTray(now time.Time) {
+	if a.tray == nil {
+		return
+	}
+	t := a.timer
+	tip := fmt.Sprintf("%s: %s left", t.Phase(), formatCountdown(t.Remaining(now)))
+	if !t.Running() {
+		tip += " (paused)"
+	}
+	a.tray.Update(TrayState{
+		Phase:    t.Phase(),
+		Running:  t.Running(),
+		Progress: t.Progress(now),
+		Tooltip:  tip,
+	})
+}
+
+func (a *App) update(gtx C) {
+	for a.run.Clicked() {
+		a.toggle(gtx.Now)
+	}
+	for a.reset.Clicked() {
+		a.stopAlarm()
+		a.timer.Reset()
+		a.updateTray(gtx.Now)
+	}
+	for a.skip.Clicked() {
+		a.command(CmdSkip, gtx.Now)
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	a.update(gtx)
+	t := a.timer
+	col := phaseColors[t.Phase()]
+	left := t.Remaining(gtx.Now)
+	run := "Start"
+	if t.Running() {
+		run = "Pause"
+		// The countdown ticks every second.
+		op.InvalidateOp{At: gtx.Now.Add(left % time.Second)}.Add(gtx.Ops)
+	} else if left < a.cycle.duration(t.Phase()) {
+		run = "Resume"
+	}
+	return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return a.layoutSessions(gtx, th, col)
+			}),
+			layout.Flexed(1, func(gtx C) D {
+				return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+					return layout.Center.Layout(gtx, progressDial(th, t.Progress(gtx.Now), col, formatCountdown(left), t.Phase().String()).Layout)
+				})
+			}),
+			layout.Rigid(func(gtx C) D {
+				b := material.Button(th, &a.run, run)
+				b.Background = col
+				return layoutButtons(gtx,
+					b.Layout,
+					material.Button(th, &a.reset, "Reset").Layout,
+					material.Button(th, &a.skip, "Skip").Layout,
+				)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(24)}.Layout),
+			layout.Rigid(func(gtx C) D {
+				return a.layoutStats(gtx, th, gtx.Now)
+			}),
+			layout.Rigid(func(gtx C) D {
+				l := material.Caption(th, "")
+				l.Color = style.MulAlpha(l.Color, 0xa0)
+				switch {
+				case a.err != nil:
+					l.Text, l.Color = fmt.Sprintf("Saving statistics: %v", a.err), errorColor
+				case a.trayErr != nil:
+					l.Text = fmt.Sprintf("No tray icon: %v", a.trayErr)
+				default:
+					l.Text = "Click the tray icon to start or pause, middle click to skip."
+				}
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, l.Layout)
+			}),
+		)
+	})
+}
+
+// layoutSessions lays out a dot for each focus session until the long
+// break, filled for the completed ones.
+func (a *App) layoutSessions(gtx C, th *material.Theme, col color.NRGBA) D {
+	done, every := a.timer.Done()
+	d := gtx.Px(unit.Dp(12))
+	gap := gtx.Px(unit.Dp(8))
+	size := image.Pt(every*d+(every-1)*gap, d)
+	r := float32(d) / 2
+	for i := 0; i < every; i++ {
+		c := f32.Pt(float32(i*(d+gap))+r, r)
+		if i < done {
+			paint.FillShape(gtx.Ops, col, clip.Circle{Center: c, Radius: r}.Op(gtx.Ops))
+			continue
+		}
+		paint.FillShape(gtx.Ops, style.MulAlpha(th.Palette.Fg, 0x40), clip.Stroke{
+			Path:  clip.Circle{Center: c, Radius: r - 1}.Path(gtx.Ops),
+			Style: clip.StrokeStyle{Width: 2},
+		}.Op())
+	}
+	return D{Size: size}
+}
+
+func (a *App) layoutStats(gtx C, th *material.Theme, now time.Time) D {
+	today := a.stats.Day(now)
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			l := material.Body1(th, "This week")
+			l.Font.Weight = text.Bold
+			return l.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx C) D {
+			txt := "No focus sessions today yet."
+			if today.Sessions > 0 {
+				txt = fmt.Sprintf("Today: %s, %s of focus.", plural(today.Sessions, "session"), formatLength(today.Focus))
+			}
+			l := material.Caption(th, txt)
+			l.Color = style.MulAlpha(l.Color, 0xa0)
+			return l.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			gtx.Constraints.Max.Y = gtx.Px(unit.Dp(140))
+			return weekChart(th, a.stats.Week(now), phaseColors[Focus]).Layout(gtx)
+		}),
+	)
+}
+
+// layoutButtons lays out a row of buttons.
+func layoutButtons(gtx C, buttons ...layout.Widget) D {
+	var children []layout.FlexChild
+	for i, b := range buttons {
+		if i > 0 {
+			children = append(children, layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout))
+		}
+		children = append(children, layout.Rigid(b))
+	}
+	return layout.Flex{}.Layout(gtx, children...)
+}
+
+// formatCountdown formats the time left of a phase in whole seconds,
+// rounded up so that it shows zero only at the end.
+func formatCountdown(d time.Duration) string {
+	s := int((d + time.Second - 1) / time.Second)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%02d:%02d", s/60, s%60)
+}
+
+// formatLength formats a length of time in hours and minutes, such as
+// "1 h 15 min".
+func formatLength(d time.Duration) string {
+	m := int(d.Round(time.Minute) / time.Minute)
+	switch {
+	case m < 60:
+		return fmt.Sprintf("%d min", m)
+	case m%60 == 0:
+		return fmt.Sprintf("%d h", m/60)
+	default:
+		return fmt.Sprintf("%d h %d min", m/60, m%60)
+	}
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
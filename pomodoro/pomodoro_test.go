@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTimer(t *testing.T) {
+	t0 := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return t0.Add(d) }
+	tm := NewTimer(Cycle{Focus: 25 * time.Minute, Short: 5 * time.Minute, Long: 15 * time.Minute, LongEvery: 2})
+	if left := tm.Start(at(0)); left != 25*time.Minute {
+		t.Fatalf("started with %v left, want 25m", left)
+	}
+	tm.Pause(at(10 * time.Minute))
+	if left := tm.Remaining(at(time.Hour)); left != 15*time.Minute {
+		t.Errorf("paused with %v left, want 15m", left)
+	}
+	tm.Start(at(20 * time.Minute))
+	if p := tm.Progress(at(25 * time.Minute)); p != 0.6 {
+		t.Errorf("progress %v, want 0.6", p)
+	}
+	if left := tm.Remaining(at(time.Hour)); left != 0 {
+		t.Errorf("%v left after the end, want 0", left)
+	}
+
+	// Focus, break, skipped focus, break, focus and the long break after
+	// the second completed session.
+	steps := []struct {
+		completed bool
+		next      Phase
+		done      int
+	}{
+		{true, ShortBreak, 1},
+		{true, Focus, 1},
+		{false, ShortBreak, 1},
+		{false, Focus, 1},
+		{true, LongBreak, 2},
+		{true, Focus, 0},
+	}
+	for i, s := range steps {
+		tm.Advance(s.completed)
+		done, every := tm.Done()
+		if tm.Phase() != s.next || done != s.done || every != 2 {
+			t.Fatalf("step %d: %v with %d done, want %v with %d", i, tm.Phase(), done, s.next, s.done)
+		}
+		if tm.Running() {
+			t.Errorf("step %d: running after advancing", i)
+		}
+	}
+	tm.Advance(true)
+	if left := tm.Remaining(at(0)); left != 5*time.Minute {
+		t.Errorf("short break of %v, want 5m", left)
+	}
+	tm.Start(at(0))
+	tm.Reset()
+	if tm.Running() || tm.Remaining(at(time.Minute)) != 5*time.Minute {
+		t.Error("reset didn't restart the phase")
+	}
+}
+
+func TestStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pomodoro", "stats.json")
+	s, err := LoadStats(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mon := time.Date(2021, 5, 17, 9, 30, 0, 0, time.Local)
+	for _, end := range []time.Time{mon, mon.Add(time.Hour), mon.AddDate(0, 0, 2)} {
+		if err := s.Record(end, 25*time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s, err = LoadStats(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := s.Day(mon); d.Sessions != 2 || d.Focus != 50*time.Minute {
+		t.Errorf("Monday %+v, want 2 sessions of 50m", d)
+	}
+	week := s.Week(mon.AddDate(0, 0, 3))
+	var sessions []int
+	for _, d := range week {
+		sessions = append(sessions, d.Sessions)
+	}
+	want := []int{0, 0, 0, 2, 0, 1, 0}
+	for i := range want {
+		if sessions[i] != want[i] {
+			t.Fatalf("week %v, want %v", sessions, want)
+		}
+	}
+	if wd := week[6].Date.Weekday(); wd != time.Thursday {
+		t.Errorf("last day of the week is a %v, want Thursday", wd)
+	}
+	if n := chartMax(week); n != 4 {
+		t.Errorf("chart max %d, want 4", n)
+	}
+}
+
+func TestTrayIcon(t *testing.T) {
+	const size = 32
+	img := trayIcon(TrayState{Phase: Focus, Progress: 0.3}, size)
+	col := phaseColors[Focus]
+	// The ring passes through the right half of the icon a quarter turn
+	// in, and through the left half three quarters in.
+	if p := img.NRGBAAt(size-3, size/2); p != col {
+		t.Errorf("ring at a quarter turn %v, want %v", p, col)
+	}
+	if p := img.NRGBAAt(2, size/2); p.A != 0x50 {
+		t.Errorf("ring at three quarters %v, want the track", p)
+	}
+	if p := img.NRGBAAt(size/2, size/2); p.A != 0 {
+		t.Errorf("center %v, want transparent while paused", p)
+	}
+	img = trayIcon(TrayState{Phase: Focus, Running: true}, size)
+	if p := img.NRGBAAt(size/2, size/2); p != col {
+		t.Errorf("center %v, want a dot while running", p)
+	}
+}
+
+func TestFormatLength(t *testing.T) {
+	tests := map[time.Duration]string{
+		5 * time.Minute:  "5 min",
+		time.Hour:        "1 h",
+		75 * time.Minute: "1 h 15 min",
+		89 * time.Second: "1 min",
+	}
+	for d, want := range tests {
+		if got := formatLength(d); got != want {
+			t.Errorf("formatLength(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
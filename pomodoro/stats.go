@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// dateLayout is the layout of the dates of statistics.
+const dateLayout = "2006-01-02"
+
+// Stats are the focus sessions completed each day, saved to a JSON file
+// after every change.
+type Stats struct {
+	path string
+	days map[string]Day
+}
+
+// Day is the statistics of a day.
+type Day struct {
+	Date     time.Time     `json:"-"`
+	Sessions int           `json:"sessions"`
+	Focus    time.Duration `json:"focus"`
+}
+
+// LoadStats loads the statistics of a file. A missing file has none.
+func LoadStats(path string) (*Stats, error) {
+	s := &Stats{path: path, days: make(map[string]Day)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.days); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Record records a focus session of length d that ended at end, and
+// saves the statistics.
+func (s *Stats) Record(end time.Time, d time.Duration) error {
+	key := end.Format(dateLayout)
+	day := s.days[key]
+	day.Sessions++
+	day.Focus += d
+	s.days[key] = day
+	return s.save()
+}
+
+func (s *Stats) save() error {
+	data, err := json.MarshalIndent(s.days, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Day returns the statistics of the day of t.
+func (s *Stats) Day(t time.Time) Day {
+	day := s.days[t.Format(dateLayout)]
+	y, m, d := t.Date()
+	day.Date = time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	return day
+}
+
+// Week returns the statistics of the seven days up to the day of t.
+func (s *Stats) Week(t time.Time) []Day {
+	week := make([]Day, 7)
+	for i := range week {
+		week[i] = s.Day(t.AddDate(0, 0, i-6))
+	}
+	return week
+}
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import "time"
+
+// Phase is a part of a pomodoro cycle.
+type Phase int
+
+const (
+	Focus Phase = iota
+	ShortBreak
+	LongBreak
+)
+
+func (p Phase) String() string {
+	switch p {
+	case ShortBreak:
+		return "Short break"
+	case LongBreak:
+		return "Long break"
+	default:
+		return "Focus"
+	}
+}
+
+// Cycle is the schedule of a pomodoro timer: focus sessions separated by
+// short breaks, with a long break after every few sessions.
+type Cycle struct {
+	Focus, Short, Long time.Duration
+	// LongEvery is the number of focus sessions before a long break.
+	LongEvery int
+}
+
+func (c Cycle) duration(p Phase) time.Duration {
+	switch p {
+	case ShortBreak:
+		return c.Short
+	case LongBreak:
+		return c.Long
+	default:
+		return c.Focus
+	}
+}
+
+// Timer counts down the phases of a cycle, one at a time. Like the
+// clock example, its methods take the current time, to keep them simple
+// to test.
+type Timer struct {
+	cycle Cycle
+	phase Phase
+	// done is the number of focus sessions completed since the last
+	// long break.
+	done    int
+	running bool
+	// end is the time a running phase ends, and remaining the time left
+	// of a paused one.
+	end       time.Time
+	remaining time.Duration
+}
+
+func NewTimer(c Cycle) *Timer {
+	if c.LongEvery < 1 {
+		c.LongEvery = 1
+	}
+	return &Timer{cycle: c, remaining: c.Focus}
+}
+
+func (t *Timer) Phase() Phase {
+	return t.phase
+}
+
+func (t *Timer) Running() bool {
+	return t.running
+}
+
+// Done returns the number of focus sessions completed since the last
+// long break, and the number between long breaks.
+func (t *Timer) Done() (done, every int) {
+	return t.done, t.cycle.LongEvery
+}
+
+// Start starts or resumes the phase, and returns the time left.
+func (t *Timer) Start(now time.Time) time.Duration {
+	if !t.running {
+		t.running = true
+		t.end = now.Add(t.remaining)
+	}
+	return t.Remaining(now)
+}
+
+func (t *Timer) Pause(now time.Time) {
+	if !t.running {
+		return
+	}
+	t.remaining = t.Remaining(now)
+	t.running = false
+}
+
+// Reset restarts the current phase, stopped.
+func (t *Timer) Reset() {
+	t.running = false
+	t.remaining = t.cycle.duration(t.phase)
+}
+
+func (t *Timer) Remaining(now time.Time) time.Duration {
+	if !t.running {
+		return t.remaining
+	}
+	if left := t.end.Sub(now); left > 0 {
+		return left
+	}
+	return 0
+}
+
+// Progress returns the fraction of the phase elapsed.
+func (t *Timer) Progress(now time.Time) float32 {
+	d := t.cycle.duration(t.phase)
+	if d <= 0 {
+		return 1
+	}
+	return 1 - float32(t.Remaining(now))/float32(d)
+}
+
+// Advance ends the phase, completed or skipped, and stops the timer at
+// the start of the next phase. It returns the phase that ended. Only
+// completed focus sessions count towards the long break.
+func (t *Timer) Advance(completed bool) Phase {
+	ended := t.phase
+	switch ended {
+	case Focus:
+		if completed {
+			t.done++
+		}
+		t.phase = ShortBreak
+		if t.done >= t.cycle.LongEvery {
+			t.phase = LongBreak
+		}
+	case LongBreak:
+		t.done = 0
+		t.phase = Focus
+	default:
+		t.phase = Focus
+	}
+	t.Reset()
+	return ended
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+)
+
+// errUnsupported is returned by newTray on platforms without tray
+// support.
+var errUnsupported = errors.New("tray icons are not supported on this platform")
+
+// Command is a command from the tray icon.
+type Command int
+
+const (
+	// CmdToggle starts or pauses the timer, and CmdSkip skips the
+	// phase.
+	CmdToggle Command = iota
+	CmdSkip
+)
+
+// TrayState is the state shown by the tray icon.
+type TrayState struct {
+	Phase    Phase
+	Running  bool
+	Progress float32
+	// Tooltip describes the state, such as the time left.
+	Tooltip string
+}
+
+// Tray is an icon in the notification area of the desktop, showing the
+// progress of the timer. Clicking it starts or pauses the timer, and
+// middle clicking skips the phase. newTray returns the tray of the
+// platform: a StatusNotifierItem on Linux and BSD.
+type Tray interface {
+	// Update shows the state. Trays skip unchanged state, so it is cheap
+	// to call often.
+	Update(s TrayState)
+	// Commands returns the channel of commands.
+	Commands() <-chan Command
+	Close() error
+}
+
+// trayIcon draws the icon of a state: a ring of the progress of the
+// phase in its color, around a dot while the timer runs.
+func trayIcon(s TrayState, size int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	col := phaseColors[s.Phase]
+	track := col
+	track.A = 0x50
+	c := float64(size) / 2
+	r1 := c - 0.5
+	r0 := r1 * 0.62
+	dot := r1 * 0.3
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)+0.5-c, float64(y)+0.5-c
+			d := math.Hypot(dx, dy)
+			var p color.NRGBA
+			switch {
+			case d <= r1+0.5 && d >= r0-0.5:
+				// The fraction of a turn clockwise from the top.
+				turn := math.Atan2(dx, -dy) / (2 * math.Pi)
+				if turn < 0 {
+					turn++
+				}
+				p = track
+				if turn <= float64(s.Progress) {
+					p = col
+				}
+				// Smooth the edges of the ring.
+				p.A = uint8(float64(p.A) * coverage(d-r1) * coverage(r0-d))
+			case s.Running && d <= dot+0.5:
+				p = col
+				p.A = uint8(float64(p.A) * coverage(d-dot))
+			}
+			img.SetNRGBA(x, y, p)
+		}
+	}
+	return img
+}
+
+// coverage returns the coverage of a pixel whose center is at distance
+// d outside an edge.
+func coverage(d float64) float64 {
+	return math.Max(0, math.Min(1, 0.5-d))
+}
+
+// sendCommand sends a command without blocking the system, dropping it
+// if the UI is behind.
+func sendCommand(c chan<- Command, cmd Command) {
+	select {
+	case c <- cmd:
+	default:
+	}
+}
@@ -0,0 +1,12 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build !((linux && !android) || freebsd || openbsd)
+// +build !linux android
+// +build !freebsd
+// +build !openbsd
+
+package main
+
+func newTray() (Tray, error) {
+	return nil, errUnsupported
+}
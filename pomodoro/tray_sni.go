@@ -0,0 +1,191 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build (linux && !android) || freebsd || openbsd
+// +build linux,!android freebsd openbsd
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+// sni implements the StatusNotifierItem D-Bus interface, which the
+// panels of KDE, GNOME with the AppIndicator extension, and most other
+// desktops show as tray icons. The item registers with the
+// StatusNotifierWatcher of the desktop; without one, there is no tray.
+type sni struct {
+	conn  *dbus.Conn
+	name  string
+	props *prop.Properties
+	cmds  chan Command
+
+	mu   sync.Mutex
+	last TrayState
+	// icon is the state last drawn as icon.
+	icon TrayState
+}
+
+const (
+	sniPath     = "/StatusNotifierItem"
+	sniItem     = "org.kde.StatusNotifierItem"
+	sniWatcher  = "org.kde.StatusNotifierWatcher"
+	watcherPath = "/StatusNotifierWatcher"
+	sniTitle    = "Gio Pomodoro"
+	iconSize    = 32
+	// iconSteps is the number of steps of the ring of the icon.
+	iconSteps = 60
+)
+
+// pixmap is an icon in the ARGB32 format of StatusNotifierItem, in
+// network byte order.
+type pixmap struct {
+	Width, Height int32
+	Data          []byte
+}
+
+// tooltip is the (sa(iiay)ss) tooltip of StatusNotifierItem.
+type tooltip struct {
+	Icon    string
+	Pixmaps []pixmap
+	Title   string
+	Text    string
+}
+
+func newTray() (Tray, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, err
+	}
+	t := &sni{
+		conn: conn,
+		name: fmt.Sprintf("org.kde.StatusNotifierItem-%d-1", os.Getpid()),
+		cmds: make(chan Command, 16),
+	}
+	if err := t.export(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	reply, err := conn.RequestName(t.name, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		conn.Close()
+		return nil, fmt.Errorf("tray: name %s taken", t.name)
+	}
+	watcher := conn.Object(sniWatcher, watcherPath)
+	if err := watcher.Call(sniWatcher+".RegisterStatusNotifierItem", 0, t.name).Err; err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("tray: no StatusNotifierWatcher: %v", err)
+	}
+	return t, nil
+}
+
+// sniMethods are the methods of the item. The coordinates of the
+// pointer are ignored.
+type sniMethods struct {
+	t *sni
+}
+
+func (m sniMethods) Activate(x, y int32) *dbus.Error {
+	sendCommand(m.t.cmds, CmdToggle)
+	return nil
+}
+
+func (m sniMethods) SecondaryActivate(x, y int32) *dbus.Error {
+	sendCommand(m.t.cmds, CmdSkip)
+	return nil
+}
+
+func (m sniMethods) ContextMenu(x, y int32) *dbus.Error {
+	return nil
+}
+
+func (m sniMethods) Scroll(delta int32, orientation string) *dbus.Error {
+	return nil
+}
+
+func (t *sni) export() error {
+	methods := sniMethods{t}
+	if err := t.conn.Export(methods, sniPath, sniItem); err != nil {
+		return err
+	}
+	props, err := prop.Export(t.conn, sniPath, prop.Map{
+		sniItem: {
+			"Category": {Value: "ApplicationStatus", Emit: prop.EmitConst},
+			"Id":       {Value: "gio-pomodoro", Emit: prop.EmitConst},
+			"Title":    {Value: sniTitle, Emit: prop.EmitConst},
+			"Status":   {Value: "Active", Emit: prop.EmitConst},
+			"IconName": {Value: "", Emit: prop.EmitConst},
+			// Hosts listen to the NewIcon and NewToolTip signals of the
+			// item, not to property changes.
+			"IconPixmap": {Value: iconPixmaps(TrayState{}), Emit: prop.EmitFalse},
+			"ToolTip":    {Value: tooltip{Title: sniTitle}, Emit: prop.EmitFalse},
+			"ItemIsMenu": {Value: false, Emit: prop.EmitConst},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	t.props = props
+	node := &introspect.Node{
+		Name: sniPath,
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			prop.IntrospectData,
+			{
+				Name:       sniItem,
+				Methods:    introspect.Methods(methods),
+				Properties: props.Introspection(sniItem),
+				Signals:    []introspect.Signal{{Name: "NewIcon"}, {Name: "NewToolTip"}},
+			},
+		},
+	}
+	return t.conn.Export(introspect.NewIntrospectable(node), sniPath, "org.freedesktop.DBus.Introspectable")
+}
+
+func (t *sni) Commands() <-chan Command {
+	return t.cmds
+}
+
+func (t *sni) Close() error {
+	return t.conn.Close()
+}
+
+func (t *sni) Update(s TrayState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if s == t.last {
+		return
+	}
+	t.last = s
+	// Redraw the icon only when the ring moves by a step.
+	if s.Phase != t.icon.Phase || s.Running != t.icon.Running || step(s.Progress) != step(t.icon.Progress) {
+		t.icon = s
+		t.props.SetMust(sniItem, "IconPixmap", iconPixmaps(s))
+		t.conn.Emit(sniPath, sniItem+".NewIcon")
+	}
+	t.props.SetMust(sniItem, "ToolTip", tooltip{Title: sniTitle, Text: s.Tooltip})
+	t.conn.Emit(sniPath, sniItem+".NewToolTip")
+}
+
+func step(progress float32) int {
+	return int(progress * iconSteps)
+}
+
+func iconPixmaps(s TrayState) []pixmap {
+	img := trayIcon(s, iconSize)
+	data := make([]byte, 0, 4*iconSize*iconSize)
+	for i := 0; i < len(img.Pix); i += 4 {
+		p := img.Pix[i : i+4]
+		data = append(data, p[3], p[0], p[1], p[2])
+	}
+	return []pixmap{{Width: iconSize, Height: iconSize, Data: data}}
+}
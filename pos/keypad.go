@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Keypad is a numeric keypad, as on a register: digits are entered from
+// the right, so that amounts are typed in cents without a decimal point.
+type Keypad struct {
+	digits string
+	keys   [len(keypadKeys)]widget.Clickable
+}
+
+// keypadKeys are the keys of the keypad, in rows of three.
+var keypadKeys = [...]string{
+	"7", "8", "9",
+	"4", "5", "6",
+	"1", "2", "3",
+	"00", "0", "C",
+}
+
+// maxDigits bounds the digits entered, to stay far from overflows.
+const maxDigits = 9
+
+// Type enters a key: digits, or C to clear the entry.
+func (k *Keypad) Type(key string) {
+	if key == "C" {
+		k.digits = ""
+		return
+	}
+	d := strings.TrimLeft(k.digits+key, "0")
+	if len(d) > maxDigits {
+		return
+	}
+	k.digits = d
+}
+
+func (k *Keypad) Clear() {
+	k.digits = ""
+}
+
+// Empty reports whether nothing is entered.
+func (k *Keypad) Empty() bool {
+	return k.digits == ""
+}
+
+// Int returns the entry as a number.
+func (k *Keypad) Int() int {
+	n, _ := strconv.Atoi(k.digits)
+	return n
+}
+
+// Money returns the entry as an amount in cents.
+func (k *Keypad) Money() Money {
+	return Money(k.Int())
+}
+
+func (k *Keypad) update() {
+	for i := range k.keys {
+		for k.keys[i].Clicked() {
+			k.Type(keypadKeys[i])
+		}
+	}
+}
+
+// Layout lays out the keys in a grid filling the width, with rows of
+// the given height.
+func (k *Keypad) Layout(gtx C, th *material.Theme, rowHeight unit.Value) D {
+	k.update()
+	var rows []layout.FlexChild
+	for r := 0; r < len(keypadKeys)/3; r++ {
+		r := r
+		rows = append(rows, layout.Rigid(func(gtx C) D {
+			gtx.Constraints.Min.Y = gtx.Px(rowHeight)
+			gtx.Constraints.Max.Y = gtx.Constraints.Min.Y
+			var cols []layout.FlexChild
+			for c := 0; c < 3; c++ {
+				i := r*3 + c
+				cols = append(cols, layout.Flexed(1, func(gtx C) D {
+					return layout.UniformInset(unit.Dp(3)).Layout(gtx, func(gtx C) D {
+						gtx.Constraints.Min = gtx.Constraints.Max
+						b := material.Button(th, &k.keys[i], keypadKeys[i])
+						b.TextSize = unit.Sp(24)
+						if keypadKeys[i] == "C" {
+							b.Background = errorColor
+						}
+						return b.Layout(gtx)
+					})
+				}))
+			}
+			return layout.Flex{}.Layout(gtx, cols...)
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
+}
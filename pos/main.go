@@ -0,0 +1,614 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates a point of sale for the touch screens of
+// registers: large touch targets, a numeric keypad and no need for a
+// physical keyboard. Tap products to ring them up, swipe an order line
+// left or right to remove it, and tap a line to select it for Qty. The
+// keypad enters quantities and amounts in cents, as on a register.
+// Paying shows the receipt, which can be printed.
+//
+// Usage:
+//
+//	go run ./pos [-shop name] [-tax percent] [-printer name]
+//
+// Receipts are printed with lp, or Notepad on Windows, on the -printer
+// or the default printer.
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"os"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/style"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/gesture"
+	"gioui.org/io/pointer"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"gioui.org/example/internal/flow"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	shopFlag    = flag.String("shop", "Gio Café", "name of the shop on receipts")
+	taxFlag     = flag.Float64("tax", 10, "sales tax in percent, included in the prices")
+	printerFlag = flag.String("printer", "", "printer of receipts (default printer if empty)")
+)
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Point of Sale"),
+			app.Size(unit.Dp(1024), unit.Dp(720)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var categories = []string{"All", "Coffee", "Drinks", "Bakery", "Lunch"}
+
+var catalog = []*Product{
+	{"Espresso", "Coffee", 250},
+	{"Americano", "Coffee", 290},
+	{"Cappuccino", "Coffee", 340},
+	{"Latte", "Coffee", 360},
+	{"Flat white", "Coffee", 350},
+	{"Tea", "Drinks", 220},
+	{"Hot chocolate", "Drinks", 330},
+	{"Orange juice", "Drinks", 320},
+	{"Water", "Drinks", 180},
+	{"Croissant", "Bakery", 280},
+	{"Muffin", "Bakery", 310},
+	{"Cookie", "Bakery", 150},
+	{"Cinnamon roll", "Bakery", 340},
+	{"Bagel", "Lunch", 450},
+	{"Sandwich", "Lunch", 690},
+	{"Soup of the day", "Lunch", 590},
+	{"Salad", "Lunch", 750},
+}
+
+var categoryColors = map[string]color.NRGBA{
+	"Coffee": {R: 0x6d, G: 0x4c, B: 0x41, A: 0xff},
+	"Drinks": {R: 0x00, G: 0x83, B: 0x8f, A: 0xff},
+	"Bakery": {R: 0xef, G: 0x6c, B: 0x00, A: 0xff},
+	"Lunch":  {R: 0x38, G: 0x8e, B: 0x3c, A: 0xff},
+}
+
+var (
+	errorColor   = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	selectedBg   = color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0x30}
+	panelBg      = color.NRGBA{R: 0xf3, G: 0xf3, B: 0xf3, A: 0xff}
+	monoFont     = text.Font{Variant: "Mono"}
+	scrimColor   = color.NRGBA{A: 0x80}
+	receiptPaper = color.NRGBA{R: 0xff, G: 0xff, B: 0xfa, A: 0xff}
+)
+
+const (
+	// swipeThreshold is how far an order line is swiped to remove it.
+	swipeThreshold = 120
+	// keyHeight is the height of the keypad keys and action buttons.
+	keyHeight = 64
+)
+
+// row is the swipe state of an order line.
+type row struct {
+	drag gesture.Drag
+	// start is where the press was, and offset how far the row has been
+	// dragged from there.
+	start, offset float32
+}
+
+type App struct {
+	order Order
+	// rows are the swipe states of the lines of the order.
+	rows []*row
+	// selected is the index of the selected line, or -1.
+	selected int
+	lineList layout.List
+
+	category    int
+	catClicks   []widget.Clickable
+	prodClicks  []widget.Clickable
+	productList layout.List
+
+	keypad Keypad
+	qty    widget.Clickable
+	cash   widget.Clickable
+	card   widget.Clickable
+	void   widget.Clickable
+	// message is an error or hint shown above the keypad.
+	message string
+
+	// receipt is the receipt of the last sale, shown until the next
+	// sale starts.
+	receipt     string
+	receiptList layout.List
+	print       widget.Clickable
+	newSale     widget.Clickable
+	printing    bool
+	printed     chan error
+	printStatus string
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		order:       Order{TaxRate: int(math.Round(*taxFlag * 100))},
+		selected:    -1,
+		lineList:    layout.List{Axis: layout.Vertical, ScrollToEnd: true},
+		catClicks:   make([]widget.Clickable, len(categories)),
+		prodClicks:  make([]widget.Clickable, len(catalog)),
+		productList: layout.List{Axis: layout.Vertical},
+		receiptList: layout.List{Axis: layout.Vertical},
+		printed:     make(chan error, 1),
+	}
+	var ops op.Ops
+	for {
+		select {
+		case err := <-a.printed:
+			a.printing = false
+			if err != nil {
+				a.printStatus = err.Error()
+			} else {
+				a.printStatus = "Printed."
+			}
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update(gtx)
+				a.layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+func (a *App) update(gtx C) {
+	for i := range a.catClicks {
+		for a.catClicks[i].Clicked() {
+			a.category = i
+			a.productList.Position = layout.Position{}
+		}
+	}
+	for i := range a.prodClicks {
+		for a.prodClicks[i].Clicked() {
+			a.add(catalog[i])
+		}
+	}
+	a.swipes(gtx)
+	for a.qty.Clicked() {
+		switch {
+		case a.selected < 0:
+			a.message = "Tap an order line to change its quantity."
+		case a.keypad.Empty():
+			a.message = "Enter the quantity first."
+		default:
+			a.setQty(a.selected, a.keypad.Int())
+			a.keypad.Clear()
+		}
+	}
+	for a.cash.Clicked() {
+		// Without an entry, the customer pays the exact amount.
+		tendered := a.order.Total()
+		if !a.keypad.Empty() {
+			tendered = a.keypad.Money()
+		}
+		a.pay(Payment{Method: "Cash", Tendered: tendered})
+	}
+	for a.card.Clicked() {
+		a.pay(Payment{Method: "Card", Tendered: a.order.Total()})
+	}
+	for a.void.Clicked() {
+		a.clearOrder()
+	}
+	for a.print.Clicked() {
+		if a.printing {
+			break
+		}
+		a.printing = true
+		a.printStatus = "Printing…"
+		receipt := a.receipt
+		go func() {
+			a.printed <- printReceipt(*printerFlag, receipt)
+		}()
+	}
+	for a.newSale.Clicked() {
+		a.receipt = ""
+		a.printStatus = ""
+		a.clearOrder()
+	}
+}
+
+func (a *App) add(p *Product) {
+	i := a.order.Add(p)
+	if i == len(a.rows) {
+		a.rows = append(a.rows, new(row))
+	}
+	a.selected = i
+	a.message = ""
+}
+
+func (a *App) setQty(i, qty int) {
+	a.order.SetQty(i, qty)
+	if qty <= 0 {
+		a.removeRow(i)
+	}
+}
+
+func (a *App) remove(i int) {
+	a.order.Remove(i)
+	a.removeRow(i)
+}
+
+func (a *App) removeRow(i int) {
+	a.rows = append(a.rows[:i:i], a.rows[i+1:]...)
+	switch {
+	case a.selected == i:
+		a.selected = -1
+	case a.selected > i:
+		a.selected--
+	}
+}
+
+func (a *App) clearOrder() {
+	a.order.Lines = nil
+	a.rows = nil
+	a.selected = -1
+	a.keypad.Clear()
+	a.message = ""
+}
+
+func (a *App) pay(p Payment) {
+	total := a.order.Total()
+	switch {
+	case len(a.order.Lines) == 0:
+		a.message = "The order is empty."
+		return
+	case p.Tendered < total:
+		a.message = fmt.Sprintf("%s is less than the total of %s.", p.Tendered, total)
+		return
+	}
+	a.receipt = Receipt(*shopFlag, &a.order, p, time.Now())
+	a.receiptList.Position = layout.Position{}
+	a.keypad.Clear()
+	a.message = ""
+}
+
+// swipes handles the drags of the order lines. Lines dragged past the
+// threshold are removed when released; lines released where they were
+// pressed are selected.
+func (a *App) swipes(gtx C) {
+	threshold := float32(gtx.Px(unit.Dp(swipeThreshold)))
+	slop := float32(gtx.Px(unit.Dp(4)))
+	for i := 0; i < len(a.rows); i++ {
+		r := a.rows[i]
+		for _, e := range r.drag.Events(gtx.Metric, gtx, gesture.Horizontal) {
+			switch e.Type {
+			case pointer.Press:
+				r.start, r.offset = e.Position.X, 0
+			case pointer.Drag:
+				r.offset = e.Position.X - r.start
+			case pointer.Release:
+				off := r.offset
+				r.offset = 0
+				switch {
+				case math.Abs(float64(off)) >= float64(threshold):
+					a.remove(i)
+					i--
+				case math.Abs(float64(off)) < float64(slop):
+					a.selected = i
+				}
+			case pointer.Cancel:
+				r.offset = 0
+			}
+		}
+	}
+}
+
+func (a *App) layout(gtx C, th *material.Theme) D {
+	dims := layout.Flex{}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return a.layoutCatalog(gtx, th)
+		}),
+		layout.Rigid(func(gtx C) D {
+			w := gtx.Px(unit.Dp(400))
+			if w > gtx.Constraints.Max.X/2 {
+				w = gtx.Constraints.Max.X / 2
+			}
+			gtx.Constraints = layout.Exact(image.Pt(w, gtx.Constraints.Max.Y))
+			paint.FillShape(gtx.Ops, panelBg, clip.Rect{Max: gtx.Constraints.Max}.Op())
+			return a.layoutOrder(gtx, th)
+		}),
+	)
+	if a.receipt != "" {
+		a.layoutReceipt(gtx, th)
+	}
+	return dims
+}
+
+func (a *App) layoutCatalog(gtx C, th *material.Theme) D {
+	return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return flow.Flow{Spacing: unit.Dp(8), LineSpacing: unit.Dp(8)}.Layout(gtx, len(categories), func(gtx C, i int) D {
+					b := material.Button(th, &a.catClicks[i], categories[i])
+					b.TextSize = unit.Sp(18)
+					b.Inset = layout.UniformInset(unit.Dp(16))
+					if i != a.category {
+						b.Background = style.MulAlpha(th.ContrastBg, 0x30)
+						b.Color = th.Fg
+					}
+					return b.Layout(gtx)
+				})
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+			layout.Flexed(1, func(gtx C) D {
+				var shown []int
+				for i, p := range catalog {
+					if a.category == 0 || p.Category == categories[a.category] {
+						shown = append(shown, i)
+					}
+				}
+				return a.productList.Layout(gtx, 1, func(gtx C, _ int) D {
+					return flow.Flow{Spacing: unit.Dp(8), LineSpacing: unit.Dp(8)}.Layout(gtx, len(shown), func(gtx C, j int) D {
+						return a.layoutProduct(gtx, th, shown[j])
+					})
+				})
+			}),
+		)
+	})
+}
+
+// layoutProduct lays out the tile of a product, large enough to hit
+// without looking.
+func (a *App) layoutProduct(gtx C, th *material.Theme, i int) D {
+	p := catalog[i]
+	b := material.ButtonLayout(th, &a.prodClicks[i])
+	b.Background = categoryColors[p.Category]
+	b.CornerRadius = unit.Dp(8)
+	return b.Layout(gtx, func(gtx C) D {
+		gtx.Constraints = layout.Exact(image.Pt(gtx.Px(unit.Dp(148)), gtx.Px(unit.Dp(104))))
+		return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+			white := color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+			return layout.Flex{Axis: layout.Vertical, Spacing: layout.SpaceBetween}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					l := material.Body1(th, p.Name)
+					l.Font.Weight = text.Bold
+					l.Color = white
+					return l.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx C) D {
+					l := material.Body1(th, p.Price.String())
+					l.Color = white
+					return l.Layout(gtx)
+				}),
+			)
+		})
+	})
+}
+
+func (a *App) layoutOrder(gtx C, th *material.Theme) D {
+	return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return layout.Flex{Alignment: layout.Baseline}.Layout(gtx,
+					layout.Flexed(1, material.H6(th, "Order").Layout),
+					layout.Rigid(func(gtx C) D {
+						b := material.Button(th, &a.void, "Void")
+						b.Background = errorColor
+						return b.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+			layout.Flexed(1, func(gtx C) D {
+				if len(a.order.Lines) == 0 {
+					return layout.Center.Layout(gtx, material.Body1(th, "Tap products to add them.").Layout)
+				}
+				return a.lineList.Layout(gtx, len(a.order.Lines), func(gtx C, i int) D {
+					return a.layoutLine(gtx, th, i)
+				})
+			}),
+			layout.Rigid(func(gtx C) D {
+				return layout.Inset{Top: unit.Dp(8), Bottom: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+					return layout.Flex{Alignment: layout.Baseline}.Layout(gtx,
+						layout.Flexed(1, material.H5(th, fmt.Sprintf("Total (%d)", a.order.Items())).Layout),
+						layout.Rigid(material.H5(th, a.order.Total().String()).Layout),
+					)
+				})
+			}),
+			layout.Rigid(func(gtx C) D {
+				return a.layoutEntry(gtx, th)
+			}),
+			layout.Rigid(func(gtx C) D {
+				return a.keypad.Layout(gtx, th, unit.Dp(keyHeight))
+			}),
+			layout.Rigid(func(gtx C) D {
+				gtx.Constraints.Min.Y = gtx.Px(unit.Dp(keyHeight))
+				gtx.Constraints.Max.Y = gtx.Constraints.Min.Y
+				action := func(click *widget.Clickable, label string) layout.FlexChild {
+					return layout.Flexed(1, func(gtx C) D {
+						return layout.UniformInset(unit.Dp(3)).Layout(gtx, func(gtx C) D {
+							gtx.Constraints.Min = gtx.Constraints.Max
+							b := material.Button(th, click, label)
+							b.TextSize = unit.Sp(20)
+							b.Background = categoryColors["Lunch"]
+							return b.Layout(gtx)
+						})
+					})
+				}
+				return layout.Flex{}.Layout(gtx,
+					action(&a.qty, "Qty"),
+					action(&a.cash, "Cash"),
+					action(&a.card, "Card"),
+				)
+			}),
+		)
+	})
+}
+
+// layoutEntry lays out the keypad entry, as a quantity and an amount,
+// or the message if there is one.
+func (a *App) layoutEntry(gtx C, th *material.Theme) D {
+	return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx C) D {
+		if a.message != "" {
+			l := material.Body1(th, a.message)
+			l.Color = errorColor
+			return l.Layout(gtx)
+		}
+		txt := "Enter a quantity or an amount"
+		if !a.keypad.Empty() {
+			txt = fmt.Sprintf("Qty %d  ·  %s", a.keypad.Int(), a.keypad.Money())
+		}
+		l := material.Body1(th, txt)
+		l.Font = monoFont
+		l.Alignment = text.End
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return l.Layout(gtx)
+	})
+}
+
+// layoutLine lays out a swipeable order line. The line follows the
+// drag, uncovering the remove action behind it.
+func (a *App) layoutLine(gtx C, th *material.Theme, i int) D {
+	l, r := a.order.Lines[i], a.rows[i]
+	m := op.Record(gtx.Ops)
+	dims := layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return layout.Flex{Alignment: layout.Baseline}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				gtx.Constraints.Min.X = gtx.Px(unit.Dp(40))
+				lbl := material.Body1(th, fmt.Sprintf("%d×", l.Qty))
+				lbl.Font.Weight = text.Bold
+				return lbl.Layout(gtx)
+			}),
+			layout.Flexed(1, material.Body1(th, l.Product.Name).Layout),
+			layout.Rigid(material.Body1(th, l.Total().String()).Layout),
+		)
+	})
+	content := m.Stop()
+	size := dims.Size
+
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect{Max: size}.Add(gtx.Ops)
+	if r.offset != 0 {
+		c := errorColor
+		if math.Abs(float64(r.offset)) < float64(gtx.Px(unit.Dp(swipeThreshold))) {
+			c = style.MulAlpha(c, 0x80)
+		}
+		paint.FillShape(gtx.Ops, c, clip.Rect{Max: size}.Op())
+		dir := layout.W
+		if r.offset < 0 {
+			dir = layout.E
+		}
+		gtx := gtx
+		gtx.Constraints = layout.Exact(size)
+		layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+			return dir.Layout(gtx, func(gtx C) D {
+				lbl := material.Body1(th, "Remove")
+				lbl.Color = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+				return lbl.Layout(gtx)
+			})
+		})
+	}
+	st := op.Save(gtx.Ops)
+	op.Offset(f32.Pt(r.offset, 0)).Add(gtx.Ops)
+	paint.FillShape(gtx.Ops, panelBg, clip.Rect{Max: size}.Op())
+	if i == a.selected {
+		paint.FillShape(gtx.Ops, selectedBg, clip.Rect{Max: size}.Op())
+	}
+	content.Add(gtx.Ops)
+	st.Load()
+	pointer.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	r.drag.Add(gtx.Ops)
+	return dims
+}
+
+// layoutReceipt lays out the receipt of the sale over the register.
+func (a *App) layoutReceipt(gtx C, th *material.Theme) {
+	defer op.Save(gtx.Ops).Load()
+	paint.FillShape(gtx.Ops, scrimColor, clip.Rect{Max: gtx.Constraints.Max}.Op())
+	// Block the input of the register below.
+	pointer.Rect(image.Rectangle{Max: gtx.Constraints.Max}).Add(gtx.Ops)
+	pointer.InputOp{Tag: &a.receipt, Types: pointer.Press}.Add(gtx.Ops)
+
+	gtx.Constraints.Min = image.Point{}
+	layout.Center.Layout(gtx, func(gtx C) D {
+		gtx.Constraints.Max.Y -= gtx.Px(unit.Dp(48))
+		return widget.Border{Color: style.MulAlpha(th.Fg, 0x40), CornerRadius: unit.Dp(4), Width: unit.Dp(1)}.Layout(gtx, func(gtx C) D {
+			m := op.Record(gtx.Ops)
+			dims := layout.UniformInset(unit.Dp(24)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, func(gtx C) D {
+						return a.receiptList.Layout(gtx, 1, func(gtx C, _ int) D {
+							l := material.Body1(th, a.receipt)
+							l.Font = monoFont
+							return l.Layout(gtx)
+						})
+					}),
+					layout.Rigid(func(gtx C) D {
+						l := material.Body2(th, a.printStatus)
+						return layout.Inset{Top: unit.Dp(12)}.Layout(gtx, l.Layout)
+					}),
+					layout.Rigid(func(gtx C) D {
+						return layout.Inset{Top: unit.Dp(12)}.Layout(gtx, func(gtx C) D {
+							return layout.Flex{}.Layout(gtx,
+								layout.Rigid(func(gtx C) D {
+									b := material.Button(th, &a.print, "Print")
+									b.TextSize = unit.Sp(20)
+									b.Inset = layout.UniformInset(unit.Dp(20))
+									if a.printing {
+										b.Background = style.MulAlpha(b.Background, 0x80)
+									}
+									return b.Layout(gtx)
+								}),
+								layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+								layout.Rigid(func(gtx C) D {
+									b := material.Button(th, &a.newSale, "New sale")
+									b.TextSize = unit.Sp(20)
+									b.Inset = layout.UniformInset(unit.Dp(20))
+									b.Background = categoryColors["Lunch"]
+									return b.Layout(gtx)
+								}),
+							)
+						})
+					}),
+				)
+			})
+			call := m.Stop()
+			paint.FillShape(gtx.Ops, receiptPaper, clip.UniformRRect(f32.Rectangle{Max: layout.FPt(dims.Size)}, float32(gtx.Px(unit.Dp(4)))).Op(gtx.Ops))
+			call.Add(gtx.Ops)
+			return dims
+		})
+	})
+}
@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Money is an amount in cents.
+type Money int64
+
+func (m Money) String() string {
+	sign := ""
+	if m < 0 {
+		sign, m = "-", -m
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, m/100, m%100)
+}
+
+type Product struct {
+	Name     string
+	Category string
+	Price    Money
+}
+
+// Line is a line of an order.
+type Line struct {
+	Product *Product
+	Qty     int
+}
+
+func (l Line) Total() Money {
+	return l.Product.Price * Money(l.Qty)
+}
+
+// Order is a sale being rung up.
+type Order struct {
+	Lines []Line
+	// TaxRate is the sales tax in basis points, included in the prices.
+	TaxRate int
+}
+
+// Add adds a product to the order, to its line if there is one, and
+// returns the index of the line.
+func (o *Order) Add(p *Product) int {
+	for i := range o.Lines {
+		if o.Lines[i].Product == p {
+			o.Lines[i].Qty++
+			return i
+		}
+	}
+	o.Lines = append(o.Lines, Line{Product: p, Qty: 1})
+	return len(o.Lines) - 1
+}
+
+// SetQty sets the quantity of a line. A quantity of zero removes it.
+func (o *Order) SetQty(i, qty int) {
+	if qty <= 0 {
+		o.Remove(i)
+		return
+	}
+	o.Lines[i].Qty = qty
+}
+
+func (o *Order) Remove(i int) {
+	o.Lines = append(o.Lines[:i:i], o.Lines[i+1:]...)
+}
+
+func (o *Order) Total() Money {
+	var t Money
+	for _, l := range o.Lines {
+		t += l.Total()
+	}
+	return t
+}
+
+// Tax returns the tax included in the total, rounded to the cent.
+func (o *Order) Tax() Money {
+	t := o.Total()
+	rate := Money(o.TaxRate)
+	// The total is the net amount plus tax: tax = total * rate / (1 + rate).
+	return (t*rate*2 + 10000 + rate) / (2 * (10000 + rate))
+}
+
+func (o *Order) Items() int {
+	n := 0
+	for _, l := range o.Lines {
+		n += l.Qty
+	}
+	return n
+}
+
+// Payment is how an order was paid.
+type Payment struct {
+	Method string
+	// Tendered is the amount handed over, at least the total.
+	Tendered Money
+}
+
+// receiptWidth is the number of columns of receipts, for the 58mm roll
+// printers of most registers.
+const receiptWidth = 32
+
+// Receipt formats the receipt of a paid order as plain text.
+func Receipt(shop string, o *Order, pay Payment, t time.Time) string {
+	var b strings.Builder
+	center := func(s string) {
+		pad := (receiptWidth - utf8.RuneCountInString(s)) / 2
+		if pad < 0 {
+			pad = 0
+		}
+		b.WriteString(strings.Repeat(" ", pad) + s + "\n")
+	}
+	// row writes a left and right aligned column, wrapping the left one
+	// if they don't fit.
+	row := func(left, right string) {
+		space := receiptWidth - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+		if space < 1 {
+			b.WriteString(left + "\n")
+			left, space = "", receiptWidth-utf8.RuneCountInString(right)
+		}
+		b.WriteString(left + strings.Repeat(" ", space) + right + "\n")
+	}
+	rule := strings.Repeat("-", receiptWidth) + "\n"
+
+	center(shop)
+	center(t.Format("2006-01-02 15:04"))
+	b.WriteString(rule)
+	for _, l := range o.Lines {
+		name := l.Product.Name
+		if l.Qty != 1 {
+			name = fmt.Sprintf("%d x %s", l.Qty, name)
+		}
+		row(name, l.Total().String())
+	}
+	b.WriteString(rule)
+	row("TOTAL", o.Total().String())
+	row(pay.Method, pay.Tendered.String())
+	if change := pay.Tendered - o.Total(); change > 0 {
+		row("Change", change.String())
+	}
+	row(fmt.Sprintf("incl. tax %d.%02d%%", o.TaxRate/100, o.TaxRate%100), o.Tax().String())
+	b.WriteString(rule)
+	center("Thank you!")
+	return b.String()
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMoney(t *testing.T) {
+	tests := map[Money]string{
+		0:      "0.00",
+		5:      "0.05",
+		1250:   "12.50",
+		-199:   "-1.99",
+		123456: "1234.56",
+	}
+	for m, want := range tests {
+		if got := m.String(); got != want {
+			t.Errorf("Money(%d) = %q, want %q", int64(m), got, want)
+		}
+	}
+}
+
+func TestOrder(t *testing.T) {
+	coffee := &Product{Name: "Coffee", Price: 250}
+	cake := &Product{Name: "Cake", Price: 400}
+	o := Order{TaxRate: 1000}
+	o.Add(coffee)
+	if i := o.Add(cake); i != 1 {
+		t.Errorf("cake added to line %d, want 1", i)
+	}
+	if i := o.Add(coffee); i != 0 {
+		t.Errorf("second coffee added to line %d, want 0", i)
+	}
+	if got := o.Total(); got != 900 {
+		t.Errorf("total %v, want 9.00", got)
+	}
+	// 9.00 includes 10% tax of 0.8181…
+	if got := o.Tax(); got != 82 {
+		t.Errorf("tax %v, want 0.82", got)
+	}
+	o.SetQty(1, 3)
+	if o.Items() != 5 || o.Total() != 1700 {
+		t.Errorf("%d items for %v, want 5 for 17.00", o.Items(), o.Total())
+	}
+	o.SetQty(0, 0)
+	if len(o.Lines) != 1 || o.Lines[0].Product != cake {
+		t.Errorf("lines %v after removing the coffee, want the cake", o.Lines)
+	}
+}
+
+func TestReceipt(t *testing.T) {
+	o := Order{TaxRate: 2000}
+	o.Add(&Product{Name: "Espresso", Price: 250})
+	o.Add(&Product{Name: "Sandwich with a very long name", Price: 690})
+	o.SetQty(0, 2)
+	r := Receipt("Shop", &o, Payment{Method: "Cash", Tendered: 2000}, time.Date(2021, 5, 20, 9, 5, 0, 0, time.UTC))
+	want := []string{
+		"              Shop",
+		"        2021-05-20 09:05",
+		"--------------------------------",
+		"2 x Espresso                5.00",
+		"Sandwich with a very long name",
+		"                            6.90",
+		"--------------------------------",
+		"TOTAL                      11.90",
+		"Cash                       20.00",
+		"Change                      8.10",
+		"incl. tax 20.00%            1.98",
+		"--------------------------------",
+		"           Thank you!",
+		"",
+	}
+	if got := strings.Split(r, "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("receipt:\n%s\nwant:\n%s", r, strings.Join(want, "\n"))
+	}
+}
+
+func TestKeypad(t *testing.T) {
+	var k Keypad
+	for _, key := range []string{"0", "1", "2", "00", "5"} {
+		k.Type(key)
+	}
+	if k.Int() != 12005 || k.Money().String() != "120.05" {
+		t.Errorf("entered %d, %v, want 12005, 120.05", k.Int(), k.Money())
+	}
+	for i := 0; i < 10; i++ {
+		k.Type("9")
+	}
+	if k.Int() != 120059999 {
+		t.Errorf("entered %d, want at most %d digits", k.Int(), maxDigits)
+	}
+	k.Type("C")
+	if !k.Empty() || k.Int() != 0 {
+		t.Errorf("entered %d after clearing", k.Int())
+	}
+}
@@ -0,0 +1,14 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build !darwin && !windows && !((linux && !android) || freebsd || openbsd)
+// +build !darwin
+// +build !windows
+// +build !linux android
+// +build !freebsd
+// +build !openbsd
+
+package main
+
+func printFile(printer, file string) error {
+	return errUnsupported
+}
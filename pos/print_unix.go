@@ -0,0 +1,18 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build darwin || (linux && !android) || freebsd || openbsd
+// +build darwin linux,!android freebsd openbsd
+
+package main
+
+import "os/exec"
+
+// printFile prints with lp, the command of CUPS, which macOS and most
+// Unix desktops use for printing.
+func printFile(printer, file string) error {
+	args := []string{"-t", "Receipt"}
+	if printer != "" {
+		args = append(args, "-d", printer)
+	}
+	return run(exec.Command("lp", append(args, file)...))
+}
@@ -0,0 +1,14 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import "os/exec"
+
+// printFile prints with Notepad, which prints text files without
+// showing a window.
+func printFile(printer, file string) error {
+	if printer != "" {
+		return run(exec.Command("notepad", "/pt", file, printer))
+	}
+	return run(exec.Command("notepad", "/p", file))
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+	"math/cmplx"
+
+	"gioui.org/example/internal/colorpicker"
+)
+
+// previewSize is the size of the previews in pixels.
+const previewSize = 160
+
+// maxIter is the iteration limit of the renders, high enough to make
+// them expensive.
+const maxIter = 1500
+
+// juliaParam returns the parameter of the Julia set of an item: points
+// along a circle through interesting parts of the Mandelbrot set.
+func juliaParam(item int) complex128 {
+	const golden = 2.399963229728653
+	return cmplx.Rect(0.7885, float64(item)*golden)
+}
+
+// renderJulia renders the Julia set of an item. It checks ctx after
+// every row, to stop soon after the item scrolls out of view.
+func renderJulia(ctx context.Context, item int) (*image.RGBA, error) {
+	c := juliaParam(item)
+	img := image.NewRGBA(image.Rect(0, 0, previewSize, previewSize))
+	hue := float32(item%12) * 30
+	for y := 0; y < previewSize; y++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for x := 0; x < previewSize; x++ {
+			z := complex(3*(float64(x)/previewSize-0.5), 3*(float64(y)/previewSize-0.5))
+			n := 0
+			for ; n < maxIter && real(z)*real(z)+imag(z)*imag(z) < 16; n++ {
+				z = z*z + c
+			}
+			v := float32(0)
+			if n < maxIter {
+				// Smooth the bands of the escape count.
+				mu := float64(n) + 1 - math.Log2(math.Log(cmplx.Abs(z)))
+				v = float32(math.Min(1, math.Sqrt(math.Max(0, mu)/64)))
+			}
+			h := float32(math.Mod(float64(hue+60*v), 360))
+			col := colorpicker.HSV{H: h, S: 0.7, V: v}.RGB(0xff)
+			img.SetRGBA(x, y, color.RGBA{R: col.R, G: col.G, B: col.B, A: 0xff})
+		}
+	}
+	return img, nil
+}
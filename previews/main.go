@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program shows a gallery of thousands of previews that are
+// expensive to compute, renders of Julia sets, the way a photo browser
+// shows thumbnails of RAW files. The previews are rendered by a bounded
+// pool of workers, in the order they appear on screen. When items scroll
+// out of view their queued jobs are dropped and their running jobs
+// cancelled, so the workers only spend time on what the user sees; the
+// status line counts the cancelled jobs and the time they had used.
+//
+// Usage:
+//
+//	go run ./previews [-workers 4] [-items 5000]
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"runtime"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	workersFlag = flag.Int("workers", runtime.NumCPU(), "number of workers")
+	itemsFlag   = flag.Int("items", 5000, "number of items")
+)
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Previews"),
+			app.Size(unit.Dp(900), unit.Dp(700)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// maxPreviews is the number of previews kept in memory, a few screens
+// full.
+const maxPreviews = 400
+
+var (
+	cellSize    = unit.Dp(120)
+	placeholder = color.NRGBA{R: 0xe0, G: 0xe0, B: 0xe0, A: 0xff}
+)
+
+type preview struct {
+	img paint.ImageOp
+	// used is the frame the preview was last shown in.
+	used int
+}
+
+type App struct {
+	pool     *Pool
+	items    int
+	previews map[int]*preview
+	frame    int
+	list     layout.List
+	// shown are the items shown in the last frame, and cols the number
+	// of columns.
+	shown []int
+	cols  int
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		pool:     NewPool(*workersFlag, renderJulia),
+		items:    *itemsFlag,
+		previews: make(map[int]*preview),
+		list:     layout.List{Axis: layout.Vertical},
+	}
+	defer a.pool.Close()
+	var ops op.Ops
+	for {
+		select {
+		case r := <-a.pool.Results():
+			if r.Err == nil {
+				a.previews[r.Item] = &preview{img: paint.NewImageOp(r.Img), used: a.frame}
+			}
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	a.frame++
+	a.shown = a.shown[:0]
+	dims := layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return a.layoutGrid(gtx, th)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, a.layoutStatus(th))
+		}),
+	)
+	a.prefetch()
+	a.pool.Frame()
+	a.evict()
+	return dims
+}
+
+// prefetch wants the row after the ones shown, after them in priority.
+func (a *App) prefetch() {
+	if len(a.shown) == 0 {
+		return
+	}
+	last := a.shown[len(a.shown)-1]
+	for it := last + 1; it < a.items && it <= last+a.cols; it++ {
+		if _, ok := a.previews[it]; !ok {
+			a.pool.Want(it)
+		}
+	}
+}
+
+// evict drops the previews shown longest ago, beyond maxPreviews.
+func (a *App) evict() {
+	for len(a.previews) > maxPreviews {
+		oldest, used := -1, a.frame
+		for it, p := range a.previews {
+			if p.used < used {
+				oldest, used = it, p.used
+			}
+		}
+		if oldest == -1 {
+			return
+		}
+		delete(a.previews, oldest)
+	}
+}
+
+func (a *App) layoutGrid(gtx C, th *material.Theme) D {
+	cell := gtx.Px(cellSize)
+	gap := gtx.Px(unit.Dp(4))
+	a.cols = (gtx.Constraints.Max.X + gap) / (cell + gap)
+	if a.cols < 1 {
+		a.cols = 1
+	}
+	rows := (a.items + a.cols - 1) / a.cols
+	return a.list.Layout(gtx, rows, func(gtx C, row int) D {
+		var children []layout.FlexChild
+		for col := 0; col < a.cols; col++ {
+			it := row*a.cols + col
+			if it >= a.items {
+				break
+			}
+			children = append(children,
+				layout.Rigid(func(gtx C) D {
+					gtx.Constraints = layout.Exact(image.Pt(cell, cell))
+					return a.layoutItem(gtx, th, it)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Px(float32(gap))}.Layout),
+			)
+		}
+		return layout.Inset{Bottom: unit.Px(float32(gap))}.Layout(gtx, func(gtx C) D {
+			return layout.Flex{}.Layout(gtx, children...)
+		})
+	})
+}
+
+func (a *App) layoutItem(gtx C, th *material.Theme, it int) D {
+	a.shown = append(a.shown, it)
+	p, ok := a.previews[it]
+	if !ok {
+		a.pool.Want(it)
+		paint.FillShape(gtx.Ops, placeholder, clip.Rect{Max: gtx.Constraints.Max}.Op())
+		return layout.Center.Layout(gtx, func(gtx C) D {
+			l := material.Caption(th, fmt.Sprintf("#%d", it))
+			l.Alignment = text.Middle
+			return l.Layout(gtx)
+		})
+	}
+	p.used = a.frame
+	return widget.Image{Src: p.img, Fit: widget.Contain}.Layout(gtx)
+}
+
+func (a *App) layoutStatus(th *material.Theme) layout.Widget {
+	return func(gtx C) D {
+		s := a.pool.Stats()
+		txt := fmt.Sprintf("%d workers: %d queued, %d running, %d done, %d cancelled (%s spent before cancelling). %d previews in memory.",
+			*workersFlag, s.Queued, s.Running, s.Done, s.Cancelled, s.Wasted.Round(time.Millisecond), len(a.previews))
+		return material.Caption(th, txt).Layout(gtx)
+	}
+}
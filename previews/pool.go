@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"image"
+	"sync"
+	"time"
+)
+
+// Render renders the preview of an item. It should return soon after ctx
+// is cancelled.
+type Render func(ctx context.Context, item int) (*image.RGBA, error)
+
+// Result is a rendered preview.
+type Result struct {
+	Item    int
+	Img     *image.RGBA
+	Err     error
+	Elapsed time.Duration
+}
+
+// Stats counts the work of a pool.
+type Stats struct {
+	Queued, Running int
+	// Done and Cancelled count the finished and the cancelled jobs, and
+	// Wasted is the time spent on cancelled jobs before they stopped.
+	Done, Cancelled int
+	Wasted          time.Duration
+}
+
+// Pool renders previews on a fixed number of workers. The UI wants the
+// previews it shows in each frame, in order of priority; at the end of
+// the frame, queued and running jobs for items no longer wanted are
+// cancelled, so scrolling past items doesn't leave the workers busy with
+// previews nobody sees.
+type Pool struct {
+	render  Render
+	results chan Result
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// pending are the queued items in order of priority, and running the
+	// cancel functions of the running ones.
+	pending []int
+	running map[int]context.CancelFunc
+	// wanted are the items wanted in the current frame, and next those
+	// of them to queue at its end.
+	wanted map[int]bool
+	next   []int
+	stats  Stats
+}
+
+// NewPool starts a pool of workers rendering with render.
+func NewPool(workers int, render Render) *Pool {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		render: render,
+		// A result per worker keeps the workers from waiting on the UI.
+		results: make(chan Result, workers),
+		ctx:     ctx,
+		cancel:  cancel,
+		running: make(map[int]context.CancelFunc),
+		wanted:  make(map[int]bool),
+	}
+	p.cond = sync.NewCond(&p.mu)
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+// Results returns the channel of rendered previews. Cancelled jobs
+// deliver no result. The channel is closed by Close.
+func (p *Pool) Results() <-chan Result {
+	return p.results
+}
+
+// Want asks for the preview of an item in this frame. The items wanted
+// in a frame are rendered in the order they were wanted.
+func (p *Pool) Want(item int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.wanted[item] {
+		return
+	}
+	p.wanted[item] = true
+	if _, ok := p.running[item]; !ok {
+		p.next = append(p.next, item)
+	}
+}
+
+// Frame ends a frame: the items wanted in it replace the queue, and the
+// running jobs of items not wanted are cancelled.
+func (p *Pool) Frame() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending, p.next = p.next, p.pending[:0]
+	for it, cancel := range p.running {
+		if !p.wanted[it] {
+			cancel()
+		}
+	}
+	p.wanted = make(map[int]bool)
+	p.cond.Broadcast()
+}
+
+// Stats returns the counts of the work of the pool.
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.stats
+	s.Queued = len(p.pending)
+	s.Running = len(p.running)
+	return s
+}
+
+// Close cancels all jobs, waits for the workers to exit and closes the
+// results channel.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	p.cancel()
+	p.cond.Broadcast()
+	p.mu.Unlock()
+	p.wg.Wait()
+	close(p.results)
+}
+
+// take waits for a pending item, or returns false when the pool is
+// closed.
+func (p *Pool) take() (int, context.Context, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.pending) == 0 && p.ctx.Err() == nil {
+		p.cond.Wait()
+	}
+	if p.ctx.Err() != nil {
+		return 0, nil, false
+	}
+	it := p.pending[0]
+	p.pending = p.pending[1:]
+	ctx, cancel := context.WithCancel(p.ctx)
+	p.running[it] = cancel
+	return it, ctx, true
+}
+
+func (p *Pool) worker() {
+	defer p.wg.Done()
+	for {
+		it, ctx, ok := p.take()
+		if !ok {
+			return
+		}
+		start := time.Now()
+		img, err := p.render(ctx, it)
+		elapsed := time.Since(start)
+		p.mu.Lock()
+		cancelled := ctx.Err() != nil
+		p.running[it]()
+		delete(p.running, it)
+		if cancelled {
+			p.stats.Cancelled++
+			p.stats.Wasted += elapsed
+		} else {
+			p.stats.Done++
+		}
+		p.mu.Unlock()
+		if cancelled {
+			continue
+		}
+		select {
+		case p.results <- Result{Item: it, Img: img, Err: err, Elapsed: elapsed}:
+		case <-p.ctx.Done():
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"image"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPoolResults(t *testing.T) {
+	p := NewPool(2, func(ctx context.Context, item int) (*image.RGBA, error) {
+		return image.NewRGBA(image.Rect(0, 0, item, item)), nil
+	})
+	for it := 1; it <= 5; it++ {
+		p.Want(it)
+	}
+	p.Frame()
+	got := make(map[int]bool)
+	for len(got) < 5 {
+		r := <-p.Results()
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		if s := r.Img.Bounds().Dx(); s != r.Item {
+			t.Errorf("item %d: got preview of size %d", r.Item, s)
+		}
+		got[r.Item] = true
+	}
+	p.Close()
+	if s := p.Stats(); s.Done != 5 || s.Cancelled != 0 {
+		t.Errorf("got %+v, want 5 done", s)
+	}
+}
+
+func TestPoolBounded(t *testing.T) {
+	const workers = 3
+	var mu sync.Mutex
+	running, peak := 0, 0
+	p := NewPool(workers, func(ctx context.Context, item int) (*image.RGBA, error) {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil, nil
+	})
+	for it := 0; it < 20; it++ {
+		p.Want(it)
+	}
+	p.Frame()
+	for i := 0; i < 20; i++ {
+		<-p.Results()
+	}
+	p.Close()
+	if peak > workers {
+		t.Errorf("%d jobs ran at once, want at most %d", peak, workers)
+	}
+}
+
+func TestPoolCancel(t *testing.T) {
+	started := make(chan int)
+	p := NewPool(1, func(ctx context.Context, item int) (*image.RGBA, error) {
+		started <- item
+		if item == 1 {
+			// Stands for a slow render, stopped by cancellation.
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return nil, nil
+	})
+	defer p.Close()
+	p.Want(1)
+	p.Want(2)
+	p.Frame()
+	if it := <-started; it != 1 {
+		t.Fatalf("started item %d, want 1", it)
+	}
+	// Item 1 scrolls out of view, item 2 stays.
+	p.Want(2)
+	p.Want(3)
+	p.Frame()
+	if it := <-started; it != 2 {
+		t.Fatalf("started item %d, want 2", it)
+	}
+	if r := <-p.Results(); r.Item != 2 {
+		t.Errorf("got result for item %d, want 2", r.Item)
+	}
+	if it := <-started; it != 3 {
+		t.Fatalf("started item %d, want 3", it)
+	}
+	if r := <-p.Results(); r.Item != 3 {
+		t.Errorf("got result for item %d, want 3", r.Item)
+	}
+	if s := p.Stats(); s.Cancelled != 1 || s.Done != 2 {
+		t.Errorf("got %+v, want 1 cancelled and 2 done", s)
+	}
+}
+
+func TestPoolClose(t *testing.T) {
+	p := NewPool(4, func(ctx context.Context, item int) (*image.RGBA, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	for it := 0; it < 10; it++ {
+		p.Want(it)
+	}
+	p.Frame()
+	done := make(chan struct{})
+	go func() {
+		p.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close didn't return")
+	}
+	if _, ok := <-p.Results(); ok {
+		t.Error("results channel open after Close")
+	}
+}
+
+func BenchmarkJulia(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		renderJulia(context.Background(), i)
+	}
+}
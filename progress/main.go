@@ -0,0 +1,336 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates progress indicators: determinate and
+// indeterminate bars and circles, a bar stacked from several segments and
+// progress reported by real background work that can be cancelled.
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Progress"),
+			app.Size(unit.Dp(560), unit.Dp(640)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// job hashes a large amount of generated data in the background,
+// reporting its progress.
+type job struct {
+	cancel   context.CancelFunc
+	progress chan float32
+	done     chan jobResult
+}
+
+type jobResult struct {
+	sum [sha256.Size]byte
+	err error
+}
+
+// jobSize is the number of bytes hashed by a job.
+const jobSize = 1 << 30
+
+func startJob(w *app.Window) *job {
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &job{
+		cancel:   cancel,
+		progress: make(chan float32, 1),
+		done:     make(chan jobResult, 1),
+	}
+	go func() {
+		h := sha256.New()
+		buf := make([]byte, 1<<20)
+		rand.New(rand.NewSource(1)).Read(buf)
+		for n := 0; n < jobSize; n += len(buf) {
+			if err := ctx.Err(); err != nil {
+				j.done <- jobResult{err: err}
+				w.Invalidate()
+				return
+			}
+			buf[0] = byte(n >> 20)
+			h.Write(buf)
+			// Report the progress without blocking: drop the previous
+			// report if the UI hasn't seen it yet.
+			select {
+			case <-j.progress:
+			default:
+			}
+			j.progress <- float32(n+len(buf)) / jobSize
+			w.Invalidate()
+		}
+		var res jobResult
+		copy(res.sum[:], h.Sum(nil))
+		j.done <- res
+		w.Invalidate()
+	}()
+	return j
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	var (
+		ops      op.Ops
+		start    widget.Clickable
+		cancel   widget.Clickable
+		running  *job
+		progress float32
+		status   = "Idle"
+		begin    = time.Now()
+	)
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			if running != nil {
+				running.cancel()
+			}
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			if running != nil {
+				select {
+				case p := <-running.progress:
+					progress = p
+					status = fmt.Sprintf("Hashing %.0f%%", p*100)
+				default:
+				}
+				select {
+				case res := <-running.done:
+					running = nil
+					if res.err != nil {
+						status = "Cancelled"
+					} else {
+						status = fmt.Sprintf("Done: %x…", res.sum[:8])
+					}
+				default:
+				}
+			}
+			for start.Clicked() {
+				if running == nil {
+					running = startJob(w)
+					progress = 0
+					status = "Starting"
+				}
+			}
+			for cancel.Clicked() {
+				if running != nil {
+					running.cancel()
+				}
+			}
+
+			// The demo value for the determinate indicators cycles every
+			// 4 seconds.
+			t := float32(math.Mod(gtx.Now.Sub(begin).Seconds()/4, 1))
+
+			section := func(title string, w layout.Widget) layout.FlexChild {
+				return layout.Rigid(func(gtx C) D {
+					return layout.Inset{Bottom: unit.Dp(24)}.Layout(gtx, func(gtx C) D {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(material.Body1(th, title).Layout),
+							layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+							layout.Rigid(w),
+						)
+					})
+				})
+			}
+			layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					section("Determinate", material.ProgressBar(th, t).Layout),
+					section("Indeterminate", func(gtx C) D {
+						return indeterminateBar(gtx, th, gtx.Now.Sub(begin))
+					}),
+					section("Circular", func(gtx C) D {
+						return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+							layout.Rigid(func(gtx C) D {
+								return progressCircle(gtx, th, t)
+							}),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(24)}.Layout),
+							layout.Rigid(material.Loader(th).Layout),
+						)
+					}),
+					section("Stacked", func(gtx C) D {
+						return stackedBar(gtx, th, []segment{
+							{"Apps", .32, color.NRGBA{R: 0x42, G: 0x85, B: 0xf4, A: 0xff}},
+							{"Photos", .21, color.NRGBA{R: 0xfb, G: 0xbc, B: 0x05, A: 0xff}},
+							{"Music", .12, color.NRGBA{R: 0x34, G: 0xa8, B: 0x53, A: 0xff}},
+							{"System", .08, color.NRGBA{R: 0x9e, G: 0x9e, B: 0x9e, A: 0xff}},
+						})
+					}),
+					section("Background work", func(gtx C) D {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(material.ProgressBar(th, progress).Layout),
+							layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+							layout.Rigid(func(gtx C) D {
+								return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+									layout.Rigid(func(gtx C) D {
+										if running != nil {
+											gtx = gtx.Disabled()
+										}
+										return material.Button(th, &start, "Hash 1 GiB").Layout(gtx)
+									}),
+									layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+									layout.Rigid(func(gtx C) D {
+										if running == nil {
+											gtx = gtx.Disabled()
+										}
+										return material.Button(th, &cancel, "Cancel").Layout(gtx)
+									}),
+									layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+									layout.Rigid(material.Body2(th, status).Layout),
+								)
+							}),
+						)
+					}),
+				)
+			})
+			// The demo indicators are always animating.
+			op.InvalidateOp{}.Add(gtx.Ops)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+// indeterminateBar draws a bar with a band sweeping from left to right.
+func indeterminateBar(gtx C, th *material.Theme, elapsed time.Duration) D {
+	const period = 1500 * time.Millisecond
+	width := gtx.Constraints.Max.X
+	height := gtx.Px(unit.Dp(4))
+	track := th.Palette.ContrastBg
+	track.A = 0x40
+	paint.FillShape(gtx.Ops, track, clip.Rect(image.Rect(0, 0, width, height)).Op())
+	// The band accelerates in and out, and is a third of the width.
+	t := float64(elapsed%period) / float64(period)
+	t = (1 - math.Cos(t*math.Pi)) / 2
+	band := width / 3
+	x := int(t*float64(width+band)) - band
+	paint.FillShape(gtx.Ops, th.Palette.ContrastBg, clip.Rect(image.Rect(x, 0, x+band, height)).Op())
+	return D{Size: image.Pt(width, height)}
+}
+
+// progressCircle draws a ring filled clockwise from the top to fraction
+// t, with the percentage in its center.
+func progressCircle(gtx C, th *material.Theme, t float32) D {
+	size := gtx.Px(unit.Dp(56))
+	r := float32(size) / 2
+	c := f32.Pt(r, r)
+	width := float32(gtx.Px(unit.Dp(5)))
+	track := th.Palette.ContrastBg
+	track.A = 0x40
+	paint.FillShape(gtx.Ops, track, ring(gtx.Ops, c, r-width, r, 0, 2*math.Pi))
+	if t > 0 {
+		paint.FillShape(gtx.Ops, th.Palette.ContrastBg, ring(gtx.Ops, c, r-width, r, 0, 2*math.Pi*t))
+	}
+	gtx.Constraints = layout.Exact(image.Pt(size, size))
+	layout.Center.Layout(gtx, material.Caption(th, fmt.Sprintf("%.0f%%", t*100)).Layout)
+	return D{Size: image.Pt(size, size)}
+}
+
+// ring returns the outline of a ring segment between the radii r0 and r1,
+// from angle a0 to a1 in radians measured clockwise from the top.
+func ring(ops *op.Ops, c f32.Point, r0, r1, a0, a1 float32) clip.Op {
+	n := int(math.Ceil(float64(a1-a0) / (math.Pi / 90)))
+	if n < 1 {
+		n = 1
+	}
+	pt := func(r, a float32) f32.Point {
+		s, co := math.Sincos(float64(a) - math.Pi/2)
+		return c.Add(f32.Pt(r*float32(co), r*float32(s)))
+	}
+	var p clip.Path
+	p.Begin(ops)
+	p.MoveTo(pt(r1, a0))
+	for i := 1; i <= n; i++ {
+		p.LineTo(pt(r1, a0+(a1-a0)*float32(i)/float32(n)))
+	}
+	for i := n; i >= 0; i-- {
+		p.LineTo(pt(r0, a0+(a1-a0)*float32(i)/float32(n)))
+	}
+	p.Close()
+	return clip.Outline{Path: p.End()}.Op()
+}
+
+// segment is a part of a stacked bar.
+type segment struct {
+	label    string
+	fraction float32
+	color    color.NRGBA
+}
+
+// stackedBar draws the segments side by side, followed by a legend.
+func stackedBar(gtx C, th *material.Theme, segs []segment) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			width := gtx.Constraints.Max.X
+			height := gtx.Px(unit.Dp(12))
+			rr := float32(height) / 2
+			defer op.Save(gtx.Ops).Load()
+			clip.UniformRRect(f32.Rectangle{Max: layout.FPt(image.Pt(width, height))}, rr).Add(gtx.Ops)
+			paint.Fill(gtx.Ops, color.NRGBA{A: 0x20})
+			x := float32(0)
+			for _, s := range segs {
+				w := s.fraction * float32(width)
+				r := image.Rect(int(x), 0, int(x+w), height)
+				paint.FillShape(gtx.Ops, s.color, clip.Rect(r).Op())
+				x += w
+			}
+			return D{Size: image.Pt(width, height)}
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+		layout.Rigid(func(gtx C) D {
+			var children []layout.FlexChild
+			for _, s := range segs {
+				s := s
+				children = append(children, layout.Rigid(func(gtx C) D {
+					return layout.Inset{Right: unit.Dp(16)}.Layout(gtx, func(gtx C) D {
+						return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+							layout.Rigid(func(gtx C) D {
+								sz := gtx.Px(unit.Dp(10))
+								paint.FillShape(gtx.Ops, s.color, clip.Rect(image.Rect(0, 0, sz, sz)).Op())
+								return D{Size: image.Pt(sz, sz)}
+							}),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+							layout.Rigid(material.Caption(th, fmt.Sprintf("%s %.0f%%", s.label, s.fraction*100)).Layout),
+						)
+					})
+				}))
+			}
+			return layout.Flex{}.Layout(gtx, children...)
+		}),
+	)
+}
@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates an inspector-style property grid, such as
+// found in editors and debug tools. The properties of a shape are
+// grouped in collapsible sections and edited with an editor suited to
+// their type; numbers are changed by dragging them sideways.
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Property Grid"),
+			app.Size(unit.Dp(900), unit.Dp(600)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var kinds = []string{"Rectangle", "Rounded", "Circle"}
+
+// shape is the object inspected by the grid.
+type shape struct {
+	Visible  bool
+	Kind     int
+	Fill     color.NRGBA
+	Outline  bool
+	Stroke   color.NRGBA
+	X, Y     float32
+	Size     float32
+	Rotation float32
+	Opacity  float32
+}
+
+func (s *shape) properties() []*Group {
+	return []*Group{
+		{Name: "Shape", Properties: []Property{
+			&Bool{Name: "Visible", Value: &s.Visible},
+			&Enum{Name: "Kind", Value: &s.Kind, Options: kinds},
+		}},
+		{Name: "Appearance", Properties: []Property{
+			&Color{Name: "Fill", Value: &s.Fill},
+			&Bool{Name: "Outline", Value: &s.Outline},
+			&Color{Name: "Outline color", Value: &s.Stroke},
+			&Number{Name: "Opacity", Value: &s.Opacity, Min: 0, Max: 1, Step: 0.01, Format: "%.2f"},
+		}},
+		{Name: "Transform", Properties: []Property{
+			&Number{Name: "X", Value: &s.X, Min: -200, Max: 200, Step: 1, Format: "%.0f dp"},
+			&Number{Name: "Y", Value: &s.Y, Min: -200, Max: 200, Step: 1, Format: "%.0f dp"},
+			&Number{Name: "Size", Value: &s.Size, Min: 10, Max: 300, Step: 1, Format: "%.0f dp"},
+			&Number{Name: "Rotation", Value: &s.Rotation, Min: -180, Max: 180, Step: 1, Format: "%.1f°"},
+		}},
+	}
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	s := &shape{
+		Visible: true,
+		Kind:    1,
+		Fill:    color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0xff},
+		Stroke:  color.NRGBA{A: 0xff},
+		Size:    120,
+		Opacity: 1,
+	}
+	grid := &Grid{Groups: s.properties(), LabelWidth: 0.4}
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			layout.Flex{}.Layout(gtx,
+				layout.Flexed(1, func(gtx C) D {
+					return drawShape(gtx, s)
+				}),
+				layout.Rigid(func(gtx C) D {
+					gtx.Constraints.Min.X = gtx.Px(unit.Dp(320))
+					gtx.Constraints.Max.X = gtx.Constraints.Min.X
+					paint.FillShape(gtx.Ops, color.NRGBA{A: 0x08}, clip.Rect(image.Rectangle{Max: gtx.Constraints.Max}).Op())
+					return grid.Layout(gtx, th)
+				}),
+			)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+// drawShape draws s centered in the available space, offset by its
+// position.
+func drawShape(gtx C, s *shape) D {
+	sz := gtx.Constraints.Max
+	if !s.Visible {
+		return D{Size: sz}
+	}
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect(image.Rectangle{Max: sz}).Add(gtx.Ops)
+	px := func(v float32) float32 { return float32(gtx.Px(unit.Dp(v))) }
+	center := layout.FPt(sz).Mul(0.5).Add(f32.Pt(px(s.X), px(s.Y)))
+	rot := float32(s.Rotation * math.Pi / 180)
+	op.Affine(f32.Affine2D{}.Rotate(f32.Point{}, rot).Offset(center)).Add(gtx.Ops)
+
+	half := px(s.Size) / 2
+	var r float32
+	switch kinds[s.Kind] {
+	case "Rounded":
+		r = half / 4
+	case "Circle":
+		r = half
+	}
+	alpha := func(c color.NRGBA) color.NRGBA {
+		c.A = uint8(float32(c.A) * s.Opacity)
+		return c
+	}
+	rect := f32.Rect(-half, -half, half, half)
+	if s.Outline {
+		// Draw the outline as a larger shape behind the fill.
+		w := px(3)
+		outer := f32.Rect(-half-w, -half-w, half+w, half+w)
+		paint.FillShape(gtx.Ops, alpha(s.Stroke), clip.UniformRRect(outer, r+w).Op(gtx.Ops))
+	}
+	paint.FillShape(gtx.Ops, alpha(s.Fill), clip.UniformRRect(rect, r).Op(gtx.Ops))
+	return D{Size: sz}
+}
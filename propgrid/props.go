@@ -0,0 +1,304 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/example/internal/colorpicker"
+	"gioui.org/f32"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Property is a named value edited in a Grid.
+type Property interface {
+	Label() string
+	// Layout lays out the editor of the value.
+	Layout(gtx C, th *material.Theme) D
+}
+
+// Group is a collapsible set of properties.
+type Group struct {
+	Name       string
+	Properties []Property
+	Collapsed  bool
+
+	header widget.Clickable
+}
+
+// Grid lays out groups of properties as rows of labels and editors.
+type Grid struct {
+	Groups []*Group
+	// LabelWidth is the fraction of the width taken by the labels.
+	LabelWidth float32
+
+	list layout.List
+}
+
+func (g *Grid) Layout(gtx C, th *material.Theme) D {
+	type row struct {
+		group *Group
+		prop  Property
+	}
+	var rows []row
+	for _, grp := range g.Groups {
+		for grp.header.Clicked() {
+			grp.Collapsed = !grp.Collapsed
+		}
+		rows = append(rows, row{group: grp})
+		if !grp.Collapsed {
+			for _, p := range grp.Properties {
+				rows = append(rows, row{prop: p})
+			}
+		}
+	}
+	g.list.Axis = layout.Vertical
+	return g.list.Layout(gtx, len(rows), func(gtx C, i int) D {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		r := rows[i]
+		if r.group != nil {
+			return g.layoutHeader(gtx, th, r.group)
+		}
+		labelWidth := int(float32(gtx.Constraints.Max.X) * g.LabelWidth)
+		dims := layout.Inset{Top: unit.Dp(3), Bottom: unit.Dp(3)}.Layout(gtx, func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					gtx.Constraints.Min.X = labelWidth
+					gtx.Constraints.Max.X = labelWidth
+					return layout.Inset{Left: unit.Dp(20), Right: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+						l := material.Body2(th, r.prop.Label())
+						l.MaxLines = 1
+						return l.Layout(gtx)
+					})
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					return r.prop.Layout(gtx, th)
+				}),
+			)
+		})
+		// The grid lines.
+		line := gtx.Px(unit.Dp(1))
+		c := color.NRGBA{A: 0x14}
+		paint.FillShape(gtx.Ops, c, clip.Rect(image.Rect(0, dims.Size.Y-line, dims.Size.X, dims.Size.Y)).Op())
+		paint.FillShape(gtx.Ops, c, clip.Rect(image.Rect(labelWidth-line, 0, labelWidth, dims.Size.Y)).Op())
+		return dims
+	})
+}
+
+func (g *Grid) layoutHeader(gtx C, th *material.Theme, grp *Group) D {
+	return material.Clickable(gtx, &grp.header, func(gtx C) D {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		macro := op.Record(gtx.Ops)
+		dims := layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(6), Left: unit.Dp(4)}.Layout(gtx, func(gtx C) D {
+			arrow := "▾ "
+			if grp.Collapsed {
+				arrow = "▸ "
+			}
+			return material.Body1(th, arrow+grp.Name).Layout(gtx)
+		})
+		call := macro.Stop()
+		paint.FillShape(gtx.Ops, color.NRGBA{A: 0x10}, clip.Rect(image.Rectangle{Max: dims.Size}).Op())
+		call.Add(gtx.Ops)
+		return dims
+	})
+}
+
+// Bool is a property edited with a check box.
+type Bool struct {
+	Name  string
+	Value *bool
+
+	box widget.Bool
+}
+
+func (p *Bool) Label() string { return p.Name }
+
+func (p *Bool) Layout(gtx C, th *material.Theme) D {
+	p.box.Value = *p.Value
+	dims := material.CheckBox(th, &p.box, "").Layout(gtx)
+	*p.Value = p.box.Value
+	return dims
+}
+
+// Enum is a property with one of a set of values, selected by cycling
+// through them.
+type Enum struct {
+	Name    string
+	Value   *int
+	Options []string
+
+	prev, next widget.Clickable
+}
+
+func (p *Enum) Label() string { return p.Name }
+
+func (p *Enum) Layout(gtx C, th *material.Theme) D {
+	n := len(p.Options)
+	for p.prev.Clicked() {
+		*p.Value = (*p.Value - 1 + n) % n
+	}
+	for p.next.Clicked() {
+		*p.Value = (*p.Value + 1) % n
+	}
+	arrow := func(c *widget.Clickable, s string) layout.FlexChild {
+		return layout.Rigid(func(gtx C) D {
+			return material.Clickable(gtx, c, func(gtx C) D {
+				return layout.Inset{Left: unit.Dp(8), Right: unit.Dp(8)}.Layout(gtx, material.Body2(th, s).Layout)
+			})
+		})
+	}
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		arrow(&p.prev, "‹"),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Center.Layout(gtx, material.Body2(th, p.Options[*p.Value]).Layout)
+		}),
+		arrow(&p.next, "›"),
+	)
+}
+
+// Color is a property edited with a swatch that expands into a color
+// picker.
+type Color struct {
+	Name  string
+	Value *color.NRGBA
+
+	swatch widget.Clickable
+	open   bool
+	picker colorpicker.State
+}
+
+func (p *Color) Label() string { return p.Name }
+
+func (p *Color) Layout(gtx C, th *material.Theme) D {
+	for p.swatch.Clicked() {
+		p.open = !p.open
+		if p.open {
+			p.picker.SetColor(*p.Value)
+		}
+	}
+	if p.picker.Changed() {
+		*p.Value = p.picker.Color()
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return material.Clickable(gtx, &p.swatch, func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						sz := image.Pt(gtx.Px(unit.Dp(32)), gtx.Px(unit.Dp(18)))
+						rect := f32.Rectangle{Max: layout.FPt(sz)}
+						rr := float32(gtx.Px(unit.Dp(3)))
+						paint.FillShape(gtx.Ops, color.NRGBA{A: 0x40}, clip.UniformRRect(rect, rr).Op(gtx.Ops))
+						inner := rect
+						inner.Min = inner.Min.Add(f32.Pt(1, 1))
+						inner.Max = inner.Max.Sub(f32.Pt(1, 1))
+						paint.FillShape(gtx.Ops, *p.Value, clip.UniformRRect(inner, rr).Op(gtx.Ops))
+						return D{Size: sz}
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(material.Body2(th, colorpicker.Hex(*p.Value)).Layout),
+				)
+			})
+		}),
+		layout.Rigid(func(gtx C) D {
+			if !p.open {
+				return D{}
+			}
+			return layout.Inset{Top: unit.Dp(8), Bottom: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+				pk := colorpicker.Picker(th, &p.picker)
+				pk.WheelSize = unit.Dp(140)
+				return pk.Layout(gtx)
+			})
+		}),
+	)
+}
+
+// Number is a numeric property. Dragging it horizontally scrubs the value
+// by Step per 4dp, or a tenth of that while shift is held.
+type Number struct {
+	Name     string
+	Value    *float32
+	Min, Max float32
+	Step     float32
+	// Format formats the value for display, such as "%.1f°".
+	Format string
+
+	dragging bool
+	startX   float32
+	start    float32
+}
+
+func (p *Number) Label() string { return p.Name }
+
+func (p *Number) Layout(gtx C, th *material.Theme) D {
+	for _, e := range gtx.Events(p) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case pointer.Press:
+			p.dragging = true
+			p.startX = e.Position.X
+			p.start = *p.Value
+		case pointer.Drag:
+			if !p.dragging {
+				break
+			}
+			step := p.Step
+			if e.Modifiers.Contain(key.ModShift) {
+				step /= 10
+			}
+			dx := (e.Position.X - p.startX) / float32(gtx.Px(unit.Dp(4)))
+			v := p.start + float32(math.Round(float64(dx)))*step
+			if v < p.Min {
+				v = p.Min
+			}
+			if v > p.Max {
+				v = p.Max
+			}
+			*p.Value = v
+		case pointer.Release, pointer.Cancel:
+			p.dragging = false
+		}
+	}
+	format := p.Format
+	if format == "" {
+		format = "%g"
+	}
+	l := material.Body2(th, fmt.Sprintf(format, *p.Value))
+	if p.dragging {
+		l.Color = th.Palette.ContrastBg
+	}
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	dims := layout.Inset{Left: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{}.Layout(gtx,
+			layout.Flexed(1, l.Layout),
+			layout.Rigid(func(gtx C) D {
+				// The position of the value in its range.
+				w := gtx.Px(unit.Dp(60))
+				h := gtx.Px(unit.Dp(4))
+				y := gtx.Px(unit.Dp(8))
+				f := (*p.Value - p.Min) / (p.Max - p.Min)
+				paint.FillShape(gtx.Ops, color.NRGBA{A: 0x20}, clip.Rect(image.Rect(0, y, w, y+h)).Op())
+				paint.FillShape(gtx.Ops, th.Palette.ContrastBg, clip.Rect(image.Rect(0, y, int(f*float32(w)), y+h)).Op())
+				return D{Size: image.Pt(w+gtx.Px(unit.Dp(8)), y+h)}
+			}),
+		)
+	})
+	defer op.Save(gtx.Ops).Load()
+	pointer.Rect(image.Rectangle{Max: dims.Size}).Add(gtx.Ops)
+	pointer.CursorNameOp{Name: pointer.CursorColResize}.Add(gtx.Ops)
+	pointer.InputOp{Tag: p, Grab: p.dragging, Types: pointer.Press | pointer.Drag | pointer.Release}.Add(gtx.Ops)
+	return dims
+}
@@ -0,0 +1,408 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program tests regular expressions. The matches of the pattern in
+// the test text are highlighted as either is typed, with capture groups
+// in their own colors, and listed in a table of their groups. A sidebar
+// summarizes the syntax; click an entry to insert it into the pattern.
+//
+// Matching runs in the background, and the highlighted text is split into
+// runs one line at a time as lines scroll into view, so the tester stays
+// responsive for large texts.
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+	"strconv"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Regex Tester"),
+			app.Size(unit.Dp(1100), unit.Dp(760)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+const (
+	samplePattern = `(\w+)@(\w+)\.(com|org)`
+	sampleText    = `Send questions to ann@example.com or to the list at
+gio@lists.org. Bob (bob@test.com) answers on weekdays.
+Invalid: carol@, @dave.com, eve@example.net`
+)
+
+// reference is the syntax summary of the sidebar.
+var reference = []struct {
+	syntax, desc string
+}{
+	{`.`, "any character"},
+	{`\d`, "digit"},
+	{`\w`, "word character"},
+	{`\s`, "whitespace"},
+	{`\b`, "word boundary"},
+	{`[abc]`, "one of a, b, c"},
+	{`[^abc]`, "none of a, b, c"},
+	{`[a-z]`, "range"},
+	{`^`, "start of text or line"},
+	{`$`, "end of text or line"},
+	{`*`, "zero or more"},
+	{`+`, "one or more"},
+	{`?`, "zero or one"},
+	{`{2,5}`, "two to five"},
+	{`*?`, "zero or more, lazily"},
+	{`a|b`, "a or b"},
+	{`(re)`, "capture group"},
+	{`(?P<name>re)`, "named group"},
+	{`(?:re)`, "group without capture"},
+	{`\pL`, "Unicode letter"},
+}
+
+var (
+	monoFont   = text.Font{Variant: "Mono"}
+	errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	// matchColors alternate between matches.
+	matchColors = []color.NRGBA{
+		{R: 0xff, G: 0xeb, B: 0x3b, A: 0x70},
+		{R: 0xff, G: 0xc1, B: 0x07, A: 0x70},
+	}
+	// groupColors color the capture groups.
+	groupColors = []color.NRGBA{
+		{R: 0x42, G: 0xa5, B: 0xf5, A: 0x70},
+		{R: 0x66, G: 0xbb, B: 0x6a, A: 0x70},
+		{R: 0xef, G: 0x53, B: 0x50, A: 0x70},
+		{R: 0xab, G: 0x47, B: 0xbc, A: 0x70},
+	}
+)
+
+type result struct {
+	gen int
+	res *Result
+}
+
+type App struct {
+	results chan result
+	// gen counts the matches started, to drop stale results.
+	gen     int
+	res     *Result
+	err     error
+	pending bool
+
+	pattern, text                 widget.Editor
+	ignoreCase, multiline, dotAll widget.Bool
+	refs                          []widget.Clickable
+	preview, table, sidebar       layout.List
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		results: make(chan result),
+		pattern: widget.Editor{SingleLine: true},
+		refs:    make([]widget.Clickable, len(reference)),
+		preview: layout.List{Axis: layout.Vertical},
+		table:   layout.List{Axis: layout.Vertical},
+		sidebar: layout.List{Axis: layout.Vertical},
+	}
+	a.pattern.SetText(samplePattern)
+	a.text.SetText(sampleText)
+	a.rematch()
+	var ops op.Ops
+	for {
+		select {
+		case r := <-a.results:
+			if r.gen == a.gen {
+				a.res = r.res
+				a.pending = false
+				w.Invalidate()
+			}
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update()
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+// rematch compiles the pattern and starts matching it against the text.
+func (a *App) rematch() {
+	a.gen++
+	re, err := compile(a.pattern.Text(), Flags{
+		IgnoreCase: a.ignoreCase.Value,
+		Multiline:  a.multiline.Value,
+		DotAll:     a.dotAll.Value,
+	})
+	a.err = err
+	if err != nil {
+		a.res = nil
+		a.pending = false
+		return
+	}
+	// Keep showing the previous matches until the new ones are ready.
+	a.pending = true
+	gen, txt := a.gen, a.text.Text()
+	go func() {
+		a.results <- result{gen, run(re, txt)}
+	}()
+}
+
+func (a *App) update() {
+	changed := false
+	for i := range a.refs {
+		for a.refs[i].Clicked() {
+			a.pattern.Insert(reference[i].syntax)
+			changed = true
+		}
+	}
+	for _, ed := range []*widget.Editor{&a.pattern, &a.text} {
+		for _, e := range ed.Events() {
+			if _, ok := e.(widget.ChangeEvent); ok {
+				changed = true
+			}
+		}
+	}
+	for _, b := range []*widget.Bool{&a.ignoreCase, &a.multiline, &a.dotAll} {
+		if b.Changed() {
+			changed = true
+		}
+	}
+	if changed {
+		a.rematch()
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	return layout.Flex{}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+				return a.layoutMain(gtx, th)
+			})
+		}),
+		layout.Rigid(func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Px(unit.Dp(240))
+			gtx.Constraints.Max.X = gtx.Constraints.Min.X
+			paint.FillShape(gtx.Ops, color.NRGBA{A: 0x0c}, clip.Rect{Max: gtx.Constraints.Max}.Op())
+			return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+				return a.layoutReference(gtx, th)
+			})
+		}),
+	)
+}
+
+func (a *App) layoutMain(gtx C, th *material.Theme) D {
+	status := material.Body2(th, "")
+	switch {
+	case a.err != nil:
+		status.Text = a.err.Error()
+		status.Color = errorColor
+	case a.pending:
+		status.Text = "Matching…"
+	case a.res != nil:
+		status.Text = fmt.Sprintf("%d matches", len(a.res.Matches))
+		if a.res.Truncated() {
+			status.Text = fmt.Sprintf("Over %d matches; only the first are shown", maxMatches)
+		}
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(material.Body1(th, "Pattern").Layout),
+		layout.Rigid(func(gtx C) D {
+			return box(gtx, func(gtx C) D {
+				ed := material.Editor(th, &a.pattern, "Regular expression")
+				ed.Font = monoFont
+				return ed.Layout(gtx)
+			})
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(material.CheckBox(th, &a.ignoreCase, "Ignore case (i)").Layout),
+				layout.Rigid(material.CheckBox(th, &a.multiline, "Multiline (m)").Layout),
+				layout.Rigid(material.CheckBox(th, &a.dotAll, "Dot matches newline (s)").Layout),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+				layout.Flexed(1, status.Layout),
+			)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+		layout.Rigid(material.Body1(th, "Test text").Layout),
+		layout.Flexed(1, func(gtx C) D {
+			gtx.Constraints.Min = gtx.Constraints.Max
+			return box(gtx, func(gtx C) D {
+				ed := material.Editor(th, &a.text, "Text to match")
+				ed.Font = monoFont
+				return ed.Layout(gtx)
+			})
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+		layout.Rigid(material.Body1(th, "Matches").Layout),
+		layout.Flexed(1, func(gtx C) D {
+			if a.res == nil {
+				return D{Size: gtx.Constraints.Max}
+			}
+			gtx.Constraints.Min = gtx.Constraints.Max
+			return box(gtx, func(gtx C) D {
+				return a.preview.Layout(gtx, len(a.res.Lines), func(gtx C, i int) D {
+					return a.layoutLine(gtx, th, i)
+				})
+			})
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+		layout.Rigid(material.Body1(th, "Groups").Layout),
+		layout.Flexed(1, func(gtx C) D {
+			return a.layoutTable(gtx, th)
+		}),
+	)
+}
+
+// layoutLine lays out a line of the text with its matches highlighted.
+func (a *App) layoutLine(gtx C, th *material.Theme, i int) D {
+	segs := a.res.Segments(i)
+	if len(segs) == 0 {
+		// Keep the height of empty lines.
+		segs = []segment{{Text: " ", Match: -1}}
+	}
+	children := make([]layout.FlexChild, len(segs))
+	for j, s := range segs {
+		s := s
+		children[j] = layout.Rigid(func(gtx C) D {
+			l := material.Body2(th, s.Text)
+			l.Font = monoFont
+			l.MaxLines = 1
+			m := op.Record(gtx.Ops)
+			dims := l.Layout(gtx)
+			call := m.Stop()
+			if s.Match >= 0 {
+				bg := matchColors[s.Match%len(matchColors)]
+				if s.Group > 0 {
+					bg = groupColors[(s.Group-1)%len(groupColors)]
+				}
+				paint.FillShape(gtx.Ops, bg, clip.Rect{Max: dims.Size}.Op())
+			}
+			call.Add(gtx.Ops)
+			return dims
+		})
+	}
+	return layout.Flex{}.Layout(gtx, children...)
+}
+
+// layoutTable lays out the capture groups of the matches.
+func (a *App) layoutTable(gtx C, th *material.Theme) D {
+	if a.res == nil {
+		return D{Size: gtx.Constraints.Max}
+	}
+	re := a.res.Re
+	headers := []string{"#", "Match"}
+	for i, name := range re.SubexpNames()[1:] {
+		if name == "" {
+			name = strconv.Itoa(i + 1)
+		}
+		headers = append(headers, name)
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return tableRow(gtx, th, headers, true)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return a.table.Layout(gtx, len(a.res.Matches), func(gtx C, i int) D {
+				m := a.res.Matches[i]
+				cells := []string{strconv.Itoa(i + 1)}
+				for g := 0; g < len(m)/2; g++ {
+					if m[2*g] < 0 {
+						cells = append(cells, "—")
+						continue
+					}
+					cells = append(cells, strconv.Quote(a.res.Text[m[2*g]:m[2*g+1]]))
+				}
+				return tableRow(gtx, th, cells, false)
+			})
+		}),
+	)
+}
+
+func tableRow(gtx C, th *material.Theme, cells []string, header bool) D {
+	children := make([]layout.FlexChild, len(cells))
+	for i, c := range cells {
+		c := c
+		width := unit.Dp(160)
+		if i == 0 {
+			width = unit.Dp(48)
+		}
+		children[i] = layout.Rigid(func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Px(width)
+			gtx.Constraints.Max.X = gtx.Constraints.Min.X
+			l := material.Body2(th, c)
+			if header {
+				l = material.Body1(th, c)
+			} else {
+				l.Font = monoFont
+			}
+			l.MaxLines = 1
+			return layout.Inset{Top: unit.Dp(2), Bottom: unit.Dp(2), Right: unit.Dp(8)}.Layout(gtx, l.Layout)
+		})
+	}
+	return layout.Flex{}.Layout(gtx, children...)
+}
+
+func (a *App) layoutReference(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, material.H6(th, "Quick reference").Layout)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return a.sidebar.Layout(gtx, len(reference), func(gtx C, i int) D {
+				r := reference[i]
+				return material.Clickable(gtx, &a.refs[i], func(gtx C) D {
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					return layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx C) D {
+						return layout.Flex{}.Layout(gtx,
+							layout.Rigid(func(gtx C) D {
+								gtx.Constraints.Min.X = gtx.Px(unit.Dp(96))
+								l := material.Body2(th, r.syntax)
+								l.Font = monoFont
+								l.Color = th.Palette.ContrastBg
+								return l.Layout(gtx)
+							}),
+							layout.Flexed(1, material.Body2(th, r.desc).Layout),
+						)
+					})
+				})
+			})
+		}),
+	)
+}
+
+// box lays out w in a bordered box.
+func box(gtx C, w layout.Widget) D {
+	return widget.Border{Color: color.NRGBA{A: 0x40}, CornerRadius: unit.Dp(4), Width: unit.Dp(1)}.Layout(gtx, func(gtx C) D {
+		return layout.UniformInset(unit.Dp(6)).Layout(gtx, w)
+	})
+}
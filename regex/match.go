@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxMatches limits the matches found, to keep the tester responsive
+// for patterns such as "" that match everywhere.
+const maxMatches = 10000
+
+// Result is the outcome of matching a pattern against a text.
+type Result struct {
+	Re   *regexp.Regexp
+	Text string
+	// Matches are the submatch indices of the matches, in order, as
+	// returned by regexp.FindAllStringSubmatchIndex.
+	Matches [][]int
+	// Lines are the offsets of the line starts in Text.
+	Lines []int
+}
+
+// run matches re against text.
+func run(re *regexp.Regexp, text string) *Result {
+	r := &Result{Re: re, Text: text, Lines: []int{0}}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			r.Lines = append(r.Lines, i+1)
+		}
+	}
+	r.Matches = re.FindAllStringSubmatchIndex(text, maxMatches)
+	return r
+}
+
+// Truncated reports whether matching stopped at maxMatches.
+func (r *Result) Truncated() bool {
+	return len(r.Matches) >= maxMatches
+}
+
+// Line returns the text of line i, without the newline.
+func (r *Result) Line(i int) string {
+	start, end := r.lineRange(i)
+	return r.Text[start:end]
+}
+
+func (r *Result) lineRange(i int) (start, end int) {
+	start, end = r.Lines[i], len(r.Text)
+	if i+1 < len(r.Lines) {
+		end = r.Lines[i+1] - 1
+	}
+	return start, end
+}
+
+// segment is a run of a line of text.
+type segment struct {
+	Text string
+	// Match is the index of the match covering the run, or -1.
+	Match int
+	// Group is the innermost capture group covering the run, or 0.
+	Group int
+}
+
+// Segments splits line i into runs by the matches covering them. Only the
+// matches overlapping the line are examined, so lines can be highlighted
+// as they scroll into view.
+func (r *Result) Segments(i int) []segment {
+	start, end := r.lineRange(i)
+	line := r.Text[start:end]
+	// The first match ending after the line start.
+	first := sort.Search(len(r.Matches), func(j int) bool {
+		return r.Matches[j][1] > start
+	})
+	match := make([]int, len(line))
+	group := make([]int, len(line))
+	for k := range match {
+		match[k] = -1
+	}
+	for j := first; j < len(r.Matches) && r.Matches[j][0] < end; j++ {
+		m := r.Matches[j]
+		for g := 0; g < len(m)/2; g++ {
+			s, e := m[2*g], m[2*g+1]
+			if s < 0 {
+				continue
+			}
+			if s < start {
+				s = start
+			}
+			if e > end {
+				e = end
+			}
+			for k := s - start; k < e-start; k++ {
+				match[k] = j
+				// Groups nest, and later groups are inner ones.
+				group[k] = g
+			}
+		}
+	}
+	var segs []segment
+	for k := 0; k < len(line); {
+		l := k + 1
+		for l < len(line) && match[l] == match[k] && group[l] == group[k] {
+			l++
+		}
+		// Don't split UTF-8 sequences.
+		for l < len(line) && !utf8Start(line[l]) {
+			l++
+		}
+		segs = append(segs, segment{Text: line[k:l], Match: match[k], Group: group[k]})
+		k = l
+	}
+	return segs
+}
+
+func utf8Start(b byte) bool {
+	return b&0xc0 != 0x80
+}
+
+// Flags are the regexp flags toggled by the tester.
+type Flags struct {
+	IgnoreCase, Multiline, DotAll bool
+}
+
+// compile compiles pattern with the flags set.
+func compile(pattern string, f Flags) (*regexp.Regexp, error) {
+	var flags strings.Builder
+	if f.IgnoreCase {
+		flags.WriteString("i")
+	}
+	if f.Multiline {
+		flags.WriteString("m")
+	}
+	if f.DotAll {
+		flags.WriteString("s")
+	}
+	if flags.Len() > 0 {
+		pattern = "(?" + flags.String() + ")" + pattern
+	}
+	return regexp.Compile(pattern)
+}
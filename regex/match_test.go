@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSegments(t *testing.T) {
+	re, err := compile(`(\w+)@(\w+)\.com`, Flags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := run(re, "mail ann@example.com\nor bob@test.com now\n")
+	if len(r.Matches) != 2 || len(r.Lines) != 3 {
+		t.Fatalf("got %d matches and %d lines", len(r.Matches), len(r.Lines))
+	}
+	got := r.Segments(1)
+	want := []segment{
+		{"or ", -1, 0},
+		{"bob", 1, 1},
+		{"@", 1, 0},
+		{"test", 1, 2},
+		{".com", 1, 0},
+		{" now", -1, 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if s := r.Segments(2); len(s) != 0 {
+		t.Errorf("got %q for the empty last line", s)
+	}
+}
+
+func TestSegmentsAcrossLines(t *testing.T) {
+	re, _ := compile(`b.c`, Flags{DotAll: true})
+	r := run(re, "ab\ncd")
+	want := [][]segment{
+		{{"a", -1, 0}, {"b", 0, 0}},
+		{{"c", 0, 0}, {"d", -1, 0}},
+	}
+	for i, w := range want {
+		if got := r.Segments(i); !reflect.DeepEqual(got, w) {
+			t.Errorf("line %d: got %q, want %q", i, got, w)
+		}
+	}
+}
+
+func TestSegmentsUTF8(t *testing.T) {
+	re, _ := compile(`é`, Flags{IgnoreCase: true})
+	r := run(re, "cafÉ")
+	want := []segment{{"caf", -1, 0}, {"É", 0, 0}}
+	if got := r.Segments(0); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,455 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"strings"
+)
+
+// Format is a barcode symbology.
+type Format int
+
+const (
+	EAN13 Format = iota
+	Code128
+)
+
+func (f Format) String() string {
+	switch f {
+	case EAN13:
+		return "EAN-13"
+	case Code128:
+		return "Code 128"
+	default:
+		panic("invalid format")
+	}
+}
+
+// Barcode is a barcode found in an image.
+type Barcode struct {
+	Format Format
+	Text   string
+	// Line is a horizontal segment across the bars, where the barcode
+	// was read.
+	Line [2]image.Point
+}
+
+// scanStep is the distance between the rows scanned for barcodes.
+const scanStep = 6
+
+// Scan finds the barcodes in img by decoding its rows. Barcodes must be
+// about horizontal, either way up, and are reported when read from at
+// least two rows.
+func Scan(img *image.Gray) []Barcode {
+	type found struct {
+		Barcode
+		lines [][2]image.Point
+	}
+	seen := make(map[string]*found)
+	var order []string
+	b := img.Bounds()
+	var dark []bool
+	for y := b.Min.Y; y < b.Max.Y; y += scanStep {
+		row := img.Pix[img.PixOffset(b.Min.X, y):img.PixOffset(b.Max.X, y)]
+		dark = binarize(dark[:0], row)
+		for _, r := range decodeRow(dark) {
+			r.Line[0].Y, r.Line[1].Y = y, y
+			r.Line[0].X += b.Min.X
+			r.Line[1].X += b.Min.X
+			key := r.Format.String() + ":" + r.Text
+			f, ok := seen[key]
+			if !ok {
+				f = &found{Barcode: r}
+				seen[key] = f
+				order = append(order, key)
+			}
+			f.lines = append(f.lines, r.Line)
+		}
+	}
+	var codes []Barcode
+	for _, k := range order {
+		if f := seen[k]; len(f.lines) >= 2 {
+			// Report the middle row read.
+			f.Line = f.lines[len(f.lines)/2]
+			codes = append(codes, f.Barcode)
+		}
+	}
+	return codes
+}
+
+// binarize appends whether the pixels of row are dark, compared to the
+// mean of their neighborhood, for lighting varying across the image.
+func binarize(dark []bool, row []uint8) []bool {
+	n := len(row)
+	sum := make([]int, n+1)
+	for i, v := range row {
+		sum[i+1] = sum[i] + int(v)
+	}
+	r := n / 16
+	if r < 8 {
+		r = 8
+	}
+	for i, v := range row {
+		lo, hi := i-r, i+r+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > n {
+			hi = n
+		}
+		mean := (sum[hi] - sum[lo]) / (hi - lo)
+		dark = append(dark, int(v) < mean-4)
+	}
+	return dark
+}
+
+// runs returns the lengths of the runs of equal pixels, starting with a
+// light run, and the start of every run.
+func runs(dark []bool) (lengths, starts []int) {
+	cur := false
+	n := 0
+	start := 0
+	for i, d := range dark {
+		if d != cur {
+			lengths = append(lengths, n)
+			starts = append(starts, start)
+			cur, n, start = d, 0, i
+		}
+		n++
+	}
+	lengths = append(lengths, n)
+	starts = append(starts, start)
+	return lengths, starts
+}
+
+// decodeRow decodes the barcodes of a binarized row, in both directions.
+func decodeRow(dark []bool) []Barcode {
+	codes := decodeRuns(dark)
+	rev := make([]bool, len(dark))
+	for i, d := range dark {
+		rev[len(dark)-1-i] = d
+	}
+	for _, c := range decodeRuns(rev) {
+		c.Line[0].X, c.Line[1].X = len(dark)-c.Line[1].X, len(dark)-c.Line[0].X
+		codes = append(codes, c)
+	}
+	return codes
+}
+
+func decodeRuns(dark []bool) []Barcode {
+	lengths, starts := runs(dark)
+	var codes []Barcode
+	// Bars are the odd runs; the light run before must be a quiet zone
+	// wider than the bars.
+	for i := 1; i < len(lengths); i += 2 {
+		if text, n, ok := decodeEAN13(lengths[i:]); ok && quiet(lengths, i) {
+			codes = append(codes, Barcode{Format: EAN13, Text: text, Line: segment(starts, lengths, i, n)})
+			i += n - 1
+			continue
+		}
+		if text, n, ok := decodeCode128(lengths[i:]); ok && quiet(lengths, i) {
+			codes = append(codes, Barcode{Format: Code128, Text: text, Line: segment(starts, lengths, i, n)})
+			i += n - 1
+		}
+	}
+	return codes
+}
+
+// quiet reports whether the light run before run i is at least as wide
+// as the three runs after it.
+func quiet(lengths []int, i int) bool {
+	return i+3 <= len(lengths) && lengths[i-1] >= lengths[i]+lengths[i+1]+lengths[i+2]
+}
+
+func segment(starts, lengths []int, i, n int) [2]image.Point {
+	last := i + n - 1
+	return [2]image.Point{{X: starts[i]}, {X: starts[last] + lengths[last]}}
+}
+
+// match returns the index of the pattern of modules closest to the run
+// lengths, and whether it is close enough.
+func match(lengths []int, patterns [][]int, modules int) (int, bool) {
+	total := 0
+	for _, l := range lengths {
+		total += l
+	}
+	if total < modules {
+		// Narrower than a pixel per module.
+		return 0, false
+	}
+	best, bestErr := -1, 0.0
+	for p, pat := range patterns {
+		var err float64
+		for j, l := range lengths {
+			d := float64(l*modules)/float64(total) - float64(pat[j])
+			if d < 0 {
+				d = -d
+			}
+			err += d
+		}
+		if best == -1 || err < bestErr {
+			best, bestErr = p, err
+		}
+	}
+	return best, bestErr < 0.4*float64(len(lengths))
+}
+
+// widths returns the run lengths of a pattern of modules, such as
+// "0001101", with 1 for dark modules.
+func widths(modules string) []int {
+	var w []int
+	for i := 0; i < len(modules); i++ {
+		if i == 0 || modules[i] != modules[i-1] {
+			w = append(w, 0)
+		}
+		w[len(w)-1]++
+	}
+	return w
+}
+
+// The digits of EAN-13, as the modules of the left half of odd parity.
+var eanL = []string{"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011"}
+
+// eanFirst is the parity of the digits of the left half, odd or even,
+// that encodes the first digit.
+var eanFirst = []string{"OOOOOO", "OOEOEE", "OOEEOE", "OOEEEO", "OEOOEE", "OEEOOE", "OEEEOE", "OEEEEO", "OEOEEO", "OEOEOE"}
+
+// eanLeft and eanRight are the run lengths of the digits, beginning with
+// a light run on the left and a dark one on the right: 0-9 of odd and
+// 10-19 of even parity on the left, 0-9 on the right.
+var eanLeft, eanRight = eanPatterns()
+
+func eanPatterns() (left, right [][]int) {
+	for _, l := range eanL {
+		left = append(left, widths(l))
+	}
+	for _, l := range eanL {
+		// The even parity digits are the odd ones mirrored and inverted,
+		// which is the mirrored runs.
+		w := widths(l)
+		for i, j := 0, len(w)-1; i < j; i, j = i+1, j-1 {
+			w[i], w[j] = w[j], w[i]
+		}
+		left = append(left, w)
+		// The right digits are the odd ones inverted, the same runs.
+		right = append(right, widths(l))
+	}
+	return left, right
+}
+
+// decodeEAN13 decodes an EAN-13 starting with the run lengths, from the
+// first bar of the start guard, and returns its digits and the number of
+// runs.
+func decodeEAN13(lengths []int) (string, int, bool) {
+	// Start guard, 6 digits, middle guard, 6 digits and end guard.
+	const n = 3 + 6*4 + 5 + 6*4 + 3
+	if len(lengths) < n {
+		return "", 0, false
+	}
+	total := 0
+	for _, l := range lengths[:n] {
+		total += l
+	}
+	module := float64(total) / 95
+	guard := func(runs []int) bool {
+		for _, l := range runs {
+			if float64(l) < module*0.5 || float64(l) > module*1.6 {
+				return false
+			}
+		}
+		return true
+	}
+	if !guard(lengths[0:3]) || !guard(lengths[27:32]) || !guard(lengths[56:59]) {
+		return "", 0, false
+	}
+	var digits [13]byte
+	var parity [6]byte
+	for i := 0; i < 6; i++ {
+		d, ok := match(lengths[3+i*4:7+i*4], eanLeft, 7)
+		if !ok {
+			return "", 0, false
+		}
+		parity[i] = 'O'
+		if d >= 10 {
+			parity[i] = 'E'
+			d -= 10
+		}
+		digits[1+i] = byte('0' + d)
+	}
+	for i := 0; i < 6; i++ {
+		d, ok := match(lengths[32+i*4:36+i*4], eanRight, 7)
+		if !ok {
+			return "", 0, false
+		}
+		digits[7+i] = byte('0' + d)
+	}
+	first := -1
+	for d, p := range eanFirst {
+		if p == string(parity[:]) {
+			first = d
+		}
+	}
+	if first == -1 {
+		return "", 0, false
+	}
+	digits[0] = byte('0' + first)
+	if eanCheck(string(digits[:12])) != digits[12] {
+		return "", 0, false
+	}
+	return string(digits[:]), n, true
+}
+
+// eanCheck returns the check digit of the first 12 digits of an EAN-13.
+func eanCheck(digits string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(digits[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// code128 is the bar and space widths of the Code 128 symbols by value;
+// 103-105 are the start codes of sets A, B and C, and 106 the stop code.
+var code128 = [][]int{
+	{2, 1, 2, 2, 2, 2}, {2, 2, 2, 1, 2, 2}, {2, 2, 2, 2, 2, 1}, {1, 2, 1, 2, 2, 3}, {1, 2, 1, 3, 2, 2},
+	{1, 3, 1, 2, 2, 2}, {1, 2, 2, 2, 1, 3}, {1, 2, 2, 3, 1, 2}, {1, 3, 2, 2, 1, 2}, {2, 2, 1, 2, 1, 3},
+	{2, 2, 1, 3, 1, 2}, {2, 3, 1, 2, 1, 2}, {1, 1, 2, 2, 3, 2}, {1, 2, 2, 1, 3, 2}, {1, 2, 2, 2, 3, 1},
+	{1, 1, 3, 2, 2, 2}, {1, 2, 3, 1, 2, 2}, {1, 2, 3, 2, 2, 1}, {2, 2, 3, 2, 1, 1}, {2, 2, 1, 1, 3, 2},
+	{2, 2, 1, 2, 3, 1}, {2, 1, 3, 2, 1, 2}, {2, 2, 3, 1, 1, 2}, {3, 1, 2, 1, 3, 1}, {3, 1, 1, 2, 2, 2},
+	{3, 2, 1, 1, 2, 2}, {3, 2, 1, 2, 2, 1}, {3, 1, 2, 2, 1, 2}, {3, 2, 2, 1, 1, 2}, {3, 2, 2, 2, 1, 1},
+	{2, 1, 2, 1, 2, 3}, {2, 1, 2, 3, 2, 1}, {2, 3, 2, 1, 2, 1}, {1, 1, 1, 3, 2, 3}, {1, 3, 1, 1, 2, 3},
+	{1, 3, 1, 3, 2, 1}, {1, 1, 2, 3, 1, 3}, {1, 3, 2, 1, 1, 3}, {1, 3, 2, 3, 1, 1}, {2, 1, 1, 3, 1, 3},
+	{2, 3, 1, 1, 1, 3}, {2, 3, 1, 3, 1, 1}, {1, 1, 2, 1, 3, 3}, {1, 1, 2, 3, 3, 1}, {1, 3, 2, 1, 3, 1},
+	{1, 1, 3, 1, 2, 3}, {1, 1, 3, 3, 2, 1}, {1, 3, 3, 1, 2, 1}, {3, 1, 3, 1, 2, 1}, {2, 1, 1, 3, 3, 1},
+	{2, 3, 1, 1, 3, 1}, {2, 1, 3, 1, 1, 3}, {2, 1, 3, 3, 1, 1}, {2, 1, 3, 1, 3, 1}, {3, 1, 1, 1, 2, 3},
+	{3, 1, 1, 3, 2, 1}, {3, 3, 1, 1, 2, 1}, {3, 1, 2, 1, 1, 3}, {3, 1, 2, 3, 1, 1}, {3, 3, 2, 1, 1, 1},
+	{3, 1, 4, 1, 1, 1}, {2, 2, 1, 4, 1, 1}, {4, 3, 1, 1, 1, 1}, {1, 1, 1, 2, 2, 4}, {1, 1, 1, 4, 2, 2},
+	{1, 2, 1, 1, 2, 4}, {1, 2, 1, 4, 2, 1}, {1, 4, 1, 1, 2, 2}, {1, 4, 1, 2, 2, 1}, {1, 1, 2, 2, 1, 4},
+	{1, 1, 2, 4, 1, 2}, {1, 2, 2, 1, 1, 4}, {1, 2, 2, 4, 1, 1}, {1, 4, 2, 1, 1, 2}, {1, 4, 2, 2, 1, 1},
+	{2, 4, 1, 2, 1, 1}, {2, 2, 1, 1, 1, 4}, {4, 1, 3, 1, 1, 1}, {2, 4, 1, 1, 1, 2}, {1, 3, 4, 1, 1, 1},
+	{1, 1, 1, 2, 4, 2}, {1, 2, 1, 1, 4, 2}, {1, 2, 1, 2, 4, 1}, {1, 1, 4, 2, 1, 2}, {1, 2, 4, 1, 1, 2},
+	{1, 2, 4, 2, 1, 1}, {4, 1, 1, 2, 1, 2}, {4, 2, 1, 1, 1, 2}, {4, 2, 1, 2, 1, 1}, {2, 1, 2, 1, 4, 1},
+	{2, 1, 4, 1, 2, 1}, {4, 1, 2, 1, 2, 1}, {1, 1, 1, 1, 4, 3}, {1, 1, 1, 3, 4, 1}, {1, 3, 1, 1, 4, 1},
+	{1, 1, 4, 1, 1, 3}, {1, 1, 4, 3, 1, 1}, {4, 1, 1, 1, 1, 3}, {4, 1, 1, 3, 1, 1}, {1, 1, 3, 1, 4, 1},
+	{1, 1, 4, 1, 3, 1}, {3, 1, 1, 1, 4, 1}, {4, 1, 1, 1, 3, 1}, {2, 1, 1, 4, 1, 2}, {2, 1, 1, 2, 1, 4},
+	{2, 1, 1, 2, 3, 2}, {2, 3, 3, 1, 1, 1, 2},
+}
+
+// The special values of Code 128.
+const (
+	c128Shift  = 98
+	c128CodeC  = 99
+	c128CodeB  = 100
+	c128CodeA  = 101
+	c128StartA = 103
+	c128StartC = 105
+	c128Stop   = 106
+)
+
+// decodeCode128 decodes a Code 128 starting with the run lengths, from
+// the first bar of the start code, and returns its text and the number
+// of runs.
+func decodeCode128(lengths []int) (string, int, bool) {
+	symbols := code128[:c128Stop]
+	if len(lengths) < 6 {
+		return "", 0, false
+	}
+	start, ok := match(lengths[:6], symbols, 11)
+	if !ok || start < c128StartA {
+		return "", 0, false
+	}
+	values := []int{start}
+	i := 6
+	for {
+		if i+7 <= len(lengths) && stop128(lengths[i:]) {
+			i += 7
+			break
+		}
+		if i+6 > len(lengths) || len(values) > 80 {
+			return "", 0, false
+		}
+		v, ok := match(lengths[i:i+6], symbols, 11)
+		if !ok || v >= c128StartA {
+			return "", 0, false
+		}
+		values = append(values, v)
+		i += 6
+	}
+	// The start code, at least a symbol and the check symbol.
+	if len(values) < 3 {
+		return "", 0, false
+	}
+	check := values[len(values)-1]
+	values = values[:len(values)-1]
+	sum := values[0]
+	for j, v := range values[1:] {
+		sum += (j + 1) * v
+	}
+	if sum%103 != check {
+		return "", 0, false
+	}
+	text, ok := code128Text(values)
+	return text, i, ok
+}
+
+// stop128 reports whether the run lengths begin with the stop code,
+// followed by the end of the row or a quiet zone. The quiet zone tells
+// the stop code from a symbol and the first bar of the next.
+func stop128(lengths []int) bool {
+	if _, ok := match(lengths[:7], code128[c128Stop:], 13); !ok {
+		return false
+	}
+	if len(lengths) == 7 {
+		return true
+	}
+	total := 0
+	for _, l := range lengths[:7] {
+		total += l
+	}
+	return lengths[7]*13 >= total*5
+}
+
+// code128Text returns the text of the values of a Code 128, beginning
+// with the start code.
+func code128Text(values []int) (string, bool) {
+	var sb strings.Builder
+	set := values[0] - c128StartA
+	const (
+		setA = iota
+		setB
+		setC
+	)
+	shift := false
+	for _, v := range values[1:] {
+		cur := set
+		if shift {
+			cur = setA + setB - set
+			shift = false
+		}
+		switch {
+		case cur == setC && v < 100:
+			sb.WriteByte(byte('0' + v/10))
+			sb.WriteByte(byte('0' + v%10))
+		case v == c128CodeC:
+			set = setC
+		case v == c128CodeB && cur != setB:
+			set = setB
+		case v == c128CodeA && cur != setA:
+			set = setA
+		case v == c128Shift:
+			shift = true
+		case v >= 96:
+			// Function codes carry no text.
+		case cur == setA && v >= 64:
+			sb.WriteByte(byte(v - 64))
+		default:
+			sb.WriteByte(byte(v + 32))
+		}
+	}
+	return sb.String(), sb.Len() > 0
+}
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+func TestCode128Table(t *testing.T) {
+	if len(code128) != 107 {
+		t.Fatalf("%d symbols, want 107", len(code128))
+	}
+	seen := make(map[[7]int]int)
+	for v, w := range code128 {
+		modules := 11
+		if v == c128Stop {
+			modules = 13
+		}
+		sum := 0
+		for _, n := range w {
+			sum += n
+		}
+		if sum != modules {
+			t.Errorf("symbol %d is %d modules wide, want %d", v, sum, modules)
+		}
+		var k [7]int
+		copy(k[:], w)
+		if prev, ok := seen[k]; ok {
+			t.Errorf("symbols %d and %d are the same", prev, v)
+		}
+		seen[k] = v
+	}
+}
+
+// barsImage draws runs starting with a bar into an image, leaving quiet
+// zones around them.
+func barsImage(runs []int, module int, flip bool) *image.Gray {
+	total := 0
+	for _, n := range runs {
+		total += n
+	}
+	quiet := 12 * module
+	img := image.NewGray(image.Rect(0, 0, total*module+2*quiet, 40))
+	for i := range img.Pix {
+		img.Pix[i] = 0xe0
+	}
+	drawBars(img, image.Pt(quiet, 5), module, 30, runs)
+	if flip {
+		for y := 0; y < img.Rect.Dy(); y++ {
+			row := img.Pix[y*img.Stride : y*img.Stride+img.Rect.Dx()]
+			for i, j := 0, len(row)-1; i < j; i, j = i+1, j-1 {
+				row[i], row[j] = row[j], row[i]
+			}
+		}
+	}
+	return img
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		format Format
+		text   string
+		runs   []int
+	}{
+		{EAN13, "4006381333931", widths(encodeEAN13("400638133393"))},
+		{EAN13, "9780201379624", widths(encodeEAN13("978020137962"))},
+		{Code128, "Gio 0.1!", encodeCode128("Gio 0.1!")},
+		{Code128, "0123456789", encodeCode128("0123456789")},
+	}
+	for _, test := range tests {
+		for _, module := range []int{1, 2, 3} {
+			for _, flip := range []bool{false, true} {
+				img := barsImage(test.runs, module, flip)
+				codes := Scan(img)
+				if len(codes) != 1 || codes[0].Format != test.format || codes[0].Text != test.text {
+					t.Errorf("%s %q, module %d, flipped %v: scanned %+v", test.format, test.text, module, flip, codes)
+					continue
+				}
+				if l := codes[0].Line; l[0].X < 10*module || l[1].X > img.Rect.Dx()-10*module {
+					t.Errorf("%s %q: line %v outside the bars", test.format, test.text, l)
+				}
+			}
+		}
+	}
+}
+
+func TestScanNothing(t *testing.T) {
+	runs := widths(encodeEAN13("400638133393"))
+	// Break a bar of the last digit.
+	runs[len(runs)-5]++
+	runs[len(runs)-4]--
+	if codes := Scan(barsImage(runs, 2, false)); len(codes) != 0 {
+		t.Errorf("scanned %+v from a damaged barcode", codes)
+	}
+}
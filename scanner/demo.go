@@ -0,0 +1,204 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"time"
+
+	"gioui.org/example/internal/camera"
+)
+
+// demoCamera films a sheet of paper with barcodes, moving about on a
+// desk, for trying the scanner without a camera.
+type demoCamera struct {
+	page  *image.Gray
+	size  image.Point
+	start time.Time
+	rnd   *rand.Rand
+	tick  *time.Ticker
+}
+
+// The barcodes printed on the demo sheet.
+const (
+	demoEAN     = "4006381333931"
+	demoCode128 = "GIO-789"
+)
+
+func newDemoCamera(size image.Point) *demoCamera {
+	return &demoCamera{
+		page:  demoPage(),
+		size:  size,
+		start: time.Now(),
+		rnd:   rand.New(rand.NewSource(1)),
+		tick:  time.NewTicker(time.Second / 15),
+	}
+}
+
+func (d *demoCamera) Size() image.Point {
+	return d.size
+}
+
+func (d *demoCamera) Close() error {
+	d.tick.Stop()
+	return nil
+}
+
+// Read renders the sheet at its place at the time of the next frame.
+func (d *demoCamera) Read(f *camera.Frame) error {
+	now := <-d.tick.C
+	t := now.Sub(d.start).Seconds()
+	f.Reset(d.size)
+	f.Time = now
+	w, h := float64(d.size.X), float64(d.size.Y)
+	cx := w/2 + w/16*math.Sin(t*0.5)
+	cy := h/2 + h/20*math.Cos(t*0.4)
+	angle := 0.1 * math.Sin(t*0.3)
+	scale := h * 0.85 / float64(d.page.Rect.Dy())
+	sin, cos := math.Sincos(-angle)
+	pw, ph := float64(d.page.Rect.Dx()), float64(d.page.Rect.Dy())
+	for y := 0; y < d.size.Y; y++ {
+		for x := 0; x < d.size.X; x++ {
+			// Map the pixel back onto the page.
+			dx, dy := float64(x)-cx, float64(y)-cy
+			u := (dx*cos-dy*sin)/scale + pw/2
+			v := (dx*sin+dy*cos)/scale + ph/2
+			noise := d.rnd.Intn(9) - 4
+			var r, g, b int
+			if u >= 0 && v >= 0 && u < pw && v < ph {
+				l := int(d.page.Pix[int(v)*d.page.Stride+int(u)]) + noise
+				r, g, b = l, l, l*96/100
+			} else {
+				// A wooden desk, lit from the top left.
+				l := 100 - int(40*(float64(x)/w+float64(y)/h)/2) + noise
+				r, g, b = l*13/10, l, l*7/10
+			}
+			p := f.RGBA.Pix[y*f.RGBA.Stride+x*4:]
+			p[0], p[1], p[2], p[3] = clampByte(r), clampByte(g), clampByte(b), 0xff
+			// The luma of BT.601.
+			f.Gray.Pix[y*f.Gray.Stride+x] = clampByte((299*r + 587*g + 114*b) / 1000)
+		}
+	}
+	return nil
+}
+
+func clampByte(v int) uint8 {
+	switch {
+	case v < 0:
+		return 0
+	case v > 0xff:
+		return 0xff
+	default:
+		return uint8(v)
+	}
+}
+
+// demoPage draws a sheet of paper with lines of text and two barcodes.
+func demoPage() *image.Gray {
+	const w, h = 420, 560
+	page := image.NewGray(image.Rect(0, 0, w, h))
+	fill := func(r image.Rectangle, l uint8) {
+		r = r.Intersect(page.Rect)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				page.Pix[y*page.Stride+x] = l
+			}
+		}
+	}
+	fill(page.Rect, 0xf0)
+	// A heading and paragraphs, as gray bars.
+	fill(image.Rect(40, 40, 260, 58), 0x40)
+	rnd := rand.New(rand.NewSource(2))
+	for _, y := range []int{80, 92, 104, 116, 140, 152, 164, 440, 452, 464, 476, 500, 512} {
+		fill(image.Rect(40, y, 40+200+rnd.Intn(140), y+6), 0x90)
+	}
+	drawBars(page, image.Pt(70, 200), 3, 90, widths(encodeEAN13(demoEAN[:12])))
+	drawBars(page, image.Pt(25, 320), 3, 80, encodeCode128(demoCode128))
+	return page
+}
+
+// drawBars draws the bars of run lengths starting with a bar, in modules
+// of module pixels.
+func drawBars(img *image.Gray, at image.Point, module, height int, runs []int) {
+	x := at.X
+	for i, n := range runs {
+		if i%2 == 0 {
+			for y := at.Y; y < at.Y+height; y++ {
+				for dx := 0; dx < n*module; dx++ {
+					img.Pix[y*img.Stride+x+dx] = 0x18
+				}
+			}
+		}
+		x += n * module
+	}
+}
+
+// encodeEAN13 returns the modules of the EAN-13 of 12 digits, followed
+// by their check digit.
+func encodeEAN13(digits string) string {
+	digits += string(eanCheck(digits))
+	parity := eanFirst[digits[0]-'0']
+	s := "101"
+	for i := 1; i <= 6; i++ {
+		l := eanL[digits[i]-'0']
+		if parity[i-1] == 'E' {
+			// Mirrored and inverted.
+			l = reverse(invert(l))
+		}
+		s += l
+	}
+	s += "01010"
+	for i := 7; i <= 12; i++ {
+		s += invert(eanL[digits[i]-'0'])
+	}
+	return s + "101"
+}
+
+func invert(modules string) string {
+	b := []byte(modules)
+	for i, c := range b {
+		b[i] = '0' + '1' - c
+	}
+	return string(b)
+}
+
+func reverse(modules string) string {
+	b := []byte(modules)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
+
+// encodeCode128 returns the bar and space widths of text as a Code 128,
+// in set C for an even number of digits and set B otherwise.
+func encodeCode128(text string) []int {
+	var values []int
+	digits := len(text)%2 == 0
+	for _, c := range text {
+		digits = digits && c >= '0' && c <= '9'
+	}
+	if digits {
+		values = append(values, c128StartC)
+		for i := 0; i < len(text); i += 2 {
+			values = append(values, int(text[i]-'0')*10+int(text[i+1]-'0'))
+		}
+	} else {
+		values = append(values, c128StartA+1)
+		for i := 0; i < len(text); i++ {
+			values = append(values, int(text[i])-32)
+		}
+	}
+	sum := values[0]
+	for i, v := range values[1:] {
+		sum += (i + 1) * v
+	}
+	values = append(values, sum%103, c128Stop)
+	var runs []int
+	for _, v := range values {
+		runs = append(runs, code128[v]...)
+	}
+	return runs
+}
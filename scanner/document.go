@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+)
+
+// Document is a sheet of paper found in an image, by its corners: top
+// left, top right, bottom right and bottom left.
+type Document [4]image.Point
+
+// detectWidth is the width images are reduced to for finding documents.
+const detectWidth = 160
+
+// FindDocument finds the largest bright quadrilateral in img, such as a
+// sheet of paper on a darker desk. The image is reduced and split into
+// bright and dark by the threshold best separating its pixels; the
+// largest bright region is a document when it fills its corners' outline
+// and a good part of the image.
+func FindDocument(img *image.Gray) (Document, bool) {
+	small, scale := reduce(img, detectWidth)
+	w, h := small.Rect.Dx(), small.Rect.Dy()
+	if w < 8 || h < 8 {
+		return Document{}, false
+	}
+	t, contrast := otsu(small.Pix)
+	if contrast < 40 {
+		return Document{}, false
+	}
+	// Find the largest connected region of bright pixels.
+	visited := make([]bool, w*h)
+	var best []int
+	stack := make([]int, 0, 256)
+	for start := range small.Pix {
+		if visited[start] || small.Pix[start] <= t {
+			continue
+		}
+		var region []int
+		stack = append(stack[:0], start)
+		visited[start] = true
+		for len(stack) > 0 {
+			p := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			region = append(region, p)
+			x, y := p%w, p/w
+			for _, n := range [4][2]int{{x - 1, y}, {x + 1, y}, {x, y - 1}, {x, y + 1}} {
+				if n[0] < 0 || n[0] >= w || n[1] < 0 || n[1] >= h {
+					continue
+				}
+				q := n[1]*w + n[0]
+				if !visited[q] && small.Pix[q] > t {
+					visited[q] = true
+					stack = append(stack, q)
+				}
+			}
+		}
+		if len(region) > len(best) {
+			best = region
+		}
+	}
+	if len(best) < w*h/10 {
+		return Document{}, false
+	}
+	// The corners are the extremes along the diagonals.
+	var d Document
+	var ext [4]int
+	for i, p := range best {
+		x, y := p%w, p/w
+		vals := [4]int{-x - y, x - y, x + y, y - x}
+		for c, v := range vals {
+			if i == 0 || v > ext[c] {
+				ext[c] = v
+				d[c] = image.Pt(x, y)
+			}
+		}
+	}
+	// The print on the paper leaves holes in the region, but most of the
+	// outline must be bright.
+	if quadArea(d) == 0 || float64(len(best)) < 0.8*quadArea(d) {
+		return Document{}, false
+	}
+	for i := range d {
+		d[i] = image.Pt(d[i].X*scale+scale/2, d[i].Y*scale+scale/2).Add(img.Rect.Min)
+	}
+	return d, true
+}
+
+// reduce returns img reduced by an integer factor to at most width
+// pixels wide, averaging the pixels of each block.
+func reduce(img *image.Gray, width int) (*image.Gray, int) {
+	b := img.Bounds()
+	scale := (b.Dx() + width - 1) / width
+	if scale < 1 {
+		scale = 1
+	}
+	w, h := b.Dx()/scale, b.Dy()/scale
+	small := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sum := 0
+			for dy := 0; dy < scale; dy++ {
+				row := img.Pix[img.PixOffset(b.Min.X+x*scale, b.Min.Y+y*scale+dy):]
+				for dx := 0; dx < scale; dx++ {
+					sum += int(row[dx])
+				}
+			}
+			small.Pix[y*small.Stride+x] = uint8(sum / (scale * scale))
+		}
+	}
+	return small, scale
+}
+
+// otsu returns the threshold that best separates pix into two classes,
+// maximizing the variance between them, and the difference of the means
+// of the classes.
+func otsu(pix []uint8) (uint8, float64) {
+	var hist [256]int
+	for _, v := range pix {
+		hist[v]++
+	}
+	total := len(pix)
+	sum := 0
+	for v, n := range hist {
+		sum += v * n
+	}
+	var best uint8
+	var bestVar, contrast float64
+	sumB, wB := 0, 0
+	for t, n := range hist {
+		wB += n
+		if wB == 0 {
+			continue
+		}
+		wF := total - wB
+		if wF == 0 {
+			break
+		}
+		sumB += t * n
+		mB := float64(sumB) / float64(wB)
+		mF := float64(sum-sumB) / float64(wF)
+		v := float64(wB) * float64(wF) * (mB - mF) * (mB - mF)
+		if v > bestVar {
+			best, bestVar, contrast = uint8(t), v, mF-mB
+		}
+	}
+	return best, contrast
+}
+
+// quadArea returns the area of a quadrilateral by the shoelace formula.
+func quadArea(d Document) float64 {
+	a := 0
+	for i := range d {
+		p, q := d[i], d[(i+1)%len(d)]
+		a += p.X*q.Y - q.X*p.Y
+	}
+	if a < 0 {
+		a = -a
+	}
+	return float64(a) / 2
+}
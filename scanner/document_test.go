@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"testing"
+
+	"gioui.org/example/internal/camera"
+)
+
+func TestFindDocument(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 640, 480))
+	for i := range img.Pix {
+		img.Pix[i] = 0x50
+	}
+	sheet := image.Rect(200, 60, 440, 400)
+	for y := sheet.Min.Y; y < sheet.Max.Y; y++ {
+		for x := sheet.Min.X; x < sheet.Max.X; x++ {
+			img.Pix[y*img.Stride+x] = 0xf0
+		}
+	}
+	d, ok := FindDocument(img)
+	if !ok {
+		t.Fatal("no document found")
+	}
+	want := Document{sheet.Min, {sheet.Max.X, sheet.Min.Y}, sheet.Max, {sheet.Min.X, sheet.Max.Y}}
+	for i := range d {
+		if diff := d[i].Sub(want[i]); abs(diff.X) > 8 || abs(diff.Y) > 8 {
+			t.Errorf("corner %d at %v, want %v", i, d[i], want[i])
+		}
+	}
+
+	for i := range img.Pix {
+		img.Pix[i] = 0x50
+	}
+	if d, ok := FindDocument(img); ok {
+		t.Errorf("found document %v in an empty image", d)
+	}
+}
+
+func TestDemoCamera(t *testing.T) {
+	cam := newDemoCamera(image.Pt(640, 480))
+	defer cam.Close()
+	var f camera.Frame
+	if err := cam.Read(&f); err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[string]bool)
+	for _, c := range Scan(f.Gray) {
+		found[c.Text] = true
+	}
+	if !found[demoEAN] || !found[demoCode128] {
+		t.Errorf("scanned %v, want %s and %s", found, demoEAN, demoCode128)
+	}
+	d, ok := FindDocument(f.Gray)
+	if !ok {
+		t.Fatal("no document found")
+	}
+	page := Straighten(f.RGBA, d)
+	// The sheet is 3:4.
+	sz := page.Rect.Size()
+	if r := float64(sz.X) / float64(sz.Y); r < 0.7 || r > 0.8 {
+		t.Errorf("straightened sheet is %v, want 3:4", sz)
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
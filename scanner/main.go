@@ -0,0 +1,439 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program scans EAN-13 and Code 128 barcodes and documents from
+// the live feed of a camera. The barcodes read and the outline of a
+// sheet of paper are drawn over the feed; the barcodes are listed as they
+// are read, and Scan document saves the sheet straightened to a PNG file.
+//
+// Frames are captured and analysed in the background, and the user
+// interface shows the latest, dropping frames when it falls behind.
+// Capture is supported with Video4Linux; elsewhere, or without a camera,
+// a simulated camera films a sheet with barcodes.
+//
+// Usage:
+//
+//	go run ./scanner [-device /dev/video0] [-demo] [-o directory]
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/camera"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/clipboard"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+var (
+	deviceFlag = flag.String("device", "", "video capture device (default the first)")
+	demoFlag   = flag.Bool("demo", false, "use a simulated camera")
+	outFlag    = flag.String("o", ".", "directory for the scanned documents")
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	errorColor    = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	documentColor = color.NRGBA{R: 0x43, G: 0xa0, B: 0x47, A: 0xff}
+	barcodeColor  = color.NRGBA{R: 0xff, G: 0x8f, B: 0x00, A: 0xff}
+)
+
+// frameSize is the size of frames asked of cameras.
+var frameSize = image.Pt(640, 480)
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Scanner"),
+			app.Size(unit.Dp(1040), unit.Dp(600)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// source is a camera, real or simulated.
+type source interface {
+	Size() image.Point
+	Read(f *camera.Frame) error
+	Close() error
+}
+
+// openSource opens the camera of the flags, falling back to the demo
+// camera. It returns a description of the source.
+func openSource() (source, string, error) {
+	if *demoFlag {
+		return newDemoCamera(frameSize), "Simulated camera", nil
+	}
+	dev := *deviceFlag
+	if dev == "" {
+		devs := camera.Devices()
+		if len(devs) == 0 {
+			return newDemoCamera(frameSize), "No camera found; showing a simulated camera", nil
+		}
+		dev = devs[0]
+	}
+	cam, err := camera.Open(dev, frameSize)
+	if err != nil {
+		return newDemoCamera(frameSize), "Simulated camera", err
+	}
+	sz := cam.Size()
+	return cam, fmt.Sprintf("%s, %d×%d", dev, sz.X, sz.Y), nil
+}
+
+// analysis is a frame and what was found in it.
+type analysis struct {
+	frame  *camera.Frame
+	codes  []Barcode
+	doc    Document
+	hasDoc bool
+	err    error
+}
+
+// capture reads and analyses frames until stop is closed, keeping the
+// latest analysis in out and calling changed for each.
+func capture(src source, out chan *analysis, changed func(), stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		// A new frame every time, for the user interface uploads the
+		// image of the last frame while the next is captured.
+		f := new(camera.Frame)
+		a := &analysis{frame: f}
+		if a.err = src.Read(f); a.err == nil {
+			a.codes = Scan(f.Gray)
+			a.doc, a.hasDoc = FindDocument(f.Gray)
+		}
+		// Replace an analysis not yet shown.
+		select {
+		case <-out:
+		default:
+		}
+		out <- a
+		changed()
+		if a.err != nil {
+			return
+		}
+	}
+}
+
+// result is an entry in the list of results: a barcode or a scanned
+// document.
+type result struct {
+	code  *Barcode
+	count int
+	// page is the scanned document, saved to path.
+	page *paint.ImageOp
+	path string
+	time time.Time
+
+	copy widget.Clickable
+}
+
+type App struct {
+	source string
+	// err is the last error of capturing or saving.
+	err error
+
+	latest *analysis
+	img    paint.ImageOp
+
+	results []*result
+	scan    widget.Clickable
+	list    layout.List
+	scans   int
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	src, desc, err := openSource()
+	defer src.Close()
+	a := &App{
+		source: desc,
+		err:    err,
+		list:   layout.List{Axis: layout.Vertical},
+	}
+	frames := make(chan *analysis, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	go capture(src, frames, w.Invalidate, stop)
+	var ops op.Ops
+	for {
+		select {
+		case f := <-frames:
+			a.show(f)
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update()
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+// show makes f the frame shown, and adds its barcodes to the results.
+func (a *App) show(f *analysis) {
+	if f.err != nil {
+		a.err = f.err
+		return
+	}
+	a.latest = f
+	a.img = paint.NewImageOp(f.frame.RGBA)
+	for _, c := range f.codes {
+		c := c
+		var r *result
+		for i, old := range a.results {
+			if old.code != nil && old.code.Format == c.Format && old.code.Text == c.Text {
+				// Seen before; move it to the top.
+				r = old
+				a.results = append(a.results[:i], a.results[i+1:]...)
+				break
+			}
+		}
+		if r == nil {
+			r = &result{code: &c}
+		}
+		r.count++
+		r.time = f.frame.Time
+		a.results = append([]*result{r}, a.results...)
+	}
+}
+
+func (a *App) update() {
+	if a.scan.Clicked() && a.latest != nil && a.latest.hasDoc {
+		if err := a.scanDocument(); err != nil {
+			a.err = err
+		}
+	}
+}
+
+// scanDocument saves the document of the latest frame, straightened.
+func (a *App) scanDocument() error {
+	page := Straighten(a.latest.frame.RGBA, a.latest.doc)
+	a.scans++
+	path := filepath.Join(*outFlag, fmt.Sprintf("scan-%d.png", a.scans))
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, page); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	img := paint.NewImageOp(page)
+	a.results = append([]*result{{page: &img, path: path, time: time.Now()}}, a.results...)
+	return nil
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	return layout.Flex{}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return a.layoutFeed(gtx, th)
+		}),
+		layout.Rigid(func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Px(unit.Dp(320))
+			gtx.Constraints.Max.X = gtx.Constraints.Min.X
+			return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+				return a.layoutPanel(gtx, th)
+			})
+		}),
+	)
+}
+
+// layoutFeed draws the latest frame scaled to fit, with the document and
+// barcodes found drawn over it.
+func (a *App) layoutFeed(gtx C, th *material.Theme) D {
+	size := gtx.Constraints.Max
+	paint.FillShape(gtx.Ops, color.NRGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xff}, clip.Rect{Max: size}.Op())
+	f := a.latest
+	if f == nil {
+		return layout.Center.Layout(gtx, func(gtx C) D {
+			l := material.Body1(th, "Starting the camera…")
+			l.Color = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xc0}
+			return l.Layout(gtx)
+		})
+	}
+	fsz := f.frame.RGBA.Rect.Size()
+	scale := float32(size.X) / float32(fsz.X)
+	if s := float32(size.Y) / float32(fsz.Y); s < scale {
+		scale = s
+	}
+	origin := f32.Pt((float32(size.X)-float32(fsz.X)*scale)/2, (float32(size.Y)-float32(fsz.Y)*scale)/2)
+	toScreen := func(p image.Point) f32.Point {
+		return origin.Add(layout.FPt(p).Mul(scale))
+	}
+
+	stack := op.Save(gtx.Ops)
+	op.Affine(f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(scale, scale)).Offset(origin)).Add(gtx.Ops)
+	clip.Rect{Max: fsz}.Add(gtx.Ops)
+	a.img.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+	stack.Load()
+
+	width := float32(gtx.Px(unit.Dp(3)))
+	if f.hasDoc {
+		var p clip.Path
+		p.Begin(gtx.Ops)
+		p.MoveTo(toScreen(f.doc[0]))
+		for _, c := range f.doc[1:] {
+			p.LineTo(toScreen(c))
+		}
+		p.Close()
+		outline := p.End()
+		fill := documentColor
+		fill.A = 0x30
+		paint.FillShape(gtx.Ops, fill, clip.Outline{Path: outline}.Op())
+		p.Begin(gtx.Ops)
+		p.MoveTo(toScreen(f.doc[0]))
+		for _, c := range f.doc[1:] {
+			p.LineTo(toScreen(c))
+		}
+		p.Close()
+		paint.FillShape(gtx.Ops, documentColor, clip.Stroke{Path: p.End(), Style: clip.StrokeStyle{Width: width, Join: clip.RoundJoin}}.Op())
+	}
+	for _, c := range f.codes {
+		from, to := toScreen(c.Line[0]), toScreen(c.Line[1])
+		var p clip.Path
+		p.Begin(gtx.Ops)
+		p.MoveTo(from)
+		p.LineTo(to)
+		paint.FillShape(gtx.Ops, barcodeColor, clip.Stroke{Path: p.End(), Style: clip.StrokeStyle{Width: width, Cap: clip.RoundCap}}.Op())
+		// The text above the line.
+		stack := op.Save(gtx.Ops)
+		macro := op.Record(gtx.Ops)
+		l := material.Caption(th, c.Text)
+		l.Color = color.NRGBA{A: 0xff}
+		cgtx := gtx
+		cgtx.Constraints.Min = image.Point{}
+		dims := layout.Inset{Left: unit.Dp(4), Right: unit.Dp(4)}.Layout(cgtx, l.Layout)
+		call := macro.Stop()
+		op.Offset(f32.Pt(from.X, from.Y-width-float32(dims.Size.Y))).Add(gtx.Ops)
+		paint.FillShape(gtx.Ops, barcodeColor, clip.Rect{Max: dims.Size}.Op())
+		call.Add(gtx.Ops)
+		stack.Load()
+	}
+	return D{Size: size}
+}
+
+func (a *App) layoutPanel(gtx C, th *material.Theme) D {
+	for _, r := range a.results {
+		if r.copy.Clicked() && r.code != nil {
+			clipboard.WriteOp{Text: r.code.Text}.Add(gtx.Ops)
+		}
+	}
+	hasDoc := a.latest != nil && a.latest.hasDoc
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(material.Caption(th, a.source).Layout),
+		layout.Rigid(func(gtx C) D {
+			if a.err == nil {
+				return D{}
+			}
+			l := material.Caption(th, a.err.Error())
+			l.Color = errorColor
+			return l.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+		layout.Rigid(func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			if !hasDoc {
+				gtx = gtx.Disabled()
+			}
+			return material.Button(th, &a.scan, "Scan document").Layout(gtx)
+		}),
+		layout.Rigid(func(gtx C) D {
+			msg := "Hold a sheet of paper against a darker background."
+			if hasDoc {
+				msg = "Document in view."
+			}
+			return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, material.Caption(th, msg).Layout)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+		layout.Rigid(material.H6(th, "Results").Layout),
+		layout.Flexed(1, func(gtx C) D {
+			if len(a.results) == 0 {
+				l := material.Body2(th, "Barcodes read appear here.")
+				l.Color = color.NRGBA{A: 0x80}
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, l.Layout)
+			}
+			return a.list.Layout(gtx, len(a.results), func(gtx C, i int) D {
+				return a.results[i].Layout(gtx, th)
+			})
+		}),
+	)
+}
+
+func (r *result) Layout(gtx C, th *material.Theme) D {
+	return layout.Inset{Top: unit.Dp(8), Bottom: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+		if r.page != nil {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					h := gtx.Px(unit.Dp(64))
+					sz := r.page.Size()
+					return widget.Image{Src: *r.page, Scale: float32(h) / float32(sz.Y) / gtx.Metric.PxPerDp}.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+				layout.Flexed(1, func(gtx C) D {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(material.Body1(th, "Document").Layout),
+						layout.Rigid(material.Caption(th, "Saved to "+r.path).Layout),
+					)
+				}),
+			)
+		}
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(material.Body1(th, r.code.Text).Layout),
+					layout.Rigid(func(gtx C) D {
+						l := material.Caption(th, fmt.Sprintf("%s · read %d× · %s", r.code.Format, r.count, r.time.Format("15:04:05")))
+						l.Color = color.NRGBA{A: 0x90}
+						return l.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx C) D {
+				b := material.Button(th, &r.copy, "Copy")
+				b.Background = color.NRGBA{}
+				b.Color = th.Palette.ContrastBg
+				return b.Layout(gtx)
+			}),
+		)
+	})
+}
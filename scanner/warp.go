@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"math"
+)
+
+// Straighten returns the document of src as seen from straight above,
+// undoing the perspective of the camera.
+func Straighten(src *image.RGBA, d Document) *image.RGBA {
+	dist := func(a, b image.Point) float64 {
+		return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y))
+	}
+	w := int(math.Max(dist(d[0], d[1]), dist(d[3], d[2])))
+	h := int(math.Max(dist(d[0], d[3]), dist(d[1], d[2])))
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if w == 0 || h == 0 {
+		return dst
+	}
+	corners := [4][2]float64{{0, 0}, {float64(w), 0}, {float64(w), float64(h)}, {0, float64(h)}}
+	var pts [4][2]float64
+	for i, p := range d {
+		pts[i] = [2]float64{float64(p.X), float64(p.Y)}
+	}
+	hm, ok := homography(corners, pts)
+	if !ok {
+		return dst
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			u, v := float64(x)+0.5, float64(y)+0.5
+			den := hm[6]*u + hm[7]*v + 1
+			sx := (hm[0]*u + hm[1]*v + hm[2]) / den
+			sy := (hm[3]*u + hm[4]*v + hm[5]) / den
+			bilinear(dst.Pix[y*dst.Stride+x*4:], src, sx-0.5, sy-0.5)
+		}
+	}
+	return dst
+}
+
+// bilinear samples src at x, y into the pixel p.
+func bilinear(p []uint8, src *image.RGBA, x, y float64) {
+	b := src.Rect
+	x0, y0 := int(math.Floor(x)), int(math.Floor(y))
+	fx, fy := x-float64(x0), y-float64(y0)
+	at := func(x, y int) []uint8 {
+		if x < b.Min.X {
+			x = b.Min.X
+		}
+		if x >= b.Max.X {
+			x = b.Max.X - 1
+		}
+		if y < b.Min.Y {
+			y = b.Min.Y
+		}
+		if y >= b.Max.Y {
+			y = b.Max.Y - 1
+		}
+		return src.Pix[src.PixOffset(x, y):]
+	}
+	p00, p10, p01, p11 := at(x0, y0), at(x0+1, y0), at(x0, y0+1), at(x0+1, y0+1)
+	for c := 0; c < 4; c++ {
+		top := float64(p00[c])*(1-fx) + float64(p10[c])*fx
+		bot := float64(p01[c])*(1-fx) + float64(p11[c])*fx
+		p[c] = uint8(top*(1-fy) + bot*fy + 0.5)
+	}
+}
+
+// homography returns the projective transform mapping the points from to
+// the points to, as the first 8 entries of its 3x3 matrix; the last is 1.
+func homography(from, to [4][2]float64) ([8]float64, bool) {
+	var m [8][9]float64
+	for i := range from {
+		u, v := from[i][0], from[i][1]
+		x, y := to[i][0], to[i][1]
+		m[2*i] = [9]float64{u, v, 1, 0, 0, 0, -u * x, -v * x, x}
+		m[2*i+1] = [9]float64{0, 0, 0, u, v, 1, -u * y, -v * y, y}
+	}
+	// Gaussian elimination with partial pivoting.
+	for c := 0; c < 8; c++ {
+		pivot := c
+		for r := c + 1; r < 8; r++ {
+			if math.Abs(m[r][c]) > math.Abs(m[pivot][c]) {
+				pivot = r
+			}
+		}
+		if math.Abs(m[pivot][c]) < 1e-12 {
+			return [8]float64{}, false
+		}
+		m[c], m[pivot] = m[pivot], m[c]
+		for r := 0; r < 8; r++ {
+			if r == c {
+				continue
+			}
+			f := m[r][c] / m[c][c]
+			for k := c; k < 9; k++ {
+				m[r][k] -= f * m[c][k]
+			}
+		}
+	}
+	var h [8]float64
+	for i := range h {
+		h[i] = m[i][8] / m[i][i]
+	}
+	return h, true
+}
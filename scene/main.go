@@ -0,0 +1,317 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates a retained scene graph. Each of thousands of
+// shapes records its drawing operations once into its own op.Ops and the
+// frame only calls the cached macros. When a shape changes, only that
+// shape is recorded again. The stress test compares the time spent
+// building frames in cached mode with naive mode, where every shape is
+// recorded every frame.
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Scene graph"),
+			app.Size(unit.Dp(900), unit.Dp(700)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// shape is the kind of a node.
+type shape uint8
+
+const (
+	rectangle shape = iota
+	rounded
+	diamond
+)
+
+// node is a shape in the scene. Its position is applied when the node is
+// drawn; everything else is part of the cached macro.
+type node struct {
+	pos   f32.Point
+	size  float32
+	shape shape
+	color color.NRGBA
+
+	dirty bool
+	ops   op.Ops
+	call  op.CallOp
+}
+
+// record records the drawing operations of n, relative to its position.
+func (n *node) record(ops *op.Ops) op.CallOp {
+	m := op.Record(ops)
+	r := f32.Rectangle{Max: f32.Pt(n.size, n.size)}
+	switch n.shape {
+	case rectangle:
+		sz := int(n.size)
+		paint.FillShape(ops, n.color, clip.Rect(image.Rect(0, 0, sz, sz)).Op())
+	case rounded:
+		paint.FillShape(ops, n.color, clip.UniformRRect(r, n.size/4).Op(ops))
+	case diamond:
+		var p clip.Path
+		p.Begin(ops)
+		p.MoveTo(f32.Pt(n.size/2, 0))
+		p.LineTo(f32.Pt(n.size, n.size/2))
+		p.LineTo(f32.Pt(n.size/2, n.size))
+		p.LineTo(f32.Pt(0, n.size/2))
+		p.Close()
+		paint.FillShape(ops, n.color, clip.Outline{Path: p.End()}.Op())
+	}
+	return m.Stop()
+}
+
+// cached returns the cached macro of n, recording it again if n changed.
+func (n *node) cached() op.CallOp {
+	if n.dirty {
+		n.ops.Reset()
+		n.call = n.record(&n.ops)
+		n.dirty = false
+	}
+	return n.call
+}
+
+// bounds returns the area covered by n.
+func (n *node) bounds() image.Rectangle {
+	min := image.Pt(int(n.pos.X), int(n.pos.Y))
+	sz := int(math.Ceil(float64(n.size)))
+	return image.Rectangle{Min: min, Max: min.Add(image.Pt(sz, sz))}
+}
+
+// Scene is a retained collection of nodes.
+type Scene struct {
+	nodes []*node
+	rnd   *rand.Rand
+	// dirty is the union of the areas changed in the last update.
+	dirty image.Rectangle
+}
+
+func newScene(n int, size image.Point) *Scene {
+	s := &Scene{rnd: rand.New(rand.NewSource(1))}
+	for i := 0; i < n; i++ {
+		nd := &node{dirty: true}
+		s.randomize(nd, size)
+		s.nodes = append(s.nodes, nd)
+	}
+	return s
+}
+
+func (s *Scene) randomize(n *node, size image.Point) {
+	n.size = 6 + s.rnd.Float32()*18
+	n.pos = f32.Pt(s.rnd.Float32()*float32(size.X), s.rnd.Float32()*float32(size.Y))
+	n.shape = shape(s.rnd.Intn(3))
+	n.color = color.NRGBA{
+		R: uint8(s.rnd.Intn(256)),
+		G: uint8(s.rnd.Intn(256)),
+		B: uint8(s.rnd.Intn(256)),
+		A: 0xc0,
+	}
+	n.dirty = true
+}
+
+// update changes count random nodes and records the union of the areas
+// they covered before and after the change.
+func (s *Scene) update(count int, size image.Point) {
+	s.dirty = image.Rectangle{}
+	for i := 0; i < count; i++ {
+		n := s.nodes[s.rnd.Intn(len(s.nodes))]
+		s.dirty = s.dirty.Union(n.bounds())
+		s.randomize(n, size)
+		s.dirty = s.dirty.Union(n.bounds())
+	}
+}
+
+// Layout draws the scene. In naive mode every node is recorded into the
+// frame; otherwise the cached macros are called.
+func (s *Scene) Layout(gtx C, naive bool) D {
+	for _, n := range s.nodes {
+		stack := op.Save(gtx.Ops)
+		op.Offset(n.pos).Add(gtx.Ops)
+		if naive {
+			n.record(gtx.Ops).Add(gtx.Ops)
+		} else {
+			n.cached().Add(gtx.Ops)
+		}
+		stack.Load()
+	}
+	return D{Size: gtx.Constraints.Max}
+}
+
+// stressFrames is the number of frames measured in each mode of the
+// stress test.
+const stressFrames = 120
+
+// stress runs the stress test, measuring naive mode first and cached mode
+// second.
+type stress struct {
+	running bool
+	frame   int
+	naive   time.Duration
+	cached  time.Duration
+}
+
+func (st *stress) result() string {
+	if st.running {
+		return fmt.Sprintf("Stress test: frame %d/%d", st.frame, 2*stressFrames)
+	}
+	if st.frame == 0 {
+		return "Stress test not run"
+	}
+	avg := func(d time.Duration) time.Duration { return d / stressFrames }
+	return fmt.Sprintf("Naive %v/frame, cached %v/frame (%.1fx)",
+		avg(st.naive), avg(st.cached), float64(st.naive)/math.Max(1, float64(st.cached)))
+}
+
+var counts = []int{1000, 5000, 20000}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	var (
+		ops       op.Ops
+		scene     *Scene
+		mode      = widget.Enum{Value: "cached"}
+		count     = widget.Enum{Value: "5000"}
+		changes   = widget.Float{Value: 20}
+		showDirty = widget.Bool{Value: true}
+		runStress widget.Clickable
+		st        stress
+		buildTime time.Duration
+		lastCount string
+	)
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			if count.Value != lastCount {
+				lastCount = count.Value
+				scene = nil
+			}
+			if runStress.Clicked() {
+				st = stress{running: true}
+			}
+			naive := mode.Value == "naive"
+			if st.running {
+				naive = st.frame < stressFrames
+			}
+
+			layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+						return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+							layout.Rigid(material.RadioButton(th, &mode, "cached", "Cached").Layout),
+							layout.Rigid(material.RadioButton(th, &mode, "naive", "Naive").Layout),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+							layout.Rigid(func(gtx C) D {
+								var children []layout.FlexChild
+								for _, c := range counts {
+									key := fmt.Sprint(c)
+									children = append(children, layout.Rigid(material.RadioButton(th, &count, key, key).Layout))
+								}
+								return layout.Flex{}.Layout(gtx, children...)
+							}),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+							layout.Rigid(material.CheckBox(th, &showDirty, "Dirty region").Layout),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+							layout.Rigid(material.Button(th, &runStress, "Stress test").Layout),
+						)
+					})
+				}),
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Left: unit.Dp(8), Right: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+						return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+							layout.Rigid(material.Body2(th, fmt.Sprintf("Changes/frame: %d", int(changes.Value))).Layout),
+							layout.Flexed(1, material.Slider(th, &changes, 0, 500).Layout),
+						)
+					})
+				}),
+				layout.Rigid(func(gtx C) D {
+					m := "cached"
+					if naive {
+						m = "naive"
+					}
+					txt := fmt.Sprintf("Building the frame (%s): %v. %s", m, buildTime.Round(time.Microsecond), st.result())
+					return layout.UniformInset(unit.Dp(8)).Layout(gtx, material.Body2(th, txt).Layout)
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					size := gtx.Constraints.Max
+					if scene == nil {
+						var n int
+						fmt.Sscan(count.Value, &n)
+						scene = newScene(n, size)
+					}
+					defer op.Save(gtx.Ops).Load()
+					clip.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+					scene.update(int(changes.Value), size)
+
+					start := time.Now()
+					dims := scene.Layout(gtx, naive)
+					buildTime = time.Since(start)
+
+					if showDirty.Value && !scene.dirty.Empty() {
+						op.Offset(layout.FPt(scene.dirty.Min)).Add(gtx.Ops)
+						widget.Border{
+							Color: color.NRGBA{R: 0xff, A: 0xff},
+							Width: unit.Dp(2),
+						}.Layout(gtx, func(gtx C) D {
+							return D{Size: scene.dirty.Size()}
+						})
+					}
+					return dims
+				}),
+			)
+
+			if st.running {
+				if st.frame < stressFrames {
+					st.naive += buildTime
+				} else {
+					st.cached += buildTime
+				}
+				st.frame++
+				if st.frame == 2*stressFrames {
+					st.running = false
+				}
+			}
+			// Changes are continuous; keep animating.
+			op.InvalidateOp{}.Add(gtx.Ops)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
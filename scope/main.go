@@ -0,0 +1,441 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates plotting a fast stream of samples in real
+// time, as an oscilloscope or a sensor monitor does. A simulated
+// instrument sends two channels at 20,000 samples per second each into
+// ring buffers holding the last minute. For display, the samples of each
+// screen column are reduced to their minimum and maximum, which keeps
+// short glitches visible however far the plot is zoomed out.
+//
+// Scroll over the plot to zoom the time window. Drag the plot, or press
+// Pause, to stop following the newest samples and look back through the
+// history; acquisition goes on meanwhile. Live returns to the newest
+// samples.
+//
+// Usage:
+//
+//	go run ./scope [-rate 20000] [-history 60s]
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"os"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/pointer"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	rateFlag    = flag.Int("rate", 20000, "samples per second per channel")
+	historyFlag = flag.Duration("history", time.Minute, "length of the history kept")
+)
+
+// minSpan is the fewest samples across the plot.
+const minSpan = 64
+
+// divisions is the number of grid divisions across the plot.
+const divisions = 10
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Scope"),
+			app.Size(unit.Dp(1000), unit.Dp(700)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// Channel describes the display of a channel.
+type Channel struct {
+	Name  string
+	Color color.NRGBA
+	// Min and Max are the values at the bottom and top of the plot.
+	Min, Max float32
+}
+
+var channelInfo = [channels]Channel{
+	{Name: "CH1 signal", Color: color.NRGBA{R: 0xff, G: 0xd5, B: 0x4f, A: 0xff}, Min: -2, Max: 2},
+	{Name: "CH2 sensor", Color: color.NRGBA{R: 0x4d, G: 0xd0, B: 0xe1, A: 0xff}, Min: -0.6, Max: 0.6},
+}
+
+var (
+	plotBg    = color.NRGBA{R: 0x12, G: 0x16, B: 0x1a, A: 0xff}
+	gridColor = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x20}
+)
+
+// Scope is the state of the plot.
+type Scope struct {
+	rate  int
+	rings [channels]*Ring
+
+	// span is the number of samples across the plot. While paused, end
+	// is the number of the sample at its right edge; otherwise the plot
+	// follows the newest sample.
+	span   int64
+	end    int64
+	paused bool
+
+	dragging bool
+	last     f32.Point
+	// width is the width of the plot in the last frame.
+	width int
+
+	buckets []Bucket
+
+	// The ingest rate, measured over the last second.
+	counted    int64
+	countStart time.Time
+	measured   float64
+
+	pause, live widget.Clickable
+}
+
+func newScope(rate int, history time.Duration) *Scope {
+	s := &Scope{rate: rate, span: int64(rate) / 10}
+	for i := range s.rings {
+		s.rings[i] = NewRing(int(history.Seconds() * float64(rate)))
+	}
+	return s
+}
+
+// Ingest appends a batch to the rings.
+func (s *Scope) Ingest(b Batch, now time.Time) {
+	for i, r := range s.rings {
+		r.Push(b[i])
+	}
+	s.counted += int64(len(b[0]))
+	if s.countStart.IsZero() {
+		s.countStart = now
+	}
+	if d := now.Sub(s.countStart); d >= time.Second {
+		s.measured = float64(s.counted) / d.Seconds()
+		s.counted, s.countStart = 0, now
+	}
+}
+
+// bounds returns the samples shown, from and up to but not including
+// to.
+func (s *Scope) bounds() (from, to int64) {
+	to = s.rings[0].Total()
+	if s.paused {
+		to = s.end
+	}
+	return to - s.span, to
+}
+
+// clampEnd keeps a paused plot within the history.
+func (s *Scope) clampEnd() {
+	r := s.rings[0]
+	if s.end > r.Total() {
+		s.end = r.Total()
+	}
+	if min := r.First() + s.span; s.end < min {
+		s.end = min
+	}
+}
+
+func (s *Scope) setPaused(paused bool) {
+	if paused && !s.paused {
+		s.end = s.rings[0].Total()
+	}
+	s.paused = paused
+}
+
+func (s *Scope) update(gtx C) {
+	for s.pause.Clicked() {
+		s.setPaused(!s.paused)
+	}
+	for s.live.Clicked() {
+		s.setPaused(false)
+	}
+	for _, e := range gtx.Events(s) {
+		e, ok := e.(pointer.Event)
+		if !ok || s.width == 0 {
+			continue
+		}
+		switch e.Type {
+		case pointer.Press:
+			s.dragging = true
+			s.last = e.Position
+		case pointer.Drag:
+			// Dragging pans through the history, which stops following
+			// the newest samples.
+			s.setPaused(true)
+			dx := e.Position.X - s.last.X
+			s.end -= int64(float64(dx) * float64(s.span) / float64(s.width))
+			s.clampEnd()
+			s.last = e.Position
+		case pointer.Release, pointer.Cancel:
+			s.dragging = false
+		case pointer.Scroll:
+			// Zoom around the pointer while paused, around the newest
+			// sample otherwise.
+			from, _ := s.bounds()
+			at := from + int64(float64(e.Position.X)/float64(s.width)*float64(s.span))
+			frac := float64(e.Position.X) / float64(s.width)
+			if !s.paused {
+				frac = 1
+			}
+			span := int64(float64(s.span) * math.Pow(2, float64(e.Scroll.Y)/100))
+			if span < minSpan {
+				span = minSpan
+			}
+			if max := int64(s.rings[0].Cap()); span > max {
+				span = max
+			}
+			s.span = span
+			if s.paused {
+				s.end = at + int64(float64(span)*(1-frac))
+				s.clampEnd()
+			}
+		}
+	}
+	if s.paused {
+		// The oldest samples are overwritten under a paused plot.
+		s.clampEnd()
+	}
+}
+
+func (s *Scope) Layout(gtx C, th *material.Theme) D {
+	s.update(gtx)
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				label := "Pause"
+				if s.paused {
+					label = "Resume"
+				}
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(material.Button(th, &s.pause, label).Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(func(gtx C) D {
+						if !s.paused {
+							return D{}
+						}
+						return material.Button(th, &s.live, "Live").Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+					layout.Flexed(1, material.Body2(th, s.status()).Layout),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return s.layoutPlots(gtx, th)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, material.Caption(th, s.timeAxis()).Layout)
+		}),
+	)
+}
+
+// status describes the acquisition and the decimation.
+func (s *Scope) status() string {
+	r := s.rings[0]
+	held := r.Total() - r.First()
+	perPx := 0.0
+	if s.width > 0 {
+		perPx = float64(s.span) / float64(s.width)
+	}
+	state := "Live"
+	if s.paused {
+		state = fmt.Sprintf("Paused %s behind", seconds(float64(r.Total()-s.end)/float64(s.rate)))
+	}
+	return fmt.Sprintf("%s. %s samples/s per channel, %s held, %.1f samples/px", state,
+		count(s.measured), count(float64(held)), perPx)
+}
+
+// timeAxis describes the time scale of the plot.
+func (s *Scope) timeAxis() string {
+	span := float64(s.span) / float64(s.rate)
+	return fmt.Sprintf("%s/div, window %s. Scroll to zoom, drag to look back.", seconds(span/divisions), seconds(span))
+}
+
+func seconds(s float64) string {
+	switch {
+	case s < 1e-3:
+		return fmt.Sprintf("%.0f µs", s*1e6)
+	case s < 1:
+		return fmt.Sprintf("%.3g ms", s*1e3)
+	default:
+		return fmt.Sprintf("%.3g s", s)
+	}
+}
+
+func count(n float64) string {
+	switch {
+	case n >= 1e6:
+		return fmt.Sprintf("%.2fM", n/1e6)
+	case n >= 1e3:
+		return fmt.Sprintf("%.1fk", n/1e3)
+	default:
+		return fmt.Sprintf("%.0f", n)
+	}
+}
+
+// layoutPlots draws the channels stacked, over a shared time axis.
+func (s *Scope) layoutPlots(gtx C, th *material.Theme) D {
+	size := gtx.Constraints.Max
+	s.width = size.X
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	paint.Fill(gtx.Ops, plotBg)
+
+	pointer.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	pointer.InputOp{
+		Tag:          s,
+		Grab:         s.dragging,
+		Types:        pointer.Press | pointer.Drag | pointer.Release | pointer.Scroll,
+		ScrollBounds: image.Rect(0, -1e6, 0, 1e6),
+	}.Add(gtx.Ops)
+
+	if cap(s.buckets) < size.X {
+		s.buckets = make([]Bucket, size.X)
+	}
+	from, to := s.bounds()
+	h := size.Y / channels
+	for i := range s.rings {
+		st := op.Save(gtx.Ops)
+		op.Offset(f32.Pt(0, float32(i*h))).Add(gtx.Ops)
+		s.layoutPlot(gtx, th, i, image.Pt(size.X, h), from, to)
+		st.Load()
+	}
+	return D{Size: size}
+}
+
+// layoutPlot draws the grid and the trace of a channel.
+func (s *Scope) layoutPlot(gtx C, th *material.Theme, ch int, size image.Point, from, to int64) {
+	info := channelInfo[ch]
+	w, h := float32(size.X), float32(size.Y)
+	y := func(v float32) float32 {
+		return h * (info.Max - v) / (info.Max - info.Min)
+	}
+
+	// The grid, with the time divisions scrolling along with the
+	// samples.
+	var grid clip.Path
+	grid.Begin(gtx.Ops)
+	div := float64(s.span) / divisions
+	first := math.Ceil(float64(from)/div) * div
+	for t := first; t < float64(to); t += div {
+		x := float32((t - float64(from)) / float64(s.span) * float64(w))
+		grid.MoveTo(f32.Pt(x, 0))
+		grid.LineTo(f32.Pt(x, h))
+	}
+	for i := 1; i < 4; i++ {
+		gy := h * float32(i) / 4
+		grid.MoveTo(f32.Pt(0, gy))
+		grid.LineTo(f32.Pt(w, gy))
+	}
+	grid.MoveTo(f32.Pt(0, h))
+	grid.LineTo(f32.Pt(w, h))
+	paint.FillShape(gtx.Ops, gridColor, clip.Stroke{Path: grid.End(), Style: clip.StrokeStyle{Width: 1}}.Op())
+
+	r := s.rings[ch]
+	width := float32(gtx.Px(unit.Dp(1.5)))
+	var p clip.Path
+	p.Begin(gtx.Ops)
+	if s.span >= 2*int64(size.X) {
+		// Zoomed out: a bar from the minimum to the maximum of every
+		// column.
+		buckets := s.buckets[:size.X]
+		r.MinMax(from, to, buckets)
+		for x, b := range buckets {
+			if b.N == 0 {
+				continue
+			}
+			top, bottom := y(b.Max)-width/2, y(b.Min)+width/2
+			p.MoveTo(f32.Pt(float32(x), top))
+			p.LineTo(f32.Pt(float32(x+1), top))
+			p.LineTo(f32.Pt(float32(x+1), bottom))
+			p.LineTo(f32.Pt(float32(x), bottom))
+			p.Close()
+		}
+		paint.FillShape(gtx.Ops, info.Color, clip.Outline{Path: p.End()}.Op())
+	} else {
+		// Zoomed in: a line through the samples.
+		start := from
+		if first := r.First(); start < first {
+			start = first
+		}
+		if start < 0 {
+			start = 0
+		}
+		for i := start; i < to && i < r.Total(); i++ {
+			pt := f32.Pt(float32(i-from)/float32(s.span)*w, y(r.At(i)))
+			if i == start {
+				p.MoveTo(pt)
+			} else {
+				p.LineTo(pt)
+			}
+		}
+		paint.FillShape(gtx.Ops, info.Color, clip.Stroke{Path: p.End(), Style: clip.StrokeStyle{Width: width}}.Op())
+	}
+
+	st := op.Save(gtx.Ops)
+	op.Offset(f32.Pt(float32(gtx.Px(unit.Dp(8))), float32(gtx.Px(unit.Dp(4))))).Add(gtx.Ops)
+	gtx.Constraints.Min = image.Point{}
+	l := material.Caption(th, fmt.Sprintf("%s  %g … %g", info.Name, info.Min, info.Max))
+	l.Color = info.Color
+	l.Layout(gtx)
+	st.Load()
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	s := newScope(*rateFlag, *historyFlag)
+	batches := make(chan Batch, 16)
+	stop := make(chan struct{})
+	defer close(stop)
+	go acquire(newSignal(*rateFlag), batches, stop)
+	var ops op.Ops
+	for {
+		select {
+		case b := <-batches:
+			// Frames are paced by the display, or by the second while
+			// paused, below; not by the batches.
+			s.Ingest(b, time.Now())
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				s.Layout(gtx, th)
+				if s.paused {
+					op.InvalidateOp{At: gtx.Now.Add(time.Second)}.Add(gtx.Ops)
+				} else {
+					op.InvalidateOp{}.Add(gtx.Ops)
+				}
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// Ring is a fixed size history of samples. Samples are numbered from 0
+// in the order pushed; the ring holds the most recent ones, overwriting
+// the oldest.
+type Ring struct {
+	buf []float32
+	// total is the number of samples pushed.
+	total int64
+}
+
+func NewRing(size int) *Ring {
+	return &Ring{buf: make([]float32, size)}
+}
+
+// Push appends samples, overwriting the oldest when the ring is full.
+func (r *Ring) Push(samples []float32) {
+	n := len(r.buf)
+	if len(samples) > n {
+		r.total += int64(len(samples) - n)
+		samples = samples[len(samples)-n:]
+	}
+	i := int(r.total % int64(n))
+	c := copy(r.buf[i:], samples)
+	copy(r.buf, samples[c:])
+	r.total += int64(len(samples))
+}
+
+// Total returns the number of samples pushed, the number of the next.
+func (r *Ring) Total() int64 {
+	return r.total
+}
+
+// First returns the number of the oldest sample held.
+func (r *Ring) First() int64 {
+	if first := r.total - int64(len(r.buf)); first > 0 {
+		return first
+	}
+	return 0
+}
+
+// Cap returns the number of samples the ring holds when full.
+func (r *Ring) Cap() int {
+	return len(r.buf)
+}
+
+// At returns sample i, which must be held.
+func (r *Ring) At(i int64) float32 {
+	return r.buf[i%int64(len(r.buf))]
+}
+
+// Bucket is the range of the samples of a screen column.
+type Bucket struct {
+	Min, Max float32
+	// N is the number of samples in the bucket; zero for a bucket of
+	// samples not held.
+	N int
+}
+
+// MinMax decimates the samples from up to to into len(dst) buckets of
+// their minimum and maximum. Unlike averaging or picking every nth
+// sample, the extremes keep short spikes visible at any zoom.
+func (r *Ring) MinMax(from, to int64, dst []Bucket) {
+	n := int64(len(dst))
+	if n == 0 {
+		return
+	}
+	first, size := r.First(), int64(len(r.buf))
+	for b := range dst {
+		start := from + (to-from)*int64(b)/n
+		end := from + (to-from)*int64(b+1)/n
+		if start < first {
+			start = first
+		}
+		if end > r.total {
+			end = r.total
+		}
+		bk := Bucket{}
+		if start < end {
+			pos := start % size
+			min, max := r.buf[pos], r.buf[pos]
+			for i := start; i < end; i++ {
+				v := r.buf[pos]
+				if v < min {
+					min = v
+				}
+				if v > max {
+					max = v
+				}
+				pos++
+				if pos == size {
+					pos = 0
+				}
+			}
+			bk = Bucket{Min: min, Max: max, N: int(end - start)}
+		}
+		dst[b] = bk
+	}
+}
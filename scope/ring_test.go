@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import "testing"
+
+func TestRing(t *testing.T) {
+	r := NewRing(4)
+	r.Push([]float32{1, 2, 3})
+	if r.Total() != 3 || r.First() != 0 {
+		t.Errorf("total %d, first %d, want 3 and 0", r.Total(), r.First())
+	}
+	r.Push([]float32{4, 5, 6})
+	if r.Total() != 6 || r.First() != 2 {
+		t.Errorf("total %d, first %d after wrapping, want 6 and 2", r.Total(), r.First())
+	}
+	for i := int64(2); i < 6; i++ {
+		if got := r.At(i); got != float32(i+1) {
+			t.Errorf("sample %d = %v, want %v", i, got, i+1)
+		}
+	}
+	// More than the ring holds keeps the newest.
+	r.Push([]float32{7, 8, 9, 10, 11, 12})
+	if r.Total() != 12 || r.First() != 8 || r.At(8) != 9 || r.At(11) != 12 {
+		t.Errorf("total %d, first %d, samples %v, %v after a large push", r.Total(), r.First(), r.At(8), r.At(11))
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	r := NewRing(100)
+	samples := make([]float32, 130)
+	// A one sample spike in the second half.
+	samples[80] = 5
+	samples[81] = -1
+	r.Push(samples)
+	buckets := make([]Bucket, 3)
+	// Samples 0 to 29 are overwritten.
+	r.MinMax(0, 150, buckets)
+	want := []Bucket{
+		{Min: 0, Max: 0, N: 20},
+		{Min: -1, Max: 5, N: 50},
+		{Min: 0, Max: 0, N: 30},
+	}
+	for i, b := range buckets {
+		if b != want[i] {
+			t.Errorf("bucket %d = %+v, want %+v", i, b, want[i])
+		}
+	}
+	r.MinMax(0, 20, buckets)
+	for i, b := range buckets {
+		if b.N != 0 {
+			t.Errorf("bucket %d of overwritten samples = %+v, want empty", i, b)
+		}
+	}
+}
+
+func BenchmarkMinMax(b *testing.B) {
+	// A minute at 20,000 samples per second, onto 1000 pixels.
+	r := NewRing(20000 * 60)
+	r.Push(make([]float32, r.Cap()))
+	buckets := make([]Bucket, 1000)
+	for i := 0; i < b.N; i++ {
+		r.MinMax(r.First(), r.Total(), buckets)
+	}
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// channels is the number of channels of the scope.
+const channels = 2
+
+// Batch is the samples of the channels acquired in a period.
+type Batch [channels][]float32
+
+// signal simulates an instrument sampling two channels: a 50 Hz signal
+// with a slow modulation, noise and the odd glitch a few samples wide,
+// and a slowly drifting sensor reading.
+type signal struct {
+	rate  int
+	rnd   *rand.Rand
+	n     int64
+	drift float64
+	// glitch counts down the samples of the current glitch.
+	glitch int
+}
+
+func newSignal(rate int) *signal {
+	return &signal{rate: rate, rnd: rand.New(rand.NewSource(1))}
+}
+
+// next returns the next n samples of the channels.
+func (s *signal) next(n int) Batch {
+	var b Batch
+	for c := range b {
+		b[c] = make([]float32, n)
+	}
+	for i := 0; i < n; i++ {
+		t := float64(s.n) / float64(s.rate)
+		v := 0.8*math.Sin(2*math.Pi*50*t)*(0.75+0.25*math.Sin(2*math.Pi*0.5*t)) + s.rnd.NormFloat64()*0.03
+		// About one glitch every two seconds.
+		if s.glitch == 0 && s.rnd.Intn(2*s.rate) == 0 {
+			s.glitch = 3
+		}
+		if s.glitch > 0 {
+			v += 1.2
+			s.glitch--
+		}
+		b[0][i] = float32(v)
+		s.drift += s.rnd.NormFloat64() * 0.002
+		s.drift *= 0.9999
+		b[1][i] = float32(s.drift + s.rnd.NormFloat64()*0.01)
+		s.n++
+	}
+	return b
+}
+
+// acquire sends batches of samples at the sample rate of s until stop is
+// closed. The number of samples is from the time passed, so the rate
+// holds even when the ticks are late.
+func acquire(s *signal, out chan<- Batch, stop <-chan struct{}) {
+	t := time.NewTicker(10 * time.Millisecond)
+	defer t.Stop()
+	start := time.Now()
+	var sent int64
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-t.C:
+			due := int64(now.Sub(start).Seconds() * float64(s.rate))
+			if due <= sent {
+				continue
+			}
+			b := s.next(int(due - sent))
+			sent = due
+			select {
+			case out <- b:
+			case <-stop:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates the scrollbar in internal/scrollbar attached
+// to a list of a million rows and to a source code view with a minimap.
+
+import (
+	_ "embed"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"strings"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/scrollbar"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+//go:embed main.go
+var source string
+
+const rows = 1000000
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Scrollbars"),
+			app.Size(unit.Dp(900), unit.Dp(600)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// pane is a scrollable list with a scrollbar.
+type pane struct {
+	list layout.List
+	bar  scrollbar.Scrollbar
+	// visible is the number of elements laid out in the last frame.
+	visible int
+}
+
+// Layout lays out n elements with a scrollbar along the right edge.
+func (p *pane) Layout(gtx C, n int, minimap layout.Widget, w layout.ListElement) D {
+	if d := p.bar.Scrolled(); d != 0 {
+		start := float32(p.list.Position.First) / float32(n)
+		first := int((start + d) * float32(n))
+		if max := n - p.visible; first > max {
+			first = max
+		}
+		if first < 0 {
+			first = 0
+		}
+		p.list.Position.First = first
+		p.list.Position.Offset = 0
+	}
+	return layout.Flex{}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			visible := 0
+			dims := p.list.Layout(gtx, n, func(gtx C, i int) D {
+				visible++
+				return w(gtx, i)
+			})
+			p.visible = visible
+			return dims
+		}),
+		layout.Rigid(func(gtx C) D {
+			start := float32(p.list.Position.First) / float32(n)
+			end := float32(p.list.Position.First+p.visible) / float32(n)
+			bar := scrollbar.New(&p.bar)
+			bar.Minimap = minimap
+			return bar.Layout(gtx, start, end)
+		}),
+	)
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	lines := strings.Split(source, "\n")
+	var (
+		ops       op.Ops
+		rowsPane  = &pane{list: layout.List{Axis: layout.Vertical}}
+		codePane  = &pane{list: layout.List{Axis: layout.Vertical}}
+		codeStyle = text.Font{Variant: "Mono"}
+	)
+	minimap := func(gtx C) D {
+		return layoutMinimap(gtx, lines)
+	}
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			layout.Flex{}.Layout(gtx,
+				layout.Flexed(.4, func(gtx C) D {
+					return rowsPane.Layout(gtx, rows, nil, func(gtx C, i int) D {
+						return layout.UniformInset(unit.Dp(4)).Layout(gtx,
+							material.Body1(th, fmt.Sprintf("Row %d of %d", i+1, rows)).Layout,
+						)
+					})
+				}),
+				layout.Flexed(.6, func(gtx C) D {
+					return codePane.Layout(gtx, len(lines), minimap, func(gtx C, i int) D {
+						l := material.Body2(th, strings.ReplaceAll(lines[i], "\t", "    "))
+						l.Font = codeStyle
+						l.MaxLines = 1
+						return layout.Inset{Left: unit.Dp(8)}.Layout(gtx, l.Layout)
+					})
+				}),
+			)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+var (
+	codeColor    = color.NRGBA{R: 0x30, G: 0x30, B: 0x60, A: 0x90}
+	commentColor = color.NRGBA{R: 0x30, G: 0x80, B: 0x30, A: 0x90}
+)
+
+// layoutMinimap draws every line of code as a thin bar proportional to
+// its length, the way code editors render their overview.
+func layoutMinimap(gtx C, lines []string) D {
+	size := gtx.Constraints.Max
+	if len(lines) == 0 {
+		return D{Size: size}
+	}
+	lineHeight := float32(size.Y) / float32(len(lines))
+	const columns = 100
+	charWidth := float32(size.X) / columns
+	for i, line := range lines {
+		indent := len(line) - len(strings.TrimLeft(line, "\t"))
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		col := codeColor
+		if strings.HasPrefix(trimmed, "//") {
+			col = commentColor
+		}
+		x0 := float32(indent*4) * charWidth
+		x1 := x0 + float32(len(trimmed))*charWidth
+		y0 := float32(i) * lineHeight
+		y1 := y0 + lineHeight*.7
+		if y1-y0 < 1 {
+			y1 = y0 + 1
+		}
+		r := image.Rect(int(x0), int(y0), int(x1+.5), int(y1+.5))
+		paint.FillShape(gtx.Ops, col, clip.Rect(r).Op())
+	}
+	return D{Size: size}
+}
@@ -0,0 +1,307 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates storing secrets in the credential store of the
+// platform through internal/keyring: the Keychain on macOS, the Credential
+// Manager on Windows and the Secret Service through libsecret on Linux and
+// the BSDs. Secrets are only read from the store when revealed. The names
+// of the secrets, which aren't secret, are kept in a file in the user
+// configuration directory, because the stores can't be listed uniformly.
+
+import (
+	"encoding/json"
+	"errors"
+	"image/color"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/keyring"
+	"gioui.org/example/internal/style"
+	"gioui.org/font/gofont"
+	"gioui.org/io/clipboard"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+// service is the keyring service of the secrets.
+const service = "gioui.org/example/secrets"
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Secrets"),
+			app.Size(unit.Dp(520), unit.Dp(560)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// entry is a stored secret.
+type entry struct {
+	name string
+	// secret is the revealed secret, or empty.
+	secret string
+
+	reveal, copy, delete widget.Clickable
+}
+
+type App struct {
+	keys    keyring.Keyring
+	entries []*entry
+	status  string
+	failed  bool
+
+	name, secret widget.Editor
+	save         widget.Clickable
+	list         layout.List
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		keys:   keyring.Default(),
+		name:   widget.Editor{SingleLine: true, Submit: true},
+		secret: widget.Editor{SingleLine: true, Submit: true, Mask: '•'},
+		list:   layout.List{Axis: layout.Vertical},
+	}
+	names, err := loadNames()
+	if err != nil {
+		a.setError(err)
+	}
+	for _, n := range names {
+		a.entries = append(a.entries, &entry{name: n})
+	}
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			a.update(gtx)
+			layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+				return a.Layout(gtx, th)
+			})
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+func (a *App) setError(err error) {
+	a.status = err.Error()
+	a.failed = true
+}
+
+func (a *App) setStatus(msg string) {
+	a.status = msg
+	a.failed = false
+}
+
+func (a *App) update(gtx C) {
+	submitted := false
+	for _, ed := range []*widget.Editor{&a.name, &a.secret} {
+		for _, e := range ed.Events() {
+			if _, ok := e.(widget.SubmitEvent); ok {
+				submitted = true
+			}
+		}
+	}
+	for a.save.Clicked() {
+		submitted = true
+	}
+	if submitted && a.name.Text() != "" {
+		a.store(a.name.Text(), a.secret.Text())
+	}
+	for i := 0; i < len(a.entries); i++ {
+		e := a.entries[i]
+		for e.reveal.Clicked() {
+			if e.secret != "" {
+				e.secret = ""
+				continue
+			}
+			s, err := a.keys.Get(service, e.name)
+			if err != nil {
+				a.setError(err)
+				continue
+			}
+			e.secret = s
+		}
+		for e.copy.Clicked() {
+			s, err := a.keys.Get(service, e.name)
+			if err != nil {
+				a.setError(err)
+				continue
+			}
+			clipboard.WriteOp{Text: s}.Add(gtx.Ops)
+			a.setStatus("Copied " + e.name + ".")
+		}
+		for e.delete.Clicked() {
+			if err := a.keys.Delete(service, e.name); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+				a.setError(err)
+				continue
+			}
+			a.entries = append(a.entries[:i], a.entries[i+1:]...)
+			i--
+			a.saveNames()
+			a.setStatus("Deleted " + e.name + ".")
+			break
+		}
+	}
+}
+
+// store saves a secret, replacing any secret with the same name.
+func (a *App) store(name, secret string) {
+	if err := a.keys.Set(service, name, secret); err != nil {
+		a.setError(err)
+		return
+	}
+	a.name.SetText("")
+	a.secret.SetText("")
+	a.name.Focus()
+	a.setStatus("Saved " + name + ".")
+	for _, e := range a.entries {
+		if e.name == name {
+			e.secret = ""
+			return
+		}
+	}
+	a.entries = append(a.entries, &entry{name: name})
+	sort.Slice(a.entries, func(i, j int) bool {
+		return a.entries[i].name < a.entries[j].name
+	})
+	a.saveNames()
+}
+
+func (a *App) saveNames() {
+	names := make([]string, len(a.entries))
+	for i, e := range a.entries {
+		names[i] = e.name
+	}
+	if err := storeNames(names); err != nil {
+		a.setError(err)
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, func(gtx C) D {
+					f := style.Field(th, &a.name, "Name")
+					f.Inset = layout.UniformInset(unit.Dp(8))
+					return f.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+				layout.Flexed(1, func(gtx C) D {
+					f := style.Field(th, &a.secret, "Secret")
+					f.Inset = layout.UniformInset(unit.Dp(8))
+					return f.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+				layout.Rigid(func(gtx C) D {
+					if a.name.Text() == "" {
+						gtx = gtx.Disabled()
+					}
+					return material.Button(th, &a.save, "Save").Layout(gtx)
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			l := material.Body2(th, a.status)
+			if a.failed {
+				l.Color = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+			}
+			return layout.Inset{Top: unit.Dp(8), Bottom: unit.Dp(8)}.Layout(gtx, l.Layout)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			if len(a.entries) == 0 {
+				return material.Body1(th, "No secrets stored.").Layout(gtx)
+			}
+			return a.list.Layout(gtx, len(a.entries), func(gtx C, i int) D {
+				return a.entries[i].Layout(gtx, th)
+			})
+		}),
+	)
+}
+
+func (e *entry) Layout(gtx C, th *material.Theme) D {
+	shown := "••••••••"
+	label := "Reveal"
+	if e.secret != "" {
+		shown = e.secret
+		label = "Hide"
+	}
+	return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(material.Body1(th, e.name).Layout),
+					layout.Rigid(material.Caption(th, shown).Layout),
+				)
+			}),
+			layout.Rigid(style.TextButton(th, &e.reveal, label)),
+			layout.Rigid(style.TextButton(th, &e.copy, "Copy")),
+			layout.Rigid(style.TextButton(th, &e.delete, "Delete")),
+		)
+	})
+}
+
+// namesFile returns the path of the file listing the secret names.
+func namesFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gio-example-secrets", "names.json"), nil
+}
+
+func loadNames() ([]string, error) {
+	path, err := namesFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return nil, err
+	}
+	var names []string
+	err = json.Unmarshal(data, &names)
+	return names, err
+}
+
+func storeNames(names []string) error {
+	path, err := namesFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(names)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// iio reads the accelerometer and gyroscope of the Industrial I/O
+// subsystem of Linux, found in convertible laptops, tablets and phones.
+type iio struct {
+	accel [3]*iioChannel
+	// gyro is nil without a gyroscope.
+	gyro *[3]*iioChannel
+}
+
+// iioChannel is an axis of a sensor, read from sysfs as a raw value
+// converted by (raw + offset) * scale.
+type iioChannel struct {
+	raw           *os.File
+	scale, offset float64
+}
+
+const iioDevices = "/sys/bus/iio/devices"
+
+func openSource() (Source, string, error) {
+	devs, _ := filepath.Glob(filepath.Join(iioDevices, "iio:device*"))
+	s := new(iio)
+	var names []string
+	for _, dev := range devs {
+		if s.accel[0] == nil {
+			if ch, err := openAxes(dev, "accel"); err == nil {
+				s.accel = ch
+				names = append(names, deviceName(dev))
+				continue
+			}
+		}
+		if s.gyro == nil {
+			if ch, err := openAxes(dev, "anglvel"); err == nil {
+				s.gyro = &ch
+				names = append(names, deviceName(dev))
+			}
+		}
+	}
+	if s.accel[0] == nil {
+		s.Close()
+		return nil, "", errors.New("no accelerometer found")
+	}
+	return s, strings.Join(names, ", "), nil
+}
+
+func deviceName(dev string) string {
+	name, err := ioutil.ReadFile(filepath.Join(dev, "name"))
+	if err != nil {
+		return filepath.Base(dev)
+	}
+	return strings.TrimSpace(string(name))
+}
+
+// openAxes opens the x, y and z channels of the sensor typ of dev.
+func openAxes(dev, typ string) ([3]*iioChannel, error) {
+	var axes [3]*iioChannel
+	for i, axis := range []string{"x", "y", "z"} {
+		prefix := filepath.Join(dev, "in_"+typ+"_"+axis)
+		f, err := os.Open(prefix + "_raw")
+		if err != nil {
+			closeAxes(axes)
+			return axes, err
+		}
+		ch := &iioChannel{raw: f, scale: 1}
+		// Scale and offset are per axis or shared by the sensor.
+		for _, name := range []string{prefix + "_scale", filepath.Join(dev, "in_"+typ+"_scale")} {
+			if v, err := readFloat(name); err == nil {
+				ch.scale = v
+				break
+			}
+		}
+		for _, name := range []string{prefix + "_offset", filepath.Join(dev, "in_"+typ+"_offset")} {
+			if v, err := readFloat(name); err == nil {
+				ch.offset = v
+				break
+			}
+		}
+		axes[i] = ch
+	}
+	return axes, nil
+}
+
+func readFloat(name string) (float64, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(strings.TrimSpace(string(b)), 64)
+}
+
+// read reads the current value of the channel. Attributes of sysfs are
+// read afresh from their start.
+func (c *iioChannel) read() (float64, error) {
+	var buf [32]byte
+	n, err := c.raw.ReadAt(buf[:], 0)
+	if n == 0 && err != nil {
+		return 0, err
+	}
+	raw, err := strconv.ParseFloat(strings.TrimSpace(string(buf[:n])), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", c.raw.Name(), err)
+	}
+	return (raw + c.offset) * c.scale, nil
+}
+
+func readAxes(axes [3]*iioChannel, dst *[3]float64) error {
+	for i, ch := range axes {
+		v, err := ch.read()
+		if err != nil {
+			return err
+		}
+		dst[i] = v
+	}
+	return nil
+}
+
+func (s *iio) Read(now time.Time) (Sample, error) {
+	smp := Sample{Time: now}
+	if err := readAxes(s.accel, &smp.Accel); err != nil {
+		return Sample{}, err
+	}
+	if s.gyro != nil {
+		if err := readAxes(*s.gyro, &smp.Gyro); err != nil {
+			return Sample{}, err
+		}
+	}
+	return smp, nil
+}
+
+func (s *iio) Gyroscope() bool {
+	return s.gyro != nil
+}
+
+func closeAxes(axes [3]*iioChannel) {
+	for _, ch := range axes {
+		if ch != nil {
+			ch.raw.Close()
+		}
+	}
+}
+
+func (s *iio) Close() error {
+	closeAxes(s.accel)
+	if s.gyro != nil {
+		closeAxes(*s.gyro)
+	}
+	return nil
+}
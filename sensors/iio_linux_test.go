@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIIO(t *testing.T) {
+	dev := t.TempDir()
+	files := map[string]string{
+		"in_accel_x_raw": "100\n",
+		"in_accel_y_raw": "-50\n",
+		"in_accel_z_raw": "1000\n",
+		"in_accel_scale": "0.00980665\n",
+		// An offset of the y axis only.
+		"in_accel_y_offset": "10\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dev, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	axes, err := openAxes(dev, "accel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeAxes(axes)
+	if _, err := openAxes(dev, "anglvel"); err == nil {
+		t.Error("opened a gyroscope that isn't there")
+	}
+	s := &iio{accel: axes}
+	smp, err := s.Read(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [3]float64{0.980665, -0.392266, 9.80665}
+	for i := range want {
+		if math.Abs(smp.Accel[i]-want[i]) > 1e-9 {
+			t.Errorf("axis %d reads %v, want %v", i, smp.Accel[i], want[i])
+		}
+	}
+	// Values are read afresh.
+	if err := ioutil.WriteFile(filepath.Join(dev, "in_accel_x_raw"), []byte("-200\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	smp, err = s.Read(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := -1.96133; math.Abs(smp.Accel[0]-want) > 1e-9 {
+		t.Errorf("x reads %v after change, want %v", smp.Accel[0], want)
+	}
+}
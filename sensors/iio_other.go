@@ -0,0 +1,12 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build !linux
+// +build !linux
+
+package main
+
+import "errors"
+
+func openSource() (Source, string, error) {
+	return nil, "", errors.New("motion sensors are not supported on this platform")
+}
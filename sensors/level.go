@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"math"
+	"time"
+
+	"gioui.org/f32"
+)
+
+// lowPass smooths a vector of readings, following changes with the time
+// constant tau. It takes out the tremor and taps from the accelerometer,
+// leaving gravity.
+type lowPass struct {
+	tau   time.Duration
+	value [3]float64
+	last  time.Time
+}
+
+// add adds a reading taken at t and returns the smoothed value.
+func (l *lowPass) add(v [3]float64, t time.Time) [3]float64 {
+	if l.last.IsZero() {
+		l.value, l.last = v, t
+		return v
+	}
+	dt := t.Sub(l.last).Seconds()
+	l.last = t
+	if dt <= 0 {
+		return l.value
+	}
+	alpha := dt / (l.tau.Seconds() + dt)
+	for i := range l.value {
+		l.value[i] += alpha * (v[i] - l.value[i])
+	}
+	return l.value
+}
+
+// tilt returns the angles of the right edge and the top of the device
+// above the horizontal, in degrees, from the direction of gravity.
+func tilt(g [3]float64) (right, top float64) {
+	n := math.Sqrt(g[0]*g[0] + g[1]*g[1] + g[2]*g[2])
+	if n == 0 {
+		return 0, 0
+	}
+	deg := func(v float64) float64 {
+		return math.Asin(math.Max(-1, math.Min(1, v/n))) * 180 / math.Pi
+	}
+	return deg(g[0]), deg(g[1])
+}
+
+// levelRange is the tilt, in degrees, that moves the bubble to the edge
+// of the level.
+const levelRange = 20
+
+// bubble returns the position of the bubble of a level for the tilts,
+// relative to a level of radius 1. The bubble floats to the higher side
+// and stays within the level.
+func bubble(right, top float64) f32.Point {
+	p := f32.Pt(float32(right/levelRange), float32(-top/levelRange))
+	if l := math.Hypot(float64(p.X), float64(p.Y)); l > 1 {
+		p = p.Mul(float32(1 / l))
+	}
+	return p
+}
+
+// spring moves a point towards a target as a critically damped spring:
+// as fast as it can without overshooting.
+type spring struct {
+	// omega is the stiffness, in radians per second.
+	omega    float64
+	pos, vel f32.Point
+	last     time.Time
+}
+
+// step advances the spring to the time now and returns its position,
+// and whether it is still moving.
+func (s *spring) step(target f32.Point, now time.Time) (f32.Point, bool) {
+	if s.last.IsZero() {
+		s.pos, s.last = target, now
+		return s.pos, false
+	}
+	dt := now.Sub(s.last).Seconds()
+	s.last = now
+	if dt <= 0 {
+		return s.pos, true
+	}
+	// The exact solution of x'' = -ω²x - 2ωx' over dt, for x the
+	// distance to the target.
+	w := s.omega
+	x := s.pos.Sub(target)
+	v := s.vel
+	e := float32(math.Exp(-w * dt))
+	c := v.Add(x.Mul(float32(w)))
+	s.pos = target.Add(x.Add(c.Mul(float32(dt))).Mul(e))
+	s.vel = v.Sub(c.Mul(float32(w * dt))).Mul(e)
+	const rest = 1e-4
+	moving := math.Hypot(float64(s.pos.X-target.X), float64(s.pos.Y-target.Y)) > rest ||
+		math.Hypot(float64(s.vel.X), float64(s.vel.Y)) > rest
+	if !moving {
+		s.pos, s.vel = target, f32.Point{}
+	}
+	return s.pos, moving
+}
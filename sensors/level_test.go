@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"gioui.org/f32"
+)
+
+func TestTilt(t *testing.T) {
+	const g = standardGravity
+	tests := []struct {
+		accel      [3]float64
+		right, top float64
+		bubble     f32.Point
+	}{
+		{[3]float64{0, 0, g}, 0, 0, f32.Point{}},
+		// The right edge raised by 10°: the bubble floats right.
+		{[3]float64{g * math.Sin(10*math.Pi/180), 0, g * math.Cos(10*math.Pi/180)}, 10, 0, f32.Pt(0.5, 0)},
+		// The top raised by 10°: the bubble floats up.
+		{[3]float64{0, g * math.Sin(10*math.Pi/180), g * math.Cos(10*math.Pi/180)}, 0, 10, f32.Pt(0, -0.5)},
+		// Standing upright: the bubble stays at the edge.
+		{[3]float64{0, g, 0}, 0, 90, f32.Pt(0, -1)},
+	}
+	for _, test := range tests {
+		right, top := tilt(test.accel)
+		if math.Abs(right-test.right) > 1e-9 || math.Abs(top-test.top) > 1e-9 {
+			t.Errorf("tilt(%v) = %v, %v, want %v, %v", test.accel, right, top, test.right, test.top)
+		}
+		b := bubble(right, top)
+		if d := b.Sub(test.bubble); math.Hypot(float64(d.X), float64(d.Y)) > 1e-6 {
+			t.Errorf("bubble(%v, %v) = %v, want %v", right, top, b, test.bubble)
+		}
+	}
+}
+
+func TestLowPass(t *testing.T) {
+	l := lowPass{tau: 100 * time.Millisecond}
+	start := time.Now()
+	l.add([3]float64{0, 0, 0}, start)
+	// After a step, a low-pass filter reaches 1 - 1/e of it in tau.
+	var v [3]float64
+	for i := 1; i <= 1000; i++ {
+		v = l.add([3]float64{1, 0, 0}, start.Add(time.Duration(i)*100*time.Microsecond))
+	}
+	if want := 1 - 1/math.E; math.Abs(v[0]-want) > 0.01 {
+		t.Errorf("after tau, filtered step is %v, want %v", v[0], want)
+	}
+}
+
+func TestSpring(t *testing.T) {
+	s := spring{omega: 12}
+	now := time.Now()
+	s.step(f32.Point{}, now)
+	target := f32.Pt(1, 0)
+	moving := true
+	for i := 0; i < 200 && moving; i++ {
+		now = now.Add(16 * time.Millisecond)
+		var p f32.Point
+		p, moving = s.step(target, now)
+		if p.X > 1 {
+			t.Fatalf("spring overshot to %v", p)
+		}
+	}
+	if moving {
+		t.Errorf("spring still moving after 200 frames at %v", s.pos)
+	}
+	if s.pos != target {
+		t.Errorf("spring rests at %v, want %v", s.pos, target)
+	}
+}
@@ -0,0 +1,421 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program streams the accelerometer and gyroscope into live charts
+// and drives a bubble level with the tilt of the device. Sensors are
+// read on a goroutine at a high rate, much faster than frames are drawn;
+// each frame takes the samples read since the last, adds them to the
+// charts and feeds the smoothed direction of gravity into the spring the
+// bubble moves with.
+//
+// Reading stops while the window is in the background and resumes in
+// the foreground.
+//
+// The sensors are read through the Industrial I/O subsystem of Linux,
+// which convertible laptops and tablets have. Gio has no sensor API of
+// its own, so elsewhere, without sensors or with -simulate, a simulated
+// phone held in a hand stands in for them.
+//
+// Usage:
+//
+//	go run ./sensors [-rate 200] [-simulate]
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+var (
+	rateFlag     = flag.Int("rate", 200, "samples per second")
+	simulateFlag = flag.Bool("simulate", false, "simulate the sensors of a phone")
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	axisColors = [3]color.NRGBA{
+		{R: 0xe5, G: 0x39, B: 0x35, A: 0xff},
+		{R: 0x43, G: 0xa0, B: 0x47, A: 0xff},
+		{R: 0x1e, G: 0x88, B: 0xe5, A: 0xff},
+	}
+	levelColor  = color.NRGBA{R: 0xc0, G: 0xca, B: 0x33, A: 0xff}
+	bubbleColor = color.NRGBA{R: 0xf9, G: 0xfb, B: 0xe7, A: 0xff}
+)
+
+const (
+	// window is the time across the charts.
+	window = 5 * time.Second
+	// accelRange and gyroRange are the values at the top of the charts.
+	accelRange = 20
+	gyroRange  = 4
+	// levelOK is the tilt, in degrees, within which the device is level.
+	levelOK = 1
+)
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Sensors"),
+			app.Size(unit.Dp(1000), unit.Dp(640)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// open opens the sensors of the device, falling back to the simulator.
+func open() (Source, string, error) {
+	if *simulateFlag {
+		return newSimulator(), "Simulated phone", nil
+	}
+	src, desc, err := openSource()
+	if err != nil {
+		return newSimulator(), "Simulated phone", err
+	}
+	return src, desc, nil
+}
+
+// feed hands the samples read on a goroutine to the frames.
+type feed struct {
+	mu      sync.Mutex
+	pending []Sample
+	err     error
+}
+
+func (f *feed) push(s Sample, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err != nil {
+		f.err = err
+		return
+	}
+	f.pending = append(f.pending, s)
+}
+
+// take appends the samples read since the last take to dst.
+func (f *feed) take(dst []Sample) ([]Sample, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	dst = append(dst, f.pending...)
+	f.pending = f.pending[:0]
+	return dst, f.err
+}
+
+// read samples src at rate until stop is closed.
+func read(src Source, rate int, f *feed, changed func(), stop <-chan struct{}) {
+	t := time.NewTicker(time.Second / time.Duration(rate))
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-t.C:
+			s, err := src.Read(now)
+			f.push(s, err)
+			changed()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+type App struct {
+	src    Source
+	source string
+	err    error
+
+	feed *feed
+	// stop stops reading; nil while in the background.
+	stop chan struct{}
+
+	// history holds the samples of the last window, oldest first.
+	history []Sample
+	gravity lowPass
+	bubble  spring
+	right   float64
+	top     float64
+
+	// The rates of samples and frames, measured over the last second.
+	samples, frames int
+	countStart      time.Time
+	sampleRate      float64
+	frameRate       float64
+	perFrame        float64
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	src, desc, err := open()
+	defer src.Close()
+	a := &App{
+		src:     src,
+		source:  desc,
+		err:     err,
+		feed:    new(feed),
+		gravity: lowPass{tau: 150 * time.Millisecond},
+		bubble:  spring{omega: 12},
+	}
+	defer a.pause()
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.StageEvent:
+			if e.Stage >= system.StageRunning {
+				a.resume(w.Invalidate)
+			} else {
+				a.pause()
+			}
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			a.update(gtx.Now)
+			a.Layout(gtx, th)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+func (a *App) resume(changed func()) {
+	if a.stop != nil {
+		return
+	}
+	a.stop = make(chan struct{})
+	go read(a.src, *rateFlag, a.feed, changed, a.stop)
+}
+
+func (a *App) pause() {
+	if a.stop != nil {
+		close(a.stop)
+		a.stop = nil
+	}
+}
+
+// update adds the samples read since the last frame.
+func (a *App) update(now time.Time) {
+	n := len(a.history)
+	var err error
+	a.history, err = a.feed.take(a.history)
+	if err != nil {
+		a.err = err
+	}
+	for _, s := range a.history[n:] {
+		g := a.gravity.add(s.Accel, s.Time)
+		a.right, a.top = tilt(g)
+	}
+	// Drop the samples older than the window.
+	old := 0
+	for old < len(a.history) && now.Sub(a.history[old].Time) > window {
+		old++
+	}
+	if old > 0 {
+		a.history = append(a.history[:0], a.history[old:]...)
+	}
+
+	a.samples += len(a.history) - n + old
+	a.frames++
+	if a.countStart.IsZero() {
+		a.countStart = now
+	}
+	if d := now.Sub(a.countStart); d >= time.Second {
+		a.sampleRate = float64(a.samples) / d.Seconds()
+		a.frameRate = float64(a.frames) / d.Seconds()
+		a.perFrame = float64(a.samples) / float64(a.frames)
+		a.samples, a.frames, a.countStart = 0, 0, now
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	paint.Fill(gtx.Ops, color.NRGBA{R: 0xfa, G: 0xfa, B: 0xfa, A: 0xff})
+	return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				status := fmt.Sprintf("%s · %.0f samples/s · %.0f frames/s · %.1f samples per frame",
+					a.source, a.sampleRate, a.frameRate, a.perFrame)
+				if a.stop == nil {
+					status = a.source + " · paused in the background"
+				}
+				return material.Body2(th, status).Layout(gtx)
+			}),
+			layout.Rigid(func(gtx C) D {
+				if a.err == nil {
+					return D{}
+				}
+				l := material.Caption(th, a.err.Error())
+				l.Color = errorColor
+				return l.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+			layout.Flexed(1, func(gtx C) D {
+				return layout.Flex{}.Layout(gtx,
+					layout.Flexed(1, func(gtx C) D {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Flexed(1, func(gtx C) D {
+								return a.chart(gtx, th, "Accelerometer", "m/s²", accelRange, func(s Sample) [3]float64 { return s.Accel })
+							}),
+							layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+							layout.Flexed(1, func(gtx C) D {
+								if !a.src.Gyroscope() {
+									return layout.Center.Layout(gtx, material.Body2(th, "No gyroscope").Layout)
+								}
+								return a.chart(gtx, th, "Gyroscope", "rad/s", gyroRange, func(s Sample) [3]float64 { return s.Gyro })
+							}),
+						)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+					layout.Rigid(func(gtx C) D {
+						return a.layoutLevel(gtx, th)
+					}),
+				)
+			}),
+		)
+	})
+}
+
+// chart draws the x, y and z values of the history over the window,
+// from -max to max.
+func (a *App) chart(gtx C, th *material.Theme, title, unitName string, max float64, values func(Sample) [3]float64) D {
+	var latest [3]float64
+	if n := len(a.history); n > 0 {
+		latest = values(a.history[n-1])
+	}
+	return widget.Border{Color: color.NRGBA{A: 0x30}, CornerRadius: unit.Dp(4), Width: unit.Dp(1)}.Layout(gtx, func(gtx C) D {
+		return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+			gtx.Constraints.Min = gtx.Constraints.Max
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					children := []layout.FlexChild{
+						layout.Flexed(1, material.Body2(th, title+" ("+unitName+")").Layout),
+					}
+					for i, name := range []string{"x", "y", "z"} {
+						i, name := i, name
+						children = append(children, layout.Rigid(func(gtx C) D {
+							l := material.Caption(th, fmt.Sprintf("%s %+6.2f", name, latest[i]))
+							l.Color = axisColors[i]
+							return layout.Inset{Left: unit.Dp(12)}.Layout(gtx, l.Layout)
+						}))
+					}
+					return layout.Flex{}.Layout(gtx, children...)
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					size := gtx.Constraints.Max
+					defer op.Save(gtx.Ops).Load()
+					clip.Rect{Max: size}.Add(gtx.Ops)
+					mid := float32(size.Y) / 2
+					paint.FillShape(gtx.Ops, color.NRGBA{A: 0x30}, clip.Rect{Min: image.Pt(0, int(mid)), Max: image.Pt(size.X, int(mid)+1)}.Op())
+					for i := range axisColors {
+						a.line(gtx, size, max, func(s Sample) float64 { return values(s)[i] }, axisColors[i])
+					}
+					return D{Size: size}
+				}),
+			)
+		})
+	})
+}
+
+// line strokes the values of the history, the newest at the right edge.
+func (a *App) line(gtx C, size image.Point, max float64, value func(Sample) float64, col color.NRGBA) {
+	if len(a.history) < 2 {
+		return
+	}
+	w, h := float32(size.X), float32(size.Y)
+	newest := a.history[len(a.history)-1].Time
+	var p clip.Path
+	p.Begin(gtx.Ops)
+	for i, s := range a.history {
+		x := w - float32(newest.Sub(s.Time).Seconds()/window.Seconds())*w
+		v := math.Max(-max, math.Min(max, value(s)))
+		y := h/2 - float32(v/max)*h/2
+		if i == 0 {
+			p.MoveTo(f32.Pt(x, y))
+		} else {
+			p.LineTo(f32.Pt(x, y))
+		}
+	}
+	paint.FillShape(gtx.Ops, col, clip.Stroke{
+		Path:  p.End(),
+		Style: clip.StrokeStyle{Width: float32(gtx.Px(unit.Dp(1.5))), Join: clip.RoundJoin},
+	}.Op())
+}
+
+// layoutLevel draws a bubble level: a vial with rings every few degrees
+// and the bubble, which springs towards the position of the tilt.
+func (a *App) layoutLevel(gtx C, th *material.Theme) D {
+	size := gtx.Px(unit.Dp(280))
+	pos, moving := a.bubble.step(bubble(a.right, a.top), gtx.Now)
+	if moving {
+		// Samples read invalidate the window, but the bubble may
+		// still be settling when they stop.
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+	level := math.Abs(a.right) < levelOK && math.Abs(a.top) < levelOK
+	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			r := float32(size) / 2
+			c := f32.Pt(r, r)
+			vial := levelColor
+			if level {
+				vial = axisColors[1]
+			}
+			paint.FillShape(gtx.Ops, vial, clip.Circle{Center: c, Radius: r}.Op(gtx.Ops))
+			ring := color.NRGBA{A: 0x50}
+			width := float32(gtx.Px(unit.Dp(1)))
+			for deg := 5; deg < levelRange; deg += 5 {
+				paint.FillShape(gtx.Ops, ring, clip.Stroke{
+					Path:  clip.Circle{Center: c, Radius: r * float32(deg) / levelRange}.Path(gtx.Ops),
+					Style: clip.StrokeStyle{Width: width},
+				}.Op())
+			}
+			// Crosshairs.
+			paint.FillShape(gtx.Ops, ring, clip.Rect{Min: image.Pt(0, int(r)), Max: image.Pt(size, int(r)+1)}.Op())
+			paint.FillShape(gtx.Ops, ring, clip.Rect{Min: image.Pt(int(r), 0), Max: image.Pt(int(r)+1, size)}.Op())
+			br := r / 6
+			// Keep the bubble inside the vial.
+			b := c.Add(pos.Mul(r - br))
+			paint.FillShape(gtx.Ops, bubbleColor, clip.Circle{Center: b, Radius: br}.Op(gtx.Ops))
+			paint.FillShape(gtx.Ops, color.NRGBA{A: 0x60}, clip.Stroke{
+				Path:  clip.Circle{Center: b, Radius: br}.Path(gtx.Ops),
+				Style: clip.StrokeStyle{Width: width},
+			}.Op())
+			return D{Size: image.Pt(size, size)}
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+		layout.Rigid(func(gtx C) D {
+			msg := fmt.Sprintf("Right edge %+.1f°, top %+.1f°", a.right, a.top)
+			if level {
+				msg = "Level"
+			}
+			return material.H6(th, msg).Layout(gtx)
+		}),
+	)
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Sample is a reading of the motion sensors, in the axes of the device:
+// x to the right of the screen, y to its top and z out of it.
+type Sample struct {
+	Time time.Time
+	// Accel is the acceleration in m/s², including gravity: a device
+	// lying flat reads about +9.81 on z.
+	Accel [3]float64
+	// Gyro is the rate of rotation about the axes in rad/s,
+	// counterclockwise looking down the axis.
+	Gyro [3]float64
+}
+
+// Source reads the motion sensors.
+type Source interface {
+	// Read returns the current reading.
+	Read(now time.Time) (Sample, error)
+	// Gyroscope reports whether the readings include rotation.
+	Gyroscope() bool
+	Close() error
+}
+
+// standardGravity is in m/s².
+const standardGravity = 9.80665
+
+// simulator is a phone held in a hand: slowly tilting about, with the
+// tremor of the hand and now and then a tap on the screen.
+type simulator struct {
+	start time.Time
+	rnd   *rand.Rand
+}
+
+func newSimulator() *simulator {
+	return &simulator{start: time.Now(), rnd: rand.New(rand.NewSource(1))}
+}
+
+// angles returns the tilt of the right edge and the top of the device
+// t seconds in, in radians.
+func (s *simulator) angles(t float64) (right, top float64) {
+	right = 0.25*math.Sin(t*0.7) + 0.08*math.Sin(t*2.3)
+	top = 0.2*math.Sin(t*0.45+1) + 0.05*math.Sin(t*3.1)
+	return right, top
+}
+
+func (s *simulator) Read(now time.Time) (Sample, error) {
+	t := now.Sub(s.start).Seconds()
+	right, top := s.angles(t)
+	// Gravity in the axes of the tilted device.
+	sr, cr := math.Sincos(right)
+	st, ct := math.Sincos(top)
+	smp := Sample{
+		Time: now,
+		Accel: [3]float64{
+			standardGravity * sr,
+			standardGravity * st * cr,
+			standardGravity * ct * cr,
+		},
+	}
+	// The rotation rates are the rates of change of the tilts: raising
+	// the top turns about x, raising the right edge turns about -y.
+	const h = 1e-3
+	r2, t2 := s.angles(t + h)
+	smp.Gyro = [3]float64{(t2 - top) / h, -(r2 - right) / h, 0}
+	for i := range smp.Accel {
+		smp.Accel[i] += s.rnd.NormFloat64() * 0.08
+		smp.Gyro[i] += s.rnd.NormFloat64() * 0.02
+	}
+	// A tap every four seconds, lasting a few samples.
+	if math.Mod(t, 4) < 0.03 {
+		smp.Accel[2] -= 6
+		smp.Gyro[0] += 0.6
+	}
+	return smp, nil
+}
+
+func (s *simulator) Gyroscope() bool {
+	return true
+}
+
+func (s *simulator) Close() error {
+	return nil
+}
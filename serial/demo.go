@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// demo simulates a sensor board on a serial line: it reports readings
+// every second, warns now and then, and answers commands. Its lines end
+// in CRLF, like many firmwares' do.
+type demo struct {
+	out  *io.PipeReader
+	cmds chan string
+	done chan struct{}
+	once sync.Once
+
+	// partial is the command being written, not yet ended.
+	partial []byte
+}
+
+// device is the state of the simulated board.
+type device struct {
+	rnd  *rand.Rand
+	led  bool
+	temp float64
+	// uptime is the number of readings since reset.
+	uptime int
+}
+
+func newDemo() io.ReadWriteCloser {
+	r, w := io.Pipe()
+	d := &demo{
+		out:  r,
+		cmds: make(chan string, 16),
+		done: make(chan struct{}),
+	}
+	go d.run(w)
+	return d
+}
+
+func newDevice() *device {
+	return &device{rnd: rand.New(rand.NewSource(time.Now().UnixNano())), temp: 21.5}
+}
+
+func (d *demo) run(w *io.PipeWriter) {
+	defer w.Close()
+	dev := newDevice()
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+	send := func(lines []string) bool {
+		for _, l := range lines {
+			if _, err := io.WriteString(w, l+"\r\n"); err != nil {
+				return false
+			}
+		}
+		return true
+	}
+	if !send(dev.boot()) {
+		return
+	}
+	for {
+		var lines []string
+		select {
+		case <-d.done:
+			return
+		case <-t.C:
+			lines = dev.tick()
+		case cmd := <-d.cmds:
+			lines = dev.respond(cmd)
+		}
+		if !send(lines) {
+			return
+		}
+	}
+}
+
+// Read reads the output of the device.
+func (d *demo) Read(p []byte) (int, error) {
+	return d.out.Read(p)
+}
+
+// Write sends commands to the device, one per line.
+func (d *demo) Write(p []byte) (int, error) {
+	for _, b := range p {
+		if b != '\r' && b != '\n' {
+			d.partial = append(d.partial, b)
+			continue
+		}
+		if len(d.partial) == 0 {
+			continue
+		}
+		cmd := string(d.partial)
+		d.partial = d.partial[:0]
+		select {
+		case d.cmds <- cmd:
+		case <-d.done:
+			return 0, io.ErrClosedPipe
+		}
+	}
+	return len(p), nil
+}
+
+func (d *demo) Close() error {
+	d.once.Do(func() {
+		close(d.done)
+		d.out.Close()
+	})
+	return nil
+}
+
+func (dev *device) boot() []string {
+	dev.uptime = 0
+	return []string{
+		"",
+		"demo-board v1.4.2 (sensor firmware)",
+		"INFO ready, type help for commands",
+	}
+}
+
+// tick returns the lines of a reading.
+func (dev *device) tick() []string {
+	dev.uptime++
+	dev.temp += dev.rnd.NormFloat64() * 0.2
+	lines := []string{fmt.Sprintf("INFO t=%ds temp=%.1fC humidity=%d%%", dev.uptime, dev.temp, 40+dev.rnd.Intn(10))}
+	if dev.rnd.Intn(15) == 0 {
+		lines = append(lines, fmt.Sprintf("WARN i2c retry on sensor 0x%02x", 0x40+dev.rnd.Intn(4)))
+	}
+	return lines
+}
+
+// respond returns the answer to a command.
+func (dev *device) respond(cmd string) []string {
+	switch strings.Join(strings.Fields(strings.ToLower(cmd)), " ") {
+	case "help":
+		return []string{
+			"commands:",
+			"  help     this text",
+			"  led      LED state",
+			"  led on   turn the LED on",
+			"  led off  turn the LED off",
+			"  temp     read the temperature",
+			"  reset    restart the board",
+		}
+	case "led":
+		return []string{"led " + onOff(dev.led)}
+	case "led on":
+		dev.led = true
+		return []string{"OK led on"}
+	case "led off":
+		dev.led = false
+		return []string{"OK led off"}
+	case "temp":
+		return []string{fmt.Sprintf("temp %.2fC", dev.temp)}
+	case "reset":
+		dev.led = false
+		return append([]string{"resetting..."}, dev.boot()...)
+	default:
+		return []string{fmt.Sprintf("ERROR unknown command %q, type help for commands", cmd)}
+	}
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"bufio"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestRespond(t *testing.T) {
+	dev := &device{rnd: rand.New(rand.NewSource(1)), temp: 20}
+	tests := []struct {
+		cmd, want string
+	}{
+		{"led", "led off"},
+		{"LED  on", "OK led on"},
+		{"led", "led on"},
+		{"temp", "temp 20.00C"},
+		{"reset", "resetting..."},
+		{"led", "led off"},
+		{"blink", `ERROR unknown command "blink", type help for commands`},
+	}
+	for _, test := range tests {
+		if got := dev.respond(test.cmd)[0]; got != test.want {
+			t.Errorf("respond(%q) = %q, want %q", test.cmd, got, test.want)
+		}
+	}
+}
+
+func TestDemo(t *testing.T) {
+	d := newDemo()
+	defer d.Close()
+	r := bufio.NewReader(d)
+	// Send a command split between writes, ended by CRLF.
+	for _, s := range []string{"te", "mp\r", "\n"} {
+		if _, err := d.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(line, "\r\n") {
+			t.Errorf("line %q doesn't end in CRLF", line)
+		}
+		if strings.HasPrefix(line, "temp ") {
+			break
+		}
+	}
+}
@@ -0,0 +1,344 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program is a console for devices on a serial port, such as
+// microcontroller boards. Pick a port and a baud rate and connect to
+// stream what the device prints into a log view, and type commands to
+// send to it. Lines are read as 8N1 data ending in LF, CR or CRLF, and
+// commands are sent with the line ending chosen.
+//
+// The demo device is listed with the ports to try the console without
+// hardware.
+//
+// Usage:
+//
+//	go run ./serial [-port /dev/ttyUSB0] [-baud 115200]
+
+import (
+	"flag"
+	"fmt"
+	"image/color"
+	"io"
+	"log"
+	"os"
+	"strconv"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/logview"
+	"gioui.org/example/internal/style"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+var (
+	portFlag = flag.String("port", "", "serial port to connect to on start")
+	baudFlag = flag.Int("baud", 115200, "baud rate")
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+// maxLines is the number of lines kept in the console.
+const maxLines = 10000
+
+// endings are the line endings commands can be sent with.
+var endings = []struct {
+	key, label, ending string
+}{
+	{"lf", "LF", "\n"},
+	{"cr", "CR", "\r"},
+	{"crlf", "CRLF", "\r\n"},
+	{"none", "None", ""},
+}
+
+var errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Serial Console"),
+			app.Size(unit.Dp(900), unit.Dp(600)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+type App struct {
+	w *app.Window
+
+	ports    []string
+	portList layout.List
+	port     widget.Enum
+	baud     widget.Enum
+	ending   widget.Enum
+
+	refresh, connect, clear, send widget.Clickable
+	cmd                           widget.Editor
+
+	buf  logview.Buffer
+	view *logview.View
+
+	// conn is the open port, or nil.
+	conn io.ReadWriteCloser
+	// connected describes the open port.
+	connected string
+	err       error
+	readErrs  chan readErr
+}
+
+// readErr is the end of reading from a port.
+type readErr struct {
+	conn io.ReadWriteCloser
+	err  error
+}
+
+// invalidator writes to a buffer and redraws the window.
+type invalidator struct {
+	buf *logview.Buffer
+	w   *app.Window
+}
+
+func (v invalidator) Write(p []byte) (int, error) {
+	n, err := v.buf.Write(p)
+	v.w.Invalidate()
+	return n, err
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		w:        w,
+		portList: layout.List{Axis: layout.Vertical},
+		baud:     widget.Enum{Value: strconv.Itoa(*baudFlag)},
+		ending:   widget.Enum{Value: endings[0].key},
+		cmd:      widget.Editor{SingleLine: true, Submit: true},
+		buf:      logview.Buffer{Max: maxLines},
+		view:     logview.NewView(),
+		readErrs: make(chan readErr, 1),
+	}
+	a.refreshPorts()
+	if *portFlag != "" {
+		a.port.Value = *portFlag
+		a.open()
+	}
+	var ops op.Ops
+	for {
+		select {
+		case r := <-a.readErrs:
+			// Ignore the end of ports closed by disconnecting.
+			if r.conn == a.conn {
+				a.close()
+				a.err = r.err
+				if r.err == nil {
+					a.err = io.EOF
+				}
+			}
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				a.close()
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update()
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+func (a *App) update() {
+	if a.refresh.Clicked() {
+		a.refreshPorts()
+	}
+	if a.connect.Clicked() {
+		if a.conn != nil {
+			a.close()
+		} else {
+			a.open()
+		}
+	}
+	if a.clear.Clicked() {
+		a.buf.Clear()
+	}
+	submit := a.send.Clicked()
+	for _, e := range a.cmd.Events() {
+		if _, ok := e.(widget.SubmitEvent); ok {
+			submit = true
+		}
+	}
+	if submit {
+		a.sendCommand()
+	}
+}
+
+// refreshPorts lists the ports again, keeping the selected port if it
+// is still there.
+func (a *App) refreshPorts() {
+	ports, err := Ports()
+	a.ports, a.err = ports, err
+	for _, p := range ports {
+		if p == a.port.Value {
+			return
+		}
+	}
+	a.port.Value = ports[0]
+}
+
+// open connects to the selected port and starts reading from it.
+func (a *App) open() {
+	a.err = nil
+	baud, _ := strconv.Atoi(a.baud.Value)
+	conn, err := Open(a.port.Value, baud)
+	if err != nil {
+		a.err = err
+		return
+	}
+	a.conn = conn
+	a.connected = fmt.Sprintf("%s at %d baud", a.port.Value, baud)
+	a.buf.Println("--- connected to " + a.connected + " ---")
+	go func() {
+		_, err := io.Copy(invalidator{buf: &a.buf, w: a.w}, conn)
+		a.readErrs <- readErr{conn: conn, err: err}
+	}()
+}
+
+// close disconnects from the port, if connected.
+func (a *App) close() {
+	if a.conn == nil {
+		return
+	}
+	conn := a.conn
+	a.conn = nil
+	conn.Close()
+	a.buf.Println("--- disconnected ---")
+}
+
+func (a *App) sendCommand() {
+	if a.conn == nil {
+		return
+	}
+	cmd := a.cmd.Text()
+	ending := ""
+	for _, e := range endings {
+		if e.key == a.ending.Value {
+			ending = e.ending
+		}
+	}
+	if _, err := io.WriteString(a.conn, cmd+ending); err != nil {
+		a.err = err
+		return
+	}
+	a.buf.Println("> " + cmd)
+	a.cmd.SetText("")
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	return layout.Flex{}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Px(unit.Dp(220))
+			gtx.Constraints.Max.X = gtx.Constraints.Min.X
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return a.layoutSettings(gtx, th)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return a.layoutConsole(gtx, th)
+		}),
+	)
+}
+
+func (a *App) layoutSettings(gtx C, th *material.Theme) D {
+	children := []layout.FlexChild{
+		layout.Rigid(material.Body1(th, "Port").Layout),
+		layout.Flexed(1, func(gtx C) D {
+			return a.portList.Layout(gtx, len(a.ports), func(gtx C, i int) D {
+				return material.RadioButton(th, &a.port, a.ports[i], a.ports[i]).Layout(gtx)
+			})
+		}),
+		layout.Rigid(material.Button(th, &a.refresh, "Refresh").Layout),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+		layout.Rigid(material.Body1(th, "Baud rate").Layout),
+	}
+	for _, b := range bauds {
+		s := strconv.Itoa(b)
+		children = append(children, layout.Rigid(material.RadioButton(th, &a.baud, s, s).Layout))
+	}
+	label := "Connect"
+	if a.conn != nil {
+		label = "Disconnect"
+	}
+	children = append(children,
+		layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+		layout.Rigid(func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			return material.Button(th, &a.connect, label).Layout(gtx)
+		}),
+		layout.Rigid(func(gtx C) D {
+			l := material.Caption(th, "Not connected")
+			switch {
+			case a.err != nil:
+				l.Text = a.err.Error()
+				l.Color = errorColor
+			case a.conn != nil:
+				l.Text = "Connected to " + a.connected
+			}
+			return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, l.Layout)
+		}),
+	)
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+func (a *App) layoutConsole(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(material.CheckBox(th, &a.view.Follow, "Follow").Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(material.Button(th, &a.clear, "Clear").Layout),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return a.view.Layout(gtx, th, a.buf.Len(), a.buf.Last(), a.buf.Line)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+				children := []layout.FlexChild{
+					layout.Flexed(1, func(gtx C) D {
+						return style.Field(th, &a.cmd, "Command (Enter to send)").Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+				}
+				for _, e := range endings {
+					children = append(children, layout.Rigid(material.RadioButton(th, &a.ending, e.key, e.label).Layout))
+				}
+				send := material.Button(th, &a.send, "Send")
+				if a.conn == nil {
+					send.Background = color.NRGBA{A: 0x60}
+				}
+				children = append(children,
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(send.Layout),
+				)
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx, children...)
+			})
+		}),
+	)
+}
@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"errors"
+	"io"
+	"sort"
+)
+
+// bauds are the baud rates offered.
+var bauds = []int{9600, 19200, 38400, 57600, 115200, 230400, 460800, 921600}
+
+// demoPort is the name of the simulated device, listed with the ports.
+const demoPort = "Demo device"
+
+// errUnsupported is returned by openPort on platforms without serial
+// port support, where only the demo device is available.
+var errUnsupported = errors.New("serial ports are not supported on this platform")
+
+// Ports lists the serial ports, sorted, followed by the demo device.
+func Ports() ([]string, error) {
+	ports, err := listPorts()
+	sort.Strings(ports)
+	return append(ports, demoPort), err
+}
+
+// Open opens a serial port at a baud rate, 8 data bits, no parity and
+// one stop bit, without flow control.
+func Open(name string, baud int) (io.ReadWriteCloser, error) {
+	if name == demoPort {
+		return newDemo(), nil
+	}
+	return openPort(name, baud)
+}
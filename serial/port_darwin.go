@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import "golang.org/x/sys/unix"
+
+const (
+	ioctlGetTermios = unix.TIOCGETA
+	ioctlSetTermios = unix.TIOCSETA
+)
+
+// speeds are the speeds of baud rates; on macOS, they are the rates.
+var speeds = map[int]uint32{
+	9600:   9600,
+	19200:  19200,
+	38400:  38400,
+	57600:  57600,
+	115200: 115200,
+	230400: 230400,
+	460800: 460800,
+	921600: 921600,
+}
+
+func setSpeed(t *unix.Termios, speed uint32) {
+	t.Ispeed = uint64(speed)
+	t.Ospeed = uint64(speed)
+}
+
+// listPorts lists the callout devices, which open without waiting for
+// a carrier.
+func listPorts() ([]string, error) {
+	return globPorts("/dev/cu.*")
+}
@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+package main
+
+import "io"
+
+func openPort(name string, baud int) (io.ReadWriteCloser, error) {
+	return nil, errUnsupported
+}
+
+func listPorts() ([]string, error) {
+	return nil, nil
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build linux || darwin
+// +build linux darwin
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+func openPort(name string, baud int) (io.ReadWriteCloser, error) {
+	speed, ok := speeds[baud]
+	if !ok {
+		return nil, fmt.Errorf("unsupported baud rate %d", baud)
+	}
+	// Without O_NONBLOCK, opening waits for the carrier detect line on
+	// some ports. Non-blocking files are read through the runtime poller,
+	// which also lets Close interrupt a pending Read.
+	f, err := os.OpenFile(name, os.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := f.SyscallConn()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	var terr error
+	err = conn.Control(func(fd uintptr) {
+		terr = makeRaw(int(fd), speed)
+	})
+	if err == nil {
+		err = terr
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return f, nil
+}
+
+// makeRaw configures a terminal for raw 8N1 data at a speed, as
+// cfmakeraw does.
+func makeRaw(fd int, speed uint32) error {
+	t, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return err
+	}
+	t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON | unix.IXOFF
+	t.Oflag &^= unix.OPOST
+	t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	t.Cflag &^= unix.CSIZE | unix.PARENB | unix.CSTOPB | unix.CRTSCTS
+	t.Cflag |= unix.CS8 | unix.CREAD | unix.CLOCAL
+	t.Cc[unix.VMIN] = 1
+	t.Cc[unix.VTIME] = 0
+	setSpeed(t, speed)
+	return unix.IoctlSetTermios(fd, ioctlSetTermios, t)
+}
+
+// globPorts returns the device files matching patterns.
+func globPorts(patterns ...string) ([]string, error) {
+	var ports []string
+	for _, p := range patterns {
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, err
+		}
+		ports = append(ports, matches...)
+	}
+	return ports, nil
+}
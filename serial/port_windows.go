@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32            = syscall.NewLazyDLL("kernel32.dll")
+	procGetCommState    = kernel32.NewProc("GetCommState")
+	procSetCommState    = kernel32.NewProc("SetCommState")
+	procSetCommTimeouts = kernel32.NewProc("SetCommTimeouts")
+	procQueryDosDevice  = kernel32.NewProc("QueryDosDeviceW")
+)
+
+const (
+	_MAXDWORD = 0xffffffff
+	// The DCB flags: binary mode, and DTR and RTS raised while open.
+	_fBinary                   = 0x1
+	_DTR_CONTROL_ENABLE        = 0x1 << 4
+	_RTS_CONTROL_ENABLE        = 0x1 << 12
+	_ERROR_INSUFFICIENT_BUFFER = 122
+)
+
+// dcb mirrors DCB, with its bit fields in Flags.
+type dcb struct {
+	DCBlength  uint32
+	BaudRate   uint32
+	Flags      uint32
+	wReserved  uint16
+	XonLim     uint16
+	XoffLim    uint16
+	ByteSize   byte
+	Parity     byte
+	StopBits   byte
+	XonChar    byte
+	XoffChar   byte
+	ErrorChar  byte
+	EofChar    byte
+	EvtChar    byte
+	wReserved1 uint16
+}
+
+// commTimeouts mirrors COMMTIMEOUTS.
+type commTimeouts struct {
+	ReadIntervalTimeout         uint32
+	ReadTotalTimeoutMultiplier  uint32
+	ReadTotalTimeoutConstant    uint32
+	WriteTotalTimeoutMultiplier uint32
+	WriteTotalTimeoutConstant   uint32
+}
+
+type port struct {
+	h syscall.Handle
+}
+
+func openPort(name string, baud int) (io.ReadWriteCloser, error) {
+	path, err := syscall.UTF16PtrFromString(`\\.\` + name)
+	if err != nil {
+		return nil, err
+	}
+	h, err := syscall.CreateFile(path, syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil, syscall.OPEN_EXISTING, 0, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	var d dcb
+	d.DCBlength = uint32(unsafe.Sizeof(d))
+	if r, _, err := procGetCommState.Call(uintptr(h), uintptr(unsafe.Pointer(&d))); r == 0 {
+		syscall.CloseHandle(h)
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	d.BaudRate = uint32(baud)
+	d.Flags = _fBinary | _DTR_CONTROL_ENABLE | _RTS_CONTROL_ENABLE
+	d.ByteSize = 8
+	d.Parity = 0   // NOPARITY
+	d.StopBits = 0 // ONESTOPBIT
+	if r, _, err := procSetCommState.Call(uintptr(h), uintptr(unsafe.Pointer(&d))); r == 0 {
+		syscall.CloseHandle(h)
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	// Return from reads as soon as any data is there, or after 100 ms
+	// without any.
+	t := commTimeouts{
+		ReadIntervalTimeout:        _MAXDWORD,
+		ReadTotalTimeoutMultiplier: _MAXDWORD,
+		ReadTotalTimeoutConstant:   100,
+	}
+	if r, _, err := procSetCommTimeouts.Call(uintptr(h), uintptr(unsafe.Pointer(&t))); r == 0 {
+		syscall.CloseHandle(h)
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &port{h: h}, nil
+}
+
+// Read waits for data, reading again after the read timeouts until some
+// arrives or the port is closed.
+func (p *port) Read(b []byte) (int, error) {
+	for {
+		var n uint32
+		if err := syscall.ReadFile(p.h, b, &n, nil); err != nil {
+			return 0, err
+		}
+		if n > 0 || len(b) == 0 {
+			return int(n), nil
+		}
+	}
+}
+
+func (p *port) Write(b []byte) (int, error) {
+	var n uint32
+	err := syscall.WriteFile(p.h, b, &n, nil)
+	return int(n), err
+}
+
+func (p *port) Close() error {
+	return syscall.CloseHandle(p.h)
+}
+
+// listPorts lists the COM devices among the MS-DOS device names.
+func listPorts() ([]string, error) {
+	buf := make([]uint16, 16<<10)
+	for {
+		r, _, err := procQueryDosDevice.Call(0, uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+		if r != 0 {
+			buf = buf[:r]
+			break
+		}
+		if err != syscall.Errno(_ERROR_INSUFFICIENT_BUFFER) || len(buf) >= 1<<20 {
+			return nil, err
+		}
+		buf = make([]uint16, 2*len(buf))
+	}
+	var ports []string
+	for _, name := range strings.Split(syscall.UTF16ToString(buf[:len(buf):len(buf)]), "\x00") {
+		if strings.HasPrefix(name, "COM") {
+			ports = append(ports, name)
+		}
+	}
+	return ports, nil
+}
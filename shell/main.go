@@ -0,0 +1,334 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program is a template for desktop applications shaped like an
+// IDE: a toolbar, a tool panel on the left that can be resized and
+// collapsed, a central document area and a status bar showing transient
+// messages and the progress of background work.
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Shell"),
+			app.Size(unit.Dp(1000), unit.Dp(700)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// messageDuration is how long transient status messages are shown.
+const messageDuration = 3 * time.Second
+
+type file struct {
+	name     string
+	contents string
+	click    widget.Clickable
+}
+
+// task is background work reporting its progress.
+type task struct {
+	name     string
+	cancel   context.CancelFunc
+	progress chan float32
+	done     chan error
+}
+
+// startTask simulates work in steps.
+func startTask(w *app.Window, name string, steps int) *task {
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &task{
+		name:     name,
+		cancel:   cancel,
+		progress: make(chan float32, 1),
+		done:     make(chan error, 1),
+	}
+	go func() {
+		tick := time.NewTicker(50 * time.Millisecond)
+		defer tick.Stop()
+		for i := 1; i <= steps; i++ {
+			select {
+			case <-ctx.Done():
+				t.done <- ctx.Err()
+				w.Invalidate()
+				return
+			case <-tick.C:
+			}
+			// Replace any progress not yet seen by the UI.
+			select {
+			case <-t.progress:
+			default:
+			}
+			t.progress <- float32(i) / float32(steps)
+			w.Invalidate()
+		}
+		t.done <- nil
+		w.Invalidate()
+	}()
+	return t
+}
+
+type UI struct {
+	theme *material.Theme
+	files []*file
+	open  *file
+
+	split  Split
+	editor widget.Editor
+	list   layout.List
+
+	togglePanel widget.Clickable
+	save        widget.Clickable
+	build       widget.Clickable
+	cancel      widget.Clickable
+
+	running  *task
+	progress float32
+
+	message      string
+	messageUntil time.Time
+}
+
+func loop(w *app.Window) error {
+	ui := &UI{
+		theme: material.NewTheme(gofont.Collection()),
+		split: Split{Width: unit.Dp(220), Min: unit.Dp(120), Max: unit.Dp(480)},
+		list:  layout.List{Axis: layout.Vertical},
+	}
+	for _, name := range []string{"main.go", "ui.go", "split.go", "README.md", "go.mod"} {
+		ui.files = append(ui.files, &file{
+			name:     name,
+			contents: fmt.Sprintf("// %s\n\n%s", name, strings.Repeat("Lorem ipsum dolor sit amet.\n", 12)),
+		})
+	}
+	ui.openFile(ui.files[0])
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			if ui.running != nil {
+				ui.running.cancel()
+			}
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			ui.update(gtx, w)
+			ui.Layout(gtx)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+func (ui *UI) openFile(f *file) {
+	if ui.open != nil {
+		ui.open.contents = ui.editor.Text()
+	}
+	ui.open = f
+	ui.editor.SetText(f.contents)
+}
+
+// flash shows a transient message in the status bar.
+func (ui *UI) flash(now time.Time, msg string) {
+	ui.message = msg
+	ui.messageUntil = now.Add(messageDuration)
+}
+
+func (ui *UI) update(gtx C, w *app.Window) {
+	for ui.togglePanel.Clicked() {
+		ui.split.Collapsed = !ui.split.Collapsed
+	}
+	for ui.save.Clicked() {
+		ui.open.contents = ui.editor.Text()
+		ui.flash(gtx.Now, fmt.Sprintf("Saved %s", ui.open.name))
+	}
+	for ui.build.Clicked() {
+		if ui.running == nil {
+			ui.running = startTask(w, "Building", 60)
+			ui.progress = 0
+		}
+	}
+	for ui.cancel.Clicked() {
+		if ui.running != nil {
+			ui.running.cancel()
+		}
+	}
+	for _, f := range ui.files {
+		for f.click.Clicked() {
+			ui.openFile(f)
+		}
+	}
+	if t := ui.running; t != nil {
+		select {
+		case p := <-t.progress:
+			ui.progress = p
+		default:
+		}
+		select {
+		case err := <-t.done:
+			ui.running = nil
+			if err != nil {
+				ui.flash(gtx.Now, "Build cancelled")
+			} else {
+				ui.flash(gtx.Now, "Build succeeded")
+			}
+		default:
+		}
+	}
+}
+
+func (ui *UI) Layout(gtx C) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(ui.layoutToolbar),
+		layout.Flexed(1, func(gtx C) D {
+			return ui.split.Layout(gtx, ui.layoutPanel, ui.layoutDocument)
+		}),
+		layout.Rigid(ui.layoutStatusBar),
+	)
+}
+
+func (ui *UI) layoutToolbar(gtx C) D {
+	th := ui.theme
+	button := func(c *widget.Clickable, label string, enabled bool) layout.FlexChild {
+		return layout.Rigid(func(gtx C) D {
+			if !enabled {
+				gtx = gtx.Disabled()
+			}
+			b := material.Button(th, c, label)
+			b.Background = color.NRGBA{A: 0x10}
+			b.Color = th.Palette.Fg
+			b.Inset = layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(6), Left: unit.Dp(12), Right: unit.Dp(12)}
+			return layout.Inset{Right: unit.Dp(4)}.Layout(gtx, b.Layout)
+		})
+	}
+	panelLabel := "Hide panel"
+	if ui.split.Collapsed {
+		panelLabel = "Show panel"
+	}
+	return bar(gtx, func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			button(&ui.togglePanel, panelLabel, true),
+			button(&ui.save, "Save", true),
+			button(&ui.build, "Build", ui.running == nil),
+			button(&ui.cancel, "Cancel", ui.running != nil),
+		)
+	})
+}
+
+func (ui *UI) layoutPanel(gtx C) D {
+	th := ui.theme
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 0x08}, clip.Rect(image.Rectangle{Max: gtx.Constraints.Max}).Op())
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(8)).Layout(gtx, material.Body1(th, "EXPLORER").Layout)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return ui.list.Layout(gtx, len(ui.files), func(gtx C, i int) D {
+				f := ui.files[i]
+				return material.Clickable(gtx, &f.click, func(gtx C) D {
+					return layout.Stack{}.Layout(gtx,
+						layout.Expanded(func(gtx C) D {
+							if f == ui.open {
+								bg := th.Palette.ContrastBg
+								bg.A = 0x30
+								paint.FillShape(gtx.Ops, bg, clip.Rect(image.Rectangle{Max: gtx.Constraints.Min}).Op())
+							}
+							return D{Size: gtx.Constraints.Min}
+						}),
+						layout.Stacked(func(gtx C) D {
+							gtx.Constraints.Min.X = gtx.Constraints.Max.X
+							return layout.Inset{Left: unit.Dp(16), Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx, material.Body2(th, f.name).Layout)
+						}),
+					)
+				})
+			})
+		}),
+	)
+}
+
+func (ui *UI) layoutDocument(gtx C) D {
+	th := ui.theme
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Left: unit.Dp(16), Top: unit.Dp(8), Bottom: unit.Dp(8)}.Layout(gtx, material.H6(th, ui.open.name).Layout)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Inset{Left: unit.Dp(16), Right: unit.Dp(16)}.Layout(gtx, func(gtx C) D {
+				gtx.Constraints.Min = gtx.Constraints.Max
+				e := material.Editor(th, &ui.editor, "")
+				e.Font.Variant = "Mono"
+				return e.Layout(gtx)
+			})
+		}),
+	)
+}
+
+func (ui *UI) layoutStatusBar(gtx C) D {
+	th := ui.theme
+	msg := "Ready"
+	if gtx.Now.Before(ui.messageUntil) {
+		msg = ui.message
+		// Redraw when the message expires.
+		op.InvalidateOp{At: ui.messageUntil}.Add(gtx.Ops)
+	}
+	if ui.running != nil {
+		msg = fmt.Sprintf("%s… %.0f%%", ui.running.name, ui.progress*100)
+	}
+	line, col := ui.editor.CaretPos()
+	return bar(gtx, func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, material.Caption(th, msg).Layout),
+			layout.Rigid(func(gtx C) D {
+				if ui.running == nil {
+					return D{}
+				}
+				gtx.Constraints.Min.X = gtx.Px(unit.Dp(160))
+				gtx.Constraints.Max.X = gtx.Constraints.Min.X
+				return layout.Inset{Right: unit.Dp(16)}.Layout(gtx, material.ProgressBar(th, ui.progress).Layout)
+			}),
+			layout.Rigid(material.Caption(th, fmt.Sprintf("Ln %d, Col %d", line+1, col+1)).Layout),
+		)
+	})
+}
+
+// bar lays out w in a shaded strip across the window.
+func bar(gtx C, w layout.Widget) D {
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	macro := op.Record(gtx.Ops)
+	dims := layout.UniformInset(unit.Dp(4)).Layout(gtx, w)
+	call := macro.Stop()
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 0x10}, clip.Rect(image.Rectangle{Max: dims.Size}).Op())
+	call.Add(gtx.Ops)
+	return dims
+}
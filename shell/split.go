@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+)
+
+// Split lays out a panel on the left with a divider that resizes it by
+// dragging, and the remaining space on the right.
+type Split struct {
+	// Width is the width of the left panel.
+	Width    unit.Value
+	Min, Max unit.Value
+	// Collapsed hides the left panel and the divider.
+	Collapsed bool
+
+	drag  bool
+	start float32
+	// startWidth is the panel width in pixels at the start of a drag.
+	startWidth int
+}
+
+func (s *Split) Layout(gtx C, left, right layout.Widget) D {
+	if s.Collapsed {
+		return right(gtx)
+	}
+	width := gtx.Px(s.Width)
+	for _, e := range gtx.Events(s) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case pointer.Press:
+			s.drag = true
+			s.start = e.Position.X
+			s.startWidth = width
+		case pointer.Drag:
+			if !s.drag {
+				break
+			}
+			w := s.startWidth + int(e.Position.X-s.start)
+			if min := gtx.Px(s.Min); w < min {
+				w = min
+			}
+			if max := gtx.Px(s.Max); w > max {
+				w = max
+			}
+			// Store the width in device independent units, so it scales
+			// with the rest of the interface.
+			s.Width = unit.Dp(float32(w) / gtx.Metric.PxPerDp)
+			width = w
+		case pointer.Release, pointer.Cancel:
+			s.drag = false
+		}
+	}
+	if max := gtx.Constraints.Max.X; width > max {
+		width = max
+	}
+	bar := gtx.Px(unit.Dp(6))
+	height := gtx.Constraints.Max.Y
+
+	stack := op.Save(gtx.Ops)
+	lgtx := gtx
+	lgtx.Constraints = layout.Exact(image.Pt(width, height))
+	clip.Rect(image.Rectangle{Max: lgtx.Constraints.Max}).Add(gtx.Ops)
+	left(lgtx)
+	stack.Load()
+
+	// The divider line, and a wider drag area around it.
+	line := image.Rect(width+bar/2, 0, width+bar/2+gtx.Px(unit.Dp(1)), height)
+	c := color.NRGBA{A: 0x20}
+	if s.drag {
+		c.A = 0x60
+	}
+	paint.FillShape(gtx.Ops, c, clip.Rect(line).Op())
+	stack = op.Save(gtx.Ops)
+	pointer.Rect(image.Rect(width, 0, width+bar, height)).Add(gtx.Ops)
+	pointer.CursorNameOp{Name: pointer.CursorColResize}.Add(gtx.Ops)
+	pointer.InputOp{Tag: s, Grab: s.drag, Types: pointer.Press | pointer.Drag | pointer.Release}.Add(gtx.Ops)
+	stack.Load()
+
+	stack = op.Save(gtx.Ops)
+	op.Offset(layout.FPt(image.Pt(width+bar, 0))).Add(gtx.Ops)
+	rgtx := gtx
+	rgtx.Constraints = layout.Exact(image.Pt(gtx.Constraints.Max.X-width-bar, height))
+	right(rgtx)
+	stack.Load()
+	return D{Size: gtx.Constraints.Max}
+}
@@ -0,0 +1,363 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates keyboard shortcuts declared in one place with
+// package internal/shortcut: a help sheet is generated from the
+// declarations, modifiers are shown the macOS way on Apple platforms and
+// every binding can be changed while the program runs, with conflicts
+// highlighted.
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"strings"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/shortcut"
+	"gioui.org/font/gofont"
+	"gioui.org/io/key"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Shortcuts"),
+			app.Size(unit.Dp(900), unit.Dp(600)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// actions declares every shortcut of the program.
+var actions = []shortcut.Action{
+	{ID: "new", Group: "Notes", Description: "New note", Default: "Shortcut+N"},
+	{ID: "delete", Group: "Notes", Description: "Delete note", Default: "Shortcut+Backspace"},
+	{ID: "next", Group: "Navigation", Description: "Next note", Default: "Alt+Down"},
+	{ID: "prev", Group: "Navigation", Description: "Previous note", Default: "Alt+Up"},
+	{ID: "first", Group: "Navigation", Description: "First note", Default: "Shortcut+Home"},
+	{ID: "last", Group: "Navigation", Description: "Last note", Default: "Shortcut+End"},
+	{ID: "bigger", Group: "View", Description: "Increase text size", Default: "Shortcut+Up"},
+	{ID: "smaller", Group: "View", Description: "Decrease text size", Default: "Shortcut+Down"},
+	{ID: "help", Group: "View", Description: "Show keyboard shortcuts", Default: "Shortcut+/"},
+}
+
+type UI struct {
+	theme     *material.Theme
+	shortcuts *shortcut.Map
+
+	notes    []string
+	selected int
+	created  int
+	zoom     float32
+	last     string
+
+	// focus is set until the root key handler is focused.
+	focus bool
+	help  bool
+	// capturing is the action being rebound, if any.
+	capturing string
+
+	showHelp widget.Clickable
+	reset    widget.Clickable
+	rebind   map[string]*widget.Clickable
+	list     layout.List
+	sheet    layout.List
+}
+
+func loop(w *app.Window) error {
+	m, err := shortcut.NewMap(actions)
+	if err != nil {
+		return err
+	}
+	ui := &UI{
+		theme:     material.NewTheme(gofont.Collection()),
+		shortcuts: m,
+		zoom:      1,
+		focus:     true,
+		rebind:    make(map[string]*widget.Clickable),
+		list:      layout.List{Axis: layout.Vertical},
+		sheet:     layout.List{Axis: layout.Vertical},
+	}
+	for i := 0; i < 5; i++ {
+		ui.run("new")
+	}
+	ui.last = ""
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			ui.Layout(gtx)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+// run performs an action.
+func (ui *UI) run(id string) {
+	switch id {
+	case "new":
+		ui.created++
+		ui.notes = append(ui.notes, fmt.Sprintf("Note %d", ui.created))
+		ui.selected = len(ui.notes) - 1
+	case "delete":
+		if len(ui.notes) > 0 {
+			ui.notes = append(ui.notes[:ui.selected], ui.notes[ui.selected+1:]...)
+			if ui.selected == len(ui.notes) && ui.selected > 0 {
+				ui.selected--
+			}
+		}
+	case "next":
+		if ui.selected < len(ui.notes)-1 {
+			ui.selected++
+		}
+	case "prev":
+		if ui.selected > 0 {
+			ui.selected--
+		}
+	case "first":
+		ui.selected = 0
+	case "last":
+		if len(ui.notes) > 0 {
+			ui.selected = len(ui.notes) - 1
+		}
+	case "bigger":
+		if ui.zoom < 2 {
+			ui.zoom += .25
+		}
+	case "smaller":
+		if ui.zoom > .75 {
+			ui.zoom -= .25
+		}
+	case "help":
+		ui.help = !ui.help
+	}
+	for _, a := range actions {
+		if a.ID == id {
+			ui.last = a.Description
+		}
+	}
+}
+
+// keys handles the key presses delivered to the root handler: they either
+// complete a rebinding or trigger an action.
+func (ui *UI) keys(gtx C) {
+	for _, e := range gtx.Events(ui) {
+		e, ok := e.(key.Event)
+		if !ok || e.State != key.Press {
+			continue
+		}
+		if ui.capturing != "" {
+			switch {
+			case e.Name == key.NameEscape && e.Modifiers == 0:
+			case e.Name == key.NameDeleteBackward && e.Modifiers == 0:
+				ui.shortcuts.Bind(ui.capturing, shortcut.Binding{})
+			default:
+				ui.shortcuts.Bind(ui.capturing, shortcut.Binding{Modifiers: e.Modifiers, Name: e.Name})
+			}
+			ui.capturing = ""
+			continue
+		}
+		if id, ok := ui.shortcuts.Lookup(e); ok {
+			ui.run(id)
+		}
+	}
+}
+
+func (ui *UI) Layout(gtx C) D {
+	th := ui.theme
+	ui.keys(gtx)
+	for ui.showHelp.Clicked() {
+		ui.run("help")
+	}
+	for ui.reset.Clicked() {
+		ui.shortcuts.Reset()
+		ui.capturing = ""
+	}
+	for id, c := range ui.rebind {
+		for c.Clicked() {
+			ui.capturing = id
+		}
+	}
+	th.TextSize = unit.Sp(16 * ui.zoom)
+
+	// The root handler receives every key press not handled elsewhere.
+	key.InputOp{Tag: ui}.Add(gtx.Ops)
+	if ui.focus {
+		key.FocusOp{Tag: ui}.Add(gtx.Ops)
+		ui.focus = false
+	}
+
+	return layout.Flex{}.Layout(gtx,
+		layout.Flexed(1, ui.layoutNotes),
+		layout.Rigid(func(gtx C) D {
+			if !ui.help {
+				return D{}
+			}
+			gtx.Constraints.Min.X = gtx.Px(unit.Dp(400))
+			gtx.Constraints.Max.X = gtx.Constraints.Min.X
+			paint.FillShape(gtx.Ops, color.NRGBA{A: 0x0c}, clip.Rect(image.Rectangle{Max: gtx.Constraints.Max}).Op())
+			return ui.layoutSheet(gtx)
+		}),
+	)
+}
+
+func (ui *UI) layoutNotes(gtx C) D {
+	th := ui.theme
+	status := fmt.Sprintf("Press %s for keyboard shortcuts", ui.shortcuts.Binding("help"))
+	if ui.last != "" {
+		status = ui.last + ". " + status
+	}
+	return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, material.H5(th, "Notes").Layout),
+					layout.Rigid(material.Button(th, &ui.showHelp, "Shortcuts").Layout),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+			layout.Flexed(1, func(gtx C) D {
+				return ui.list.Layout(gtx, len(ui.notes), func(gtx C, i int) D {
+					return layout.Stack{}.Layout(gtx,
+						layout.Expanded(func(gtx C) D {
+							if i == ui.selected {
+								bg := th.Palette.ContrastBg
+								bg.A = 0x30
+								paint.FillShape(gtx.Ops, bg, clip.Rect(image.Rectangle{Max: gtx.Constraints.Min}).Op())
+							}
+							return D{Size: gtx.Constraints.Min}
+						}),
+						layout.Stacked(func(gtx C) D {
+							gtx.Constraints.Min.X = gtx.Constraints.Max.X
+							return layout.UniformInset(unit.Dp(8)).Layout(gtx, material.Body1(th, ui.notes[i]).Layout)
+						}),
+					)
+				})
+			}),
+			layout.Rigid(material.Body2(th, status).Layout),
+		)
+	})
+}
+
+// layoutSheet lays out the help sheet generated from the shortcut
+// declarations, grouped.
+func (ui *UI) layoutSheet(gtx C) D {
+	th := ui.theme
+	var rows []layout.Widget
+	rows = append(rows, func(gtx C) D {
+		return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, material.H6(th, "Keyboard shortcuts").Layout)
+	})
+	for _, g := range ui.shortcuts.Groups() {
+		g := g
+		rows = append(rows, func(gtx C) D {
+			return layout.Inset{Top: unit.Dp(12), Bottom: unit.Dp(4)}.Layout(gtx, material.Body1(th, g).Layout)
+		})
+		for _, a := range ui.shortcuts.Actions() {
+			if a.Group != g {
+				continue
+			}
+			a := a
+			rows = append(rows, func(gtx C) D {
+				return ui.layoutAction(gtx, a)
+			})
+		}
+	}
+	rows = append(rows, func(gtx C) D {
+		return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(material.Caption(th, "Click a shortcut, then press the new keys. Escape cancels, Backspace removes the shortcut.").Layout),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+				layout.Rigid(material.Button(th, &ui.reset, "Reset to defaults").Layout),
+			)
+		})
+	})
+	return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+		return ui.sheet.Layout(gtx, len(rows), func(gtx C, i int) D {
+			return rows[i](gtx)
+		})
+	})
+}
+
+func (ui *UI) layoutAction(gtx C, a shortcut.Action) D {
+	th := ui.theme
+	click := ui.rebind[a.ID]
+	if click == nil {
+		click = new(widget.Clickable)
+		ui.rebind[a.ID] = click
+	}
+	conflicts := ui.shortcuts.Conflicts(a.ID)
+	binding := ui.shortcuts.Binding(a.ID).String()
+	switch {
+	case ui.capturing == a.ID:
+		binding = "Press keys…"
+	case binding == "":
+		binding = "—"
+	}
+	return layout.Inset{Top: unit.Dp(2), Bottom: unit.Dp(2)}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(material.Body2(th, a.Description).Layout),
+					layout.Rigid(func(gtx C) D {
+						if len(conflicts) == 0 {
+							return D{}
+						}
+						var names []string
+						for _, id := range conflicts {
+							for _, o := range ui.shortcuts.Actions() {
+								if o.ID == id {
+									names = append(names, o.Description)
+								}
+							}
+						}
+						l := material.Caption(th, "Conflicts with "+strings.Join(names, ", "))
+						l.Color = conflictColor
+						return l.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx C) D {
+				b := material.Button(th, click, binding)
+				b.Background = color.NRGBA{A: 0x18}
+				b.Color = th.Palette.Fg
+				if len(conflicts) > 0 {
+					b.Background = conflictColor
+					b.Color = th.Palette.ContrastFg
+				}
+				if ui.capturing == a.ID {
+					b.Background = th.Palette.ContrastBg
+					b.Color = th.Palette.ContrastFg
+				}
+				b.Inset = layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4), Left: unit.Dp(10), Right: unit.Dp(10)}
+				return b.Layout(gtx)
+			}),
+		)
+	})
+}
+
+var conflictColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BlockKind is the kind of a Block.
+type BlockKind int
+
+const (
+	Paragraph BlockKind = iota
+	Heading
+	Bullet
+	Numbered
+	Quote
+	Code
+)
+
+// Block is a paragraph level element of a slide.
+type Block struct {
+	Kind BlockKind
+	// Level is the level of a heading, from 1, or the nesting of a list
+	// item, from 0.
+	Level int
+	// Number is the number of a numbered list item.
+	Number int
+	Spans  []Span
+	// Code holds the lines of a code block.
+	Code []string
+}
+
+// Span is a run of text in a single style.
+type Span struct {
+	Text               string
+	Bold, Italic, Mono bool
+}
+
+// Slide is a page of a presentation.
+type Slide struct {
+	Blocks []Block
+	// Notes are shown to the presenter only.
+	Notes string
+}
+
+// Title returns the text of the first heading of s.
+func (s Slide) Title() string {
+	for _, b := range s.Blocks {
+		if b.Kind == Heading {
+			var t strings.Builder
+			for _, sp := range b.Spans {
+				t.WriteString(sp.Text)
+			}
+			return t.String()
+		}
+	}
+	return ""
+}
+
+var (
+	separator = regexp.MustCompile(`^---+\s*$`)
+	numbered  = regexp.MustCompile(`^(\d+)[.)]\s+`)
+)
+
+// ParseDeck parses a presentation written in a subset of Markdown:
+// headings, paragraphs, bullet and numbered lists, quotes, fenced code,
+// and bold, italic and code spans. Lines of three or more dashes
+// separate slides, and a line starting with "Note:" starts the notes of
+// the slide.
+func ParseDeck(src string) []Slide {
+	var (
+		slides []Slide
+		cur    Slide
+		para   []string
+		code   []string
+		inCode bool
+		notes  []string
+		inNote bool
+	)
+	flushPara := func() {
+		if len(para) > 0 {
+			cur.Blocks = append(cur.Blocks, Block{Kind: Paragraph, Spans: parseInline(strings.Join(para, " "))})
+			para = nil
+		}
+	}
+	flushSlide := func() {
+		flushPara()
+		cur.Notes = strings.TrimSpace(strings.Join(notes, "\n"))
+		if len(cur.Blocks) > 0 || cur.Notes != "" {
+			slides = append(slides, cur)
+		}
+		cur, notes, inNote = Slide{}, nil, false
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case inCode:
+			if strings.HasPrefix(trimmed, "```") {
+				cur.Blocks = append(cur.Blocks, Block{Kind: Code, Code: code})
+				code, inCode = nil, false
+			} else {
+				code = append(code, line)
+			}
+		case separator.MatchString(line):
+			flushSlide()
+		case inNote:
+			notes = append(notes, line)
+		case strings.HasPrefix(trimmed, "Note:") || strings.HasPrefix(trimmed, "Notes:"):
+			flushPara()
+			inNote = true
+			notes = append(notes, strings.TrimSpace(trimmed[strings.IndexByte(trimmed, ':')+1:]))
+		case strings.HasPrefix(trimmed, "```"):
+			flushPara()
+			inCode = true
+		case trimmed == "":
+			flushPara()
+		case strings.HasPrefix(trimmed, "#"):
+			flushPara()
+			level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			cur.Blocks = append(cur.Blocks, Block{
+				Kind:  Heading,
+				Level: level,
+				Spans: parseInline(strings.TrimSpace(trimmed[level:])),
+			})
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			flushPara()
+			cur.Blocks = append(cur.Blocks, Block{
+				Kind:  Bullet,
+				Level: indent(line),
+				Spans: parseInline(strings.TrimSpace(trimmed[2:])),
+			})
+		case numbered.MatchString(trimmed):
+			flushPara()
+			m := numbered.FindStringSubmatch(trimmed)
+			n, _ := strconv.Atoi(m[1])
+			cur.Blocks = append(cur.Blocks, Block{
+				Kind:   Numbered,
+				Level:  indent(line),
+				Number: n,
+				Spans:  parseInline(trimmed[len(m[0]):]),
+			})
+		case strings.HasPrefix(trimmed, ">"):
+			flushPara()
+			cur.Blocks = append(cur.Blocks, Block{Kind: Quote, Spans: parseInline(strings.TrimSpace(trimmed[1:]))})
+		default:
+			para = append(para, trimmed)
+		}
+	}
+	if inCode {
+		cur.Blocks = append(cur.Blocks, Block{Kind: Code, Code: code})
+	}
+	flushSlide()
+	return slides
+}
+
+// indent returns the nesting level of a list item, every two spaces or
+// a tab.
+func indent(line string) int {
+	n := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += 2
+		default:
+			return n / 2
+		}
+	}
+	return n / 2
+}
+
+// parseInline splits text into spans by its **bold**, *italic* or
+// _italic_, and `code` markers.
+func parseInline(s string) []Span {
+	var (
+		spans        []Span
+		cur          strings.Builder
+		bold, italic bool
+	)
+	flush := func() {
+		if cur.Len() > 0 {
+			spans = append(spans, Span{Text: cur.String(), Bold: bold, Italic: italic})
+			cur.Reset()
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			i++
+			cur.WriteByte(s[i])
+		case c == '`':
+			end := strings.IndexByte(s[i+1:], '`')
+			if end == -1 {
+				cur.WriteByte(c)
+				continue
+			}
+			flush()
+			spans = append(spans, Span{Text: s[i+1 : i+1+end], Mono: true, Bold: bold, Italic: italic})
+			i += end + 1
+		case c == '*' && i+1 < len(s) && s[i+1] == '*':
+			flush()
+			bold = !bold
+			i++
+		case c == '*' || c == '_' && (i == 0 || s[i-1] == ' ' || italic):
+			flush()
+			italic = !italic
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	flush()
+	return spans
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDeck(t *testing.T) {
+	slides := ParseDeck(`# Title
+
+Subtitle on
+two lines
+
+---
+
+## List
+
+- one
+  - nested
+2. two
+
+` + "```" + `
+---
+` + "```" + `
+
+Note: Say something.
+More notes.
+`)
+	if len(slides) != 2 {
+		t.Fatalf("got %d slides, want 2", len(slides))
+	}
+	if got := slides[0].Title(); got != "Title" {
+		t.Errorf("got title %q", got)
+	}
+	if b := slides[0].Blocks[1]; b.Kind != Paragraph || b.Spans[0].Text != "Subtitle on two lines" {
+		t.Errorf("unexpected paragraph %+v", b)
+	}
+	s := slides[1]
+	kinds := make([]BlockKind, len(s.Blocks))
+	for i, b := range s.Blocks {
+		kinds[i] = b.Kind
+	}
+	if want := []BlockKind{Heading, Bullet, Bullet, Numbered, Code}; !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("got blocks %v, want %v", kinds, want)
+	}
+	if s.Blocks[2].Level != 1 || s.Blocks[3].Number != 2 {
+		t.Errorf("unexpected list items %+v", s.Blocks[2:4])
+	}
+	if want := []string{"---"}; !reflect.DeepEqual(s.Blocks[4].Code, want) {
+		t.Errorf("got code %q, want %q", s.Blocks[4].Code, want)
+	}
+	if want := "Say something.\nMore notes."; s.Notes != want {
+		t.Errorf("got notes %q, want %q", s.Notes, want)
+	}
+}
+
+func TestParseInline(t *testing.T) {
+	got := parseInline("a **bold** and *it* `x*y` snake_case")
+	want := []Span{
+		{Text: "a "},
+		{Text: "bold", Bold: true},
+		{Text: " and "},
+		{Text: "it", Italic: true},
+		{Text: " "},
+		{Text: "x*y", Mono: true},
+		{Text: " snake_case"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,473 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program presents a Markdown file as slides. Slides are separated
+// by lines of dashes and scale with the window; F toggles full screen.
+// The arrow keys, Space and Page Up and Down move between slides, with
+// a sliding transition. A second window for the presenter shows the
+// notes of the slide, the next slide and a timer. R reloads the file.
+//
+// Usage:
+//
+//	go run ./slides [-presenter=false] [talk.md]
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/style"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/key"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+
+var presenter = flag.Bool("presenter", true, "open the presenter window")
+
+// transition is the duration of the slide transition.
+const transition = 350 * time.Millisecond
+
+const sample = `# Slides
+
+A Gio presentation
+
+Note: Welcome everyone. These notes are only visible in the presenter
+window.
+
+---
+
+## Moving around
+
+- **Right**, **Space** or **Page Down** for the next slide
+- **Left** or **Page Up** for the previous one
+- **Home** and **End** for the first and last
+- **F** for full screen, **R** to reload
+
+---
+
+## Writing slides
+
+1. Separate slides with a line of ` + "`---`" + `
+2. Use headings, lists, *emphasis* and ` + "`code`" + `
+  - Nested items are indented
+3. Start the notes with ` + "`Note:`" + `
+
+> Everything scales with the window.
+
+---
+
+## Code
+
+` + "```" + `
+func main() {
+	go func() {
+		w := app.NewWindow()
+		loop(w)
+	}()
+	app.Main()
+}
+` + "```" + `
+
+Note: Point out that the code block uses a monospaced font.
+
+---
+
+# Thank you
+`
+
+func main() {
+	flag.Parse()
+	p := &Presentation{start: time.Now()}
+	if flag.NArg() > 0 {
+		p.path = flag.Arg(0)
+		if err := p.Reload(); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		p.slides = ParseDeck(sample)
+	}
+	th := material.NewTheme(gofont.Collection())
+	go func() {
+		w := app.NewWindow(
+			app.Title("Slides"),
+			app.Size(unit.Dp(1280), unit.Dp(720)),
+		)
+		p.add(w)
+		if err := (&slideView{p: p}).loop(w, th); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	if *presenter {
+		go func() {
+			w := app.NewWindow(
+				app.Title("Slides – Presenter"),
+				app.Size(unit.Dp(1000), unit.Dp(640)),
+			)
+			p.add(w)
+			// Closing the presenter window leaves the slides open.
+			if err := (&presenterView{p: p}).loop(w, th); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	}
+	app.Main()
+}
+
+// Presentation is the state shared by the windows.
+type Presentation struct {
+	mu      sync.Mutex
+	path    string
+	slides  []Slide
+	index   int
+	start   time.Time
+	windows []*app.Window
+}
+
+func (p *Presentation) add(w *app.Window) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.windows = append(p.windows, w)
+}
+
+// State returns the slides and the index of the current one.
+func (p *Presentation) State() ([]Slide, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.slides, p.index
+}
+
+// Go moves to slide i and redraws the windows.
+func (p *Presentation) Go(i int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if i >= len(p.slides) {
+		i = len(p.slides) - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	p.index = i
+	p.invalidate()
+}
+
+// Move moves by delta slides.
+func (p *Presentation) Move(delta int) {
+	_, i := p.State()
+	p.Go(i + delta)
+}
+
+// Reload reads the file again, staying on the current slide if it still
+// exists.
+func (p *Presentation) Reload() error {
+	if p.path == "" {
+		return nil
+	}
+	src, err := ioutil.ReadFile(p.path)
+	if err != nil {
+		return err
+	}
+	slides := ParseDeck(string(src))
+	p.mu.Lock()
+	p.slides = slides
+	p.mu.Unlock()
+	p.Move(0)
+	return nil
+}
+
+// Elapsed returns the time since the presentation started.
+func (p *Presentation) Elapsed() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return time.Since(p.start)
+}
+
+func (p *Presentation) ResetTimer() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.start = time.Now()
+	p.invalidate()
+}
+
+func (p *Presentation) invalidate() {
+	for _, w := range p.windows {
+		w.Invalidate()
+	}
+}
+
+// key handles the navigation keys common to the windows.
+func (p *Presentation) key(e key.Event) error {
+	if e.State != key.Press {
+		return nil
+	}
+	switch e.Name {
+	case key.NameRightArrow, key.NameDownArrow, key.NamePageDown, key.NameSpace, "N":
+		p.Move(1)
+	case key.NameLeftArrow, key.NameUpArrow, key.NamePageUp, key.NameDeleteBackward, "P":
+		p.Move(-1)
+	case key.NameHome:
+		p.Go(0)
+	case key.NameEnd:
+		slides, _ := p.State()
+		p.Go(len(slides) - 1)
+	case "R":
+		return p.Reload()
+	}
+	return nil
+}
+
+// slideView shows the current slide, sliding from the previous one.
+type slideView struct {
+	p          *Presentation
+	fullscreen bool
+	err        error
+	// shown is the slide shown, and from the one before it during a
+	// transition started at start.
+	shown, from int
+	start       time.Time
+}
+
+func (v *slideView) loop(w *app.Window, th *material.Theme) error {
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case key.Event:
+			if e.State == key.Press {
+				switch e.Name {
+				case "F":
+					v.fullscreen = !v.fullscreen
+					if v.fullscreen {
+						w.Option(app.Fullscreen)
+					} else {
+						w.Option(app.Windowed)
+					}
+				case key.NameEscape:
+					if v.fullscreen {
+						v.fullscreen = false
+						w.Option(app.Windowed)
+					}
+				}
+			}
+			v.err = v.p.key(e)
+			w.Invalidate()
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			v.Layout(gtx, th)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+func (v *slideView) Layout(gtx C, th *material.Theme) D {
+	slides, idx := v.p.State()
+	if idx != v.shown {
+		v.from, v.shown = v.shown, idx
+		v.start = gtx.Now
+	}
+	slide := func(i int) *Slide {
+		if i < len(slides) {
+			return &slides[i]
+		}
+		return nil
+	}
+	size := gtx.Constraints.Max
+	t := float32(gtx.Now.Sub(v.start)) / float32(transition)
+	if t < 1 && v.from != v.shown {
+		op.InvalidateOp{}.Add(gtx.Ops)
+		// Ease out.
+		t = 1 - (1-t)*(1-t)*(1-t)
+		dir := float32(1)
+		if v.shown < v.from {
+			dir = -1
+		}
+		w := float32(size.X)
+		offset := func(x float32, s *Slide) {
+			defer op.Save(gtx.Ops).Load()
+			op.Offset(f32.Pt(x, 0)).Add(gtx.Ops)
+			layoutSlide(gtx, th, s)
+		}
+		offset(-dir*t*w, slide(v.from))
+		offset(dir*(1-t)*w, slide(v.shown))
+	} else {
+		layoutSlide(gtx, th, slide(v.shown))
+	}
+	// The progress through the presentation.
+	if n := len(slides); n > 1 {
+		r, _ := slideRect(size)
+		h := gtx.Px(unit.Dp(4))
+		bar := image.Rect(r.Min.X, r.Max.Y-h, r.Min.X+r.Dx()*idx/(n-1), r.Max.Y)
+		paint.FillShape(gtx.Ops, accent, clip.Rect(bar).Op())
+	}
+	if v.err != nil {
+		l := material.Body1(th, v.err.Error())
+		l.Color = errorColor
+		layout.S.Layout(gtx, l.Layout)
+	}
+	return D{Size: size}
+}
+
+// presenterView shows the current and next slides, the notes and the
+// time elapsed.
+type presenterView struct {
+	p          *Presentation
+	err        error
+	notesList  layout.List
+	prev, next widget.Clickable
+	resetTimer widget.Clickable
+	goTo       []widget.Clickable
+	slideList  layout.List
+}
+
+func (v *presenterView) loop(w *app.Window, th *material.Theme) error {
+	v.notesList.Axis = layout.Vertical
+	v.slideList.Axis = layout.Vertical
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case key.Event:
+			v.err = v.p.key(e)
+			w.Invalidate()
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			v.update()
+			v.Layout(gtx, th)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+func (v *presenterView) update() {
+	for v.prev.Clicked() {
+		v.p.Move(-1)
+	}
+	for v.next.Clicked() {
+		v.p.Move(1)
+	}
+	for v.resetTimer.Clicked() {
+		v.p.ResetTimer()
+	}
+	for i := range v.goTo {
+		for v.goTo[i].Clicked() {
+			v.p.Go(i)
+		}
+	}
+}
+
+func (v *presenterView) Layout(gtx C, th *material.Theme) D {
+	slides, idx := v.p.State()
+	if len(v.goTo) != len(slides) {
+		v.goTo = make([]widget.Clickable, len(slides))
+	}
+	var cur, next *Slide
+	if idx < len(slides) {
+		cur = &slides[idx]
+	}
+	if idx+1 < len(slides) {
+		next = &slides[idx+1]
+	}
+	// Redraw when the clock ticks over.
+	elapsed := v.p.Elapsed()
+	op.InvalidateOp{At: gtx.Now.Add(time.Second - elapsed%time.Second)}.Add(gtx.Ops)
+	return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{}.Layout(gtx,
+			layout.Flexed(3, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Flexed(3, func(gtx C) D {
+						return layoutSlide(gtx, th, cur)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+					layout.Rigid(material.H6(th, "Notes").Layout),
+					layout.Flexed(2, func(gtx C) D {
+						notes := "No notes."
+						if cur != nil && cur.Notes != "" {
+							notes = cur.Notes
+						}
+						return v.notesList.Layout(gtx, 1, func(gtx C, _ int) D {
+							return material.Label(th, unit.Sp(20), notes).Layout(gtx)
+						})
+					}),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+			layout.Flexed(2, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						secs := int(elapsed / time.Second)
+						return material.H3(th, fmt.Sprintf("%02d:%02d", secs/60, secs%60)).Layout(gtx)
+					}),
+					layout.Rigid(func(gtx C) D {
+						return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+							layout.Rigid(material.Body1(th, fmt.Sprintf("Slide %d of %d", idx+1, len(slides))).Layout),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+							layout.Rigid(material.Button(th, &v.prev, "Previous").Layout),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+							layout.Rigid(material.Button(th, &v.next, "Next").Layout),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+							layout.Rigid(style.TextButton(th, &v.resetTimer, "Reset timer")),
+						)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+					layout.Rigid(material.H6(th, "Next").Layout),
+					layout.Rigid(func(gtx C) D {
+						gtx.Constraints.Max.Y = gtx.Constraints.Max.X * slideHeight / slideWidth
+						gtx.Constraints.Min = gtx.Constraints.Max
+						return layoutSlide(gtx, th, next)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+					layout.Flexed(1, func(gtx C) D {
+						return v.slideList.Layout(gtx, len(slides), func(gtx C, i int) D {
+							return material.Clickable(gtx, &v.goTo[i], func(gtx C) D {
+								title := slides[i].Title()
+								if title == "" {
+									title = "Untitled"
+								}
+								l := material.Body2(th, fmt.Sprintf("%d. %s", i+1, title))
+								if i == idx {
+									l.Color = accent
+								}
+								return layout.UniformInset(unit.Dp(4)).Layout(gtx, l.Layout)
+							})
+						})
+					}),
+					layout.Rigid(func(gtx C) D {
+						if v.err == nil {
+							return D{}
+						}
+						l := material.Body2(th, v.err.Error())
+						l.Color = errorColor
+						return l.Layout(gtx)
+					}),
+				)
+			}),
+		)
+	})
+}
@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"gioui.org/example/internal/flow"
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+// slideWidth and slideHeight are the size slides are designed for, in
+// Dp. Slides are scaled to fit their window, text included.
+const slideWidth, slideHeight = 1280, 720
+
+var (
+	backdrop = color.NRGBA{R: 0x10, G: 0x10, B: 0x10, A: 0xff}
+	codeBg   = color.NRGBA{R: 0xf3, G: 0xf3, B: 0xf6, A: 0xff}
+	quoteFg  = color.NRGBA{R: 0x60, G: 0x60, B: 0x68, A: 0xff}
+	accent   = color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0xff}
+)
+
+// slideRect returns the largest rectangle of the slide aspect ratio
+// centered in size, and the number of pixels per slide Dp.
+func slideRect(size image.Point) (image.Rectangle, float32) {
+	scale := float32(size.X) / slideWidth
+	if s := float32(size.Y) / slideHeight; s < scale {
+		scale = s
+	}
+	w, h := int(slideWidth*scale), int(slideHeight*scale)
+	min := image.Pt((size.X-w)/2, (size.Y-h)/2)
+	return image.Rectangle{Min: min, Max: min.Add(image.Pt(w, h))}, scale
+}
+
+// layoutSlide draws s scaled to fit the constraints, on a dark backdrop.
+func layoutSlide(gtx C, th *material.Theme, s *Slide) D {
+	size := gtx.Constraints.Max
+	paint.FillShape(gtx.Ops, backdrop, clip.Rect{Max: size}.Op())
+	r, scale := slideRect(size)
+	defer op.Save(gtx.Ops).Load()
+	op.Offset(layout.FPt(r.Min)).Add(gtx.Ops)
+	clip.Rect{Max: r.Size()}.Add(gtx.Ops)
+	paint.Fill(gtx.Ops, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+	sgtx := gtx
+	sgtx.Metric = unit.Metric{PxPerDp: scale, PxPerSp: scale}
+	sgtx.Constraints = layout.Exact(r.Size())
+	if s != nil {
+		layoutBlocks(sgtx, th, s.Blocks)
+	}
+	return D{Size: size}
+}
+
+// layoutBlocks lays out the blocks of a slide from the top, or centered
+// if the slide is a title slide: a top level heading with at most a
+// line below it.
+func layoutBlocks(gtx C, th *material.Theme, blocks []Block) D {
+	title := len(blocks) > 0 && len(blocks) <= 2 && blocks[0].Kind == Heading && blocks[0].Level == 1
+	var children []layout.FlexChild
+	for i := range blocks {
+		b := &blocks[i]
+		if i > 0 {
+			children = append(children, layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout))
+		}
+		children = append(children, layout.Rigid(func(gtx C) D {
+			return layoutBlock(gtx, th, b, title)
+		}))
+	}
+	return layout.UniformInset(unit.Dp(72)).Layout(gtx, func(gtx C) D {
+		if !title {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+		}
+		return layout.Center.Layout(gtx, func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx, children...)
+		})
+	})
+}
+
+func layoutBlock(gtx C, th *material.Theme, b *Block, title bool) D {
+	switch b.Kind {
+	case Heading:
+		size := map[int]float32{1: 60, 2: 44}[b.Level]
+		if size == 0 {
+			size = 34
+		}
+		dims := richText(gtx, th, b.Spans, size, accent, true)
+		if b.Level == 1 && !title {
+			// Underline the slide title.
+			bar := image.Rect(0, dims.Size.Y+gtx.Px(unit.Dp(8)), gtx.Px(unit.Dp(120)), dims.Size.Y+gtx.Px(unit.Dp(14)))
+			paint.FillShape(gtx.Ops, accent, clip.Rect(bar).Op())
+			dims.Size.Y = bar.Max.Y
+		}
+		return dims
+	case Bullet, Numbered:
+		marker := "•"
+		if b.Kind == Numbered {
+			marker = strconv.Itoa(b.Number) + "."
+		}
+		return layout.Inset{Left: unit.Dp(float32(40 * b.Level))}.Layout(gtx, func(gtx C) D {
+			return layout.Flex{Alignment: layout.Baseline}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					gtx.Constraints.Min.X = gtx.Px(unit.Dp(44))
+					l := material.Label(th, unit.Sp(30), marker)
+					l.Color = accent
+					return l.Layout(gtx)
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					return richText(gtx, th, b.Spans, 30, th.Palette.Fg, false)
+				}),
+			)
+		})
+	case Quote:
+		m := op.Record(gtx.Ops)
+		dims := layout.Inset{Left: unit.Dp(28)}.Layout(gtx, func(gtx C) D {
+			spans := make([]Span, len(b.Spans))
+			for i, s := range b.Spans {
+				s.Italic = true
+				spans[i] = s
+			}
+			return richText(gtx, th, spans, 30, quoteFg, false)
+		})
+		call := m.Stop()
+		paint.FillShape(gtx.Ops, accent, clip.Rect{Max: image.Pt(gtx.Px(unit.Dp(6)), dims.Size.Y)}.Op())
+		call.Add(gtx.Ops)
+		return dims
+	case Code:
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		m := op.Record(gtx.Ops)
+		dims := layout.UniformInset(unit.Dp(20)).Layout(gtx, func(gtx C) D {
+			l := material.Label(th, unit.Sp(22), strings.ReplaceAll(strings.Join(b.Code, "\n"), "\t", "    "))
+			l.Font = text.Font{Variant: "Mono"}
+			return l.Layout(gtx)
+		})
+		call := m.Stop()
+		rr := float32(gtx.Px(unit.Dp(8)))
+		paint.FillShape(gtx.Ops, codeBg, clip.UniformRRect(f32.Rectangle{Max: layout.FPt(dims.Size)}, rr).Op(gtx.Ops))
+		call.Add(gtx.Ops)
+		return dims
+	default:
+		if title {
+			return richText(gtx, th, b.Spans, 32, quoteFg, false)
+		}
+		return richText(gtx, th, b.Spans, 30, th.Palette.Fg, false)
+	}
+}
+
+// richText lays out spans of text wrapped word by word.
+func richText(gtx C, th *material.Theme, spans []Span, size float32, col color.NRGBA, bold bool) D {
+	type word struct {
+		text string
+		span *Span
+	}
+	var words []word
+	for i := range spans {
+		s := &spans[i]
+		for _, w := range strings.SplitAfter(s.Text, " ") {
+			if w != "" {
+				words = append(words, word{w, s})
+			}
+		}
+	}
+	return flow.Flow{Alignment: layout.Baseline}.Layout(gtx, len(words), func(gtx C, i int) D {
+		w := words[i]
+		l := material.Label(th, unit.Sp(size), w.text)
+		l.Color = col
+		l.MaxLines = 1
+		if bold || w.span.Bold {
+			l.Font.Weight = text.Bold
+		}
+		if w.span.Italic {
+			l.Font.Style = text.Italic
+		}
+		if !w.span.Mono {
+			return l.Layout(gtx)
+		}
+		l.Font.Variant = "Mono"
+		l.TextSize = unit.Sp(size * 0.85)
+		m := op.Record(gtx.Ops)
+		dims := l.Layout(gtx)
+		call := m.Stop()
+		paint.FillShape(gtx.Ops, codeBg, clip.Rect{Max: dims.Size}.Op())
+		call.Add(gtx.Ops)
+		return dims
+	})
+}
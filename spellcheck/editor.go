@@ -0,0 +1,336 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"strings"
+
+	"gioui.org/example/internal/spell"
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"golang.org/x/image/math/fixed"
+)
+
+var squiggleColor = color.NRGBA{R: 0xe5, G: 0x39, B: 0x35, A: 0xff}
+
+// SpellEditor is a multi-line editor that underlines misspelled words.
+// The editor doesn't expose the positions of its glyphs, so the text is
+// shaped again with the same shaper and width to find the words. For the
+// decorations to stay in place, the editor must not scroll; lay it out
+// with unbounded height, such as in a layout.List.
+type SpellEditor struct {
+	Editor  widget.Editor
+	Checker *spell.Checker
+
+	// ignored are the words ignored for the session, in lower case.
+	ignored map[string]bool
+	bad     []spell.Word
+	// hits are the areas of the misspelled words in the last frame.
+	hits []hit
+	// clicked is the word right-clicked, waiting for the position of the
+	// click in the window.
+	clicked *spell.Word
+	menu    menu
+}
+
+type hit struct {
+	area image.Rectangle
+	word spell.Word
+}
+
+// menu is the suggestion menu for a misspelled word.
+type menu struct {
+	open        bool
+	word        spell.Word
+	suggestions []string
+	// area is the area of the menu in the window.
+	area image.Rectangle
+
+	items         []widget.Clickable
+	ignore, learn widget.Clickable
+}
+
+const maxSuggestions = 6
+
+// check finds the misspelled words of the editor text.
+func (e *SpellEditor) check() {
+	e.bad = e.bad[:0]
+	for _, w := range e.Checker.Misspelled(e.Editor.Text()) {
+		if !e.ignored[strings.ToLower(w.Text)] {
+			e.bad = append(e.bad, w)
+		}
+	}
+}
+
+// Update processes the events of the editor and its menu. It must be
+// called with the window's context.
+func (e *SpellEditor) Update(gtx layout.Context) {
+	for _, ev := range e.Editor.Events() {
+		if _, ok := ev.(widget.ChangeEvent); ok {
+			e.check()
+		}
+	}
+	for _, ev := range gtx.Events(e) {
+		ev, ok := ev.(pointer.Event)
+		if !ok || !ev.Buttons.Contain(pointer.ButtonSecondary) {
+			continue
+		}
+		e.clicked = nil
+		pos := image.Pt(int(ev.Position.X), int(ev.Position.Y))
+		for _, h := range e.hits {
+			if pos.In(h.area) {
+				w := h.word
+				e.clicked = &w
+			}
+		}
+	}
+	for _, ev := range gtx.Events(&e.menu) {
+		ev, ok := ev.(pointer.Event)
+		if !ok {
+			continue
+		}
+		pos := image.Pt(int(ev.Position.X), int(ev.Position.Y))
+		switch {
+		case e.clicked != nil:
+			e.openMenu(*e.clicked, pos)
+			e.clicked = nil
+		case e.menu.open && !pos.In(e.menu.area):
+			e.menu.open = false
+		}
+	}
+	if !e.menu.open {
+		return
+	}
+	m := &e.menu
+	for i := range m.suggestions {
+		for m.items[i].Clicked() {
+			e.replace(m.word, m.suggestions[i])
+		}
+	}
+	for m.ignore.Clicked() {
+		if e.ignored == nil {
+			e.ignored = make(map[string]bool)
+		}
+		e.ignored[strings.ToLower(m.word.Text)] = true
+		e.menu.open = false
+		e.check()
+	}
+	for m.learn.Clicked() {
+		e.Checker.Add(m.word.Text)
+		e.menu.open = false
+		e.check()
+	}
+}
+
+func (e *SpellEditor) openMenu(w spell.Word, pos image.Point) {
+	e.menu.open = true
+	e.menu.word = w
+	e.menu.suggestions = e.Checker.Suggest(w.Text, maxSuggestions)
+	e.menu.area = image.Rectangle{Min: pos, Max: pos}
+	if len(e.menu.items) < len(e.menu.suggestions) {
+		e.menu.items = make([]widget.Clickable, len(e.menu.suggestions))
+	}
+}
+
+// replace replaces a word of the text, if it hasn't changed since it was
+// checked.
+func (e *SpellEditor) replace(w spell.Word, with string) {
+	e.menu.open = false
+	txt := e.Editor.Text()
+	if w.End > len(txt) || txt[w.Start:w.End] != w.Text {
+		return
+	}
+	e.Editor.SetText(txt[:w.Start] + with + txt[w.End:])
+	e.check()
+}
+
+func (e *SpellEditor) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	dims := material.Editor(th, &e.Editor, "Type here").Layout(gtx)
+	e.layoutDecorations(gtx, th)
+
+	defer op.Save(gtx.Ops).Load()
+	pointer.PassOp{Pass: true}.Add(gtx.Ops)
+	pointer.Rect(image.Rectangle{Max: dims.Size}).Add(gtx.Ops)
+	pointer.InputOp{Tag: e, Types: pointer.Press}.Add(gtx.Ops)
+	return dims
+}
+
+// layoutDecorations underlines the misspelled words and records their
+// areas.
+func (e *SpellEditor) layoutDecorations(gtx layout.Context, th *material.Theme) {
+	e.hits = e.hits[:0]
+	font := text.Font{}
+	size := fixed.I(gtx.Px(th.TextSize))
+	bad := e.bad
+	y, start := 0, 0
+	for _, para := range strings.Split(e.Editor.Text(), "\n") {
+		shaped := para
+		if shaped == "" {
+			// Shape something for the height of the empty line.
+			shaped = " "
+		}
+		for _, l := range th.Shaper.LayoutString(font, size, gtx.Constraints.Max.X, shaped) {
+			end := start + len(l.Layout.Text)
+			ascent, descent := l.Ascent.Ceil(), l.Descent.Ceil()
+			for len(bad) > 0 && bad[0].Start < end {
+				w := bad[0]
+				bad = bad[1:]
+				x0 := advance(l.Layout, w.Start-start).Floor()
+				x1 := advance(l.Layout, w.End-start).Ceil()
+				base := y + ascent
+				squiggle(gtx, float32(x0), float32(x1), float32(base+descent/4))
+				e.hits = append(e.hits, hit{
+					area: image.Rect(x0, y, x1, y+ascent+descent),
+					word: w,
+				})
+			}
+			y += ascent + descent
+			start = end
+		}
+		if para == "" {
+			start = start - 1
+		}
+		// Skip the newline.
+		start++
+	}
+}
+
+// squiggle draws a zigzag line from x0 to x1 below y.
+func squiggle(gtx layout.Context, x0, x1, y float32) {
+	period := float32(gtx.Px(unit.Dp(4)))
+	height := float32(gtx.Px(unit.Dp(2)))
+	width := float32(gtx.Px(unit.Dp(1)))
+	var pts []f32.Point
+	for x, up := x0, false; ; x, up = x+period/2, !up {
+		if x > x1 {
+			x = x1
+		}
+		py := y
+		if !up {
+			py += height
+		}
+		pts = append(pts, f32.Pt(x, py))
+		if x == x1 {
+			break
+		}
+	}
+	// Outline the line along the points and back below them.
+	var p clip.Path
+	p.Begin(gtx.Ops)
+	p.MoveTo(pts[0])
+	for _, pt := range pts[1:] {
+		p.LineTo(pt)
+	}
+	for i := len(pts) - 1; i >= 0; i-- {
+		p.LineTo(pts[i].Add(f32.Pt(0, width)))
+	}
+	p.Close()
+	paint.FillShape(gtx.Ops, squiggleColor, clip.Outline{Path: p.End()}.Op())
+}
+
+// advance returns the width of the first n bytes of l.
+func advance(l text.Layout, n int) fixed.Int26_6 {
+	var x fixed.Int26_6
+	i := 0
+	for b := range l.Text {
+		if b >= n || i >= len(l.Advances) {
+			break
+		}
+		x += l.Advances[i]
+		i++
+	}
+	return x
+}
+
+// LayoutMenu lays out the suggestion menu, if open, at the position of
+// the click that opened it. It must be called with the window's context,
+// after the other widgets.
+func (e *SpellEditor) LayoutMenu(gtx layout.Context, th *material.Theme) {
+	if e.menu.open {
+		e.layoutMenu(gtx, th)
+	}
+	// Catch presses anywhere in the window, to place the menu and to
+	// close it when clicking elsewhere.
+	defer op.Save(gtx.Ops).Load()
+	pointer.PassOp{Pass: true}.Add(gtx.Ops)
+	pointer.Rect(image.Rectangle{Max: gtx.Constraints.Max}).Add(gtx.Ops)
+	pointer.InputOp{Tag: &e.menu, Types: pointer.Press}.Add(gtx.Ops)
+}
+
+func (e *SpellEditor) layoutMenu(gtx layout.Context, th *material.Theme) {
+	m := &e.menu
+	item := func(c *widget.Clickable, label string, bold bool) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.Clickable(gtx, c, func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+				l := material.Body1(th, label)
+				if bold {
+					l.Font.Weight = text.Bold
+				}
+				return layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(6), Left: unit.Dp(12), Right: unit.Dp(12)}.Layout(gtx, l.Layout)
+			})
+		})
+	}
+	var children []layout.FlexChild
+	for i, s := range m.suggestions {
+		children = append(children, item(&m.items[i], s, true))
+	}
+	if len(m.suggestions) == 0 {
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			l := material.Body1(th, "No suggestions")
+			l.Color.A = 0x80
+			return layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(6), Left: unit.Dp(12), Right: unit.Dp(12)}.Layout(gtx, l.Layout)
+		}))
+	}
+	children = append(children,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			sz := image.Pt(gtx.Constraints.Max.X, gtx.Px(unit.Dp(1)))
+			paint.FillShape(gtx.Ops, color.NRGBA{A: 0x20}, clip.Rect(image.Rectangle{Max: sz}).Op())
+			return layout.Dimensions{Size: sz}
+		}),
+		item(&m.ignore, "Ignore “"+m.word.Text+"”", false),
+		item(&m.learn, "Add to dictionary", false),
+	)
+
+	mgtx := gtx
+	mgtx.Constraints.Min = image.Point{}
+	mgtx.Constraints.Max.X = gtx.Px(unit.Dp(220))
+	macro := op.Record(gtx.Ops)
+	dims := layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(mgtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	})
+	call := macro.Stop()
+
+	// Keep the menu inside the window.
+	pos := m.area.Min
+	limit := gtx.Constraints.Max.Sub(dims.Size)
+	if pos.X > limit.X {
+		pos.X = limit.X
+	}
+	if pos.Y > limit.Y {
+		pos.Y = limit.Y
+	}
+	m.area = image.Rectangle{Min: pos, Max: pos.Add(dims.Size)}
+
+	defer op.Save(gtx.Ops).Load()
+	op.Offset(layout.FPt(pos)).Add(gtx.Ops)
+	rr := float32(gtx.Px(unit.Dp(4)))
+	bounds := f32.Rectangle{Max: layout.FPt(dims.Size)}
+	shadow := bounds
+	shadow.Min = shadow.Min.Add(f32.Pt(-1, -1))
+	shadow.Max = shadow.Max.Add(f32.Pt(1, 2))
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 0x40}, clip.UniformRRect(shadow, rr).Op(gtx.Ops))
+	paint.FillShape(gtx.Ops, th.Palette.Bg, clip.UniformRRect(bounds, rr).Op(gtx.Ops))
+	call.Add(gtx.Ops)
+}
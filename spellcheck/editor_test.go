@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"gioui.org/example/internal/edittest"
+	"gioui.org/example/internal/spell"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/widget/material"
+)
+
+func TestEditorFuzz(t *testing.T) {
+	th := material.NewTheme(gofont.Collection())
+	edittest.Run(t, func(seed int64) *edittest.Harness {
+		checker, err := spell.Load(bytes.NewReader(builtinWords))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ed := &SpellEditor{Checker: checker}
+		ed.Editor.SetText(sample)
+		ed.check()
+		list := layout.List{Axis: layout.Vertical}
+		return &edittest.Harness{
+			Editor: &ed.Editor,
+			Layout: func(gtx C) {
+				ed.Update(gtx)
+				list.Layout(gtx, 1, func(gtx C, _ int) D {
+					return ed.Layout(gtx, th)
+				})
+				ed.LayoutMenu(gtx, th)
+			},
+			Predict: true,
+		}
+	})
+}
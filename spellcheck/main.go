@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates decorating ranges of the text of a
+// widget.Editor. Misspelled words are underlined with a squiggly line,
+// and right-clicking one opens a menu of suggested corrections.
+//
+// The words are checked against the system word list, if any, or a small
+// built-in list. Set the SPELL_DICT environment variable to use another
+// list or a hunspell .dic file.
+
+import (
+	"bytes"
+	_ "embed"
+	"log"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/spell"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+//go:embed words.txt
+var builtinWords []byte
+
+const sample = `Gio is a libary for writing cross-platform immediate mode user interfaces in Go. Teh same program runs on Linux, macOS, Windows, Android and iOS, and in the browser.
+
+Misspelled words are underlined as you type. Right-click one of them to recieve a list of suggestions, to ignore the word for the rest of the session or to add it to the dictionary.
+
+The checker is definately not a replacement for a real spelling library: it knows nothing about grammar, and it only suggests words that are at most two edits away. Still, it is enough to seperate the words that occured in the dictionary from the ones that did not, in any langauge with a word list.`
+
+func main() {
+	checker, err := loadDictionary()
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		w := app.NewWindow(
+			app.Title("Spell Check"),
+			app.Size(unit.Dp(700), unit.Dp(600)),
+		)
+		if err := loop(w, checker); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+func loadDictionary() (*spell.Checker, error) {
+	for _, path := range []string{os.Getenv("SPELL_DICT"), "/usr/share/dict/words"} {
+		if path == "" {
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+		return spell.Load(f)
+	}
+	return spell.Load(bytes.NewReader(builtinWords))
+}
+
+func loop(w *app.Window, checker *spell.Checker) error {
+	th := material.NewTheme(gofont.Collection())
+	ed := &SpellEditor{Checker: checker}
+	ed.Editor.SetText(sample)
+	ed.check()
+	var (
+		ops  op.Ops
+		list = layout.List{Axis: layout.Vertical}
+	)
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			ed.Update(gtx)
+			layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+				// The editor is laid out in a list at its full height, so
+				// that the decorations scroll along with its text.
+				return list.Layout(gtx, 1, func(gtx C, _ int) D {
+					return ed.Layout(gtx, th)
+				})
+			})
+			ed.LayoutMenu(gtx, th)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
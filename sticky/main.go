@@ -0,0 +1,292 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates sticky section headers and a collapsing
+// toolbar with a parallax header image on top of a layout.List.
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Sticky headers"),
+			app.Size(unit.Dp(400), unit.Dp(700)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+const (
+	sections        = 12
+	rowsPerSection  = 10
+	elementsSection = rowsPerSection + 1
+)
+
+var (
+	expandedHeight  = unit.Dp(240)
+	collapsedHeight = unit.Dp(56)
+)
+
+// element identifies a list element. Element 0 is a spacer reserving room
+// for the expanded toolbar; the rest are headers and rows.
+type element struct {
+	spacer  bool
+	section int
+	// row is -1 for section headers.
+	row int
+}
+
+func elementAt(i int) element {
+	if i == 0 {
+		return element{spacer: true}
+	}
+	i--
+	return element{section: i / elementsSection, row: i%elementsSection - 1}
+}
+
+// UI holds the program state.
+type UI struct {
+	theme *material.Theme
+	list  layout.List
+	// heights records the height of every element laid out in the
+	// current frame, indexed by element.
+	heights map[int]int
+}
+
+func loop(w *app.Window) error {
+	ui := &UI{
+		theme:   material.NewTheme(gofont.Collection()),
+		list:    layout.List{Axis: layout.Vertical},
+		heights: make(map[int]int),
+	}
+	var ops op.Ops
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			ui.Layout(gtx)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+// Layout lays out the list, then the sticky header and the toolbar on
+// top of it.
+func (ui *UI) Layout(gtx C) D {
+	for k := range ui.heights {
+		delete(ui.heights, k)
+	}
+	n := 1 + sections*elementsSection
+	dims := ui.list.Layout(gtx, n, func(gtx C, i int) D {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		dims := ui.layoutElement(gtx, elementAt(i))
+		ui.heights[i] = dims.Size.Y
+		return dims
+	})
+
+	// scrolled is the distance scrolled, as far as the toolbar is
+	// concerned; past the spacer it no longer matters.
+	pos := ui.list.Position
+	expanded := gtx.Px(expandedHeight)
+	scrolled := expanded
+	if pos.First == 0 {
+		scrolled = pos.Offset
+	}
+	collapsed := gtx.Px(collapsedHeight)
+	barHeight := expanded - scrolled
+	if barHeight < collapsed {
+		barHeight = collapsed
+	}
+
+	ui.layoutStickyHeader(gtx, barHeight)
+	ui.layoutToolbar(gtx, barHeight, scrolled)
+	return dims
+}
+
+// y returns the position of element i relative to the top of the list,
+// and whether it was laid out in this frame.
+func (ui *UI) y(i int) (int, bool) {
+	pos := ui.list.Position
+	if _, ok := ui.heights[i]; !ok || i < pos.First {
+		return 0, false
+	}
+	y := -pos.Offset
+	for j := pos.First; j < i; j++ {
+		y += ui.heights[j]
+	}
+	return y, true
+}
+
+// layoutStickyHeader draws the header of the section at the top of the
+// viewport, right below the toolbar. The next header pushes it out of the
+// way as it arrives.
+func (ui *UI) layoutStickyHeader(gtx C, top int) {
+	// Find the topmost element below the toolbar.
+	first := ui.list.Position.First
+	for i := first; ; i++ {
+		y, ok := ui.y(i)
+		if !ok {
+			return
+		}
+		if y+ui.heights[i] > top {
+			first = i
+			break
+		}
+	}
+	el := elementAt(first)
+	if el.spacer {
+		return
+	}
+	headerIndex := 1 + el.section*elementsSection
+	if y, ok := ui.y(headerIndex); ok && y >= top {
+		// The real header is visible; no need to duplicate it.
+		return
+	}
+	m := op.Record(gtx.Ops)
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	dims := ui.layoutElement(gtx, element{section: el.section, row: -1})
+	call := m.Stop()
+
+	offset := top
+	if next := headerIndex + elementsSection; next < 1+sections*elementsSection {
+		if y, ok := ui.y(next); ok && y < top+dims.Size.Y {
+			offset = y - dims.Size.Y
+		}
+	}
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect(image.Rect(0, top, gtx.Constraints.Max.X, gtx.Constraints.Max.Y)).Add(gtx.Ops)
+	op.Offset(f32.Pt(0, float32(offset))).Add(gtx.Ops)
+	call.Add(gtx.Ops)
+}
+
+// layoutToolbar draws the collapsing toolbar. The image scrolls at half
+// speed for a parallax effect and the title shrinks as the bar collapses.
+func (ui *UI) layoutToolbar(gtx C, height, scrolled int) {
+	defer op.Save(gtx.Ops).Load()
+	width := gtx.Constraints.Max.X
+	clip.Rect(image.Rect(0, 0, width, height)).Add(gtx.Ops)
+
+	expanded := gtx.Px(expandedHeight)
+	collapsed := gtx.Px(collapsedHeight)
+	progress := float32(scrolled) / float32(expanded-collapsed)
+	if progress > 1 {
+		progress = 1
+	}
+
+	img := op.Save(gtx.Ops)
+	op.Offset(f32.Pt(0, -float32(scrolled)/2)).Add(gtx.Ops)
+	drawLandscape(gtx.Ops, image.Pt(width, expanded))
+	img.Load()
+
+	// Fade to a solid bar as the toolbar collapses.
+	bar := ui.theme.Palette.ContrastBg
+	bar.A = uint8(255 * progress)
+	paint.ColorOp{Color: bar}.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+
+	size := unit.Sp(34 - 14*progress)
+	title := material.Label(ui.theme, size, "Mountains")
+	title.Color = ui.theme.Palette.ContrastFg
+	gtx.Constraints = layout.Exact(image.Pt(width, height))
+	layout.SW.Layout(gtx, func(gtx C) D {
+		return layout.UniformInset(unit.Dp(16)).Layout(gtx, title.Layout)
+	})
+}
+
+// drawLandscape draws a simple generated picture standing in for a
+// header photo.
+func drawLandscape(ops *op.Ops, size image.Point) {
+	w, h := float32(size.X), float32(size.Y)
+	stack := op.Save(ops)
+	clip.Rect(image.Rectangle{Max: size}).Add(ops)
+	paint.LinearGradientOp{
+		Stop1:  f32.Pt(0, 0),
+		Stop2:  f32.Pt(0, h),
+		Color1: color.NRGBA{R: 0x2a, G: 0x5c, B: 0xaa, A: 0xff},
+		Color2: color.NRGBA{R: 0xf4, G: 0xb0, B: 0x70, A: 0xff},
+	}.Add(ops)
+	paint.PaintOp{}.Add(ops)
+	stack.Load()
+
+	ridge := func(base float32, peaks []float32, col color.NRGBA) {
+		var p clip.Path
+		p.Begin(ops)
+		p.MoveTo(f32.Pt(0, h))
+		p.LineTo(f32.Pt(0, base))
+		step := w / float32(len(peaks))
+		for i, peak := range peaks {
+			p.LineTo(f32.Pt(step*(float32(i)+.5), base-peak*h))
+			p.LineTo(f32.Pt(step*float32(i+1), base))
+		}
+		p.LineTo(f32.Pt(w, h))
+		p.Close()
+		paint.FillShape(ops, col, clip.Outline{Path: p.End()}.Op())
+	}
+	ridge(h*.8, []float32{.35, .5, .3, .45}, color.NRGBA{R: 0x4a, G: 0x4e, B: 0x69, A: 0xff})
+	ridge(h*.95, []float32{.2, .3, .25}, color.NRGBA{R: 0x22, G: 0x2f, B: 0x3e, A: 0xff})
+}
+
+func (ui *UI) layoutElement(gtx C, el element) D {
+	th := ui.theme
+	switch {
+	case el.spacer:
+		return D{Size: image.Pt(gtx.Constraints.Max.X, gtx.Px(expandedHeight))}
+	case el.row == -1:
+		return layout.Stack{}.Layout(gtx,
+			layout.Expanded(func(gtx C) D {
+				paint.FillShape(gtx.Ops, headerColor, clip.Rect(image.Rectangle{Max: gtx.Constraints.Min}).Op())
+				return D{Size: gtx.Constraints.Min}
+			}),
+			layout.Stacked(func(gtx C) D {
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+				return layout.UniformInset(unit.Dp(8)).Layout(gtx,
+					material.H6(th, fmt.Sprintf("Section %d", el.section+1)).Layout,
+				)
+			}),
+		)
+	default:
+		return layout.Stack{}.Layout(gtx,
+			layout.Expanded(func(gtx C) D {
+				paint.FillShape(gtx.Ops, th.Palette.Bg, clip.Rect(image.Rectangle{Max: gtx.Constraints.Min}).Op())
+				return D{Size: gtx.Constraints.Min}
+			}),
+			layout.Stacked(func(gtx C) D {
+				return layout.UniformInset(unit.Dp(16)).Layout(gtx,
+					material.Body1(th, fmt.Sprintf("Item %d.%d", el.section+1, el.row+1)).Layout,
+				)
+			}),
+		)
+	}
+}
+
+var headerColor = color.NRGBA{R: 0xe8, G: 0xea, B: 0xf6, A: 0xff}
@@ -0,0 +1,260 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program draws worst case operation loads for performance
+// investigations: thousands of clipped rectangles, deep transform
+// stacks, thousands of text lines, huge paths and heavy overdraw. It
+// redraws continuously and reports the frame times: the interval
+// between frames, the time to build the operations and the time to
+// submit them to the window.
+//
+// Attach the reported numbers, the scenario and the scale to
+// performance reports and driver bug reports; with -report the program
+// logs them periodically, without needing a screenshot.
+//
+// Usage:
+//
+//	go run ./stress [-scenario rects|transforms|text|paths|overdraw] [-scale 1] [-report 5s]
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"os"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	scenarioFlag = flag.String("scenario", "rects", "initial scenario")
+	scaleFlag    = flag.Float64("scale", 1, "initial scale of the workload, from 0.1 to 10")
+	reportFlag   = flag.Duration("report", 0, "log the frame times at this interval")
+)
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Stress"),
+			app.Size(unit.Dp(1024), unit.Dp(768)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var (
+	panelBg     = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xe8}
+	budgetColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	barColor    = color.NRGBA{R: 0x1e, G: 0x88, B: 0xe5, A: 0xff}
+)
+
+type App struct {
+	scenario widget.Enum
+	// scale is the workload scale on a logarithmic slider; 0.5 is scale
+	// 1.
+	scale widget.Float
+	pause widget.Bool
+	reset widget.Clickable
+
+	frame int
+	last  time.Time
+	// interval times the frames, build the construction of their
+	// operations and submit their Frame calls.
+	interval, build, submit timings
+	opsSize                 int
+	lastReport              time.Time
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := new(App)
+	a.scenario.Value = scenarios[0].name
+	for _, s := range scenarios {
+		if s.name == *scenarioFlag {
+			a.scenario.Value = s.name
+		}
+	}
+	a.scale.Value = float32(math.Log10(*scaleFlag)+1) / 2
+	var ops op.Ops
+	for e := range w.Events() {
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			start := time.Now()
+			gtx := layout.NewContext(&ops, e)
+			a.Layout(gtx, th)
+			built := time.Now()
+			e.Frame(gtx.Ops)
+			a.record(e.Now, built.Sub(start), time.Since(built), len(ops.Data()))
+		}
+	}
+	return nil
+}
+
+// record adds the timings of a frame.
+func (a *App) record(now time.Time, build, submit time.Duration, opsSize int) {
+	if !a.last.IsZero() {
+		a.interval.add(now.Sub(a.last))
+	}
+	a.last = now
+	a.build.add(build)
+	a.submit.add(submit)
+	a.opsSize = opsSize
+	if *reportFlag > 0 && now.Sub(a.lastReport) >= *reportFlag {
+		a.lastReport = now
+		log.Print(a.report())
+	}
+}
+
+func (a *App) resetTimings() {
+	a.interval.reset()
+	a.build.reset()
+	a.submit.reset()
+	a.last = time.Time{}
+}
+
+func (a *App) current() scenario {
+	for _, s := range scenarios {
+		if s.name == a.scenario.Value {
+			return s
+		}
+	}
+	return scenarios[0]
+}
+
+// scaleFactor maps the slider to scales from 0.1 to 10.
+func (a *App) scaleFactor() float64 {
+	return math.Pow(10, float64(a.scale.Value)*2-1)
+}
+
+func (a *App) count() int {
+	n := int(float64(a.current().base)*a.scaleFactor() + 0.5)
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+func ms(d time.Duration) string {
+	return fmt.Sprintf("%.1f", float64(d)/float64(time.Millisecond))
+}
+
+// report summarizes the timings.
+func (a *App) report() string {
+	s := a.current()
+	iv, b, sub := a.interval.summary(), a.build.summary(), a.submit.summary()
+	fps := 0.0
+	if iv.mean > 0 {
+		fps = float64(time.Second) / float64(iv.mean)
+	}
+	return fmt.Sprintf("%s ×%.2f (%d %s): %.0f fps, frame %s ms (p95 %s, max %s), build %s ms, submit %s ms, ops %d KiB",
+		s.name, a.scaleFactor(), a.count(), s.unit, fps, ms(iv.mean), ms(iv.p95), ms(iv.max), ms(b.mean), ms(sub.mean), a.opsSize/1024)
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	if a.scenario.Changed() || a.scale.Changed() {
+		a.resetTimings()
+	}
+	for a.reset.Clicked() {
+		a.resetTimings()
+	}
+	if a.pause.Changed() {
+		a.last = time.Time{}
+	}
+	if !a.pause.Value {
+		a.frame++
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+	paint.Fill(gtx.Ops, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+	st := op.Save(gtx.Ops)
+	a.current().layout(gtx, th, a.count(), a.frame)
+	st.Load()
+	return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+		gtx.Constraints.Max.X = gtx.Px(unit.Dp(440))
+		return a.layoutPanel(gtx, th)
+	})
+}
+
+func (a *App) layoutPanel(gtx C, th *material.Theme) D {
+	m := op.Record(gtx.Ops)
+	dims := layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx C) D {
+		s := a.current()
+		var radios []layout.FlexChild
+		for _, sc := range scenarios {
+			radios = append(radios, layout.Rigid(material.RadioButton(th, &a.scenario, sc.name, sc.name).Layout))
+		}
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return layout.Flex{}.Layout(gtx, radios...)
+			}),
+			layout.Rigid(material.Caption(th, s.desc).Layout),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+			layout.Rigid(material.Body2(th, fmt.Sprintf("Scale ×%.2f: %d %s", a.scaleFactor(), a.count(), s.unit)).Layout),
+			layout.Rigid(material.Slider(th, &a.scale, 0, 1).Layout),
+			layout.Rigid(func(gtx C) D {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(material.CheckBox(th, &a.pause, "Pause").Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+					layout.Rigid(material.Button(th, &a.reset, "Reset timings").Layout),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+			layout.Rigid(material.Body2(th, a.report()).Layout),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+			layout.Rigid(func(gtx C) D {
+				return a.layoutGraph(gtx)
+			}),
+		)
+	})
+	call := m.Stop()
+	r := f32.Rectangle{Max: layout.FPt(dims.Size)}
+	paint.FillShape(gtx.Ops, panelBg, clip.UniformRRect(r, float32(gtx.Px(unit.Dp(6)))).Op(gtx.Ops))
+	call.Add(gtx.Ops)
+	return dims
+}
+
+// layoutGraph draws the frame intervals as bars, with a line at the
+// 60 Hz budget.
+func (a *App) layoutGraph(gtx C) D {
+	const top = 50 * time.Millisecond
+	sz := image.Pt(gtx.Constraints.Max.X, gtx.Px(unit.Dp(60)))
+	y := func(d time.Duration) int {
+		if d > top {
+			d = top
+		}
+		return sz.Y - int(float64(sz.Y)*float64(d)/float64(top))
+	}
+	w := float32(sz.X) / maxFrames
+	for i, d := range a.interval.values() {
+		r := f32.Rect(float32(i)*w, float32(y(d)), float32(i+1)*w, float32(sz.Y))
+		paint.FillShape(gtx.Ops, barColor, clip.UniformRRect(r, 0).Op(gtx.Ops))
+	}
+	budget := y(time.Second / 60)
+	paint.FillShape(gtx.Ops, budgetColor, clip.Rect{Min: image.Pt(0, budget), Max: image.Pt(sz.X, budget+1)}.Op())
+	return D{Size: sz}
+}
@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/example/internal/colorpicker"
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+// scenario is a workload. Its layout draws n units of work, where n is
+// base at scale 1, animated by the frame number.
+type scenario struct {
+	name string
+	desc string
+	base int
+	// unit names what n counts.
+	unit   string
+	layout func(gtx C, th *material.Theme, n, frame int)
+}
+
+var scenarios = []scenario{
+	{
+		name:   "rects",
+		desc:   "Rounded rectangles in a grid, each clipped and painted on its own.",
+		base:   10000,
+		unit:   "rectangles",
+		layout: layoutRects,
+	},
+	{
+		name:   "transforms",
+		desc:   "Chains of nested transforms, each level rotating and moving the next, saved on a deep state stack.",
+		base:   500,
+		unit:   "levels per chain",
+		layout: layoutTransforms,
+	},
+	{
+		name:   "text",
+		desc:   "Lines of text in columns, with a frame counter in every line so that each frame shapes new text.",
+		base:   3000,
+		unit:   "lines",
+		layout: layoutText,
+	},
+	{
+		name:   "paths",
+		desc:   "A filled star of many points and a stroked spiral, both rebuilt every frame.",
+		base:   20000,
+		unit:   "vertices",
+		layout: layoutPaths,
+	},
+	{
+		name:   "overdraw",
+		desc:   "Translucent full window gradients painted on top of each other.",
+		base:   40,
+		unit:   "layers",
+		layout: layoutOverdraw,
+	},
+}
+
+// hue returns a saturated color of the hue in degrees.
+func hue(h float64) color.NRGBA {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	return colorpicker.HSV{H: float32(h), S: 0.6, V: 0.9}.RGB(0xff)
+}
+
+func layoutRects(gtx C, th *material.Theme, n, frame int) {
+	sz := gtx.Constraints.Max
+	// Choose square cells filling the window.
+	cell := int(math.Sqrt(float64(sz.X*sz.Y) / float64(n)))
+	if cell < 2 {
+		cell = 2
+	}
+	cols := sz.X / cell
+	if cols < 1 {
+		cols = 1
+	}
+	for i := 0; i < n; i++ {
+		x, y := i%cols*cell, i/cols*cell
+		r := f32.Rect(float32(x+1), float32(y+1), float32(x+cell-1), float32(y+cell-1))
+		c := hue(float64(i)*0.5 + float64(frame)*3)
+		paint.FillShape(gtx.Ops, c, clip.UniformRRect(r, float32(cell)/4).Op(gtx.Ops))
+	}
+}
+
+func layoutTransforms(gtx C, th *material.Theme, n, frame int) {
+	const chains = 24
+	center := layout.FPt(gtx.Constraints.Max).Mul(0.5)
+	step := float32(gtx.Px(unit.Dp(400))) / float32(n)
+	dot := float32(gtx.Px(unit.Dp(3)))
+	var stack []op.StateOp
+	for c := 0; c < chains; c++ {
+		angle := float32(c)*2*math.Pi/chains + float32(frame)*0.01
+		stack = append(stack[:0], op.Save(gtx.Ops))
+		op.Affine(f32.Affine2D{}.Rotate(f32.Point{}, angle).Offset(center)).Add(gtx.Ops)
+		for d := 0; d < n; d++ {
+			stack = append(stack, op.Save(gtx.Ops))
+			// Bend each chain a little at every level.
+			bend := 3 * float32(math.Sin(float64(frame)*0.02+float64(c))) / float32(n)
+			op.Affine(f32.Affine2D{}.Offset(f32.Pt(step, 0)).Rotate(f32.Pt(step, 0), bend)).Add(gtx.Ops)
+			if d%10 == 0 {
+				r := f32.Rect(-dot, -dot, dot, dot)
+				paint.FillShape(gtx.Ops, hue(float64(c*15+d)), clip.UniformRRect(r, dot).Op(gtx.Ops))
+			}
+		}
+		for i := len(stack) - 1; i >= 0; i-- {
+			stack[i].Load()
+		}
+	}
+}
+
+func layoutText(gtx C, th *material.Theme, n, frame int) {
+	lineHeight := gtx.Px(unit.Sp(13))
+	colWidth := gtx.Px(unit.Dp(280))
+	rows := gtx.Constraints.Max.Y / lineHeight
+	if rows < 1 {
+		rows = 1
+	}
+	gtx.Constraints.Min = image.Point{}
+	gtx.Constraints.Max.X = colWidth
+	for i := 0; i < n; i++ {
+		x, y := i/rows*colWidth, i%rows*lineHeight
+		st := op.Save(gtx.Ops)
+		op.Offset(f32.Pt(float32(x), float32(y))).Add(gtx.Ops)
+		l := material.Label(th, unit.Sp(10), fmt.Sprintf("Line %d of %d: the quick brown fox, frame %d", i+1, n, frame))
+		l.MaxLines = 1
+		l.Layout(gtx)
+		st.Load()
+	}
+}
+
+func layoutPaths(gtx C, th *material.Theme, n, frame int) {
+	sz := layout.FPt(gtx.Constraints.Max)
+	center := sz.Mul(0.5)
+	radius := float64(sz.X)
+	if float64(sz.Y) < radius {
+		radius = float64(sz.Y)
+	}
+	radius *= 0.45
+	t := float64(frame) * 0.01
+	// The star takes 3/4 of the vertices.
+	points := n * 3 / 4
+	var p clip.Path
+	p.Begin(gtx.Ops)
+	for i := 0; i < points; i++ {
+		a := float64(i)*2*math.Pi/float64(points) + t
+		r := radius
+		if i%2 == 1 {
+			r *= 0.6 + 0.3*math.Sin(t*3+float64(i)*0.001)
+		}
+		pt := center.Add(f32.Pt(float32(r*math.Cos(a)), float32(r*math.Sin(a))))
+		if i == 0 {
+			p.MoveTo(pt)
+		} else {
+			p.LineTo(pt)
+		}
+	}
+	p.Close()
+	paint.FillShape(gtx.Ops, hue(t*40), clip.Outline{Path: p.End()}.Op())
+
+	segs := n - points
+	p.Begin(gtx.Ops)
+	for i := 0; i <= segs; i++ {
+		f := float64(i) / float64(segs)
+		a := f*40*math.Pi - t*2
+		r := radius * f
+		pt := center.Add(f32.Pt(float32(r*math.Cos(a)), float32(r*math.Sin(a))))
+		if i == 0 {
+			p.MoveTo(pt)
+		} else {
+			p.LineTo(pt)
+		}
+	}
+	paint.FillShape(gtx.Ops, color.NRGBA{A: 0xc0}, clip.Stroke{
+		Path:  p.End(),
+		Style: clip.StrokeStyle{Width: float32(gtx.Px(unit.Dp(1.5)))},
+	}.Op())
+}
+
+func layoutOverdraw(gtx C, th *material.Theme, n, frame int) {
+	sz := layout.FPt(gtx.Constraints.Max)
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect{Max: gtx.Constraints.Max}.Add(gtx.Ops)
+	for i := 0; i < n; i++ {
+		a := float64(i)*2*math.Pi/float64(n) + float64(frame)*0.02
+		dir := f32.Pt(float32(math.Cos(a)), float32(math.Sin(a))).Mul(sz.X / 2)
+		c1, c2 := hue(float64(i*360/n)), hue(float64(i*360/n+180))
+		c1.A, c2.A = 0x18, 0x18
+		paint.LinearGradientOp{
+			Stop1:  sz.Mul(0.5).Sub(dir),
+			Color1: c1,
+			Stop2:  sz.Mul(0.5).Add(dir),
+			Color2: c2,
+		}.Add(gtx.Ops)
+		paint.PaintOp{}.Add(gtx.Ops)
+	}
+}
@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimings(t *testing.T) {
+	var tm timings
+	for i := 1; i <= maxFrames+2; i++ {
+		tm.add(time.Duration(i) * time.Millisecond)
+	}
+	v := tm.values()
+	if len(v) != maxFrames || v[0] != 3*time.Millisecond || v[len(v)-1] != (maxFrames+2)*time.Millisecond {
+		t.Fatalf("values are not the latest frames from the oldest: %v...%v", v[0], v[len(v)-1])
+	}
+	s := tm.summary()
+	want := summary{
+		n:    maxFrames,
+		mean: (maxFrames + 5) * time.Millisecond / 2,
+		p95:  230 * time.Millisecond,
+		max:  (maxFrames + 2) * time.Millisecond,
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("got %+v, want %+v", s, want)
+	}
+	tm.reset()
+	if s := tm.summary(); s.n != 0 {
+		t.Errorf("%d frames after reset", s.n)
+	}
+}
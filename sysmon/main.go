@@ -0,0 +1,427 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program is a system monitor with live graphs of the CPU, memory
+// and network usage, and a table of processes that can be sorted by
+// column and killed. Statistics are polled in the background and sent to
+// the event loop; lower the -interval flag to stress the redrawing of a
+// busy dashboard.
+//
+// The statistics are read with gopsutil, which supports Linux, macOS,
+// Windows and the BSDs.
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/bytesize"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+var interval = flag.Duration("interval", time.Second, "polling interval")
+
+// historyLen is the number of samples in the graphs.
+const historyLen = 120
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("System Monitor"),
+			app.Size(unit.Dp(900), unit.Dp(700)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// poll sends samples at every interval until stop is closed.
+func poll(samples chan Sample, errs chan<- error, stop <-chan struct{}) {
+	prev, err := readSnapshot()
+	if err != nil {
+		errs <- err
+		return
+	}
+	last := time.Now()
+	t := time.NewTicker(*interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-t.C:
+			cur, err := readSnapshot()
+			if err != nil {
+				errs <- err
+				return
+			}
+			s := diff(prev, cur, now.Sub(last))
+			prev, last = cur, now
+			// Drop samples the UI hasn't caught up with.
+			select {
+			case <-samples:
+			default:
+			}
+			samples <- s
+		}
+	}
+}
+
+// column is a column of the process table.
+type column int
+
+const (
+	colPID column = iota
+	colName
+	colCPU
+	colMem
+	numColumns
+)
+
+var columnNames = [numColumns]string{"PID", "Name", "CPU %", "Memory"}
+
+type App struct {
+	sample   Sample
+	cpu      series
+	mem      series
+	rx, tx   series
+	err      error
+	status   string
+	procs    []Process
+	sortCol  column
+	sortDesc bool
+	// armed is the process whose kill button asks for confirmation.
+	armed int
+
+	headers [numColumns]widget.Clickable
+	kills   map[int]*widget.Clickable
+	list    layout.List
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	samples := make(chan Sample, 1)
+	errs := make(chan error, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	go poll(samples, errs, stop)
+	a := &App{
+		cpu:      series{max: historyLen},
+		mem:      series{max: historyLen},
+		rx:       series{max: historyLen},
+		tx:       series{max: historyLen},
+		sortCol:  colCPU,
+		sortDesc: true,
+		kills:    make(map[int]*widget.Clickable),
+		list:     layout.List{Axis: layout.Vertical},
+	}
+	var ops op.Ops
+	for {
+		select {
+		case s := <-samples:
+			a.add(s)
+			w.Invalidate()
+		case err := <-errs:
+			a.err = err
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update()
+				layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+					return a.Layout(gtx, th)
+				})
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+// add records a sample.
+func (a *App) add(s Sample) {
+	a.sample = s
+	a.cpu.push(s.CPU)
+	a.mem.push(float64(s.MemUsed))
+	a.rx.push(s.RxRate)
+	a.tx.push(s.TxRate)
+	a.procs = s.Procs
+	a.sortProcs()
+	alive := make(map[int]bool, len(a.procs))
+	for _, p := range a.procs {
+		alive[p.PID] = true
+		if a.kills[p.PID] == nil {
+			a.kills[p.PID] = new(widget.Clickable)
+		}
+	}
+	for pid := range a.kills {
+		if !alive[pid] {
+			delete(a.kills, pid)
+		}
+	}
+}
+
+func (a *App) sortProcs() {
+	less := func(p, q Process) bool {
+		switch a.sortCol {
+		case colName:
+			return p.Name < q.Name
+		case colCPU:
+			return p.CPU < q.CPU
+		case colMem:
+			return p.Mem < q.Mem
+		default:
+			return p.PID < q.PID
+		}
+	}
+	sort.SliceStable(a.procs, func(i, j int) bool {
+		if a.sortDesc {
+			return less(a.procs[j], a.procs[i])
+		}
+		return less(a.procs[i], a.procs[j])
+	})
+}
+
+func (a *App) update() {
+	for i := range a.headers {
+		for a.headers[i].Clicked() {
+			col := column(i)
+			if a.sortCol == col {
+				a.sortDesc = !a.sortDesc
+			} else {
+				// Numbers sort largest first, names alphabetically.
+				a.sortCol, a.sortDesc = col, col == colCPU || col == colMem
+			}
+			a.sortProcs()
+		}
+	}
+	for _, p := range a.procs {
+		for a.kills[p.PID].Clicked() {
+			if a.armed != p.PID {
+				a.armed = p.PID
+				continue
+			}
+			a.armed = 0
+			a.status = fmt.Sprintf("Killed %s (%d).", p.Name, p.PID)
+			if err := kill(p.PID); err != nil {
+				a.status = fmt.Sprintf("Killing %s (%d): %v", p.Name, p.PID, err)
+			}
+		}
+	}
+}
+
+func kill(pid int) error {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return proc.Kill()
+}
+
+var (
+	cpuColor = color.NRGBA{R: 0x42, G: 0x85, B: 0xf4, A: 0xff}
+	memColor = color.NRGBA{R: 0xab, G: 0x47, B: 0xbc, A: 0xff}
+	rxColor  = color.NRGBA{R: 0x0f, G: 0x9d, B: 0x58, A: 0xff}
+	txColor  = color.NRGBA{R: 0xf4, G: 0xb4, B: 0x00, A: 0xff}
+)
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	if a.err != nil {
+		return layout.Center.Layout(gtx, material.Body1(th, a.err.Error()).Layout)
+	}
+	s := a.sample
+	netMax := 1024.0
+	for _, ser := range []*series{&a.rx, &a.tx} {
+		for _, v := range ser.values {
+			if v > netMax {
+				netMax = v
+			}
+		}
+	}
+	graphs := []layout.FlexChild{
+		layout.Flexed(1, func(gtx C) D {
+			return graph(gtx, th, "CPU", fmt.Sprintf("%.0f%%", s.CPU), 100, plot{&a.cpu, cpuColor})
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+		layout.Flexed(1, func(gtx C) D {
+			return graph(gtx, th, "Memory", fmt.Sprintf("%s of %s", bytesize.Format(int64(s.MemUsed)), bytesize.Format(int64(s.MemTotal))), float64(s.MemTotal), plot{&a.mem, memColor})
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+		layout.Flexed(1, func(gtx C) D {
+			value := fmt.Sprintf("↓ %s/s  ↑ %s/s", bytesize.Format(int64(s.RxRate)), bytesize.Format(int64(s.TxRate)))
+			return graph(gtx, th, "Network", value, netMax, plot{&a.rx, rxColor}, plot{&a.tx, txColor})
+		}),
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			gtx.Constraints.Max.Y = gtx.Px(unit.Dp(150))
+			return layout.Flex{}.Layout(gtx, graphs...)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+		layout.Rigid(func(gtx C) D {
+			return a.layoutRow(gtx, th, func(gtx C, col column) D {
+				label := columnNames[col]
+				if col == a.sortCol {
+					if a.sortDesc {
+						label += " ▼"
+					} else {
+						label += " ▲"
+					}
+				}
+				return material.Clickable(gtx, &a.headers[col], func(gtx C) D {
+					l := material.Body2(th, label)
+					l.Font.Weight = text.Bold
+					return layout.UniformInset(unit.Dp(4)).Layout(gtx, l.Layout)
+				})
+			}, func(gtx C) D {
+				return D{}
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return a.list.Layout(gtx, len(a.procs), func(gtx C, i int) D {
+				p := a.procs[i]
+				return a.layoutRow(gtx, th, func(gtx C, col column) D {
+					var v string
+					switch col {
+					case colPID:
+						v = strconv.Itoa(p.PID)
+					case colName:
+						v = p.Name
+					case colCPU:
+						v = fmt.Sprintf("%.1f", p.CPU)
+					case colMem:
+						v = bytesize.Format(int64(p.Mem))
+					}
+					l := material.Body2(th, v)
+					l.MaxLines = 1
+					return layout.UniformInset(unit.Dp(4)).Layout(gtx, l.Layout)
+				}, func(gtx C) D {
+					label := "Kill"
+					if a.armed == p.PID {
+						label = "Confirm"
+					}
+					b := material.Button(th, a.kills[p.PID], label)
+					b.Inset = layout.Inset{Top: unit.Dp(2), Bottom: unit.Dp(2), Left: unit.Dp(8), Right: unit.Dp(8)}
+					b.TextSize = th.TextSize.Scale(0.8)
+					b.Background = color.NRGBA{A: 0x18}
+					b.Color = th.Palette.Fg
+					if a.armed == p.PID {
+						b.Background = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+						b.Color = th.Palette.ContrastFg
+					}
+					return b.Layout(gtx)
+				})
+			})
+		}),
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, material.Caption(th, fmt.Sprintf("%d processes. %s", len(a.procs), a.status)).Layout)
+		}),
+	)
+}
+
+// layoutRow lays out a table row of cells and a trailing action.
+func (a *App) layoutRow(gtx C, th *material.Theme, cell func(gtx C, col column) D, action layout.Widget) D {
+	weights := [numColumns]float32{1, 3, 1, 1.5}
+	children := make([]layout.FlexChild, 0, numColumns+1)
+	for i := range weights {
+		col := column(i)
+		children = append(children, layout.Flexed(weights[i], func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			return cell(gtx, col)
+		}))
+	}
+	children = append(children, layout.Rigid(func(gtx C) D {
+		gtx.Constraints.Min.X = gtx.Px(unit.Dp(80))
+		return action(gtx)
+	}))
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx, children...)
+}
+
+// plot is a series drawn in a graph.
+type plot struct {
+	series *series
+	color  color.NRGBA
+}
+
+// graph draws a titled area chart of plots scaled to max.
+func graph(gtx C, th *material.Theme, title, value string, max float64, plots ...plot) D {
+	return widget.Border{Color: color.NRGBA{A: 0x30}, CornerRadius: unit.Dp(4), Width: unit.Dp(1)}.Layout(gtx, func(gtx C) D {
+		return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+			gtx.Constraints.Min = gtx.Constraints.Max
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					return layout.Flex{}.Layout(gtx,
+						layout.Flexed(1, material.Body2(th, title).Layout),
+						layout.Rigid(material.Caption(th, value).Layout),
+					)
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					size := gtx.Constraints.Max
+					for _, p := range plots {
+						area(gtx, size, p, max)
+					}
+					return D{Size: size}
+				}),
+			)
+		})
+	})
+}
+
+// area fills the area below the values of a plot, with the latest value
+// at the right edge.
+func area(gtx C, size image.Point, p plot, max float64) {
+	vals := p.series.values
+	if len(vals) < 2 || max <= 0 {
+		return
+	}
+	w, h := float32(size.X), float32(size.Y)
+	step := w / float32(historyLen-1)
+	x0 := w - step*float32(len(vals)-1)
+	y := func(v float64) float32 {
+		f := float32(v / max)
+		if f > 1 {
+			f = 1
+		}
+		return h - f*h
+	}
+	var path clip.Path
+	path.Begin(gtx.Ops)
+	path.MoveTo(f32.Pt(x0, h))
+	for i, v := range vals {
+		path.LineTo(f32.Pt(x0+float32(i)*step, y(v)))
+	}
+	path.LineTo(f32.Pt(w, h))
+	path.Close()
+	c := p.color
+	c.A = 0x80
+	paint.FillShape(gtx.Ops, c, clip.Outline{Path: path.End()}.Op())
+}
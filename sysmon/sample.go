@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"time"
+)
+
+// Process is the resource usage of a process.
+type Process struct {
+	PID  int
+	Name string
+	// CPU is the share of one CPU used, in percent.
+	CPU float64
+	// Mem is the resident memory in bytes.
+	Mem uint64
+}
+
+// Sample is the resource usage of the system between two snapshots.
+type Sample struct {
+	// CPU is the share of all CPUs used, in percent.
+	CPU               float64
+	MemUsed, MemTotal uint64
+	// RxRate and TxRate are the network rates in bytes per second.
+	RxRate, TxRate float64
+	Procs          []Process
+}
+
+// diff computes the usage between the snapshots prev and cur, taken dt
+// apart.
+func diff(prev, cur *snapshot, dt time.Duration) Sample {
+	s := Sample{
+		MemTotal: cur.mem.Total,
+		MemUsed:  cur.mem.Total - cur.mem.Available,
+	}
+	total := cur.cpu.total - prev.cpu.total
+	if total > 0 {
+		busy := total - (cur.cpu.idle - prev.cpu.idle)
+		s.CPU = busy / total * 100
+	}
+	if secs := dt.Seconds(); secs > 0 {
+		s.RxRate = float64(cur.net.rx-prev.net.rx) / secs
+		s.TxRate = float64(cur.net.tx-prev.net.tx) / secs
+	}
+	before := make(map[int]float64, len(prev.procs))
+	for _, p := range prev.procs {
+		before[p.pid] = p.cpu
+	}
+	ncpu := float64(cur.ncpu)
+	if ncpu == 0 {
+		ncpu = 1
+	}
+	for _, p := range cur.procs {
+		proc := Process{PID: p.pid, Name: p.name, Mem: p.rss}
+		if t, ok := before[p.pid]; ok && total > 0 && p.cpu >= t {
+			// The total counts the time of all CPUs.
+			proc.CPU = (p.cpu - t) / total * ncpu * 100
+		}
+		s.Procs = append(s.Procs, proc)
+	}
+	return s
+}
+
+// series is a fixed length history of values.
+type series struct {
+	values []float64
+	max    int
+}
+
+func (s *series) push(v float64) {
+	s.values = append(s.values, v)
+	if len(s.values) > s.max {
+		s.values = s.values[len(s.values)-s.max:]
+	}
+}
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"errors"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// snapshot is the raw state of the system at one time. Times are in
+// seconds, and counters count from boot.
+type snapshot struct {
+	cpu   cpuTimes
+	ncpu  int
+	mem   memInfo
+	net   netCounters
+	procs []procStat
+}
+
+type cpuTimes struct {
+	total, idle float64
+}
+
+type memInfo struct {
+	// Total and Available are in bytes.
+	Total, Available uint64
+}
+
+type netCounters struct {
+	rx, tx uint64
+}
+
+type procStat struct {
+	pid  int
+	name string
+	// cpu is the CPU time used by the process.
+	cpu float64
+	// rss is the resident set size in bytes.
+	rss uint64
+}
+
+func readSnapshot() (*snapshot, error) {
+	s := new(snapshot)
+	times, err := cpu.Times(false)
+	if err != nil {
+		return nil, err
+	}
+	if len(times) == 0 {
+		return nil, errors.New("no CPU times")
+	}
+	t := times[0]
+	s.cpu.idle = t.Idle + t.Iowait
+	// Guest time is counted in user time.
+	s.cpu.total = t.User + t.System + t.Nice + t.Irq + t.Softirq + t.Steal + s.cpu.idle
+	if s.ncpu, err = cpu.Counts(true); err != nil {
+		return nil, err
+	}
+	vm, err := mem.VirtualMemory()
+	if err != nil {
+		return nil, err
+	}
+	s.mem = memInfo{Total: vm.Total, Available: vm.Available}
+	if s.net, err = readNet(); err != nil {
+		return nil, err
+	}
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range procs {
+		// Processes may exit while they are read.
+		name, err := p.Name()
+		if err != nil {
+			continue
+		}
+		ps := procStat{pid: int(p.Pid), name: name}
+		// The usage of processes of other users may be hidden; show
+		// them without.
+		if t, err := p.Times(); err == nil {
+			ps.cpu = t.User + t.System
+		}
+		if m, err := p.MemoryInfo(); err == nil {
+			ps.rss = m.RSS
+		}
+		s.procs = append(s.procs, ps)
+	}
+	return s, nil
+}
+
+// readNet reads the bytes received and sent by all interfaces except
+// loopback.
+func readNet() (netCounters, error) {
+	var c netCounters
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return c, err
+	}
+	loopback := make(map[string]bool)
+	for _, ifi := range ifaces {
+		for _, f := range ifi.Flags {
+			if f == "loopback" {
+				loopback[ifi.Name] = true
+			}
+		}
+	}
+	counters, err := net.IOCounters(true)
+	if err != nil {
+		return c, err
+	}
+	for _, n := range counters {
+		if !loopback[n.Name] {
+			c.rx += n.BytesRecv
+			c.tx += n.BytesSent
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	prev := &snapshot{
+		cpu:   cpuTimes{total: 1000, idle: 800},
+		ncpu:  2,
+		net:   netCounters{rx: 1000, tx: 2000},
+		procs: []procStat{{pid: 1, cpu: 100}},
+	}
+	cur := &snapshot{
+		cpu:   cpuTimes{total: 1200, idle: 900},
+		ncpu:  2,
+		mem:   memInfo{Total: 100, Available: 25},
+		net:   netCounters{rx: 3000, tx: 2500},
+		procs: []procStat{{pid: 1, cpu: 150, rss: 10}, {pid: 2, cpu: 5}},
+	}
+	s := diff(prev, cur, 2*time.Second)
+	if s.CPU != 50 || s.MemUsed != 75 || s.RxRate != 1000 || s.TxRate != 250 {
+		t.Errorf("got %+v", s)
+	}
+	if len(s.Procs) != 2 || s.Procs[0].CPU != 50 || s.Procs[1].CPU != 0 {
+		t.Errorf("got processes %+v, want 50%% for the first and 0%% for the new one", s.Procs)
+	}
+}
@@ -0,0 +1,526 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program renders a sample text at many pixel sizes and weights to
+// evaluate text rasterization. Every row shows Gio's text next to a
+// reference rendering by the golang.org/x/image CPU rasterizer. Gio
+// renders glyph outlines on the GPU without hinting and blends in linear
+// light; the hinting, subpixel positioning and gamma settings apply to
+// the reference, so their effects can be compared against Gio's output.
+// The origin offset moves both renderings by a fraction of a pixel.
+//
+// Click a row to inspect its first pixels enlarged. The inspector renders
+// the Gio text offscreen and reads it back, so it shows the pixels
+// before any scaling by the window system. Save writes the enlarged
+// pixels and a description of the settings, for attaching to
+// reproducible bug reports.
+//
+// Usage:
+//
+//	go run ./textquality [-o dir]
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/gpu/headless"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var outDir = flag.String("o", ".", "directory for the saved inspections")
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Text Quality"),
+			app.Size(unit.Dp(1200), unit.Dp(800)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var (
+	errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	selectBg   = color.NRGBA{R: 0xe3, G: 0xf2, B: 0xfd, A: 0xff}
+	textColor  = color.NRGBA{A: 0xff}
+	white      = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+)
+
+const (
+	sampleText = "Hamburgefonstiv 0123456789 Il1|O0 rnm AVAWAY"
+	// inspectWidth is the number of pixel columns inspected.
+	inspectWidth = 48
+	zoomFactor   = 8
+)
+
+var (
+	sizes   = []int{8, 9, 10, 11, 12, 13, 14, 15, 16, 18, 20, 24, 32}
+	weights = []text.Weight{text.Normal, text.Medium, text.Bold}
+	gammas  = []string{"1.0", "1.45", "1.8", "2.2"}
+)
+
+func weightName(w text.Weight) string {
+	switch w {
+	case text.Medium:
+		return "Medium"
+	case text.Bold:
+		return "Bold"
+	default:
+		return "Regular"
+	}
+}
+
+// sample is a row of the page.
+type sample struct {
+	size   int
+	weight text.Weight
+	click  widget.Clickable
+	// ref is the reference rendering with the current settings.
+	ref   *image.RGBA
+	refOp paint.ImageOp
+}
+
+// inspectReq describes a rendering of Gio text to read back.
+type inspectReq struct {
+	text   string
+	size   int
+	weight text.Weight
+	origin float64
+}
+
+// inspection is the Gio rendering of a request.
+type inspection struct {
+	req inspectReq
+	img *image.RGBA
+	err error
+}
+
+type App struct {
+	samples []*sample
+	sel     int
+
+	editor            widget.Editor
+	hinting, subpixel widget.Bool
+	gamma             widget.Enum
+	origin, pan       widget.Float
+	save              widget.Clickable
+
+	faces faces
+	// refFor is the settings the reference renderings are for.
+	refFor string
+
+	reqs        chan inspectReq
+	inspections chan inspection
+	requested   inspectReq
+	inspected   inspection
+	// zoomed are the enlarged pixels of the Gio and reference
+	// renderings, for zoomFor.
+	zoomed  [2]*image.RGBA
+	zoomOps [2]paint.ImageOp
+	zoomFor string
+
+	status string
+	err    error
+	list   layout.List
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		reqs:        make(chan inspectReq, 1),
+		inspections: make(chan inspection, 1),
+		list:        layout.List{Axis: layout.Vertical},
+	}
+	a.editor.SingleLine = true
+	a.editor.SetText(sampleText)
+	a.subpixel.Value = true
+	a.gamma.Value = "2.2"
+	for _, w := range weights {
+		for _, sz := range sizes {
+			a.samples = append(a.samples, &sample{size: sz, weight: w})
+		}
+	}
+	a.sel = 4
+	go inspect(a.reqs, a.inspections)
+	defer close(a.reqs)
+	var ops op.Ops
+	for {
+		select {
+		case in := <-a.inspections:
+			a.inspected = in
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.update(gtx)
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+// inspect renders the requests with Gio and reads them back. It uses a
+// theme of its own, because text shapers aren't safe for concurrent use.
+func inspect(reqs <-chan inspectReq, results chan<- inspection) {
+	th := material.NewTheme(gofont.Collection())
+	for req := range reqs {
+		img, err := renderGio(th, req)
+		results <- inspection{req: req, img: img, err: err}
+	}
+}
+
+func gioLabel(th *material.Theme, req inspectReq) material.LabelStyle {
+	l := material.Label(th, unit.Px(float32(req.size)), req.text)
+	l.Font.Weight = req.weight
+	l.Color = textColor
+	l.MaxLines = 1
+	return l
+}
+
+func renderGio(th *material.Theme, req inspectReq) (*image.RGBA, error) {
+	var ops op.Ops
+	gtx := layout.Context{
+		Ops:         &ops,
+		Metric:      unit.Metric{PxPerDp: 1, PxPerSp: 1},
+		Constraints: layout.Constraints{Max: image.Pt(1<<14, 1<<10)},
+	}
+	m := op.Record(gtx.Ops)
+	dims := gioLabel(th, req).Layout(gtx)
+	call := m.Stop()
+	sz := image.Pt(int(math.Ceil(req.origin))+dims.Size.X+1, dims.Size.Y)
+	w, err := headless.NewWindow(sz.X, sz.Y)
+	if err != nil {
+		return nil, err
+	}
+	defer w.Release()
+	paint.Fill(gtx.Ops, white)
+	op.Offset(f32.Pt(float32(req.origin), 0)).Add(gtx.Ops)
+	call.Add(gtx.Ops)
+	if err := w.Frame(gtx.Ops); err != nil {
+		return nil, err
+	}
+	return w.Screenshot()
+}
+
+func (a *App) refOptions() refOptions {
+	g, err := strconv.ParseFloat(a.gamma.Value, 64)
+	if err != nil {
+		g = 2.2
+	}
+	return refOptions{hinting: a.hinting.Value, subpixel: a.subpixel.Value, gamma: g}
+}
+
+// originOffset returns the origin offset, in eighths of a pixel to keep
+// settings reproducible.
+func (a *App) originOffset() float64 {
+	return math.Round(float64(a.origin.Value)*8) / 8
+}
+
+func (a *App) update(gtx C) {
+	for i, s := range a.samples {
+		for s.click.Clicked() {
+			a.sel = i
+		}
+	}
+	opts := a.refOptions()
+	txt := a.editor.Text()
+	origin := a.originOffset()
+	if key := fmt.Sprintf("%q %v %v", txt, opts, origin); key != a.refFor {
+		a.refFor = key
+		a.err = nil
+		for _, s := range a.samples {
+			face, err := a.faces.face(faceKey{size: s.size, weight: s.weight, hinting: opts.hinting})
+			if err != nil {
+				a.err = err
+				break
+			}
+			s.ref = renderReference(face, txt, origin, opts)
+			s.refOp = paint.NewImageOp(s.ref)
+		}
+	}
+	s := a.samples[a.sel]
+	req := inspectReq{text: txt, size: s.size, weight: s.weight, origin: origin}
+	if req != a.requested {
+		a.requested = req
+		// Replace a request not yet started.
+		select {
+		case <-a.reqs:
+		default:
+		}
+		a.reqs <- req
+	}
+	in := a.inspected
+	if in.req == req && in.img != nil {
+		key := fmt.Sprintf("%s %v %v", a.refFor, in.req, a.pan.Value)
+		if key != a.zoomFor {
+			a.zoomFor = key
+			for i, img := range []*image.RGBA{in.img, s.ref} {
+				w := img.Bounds().Dx()
+				x := int(a.pan.Value * float32(w-inspectWidth))
+				if x < 0 {
+					x = 0
+				}
+				a.zoomed[i] = zoom(img, image.Rect(x, 0, x+inspectWidth, img.Bounds().Dy()), zoomFactor)
+				a.zoomOps[i] = paint.NewImageOp(a.zoomed[i])
+			}
+		}
+	}
+	for a.save.Clicked() {
+		a.status = ""
+		path, err := a.saveInspection(a.settings(gtx))
+		a.err = err
+		if err == nil {
+			a.status = "Saved " + path
+		}
+	}
+}
+
+// settings describes the settings of the selected sample.
+func (a *App) settings(gtx C) string {
+	s := a.samples[a.sel]
+	opts := a.refOptions()
+	onOff := func(b bool) string {
+		if b {
+			return "on"
+		}
+		return "off"
+	}
+	return fmt.Sprintf("Go %s %d px, origin %+.3f px; reference hinting %s, subpixel positioning %s, gamma %s; %s/%s, %.2f px per dp",
+		weightName(s.weight), s.size, a.originOffset(), onOff(opts.hinting), onOff(opts.subpixel), a.gamma.Value,
+		runtime.GOOS, runtime.GOARCH, gtx.Metric.PxPerDp)
+}
+
+// saveInspection writes the enlarged pixels as a PNG image, and the
+// settings next to it.
+func (a *App) saveInspection(settings string) (string, error) {
+	gio, ref := a.zoomed[0], a.zoomed[1]
+	if gio == nil || a.zoomFor == "" {
+		return "", fmt.Errorf("nothing to save yet")
+	}
+	const gap = 8
+	b := image.Rect(0, 0, gio.Bounds().Dx(), gio.Bounds().Dy()+gap+ref.Bounds().Dy())
+	if w := ref.Bounds().Dx(); w > b.Dx() {
+		b.Max.X = w
+	}
+	img := image.NewRGBA(b)
+	draw.Draw(img, b, image.NewUniform(white), image.Point{}, draw.Src)
+	draw.Draw(img, gio.Bounds(), gio, image.Point{}, draw.Src)
+	draw.Draw(img, ref.Bounds().Add(image.Pt(0, gio.Bounds().Dy()+gap)), ref, image.Point{}, draw.Src)
+	s := a.samples[a.sel]
+	base := filepath.Join(*outDir, fmt.Sprintf("textquality-%dpx-%s", s.size, weightName(s.weight)))
+	f, err := os.Create(base + ".png")
+	if err != nil {
+		return "", err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	desc := settings + "\nTop: Gio, bottom: reference rasterizer.\n"
+	if err := ioutil.WriteFile(base+".txt", []byte(desc), 0644); err != nil {
+		return "", err
+	}
+	return base + ".png", nil
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return widget.Border{Color: color.NRGBA{A: 0x60}, Width: unit.Dp(1), CornerRadius: unit.Dp(4)}.Layout(gtx, func(gtx C) D {
+					return layout.UniformInset(unit.Dp(6)).Layout(gtx, material.Editor(th, &a.editor, "Sample text").Layout)
+				})
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+			layout.Rigid(a.layoutControls(th)),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+			layout.Flexed(1, func(gtx C) D {
+				return layout.Flex{}.Layout(gtx,
+					layout.Flexed(1, func(gtx C) D {
+						return a.list.Layout(gtx, len(a.samples), func(gtx C, i int) D {
+							return a.layoutSample(gtx, th, i)
+						})
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+					layout.Rigid(func(gtx C) D {
+						gtx.Constraints.Max.X = gtx.Px(unit.Dp(420))
+						gtx.Constraints.Min.X = gtx.Constraints.Max.X
+						return a.layoutInspector(gtx, th)
+					}),
+				)
+			}),
+		)
+	})
+}
+
+func (a *App) layoutControls(th *material.Theme) layout.Widget {
+	return func(gtx C) D {
+		children := []layout.FlexChild{
+			layout.Rigid(material.CheckBox(th, &a.hinting, "Hinting").Layout),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+			layout.Rigid(material.CheckBox(th, &a.subpixel, "Subpixel positioning").Layout),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(24)}.Layout),
+			layout.Rigid(material.Body1(th, "Gamma").Layout),
+		}
+		for _, g := range gammas {
+			children = append(children, layout.Rigid(material.RadioButton(th, &a.gamma, g, g).Layout))
+		}
+		children = append(children,
+			layout.Rigid(layout.Spacer{Width: unit.Dp(24)}.Layout),
+			layout.Rigid(material.Body1(th, fmt.Sprintf("Origin %+.3f px", a.originOffset())).Layout),
+			layout.Rigid(func(gtx C) D {
+				gtx.Constraints.Min.X = gtx.Px(unit.Dp(160))
+				gtx.Constraints.Max.X = gtx.Constraints.Min.X
+				return material.Slider(th, &a.origin, 0, 1).Layout(gtx)
+			}),
+		)
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx, children...)
+	}
+}
+
+func (a *App) layoutSample(gtx C, th *material.Theme, i int) D {
+	s := a.samples[i]
+	return material.Clickable(gtx, &s.click, func(gtx C) D {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		m := op.Record(gtx.Ops)
+		dims := layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4), Left: unit.Dp(4)}.Layout(gtx, func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					gtx.Constraints.Min.X = gtx.Px(unit.Dp(96))
+					return material.Caption(th, fmt.Sprintf("%d px %s", s.size, weightName(s.weight))).Layout(gtx)
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(func(gtx C) D {
+							req := inspectReq{text: a.editor.Text(), size: s.size, weight: s.weight, origin: a.originOffset()}
+							defer op.Save(gtx.Ops).Load()
+							op.Offset(f32.Pt(float32(req.origin), 0)).Add(gtx.Ops)
+							return gioLabel(th, req).Layout(gtx)
+						}),
+						layout.Rigid(func(gtx C) D {
+							if s.ref == nil {
+								return D{}
+							}
+							return drawImage(gtx, s.ref, s.refOp)
+						}),
+					)
+				}),
+			)
+		})
+		call := m.Stop()
+		if i == a.sel {
+			paint.FillShape(gtx.Ops, selectBg, clip.Rect{Max: dims.Size}.Op())
+		}
+		call.Add(gtx.Ops)
+		return dims
+	})
+}
+
+// drawImage draws img at its pixel size, without any scaling that would
+// blur its pixels.
+func drawImage(gtx C, img image.Image, imgOp paint.ImageOp) D {
+	sz := img.Bounds().Size()
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect{Max: sz}.Add(gtx.Ops)
+	imgOp.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+	return D{Size: sz}
+}
+
+func (a *App) layoutInspector(gtx C, th *material.Theme) D {
+	in := a.inspected
+	zoomed := func(title string, i int) layout.FlexChild {
+		return layout.Rigid(func(gtx C) D {
+			if a.zoomed[i] == nil || in.req != a.requested {
+				return D{}
+			}
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(material.Body2(th, title).Layout),
+				layout.Rigid(func(gtx C) D {
+					return drawImage(gtx, a.zoomed[i], a.zoomOps[i])
+				}),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+			)
+		})
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(material.H6(th, "Inspector").Layout),
+		layout.Rigid(material.Caption(th, a.settings(gtx)).Layout),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+		layout.Rigid(func(gtx C) D {
+			switch {
+			case in.req != a.requested:
+				return material.Body2(th, "Rendering…").Layout(gtx)
+			case in.err != nil:
+				l := material.Body2(th, in.err.Error())
+				l.Color = errorColor
+				return l.Layout(gtx)
+			}
+			return D{}
+		}),
+		zoomed("Gio", 0),
+		zoomed("Reference", 1),
+		layout.Rigid(material.Body2(th, "Pan").Layout),
+		layout.Rigid(material.Slider(th, &a.pan, 0, 1).Layout),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					if a.zoomed[0] == nil || in.req != a.requested {
+						gtx = gtx.Disabled()
+					}
+					return material.Button(th, &a.save, "Save").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+				layout.Flexed(1, func(gtx C) D {
+					if a.err != nil {
+						l := material.Caption(th, a.err.Error())
+						l.Color = errorColor
+						return l.Layout(gtx)
+					}
+					return material.Caption(th, a.status).Layout(gtx)
+				}),
+			)
+		}),
+	)
+}
@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"gioui.org/text"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gomedium"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// refOptions are the settings of the reference rasterizer.
+type refOptions struct {
+	// hinting rounds advances and metrics to whole pixels. The sfnt
+	// package doesn't run the glyph programs of TrueType fonts, so the
+	// outlines themselves are never hinted.
+	hinting bool
+	// subpixel places glyphs at fractional positions instead of rounding
+	// every pen position to a whole pixel.
+	subpixel bool
+	// gamma is the gamma of the coverage blending. 1 blends the sRGB
+	// encoded values, which thins dark text on light backgrounds; 2.2
+	// approximates blending in linear light.
+	gamma float64
+}
+
+// faceKey identifies a face of the reference rasterizer.
+type faceKey struct {
+	size    int
+	weight  text.Weight
+	hinting bool
+}
+
+// faces caches the reference faces.
+type faces struct {
+	fonts map[text.Weight]*opentype.Font
+	faces map[faceKey]font.Face
+}
+
+func (f *faces) face(k faceKey) (font.Face, error) {
+	if face, ok := f.faces[k]; ok {
+		return face, nil
+	}
+	if f.fonts == nil {
+		f.fonts = make(map[text.Weight]*opentype.Font)
+		f.faces = make(map[faceKey]font.Face)
+	}
+	fnt, ok := f.fonts[k.weight]
+	if !ok {
+		data := goregular.TTF
+		switch k.weight {
+		case text.Medium:
+			data = gomedium.TTF
+		case text.Bold:
+			data = gobold.TTF
+		}
+		var err error
+		fnt, err = opentype.Parse(data)
+		if err != nil {
+			return nil, err
+		}
+		f.fonts[k.weight] = fnt
+	}
+	hinting := font.HintingNone
+	if k.hinting {
+		hinting = font.HintingFull
+	}
+	// At 72 DPI, points are pixels.
+	face, err := opentype.NewFace(fnt, &opentype.FaceOptions{Size: float64(k.size), DPI: 72, Hinting: hinting})
+	if err != nil {
+		return nil, err
+	}
+	f.faces[k] = face
+	return face, nil
+}
+
+// renderReference rasterizes black text on white, starting origin pixels
+// from the left edge.
+func renderReference(face font.Face, s string, origin float64, opts refOptions) *image.RGBA {
+	m := face.Metrics()
+	asc, desc := m.Ascent.Ceil(), m.Descent.Ceil()
+	width := int(math.Ceil(origin)) + font.MeasureString(face, s).Ceil() + 1
+	mask := image.NewAlpha(image.Rect(0, 0, width, asc+desc))
+	dot := fixed.Point26_6{X: fixed.Int26_6(origin * 64), Y: fixed.I(asc)}
+	prev := rune(-1)
+	for _, r := range s {
+		if prev >= 0 {
+			dot.X += face.Kern(prev, r)
+		}
+		if !opts.subpixel {
+			dot.X = fixed.I(dot.X.Round())
+		}
+		dr, glyph, gp, adv, ok := face.Glyph(dot, r)
+		if ok {
+			draw.DrawMask(mask, dr, image.Opaque, image.Point{}, glyph, gp, draw.Over)
+		}
+		dot.X += adv
+		prev = r
+	}
+	return composite(mask, opts.gamma)
+}
+
+// composite blends black through the coverage of mask onto white, with
+// the given gamma.
+func composite(mask *image.Alpha, gamma float64) *image.RGBA {
+	// The coverage levels map to 256 shades.
+	var shades [256]uint8
+	for i := range shades {
+		c := float64(i) / 0xff
+		shades[i] = uint8(math.Pow(1-c, 1/gamma)*0xff + 0.5)
+	}
+	b := mask.Bounds()
+	img := image.NewRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			v := shades[mask.AlphaAt(x, y).A]
+			img.SetRGBA(x, y, color.RGBA{R: v, G: v, B: v, A: 0xff})
+		}
+	}
+	return img
+}
+
+// gridColor separates the pixels of zoomed images.
+var gridColor = color.RGBA{R: 0xe0, G: 0xe0, B: 0xe0, A: 0xff}
+
+// zoom enlarges the pixels of src inside r factor times, drawing a
+// one pixel grid between them.
+func zoom(src image.Image, r image.Rectangle, factor int) *image.RGBA {
+	r = r.Intersect(src.Bounds())
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx()*factor+1, r.Dy()*factor+1))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(gridColor), image.Point{}, draw.Src)
+	for y := 0; y < r.Dy(); y++ {
+		for x := 0; x < r.Dx(); x++ {
+			c := src.At(r.Min.X+x, r.Min.Y+y)
+			cell := image.Rect(x*factor+1, y*factor+1, (x+1)*factor, (y+1)*factor)
+			draw.Draw(dst, cell, image.NewUniform(c), image.Point{}, draw.Src)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"gioui.org/text"
+)
+
+func TestComposite(t *testing.T) {
+	mask := image.NewAlpha(image.Rect(0, 0, 3, 1))
+	mask.Pix = []uint8{0, 0x80, 0xff}
+	for _, tc := range []struct {
+		gamma float64
+		want  []uint8
+	}{
+		{1, []uint8{0xff, 0x7f, 0}},
+		{2.2, []uint8{0xff, 0xba, 0}},
+	} {
+		img := composite(mask, tc.gamma)
+		for x, want := range tc.want {
+			if got := img.RGBAAt(x, 0).R; got != want {
+				t.Errorf("gamma %v, coverage %#x: got %#x, want %#x", tc.gamma, mask.Pix[x], got, want)
+			}
+		}
+	}
+}
+
+func TestZoom(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	red := color.RGBA{R: 0xff, A: 0xff}
+	src.SetRGBA(2, 1, red)
+	z := zoom(src, image.Rect(1, 1, 3, 2), 4)
+	if got, want := z.Bounds().Size(), image.Pt(9, 5); got != want {
+		t.Fatalf("zoomed size %v, want %v", got, want)
+	}
+	if got := z.RGBAAt(0, 0); got != gridColor {
+		t.Errorf("grid pixel is %v", got)
+	}
+	if got := z.RGBAAt(6, 2); got != red {
+		t.Errorf("zoomed pixel is %v, want %v", got, red)
+	}
+}
+
+func TestSubpixelPositioning(t *testing.T) {
+	var f faces
+	face, err := f.face(faceKey{size: 12, weight: text.Normal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	render := func(origin float64, subpixel bool) []byte {
+		return renderReference(face, "Il1", origin, refOptions{subpixel: subpixel, gamma: 2.2}).Pix
+	}
+	// Without subpixel positioning, half a pixel rounds to a whole.
+	if !bytes.Equal(render(0.5, false), render(1, false)) {
+		t.Error("snapped glyphs moved by the fraction of a pixel")
+	}
+	if bytes.Equal(render(0.5, true), render(1, true)) {
+		t.Error("subpixel positioned glyphs didn't move by the fraction of a pixel")
+	}
+}
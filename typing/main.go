@@ -0,0 +1,243 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program types made up text into an editor at a steady rate and
+// times the frames, for investigating editor performance on slow
+// devices and in browsers. It reports the frame intervals, the frames
+// dropped against the frame rate of the display, the time to lay out
+// the editor and how long characters wait before a frame shows them.
+//
+// Characters are inserted in the frames, when they are due; a late
+// frame inserts all the characters due since the frame before, as a
+// busy program receives key events. The editor slows down as the text
+// grows, so clear it to start over.
+//
+// Usage:
+//
+//	go run ./typing [-rate 20] [-fps 60] [-report 5s]
+//
+// To run it in a browser, build it with gogio:
+//
+//	go run gioui.org/cmd/gogio -target js -o typing-web ./typing
+
+import (
+	"flag"
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+	"os"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	rateFlag   = flag.Float64("rate", 20, "initial typing rate in characters per second, from 1 to 1000")
+	fpsFlag    = flag.Float64("fps", 60, "frame rate of the display, for counting dropped frames")
+	reportFlag = flag.Duration("report", 0, "log the timings at this interval")
+)
+
+var (
+	borderColor  = color.NRGBA{A: 0x40}
+	droppedColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+)
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Typing"),
+			app.Size(unit.Dp(800), unit.Dp(700)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+type App struct {
+	editor widget.Editor
+	typist *typist
+	// rate is the typing rate on a logarithmic slider, from 1 to 1000
+	// characters per second.
+	rate   widget.Float
+	typing widget.Bool
+	clear  widget.Clickable
+	reset  widget.Clickable
+
+	last time.Time
+	// interval times the frames while typing, edit the editor layouts
+	// and delay how long the first character inserted in each frame
+	// waited.
+	interval, edit, delay timings
+	frames, dropped       int
+	lastReport            time.Time
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	rate := math.Max(1, math.Min(*rateFlag, 1000))
+	a := &App{typist: newTypist(rate)}
+	a.rate.Value = float32(math.Log10(rate) / 3)
+	a.typing.Value = true
+	var ops op.Ops
+	for e := range w.Events() {
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			a.Layout(gtx, th)
+			e.Frame(gtx.Ops)
+		}
+	}
+	return nil
+}
+
+// cps returns the typing rate in characters per second.
+func (a *App) cps() float64 {
+	return math.Pow(10, 3*float64(a.rate.Value))
+}
+
+// budget returns the interval between frames of the display.
+func budget() time.Duration {
+	return time.Duration(float64(time.Second) / *fpsFlag)
+}
+
+func (a *App) resetTimings() {
+	a.interval.reset()
+	a.edit.reset()
+	a.delay.reset()
+	a.frames, a.dropped = 0, 0
+	a.last = time.Time{}
+}
+
+// record adds the interval since the frame before, and counts the
+// frames of the display it missed.
+func (a *App) record(now time.Time) {
+	if !a.last.IsZero() {
+		d := now.Sub(a.last)
+		a.interval.add(d)
+		a.frames++
+		if missed := int(float64(d)/float64(budget())+0.5) - 1; missed > 0 {
+			a.dropped += missed
+		}
+	}
+	a.last = now
+	if *reportFlag > 0 && now.Sub(a.lastReport) >= *reportFlag {
+		a.lastReport = now
+		log.Print(a.report())
+	}
+}
+
+func ms(d time.Duration) string {
+	return fmt.Sprintf("%.1f", float64(d)/float64(time.Millisecond))
+}
+
+// report summarizes the timings.
+func (a *App) report() string {
+	iv, l, d := a.interval.summary(), a.edit.summary(), a.delay.summary()
+	pct := 0.0
+	if n := a.frames + a.dropped; n > 0 {
+		pct = 100 * float64(a.dropped) / float64(n)
+	}
+	return fmt.Sprintf("%.0f chars/s, %d chars in %d lines: frame %s ms (p95 %s, max %s), %d dropped (%.1f%%), editor layout %s ms (p95 %s, max %s), typing delay %s ms (p95 %s, max %s)",
+		a.cps(), a.editor.Len(), a.editor.NumLines(),
+		ms(iv.mean), ms(iv.p95), ms(iv.max), a.dropped, pct,
+		ms(l.mean), ms(l.p95), ms(l.max),
+		ms(d.mean), ms(d.p95), ms(d.max))
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	if a.rate.Changed() {
+		a.typist.restart(gtx.Now, a.cps())
+		a.resetTimings()
+	}
+	if a.typing.Changed() {
+		a.typist.restart(gtx.Now, a.cps())
+		a.last = time.Time{}
+	}
+	for a.clear.Clicked() {
+		a.editor.SetText("")
+		a.resetTimings()
+	}
+	for a.reset.Clicked() {
+		a.resetTimings()
+	}
+	if a.typing.Value {
+		if s, delay := a.typist.due(gtx.Now); s != "" {
+			a.editor.Insert(s)
+			a.delay.add(delay)
+		}
+		a.record(gtx.Now)
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+				return a.layoutPanel(gtx, th)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Inset{Left: unit.Dp(12), Right: unit.Dp(12), Bottom: unit.Dp(12)}.Layout(gtx, func(gtx C) D {
+				return widget.Border{Color: borderColor, Width: unit.Dp(1)}.Layout(gtx, func(gtx C) D {
+					return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+						gtx.Constraints.Min = gtx.Constraints.Max
+						start := time.Now()
+						dims := material.Editor(th, &a.editor, "Typed text appears here").Layout(gtx)
+						if a.typing.Value {
+							a.edit.add(time.Since(start))
+						}
+						return dims
+					})
+				})
+			})
+		}),
+	)
+}
+
+func (a *App) layoutPanel(gtx C, th *material.Theme) D {
+	iv, l, d := a.interval.summary(), a.edit.summary(), a.delay.summary()
+	dropped := material.Body2(th, fmt.Sprintf("Frames: %d, dropped %d at %.0f fps", a.frames, a.dropped, *fpsFlag))
+	if a.dropped > 0 {
+		dropped.Color = droppedColor
+	}
+	stat := func(label string, s summary) layout.FlexChild {
+		return layout.Rigid(material.Body2(th, fmt.Sprintf("%s: %s ms, p95 %s ms, max %s ms", label, ms(s.mean), ms(s.p95), ms(s.max))).Layout)
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(material.Body1(th, fmt.Sprintf("Typing %.0f characters per second", a.cps())).Layout),
+		layout.Rigid(material.Slider(th, &a.rate, 0, 1).Layout),
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(material.CheckBox(th, &a.typing, "Type").Layout),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+				layout.Rigid(material.Button(th, &a.clear, "Clear text").Layout),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+				layout.Rigid(material.Button(th, &a.reset, "Reset timings").Layout),
+			)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+		layout.Rigid(material.Body2(th, fmt.Sprintf("Text: %d characters in %d lines", a.editor.Len(), a.editor.NumLines())).Layout),
+		layout.Rigid(dropped.Layout),
+		stat("Frame interval", iv),
+		stat("Editor layout", l),
+		stat("Typing delay", d),
+	)
+}
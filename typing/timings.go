@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// maxFrames is the number of frames timed.
+const maxFrames = 240
+
+// timings holds the durations of the latest frames.
+type timings struct {
+	d []time.Duration
+	// next is the index the next duration replaces once full.
+	next int
+}
+
+func (t *timings) add(d time.Duration) {
+	if len(t.d) < maxFrames {
+		t.d = append(t.d, d)
+		return
+	}
+	t.d[t.next] = d
+	t.next = (t.next + 1) % maxFrames
+}
+
+// values returns the durations from the oldest.
+func (t *timings) values() []time.Duration {
+	return append(append([]time.Duration(nil), t.d[t.next:]...), t.d[:t.next]...)
+}
+
+func (t *timings) reset() {
+	t.d = t.d[:0]
+	t.next = 0
+}
+
+// summary describes timings.
+type summary struct {
+	n              int
+	mean, p95, max time.Duration
+}
+
+func (t *timings) summary() summary {
+	n := len(t.d)
+	if n == 0 {
+		return summary{}
+	}
+	sorted := append([]time.Duration(nil), t.d...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	return summary{
+		n:    n,
+		mean: sum / time.Duration(n),
+		p95:  sorted[int(0.95*float64(n-1)+0.5)],
+		max:  sorted[n-1],
+	}
+}
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"strings"
+	"time"
+
+	"gioui.org/example/internal/fakedata"
+)
+
+// typist types text at a steady rate. Characters are due at fixed times
+// from the start, one every 1/rate seconds, so a late frame receives all
+// the characters due since the frame before, like a program busy while
+// keys are pressed.
+type typist struct {
+	source []rune
+	// next is the index in source of the next character.
+	next int
+
+	// rate is the number of characters per second.
+	rate  float64
+	start time.Time
+	// typed is the number of characters typed since start.
+	typed int
+}
+
+// newTypist returns a typist of paragraphs of made up text.
+func newTypist(rate float64) *typist {
+	f := fakedata.New(1)
+	var b strings.Builder
+	for i := 0; i < 100; i++ {
+		b.WriteString(f.Paragraph(1 + f.Intn(4)))
+		b.WriteString("\n\n")
+	}
+	return &typist{source: []rune(b.String()), rate: rate}
+}
+
+// restart restarts typing at now, at rate characters per second.
+func (t *typist) restart(now time.Time, rate float64) {
+	t.rate = rate
+	t.start = now
+	t.typed = 0
+}
+
+// due returns the characters due by now, and how long ago the first of
+// them was due. It returns an empty string if none are due.
+func (t *typist) due(now time.Time) (string, time.Duration) {
+	if t.start.IsZero() {
+		t.start = now
+	}
+	n := int(now.Sub(t.start).Seconds()*t.rate) - t.typed
+	if n <= 0 {
+		return "", 0
+	}
+	first := t.start.Add(time.Duration(float64(t.typed+1) / t.rate * float64(time.Second)))
+	t.typed += n
+	s := make([]rune, n)
+	for i := range s {
+		s[i] = t.source[t.next]
+		t.next = (t.next + 1) % len(t.source)
+	}
+	return string(s), now.Sub(first)
+}
@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTypist(t *testing.T) {
+	ty := &typist{source: []rune("abc")}
+	start := time.Now()
+	ty.restart(start, 10)
+	if s, _ := ty.due(start.Add(50 * time.Millisecond)); s != "" {
+		t.Errorf("typed %q before the first character was due", s)
+	}
+	s, delay := ty.due(start.Add(120 * time.Millisecond))
+	if s != "a" || delay != 20*time.Millisecond {
+		t.Errorf("got %q %v, want \"a\" 20ms", s, delay)
+	}
+	// A late frame gets every character due since, and the source
+	// repeats.
+	s, delay = ty.due(start.Add(450 * time.Millisecond))
+	if s != "bca" || delay != 250*time.Millisecond {
+		t.Errorf("got %q %v, want \"bca\" 250ms", s, delay)
+	}
+	ty.restart(start.Add(time.Second), 100)
+	if s, _ := ty.due(start.Add(time.Second + 25*time.Millisecond)); s != "bc" {
+		t.Errorf("got %q after restarting at a new rate, want \"bc\"", s)
+	}
+}
@@ -0,0 +1,268 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client is a client of the Open-Meteo forecast and geocoding APIs,
+// which are free and need no key.
+type Client struct {
+	HTTP        *http.Client
+	GeocodeURL  string
+	ForecastURL string
+	// Fahrenheit selects imperial units: degrees Fahrenheit and miles
+	// per hour.
+	Fahrenheit bool
+}
+
+// Location is a place to forecast.
+type Location struct {
+	Name    string
+	Region  string
+	Country string
+	Lat     float64
+	Lon     float64
+}
+
+// Detail returns the region and country of a location.
+func (l Location) Detail() string {
+	var parts []string
+	for _, p := range []string{l.Region, l.Country} {
+		if p != "" && p != l.Name {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Forecast is the weather at a location, now and in the coming hours
+// and days. Times are in the time zone of the location.
+type Forecast struct {
+	Current Current
+	Hours   []Hour
+	Days    []Day
+}
+
+type Current struct {
+	Time time.Time
+	Temp float64
+	// Wind is the wind speed and WindDir the direction it blows from,
+	// in degrees clockwise from north.
+	Wind    float64
+	WindDir float64
+	Code    int
+}
+
+type Hour struct {
+	Time time.Time
+	Temp float64
+	// Precip is the probability of precipitation, in percent.
+	Precip float64
+	Code   int
+}
+
+type Day struct {
+	Date     time.Time
+	Min, Max float64
+	Code     int
+}
+
+func NewClient() *Client {
+	return &Client{
+		HTTP:        http.DefaultClient,
+		GeocodeURL:  "https://geocoding-api.open-meteo.com/v1/search",
+		ForecastURL: "https://api.open-meteo.com/v1/forecast",
+	}
+}
+
+// TempUnit returns the unit of temperatures.
+func (c *Client) TempUnit() string {
+	if c.Fahrenheit {
+		return "°F"
+	}
+	return "°C"
+}
+
+// WindUnit returns the unit of wind speeds.
+func (c *Client) WindUnit() string {
+	if c.Fahrenheit {
+		return "mph"
+	}
+	return "km/h"
+}
+
+// Search returns the places matching a name, most populous first.
+func (c *Client) Search(ctx context.Context, name string) ([]Location, error) {
+	q := url.Values{
+		"name":     {name},
+		"count":    {"8"},
+		"language": {"en"},
+		"format":   {"json"},
+	}
+	var resp struct {
+		Results []struct {
+			Name      string  `json:"name"`
+			Admin1    string  `json:"admin1"`
+			Country   string  `json:"country"`
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		} `json:"results"`
+	}
+	if err := c.get(ctx, c.GeocodeURL, q, &resp); err != nil {
+		return nil, err
+	}
+	var locs []Location
+	for _, r := range resp.Results {
+		locs = append(locs, Location{
+			Name:    r.Name,
+			Region:  r.Admin1,
+			Country: r.Country,
+			Lat:     r.Latitude,
+			Lon:     r.Longitude,
+		})
+	}
+	return locs, nil
+}
+
+// Forecast returns the forecast of a location for the next week.
+func (c *Client) Forecast(ctx context.Context, loc Location) (*Forecast, error) {
+	q := url.Values{
+		"latitude":        {strconv.FormatFloat(loc.Lat, 'f', 4, 64)},
+		"longitude":       {strconv.FormatFloat(loc.Lon, 'f', 4, 64)},
+		"current_weather": {"true"},
+		"hourly":          {"temperature_2m,precipitation_probability,weathercode"},
+		"daily":           {"weathercode,temperature_2m_max,temperature_2m_min"},
+		"timezone":        {"auto"},
+	}
+	if c.Fahrenheit {
+		q.Set("temperature_unit", "fahrenheit")
+		q.Set("windspeed_unit", "mph")
+	}
+	var resp struct {
+		UTCOffset int `json:"utc_offset_seconds"`
+		Current   struct {
+			Time          string  `json:"time"`
+			Temperature   float64 `json:"temperature"`
+			WindSpeed     float64 `json:"windspeed"`
+			WindDirection float64 `json:"winddirection"`
+			WeatherCode   int     `json:"weathercode"`
+		} `json:"current_weather"`
+		Hourly struct {
+			Time        []string   `json:"time"`
+			Temperature []float64  `json:"temperature_2m"`
+			Precip      []*float64 `json:"precipitation_probability"`
+			WeatherCode []int      `json:"weathercode"`
+		} `json:"hourly"`
+		Daily struct {
+			Time        []string  `json:"time"`
+			WeatherCode []int     `json:"weathercode"`
+			Max         []float64 `json:"temperature_2m_max"`
+			Min         []float64 `json:"temperature_2m_min"`
+		} `json:"daily"`
+	}
+	if err := c.get(ctx, c.ForecastURL, q, &resp); err != nil {
+		return nil, err
+	}
+	zone := time.FixedZone("", resp.UTCOffset)
+	parse := func(layout, s string) (time.Time, error) {
+		t, err := time.ParseInLocation(layout, s, zone)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("weather: bad time %q", s)
+		}
+		return t, nil
+	}
+	const hourLayout, dayLayout = "2006-01-02T15:04", "2006-01-02"
+	f := &Forecast{
+		Current: Current{
+			Temp:    resp.Current.Temperature,
+			Wind:    resp.Current.WindSpeed,
+			WindDir: resp.Current.WindDirection,
+			Code:    resp.Current.WeatherCode,
+		},
+	}
+	var err error
+	if f.Current.Time, err = parse(hourLayout, resp.Current.Time); err != nil {
+		return nil, err
+	}
+	h := resp.Hourly
+	if len(h.Temperature) != len(h.Time) || len(h.WeatherCode) != len(h.Time) {
+		return nil, errors.New("weather: malformed hourly forecast")
+	}
+	for i, ts := range h.Time {
+		t, err := parse(hourLayout, ts)
+		if err != nil {
+			return nil, err
+		}
+		hour := Hour{Time: t, Temp: h.Temperature[i], Code: h.WeatherCode[i]}
+		// The probability is missing beyond the range of some models.
+		if i < len(h.Precip) && h.Precip[i] != nil {
+			hour.Precip = *h.Precip[i]
+		}
+		f.Hours = append(f.Hours, hour)
+	}
+	d := resp.Daily
+	if len(d.WeatherCode) != len(d.Time) || len(d.Max) != len(d.Time) || len(d.Min) != len(d.Time) {
+		return nil, errors.New("weather: malformed daily forecast")
+	}
+	for i, ds := range d.Time {
+		t, err := parse(dayLayout, ds)
+		if err != nil {
+			return nil, err
+		}
+		f.Days = append(f.Days, Day{Date: t, Min: d.Min[i], Max: d.Max[i], Code: d.WeatherCode[i]})
+	}
+	return f, nil
+}
+
+// Upcoming returns the hours of the forecast from the current one on, at
+// most n of them.
+func (f *Forecast) Upcoming(n int) []Hour {
+	from := f.Current.Time.Truncate(time.Hour)
+	for i, h := range f.Hours {
+		if !h.Time.Before(from) {
+			hours := f.Hours[i:]
+			if len(hours) > n {
+				hours = hours[:n]
+			}
+			return hours
+		}
+	}
+	return nil
+}
+
+// get decodes the JSON response to a GET request. The APIs report
+// errors as JSON objects with a reason.
+func (c *Client) get(ctx context.Context, base string, q url.Values, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", base+"?"+q.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Reason string `json:"reason"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Reason != "" {
+			return fmt.Errorf("weather: %s", apiErr.Reason)
+		}
+		return fmt.Errorf("weather: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("weather: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const geocodeResponse = `{"results":[
+	{"id":2950159,"name":"Berlin","latitude":52.52437,"longitude":13.41053,"country":"Germany","admin1":"Land Berlin"},
+	{"id":5083330,"name":"Berlin","latitude":44.46867,"longitude":-71.18508,"country":"United States","admin1":"New Hampshire"}
+],"generationtime_ms":0.5}`
+
+const forecastResponse = `{
+	"latitude":52.52,"longitude":13.42,"utc_offset_seconds":7200,"timezone":"Europe/Berlin",
+	"current_weather":{"temperature":13.3,"windspeed":10.1,"winddirection":275,"weathercode":61,"time":"2021-05-20T10:00"},
+	"hourly":{
+		"time":["2021-05-20T08:00","2021-05-20T09:00","2021-05-20T10:00","2021-05-20T11:00","2021-05-20T12:00"],
+		"temperature_2m":[11.2,12.0,13.3,14.1,14.8],
+		"precipitation_probability":[10,20,60,null,40],
+		"weathercode":[3,3,61,61,2]
+	},
+	"daily":{
+		"time":["2021-05-20","2021-05-21"],
+		"weathercode":[61,1],
+		"temperature_2m_max":[15.2,18.9],
+		"temperature_2m_min":[8.1,9.4]
+	}
+}`
+
+func newTestClient(t *testing.T) *Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/search":
+			if q.Get("name") != "berl" {
+				fmt.Fprint(w, `{"generationtime_ms":0.1}`)
+				return
+			}
+			fmt.Fprint(w, geocodeResponse)
+		case "/forecast":
+			if q.Get("latitude") == "100.0000" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":true,"reason":"Latitude must be in range of -90 to 90°. Given: 100.0."}`)
+				return
+			}
+			if q.Get("temperature_unit") != "" {
+				t.Errorf("unexpected temperature unit %q", q.Get("temperature_unit"))
+			}
+			fmt.Fprint(w, forecastResponse)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return &Client{
+		HTTP:        srv.Client(),
+		GeocodeURL:  srv.URL + "/search",
+		ForecastURL: srv.URL + "/forecast",
+	}
+}
+
+func TestSearch(t *testing.T) {
+	c := newTestClient(t)
+	locs, err := c.Search(context.Background(), "berl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locs) != 2 {
+		t.Fatalf("%d locations, want 2", len(locs))
+	}
+	want := Location{Name: "Berlin", Region: "Land Berlin", Country: "Germany", Lat: 52.52437, Lon: 13.41053}
+	if locs[0] != want {
+		t.Errorf("location %+v, want %+v", locs[0], want)
+	}
+	if d := locs[1].Detail(); d != "New Hampshire, United States" {
+		t.Errorf("detail %q", d)
+	}
+	locs, err = c.Search(context.Background(), "nowhere")
+	if err != nil || len(locs) != 0 {
+		t.Errorf("search for nowhere: %v, %v", locs, err)
+	}
+}
+
+func TestForecast(t *testing.T) {
+	c := newTestClient(t)
+	f, err := c.Forecast(context.Background(), Location{Name: "Berlin", Lat: 52.52, Lon: 13.41})
+	if err != nil {
+		t.Fatal(err)
+	}
+	zone := time.FixedZone("", 7200)
+	if want := time.Date(2021, 5, 20, 10, 0, 0, 0, zone); !f.Current.Time.Equal(want) {
+		t.Errorf("current time %v, want %v", f.Current.Time, want)
+	}
+	if f.Current.Temp != 13.3 || f.Current.Code != 61 || compass(f.Current.WindDir) != "west" {
+		t.Errorf("current weather %+v", f.Current)
+	}
+	if len(f.Hours) != 5 || f.Hours[3].Precip != 0 || f.Hours[4].Precip != 40 {
+		t.Errorf("hours %+v", f.Hours)
+	}
+	if len(f.Days) != 2 || f.Days[1].Max != 18.9 || f.Days[1].Date.Day() != 21 {
+		t.Errorf("days %+v", f.Days)
+	}
+	up := f.Upcoming(2)
+	if len(up) != 2 || up[0].Temp != 13.3 || up[1].Temp != 14.1 {
+		t.Errorf("upcoming hours %+v", up)
+	}
+	if up := f.Upcoming(24); len(up) != 3 {
+		t.Errorf("%d upcoming hours, want 3", len(up))
+	}
+}
+
+func TestForecastError(t *testing.T) {
+	c := newTestClient(t)
+	_, err := c.Forecast(context.Background(), Location{Lat: 100})
+	if err == nil || !strings.Contains(err.Error(), "Latitude must be in range") {
+		t.Errorf("error %v, want the reason of the API", err)
+	}
+	c.ForecastURL = strings.Replace(c.ForecastURL, "/forecast", "/missing", 1)
+	if _, err := c.Forecast(context.Background(), Location{}); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("error %v, want a 404 status", err)
+	}
+}
+
+func TestTempRange(t *testing.T) {
+	tests := []struct {
+		temps  []float64
+		lo, hi float64
+	}{
+		{[]float64{11.2, 14.8}, 11, 15},
+		{[]float64{10, 10}, 8, 12},
+		{[]float64{-3.5, 12.1}, -4, 13},
+	}
+	for _, test := range tests {
+		var hours []Hour
+		for _, temp := range test.temps {
+			hours = append(hours, Hour{Temp: temp})
+		}
+		if lo, hi := tempRange(hours); lo != test.lo || hi != test.hi {
+			t.Errorf("range of %v: %v, %v; want %v, %v", test.temps, lo, hi, test.lo, test.hi)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/example/internal/style"
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+var tempColor = color.NRGBA{R: 0xf4, G: 0x51, B: 0x1e, A: 0xff}
+
+// ChartStyle draws a chart of the temperature of hours as a line over
+// the probability of precipitation as bars, labelled every few hours.
+type ChartStyle struct {
+	Hours []Hour
+	// Every is the number of hours between labels.
+	Every int
+	Theme *material.Theme
+}
+
+func hourlyChart(th *material.Theme, hours []Hour) ChartStyle {
+	return ChartStyle{Hours: hours, Every: 3, Theme: th}
+}
+
+// tempRange returns the range of the temperature axis: the range of the
+// temperatures widened to whole degrees and a span of at least 4.
+func tempRange(hours []Hour) (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, h := range hours {
+		lo = math.Min(lo, h.Temp)
+		hi = math.Max(hi, h.Temp)
+	}
+	lo, hi = math.Floor(lo), math.Ceil(hi)
+	if d := 4 - (hi - lo); d > 0 {
+		lo -= math.Floor(d / 2)
+		hi += math.Ceil(d / 2)
+	}
+	return lo, hi
+}
+
+func (c ChartStyle) Layout(gtx layout.Context) layout.Dimensions {
+	size := gtx.Constraints.Max
+	n := len(c.Hours)
+	if n < 2 {
+		return layout.Dimensions{Size: size}
+	}
+	th := c.Theme
+	labelH := gtx.Px(unit.Dp(18))
+	// The plot leaves room for the temperatures above it and the hours
+	// below.
+	top, bottom := float32(labelH), float32(size.Y-labelH)
+	plotH := bottom - top
+	slot := float32(size.X) / float32(n)
+	x := func(i int) float32 { return (float32(i) + 0.5) * slot }
+	lo, hi := tempRange(c.Hours)
+	y := func(temp float64) float32 {
+		return bottom - float32((temp-lo)/(hi-lo))*plotH*0.8
+	}
+
+	// Precipitation bars, up to 40% of the plot for certain rain.
+	for i, h := range c.Hours {
+		if h.Precip <= 0 {
+			continue
+		}
+		bh := float32(h.Precip/100) * plotH * 0.4
+		r := f32.Rect(x(i)-slot*0.3, bottom-bh, x(i)+slot*0.3, bottom)
+		paint.FillShape(gtx.Ops, style.MulAlpha(rainColor, 0x60), clip.RRect{Rect: r}.Op(gtx.Ops))
+	}
+
+	// The area below the temperatures, and their line.
+	var area clip.Path
+	area.Begin(gtx.Ops)
+	area.MoveTo(f32.Pt(x(0), bottom))
+	for i, h := range c.Hours {
+		area.LineTo(f32.Pt(x(i), y(h.Temp)))
+	}
+	area.LineTo(f32.Pt(x(n-1), bottom))
+	area.Close()
+	paint.FillShape(gtx.Ops, style.MulAlpha(tempColor, 0x30), clip.Outline{Path: area.End()}.Op())
+	var path clip.Path
+	path.Begin(gtx.Ops)
+	path.MoveTo(f32.Pt(x(0), y(c.Hours[0].Temp)))
+	for i, h := range c.Hours[1:] {
+		path.LineTo(f32.Pt(x(i+1), y(h.Temp)))
+	}
+	width := float32(gtx.Px(unit.Dp(2)))
+	paint.FillShape(gtx.Ops, tempColor, clip.Stroke{Path: path.End(), Style: clip.StrokeStyle{Width: width}}.Op())
+
+	// Labels: the temperature above the line and the hour below the
+	// plot, centered on the points.
+	every := c.Every
+	if every < 1 {
+		every = 1
+	}
+	label := func(txt string, center f32.Point, col color.NRGBA) {
+		st := op.Save(gtx.Ops)
+		w := int(slot * float32(every))
+		op.Offset(f32.Pt(center.X-float32(w)/2, center.Y)).Add(gtx.Ops)
+		gtx := gtx
+		gtx.Constraints = layout.Exact(image.Pt(w, labelH))
+		l := material.Caption(th, txt)
+		l.Color = col
+		l.Alignment = text.Middle
+		l.MaxLines = 1
+		l.Layout(gtx)
+		st.Load()
+	}
+	for i := 0; i < n; i += every {
+		h := c.Hours[i]
+		label(fmt.Sprintf("%.0f°", h.Temp), f32.Pt(x(i), y(h.Temp)-float32(labelH)), th.Palette.Fg)
+		label(h.Time.Format("15h"), f32.Pt(x(i), bottom), style.MulAlpha(th.Palette.Fg, 0xa0))
+	}
+	return layout.Dimensions{Size: size}
+}
@@ -0,0 +1,219 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+)
+
+// Condition is the kind of weather an icon shows.
+type Condition int
+
+const (
+	Clear Condition = iota
+	PartlyCloudy
+	Cloudy
+	Fog
+	Drizzle
+	Rain
+	Snow
+	Thunder
+)
+
+// condition returns the condition and the description of a WMO weather
+// code, as used by the forecast API.
+func condition(code int) (Condition, string) {
+	switch code {
+	case 0:
+		return Clear, "Clear sky"
+	case 1:
+		return Clear, "Mainly clear"
+	case 2:
+		return PartlyCloudy, "Partly cloudy"
+	case 3:
+		return Cloudy, "Overcast"
+	case 45, 48:
+		return Fog, "Fog"
+	case 51, 53, 55:
+		return Drizzle, "Drizzle"
+	case 56, 57:
+		return Drizzle, "Freezing drizzle"
+	case 61, 63, 65:
+		return Rain, "Rain"
+	case 66, 67:
+		return Rain, "Freezing rain"
+	case 80, 81, 82:
+		return Rain, "Rain showers"
+	case 71, 73, 75, 77:
+		return Snow, "Snow"
+	case 85, 86:
+		return Snow, "Snow showers"
+	case 95:
+		return Thunder, "Thunderstorm"
+	case 96, 99:
+		return Thunder, "Thunderstorm with hail"
+	}
+	return Cloudy, "Unknown"
+}
+
+var (
+	sunColor       = color.NRGBA{R: 0xff, G: 0xb3, B: 0x00, A: 0xff}
+	cloudColor     = color.NRGBA{R: 0xb0, G: 0xbe, B: 0xc5, A: 0xff}
+	darkCloudColor = color.NRGBA{R: 0x78, G: 0x90, B: 0x9c, A: 0xff}
+	rainColor      = color.NRGBA{R: 0x42, G: 0xa5, B: 0xf5, A: 0xff}
+	snowColor      = color.NRGBA{R: 0x90, G: 0xca, B: 0xf9, A: 0xff}
+	boltColor      = color.NRGBA{R: 0xff, G: 0xd6, B: 0x00, A: 0xff}
+)
+
+// IconStyle draws a weather icon, animated by time: the rays of the sun
+// turn, clouds drift, and rain and snow fall.
+type IconStyle struct {
+	Cond Condition
+	Size unit.Value
+	// Time is the time of the animation, in seconds.
+	Time float32
+}
+
+func (s IconStyle) Layout(gtx layout.Context) layout.Dimensions {
+	sz := gtx.Px(s.Size)
+	size := gtx.Constraints.Constrain(image.Pt(sz, sz))
+	drawIcon(gtx.Ops, s.Cond, float32(size.X), s.Time)
+	return layout.Dimensions{Size: size}
+}
+
+// drawIcon draws the icon of a condition in a square of side s at time
+// t.
+func drawIcon(ops *op.Ops, cond Condition, s, t float32) {
+	pt := func(x, y float32) f32.Point { return f32.Pt(x*s, y*s) }
+	// drift is the sideways sway of clouds.
+	drift := float32(math.Sin(float64(t)*0.8)) * 0.03
+	switch cond {
+	case Clear:
+		sun(ops, pt(0.5, 0.5), 0.4*s, t)
+	case PartlyCloudy:
+		sun(ops, pt(0.38, 0.36), 0.28*s, t)
+		cloud(ops, pt(0.56+drift, 0.58), 0.7*s, cloudColor)
+	case Cloudy:
+		cloud(ops, pt(0.62-drift, 0.4), 0.6*s, darkCloudColor)
+		cloud(ops, pt(0.45+drift, 0.55), 0.8*s, cloudColor)
+	case Fog:
+		cloud(ops, pt(0.5+drift, 0.36), 0.8*s, cloudColor)
+		for i := 0; i < 3; i++ {
+			y := 0.72 + float32(i)*0.1
+			dx := float32(math.Sin(float64(t)*1.2+float64(i)*2)) * 0.06
+			paint.FillShape(ops, darkCloudColor, line(ops, pt(0.2+dx, y), pt(0.8+dx, y), 0.04*s))
+		}
+	case Drizzle:
+		drops(ops, s, t, 3, 0.7, rainColor)
+		cloud(ops, pt(0.5+drift, 0.38), 0.85*s, cloudColor)
+	case Rain:
+		drops(ops, s, t, 5, 1.1, rainColor)
+		cloud(ops, pt(0.5+drift, 0.38), 0.85*s, darkCloudColor)
+	case Snow:
+		flakes(ops, s, t)
+		cloud(ops, pt(0.5+drift, 0.38), 0.85*s, cloudColor)
+	case Thunder:
+		drops(ops, s, t, 2, 1.1, rainColor)
+		bolt(ops, s, t)
+		cloud(ops, pt(0.5+drift, 0.36), 0.85*s, darkCloudColor)
+	}
+}
+
+// sun draws a sun of radius r with turning rays that pulse slightly.
+func sun(ops *op.Ops, c f32.Point, r, t float32) {
+	paint.FillShape(ops, sunColor, clip.Circle{Center: c, Radius: r * 0.55}.Op(ops))
+	const rays = 8
+	turn := float64(t) * 0.25
+	pulse := 1 + 0.06*float32(math.Sin(float64(t)*2))
+	for i := 0; i < rays; i++ {
+		a := turn + float64(i)*2*math.Pi/rays
+		dx, dy := float32(math.Cos(a)), float32(math.Sin(a))
+		p0 := c.Add(f32.Pt(dx, dy).Mul(r * 0.72))
+		p1 := c.Add(f32.Pt(dx, dy).Mul(r * 0.95 * pulse))
+		paint.FillShape(ops, sunColor, line(ops, p0, p1, r*0.12))
+	}
+}
+
+// cloud draws a cloud of width w with its flat bottom below c.
+func cloud(ops *op.Ops, c f32.Point, w float32, col color.NRGBA) {
+	circles := []struct{ x, y, r float32 }{
+		{-0.22, 0.05, 0.2},
+		{0, -0.08, 0.28},
+		{0.22, 0.04, 0.2},
+	}
+	for _, k := range circles {
+		center := c.Add(f32.Pt(k.x*w, k.y*w))
+		paint.FillShape(ops, col, clip.Circle{Center: center, Radius: k.r * w}.Op(ops))
+	}
+	base := f32.Rectangle{
+		Min: c.Add(f32.Pt(-0.42*w, 0)),
+		Max: c.Add(f32.Pt(0.42*w, 0.25*w)),
+	}
+	paint.FillShape(ops, col, clip.UniformRRect(base, 0.125*w).Op(ops))
+}
+
+// drops draws n falling rain drops below a cloud at speed falls per
+// second, fading as they fall.
+func drops(ops *op.Ops, s, t float32, n int, speed float32, col color.NRGBA) {
+	for i := 0; i < n; i++ {
+		x := 0.28 + 0.44*float32(i)/float32(n)
+		p := frac(t*speed + float32(i)*0.37)
+		y := 0.6 + p*0.3
+		c := col
+		c.A = uint8(float32(col.A) * (1 - p))
+		paint.FillShape(ops, c, line(ops, f32.Pt(x*s, y*s), f32.Pt((x-0.03)*s, (y+0.08)*s), 0.035*s))
+	}
+}
+
+// flakes draws snowflakes swaying as they fall.
+func flakes(ops *op.Ops, s, t float32) {
+	const n = 5
+	for i := 0; i < n; i++ {
+		x := 0.28 + 0.44*float32(i)/n
+		p := frac(t*0.35 + float32(i)*0.41)
+		x += float32(math.Sin(float64(t)*2+float64(i))) * 0.03
+		y := 0.62 + p*0.32
+		c := snowColor
+		c.A = uint8(0xff * (1 - p*p))
+		paint.FillShape(ops, c, clip.Circle{Center: f32.Pt(x*s, y*s), Radius: 0.035 * s}.Op(ops))
+	}
+}
+
+// bolt draws a lightning bolt that flashes every few seconds.
+func bolt(ops *op.Ops, s, t float32) {
+	p := frac(t / 2.5)
+	if p > 0.12 && !(p > 0.2 && p < 0.3) {
+		return
+	}
+	pts := []f32.Point{{X: 0.52, Y: 0.55}, {X: 0.4, Y: 0.76}, {X: 0.49, Y: 0.76}, {X: 0.42, Y: 0.96}, {X: 0.63, Y: 0.68}, {X: 0.54, Y: 0.68}, {X: 0.62, Y: 0.55}}
+	var path clip.Path
+	path.Begin(ops)
+	path.MoveTo(pts[0].Mul(s))
+	for _, p := range pts[1:] {
+		path.LineTo(p.Mul(s))
+	}
+	path.Close()
+	paint.FillShape(ops, boltColor, clip.Outline{Path: path.End()}.Op())
+}
+
+// line returns the stroke of the line between two points.
+func line(ops *op.Ops, p0, p1 f32.Point, width float32) clip.Op {
+	var p clip.Path
+	p.Begin(ops)
+	p.MoveTo(p0)
+	p.LineTo(p1)
+	return clip.Stroke{Path: p.End(), Style: clip.StrokeStyle{Width: width}}.Op()
+}
+
+func frac(v float32) float32 {
+	return v - float32(math.Floor(float64(v)))
+}
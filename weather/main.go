@@ -0,0 +1,441 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program is a weather dashboard for the free Open-Meteo API. It
+// shows the current weather with an animated vector icon, a chart of the
+// temperature and the chance of rain in the coming hours and the
+// forecast of the week. The location search completes place names with
+// internal/complete, querying the geocoding API as you type.
+//
+// The forecast is fetched in the background and refreshed every
+// -refresh interval. The flags of internal/netsim simulate a slow or
+// unreliable network.
+//
+// Usage:
+//
+//	go run ./weather [-city Berlin] [-fahrenheit] [-net-latency 1s]
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/complete"
+	"gioui.org/example/internal/netsim"
+	"gioui.org/example/internal/shimmer"
+	"gioui.org/example/internal/style"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var (
+	cityFlag       = flag.String("city", "Berlin", "initial location")
+	fahrenheitFlag = flag.Bool("fahrenheit", false, "use degrees Fahrenheit and miles per hour")
+	refreshFlag    = flag.Duration("refresh", 15*time.Minute, "time between forecast refreshes")
+)
+
+func main() {
+	flag.Parse()
+	netsim.Install()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Weather"),
+			app.Size(unit.Dp(860), unit.Dp(720)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+var errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+
+var placeholder = shimmer.New()
+
+// result is a fetched forecast, or the reason there is none.
+type result struct {
+	gen int
+	loc Location
+	f   *Forecast
+	err error
+}
+
+type App struct {
+	client *Client
+	search widget.Editor
+	compl  *complete.Completer
+	// places are the locations of the suggestions of the last search,
+	// keyed by suggestion. The completer searches on another goroutine.
+	mu     sync.Mutex
+	places map[complete.Suggestion]Location
+
+	// loc is the location shown, forecast its forecast if any, and err
+	// the reason the last fetch failed.
+	loc      Location
+	forecast *Forecast
+	fetched  time.Time
+	err      error
+	// gen counts the fetches, to tell the latest one; loading is set
+	// while it runs.
+	gen     int
+	loading bool
+	cancel  context.CancelFunc
+	results chan result
+
+	// epoch is the time of the first frame, the origin of the animation
+	// of the icons.
+	epoch time.Time
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	client := NewClient()
+	client.Fahrenheit = *fahrenheitFlag
+	a := &App{
+		client:  client,
+		places:  make(map[complete.Suggestion]Location),
+		results: make(chan result, 1),
+	}
+	a.search.SingleLine = true
+	a.search.Submit = true
+	a.compl = &complete.Completer{
+		Editor:     &a.search,
+		Source:     a.searchPlaces,
+		Delay:      250 * time.Millisecond,
+		MinLength:  2,
+		Invalidate: w.Invalidate,
+	}
+	a.find(*cityFlag)
+	ticker := time.NewTicker(*refreshFlag)
+	defer ticker.Stop()
+	var ops op.Ops
+	for {
+		select {
+		case res := <-a.results:
+			a.result(res)
+			w.Invalidate()
+		case <-ticker.C:
+			if a.loc != (Location{}) {
+				a.fetch(a.loc)
+			}
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				return e.Err
+			case system.FrameEvent:
+				gtx := layout.NewContext(&ops, e)
+				a.Layout(gtx, th)
+				e.Frame(gtx.Ops)
+			}
+		}
+	}
+}
+
+// searchPlaces is the source of the location suggestions.
+func (a *App) searchPlaces(ctx context.Context, query string) ([]complete.Suggestion, error) {
+	locs, err := a.client.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var res []complete.Suggestion
+	for _, l := range locs {
+		s := complete.Suggestion{Text: l.Name, Detail: l.Detail()}
+		a.places[s] = l
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+// find shows the forecast of the best match of a place name.
+func (a *App) find(name string) {
+	a.run(func(ctx context.Context) (Location, *Forecast, error) {
+		locs, err := a.client.Search(ctx, name)
+		if err != nil {
+			return Location{}, nil, err
+		}
+		if len(locs) == 0 {
+			return Location{}, nil, fmt.Errorf("no place named %q", name)
+		}
+		f, err := a.client.Forecast(ctx, locs[0])
+		return locs[0], f, err
+	})
+}
+
+// fetch shows the forecast of a location.
+func (a *App) fetch(loc Location) {
+	a.run(func(ctx context.Context) (Location, *Forecast, error) {
+		f, err := a.client.Forecast(ctx, loc)
+		return loc, f, err
+	})
+}
+
+// run runs a fetch in the background, cancelling the one running.
+func (a *App) run(fetch func(ctx context.Context) (Location, *Forecast, error)) {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	a.cancel = cancel
+	a.gen++
+	a.loading = true
+	gen, results := a.gen, a.results
+	go func() {
+		defer cancel()
+		loc, f, err := fetch(ctx)
+		if ctx.Err() == context.Canceled {
+			return
+		}
+		// Replace a stale result not yet seen.
+		select {
+		case <-results:
+		default:
+		}
+		results <- result{gen: gen, loc: loc, f: f, err: err}
+	}()
+}
+
+func (a *App) result(res result) {
+	if res.gen != a.gen {
+		return
+	}
+	a.loading = false
+	a.cancel = nil
+	if res.err != nil {
+		a.err = res.err
+		return
+	}
+	a.err = nil
+	a.loc = res.loc
+	a.forecast = res.f
+	a.fetched = time.Now()
+}
+
+func (a *App) update() {
+	for _, e := range a.search.Events() {
+		if e, ok := e.(widget.SubmitEvent); ok && e.Text != "" {
+			a.compl.Close()
+			a.find(e.Text)
+		}
+	}
+	for {
+		s, ok := a.compl.Accepted()
+		if !ok {
+			break
+		}
+		a.mu.Lock()
+		loc, ok := a.places[s]
+		a.mu.Unlock()
+		if ok {
+			a.fetch(loc)
+		}
+	}
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	a.update()
+	if a.epoch.IsZero() {
+		a.epoch = gtx.Now
+	}
+	// The icons and placeholders are animated.
+	op.InvalidateOp{}.Add(gtx.Ops)
+	t := float32(gtx.Now.Sub(a.epoch).Seconds())
+	const headerHeight = 72
+	return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+		// The search field is laid out after the forecast, so that the
+		// popup of suggestions covers it.
+		return layout.Stack{}.Layout(gtx,
+			layout.Expanded(func(gtx C) D {
+				return layout.Inset{Top: unit.Dp(headerHeight)}.Layout(gtx, func(gtx C) D {
+					return a.layoutForecast(gtx, th, t)
+				})
+			}),
+			layout.Stacked(func(gtx C) D {
+				return a.layoutSearch(gtx, th)
+			}),
+		)
+	})
+}
+
+func (a *App) layoutSearch(gtx C, th *material.Theme) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			return widget.Border{
+				Color:        color.NRGBA{A: 0x40},
+				CornerRadius: unit.Dp(4),
+				Width:        unit.Px(1),
+			}.Layout(gtx, func(gtx C) D {
+				return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+					return a.compl.Layout(gtx, th, material.Editor(th, &a.search, "Search places").Layout)
+				})
+			})
+		}),
+		layout.Rigid(func(gtx C) D {
+			l := material.Caption(th, "")
+			l.Color = style.MulAlpha(l.Color, 0xa0)
+			switch {
+			case a.err != nil:
+				l.Text, l.Color = a.err.Error(), errorColor
+			case a.loading || a.compl.Loading():
+				l.Text = "Loading…"
+			case a.forecast != nil:
+				l.Text = fmt.Sprintf("Updated at %s.", a.fetched.Format("15:04"))
+			default:
+				return D{}
+			}
+			return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, l.Layout)
+		}),
+	)
+}
+
+func (a *App) layoutForecast(gtx C, th *material.Theme, t float32) D {
+	f := a.forecast
+	if f == nil {
+		if a.loading {
+			return a.layoutPlaceholder(gtx)
+		}
+		return D{}
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return a.layoutCurrent(gtx, th, t)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+		layout.Rigid(material.Body1(th, "Next 24 hours").Layout),
+		layout.Rigid(func(gtx C) D {
+			gtx.Constraints.Max.Y = gtx.Px(unit.Dp(180))
+			gtx.Constraints.Min = gtx.Constraints.Max
+			return hourlyChart(th, f.Upcoming(24)).Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+		layout.Rigid(func(gtx C) D {
+			return a.layoutDays(gtx, th, t)
+		}),
+	)
+}
+
+func (a *App) layoutCurrent(gtx C, th *material.Theme, t float32) D {
+	cur := a.forecast.Current
+	cond, desc := condition(cur.Code)
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(IconStyle{Cond: cond, Size: unit.Dp(128), Time: t}.Layout),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(24)}.Layout),
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(material.H5(th, a.loc.Name).Layout),
+				layout.Rigid(func(gtx C) D {
+					l := material.Caption(th, a.loc.Detail())
+					l.Color = style.MulAlpha(l.Color, 0xa0)
+					return l.Layout(gtx)
+				}),
+				layout.Rigid(material.H2(th, fmt.Sprintf("%.0f%s", cur.Temp, a.client.TempUnit())).Layout),
+				layout.Rigid(material.Body1(th, desc).Layout),
+				layout.Rigid(func(gtx C) D {
+					wind := fmt.Sprintf("Wind %.0f %s from the %s", cur.Wind, a.client.WindUnit(), compass(cur.WindDir))
+					return material.Body2(th, wind).Layout(gtx)
+				}),
+			)
+		}),
+	)
+}
+
+// layoutDays lays out the forecast of the days in columns.
+func (a *App) layoutDays(gtx C, th *material.Theme, t float32) D {
+	days := a.forecast.Days
+	var cols []layout.FlexChild
+	for i, d := range days {
+		i, d := i, d
+		cols = append(cols, layout.Flexed(1, func(gtx C) D {
+			cond, _ := condition(d.Code)
+			name := d.Date.Format("Mon")
+			if i == 0 {
+				name = "Today"
+			}
+			return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(material.Body2(th, name).Layout),
+				// Offset the animations of the days.
+				layout.Rigid(IconStyle{Cond: cond, Size: unit.Dp(48), Time: t + float32(i)*0.7}.Layout),
+				layout.Rigid(func(gtx C) D {
+					l := material.Body2(th, fmt.Sprintf("%.0f°", d.Max))
+					l.Font.Weight = text.Bold
+					return l.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx C) D {
+					l := material.Body2(th, fmt.Sprintf("%.0f°", d.Min))
+					l.Color = style.MulAlpha(l.Color, 0xa0)
+					return l.Layout(gtx)
+				}),
+			)
+		}))
+	}
+	return layout.Flex{}.Layout(gtx, cols...)
+}
+
+// layoutPlaceholder lays out skeletons of the current weather and the
+// chart while the first forecast loads.
+func (a *App) layoutPlaceholder(gtx C) D {
+	rect := func(w, h unit.Value) layout.Widget {
+		return func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Px(w)
+			gtx.Constraints.Min.Y = gtx.Px(h)
+			if gtx.Constraints.Min.X > gtx.Constraints.Max.X {
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			}
+			return placeholder.Rect(gtx)
+		}
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					return placeholder.Circle(gtx, unit.Dp(128))
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(24)}.Layout),
+				layout.Rigid(func(gtx C) D {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(rect(unit.Dp(160), unit.Dp(24))),
+						layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+						layout.Rigid(rect(unit.Dp(96), unit.Dp(56))),
+						layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+						layout.Rigid(rect(unit.Dp(200), unit.Dp(16))),
+					)
+				}),
+			)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(24)}.Layout),
+		layout.Rigid(rect(unit.Dp(10000), unit.Dp(180))),
+	)
+}
+
+// compass returns the compass point of a direction in degrees.
+func compass(deg float64) string {
+	points := []string{"north", "northeast", "east", "southeast", "south", "southwest", "west", "northwest"}
+	i := int(math.Round(deg/45)) % len(points)
+	if i < 0 {
+		i += len(points)
+	}
+	return points[i]
+}
@@ -0,0 +1,368 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates a multi-step wizard: a step indicator laid out
+// horizontally or vertically, validation that gates moving to the next
+// step and pages that slide in and out when navigating.
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Title("Wizard"),
+			app.Size(unit.Dp(640), unit.Dp(520)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+const transitionDuration = 300 * time.Millisecond
+
+// step is a page of the wizard.
+type step struct {
+	title  string
+	layout func(gtx C, th *material.Theme) D
+	// validate returns the reason the step is incomplete, or the empty
+	// string.
+	validate func() string
+}
+
+// form holds the values entered into the wizard.
+type form struct {
+	name       widget.Editor
+	email      widget.Editor
+	plan       widget.Enum
+	terms      widget.Bool
+	newsletter widget.Bool
+}
+
+func (f *form) steps() []step {
+	field := func(th *material.Theme, label string, e *widget.Editor, hint string) layout.FlexChild {
+		return layout.Rigid(func(gtx C) D {
+			return layout.Inset{Bottom: unit.Dp(16)}.Layout(gtx, func(gtx C) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(material.Caption(th, label).Layout),
+					layout.Rigid(material.Editor(th, e, hint).Layout),
+				)
+			})
+		})
+	}
+	return []step{
+		{
+			title: "Account",
+			layout: func(gtx C, th *material.Theme) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					field(th, "Name", &f.name, "Your name"),
+					field(th, "Email", &f.email, "you@example.com"),
+				)
+			},
+			validate: func() string {
+				if strings.TrimSpace(f.name.Text()) == "" {
+					return "Enter your name"
+				}
+				email := f.email.Text()
+				at := strings.Index(email, "@")
+				if at < 1 || !strings.Contains(email[at:], ".") {
+					return "Enter a valid email address"
+				}
+				return ""
+			},
+		},
+		{
+			title: "Plan",
+			layout: func(gtx C, th *material.Theme) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(material.RadioButton(th, &f.plan, "free", "Free – 1 project").Layout),
+					layout.Rigid(material.RadioButton(th, &f.plan, "pro", "Pro – 10 projects").Layout),
+					layout.Rigid(material.RadioButton(th, &f.plan, "team", "Team – unlimited projects").Layout),
+				)
+			},
+			validate: func() string {
+				if f.plan.Value == "" {
+					return "Choose a plan"
+				}
+				return ""
+			},
+		},
+		{
+			title: "Terms",
+			layout: func(gtx C, th *material.Theme) D {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(material.Body2(th, "The service is provided as is, without warranty of any kind.").Layout),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+					layout.Rigid(material.CheckBox(th, &f.terms, "I accept the terms").Layout),
+					layout.Rigid(material.CheckBox(th, &f.newsletter, "Send me the newsletter").Layout),
+				)
+			},
+			validate: func() string {
+				if !f.terms.Value {
+					return "Accept the terms to continue"
+				}
+				return ""
+			},
+		},
+		{
+			title: "Review",
+			layout: func(gtx C, th *material.Theme) D {
+				newsletter := "No"
+				if f.newsletter.Value {
+					newsletter = "Yes"
+				}
+				var rows []layout.FlexChild
+				for _, r := range [][2]string{
+					{"Name", f.name.Text()},
+					{"Email", f.email.Text()},
+					{"Plan", f.plan.Value},
+					{"Newsletter", newsletter},
+				} {
+					r := r
+					rows = append(rows, layout.Rigid(func(gtx C) D {
+						return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, func(gtx C) D {
+							return layout.Flex{}.Layout(gtx,
+								layout.Rigid(func(gtx C) D {
+									gtx.Constraints.Min.X = gtx.Px(unit.Dp(100))
+									return material.Body2(th, r[0]).Layout(gtx)
+								}),
+								layout.Rigid(material.Body1(th, r[1]).Layout),
+							)
+						})
+					}))
+				}
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
+			},
+			validate: func() string { return "" },
+		},
+	}
+}
+
+// transition is the slide from one step to the current step.
+type transition struct {
+	from  int
+	start time.Time
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	f := new(form)
+	f.name.SingleLine = true
+	f.email.SingleLine = true
+	steps := f.steps()
+	var (
+		ops      op.Ops
+		stepper  = &Stepper{}
+		vertical widget.Bool
+		back     widget.Clickable
+		next     widget.Clickable
+		restart  widget.Clickable
+		current  int
+		reached  int
+		done     bool
+		trans    *transition
+	)
+	for _, s := range steps {
+		stepper.Steps = append(stepper.Steps, s.title)
+	}
+	// goTo moves to step to, if every step before it is valid.
+	goTo := func(now time.Time, to int) {
+		if to == current || to < 0 || to >= len(steps) {
+			return
+		}
+		for i := current; i < to; i++ {
+			if steps[i].validate() != "" {
+				to = i
+				break
+			}
+		}
+		if to == current {
+			return
+		}
+		trans = &transition{from: current, start: now}
+		current = to
+		if current > reached {
+			reached = current
+		}
+	}
+	for {
+		e := <-w.Events()
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			for back.Clicked() {
+				goTo(gtx.Now, current-1)
+			}
+			for next.Clicked() {
+				if current == len(steps)-1 {
+					done = true
+				} else {
+					goTo(gtx.Now, current+1)
+				}
+			}
+			if i, ok := stepper.Clicked(); ok {
+				goTo(gtx.Now, i)
+			}
+			for restart.Clicked() {
+				*f = form{}
+				f.name.SingleLine = true
+				f.email.SingleLine = true
+				current, reached, done, trans = 0, 0, false, nil
+			}
+			if trans != nil && gtx.Now.Sub(trans.start) >= transitionDuration {
+				trans = nil
+			}
+			stepper.Axis = layout.Horizontal
+			if vertical.Value {
+				stepper.Axis = layout.Vertical
+			}
+			invalid := steps[current].validate()
+
+			if done {
+				layout.Center.Layout(gtx, func(gtx C) D {
+					return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+						layout.Rigid(material.H5(th, fmt.Sprintf("Welcome, %s!", f.name.Text())).Layout),
+						layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+						layout.Rigid(material.Button(th, &restart, "Start over").Layout),
+					)
+				})
+				e.Frame(gtx.Ops)
+				continue
+			}
+
+			indicator := func(gtx C) D {
+				return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+					return stepper.Layout(gtx, th, current, reached)
+				})
+			}
+			content := func(gtx C) D {
+				return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(material.H6(th, steps[current].title).Layout),
+						layout.Rigid(layout.Spacer{Height: unit.Dp(16)}.Layout),
+						layout.Flexed(1, func(gtx C) D {
+							return layoutPages(gtx, th, steps, current, trans)
+						}),
+						layout.Rigid(func(gtx C) D {
+							return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+								layout.Rigid(func(gtx C) D {
+									if current == 0 {
+										gtx = gtx.Disabled()
+									}
+									return material.Button(th, &back, "Back").Layout(gtx)
+								}),
+								layout.Flexed(1, func(gtx C) D {
+									if invalid == "" {
+										return D{}
+									}
+									return layout.E.Layout(gtx, func(gtx C) D {
+										return layout.Inset{Right: unit.Dp(12)}.Layout(gtx, func(gtx C) D {
+											l := material.Caption(th, invalid)
+											l.Color = th.Palette.ContrastBg
+											return l.Layout(gtx)
+										})
+									})
+								}),
+								layout.Rigid(func(gtx C) D {
+									if invalid != "" {
+										gtx = gtx.Disabled()
+									}
+									label := "Next"
+									if current == len(steps)-1 {
+										label = "Finish"
+									}
+									return material.Button(th, &next, label).Layout(gtx)
+								}),
+							)
+						}),
+					)
+				})
+			}
+			layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Left: unit.Dp(8), Top: unit.Dp(8)}.Layout(gtx,
+						material.CheckBox(th, &vertical, "Vertical steps").Layout)
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					if vertical.Value {
+						return layout.Flex{}.Layout(gtx,
+							layout.Rigid(func(gtx C) D {
+								gtx.Constraints.Max.X = gtx.Px(unit.Dp(180))
+								return indicator(gtx)
+							}),
+							layout.Flexed(1, content),
+						)
+					}
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(indicator),
+						layout.Flexed(1, content),
+					)
+				}),
+			)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+// layoutPages lays out the current step. During a transition, the
+// previous step slides out while the current step slides in from the
+// side of the navigation.
+func layoutPages(gtx C, th *material.Theme, steps []step, current int, trans *transition) D {
+	size := gtx.Constraints.Max
+	if trans == nil {
+		gtx.Constraints.Min = size
+		return steps[current].layout(gtx, th)
+	}
+	t := float32(gtx.Now.Sub(trans.start)) / float32(transitionDuration)
+	if t > 1 {
+		t = 1
+	}
+	// Ease out.
+	t = 1 - (1-t)*(1-t)
+	dir := float32(1)
+	if current < trans.from {
+		dir = -1
+	}
+	w := float32(size.X)
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect(image.Rectangle{Max: size}).Add(gtx.Ops)
+	page := func(gtx C, i int, x float32) {
+		defer op.Save(gtx.Ops).Load()
+		op.Offset(f32.Pt(x, 0)).Add(gtx.Ops)
+		gtx.Constraints.Min = size
+		steps[i].layout(gtx, th)
+	}
+	// The leaving page doesn't receive input.
+	page(gtx.Disabled(), trans.from, -dir*t*w)
+	page(gtx, current, dir*(1-t)*w)
+	op.InvalidateOp{}.Add(gtx.Ops)
+	return D{Size: size}
+}
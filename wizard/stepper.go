@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Stepper is a step indicator: numbered circles joined by connectors,
+// with completed steps checked. Reached steps can be clicked.
+type Stepper struct {
+	Steps []string
+	Axis  layout.Axis
+
+	clicks []widget.Clickable
+}
+
+// Clicked returns the step clicked, if any.
+func (s *Stepper) Clicked() (int, bool) {
+	for i := range s.clicks {
+		for s.clicks[i].Clicked() {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Layout lays out the steps with the current step highlighted. Steps
+// after reached are disabled.
+func (s *Stepper) Layout(gtx C, th *material.Theme, current, reached int) D {
+	n := len(s.Steps)
+	if len(s.clicks) < n {
+		s.clicks = make([]widget.Clickable, n)
+	}
+	d := gtx.Px(unit.Dp(28))
+	horizontal := s.Axis == layout.Horizontal
+	var cell image.Point
+	if horizontal {
+		cell = image.Pt(gtx.Constraints.Max.X/n, d+gtx.Px(unit.Dp(28)))
+	} else {
+		cell = image.Pt(gtx.Constraints.Max.X, gtx.Px(unit.Dp(64)))
+	}
+	origin := func(i int) image.Point {
+		if horizontal {
+			return image.Pt(i*cell.X, 0)
+		}
+		return image.Pt(0, i*cell.Y)
+	}
+	// center is the center of the circle of step i.
+	center := func(i int) image.Point {
+		if horizontal {
+			return origin(i).Add(image.Pt(cell.X/2, d/2))
+		}
+		return origin(i).Add(image.Pt(d/2, d/2))
+	}
+	muted := color.NRGBA{A: 0x40}
+
+	// The connectors, under the circles.
+	lw := gtx.Px(unit.Dp(2))
+	for i := 0; i < n-1; i++ {
+		a, b := center(i), center(i+1)
+		c := muted
+		if i < reached {
+			c = th.Palette.ContrastBg
+		}
+		r := image.Rect(a.X-lw/2, a.Y+d/2, a.X-lw/2+lw, b.Y-d/2)
+		if horizontal {
+			r = image.Rect(a.X+d/2, a.Y-lw/2, b.X-d/2, a.Y-lw/2+lw)
+		}
+		paint.FillShape(gtx.Ops, c, clip.Rect(r).Op())
+	}
+
+	for i, name := range s.Steps {
+		stack := op.Save(gtx.Ops)
+		op.Offset(layout.FPt(origin(i))).Add(gtx.Ops)
+		cgtx := gtx
+		cgtx.Constraints = layout.Exact(cell)
+		if i > reached {
+			cgtx = cgtx.Disabled()
+		}
+		i, name := i, name
+		material.Clickable(cgtx, &s.clicks[i], func(gtx C) D {
+			s.layoutStep(gtx, th, i, name, current, reached, d)
+			return D{Size: cell}
+		})
+		stack.Load()
+	}
+	if horizontal {
+		return D{Size: image.Pt(gtx.Constraints.Max.X, cell.Y)}
+	}
+	return D{Size: image.Pt(cell.X, n*cell.Y)}
+}
+
+// layoutStep draws the circle of step i of diameter d and its name.
+func (s *Stepper) layoutStep(gtx C, th *material.Theme, i int, name string, current, reached, d int) {
+	bg, fg := color.NRGBA{A: 0x40}, th.Palette.ContrastFg
+	if i <= reached {
+		bg = th.Palette.ContrastBg
+	}
+	mark := fmt.Sprint(i + 1)
+	if i < current {
+		mark = "✓"
+	}
+	label := material.Body2(th, name)
+	if i > reached {
+		label.Color = color.NRGBA{A: 0x80}
+	}
+	if i == current {
+		label.Font.Weight = text.Bold
+	}
+
+	var circlePos, labelPos image.Point
+	lgtx := gtx
+	lgtx.Constraints.Min = image.Point{}
+	macro := op.Record(gtx.Ops)
+	dims := label.Layout(lgtx)
+	call := macro.Stop()
+	if s.Axis == layout.Horizontal {
+		w := gtx.Constraints.Max.X
+		circlePos = image.Pt((w-d)/2, 0)
+		labelPos = image.Pt((w-dims.Size.X)/2, d+gtx.Px(unit.Dp(4)))
+	} else {
+		labelPos = image.Pt(d+gtx.Px(unit.Dp(12)), (d-dims.Size.Y)/2)
+	}
+
+	stack := op.Save(gtx.Ops)
+	op.Offset(layout.FPt(circlePos)).Add(gtx.Ops)
+	rect := f32.Rectangle{Max: f32.Pt(float32(d), float32(d))}
+	paint.FillShape(gtx.Ops, bg, clip.UniformRRect(rect, float32(d)/2).Op(gtx.Ops))
+	cgtx := gtx
+	cgtx.Constraints = layout.Exact(image.Pt(d, d))
+	m := material.Body2(th, mark)
+	m.Color = fg
+	layout.Center.Layout(cgtx, m.Layout)
+	stack.Load()
+
+	stack = op.Save(gtx.Ops)
+	op.Offset(layout.FPt(labelPos)).Add(gtx.Ops)
+	call.Add(gtx.Ops)
+	stack.Load()
+}
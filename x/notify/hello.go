@@ -3,6 +3,9 @@
 package main
 
 // A simple Gio program. See https://gioui.org for more information.
+//
+// Sending a notification chimes on success and buzzes on failure, with
+// the sounds of package gioui.org/example/internal/cue.
 
 import (
 	//	"image/color"
@@ -10,6 +13,7 @@ import (
 	"time"
 
 	"gioui.org/app"
+	"gioui.org/example/internal/cue"
 	"gioui.org/io/system"
 	"gioui.org/layout"
 	"gioui.org/op"
@@ -25,7 +29,9 @@ import (
 func main() {
 	go func() {
 		w := app.NewWindow()
-		if err := loop(w); err != nil {
+		err := loop(w)
+		cue.Close()
+		if err != nil {
 			log.Fatal(err)
 		}
 	}()
@@ -38,6 +44,7 @@ func loop(w *app.Window) error {
 	first := true
 	notificationRequests := make(chan struct{})
 	var button widget.Clickable
+	var mute widget.Bool
 	var err error
 	for {
 		e := <-w.Events()
@@ -48,6 +55,9 @@ func loop(w *app.Window) error {
 			if button.Clicked() {
 				notificationRequests <- struct{}{}
 			}
+			if mute.Changed() {
+				cue.SetMuted(mute.Value)
+			}
 			gtx := layout.NewContext(&ops, e)
 
 			layout.Flex{Axis: layout.Vertical}.Layout(gtx,
@@ -58,6 +68,7 @@ func loop(w *app.Window) error {
 					}
 					return material.Body1(th, text).Layout(gtx)
 				}),
+				layout.Rigid(material.CheckBox(th, &mute, "Mute sounds").Layout),
 				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 					return material.Button(th, &button, "Send Notification").Layout(gtx)
 				}),
@@ -78,8 +89,10 @@ func loop(w *app.Window) error {
 						if e != nil {
 							log.Printf("notification send failed: %v", e)
 							err = e
+							cue.Play(cue.Error)
 							continue
 						}
+						cue.Play(cue.Success)
 						go func() {
 							time.Sleep(time.Second * 10)
 							if err = notif.Cancel(); err != nil {
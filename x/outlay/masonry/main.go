@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates a masonry (waterfall) layout of cards with
+// varying heights. Use the button to prepend cards and notice that the
+// cards on screen stay in place.
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math/rand"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+func main() {
+	go func() {
+		w := app.NewWindow(
+			app.Size(unit.Dp(800), unit.Dp(600)),
+			app.Title("Masonry"),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// card is an item in the masonry.
+type card struct {
+	id    int
+	lines int
+	color color.NRGBA
+	// aspect is the height of the picture relative to its width.
+	aspect float32
+}
+
+func newCard(id int) card {
+	r := rand.New(rand.NewSource(int64(id)))
+	return card{
+		id:     id,
+		lines:  1 + r.Intn(4),
+		aspect: .5 + r.Float32(),
+		color: color.NRGBA{
+			R: uint8(0x60 + r.Intn(0x80)),
+			G: uint8(0x60 + r.Intn(0x80)),
+			B: uint8(0x60 + r.Intn(0x80)),
+			A: 0xff,
+		},
+	}
+}
+
+func loop(w *app.Window) error {
+	th := material.NewTheme(gofont.Collection())
+	var cards []card
+	for i := 0; i < 500; i++ {
+		cards = append(cards, newCard(i))
+	}
+	nextID := -1
+	masonry := Masonry{
+		MinColumnWidth: unit.Dp(160),
+		Gap:            unit.Dp(8),
+	}
+	var prepend widget.Clickable
+
+	var ops op.Ops
+	for e := range w.Events() {
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			for prepend.Clicked() {
+				var added []card
+				for i := 0; i < 10; i++ {
+					added = append([]card{newCard(nextID)}, added...)
+					nextID--
+				}
+				cards = append(added, cards...)
+				masonry.Prepend(len(added))
+			}
+			layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					return layout.UniformInset(unit.Dp(8)).Layout(gtx,
+						material.Button(th, &prepend, "Prepend 10 cards").Layout,
+					)
+				}),
+				layout.Flexed(1, func(gtx C) D {
+					return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+						return masonry.Layout(gtx, len(cards), func(gtx C, i int) D {
+							return layoutCard(gtx, th, cards[i])
+						})
+					})
+				}),
+			)
+			e.Frame(gtx.Ops)
+		}
+	}
+	return nil
+}
+
+func layoutCard(gtx C, th *material.Theme, c card) D {
+	width := gtx.Constraints.Min.X
+	return layout.Stack{}.Layout(gtx,
+		layout.Expanded(func(gtx C) D {
+			bounds := f32.Rectangle{Max: layout.FPt(gtx.Constraints.Min)}
+			paint.FillShape(gtx.Ops, cardBackground, clip.UniformRRect(bounds, 6).Op(gtx.Ops))
+			return D{Size: gtx.Constraints.Min}
+		}),
+		layout.Stacked(func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					sz := image.Pt(width, int(float32(width)*c.aspect))
+					bounds := f32.Rectangle{Max: layout.FPt(sz)}
+					paint.FillShape(gtx.Ops, c.color, clip.UniformRRect(bounds, 6).Op(gtx.Ops))
+					return D{Size: sz}
+				}),
+				layout.Rigid(func(gtx C) D {
+					return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+						l := material.Body2(th, fmt.Sprintf("Card %d. %s", c.id, lorem[:c.lines*40]))
+						return l.Layout(gtx)
+					})
+				}),
+			)
+		}),
+	)
+}
+
+var cardBackground = color.NRGBA{R: 0xf0, G: 0xf0, B: 0xf0, A: 0xff}
+
+const lorem = "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis nostrud exercitation."
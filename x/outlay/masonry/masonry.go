@@ -0,0 +1,201 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/unit"
+)
+
+// Masonry lays out items of varying heights in balanced columns, placing
+// each item at the bottom of the shortest column. Only the visible items
+// are laid out, and items are measured lazily as they scroll into view.
+//
+// Items prepended with Prepend are stacked upwards on top of the existing
+// ones, so the items already on screen keep their positions.
+type Masonry struct {
+	// MinColumnWidth determines the number of columns.
+	MinColumnWidth unit.Value
+	// Gap is the space between items.
+	Gap unit.Value
+
+	// offset is the scroll position in content coordinates. It becomes
+	// negative when items are prepended.
+	offset  int
+	width   int
+	columns []column
+	items   []placement
+	prepend int
+
+	dragging bool
+	last     float32
+}
+
+type column struct {
+	// top is the position of the topmost item; bottom is where the next
+	// item will be placed.
+	top, bottom int
+}
+
+type placement struct {
+	col, y, h int
+}
+
+// Prepend records that n items were inserted at the start of the list.
+func (m *Masonry) Prepend(n int) {
+	m.prepend += n
+}
+
+// Layout lays out n items.
+func (m *Masonry) Layout(gtx layout.Context, n int, w layout.ListElement) layout.Dimensions {
+	viewport := gtx.Constraints.Max
+	gap := gtx.Px(m.Gap)
+	cols := viewport.X / gtx.Px(m.MinColumnWidth)
+	if cols < 1 {
+		cols = 1
+	}
+	colWidth := (viewport.X - gap*(cols-1)) / cols
+	if colWidth != m.width || cols != len(m.columns) || n < len(m.items)+m.prepend {
+		// The geometry or the items changed; start over.
+		m.width = colWidth
+		m.columns = make([]column, cols)
+		m.items = m.items[:0]
+		m.prepend = 0
+		m.offset = 0
+	}
+
+	measure := func(i int) int {
+		gtx := gtx
+		gtx.Constraints = layout.Constraints{
+			Min: image.Pt(colWidth, 0),
+			Max: image.Pt(colWidth, viewport.Y*4),
+		}
+		macro := op.Record(gtx.Ops)
+		dims := w(gtx, i)
+		macro.Stop()
+		return dims.Size.Y
+	}
+
+	if m.prepend > 0 && len(m.items) > 0 {
+		// Place the new items upwards, starting with the last one so it
+		// ends up adjacent to the existing items.
+		added := make([]placement, m.prepend)
+		for i := m.prepend - 1; i >= 0; i-- {
+			h := measure(i)
+			c := 0
+			for j, col := range m.columns {
+				if col.top > m.columns[c].top {
+					c = j
+				}
+			}
+			y := m.columns[c].top - gap - h
+			m.columns[c].top = y
+			added[i] = placement{col: c, y: y, h: h}
+		}
+		m.items = append(added, m.items...)
+	}
+	m.prepend = 0
+
+	m.scroll(gtx)
+
+	// Measure and place items until the viewport is filled.
+	for len(m.items) < n && m.shortest() < m.offset+viewport.Y {
+		i := len(m.items)
+		h := measure(i)
+		c := m.shortestColumn()
+		y := m.columns[c].bottom
+		m.columns[c].bottom = y + h + gap
+		m.items = append(m.items, placement{col: c, y: y, h: h})
+	}
+
+	// Clamp the scroll position to the content.
+	top, bottom := m.extent()
+	if len(m.items) == n && m.offset > bottom-viewport.Y {
+		m.offset = bottom - viewport.Y
+	}
+	if m.offset < top {
+		m.offset = top
+	}
+
+	defer op.Save(gtx.Ops).Load()
+	bounds := image.Rectangle{Max: viewport}
+	clip.Rect(bounds).Add(gtx.Ops)
+	pointer.Rect(bounds).Add(gtx.Ops)
+	pointer.InputOp{
+		Tag:   m,
+		Grab:  m.dragging,
+		Types: pointer.Press | pointer.Drag | pointer.Release | pointer.Scroll,
+		// The router clamps scroll distances to the bounds; the offset is
+		// clamped to the content instead.
+		ScrollBounds: image.Rect(0, -1e6, 0, 1e6),
+	}.Add(gtx.Ops)
+	for i, p := range m.items {
+		if p.y+p.h <= m.offset || p.y >= m.offset+viewport.Y {
+			continue
+		}
+		stack := op.Save(gtx.Ops)
+		x := p.col * (colWidth + gap)
+		op.Offset(f32.Pt(float32(x), float32(p.y-m.offset))).Add(gtx.Ops)
+		gtx := gtx
+		gtx.Constraints = layout.Exact(image.Pt(colWidth, p.h))
+		w(gtx, i)
+		stack.Load()
+	}
+	return layout.Dimensions{Size: viewport}
+}
+
+// scroll applies pointer scrolling and dragging.
+func (m *Masonry) scroll(gtx layout.Context) {
+	for _, e := range gtx.Events(m) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case pointer.Press:
+			m.dragging = true
+			m.last = e.Position.Y
+		case pointer.Drag:
+			m.offset += int(m.last - e.Position.Y)
+			m.last = e.Position.Y
+		case pointer.Release, pointer.Cancel:
+			m.dragging = false
+		case pointer.Scroll:
+			m.offset += int(e.Scroll.Y)
+		}
+	}
+}
+
+// shortestColumn returns the index of the column with the least height.
+func (m *Masonry) shortestColumn() int {
+	c := 0
+	for i, col := range m.columns {
+		if col.bottom < m.columns[c].bottom {
+			c = i
+		}
+	}
+	return c
+}
+
+func (m *Masonry) shortest() int {
+	return m.columns[m.shortestColumn()].bottom
+}
+
+// extent returns the top and bottom of the placed content.
+func (m *Masonry) extent() (top, bottom int) {
+	for _, col := range m.columns {
+		if col.top < top {
+			top = col.top
+		}
+		if col.bottom > bottom {
+			bottom = col.bottom
+		}
+	}
+	return top, bottom
+}
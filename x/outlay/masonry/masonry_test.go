@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"image"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+)
+
+func TestMasonryScroll(t *testing.T) {
+	var (
+		r   router.Router
+		ops op.Ops
+	)
+	m := &Masonry{MinColumnWidth: unit.Px(100), Gap: unit.Px(10)}
+	item := func(gtx layout.Context, i int) layout.Dimensions {
+		return layout.Dimensions{Size: image.Pt(gtx.Constraints.Min.X, 50)}
+	}
+	frame := func() {
+		ops.Reset()
+		gtx := layout.Context{
+			Ops:         &ops,
+			Queue:       &r,
+			Constraints: layout.Exact(image.Pt(200, 200)),
+		}
+		m.Layout(gtx, 100, item)
+		r.Frame(&ops)
+	}
+	frame()
+	r.Queue(pointer.Event{
+		Type:     pointer.Scroll,
+		Source:   pointer.Mouse,
+		Position: f32.Pt(50, 50),
+		Scroll:   f32.Pt(0, 120),
+	})
+	frame()
+	if m.offset != 120 {
+		t.Errorf("scrolled to %d, want 120", m.offset)
+	}
+}
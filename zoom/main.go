@@ -0,0 +1,310 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+// This program demonstrates zooming a user interface: Ctrl+= and Ctrl+-
+// (Cmd on macOS) step the zoom from 75% to 300% and Ctrl+0 resets it.
+// The zoom is saved and restored the next time the program starts.
+//
+// The zoom multiplies the pixels per dp and sp of the metric of the
+// frames, on top of the scale of the system, so everything sized in dp
+// and sp is laid out again at the new size. Unlike scaling the finished
+// frame with a transform, text and icons are drawn at their new pixel
+// size and stay sharp, and pointer positions need no conversion.
+// Images are the exception: an image drawn at one size is blurred when
+// scaled, so draw them again at the new pixel size, as the example
+// shows next to one that is only scaled.
+//
+// Usage:
+//
+//	go run ./zoom [-prefs file]
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+
+	"gioui.org/app"
+	"gioui.org/example/internal/shortcut"
+	"gioui.org/font/gofont"
+	"gioui.org/io/key"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+var prefsFlag = flag.String("prefs", "", "preferences file (default in the user configuration directory)")
+
+var (
+	errorColor = color.NRGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}
+	ringColor  = color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0xff}
+)
+
+// imageSize is the size of the images, in dp.
+const imageSize = 120
+
+func main() {
+	flag.Parse()
+	go func() {
+		w := app.NewWindow(
+			app.Title("Zoom"),
+			app.Size(unit.Dp(800), unit.Dp(600)),
+		)
+		if err := loop(w); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// actions are the zoom shortcuts. Shift is ignored, because the plus
+// sign is on the key of the equals sign on many keyboards.
+var actions = []shortcut.Action{
+	{ID: "in", Description: "Zoom in", Default: "Shortcut+="},
+	{ID: "in-plus", Description: "Zoom in", Default: "Shortcut++"},
+	{ID: "out", Description: "Zoom out", Default: "Shortcut+-"},
+	{ID: "reset", Description: "Actual size", Default: "Shortcut+0"},
+}
+
+type App struct {
+	prefs     *Prefs
+	saveErr   error
+	shortcuts *shortcut.Map
+
+	in, out, reset widget.Clickable
+	button         widget.Clickable
+	check          widget.Bool
+	list           layout.List
+	icon           *widget.Icon
+
+	// sharp is the image drawn at the pixel size of the last frame, and
+	// scaled the image drawn once at a pixel per dp.
+	sharp     paint.ImageOp
+	sharpSize int
+	scaled    paint.ImageOp
+}
+
+func loop(w *app.Window) error {
+	path := *prefsFlag
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(dir, "gio-zoom", "prefs.json")
+	}
+	prefs, err := LoadPrefs(path)
+	if err != nil {
+		return err
+	}
+	m, err := shortcut.NewMap(actions)
+	if err != nil {
+		return err
+	}
+	icon, err := widget.NewIcon(icons.ActionZoomIn)
+	if err != nil {
+		return err
+	}
+	th := material.NewTheme(gofont.Collection())
+	a := &App{
+		prefs:     prefs,
+		shortcuts: m,
+		icon:      icon,
+		list:      layout.List{Axis: layout.Vertical},
+		scaled:    paint.NewImageOp(drawRings(imageSize, ringColor)),
+	}
+	var ops op.Ops
+	for e := range w.Events() {
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			a.Layout(gtx, th)
+			e.Frame(gtx.Ops)
+		case key.Event:
+			e.Modifiers &^= key.ModShift
+			if id, ok := a.shortcuts.Lookup(e); ok {
+				a.run(id)
+				w.Invalidate()
+			}
+		}
+	}
+	return nil
+}
+
+// run performs a zoom action.
+func (a *App) run(id string) {
+	l := nearest(a.prefs.Scale)
+	switch id {
+	case "in", "in-plus":
+		if l < len(scales)-1 {
+			l++
+		}
+	case "out":
+		if l > 0 {
+			l--
+		}
+	case "reset":
+		l = nearest(1)
+	}
+	if scales[l] == a.prefs.Scale {
+		return
+	}
+	a.prefs.Scale = scales[l]
+	a.saveErr = a.prefs.Save()
+}
+
+func (a *App) Layout(gtx C, th *material.Theme) D {
+	for a.in.Clicked() {
+		a.run("in")
+	}
+	for a.out.Clicked() {
+		a.run("out")
+	}
+	for a.reset.Clicked() {
+		a.run("reset")
+	}
+	sys := gtx.Metric
+	gtx.Metric.PxPerDp *= a.prefs.Scale
+	gtx.Metric.PxPerSp *= a.prefs.Scale
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return a.layoutToolbar(gtx, th)
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			items := []layout.Widget{
+				material.H4(th, "Zoom").Layout,
+				material.Body1(th, "Everything here is sized in dp and sp, and zooming changes how many pixels they are. "+
+					"Text and icons are drawn again at the new size and stay sharp at every zoom level.").Layout,
+				func(gtx C) D {
+					return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+						layout.Rigid(material.Button(th, &a.button, "Button").Layout),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+						layout.Rigid(material.CheckBox(th, &a.check, "Check box").Layout),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+						layout.Rigid(func(gtx C) D {
+							a.icon.Color = th.Palette.ContrastBg
+							return a.icon.Layout(gtx, unit.Dp(36))
+						}),
+					)
+				},
+				func(gtx C) D {
+					return layout.Flex{}.Layout(gtx,
+						layout.Rigid(func(gtx C) D {
+							return a.layoutFigure(gtx, th, a.layoutSharp, "Drawn at the pixel size")
+						}),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(24)}.Layout),
+						layout.Rigid(func(gtx C) D {
+							return a.layoutFigure(gtx, th, a.layoutScaled, "Drawn at 100% and scaled")
+						}),
+					)
+				},
+				material.Caption(th, fmt.Sprintf("System %.2f px/dp × zoom %.0f%% = %.2f px/dp",
+					sys.PxPerDp, a.prefs.Scale*100, gtx.Metric.PxPerDp)).Layout,
+			}
+			if a.saveErr != nil {
+				l := material.Caption(th, "The zoom couldn't be saved: "+a.saveErr.Error())
+				l.Color = errorColor
+				items = append(items, l.Layout)
+			}
+			return a.list.Layout(gtx, len(items), func(gtx C, i int) D {
+				return layout.Inset{Left: unit.Dp(16), Right: unit.Dp(16), Top: unit.Dp(8), Bottom: unit.Dp(8)}.Layout(gtx, items[i])
+			})
+		}),
+	)
+}
+
+func (a *App) layoutToolbar(gtx C, th *material.Theme) D {
+	b := func(c *widget.Clickable, label string) layout.FlexChild {
+		return layout.Rigid(func(gtx C) D {
+			btn := material.Button(th, c, label)
+			btn.Inset = layout.Inset{Left: unit.Dp(12), Right: unit.Dp(12), Top: unit.Dp(6), Bottom: unit.Dp(6)}
+			return btn.Layout(gtx)
+		})
+	}
+	help := fmt.Sprintf("%s zoom in, %s zoom out, %s actual size",
+		a.shortcuts.Binding("in"), a.shortcuts.Binding("out"), a.shortcuts.Binding("reset"))
+	return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx C) D {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			b(&a.out, "−"),
+			layout.Rigid(func(gtx C) D {
+				gtx.Constraints.Min.X = gtx.Px(unit.Dp(64))
+				return layout.Center.Layout(gtx, material.Body1(th, fmt.Sprintf("%.0f%%", a.prefs.Scale*100)).Layout)
+			}),
+			b(&a.in, "+"),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+			b(&a.reset, "Reset"),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+			layout.Flexed(1, material.Caption(th, help).Layout),
+		)
+	})
+}
+
+// layoutFigure lays out an image above its caption.
+func (a *App) layoutFigure(gtx C, th *material.Theme, img layout.Widget, caption string) D {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(img),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+		layout.Rigid(material.Caption(th, caption).Layout),
+	)
+}
+
+// layoutSharp draws the rings at the pixel size, drawing them again
+// when the size changes.
+func (a *App) layoutSharp(gtx C) D {
+	sz := gtx.Px(unit.Dp(imageSize))
+	if sz != a.sharpSize {
+		a.sharp = paint.NewImageOp(drawRings(sz, ringColor))
+		a.sharpSize = sz
+	}
+	defer op.Save(gtx.Ops).Load()
+	clip.Rect{Max: image.Pt(sz, sz)}.Add(gtx.Ops)
+	a.sharp.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+	return D{Size: image.Pt(sz, sz)}
+}
+
+// layoutScaled draws the rings drawn at a pixel per dp, scaled.
+func (a *App) layoutScaled(gtx C) D {
+	return widget.Image{Src: a.scaled, Scale: 1}.Layout(gtx)
+}
+
+// drawRings draws concentric rings in a square image of size pixels,
+// the same rings at every size.
+func drawRings(size int, fg color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	// period is the width of a ring and its gap, in pixels.
+	period := float64(size) / imageSize * 3
+	c := float64(size) / 2
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			d := math.Hypot(float64(x)+.5-c, float64(y)+.5-c)
+			col := color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+			if d < c*0.95 && math.Mod(d, period) < period/2 {
+				col = fg
+			}
+			img.SetNRGBA(x, y, col)
+		}
+	}
+	return img
+}
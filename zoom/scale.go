@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// scales are the zoom levels, as in web browsers.
+var scales = []float32{0.75, 0.9, 1, 1.1, 1.25, 1.5, 1.75, 2, 2.5, 3}
+
+// nearest returns the index of the zoom level closest to s.
+func nearest(s float32) int {
+	best := 0
+	for i, l := range scales {
+		if abs(l-s) < abs(scales[best]-s) {
+			best = i
+		}
+	}
+	return best
+}
+
+func abs(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Prefs are the preferences of the program, saved to a JSON file after
+// every change.
+type Prefs struct {
+	path string
+	// Scale is the zoom level.
+	Scale float32 `json:"scale"`
+}
+
+// LoadPrefs loads the preferences of a file. A missing file has the
+// defaults. The scale is rounded to the nearest zoom level.
+func LoadPrefs(path string) (*Prefs, error) {
+	p := &Prefs{path: path, Scale: 1}
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, p); err != nil {
+			return nil, err
+		}
+	}
+	p.Scale = scales[nearest(p.Scale)]
+	return p, nil
+}
+
+// Save saves the preferences.
+func (p *Prefs) Save() error {
+	data, err := json.MarshalIndent(p, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0700); err != nil {
+		return err
+	}
+	tmp := p.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.path)
+}
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestNearest(t *testing.T) {
+	tests := []struct {
+		s    float32
+		want float32
+	}{
+		{0.1, 0.75},
+		{1, 1},
+		{1.2, 1.25},
+		{1.3, 1.25},
+		{9, 3},
+	}
+	for _, test := range tests {
+		if got := scales[nearest(test.s)]; got != test.want {
+			t.Errorf("nearest level to %v: got %v, want %v", test.s, got, test.want)
+		}
+	}
+}
+
+func TestPrefs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zoom", "prefs.json")
+	p, err := LoadPrefs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Scale != 1 {
+		t.Errorf("default scale is %v", p.Scale)
+	}
+	p.Scale = 1.5
+	if err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+	p, err = LoadPrefs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Scale != 1.5 {
+		t.Errorf("loaded scale %v, want 1.5", p.Scale)
+	}
+	if err := ioutil.WriteFile(path, []byte(`{"scale": 7}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	p, err = LoadPrefs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Scale != 3 {
+		t.Errorf("loaded an out of range scale as %v, want 3", p.Scale)
+	}
+}